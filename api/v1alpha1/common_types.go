@@ -46,6 +46,28 @@ const (
 	OperationRemoveBroker CruiseControlTaskOperation = "remove_broker"
 	// OperationRebalance means a Cruise Control rebalance operation
 	OperationRebalance CruiseControlTaskOperation = "rebalance"
+	// OperationDemoteBroker means a Cruise Control demote_broker operation
+	OperationDemoteBroker CruiseControlTaskOperation = "demote_broker"
+	// OperationPreferredLeaderElection means moving the partition leaderships back to the preferred replicas, it is
+	// executed as a Cruise Control rebalance which uses only the PreferredLeaderElectionGoal
+	OperationPreferredLeaderElection CruiseControlTaskOperation = "preferred_leader_election"
+	// OperationFixOfflineReplicas means a Cruise Control fix_offline_replicas operation which moves the offline
+	// replicas, e.g. the ones of the permanently lost brokers, to the alive brokers
+	OperationFixOfflineReplicas CruiseControlTaskOperation = "fix_offline_replicas"
+	// Cruise Control REST API parameters
+	// Check for more details: https://github.com/linkedin/cruise-control/wiki/REST-APIs
+	ParamBrokerID                     = "brokerid"
+	ParamDestbrokerIDs                = "destination_broker_ids"
+	ParamExcludedTopics               = "excluded_topics"
+	ParamGoals                        = "goals"
+	ParamConcurrentPartitionMovements = "concurrent_partition_movements_per_broker"
+	ParamConcurrentLeaderMovements    = "concurrent_leader_movements"
+	ParamDryRun                       = "dryrun"
+	ParamRebalanceDisk                = "rebalance_disk"
+	ParamExcludeDemoted               = "exclude_recently_demoted_brokers"
+	ParamExcludeRemoved               = "exclude_recently_removed_brokers"
+	ParamSkipHardGoalCheck            = "skip_hard_goal_check"
+	ParamReason                       = "reason"
 	// KafkaAccessTypeRead states that a user wants consume access to a topic
 	KafkaAccessTypeRead KafkaAccessType = "read"
 	// KafkaAccessTypeWrite states that a user wants produce access to a topic
@@ -64,8 +86,15 @@ const (
 	TLSJKSKeyStore string = "keystore.jks"
 	// TLSJKSTrustStore is where a JKS truststore is stored in a user secret when requested
 	TLSJKSTrustStore string = "truststore.jks"
+	// TLSPKCS12KeyStore is where a PKCS12 keystore is stored in a user secret when requested
+	TLSPKCS12KeyStore string = "keystore.p12"
+	// TLSPKCS12TrustStore is where a PKCS12 truststore is stored in a user secret when requested
+	TLSPKCS12TrustStore string = "truststore.p12"
 	// CoreCACertKey is where ca certificates are stored in user certificates
 	CoreCACertKey string = "ca.crt"
+	// TrustBundleKey is where the CA certificates of the cluster and the additional trusted CA certificates
+	// are stored in user certificates
+	TrustBundleKey string = "trust-bundle.crt"
 	// CaChainPem is where CA certificate(s) are stored as a chain for user secret
 	CaChainPem string = "chain.pem"
 	// CACertKey is the key where the CA certificate is stored in the operator secrets
@@ -82,4 +111,14 @@ const (
 	PeerPrivateKeyKey string = "peerKey"
 	// PasswordKey stores the JKS password
 	PasswordKey string = "password"
+	// DelegationTokenIDKey is where the ID of the delegation token is stored in the delegation token secret
+	DelegationTokenIDKey string = "tokenId"
+	// DelegationTokenHMACKey is where the HMAC of the delegation token is stored in the delegation token secret
+	DelegationTokenHMACKey string = "hmac"
+	// DelegationTokenJAASConfigKey is where the SASL JAAS configuration of the clients authenticating with the
+	// delegation token is stored in the delegation token secret
+	DelegationTokenJAASConfigKey string = "sasl.jaas.config"
+	// DefaultClientSASLMechanism is the SCRAM mechanism the clients deployed by the operator authenticate with on
+	// SASL listeners by default
+	DefaultClientSASLMechanism string = "SCRAM-SHA-512"
 )
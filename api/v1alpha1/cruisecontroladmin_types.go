@@ -0,0 +1,130 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CruiseControlAdminStateApplied means that the desired configuration has been applied to Cruise Control.
+	CruiseControlAdminStateApplied CruiseControlAdminState = "Applied"
+	// CruiseControlAdminStateFailed means that applying the desired configuration to Cruise Control failed.
+	CruiseControlAdminStateFailed CruiseControlAdminState = "Failed"
+)
+
+// CruiseControlAdminState defines the state of applying a CruiseControlAdmin to Cruise Control.
+type CruiseControlAdminState string
+
+// CruiseControlAnomalyType is a type of anomaly which can be detected by the Cruise Control anomaly detector.
+// +kubebuilder:validation:Enum=GOAL_VIOLATION;BROKER_FAILURE;METRIC_ANOMALY;DISK_FAILURE;TOPIC_ANOMALY
+type CruiseControlAnomalyType string
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterRef.name"
+//+kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// CruiseControlAdmin is the Schema for the cruiseControlAdmin API.
+// It is used for changing the runtime configuration of Cruise Control through its admin endpoint.
+type CruiseControlAdmin struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CruiseControlAdminSpec   `json:"spec,omitempty"`
+	Status CruiseControlAdminStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// CruiseControlAdminList contains a list of CruiseControlAdmin.
+type CruiseControlAdminList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CruiseControlAdmin `json:"items"`
+}
+
+// CruiseControlAdminSpec defines the desired runtime configuration of Cruise Control.
+type CruiseControlAdminSpec struct {
+	ClusterRef ClusterReference `json:"clusterRef"`
+	// Concurrency defines the upper bounds of the ongoing movements executed by Cruise Control.
+	// +optional
+	Concurrency *CruiseControlExecutorConcurrency `json:"concurrency,omitempty"`
+	// EnableSelfHealingFor is the list of anomaly types for which self-healing is enabled.
+	// +optional
+	EnableSelfHealingFor []CruiseControlAnomalyType `json:"enableSelfHealingFor,omitempty"`
+	// DisableSelfHealingFor is the list of anomaly types for which self-healing is disabled.
+	// +optional
+	DisableSelfHealingFor []CruiseControlAnomalyType `json:"disableSelfHealingFor,omitempty"`
+	// DropRecentlyRemovedBrokers is the list of broker IDs which are dropped from the recently removed brokers of
+	// Cruise Control so that partition replicas can be moved to them again.
+	// +optional
+	DropRecentlyRemovedBrokers []int32 `json:"dropRecentlyRemovedBrokers,omitempty"`
+	// DropRecentlyDemotedBrokers is the list of broker IDs which are dropped from the recently demoted brokers of
+	// Cruise Control so that partition leadership can be moved to them again.
+	// +optional
+	DropRecentlyDemotedBrokers []int32 `json:"dropRecentlyDemotedBrokers,omitempty"`
+}
+
+// CruiseControlExecutorConcurrency defines the upper bounds of ongoing movements of the Cruise Control executor.
+type CruiseControlExecutorConcurrency struct {
+	// InterBrokerPartitionMovements is the upper bound of ongoing replica movements going into/out of each broker.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	InterBrokerPartitionMovements *int32 `json:"interBrokerPartitionMovements,omitempty"`
+	// IntraBrokerPartitionMovements is the upper bound of ongoing replica movements between disks within each broker.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	IntraBrokerPartitionMovements *int32 `json:"intraBrokerPartitionMovements,omitempty"`
+	// LeadershipMovements is the upper bound of ongoing leadership movements.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	LeadershipMovements *int32 `json:"leadershipMovements,omitempty"`
+}
+
+// CruiseControlAdminStatus defines the observed state of CruiseControlAdmin.
+type CruiseControlAdminStatus struct {
+	// ObservedGeneration is the generation of the CruiseControlAdmin which has been applied last.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// State of applying the desired configuration to Cruise Control.
+	// +optional
+	State CruiseControlAdminState `json:"state,omitempty"`
+	// ErrorMessage is the reason of the failure when the desired configuration could not be applied.
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// LastAppliedTime is the time when the configuration has been applied to Cruise Control last.
+	// +optional
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+	// Concurrency is the executor concurrency which has been applied to Cruise Control last.
+	// +optional
+	Concurrency *CruiseControlExecutorConcurrency `json:"concurrency,omitempty"`
+	// SelfHealingEnabled is the list of anomaly types for which self-healing is enabled in Cruise Control.
+	// +optional
+	SelfHealingEnabled []CruiseControlAnomalyType `json:"selfHealingEnabled,omitempty"`
+	// SelfHealingDisabled is the list of anomaly types for which self-healing is disabled in Cruise Control.
+	// +optional
+	SelfHealingDisabled []CruiseControlAnomalyType `json:"selfHealingDisabled,omitempty"`
+	// RecentlyRemovedBrokers is the list of recently removed broker IDs known by Cruise Control.
+	// +optional
+	RecentlyRemovedBrokers []int32 `json:"recentlyRemovedBrokers,omitempty"`
+	// RecentlyDemotedBrokers is the list of recently demoted broker IDs known by Cruise Control.
+	// +optional
+	RecentlyDemotedBrokers []int32 `json:"recentlyDemotedBrokers,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CruiseControlAdmin{}, &CruiseControlAdminList{})
+}
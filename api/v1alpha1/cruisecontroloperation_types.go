@@ -15,9 +15,12 @@
 package v1alpha1
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/banzaicloud/koperator/api/v1beta1"
 )
@@ -27,10 +30,35 @@ const (
 	ErrorPolicyIgnore ErrorPolicyType = "ignore"
 	// ErrorPolicyRetry means Koperator re-executes the failed task in every 30 sec (by default).
 	ErrorPolicyRetry ErrorPolicyType = "retry"
+	// ErrorPolicyRetryN means Koperator re-executes the failed task at most MaxRetries times and then handles the
+	// operation as failed.
+	ErrorPolicyRetryN ErrorPolicyType = "retryN"
+	// ErrorPolicyIgnoreWithEvent means the Koperator handles the failed task as completed and emits a warning event.
+	ErrorPolicyIgnoreWithEvent ErrorPolicyType = "ignoreWithEvent"
+	// ErrorPolicyManualAck means the Koperator waits with the failed task until it is acknowledged with the
+	// AcknowledgeFailureAnnotation.
+	ErrorPolicyManualAck ErrorPolicyType = "manualAck"
+	// DefaultMaxRetries is the number of retries of the failed task with ErrorPolicyRetryN when MaxRetries is not set.
+	DefaultMaxRetries = 3
+	// AcknowledgeFailureAnnotation is used to acknowledge the failed task of the CruiseControlOperation
+	// with ErrorPolicyManualAck. Its value is either AcknowledgeFailureRetry or AcknowledgeFailureIgnore.
+	AcknowledgeFailureAnnotation = "cruisecontroloperation.kafka.banzaicloud.io/acknowledge-failure"
+	// AcknowledgeFailureRetry makes the Koperator re-execute the acknowledged failed task once.
+	AcknowledgeFailureRetry = "retry"
+	// AcknowledgeFailureIgnore makes the Koperator handle the acknowledged failed task as completed.
+	AcknowledgeFailureIgnore = "ignore"
+	// AnomalyTypeLabel is set on the CruiseControlOperations created by the Koperator to remediate an anomaly detected
+	// by Cruise Control, its value is the lowercase type of the anomaly.
+	AnomalyTypeLabel = "cruisecontroloperation.kafka.banzaicloud.io/anomaly-type"
 	// DefaultRetryBackOffDurationSec defines the time between retries of the failed tasks.
 	DefaultRetryBackOffDurationSec = 30
+	// DefaultRetryBackoffMaxDelaySec is the upper bound of the exponential delay between retries when
+	// RetryBackoff does not set it.
+	DefaultRetryBackoffMaxDelaySec = 600
 )
 
+// +kubebuilder:webhook:verbs=create;update,path=/validate-kafka-banzaicloud-io-v1alpha1-cruisecontroloperation,mutating=false,failurePolicy=fail,groups=kafka.banzaicloud.io,resources=cruisecontroloperations;cruisecontroloperations/status,versions=v1alpha1,name=cruisecontroloperations.kafka.banzaicloud.io,sideEffects=None,admissionReviewVersions=v1
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
@@ -54,12 +82,22 @@ type CruiseControlOperationList struct {
 // CruiseControlOperationSpec defines the desired state of CruiseControlOperation.
 type CruiseControlOperationSpec struct {
 	// ErrorPolicy defines how failed Cruise Control operation should be handled.
-	// When it is "retry", the Koperator re-executes the failed task in every 30 sec (by default).
+	// When it is "retry", the Koperator re-executes the failed task in every 30 sec (by default) or with the delays
+	// defined by RetryBackoff.
+	// When it is "retryN", the Koperator re-executes the failed task at most MaxRetries times and then handles
+	// the operation as failed.
 	// When it is "ignore", the Koperator handles the failed task as completed.
-	// +kubebuilder:validation:Enum=ignore;retry
+	// When it is "ignoreWithEvent", the Koperator handles the failed task as completed and emits a warning event.
+	// When it is "manualAck", the Koperator waits until the failed task is acknowledged by setting the
+	// cruisecontroloperation.kafka.banzaicloud.io/acknowledge-failure annotation to "retry" or "ignore".
+	// +kubebuilder:validation:Enum=ignore;retry;retryN;ignoreWithEvent;manualAck
 	// +kubebuilder:default=retry
 	// +optional
 	ErrorPolicy ErrorPolicyType `json:"errorPolicy,omitempty"`
+	// MaxRetries is the number of times the failed task is re-executed with the "retryN" error policy, 3 by default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRetries *int `json:"maxRetries,omitempty"`
 	// When TTLSecondsAfterFinished is specified, the created and finished (completed successfully or completedWithError and errorPolicy: ignore)
 	// cruiseControlOperation custom resource will be deleted after the given time elapsed.
 	// When it is 0 then the resource is going to be deleted instantly after the operation is finished.
@@ -67,6 +105,59 @@ type CruiseControlOperationSpec struct {
 	// Value can be only zero and positive integers
 	// +kubebuilder:validation:Minimum=0
 	TTLSecondsAfterFinished *int `json:"ttlSecondsAfterFinished,omitempty"`
+	// SchedulingGroup groups the CruiseControlOperations of a workflow. Operations within a group are executed in the order
+	// of their creation while the groups are served in turns so a burst of operations in one group cannot starve the others.
+	// Operations without SchedulingGroup belong to the same default group.
+	// +optional
+	SchedulingGroup string `json:"schedulingGroup,omitempty"`
+	// ConcurrencyBoost raises the replica movement concurrency of Cruise Control during the off-peak windows while
+	// the rebalance or remove_broker operation is in progress, and lowers it again during peak hours.
+	// +optional
+	ConcurrencyBoost *ConcurrencyBoost `json:"concurrencyBoost,omitempty"`
+	// RetryBackoff makes the delay between the retries of the failed task grow exponentially. The failed task is
+	// re-executed in every 30 sec when it is not set.
+	// +optional
+	RetryBackoff *RetryBackoff `json:"retryBackoff,omitempty"`
+	// FailedTasksHistory overrides the limits of the failedTasks history set for the Kafka cluster or the operator.
+	// +optional
+	FailedTasksHistory *v1beta1.FailedTasksHistoryLimits `json:"failedTasksHistory,omitempty"`
+}
+
+// RetryBackoff defines the exponential delay before re-executing the failed task. The delay starts from
+// InitialDelaySeconds and it is doubled after every failed retry until it reaches MaxDelaySeconds.
+type RetryBackoff struct {
+	// InitialDelaySeconds is the delay before the first retry, 30 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// MaxDelaySeconds is the upper bound of the delay, 600 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxDelaySeconds *int32 `json:"maxDelaySeconds,omitempty"`
+}
+
+// ConcurrencyBoost defines the executor concurrency of Cruise Control for the peak and off-peak hours.
+type ConcurrencyBoost struct {
+	// OffPeakWindows defines the daily time windows when the OffPeak concurrency is applied.
+	// +kubebuilder:validation:MinItems=1
+	OffPeakWindows []TimeWindow `json:"offPeakWindows"`
+	// TimeZone is the IANA name of the time zone of the windows, UTC is used when it is not specified.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// OffPeak is the executor concurrency applied inside of the off-peak windows.
+	OffPeak CruiseControlExecutorConcurrency `json:"offPeak"`
+	// Peak is the executor concurrency applied outside of the off-peak windows and after the operation has finished.
+	Peak CruiseControlExecutorConcurrency `json:"peak"`
+}
+
+// TimeWindow defines a daily time window. When End is before Start the window spans midnight.
+type TimeWindow struct {
+	// Start of the window in HH:MM format.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// End of the window in HH:MM format.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
 }
 
 // ErrorPolicyType defines methods of handling Cruise Control user task errors.
@@ -78,6 +169,62 @@ type CruiseControlOperationStatus struct {
 	ErrorPolicy ErrorPolicyType     `json:"errorPolicy"`
 	RetryCount  int                 `json:"retryCount"`
 	FailedTasks []CruiseControlTask `json:"failedTasks,omitempty"`
+	// DuplicateOf is the name of the CruiseControlOperation with the same operation type and parameters
+	// which this operation has been coalesced into instead of being executed by Cruise Control.
+	// +optional
+	DuplicateOf string `json:"duplicateOf,omitempty"`
+	// ConcurrencyBoosted is true when the off-peak concurrency of the ConcurrencyBoost is applied to Cruise Control
+	// and false when the peak concurrency is applied.
+	// +optional
+	ConcurrencyBoosted *bool `json:"concurrencyBoosted,omitempty"`
+	// ObservedGeneration is the generation of the CruiseControlOperation which has been processed last.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// ExecutionIntent is recorded right before the task is submitted to Cruise Control and removed when the result of
+	// the submission is saved. When it is found for the upcoming execution, the task may have been submitted without
+	// saving its result, so the submitted task is looked up and adopted instead of being submitted again.
+	// +optional
+	ExecutionIntent *CruiseControlExecutionIntent `json:"executionIntent,omitempty"`
+	// RetryBackoffAttempt is the number of the upcoming retry of the failed task, it is set while the task is
+	// waiting to be re-executed.
+	// +optional
+	RetryBackoffAttempt int `json:"retryBackoffAttempt,omitempty"`
+	// NextRetryTime is the time when the failed task is re-executed at the earliest, it is set while the task is
+	// waiting to be re-executed.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+	// WaitState is set when the CruiseControlOperation waiting for execution is held back by the operator
+	// instead of being submitted to Cruise Control.
+	// +optional
+	WaitState CruiseControlOperationWaitState `json:"waitState,omitempty"`
+	// WaitReason describes why the CruiseControlOperation is held back in the WaitState.
+	// +optional
+	WaitReason string `json:"waitReason,omitempty"`
+}
+
+// CruiseControlOperationWaitState describes why a CruiseControlOperation is not submitted to Cruise Control yet
+type CruiseControlOperationWaitState string
+
+const (
+	// WaitStateWaitingForCCModel means that the Analyzer of Cruise Control cannot generate proposals yet because
+	// it has not collected enough valid metric windows or partitions.
+	WaitStateWaitingForCCModel CruiseControlOperationWaitState = "WaitingForCCModel"
+	// WaitStateWaitingForMinInSyncReplicas means that removing the brokers of the remove_broker operation would push
+	// partitions below their min.insync.replicas.
+	WaitStateWaitingForMinInSyncReplicas CruiseControlOperationWaitState = "WaitingForMinInSyncReplicas"
+)
+
+// CruiseControlExecutionIntent describes the submission of the task of the CruiseControlOperation in progress
+type CruiseControlExecutionIntent struct {
+	// OperationUID is the UID of the CruiseControlOperation the intent has been recorded for
+	OperationUID types.UID `json:"operationUid"`
+	// Attempt is the number of the execution of the CruiseControlOperation
+	Attempt int `json:"attempt"`
+	// SubmittedBy is the host name of the operator replica submitting the task when leader election is enabled
+	// +optional
+	SubmittedBy string `json:"submittedBy,omitempty"`
+	// Recorded is the time when the intent has been recorded
+	Recorded metav1.Time `json:"recorded"`
 }
 
 // CruiseControlTask defines the observed state of the Cruise Control user task.
@@ -87,22 +234,272 @@ type CruiseControlTask struct {
 	Finished *metav1.Time `json:"finished,omitempty"`
 	// Operation defines the Cruise Control operation kind.
 	Operation CruiseControlTaskOperation `json:"operation"`
-	// Parameters defines the configuration of the operation.
+	// Parameters defines the raw configuration of the operation using the Cruise Control REST API parameter names.
+	// It can be used to pass parameters which are not covered by TypedParameters.
 	Parameters map[string]string `json:"parameters,omitempty"`
-	// HTTPRequest is a Cruise Control user task HTTP request.
+	// TypedParameters defines the configuration of the operation. When a parameter is set both
+	// in Parameters and in TypedParameters, the value from TypedParameters is used.
+	// +optional
+	TypedParameters *CruiseControlTaskParameters `json:"typedParameters,omitempty"`
+	// HTTPRequest is a Cruise Control user task HTTP request, the credentials and the sensitive parameters are redacted.
 	HTTPRequest      string `json:"httpRequest,omitempty"`
 	HTTPResponseCode *int   `json:"httpResponseCode,omitempty"`
 	// Summary of the Cruise Control user task execution proposal.
 	Summary map[string]string `json:"summary,omitempty"`
+	// ResultConfigMap is the name of the ConfigMap which holds the full, gzip compressed optimization result
+	// of the Cruise Control user task.
+	// +optional
+	ResultConfigMap string `json:"resultConfigMap,omitempty"`
 	// State is the current state of the Cruise Control user task.
 	State        v1beta1.CruiseControlUserTaskState `json:"state,omitempty"`
 	ErrorMessage string                             `json:"errorMessage,omitempty"`
+	// ErrorReason is the cause of the failure of the Cruise Control request, e.g. InvalidParameter or ServerError.
+	// +optional
+	ErrorReason string `json:"errorReason,omitempty"`
+	// TerminalError is true when the Cruise Control request failed with an error which would occur again on
+	// re-execution, thus the task is not retried.
+	// +optional
+	TerminalError bool `json:"terminalError,omitempty"`
+	// PartitionMovements describes the inter-broker partition movements of the task as reported by the executor of
+	// Cruise Control when the task has been observed in execution last.
+	// +optional
+	PartitionMovements *CruiseControlTaskPartitionMovements `json:"partitionMovements,omitempty"`
+	// Simulation is the projected outcome of the dry run of a remove_broker task computed from the proposals of
+	// Cruise Control, it lets the scale-down of the cluster be validated before it is executed.
+	// +optional
+	Simulation *ScaleDownSimulation `json:"simulation,omitempty"`
+}
+
+// ScaleDownSimulation describes the replica movements and the load of the remaining brokers projected by the dry run
+// of a remove_broker task
+type ScaleDownSimulation struct {
+	// ReplicaMovements is the total number of the replicas which would be moved off from the removed brokers
+	ReplicaMovements int32 `json:"replicaMovements"`
+	// DataToMoveMB is the amount of data which would be moved
+	DataToMoveMB int64 `json:"dataToMoveMB"`
+	// Topics are the replica movements of the topics ordered by name
+	// +optional
+	Topics []TopicReplicaMovements `json:"topics,omitempty"`
+	// OmittedTopics is the number of topics left out from Topics to keep the size of the status bounded
+	// +optional
+	OmittedTopics int32 `json:"omittedTopics,omitempty"`
+	// Brokers are the projected loads of the remaining brokers ordered by broker ID
+	// +optional
+	Brokers []BrokerProjectedLoad `json:"brokers,omitempty"`
+}
+
+// TopicReplicaMovements describes the replicas of a topic which would be moved by a Cruise Control task
+type TopicReplicaMovements struct {
+	Topic string `json:"topic"`
+	// ReplicaMovements is the number of the replicas of the topic which would be moved
+	ReplicaMovements int32 `json:"replicaMovements"`
+	// TargetBrokers are the brokers which would receive the replicas ordered by broker ID
+	// +optional
+	TargetBrokers []int32 `json:"targetBrokers,omitempty"`
+}
+
+// BrokerProjectedLoad describes the load of a broker after the execution of a Cruise Control task as projected by
+// Cruise Control
+type BrokerProjectedLoad struct {
+	BrokerID int32 `json:"brokerId"`
+	Replicas int32 `json:"replicas"`
+	Leaders  int32 `json:"leaders"`
+	// DiskMB is the disk space used by the replicas of the broker
+	DiskMB int64 `json:"diskMB"`
+	// DiskCapacityMB is the disk capacity of the broker known by Cruise Control
+	DiskCapacityMB int64 `json:"diskCapacityMB"`
+	// DiskUsagePercent is the disk utilization of the broker rounded up to the nearest integer
+	DiskUsagePercent int32 `json:"diskUsagePercent"`
+}
+
+// CruiseControlTaskPartitionMovements describes the progress of the inter-broker partition movements of the
+// Cruise Control user task. The movements in progress are listed only while the task is being executed.
+type CruiseControlTaskPartitionMovements struct {
+	// Total is the number of the partition movements of the execution
+	Total int32 `json:"total"`
+	// Pending is the number of the partition movements which have not been started yet
+	Pending int32 `json:"pending"`
+	// Finished is the number of the finished partition movements
+	Finished int32 `json:"finished"`
+	// InProgressTopics is the number of the partitions being moved keyed by the name of their topic
+	// +optional
+	InProgressTopics map[string]int32 `json:"inProgressTopics,omitempty"`
+	// InProgress lists the partition movements in progress, the list is truncated to the first 50 movements
+	// +optional
+	InProgress []PartitionMovement `json:"inProgress,omitempty"`
+}
+
+// PartitionMovement describes the reassignment of a partition between brokers
+type PartitionMovement struct {
+	Topic       string  `json:"topic"`
+	Partition   int32   `json:"partition"`
+	OldReplicas []int32 `json:"oldReplicas,omitempty"`
+	NewReplicas []int32 `json:"newReplicas,omitempty"`
+}
+
+// CruiseControlTaskParameters defines the typed configuration of a Cruise Control user task.
+// More details: https://github.com/linkedin/cruise-control/wiki/REST-APIs#post-requests
+type CruiseControlTaskParameters struct {
+	// BrokerIDs is the list of brokers to be added or removed. Used by the add_broker and remove_broker operations.
+	// +optional
+	BrokerIDs []int32 `json:"brokerIds,omitempty"`
+	// DestinationBrokerIDs is the list of brokers which the partition replicas can be moved to.
+	// Used by the rebalance and remove_broker operations.
+	// +optional
+	DestinationBrokerIDs []int32 `json:"destinationBrokerIds,omitempty"`
+	// ExcludedTopics is a regular expression matching the topics whose partition replicas are not moved.
+	// +optional
+	ExcludedTopics string `json:"excludedTopics,omitempty"`
+	// Goals is the list of Cruise Control goals to use for the optimization (e.g. RackAwareGoal).
+	// When it is not specified the default goals of Cruise Control are used.
+	// +optional
+	Goals []string `json:"goals,omitempty"`
+	// ConcurrentPartitionMovementsPerBroker is the upper bound of ongoing replica movements going into or out of each broker.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ConcurrentPartitionMovementsPerBroker *int32 `json:"concurrentPartitionMovementsPerBroker,omitempty"`
+	// ConcurrentLeaderMovements is the upper bound of ongoing leadership movements.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ConcurrentLeaderMovements *int32 `json:"concurrentLeaderMovements,omitempty"`
+	// DryRun makes Cruise Control only calculate the proposal of the operation without executing it.
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty"`
+	// RebalanceDisk makes Cruise Control balance the load between the disks of the brokers instead of between the brokers.
+	// Used by the rebalance operation.
+	// +optional
+	RebalanceDisk *bool `json:"rebalanceDisk,omitempty"`
+	// ExcludeRecentlyDemotedBrokers excludes the recently demoted brokers from receiving leadership.
+	// +optional
+	ExcludeRecentlyDemotedBrokers *bool `json:"excludeRecentlyDemotedBrokers,omitempty"`
+	// ExcludeRecentlyRemovedBrokers excludes the recently removed brokers from receiving partition replicas.
+	// +optional
+	ExcludeRecentlyRemovedBrokers *bool `json:"excludeRecentlyRemovedBrokers,omitempty"`
+	// SkipHardGoalCheck allows Cruise Control to use goals which do not include all the configured hard goals.
+	// +optional
+	SkipHardGoalCheck *bool `json:"skipHardGoalCheck,omitempty"`
+}
+
+// ToParameters converts the typed parameters to Cruise Control REST API parameters.
+func (p *CruiseControlTaskParameters) ToParameters() map[string]string {
+	if p == nil {
+		return nil
+	}
+	params := make(map[string]string)
+	if len(p.BrokerIDs) > 0 {
+		params[ParamBrokerID] = joinBrokerIDs(p.BrokerIDs)
+	}
+	if len(p.DestinationBrokerIDs) > 0 {
+		params[ParamDestbrokerIDs] = joinBrokerIDs(p.DestinationBrokerIDs)
+	}
+	if p.ExcludedTopics != "" {
+		params[ParamExcludedTopics] = p.ExcludedTopics
+	}
+	if len(p.Goals) > 0 {
+		params[ParamGoals] = strings.Join(p.Goals, ",")
+	}
+	if p.ConcurrentPartitionMovementsPerBroker != nil {
+		params[ParamConcurrentPartitionMovements] = strconv.Itoa(int(*p.ConcurrentPartitionMovementsPerBroker))
+	}
+	if p.ConcurrentLeaderMovements != nil {
+		params[ParamConcurrentLeaderMovements] = strconv.Itoa(int(*p.ConcurrentLeaderMovements))
+	}
+	if p.DryRun != nil {
+		params[ParamDryRun] = strconv.FormatBool(*p.DryRun)
+	}
+	if p.RebalanceDisk != nil {
+		params[ParamRebalanceDisk] = strconv.FormatBool(*p.RebalanceDisk)
+	}
+	if p.ExcludeRecentlyDemotedBrokers != nil {
+		params[ParamExcludeDemoted] = strconv.FormatBool(*p.ExcludeRecentlyDemotedBrokers)
+	}
+	if p.ExcludeRecentlyRemovedBrokers != nil {
+		params[ParamExcludeRemoved] = strconv.FormatBool(*p.ExcludeRecentlyRemovedBrokers)
+	}
+	if p.SkipHardGoalCheck != nil {
+		params[ParamSkipHardGoalCheck] = strconv.FormatBool(*p.SkipHardGoalCheck)
+	}
+	return params
+}
+
+func joinBrokerIDs(brokerIDs []int32) string {
+	ids := make([]string, 0, len(brokerIDs))
+	for _, id := range brokerIDs {
+		ids = append(ids, strconv.Itoa(int(id)))
+	}
+	return strings.Join(ids, ",")
 }
 
 func init() {
 	SchemeBuilder.Register(&CruiseControlOperation{}, &CruiseControlOperationList{})
 }
 
+// GetParameters returns the Cruise Control REST API parameters of the task by merging
+// the raw Parameters with the TypedParameters where the latter takes precedence.
+func (task *CruiseControlTask) GetParameters() map[string]string {
+	if task == nil {
+		return nil
+	}
+	if task.TypedParameters == nil {
+		return task.Parameters
+	}
+	params := make(map[string]string, len(task.Parameters))
+	for key, value := range task.Parameters {
+		params[key] = value
+	}
+	for key, value := range task.TypedParameters.ToParameters() {
+		params[key] = value
+	}
+	return params
+}
+
+// LastStarted returns the time when the last task of the operation has been started or nil when it has never been executed.
+func (o *CruiseControlOperation) LastStarted() *metav1.Time {
+	var lastStarted *metav1.Time
+	if task := o.CurrentTask(); task != nil && task.Started != nil {
+		lastStarted = task.Started
+	}
+	for i := range o.Status.FailedTasks {
+		started := o.Status.FailedTasks[i].Started
+		if started != nil && (lastStarted == nil || lastStarted.Before(started)) {
+			lastStarted = started
+		}
+	}
+	return lastStarted
+}
+
+// IsOffPeak returns true when the given time is inside one of the off-peak windows.
+func (b *ConcurrencyBoost) IsOffPeak(t time.Time) (bool, error) {
+	location := time.UTC
+	if b.TimeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(b.TimeZone); err != nil {
+			return false, err
+		}
+	}
+	t = t.In(location)
+	minuteOfDay := t.Hour()*60 + t.Minute()
+
+	for _, window := range b.OffPeakWindows {
+		start, err := v1beta1.ParseMinuteOfDay(window.Start)
+		if err != nil {
+			return false, err
+		}
+		end, err := v1beta1.ParseMinuteOfDay(window.End)
+		if err != nil {
+			return false, err
+		}
+		if start <= end && minuteOfDay >= start && minuteOfDay < end {
+			return true, nil
+		}
+		// The window spans midnight
+		if start > end && (minuteOfDay >= start || minuteOfDay < end) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // GetTTLSecondsAfterFinished returns Spec.TTLSecondsAfterFinished
 func (c CruiseControlOperation) GetTTLSecondsAfterFinished() *int {
 	return c.Spec.TTLSecondsAfterFinished
@@ -117,6 +514,10 @@ func (task *CruiseControlTask) SetDefaults() {
 	task.HTTPResponseCode = nil
 	task.ID = ""
 	task.Summary = nil
+	task.Simulation = nil
+	task.ResultConfigMap = ""
+	task.ErrorReason = ""
+	task.TerminalError = false
 }
 
 func (o *CruiseControlOperation) CurrentTask() *CruiseControlTask {
@@ -130,7 +531,7 @@ func (o *CruiseControlOperation) CurrentTaskParameters() map[string]string {
 	if o.CurrentTask() == nil {
 		return nil
 	}
-	return o.CurrentTask().Parameters
+	return o.CurrentTask().GetParameters()
 }
 
 func (o *CruiseControlOperation) GetClusterRef() string {
@@ -180,23 +581,72 @@ func (o *CruiseControlOperation) IsInProgress() bool {
 }
 
 func (o *CruiseControlOperation) IsDone() bool {
-	return (o.IsPaused() && o.CurrentTaskState() == v1beta1.CruiseControlTaskCompletedWithError) || o.IsFinished()
+	return (o.IsPaused() && o.CurrentTaskState() == v1beta1.CruiseControlTaskCompletedWithError) || o.IsFinished() ||
+		o.IsFailed() || o.IsWaitingForAcknowledgement()
 }
 
 func (o *CruiseControlOperation) IsPaused() bool {
 	return o.GetLabels()["pause"] == "true"
 }
 
+// IsErrorPolicyIgnore returns true when the failed task of the CruiseControlOperation is handled as completed
 func (o *CruiseControlOperation) IsErrorPolicyIgnore() bool {
-	return o.Spec.ErrorPolicy == ErrorPolicyIgnore
+	switch o.Spec.ErrorPolicy {
+	case ErrorPolicyIgnore, ErrorPolicyIgnoreWithEvent:
+		return true
+	case ErrorPolicyManualAck:
+		return o.acknowledgedFailure() == AcknowledgeFailureIgnore
+	}
+	return false
 }
 
 func (o *CruiseControlOperation) IsFinished() bool {
-	return o.CurrentTaskState() == v1beta1.CruiseControlTaskCompleted || (o.Spec.ErrorPolicy == ErrorPolicyIgnore && o.CurrentTaskState() == v1beta1.CruiseControlTaskCompletedWithError)
+	return o.CurrentTaskState() == v1beta1.CruiseControlTaskCompleted || (o.IsErrorPolicyIgnore() && o.CurrentTaskState() == v1beta1.CruiseControlTaskCompletedWithError)
 }
 
+// IsErrorPolicyRetry returns true when the failed task of the CruiseControlOperation needs to be re-executed
 func (o *CruiseControlOperation) IsErrorPolicyRetry() bool {
-	return o.Spec.ErrorPolicy == ErrorPolicyRetry
+	switch o.Spec.ErrorPolicy {
+	case ErrorPolicyRetry:
+		return !o.HasTerminalError()
+	case ErrorPolicyRetryN:
+		return !o.HasTerminalError() && o.Status.RetryCount < o.GetMaxRetries()
+	case ErrorPolicyManualAck:
+		return o.acknowledgedFailure() == AcknowledgeFailureRetry
+	}
+	return false
+}
+
+// GetMaxRetries returns the number of times the failed task is re-executed with the "retryN" error policy
+func (o *CruiseControlOperation) GetMaxRetries() int {
+	if o.Spec.MaxRetries != nil {
+		return *o.Spec.MaxRetries
+	}
+	return DefaultMaxRetries
+}
+
+func (o *CruiseControlOperation) acknowledgedFailure() string {
+	return o.GetAnnotations()[AcknowledgeFailureAnnotation]
+}
+
+// IsWaitingForAcknowledgement returns true when the failed task of the CruiseControlOperation with the "manualAck"
+// error policy has not been acknowledged yet
+func (o *CruiseControlOperation) IsWaitingForAcknowledgement() bool {
+	return o.Spec.ErrorPolicy == ErrorPolicyManualAck && o.CurrentTaskState() == v1beta1.CruiseControlTaskCompletedWithError &&
+		o.acknowledgedFailure() != AcknowledgeFailureRetry && o.acknowledgedFailure() != AcknowledgeFailureIgnore
+}
+
+// HasTerminalError returns true when the current task failed with an error which would occur again on re-execution
+func (o *CruiseControlOperation) HasTerminalError() bool {
+	return o.CurrentTask() != nil && o.CurrentTask().TerminalError
+}
+
+// IsFailed returns true when the failed task of the CruiseControlOperation is not going to be re-executed either
+// because it failed with a terminal error or the retries of the "retryN" error policy have been used up.
+// The failed task of the "manualAck" error policy can still be re-executed after acknowledging it.
+func (o *CruiseControlOperation) IsFailed() bool {
+	return (o.Spec.ErrorPolicy == ErrorPolicyRetry || o.Spec.ErrorPolicy == ErrorPolicyRetryN) &&
+		o.CurrentTaskState() == v1beta1.CruiseControlTaskCompletedWithError && !o.IsErrorPolicyRetry()
 }
 
 func (o *CruiseControlOperation) IsWaitingForRetryExecution() bool {
@@ -208,7 +658,45 @@ func (o *CruiseControlOperation) IsWaitingForRetryExecution() bool {
 }
 
 func (o *CruiseControlOperation) IsReadyForRetryExecution() bool {
-	return o.IsWaitingForRetryExecution() && o.CurrentTaskFinished() != nil && o.CurrentTaskFinished().Add(time.Second*DefaultRetryBackOffDurationSec).Before(time.Now())
+	next := o.NextRetryTime()
+	return next != nil && next.Before(time.Now())
+}
+
+// RetryBackoffAttempt returns the number of the upcoming retry of the failed task
+func (o *CruiseControlOperation) RetryBackoffAttempt() int {
+	return o.Status.RetryCount + 1
+}
+
+// RetryBackoffDelay returns the delay before the given retry of the failed task
+func (o *CruiseControlOperation) RetryBackoffDelay(attempt int) time.Duration {
+	backoff := o.Spec.RetryBackoff
+	if backoff == nil {
+		return time.Second * DefaultRetryBackOffDurationSec
+	}
+	delay, maxDelay := time.Second*DefaultRetryBackOffDurationSec, time.Second*DefaultRetryBackoffMaxDelaySec
+	if backoff.InitialDelaySeconds != nil {
+		delay = time.Second * time.Duration(*backoff.InitialDelaySeconds)
+	}
+	if backoff.MaxDelaySeconds != nil {
+		maxDelay = time.Second * time.Duration(*backoff.MaxDelaySeconds)
+	}
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
+
+// NextRetryTime returns the time when the failed task is re-executed at the earliest, or nil when it is not waiting
+// for retry
+func (o *CruiseControlOperation) NextRetryTime() *time.Time {
+	if !o.IsWaitingForRetryExecution() || o.CurrentTaskFinished() == nil {
+		return nil
+	}
+	next := o.CurrentTaskFinished().Add(o.RetryBackoffDelay(o.RetryBackoffAttempt()))
+	return &next
 }
 
 func (o *CruiseControlOperation) IsCurrentTaskRunning() bool {
@@ -219,7 +707,31 @@ func (o *CruiseControlOperation) IsCurrentTaskFinished() bool {
 	return o.CurrentTaskState() == v1beta1.CruiseControlTaskCompleted || o.CurrentTaskState() == v1beta1.CruiseControlTaskCompletedWithError
 }
 
+// IsDuplicateOf returns true when the other CruiseControlOperation is meant to execute the same
+// operation type with the same parameters on the same Kafka cluster.
+func (o *CruiseControlOperation) IsDuplicateOf(other *CruiseControlOperation) bool {
+	if o == nil || other == nil || (o.GetName() == other.GetName() && o.GetNamespace() == other.GetNamespace()) {
+		return false
+	}
+	if o.GetNamespace() != other.GetNamespace() || o.GetClusterRef() != other.GetClusterRef() ||
+		o.CurrentTaskOperation() != other.CurrentTaskOperation() {
+		return false
+	}
+	params, otherParams := o.CurrentTaskParameters(), other.CurrentTaskParameters()
+	if len(params) != len(otherParams) {
+		return false
+	}
+	for key, value := range params {
+		if otherValue, ok := otherParams[key]; !ok || otherValue != value {
+			return false
+		}
+	}
+	return true
+}
+
 func (o *CruiseControlOperation) IsCurrentTaskOperationValid() bool {
 	return o.CurrentTaskOperation() == OperationAddBroker ||
-		o.CurrentTaskOperation() == OperationRebalance || o.CurrentTaskOperation() == OperationRemoveBroker || o.CurrentTaskOperation() == OperationStopExecution
+		o.CurrentTaskOperation() == OperationRebalance || o.CurrentTaskOperation() == OperationRemoveBroker ||
+		o.CurrentTaskOperation() == OperationStopExecution || o.CurrentTaskOperation() == OperationPreferredLeaderElection ||
+		o.CurrentTaskOperation() == OperationDemoteBroker || o.CurrentTaskOperation() == OperationFixOfflineReplicas
 }
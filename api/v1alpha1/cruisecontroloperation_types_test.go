@@ -0,0 +1,281 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestCruiseControlTaskGetParameters(t *testing.T) {
+	trueValue := true
+	falseValue := false
+	concurrency := int32(5)
+
+	testCases := []struct {
+		testName       string
+		task           *CruiseControlTask
+		expectedParams map[string]string
+	}{
+		{
+			testName: "nil task",
+		},
+		{
+			testName: "raw parameters only",
+			task: &CruiseControlTask{
+				Parameters: map[string]string{ParamBrokerID: "1,2", "replication_throttle": "1000"},
+			},
+			expectedParams: map[string]string{ParamBrokerID: "1,2", "replication_throttle": "1000"},
+		},
+		{
+			testName: "typed parameters only",
+			task: &CruiseControlTask{
+				TypedParameters: &CruiseControlTaskParameters{
+					DestinationBrokerIDs:                  []int32{1, 2, 3},
+					ExcludedTopics:                        "^__.*",
+					Goals:                                 []string{"RackAwareGoal", "DiskCapacityGoal"},
+					ConcurrentPartitionMovementsPerBroker: &concurrency,
+					RebalanceDisk:                         &trueValue,
+					DryRun:                                &falseValue,
+				},
+			},
+			expectedParams: map[string]string{
+				ParamDestbrokerIDs:                "1,2,3",
+				ParamExcludedTopics:               "^__.*",
+				ParamGoals:                        "RackAwareGoal,DiskCapacityGoal",
+				ParamConcurrentPartitionMovements: "5",
+				ParamRebalanceDisk:                "true",
+				ParamDryRun:                       "false",
+			},
+		},
+		{
+			testName: "typed parameters take precedence over raw parameters",
+			task: &CruiseControlTask{
+				Parameters: map[string]string{ParamBrokerID: "1", "replication_throttle": "1000"},
+				TypedParameters: &CruiseControlTaskParameters{
+					BrokerIDs:                     []int32{4, 5},
+					ExcludeRecentlyRemovedBrokers: &trueValue,
+				},
+			},
+			expectedParams: map[string]string{
+				ParamBrokerID:          "4,5",
+				ParamExcludeRemoved:    "true",
+				"replication_throttle": "1000",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			assert.DeepEqual(t, testCase.task.GetParameters(), testCase.expectedParams)
+		})
+	}
+}
+
+func TestConcurrencyBoostIsOffPeak(t *testing.T) {
+	boost := &ConcurrencyBoost{
+		OffPeakWindows: []TimeWindow{
+			{Start: "22:00", End: "06:00"},
+			{Start: "12:00", End: "13:30"},
+		},
+	}
+
+	testCases := []struct {
+		time     time.Time
+		expected bool
+	}{
+		{time: time.Date(2023, 1, 1, 23, 0, 0, 0, time.UTC), expected: true},
+		{time: time.Date(2023, 1, 1, 5, 59, 0, 0, time.UTC), expected: true},
+		{time: time.Date(2023, 1, 1, 6, 0, 0, 0, time.UTC), expected: false},
+		{time: time.Date(2023, 1, 1, 13, 0, 0, 0, time.UTC), expected: true},
+		{time: time.Date(2023, 1, 1, 13, 30, 0, 0, time.UTC), expected: false},
+		{time: time.Date(2023, 1, 1, 21, 59, 0, 0, time.UTC), expected: false},
+	}
+
+	for _, testCase := range testCases {
+		offPeak, err := boost.IsOffPeak(testCase.time)
+		assert.NilError(t, err)
+		assert.Equal(t, testCase.expected, offPeak, testCase.time.String())
+	}
+
+	boost.TimeZone = "Invalid/TimeZone"
+	_, err := boost.IsOffPeak(time.Now())
+	assert.Assert(t, err != nil)
+}
+
+func TestErrorPolicies(t *testing.T) {
+	maxRetries := 2
+	newFailedOperation := func(errorPolicy ErrorPolicyType, retryCount int, annotations map[string]string) *CruiseControlOperation {
+		return &CruiseControlOperation{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+			Spec:       CruiseControlOperationSpec{ErrorPolicy: errorPolicy, MaxRetries: &maxRetries},
+			Status: CruiseControlOperationStatus{
+				RetryCount: retryCount,
+				CurrentTask: &CruiseControlTask{
+					ID:        "task",
+					Operation: OperationRebalance,
+					State:     v1beta1.CruiseControlTaskCompletedWithError,
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		testName              string
+		operation             *CruiseControlOperation
+		expectedRetry         bool
+		expectedFinished      bool
+		expectedDone          bool
+		expectedWaitingForAck bool
+		expectedFailed        bool
+	}{
+		{
+			testName:      "retry",
+			operation:     newFailedOperation(ErrorPolicyRetry, 10, nil),
+			expectedRetry: true,
+		},
+		{
+			testName:         "ignore with event",
+			operation:        newFailedOperation(ErrorPolicyIgnoreWithEvent, 0, nil),
+			expectedFinished: true,
+			expectedDone:     true,
+		},
+		{
+			testName:      "retryN with retries left",
+			operation:     newFailedOperation(ErrorPolicyRetryN, 1, nil),
+			expectedRetry: true,
+		},
+		{
+			testName: "retry with terminal error",
+			operation: func() *CruiseControlOperation {
+				operation := newFailedOperation(ErrorPolicyRetry, 0, nil)
+				operation.Status.CurrentTask.TerminalError = true
+				return operation
+			}(),
+			expectedDone:   true,
+			expectedFailed: true,
+		},
+		{
+			testName:       "retryN with retries used up",
+			operation:      newFailedOperation(ErrorPolicyRetryN, 2, nil),
+			expectedDone:   true,
+			expectedFailed: true,
+		},
+		{
+			testName:              "manualAck not acknowledged",
+			operation:             newFailedOperation(ErrorPolicyManualAck, 0, nil),
+			expectedDone:          true,
+			expectedWaitingForAck: true,
+		},
+		{
+			testName:      "manualAck acknowledged for retry",
+			operation:     newFailedOperation(ErrorPolicyManualAck, 0, map[string]string{AcknowledgeFailureAnnotation: AcknowledgeFailureRetry}),
+			expectedRetry: true,
+		},
+		{
+			testName:         "manualAck acknowledged for ignore",
+			operation:        newFailedOperation(ErrorPolicyManualAck, 0, map[string]string{AcknowledgeFailureAnnotation: AcknowledgeFailureIgnore}),
+			expectedFinished: true,
+			expectedDone:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedRetry, testCase.operation.IsWaitingForRetryExecution())
+			assert.Equal(t, testCase.expectedFinished, testCase.operation.IsFinished())
+			assert.Equal(t, testCase.expectedDone, testCase.operation.IsDone())
+			assert.Equal(t, testCase.expectedWaitingForAck, testCase.operation.IsWaitingForAcknowledgement())
+			assert.Equal(t, testCase.expectedFailed, testCase.operation.IsFailed())
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	initialDelay, maxDelay := int32(10), int32(60)
+	finished := metav1.Time{Time: time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)}
+	newFailedOperation := func(retryBackoff *RetryBackoff, retryCount int) *CruiseControlOperation {
+		return &CruiseControlOperation{
+			Spec: CruiseControlOperationSpec{ErrorPolicy: ErrorPolicyRetry, RetryBackoff: retryBackoff},
+			Status: CruiseControlOperationStatus{
+				RetryCount: retryCount,
+				CurrentTask: &CruiseControlTask{
+					ID:        "task",
+					Operation: OperationRebalance,
+					State:     v1beta1.CruiseControlTaskCompletedWithError,
+					Finished:  &finished,
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		testName          string
+		operation         *CruiseControlOperation
+		expectedAttempt   int
+		expectedNextRetry time.Duration
+	}{
+		{
+			testName:          "default constant delay",
+			operation:         newFailedOperation(nil, 5),
+			expectedAttempt:   6,
+			expectedNextRetry: 30 * time.Second,
+		},
+		{
+			testName:          "first retry",
+			operation:         newFailedOperation(&RetryBackoff{InitialDelaySeconds: &initialDelay, MaxDelaySeconds: &maxDelay}, 0),
+			expectedAttempt:   1,
+			expectedNextRetry: 10 * time.Second,
+		},
+		{
+			testName:          "third retry",
+			operation:         newFailedOperation(&RetryBackoff{InitialDelaySeconds: &initialDelay, MaxDelaySeconds: &maxDelay}, 2),
+			expectedAttempt:   3,
+			expectedNextRetry: 40 * time.Second,
+		},
+		{
+			testName:          "delay capped",
+			operation:         newFailedOperation(&RetryBackoff{InitialDelaySeconds: &initialDelay, MaxDelaySeconds: &maxDelay}, 100),
+			expectedAttempt:   101,
+			expectedNextRetry: 60 * time.Second,
+		},
+		{
+			testName:          "default initial and max delays",
+			operation:         newFailedOperation(&RetryBackoff{}, 10),
+			expectedAttempt:   11,
+			expectedNextRetry: DefaultRetryBackoffMaxDelaySec * time.Second,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedAttempt, testCase.operation.RetryBackoffAttempt())
+			next := testCase.operation.NextRetryTime()
+			assert.Assert(t, next != nil)
+			assert.Equal(t, testCase.expectedNextRetry, next.Sub(finished.Time))
+			assert.Assert(t, testCase.operation.IsReadyForRetryExecution())
+		})
+	}
+
+	completed := newFailedOperation(nil, 0)
+	completed.Status.CurrentTask.State = v1beta1.CruiseControlTaskCompleted
+	assert.Assert(t, completed.NextRetryTime() == nil)
+	assert.Assert(t, !completed.IsReadyForRetryExecution())
+}
@@ -0,0 +1,116 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DecommissionPhasePending means that the decommission has not been started yet.
+	DecommissionPhasePending KafkaBrokerDecommissionPhase = "Pending"
+	// DecommissionPhaseDemoting means that the partition leaderships are being moved off from the broker.
+	DecommissionPhaseDemoting KafkaBrokerDecommissionPhase = "Demoting"
+	// DecommissionPhaseRemoving means that the partition replicas are being moved off from the broker by Cruise Control.
+	DecommissionPhaseRemoving KafkaBrokerDecommissionPhase = "Removing"
+	// DecommissionPhaseVerifying means that the partition metadata is being checked for replicas left on the broker.
+	DecommissionPhaseVerifying KafkaBrokerDecommissionPhase = "Verifying"
+	// DecommissionPhaseCleaningUp means that the pod, PersistentVolumeClaims and status of the broker are being removed.
+	DecommissionPhaseCleaningUp KafkaBrokerDecommissionPhase = "CleaningUp"
+	// DecommissionPhaseCompleted means that the broker has been removed and its ID has been retired.
+	DecommissionPhaseCompleted KafkaBrokerDecommissionPhase = "Completed"
+	// DecommissionPhaseFailed means that the decommission cannot be continued.
+	DecommissionPhaseFailed KafkaBrokerDecommissionPhase = "Failed"
+
+	// DecommissionConditionDemoted is true once the partition leaderships have been moved off from the broker.
+	DecommissionConditionDemoted = "Demoted"
+	// DecommissionConditionRemoved is true once Cruise Control has moved the partition replicas off from the broker.
+	DecommissionConditionRemoved = "Removed"
+	// DecommissionConditionVerified is true once the partition metadata reports no replica on the broker.
+	DecommissionConditionVerified = "Verified"
+	// DecommissionConditionCleanedUp is true once the pod, PersistentVolumeClaims and status of the broker are removed.
+	DecommissionConditionCleanedUp = "CleanedUp"
+	// DecommissionConditionRetired is true once the ID of the broker has been retired in the KafkaCluster status.
+	DecommissionConditionRetired = "Retired"
+)
+
+// KafkaBrokerDecommissionPhase defines the step of the broker decommission state machine.
+type KafkaBrokerDecommissionPhase string
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=kbd
+//+kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterRef.name"
+//+kubebuilder:printcolumn:name="Broker",type="integer",JSONPath=".spec.brokerId"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KafkaBrokerDecommission is the Schema for the kafkaBrokerDecommissions API.
+// It removes a broker from the Kafka cluster safely: the broker is demoted, its partition replicas are moved off by
+// Cruise Control, the result is verified, then its resources are cleaned up and its ID is retired.
+type KafkaBrokerDecommission struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaBrokerDecommissionSpec   `json:"spec,omitempty"`
+	Status KafkaBrokerDecommissionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// KafkaBrokerDecommissionList contains a list of KafkaBrokerDecommission.
+type KafkaBrokerDecommissionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaBrokerDecommission `json:"items"`
+}
+
+// KafkaBrokerDecommissionSpec defines the broker to be decommissioned.
+type KafkaBrokerDecommissionSpec struct {
+	ClusterRef ClusterReference `json:"clusterRef"`
+	// BrokerID is the ID of the broker to be decommissioned.
+	// +kubebuilder:validation:Minimum=0
+	BrokerID int32 `json:"brokerId"`
+	// SkipDemotion skips moving the partition leaderships off from the broker before its replicas are moved.
+	// +optional
+	SkipDemotion bool `json:"skipDemotion,omitempty"`
+}
+
+// KafkaBrokerDecommissionStatus defines the observed state of KafkaBrokerDecommission.
+type KafkaBrokerDecommissionStatus struct {
+	// Phase is the current step of the decommission.
+	// +optional
+	Phase KafkaBrokerDecommissionPhase `json:"phase,omitempty"`
+	// Conditions of the steps of the decommission.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// DemoteOperation is the name of the CruiseControlOperation which demotes the broker.
+	// +optional
+	DemoteOperation string `json:"demoteOperation,omitempty"`
+	// ErrorMessage is the reason of the failure when the decommission cannot be continued.
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// CompletionTime is the time when the decommission has been completed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// IsDone returns true when the decommission has either been completed or failed.
+func (d *KafkaBrokerDecommission) IsDone() bool {
+	return d.Status.Phase == DecommissionPhaseCompleted || d.Status.Phase == DecommissionPhaseFailed
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaBrokerDecommission{}, &KafkaBrokerDecommissionList{})
+}
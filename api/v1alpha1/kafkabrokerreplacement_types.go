@@ -0,0 +1,127 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// ReplacementPhasePending means that the replacement has not been started yet.
+	ReplacementPhasePending KafkaBrokerReplacementPhase = "Pending"
+	// ReplacementPhaseDemoting means that the partition leaderships are being moved off from the broker.
+	ReplacementPhaseDemoting KafkaBrokerReplacementPhase = "Demoting"
+	// ReplacementPhaseEvicting means that the pod of the broker is being evicted.
+	ReplacementPhaseEvicting KafkaBrokerReplacementPhase = "Evicting"
+	// ReplacementPhaseRescheduling means that the new pod of the broker is waiting to be scheduled and become ready.
+	ReplacementPhaseRescheduling KafkaBrokerReplacementPhase = "Rescheduling"
+	// ReplacementPhaseCatchingUp means that the replicas of the broker are waiting to rejoin the in-sync replicas.
+	ReplacementPhaseCatchingUp KafkaBrokerReplacementPhase = "CatchingUp"
+	// ReplacementPhaseCompleted means that the broker is running on the new node with all of its replicas in sync.
+	ReplacementPhaseCompleted KafkaBrokerReplacementPhase = "Completed"
+	// ReplacementPhaseFailed means that the replacement cannot be continued.
+	ReplacementPhaseFailed KafkaBrokerReplacementPhase = "Failed"
+
+	// ReplacementConditionDemoted is true once the partition leaderships have been moved off from the broker.
+	ReplacementConditionDemoted = "Demoted"
+	// ReplacementConditionEvicted is true once the pod of the broker has been evicted.
+	ReplacementConditionEvicted = "Evicted"
+	// ReplacementConditionRescheduled is true once the new pod of the broker is ready.
+	ReplacementConditionRescheduled = "Rescheduled"
+	// ReplacementConditionCaughtUp is true once every replica of the broker is in sync again.
+	ReplacementConditionCaughtUp = "CaughtUp"
+)
+
+// KafkaBrokerReplacementPhase defines the step of the broker replacement state machine.
+type KafkaBrokerReplacementPhase string
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=kbr
+//+kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterRef.name"
+//+kubebuilder:printcolumn:name="Broker",type="integer",JSONPath=".spec.brokerId"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Node",type="string",JSONPath=".status.nodeName"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KafkaBrokerReplacement is the Schema for the kafkaBrokerReplacements API.
+// It moves a broker onto a new node: the broker is demoted, its pod is evicted and rescheduled with the updated node
+// selector, then the replicas of the broker are verified to have caught up with the in-sync replicas. The volumes of
+// the broker are kept, which makes it usable during node upgrades where the persistent volumes are zonal.
+type KafkaBrokerReplacement struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaBrokerReplacementSpec   `json:"spec,omitempty"`
+	Status KafkaBrokerReplacementStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// KafkaBrokerReplacementList contains a list of KafkaBrokerReplacement.
+type KafkaBrokerReplacementList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaBrokerReplacement `json:"items"`
+}
+
+// KafkaBrokerReplacementSpec defines the broker to be moved onto a new node.
+type KafkaBrokerReplacementSpec struct {
+	ClusterRef ClusterReference `json:"clusterRef"`
+	// BrokerID is the ID of the broker to be replaced.
+	// +kubebuilder:validation:Minimum=0
+	BrokerID int32 `json:"brokerId"`
+	// NodeSelector is set on the broker in the KafkaCluster before its pod is evicted, so that the new pod is
+	// scheduled onto the nodes having the labels. The node selector of the broker is left unchanged when it is empty.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// SkipDemotion skips moving the partition leaderships off from the broker before its pod is evicted.
+	// +optional
+	SkipDemotion bool `json:"skipDemotion,omitempty"`
+}
+
+// KafkaBrokerReplacementStatus defines the observed state of KafkaBrokerReplacement.
+type KafkaBrokerReplacementStatus struct {
+	// Phase is the current step of the replacement.
+	// +optional
+	Phase KafkaBrokerReplacementPhase `json:"phase,omitempty"`
+	// Conditions of the steps of the replacement.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// DemoteOperation is the name of the CruiseControlOperation which demotes the broker.
+	// +optional
+	DemoteOperation string `json:"demoteOperation,omitempty"`
+	// EvictedPodUID is the UID of the pod of the broker which is evicted.
+	// +optional
+	EvictedPodUID types.UID `json:"evictedPodUid,omitempty"`
+	// NodeName is the name of the node the new pod of the broker has been scheduled onto.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// ErrorMessage is the reason of the failure when the replacement cannot be continued.
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// CompletionTime is the time when the replacement has been completed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// IsDone returns true when the replacement has either been completed or failed.
+func (r *KafkaBrokerReplacement) IsDone() bool {
+	return r.Status.Phase == ReplacementPhaseCompleted || r.Status.Phase == ReplacementPhaseFailed
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaBrokerReplacement{}, &KafkaBrokerReplacementList{})
+}
@@ -0,0 +1,171 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// KafkaConnectStateCreating means that the credentials or the workers of the Kafka Connect cluster are not ready yet.
+	KafkaConnectStateCreating KafkaConnectState = "Creating"
+	// KafkaConnectStateRunning means that all of the workers of the Kafka Connect cluster are ready.
+	KafkaConnectStateRunning KafkaConnectState = "Running"
+	// KafkaConnectStateFailed means that the Kafka Connect cluster cannot be deployed.
+	KafkaConnectStateFailed KafkaConnectState = "Failed"
+
+	// KafkaConnectRESTPort is the port of the REST API of the Kafka Connect workers.
+	KafkaConnectRESTPort = 8083
+)
+
+// KafkaConnectState defines the state of the Kafka Connect cluster.
+type KafkaConnectState string
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=kc
+//+kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterRef.name"
+//+kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas"
+//+kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KafkaConnect is the Schema for the kafkaConnects API.
+// It deploys a Kafka Connect cluster in distributed mode which is connected to a KafkaCluster. When the listener of
+// the KafkaCluster requires authentication the credentials of the workers are provisioned through a KafkaUser.
+type KafkaConnect struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaConnectSpec   `json:"spec,omitempty"`
+	Status KafkaConnectStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// KafkaConnectList contains a list of KafkaConnect.
+type KafkaConnectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaConnect `json:"items"`
+}
+
+// KafkaConnectSpec defines the desired state of the Kafka Connect cluster.
+type KafkaConnectSpec struct {
+	ClusterRef ClusterReference `json:"clusterRef"`
+	// ListenerName is the name of the internal listener of the KafkaCluster the workers connect to, defaults to the
+	// listener used for inner broker communication.
+	// +optional
+	ListenerName string `json:"listenerName,omitempty"`
+	// Replicas is the number of the workers, defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Image of the workers, defaults to the image of the brokers of the KafkaCluster which contains Kafka Connect.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// GroupID is the consumer group of the workers, defaults to the name of the KafkaConnect. The internal topics of
+	// the Kafka Connect cluster are prefixed with it.
+	// +optional
+	GroupID string `json:"groupId,omitempty"`
+	// Config holds additional worker configuration in properties format, it cannot override the bootstrap servers,
+	// the group, the internal topics and the security settings.
+	// +optional
+	Config string `json:"config,omitempty"`
+	// SASLMechanism is the SCRAM mechanism the workers use on SASL listeners, the workers authenticate with the
+	// delegation token of their KafkaUser. Defaults to SCRAM-SHA-512.
+	// +kubebuilder:validation:Enum=SCRAM-SHA-256;SCRAM-SHA-512
+	// +optional
+	SASLMechanism string `json:"saslMechanism,omitempty"`
+	// TopicGrants are the permissions of the workers on the topics used by the connectors, the permissions on the
+	// internal topics are granted by the operator.
+	// +optional
+	TopicGrants []UserTopicGrant `json:"topicGrants,omitempty"`
+	// Resources of the workers.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Envs are additional environment variables of the workers, e.g. KAFKA_HEAP_OPTS.
+	// +optional
+	Envs []corev1.EnvVar `json:"envs,omitempty"`
+	// Volumes and VolumeMounts are additional volumes of the workers, e.g. to make connector plugins or files read by
+	// the connectors available to them.
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// PluginVolumes are the names of the VolumeMounts holding connector plugins, the plugin path of the workers is set
+	// to their mount paths.
+	// +optional
+	PluginVolumes []string `json:"pluginVolumes,omitempty"`
+	// ImagePullSecrets of the workers.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// KafkaConnectStatus defines the observed state of the Kafka Connect cluster.
+type KafkaConnectStatus struct {
+	// State of the Kafka Connect cluster.
+	// +optional
+	State KafkaConnectState `json:"state,omitempty"`
+	// ReadyReplicas is the number of the ready workers.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// RESTEndpoint is the URL of the REST API of the Kafka Connect cluster.
+	// +optional
+	RESTEndpoint string `json:"restEndpoint,omitempty"`
+	// UserName is the name of the KafkaUser holding the credentials of the workers.
+	// +optional
+	UserName string `json:"userName,omitempty"`
+	// ErrorMessage is the reason why the Kafka Connect cluster cannot be deployed.
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// ObservedGeneration is the generation of the KafkaConnect which has been reconciled last.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// GetReplicas returns the number of the workers
+func (spec *KafkaConnectSpec) GetReplicas() int32 {
+	if spec.Replicas != nil {
+		return *spec.Replicas
+	}
+	return 1
+}
+
+// GetSASLMechanism returns the SCRAM mechanism the workers use on SASL listeners
+func (spec *KafkaConnectSpec) GetSASLMechanism() string {
+	if spec.SASLMechanism != "" {
+		return spec.SASLMechanism
+	}
+	return DefaultClientSASLMechanism
+}
+
+// GetGroupID returns the consumer group of the workers
+func (c *KafkaConnect) GetGroupID() string {
+	if c.Spec.GroupID != "" {
+		return c.Spec.GroupID
+	}
+	return c.GetName()
+}
+
+// GetRESTEndpoint returns the URL of the REST API of the Kafka Connect cluster
+func (c *KafkaConnect) GetRESTEndpoint(kubernetesClusterDomain string) string {
+	return fmt.Sprintf("http://%s-connect.%s.svc.%s:%d", c.GetName(), c.GetNamespace(), kubernetesClusterDomain, KafkaConnectRESTPort)
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaConnect{}, &KafkaConnectList{})
+}
@@ -0,0 +1,105 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=kctr
+//+kubebuilder:printcolumn:name="Connect",type="string",JSONPath=".spec.connectRef"
+//+kubebuilder:printcolumn:name="Class",type="string",JSONPath=".spec.class"
+//+kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KafkaConnector is the Schema for the kafkaConnectors API.
+// It manages a connector of a Kafka Connect cluster deployed by a KafkaConnect through the REST API of the workers.
+type KafkaConnector struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaConnectorSpec   `json:"spec,omitempty"`
+	Status KafkaConnectorStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// KafkaConnectorList contains a list of KafkaConnector.
+type KafkaConnectorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaConnector `json:"items"`
+}
+
+// KafkaConnectorSpec defines the desired state of the connector.
+type KafkaConnectorSpec struct {
+	// ConnectRef is the name of the KafkaConnect in the namespace of the KafkaConnector running the connector.
+	ConnectRef string `json:"connectRef"`
+	// Class is the class of the connector.
+	Class string `json:"class"`
+	// TasksMax is the maximum number of the tasks of the connector, defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TasksMax *int32 `json:"tasksMax,omitempty"`
+	// Config holds the configuration of the connector. The name, the class and the maximum number of tasks are set
+	// by the other fields.
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+	// Paused pauses the connector and its tasks.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// KafkaConnectorStatus defines the observed state of the connector as reported by the Kafka Connect cluster.
+type KafkaConnectorStatus struct {
+	// State of the connector, e.g. RUNNING, PAUSED or FAILED.
+	// +optional
+	State string `json:"state,omitempty"`
+	// WorkerID is the worker running the connector.
+	// +optional
+	WorkerID string `json:"workerId,omitempty"`
+	// Tasks are the states of the tasks of the connector.
+	// +optional
+	Tasks []KafkaConnectorTaskStatus `json:"tasks,omitempty"`
+	// ErrorMessage is the reason why the connector cannot be configured.
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// ObservedGeneration is the generation of the KafkaConnector which has been applied last.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// KafkaConnectorTaskStatus defines the state of a task of the connector.
+type KafkaConnectorTaskStatus struct {
+	ID       int32  `json:"id"`
+	State    string `json:"state"`
+	WorkerID string `json:"workerId,omitempty"`
+	// Trace is the stack trace of the failed task.
+	// +optional
+	Trace string `json:"trace,omitempty"`
+}
+
+// GetTasksMax returns the maximum number of the tasks of the connector
+func (spec *KafkaConnectorSpec) GetTasksMax() int32 {
+	if spec.TasksMax != nil {
+		return *spec.TasksMax
+	}
+	return 1
+}
+
+func init() {
+	SchemeBuilder.Register(&KafkaConnector{}, &KafkaConnectorList{})
+}
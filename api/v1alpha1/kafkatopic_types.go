@@ -21,6 +21,8 @@ import (
 const (
 	MinPartitions        = -1
 	MinReplicationFactor = -1
+	// DefaultPartitionScalingEvaluationIntervalSeconds is how often the consumer lag of the topics is evaluated by default
+	DefaultPartitionScalingEvaluationIntervalSeconds = 300
 )
 
 // KafkaTopicSpec defines the desired state of KafkaTopic
@@ -35,6 +37,56 @@ type KafkaTopicSpec struct {
 	ReplicationFactor int32             `json:"replicationFactor"`
 	Config            map[string]string `json:"config,omitempty"`
 	ClusterRef        ClusterReference  `json:"clusterRef"`
+	// PartitionScaling enables partition count recommendations based on the lag of the consumer groups of the topic.
+	// The recommendations are only evaluated when the partition scaling controller of the operator is enabled.
+	// +optional
+	PartitionScaling *PartitionScalingPolicy `json:"partitionScaling,omitempty"`
+}
+
+// PartitionScalingPolicy defines when more partitions are recommended for the topic. The lag of a consumer group is
+// the sum of the differences between the high watermarks and the committed offsets of the group on the partitions.
+type PartitionScalingPolicy struct {
+	// MaxLagPerPartition is the consumer lag per partition above which more partitions are recommended, the lag of the
+	// consumer group with the highest lag is used
+	// +kubebuilder:validation:Minimum=1
+	MaxLagPerPartition int64 `json:"maxLagPerPartition"`
+	// MaxPartitions is the upper limit of the recommended partition count
+	// +kubebuilder:validation:Minimum=1
+	MaxPartitions int32 `json:"maxPartitions"`
+	// AutoApply increases the partitions of the KafkaTopic to the recommended count, otherwise the recommendation is
+	// only reported in the status
+	// +optional
+	AutoApply bool `json:"autoApply,omitempty"`
+	// EvaluationIntervalSeconds is how often the consumer lag is evaluated, defaults to 300
+	// +kubebuilder:validation:Minimum=30
+	// +optional
+	EvaluationIntervalSeconds *int32 `json:"evaluationIntervalSeconds,omitempty"`
+}
+
+// GetEvaluationIntervalSeconds returns how often the consumer lag is evaluated
+func (p *PartitionScalingPolicy) GetEvaluationIntervalSeconds() int32 {
+	if p.EvaluationIntervalSeconds != nil {
+		return *p.EvaluationIntervalSeconds
+	}
+	return DefaultPartitionScalingEvaluationIntervalSeconds
+}
+
+// PartitionRecommendation is the partition count recommended for the topic based on its consumer lag
+type PartitionRecommendation struct {
+	// ConsumerGroup is the consumer group with the highest lag on the topic
+	// +optional
+	ConsumerGroup string `json:"consumerGroup,omitempty"`
+	// ConsumerLag is the lag of the consumer group
+	ConsumerLag int64 `json:"consumerLag"`
+	// CurrentPartitions is the partition count of the topic at the time of the evaluation
+	CurrentPartitions int32 `json:"currentPartitions"`
+	// RecommendedPartitions is the partition count which keeps the lag per partition within the policy
+	RecommendedPartitions int32 `json:"recommendedPartitions"`
+	// Applied is true when the recommendation has been applied to the KafkaTopic
+	// +optional
+	Applied bool `json:"applied,omitempty"`
+	// LastEvaluatedTime is the time of the last evaluation of the consumer lag
+	LastEvaluatedTime metav1.Time `json:"lastEvaluatedTime"`
 }
 
 // KafkaTopicStatus defines the observed state of KafkaTopic
@@ -45,6 +97,12 @@ type KafkaTopicStatus struct {
 	// Manager of the Kafka topic can be changed by adding the "managedBy: <manager>" annotation to the KafkaTopic CR.
 	ManagedBy string     `json:"managedBy"`
 	State     TopicState `json:"state"`
+	// PartitionRecommendation is the last partition count recommendation of the partition scaling controller
+	// +optional
+	PartitionRecommendation *PartitionRecommendation `json:"partitionRecommendation,omitempty"`
+	// ObservedGeneration is the generation of the KafkaTopic which has been reconciled last.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // +kubebuilder:webhook:verbs=create;update,path=/validate-kafka-banzaicloud-io-v1alpha1-kafkatopic,mutating=false,failurePolicy=fail,groups=kafka.banzaicloud.io,resources=kafkatopics,versions=v1alpha1,name=kafkatopics.kafka.banzaicloud.io,sideEffects=None,admissionReviewVersions=v1
@@ -15,9 +15,12 @@
 package v1alpha1
 
 import (
+	"time"
+
 	"github.com/banzaicloud/koperator/api/util"
 
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,6 +36,61 @@ type KafkaUserSpec struct {
 	IncludeJKS     bool              `json:"includeJKS,omitempty"`
 	CreateCert     *bool             `json:"createCert,omitempty"`
 	PKIBackendSpec *PKIBackendSpec   `json:"pkiBackendSpec,omitempty"`
+	// DelegationToken requests a delegation token for the user for the workloads which cannot use TLS client
+	// certificates. The token is issued again before it expires. Delegation tokens must be enabled for the cluster.
+	// +optional
+	DelegationToken *UserDelegationToken `json:"delegationToken,omitempty"`
+	// ExternalCertificate makes the operator use the client certificate stored in the existing Secret referenced by
+	// 'secretName' instead of issuing one, e.g. when the client certificates come from a corporate PKI. The ACLs are
+	// created for the Distinguished Name of the certificate. The Secret is neither created nor modified by the
+	// operator and 'createCert' and 'pkiBackendSpec' are ignored.
+	// +optional
+	ExternalCertificate *ExternalUserCertificate `json:"externalCertificate,omitempty"`
+	// ConnectionProfile generates a Secret with ready-to-use client properties for each listener of the cluster
+	// together with the key material they reference, so that the applications can connect by mounting a single Secret
+	// +optional
+	ConnectionProfile *UserConnectionProfile `json:"connectionProfile,omitempty"`
+}
+
+// UserConnectionProfile defines the Secret holding the client connection profile of the KafkaUser. The Secret
+// contains a <listener name>.properties key for each listener with the bootstrap servers, the security protocol
+// and the SSL and SASL settings of the listener, and client.properties for the default listener. When the
+// certificate of the user is issued by the operator, the PEM encoded keystore.pem and truststore.pem are stored
+// in the Secret too. The SASL settings are generated only when the user has a delegation token. The Secret also
+// holds the keys of the default listener defined by the Service Binding specification (type, provider,
+// bootstrap-servers, securityProtocol and saslMechanism), it is the binding Secret of the KafkaUser.
+type UserConnectionProfile struct {
+	// SecretName is the name of the generated Secret
+	SecretName string `json:"secretName"`
+	// MountPath is the directory the Secret is mounted to in the application containers, the locations of the
+	// keystore and the truststore in the client properties point into it. It defaults to /etc/kafka/client.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+	// Listener is the name of the listener client.properties is generated for, it defaults to the internal listener
+	// used for inner broker communication
+	// +optional
+	Listener string `json:"listener,omitempty"`
+}
+
+// ExternalUserCertificate defines where the externally managed client certificate of the KafkaUser is stored
+type ExternalUserCertificate struct {
+	// CertificateKey is the key of the PEM encoded client certificate in the Secret, it defaults to tls.crt
+	// +optional
+	CertificateKey string `json:"certificateKey,omitempty"`
+}
+
+// UserDelegationToken defines the delegation token issued for the KafkaUser
+type UserDelegationToken struct {
+	// SecretName is the name of the Secret the token is stored in
+	SecretName string `json:"secretName"`
+	// MaxLifetimeSeconds limits the lifetime of the token, defaults to the maximal lifetime configured for the cluster
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxLifetimeSeconds *int64 `json:"maxLifetimeSeconds,omitempty"`
+	// RenewBeforeSeconds is how long before the expiry of the token a new one is issued, defaults to 1 hour
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RenewBeforeSeconds *int64 `json:"renewBeforeSeconds,omitempty"`
 }
 
 type PKIBackendSpec struct {
@@ -57,6 +115,32 @@ type UserTopicGrant struct {
 type KafkaUserStatus struct {
 	State UserState `json:"state"`
 	ACLs  []string  `json:"acls,omitempty"`
+	// DelegationToken describes the delegation token issued last for the user
+	// +optional
+	DelegationToken *UserDelegationTokenStatus `json:"delegationToken,omitempty"`
+	// ObservedGeneration is the generation of the KafkaUser which has been reconciled last.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Principal is the Distinguished Name of the user the ACLs have been created for
+	// +optional
+	Principal string `json:"principal,omitempty"`
+	// Binding references the Secret of the user which can be bound to workloads as defined by the Service Binding
+	// specification. It is the Secret of the connection profile when it is set.
+	// +optional
+	Binding *corev1.LocalObjectReference `json:"binding,omitempty"`
+}
+
+// UserDelegationTokenStatus describes the delegation token issued for the KafkaUser
+type UserDelegationTokenStatus struct {
+	// TokenID is the ID of the token
+	TokenID string `json:"tokenId"`
+	// SecretName is the name of the Secret the token is stored in
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// IssueTime is the time when the token has been issued
+	IssueTime metav1.Time `json:"issueTime"`
+	// ExpiryTime is the time when the token expires
+	ExpiryTime metav1.Time `json:"expiryTime"`
 }
 
 // KafkaUser is the Schema for the kafka users API
@@ -92,7 +176,31 @@ func (spec *KafkaUserSpec) GetIfCertShouldBeCreated() bool {
 	return true
 }
 
+// GetCertificateKey returns the key of the externally managed client certificate in the Secret of the KafkaUser
+func (c *ExternalUserCertificate) GetCertificateKey() string {
+	if c.CertificateKey != "" {
+		return c.CertificateKey
+	}
+	return corev1.TLSCertKey
+}
+
+// GetMountPath returns the directory the connection profile Secret is mounted to
+func (p *UserConnectionProfile) GetMountPath() string {
+	if p.MountPath != "" {
+		return p.MountPath
+	}
+	return "/etc/kafka/client"
+}
+
 // GetAnnotations returns Annotations to use for certificate or certificate signing request object
 func (spec *KafkaUserSpec) GetAnnotations() map[string]string {
 	return util.CloneMap(spec.Annotations)
 }
+
+// GetRenewBefore returns how long before the expiry of the delegation token a new one is issued
+func (token *UserDelegationToken) GetRenewBefore() time.Duration {
+	if token.RenewBeforeSeconds != nil {
+		return time.Duration(*token.RenewBeforeSeconds) * time.Second
+	}
+	return time.Hour
+}
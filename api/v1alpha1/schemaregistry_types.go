@@ -0,0 +1,191 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SchemaRegistryStateCreating means that the credentials, the topic or the instances of the Schema Registry are not ready yet.
+	SchemaRegistryStateCreating SchemaRegistryState = "Creating"
+	// SchemaRegistryStateRunning means that all of the instances of the Schema Registry are ready.
+	SchemaRegistryStateRunning SchemaRegistryState = "Running"
+	// SchemaRegistryStateFailed means that the Schema Registry cannot be deployed.
+	SchemaRegistryStateFailed SchemaRegistryState = "Failed"
+
+	// SchemaRegistryPort is the port of the REST API of the Schema Registry.
+	SchemaRegistryPort = 8081
+	// DefaultSchemaRegistryImage is the image of the Schema Registry used by default.
+	DefaultSchemaRegistryImage = "confluentinc/cp-schema-registry:7.3.3"
+	// DefaultSchemaRegistryTopic is the topic the schemas are stored in by default.
+	DefaultSchemaRegistryTopic = "_schemas"
+)
+
+// SchemaRegistryState defines the state of the Schema Registry.
+type SchemaRegistryState string
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=sr
+//+kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterRef.name"
+//+kubebuilder:printcolumn:name="Topic",type="string",JSONPath=".status.topicName"
+//+kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas"
+//+kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SchemaRegistry is the Schema for the schemaRegistries API.
+// It deploys a Schema Registry which is connected to a KafkaCluster. The topic of the schemas is created as a
+// KafkaTopic and the credentials of the Schema Registry are provisioned through a KafkaUser when the listener of the
+// KafkaCluster requires authentication.
+type SchemaRegistry struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SchemaRegistrySpec   `json:"spec,omitempty"`
+	Status SchemaRegistryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// SchemaRegistryList contains a list of SchemaRegistry.
+type SchemaRegistryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SchemaRegistry `json:"items"`
+}
+
+// SchemaRegistrySpec defines the desired state of the Schema Registry.
+type SchemaRegistrySpec struct {
+	ClusterRef ClusterReference `json:"clusterRef"`
+	// ListenerName is the name of the internal listener of the KafkaCluster the Schema Registry connects to, defaults
+	// to the listener used for inner broker communication.
+	// +optional
+	ListenerName string `json:"listenerName,omitempty"`
+	// Replicas is the number of the instances, defaults to 1. The instances elect a leader which serves the writes.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Image of the Schema Registry, defaults to confluentinc/cp-schema-registry. The configuration is passed to the
+	// Schema Registry with SCHEMA_REGISTRY_ prefixed environment variables as expected by the Confluent images.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Topic is where the schemas are stored, defaults to _schemas. The topic is created with a single compacted
+	// partition, and it is kept when the SchemaRegistry is deleted so that the schemas are not lost.
+	// +optional
+	Topic string `json:"topic,omitempty"`
+	// TopicReplicationFactor is the replication factor of the topic of the schemas, defaults to 3 or the number of
+	// the brokers when the KafkaCluster has less brokers.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TopicReplicationFactor *int32 `json:"topicReplicationFactor,omitempty"`
+	// GroupID is used for the leader election of the instances, defaults to the name of the SchemaRegistry.
+	// +optional
+	GroupID string `json:"groupId,omitempty"`
+	// Config holds additional Schema Registry configuration in properties format, it cannot override the connection,
+	// the topic, the group and the security settings.
+	// +optional
+	Config string `json:"config,omitempty"`
+	// SASLMechanism is the SCRAM mechanism the Schema Registry uses on SASL listeners, it authenticates with the
+	// delegation token of its KafkaUser. Defaults to SCRAM-SHA-512.
+	// +kubebuilder:validation:Enum=SCRAM-SHA-256;SCRAM-SHA-512
+	// +optional
+	SASLMechanism string `json:"saslMechanism,omitempty"`
+	// Resources of the instances.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Envs are additional environment variables of the instances, e.g. SCHEMA_REGISTRY_HEAP_OPTS.
+	// +optional
+	Envs []corev1.EnvVar `json:"envs,omitempty"`
+	// ImagePullSecrets of the instances.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// SchemaRegistryStatus defines the observed state of the Schema Registry.
+type SchemaRegistryStatus struct {
+	// State of the Schema Registry.
+	// +optional
+	State SchemaRegistryState `json:"state,omitempty"`
+	// ReadyReplicas is the number of the ready instances.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// URL of the REST API of the Schema Registry.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// TopicName is the name of the topic the schemas are stored in.
+	// +optional
+	TopicName string `json:"topicName,omitempty"`
+	// UserName is the name of the KafkaUser holding the credentials of the Schema Registry.
+	// +optional
+	UserName string `json:"userName,omitempty"`
+	// ErrorMessage is the reason why the Schema Registry cannot be deployed.
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// ObservedGeneration is the generation of the SchemaRegistry which has been reconciled last.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// GetReplicas returns the number of the instances
+func (spec *SchemaRegistrySpec) GetReplicas() int32 {
+	if spec.Replicas != nil {
+		return *spec.Replicas
+	}
+	return 1
+}
+
+// GetImage returns the image of the Schema Registry
+func (spec *SchemaRegistrySpec) GetImage() string {
+	if spec.Image != "" {
+		return spec.Image
+	}
+	return DefaultSchemaRegistryImage
+}
+
+// GetTopic returns the topic the schemas are stored in
+func (spec *SchemaRegistrySpec) GetTopic() string {
+	if spec.Topic != "" {
+		return spec.Topic
+	}
+	return DefaultSchemaRegistryTopic
+}
+
+// GetSASLMechanism returns the SCRAM mechanism the Schema Registry uses on SASL listeners
+func (spec *SchemaRegistrySpec) GetSASLMechanism() string {
+	if spec.SASLMechanism != "" {
+		return spec.SASLMechanism
+	}
+	return DefaultClientSASLMechanism
+}
+
+// GetGroupID returns the group used for the leader election of the instances
+func (s *SchemaRegistry) GetGroupID() string {
+	if s.Spec.GroupID != "" {
+		return s.Spec.GroupID
+	}
+	return s.GetName()
+}
+
+// GetURL returns the URL of the REST API of the Schema Registry
+func (s *SchemaRegistry) GetURL(kubernetesClusterDomain string) string {
+	return fmt.Sprintf("http://%s-schema-registry.%s.svc.%s:%d", s.GetName(), s.GetNamespace(), kubernetesClusterDomain, SchemaRegistryPort)
+}
+
+func init() {
+	SchemeBuilder.Register(&SchemaRegistry{}, &SchemaRegistryList{})
+}
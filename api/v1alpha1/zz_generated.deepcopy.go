@@ -20,10 +20,28 @@
 package v1alpha1
 
 import (
-	"github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	metav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerProjectedLoad) DeepCopyInto(out *BrokerProjectedLoad) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerProjectedLoad.
+func (in *BrokerProjectedLoad) DeepCopy() *BrokerProjectedLoad {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerProjectedLoad)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterReference) DeepCopyInto(out *ClusterReference) {
 	*out = *in
@@ -39,6 +57,218 @@ func (in *ClusterReference) DeepCopy() *ClusterReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConcurrencyBoost) DeepCopyInto(out *ConcurrencyBoost) {
+	*out = *in
+	if in.OffPeakWindows != nil {
+		in, out := &in.OffPeakWindows, &out.OffPeakWindows
+		*out = make([]TimeWindow, len(*in))
+		copy(*out, *in)
+	}
+	in.OffPeak.DeepCopyInto(&out.OffPeak)
+	in.Peak.DeepCopyInto(&out.Peak)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConcurrencyBoost.
+func (in *ConcurrencyBoost) DeepCopy() *ConcurrencyBoost {
+	if in == nil {
+		return nil
+	}
+	out := new(ConcurrencyBoost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlAdmin) DeepCopyInto(out *CruiseControlAdmin) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlAdmin.
+func (in *CruiseControlAdmin) DeepCopy() *CruiseControlAdmin {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlAdmin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CruiseControlAdmin) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlAdminList) DeepCopyInto(out *CruiseControlAdminList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CruiseControlAdmin, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlAdminList.
+func (in *CruiseControlAdminList) DeepCopy() *CruiseControlAdminList {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlAdminList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CruiseControlAdminList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlAdminSpec) DeepCopyInto(out *CruiseControlAdminSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(CruiseControlExecutorConcurrency)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EnableSelfHealingFor != nil {
+		in, out := &in.EnableSelfHealingFor, &out.EnableSelfHealingFor
+		*out = make([]CruiseControlAnomalyType, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisableSelfHealingFor != nil {
+		in, out := &in.DisableSelfHealingFor, &out.DisableSelfHealingFor
+		*out = make([]CruiseControlAnomalyType, len(*in))
+		copy(*out, *in)
+	}
+	if in.DropRecentlyRemovedBrokers != nil {
+		in, out := &in.DropRecentlyRemovedBrokers, &out.DropRecentlyRemovedBrokers
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.DropRecentlyDemotedBrokers != nil {
+		in, out := &in.DropRecentlyDemotedBrokers, &out.DropRecentlyDemotedBrokers
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlAdminSpec.
+func (in *CruiseControlAdminSpec) DeepCopy() *CruiseControlAdminSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlAdminSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlAdminStatus) DeepCopyInto(out *CruiseControlAdminStatus) {
+	*out = *in
+	if in.LastAppliedTime != nil {
+		in, out := &in.LastAppliedTime, &out.LastAppliedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(CruiseControlExecutorConcurrency)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SelfHealingEnabled != nil {
+		in, out := &in.SelfHealingEnabled, &out.SelfHealingEnabled
+		*out = make([]CruiseControlAnomalyType, len(*in))
+		copy(*out, *in)
+	}
+	if in.SelfHealingDisabled != nil {
+		in, out := &in.SelfHealingDisabled, &out.SelfHealingDisabled
+		*out = make([]CruiseControlAnomalyType, len(*in))
+		copy(*out, *in)
+	}
+	if in.RecentlyRemovedBrokers != nil {
+		in, out := &in.RecentlyRemovedBrokers, &out.RecentlyRemovedBrokers
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.RecentlyDemotedBrokers != nil {
+		in, out := &in.RecentlyDemotedBrokers, &out.RecentlyDemotedBrokers
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlAdminStatus.
+func (in *CruiseControlAdminStatus) DeepCopy() *CruiseControlAdminStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlAdminStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlExecutionIntent) DeepCopyInto(out *CruiseControlExecutionIntent) {
+	*out = *in
+	in.Recorded.DeepCopyInto(&out.Recorded)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlExecutionIntent.
+func (in *CruiseControlExecutionIntent) DeepCopy() *CruiseControlExecutionIntent {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlExecutionIntent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlExecutorConcurrency) DeepCopyInto(out *CruiseControlExecutorConcurrency) {
+	*out = *in
+	if in.InterBrokerPartitionMovements != nil {
+		in, out := &in.InterBrokerPartitionMovements, &out.InterBrokerPartitionMovements
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IntraBrokerPartitionMovements != nil {
+		in, out := &in.IntraBrokerPartitionMovements, &out.IntraBrokerPartitionMovements
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LeadershipMovements != nil {
+		in, out := &in.LeadershipMovements, &out.LeadershipMovements
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlExecutorConcurrency.
+func (in *CruiseControlExecutorConcurrency) DeepCopy() *CruiseControlExecutorConcurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlExecutorConcurrency)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CruiseControlOperation) DeepCopyInto(out *CruiseControlOperation) {
 	*out = *in
@@ -101,11 +331,31 @@ func (in *CruiseControlOperationList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CruiseControlOperationSpec) DeepCopyInto(out *CruiseControlOperationSpec) {
 	*out = *in
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int)
+		**out = **in
+	}
 	if in.TTLSecondsAfterFinished != nil {
 		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
 		*out = new(int)
 		**out = **in
 	}
+	if in.ConcurrencyBoost != nil {
+		in, out := &in.ConcurrencyBoost, &out.ConcurrencyBoost
+		*out = new(ConcurrencyBoost)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(RetryBackoff)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailedTasksHistory != nil {
+		in, out := &in.FailedTasksHistory, &out.FailedTasksHistory
+		*out = new(v1beta1.FailedTasksHistoryLimits)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationSpec.
@@ -133,6 +383,20 @@ func (in *CruiseControlOperationStatus) DeepCopyInto(out *CruiseControlOperation
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ConcurrencyBoosted != nil {
+		in, out := &in.ConcurrencyBoosted, &out.ConcurrencyBoosted
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExecutionIntent != nil {
+		in, out := &in.ExecutionIntent, &out.ExecutionIntent
+		*out = new(CruiseControlExecutionIntent)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationStatus.
@@ -163,26 +427,616 @@ func (in *CruiseControlTask) DeepCopyInto(out *CruiseControlTask) {
 			(*out)[key] = val
 		}
 	}
+	if in.TypedParameters != nil {
+		in, out := &in.TypedParameters, &out.TypedParameters
+		*out = new(CruiseControlTaskParameters)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.HTTPResponseCode != nil {
 		in, out := &in.HTTPResponseCode, &out.HTTPResponseCode
 		*out = new(int)
 		**out = **in
 	}
-	if in.Summary != nil {
-		in, out := &in.Summary, &out.Summary
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PartitionMovements != nil {
+		in, out := &in.PartitionMovements, &out.PartitionMovements
+		*out = new(CruiseControlTaskPartitionMovements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Simulation != nil {
+		in, out := &in.Simulation, &out.Simulation
+		*out = new(ScaleDownSimulation)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTask.
+func (in *CruiseControlTask) DeepCopy() *CruiseControlTask {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlTask)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlTaskParameters) DeepCopyInto(out *CruiseControlTaskParameters) {
+	*out = *in
+	if in.BrokerIDs != nil {
+		in, out := &in.BrokerIDs, &out.BrokerIDs
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.DestinationBrokerIDs != nil {
+		in, out := &in.DestinationBrokerIDs, &out.DestinationBrokerIDs
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConcurrentPartitionMovementsPerBroker != nil {
+		in, out := &in.ConcurrentPartitionMovementsPerBroker, &out.ConcurrentPartitionMovementsPerBroker
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ConcurrentLeaderMovements != nil {
+		in, out := &in.ConcurrentLeaderMovements, &out.ConcurrentLeaderMovements
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RebalanceDisk != nil {
+		in, out := &in.RebalanceDisk, &out.RebalanceDisk
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExcludeRecentlyDemotedBrokers != nil {
+		in, out := &in.ExcludeRecentlyDemotedBrokers, &out.ExcludeRecentlyDemotedBrokers
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExcludeRecentlyRemovedBrokers != nil {
+		in, out := &in.ExcludeRecentlyRemovedBrokers, &out.ExcludeRecentlyRemovedBrokers
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SkipHardGoalCheck != nil {
+		in, out := &in.SkipHardGoalCheck, &out.SkipHardGoalCheck
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTaskParameters.
+func (in *CruiseControlTaskParameters) DeepCopy() *CruiseControlTaskParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlTaskParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlTaskPartitionMovements) DeepCopyInto(out *CruiseControlTaskPartitionMovements) {
+	*out = *in
+	if in.InProgressTopics != nil {
+		in, out := &in.InProgressTopics, &out.InProgressTopics
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.InProgress != nil {
+		in, out := &in.InProgress, &out.InProgress
+		*out = make([]PartitionMovement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTaskPartitionMovements.
+func (in *CruiseControlTaskPartitionMovements) DeepCopy() *CruiseControlTaskPartitionMovements {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlTaskPartitionMovements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalUserCertificate) DeepCopyInto(out *ExternalUserCertificate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalUserCertificate.
+func (in *ExternalUserCertificate) DeepCopy() *ExternalUserCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalUserCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBrokerDecommission) DeepCopyInto(out *KafkaBrokerDecommission) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBrokerDecommission.
+func (in *KafkaBrokerDecommission) DeepCopy() *KafkaBrokerDecommission {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBrokerDecommission)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBrokerDecommission) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBrokerDecommissionList) DeepCopyInto(out *KafkaBrokerDecommissionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaBrokerDecommission, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBrokerDecommissionList.
+func (in *KafkaBrokerDecommissionList) DeepCopy() *KafkaBrokerDecommissionList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBrokerDecommissionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBrokerDecommissionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBrokerDecommissionSpec) DeepCopyInto(out *KafkaBrokerDecommissionSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBrokerDecommissionSpec.
+func (in *KafkaBrokerDecommissionSpec) DeepCopy() *KafkaBrokerDecommissionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBrokerDecommissionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBrokerDecommissionStatus) DeepCopyInto(out *KafkaBrokerDecommissionStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBrokerDecommissionStatus.
+func (in *KafkaBrokerDecommissionStatus) DeepCopy() *KafkaBrokerDecommissionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBrokerDecommissionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBrokerReplacement) DeepCopyInto(out *KafkaBrokerReplacement) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBrokerReplacement.
+func (in *KafkaBrokerReplacement) DeepCopy() *KafkaBrokerReplacement {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBrokerReplacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBrokerReplacement) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBrokerReplacementList) DeepCopyInto(out *KafkaBrokerReplacementList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaBrokerReplacement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBrokerReplacementList.
+func (in *KafkaBrokerReplacementList) DeepCopy() *KafkaBrokerReplacementList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBrokerReplacementList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBrokerReplacementList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBrokerReplacementSpec) DeepCopyInto(out *KafkaBrokerReplacementSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBrokerReplacementSpec.
+func (in *KafkaBrokerReplacementSpec) DeepCopy() *KafkaBrokerReplacementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBrokerReplacementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBrokerReplacementStatus) DeepCopyInto(out *KafkaBrokerReplacementStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBrokerReplacementStatus.
+func (in *KafkaBrokerReplacementStatus) DeepCopy() *KafkaBrokerReplacementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBrokerReplacementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnect) DeepCopyInto(out *KafkaConnect) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnect.
+func (in *KafkaConnect) DeepCopy() *KafkaConnect {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnect)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaConnect) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectList) DeepCopyInto(out *KafkaConnectList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaConnect, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectList.
+func (in *KafkaConnectList) DeepCopy() *KafkaConnectList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaConnectList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectSpec) DeepCopyInto(out *KafkaConnectSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TopicGrants != nil {
+		in, out := &in.TopicGrants, &out.TopicGrants
+		*out = make([]UserTopicGrant, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Envs != nil {
+		in, out := &in.Envs, &out.Envs
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]corev1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PluginVolumes != nil {
+		in, out := &in.PluginVolumes, &out.PluginVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectSpec.
+func (in *KafkaConnectSpec) DeepCopy() *KafkaConnectSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectStatus) DeepCopyInto(out *KafkaConnectStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectStatus.
+func (in *KafkaConnectStatus) DeepCopy() *KafkaConnectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnector) DeepCopyInto(out *KafkaConnector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnector.
+func (in *KafkaConnector) DeepCopy() *KafkaConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaConnector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectorList) DeepCopyInto(out *KafkaConnectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaConnector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectorList.
+func (in *KafkaConnectorList) DeepCopy() *KafkaConnectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaConnectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectorSpec) DeepCopyInto(out *KafkaConnectorSpec) {
+	*out = *in
+	if in.TasksMax != nil {
+		in, out := &in.TasksMax, &out.TasksMax
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectorSpec.
+func (in *KafkaConnectorSpec) DeepCopy() *KafkaConnectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaConnectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectorStatus) DeepCopyInto(out *KafkaConnectorStatus) {
+	*out = *in
+	if in.Tasks != nil {
+		in, out := &in.Tasks, &out.Tasks
+		*out = make([]KafkaConnectorTaskStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectorStatus.
+func (in *KafkaConnectorStatus) DeepCopy() *KafkaConnectorStatus {
+	if in == nil {
+		return nil
 	}
+	out := new(KafkaConnectorStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTask.
-func (in *CruiseControlTask) DeepCopy() *CruiseControlTask {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaConnectorTaskStatus) DeepCopyInto(out *KafkaConnectorTaskStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaConnectorTaskStatus.
+func (in *KafkaConnectorTaskStatus) DeepCopy() *KafkaConnectorTaskStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CruiseControlTask)
+	out := new(KafkaConnectorTaskStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -193,7 +1047,7 @@ func (in *KafkaTopic) DeepCopyInto(out *KafkaTopic) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopic.
@@ -257,6 +1111,11 @@ func (in *KafkaTopicSpec) DeepCopyInto(out *KafkaTopicSpec) {
 		}
 	}
 	out.ClusterRef = in.ClusterRef
+	if in.PartitionScaling != nil {
+		in, out := &in.PartitionScaling, &out.PartitionScaling
+		*out = new(PartitionScalingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopicSpec.
@@ -272,6 +1131,11 @@ func (in *KafkaTopicSpec) DeepCopy() *KafkaTopicSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaTopicStatus) DeepCopyInto(out *KafkaTopicStatus) {
 	*out = *in
+	if in.PartitionRecommendation != nil {
+		in, out := &in.PartitionRecommendation, &out.PartitionRecommendation
+		*out = new(PartitionRecommendation)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopicStatus.
@@ -374,6 +1238,21 @@ func (in *KafkaUserSpec) DeepCopyInto(out *KafkaUserSpec) {
 		*out = new(PKIBackendSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DelegationToken != nil {
+		in, out := &in.DelegationToken, &out.DelegationToken
+		*out = new(UserDelegationToken)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalCertificate != nil {
+		in, out := &in.ExternalCertificate, &out.ExternalCertificate
+		*out = new(ExternalUserCertificate)
+		**out = **in
+	}
+	if in.ConnectionProfile != nil {
+		in, out := &in.ConnectionProfile, &out.ConnectionProfile
+		*out = new(UserConnectionProfile)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaUserSpec.
@@ -394,6 +1273,16 @@ func (in *KafkaUserStatus) DeepCopyInto(out *KafkaUserStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DelegationToken != nil {
+		in, out := &in.DelegationToken, &out.DelegationToken
+		*out = new(UserDelegationTokenStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Binding != nil {
+		in, out := &in.Binding, &out.Binding
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaUserStatus.
@@ -411,7 +1300,7 @@ func (in *PKIBackendSpec) DeepCopyInto(out *PKIBackendSpec) {
 	*out = *in
 	if in.IssuerRef != nil {
 		in, out := &in.IssuerRef, &out.IssuerRef
-		*out = new(v1.ObjectReference)
+		*out = new(metav1.ObjectReference)
 		**out = **in
 	}
 }
@@ -426,6 +1315,328 @@ func (in *PKIBackendSpec) DeepCopy() *PKIBackendSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionMovement) DeepCopyInto(out *PartitionMovement) {
+	*out = *in
+	if in.OldReplicas != nil {
+		in, out := &in.OldReplicas, &out.OldReplicas
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.NewReplicas != nil {
+		in, out := &in.NewReplicas, &out.NewReplicas
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionMovement.
+func (in *PartitionMovement) DeepCopy() *PartitionMovement {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionMovement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionRecommendation) DeepCopyInto(out *PartitionRecommendation) {
+	*out = *in
+	in.LastEvaluatedTime.DeepCopyInto(&out.LastEvaluatedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionRecommendation.
+func (in *PartitionRecommendation) DeepCopy() *PartitionRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionScalingPolicy) DeepCopyInto(out *PartitionScalingPolicy) {
+	*out = *in
+	if in.EvaluationIntervalSeconds != nil {
+		in, out := &in.EvaluationIntervalSeconds, &out.EvaluationIntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionScalingPolicy.
+func (in *PartitionScalingPolicy) DeepCopy() *PartitionScalingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionScalingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryBackoff) DeepCopyInto(out *RetryBackoff) {
+	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxDelaySeconds != nil {
+		in, out := &in.MaxDelaySeconds, &out.MaxDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryBackoff.
+func (in *RetryBackoff) DeepCopy() *RetryBackoff {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryBackoff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleDownSimulation) DeepCopyInto(out *ScaleDownSimulation) {
+	*out = *in
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]TopicReplicaMovements, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]BrokerProjectedLoad, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleDownSimulation.
+func (in *ScaleDownSimulation) DeepCopy() *ScaleDownSimulation {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleDownSimulation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaRegistry) DeepCopyInto(out *SchemaRegistry) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaRegistry.
+func (in *SchemaRegistry) DeepCopy() *SchemaRegistry {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaRegistry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SchemaRegistry) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaRegistryList) DeepCopyInto(out *SchemaRegistryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SchemaRegistry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaRegistryList.
+func (in *SchemaRegistryList) DeepCopy() *SchemaRegistryList {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaRegistryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SchemaRegistryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaRegistrySpec) DeepCopyInto(out *SchemaRegistrySpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TopicReplicationFactor != nil {
+		in, out := &in.TopicReplicationFactor, &out.TopicReplicationFactor
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Envs != nil {
+		in, out := &in.Envs, &out.Envs
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaRegistrySpec.
+func (in *SchemaRegistrySpec) DeepCopy() *SchemaRegistrySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaRegistrySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaRegistryStatus) DeepCopyInto(out *SchemaRegistryStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaRegistryStatus.
+func (in *SchemaRegistryStatus) DeepCopy() *SchemaRegistryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaRegistryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeWindow) DeepCopyInto(out *TimeWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeWindow.
+func (in *TimeWindow) DeepCopy() *TimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopicReplicaMovements) DeepCopyInto(out *TopicReplicaMovements) {
+	*out = *in
+	if in.TargetBrokers != nil {
+		in, out := &in.TargetBrokers, &out.TargetBrokers
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopicReplicaMovements.
+func (in *TopicReplicaMovements) DeepCopy() *TopicReplicaMovements {
+	if in == nil {
+		return nil
+	}
+	out := new(TopicReplicaMovements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserConnectionProfile) DeepCopyInto(out *UserConnectionProfile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserConnectionProfile.
+func (in *UserConnectionProfile) DeepCopy() *UserConnectionProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(UserConnectionProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserDelegationToken) DeepCopyInto(out *UserDelegationToken) {
+	*out = *in
+	if in.MaxLifetimeSeconds != nil {
+		in, out := &in.MaxLifetimeSeconds, &out.MaxLifetimeSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RenewBeforeSeconds != nil {
+		in, out := &in.RenewBeforeSeconds, &out.RenewBeforeSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserDelegationToken.
+func (in *UserDelegationToken) DeepCopy() *UserDelegationToken {
+	if in == nil {
+		return nil
+	}
+	out := new(UserDelegationToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserDelegationTokenStatus) DeepCopyInto(out *UserDelegationTokenStatus) {
+	*out = *in
+	in.IssueTime.DeepCopyInto(&out.IssueTime)
+	in.ExpiryTime.DeepCopyInto(&out.ExpiryTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserDelegationTokenStatus.
+func (in *UserDelegationTokenStatus) DeepCopy() *UserDelegationTokenStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UserDelegationTokenStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserTopicGrant) DeepCopyInto(out *UserTopicGrant) {
 	*out = *in
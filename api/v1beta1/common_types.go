@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // RackAwarenessState stores info about rack awareness status
@@ -46,9 +47,21 @@ type SecurityProtocol string
 // Valid values are: required, requested, none
 type SSLClientAuthentication string
 
+// TLSProtocol is a TLS protocol version which can be enabled on a listener.
+// Valid values are: TLSv1.2, TLSv1.3
+// +kubebuilder:validation:Enum=TLSv1.2;TLSv1.3
+type TLSProtocol string
+
+// KeyStoreType is the file format of a keystore or truststore.
+// Valid values are: JKS, PKCS12
+type KeyStoreType string
+
 // PerBrokerConfigurationState holds info about the per-broker configuration state
 type PerBrokerConfigurationState string
 
+// Log4jConfigHash holds the hash of the log4j configuration the logger levels of the running broker have been set from
+type Log4jConfigHash string
+
 // ExternalListenerConfigNames type describes a collection of external listener names
 type ExternalListenerConfigNames []string
 
@@ -199,6 +212,13 @@ type VolumeState struct {
 	CruiseControlVolumeState CruiseControlVolumeState `json:"cruiseControlVolumeState"`
 	// CruiseControlOperationReference refers to the created CruiseControlOperation to execute a CC task
 	CruiseControlOperationReference *corev1.LocalObjectReference `json:"cruiseControlOperationReference,omitempty"`
+	// CruiseControlState holds the state of the CC upscale or downscale of the broker the volume is moved with
+	// +optional
+	CruiseControlState CruiseControlState `json:"cruiseControlState,omitempty"`
+	// LastTransitionTime is the time when either the CC disk rebalance state or the CC upscale or downscale state
+	// of the volume has been changed last
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // BrokerState holds information about broker state
@@ -211,6 +231,8 @@ type BrokerState struct {
 	ConfigurationState ConfigurationState `json:"configurationState"`
 	// PerBrokerConfigurationState holds info about the per-broker (dynamically updatable) config
 	PerBrokerConfigurationState PerBrokerConfigurationState `json:"perBrokerConfigurationState"`
+	// Log4jConfigHash holds the hash of the log4j configuration last applied to the logger levels of the broker
+	Log4jConfigHash Log4jConfigHash `json:"log4jConfigHash,omitempty"`
 	// ExternalListenerConfigNames holds info about what listener config is in use with the broker
 	ExternalListenerConfigNames ExternalListenerConfigNames `json:"externalListenerConfigNames,omitempty"`
 	// Version holds the current version of the broker in semver format
@@ -219,6 +241,28 @@ type BrokerState struct {
 	Image string `json:"image,omitempty"`
 	// Compressed data from broker configuration to restore broker pod in specific cases
 	ConfigurationBackup string `json:"configurationBackup,omitempty"`
+	// LastShutdown is the outcome of the last termination of the broker container observed by the operator
+	// +optional
+	LastShutdown *BrokerShutdownStatus `json:"lastShutdown,omitempty"`
+}
+
+// BrokerShutdownStatus describes whether the broker has completed the controlled shutdown before its container
+// has been terminated
+type BrokerShutdownStatus struct {
+	// Pod is the name of the pod the broker container of which has been terminated
+	Pod string `json:"pod"`
+	// Clean is true when the broker has completed the controlled shutdown before it exited
+	Clean bool `json:"clean"`
+	// ExitCode is the exit code of the broker container
+	ExitCode int32 `json:"exitCode"`
+	// Reason is the reason of the termination of the broker container as reported by the kubelet
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message describes the outcome of the shutdown in a human readable form
+	// +optional
+	Message string `json:"message,omitempty"`
+	// FinishedAt is the time when the broker container has been terminated
+	FinishedAt metav1.Time `json:"finishedAt"`
 }
 
 const (
@@ -310,4 +354,18 @@ const (
 
 	// SSLClientAuthRequired states that the client authentication is required when SSL is enabled
 	SSLClientAuthRequired SSLClientAuthentication = "required"
+	// SSLClientAuthRequested states that the client authentication is requested but not required when SSL is enabled
+	SSLClientAuthRequested SSLClientAuthentication = "requested"
+	// SSLClientAuthNone states that the client authentication is disabled when SSL is enabled
+	SSLClientAuthNone SSLClientAuthentication = "none"
+
+	// TLSProtocolV12 is the TLS 1.2 protocol
+	TLSProtocolV12 TLSProtocol = "TLSv1.2"
+	// TLSProtocolV13 is the TLS 1.3 protocol
+	TLSProtocolV13 TLSProtocol = "TLSv1.3"
+
+	// KeyStoreTypeJKS is the Java KeyStore format
+	KeyStoreTypeJKS KeyStoreType = "JKS"
+	// KeyStoreTypePKCS12 is the PKCS#12 format
+	KeyStoreTypePKCS12 KeyStoreType = "PKCS12"
 )
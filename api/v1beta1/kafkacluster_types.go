@@ -16,7 +16,10 @@ package v1beta1
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"emperror.dev/errors"
 
@@ -26,8 +29,12 @@ import (
 
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/banzaicloud/koperator/api/assets"
 	"github.com/banzaicloud/koperator/api/util"
@@ -36,6 +43,16 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// PodSecurityStandard is a profile of the Kubernetes Pod Security Standards
+type PodSecurityStandard string
+
+const (
+	// PodSecurityStandardPrivileged leaves the security context of the pods as it is configured
+	PodSecurityStandardPrivileged PodSecurityStandard = "privileged"
+	// PodSecurityStandardRestricted defaults the security context of the pods to pass the restricted profile
+	PodSecurityStandardRestricted PodSecurityStandard = "restricted"
+)
+
 const (
 	// DefaultServiceAccountName name used for the various ServiceAccounts
 	DefaultServiceAccountName = "default"
@@ -45,8 +62,40 @@ const (
 	DefaultEnvoyHealthCheckPort = 8080
 	// DefaultEnvoyAdminPort envoy admin port
 	DefaultEnvoyAdminPort = 8081
+	// DefaultRemoteJMXPort default port of the remote JMX endpoint of the brokers
+	DefaultRemoteJMXPort = 9999
 	// DefaultBrokerTerminationGracePeriod default kafka pod termination grace period
 	DefaultBrokerTerminationGracePeriod = 120
+	// DefaultCruiseControlOperationMaxErrorMessageLength default maximum length of the error message of a CruiseControlOperation task
+	DefaultCruiseControlOperationMaxErrorMessageLength = 4096
+	// DefaultCruiseControlOperationMaxSummaryValueLength default maximum length of a summary value of a CruiseControlOperation task
+	DefaultCruiseControlOperationMaxSummaryValueLength = 1024
+	// DefaultSPIFFECSIDriver is the name of the CSI driver exposing the SPIFFE Workload API to the brokers
+	DefaultSPIFFECSIDriver = "csi.spiffe.io"
+	// DefaultSPIFFEHelperImage is the image of the sidecar writing the X.509 SVIDs of the brokers to files
+	DefaultSPIFFEHelperImage = "ghcr.io/spiffe/spiffe-helper:0.8.0"
+	// DefaultSPIFFEAgentSocketName is the name of the socket of the SPIFFE Workload API exposed by the CSI driver
+	DefaultSPIFFEAgentSocketName = "spire-agent.sock"
+	// DefaultKafkaExporterImage is the image of the kafka_exporter deployed for the Kafka cluster
+	DefaultKafkaExporterImage = "danielqsj/kafka-exporter:v1.7.0"
+	// DefaultSPIFFEKeystoreReloadIntervalSeconds is how often the brokers are asked to reload the keystores of the
+	// SPIFFE listeners
+	DefaultSPIFFEKeystoreReloadIntervalSeconds = 300
+	// DefaultZoneBalancingCheckIntervalSeconds is how often the distribution of the partitions across the zones of the
+	// brokers is computed
+	DefaultZoneBalancingCheckIntervalSeconds = 300
+	// DefaultReplicationFactorRepairCheckIntervalSeconds is how often the partitions are checked for replicas on the
+	// permanently lost brokers
+	DefaultReplicationFactorRepairCheckIntervalSeconds = 300
+	// DefaultCapacityScheduleCheckIntervalSeconds is how often the capacity schedule of the cluster is evaluated
+	DefaultCapacityScheduleCheckIntervalSeconds = 60
+	// DefaultOrphanedResourceCheckIntervalSeconds is how often the retention period of the orphaned resources is checked
+	DefaultOrphanedResourceCheckIntervalSeconds = 300
+	// DefaultAnomalyRemediationMinIntervalSeconds is the minimum time between two remediations of the same anomaly type
+	DefaultAnomalyRemediationMinIntervalSeconds = 3600
+	// CruiseControlAnomalyReceiverPath is the path of the alert receiver of the operator the anomalies detected by
+	// Cruise Control are posted to, followed by the namespace and the name of the Kafka cluster
+	CruiseControlAnomalyReceiverPath = "/cruisecontrol/anomalies/"
 
 	// AppLabelKey is used to represent the reserved operator label, "app"
 	AppLabelKey = "app"
@@ -54,6 +103,30 @@ const (
 	KafkaCRLabelKey = "kafka_cr"
 	// BrokerIdLabelKey is used to represent the reserved operator label, "brokerId"
 	BrokerIdLabelKey = "brokerId"
+
+	// RestartAtAnnotationKey is the annotation of the KafkaCluster which triggers a rolling restart of the brokers
+	// when its value is changed; setting it in the brokerAnnotations restarts only the affected brokers
+	RestartAtAnnotationKey = "kafka.banzaicloud.io/restart-at"
+	// PreferredLeaderElectionAnnotationKey is the annotation of the KafkaCluster which triggers a preferred leader
+	// election when its value is changed
+	PreferredLeaderElectionAnnotationKey = "kafka.banzaicloud.io/preferred-leader-election"
+	// ConfigHashAnnotationKey is the annotation of the broker pods holding the hash of the effective configuration
+	// of the broker
+	ConfigHashAnnotationKey = "kafka.banzaicloud.io/config-hash"
+	// DryRunAnnotationKey is the annotation of the KafkaCluster which makes the operator only compute the plan of the
+	// pending spec changes into the status instead of applying them when its value is "true"
+	DryRunAnnotationKey = "kafka.banzaicloud.io/dry-run"
+	// SkipFinalizersAnnotationKey is the annotation of the KafkaTopics, KafkaUsers and CruiseControlOperations which
+	// prevents the operator from adding its finalizer to the resource when its value is "true"
+	SkipFinalizersAnnotationKey = "kafka.banzaicloud.io/skip-finalizers"
+	// ForceCleanupAnnotationKey is the annotation of the KafkaTopics, KafkaUsers and CruiseControlOperations which
+	// makes the operator remove its finalizer without cleaning up the resource in Kafka or Cruise Control when its
+	// value is "true" and the resource is being deleted
+	ForceCleanupAnnotationKey = "kafka.banzaicloud.io/force-cleanup"
+	// SkipMinInSyncReplicasCheckAnnotationKey is the annotation of the KafkaCluster and the CruiseControlOperations
+	// which lets the broker restarts and the remove_broker operations proceed in emergencies even when they would
+	// push partitions below their min.insync.replicas when its value is "true"
+	SkipMinInSyncReplicasCheckAnnotationKey = "kafka.banzaicloud.io/skip-min-insync-replicas-check"
 )
 
 // KafkaClusterSpec defines the desired state of KafkaCluster
@@ -67,16 +140,26 @@ type KafkaClusterSpec struct {
 	ZKAddresses []string `json:"zkAddresses"`
 	// ZKPath specifies the ZooKeeper chroot path as part
 	// of its ZooKeeper connection string which puts its data under some path in the global ZooKeeper namespace.
-	ZKPath                      string                  `json:"zkPath,omitempty"`
-	RackAwareness               *RackAwareness          `json:"rackAwareness,omitempty"`
-	ClusterImage                string                  `json:"clusterImage,omitempty"`
-	ClusterMetricsReporterImage string                  `json:"clusterMetricsReporterImage,omitempty"`
-	ReadOnlyConfig              string                  `json:"readOnlyConfig,omitempty"`
-	ClusterWideConfig           string                  `json:"clusterWideConfig,omitempty"`
-	BrokerConfigGroups          map[string]BrokerConfig `json:"brokerConfigGroups,omitempty"`
-	Brokers                     []Broker                `json:"brokers"`
-	DisruptionBudget            DisruptionBudget        `json:"disruptionBudget,omitempty"`
-	RollingUpgradeConfig        RollingUpgradeConfig    `json:"rollingUpgradeConfig"`
+	ZKPath                      string         `json:"zkPath,omitempty"`
+	RackAwareness               *RackAwareness `json:"rackAwareness,omitempty"`
+	ClusterImage                string         `json:"clusterImage,omitempty"`
+	ClusterMetricsReporterImage string         `json:"clusterMetricsReporterImage,omitempty"`
+	// ImageRegistry replaces the registry of every image used by the operator for the Kafka cluster, e.g. the broker,
+	// Cruise Control, Envoy and JMX exporter images, so they can be pulled from an internal mirror in air-gapped
+	// environments. It may contain a path prefix, e.g. "mirror.internal:5000/dockerhub", images without a registry
+	// are treated as Docker Hub images and are prefixed with it.
+	// +optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+	// ImagePullSecrets are added to the pods of every component of the Kafka cluster in addition to the image
+	// pull secrets of the components
+	// +optional
+	ImagePullSecrets     []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	ReadOnlyConfig       string                        `json:"readOnlyConfig,omitempty"`
+	ClusterWideConfig    string                        `json:"clusterWideConfig,omitempty"`
+	BrokerConfigGroups   map[string]BrokerConfig       `json:"brokerConfigGroups,omitempty"`
+	Brokers              []Broker                      `json:"brokers"`
+	DisruptionBudget     DisruptionBudget              `json:"disruptionBudget,omitempty"`
+	RollingUpgradeConfig RollingUpgradeConfig          `json:"rollingUpgradeConfig"`
 	// +kubebuilder:validation:Enum=envoy;istioingress
 	// IngressController specifies the type of the ingress controller to be used for external listeners. The `istioingress` ingress controller type requires the `spec.istioControlPlane` field to be populated as well.
 	IngressController string `json:"ingressController,omitempty"`
@@ -94,8 +177,15 @@ type KafkaClusterSpec struct {
 	// Envs defines environment variables for Kafka broker Pods.
 	// Adding the "+" prefix to the name prepends the value to that environment variable instead of overwriting it.
 	// Add the "+" suffix to append.
-	Envs                    []corev1.EnvVar `json:"envs,omitempty"`
-	KubernetesClusterDomain string          `json:"kubernetesClusterDomain,omitempty"`
+	Envs []corev1.EnvVar `json:"envs,omitempty"`
+	// EnvFrom populates environment variables of the Kafka broker Pods from Secrets and ConfigMaps.
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	// JVMOptions defines additional JVM flags for the Kafka brokers, the flags of the broker config groups and the
+	// brokers are added after them.
+	// +optional
+	JVMOptions              *JVMOptions `json:"jvmOptions,omitempty"`
+	KubernetesClusterDomain string      `json:"kubernetesClusterDomain,omitempty"`
 	// ClientSSLCertSecret is a reference to the Kubernetes secret where custom client SSL certificate can be provided.
 	// It will be used by the koperator, cruise control, cruise control metrics reporter
 	// to communicate on SSL with that internal listener which is used for interbroker communication.
@@ -103,6 +193,124 @@ type KafkaClusterSpec struct {
 	// The secret must contain the keystore, truststore jks files and the password for them in base64 encoded format
 	// under the keystore.jks, truststore.jks, password data fields.
 	ClientSSLCertSecret *corev1.LocalObjectReference `json:"clientSSLCertSecret,omitempty"`
+	// PodSecurityStandard is the Pod Security Standard the pods of the Kafka cluster need to comply with.
+	// When it is restricted the security context fields which are not set explicitly on the brokers, Cruise Control
+	// and Envoy are defaulted to pass the restricted profile. The images need to run as a non-root user in that case,
+	// which can be set by the runAsUser field of the security contexts when it is not declared by the image.
+	// +kubebuilder:validation:Enum=privileged;restricted
+	// +optional
+	PodSecurityStandard PodSecurityStandard `json:"podSecurityStandard,omitempty"`
+	// NetworkPolicyConfig defines the NetworkPolicies which restrict the traffic of the Kafka cluster
+	// +optional
+	NetworkPolicyConfig *NetworkPolicyConfig `json:"networkPolicyConfig,omitempty"`
+	// SPIFFEConfig configures how the brokers obtain their X.509 SVIDs from SPIRE for the listeners which have
+	// 'spiffe' enabled
+	// +optional
+	SPIFFEConfig *SPIFFEConfig `json:"spiffeConfig,omitempty"`
+	// KafkaExporterConfig defines the kafka_exporter deployment which exposes the consumer group lag and topic level
+	// metrics of the Kafka cluster
+	// +optional
+	KafkaExporterConfig *KafkaExporterConfig `json:"kafkaExporterConfig,omitempty"`
+	// DeletionPolicy controls what happens to the data of the brokers when the KafkaCluster is deleted. With Delete,
+	// the default, the PersistentVolumeClaims of the brokers are garbage collected together with the KafkaCluster.
+	// With Retain the PersistentVolumeClaims and the topics stored on them are kept, so a KafkaCluster created again
+	// with the same name adopts them
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// FinalSnapshotHook defines a Job which is run to completion before the KafkaCluster is torn down, while the
+	// brokers are still running, e.g. to back up the topics or snapshot the volumes of the brokers
+	// +optional
+	FinalSnapshotHook *HookJob `json:"finalSnapshotHook,omitempty"`
+	// BrokerIDPolicy controls which IDs can be given to the brokers added to the cluster. With ReuseLowestFree, the
+	// default, the IDs of the removed brokers can be used again. With AlwaysIncrement the added brokers must have a
+	// higher ID than any broker the cluster has ever had, the highest ID is tracked in the status
+	// +kubebuilder:validation:Enum=ReuseLowestFree;AlwaysIncrement
+	// +optional
+	BrokerIDPolicy BrokerIDPolicy `json:"brokerIdPolicy,omitempty"`
+	// DriftPolicy controls how the out-of-band changes of the broker pods, the broker ConfigMaps and the Cruise Control
+	// resources are handled. The changes are always reported as Events, with Enforce, the default, they are reverted,
+	// with Warn they are kept until the desired state of the resource changes
+	// +kubebuilder:validation:Enum=Enforce;Warn
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+	// AuthorizationConfig configures the authorizer of the brokers and the super users bypassing it. The principals
+	// used by the brokers, the operator and Cruise Control are always added to the super users.
+	// +optional
+	AuthorizationConfig *AuthorizationConfig `json:"authorizationConfig,omitempty"`
+	// DelegationTokenConfig enables the delegation token based authentication of the clients
+	// +optional
+	DelegationTokenConfig *DelegationTokenConfig `json:"delegationTokenConfig,omitempty"`
+	// ClientQuotaConfig defines the cluster default quotas of the clients, applied as dynamic configs, and the client
+	// quota callback plugin of the brokers
+	// +optional
+	ClientQuotaConfig *ClientQuotaConfig `json:"clientQuotaConfig,omitempty"`
+	// RemoteJMXConfig enables the password authenticated remote JMX endpoint of the brokers
+	// +optional
+	RemoteJMXConfig *RemoteJMXConfig `json:"remoteJmxConfig,omitempty"`
+	// ZoneBalancing enables reporting the distribution of the partition replicas and leaders across the zones
+	// (broker.rack) of the brokers and optionally rebalancing the cluster when it is skewed
+	// +optional
+	ZoneBalancing *ZoneBalancingConfig `json:"zoneBalancing,omitempty"`
+	// ReplicationFactorRepair enables detecting the partitions whose replication factor dropped below the intended
+	// one because their replicas were hosted by brokers which have been removed from the cluster without moving
+	// their data, and restoring it with Cruise Control
+	// +optional
+	ReplicationFactorRepair *ReplicationFactorRepairConfig `json:"replicationFactorRepair,omitempty"`
+	// ParkedBrokerConfigGroups lists the broker config groups whose brokers are scaled to zero. The partition
+	// leaderships of the brokers are demoted and their replicas are moved to the remaining brokers by Cruise Control
+	// before their pods and volumes are deleted. Removing a group from the list adds its brokers back to the cluster.
+	// +optional
+	ParkedBrokerConfigGroups []string `json:"parkedBrokerConfigGroups,omitempty"`
+	// CapacitySchedule makes the number of brokers of the broker config groups follow a weekly calendar. The operator
+	// adds brokers to or removes the brokers with the highest IDs from the spec, the partitions are moved by Cruise
+	// Control the same way as when the brokers are changed by hand.
+	// +optional
+	CapacitySchedule *CapacitySchedule `json:"capacitySchedule,omitempty"`
+	// BrokerFailurePolicy defines how the brokers which have been down for longer than a grace period are handled.
+	// The brokers are not tracked when it is not set.
+	// +optional
+	BrokerFailurePolicy *BrokerFailurePolicy `json:"brokerFailurePolicy,omitempty"`
+	// VolumeSnapshots makes the operator create CSI VolumeSnapshots of the PersistentVolumeClaims of the brokers
+	// before the given operations, as a last-resort recovery path. The snapshots are not owned by the KafkaCluster
+	// so they are kept when the cluster is deleted.
+	// +optional
+	VolumeSnapshots *VolumeSnapshotConfig `json:"volumeSnapshots,omitempty"`
+	// OrphanedResourceCleanup makes the operator delete the PersistentVolumeClaims and Services left behind by the
+	// brokers which have been removed from the cluster, once they have been orphaned for longer than the retention
+	// period. The orphaned resources are kept when it is not set.
+	// +optional
+	OrphanedResourceCleanup *OrphanedResourceCleanupConfig `json:"orphanedResourceCleanup,omitempty"`
+	// CrossNamespaceReferences restricts the namespaces whose KafkaUsers, KafkaTopics, KafkaBrokerDecommissions and
+	// KafkaBrokerReplacements can reference the cluster. The ones in the namespace of the cluster can always reference it, the ones in any
+	// namespace can when it is not set.
+	// +optional
+	CrossNamespaceReferences *CrossNamespaceReferences `json:"crossNamespaceReferences,omitempty"`
+	// ServiceBinding exposes the cluster as a provisioned service of the Service Binding specification
+	// (servicebinding.io), the Secret referenced from the status can be projected into the workloads by the
+	// service binding implementations.
+	// +optional
+	ServiceBinding *ClusterServiceBinding `json:"serviceBinding,omitempty"`
+}
+
+// ClusterServiceBinding defines the binding Secret of the KafkaCluster. The Secret holds the bootstrap servers and the
+// security protocol of the listener, the credentials of the clients are exposed by the KafkaUsers.
+type ClusterServiceBinding struct {
+	// Listener is the name of the listener the binding points to. The listener used for the inner broker
+	// communication is used when it is not set.
+	// +optional
+	Listener string `json:"listener,omitempty"`
+}
+
+// CrossNamespaceReferences is the allow-list of the namespaces whose KafkaUsers, KafkaTopics,
+// KafkaBrokerDecommissions and KafkaBrokerReplacements can reference the cluster. No other namespace is allowed when both fields are empty.
+type CrossNamespaceReferences struct {
+	// NamespaceSelector selects the allowed namespaces by their labels
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Namespaces lists the names of further allowed namespaces
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
 }
 
 // KafkaClusterStatus defines the observed state of KafkaCluster
@@ -113,6 +321,273 @@ type KafkaClusterStatus struct {
 	RollingUpgrade           RollingUpgradeStatus     `json:"rollingUpgradeStatus,omitempty"`
 	AlertCount               int                      `json:"alertCount"`
 	ListenerStatuses         ListenerStatuses         `json:"listenerStatuses,omitempty"`
+	// CruiseControlOperations is the aggregated view of the CruiseControlOperations of the cluster
+	// +optional
+	CruiseControlOperations *CruiseControlOperationSummary `json:"cruiseControlOperations,omitempty"`
+	// RetiredBrokerIDs is the list of broker IDs which have been decommissioned by a KafkaBrokerDecommission
+	// +optional
+	RetiredBrokerIDs []int32 `json:"retiredBrokerIds,omitempty"`
+	// PreferredLeaderElection is the value of the preferred-leader-election annotation which has been processed last
+	// +optional
+	PreferredLeaderElection string `json:"preferredLeaderElection,omitempty"`
+	// HighestBrokerID is the highest broker ID the cluster has ever had
+	// +optional
+	HighestBrokerID *int32 `json:"highestBrokerId,omitempty"`
+	// ChangePlan is the plan of the pending spec changes computed while the dry-run annotation is set
+	// +optional
+	ChangePlan *KafkaClusterChangePlan `json:"changePlan,omitempty"`
+	// ObservedGeneration is the generation of the KafkaCluster which has been reconciled last.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// DiskUsage is the disk usage of the log directories of the brokers keyed by broker ID as reported by the
+	// DescribeLogDirs Admin API when the cluster has been reconciled last
+	// +optional
+	DiskUsage map[string][]LogDirUsage `json:"diskUsage,omitempty"`
+	// ZoneBalance is the distribution of the partition replicas and leaders across the zones of the brokers
+	// +optional
+	ZoneBalance *ZoneBalanceStatus `json:"zoneBalance,omitempty"`
+	// ReplicationFactorRepair is the state of the partitions which lost replicas with the removed brokers
+	// +optional
+	ReplicationFactorRepair *ReplicationFactorRepairStatus `json:"replicationFactorRepair,omitempty"`
+	// BrokerGroupParking is the state of the parking of the broker config groups keyed by the name of the group
+	// +optional
+	BrokerGroupParking map[string]BrokerGroupParkingStatus `json:"brokerGroupParking,omitempty"`
+	// CapacitySchedule is the state of the capacity schedule of the cluster
+	// +optional
+	CapacitySchedule *CapacityScheduleStatus `json:"capacitySchedule,omitempty"`
+	// FailedBrokers are the brokers which are down, keyed by the broker ID
+	// +optional
+	FailedBrokers map[string]FailedBrokerStatus `json:"failedBrokers,omitempty"`
+	// VolumeSnapshots are the VolumeSnapshots which have been created of the volumes of the brokers
+	// +optional
+	VolumeSnapshots []VolumeSnapshotReference `json:"volumeSnapshots,omitempty"`
+	// OrphanedResources are the PersistentVolumeClaims and Services of the removed brokers waiting for the retention
+	// period of the orphaned resource cleanup to pass
+	// +optional
+	OrphanedResources []OrphanedResource `json:"orphanedResources,omitempty"`
+	// Conditions describe the state of the KafkaCluster which is not captured by the other fields of the status
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Binding references the Secret of the cluster which can be bound to workloads as defined by the Service Binding
+	// specification
+	// +optional
+	Binding *corev1.LocalObjectReference `json:"binding,omitempty"`
+}
+
+// CapacityScheduleStatus describes the rule of the capacity schedule in effect and the outcome of its evaluation
+type CapacityScheduleStatus struct {
+	// ActiveRule is the name of the rule of the schedule in effect, empty when no rule matches the current time
+	// +optional
+	ActiveRule string `json:"activeRule,omitempty"`
+	// BrokerCounts are the numbers of brokers of the broker config groups required by the active rule
+	// +optional
+	BrokerCounts map[string]int32 `json:"brokerCounts,omitempty"`
+	// LastScaleTime is the time when the brokers of the cluster have been changed by the schedule last
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+	// Message explains why the brokers have not been scaled to the counts of the active rule yet
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// FailedBrokerStatus describes a broker which is down
+type FailedBrokerStatus struct {
+	// DownSince is the time when the broker has been found down first
+	DownSince metav1.Time `json:"downSince"`
+	// GracePeriodExceeded is true when the broker has been down for longer than the grace period of the broker
+	// failure policy and it has been reported
+	// +optional
+	GracePeriodExceeded bool `json:"gracePeriodExceeded,omitempty"`
+	// ReplacedBy is the ID of the broker added to the spec in place of the failed broker
+	// +optional
+	ReplacedBy *int32 `json:"replacedBy,omitempty"`
+}
+
+const (
+	// BrokersShutDownCleanlyCondition is the type of the condition which states whether the brokers have completed
+	// the controlled shutdown when their containers have been terminated last
+	BrokersShutDownCleanlyCondition = "BrokersShutDownCleanly"
+	// ControlledShutdownReason is the reason of the BrokersShutDownCleanly condition when every broker has completed
+	// the controlled shutdown
+	ControlledShutdownReason = "ControlledShutdown"
+	// UncleanShutdownReason is the reason of the BrokersShutDownCleanly condition when a broker has been terminated
+	// without completing the controlled shutdown
+	UncleanShutdownReason = "UncleanShutdown"
+	// MinInSyncReplicasPreservedCondition is the type of the condition which states whether the rolling restart of
+	// the brokers can proceed without pushing partitions below their min.insync.replicas
+	MinInSyncReplicasPreservedCondition = "MinInSyncReplicasPreserved"
+	// BrokerRestartAllowedReason is the reason of the MinInSyncReplicasPreserved condition when restarting the next
+	// broker keeps the partitions at or above their min.insync.replicas
+	BrokerRestartAllowedReason = "RestartAllowed"
+	// BrokerRestartBlockedReason is the reason of the MinInSyncReplicasPreserved condition when the rolling restart is
+	// blocked as restarting the next broker would push partitions below their min.insync.replicas
+	BrokerRestartBlockedReason = "RestartBlocked"
+	// BootstrappingCondition is the type of the condition which states whether the cluster is being created and has
+	// not reached the running state yet. The condition is only set on clusters created with no status at all.
+	BootstrappingCondition = "Bootstrapping"
+	// ClusterCreatedReason is the reason of the Bootstrapping condition while the new cluster is being created
+	ClusterCreatedReason = "ClusterCreated"
+	// ClusterRunningReason is the reason of the Bootstrapping condition once the cluster has reached the running state
+	ClusterRunningReason = "ClusterRunning"
+)
+
+// BrokerGroupParkingState is the state of the parking of a broker config group
+type BrokerGroupParkingState string
+
+const (
+	// BrokerGroupDemoting states that the partition leaderships are being moved off from the brokers of the group
+	BrokerGroupDemoting BrokerGroupParkingState = "Demoting"
+	// BrokerGroupDraining states that the partition replicas of the brokers of the group are being moved to the
+	// remaining brokers before the brokers are deleted
+	BrokerGroupDraining BrokerGroupParkingState = "Draining"
+	// BrokerGroupParked states that the brokers of the group are scaled to zero
+	BrokerGroupParked BrokerGroupParkingState = "Parked"
+	// BrokerGroupUnparking states that the brokers of the group are being added back to the cluster
+	BrokerGroupUnparking BrokerGroupParkingState = "Unparking"
+)
+
+// BrokerGroupParkingStatus describes the parking of a broker config group
+type BrokerGroupParkingStatus struct {
+	// State is the state of the parking of the group
+	State BrokerGroupParkingState `json:"state"`
+	// BrokerIDs are the IDs of the brokers of the group which have been parked
+	// +optional
+	BrokerIDs []int32 `json:"brokerIds,omitempty"`
+	// DemoteOperation is the name of the CruiseControlOperation demoting the brokers of the group
+	// +optional
+	DemoteOperation string `json:"demoteOperation,omitempty"`
+}
+
+// ZoneBalanceStatus describes the distribution of the partition replicas and leaders across the zones of the brokers.
+// The skew of a zone is its deviation in percent from the share of the replicas or leaders proportional to the
+// number of its brokers.
+type ZoneBalanceStatus struct {
+	// ComputedAt is the time when the distribution has been computed
+	ComputedAt metav1.Time `json:"computedAt"`
+	// Zones are the loads of the zones ordered by name
+	// +optional
+	Zones []ZoneLoad `json:"zones,omitempty"`
+	// ReplicaSkewPercent is the highest replica skew of the zones
+	ReplicaSkewPercent int32 `json:"replicaSkewPercent"`
+	// LeaderSkewPercent is the highest leader skew of the zones
+	LeaderSkewPercent int32 `json:"leaderSkewPercent"`
+	// RebalanceOperation is the name of the CruiseControlOperation created last to restore the balance of the zones
+	// +optional
+	RebalanceOperation string `json:"rebalanceOperation,omitempty"`
+}
+
+// ReplicationFactorRepairStatus describes the partitions which have replicas on lost brokers, i.e. brokers which are
+// not alive and are not part of the spec of the cluster anymore
+type ReplicationFactorRepairStatus struct {
+	// CheckedAt is the time when the partitions have been checked
+	CheckedAt metav1.Time `json:"checkedAt"`
+	// LostBrokers are the IDs of the lost brokers which still host replicas
+	// +optional
+	LostBrokers []int32 `json:"lostBrokers,omitempty"`
+	// UnderReplicatedPartitions is the number of partitions whose alive replicas are fewer than the intended
+	// replication factor due to the lost brokers
+	UnderReplicatedPartitions int32 `json:"underReplicatedPartitions"`
+	// Topics are the names of the topics of the under-replicated partitions ordered by name
+	// +optional
+	Topics []string `json:"topics,omitempty"`
+	// RepairOperation is the name of the CruiseControlOperation created last to restore the replication factor
+	// +optional
+	RepairOperation string `json:"repairOperation,omitempty"`
+}
+
+// ZoneLoad is the number of brokers, partition replicas and leaders of a zone
+type ZoneLoad struct {
+	// Zone is the broker.rack of the brokers, empty for the brokers without rack
+	Zone string `json:"zone"`
+	// Brokers is the number of brokers in the zone
+	Brokers int32 `json:"brokers"`
+	// Replicas is the number of partition replicas hosted by the brokers of the zone
+	Replicas int32 `json:"replicas"`
+	// Leaders is the number of partition leaders hosted by the brokers of the zone
+	Leaders int32 `json:"leaders"`
+}
+
+// LogDirUsage describes the disk usage of a log directory of a broker
+type LogDirUsage struct {
+	// Path is the absolute path of the log directory
+	Path string `json:"path"`
+	// UsedBytes is the total size of the partition replicas stored in the log directory
+	UsedBytes int64 `json:"usedBytes"`
+	// CapacityBytes is the requested storage size of the persistent volume claim of the log directory
+	// +optional
+	CapacityBytes *int64 `json:"capacityBytes,omitempty"`
+	// Offline is true when the log directory cannot be used due to storage errors
+	// +optional
+	Offline bool `json:"offline,omitempty"`
+}
+
+// KafkaClusterChangePlan describes the changes the operator would apply to reach the desired state of the KafkaCluster
+type KafkaClusterChangePlan struct {
+	// ObservedGeneration is the generation of the KafkaCluster the plan has been computed for
+	ObservedGeneration int64 `json:"observedGeneration"`
+	// ComputedAt is the time when the plan has been computed
+	ComputedAt metav1.Time `json:"computedAt"`
+	// BrokersToRestart are the brokers whose pods would be recreated by a rolling upgrade
+	// +optional
+	BrokersToRestart []int32 `json:"brokersToRestart,omitempty"`
+	// BrokersToAdd are the brokers of the spec which do not have a pod yet
+	// +optional
+	BrokersToAdd []int32 `json:"brokersToAdd,omitempty"`
+	// BrokersToRemove are the brokers which have a pod but are not part of the spec anymore
+	// +optional
+	BrokersToRemove []int32 `json:"brokersToRemove,omitempty"`
+	// CruiseControlOperations are the CruiseControlOperations which would be created
+	// +optional
+	CruiseControlOperations []PlannedCruiseControlOperation `json:"cruiseControlOperations,omitempty"`
+	// Resources are the resources which would be created or updated
+	// +optional
+	Resources []PlannedResourceChange `json:"resources,omitempty"`
+	// Error is the reason why the plan could not be computed completely
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// PlannedCruiseControlOperation describes a CruiseControlOperation which would be created
+type PlannedCruiseControlOperation struct {
+	// Operation is the type of the Cruise Control task, e.g. add_broker
+	Operation string `json:"operation"`
+	// BrokerIDs are the brokers the operation is executed for
+	BrokerIDs []int32 `json:"brokerIds"`
+}
+
+// PlannedResourceChange describes a resource which would be created or updated
+type PlannedResourceChange struct {
+	// Kind of the resource, e.g. ConfigMap
+	Kind string `json:"kind"`
+	// Name of the resource
+	Name string `json:"name"`
+	// Action is either create or update
+	Action string `json:"action"`
+}
+
+// CruiseControlOperationSummary summarizes the CruiseControlOperations of the cluster
+type CruiseControlOperationSummary struct {
+	// Pending is the number of CruiseControlOperations waiting for execution
+	Pending int32 `json:"pending"`
+	// InProgress is the number of CruiseControlOperations being executed by Cruise Control
+	InProgress int32 `json:"inProgress"`
+	// Failed is the number of CruiseControlOperations whose task completed with error
+	// and which are either waiting for retry or paused
+	Failed int32 `json:"failed"`
+	// Executing refers to the CruiseControlOperation which is being executed by Cruise Control
+	// +optional
+	Executing *ExecutingCruiseControlOperation `json:"executing,omitempty"`
+	// LastCompletedRebalance is the time when a rebalance operation has been completed last
+	// +optional
+	LastCompletedRebalance *metav1.Time `json:"lastCompletedRebalance,omitempty"`
+}
+
+// ExecutingCruiseControlOperation identifies the CruiseControlOperation being executed by Cruise Control
+type ExecutingCruiseControlOperation struct {
+	// Name of the CruiseControlOperation
+	Name string `json:"name"`
+	// Operation is the type of the Cruise Control task, e.g. add_broker
+	Operation string `json:"operation"`
 }
 
 // RollingUpgradeStatus defines status of rolling upgrade
@@ -130,6 +605,214 @@ type RollingUpgradeConfig struct {
 	// distinct broker replicas with either offline replicas or out of sync replicas and the number of alerts triggered by
 	// alerts with 'rollingupgrade'
 	FailureThreshold int `json:"failureThreshold"`
+	// BrokerReadinessGate when set holds back the rolling upgrade until every restarted broker has rejoined the ISR of
+	// its partitions, regardless of the FailureThreshold, so that the next broker is not rolled while the cluster
+	// has under-replicated partitions
+	// +optional
+	BrokerReadinessGate *BrokerReadinessGate `json:"brokerReadinessGate,omitempty"`
+	// PreUpgradeHook defines a Job which has to complete successfully before the first broker of a rolling upgrade is
+	// restarted, e.g. to run compatibility checks or to notify the clients. A failed Job holds back the rolling upgrade
+	// until it is deleted to be retried or the hook is removed.
+	// +optional
+	PreUpgradeHook *HookJob `json:"preUpgradeHook,omitempty"`
+	// PostUpgradeHook defines a Job which is run once every broker of a rolling upgrade has been restarted, e.g. to run
+	// smoke tests. The cluster stays in rolling upgrade state until the Job completes successfully.
+	// +optional
+	PostUpgradeHook *HookJob `json:"postUpgradeHook,omitempty"`
+}
+
+// DeletionPolicy defines what happens to the data of the brokers when the KafkaCluster is deleted
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the PersistentVolumeClaims of the brokers together with the KafkaCluster
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the PersistentVolumeClaims of the brokers when the KafkaCluster is deleted
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// BrokerIDPolicy defines which IDs can be given to the brokers added to the cluster
+type BrokerIDPolicy string
+
+const (
+	// BrokerIDPolicyReuseLowestFree allows the IDs of the removed brokers to be used again
+	BrokerIDPolicyReuseLowestFree BrokerIDPolicy = "ReuseLowestFree"
+	// BrokerIDPolicyAlwaysIncrement requires the added brokers to have a higher ID than any former broker
+	BrokerIDPolicyAlwaysIncrement BrokerIDPolicy = "AlwaysIncrement"
+)
+
+// AuthorizerType defines the authorizer plugin of the brokers
+type AuthorizerType string
+
+const (
+	// AuthorizerTypeACL is the ACL based authorizer shipped with Kafka
+	AuthorizerTypeACL AuthorizerType = "ACL"
+	// AuthorizerTypeOPA is the Open Policy Agent authorizer plugin
+	AuthorizerTypeOPA AuthorizerType = "OPA"
+	// AuthorizerTypeCustom is an authorizer plugin given by its class name
+	AuthorizerTypeCustom AuthorizerType = "Custom"
+)
+
+// AuthorizationConfig defines the authorizer of the brokers. The brokers have to communicate over an SSL listener
+// without SPIFFE when it is set, as only the principals of its certificates are made super users.
+type AuthorizationConfig struct {
+	// Authorizer selects the authorizer plugin of the brokers, defaults to ACL
+	// +kubebuilder:validation:Enum=ACL;OPA;Custom
+	// +optional
+	Authorizer AuthorizerType `json:"authorizer,omitempty"`
+	// CustomAuthorizerClassName is the class name of the authorizer, required when the authorizer is Custom
+	// +optional
+	CustomAuthorizerClassName string `json:"customAuthorizerClassName,omitempty"`
+	// SuperUsers lists the principals which are allowed to perform any operation, e.g. User:admin
+	// +optional
+	SuperUsers []string `json:"superUsers,omitempty"`
+	// OPA configures the Open Policy Agent authorizer plugin, required when the authorizer is OPA
+	// +optional
+	OPA *OPAAuthorizerConfig `json:"opa,omitempty"`
+	// PluginConfig holds additional configuration properties of the authorizer plugin
+	// +optional
+	PluginConfig map[string]string `json:"pluginConfig,omitempty"`
+}
+
+// OPAAuthorizerConfig defines the configuration of the Open Policy Agent authorizer plugin
+type OPAAuthorizerConfig struct {
+	// URL of the OPA policy deciding on the requests, e.g. http://opa:8181/v1/data/kafka/authz/allow
+	URL string `json:"url"`
+	// AllowOnError allows the requests when OPA cannot be reached
+	// +optional
+	AllowOnError *bool `json:"allowOnError,omitempty"`
+	// CacheExpireAfterSeconds is the time the decisions of OPA are cached for
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CacheExpireAfterSeconds *int64 `json:"cacheExpireAfterSeconds,omitempty"`
+}
+
+// DelegationTokenConfig defines the delegation token settings of the brokers
+type DelegationTokenConfig struct {
+	// SecretKeyRef selects the key of the Secret holding the secret the brokers use to generate and verify the
+	// delegation tokens. The Secret must be in the namespace of the KafkaCluster. Changing the secret invalidates the
+	// issued tokens. The brokers read the secret from the mounted Secret through the DirectoryConfigProvider which
+	// requires Kafka 2.7 or later.
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+	// MaxLifetimeSeconds is the lifetime of the delegation tokens which they cannot be renewed beyond, defaults to
+	// 7 days
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxLifetimeSeconds *int64 `json:"maxLifetimeSeconds,omitempty"`
+	// ExpiryTimeSeconds is the validity of the delegation tokens which are not renewed, defaults to 1 day
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ExpiryTimeSeconds *int64 `json:"expiryTimeSeconds,omitempty"`
+}
+
+// RemoteJMXConfig defines the remote JMX endpoint of the brokers. The operator generates the credentials of each
+// broker into the <cluster name>-<broker id>-jmx Secret under the username and password keys, the credentials grant
+// read-only access. The Prometheus JMX exporter runs as a Java agent inside the broker process so it does not use the
+// remote endpoint.
+type RemoteJMXConfig struct {
+	// Enabled turns on the remote JMX endpoint of the brokers
+	Enabled bool `json:"enabled"`
+	// Port is the port of the remote JMX endpoint, it defaults to 9999
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+	// SSL protects the remote JMX endpoint with the server certificate of the SSL listener used for inner broker
+	// communication
+	// +optional
+	SSL bool `json:"ssl,omitempty"`
+}
+
+// GetPort returns the port of the remote JMX endpoint
+func (c *RemoteJMXConfig) GetPort() int32 {
+	if c.Port != nil {
+		return *c.Port
+	}
+	return DefaultRemoteJMXPort
+}
+
+// IsRemoteJMXEnabled returns true when the remote JMX endpoint of the brokers is enabled
+func (kSpec *KafkaClusterSpec) IsRemoteJMXEnabled() bool {
+	return kSpec.RemoteJMXConfig != nil && kSpec.RemoteJMXConfig.Enabled
+}
+
+// ClientQuotaConfig defines the cluster default quotas of the clients and the client quota callback plugin
+type ClientQuotaConfig struct {
+	// UserDefaults are the quotas of the users which have no quota of their own. The quotas which are not set are
+	// removed from the default user entity.
+	// +optional
+	UserDefaults *ClientQuotas `json:"userDefaults,omitempty"`
+	// ClientIDDefaults are the quotas of the client IDs which have no quota of their own. The quotas which are not set
+	// are removed from the default client ID entity.
+	// +optional
+	ClientIDDefaults *ClientQuotas `json:"clientIdDefaults,omitempty"`
+	// CallbackClassName is the class name of the client quota callback plugin of the brokers, e.g. a static quota
+	// plugin. Changing it restarts the brokers.
+	// +optional
+	CallbackClassName string `json:"callbackClassName,omitempty"`
+	// PluginConfig holds additional configuration properties of the client quota callback plugin
+	// +optional
+	PluginConfig map[string]string `json:"pluginConfig,omitempty"`
+}
+
+// ClientQuotas defines the produce, fetch and request quotas of a client entity
+type ClientQuotas struct {
+	// ProducerByteRate is the produce rate in bytes per second per broker
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProducerByteRate *int64 `json:"producerByteRate,omitempty"`
+	// ConsumerByteRate is the fetch rate in bytes per second per broker
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ConsumerByteRate *int64 `json:"consumerByteRate,omitempty"`
+	// RequestPercentage is the percentage of the request handler and network threads time per quota window
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RequestPercentage *int32 `json:"requestPercentage,omitempty"`
+}
+
+// DriftPolicy defines how the out-of-band changes of the resources managed by the operator are handled
+type DriftPolicy string
+
+const (
+	// DriftPolicyEnforce reverts the out-of-band changes of the managed resources
+	DriftPolicyEnforce DriftPolicy = "Enforce"
+	// DriftPolicyWarn only reports the out-of-band changes of the managed resources
+	DriftPolicyWarn DriftPolicy = "Warn"
+)
+
+// HookJob defines a Job which is run to completion at a step of the lifecycle of the KafkaCluster, like before it is
+// torn down or around its rolling upgrades
+type HookJob struct {
+	// Template of the pods of the Job. The restart policy defaults to Never.
+	Template corev1.PodTemplateSpec `json:"template"`
+	// BackoffLimit is the number of retries before the Job is considered failed, defaults to 3
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// ActiveDeadlineSeconds limits the duration of the Job
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+}
+
+// BrokerReadinessGateMethod defines how the under-replicated partitions are queried by the broker readiness gate
+type BrokerReadinessGateMethod string
+
+const (
+	// BrokerReadinessGateAdminAPI queries the partition metadata through the Kafka Admin API, it also requires the
+	// brokers to be registered in the cluster with all of their log directories online
+	BrokerReadinessGateAdminAPI BrokerReadinessGateMethod = "AdminAPI"
+	// BrokerReadinessGateJMX reads the UnderReplicatedPartitions metric exposed by the Prometheus JMX exporter of the brokers
+	BrokerReadinessGateJMX BrokerReadinessGateMethod = "JMX"
+)
+
+// BrokerReadinessGate defines the readiness gate of the brokers during rolling upgrades
+type BrokerReadinessGate struct {
+	// Method defines how the under-replicated partitions are queried, defaults to AdminAPI
+	// +kubebuilder:validation:Enum=AdminAPI;JMX
+	// +optional
+	Method BrokerReadinessGateMethod `json:"method,omitempty"`
 }
 
 // DisruptionBudget defines the configuration for PodDisruptionBudget where the workload is managed by the kafka-operator
@@ -162,6 +845,40 @@ type Broker struct {
 	BrokerConfig      *BrokerConfig `json:"brokerConfig,omitempty"`
 }
 
+// JVMOptions defines additional JVM flags which are appended to the KAFKA_OPTS environment variable of the Kafka
+// brokers. The values may reference environment variables of the broker container in the $(VAR_NAME) format, e.g.
+// variables populated from Secrets, ConfigMaps or the downward API.
+type JVMOptions struct {
+	// Flags are passed to the JVM as they are, e.g. -XX:+HeapDumpOnOutOfMemoryError
+	// +optional
+	Flags []string `json:"flags,omitempty"`
+	// SystemProperties are passed to the JVM as -D<name>=<value> flags
+	// +optional
+	SystemProperties map[string]string `json:"systemProperties,omitempty"`
+}
+
+// IsEmpty returns true when no JVM flags are defined
+func (o *JVMOptions) IsEmpty() bool {
+	return o == nil || (len(o.Flags) == 0 && len(o.SystemProperties) == 0)
+}
+
+// String returns the JVM flags separated by spaces, the system properties follow the flags in the order of their names
+func (o *JVMOptions) String() string {
+	if o.IsEmpty() {
+		return ""
+	}
+	flags := append([]string{}, o.Flags...)
+	names := make([]string, 0, len(o.SystemProperties))
+	for name := range o.SystemProperties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flags = append(flags, fmt.Sprintf("-D%s=%s", name, o.SystemProperties[name]))
+	}
+	return strings.Join(flags, " ")
+}
+
 // BrokerConfig defines the broker configuration
 type BrokerConfig struct {
 	Image                string                        `json:"image,omitempty"`
@@ -172,11 +889,22 @@ type BrokerConfig struct {
 	Resources            *corev1.ResourceRequirements  `json:"resourceRequirements,omitempty"`
 	ImagePullSecrets     []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 	NodeSelector         map[string]string             `json:"nodeSelector,omitempty"`
-	Tolerations          []corev1.Toleration           `json:"tolerations,omitempty"`
-	KafkaHeapOpts        string                        `json:"kafkaHeapOpts,omitempty"`
-	KafkaJVMPerfOpts     string                        `json:"kafkaJvmPerfOpts,omitempty"`
+	// Architecture is the CPU architecture of the nodes the brokers are scheduled to, it is added to the node selector
+	// as the kubernetes.io/arch label unless the node selector sets that label explicitly. It allows moving a broker
+	// group to a node pool of a different architecture together with an image built for it.
+	// +kubebuilder:validation:Enum=amd64;arm64
+	// +optional
+	Architecture     string              `json:"architecture,omitempty"`
+	Tolerations      []corev1.Toleration `json:"tolerations,omitempty"`
+	KafkaHeapOpts    string              `json:"kafkaHeapOpts,omitempty"`
+	KafkaJVMPerfOpts string              `json:"kafkaJvmPerfOpts,omitempty"`
 	// Override for the default log4j configuration
 	Log4jConfig string `json:"log4jConfig,omitempty"`
+	// Log4jConfigMap selects a ConfigMap key holding the log4j configuration of the brokers, it takes precedence over
+	// log4jConfig. The ConfigMap is mounted into the broker pods, so changing it does not restart the brokers, the
+	// logger levels of the new configuration are applied to the running brokers through the Kafka admin API instead.
+	// +optional
+	Log4jConfigMap *corev1.ConfigMapKeySelector `json:"log4jConfigMap,omitempty"`
 	// Custom annotations for the broker pods - e.g.: Prometheus scraping annotations:
 	// prometheus.io/scrape: "true"
 	// prometheus.io/port: "9020"
@@ -201,6 +929,12 @@ type BrokerConfig struct {
 	// +kubebuilder:validation:Enum=Hostname;ExternalIP;InternalIP;InternalDNS;ExternalDNS
 	// +optional
 	NodePortNodeAddressType corev1.NodeAddressType `json:"nodePortNodeAddressType,omitempty"`
+	// HostNetwork runs the broker pods in the network namespace of their node to bypass kube-proxy and the overlay
+	// network. The ports of the listeners and the metrics port are allocated on the node as host ports so at most one
+	// broker is scheduled to a node. The external listeners using the NodePort access method are advertised on the
+	// 'nodePortNodeAddressType' address of the node, InternalIP by default, and the container port of the listener.
+	// +optional
+	HostNetwork bool `json:"hostNetwork,omitempty"`
 	// Any definition received through this field will override the default behaviour of OneBrokerPerNode flag
 	// and the operator supposes that the user is aware of how scheduling is done by kubernetes
 	// Affinity could be set through brokerConfigGroups definitions and can be set for individual brokers as well
@@ -209,6 +943,16 @@ type BrokerConfig struct {
 	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
 	// SecurityContext allows to set security context for the kafka container
 	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+	// StartupProbe of the kafka container, disabled by default. Slow-starting brokers, for example brokers which
+	// recover a large number of log segments, should use it to hold back the liveness probe until Kafka has started
+	// +optional
+	StartupProbe *corev1.Probe `json:"startupProbe,omitempty"`
+	// LivenessProbe of the kafka container, disabled by default
+	// +optional
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+	// ReadinessProbe of the kafka container, disabled by default
+	// +optional
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
 	// BrokerIngressMapping allows to set specific ingress to a specific broker mappings.
 	// If left empty, all broker will inherit the default one specified under external listeners config
 	// Only used when ExternalListeners.Config is populated
@@ -225,6 +969,12 @@ type BrokerConfig struct {
 	// Adding the "+" prefix to the name prepends the value to that environment variable instead of overwriting it.
 	// Add the "+" suffix to append.
 	Envs []corev1.EnvVar `json:"envs,omitempty"`
+	// EnvFrom populates environment variables of the Kafka broker Pods from Secrets and ConfigMaps.
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	// JVMOptions defines additional JVM flags for the Kafka brokers.
+	// +optional
+	JVMOptions *JVMOptions `json:"jvmOptions,omitempty"`
 	// TerminationGracePeriod defines the pod termination grace period
 	// +kubebuilder:default=120
 	// +optional
@@ -256,12 +1006,43 @@ type CruiseControlConfig struct {
 	ImagePullSecrets           []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 	NodeSelector               map[string]string             `json:"nodeSelector,omitempty"`
 	Tolerations                []corev1.Toleration           `json:"tolerations,omitempty"`
-	Config                     string                        `json:"config,omitempty"`
-	CapacityConfig             string                        `json:"capacityConfig,omitempty"`
-	ClusterConfig              string                        `json:"clusterConfig,omitempty"`
-	Log4jConfig                string                        `json:"log4jConfig,omitempty"`
-	Image                      string                        `json:"image,omitempty"`
-	TopicConfig                *TopicConfig                  `json:"topicConfig,omitempty"`
+	// Affinity of the CruiseControl pod
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// TopologySpreadConstraints of the CruiseControl pod
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// HeapOpts sets the heap of the JVM of Cruise Control, e.g. "-Xms2G -Xmx2G", it defaults to the heap settings of
+	// the Cruise Control start script. The memory limit of the container must leave room for the off-heap memory.
+	// +optional
+	HeapOpts string `json:"heapOpts,omitempty"`
+	// JVMOptions defines additional JVM flags for Cruise Control, e.g. the garbage collector settings
+	// +optional
+	JVMOptions     *JVMOptions  `json:"jvmOptions,omitempty"`
+	Config         string       `json:"config,omitempty"`
+	CapacityConfig string       `json:"capacityConfig,omitempty"`
+	ClusterConfig  string       `json:"clusterConfig,omitempty"`
+	Log4jConfig    string       `json:"log4jConfig,omitempty"`
+	Image          string       `json:"image,omitempty"`
+	TopicConfig    *TopicConfig `json:"topicConfig,omitempty"`
+	// ManageInternalTopics makes the operator create the sample store topics of Cruise Control besides its metrics
+	// topic and enforce the partitions, the retention and the cleanup policy of the internal topics. Topics which
+	// already exist, e.g. created by Cruise Control with its defaults, are adopted by KafkaTopics and repaired. The
+	// replication factor of an existing topic is not changed.
+	// +optional
+	ManageInternalTopics bool `json:"manageInternalTopics,omitempty"`
+	// SampleStoreTopicConfig defines the partitions and the replication factor of the sample store topics created by
+	// the operator, it defaults to the sample store configuration of Cruise Control
+	// +optional
+	SampleStoreTopicConfig *TopicConfig `json:"sampleStoreTopicConfig,omitempty"`
+	// SampleStore configures the persistence of the metric samples of Cruise Control
+	// +optional
+	SampleStore *CruiseControlSampleStore `json:"sampleStore,omitempty"`
+	// Log4jConfigMap selects a ConfigMap key holding the log4j2 configuration of Cruise Control, it takes precedence
+	// over log4jConfig. Cruise Control watches the configuration for changes, so changing the ConfigMap does not
+	// restart it. The monitorInterval of the configuration is set to 30 seconds unless it is set explicitly.
+	// +optional
+	Log4jConfigMap *corev1.ConfigMapKeySelector `json:"log4jConfigMap,omitempty"`
 	//  Annotations to be applied to CruiseControl pod
 	// +optional
 	CruiseControlAnnotations map[string]string `json:"cruiseControlAnnotations,omitempty"`
@@ -277,60 +1058,502 @@ type CruiseControlConfig struct {
 	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
 	// SecurityContext allows to set security context for the CruiseControl container
 	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+	// StartupProbe of the CruiseControl container, disabled by default
+	// +optional
+	StartupProbe *corev1.Probe `json:"startupProbe,omitempty"`
+	// LivenessProbe of the CruiseControl container, disabled by default
+	// +optional
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+	// ReadinessProbe of the CruiseControl container, defaults to a TCP check of the Cruise Control REST API port
+	// +optional
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
 	// PriorityClassName specifies the priority class name for the CruiseControl pod.
 	// If specified, the PriorityClass resource with this PriorityClassName must be created beforehand.
 	// If not specified, the CruiseControl pod's priority is default to zero.
 	// +optional
 	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// ClientTLS makes the operator connect to Cruise Control over HTTPS presenting a dedicated client certificate,
+	// it is required when the REST API of Cruise Control authenticates its clients with certificates
+	// +optional
+	ClientTLS *CruiseControlClientTLS `json:"clientTLS,omitempty"`
+	// HTTPClient configures the proxy, the headers and the bearer token of the requests sent by the operator to
+	// Cruise Control, e.g. when Cruise Control is behind an OAuth proxy or can only be reached through an egress proxy
+	// +optional
+	HTTPClient *CruiseControlHTTPClientConfig `json:"httpClient,omitempty"`
+	// Settings defines the commonly tuned properties of Cruise Control as typed fields. The properties set in config
+	// take precedence over them, so config remains available for overrides and for any other property.
+	// +optional
+	Settings *CruiseControlSettings `json:"settings,omitempty"`
+	// Version of Cruise Control the settings are validated against, e.g. 2.5.101. It defaults to the tag of the
+	// image, the settings are not validated when the version is not known.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// AnomalyNotifier makes Cruise Control report the anomalies it detects to the alert receiver of the operator,
+	// which records them as Kubernetes events and metrics and remediates them according to the remediations.
+	// It overrides the anomaly notifier of Cruise Control set in config.
+	// +optional
+	AnomalyNotifier *CruiseControlAnomalyNotifier `json:"anomalyNotifier,omitempty"`
 }
 
-// CruiseControlOperationSpec specifies the configuration of the CruiseControlOperation handling
-type CruiseControlOperationSpec struct {
-	// When TTLSecondsAfterFinished is specified, the created and finished (completed successfully or completedWithError and errorPolicy: ignore)
-	// cruiseControlOperation custom resource will be deleted after the given time elapsed.
-	// When it is 0 then the resource is going to be deleted instantly after the operation is finished.
-	// When it is not specified the resource is not going to be removed.
-	// Value can be only zero and positive integers.
+// CruiseControlAnomalyNotifier describes how the anomalies detected by Cruise Control are handled by the operator
+type CruiseControlAnomalyNotifier struct {
+	// ReceiverURL is the URL of the alert receiver of the operator which is reachable from Cruise Control,
+	// e.g. http://kafka-operator-alertmanager.kafka.svc:9001
+	// +kubebuilder:validation:Pattern=`^https?://`
+	ReceiverURL string `json:"receiverURL"`
+	// Remediations define the CruiseControlOperations created for the anomalies of the given types
+	// +optional
+	Remediations []CruiseControlAnomalyRemediation `json:"remediations,omitempty"`
+}
+
+// CruiseControlAnomalyRemediation defines the CruiseControlOperation created for an anomaly type
+type CruiseControlAnomalyRemediation struct {
+	// AnomalyType is the type of the anomalies remediated
+	// +kubebuilder:validation:Enum=BROKER_FAILURE;DISK_FAILURE;GOAL_VIOLATION;METRIC_ANOMALY;TOPIC_ANOMALY;MAINTENANCE_EVENT
+	AnomalyType string `json:"anomalyType"`
+	// Operation is the Cruise Control operation created to remediate the anomaly
+	// +kubebuilder:validation:Enum=rebalance;fix_offline_replicas
+	Operation string `json:"operation"`
+	// MinIntervalSeconds is the minimum time between the creation of two remediations of the anomaly type, the
+	// anomalies reported meanwhile or while a remediation is pending are only recorded, defaults to 3600
 	// +kubebuilder:validation:Minimum=0
-	TTLSecondsAfterFinished *int `json:"ttlSecondsAfterFinished,omitempty"`
+	// +optional
+	MinIntervalSeconds *int32 `json:"minIntervalSeconds,omitempty"`
 }
 
-// GetTTLSecondsAfterFinished returns NIL when CruiseControlOperationSpec is not specified otherwise it returns itself
-func (c *CruiseControlOperationSpec) GetTTLSecondsAfterFinished() *int {
-	if c == nil {
-		return nil
+// GetMinIntervalSeconds returns the minimum time between the creation of two remediations of the anomaly type
+func (r CruiseControlAnomalyRemediation) GetMinIntervalSeconds() int32 {
+	if r.MinIntervalSeconds == nil {
+		return DefaultAnomalyRemediationMinIntervalSeconds
 	}
-	return c.TTLSecondsAfterFinished
+	return *r.MinIntervalSeconds
 }
 
-// CruiseControlTaskSpec specifies the configuration of the CC Tasks
-type CruiseControlTaskSpec struct {
-	// RetryDurationMinutes describes the amount of time the Operator waits for the task
-	RetryDurationMinutes int `json:"RetryDurationMinutes"`
+// GetRemediation returns the remediation of the given anomaly type
+func (n *CruiseControlAnomalyNotifier) GetRemediation(anomalyType string) (CruiseControlAnomalyRemediation, bool) {
+	if n == nil {
+		return CruiseControlAnomalyRemediation{}, false
+	}
+	for _, remediation := range n.Remediations {
+		if remediation.AnomalyType == anomalyType {
+			return remediation, true
+		}
+	}
+	return CruiseControlAnomalyRemediation{}, false
 }
 
-// TopicConfig holds info for topic configuration regarding partitions and replicationFactor
-type TopicConfig struct {
-	Partitions int32 `json:"partitions"`
-	// +kubebuilder:validation:Minimum=2
-	ReplicationFactor int32 `json:"replicationFactor"`
+// GetWebhookURL returns the URL Cruise Control posts the anomalies of the given Kafka cluster to
+func (n *CruiseControlAnomalyNotifier) GetWebhookURL(namespace, name string) string {
+	return strings.TrimSuffix(n.ReceiverURL, "/") + CruiseControlAnomalyReceiverPath + namespace + "/" + name
 }
 
-// EnvoyConfig defines the config for Envoy
-type EnvoyConfig struct {
-	Image     string                       `json:"image,omitempty"`
-	Resources *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+// CruiseControlSettings defines the commonly tuned properties of Cruise Control
+type CruiseControlSettings struct {
+	// NumConcurrentPartitionMovementsPerBroker is the maximum number of inter-broker partition movements per broker,
+	// num.concurrent.partition.movements.per.broker in the Cruise Control configuration
 	// +kubebuilder:validation:Minimum=1
-	Replicas int32 `json:"replicas,omitempty"`
-	// ServiceAccountName is the name of service account
-	ServiceAccountName string `json:"serviceAccountName,omitempty"`
-	// ImagePullSecrets for the envoy image pull
-	ImagePullSecrets          []corev1.LocalObjectReference     `json:"imagePullSecrets,omitempty"`
-	Affinity                  *corev1.Affinity                  `json:"affinity,omitempty"`
-	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
-	// NodeSelector is the node selector expression for envoy pods
-	NodeSelector map[string]string   `json:"nodeSelector,omitempty"`
-	Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
+	// +optional
+	NumConcurrentPartitionMovementsPerBroker *int32 `json:"numConcurrentPartitionMovementsPerBroker,omitempty"`
+	// NumConcurrentIntraBrokerPartitionMovements is the maximum number of intra-broker partition movements per broker,
+	// num.concurrent.intra.broker.partition.movements in the Cruise Control configuration
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NumConcurrentIntraBrokerPartitionMovements *int32 `json:"numConcurrentIntraBrokerPartitionMovements,omitempty"`
+	// NumConcurrentLeaderMovements is the maximum number of leadership movements in the cluster,
+	// num.concurrent.leader.movements in the Cruise Control configuration
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NumConcurrentLeaderMovements *int32 `json:"numConcurrentLeaderMovements,omitempty"`
+	// MetricSamplingIntervalMs is the interval of the metric sampling, metric.sampling.interval.ms in the Cruise
+	// Control configuration
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MetricSamplingIntervalMs *int64 `json:"metricSamplingIntervalMs,omitempty"`
+	// PartitionMetricsWindowMs is the size of the partition metric windows, partition.metrics.window.ms in the Cruise
+	// Control configuration
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PartitionMetricsWindowMs *int64 `json:"partitionMetricsWindowMs,omitempty"`
+	// NumPartitionMetricsWindows is the number of partition metric windows kept, num.partition.metrics.windows in the
+	// Cruise Control configuration
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NumPartitionMetricsWindows *int32 `json:"numPartitionMetricsWindows,omitempty"`
+	// BrokerMetricsWindowMs is the size of the broker metric windows, broker.metrics.window.ms in the Cruise Control
+	// configuration
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BrokerMetricsWindowMs *int64 `json:"brokerMetricsWindowMs,omitempty"`
+	// NumBrokerMetricsWindows is the number of broker metric windows kept, num.broker.metrics.windows in the Cruise
+	// Control configuration
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NumBrokerMetricsWindows *int32 `json:"numBrokerMetricsWindows,omitempty"`
+	// AnomalyNotifierClass is the Java class notified about the anomalies detected by Cruise Control,
+	// anomaly.notifier.class in the Cruise Control configuration
+	// +kubebuilder:validation:Pattern=`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`
+	// +optional
+	AnomalyNotifierClass string `json:"anomalyNotifierClass,omitempty"`
+}
+
+// Properties returns the Cruise Control properties of the settings which are set
+func (s *CruiseControlSettings) Properties() map[string]string {
+	props := make(map[string]string)
+	if s == nil {
+		return props
+	}
+	setInt32 := func(key string, value *int32) {
+		if value != nil {
+			props[key] = strconv.FormatInt(int64(*value), 10)
+		}
+	}
+	setInt64 := func(key string, value *int64) {
+		if value != nil {
+			props[key] = strconv.FormatInt(*value, 10)
+		}
+	}
+	setInt32("num.concurrent.partition.movements.per.broker", s.NumConcurrentPartitionMovementsPerBroker)
+	setInt32("num.concurrent.intra.broker.partition.movements", s.NumConcurrentIntraBrokerPartitionMovements)
+	setInt32("num.concurrent.leader.movements", s.NumConcurrentLeaderMovements)
+	setInt64("metric.sampling.interval.ms", s.MetricSamplingIntervalMs)
+	setInt64("partition.metrics.window.ms", s.PartitionMetricsWindowMs)
+	setInt32("num.partition.metrics.windows", s.NumPartitionMetricsWindows)
+	setInt64("broker.metrics.window.ms", s.BrokerMetricsWindowMs)
+	setInt32("num.broker.metrics.windows", s.NumBrokerMetricsWindows)
+	if s.AnomalyNotifierClass != "" {
+		props["anomaly.notifier.class"] = s.AnomalyNotifierClass
+	}
+	return props
+}
+
+// CruiseControlHTTPClientConfig describes the requests sent by the operator to Cruise Control. The referenced Secrets
+// must be in the namespace of the KafkaCluster.
+type CruiseControlHTTPClientConfig struct {
+	// ProxyURL is the URL of the HTTP or HTTPS proxy the requests to Cruise Control are sent through
+	// +optional
+	ProxyURL string `json:"proxyURL,omitempty"`
+	// ProxyURLSecretRef selects the key of a Secret holding the URL of the proxy, it takes precedence over ProxyURL
+	// and it is meant for proxy URLs containing credentials
+	// +optional
+	ProxyURLSecretRef *corev1.SecretKeySelector `json:"proxyURLSecretRef,omitempty"`
+	// Headers are set on every request sent to Cruise Control
+	// +optional
+	Headers []CruiseControlHTTPHeader `json:"headers,omitempty"`
+	// BearerTokenSecretRef selects the key of a Secret holding the token sent in the Authorization header of the
+	// requests as a bearer token
+	// +optional
+	BearerTokenSecretRef *corev1.SecretKeySelector `json:"bearerTokenSecretRef,omitempty"`
+}
+
+// CruiseControlHTTPHeader is a header of the requests sent to Cruise Control
+type CruiseControlHTTPHeader struct {
+	// Name of the header
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Value of the header
+	// +optional
+	Value string `json:"value,omitempty"`
+	// ValueFrom selects the key of a Secret holding the value of the header, it takes precedence over Value
+	// +optional
+	ValueFrom *corev1.SecretKeySelector `json:"valueFrom,omitempty"`
+}
+
+// CruiseControlClientTLS describes how the client certificate of the operator used for Cruise Control is issued.
+// The certificate is issued through a KafkaUser so it is renewed by the PKI backend before it expires.
+type CruiseControlClientTLS struct {
+	// PKIBackend issues the client certificate, defaults to cert-manager
+	// +kubebuilder:validation:Enum={"cert-manager","k8s-csr"}
+	// +optional
+	PKIBackend PKIBackend `json:"pkiBackend,omitempty"`
+	// IssuerRef is the cert-manager issuer of the client certificate, defaults to the issuer of the cluster
+	// +optional
+	IssuerRef *cmmeta.ObjectReference `json:"issuerRef,omitempty"`
+	// SignerName is the signer of the client certificate requested from the Kubernetes CSR API
+	// +optional
+	SignerName string `json:"signerName,omitempty"`
+}
+
+// CruiseControlSampleStore specifies how the metric samples of Cruise Control are persisted
+type CruiseControlSampleStore struct {
+	// Enabled stores the metric samples of Cruise Control in Kafka topics, Cruise Control loads them back when it is
+	// restarted instead of collecting the samples of the metric windows again. The sample store topics are created
+	// and repaired by the operator.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// LoadingThreads is the number of threads Cruise Control uses to load the samples from the sample store topics
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	LoadingThreads *int32 `json:"loadingThreads,omitempty"`
+	// VerifyTopics makes the operator check that every partition of the sample store topics has a leader before
+	// the Cruise Control topics are reported to be ready, so that Cruise Control is deployed only when it is able to
+	// load its samples.
+	// +optional
+	VerifyTopics bool `json:"verifyTopics,omitempty"`
+}
+
+// CruiseControlOperationSpec specifies the configuration of the CruiseControlOperation handling
+type CruiseControlOperationSpec struct {
+	// When TTLSecondsAfterFinished is specified, the created and finished (completed successfully or completedWithError and errorPolicy: ignore)
+	// cruiseControlOperation custom resource will be deleted after the given time elapsed.
+	// When it is 0 then the resource is going to be deleted instantly after the operation is finished.
+	// When it is not specified the resource is not going to be removed.
+	// Value can be only zero and positive integers.
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterFinished *int `json:"ttlSecondsAfterFinished,omitempty"`
+	// Quotas limit the CruiseControlOperations of the tenants sharing the Cruise Control of the Kafka cluster.
+	// A CruiseControlOperation has to satisfy every quota which selects it.
+	// +optional
+	Quotas []CruiseControlOperationQuota `json:"quotas,omitempty"`
+	// StatusLimits bounds the size of the CruiseControlOperation status to stay below the object size limit of etcd.
+	// +optional
+	StatusLimits *CruiseControlOperationStatusLimits `json:"statusLimits,omitempty"`
+	// MovementExclusions are the topics and brokers excluded from the partition replica movements
+	// of the CruiseControlOperations created by the operator.
+	// +optional
+	MovementExclusions []CruiseControlMovementExclusion `json:"movementExclusions,omitempty"`
+	// BrokerRemovalDestinations constrains the brokers receiving the partition replicas of the removed brokers
+	// in the remove_broker operations created by the operator.
+	// +optional
+	BrokerRemovalDestinations *BrokerRemovalDestinationPolicy `json:"brokerRemovalDestinations,omitempty"`
+}
+
+// BrokerRemovalDestinationPolicy constrains the destination brokers of the remove_broker operations, it is translated
+// into the destination_broker_ids parameter of Cruise Control. The operation is not created when none of the brokers
+// satisfies the policy.
+type BrokerRemovalDestinationPolicy struct {
+	// SameZone moves the partition replicas only to the brokers in the zones (broker.rack) of the removed brokers.
+	// The zones are not restricted when the zone of the removed brokers is not known.
+	// +optional
+	SameZone bool `json:"sameZone,omitempty"`
+	// ExcludedBrokerIDs are the brokers which do not receive the partition replicas of the removed brokers.
+	// +optional
+	ExcludedBrokerIDs []int32 `json:"excludedBrokerIds,omitempty"`
+	// MaxDiskUsagePercent excludes the brokers having a log directory filled above the given percentage of its
+	// capacity according to the disk usage in the status of the Kafka cluster.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxDiskUsagePercent *int32 `json:"maxDiskUsagePercent,omitempty"`
+}
+
+// CruiseControlMovementOperation is a Cruise Control operation moving partition replicas
+// +kubebuilder:validation:Enum=add_broker;remove_broker;rebalance
+type CruiseControlMovementOperation string
+
+const (
+	MovementOperationAddBroker    CruiseControlMovementOperation = "add_broker"
+	MovementOperationRemoveBroker CruiseControlMovementOperation = "remove_broker"
+	MovementOperationRebalance    CruiseControlMovementOperation = "rebalance"
+)
+
+// CruiseControlMovementExclusion defines topics and brokers excluded from the partition replica movements
+type CruiseControlMovementExclusion struct {
+	// Operations limits the exclusion to the given operation types, by default it applies to all of them.
+	// +optional
+	Operations []CruiseControlMovementOperation `json:"operations,omitempty"`
+	// Topics are regular expressions matching the topics whose partition replicas are not moved,
+	// e.g. large compacted changelog topics.
+	// +optional
+	Topics []string `json:"topics,omitempty"`
+	// BrokerIDs are the brokers which do not receive partition replicas in rebalance and remove_broker operations.
+	// +optional
+	BrokerIDs []int32 `json:"brokerIds,omitempty"`
+	// SelfHealing excludes the topics from the partition movements of Cruise Control self-healing as well by setting
+	// topics.excluded.from.partition.movement in the Cruise Control configuration, which also applies to the operations
+	// not specifying excluded topics. It can be set only when Operations is empty.
+	// +optional
+	SelfHealing bool `json:"selfHealing,omitempty"`
+}
+
+// AppliesTo returns true when the exclusion applies to the given operation type
+func (e CruiseControlMovementExclusion) AppliesTo(operation string) bool {
+	if len(e.Operations) == 0 {
+		switch CruiseControlMovementOperation(operation) {
+		case MovementOperationAddBroker, MovementOperationRemoveBroker, MovementOperationRebalance:
+			return true
+		}
+		return false
+	}
+	for _, op := range e.Operations {
+		if string(op) == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// CruiseControlOperationStatusLimits specifies the bounds of the CruiseControlOperation status fields
+type CruiseControlOperationStatusLimits struct {
+	// MaxErrorMessageLength is the maximum length of the error message of a task, longer messages are truncated.
+	// Default is 4096.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxErrorMessageLength *int32 `json:"maxErrorMessageLength,omitempty"`
+	// MaxSummaryValueLength is the maximum length of a value in the summary of a task, longer values are truncated.
+	// Default is 1024.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxSummaryValueLength *int32 `json:"maxSummaryValueLength,omitempty"`
+	// CompactFailedTasks drops the summary, the simulation and the HTTP request of the tasks in the failedTasks history
+	// keeping only their state, timestamps and error message.
+	// +optional
+	CompactFailedTasks bool `json:"compactFailedTasks,omitempty"`
+	// SpillResultToConfigMap stores the full, gzip compressed optimization result of the Cruise Control user tasks
+	// in a ConfigMap owned by the CruiseControlOperation.
+	// +optional
+	SpillResultToConfigMap bool `json:"spillResultToConfigMap,omitempty"`
+	// FailedTasksHistory bounds the failedTasks history of the CruiseControlOperations of the Kafka cluster,
+	// it overrides the defaults of the operator.
+	// +optional
+	FailedTasksHistory *FailedTasksHistoryLimits `json:"failedTasksHistory,omitempty"`
+}
+
+// FailedTasksHistoryLimits bounds the failedTasks history of the CruiseControlOperations, the limits which are not set
+// are inherited from the next level: operation, Kafka cluster and operator.
+type FailedTasksHistoryLimits struct {
+	// MaxLength is the maximum number of failed tasks kept in the history, the oldest ones are dropped first.
+	// When it is 0 the full history is kept. The operator default is 50.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxLength *int32 `json:"maxLength,omitempty"`
+	// MaxAgeSeconds drops the failed tasks from the history which finished longer ago than the given time.
+	// When it is 0 the failed tasks are not pruned by their age, which is the operator default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxAgeSeconds *int64 `json:"maxAgeSeconds,omitempty"`
+}
+
+// GetFailedTasksHistory returns the limits of the failedTasks history
+func (l *CruiseControlOperationStatusLimits) GetFailedTasksHistory() *FailedTasksHistoryLimits {
+	if l == nil {
+		return nil
+	}
+	return l.FailedTasksHistory
+}
+
+// GetMaxErrorMessageLength returns the maximum length of the error message of a task
+func (l *CruiseControlOperationStatusLimits) GetMaxErrorMessageLength() int {
+	if l == nil || l.MaxErrorMessageLength == nil {
+		return DefaultCruiseControlOperationMaxErrorMessageLength
+	}
+	return int(*l.MaxErrorMessageLength)
+}
+
+// GetMaxSummaryValueLength returns the maximum length of a value in the summary of a task
+func (l *CruiseControlOperationStatusLimits) GetMaxSummaryValueLength() int {
+	if l == nil || l.MaxSummaryValueLength == nil {
+		return DefaultCruiseControlOperationMaxSummaryValueLength
+	}
+	return int(*l.MaxSummaryValueLength)
+}
+
+// GetStatusLimits returns NIL when CruiseControlOperationSpec is not specified otherwise it returns the status limits
+func (c *CruiseControlOperationSpec) GetStatusLimits() *CruiseControlOperationStatusLimits {
+	if c == nil {
+		return nil
+	}
+	return c.StatusLimits
+}
+
+// CruiseControlOperationQuota limits the CruiseControlOperations selected by the label selector
+type CruiseControlOperationQuota struct {
+	// Name identifies the quota (e.g. the name of the tenant) in the validation and status messages.
+	Name string `json:"name"`
+	// Selector selects the CruiseControlOperations the quota applies to by their labels.
+	// When it is not specified the quota applies to every CruiseControlOperation of the Kafka cluster.
+	// The operations created by the Koperator are counted and held back from execution by the quota,
+	// but they are never rejected.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// MaxPending is the maximum number of selected CruiseControlOperations which are not finished yet.
+	// Operations above the limit are rejected at creation and held back from execution.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxPending *int32 `json:"maxPending,omitempty"`
+	// MaxPerHour is the maximum number of selected CruiseControlOperations which can be created and
+	// executed within an hour.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxPerHour *int32 `json:"maxPerHour,omitempty"`
+	// AllowedOperations is the list of Cruise Control operation types which can be used by the selected
+	// CruiseControlOperations. When it is empty every operation type is allowed.
+	// +optional
+	AllowedOperations []string `json:"allowedOperations,omitempty"`
+}
+
+// GetQuotas returns NIL when CruiseControlOperationSpec is not specified otherwise it returns the quotas
+func (c *CruiseControlOperationSpec) GetQuotas() []CruiseControlOperationQuota {
+	if c == nil {
+		return nil
+	}
+	return c.Quotas
+}
+
+// GetBrokerRemovalDestinations returns NIL when CruiseControlOperationSpec is not specified otherwise it returns
+// the destination policy of the remove_broker operations
+func (c *CruiseControlOperationSpec) GetBrokerRemovalDestinations() *BrokerRemovalDestinationPolicy {
+	if c == nil {
+		return nil
+	}
+	return c.BrokerRemovalDestinations
+}
+
+// GetMovementExclusions returns NIL when CruiseControlOperationSpec is not specified otherwise it returns the movement exclusions
+func (c *CruiseControlOperationSpec) GetMovementExclusions() []CruiseControlMovementExclusion {
+	if c == nil {
+		return nil
+	}
+	return c.MovementExclusions
+}
+
+// GetSelfHealingExcludedTopics returns the regular expression matching the topics excluded from the partition
+// movements of Cruise Control self-healing, or an empty string when no topic is excluded
+func (c *CruiseControlOperationSpec) GetSelfHealingExcludedTopics() string {
+	var topics []string
+	for _, exclusion := range c.GetMovementExclusions() {
+		if exclusion.SelfHealing && len(exclusion.Operations) == 0 {
+			topics = append(topics, exclusion.Topics...)
+		}
+	}
+	return strings.Join(topics, "|")
+}
+
+// GetTTLSecondsAfterFinished returns NIL when CruiseControlOperationSpec is not specified otherwise it returns itself
+func (c *CruiseControlOperationSpec) GetTTLSecondsAfterFinished() *int {
+	if c == nil {
+		return nil
+	}
+	return c.TTLSecondsAfterFinished
+}
+
+// CruiseControlTaskSpec specifies the configuration of the CC Tasks
+type CruiseControlTaskSpec struct {
+	// RetryDurationMinutes describes the amount of time the Operator waits for the task
+	RetryDurationMinutes int `json:"RetryDurationMinutes"`
+}
+
+// TopicConfig holds info for topic configuration regarding partitions and replicationFactor
+type TopicConfig struct {
+	Partitions int32 `json:"partitions"`
+	// +kubebuilder:validation:Minimum=2
+	ReplicationFactor int32 `json:"replicationFactor"`
+}
+
+// EnvoyConfig defines the config for Envoy
+type EnvoyConfig struct {
+	Image     string                       `json:"image,omitempty"`
+	Resources *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	Replicas int32 `json:"replicas,omitempty"`
+	// ServiceAccountName is the name of service account
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// ImagePullSecrets for the envoy image pull
+	ImagePullSecrets          []corev1.LocalObjectReference     `json:"imagePullSecrets,omitempty"`
+	Affinity                  *corev1.Affinity                  `json:"affinity,omitempty"`
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// NodeSelector is the node selector expression for envoy pods
+	NodeSelector map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
 	// Annotations defines the annotations placed on the envoy ingress controller deployment
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// Labels defines the labels placed on the envoy ingress controller deployment
@@ -361,6 +1584,56 @@ type EnvoyConfig struct {
 	// EnableHealthCheckHttp10 is a toggle for adding HTTP1.0 support to Envoy health-check, default false
 	// +optional
 	EnableHealthCheckHttp10 bool `json:"enableHealthCheckHttp10,omitempty"`
+	// PodSecurityContext holds pod-level security attributes and common container settings for the Envoy pods
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// SecurityContext allows to set security context for the Envoy container
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+	// ConfigOverrides defines typed overrides of the Envoy configuration generated for the brokers
+	// +optional
+	ConfigOverrides *EnvoyConfigOverrides `json:"configOverrides,omitempty"`
+	// ConfigPatches are JSON patch (RFC 6902) operations applied to the generated Envoy bootstrap configuration
+	// after the overrides. The paths refer to the JSON representation of the bootstrap configuration which uses
+	// lowerCamelCase field names (e.g. /staticResources/listeners/0/perConnectionBufferLimitBytes).
+	// The patches are skipped when they cannot be applied or the result is not a valid Envoy bootstrap configuration.
+	// +optional
+	ConfigPatches []EnvoyConfigPatch `json:"configPatches,omitempty"`
+}
+
+// EnvoyConfigOverrides defines the tunables of the Envoy configuration generated for the brokers
+type EnvoyConfigOverrides struct {
+	// ConnectTimeout is the timeout of establishing the upstream connections to the brokers, defaults to 1s
+	// +optional
+	ConnectTimeout *metav1.Duration `json:"connectTimeout,omitempty"`
+	// IdleTimeout is the time after which the proxied connections without any traffic are closed,
+	// defaults to 1h and zero disables it
+	// +optional
+	IdleTimeout *metav1.Duration `json:"idleTimeout,omitempty"`
+	// PerConnectionBufferLimitBytes is the soft limit on the size of the read and write buffers
+	// of the downstream and the upstream connections, defaults to 1MiB
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PerConnectionBufferLimitBytes *uint32 `json:"perConnectionBufferLimitBytes,omitempty"`
+	// AccessLog enables logging of the proxied broker connections to the standard output of Envoy
+	// +optional
+	AccessLog bool `json:"accessLog,omitempty"`
+}
+
+// EnvoyConfigPatch is a JSON patch operation applied to the generated Envoy bootstrap configuration
+type EnvoyConfigPatch struct {
+	// +kubebuilder:validation:Enum=add;remove;replace;move;copy;test
+	Op string `json:"op"`
+	// Path is the JSON pointer of the location the operation is applied to
+	Path string `json:"path"`
+	// From is the JSON pointer of the source location of move and copy operations
+	// +optional
+	From string `json:"from,omitempty"`
+	// Value is the value of add, replace and test operations
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Value *runtime.RawExtension `json:"value,omitempty"`
 }
 
 // EnvoyCommandLineArgs defines envoy command line arguments
@@ -398,99 +1671,704 @@ func (iIConfig *IstioIngressConfig) GetAnnotations() map[string]string {
 	return util.CloneMap(iIConfig.Annotations)
 }
 
-// GetVirtualServiceAnnotations returns a copy of the VirtualServiceAnnotations field
-func (iIConfig *IstioIngressConfig) GetVirtualServiceAnnotations() map[string]string {
-	return util.CloneMap(iIConfig.VirtualServiceAnnotations)
+// GetVirtualServiceAnnotations returns a copy of the VirtualServiceAnnotations field
+func (iIConfig *IstioIngressConfig) GetVirtualServiceAnnotations() map[string]string {
+	return util.CloneMap(iIConfig.VirtualServiceAnnotations)
+}
+
+// GetLoadBalancerSourceRanges returns LoadBalancerSourceRanges to use for Istio Meshagetway generated LoadBalancer
+func (iIConfig *IstioIngressConfig) GetLoadBalancerSourceRanges() []string {
+	return iIConfig.LoadBalancerSourceRanges
+}
+
+// MonitoringConfig defines the config for monitoring Kafka and Cruise Control
+type MonitoringConfig struct {
+	JmxImage               string `json:"jmxImage,omitempty"`
+	PathToJar              string `json:"pathToJar,omitempty"`
+	KafkaJMXExporterConfig string `json:"kafkaJMXExporterConfig,omitempty"`
+	CCJMXExporterConfig    string `json:"cCJMXExporterConfig,omitempty"`
+}
+
+// StorageConfig defines the broker storage configuration
+type StorageConfig struct {
+	MountPath string `json:"mountPath"`
+
+	// If set https://kubernetes.io/docs/concepts/storage/volumes/#persistentvolumeclaim is used
+	// as storage for Kafka broker log dirs. Either `pvcSpec` or `emptyDir` has to be set.
+	// When both `pvcSpec` and `emptyDir` fields are set
+	// the `pvcSpec` is used by default.
+	// +optional
+	PvcSpec *corev1.PersistentVolumeClaimSpec `json:"pvcSpec,omitempty"`
+
+	// If set https://kubernetes.io/docs/concepts/storage/volumes#emptydir is used
+	// as storage for Kafka broker log dirs. The use of empty dir as Kafka broker storage is useful in development
+	// environments where data loss is not a concern as data stored on emptydir backed storage is lost at pod restarts.
+	// Either `pvcSpec` or `emptyDir` has to be set.
+	// When both `pvcSpec` and `emptyDir` fields are set
+	// the `pvcSpec` is used by default.
+	// +optional
+	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+}
+
+// ListenersConfig defines the Kafka listener types
+type ListenersConfig struct {
+	ExternalListeners  []ExternalListenerConfig `json:"externalListeners,omitempty"`
+	InternalListeners  []InternalListenerConfig `json:"internalListeners"`
+	SSLSecrets         *SSLSecrets              `json:"sslSecrets,omitempty"`
+	ServiceAnnotations map[string]string        `json:"serviceAnnotations,omitempty"`
+	// AdditionalTrustedCAs are PEM encoded CA certificates appended to the truststores of the SSL listeners and to the
+	// secrets of the KafkaUsers, e.g. to accept clients signed by a corporate CA. The sources are looked up in the
+	// namespace of the KafkaCluster.
+	// +optional
+	AdditionalTrustedCAs []TrustedCASource `json:"additionalTrustedCAs,omitempty"`
+	// ConnectionLimits limits the number of client connections accepted by each broker over all of its listeners.
+	// The limits are applied dynamically without restarting the brokers.
+	// +optional
+	ConnectionLimits *BrokerConnectionLimits `json:"connectionLimits,omitempty"`
+}
+
+// BrokerConnectionLimits defines the connection limits of a broker which apply to all of its listeners
+type BrokerConnectionLimits struct {
+	// MaxConnections is the maximum number of connections accepted by the broker, it is set as max.connections
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConnections *int32 `json:"maxConnections,omitempty"`
+	// MaxConnectionsPerIP is the maximum number of connections accepted by the broker from a single IP address,
+	// it is set as max.connections.per.ip
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConnectionsPerIP *int32 `json:"maxConnectionsPerIP,omitempty"`
+	// MaxConnectionsPerIPOverrides overrides MaxConnectionsPerIP for the given IP addresses or hostnames,
+	// it is set as max.connections.per.ip.overrides
+	// +optional
+	MaxConnectionsPerIPOverrides map[string]int32 `json:"maxConnectionsPerIPOverrides,omitempty"`
+	// MaxConnectionCreationRate is the maximum number of new connections accepted by the broker per second,
+	// it is set as max.connection.creation.rate
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConnectionCreationRate *int32 `json:"maxConnectionCreationRate,omitempty"`
+}
+
+// ListenerConnectionLimits defines the connection limits of a single listener of the brokers
+type ListenerConnectionLimits struct {
+	// MaxConnections is the maximum number of connections accepted by the listener of each broker
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConnections *int32 `json:"maxConnections,omitempty"`
+	// MaxConnectionCreationRate is the maximum number of new connections accepted by the listener of each broker
+	// per second
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConnectionCreationRate *int32 `json:"maxConnectionCreationRate,omitempty"`
+}
+
+// TrustedCASource references PEM encoded CA certificates stored under a key of a ConfigMap or a Secret
+type TrustedCASource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap holding the CA certificates
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// SecretKeyRef selects a key of a Secret holding the CA certificates
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// GetServiceAnnotations returns a copy of the ServiceAnnotations field.
+func (c ListenersConfig) GetServiceAnnotations() map[string]string {
+	return util.CloneMap(c.ServiceAnnotations)
+}
+
+func (c ExternalListenerConfig) GetAccessMethod() corev1.ServiceType {
+	if c.AccessMethod == "" {
+		return corev1.ServiceTypeLoadBalancer
+	}
+	return c.AccessMethod
+}
+
+func (c ExternalListenerConfig) GetAnyCastPort() int32 {
+	if c.AnyCastPort == nil {
+		return DefaultAnyCastPort
+	}
+	return *c.AnyCastPort
+}
+
+// GetServiceAnnotations returns a copy of the ServiceAnnotations field.
+func (c IngressServiceSettings) GetServiceAnnotations() map[string]string {
+	return util.CloneMap(c.ServiceAnnotations)
+}
+
+// GetLoadBalancerSourceRanges returns the LoadBalancerSourceRanges of the external listener when set,
+// otherwise the given ranges of the ingress controller config.
+func (c IngressServiceSettings) GetLoadBalancerSourceRanges(ingressControllerRanges []string) []string {
+	if len(c.LoadBalancerSourceRanges) > 0 {
+		return c.LoadBalancerSourceRanges
+	}
+	return ingressControllerRanges
+}
+
+// GetServiceType returns the field value of ServiceType defaults to LoadBalancer.
+func (c IngressServiceSettings) GetServiceType() corev1.ServiceType {
+	if c.ServiceType == "" {
+		return corev1.ServiceTypeLoadBalancer
+	}
+	return c.ServiceType
+}
+
+// SSLSecrets defines the Kafka SSL secrets
+type SSLSecrets struct {
+	TLSSecretName   string                  `json:"tlsSecretName"`
+	JKSPasswordName string                  `json:"jksPasswordName,omitempty"`
+	Create          bool                    `json:"create,omitempty"`
+	IssuerRef       *cmmeta.ObjectReference `json:"issuerRef,omitempty"`
+	// +kubebuilder:validation:Enum={"cert-manager"}
+	PKIBackend PKIBackend `json:"pkiBackend,omitempty"`
+}
+
+// TODO (tinyzimmer): The above are all optional now in one way or another.
+// Would be another good use-case for a pre-admission hook
+// E.g. TLSSecretName and JKSPasswordName are only required if Create is false
+// Or heck, do we even want to bother supporting an imported PKI?
+
+// NetworkPolicyConfig defines the NetworkPolicies generated for the Kafka cluster. The allowed ports are derived from
+// the listener definitions so that brokers, Cruise Control and the operator can reach each other while other clients
+// can be limited per listener.
+type NetworkPolicyConfig struct {
+	// Enabled turns on the generation of NetworkPolicies for the brokers and Cruise Control
+	Enabled bool `json:"enabled,omitempty"`
+	// OperatorPeers selects the pods of the operator which are allowed to reach the brokers and Cruise Control.
+	// The pods labeled with app.kubernetes.io/name=kafka-operator in any namespace are selected when it is empty.
+	// +optional
+	OperatorPeers []networkingv1.NetworkPolicyPeer `json:"operatorPeers,omitempty"`
+	// ListenerPeers restricts the clients which are allowed to connect to the listener with the given name.
+	// Listeners which are not listed here accept connections from any client.
+	// +optional
+	ListenerPeers map[string][]networkingv1.NetworkPolicyPeer `json:"listenerPeers,omitempty"`
+	// MetricsPeers restricts the clients which are allowed to scrape the metrics of the brokers and Cruise Control.
+	// The metrics can be scraped from anywhere when it is empty.
+	// +optional
+	MetricsPeers []networkingv1.NetworkPolicyPeer `json:"metricsPeers,omitempty"`
+	// ZooKeeperPodSelector selects the ZooKeeper pods running in the namespace of the Kafka cluster. When it is set
+	// a NetworkPolicy is generated which allows only the brokers, Cruise Control and the operator to reach ZooKeeper.
+	// +optional
+	ZooKeeperPodSelector *metav1.LabelSelector `json:"zooKeeperPodSelector,omitempty"`
+}
+
+// KafkaExporterConfig defines the kafka_exporter deployment of the Kafka cluster. It connects to the internal listener
+// used for inner broker communication with the client certificate of the operator when the listener uses SSL.
+// The client certificate secret needs to contain the PEM encoded certificate, key and CA certificate under the
+// tls.crt, tls.key and ca.crt keys, which is the case for the certificate generated through 'sslSecrets'.
+type KafkaExporterConfig struct {
+	// Enabled turns on the deployment of kafka_exporter
+	Enabled bool `json:"enabled,omitempty"`
+	// Image is the image of kafka_exporter
+	// +optional
+	Image string `json:"image,omitempty"`
+	// TopicFilter is a regular expression selecting the topics whose metrics are exposed, all topics are selected by default
+	// +optional
+	TopicFilter string `json:"topicFilter,omitempty"`
+	// GroupFilter is a regular expression selecting the consumer groups whose metrics are exposed, all consumer groups
+	// are selected by default
+	// +optional
+	GroupFilter string `json:"groupFilter,omitempty"`
+	// SASL defines the credentials used when the listener used for inner broker communication requires SASL authentication
+	// +optional
+	SASL *KafkaExporterSASLConfig `json:"sasl,omitempty"`
+	// ExtraArgs are passed to kafka_exporter besides the generated ones, e.g. --kafka.version=3.1.0
+	// +optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// KafkaExporterSASLConfig defines the SASL credentials of kafka_exporter
+type KafkaExporterSASLConfig struct {
+	// Mechanism is the SASL mechanism used for authentication
+	// +kubebuilder:validation:Enum=plain;scram-sha256;scram-sha512
+	Mechanism string `json:"mechanism"`
+	// SecretRef references the secret holding the credentials under the username and password keys
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+}
+
+// IsEnabled returns true when kafka_exporter needs to be deployed for the Kafka cluster
+func (c *KafkaExporterConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// GetImage returns the image of kafka_exporter
+func (c *KafkaExporterConfig) GetImage() string {
+	if c.Image == "" {
+		return DefaultKafkaExporterImage
+	}
+	return c.Image
+}
+
+// GetResources returns the resource requirements of kafka_exporter
+func (c *KafkaExporterConfig) GetResources() corev1.ResourceRequirements {
+	if c.Resources == nil {
+		return corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("200m"),
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+		}
+	}
+	return *c.Resources
+}
+
+// GetServiceAccount returns the service account of kafka_exporter
+func (c *KafkaExporterConfig) GetServiceAccount() string {
+	if c.ServiceAccountName == "" {
+		return DefaultServiceAccountName
+	}
+	return c.ServiceAccountName
+}
+
+// SPIFFEConfig defines how the brokers obtain their X.509 SVIDs from the SPIFFE Workload API. A spiffe-helper sidecar
+// writes the SVID, its key and the trust bundle to files which are assembled into the PEM keystore and truststore of
+// the SPIFFE listeners. SVID rotation is picked up by periodically setting the keystore location of the listeners
+// again, which makes the brokers reload the changed files.
+type SPIFFEConfig struct {
+	// CSIDriver is the name of the CSI driver exposing the SPIFFE Workload API socket, it defaults to csi.spiffe.io
+	// +optional
+	CSIDriver string `json:"csiDriver,omitempty"`
+	// AgentSocketName is the name of the Workload API socket within the CSI volume, it defaults to spire-agent.sock
+	// +optional
+	AgentSocketName string `json:"agentSocketName,omitempty"`
+	// HelperImage is the image of the spiffe-helper sidecar
+	// +optional
+	HelperImage string `json:"helperImage,omitempty"`
+	// HelperResources are the resource requirements of the spiffe-helper sidecar
+	// +optional
+	HelperResources *corev1.ResourceRequirements `json:"helperResources,omitempty"`
+	// KeystoreReloadIntervalSeconds is how often the brokers are asked to reload the keystores of the SPIFFE
+	// listeners. It needs to be shorter than half of the lifetime of the SVIDs, it defaults to 300.
+	// +kubebuilder:validation:Minimum=30
+	// +optional
+	KeystoreReloadIntervalSeconds int32 `json:"keystoreReloadIntervalSeconds,omitempty"`
+}
+
+// GetCSIDriver returns the name of the CSI driver exposing the SPIFFE Workload API
+func (c *SPIFFEConfig) GetCSIDriver() string {
+	if c == nil || c.CSIDriver == "" {
+		return DefaultSPIFFECSIDriver
+	}
+	return c.CSIDriver
+}
+
+// GetAgentSocketName returns the name of the Workload API socket within the CSI volume
+func (c *SPIFFEConfig) GetAgentSocketName() string {
+	if c == nil || c.AgentSocketName == "" {
+		return DefaultSPIFFEAgentSocketName
+	}
+	return c.AgentSocketName
+}
+
+// GetHelperImage returns the image of the spiffe-helper sidecar
+func (c *SPIFFEConfig) GetHelperImage() string {
+	if c == nil || c.HelperImage == "" {
+		return DefaultSPIFFEHelperImage
+	}
+	return c.HelperImage
+}
+
+// GetHelperResources returns the resource requirements of the spiffe-helper sidecar
+func (c *SPIFFEConfig) GetHelperResources() corev1.ResourceRequirements {
+	if c == nil || c.HelperResources == nil {
+		return corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("32Mi"),
+			},
+		}
+	}
+	return *c.HelperResources
+}
+
+// Weekday is the name of a day of the week
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type Weekday string
+
+// WeeklyTimeWindow defines a time window on the given days of the week. When End is before Start the window spans
+// midnight and ends on the next day.
+type WeeklyTimeWindow struct {
+	// Days are the days of the week the window starts on, every day when empty
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+	// Start of the window in HH:MM format, the start of the day when empty
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +optional
+	Start string `json:"start,omitempty"`
+	// End of the window in HH:MM format, the end of the day when empty
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +optional
+	End string `json:"end,omitempty"`
+}
+
+// VolumeSnapshotOperation is an operation of the operator which is preceded by the snapshots of the volumes of the
+// affected brokers
+// +kubebuilder:validation:Enum=RollingUpgrade;ScaleDown
+type VolumeSnapshotOperation string
+
+const (
+	// VolumeSnapshotBeforeRollingUpgrade creates the snapshots of the volumes of every broker before the rolling
+	// upgrade of the brokers is started
+	VolumeSnapshotBeforeRollingUpgrade VolumeSnapshotOperation = "RollingUpgrade"
+	// VolumeSnapshotBeforeScaleDown creates the snapshots of the volumes of the removed brokers before their pods and
+	// PersistentVolumeClaims are deleted
+	VolumeSnapshotBeforeScaleDown VolumeSnapshotOperation = "ScaleDown"
+)
+
+// VolumeSnapshotConfig defines which operations are preceded by the VolumeSnapshots of the volumes of the brokers.
+// The operations are held back until the snapshots are ready to use.
+type VolumeSnapshotConfig struct {
+	// VolumeSnapshotClassName is the name of the VolumeSnapshotClass of the snapshots, the default class of the CSI
+	// driver is used when it is not set
+	// +optional
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+	// Before lists the operations the volumes are snapshotted before
+	// +kubebuilder:validation:MinItems=1
+	Before []VolumeSnapshotOperation `json:"before"`
+	// Retained is the number of the most recent snapshots kept of each PersistentVolumeClaim, the older snapshots
+	// are deleted, defaults to 3
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retained *int32 `json:"retained,omitempty"`
+}
+
+// DefaultRetainedVolumeSnapshots is the number of snapshots kept of each PersistentVolumeClaim when it is not set
+const DefaultRetainedVolumeSnapshots = 3
+
+// GetRetained returns the number of the most recent snapshots kept of each PersistentVolumeClaim
+func (c *VolumeSnapshotConfig) GetRetained() int {
+	if c == nil || c.Retained == nil {
+		return DefaultRetainedVolumeSnapshots
+	}
+	return int(*c.Retained)
+}
+
+// IsEnabledBefore returns true when the volumes of the brokers are snapshotted before the given operation
+func (c *VolumeSnapshotConfig) IsEnabledBefore(operation VolumeSnapshotOperation) bool {
+	if c == nil {
+		return false
+	}
+	for _, before := range c.Before {
+		if before == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// VolumeSnapshotReference refers to a VolumeSnapshot of a volume of a broker
+type VolumeSnapshotReference struct {
+	// Name is the name of the VolumeSnapshot in the namespace of the cluster
+	Name string `json:"name"`
+	// BrokerID is the ID of the broker the volume belongs to
+	BrokerID string `json:"brokerId"`
+	// PersistentVolumeClaimName is the name of the snapshotted PersistentVolumeClaim
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName"`
+	// Operation is the operation the volume has been snapshotted before
+	Operation VolumeSnapshotOperation `json:"operation"`
+	// CreationTime is the time when the VolumeSnapshot has been created
+	CreationTime metav1.Time `json:"creationTime"`
+	// ReadyToUse is true once the snapshot can be used to restore the volume
+	// +optional
+	ReadyToUse bool `json:"readyToUse,omitempty"`
+}
+
+// DefaultOrphanedResourceRetentionSeconds is the retention period of the orphaned resources when it is not set
+const DefaultOrphanedResourceRetentionSeconds = 86400
+
+// OrphanedResourceCleanupConfig defines how long the resources of the removed brokers are kept for. A resource is
+// orphaned when its broker is neither in the spec nor in the status of the cluster and has no pod, that is the
+// removal of the broker has been completed.
+type OrphanedResourceCleanupConfig struct {
+	// RetentionSeconds is the time an orphaned resource is kept for before it is deleted, defaults to 86400
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RetentionSeconds *int32 `json:"retentionSeconds,omitempty"`
+}
+
+// GetRetention returns the time an orphaned resource is kept for before it is deleted
+func (c *OrphanedResourceCleanupConfig) GetRetention() time.Duration {
+	if c == nil || c.RetentionSeconds == nil {
+		return DefaultOrphanedResourceRetentionSeconds * time.Second
+	}
+	return time.Duration(*c.RetentionSeconds) * time.Second
+}
+
+// OrphanedResource is a resource of a removed broker which is going to be deleted
+type OrphanedResource struct {
+	// Kind is either PersistentVolumeClaim or Service
+	Kind string `json:"kind"`
+	// Name is the name of the resource in the namespace of the cluster
+	Name string `json:"name"`
+	// BrokerID is the ID of the removed broker the resource belongs to
+	BrokerID string `json:"brokerId"`
+	// OrphanedSince is the time when the resource has been found orphaned first
+	OrphanedSince metav1.Time `json:"orphanedSince"`
+}
+
+// BrokerFailureAction is the action taken on a broker which has been down for longer than the grace period
+// +kubebuilder:validation:Enum=Alert;Replace
+type BrokerFailureAction string
+
+const (
+	// BrokerFailureActionAlert reports the failed broker with a warning event
+	BrokerFailureActionAlert BrokerFailureAction = "Alert"
+	// BrokerFailureActionReplace reports the failed broker and replaces it with a new broker of the same broker
+	// config group. The partitions of the failed broker are moved to the other brokers by Cruise Control the
+	// same way as when it is removed by hand. No broker is replaced while more than one broker is down.
+	BrokerFailureActionReplace BrokerFailureAction = "Replace"
+
+	// DefaultBrokerFailureGracePeriodSeconds is the grace period of the broker failure policy when it is not set
+	DefaultBrokerFailureGracePeriodSeconds = 1800
+)
+
+// BrokerFailurePolicy defines how the brokers which are down are handled. A broker is down when its pod is missing or
+// not ready, only the brokers which have been added to the cluster by Cruise Control are considered.
+type BrokerFailurePolicy struct {
+	// GracePeriodSeconds is the time a broker has to be down for before the action is taken, defaults to 1800
+	// +kubebuilder:validation:Minimum=60
+	// +optional
+	GracePeriodSeconds *int32 `json:"gracePeriodSeconds,omitempty"`
+	// Action is taken once the grace period has been exceeded, only an alert is raised by default
+	// +optional
+	Action BrokerFailureAction `json:"action,omitempty"`
+}
+
+// GetGracePeriod returns the time a broker has to be down for before the action of the policy is taken
+func (p *BrokerFailurePolicy) GetGracePeriod() time.Duration {
+	if p == nil || p.GracePeriodSeconds == nil {
+		return DefaultBrokerFailureGracePeriodSeconds * time.Second
+	}
+	return time.Duration(*p.GracePeriodSeconds) * time.Second
+}
+
+// GetAction returns the action taken on a broker which has been down for longer than the grace period
+func (p *BrokerFailurePolicy) GetAction() BrokerFailureAction {
+	if p == nil || p.Action == "" {
+		return BrokerFailureActionAlert
+	}
+	return p.Action
+}
+
+// CapacitySchedule defines the number of brokers of the broker config groups along a weekly calendar
+type CapacitySchedule struct {
+	// TimeZone is the IANA name of the time zone of the windows, UTC is used when it is not specified
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// Rules are evaluated in order, the first rule whose window contains the current time is applied. The brokers are
+	// left unchanged when none of the rules matches.
+	// +kubebuilder:validation:MinItems=1
+	Rules []CapacityScheduleRule `json:"rules"`
+	// MaintenanceWindows restricts the changes of the brokers to the given windows, the brokers can be changed at any
+	// time when it is empty
+	// +optional
+	MaintenanceWindows []WeeklyTimeWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// CapacityScheduleRule defines the number of brokers of the broker config groups inside a weekly time window
+type CapacityScheduleRule struct {
+	// Name identifies the rule in the status
+	Name string `json:"name"`
+	// Window is the time window the rule is applied in
+	Window WeeklyTimeWindow `json:"window"`
+	// BrokerCounts are the numbers of brokers keyed by the name of the broker config group, the groups which are not
+	// listed are left unchanged
+	BrokerCounts map[string]int32 `json:"brokerCounts"`
+}
+
+// location returns the time zone of the schedule
+func (s *CapacitySchedule) location() (*time.Location, error) {
+	if s.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.TimeZone)
+}
+
+// ActiveRule returns the first rule of the schedule whose window contains the given time, nil when none of them does
+func (s *CapacitySchedule) ActiveRule(t time.Time) (*CapacityScheduleRule, error) {
+	location, err := s.location()
+	if err != nil {
+		return nil, err
+	}
+	for i := range s.Rules {
+		contains, err := s.Rules[i].Window.Contains(t.In(location))
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "invalid capacity schedule rule", "rule", s.Rules[i].Name)
+		}
+		if contains {
+			return &s.Rules[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// InMaintenanceWindow returns true when the brokers can be changed at the given time
+func (s *CapacitySchedule) InMaintenanceWindow(t time.Time) (bool, error) {
+	if len(s.MaintenanceWindows) == 0 {
+		return true, nil
+	}
+	location, err := s.location()
+	if err != nil {
+		return false, err
+	}
+	for _, window := range s.MaintenanceWindows {
+		contains, err := window.Contains(t.In(location))
+		if err != nil {
+			return false, errors.WrapIf(err, "invalid maintenance window")
+		}
+		if contains {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Contains returns true when the given time, in the time zone of the window, is inside of the window
+func (w WeeklyTimeWindow) Contains(t time.Time) (bool, error) {
+	start, end := 0, 24*60
+	var err error
+	if w.Start != "" {
+		if start, err = ParseMinuteOfDay(w.Start); err != nil {
+			return false, err
+		}
+	}
+	if w.End != "" {
+		if end, err = ParseMinuteOfDay(w.End); err != nil {
+			return false, err
+		}
+	}
+	minuteOfDay := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return w.startsOn(t.Weekday()) && minuteOfDay >= start && minuteOfDay < end, nil
+	}
+	// The window spans midnight, its part after midnight belongs to the window started on the previous day
+	return (w.startsOn(t.Weekday()) && minuteOfDay >= start) ||
+		(w.startsOn((t.Weekday()+6)%7) && minuteOfDay < end), nil
 }
 
-// GetLoadBalancerSourceRanges returns LoadBalancerSourceRanges to use for Istio Meshagetway generated LoadBalancer
-func (iIConfig *IstioIngressConfig) GetLoadBalancerSourceRanges() []string {
-	return iIConfig.LoadBalancerSourceRanges
+func (w WeeklyTimeWindow) startsOn(weekday time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, day := range w.Days {
+		if string(day) == weekday.String() {
+			return true
+		}
+	}
+	return false
 }
 
-// MonitoringConfig defines the config for monitoring Kafka and Cruise Control
-type MonitoringConfig struct {
-	JmxImage               string `json:"jmxImage,omitempty"`
-	PathToJar              string `json:"pathToJar,omitempty"`
-	KafkaJMXExporterConfig string `json:"kafkaJMXExporterConfig,omitempty"`
-	CCJMXExporterConfig    string `json:"cCJMXExporterConfig,omitempty"`
+// ParseMinuteOfDay returns the number of minutes elapsed since midnight for a time in HH:MM format
+func ParseMinuteOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
 }
 
-// StorageConfig defines the broker storage configuration
-type StorageConfig struct {
-	MountPath string `json:"mountPath"`
-
-	// If set https://kubernetes.io/docs/concepts/storage/volumes/#persistentvolumeclaim is used
-	// as storage for Kafka broker log dirs. Either `pvcSpec` or `emptyDir` has to be set.
-	// When both `pvcSpec` and `emptyDir` fields are set
-	// the `pvcSpec` is used by default.
+// ZoneBalancingConfig configures the zone balance report and its enforcement
+type ZoneBalancingConfig struct {
+	// CheckIntervalSeconds is how often the distribution is computed, 300 by default
+	// +kubebuilder:validation:Minimum=30
 	// +optional
-	PvcSpec *corev1.PersistentVolumeClaimSpec `json:"pvcSpec,omitempty"`
-
-	// If set https://kubernetes.io/docs/concepts/storage/volumes#emptydir is used
-	// as storage for Kafka broker log dirs. The use of empty dir as Kafka broker storage is useful in development
-	// environments where data loss is not a concern as data stored on emptydir backed storage is lost at pod restarts.
-	// Either `pvcSpec` or `emptyDir` has to be set.
-	// When both `pvcSpec` and `emptyDir` fields are set
-	// the `pvcSpec` is used by default.
+	CheckIntervalSeconds int32 `json:"checkIntervalSeconds,omitempty"`
+	// RebalanceSkewThresholdPercent enables creating a rebalance CruiseControlOperation with the rack-aware goals when
+	// the replica or leader skew of the zones exceeds it
+	// +kubebuilder:validation:Minimum=1
 	// +optional
-	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
-}
-
-// ListenersConfig defines the Kafka listener types
-type ListenersConfig struct {
-	ExternalListeners  []ExternalListenerConfig `json:"externalListeners,omitempty"`
-	InternalListeners  []InternalListenerConfig `json:"internalListeners"`
-	SSLSecrets         *SSLSecrets              `json:"sslSecrets,omitempty"`
-	ServiceAnnotations map[string]string        `json:"serviceAnnotations,omitempty"`
+	RebalanceSkewThresholdPercent *int32 `json:"rebalanceSkewThresholdPercent,omitempty"`
+	// Goals are the Cruise Control goals of the rebalance operation, the rack-aware distribution, the replica
+	// distribution and the leader replica distribution goals by default
+	// +optional
+	Goals []string `json:"goals,omitempty"`
 }
 
-// GetServiceAnnotations returns a copy of the ServiceAnnotations field.
-func (c ListenersConfig) GetServiceAnnotations() map[string]string {
-	return util.CloneMap(c.ServiceAnnotations)
+// GetCheckIntervalSeconds returns how often the distribution of the partitions across the zones is computed
+func (c *ZoneBalancingConfig) GetCheckIntervalSeconds() int32 {
+	if c == nil || c.CheckIntervalSeconds == 0 {
+		return DefaultZoneBalancingCheckIntervalSeconds
+	}
+	return c.CheckIntervalSeconds
 }
 
-func (c ExternalListenerConfig) GetAccessMethod() corev1.ServiceType {
-	if c.AccessMethod == "" {
-		return corev1.ServiceTypeLoadBalancer
+// GetGoals returns the Cruise Control goals of the rebalance operation restoring the balance of the zones
+func (c *ZoneBalancingConfig) GetGoals() []string {
+	if c == nil || len(c.Goals) == 0 {
+		return []string{"RackAwareDistributionGoal", "ReplicaDistributionGoal", "LeaderReplicaDistributionGoal"}
 	}
-	return c.AccessMethod
+	return c.Goals
 }
 
-func (c ExternalListenerConfig) GetAnyCastPort() int32 {
-	if c.AnyCastPort == nil {
-		return DefaultAnyCastPort
-	}
-	return *c.AnyCastPort
+// ReplicationFactorRepairConfig configures the detection and the repair of the partitions which lost replicas
+type ReplicationFactorRepairConfig struct {
+	// CheckIntervalSeconds is how often the partitions are checked, 300 by default
+	// +kubebuilder:validation:Minimum=30
+	// +optional
+	CheckIntervalSeconds int32 `json:"checkIntervalSeconds,omitempty"`
+	// ReportOnly disables creating the fix_offline_replicas CruiseControlOperations, the under-replicated partitions
+	// are only reported in the status of the cluster
+	// +optional
+	ReportOnly bool `json:"reportOnly,omitempty"`
 }
 
-// GetServiceAnnotations returns a copy of the ServiceAnnotations field.
-func (c IngressServiceSettings) GetServiceAnnotations() map[string]string {
-	return util.CloneMap(c.ServiceAnnotations)
+// GetCheckIntervalSeconds returns how often the partitions are checked for replicas on the lost brokers
+func (c *ReplicationFactorRepairConfig) GetCheckIntervalSeconds() int32 {
+	if c == nil || c.CheckIntervalSeconds == 0 {
+		return DefaultReplicationFactorRepairCheckIntervalSeconds
+	}
+	return c.CheckIntervalSeconds
 }
 
-// GetServiceType returns the field value of ServiceType defaults to LoadBalancer.
-func (c IngressServiceSettings) GetServiceType() corev1.ServiceType {
-	if c.ServiceType == "" {
-		return corev1.ServiceTypeLoadBalancer
+// GetKeystoreReloadIntervalSeconds returns how often the brokers are asked to reload the keystores of the SPIFFE listeners
+func (c *SPIFFEConfig) GetKeystoreReloadIntervalSeconds() int32 {
+	if c == nil || c.KeystoreReloadIntervalSeconds == 0 {
+		return DefaultSPIFFEKeystoreReloadIntervalSeconds
 	}
-	return c.ServiceType
+	return c.KeystoreReloadIntervalSeconds
 }
 
-// SSLSecrets defines the Kafka SSL secrets
-type SSLSecrets struct {
-	TLSSecretName   string                  `json:"tlsSecretName"`
-	JKSPasswordName string                  `json:"jksPasswordName,omitempty"`
-	Create          bool                    `json:"create,omitempty"`
-	IssuerRef       *cmmeta.ObjectReference `json:"issuerRef,omitempty"`
-	// +kubebuilder:validation:Enum={"cert-manager"}
-	PKIBackend PKIBackend `json:"pkiBackend,omitempty"`
+// IsEnabled returns true when the NetworkPolicies of the Kafka cluster need to be generated
+func (c *NetworkPolicyConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
 }
 
-// TODO (tinyzimmer): The above are all optional now in one way or another.
-// Would be another good use-case for a pre-admission hook
-// E.g. TLSSecretName and JKSPasswordName are only required if Create is false
-// Or heck, do we even want to bother supporting an imported PKI?
+// GetOperatorPeers returns the peers selecting the pods of the operator
+func (c *NetworkPolicyConfig) GetOperatorPeers() []networkingv1.NetworkPolicyPeer {
+	if c == nil || len(c.OperatorPeers) == 0 {
+		return []networkingv1.NetworkPolicyPeer{
+			{
+				NamespaceSelector: &metav1.LabelSelector{},
+				PodSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app.kubernetes.io/name": "kafka-operator"},
+				},
+			},
+		}
+	}
+	return c.OperatorPeers
+}
 
 // AlertManagerConfig defines configuration for alert manager
 type AlertManagerConfig struct {
@@ -526,6 +2404,14 @@ type IngressServiceSettings struct {
 	// Only "NodePort" and "LoadBalancer" is supported.
 	// Default value is LoadBalancer
 	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+	// LoadBalancerSourceRanges restricts the client IP ranges allowed to access the LoadBalancer service
+	// created for the external listener. When set it takes precedence over the source ranges of the ingress controller config.
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+	// LoadBalancerClass is the class of the load balancer implementation the LoadBalancer service created for the
+	// external listener belongs to. It is supported only with Envoy ingress controller and cannot be changed once set.
+	// +optional
+	LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
 }
 
 // ExternalListenerConfig defines the external listener config for Kafka
@@ -551,6 +2437,27 @@ type ExternalListenerConfig struct {
 	// if set overrides the the default `KafkaClusterSpec.IstioIngressConfig` or `KafkaClusterSpec.EnvoyConfig` for this external listener.
 	// +optional
 	Config *Config `json:"config,omitempty"`
+	// AccessControl defines the client IP based access control enforced by Envoy on the external listener.
+	// It is supported only with Envoy ingress controller and LoadBalancer access method. The client IP is preserved
+	// only when the externalTrafficPolicy of the listener is Local.
+	// +optional
+	AccessControl *EnvoyAccessControl `json:"accessControl,omitempty"`
+}
+
+// EnvoyAccessControl defines the client IP ranges allowed or denied by the Envoy RBAC network filter of an external listener
+type EnvoyAccessControl struct {
+	// AllowedSourceRanges lists the client IP ranges in CIDR notation allowed to connect to the listener.
+	// All clients are allowed when empty.
+	// +optional
+	AllowedSourceRanges []string `json:"allowedSourceRanges,omitempty"`
+	// DeniedSourceRanges lists the client IP ranges in CIDR notation rejected by the listener.
+	// It takes precedence over AllowedSourceRanges.
+	// +optional
+	DeniedSourceRanges []string `json:"deniedSourceRanges,omitempty"`
+	// ShadowMode makes Envoy only record the connections which would be rejected in the shadow rule statistics
+	// instead of rejecting them, so that the access control can be tried out before it is enforced.
+	// +optional
+	ShadowMode bool `json:"shadowMode,omitempty"`
 }
 
 // Config defines the external access ingress controller configuration
@@ -570,6 +2477,26 @@ type InternalListenerConfig struct {
 	CommonListenerSpec              `json:",inline"`
 	UsedForInnerBrokerCommunication bool `json:"usedForInnerBrokerCommunication"`
 	UsedForControllerCommunication  bool `json:"usedForControllerCommunication,omitempty"`
+	// UsedForReplication makes the brokers replicate over this listener instead of the one used for inner broker
+	// communication, which is still used by the operator, Cruise Control and the metrics reporters
+	// +optional
+	UsedForReplication bool `json:"usedForReplication,omitempty"`
+	// NetworkAttachment binds the listener to a secondary network of the broker pods attached by Multus, the brokers
+	// advertise their address on that network. It cannot be set on the listener used for inner broker communication
+	// as the operator and Cruise Control are not attached to the secondary network.
+	// +optional
+	NetworkAttachment *ListenerNetworkAttachment `json:"networkAttachment,omitempty"`
+}
+
+// ListenerNetworkAttachment defines the secondary network of the broker pods a listener is bound to
+type ListenerNetworkAttachment struct {
+	// Name of the Multus NetworkAttachmentDefinition in the [namespace/]name format, the namespace defaults to the
+	// namespace of the KafkaCluster
+	Name string `json:"name"`
+	// Interface is the name of the network interface of the secondary network in the broker pods, e.g. net1
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=^[a-z0-9][a-z0-9.-]*$
+	Interface string `json:"interface"`
 }
 
 // CommonListenerSpec defines the common building block for Listener type
@@ -580,10 +2507,27 @@ type CommonListenerSpec struct {
 	// The secret must contain the keystore, truststore jks files and the password for them in base64 encoded format under the keystore.jks, truststore.jks, password data fields.
 	// If this field is omitted koperator will auto-create a self-signed server certificate using the configuration provided in 'sslSecrets' field.
 	ServerSSLCertSecret *corev1.LocalObjectReference `json:"serverSSLCertSecret,omitempty"`
+	// CertManager requests a dedicated server certificate for the listener from cert-manager instead of the one shared by
+	// the listeners which are configured by the 'sslSecrets' field. It is ignored when 'serverSSLCertSecret' is set.
+	// +optional
+	CertManager *ListenerCertManagerConfig `json:"certManager,omitempty"`
+	// SPIFFE makes the SSL listener use the X.509 SVID of the broker obtained from SPIRE as server certificate and the
+	// SPIFFE trust bundle, extended with the CA of the cluster, as truststore. It requires 'spiffeConfig' to be set.
+	// When the listener is used for inner broker communication the SPIFFE trust bundle needs to be added to
+	// 'additionalTrustedCAs' so that the operator and Cruise Control accept the SVIDs of the brokers.
+	// +optional
+	SPIFFE bool `json:"spiffe,omitempty"`
 	// SSLClientAuth specifies whether client authentication is required, requested, or not required.
-	// This field defaults to "required" if it is omitted
+	// This field defaults to "required" if it is omitted. It can only be set on ssl and sasl_ssl listeners.
 	// +kubebuilder:validation:Enum=required;requested;none
 	SSLClientAuth SSLClientAuthentication `json:"sslClientAuth,omitempty"`
+	// TLSPolicy restricts the TLS protocol versions and cipher suites accepted by the listener
+	// +optional
+	TLSPolicy *ListenerTLSPolicy `json:"tlsPolicy,omitempty"`
+	// ConnectionLimits limits the number of client connections accepted by the listener of each broker, on top of the
+	// limits of the broker defined in 'connectionLimits' of the listeners config
+	// +optional
+	ConnectionLimits *ListenerConnectionLimits `json:"connectionLimits,omitempty"`
 	// +kubebuilder:validation:Pattern=^[a-z0-9\-]+
 	Name string `json:"name"`
 	// +kubebuilder:validation:Minimum=0
@@ -599,6 +2543,77 @@ func (c *CommonListenerSpec) GetServerSSLCertSecretName() string {
 	return c.ServerSSLCertSecret.Name
 }
 
+// HasDedicatedCertManagerCertificate returns true when the server certificate of the listener is issued by cert-manager
+// separately from the one shared by the listeners
+func (c *CommonListenerSpec) HasDedicatedCertManagerCertificate() bool {
+	return c.Type == SecurityProtocolSSL && !c.SPIFFE && c.ServerSSLCertSecret == nil && c.CertManager != nil
+}
+
+// UsesSPIFFE returns true when the server certificate of the listener is the X.509 SVID of the broker
+func (c *CommonListenerSpec) UsesSPIFFE() bool {
+	return c.Type == SecurityProtocolSSL && c.SPIFFE
+}
+
+// ListenerTLSPolicy defines the TLS protocol versions, cipher suites and keystore formats of an SSL listener
+type ListenerTLSPolicy struct {
+	// EnabledProtocols is the list of TLS protocol versions accepted by the listener, the newest one is used as
+	// ssl.protocol. The default of the broker is used when it is empty.
+	// +optional
+	EnabledProtocols []TLSProtocol `json:"enabledProtocols,omitempty"`
+	// CipherSuites is the list of cipher suites accepted by the listener using the JSSE names,
+	// e.g. TLS_AES_256_GCM_SHA384. The default of the broker is used when it is empty.
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+	// KeyStoreType is the format of the keystore of the listener, it defaults to JKS.
+	// PKCS12 can only be used with a custom server certificate provided by 'serverSSLCertSecret',
+	// the secret has to hold the PKCS12 keystore under the 'keystore.p12' key.
+	// +kubebuilder:validation:Enum=JKS;PKCS12
+	// +optional
+	KeyStoreType KeyStoreType `json:"keyStoreType,omitempty"`
+	// TrustStoreType is the format of the truststore of the listener, it defaults to JKS.
+	// PKCS12 can only be used with a custom server certificate provided by 'serverSSLCertSecret',
+	// the secret has to hold the PKCS12 truststore under the 'truststore.p12' key.
+	// +kubebuilder:validation:Enum=JKS;PKCS12
+	// +optional
+	TrustStoreType KeyStoreType `json:"trustStoreType,omitempty"`
+}
+
+// GetKeyStoreType returns the format of the keystore of the listener
+func (p *ListenerTLSPolicy) GetKeyStoreType() KeyStoreType {
+	if p == nil || p.KeyStoreType == "" {
+		return KeyStoreTypeJKS
+	}
+	return p.KeyStoreType
+}
+
+// GetTrustStoreType returns the format of the truststore of the listener
+func (p *ListenerTLSPolicy) GetTrustStoreType() KeyStoreType {
+	if p == nil || p.TrustStoreType == "" {
+		return KeyStoreTypeJKS
+	}
+	return p.TrustStoreType
+}
+
+// ListenerCertManagerConfig defines the server certificate of a listener issued by cert-manager
+type ListenerCertManagerConfig struct {
+	// IssuerRef is the cert-manager Issuer or ClusterIssuer which signs the server certificate of the listener,
+	// e.g. an ACME issuer for public-facing listeners. The issuer of the 'sslSecrets' field is used when it is omitted.
+	// +optional
+	IssuerRef *cmmeta.ObjectReference `json:"issuerRef,omitempty"`
+	// DNSNames are subject alternative names added to the server certificate besides the internal names of the
+	// brokers and the addresses of the listener. The {clusterName} and {namespace} placeholders are replaced with
+	// the name and namespace of the KafkaCluster, names containing {brokerId} are added once for every broker.
+	// +optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+	// Duration is the requested lifetime of the server certificate, the default of cert-manager is used when it is omitted
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// RenewBefore is how long before the expiry the server certificate is renewed, the default of cert-manager is used
+	// when it is omitted
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
 // ListenerStatuses holds information about the statuses of the configured listeners.
 // The internal and external listeners are stored in separate maps, and each listener can be looked up by name.
 type ListenerStatuses struct {
@@ -742,6 +2757,44 @@ func (kSpec *KafkaClusterSpec) GetClusterImage() string {
 	return "ghcr.io/banzaicloud/kafka:2.13-3.1.0"
 }
 
+// GetImage returns the given image with its registry replaced by the image registry of the Kafka cluster
+func (kSpec *KafkaClusterSpec) GetImage(image string) string {
+	registry := strings.TrimSuffix(kSpec.ImageRegistry, "/")
+	if registry == "" || image == "" {
+		return image
+	}
+	// The first component of the reference is a registry when it is a host name or contains a port
+	if i := strings.Index(image, "/"); i > 0 {
+		if host := image[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			image = image[i+1:]
+		}
+	}
+	return registry + "/" + image
+}
+
+// GetImagePullSecrets returns the given image pull secrets of a component extended with the image pull secrets
+// of the Kafka cluster
+func (kSpec *KafkaClusterSpec) GetImagePullSecrets(secrets []corev1.LocalObjectReference) []corev1.LocalObjectReference {
+	if len(kSpec.ImagePullSecrets) == 0 {
+		return secrets
+	}
+	ret := make([]corev1.LocalObjectReference, 0, len(secrets)+len(kSpec.ImagePullSecrets))
+	ret = append(ret, secrets...)
+	for _, secret := range kSpec.ImagePullSecrets {
+		found := false
+		for _, s := range secrets {
+			if s.Name == secret.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, secret)
+		}
+	}
+	return ret
+}
+
 // GetClusterMetricsReporterImage returns the default container image for Kafka Cluster
 func (kSpec *KafkaClusterSpec) GetClusterMetricsReporterImage() string {
 	if kSpec.ClusterMetricsReporterImage != "" {
@@ -835,6 +2888,16 @@ func (cConfig *CruiseControlConfig) GetNodeSelector() map[string]string {
 	return cConfig.NodeSelector
 }
 
+// GetAffinity returns the Affinity config for cruise control
+func (cConfig *CruiseControlConfig) GetAffinity() *corev1.Affinity {
+	return cConfig.Affinity
+}
+
+// GetTopologySpreadConstraints returns the topology spread constraints for cruise control
+func (cConfig *CruiseControlConfig) GetTopologySpreadConstraints() []corev1.TopologySpreadConstraint {
+	return cConfig.TopologySpreadConstraints
+}
+
 // GetNodeSelector returns the node selector for envoy
 func (eConfig *EnvoyConfig) GetNodeSelector() map[string]string {
 	return eConfig.NodeSelector
@@ -855,9 +2918,50 @@ func (eConfig *EnvoyConfig) GetPriorityClassName() string {
 	return eConfig.PriorityClassName
 }
 
+// IsRestrictedPodSecurityStandard returns true when the pods of the Kafka cluster need to comply with the restricted
+// Pod Security Standard
+func (kSpec *KafkaClusterSpec) IsRestrictedPodSecurityStandard() bool {
+	return kSpec.PodSecurityStandard == PodSecurityStandardRestricted
+}
+
+// GetNetworkAttachedListeners returns the internal listeners which are bound to a secondary network of the broker pods
+func (kSpec *KafkaClusterSpec) GetNetworkAttachedListeners() []InternalListenerConfig {
+	var listeners []InternalListenerConfig
+	for _, iListener := range kSpec.ListenersConfig.InternalListeners {
+		if iListener.NetworkAttachment != nil {
+			listeners = append(listeners, iListener)
+		}
+	}
+	return listeners
+}
+
+// GetSPIFFEListeners returns the common spec of the listeners using the X.509 SVIDs of the brokers
+func (kSpec *KafkaClusterSpec) GetSPIFFEListeners() []CommonListenerSpec {
+	var listeners []CommonListenerSpec
+	for _, iListener := range kSpec.ListenersConfig.InternalListeners {
+		if iListener.UsesSPIFFE() {
+			listeners = append(listeners, iListener.CommonListenerSpec)
+		}
+	}
+	for _, eListener := range kSpec.ListenersConfig.ExternalListeners {
+		if eListener.UsesSPIFFE() {
+			listeners = append(listeners, eListener.CommonListenerSpec)
+		}
+	}
+	return listeners
+}
+
 // GetNodeSelector returns the node selector for the given broker
 func (bConfig *BrokerConfig) GetNodeSelector() map[string]string {
-	return bConfig.NodeSelector
+	if bConfig.Architecture == "" {
+		return bConfig.NodeSelector
+	}
+	if _, ok := bConfig.NodeSelector[corev1.LabelArchStable]; ok {
+		return bConfig.NodeSelector
+	}
+	nodeSelector := util.CloneMap(bConfig.NodeSelector)
+	nodeSelector[corev1.LabelArchStable] = bConfig.Architecture
+	return nodeSelector
 }
 
 // GetPriorityClassName returns the priority class name for the given broker
@@ -884,6 +2988,19 @@ func (bConfig *BrokerConfig) GetBrokerLabels(kafkaClusterName string, brokerId i
 	)
 }
 
+// GetClientTLSSecretName returns the name of the secret holding the client certificate of the operator for Cruise Control
+func (cConfig *CruiseControlConfig) GetClientTLSSecretName(clusterName string) string {
+	return fmt.Sprintf("%s-cruisecontrol-client", clusterName)
+}
+
+// GetClientTLSPKIBackend returns the PKI backend issuing the client certificate of the operator for Cruise Control
+func (cConfig *CruiseControlConfig) GetClientTLSPKIBackend() PKIBackend {
+	if cConfig.ClientTLS == nil || cConfig.ClientTLS.PKIBackend == "" {
+		return PKIBackendCertManager
+	}
+	return cConfig.ClientTLS.PKIBackend
+}
+
 // GetCruiseControlAnnotations return the annotations which applied to CruiseControl pod
 func (cConfig *CruiseControlConfig) GetCruiseControlAnnotations() map[string]string {
 	return util.CloneMap(cConfig.CruiseControlAnnotations)
@@ -949,6 +3066,25 @@ func (cConfig *CruiseControlConfig) GetResources() *corev1.ResourceRequirements
 	}
 }
 
+// GetReadinessProbe returns the readiness probe of the CC container
+func (cConfig *CruiseControlConfig) GetReadinessProbe() *corev1.Probe {
+	if cConfig.ReadinessProbe != nil {
+		return cConfig.ReadinessProbe
+	}
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt(8090),
+			},
+		},
+		TimeoutSeconds:      1,
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       10,
+		FailureThreshold:    3,
+		SuccessThreshold:    1,
+	}
+}
+
 // GetResources returns the broker specific Kubernetes resource
 func (bConfig *BrokerConfig) GetResources() *corev1.ResourceRequirements {
 	if bConfig.Resources != nil {
@@ -1017,6 +3153,51 @@ func (cConfig *CruiseControlConfig) GetCCImage() string {
 	return "ghcr.io/banzaicloud/cruise-control:2.5.101"
 }
 
+// GetVersion returns the version of Cruise Control, which defaults to the tag of the image
+func (cConfig *CruiseControlConfig) GetVersion() string {
+	if cConfig.Version != "" {
+		return cConfig.Version
+	}
+	image := cConfig.GetCCImage()
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i >= 0 && !strings.Contains(image[i:], "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// IsSampleStoreEnabled returns true when the metric samples of Cruise Control are persisted in Kafka topics
+func (cConfig *CruiseControlConfig) IsSampleStoreEnabled() bool {
+	return cConfig.SampleStore != nil && cConfig.SampleStore.Enabled
+}
+
+// GetLog4jConfigMapNames returns the sorted names of the ConfigMaps holding the logging configuration of the brokers
+// and Cruise Control
+func (kSpec *KafkaClusterSpec) GetLog4jConfigMapNames() []string {
+	names := make(map[string]struct{})
+	for _, brokerConfig := range kSpec.BrokerConfigGroups {
+		if brokerConfig.Log4jConfigMap != nil {
+			names[brokerConfig.Log4jConfigMap.Name] = struct{}{}
+		}
+	}
+	for _, broker := range kSpec.Brokers {
+		if broker.BrokerConfig != nil && broker.BrokerConfig.Log4jConfigMap != nil {
+			names[broker.BrokerConfig.Log4jConfigMap.Name] = struct{}{}
+		}
+	}
+	if kSpec.CruiseControlConfig.Log4jConfigMap != nil {
+		names[kSpec.CruiseControlConfig.Log4jConfigMap.Name] = struct{}{}
+	}
+	ret := make([]string, 0, len(names))
+	for name := range names {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
 // GetCCLog4jConfig returns the used Cruise Control log4j configuration
 func (cConfig *CruiseControlConfig) GetCCLog4jConfig() string {
 	if cConfig.Log4jConfig != "" {
@@ -1041,6 +3222,188 @@ func (mConfig *MonitoringConfig) GetPathToJar() string {
 	return "/jmx_prometheus_javaagent.jar"
 }
 
+// RetainsData returns true when the PersistentVolumeClaims of the brokers are kept after the KafkaCluster is deleted
+func (kSpec *KafkaClusterSpec) RetainsData() bool {
+	return kSpec.DeletionPolicy == DeletionPolicyRetain
+}
+
+// GetBrokerIDPolicy returns the policy of the broker ID assignment, ReuseLowestFree by default
+func (kSpec *KafkaClusterSpec) GetBrokerIDPolicy() BrokerIDPolicy {
+	if kSpec.BrokerIDPolicy == "" {
+		return BrokerIDPolicyReuseLowestFree
+	}
+	return kSpec.BrokerIDPolicy
+}
+
+// GetDriftPolicy returns how the out-of-band changes of the managed resources are handled, Enforce by default
+func (kSpec *KafkaClusterSpec) GetDriftPolicy() DriftPolicy {
+	if kSpec.DriftPolicy == "" {
+		return DriftPolicyEnforce
+	}
+	return kSpec.DriftPolicy
+}
+
+// IsDryRun returns true when the dry-run annotation is set on the KafkaCluster
+func (k *KafkaCluster) IsDryRun() bool {
+	return k.GetAnnotations()[DryRunAnnotationKey] == "true"
+}
+
+// IsBootstrapping returns true while the cluster is being created, that is until it reaches the running state for
+// the first time. The brokers hold no data yet, so they do not need to be brought up one after the other.
+func (k *KafkaCluster) IsBootstrapping() bool {
+	return meta.IsStatusConditionTrue(k.Status.Conditions, BootstrappingCondition)
+}
+
+// IsBrokerConfigGroupParked returns true when the broker config group is listed in the parked broker config groups
+func (kSpec *KafkaClusterSpec) IsBrokerConfigGroupParked(group string) bool {
+	for _, parked := range kSpec.ParkedBrokerConfigGroups {
+		if parked == group {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBrokerParked returns true when the broker belongs to a broker config group whose brokers are being drained or
+// have been scaled to zero, the resources of these brokers are not reconciled
+func (k *KafkaCluster) IsBrokerParked(broker Broker) bool {
+	if broker.BrokerConfigGroup == "" {
+		return false
+	}
+	parking, ok := k.Status.BrokerGroupParking[broker.BrokerConfigGroup]
+	return ok && (parking.State == BrokerGroupDraining || parking.State == BrokerGroupParked)
+}
+
+// GetHighestBrokerID returns the highest ID of the current, the former and the retired brokers of the cluster,
+// the second return value is false when the cluster has never had any broker
+func (k *KafkaCluster) GetHighestBrokerID() (int32, bool) {
+	var highest int32
+	found := false
+	check := func(id int32) {
+		if !found || id > highest {
+			highest = id
+			found = true
+		}
+	}
+	if k.Status.HighestBrokerID != nil {
+		check(*k.Status.HighestBrokerID)
+	}
+	for _, broker := range k.Spec.Brokers {
+		check(broker.Id)
+	}
+	for brokerID := range k.Status.BrokersState {
+		if id, err := strconv.ParseInt(brokerID, 10, 32); err == nil {
+			check(int32(id))
+		}
+	}
+	for _, id := range k.Status.RetiredBrokerIDs {
+		check(id)
+	}
+	return highest, found
+}
+
+// IsBrokerIDRetired returns true when the ID has been retired by a KafkaBrokerDecommission and must not be reused
+func (k *KafkaCluster) IsBrokerIDRetired(brokerID int32) bool {
+	for _, id := range k.Status.RetiredBrokerIDs {
+		if id == brokerID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetNextBrokerID returns the ID which the next broker added to the cluster gets according to the broker ID policy.
+// The reserved IDs are skipped as well, e.g. the IDs of the former brokers whose data is still kept on a
+// PersistentVolumeClaim.
+func (k *KafkaCluster) GetNextBrokerID(reservedIDs ...int32) int32 {
+	used := make(map[int32]struct{}, len(k.Spec.Brokers)+len(reservedIDs))
+	for _, id := range reservedIDs {
+		used[id] = struct{}{}
+	}
+
+	if k.Spec.GetBrokerIDPolicy() == BrokerIDPolicyAlwaysIncrement {
+		var next int32
+		if highest, ok := k.GetHighestBrokerID(); ok {
+			next = highest + 1
+		}
+		for ; ; next++ {
+			if _, ok := used[next]; !ok {
+				return next
+			}
+		}
+	}
+
+	for _, broker := range k.Spec.Brokers {
+		used[broker.Id] = struct{}{}
+	}
+	for brokerID := range k.Status.BrokersState {
+		if id, err := strconv.ParseInt(brokerID, 10, 32); err == nil {
+			used[int32(id)] = struct{}{}
+		}
+	}
+	for _, id := range k.Status.RetiredBrokerIDs {
+		used[id] = struct{}{}
+	}
+	var next int32
+	for ; ; next++ {
+		if _, ok := used[next]; !ok {
+			return next
+		}
+	}
+}
+
+// GetBackoffLimit returns the number of retries of the hook Job
+func (hook *HookJob) GetBackoffLimit() int32 {
+	if hook.BackoffLimit != nil {
+		return *hook.BackoffLimit
+	}
+	return 3
+}
+
+// GetAuthorizer returns the authorizer plugin of the brokers, ACL by default
+func (c *AuthorizationConfig) GetAuthorizer() AuthorizerType {
+	if c.Authorizer == "" {
+		return AuthorizerTypeACL
+	}
+	return c.Authorizer
+}
+
+// GetAuthorizerClassName returns the class name of the authorizer plugin of the brokers
+func (c *AuthorizationConfig) GetAuthorizerClassName() string {
+	switch c.GetAuthorizer() {
+	case AuthorizerTypeOPA:
+		return "org.openpolicyagent.kafka.OpaAuthorizer"
+	case AuthorizerTypeCustom:
+		return c.CustomAuthorizerClassName
+	default:
+		return "kafka.security.authorizer.AclAuthorizer"
+	}
+}
+
+// GetMaxLifetimeSeconds returns the lifetime of the delegation tokens which they cannot be renewed beyond
+func (c *DelegationTokenConfig) GetMaxLifetimeSeconds() int64 {
+	if c.MaxLifetimeSeconds != nil {
+		return *c.MaxLifetimeSeconds
+	}
+	return 7 * 24 * 60 * 60
+}
+
+// GetExpiryTimeSeconds returns the validity of the delegation tokens which are not renewed
+func (c *DelegationTokenConfig) GetExpiryTimeSeconds() int64 {
+	if c.ExpiryTimeSeconds != nil {
+		return *c.ExpiryTimeSeconds
+	}
+	return 24 * 60 * 60
+}
+
+// GetMethod returns the method used by the broker readiness gate
+func (gate *BrokerReadinessGate) GetMethod() BrokerReadinessGateMethod {
+	if gate.Method == "" {
+		return BrokerReadinessGateAdminAPI
+	}
+	return gate.Method
+}
+
 // GetKafkaJMXExporterConfig returns the config for Kafka Prometheus JMX exporter
 func (mConfig *MonitoringConfig) GetKafkaJMXExporterConfig() string {
 	if mConfig.KafkaJMXExporterConfig != "" {
@@ -1079,6 +3442,8 @@ func (b *Broker) GetBrokerConfig(kafkaClusterSpec KafkaClusterSpec) (*BrokerConf
 		return nil, errors.WrapIf(err, "could not merge brokerConfig.Affinity with ConfigGroup.Affinity")
 	}
 	envs := mergeEnvs(kafkaClusterSpec, &groupConfig, bConfig)
+	envFrom := mergeEnvFrom(kafkaClusterSpec, &groupConfig, bConfig)
+	jvmOptions := mergeJVMOptions(kafkaClusterSpec.JVMOptions, groupConfig.JVMOptions, bConfig.JVMOptions)
 
 	err = mergo.Merge(bConfig, groupConfig, mergo.WithAppendSlice)
 	if err != nil {
@@ -1090,6 +3455,20 @@ func (b *Broker) GetBrokerConfig(kafkaClusterSpec KafkaClusterSpec) (*BrokerConf
 		bConfig.Affinity = dstAffinity
 	}
 	bConfig.Envs = envs
+	bConfig.EnvFrom = envFrom
+	bConfig.JVMOptions = jvmOptions
+	// Probes are not merged field by field as a probe with multiple handlers is invalid
+	if b.BrokerConfig != nil {
+		if b.BrokerConfig.StartupProbe != nil {
+			bConfig.StartupProbe = b.BrokerConfig.StartupProbe.DeepCopy()
+		}
+		if b.BrokerConfig.LivenessProbe != nil {
+			bConfig.LivenessProbe = b.BrokerConfig.LivenessProbe.DeepCopy()
+		}
+		if b.BrokerConfig.ReadinessProbe != nil {
+			bConfig.ReadinessProbe = b.BrokerConfig.ReadinessProbe.DeepCopy()
+		}
+	}
 
 	return bConfig, nil
 }
@@ -1104,6 +3483,38 @@ func mergeEnvs(kafkaClusterSpec KafkaClusterSpec, groupConfig, bConfig *BrokerCo
 	return envs
 }
 
+func mergeEnvFrom(kafkaClusterSpec KafkaClusterSpec, groupConfig, bConfig *BrokerConfig) []corev1.EnvFromSource {
+	var envFrom []corev1.EnvFromSource
+	envFrom = append(envFrom, kafkaClusterSpec.EnvFrom...)
+	envFrom = append(envFrom, groupConfig.EnvFrom...)
+	if bConfig != nil {
+		envFrom = append(envFrom, bConfig.EnvFrom...)
+	}
+	return envFrom
+}
+
+// mergeJVMOptions merges the JVM options from the least to the most specific one, the flags are appended and the
+// system properties are overridden
+func mergeJVMOptions(jvmOptions ...*JVMOptions) *JVMOptions {
+	var merged *JVMOptions
+	for _, options := range jvmOptions {
+		if options.IsEmpty() {
+			continue
+		}
+		if merged == nil {
+			merged = &JVMOptions{}
+		}
+		merged.Flags = append(merged.Flags, options.Flags...)
+		for name, value := range options.SystemProperties {
+			if merged.SystemProperties == nil {
+				merged.SystemProperties = make(map[string]string)
+			}
+			merged.SystemProperties[name] = value
+		}
+	}
+	return merged
+}
+
 func mergeAffinity(groupConfig BrokerConfig, bConfig *BrokerConfig) (*corev1.Affinity, error) {
 	dstAffinity := &corev1.Affinity{}
 	srcAffinity := &corev1.Affinity{}
@@ -18,12 +18,14 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // we expect the final result will be the two segment's union
@@ -427,6 +429,52 @@ func TestGetBrokerConfigEnvs(t *testing.T) {
 	}
 }
 
+// TestGetBrokerConfigProbes makes sure probes of the broker override the probes of its config group
+func TestGetBrokerConfigProbes(t *testing.T) {
+	groupStartupProbe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(9092)},
+		},
+		PeriodSeconds:    10,
+		FailureThreshold: 360,
+	}
+	groupLivenessProbe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(9092)},
+		},
+		TimeoutSeconds: 5,
+	}
+	brokerLivenessProbe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", "true"}},
+		},
+		InitialDelaySeconds: 60,
+	}
+
+	broker := Broker{
+		Id:                0,
+		BrokerConfigGroup: "default",
+		BrokerConfig: &BrokerConfig{
+			LivenessProbe: brokerLivenessProbe,
+		},
+	}
+
+	spec := KafkaClusterSpec{
+		BrokerConfigGroups: map[string]BrokerConfig{
+			"default": {
+				StartupProbe:  groupStartupProbe,
+				LivenessProbe: groupLivenessProbe,
+			},
+		},
+	}
+
+	result, err := broker.GetBrokerConfig(spec)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, result.StartupProbe, groupStartupProbe)
+	assert.DeepEqual(t, result.LivenessProbe, brokerLivenessProbe)
+	assert.Assert(t, result.ReadinessProbe == nil)
+}
+
 // TestGetBrokerLabels makes sure the reserved labels "app", "brokerId", and "kafka_cr" are not overridden by the BrokerConfig
 func TestGetBrokerLabels(t *testing.T) {
 	const (
@@ -458,3 +506,246 @@ func TestGetBrokerLabels(t *testing.T) {
 		t.Error("Expected:", expected, "Got:", result)
 	}
 }
+
+func TestGetNextBrokerID(t *testing.T) {
+	highest := int32(7)
+	cluster := &KafkaCluster{
+		Spec: KafkaClusterSpec{
+			Brokers: []Broker{{Id: 0}, {Id: 2}},
+		},
+		Status: KafkaClusterStatus{
+			BrokersState:     map[string]BrokerState{"1": {}, "2": {}},
+			RetiredBrokerIDs: []int32{3},
+		},
+	}
+
+	id, ok := cluster.GetHighestBrokerID()
+	assert.Assert(t, ok)
+	assert.Equal(t, int32(3), id)
+	// Broker 1 is being removed and broker 3 has been retired so the lowest free ID is 4
+	assert.Equal(t, int32(4), cluster.GetNextBrokerID())
+	// The data of former brokers is still kept for the reserved IDs
+	assert.Equal(t, int32(6), cluster.GetNextBrokerID(4, 5))
+
+	cluster.Spec.BrokerIDPolicy = BrokerIDPolicyAlwaysIncrement
+	assert.Equal(t, int32(4), cluster.GetNextBrokerID())
+	assert.Equal(t, int32(5), cluster.GetNextBrokerID(4))
+
+	cluster.Status.HighestBrokerID = &highest
+	assert.Equal(t, int32(8), cluster.GetNextBrokerID())
+
+	_, ok = (&KafkaCluster{}).GetHighestBrokerID()
+	assert.Assert(t, !ok)
+	assert.Equal(t, int32(0), (&KafkaCluster{Spec: KafkaClusterSpec{BrokerIDPolicy: BrokerIDPolicyAlwaysIncrement}}).GetNextBrokerID())
+}
+
+func TestGetNodeSelector(t *testing.T) {
+	brokerConfig := &BrokerConfig{NodeSelector: map[string]string{"pool": "kafka"}}
+	assert.DeepEqual(t, map[string]string{"pool": "kafka"}, brokerConfig.GetNodeSelector())
+
+	brokerConfig.Architecture = "arm64"
+	assert.DeepEqual(t, map[string]string{"pool": "kafka", corev1.LabelArchStable: "arm64"}, brokerConfig.GetNodeSelector())
+	// The node selector of the broker config is not modified
+	assert.DeepEqual(t, map[string]string{"pool": "kafka"}, brokerConfig.NodeSelector)
+
+	// An explicit architecture label takes precedence
+	brokerConfig.NodeSelector[corev1.LabelArchStable] = "amd64"
+	assert.Equal(t, "amd64", brokerConfig.GetNodeSelector()[corev1.LabelArchStable])
+
+	assert.DeepEqual(t, map[string]string{corev1.LabelArchStable: "amd64"}, (&BrokerConfig{Architecture: "amd64"}).GetNodeSelector())
+}
+
+func TestGetImage(t *testing.T) {
+	spec := KafkaClusterSpec{}
+	assert.Equal(t, "ghcr.io/banzaicloud/kafka:2.13-3.1.0", spec.GetImage("ghcr.io/banzaicloud/kafka:2.13-3.1.0"))
+
+	spec.ImageRegistry = "mirror.internal:5000/"
+	testCases := map[string]string{
+		"ghcr.io/banzaicloud/kafka:2.13-3.1.0":    "mirror.internal:5000/banzaicloud/kafka:2.13-3.1.0",
+		"envoyproxy/envoy:v1.22.2":                "mirror.internal:5000/envoyproxy/envoy:v1.22.2",
+		"busybox":                                 "mirror.internal:5000/busybox",
+		"localhost/kafka:latest":                  "mirror.internal:5000/kafka:latest",
+		"registry:5000/kafka@sha256:0123456789ab": "mirror.internal:5000/kafka@sha256:0123456789ab",
+	}
+	for image, expected := range testCases {
+		assert.Equal(t, expected, spec.GetImage(image))
+	}
+}
+
+func TestGetImagePullSecrets(t *testing.T) {
+	spec := KafkaClusterSpec{}
+	assert.DeepEqual(t, []corev1.LocalObjectReference{{Name: "broker"}}, spec.GetImagePullSecrets([]corev1.LocalObjectReference{{Name: "broker"}}))
+
+	spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "mirror"}, {Name: "broker"}}
+	assert.DeepEqual(t, []corev1.LocalObjectReference{{Name: "broker"}, {Name: "mirror"}},
+		spec.GetImagePullSecrets([]corev1.LocalObjectReference{{Name: "broker"}}))
+	assert.DeepEqual(t, []corev1.LocalObjectReference{{Name: "mirror"}, {Name: "broker"}}, spec.GetImagePullSecrets(nil))
+}
+
+func TestGetLoadBalancerSourceRanges(t *testing.T) {
+	settings := IngressServiceSettings{}
+	assert.DeepEqual(t, []string{"10.0.0.0/8"}, settings.GetLoadBalancerSourceRanges([]string{"10.0.0.0/8"}))
+	assert.Assert(t, settings.GetLoadBalancerSourceRanges(nil) == nil)
+
+	// The source ranges of the external listener take precedence
+	settings.LoadBalancerSourceRanges = []string{"192.168.0.0/16"}
+	assert.DeepEqual(t, []string{"192.168.0.0/16"}, settings.GetLoadBalancerSourceRanges([]string{"10.0.0.0/8"}))
+}
+
+func TestMovementExclusions(t *testing.T) {
+	spec := &CruiseControlOperationSpec{
+		MovementExclusions: []CruiseControlMovementExclusion{
+			{Topics: []string{"__consumer_offsets", ".*-changelog"}, SelfHealing: true},
+			{Operations: []CruiseControlMovementOperation{MovementOperationRemoveBroker}, Topics: []string{"audit"}},
+		},
+	}
+	exclusions := spec.GetMovementExclusions()
+	assert.Equal(t, exclusions[0].AppliesTo("rebalance"), true)
+	assert.Equal(t, exclusions[0].AppliesTo("demote_broker"), false)
+	assert.Equal(t, exclusions[1].AppliesTo("remove_broker"), true)
+	assert.Equal(t, exclusions[1].AppliesTo("add_broker"), false)
+	assert.Equal(t, spec.GetSelfHealingExcludedTopics(), "__consumer_offsets|.*-changelog")
+
+	var nilSpec *CruiseControlOperationSpec
+	assert.Equal(t, len(nilSpec.GetMovementExclusions()), 0)
+	assert.Equal(t, nilSpec.GetSelfHealingExcludedTopics(), "")
+}
+
+func TestCapacitySchedule(t *testing.T) {
+	schedule := &CapacitySchedule{
+		Rules: []CapacityScheduleRule{
+			{Name: "weekend", Window: WeeklyTimeWindow{Days: []Weekday{"Saturday", "Sunday"}}, BrokerCounts: map[string]int32{"default": 6}},
+			{Name: "weekdays", BrokerCounts: map[string]int32{"default": 9}},
+		},
+		MaintenanceWindows: []WeeklyTimeWindow{{Days: []Weekday{"Friday"}, Start: "22:00", End: "02:00"}},
+	}
+	testCases := []struct {
+		time              string
+		expectedRule      string
+		expectedInWindows bool
+	}{
+		{time: "2023-05-05T21:59:00Z", expectedRule: "weekdays"},
+		{time: "2023-05-05T22:00:00Z", expectedRule: "weekdays", expectedInWindows: true},
+		// The maintenance window started on Friday spans midnight
+		{time: "2023-05-06T01:59:00Z", expectedRule: "weekend", expectedInWindows: true},
+		{time: "2023-05-06T02:00:00Z", expectedRule: "weekend"},
+		{time: "2023-05-07T01:00:00Z", expectedRule: "weekend"},
+		{time: "2023-05-08T00:00:00Z", expectedRule: "weekdays"},
+	}
+	for _, testCase := range testCases {
+		now, err := time.Parse(time.RFC3339, testCase.time)
+		assert.NilError(t, err)
+		rule, err := schedule.ActiveRule(now)
+		assert.NilError(t, err)
+		assert.Equal(t, testCase.expectedRule, rule.Name, testCase.time)
+		inWindow, err := schedule.InMaintenanceWindow(now)
+		assert.NilError(t, err)
+		assert.Equal(t, testCase.expectedInWindows, inWindow, testCase.time)
+	}
+
+	// The windows are evaluated in the time zone of the schedule
+	schedule.TimeZone = "America/New_York"
+	now, err := time.Parse(time.RFC3339, "2023-05-06T02:00:00Z")
+	assert.NilError(t, err)
+	rule, err := schedule.ActiveRule(now)
+	assert.NilError(t, err)
+	assert.Equal(t, "weekdays", rule.Name)
+	inWindow, err := schedule.InMaintenanceWindow(now)
+	assert.NilError(t, err)
+	assert.Assert(t, inWindow)
+}
+
+func TestGetBrokerConfigEnvFromAndJVMOptions(t *testing.T) {
+	broker := Broker{
+		Id:                0,
+		BrokerConfigGroup: "default",
+		BrokerConfig: &BrokerConfig{
+			EnvFrom: []corev1.EnvFromSource{
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "broker"}}},
+			},
+			JVMOptions: &JVMOptions{
+				Flags:            []string{"-XX:+ExitOnOutOfMemoryError"},
+				SystemProperties: map[string]string{"rack": "$(RACK)"},
+			},
+		},
+	}
+
+	spec := KafkaClusterSpec{
+		EnvFrom: []corev1.EnvFromSource{
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cluster"}}},
+		},
+		JVMOptions: &JVMOptions{
+			Flags:            []string{"-XX:+HeapDumpOnOutOfMemoryError"},
+			SystemProperties: map[string]string{"rack": "cluster", "zone": "cluster"},
+		},
+		BrokerConfigGroups: map[string]BrokerConfig{
+			"default": {
+				EnvFrom: []corev1.EnvFromSource{
+					{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "group"}}},
+				},
+			},
+		},
+	}
+
+	result, err := broker.GetBrokerConfig(spec)
+	if err != nil {
+		t.Error("Error GetBrokerConfig throw an unexpected error")
+	}
+
+	expectedEnvFrom := []corev1.EnvFromSource{
+		{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cluster"}}},
+		{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "group"}}},
+		{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "broker"}}},
+	}
+	if !reflect.DeepEqual(result.EnvFrom, expectedEnvFrom) {
+		t.Error("Expected:", expectedEnvFrom, "Got:", result.EnvFrom)
+	}
+
+	expectedJVMOptions := "-XX:+HeapDumpOnOutOfMemoryError -XX:+ExitOnOutOfMemoryError -Drack=$(RACK) -Dzone=cluster"
+	if result.JVMOptions.String() != expectedJVMOptions {
+		t.Error("Expected:", expectedJVMOptions, "Got:", result.JVMOptions.String())
+	}
+	// the JVM options of the cluster are not modified by the merge
+	if spec.JVMOptions.SystemProperties["rack"] != "cluster" || len(spec.JVMOptions.Flags) != 1 {
+		t.Error("JVM options of the cluster have been modified:", spec.JVMOptions)
+	}
+}
+
+func TestCruiseControlConfigGetVersion(t *testing.T) {
+	testCases := []struct {
+		ccConfig CruiseControlConfig
+		expected string
+	}{
+		{ccConfig: CruiseControlConfig{}, expected: "2.5.101"},
+		{ccConfig: CruiseControlConfig{Image: "localhost:5000/cruise-control"}, expected: ""},
+		{ccConfig: CruiseControlConfig{Image: "localhost:5000/cruise-control:2.5.114@sha256:0123"}, expected: "2.5.114"},
+		{ccConfig: CruiseControlConfig{Image: "cruise-control:2.5.114", Version: "2.5.120"}, expected: "2.5.120"},
+	}
+	for _, testCase := range testCases {
+		if got := testCase.ccConfig.GetVersion(); got != testCase.expected {
+			t.Errorf("Expected: %q, got: %q for image %q", testCase.expected, got, testCase.ccConfig.Image)
+		}
+	}
+}
+
+func TestIsBootstrapping(t *testing.T) {
+	testCases := []struct {
+		status   KafkaClusterStatus
+		expected bool
+	}{
+		{status: KafkaClusterStatus{}, expected: false},
+		{status: KafkaClusterStatus{State: KafkaClusterReconciling}, expected: false},
+		{status: KafkaClusterStatus{State: KafkaClusterReconciling, Conditions: []metav1.Condition{
+			{Type: BootstrappingCondition, Status: metav1.ConditionTrue, Reason: ClusterCreatedReason},
+		}}, expected: true},
+		{status: KafkaClusterStatus{State: KafkaClusterRunning, Conditions: []metav1.Condition{
+			{Type: BootstrappingCondition, Status: metav1.ConditionFalse, Reason: ClusterRunningReason},
+		}}, expected: false},
+	}
+	for _, testCase := range testCases {
+		cluster := KafkaCluster{Status: testCase.status}
+		if got := cluster.IsBootstrapping(); got != testCase.expected {
+			t.Errorf("Expected: %t, got: %t for status %+v", testCase.expected, got, testCase.status)
+		}
+	}
+}
@@ -21,9 +21,11 @@ package v1beta1
 
 import (
 	networkingv1beta1 "github.com/banzaicloud/istio-client-go/pkg/networking/v1beta1"
-	metav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	apismetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"k8s.io/api/core/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -41,6 +43,38 @@ func (in *AlertManagerConfig) DeepCopy() *AlertManagerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorizationConfig) DeepCopyInto(out *AuthorizationConfig) {
+	*out = *in
+	if in.SuperUsers != nil {
+		in, out := &in.SuperUsers, &out.SuperUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OPA != nil {
+		in, out := &in.OPA, &out.OPA
+		*out = new(OPAAuthorizerConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PluginConfig != nil {
+		in, out := &in.PluginConfig, &out.PluginConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationConfig.
+func (in *AuthorizationConfig) DeepCopy() *AuthorizationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorizationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Broker) DeepCopyInto(out *Broker) {
 	*out = *in
@@ -95,6 +129,11 @@ func (in *BrokerConfig) DeepCopyInto(out *BrokerConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Log4jConfigMap != nil {
+		in, out := &in.Log4jConfigMap, &out.Log4jConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.BrokerAnnotations != nil {
 		in, out := &in.BrokerAnnotations, &out.BrokerAnnotations
 		*out = make(map[string]string, len(*in))
@@ -136,6 +175,21 @@ func (in *BrokerConfig) DeepCopyInto(out *BrokerConfig) {
 		*out = new(v1.SecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.BrokerIngressMapping != nil {
 		in, out := &in.BrokerIngressMapping, &out.BrokerIngressMapping
 		*out = make([]string, len(*in))
@@ -176,6 +230,18 @@ func (in *BrokerConfig) DeepCopyInto(out *BrokerConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.JVMOptions != nil {
+		in, out := &in.JVMOptions, &out.JVMOptions
+		*out = new(JVMOptions)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TerminationGracePeriod != nil {
 		in, out := &in.TerminationGracePeriod, &out.TerminationGracePeriod
 		*out = new(int64)
@@ -194,262 +260,482 @@ func (in *BrokerConfig) DeepCopy() *BrokerConfig {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BrokerState) DeepCopyInto(out *BrokerState) {
+func (in *BrokerConnectionLimits) DeepCopyInto(out *BrokerConnectionLimits) {
 	*out = *in
-	in.GracefulActionState.DeepCopyInto(&out.GracefulActionState)
-	if in.ExternalListenerConfigNames != nil {
-		in, out := &in.ExternalListenerConfigNames, &out.ExternalListenerConfigNames
-		*out = make(ExternalListenerConfigNames, len(*in))
-		copy(*out, *in)
+	if in.MaxConnections != nil {
+		in, out := &in.MaxConnections, &out.MaxConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxConnectionsPerIP != nil {
+		in, out := &in.MaxConnectionsPerIP, &out.MaxConnectionsPerIP
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxConnectionsPerIPOverrides != nil {
+		in, out := &in.MaxConnectionsPerIPOverrides, &out.MaxConnectionsPerIPOverrides
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaxConnectionCreationRate != nil {
+		in, out := &in.MaxConnectionCreationRate, &out.MaxConnectionCreationRate
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerState.
-func (in *BrokerState) DeepCopy() *BrokerState {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerConnectionLimits.
+func (in *BrokerConnectionLimits) DeepCopy() *BrokerConnectionLimits {
 	if in == nil {
 		return nil
 	}
-	out := new(BrokerState)
+	out := new(BrokerConnectionLimits)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CommonListenerSpec) DeepCopyInto(out *CommonListenerSpec) {
+func (in *BrokerFailurePolicy) DeepCopyInto(out *BrokerFailurePolicy) {
 	*out = *in
-	if in.ServerSSLCertSecret != nil {
-		in, out := &in.ServerSSLCertSecret, &out.ServerSSLCertSecret
-		*out = new(v1.LocalObjectReference)
+	if in.GracePeriodSeconds != nil {
+		in, out := &in.GracePeriodSeconds, &out.GracePeriodSeconds
+		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonListenerSpec.
-func (in *CommonListenerSpec) DeepCopy() *CommonListenerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerFailurePolicy.
+func (in *BrokerFailurePolicy) DeepCopy() *BrokerFailurePolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(CommonListenerSpec)
+	out := new(BrokerFailurePolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Config) DeepCopyInto(out *Config) {
+func (in *BrokerGroupParkingStatus) DeepCopyInto(out *BrokerGroupParkingStatus) {
 	*out = *in
-	if in.IngressConfig != nil {
-		in, out := &in.IngressConfig, &out.IngressConfig
-		*out = make(map[string]IngressConfig, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	if in.BrokerIDs != nil {
+		in, out := &in.BrokerIDs, &out.BrokerIDs
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Config.
-func (in *Config) DeepCopy() *Config {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerGroupParkingStatus.
+func (in *BrokerGroupParkingStatus) DeepCopy() *BrokerGroupParkingStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Config)
+	out := new(BrokerGroupParkingStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CruiseControlConfig) DeepCopyInto(out *CruiseControlConfig) {
+func (in *BrokerReadinessGate) DeepCopyInto(out *BrokerReadinessGate) {
 	*out = *in
-	out.CruiseControlTaskSpec = in.CruiseControlTaskSpec
-	if in.CruiseControlOperationSpec != nil {
-		in, out := &in.CruiseControlOperationSpec, &out.CruiseControlOperationSpec
-		*out = new(CruiseControlOperationSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerReadinessGate.
+func (in *BrokerReadinessGate) DeepCopy() *BrokerReadinessGate {
+	if in == nil {
+		return nil
 	}
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
+	out := new(BrokerReadinessGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerRemovalDestinationPolicy) DeepCopyInto(out *BrokerRemovalDestinationPolicy) {
+	*out = *in
+	if in.ExcludedBrokerIDs != nil {
+		in, out := &in.ExcludedBrokerIDs, &out.ExcludedBrokerIDs
+		*out = make([]int32, len(*in))
 		copy(*out, *in)
 	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.MaxDiskUsagePercent != nil {
+		in, out := &in.MaxDiskUsagePercent, &out.MaxDiskUsagePercent
+		*out = new(int32)
+		**out = **in
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerRemovalDestinationPolicy.
+func (in *BrokerRemovalDestinationPolicy) DeepCopy() *BrokerRemovalDestinationPolicy {
+	if in == nil {
+		return nil
 	}
-	if in.TopicConfig != nil {
-		in, out := &in.TopicConfig, &out.TopicConfig
-		*out = new(TopicConfig)
-		**out = **in
+	out := new(BrokerRemovalDestinationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerShutdownStatus) DeepCopyInto(out *BrokerShutdownStatus) {
+	*out = *in
+	in.FinishedAt.DeepCopyInto(&out.FinishedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerShutdownStatus.
+func (in *BrokerShutdownStatus) DeepCopy() *BrokerShutdownStatus {
+	if in == nil {
+		return nil
 	}
-	if in.CruiseControlAnnotations != nil {
-		in, out := &in.CruiseControlAnnotations, &out.CruiseControlAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	out := new(BrokerShutdownStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerState) DeepCopyInto(out *BrokerState) {
+	*out = *in
+	in.GracefulActionState.DeepCopyInto(&out.GracefulActionState)
+	if in.ExternalListenerConfigNames != nil {
+		in, out := &in.ExternalListenerConfigNames, &out.ExternalListenerConfigNames
+		*out = make(ExternalListenerConfigNames, len(*in))
+		copy(*out, *in)
 	}
-	if in.InitContainers != nil {
-		in, out := &in.InitContainers, &out.InitContainers
-		*out = make([]v1.Container, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.LastShutdown != nil {
+		in, out := &in.LastShutdown, &out.LastShutdown
+		*out = new(BrokerShutdownStatus)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
-		*out = make([]v1.Volume, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerState.
+func (in *BrokerState) DeepCopy() *BrokerState {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacitySchedule) DeepCopyInto(out *CapacitySchedule) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]CapacityScheduleRule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.VolumeMounts != nil {
-		in, out := &in.VolumeMounts, &out.VolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]WeeklyTimeWindow, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.PodSecurityContext != nil {
-		in, out := &in.PodSecurityContext, &out.PodSecurityContext
-		*out = new(v1.PodSecurityContext)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.SecurityContext != nil {
-		in, out := &in.SecurityContext, &out.SecurityContext
-		*out = new(v1.SecurityContext)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlConfig.
-func (in *CruiseControlConfig) DeepCopy() *CruiseControlConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacitySchedule.
+func (in *CapacitySchedule) DeepCopy() *CapacitySchedule {
 	if in == nil {
 		return nil
 	}
-	out := new(CruiseControlConfig)
+	out := new(CapacitySchedule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CruiseControlOperationSpec) DeepCopyInto(out *CruiseControlOperationSpec) {
+func (in *CapacityScheduleRule) DeepCopyInto(out *CapacityScheduleRule) {
 	*out = *in
-	if in.TTLSecondsAfterFinished != nil {
-		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
-		*out = new(int)
-		**out = **in
+	in.Window.DeepCopyInto(&out.Window)
+	if in.BrokerCounts != nil {
+		in, out := &in.BrokerCounts, &out.BrokerCounts
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationSpec.
-func (in *CruiseControlOperationSpec) DeepCopy() *CruiseControlOperationSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityScheduleRule.
+func (in *CapacityScheduleRule) DeepCopy() *CapacityScheduleRule {
 	if in == nil {
 		return nil
 	}
-	out := new(CruiseControlOperationSpec)
+	out := new(CapacityScheduleRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CruiseControlTaskSpec) DeepCopyInto(out *CruiseControlTaskSpec) {
+func (in *CapacityScheduleStatus) DeepCopyInto(out *CapacityScheduleStatus) {
 	*out = *in
+	if in.BrokerCounts != nil {
+		in, out := &in.BrokerCounts, &out.BrokerCounts
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTaskSpec.
-func (in *CruiseControlTaskSpec) DeepCopy() *CruiseControlTaskSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityScheduleStatus.
+func (in *CapacityScheduleStatus) DeepCopy() *CapacityScheduleStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CruiseControlTaskSpec)
+	out := new(CapacityScheduleStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DisruptionBudget) DeepCopyInto(out *DisruptionBudget) {
+func (in *ClientQuotaConfig) DeepCopyInto(out *ClientQuotaConfig) {
 	*out = *in
+	if in.UserDefaults != nil {
+		in, out := &in.UserDefaults, &out.UserDefaults
+		*out = new(ClientQuotas)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientIDDefaults != nil {
+		in, out := &in.ClientIDDefaults, &out.ClientIDDefaults
+		*out = new(ClientQuotas)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PluginConfig != nil {
+		in, out := &in.PluginConfig, &out.PluginConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudget.
-func (in *DisruptionBudget) DeepCopy() *DisruptionBudget {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientQuotaConfig.
+func (in *ClientQuotaConfig) DeepCopy() *ClientQuotaConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(DisruptionBudget)
+	out := new(ClientQuotaConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DisruptionBudgetWithStrategy) DeepCopyInto(out *DisruptionBudgetWithStrategy) {
+func (in *ClientQuotas) DeepCopyInto(out *ClientQuotas) {
 	*out = *in
-	out.DisruptionBudget = in.DisruptionBudget
+	if in.ProducerByteRate != nil {
+		in, out := &in.ProducerByteRate, &out.ProducerByteRate
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ConsumerByteRate != nil {
+		in, out := &in.ConsumerByteRate, &out.ConsumerByteRate
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RequestPercentage != nil {
+		in, out := &in.RequestPercentage, &out.RequestPercentage
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudgetWithStrategy.
-func (in *DisruptionBudgetWithStrategy) DeepCopy() *DisruptionBudgetWithStrategy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientQuotas.
+func (in *ClientQuotas) DeepCopy() *ClientQuotas {
 	if in == nil {
 		return nil
 	}
-	out := new(DisruptionBudgetWithStrategy)
+	out := new(ClientQuotas)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EnvoyCommandLineArgs) DeepCopyInto(out *EnvoyCommandLineArgs) {
+func (in *ClusterServiceBinding) DeepCopyInto(out *ClusterServiceBinding) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyCommandLineArgs.
-func (in *EnvoyCommandLineArgs) DeepCopy() *EnvoyCommandLineArgs {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterServiceBinding.
+func (in *ClusterServiceBinding) DeepCopy() *ClusterServiceBinding {
 	if in == nil {
 		return nil
 	}
-	out := new(EnvoyCommandLineArgs)
+	out := new(ClusterServiceBinding)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EnvoyConfig) DeepCopyInto(out *EnvoyConfig) {
+func (in *CommonListenerSpec) DeepCopyInto(out *CommonListenerSpec) {
 	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+	if in.ServerSSLCertSecret != nil {
+		in, out := &in.ServerSSLCertSecret, &out.ServerSSLCertSecret
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(ListenerCertManagerConfig)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
-		copy(*out, *in)
+	if in.TLSPolicy != nil {
+		in, out := &in.TLSPolicy, &out.TLSPolicy
+		*out = new(ListenerTLSPolicy)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+	if in.ConnectionLimits != nil {
+		in, out := &in.ConnectionLimits, &out.ConnectionLimits
+		*out = new(ListenerConnectionLimits)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.TopologySpreadConstraints != nil {
-		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]v1.TopologySpreadConstraint, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonListenerSpec.
+func (in *CommonListenerSpec) DeepCopy() *CommonListenerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CommonListenerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Config) DeepCopyInto(out *Config) {
+	*out = *in
+	if in.IngressConfig != nil {
+		in, out := &in.IngressConfig, &out.IngressConfig
+		*out = make(map[string]IngressConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Config.
+func (in *Config) DeepCopy() *Config {
+	if in == nil {
+		return nil
+	}
+	out := new(Config)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrossNamespaceReferences) DeepCopyInto(out *CrossNamespaceReferences) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrossNamespaceReferences.
+func (in *CrossNamespaceReferences) DeepCopy() *CrossNamespaceReferences {
+	if in == nil {
+		return nil
+	}
+	out := new(CrossNamespaceReferences)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlAnomalyNotifier) DeepCopyInto(out *CruiseControlAnomalyNotifier) {
+	*out = *in
+	if in.Remediations != nil {
+		in, out := &in.Remediations, &out.Remediations
+		*out = make([]CruiseControlAnomalyRemediation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlAnomalyNotifier.
+func (in *CruiseControlAnomalyNotifier) DeepCopy() *CruiseControlAnomalyNotifier {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlAnomalyNotifier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlAnomalyRemediation) DeepCopyInto(out *CruiseControlAnomalyRemediation) {
+	*out = *in
+	if in.MinIntervalSeconds != nil {
+		in, out := &in.MinIntervalSeconds, &out.MinIntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlAnomalyRemediation.
+func (in *CruiseControlAnomalyRemediation) DeepCopy() *CruiseControlAnomalyRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlAnomalyRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlClientTLS) DeepCopyInto(out *CruiseControlClientTLS) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(apismetav1.ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlClientTLS.
+func (in *CruiseControlClientTLS) DeepCopy() *CruiseControlClientTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlClientTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlConfig) DeepCopyInto(out *CruiseControlConfig) {
+	*out = *in
+	out.CruiseControlTaskSpec = in.CruiseControlTaskSpec
+	if in.CruiseControlOperationSpec != nil {
+		in, out := &in.CruiseControlOperationSpec, &out.CruiseControlOperationSpec
+		*out = new(CruiseControlOperationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
 		*out = make(map[string]string, len(*in))
@@ -464,612 +750,2049 @@ func (in *EnvoyConfig) DeepCopyInto(out *EnvoyConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.JVMOptions != nil {
+		in, out := &in.JVMOptions, &out.JVMOptions
+		*out = new(JVMOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopicConfig != nil {
+		in, out := &in.TopicConfig, &out.TopicConfig
+		*out = new(TopicConfig)
+		**out = **in
+	}
+	if in.SampleStoreTopicConfig != nil {
+		in, out := &in.SampleStoreTopicConfig, &out.SampleStoreTopicConfig
+		*out = new(TopicConfig)
+		**out = **in
+	}
+	if in.SampleStore != nil {
+		in, out := &in.SampleStore, &out.SampleStore
+		*out = new(CruiseControlSampleStore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Log4jConfigMap != nil {
+		in, out := &in.Log4jConfigMap, &out.Log4jConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CruiseControlAnnotations != nil {
+		in, out := &in.CruiseControlAnnotations, &out.CruiseControlAnnotations
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.LoadBalancerSourceRanges != nil {
-		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.CruiseControlLabels != nil {
+		in, out := &in.CruiseControlLabels, &out.CruiseControlLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.AdminPort != nil {
-		in, out := &in.AdminPort, &out.AdminPort
-		*out = new(int32)
-		**out = **in
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.HealthCheckPort != nil {
-		in, out := &in.HealthCheckPort, &out.HealthCheckPort
-		*out = new(int32)
-		**out = **in
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.DisruptionBudget != nil {
-		in, out := &in.DisruptionBudget, &out.DisruptionBudget
-		*out = new(DisruptionBudgetWithStrategy)
-		**out = **in
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.CommandLineArgs != nil {
-		in, out := &in.CommandLineArgs, &out.CommandLineArgs
-		*out = new(EnvoyCommandLineArgs)
-		**out = **in
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(v1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientTLS != nil {
+		in, out := &in.ClientTLS, &out.ClientTLS
+		*out = new(CruiseControlClientTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPClient != nil {
+		in, out := &in.HTTPClient, &out.HTTPClient
+		*out = new(CruiseControlHTTPClientConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(CruiseControlSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AnomalyNotifier != nil {
+		in, out := &in.AnomalyNotifier, &out.AnomalyNotifier
+		*out = new(CruiseControlAnomalyNotifier)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyConfig.
-func (in *EnvoyConfig) DeepCopy() *EnvoyConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlConfig.
+func (in *CruiseControlConfig) DeepCopy() *CruiseControlConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(EnvoyConfig)
+	out := new(CruiseControlConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExternalListenerConfig) DeepCopyInto(out *ExternalListenerConfig) {
+func (in *CruiseControlHTTPClientConfig) DeepCopyInto(out *CruiseControlHTTPClientConfig) {
 	*out = *in
-	in.CommonListenerSpec.DeepCopyInto(&out.CommonListenerSpec)
-	in.IngressServiceSettings.DeepCopyInto(&out.IngressServiceSettings)
-	if in.AnyCastPort != nil {
-		in, out := &in.AnyCastPort, &out.AnyCastPort
-		*out = new(int32)
-		**out = **in
+	if in.ProxyURLSecretRef != nil {
+		in, out := &in.ProxyURLSecretRef, &out.ProxyURLSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Config != nil {
-		in, out := &in.Config, &out.Config
-		*out = new(Config)
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]CruiseControlHTTPHeader, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BearerTokenSecretRef != nil {
+		in, out := &in.BearerTokenSecretRef, &out.BearerTokenSecretRef
+		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalListenerConfig.
-func (in *ExternalListenerConfig) DeepCopy() *ExternalListenerConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlHTTPClientConfig.
+func (in *CruiseControlHTTPClientConfig) DeepCopy() *CruiseControlHTTPClientConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ExternalListenerConfig)
+	out := new(CruiseControlHTTPClientConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in ExternalListenerConfigNames) DeepCopyInto(out *ExternalListenerConfigNames) {
-	{
-		in := &in
-		*out = make(ExternalListenerConfigNames, len(*in))
-		copy(*out, *in)
+func (in *CruiseControlHTTPHeader) DeepCopyInto(out *CruiseControlHTTPHeader) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalListenerConfigNames.
-func (in ExternalListenerConfigNames) DeepCopy() ExternalListenerConfigNames {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlHTTPHeader.
+func (in *CruiseControlHTTPHeader) DeepCopy() *CruiseControlHTTPHeader {
 	if in == nil {
 		return nil
 	}
-	out := new(ExternalListenerConfigNames)
+	out := new(CruiseControlHTTPHeader)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GracefulActionState) DeepCopyInto(out *GracefulActionState) {
+func (in *CruiseControlMovementExclusion) DeepCopyInto(out *CruiseControlMovementExclusion) {
 	*out = *in
-	if in.CruiseControlOperationReference != nil {
-		in, out := &in.CruiseControlOperationReference, &out.CruiseControlOperationReference
-		*out = new(v1.LocalObjectReference)
-		**out = **in
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]CruiseControlMovementOperation, len(*in))
+		copy(*out, *in)
 	}
-	if in.VolumeStates != nil {
-		in, out := &in.VolumeStates, &out.VolumeStates
-		*out = make(map[string]VolumeState, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BrokerIDs != nil {
+		in, out := &in.BrokerIDs, &out.BrokerIDs
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlMovementExclusion.
+func (in *CruiseControlMovementExclusion) DeepCopy() *CruiseControlMovementExclusion {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlMovementExclusion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationQuota) DeepCopyInto(out *CruiseControlOperationQuota) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxPending != nil {
+		in, out := &in.MaxPending, &out.MaxPending
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPerHour != nil {
+		in, out := &in.MaxPerHour, &out.MaxPerHour
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AllowedOperations != nil {
+		in, out := &in.AllowedOperations, &out.AllowedOperations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationQuota.
+func (in *CruiseControlOperationQuota) DeepCopy() *CruiseControlOperationQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationSpec) DeepCopyInto(out *CruiseControlOperationSpec) {
+	*out = *in
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int)
+		**out = **in
+	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = make([]CruiseControlOperationQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StatusLimits != nil {
+		in, out := &in.StatusLimits, &out.StatusLimits
+		*out = new(CruiseControlOperationStatusLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MovementExclusions != nil {
+		in, out := &in.MovementExclusions, &out.MovementExclusions
+		*out = make([]CruiseControlMovementExclusion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BrokerRemovalDestinations != nil {
+		in, out := &in.BrokerRemovalDestinations, &out.BrokerRemovalDestinations
+		*out = new(BrokerRemovalDestinationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationSpec.
+func (in *CruiseControlOperationSpec) DeepCopy() *CruiseControlOperationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationStatusLimits) DeepCopyInto(out *CruiseControlOperationStatusLimits) {
+	*out = *in
+	if in.MaxErrorMessageLength != nil {
+		in, out := &in.MaxErrorMessageLength, &out.MaxErrorMessageLength
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSummaryValueLength != nil {
+		in, out := &in.MaxSummaryValueLength, &out.MaxSummaryValueLength
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedTasksHistory != nil {
+		in, out := &in.FailedTasksHistory, &out.FailedTasksHistory
+		*out = new(FailedTasksHistoryLimits)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationStatusLimits.
+func (in *CruiseControlOperationStatusLimits) DeepCopy() *CruiseControlOperationStatusLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationStatusLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlOperationSummary) DeepCopyInto(out *CruiseControlOperationSummary) {
+	*out = *in
+	if in.Executing != nil {
+		in, out := &in.Executing, &out.Executing
+		*out = new(ExecutingCruiseControlOperation)
+		**out = **in
+	}
+	if in.LastCompletedRebalance != nil {
+		in, out := &in.LastCompletedRebalance, &out.LastCompletedRebalance
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlOperationSummary.
+func (in *CruiseControlOperationSummary) DeepCopy() *CruiseControlOperationSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlOperationSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlSampleStore) DeepCopyInto(out *CruiseControlSampleStore) {
+	*out = *in
+	if in.LoadingThreads != nil {
+		in, out := &in.LoadingThreads, &out.LoadingThreads
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlSampleStore.
+func (in *CruiseControlSampleStore) DeepCopy() *CruiseControlSampleStore {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlSampleStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlSettings) DeepCopyInto(out *CruiseControlSettings) {
+	*out = *in
+	if in.NumConcurrentPartitionMovementsPerBroker != nil {
+		in, out := &in.NumConcurrentPartitionMovementsPerBroker, &out.NumConcurrentPartitionMovementsPerBroker
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NumConcurrentIntraBrokerPartitionMovements != nil {
+		in, out := &in.NumConcurrentIntraBrokerPartitionMovements, &out.NumConcurrentIntraBrokerPartitionMovements
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NumConcurrentLeaderMovements != nil {
+		in, out := &in.NumConcurrentLeaderMovements, &out.NumConcurrentLeaderMovements
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MetricSamplingIntervalMs != nil {
+		in, out := &in.MetricSamplingIntervalMs, &out.MetricSamplingIntervalMs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PartitionMetricsWindowMs != nil {
+		in, out := &in.PartitionMetricsWindowMs, &out.PartitionMetricsWindowMs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NumPartitionMetricsWindows != nil {
+		in, out := &in.NumPartitionMetricsWindows, &out.NumPartitionMetricsWindows
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BrokerMetricsWindowMs != nil {
+		in, out := &in.BrokerMetricsWindowMs, &out.BrokerMetricsWindowMs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NumBrokerMetricsWindows != nil {
+		in, out := &in.NumBrokerMetricsWindows, &out.NumBrokerMetricsWindows
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlSettings.
+func (in *CruiseControlSettings) DeepCopy() *CruiseControlSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CruiseControlTaskSpec) DeepCopyInto(out *CruiseControlTaskSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CruiseControlTaskSpec.
+func (in *CruiseControlTaskSpec) DeepCopy() *CruiseControlTaskSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CruiseControlTaskSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DelegationTokenConfig) DeepCopyInto(out *DelegationTokenConfig) {
+	*out = *in
+	in.SecretKeyRef.DeepCopyInto(&out.SecretKeyRef)
+	if in.MaxLifetimeSeconds != nil {
+		in, out := &in.MaxLifetimeSeconds, &out.MaxLifetimeSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ExpiryTimeSeconds != nil {
+		in, out := &in.ExpiryTimeSeconds, &out.ExpiryTimeSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DelegationTokenConfig.
+func (in *DelegationTokenConfig) DeepCopy() *DelegationTokenConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DelegationTokenConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionBudget) DeepCopyInto(out *DisruptionBudget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudget.
+func (in *DisruptionBudget) DeepCopy() *DisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionBudgetWithStrategy) DeepCopyInto(out *DisruptionBudgetWithStrategy) {
+	*out = *in
+	out.DisruptionBudget = in.DisruptionBudget
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudgetWithStrategy.
+func (in *DisruptionBudgetWithStrategy) DeepCopy() *DisruptionBudgetWithStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionBudgetWithStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyAccessControl) DeepCopyInto(out *EnvoyAccessControl) {
+	*out = *in
+	if in.AllowedSourceRanges != nil {
+		in, out := &in.AllowedSourceRanges, &out.AllowedSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedSourceRanges != nil {
+		in, out := &in.DeniedSourceRanges, &out.DeniedSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyAccessControl.
+func (in *EnvoyAccessControl) DeepCopy() *EnvoyAccessControl {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyAccessControl)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyCommandLineArgs) DeepCopyInto(out *EnvoyCommandLineArgs) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyCommandLineArgs.
+func (in *EnvoyCommandLineArgs) DeepCopy() *EnvoyCommandLineArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyCommandLineArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyConfig) DeepCopyInto(out *EnvoyConfig) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdminPort != nil {
+		in, out := &in.AdminPort, &out.AdminPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HealthCheckPort != nil {
+		in, out := &in.HealthCheckPort, &out.HealthCheckPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DisruptionBudget != nil {
+		in, out := &in.DisruptionBudget, &out.DisruptionBudget
+		*out = new(DisruptionBudgetWithStrategy)
+		**out = **in
+	}
+	if in.CommandLineArgs != nil {
+		in, out := &in.CommandLineArgs, &out.CommandLineArgs
+		*out = new(EnvoyCommandLineArgs)
+		**out = **in
+	}
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigOverrides != nil {
+		in, out := &in.ConfigOverrides, &out.ConfigOverrides
+		*out = new(EnvoyConfigOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigPatches != nil {
+		in, out := &in.ConfigPatches, &out.ConfigPatches
+		*out = make([]EnvoyConfigPatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyConfig.
+func (in *EnvoyConfig) DeepCopy() *EnvoyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyConfigOverrides) DeepCopyInto(out *EnvoyConfigOverrides) {
+	*out = *in
+	if in.ConnectTimeout != nil {
+		in, out := &in.ConnectTimeout, &out.ConnectTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.IdleTimeout != nil {
+		in, out := &in.IdleTimeout, &out.IdleTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PerConnectionBufferLimitBytes != nil {
+		in, out := &in.PerConnectionBufferLimitBytes, &out.PerConnectionBufferLimitBytes
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyConfigOverrides.
+func (in *EnvoyConfigOverrides) DeepCopy() *EnvoyConfigOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyConfigOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoyConfigPatch) DeepCopyInto(out *EnvoyConfigPatch) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyConfigPatch.
+func (in *EnvoyConfigPatch) DeepCopy() *EnvoyConfigPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoyConfigPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutingCruiseControlOperation) DeepCopyInto(out *ExecutingCruiseControlOperation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutingCruiseControlOperation.
+func (in *ExecutingCruiseControlOperation) DeepCopy() *ExecutingCruiseControlOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutingCruiseControlOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalListenerConfig) DeepCopyInto(out *ExternalListenerConfig) {
+	*out = *in
+	in.CommonListenerSpec.DeepCopyInto(&out.CommonListenerSpec)
+	in.IngressServiceSettings.DeepCopyInto(&out.IngressServiceSettings)
+	if in.AnyCastPort != nil {
+		in, out := &in.AnyCastPort, &out.AnyCastPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(Config)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessControl != nil {
+		in, out := &in.AccessControl, &out.AccessControl
+		*out = new(EnvoyAccessControl)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalListenerConfig.
+func (in *ExternalListenerConfig) DeepCopy() *ExternalListenerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalListenerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in ExternalListenerConfigNames) DeepCopyInto(out *ExternalListenerConfigNames) {
+	{
+		in := &in
+		*out = make(ExternalListenerConfigNames, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalListenerConfigNames.
+func (in ExternalListenerConfigNames) DeepCopy() ExternalListenerConfigNames {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalListenerConfigNames)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailedBrokerStatus) DeepCopyInto(out *FailedBrokerStatus) {
+	*out = *in
+	in.DownSince.DeepCopyInto(&out.DownSince)
+	if in.ReplacedBy != nil {
+		in, out := &in.ReplacedBy, &out.ReplacedBy
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailedBrokerStatus.
+func (in *FailedBrokerStatus) DeepCopy() *FailedBrokerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FailedBrokerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailedTasksHistoryLimits) DeepCopyInto(out *FailedTasksHistoryLimits) {
+	*out = *in
+	if in.MaxLength != nil {
+		in, out := &in.MaxLength, &out.MaxLength
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAgeSeconds != nil {
+		in, out := &in.MaxAgeSeconds, &out.MaxAgeSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailedTasksHistoryLimits.
+func (in *FailedTasksHistoryLimits) DeepCopy() *FailedTasksHistoryLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(FailedTasksHistoryLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GracefulActionState) DeepCopyInto(out *GracefulActionState) {
+	*out = *in
+	if in.CruiseControlOperationReference != nil {
+		in, out := &in.CruiseControlOperationReference, &out.CruiseControlOperationReference
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.VolumeStates != nil {
+		in, out := &in.VolumeStates, &out.VolumeStates
+		*out = make(map[string]VolumeState, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GracefulActionState.
+func (in *GracefulActionState) DeepCopy() *GracefulActionState {
+	if in == nil {
+		return nil
+	}
+	out := new(GracefulActionState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookJob) DeepCopyInto(out *HookJob) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookJob.
+func (in *HookJob) DeepCopy() *HookJob {
+	if in == nil {
+		return nil
+	}
+	out := new(HookJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
+	*out = *in
+	in.IngressServiceSettings.DeepCopyInto(&out.IngressServiceSettings)
+	if in.IstioIngressConfig != nil {
+		in, out := &in.IstioIngressConfig, &out.IstioIngressConfig
+		*out = new(IstioIngressConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EnvoyConfig != nil {
+		in, out := &in.EnvoyConfig, &out.EnvoyConfig
+		*out = new(EnvoyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressConfig.
+func (in *IngressConfig) DeepCopy() *IngressConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressServiceSettings) DeepCopyInto(out *IngressServiceSettings) {
+	*out = *in
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LoadBalancerClass != nil {
+		in, out := &in.LoadBalancerClass, &out.LoadBalancerClass
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressServiceSettings.
+func (in *IngressServiceSettings) DeepCopy() *IngressServiceSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressServiceSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalListenerConfig) DeepCopyInto(out *InternalListenerConfig) {
+	*out = *in
+	in.CommonListenerSpec.DeepCopyInto(&out.CommonListenerSpec)
+	if in.NetworkAttachment != nil {
+		in, out := &in.NetworkAttachment, &out.NetworkAttachment
+		*out = new(ListenerNetworkAttachment)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalListenerConfig.
+func (in *InternalListenerConfig) DeepCopy() *InternalListenerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalListenerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioControlPlaneReference) DeepCopyInto(out *IstioControlPlaneReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioControlPlaneReference.
+func (in *IstioControlPlaneReference) DeepCopy() *IstioControlPlaneReference {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioControlPlaneReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioIngressConfig) DeepCopyInto(out *IstioIngressConfig) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]*v1.Toleration, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(v1.Toleration)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TLSOptions != nil {
+		in, out := &in.TLSOptions, &out.TLSOptions
+		*out = new(networkingv1beta1.TLSOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VirtualServiceAnnotations != nil {
+		in, out := &in.VirtualServiceAnnotations, &out.VirtualServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Envs != nil {
+		in, out := &in.Envs, &out.Envs
+		*out = make([]*v1.EnvVar, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(v1.EnvVar)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioIngressConfig.
+func (in *IstioIngressConfig) DeepCopy() *IstioIngressConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioIngressConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JVMOptions) DeepCopyInto(out *JVMOptions) {
+	*out = *in
+	if in.Flags != nil {
+		in, out := &in.Flags, &out.Flags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SystemProperties != nil {
+		in, out := &in.SystemProperties, &out.SystemProperties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JVMOptions.
+func (in *JVMOptions) DeepCopy() *JVMOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(JVMOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaCluster) DeepCopyInto(out *KafkaCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaCluster.
+func (in *KafkaCluster) DeepCopy() *KafkaCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaClusterChangePlan) DeepCopyInto(out *KafkaClusterChangePlan) {
+	*out = *in
+	in.ComputedAt.DeepCopyInto(&out.ComputedAt)
+	if in.BrokersToRestart != nil {
+		in, out := &in.BrokersToRestart, &out.BrokersToRestart
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.BrokersToAdd != nil {
+		in, out := &in.BrokersToAdd, &out.BrokersToAdd
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.BrokersToRemove != nil {
+		in, out := &in.BrokersToRemove, &out.BrokersToRemove
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.CruiseControlOperations != nil {
+		in, out := &in.CruiseControlOperations, &out.CruiseControlOperations
+		*out = make([]PlannedCruiseControlOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]PlannedResourceChange, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterChangePlan.
+func (in *KafkaClusterChangePlan) DeepCopy() *KafkaClusterChangePlan {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaClusterChangePlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaClusterList) DeepCopyInto(out *KafkaClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterList.
+func (in *KafkaClusterList) DeepCopy() *KafkaClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaClusterSpec) DeepCopyInto(out *KafkaClusterSpec) {
+	*out = *in
+	in.ListenersConfig.DeepCopyInto(&out.ListenersConfig)
+	if in.AdditionalPorts != nil {
+		in, out := &in.AdditionalPorts, &out.AdditionalPorts
+		*out = make([]v1.ContainerPort, len(*in))
+		copy(*out, *in)
+	}
+	if in.ZKAddresses != nil {
+		in, out := &in.ZKAddresses, &out.ZKAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RackAwareness != nil {
+		in, out := &in.RackAwareness, &out.RackAwareness
+		*out = new(RackAwareness)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.BrokerConfigGroups != nil {
+		in, out := &in.BrokerConfigGroups, &out.BrokerConfigGroups
+		*out = make(map[string]BrokerConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]Broker, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.DisruptionBudget = in.DisruptionBudget
+	in.RollingUpgradeConfig.DeepCopyInto(&out.RollingUpgradeConfig)
+	if in.IstioControlPlane != nil {
+		in, out := &in.IstioControlPlane, &out.IstioControlPlane
+		*out = new(IstioControlPlaneReference)
+		**out = **in
+	}
+	in.CruiseControlConfig.DeepCopyInto(&out.CruiseControlConfig)
+	in.EnvoyConfig.DeepCopyInto(&out.EnvoyConfig)
+	out.MonitoringConfig = in.MonitoringConfig
+	if in.AlertManagerConfig != nil {
+		in, out := &in.AlertManagerConfig, &out.AlertManagerConfig
+		*out = new(AlertManagerConfig)
+		**out = **in
+	}
+	in.IstioIngressConfig.DeepCopyInto(&out.IstioIngressConfig)
+	if in.Envs != nil {
+		in, out := &in.Envs, &out.Envs
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.JVMOptions != nil {
+		in, out := &in.JVMOptions, &out.JVMOptions
+		*out = new(JVMOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientSSLCertSecret != nil {
+		in, out := &in.ClientSSLCertSecret, &out.ClientSSLCertSecret
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.NetworkPolicyConfig != nil {
+		in, out := &in.NetworkPolicyConfig, &out.NetworkPolicyConfig
+		*out = new(NetworkPolicyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SPIFFEConfig != nil {
+		in, out := &in.SPIFFEConfig, &out.SPIFFEConfig
+		*out = new(SPIFFEConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KafkaExporterConfig != nil {
+		in, out := &in.KafkaExporterConfig, &out.KafkaExporterConfig
+		*out = new(KafkaExporterConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FinalSnapshotHook != nil {
+		in, out := &in.FinalSnapshotHook, &out.FinalSnapshotHook
+		*out = new(HookJob)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AuthorizationConfig != nil {
+		in, out := &in.AuthorizationConfig, &out.AuthorizationConfig
+		*out = new(AuthorizationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DelegationTokenConfig != nil {
+		in, out := &in.DelegationTokenConfig, &out.DelegationTokenConfig
+		*out = new(DelegationTokenConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientQuotaConfig != nil {
+		in, out := &in.ClientQuotaConfig, &out.ClientQuotaConfig
+		*out = new(ClientQuotaConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteJMXConfig != nil {
+		in, out := &in.RemoteJMXConfig, &out.RemoteJMXConfig
+		*out = new(RemoteJMXConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ZoneBalancing != nil {
+		in, out := &in.ZoneBalancing, &out.ZoneBalancing
+		*out = new(ZoneBalancingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicationFactorRepair != nil {
+		in, out := &in.ReplicationFactorRepair, &out.ReplicationFactorRepair
+		*out = new(ReplicationFactorRepairConfig)
+		**out = **in
+	}
+	if in.ParkedBrokerConfigGroups != nil {
+		in, out := &in.ParkedBrokerConfigGroups, &out.ParkedBrokerConfigGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CapacitySchedule != nil {
+		in, out := &in.CapacitySchedule, &out.CapacitySchedule
+		*out = new(CapacitySchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BrokerFailurePolicy != nil {
+		in, out := &in.BrokerFailurePolicy, &out.BrokerFailurePolicy
+		*out = new(BrokerFailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeSnapshots != nil {
+		in, out := &in.VolumeSnapshots, &out.VolumeSnapshots
+		*out = new(VolumeSnapshotConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OrphanedResourceCleanup != nil {
+		in, out := &in.OrphanedResourceCleanup, &out.OrphanedResourceCleanup
+		*out = new(OrphanedResourceCleanupConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CrossNamespaceReferences != nil {
+		in, out := &in.CrossNamespaceReferences, &out.CrossNamespaceReferences
+		*out = new(CrossNamespaceReferences)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceBinding != nil {
+		in, out := &in.ServiceBinding, &out.ServiceBinding
+		*out = new(ClusterServiceBinding)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GracefulActionState.
-func (in *GracefulActionState) DeepCopy() *GracefulActionState {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterSpec.
+func (in *KafkaClusterSpec) DeepCopy() *KafkaClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaClusterStatus) DeepCopyInto(out *KafkaClusterStatus) {
+	*out = *in
+	if in.BrokersState != nil {
+		in, out := &in.BrokersState, &out.BrokersState
+		*out = make(map[string]BrokerState, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	out.RollingUpgrade = in.RollingUpgrade
+	in.ListenerStatuses.DeepCopyInto(&out.ListenerStatuses)
+	if in.CruiseControlOperations != nil {
+		in, out := &in.CruiseControlOperations, &out.CruiseControlOperations
+		*out = new(CruiseControlOperationSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetiredBrokerIDs != nil {
+		in, out := &in.RetiredBrokerIDs, &out.RetiredBrokerIDs
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.HighestBrokerID != nil {
+		in, out := &in.HighestBrokerID, &out.HighestBrokerID
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ChangePlan != nil {
+		in, out := &in.ChangePlan, &out.ChangePlan
+		*out = new(KafkaClusterChangePlan)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiskUsage != nil {
+		in, out := &in.DiskUsage, &out.DiskUsage
+		*out = make(map[string][]LogDirUsage, len(*in))
+		for key, val := range *in {
+			var outVal []LogDirUsage
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]LogDirUsage, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ZoneBalance != nil {
+		in, out := &in.ZoneBalance, &out.ZoneBalance
+		*out = new(ZoneBalanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicationFactorRepair != nil {
+		in, out := &in.ReplicationFactorRepair, &out.ReplicationFactorRepair
+		*out = new(ReplicationFactorRepairStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BrokerGroupParking != nil {
+		in, out := &in.BrokerGroupParking, &out.BrokerGroupParking
+		*out = make(map[string]BrokerGroupParkingStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.CapacitySchedule != nil {
+		in, out := &in.CapacitySchedule, &out.CapacitySchedule
+		*out = new(CapacityScheduleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailedBrokers != nil {
+		in, out := &in.FailedBrokers, &out.FailedBrokers
+		*out = make(map[string]FailedBrokerStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.VolumeSnapshots != nil {
+		in, out := &in.VolumeSnapshots, &out.VolumeSnapshots
+		*out = make([]VolumeSnapshotReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OrphanedResources != nil {
+		in, out := &in.OrphanedResources, &out.OrphanedResources
+		*out = make([]OrphanedResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Binding != nil {
+		in, out := &in.Binding, &out.Binding
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterStatus.
+func (in *KafkaClusterStatus) DeepCopy() *KafkaClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaExporterConfig) DeepCopyInto(out *KafkaExporterConfig) {
+	*out = *in
+	if in.SASL != nil {
+		in, out := &in.SASL, &out.SASL
+		*out = new(KafkaExporterSASLConfig)
+		**out = **in
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaExporterConfig.
+func (in *KafkaExporterConfig) DeepCopy() *KafkaExporterConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaExporterConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaExporterSASLConfig) DeepCopyInto(out *KafkaExporterSASLConfig) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaExporterSASLConfig.
+func (in *KafkaExporterSASLConfig) DeepCopy() *KafkaExporterSASLConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaExporterSASLConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaVersion) DeepCopyInto(out *KafkaVersion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaVersion.
+func (in *KafkaVersion) DeepCopy() *KafkaVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerCertManagerConfig) DeepCopyInto(out *ListenerCertManagerConfig) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(apismetav1.ObjectReference)
+		**out = **in
+	}
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerCertManagerConfig.
+func (in *ListenerCertManagerConfig) DeepCopy() *ListenerCertManagerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerCertManagerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerConnectionLimits) DeepCopyInto(out *ListenerConnectionLimits) {
+	*out = *in
+	if in.MaxConnections != nil {
+		in, out := &in.MaxConnections, &out.MaxConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxConnectionCreationRate != nil {
+		in, out := &in.MaxConnectionCreationRate, &out.MaxConnectionCreationRate
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerConnectionLimits.
+func (in *ListenerConnectionLimits) DeepCopy() *ListenerConnectionLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerConnectionLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerNetworkAttachment) DeepCopyInto(out *ListenerNetworkAttachment) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerNetworkAttachment.
+func (in *ListenerNetworkAttachment) DeepCopy() *ListenerNetworkAttachment {
 	if in == nil {
 		return nil
 	}
-	out := new(GracefulActionState)
+	out := new(ListenerNetworkAttachment)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
+func (in *ListenerStatus) DeepCopyInto(out *ListenerStatus) {
 	*out = *in
-	in.IngressServiceSettings.DeepCopyInto(&out.IngressServiceSettings)
-	if in.IstioIngressConfig != nil {
-		in, out := &in.IstioIngressConfig, &out.IstioIngressConfig
-		*out = new(IstioIngressConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.EnvoyConfig != nil {
-		in, out := &in.EnvoyConfig, &out.EnvoyConfig
-		*out = new(EnvoyConfig)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressConfig.
-func (in *IngressConfig) DeepCopy() *IngressConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatus.
+func (in *ListenerStatus) DeepCopy() *ListenerStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(IngressConfig)
+	out := new(ListenerStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IngressServiceSettings) DeepCopyInto(out *IngressServiceSettings) {
-	*out = *in
-	if in.ServiceAnnotations != nil {
-		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+func (in ListenerStatusList) DeepCopyInto(out *ListenerStatusList) {
+	{
+		in := &in
+		*out = make(ListenerStatusList, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressServiceSettings.
-func (in *IngressServiceSettings) DeepCopy() *IngressServiceSettings {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatusList.
+func (in ListenerStatusList) DeepCopy() ListenerStatusList {
 	if in == nil {
 		return nil
 	}
-	out := new(IngressServiceSettings)
+	out := new(ListenerStatusList)
 	in.DeepCopyInto(out)
-	return out
+	return *out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InternalListenerConfig) DeepCopyInto(out *InternalListenerConfig) {
+func (in *ListenerStatuses) DeepCopyInto(out *ListenerStatuses) {
 	*out = *in
-	in.CommonListenerSpec.DeepCopyInto(&out.CommonListenerSpec)
+	if in.InternalListeners != nil {
+		in, out := &in.InternalListeners, &out.InternalListeners
+		*out = make(map[string]ListenerStatusList, len(*in))
+		for key, val := range *in {
+			var outVal []ListenerStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(ListenerStatusList, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ExternalListeners != nil {
+		in, out := &in.ExternalListeners, &out.ExternalListeners
+		*out = make(map[string]ListenerStatusList, len(*in))
+		for key, val := range *in {
+			var outVal []ListenerStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(ListenerStatusList, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalListenerConfig.
-func (in *InternalListenerConfig) DeepCopy() *InternalListenerConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatuses.
+func (in *ListenerStatuses) DeepCopy() *ListenerStatuses {
 	if in == nil {
 		return nil
 	}
-	out := new(InternalListenerConfig)
+	out := new(ListenerStatuses)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IstioControlPlaneReference) DeepCopyInto(out *IstioControlPlaneReference) {
+func (in *ListenerTLSPolicy) DeepCopyInto(out *ListenerTLSPolicy) {
 	*out = *in
+	if in.EnabledProtocols != nil {
+		in, out := &in.EnabledProtocols, &out.EnabledProtocols
+		*out = make([]TLSProtocol, len(*in))
+		copy(*out, *in)
+	}
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioControlPlaneReference.
-func (in *IstioControlPlaneReference) DeepCopy() *IstioControlPlaneReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerTLSPolicy.
+func (in *ListenerTLSPolicy) DeepCopy() *ListenerTLSPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(IstioControlPlaneReference)
+	out := new(ListenerTLSPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IstioIngressConfig) DeepCopyInto(out *IstioIngressConfig) {
+func (in *ListenersConfig) DeepCopyInto(out *ListenersConfig) {
 	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]*v1.Toleration, len(*in))
+	if in.ExternalListeners != nil {
+		in, out := &in.ExternalListeners, &out.ExternalListeners
+		*out = make([]ExternalListenerConfig, len(*in))
 		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(v1.Toleration)
-				(*in).DeepCopyInto(*out)
-			}
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	if in.InternalListeners != nil {
+		in, out := &in.InternalListeners, &out.InternalListeners
+		*out = make([]InternalListenerConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.TLSOptions != nil {
-		in, out := &in.TLSOptions, &out.TLSOptions
-		*out = new(networkingv1beta1.TLSOptions)
+	if in.SSLSecrets != nil {
+		in, out := &in.SSLSecrets, &out.SSLSecrets
+		*out = new(SSLSecrets)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.VirtualServiceAnnotations != nil {
-		in, out := &in.VirtualServiceAnnotations, &out.VirtualServiceAnnotations
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.Envs != nil {
-		in, out := &in.Envs, &out.Envs
-		*out = make([]*v1.EnvVar, len(*in))
+	if in.AdditionalTrustedCAs != nil {
+		in, out := &in.AdditionalTrustedCAs, &out.AdditionalTrustedCAs
+		*out = make([]TrustedCASource, len(*in))
 		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(v1.EnvVar)
-				(*in).DeepCopyInto(*out)
-			}
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.LoadBalancerSourceRanges != nil {
-		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.ConnectionLimits != nil {
+		in, out := &in.ConnectionLimits, &out.ConnectionLimits
+		*out = new(BrokerConnectionLimits)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioIngressConfig.
-func (in *IstioIngressConfig) DeepCopy() *IstioIngressConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenersConfig.
+func (in *ListenersConfig) DeepCopy() *ListenersConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(IstioIngressConfig)
+	out := new(ListenersConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaCluster) DeepCopyInto(out *KafkaCluster) {
+func (in *LogDirUsage) DeepCopyInto(out *LogDirUsage) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.CapacityBytes != nil {
+		in, out := &in.CapacityBytes, &out.CapacityBytes
+		*out = new(int64)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaCluster.
-func (in *KafkaCluster) DeepCopy() *KafkaCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogDirUsage.
+func (in *LogDirUsage) DeepCopy() *LogDirUsage {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaCluster)
+	out := new(LogDirUsage)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KafkaCluster) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaClusterList) DeepCopyInto(out *KafkaClusterList) {
+func (in *MonitoringConfig) DeepCopyInto(out *MonitoringConfig) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]KafkaCluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterList.
-func (in *KafkaClusterList) DeepCopy() *KafkaClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConfig.
+func (in *MonitoringConfig) DeepCopy() *MonitoringConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaClusterList)
+	out := new(MonitoringConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KafkaClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkConfig) DeepCopyInto(out *NetworkConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkConfig.
+func (in *NetworkConfig) DeepCopy() *NetworkConfig {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(NetworkConfig)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaClusterSpec) DeepCopyInto(out *KafkaClusterSpec) {
+func (in *NetworkPolicyConfig) DeepCopyInto(out *NetworkPolicyConfig) {
 	*out = *in
-	in.ListenersConfig.DeepCopyInto(&out.ListenersConfig)
-	if in.AdditionalPorts != nil {
-		in, out := &in.AdditionalPorts, &out.AdditionalPorts
-		*out = make([]v1.ContainerPort, len(*in))
-		copy(*out, *in)
-	}
-	if in.ZKAddresses != nil {
-		in, out := &in.ZKAddresses, &out.ZKAddresses
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.RackAwareness != nil {
-		in, out := &in.RackAwareness, &out.RackAwareness
-		*out = new(RackAwareness)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.BrokerConfigGroups != nil {
-		in, out := &in.BrokerConfigGroups, &out.BrokerConfigGroups
-		*out = make(map[string]BrokerConfig, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
-	}
-	if in.Brokers != nil {
-		in, out := &in.Brokers, &out.Brokers
-		*out = make([]Broker, len(*in))
+	if in.OperatorPeers != nil {
+		in, out := &in.OperatorPeers, &out.OperatorPeers
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.DisruptionBudget = in.DisruptionBudget
-	out.RollingUpgradeConfig = in.RollingUpgradeConfig
-	if in.IstioControlPlane != nil {
-		in, out := &in.IstioControlPlane, &out.IstioControlPlane
-		*out = new(IstioControlPlaneReference)
-		**out = **in
-	}
-	in.CruiseControlConfig.DeepCopyInto(&out.CruiseControlConfig)
-	in.EnvoyConfig.DeepCopyInto(&out.EnvoyConfig)
-	out.MonitoringConfig = in.MonitoringConfig
-	if in.AlertManagerConfig != nil {
-		in, out := &in.AlertManagerConfig, &out.AlertManagerConfig
-		*out = new(AlertManagerConfig)
-		**out = **in
+	if in.ListenerPeers != nil {
+		in, out := &in.ListenerPeers, &out.ListenerPeers
+		*out = make(map[string][]networkingv1.NetworkPolicyPeer, len(*in))
+		for key, val := range *in {
+			var outVal []networkingv1.NetworkPolicyPeer
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
 	}
-	in.IstioIngressConfig.DeepCopyInto(&out.IstioIngressConfig)
-	if in.Envs != nil {
-		in, out := &in.Envs, &out.Envs
-		*out = make([]v1.EnvVar, len(*in))
+	if in.MetricsPeers != nil {
+		in, out := &in.MetricsPeers, &out.MetricsPeers
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.ClientSSLCertSecret != nil {
-		in, out := &in.ClientSSLCertSecret, &out.ClientSSLCertSecret
-		*out = new(v1.LocalObjectReference)
-		**out = **in
+	if in.ZooKeeperPodSelector != nil {
+		in, out := &in.ZooKeeperPodSelector, &out.ZooKeeperPodSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterSpec.
-func (in *KafkaClusterSpec) DeepCopy() *KafkaClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicyConfig.
+func (in *NetworkPolicyConfig) DeepCopy() *NetworkPolicyConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaClusterSpec)
+	out := new(NetworkPolicyConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaClusterStatus) DeepCopyInto(out *KafkaClusterStatus) {
+func (in *OPAAuthorizerConfig) DeepCopyInto(out *OPAAuthorizerConfig) {
 	*out = *in
-	if in.BrokersState != nil {
-		in, out := &in.BrokersState, &out.BrokersState
-		*out = make(map[string]BrokerState, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	if in.AllowOnError != nil {
+		in, out := &in.AllowOnError, &out.AllowOnError
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CacheExpireAfterSeconds != nil {
+		in, out := &in.CacheExpireAfterSeconds, &out.CacheExpireAfterSeconds
+		*out = new(int64)
+		**out = **in
 	}
-	out.RollingUpgrade = in.RollingUpgrade
-	in.ListenerStatuses.DeepCopyInto(&out.ListenerStatuses)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterStatus.
-func (in *KafkaClusterStatus) DeepCopy() *KafkaClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OPAAuthorizerConfig.
+func (in *OPAAuthorizerConfig) DeepCopy() *OPAAuthorizerConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaClusterStatus)
+	out := new(OPAAuthorizerConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaVersion) DeepCopyInto(out *KafkaVersion) {
+func (in *OrphanedResource) DeepCopyInto(out *OrphanedResource) {
 	*out = *in
+	in.OrphanedSince.DeepCopyInto(&out.OrphanedSince)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaVersion.
-func (in *KafkaVersion) DeepCopy() *KafkaVersion {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedResource.
+func (in *OrphanedResource) DeepCopy() *OrphanedResource {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaVersion)
+	out := new(OrphanedResource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListenerStatus) DeepCopyInto(out *ListenerStatus) {
+func (in *OrphanedResourceCleanupConfig) DeepCopyInto(out *OrphanedResourceCleanupConfig) {
 	*out = *in
+	if in.RetentionSeconds != nil {
+		in, out := &in.RetentionSeconds, &out.RetentionSeconds
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatus.
-func (in *ListenerStatus) DeepCopy() *ListenerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedResourceCleanupConfig.
+func (in *OrphanedResourceCleanupConfig) DeepCopy() *OrphanedResourceCleanupConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ListenerStatus)
+	out := new(OrphanedResourceCleanupConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in ListenerStatusList) DeepCopyInto(out *ListenerStatusList) {
-	{
-		in := &in
-		*out = make(ListenerStatusList, len(*in))
+func (in *PlannedCruiseControlOperation) DeepCopyInto(out *PlannedCruiseControlOperation) {
+	*out = *in
+	if in.BrokerIDs != nil {
+		in, out := &in.BrokerIDs, &out.BrokerIDs
+		*out = make([]int32, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatusList.
-func (in ListenerStatusList) DeepCopy() ListenerStatusList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedCruiseControlOperation.
+func (in *PlannedCruiseControlOperation) DeepCopy() *PlannedCruiseControlOperation {
 	if in == nil {
 		return nil
 	}
-	out := new(ListenerStatusList)
+	out := new(PlannedCruiseControlOperation)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListenerStatuses) DeepCopyInto(out *ListenerStatuses) {
+func (in *PlannedResourceChange) DeepCopyInto(out *PlannedResourceChange) {
 	*out = *in
-	if in.InternalListeners != nil {
-		in, out := &in.InternalListeners, &out.InternalListeners
-		*out = make(map[string]ListenerStatusList, len(*in))
-		for key, val := range *in {
-			var outVal []ListenerStatus
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				in, out := &val, &outVal
-				*out = make(ListenerStatusList, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
-		}
-	}
-	if in.ExternalListeners != nil {
-		in, out := &in.ExternalListeners, &out.ExternalListeners
-		*out = make(map[string]ListenerStatusList, len(*in))
-		for key, val := range *in {
-			var outVal []ListenerStatus
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				in, out := &val, &outVal
-				*out = make(ListenerStatusList, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerStatuses.
-func (in *ListenerStatuses) DeepCopy() *ListenerStatuses {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedResourceChange.
+func (in *PlannedResourceChange) DeepCopy() *PlannedResourceChange {
 	if in == nil {
 		return nil
 	}
-	out := new(ListenerStatuses)
+	out := new(PlannedResourceChange)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListenersConfig) DeepCopyInto(out *ListenersConfig) {
+func (in *RackAwareness) DeepCopyInto(out *RackAwareness) {
 	*out = *in
-	if in.ExternalListeners != nil {
-		in, out := &in.ExternalListeners, &out.ExternalListeners
-		*out = make([]ExternalListenerConfig, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.InternalListeners != nil {
-		in, out := &in.InternalListeners, &out.InternalListeners
-		*out = make([]InternalListenerConfig, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.SSLSecrets != nil {
-		in, out := &in.SSLSecrets, &out.SSLSecrets
-		*out = new(SSLSecrets)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ServiceAnnotations != nil {
-		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenersConfig.
-func (in *ListenersConfig) DeepCopy() *ListenersConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RackAwareness.
+func (in *RackAwareness) DeepCopy() *RackAwareness {
 	if in == nil {
 		return nil
 	}
-	out := new(ListenersConfig)
+	out := new(RackAwareness)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitoringConfig) DeepCopyInto(out *MonitoringConfig) {
+func (in *RemoteJMXConfig) DeepCopyInto(out *RemoteJMXConfig) {
 	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConfig.
-func (in *MonitoringConfig) DeepCopy() *MonitoringConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteJMXConfig.
+func (in *RemoteJMXConfig) DeepCopy() *RemoteJMXConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitoringConfig)
+	out := new(RemoteJMXConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkConfig) DeepCopyInto(out *NetworkConfig) {
+func (in *ReplicationFactorRepairConfig) DeepCopyInto(out *ReplicationFactorRepairConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkConfig.
-func (in *NetworkConfig) DeepCopy() *NetworkConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationFactorRepairConfig.
+func (in *ReplicationFactorRepairConfig) DeepCopy() *ReplicationFactorRepairConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkConfig)
+	out := new(ReplicationFactorRepairConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RackAwareness) DeepCopyInto(out *RackAwareness) {
+func (in *ReplicationFactorRepairStatus) DeepCopyInto(out *ReplicationFactorRepairStatus) {
 	*out = *in
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
+	in.CheckedAt.DeepCopyInto(&out.CheckedAt)
+	if in.LostBrokers != nil {
+		in, out := &in.LostBrokers, &out.LostBrokers
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RackAwareness.
-func (in *RackAwareness) DeepCopy() *RackAwareness {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationFactorRepairStatus.
+func (in *ReplicationFactorRepairStatus) DeepCopy() *ReplicationFactorRepairStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RackAwareness)
+	out := new(ReplicationFactorRepairStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1077,6 +2800,21 @@ func (in *RackAwareness) DeepCopy() *RackAwareness {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RollingUpgradeConfig) DeepCopyInto(out *RollingUpgradeConfig) {
 	*out = *in
+	if in.BrokerReadinessGate != nil {
+		in, out := &in.BrokerReadinessGate, &out.BrokerReadinessGate
+		*out = new(BrokerReadinessGate)
+		**out = **in
+	}
+	if in.PreUpgradeHook != nil {
+		in, out := &in.PreUpgradeHook, &out.PreUpgradeHook
+		*out = new(HookJob)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostUpgradeHook != nil {
+		in, out := &in.PostUpgradeHook, &out.PostUpgradeHook
+		*out = new(HookJob)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpgradeConfig.
@@ -1104,12 +2842,32 @@ func (in *RollingUpgradeStatus) DeepCopy() *RollingUpgradeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SPIFFEConfig) DeepCopyInto(out *SPIFFEConfig) {
+	*out = *in
+	if in.HelperResources != nil {
+		in, out := &in.HelperResources, &out.HelperResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SPIFFEConfig.
+func (in *SPIFFEConfig) DeepCopy() *SPIFFEConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SPIFFEConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SSLSecrets) DeepCopyInto(out *SSLSecrets) {
 	*out = *in
 	if in.IssuerRef != nil {
 		in, out := &in.IssuerRef, &out.IssuerRef
-		*out = new(metav1.ObjectReference)
+		*out = new(apismetav1.ObjectReference)
 		**out = **in
 	}
 }
@@ -1164,6 +2922,72 @@ func (in *TopicConfig) DeepCopy() *TopicConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustedCASource) DeepCopyInto(out *TrustedCASource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustedCASource.
+func (in *TrustedCASource) DeepCopy() *TrustedCASource {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustedCASource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotConfig) DeepCopyInto(out *VolumeSnapshotConfig) {
+	*out = *in
+	if in.Before != nil {
+		in, out := &in.Before, &out.Before
+		*out = make([]VolumeSnapshotOperation, len(*in))
+		copy(*out, *in)
+	}
+	if in.Retained != nil {
+		in, out := &in.Retained, &out.Retained
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotConfig.
+func (in *VolumeSnapshotConfig) DeepCopy() *VolumeSnapshotConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotReference) DeepCopyInto(out *VolumeSnapshotReference) {
+	*out = *in
+	in.CreationTime.DeepCopyInto(&out.CreationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotReference.
+func (in *VolumeSnapshotReference) DeepCopy() *VolumeSnapshotReference {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeState) DeepCopyInto(out *VolumeState) {
 	*out = *in
@@ -1172,6 +2996,10 @@ func (in *VolumeState) DeepCopyInto(out *VolumeState) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeState.
@@ -1183,3 +3011,84 @@ func (in *VolumeState) DeepCopy() *VolumeState {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeeklyTimeWindow) DeepCopyInto(out *WeeklyTimeWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeeklyTimeWindow.
+func (in *WeeklyTimeWindow) DeepCopy() *WeeklyTimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(WeeklyTimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneBalanceStatus) DeepCopyInto(out *ZoneBalanceStatus) {
+	*out = *in
+	in.ComputedAt.DeepCopyInto(&out.ComputedAt)
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]ZoneLoad, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneBalanceStatus.
+func (in *ZoneBalanceStatus) DeepCopy() *ZoneBalanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneBalanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneBalancingConfig) DeepCopyInto(out *ZoneBalancingConfig) {
+	*out = *in
+	if in.RebalanceSkewThresholdPercent != nil {
+		in, out := &in.RebalanceSkewThresholdPercent, &out.RebalanceSkewThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneBalancingConfig.
+func (in *ZoneBalancingConfig) DeepCopy() *ZoneBalancingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneBalancingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneLoad) DeepCopyInto(out *ZoneLoad) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneLoad.
+func (in *ZoneLoad) DeepCopy() *ZoneLoad {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneLoad)
+	in.DeepCopyInto(out)
+	return out
+}
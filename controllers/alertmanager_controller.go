@@ -19,6 +19,7 @@ import (
 	"net"
 	"net/http"
 
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -33,13 +34,14 @@ const (
 
 // AController implements Runnable
 type AController struct {
-	Client client.Client
+	Client   client.Client
+	Recorder record.EventRecorder
 }
 
 // SetAlertManagerWithManager creates a new Alertmanager Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func SetAlertManagerWithManager(mgr manager.Manager) error {
-	return mgr.Add(AController{Client: mgr.GetClient()})
+	return mgr.Add(AController{Client: mgr.GetClient(), Recorder: mgr.GetEventRecorderFor("cruisecontrol-anomaly-notifier")})
 }
 
 // Start initiates the alertmanager controller
@@ -48,6 +50,6 @@ func (c AController) Start(ctx context.Context) error {
 	log := logf.Log.WithName("alertmanager")
 
 	ln, _ := net.Listen("tcp", receiverAddr)
-	httpServer := &http.Server{Handler: alertmanager.NewApp(log, c.Client)}
+	httpServer := &http.Server{Handler: alertmanager.NewApp(log, c.Client, c.Recorder)}
 	return httpServer.Serve(ln)
 }
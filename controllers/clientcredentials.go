@@ -0,0 +1,147 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+// clientListener returns the internal listener of the KafkaCluster a client deployed by the operator, e.g. Kafka
+// Connect, connects to. It defaults to the listener used for inner broker communication.
+func clientListener(cluster *v1beta1.KafkaCluster, listenerName string) (v1beta1.InternalListenerConfig, error) {
+	for _, listener := range cluster.Spec.ListenersConfig.InternalListeners {
+		if listenerName == "" && listener.UsedForInnerBrokerCommunication && !listener.UsedForControllerCommunication {
+			return listener, nil
+		}
+		if listenerName != "" && listener.Name == listenerName {
+			if listener.UsedForControllerCommunication {
+				return listener, errors.NewWithDetails("the listener used for controller communication cannot be used by clients", "listener", listenerName)
+			}
+			return listener, nil
+		}
+	}
+	return v1beta1.InternalListenerConfig{}, errors.NewWithDetails("internal listener not found in the KafkaCluster", "listener", listenerName)
+}
+
+// clientKafkaUser returns the KafkaUser holding the credentials of a client deployed by the operator. The client
+// authenticates with the client certificate on SSL listeners and with the delegation token on SASL listeners, the
+// truststore on SASL_SSL listeners is taken from the client certificate as well. There is no KafkaUser for
+// plaintext listeners.
+func clientKafkaUser(name, namespace string, clusterRef v1alpha1.ClusterReference, labels map[string]string,
+	protocol v1beta1.SecurityProtocol, grants []v1alpha1.UserTopicGrant) *v1alpha1.KafkaUser {
+	if protocol.IsPlaintext() {
+		return nil
+	}
+	user := &v1alpha1.KafkaUser{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: v1alpha1.KafkaUserSpec{
+			SecretName:  name + "-credentials",
+			ClusterRef:  clusterRef,
+			TopicGrants: grants,
+		},
+	}
+	if protocol.IsSSL() {
+		user.Spec.IncludeJKS = true
+	} else {
+		user.Spec.CreateCert = util.BoolPointer(false)
+	}
+	if protocol.IsSasl() {
+		user.Spec.DelegationToken = &v1alpha1.UserDelegationToken{SecretName: name + "-token"}
+	}
+	return user
+}
+
+// reconcileClientKafkaUser creates or updates the KafkaUser of a client owned by the given object
+func reconcileClientKafkaUser(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object, desired *v1alpha1.KafkaUser) error {
+	user := &v1alpha1.KafkaUser{ObjectMeta: metav1.ObjectMeta{Name: desired.GetName(), Namespace: desired.GetNamespace()}}
+	_, err := controllerutil.CreateOrUpdate(ctx, c, user, func() error {
+		if user.Labels == nil {
+			user.Labels = make(map[string]string)
+		}
+		for key, value := range desired.GetLabels() {
+			user.Labels[key] = value
+		}
+		user.Spec = desired.Spec
+		return controllerutil.SetControllerReference(owner, user, scheme)
+	})
+	return errors.WrapIfWithDetails(err, "could not create or update KafkaUser", "kafkaUser", desired.GetName())
+}
+
+// clientCredentials returns the Secrets holding the credentials of the KafkaUser of a client, ready is false when
+// the KafkaUser controller has not created them yet
+func clientCredentials(ctx context.Context, c client.Reader, user *v1alpha1.KafkaUser) (secrets []*corev1.Secret, ready bool, err error) {
+	if user == nil {
+		return nil, true, nil
+	}
+	secretNames := make([]string, 0, 2)
+	if user.Spec.GetIfCertShouldBeCreated() {
+		secretNames = append(secretNames, user.Spec.SecretName)
+	}
+	if user.Spec.DelegationToken != nil {
+		secretNames = append(secretNames, user.Spec.DelegationToken.SecretName)
+	}
+	for _, secretName := range secretNames {
+		secret := &corev1.Secret{}
+		err = c.Get(ctx, types.NamespacedName{Name: secretName, Namespace: user.GetNamespace()}, secret)
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, errors.WrapIfWithDetails(err, "could not get secret", "secret", secretName)
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, true, nil
+}
+
+// clientConfigHash returns the hash of the configuration and the credentials of a client, the pods of the client
+// are restarted when it changes
+func clientConfigHash(config *properties.Properties, secrets []*corev1.Secret) string {
+	h := sha256.New()
+	for _, key := range config.Keys() {
+		property, _ := config.Get(key)
+		fmt.Fprintf(h, "config:%d:%s:%d:%s\n", len(key), key, len(property.Value()), property.Value())
+	}
+	for _, secret := range secrets {
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			name := secret.GetName() + "/" + key
+			fmt.Fprintf(h, "secret:%d:%s:%d:%s\n", len(name), name, len(secret.Data[key]), secret.Data[key])
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
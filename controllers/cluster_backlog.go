@@ -0,0 +1,297 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+var (
+	ccOperationsPendingGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_cruisecontroloperations_pending",
+		Help: "Number of CruiseControlOperations of the Kafka cluster waiting for execution",
+	}, []string{"namespace", "kafka_cluster"})
+	ccOperationsInProgressGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_cruisecontroloperations_in_progress",
+		Help: "Number of CruiseControlOperations of the Kafka cluster being executed by Cruise Control",
+	}, []string{"namespace", "kafka_cluster"})
+	ccReadyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_cruisecontrol_ready",
+		Help: "Whether Cruise Control of the Kafka cluster was ready when it was contacted last",
+	}, []string{"namespace", "kafka_cluster"})
+	ccLastContactGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_cruisecontrol_last_successful_contact_timestamp_seconds",
+		Help: "Unix time of the last successful contact with Cruise Control of the Kafka cluster",
+	}, []string{"namespace", "kafka_cluster"})
+	ccOperationIgnoredFailuresCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "koperator_cruisecontroloperations_ignored_failures_total",
+		Help: "Number of failed Cruise Control tasks of the Kafka cluster handled as completed due to the ignoreWithEvent error policy",
+	}, []string{"namespace", "kafka_cluster"})
+	ccPartitionMovementsInProgressGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_cruisecontrol_partition_movements_in_progress",
+		Help: "Number of partitions of the topic being moved by the executor of Cruise Control of the Kafka cluster",
+	}, []string{"namespace", "kafka_cluster", "topic"})
+	ccMonitoredWindowsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_cruisecontrol_monitored_windows",
+		Help: "Number of metric windows monitored by the load monitor of Cruise Control of the Kafka cluster",
+	}, []string{"namespace", "kafka_cluster"})
+	ccValidPartitionsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_cruisecontrol_valid_partitions_percentage",
+		Help: "Percentage of the partitions having enough valid metric samples in Cruise Control of the Kafka cluster",
+	}, []string{"namespace", "kafka_cluster"})
+	ccProposalReadyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_cruisecontrol_proposal_ready",
+		Help: "Whether the analyzer of Cruise Control of the Kafka cluster is ready to generate proposals",
+	}, []string{"namespace", "kafka_cluster"})
+	ccExecutorStateGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_cruisecontrol_executor_state",
+		Help: "State of the executor of Cruise Control of the Kafka cluster, the series of the current state is 1",
+	}, []string{"namespace", "kafka_cluster", "state"})
+	incompleteReconcilesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_kafkacluster_incomplete_reconciles",
+		Help: "Number of reconciles of the Kafka cluster which have been requeued or failed since the last completed one",
+	}, []string{"namespace", "kafka_cluster"})
+	lastReconcileGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_kafkacluster_last_reconcile_timestamp_seconds",
+		Help: "Unix time of the start of the last reconcile of the Kafka cluster",
+	}, []string{"namespace", "kafka_cluster"})
+	lastCompletedReconcileGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_kafkacluster_last_completed_reconcile_timestamp_seconds",
+		Help: "Unix time of the start of the last reconcile of the Kafka cluster which went through every step",
+	}, []string{"namespace", "kafka_cluster"})
+
+	// clusterBacklogs holds the backlog of the Kafka clusters managed by the operator
+	clusterBacklogs = &clusterBacklogTracker{}
+)
+
+func init() {
+	metrics.Registry.MustRegister(ccOperationsPendingGauge, ccOperationsInProgressGauge, ccReadyGauge, ccLastContactGauge,
+		ccOperationIgnoredFailuresCounter, ccPartitionMovementsInProgressGauge, ccMonitoredWindowsGauge, ccValidPartitionsGauge,
+		ccProposalReadyGauge, ccExecutorStateGauge, incompleteReconcilesGauge, lastReconcileGauge, lastCompletedReconcileGauge)
+}
+
+// ClusterBacklog summarizes the work waiting for a Kafka cluster
+type ClusterBacklog struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// IncompleteReconciles is the number of reconciles of the KafkaCluster which have been requeued or failed before
+	// going through every step since the last completed reconcile
+	IncompleteReconciles               int        `json:"incompleteReconciles"`
+	LastReconcile                      *time.Time `json:"lastReconcile,omitempty"`
+	LastCompletedReconcile             *time.Time `json:"lastCompletedReconcile,omitempty"`
+	PendingCCOperations                int        `json:"pendingCruiseControlOperations"`
+	InProgressCCOperations             int        `json:"inProgressCruiseControlOperations"`
+	CruiseControlReady                 bool       `json:"cruiseControlReady"`
+	LastSuccessfulCruiseControlContact *time.Time `json:"lastSuccessfulCruiseControlContact,omitempty"`
+	// PartitionMovementsInProgress is the number of partitions being moved by Cruise Control keyed by topic
+	PartitionMovementsInProgress map[string]int32 `json:"partitionMovementsInProgress,omitempty"`
+	// CruiseControlHealth is the state of the Cruise Control components when it was contacted last
+	CruiseControlHealth *CruiseControlHealth `json:"cruiseControlHealth,omitempty"`
+}
+
+// CruiseControlHealth describes the state of the Cruise Control components which decides whether
+// the CruiseControlOperations can be executed
+type CruiseControlHealth struct {
+	MonitoredWindows          float32 `json:"monitoredWindows"`
+	ValidPartitionsPercentage float64 `json:"validPartitionsPercentage"`
+	ProposalReady             bool    `json:"proposalReady"`
+	ExecutorState             string  `json:"executorState"`
+}
+
+// clusterBacklogTracker keeps the backlog of the Kafka clusters in sync with the exported metrics.
+// The zero value is ready to use.
+type clusterBacklogTracker struct {
+	mu       sync.Mutex
+	clusters map[types.NamespacedName]*ClusterBacklog
+}
+
+func (t *clusterBacklogTracker) backlog(cluster types.NamespacedName) *ClusterBacklog {
+	if t.clusters == nil {
+		t.clusters = make(map[types.NamespacedName]*ClusterBacklog)
+	}
+	backlog, ok := t.clusters[cluster]
+	if !ok {
+		backlog = &ClusterBacklog{Namespace: cluster.Namespace, Name: cluster.Name}
+		t.clusters[cluster] = backlog
+	}
+	return backlog
+}
+
+// setReconcileResult records the result of the reconcile of the KafkaCluster started at the given time
+func (t *clusterBacklogTracker) setReconcileResult(cluster types.NamespacedName, start time.Time, completed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	backlog := t.backlog(cluster)
+	backlog.LastReconcile = &start
+	if completed {
+		backlog.IncompleteReconciles = 0
+		backlog.LastCompletedReconcile = &start
+		lastCompletedReconcileGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(start.Unix()))
+	} else {
+		backlog.IncompleteReconciles++
+	}
+	incompleteReconcilesGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(backlog.IncompleteReconciles))
+	lastReconcileGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(start.Unix()))
+}
+
+// setCCOperations records the number of pending and in progress CruiseControlOperations of the Kafka cluster
+func (t *clusterBacklogTracker) setCCOperations(cluster types.NamespacedName, pending, inProgress int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	backlog := t.backlog(cluster)
+	backlog.PendingCCOperations = pending
+	backlog.InProgressCCOperations = inProgress
+	ccOperationsPendingGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(pending))
+	ccOperationsInProgressGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(inProgress))
+}
+
+// setCCContact records the result of contacting Cruise Control of the Kafka cluster
+func (t *clusterBacklogTracker) setCCContact(cluster types.NamespacedName, ready bool, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	backlog := t.backlog(cluster)
+	backlog.CruiseControlReady = ready
+	readyValue := 0.0
+	if ready {
+		readyValue = 1
+		backlog.LastSuccessfulCruiseControlContact = &now
+		ccLastContactGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(now.Unix()))
+	}
+	ccReadyGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(readyValue)
+}
+
+// setPartitionMovements records the number of partitions being moved by Cruise Control of the Kafka cluster per topic
+func (t *clusterBacklogTracker) setPartitionMovements(cluster types.NamespacedName, topics map[string]int32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	backlog := t.backlog(cluster)
+	for topic := range backlog.PartitionMovementsInProgress {
+		if _, ok := topics[topic]; !ok {
+			ccPartitionMovementsInProgressGauge.DeleteLabelValues(cluster.Namespace, cluster.Name, topic)
+		}
+	}
+	for topic, partitions := range topics {
+		ccPartitionMovementsInProgressGauge.WithLabelValues(cluster.Namespace, cluster.Name, topic).Set(float64(partitions))
+	}
+	backlog.PartitionMovementsInProgress = topics
+}
+
+// setCCHealth records the state of the Cruise Control components of the Kafka cluster
+func (t *clusterBacklogTracker) setCCHealth(cluster types.NamespacedName, status scale.CruiseControlStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	backlog := t.backlog(cluster)
+	if backlog.CruiseControlHealth != nil && backlog.CruiseControlHealth.ExecutorState != status.ExecutorState {
+		ccExecutorStateGauge.DeleteLabelValues(cluster.Namespace, cluster.Name, backlog.CruiseControlHealth.ExecutorState)
+	}
+	backlog.CruiseControlHealth = &CruiseControlHealth{
+		MonitoredWindows:          status.MonitoredWindows,
+		ValidPartitionsPercentage: status.ValidPartitionsPercentage,
+		ProposalReady:             status.ProposalReady,
+		ExecutorState:             status.ExecutorState,
+	}
+	proposalReady := 0.0
+	if status.ProposalReady {
+		proposalReady = 1
+	}
+	ccMonitoredWindowsGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(status.MonitoredWindows))
+	ccValidPartitionsGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(status.ValidPartitionsPercentage)
+	ccProposalReadyGauge.WithLabelValues(cluster.Namespace, cluster.Name).Set(proposalReady)
+	ccExecutorStateGauge.WithLabelValues(cluster.Namespace, cluster.Name, status.ExecutorState).Set(1)
+}
+
+// forget drops the backlog and the metrics of the removed Kafka cluster
+func (t *clusterBacklogTracker) forget(cluster types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if backlog, ok := t.clusters[cluster]; ok {
+		for topic := range backlog.PartitionMovementsInProgress {
+			ccPartitionMovementsInProgressGauge.DeleteLabelValues(cluster.Namespace, cluster.Name, topic)
+		}
+		if backlog.CruiseControlHealth != nil {
+			ccExecutorStateGauge.DeleteLabelValues(cluster.Namespace, cluster.Name, backlog.CruiseControlHealth.ExecutorState)
+		}
+	}
+	delete(t.clusters, cluster)
+	for _, gauge := range []*prometheus.GaugeVec{ccOperationsPendingGauge, ccOperationsInProgressGauge, ccReadyGauge, ccLastContactGauge,
+		ccMonitoredWindowsGauge, ccValidPartitionsGauge, ccProposalReadyGauge, incompleteReconcilesGauge, lastReconcileGauge,
+		lastCompletedReconcileGauge} {
+		gauge.DeleteLabelValues(cluster.Namespace, cluster.Name)
+	}
+	ccOperationIgnoredFailuresCounter.DeleteLabelValues(cluster.Namespace, cluster.Name)
+}
+
+// snapshot returns the backlog of every tracked Kafka cluster ordered by namespace and name
+func (t *clusterBacklogTracker) snapshot() []ClusterBacklog {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ret := make([]ClusterBacklog, 0, len(t.clusters))
+	for _, backlog := range t.clusters {
+		ret = append(ret, *backlog)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Namespace != ret[j].Namespace {
+			return ret[i].Namespace < ret[j].Namespace
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// ClusterBacklogHandler returns the HTTP handler serving the backlog of the Kafka clusters as JSON
+func ClusterBacklogHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(clusterBacklogs.snapshot()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// ccOperationBacklog returns the number of pending and in progress CruiseControlOperations of the Kafka cluster
+func ccOperationBacklog(operations []banzaiv1alpha1.CruiseControlOperation, cluster types.NamespacedName) (int, int) {
+	var pending, inProgress int
+	for i := range operations {
+		operation := &operations[i]
+		if operation.GetNamespace() != cluster.Namespace || operation.GetClusterRef() != cluster.Name ||
+			!operation.IsCurrentTaskOperationValid() || operation.IsDone() {
+			continue
+		}
+		if operation.IsCurrentTaskRunning() {
+			inProgress++
+		} else {
+			pending++
+		}
+	}
+	return pending, inProgress
+}
@@ -0,0 +1,173 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+func TestCCOperationBacklog(t *testing.T) {
+	newOperation := func(cluster string, state v1beta1.CruiseControlUserTaskState) v1alpha1.CruiseControlOperation {
+		return v1alpha1.CruiseControlOperation{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "kafka",
+				Labels:    map[string]string{v1beta1.KafkaCRLabelKey: cluster},
+			},
+			Status: v1alpha1.CruiseControlOperationStatus{
+				CurrentTask: &v1alpha1.CruiseControlTask{
+					Operation: v1alpha1.OperationRebalance,
+					State:     state,
+				},
+			},
+		}
+	}
+	operations := []v1alpha1.CruiseControlOperation{
+		newOperation("kafka", ""),
+		newOperation("kafka", v1beta1.CruiseControlTaskCompletedWithError),
+		newOperation("kafka", v1beta1.CruiseControlTaskActive),
+		newOperation("kafka", v1beta1.CruiseControlTaskCompleted),
+		newOperation("other", ""),
+	}
+
+	pending, inProgress := ccOperationBacklog(operations, types.NamespacedName{Namespace: "kafka", Name: "kafka"})
+	assert.Equal(t, 2, pending)
+	assert.Equal(t, 1, inProgress)
+}
+
+func TestClusterBacklogTracker(t *testing.T) {
+	tracker := &clusterBacklogTracker{}
+	cluster := types.NamespacedName{Namespace: "kafka", Name: "kafka"}
+	otherCluster := types.NamespacedName{Namespace: "default", Name: "kafka"}
+	defer tracker.forget(cluster)
+	defer tracker.forget(otherCluster)
+	now := time.Unix(1680000000, 0)
+
+	tracker.setCCOperations(cluster, 3, 1)
+	tracker.setCCContact(cluster, true, now)
+	tracker.setCCContact(cluster, false, now.Add(time.Minute))
+	tracker.setCCOperations(otherCluster, 0, 0)
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(ccOperationsPendingGauge.WithLabelValues("kafka", "kafka")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(ccOperationsInProgressGauge.WithLabelValues("kafka", "kafka")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(ccReadyGauge.WithLabelValues("kafka", "kafka")))
+	assert.Equal(t, float64(now.Unix()), testutil.ToFloat64(ccLastContactGauge.WithLabelValues("kafka", "kafka")))
+
+	snapshot := tracker.snapshot()
+	assert.Len(t, snapshot, 2)
+	assert.Equal(t, "default", snapshot[0].Namespace)
+	assert.Equal(t, 3, snapshot[1].PendingCCOperations)
+	assert.False(t, snapshot[1].CruiseControlReady)
+	assert.Equal(t, now, *snapshot[1].LastSuccessfulCruiseControlContact)
+
+	tracker.forget(otherCluster)
+	assert.Len(t, tracker.snapshot(), 1)
+}
+
+func TestClusterBacklogReconciles(t *testing.T) {
+	tracker := &clusterBacklogTracker{}
+	cluster := types.NamespacedName{Namespace: "kafka", Name: "reconciles"}
+	defer tracker.forget(cluster)
+	now := time.Unix(1680000000, 0)
+
+	tracker.setReconcileResult(cluster, now, true)
+	tracker.setReconcileResult(cluster, now.Add(time.Minute), false)
+	tracker.setReconcileResult(cluster, now.Add(2*time.Minute), false)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(incompleteReconcilesGauge.WithLabelValues("kafka", "reconciles")))
+	assert.Equal(t, float64(now.Add(2*time.Minute).Unix()), testutil.ToFloat64(lastReconcileGauge.WithLabelValues("kafka", "reconciles")))
+	assert.Equal(t, float64(now.Unix()), testutil.ToFloat64(lastCompletedReconcileGauge.WithLabelValues("kafka", "reconciles")))
+	backlog := tracker.snapshot()[0]
+	assert.Equal(t, 2, backlog.IncompleteReconciles)
+	assert.Equal(t, now, *backlog.LastCompletedReconcile)
+
+	// a completed reconcile clears the backlog of the reconciles
+	tracker.setReconcileResult(cluster, now.Add(3*time.Minute), true)
+	assert.Equal(t, 0.0, testutil.ToFloat64(incompleteReconcilesGauge.WithLabelValues("kafka", "reconciles")))
+	assert.Equal(t, now.Add(3*time.Minute), *tracker.snapshot()[0].LastCompletedReconcile)
+
+	tracker.forget(cluster)
+	assert.Empty(t, tracker.snapshot())
+}
+
+func TestClusterBacklogPartitionMovements(t *testing.T) {
+	tracker := &clusterBacklogTracker{}
+	cluster := types.NamespacedName{Namespace: "kafka", Name: "partition-movements"}
+	defer tracker.forget(cluster)
+
+	tracker.setPartitionMovements(cluster, map[string]int32{"orders": 3, "payments": 1})
+	assert.Equal(t, 3.0, testutil.ToFloat64(ccPartitionMovementsInProgressGauge.WithLabelValues("kafka", "partition-movements", "orders")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(ccPartitionMovementsInProgressGauge.WithLabelValues("kafka", "partition-movements", "payments")))
+
+	// the topics which are not moved anymore are dropped from the metrics
+	tracker.setPartitionMovements(cluster, map[string]int32{"orders": 2})
+	assert.Equal(t, 1, testutil.CollectAndCount(ccPartitionMovementsInProgressGauge))
+	assert.Equal(t, map[string]int32{"orders": 2}, tracker.snapshot()[0].PartitionMovementsInProgress)
+
+	tracker.forget(cluster)
+	assert.Equal(t, 0, testutil.CollectAndCount(ccPartitionMovementsInProgressGauge))
+}
+
+func TestClusterBacklogHandler(t *testing.T) {
+	cluster := types.NamespacedName{Namespace: "kafka", Name: "backlog-handler"}
+	clusterBacklogs.setCCOperations(cluster, 2, 0)
+	defer clusterBacklogs.forget(cluster)
+
+	recorder := httptest.NewRecorder()
+	ClusterBacklogHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/clusters", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var backlogs []ClusterBacklog
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &backlogs))
+	assert.Contains(t, backlogs, ClusterBacklog{Namespace: "kafka", Name: "backlog-handler", PendingCCOperations: 2})
+}
+
+func TestClusterBacklogCCHealth(t *testing.T) {
+	tracker := &clusterBacklogTracker{}
+	cluster := types.NamespacedName{Namespace: "kafka", Name: "cc-health"}
+	defer tracker.forget(cluster)
+
+	tracker.setCCHealth(cluster, scale.CruiseControlStatus{
+		MonitoredWindows:          3,
+		ValidPartitionsPercentage: 95.5,
+		ExecutorState:             "INTER_BROKER_REPLICA_MOVEMENT_TASK_IN_PROGRESS",
+	})
+	assert.Equal(t, 3.0, testutil.ToFloat64(ccMonitoredWindowsGauge.WithLabelValues("kafka", "cc-health")))
+	assert.Equal(t, 95.5, testutil.ToFloat64(ccValidPartitionsGauge.WithLabelValues("kafka", "cc-health")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(ccProposalReadyGauge.WithLabelValues("kafka", "cc-health")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(ccExecutorStateGauge.WithLabelValues("kafka", "cc-health", "INTER_BROKER_REPLICA_MOVEMENT_TASK_IN_PROGRESS")))
+
+	// only the current executor state is exported
+	tracker.setCCHealth(cluster, scale.CruiseControlStatus{ProposalReady: true, ExecutorState: "NO_TASK_IN_PROGRESS"})
+	assert.Equal(t, 1, testutil.CollectAndCount(ccExecutorStateGauge))
+	assert.Equal(t, 1.0, testutil.ToFloat64(ccProposalReadyGauge.WithLabelValues("kafka", "cc-health")))
+	assert.Equal(t, &CruiseControlHealth{ProposalReady: true, ExecutorState: "NO_TASK_IN_PROGRESS"}, tracker.snapshot()[0].CruiseControlHealth)
+
+	tracker.forget(cluster)
+	assert.Equal(t, 0, testutil.CollectAndCount(ccExecutorStateGauge))
+	assert.Equal(t, 0, testutil.CollectAndCount(ccMonitoredWindowsGauge))
+}
@@ -15,6 +15,7 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/errorfactory"
 	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/scale"
 )
 
 // clusterRefLabel is the label key used for referencing KafkaUsers/KafkaTopics
@@ -62,6 +64,12 @@ func reconciled() (ctrl.Result, error) {
 	return ctrl.Result{}, nil
 }
 
+// withCCRequestTag returns a context which tags the Cruise Control requests sent with it by the UID of the object
+// and the reason of the requests to make them traceable in the Cruise Control logs and user tasks
+func withCCRequestTag(ctx context.Context, obj client.Object, reason string) context.Context {
+	return scale.WithRequestTag(ctx, scale.RequestTag{CorrelationID: string(obj.GetUID()), Reason: reason})
+}
+
 // getClusterRefNamespace returns the expected namespace for a kafka cluster
 // referenced by a user/topic CR. It takes the namespace of the CR as the first
 // argument and the reference itself as the second.
@@ -0,0 +1,122 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	ccHealthBackoffBase      = 10 * time.Second
+	ccHealthBackoffMax       = 5 * time.Minute
+	ccHealthBackoffJitter    = 0.2
+	ccHealthBackoffMaxRounds = 16
+)
+
+// ccHealth is shared by the reconciles of the CruiseControlOperation controller and the watches waking them up
+var ccHealth = &ccHealthGate{}
+
+// ccHealthGate tracks the health of Cruise Control per Kafka cluster. While Cruise Control is not ready only one
+// reconcile is allowed to probe it per backoff interval, the others are requeued to the end of the interval with
+// jitter so that the pending CruiseControlOperations do not hit Cruise Control in lockstep.
+// The zero value is ready to use.
+type ccHealthGate struct {
+	mu       sync.Mutex
+	clusters map[types.NamespacedName]*ccHealthState
+}
+
+type ccHealthState struct {
+	failures  int
+	nextProbe time.Time
+}
+
+// acquire returns true when the caller is allowed to probe the health of Cruise Control of the given Kafka cluster.
+// Otherwise it returns the time the caller has to wait before trying again.
+func (g *ccHealthGate) acquire(cluster types.NamespacedName, now time.Time) (bool, time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state, ok := g.clusters[cluster]
+	if !ok {
+		// Cruise Control is considered to be healthy until the first failed probe
+		return true, 0
+	}
+	if now.Before(state.nextProbe) {
+		return false, wait.Jitter(state.nextProbe.Sub(now), ccHealthBackoffJitter)
+	}
+	// Reserving the probe so that concurrent reconciles wait for its result
+	state.nextProbe = now.Add(ccHealthBackoff(state.failures))
+	return true, 0
+}
+
+// failed records a failed probe of Cruise Control of the given Kafka cluster and returns the time to wait before the
+// next probe.
+func (g *ccHealthGate) failed(cluster types.NamespacedName, now time.Time) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.clusters == nil {
+		g.clusters = make(map[types.NamespacedName]*ccHealthState)
+	}
+	state, ok := g.clusters[cluster]
+	if !ok {
+		state = &ccHealthState{}
+		g.clusters[cluster] = state
+	}
+	if state.failures < ccHealthBackoffMaxRounds {
+		state.failures++
+	}
+	delay := wait.Jitter(ccHealthBackoff(state.failures), ccHealthBackoffJitter)
+	state.nextProbe = now.Add(delay)
+	return delay
+}
+
+// succeeded resets the backoff of the given Kafka cluster after Cruise Control has become ready.
+func (g *ccHealthGate) succeeded(cluster types.NamespacedName) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.clusters, cluster)
+}
+
+// wake allows the next reconcile to probe Cruise Control of the given Kafka cluster right away. The number of
+// consecutive failures is kept so that the backoff continues to grow when Cruise Control is still not ready.
+func (g *ccHealthGate) wake(cluster types.NamespacedName) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if state, ok := g.clusters[cluster]; ok {
+		state.nextProbe = time.Time{}
+	}
+}
+
+// ccHealthBackoff returns the exponential backoff, without jitter, after the given number of consecutive failures
+func ccHealthBackoff(failures int) time.Duration {
+	if failures <= 1 {
+		return ccHealthBackoffBase
+	}
+	delay := ccHealthBackoffBase
+	for i := 1; i < failures && delay < ccHealthBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > ccHealthBackoffMax {
+		return ccHealthBackoffMax
+	}
+	return delay
+}
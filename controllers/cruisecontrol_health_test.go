@@ -0,0 +1,91 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCCHealthBackoff(t *testing.T) {
+	assert.Equal(t, ccHealthBackoffBase, ccHealthBackoff(0))
+	assert.Equal(t, ccHealthBackoffBase, ccHealthBackoff(1))
+	assert.Equal(t, 2*ccHealthBackoffBase, ccHealthBackoff(2))
+	assert.Equal(t, 8*ccHealthBackoffBase, ccHealthBackoff(4))
+	assert.Equal(t, ccHealthBackoffMax, ccHealthBackoff(ccHealthBackoffMaxRounds))
+}
+
+func TestCCHealthGate(t *testing.T) {
+	gate := ccHealthGate{}
+	cluster := types.NamespacedName{Namespace: "kafka", Name: "kafka"}
+	otherCluster := types.NamespacedName{Namespace: "kafka", Name: "other"}
+	now := time.Now()
+
+	// Healthy clusters are not gated
+	probe, _ := gate.acquire(cluster, now)
+	assert.True(t, probe)
+
+	delay := gate.failed(cluster, now)
+	assert.GreaterOrEqual(t, delay, ccHealthBackoffBase)
+	assert.LessOrEqual(t, delay, time.Duration(float64(ccHealthBackoffBase)*(1+ccHealthBackoffJitter)))
+
+	// Reconciles within the backoff interval are requeued to its end
+	probe, wait := gate.acquire(cluster, now.Add(time.Second))
+	assert.False(t, probe)
+	assert.GreaterOrEqual(t, wait, delay-time.Second)
+
+	// Failures are tracked per cluster
+	probe, _ = gate.acquire(otherCluster, now.Add(time.Second))
+	assert.True(t, probe)
+
+	// Only a single reconcile probes when the interval has elapsed
+	probe, _ = gate.acquire(cluster, now.Add(delay))
+	assert.True(t, probe)
+	probe, _ = gate.acquire(cluster, now.Add(delay))
+	assert.False(t, probe)
+
+	secondDelay := gate.failed(cluster, now.Add(delay))
+	assert.GreaterOrEqual(t, secondDelay, 2*ccHealthBackoffBase)
+
+	gate.succeeded(cluster)
+	probe, _ = gate.acquire(cluster, now.Add(delay))
+	assert.True(t, probe)
+}
+
+func TestCCHealthGateWake(t *testing.T) {
+	gate := ccHealthGate{}
+	cluster := types.NamespacedName{Namespace: "kafka", Name: "kafka"}
+	now := time.Now()
+
+	// Waking a healthy cluster is a no-op
+	gate.wake(cluster)
+	probe, _ := gate.acquire(cluster, now)
+	assert.True(t, probe)
+
+	gate.failed(cluster, now)
+	gate.failed(cluster, now)
+	probe, _ = gate.acquire(cluster, now)
+	assert.False(t, probe)
+
+	gate.wake(cluster)
+	probe, _ = gate.acquire(cluster, now)
+	assert.True(t, probe)
+
+	// The backoff keeps growing after waking up
+	assert.GreaterOrEqual(t, gate.failed(cluster, now), 4*ccHealthBackoffBase)
+}
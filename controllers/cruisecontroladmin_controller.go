@@ -0,0 +1,281 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaiv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+const (
+	// The runtime configuration of Cruise Control is lost when it is restarted, periodic resync makes sure
+	// that the desired state is applied again.
+	defaultCruiseControlAdminResyncIntervalInSeconds = 300
+)
+
+// CruiseControlAdminReconciler reconciles CruiseControlAdmin custom resources
+type CruiseControlAdminReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	ScaleFactory func(ctx context.Context, kafkaCluster *banzaiv1beta1.KafkaCluster) (scale.CruiseControlScaler, error)
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=cruisecontroladmins,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=cruisecontroladmins/status,verbs=get;update;patch
+
+//nolint:gocyclo
+func (r *CruiseControlAdminReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	ccAdmin := &banzaiv1alpha1.CruiseControlAdmin{}
+	if err := r.Get(ctx, request.NamespacedName, ccAdmin); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if !ccAdmin.GetDeletionTimestamp().IsZero() {
+		return reconciled()
+	}
+
+	kafkaCluster, err := k8sutil.LookupKafkaCluster(ctx, r.Client, ccAdmin.Spec.ClusterRef.Name,
+		getClusterRefNamespace(ccAdmin.GetNamespace(), ccAdmin.Spec.ClusterRef))
+	if err != nil {
+		return requeueWithError(log, "failed to lookup referenced kafka cluster", err)
+	}
+
+	scaler, err := r.ScaleFactory(ctx, kafkaCluster)
+	if err != nil {
+		return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
+	}
+	ctx = withCCRequestTag(ctx, ccAdmin, "cruisecontroladmin-reconcile")
+
+	status, err := scaler.Status(ctx)
+	if err != nil {
+		log.Error(err, "could not get Cruise Control status")
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+
+	ccStartTime, err := r.cruiseControlStartTime(ctx, kafkaCluster)
+	if err != nil {
+		return requeueWithError(log, "could not get the start time of Cruise Control", err)
+	}
+
+	config, err := desiredAdminConfig(ccAdmin, status, ccStartTime)
+	if err != nil {
+		return r.updateFailedStatus(ctx, log, ccAdmin, err)
+	}
+
+	if !config.IsEmpty() {
+		log.Info("applying runtime configuration to Cruise Control", "config", config)
+		if err = scaler.Admin(ctx, config); err != nil {
+			return r.updateFailedStatus(ctx, log, ccAdmin, errors.WrapIf(err, "could not apply runtime configuration to Cruise Control"))
+		}
+		ccAdmin.Status.LastAppliedTime = &metav1.Time{Time: time.Now()}
+		ccAdmin.Status.Concurrency = ccAdmin.Spec.Concurrency.DeepCopy()
+
+		// Reading back the state of Cruise Control to mirror the actual values into the status
+		if status, err = scaler.Status(ctx); err != nil {
+			log.Error(err, "could not get Cruise Control status")
+			return requeueAfter(defaultRequeueIntervalInSeconds)
+		}
+	}
+
+	ccAdmin.Status.ObservedGeneration = ccAdmin.GetGeneration()
+	ccAdmin.Status.State = banzaiv1alpha1.CruiseControlAdminStateApplied
+	ccAdmin.Status.ErrorMessage = ""
+	ccAdmin.Status.SelfHealingEnabled = toAnomalyTypes(status.SelfHealingEnabled)
+	ccAdmin.Status.SelfHealingDisabled = toAnomalyTypes(status.SelfHealingDisabled)
+	ccAdmin.Status.RecentlyRemovedBrokers = status.RecentlyRemovedBrokers
+	ccAdmin.Status.RecentlyDemotedBrokers = status.RecentlyDemotedBrokers
+
+	if err = r.Status().Update(ctx, ccAdmin); err != nil {
+		return requeueWithError(log, "could not update CruiseControlAdmin status", err)
+	}
+
+	return requeueAfter(defaultCruiseControlAdminResyncIntervalInSeconds)
+}
+
+func (r *CruiseControlAdminReconciler) updateFailedStatus(ctx context.Context, log logr.Logger, ccAdmin *banzaiv1alpha1.CruiseControlAdmin, err error) (ctrl.Result, error) {
+	log.Error(err, "applying CruiseControlAdmin failed")
+	ccAdmin.Status.ObservedGeneration = ccAdmin.GetGeneration()
+	ccAdmin.Status.State = banzaiv1alpha1.CruiseControlAdminStateFailed
+	ccAdmin.Status.ErrorMessage = scale.Redact(err.Error())
+	if err := r.Status().Update(ctx, ccAdmin); err != nil {
+		return requeueWithError(log, "could not update CruiseControlAdmin status", err)
+	}
+	return requeueAfter(defaultRequeueIntervalInSeconds)
+}
+
+// cruiseControlStartTime returns the time when the Cruise Control container of the Kafka cluster has been started
+// last, nil is returned when Cruise Control is not running
+func (r *CruiseControlAdminReconciler) cruiseControlStartTime(ctx context.Context, kafkaCluster *banzaiv1beta1.KafkaCluster) (*metav1.Time, error) {
+	pods := &corev1.PodList{}
+	err := r.List(ctx, pods, client.InNamespace(kafkaCluster.GetNamespace()),
+		client.MatchingLabels{banzaiv1beta1.AppLabelKey: "cruisecontrol", banzaiv1beta1.KafkaCRLabelKey: kafkaCluster.GetName()})
+	if err != nil {
+		return nil, err
+	}
+	var startTime *metav1.Time
+	for _, pod := range pods.Items {
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if running := containerStatus.State.Running; running != nil && (startTime == nil || startTime.Before(&running.StartedAt)) {
+				startTime = running.StartedAt.DeepCopy()
+			}
+		}
+	}
+	return startTime, nil
+}
+
+// desiredAdminConfig returns the changes which need to be applied to Cruise Control to reach the state described
+// by the CruiseControlAdmin. The executor concurrency cannot be read back from Cruise Control so it is applied
+// only when the CruiseControlAdmin has been changed, the previous attempt failed or Cruise Control has been
+// restarted since the configuration was applied last.
+func desiredAdminConfig(ccAdmin *banzaiv1alpha1.CruiseControlAdmin, status scale.CruiseControlStatus, ccStartTime *metav1.Time) (scale.AdminConfig, error) {
+	config := scale.AdminConfig{}
+
+	enable := anomalyTypeSet(ccAdmin.Spec.EnableSelfHealingFor)
+	disable := anomalyTypeSet(ccAdmin.Spec.DisableSelfHealingFor)
+	for anomalyType := range enable {
+		if _, ok := disable[anomalyType]; ok {
+			return config, errors.NewWithDetails("self-healing cannot be enabled and disabled at the same time", "anomalyType", anomalyType)
+		}
+	}
+
+	specChanged := ccAdmin.Status.ObservedGeneration != ccAdmin.GetGeneration() ||
+		ccAdmin.Status.State != banzaiv1alpha1.CruiseControlAdminStateApplied
+
+	ccRestarted := ccStartTime != nil &&
+		(ccAdmin.Status.LastAppliedTime == nil || ccAdmin.Status.LastAppliedTime.Before(ccStartTime))
+
+	if specChanged || ccRestarted {
+		config = executorConcurrencyAdminConfig(ccAdmin.Spec.Concurrency)
+	}
+
+	// Self-healing state is compared with the actual state of Cruise Control to heal the drift
+	if specChanged || !isSubset(enable, status.SelfHealingEnabled) || !isSubset(disable, status.SelfHealingDisabled) {
+		config.EnableSelfHealingFor = anomalyTypesToStrings(ccAdmin.Spec.EnableSelfHealingFor)
+		config.DisableSelfHealingFor = anomalyTypesToStrings(ccAdmin.Spec.DisableSelfHealingFor)
+	}
+
+	config.DropRecentlyRemovedBrokers = intersectBrokerIDs(ccAdmin.Spec.DropRecentlyRemovedBrokers, status.RecentlyRemovedBrokers)
+	config.DropRecentlyDemotedBrokers = intersectBrokerIDs(ccAdmin.Spec.DropRecentlyDemotedBrokers, status.RecentlyDemotedBrokers)
+
+	return config, nil
+}
+
+// executorConcurrencyAdminConfig returns the AdminConfig which sets the given executor concurrency in Cruise Control
+func executorConcurrencyAdminConfig(concurrency *banzaiv1alpha1.CruiseControlExecutorConcurrency) scale.AdminConfig {
+	config := scale.AdminConfig{}
+	if concurrency == nil {
+		return config
+	}
+	if concurrency.InterBrokerPartitionMovements != nil {
+		config.ConcurrentPartitionMovementsPerBroker = *concurrency.InterBrokerPartitionMovements
+	}
+	if concurrency.IntraBrokerPartitionMovements != nil {
+		config.ConcurrentIntraBrokerPartitionMovements = *concurrency.IntraBrokerPartitionMovements
+	}
+	if concurrency.LeadershipMovements != nil {
+		config.ConcurrentLeaderMovements = *concurrency.LeadershipMovements
+	}
+	return config
+}
+
+func anomalyTypeSet(anomalyTypes []banzaiv1alpha1.CruiseControlAnomalyType) map[string]struct{} {
+	set := make(map[string]struct{}, len(anomalyTypes))
+	for _, anomalyType := range anomalyTypes {
+		set[string(anomalyType)] = struct{}{}
+	}
+	return set
+}
+
+func isSubset(set map[string]struct{}, values []string) bool {
+	valueSet := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		valueSet[value] = struct{}{}
+	}
+	for key := range set {
+		if _, ok := valueSet[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func intersectBrokerIDs(desired, actual []int32) []int32 {
+	var ret []int32
+	for _, desiredID := range desired {
+		for _, actualID := range actual {
+			if desiredID == actualID {
+				ret = append(ret, desiredID)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+func anomalyTypesToStrings(anomalyTypes []banzaiv1alpha1.CruiseControlAnomalyType) []string {
+	if len(anomalyTypes) == 0 {
+		return nil
+	}
+	ret := make([]string, 0, len(anomalyTypes))
+	for _, anomalyType := range anomalyTypes {
+		ret = append(ret, string(anomalyType))
+	}
+	return ret
+}
+
+func toAnomalyTypes(anomalyTypes []string) []banzaiv1alpha1.CruiseControlAnomalyType {
+	if len(anomalyTypes) == 0 {
+		return nil
+	}
+	ret := make([]banzaiv1alpha1.CruiseControlAnomalyType, 0, len(anomalyTypes))
+	for _, anomalyType := range anomalyTypes {
+		ret = append(ret, banzaiv1alpha1.CruiseControlAnomalyType(anomalyType))
+	}
+	return ret
+}
+
+// SetupCruiseControlAdminWithManager registers CruiseControlAdmin controller to the manager
+func SetupCruiseControlAdminWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&banzaiv1alpha1.CruiseControlAdmin{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
+		Named("CruiseControlAdmin")
+}
+
+// blank assignment to verify that CruiseControlAdminReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &CruiseControlAdminReconciler{}
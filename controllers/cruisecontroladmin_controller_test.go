@@ -0,0 +1,161 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestDesiredAdminConfig(t *testing.T) {
+	spec := v1alpha1.CruiseControlAdminSpec{
+		Concurrency: &v1alpha1.CruiseControlExecutorConcurrency{
+			InterBrokerPartitionMovements: util.Int32Pointer(10),
+			LeadershipMovements:           util.Int32Pointer(500),
+		},
+		EnableSelfHealingFor:       []v1alpha1.CruiseControlAnomalyType{"BROKER_FAILURE"},
+		DisableSelfHealingFor:      []v1alpha1.CruiseControlAnomalyType{"GOAL_VIOLATION"},
+		DropRecentlyRemovedBrokers: []int32{1, 2},
+	}
+
+	applied := v1.NewTime(time.Now().Add(-time.Hour))
+	restarted := v1.NewTime(time.Now())
+
+	testCases := []struct {
+		testName       string
+		generation     int64
+		status         v1alpha1.CruiseControlAdminStatus
+		ccStatus       scale.CruiseControlStatus
+		ccStartTime    *v1.Time
+		spec           v1alpha1.CruiseControlAdminSpec
+		expectedConfig scale.AdminConfig
+		wantErr        bool
+	}{
+		{
+			testName:   "new spec is applied",
+			generation: 1,
+			spec:       spec,
+			ccStatus: scale.CruiseControlStatus{
+				RecentlyRemovedBrokers: []int32{2, 3},
+			},
+			expectedConfig: scale.AdminConfig{
+				ConcurrentPartitionMovementsPerBroker: 10,
+				ConcurrentLeaderMovements:             500,
+				EnableSelfHealingFor:                  []string{"BROKER_FAILURE"},
+				DisableSelfHealingFor:                 []string{"GOAL_VIOLATION"},
+				DropRecentlyRemovedBrokers:            []int32{2},
+			},
+		},
+		{
+			testName:   "applied spec without drift",
+			generation: 1,
+			spec:       spec,
+			status: v1alpha1.CruiseControlAdminStatus{
+				ObservedGeneration: 1,
+				State:              v1alpha1.CruiseControlAdminStateApplied,
+			},
+			ccStatus: scale.CruiseControlStatus{
+				SelfHealingEnabled:  []string{"BROKER_FAILURE", "DISK_FAILURE"},
+				SelfHealingDisabled: []string{"GOAL_VIOLATION"},
+			},
+			expectedConfig: scale.AdminConfig{},
+		},
+		{
+			testName:   "applied spec is not repeated while Cruise Control is running",
+			generation: 1,
+			spec:       spec,
+			status: v1alpha1.CruiseControlAdminStatus{
+				ObservedGeneration: 1,
+				State:              v1alpha1.CruiseControlAdminStateApplied,
+				LastAppliedTime:    &restarted,
+			},
+			ccStatus: scale.CruiseControlStatus{
+				SelfHealingEnabled:  []string{"BROKER_FAILURE"},
+				SelfHealingDisabled: []string{"GOAL_VIOLATION"},
+			},
+			ccStartTime:    &applied,
+			expectedConfig: scale.AdminConfig{},
+		},
+		{
+			testName:   "concurrency is applied again after Cruise Control restart",
+			generation: 1,
+			spec:       spec,
+			status: v1alpha1.CruiseControlAdminStatus{
+				ObservedGeneration: 1,
+				State:              v1alpha1.CruiseControlAdminStateApplied,
+				LastAppliedTime:    &applied,
+			},
+			ccStatus: scale.CruiseControlStatus{
+				SelfHealingEnabled:  []string{"BROKER_FAILURE"},
+				SelfHealingDisabled: []string{"GOAL_VIOLATION"},
+			},
+			ccStartTime: &restarted,
+			expectedConfig: scale.AdminConfig{
+				ConcurrentPartitionMovementsPerBroker: 10,
+				ConcurrentLeaderMovements:             500,
+			},
+		},
+		{
+			testName:   "self-healing drift is healed",
+			generation: 1,
+			spec:       spec,
+			status: v1alpha1.CruiseControlAdminStatus{
+				ObservedGeneration: 1,
+				State:              v1alpha1.CruiseControlAdminStateApplied,
+			},
+			ccStatus: scale.CruiseControlStatus{
+				SelfHealingDisabled: []string{"BROKER_FAILURE", "GOAL_VIOLATION"},
+			},
+			expectedConfig: scale.AdminConfig{
+				EnableSelfHealingFor:  []string{"BROKER_FAILURE"},
+				DisableSelfHealingFor: []string{"GOAL_VIOLATION"},
+			},
+		},
+		{
+			testName:   "conflicting self-healing configuration",
+			generation: 1,
+			spec: v1alpha1.CruiseControlAdminSpec{
+				EnableSelfHealingFor:  []v1alpha1.CruiseControlAnomalyType{"BROKER_FAILURE"},
+				DisableSelfHealingFor: []v1alpha1.CruiseControlAnomalyType{"BROKER_FAILURE"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.testName, func(t *testing.T) {
+			ccAdmin := &v1alpha1.CruiseControlAdmin{
+				ObjectMeta: v1.ObjectMeta{Generation: testCase.generation},
+				Spec:       testCase.spec,
+				Status:     testCase.status,
+			}
+			config, err := desiredAdminConfig(ccAdmin, testCase.ccStatus, testCase.ccStartTime)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedConfig, config)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+const ccOperationFingerprintPrefix = "koperator-"
+
+// operatorStartTime is used to decide whether a Cruise Control task could have been submitted by a previous instance
+// of the operator for a CruiseControlOperation
+var operatorStartTime = time.Now()
+
+// ccOperationAttempt returns the number of the upcoming execution of the CruiseControlOperation which is equal to
+// the retry count stored when the result of the execution is persisted
+func ccOperationAttempt(operation *banzaiv1alpha1.CruiseControlOperation) int {
+	if operation.CurrentTaskID() != "" {
+		return operation.Status.RetryCount + 1
+	}
+	return operation.Status.RetryCount
+}
+
+// ccOperationFingerprint returns the deterministic tag of the upcoming execution of the CruiseControlOperation which is
+// sent to Cruise Control in the reason of the request to be able to find the submitted task after an operator restart
+func ccOperationFingerprint(operation *banzaiv1alpha1.CruiseControlOperation) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s/%s/%d", operation.GetNamespace(), operation.GetName(),
+		operation.GetUID(), operation.CurrentTaskOperation(), ccOperationAttempt(operation))))
+	return ccOperationFingerprintPrefix + hex.EncodeToString(hash[:8])
+}
+
+// withFingerprint returns a copy of the parameters of the Cruise Control request where the fingerprint is added to the reason
+func withFingerprint(params map[string]string, fingerprint string) map[string]string {
+	ret := make(map[string]string, len(params)+1)
+	for param, value := range params {
+		ret[param] = value
+	}
+	if reason := ret[banzaiv1alpha1.ParamReason]; reason != "" {
+		ret[banzaiv1alpha1.ParamReason] = fmt.Sprintf("%s [%s]", reason, fingerprint)
+	} else {
+		ret[banzaiv1alpha1.ParamReason] = fingerprint
+	}
+	return ret
+}
+
+// requestFingerprintMatches returns true when the reason of the Cruise Control user task request contains the fingerprint
+func requestFingerprintMatches(requestURL, fingerprint string) bool {
+	// The request URL of the user task may be prefixed with the HTTP method
+	idx := strings.Index(requestURL, "?")
+	if idx < 0 {
+		return false
+	}
+	query, err := url.ParseQuery(requestURL[idx+1:])
+	if err != nil {
+		return false
+	}
+	return strings.Contains(query.Get(banzaiv1alpha1.ParamReason), fingerprint)
+}
+
+// recordExecutionIntent records the intent of the upcoming execution of the CruiseControlOperation in its status
+// before the task is submitted to Cruise Control. When leader election is enabled the lease is checked again before
+// recording the intent. Recording it fails with conflict when another operator replica has updated the
+// CruiseControlOperation since it has been read, so only one of the replicas racing during a leadership change can
+// submit the task.
+func (r *CruiseControlOperationReconciler) recordExecutionIntent(ctx context.Context, operation *banzaiv1alpha1.CruiseControlOperation) error {
+	if err := r.LeaseFence.Check(ctx, time.Now()); err != nil {
+		return err
+	}
+	operation.Status.ExecutionIntent = &banzaiv1alpha1.CruiseControlExecutionIntent{
+		OperationUID: operation.GetUID(),
+		Attempt:      ccOperationAttempt(operation),
+		SubmittedBy:  r.LeaseFence.identity(),
+		Recorded:     metav1.Now(),
+	}
+	return errors.WrapIfWithDetails(r.Status().Update(ctx, operation), "could not record the execution intent of the Cruise Control task",
+		"name", operation.GetName(), "namespace", operation.GetNamespace())
+}
+
+// hasExecutionIntent returns true when the intent of the upcoming execution of the CruiseControlOperation has been
+// recorded, i.e. its task may have been submitted without saving the result of the submission
+func hasExecutionIntent(operation *banzaiv1alpha1.CruiseControlOperation) bool {
+	intent := operation.Status.ExecutionIntent
+	return intent != nil && intent.OperationUID == operation.GetUID() && intent.Attempt == ccOperationAttempt(operation)
+}
+
+// isAdoptionCheckNeeded returns true when the upcoming execution of the CruiseControlOperation could have been submitted
+// to Cruise Control without persisting its result, either according to its execution intent or by a previous instance
+// of the operator
+func isAdoptionCheckNeeded(operation *banzaiv1alpha1.CruiseControlOperation, startTime time.Time) bool {
+	if operation.CurrentTaskOperation() == banzaiv1alpha1.OperationStopExecution {
+		return false
+	}
+	if hasExecutionIntent(operation) {
+		return true
+	}
+	eligibleSince := operation.GetCreationTimestamp().Time
+	if finished := operation.CurrentTaskFinished(); finished != nil {
+		eligibleSince = finished.Time
+	}
+	return eligibleSince.Before(startTime)
+}
+
+// findSubmittedTask looks up the Cruise Control user task which has already been submitted for the upcoming execution
+// of the CruiseControlOperation. It returns nil when there is no such task.
+func (r *CruiseControlOperationReconciler) findSubmittedTask(ctx context.Context, operation *banzaiv1alpha1.CruiseControlOperation) (*scale.Result, error) {
+	if !isAdoptionCheckNeeded(operation, operatorStartTime) {
+		return nil, nil
+	}
+	tasks, err := r.scaler.UserTasks(ctx)
+	if err != nil {
+		return nil, errors.WrapIff(err, "could not get user tasks from Cruise Control API")
+	}
+	fingerprint := ccOperationFingerprint(operation)
+	for _, task := range tasks {
+		if task == nil || task.TaskID == "" || task.TaskID == operation.CurrentTaskID() {
+			continue
+		}
+		if requestFingerprintMatches(task.RequestURL, fingerprint) {
+			adopted := *task
+			if adopted.ResponseStatusCode == 0 {
+				adopted.ResponseStatusCode = http.StatusOK
+			}
+			return &adopted, nil
+		}
+	}
+	return nil, nil
+}
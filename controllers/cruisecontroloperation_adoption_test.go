@@ -0,0 +1,212 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+	"github.com/banzaicloud/koperator/pkg/testing/mocks"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func newAdoptionTestOperation(created time.Time) *v1alpha1.CruiseControlOperation {
+	return &v1alpha1.CruiseControlOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "rebalance",
+			Namespace:         "kafka",
+			UID:               "4f0b7c3e-1d2a-4c5b-9e8f-7a6b5c4d3e2f",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: v1alpha1.CruiseControlOperationStatus{
+			CurrentTask: &v1alpha1.CruiseControlTask{
+				Operation: v1alpha1.OperationRebalance,
+				Parameters: map[string]string{
+					v1alpha1.ParamExcludeDemoted: "true",
+				},
+			},
+		},
+	}
+}
+
+func requestURLWithReason(reason string) string {
+	return "POST /kafkacruisecontrol/rebalance?" + url.Values{
+		v1alpha1.ParamReason:         []string{reason},
+		v1alpha1.ParamExcludeDemoted: []string{"true"},
+	}.Encode()
+}
+
+func TestCCOperationFingerprint(t *testing.T) {
+	operation := newAdoptionTestOperation(time.Now())
+	fingerprint := ccOperationFingerprint(operation)
+
+	assert.Equal(t, fingerprint, ccOperationFingerprint(operation.DeepCopy()))
+	assert.Regexp(t, "^koperator-[0-9a-f]{16}$", fingerprint)
+
+	failed := operation.DeepCopy()
+	failed.Status.CurrentTask.ID = "failed-task"
+	failed.Status.CurrentTask.State = v1beta1.CruiseControlTaskCompletedWithError
+	assert.NotEqual(t, fingerprint, ccOperationFingerprint(failed), "retry of a failed task must have a new fingerprint")
+
+	retried := operation.DeepCopy()
+	retried.Status.RetryCount = 1
+	assert.Equal(t, ccOperationFingerprint(failed), ccOperationFingerprint(retried))
+
+	other := operation.DeepCopy()
+	other.UID = "0c0b7c3e-1d2a-4c5b-9e8f-7a6b5c4d3e2f"
+	assert.NotEqual(t, fingerprint, ccOperationFingerprint(other))
+}
+
+func TestWithFingerprint(t *testing.T) {
+	params := map[string]string{v1alpha1.ParamExcludeDemoted: "true"}
+	withReason := withFingerprint(params, "koperator-0123456789abcdef")
+	assert.Equal(t, map[string]string{
+		v1alpha1.ParamExcludeDemoted: "true",
+		v1alpha1.ParamReason:         "koperator-0123456789abcdef",
+	}, withReason)
+	assert.NotContains(t, params, v1alpha1.ParamReason, "parameters of the operation must not be changed")
+
+	params[v1alpha1.ParamReason] = "scheduled rebalance"
+	assert.Equal(t, "scheduled rebalance [koperator-0123456789abcdef]",
+		withFingerprint(params, "koperator-0123456789abcdef")[v1alpha1.ParamReason])
+}
+
+func TestRequestFingerprintMatches(t *testing.T) {
+	fingerprint := "koperator-0123456789abcdef"
+	assert.True(t, requestFingerprintMatches(requestURLWithReason(fingerprint), fingerprint))
+	assert.True(t, requestFingerprintMatches(requestURLWithReason("scheduled rebalance ["+fingerprint+"]"), fingerprint))
+	assert.False(t, requestFingerprintMatches(requestURLWithReason("koperator-fedcba9876543210"), fingerprint))
+	assert.False(t, requestFingerprintMatches("POST /kafkacruisecontrol/rebalance", fingerprint))
+	assert.False(t, requestFingerprintMatches("", fingerprint))
+}
+
+func TestIsAdoptionCheckNeeded(t *testing.T) {
+	startTime := time.Now()
+	before, after := startTime.Add(-time.Minute), startTime.Add(time.Minute)
+
+	assert.True(t, isAdoptionCheckNeeded(newAdoptionTestOperation(before), startTime))
+	assert.False(t, isAdoptionCheckNeeded(newAdoptionTestOperation(after), startTime))
+
+	finishedAfterStart := newAdoptionTestOperation(before)
+	finished := metav1.NewTime(after)
+	finishedAfterStart.Status.CurrentTask.Finished = &finished
+	assert.False(t, isAdoptionCheckNeeded(finishedAfterStart, startTime))
+
+	stop := newAdoptionTestOperation(before)
+	stop.Status.CurrentTask.Operation = v1alpha1.OperationStopExecution
+	assert.False(t, isAdoptionCheckNeeded(stop, startTime))
+
+	// The recorded intent of the upcoming execution requires the check regardless of the start of the operator
+	withIntent := newAdoptionTestOperation(after)
+	withIntent.Status.ExecutionIntent = &v1alpha1.CruiseControlExecutionIntent{OperationUID: withIntent.GetUID(), Attempt: 0}
+	assert.True(t, isAdoptionCheckNeeded(withIntent, startTime))
+
+	// The intent of an earlier execution or of another object with the same name is ignored
+	withIntent.Status.ExecutionIntent.Attempt = 1
+	assert.False(t, isAdoptionCheckNeeded(withIntent, startTime))
+	withIntent.Status.ExecutionIntent = &v1alpha1.CruiseControlExecutionIntent{OperationUID: "other-uid", Attempt: 0}
+	assert.False(t, isAdoptionCheckNeeded(withIntent, startTime))
+}
+
+func TestFindSubmittedTask(t *testing.T) {
+	operation := newAdoptionTestOperation(operatorStartTime.Add(-time.Minute))
+	fingerprint := ccOperationFingerprint(operation)
+
+	mockCtrl := gomock.NewController(t)
+	scaler := mocks.NewMockCruiseControlScaler(mockCtrl)
+	scaler.EXPECT().UserTasks(gomock.Any()).Return([]*scale.Result{
+		{TaskID: "other", RequestURL: requestURLWithReason("koperator-fedcba9876543210"), State: v1beta1.CruiseControlTaskCompleted},
+		{TaskID: "submitted", RequestURL: requestURLWithReason(fingerprint), State: v1beta1.CruiseControlTaskInExecution},
+	}, nil)
+
+	r := &CruiseControlOperationReconciler{scaler: scaler}
+	task, err := r.findSubmittedTask(context.Background(), operation)
+	assert.NoError(t, err)
+	if assert.NotNil(t, task) {
+		assert.Equal(t, "submitted", task.TaskID)
+		assert.Equal(t, http.StatusOK, task.ResponseStatusCode)
+	}
+
+	// Operations created after the operator has started are executed without looking up the user tasks
+	task, err = r.findSubmittedTask(context.Background(), newAdoptionTestOperation(time.Now().Add(time.Minute)))
+	assert.NoError(t, err)
+	assert.Nil(t, task)
+}
+
+func TestRecordExecutionIntent(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, coordinationv1.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	now := time.Now()
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kafka", Name: "koperator"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       util.StringPointer("operator-0_6f1c"),
+			LeaseDurationSeconds: util.Int32Pointer(15),
+			RenewTime:            &metav1.MicroTime{Time: now},
+		},
+	}
+	operation := &v1alpha1.CruiseControlOperation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kafka", Name: "remove-broker", UID: "remove-broker-uid", Labels: map[string]string{v1beta1.KafkaCRLabelKey: "kafka"}},
+		Status: v1alpha1.CruiseControlOperationStatus{
+			CurrentTask: &v1alpha1.CruiseControlTask{Operation: v1alpha1.OperationRemoveBroker},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(lease, operation).Build()
+
+	leader := &CruiseControlOperationReconciler{Client: c, LeaseFence: &LeaseFence{
+		Reader: c, Lease: types.NamespacedName{Namespace: "kafka", Name: "koperator"}, Identity: "operator-0",
+	}}
+	formerLeader := &CruiseControlOperationReconciler{Client: c, LeaseFence: &LeaseFence{
+		Reader: c, Lease: types.NamespacedName{Namespace: "kafka", Name: "koperator"}, Identity: "operator-1",
+	}}
+
+	// both replicas have read the same version of the CruiseControlOperation
+	current := &v1alpha1.CruiseControlOperation{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "remove-broker"}, current))
+	stale := current.DeepCopy()
+
+	assert.Error(t, formerLeader.recordExecutionIntent(ctx, stale.DeepCopy()))
+	require.NoError(t, leader.recordExecutionIntent(ctx, current))
+	intent := current.Status.ExecutionIntent
+	if assert.NotNil(t, intent) {
+		assert.Equal(t, "operator-0", intent.SubmittedBy)
+		assert.Equal(t, current.GetUID(), intent.OperationUID)
+		assert.Equal(t, 0, intent.Attempt)
+	}
+	assert.True(t, hasExecutionIntent(current))
+
+	// the intent of a replica which has read the CruiseControlOperation before the intent of the leader conflicts,
+	// even if it still believes to hold the lease
+	formerLeader.LeaseFence.Identity = "operator-0"
+	assert.Error(t, formerLeader.recordExecutionIntent(ctx, stale))
+}
@@ -19,14 +19,18 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"time"
 
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlBuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -36,6 +40,8 @@ import (
 
 	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
 	banzaiv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
 	"github.com/banzaicloud/koperator/pkg/scale"
 	"github.com/banzaicloud/koperator/pkg/util"
 )
@@ -52,9 +58,12 @@ const (
 var (
 	defaultRequeueIntervalInSeconds = 10
 	executionPriorityMap            = map[banzaiv1alpha1.CruiseControlTaskOperation]int{
-		banzaiv1alpha1.OperationAddBroker:    2,
-		banzaiv1alpha1.OperationRemoveBroker: 1,
-		banzaiv1alpha1.OperationRebalance:    0,
+		banzaiv1alpha1.OperationAddBroker:               2,
+		banzaiv1alpha1.OperationFixOfflineReplicas:      2,
+		banzaiv1alpha1.OperationRemoveBroker:            1,
+		banzaiv1alpha1.OperationDemoteBroker:            1,
+		banzaiv1alpha1.OperationRebalance:               0,
+		banzaiv1alpha1.OperationPreferredLeaderElection: 0,
 	}
 	missingCCResErr = errors.New("missing Cruise Control user task result")
 )
@@ -66,11 +75,26 @@ type CruiseControlOperationReconciler struct {
 	Scheme       *runtime.Scheme
 	scaler       scale.CruiseControlScaler
 	ScaleFactory func(ctx context.Context, kafkaCluster *banzaiv1beta1.KafkaCluster) (scale.CruiseControlScaler, error)
+	// Recorder is used for emitting the events of the CruiseControlOperations, events are not emitted when it is nil
+	Recorder record.EventRecorder
+	// FinalizerPolicy defines whether the finalizer is added to the CruiseControlOperations and when it is released
+	// while the Cruise Control task may still be running
+	FinalizerPolicy FinalizerPolicy
+	// LeaseFence guards the submission of the Cruise Control tasks when leader election is enabled
+	LeaseFence *LeaseFence
+	// FailedTasksHistory is the operator default of the failedTasks history limits which can be overridden
+	// by the Kafka clusters and the CruiseControlOperations
+	FailedTasksHistory banzaiv1beta1.FailedTasksHistoryLimits
+	// KafkaClientProvider is used for checking the in-sync replicas of the partitions before removing brokers,
+	// the check is disabled when it is nil
+	KafkaClientProvider kafkaclient.Provider
 }
 
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=cruisecontroloperations,verbs=get;list;watch;create;update;patch;delete;deletecollection
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=cruisecontroloperations/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=cruisecontroloperations/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 //nolint:gocyclo
 func (r *CruiseControlOperationReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
@@ -97,6 +121,16 @@ func (r *CruiseControlOperationReconciler) Reconcile(ctx context.Context, reques
 		return reconciled()
 	}
 
+	if r.FinalizerPolicy.forceRelease(currentCCOperation, time.Now()) && controllerutil.ContainsFinalizer(currentCCOperation, ccOperationFinalizerGroup) {
+		log.Info("releasing the finalizer of the CruiseControlOperation without waiting for the Cruise Control task",
+			"name", currentCCOperation.GetName(), "namespace", currentCCOperation.GetNamespace())
+		controllerutil.RemoveFinalizer(currentCCOperation, ccOperationFinalizerGroup)
+		if err := r.Update(ctx, currentCCOperation); err != nil {
+			return requeueWithError(log, "failed to remove finalizer from CruiseControlOperation", err)
+		}
+		return reconciled()
+	}
+
 	// When the task is done we can remove the finalizer instantly thus we can return fast here.
 	if isFinalizerNeeded(currentCCOperation) && currentCCOperation.IsDone() {
 		controllerutil.RemoveFinalizer(currentCCOperation, ccOperationFinalizerGroup)
@@ -121,6 +155,7 @@ func (r *CruiseControlOperationReconciler) Reconcile(ctx context.Context, reques
 	err = r.Get(ctx, kafkaClusterRef, kafkaCluster)
 	if err != nil {
 		if apiErrors.IsNotFound(err) {
+			clusterBacklogs.forget(kafkaClusterRef)
 			if !currentCCOperation.ObjectMeta.DeletionTimestamp.IsZero() {
 				log.Info("kafka cluster has been removed, now removing finalizer from CruiseControlOperation")
 				controllerutil.RemoveFinalizer(currentCCOperation, ccOperationFinalizerGroup)
@@ -138,22 +173,43 @@ func (r *CruiseControlOperationReconciler) Reconcile(ctx context.Context, reques
 		return requeueWithError(log, "failed to add finalizer to CruiseControlOperation", err)
 	}
 
+	pending, inProgress := ccOperationBacklog(ccOperationListClusterWide.Items, kafkaClusterRef)
+	clusterBacklogs.setCCOperations(kafkaClusterRef, pending, inProgress)
+
+	// Another reconcile is already probing the health of Cruise Control which has not been ready recently
+	if probe, wait := ccHealth.acquire(kafkaClusterRef, time.Now()); !probe {
+		log.V(1).Info("requeue event as Cruise Control health is being checked by another reconcile", "requeueAfter", wait)
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
 	r.scaler, err = r.ScaleFactory(ctx, kafkaCluster)
 	if err != nil {
 		return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
 	}
+	// Requests which are not sent for a specific CruiseControlOperation are correlated with the Kafka cluster
+	ctx = withCCRequestTag(ctx, kafkaCluster, "cruisecontroloperation-reconcile")
 
 	// Checking Cruise Control health
 	status, err := r.scaler.Status(ctx)
 	if err != nil {
-		log.Error(err, "could not get Cruise Control status")
-		return requeueAfter(defaultRequeueIntervalInSeconds)
+		clusterBacklogs.setCCContact(kafkaClusterRef, false, time.Now())
+		wait := ccHealth.failed(kafkaClusterRef, time.Now())
+		log.Error(err, "could not get Cruise Control status", "requeueAfter", wait)
+		return ctrl.Result{RequeueAfter: wait}, nil
 	}
+	// The health of Cruise Control is exported even when it is not ready to explain why the operations are queued
+	clusterBacklogs.setCCHealth(kafkaClusterRef, status)
 
-	if !status.IsReady() {
-		log.Info("requeue event as Cruise Control is not ready (yet)", "status", status)
-		return requeueAfter(defaultRequeueIntervalInSeconds)
+	// The operations which need the model of Cruise Control are held back separately until the Analyzer is ready
+	if !status.MonitorReady {
+		clusterBacklogs.setCCContact(kafkaClusterRef, false, time.Now())
+		wait := ccHealth.failed(kafkaClusterRef, time.Now())
+		log.Info("requeue event as the Monitor of Cruise Control is not ready (yet)", "status", status, "requeueAfter", wait)
+		return ctrl.Result{RequeueAfter: wait}, nil
 	}
+	ccHealth.succeeded(kafkaClusterRef)
+	clusterBacklogs.setCCContact(kafkaClusterRef, true, time.Now())
+	clusterBacklogs.setPartitionMovements(kafkaClusterRef, inProgressTopics(status.PartitionMovements.InProgress))
 
 	// Filtering out CruiseControlOperation by kafka cluster ref and state
 	var ccOperationsKafkaClusterFiltered []*banzaiv1alpha1.CruiseControlOperation
@@ -172,12 +228,30 @@ func (r *CruiseControlOperationReconciler) Reconcile(ctx context.Context, reques
 	}
 
 	// Update currentTask states from Cruise Control
-	err = r.updateCurrentTasks(ctx, ccOperationsKafkaClusterFiltered)
+	err = r.updateCurrentTasks(ctx, ccOperationsKafkaClusterFiltered, status)
 	if err != nil {
 		log.Error(err, "requeue event as updating state of currentTask(s) failed")
 		return requeueAfter(defaultRequeueIntervalInSeconds)
 	}
 
+	// Failing to update the summary must not prevent the execution of the operations
+	if err = r.updateCCOperationSummary(ctx, kafkaCluster, ccOperationListClusterWide.Items); err != nil {
+		log.Error(err, "could not update the summary of CruiseControlOperations in the Kafka cluster status")
+	}
+
+	// Holding back or completing operations which are duplicates of an earlier operation
+	ccOperationsKafkaClusterFiltered, err = r.coalesceDuplicateOperations(ctx, ccOperationListClusterWide.Items, ccOperationsKafkaClusterFiltered)
+	if err != nil {
+		log.Error(err, "requeue event as coalescing duplicated CruiseControlOperations failed")
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+
+	// Raising or lowering the executor concurrency of Cruise Control for operations with concurrency boost
+	if err = r.adjustConcurrency(ctx, ccOperationListClusterWide.Items, kafkaClusterRef); err != nil {
+		log.Error(err, "requeue event as adjusting Cruise Control executor concurrency failed")
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+
 	// When the task is not in execution we can remove the finalizer
 	if isFinalizerNeeded(currentCCOperation) && !currentCCOperation.IsCurrentTaskRunning() {
 		controllerutil.RemoveFinalizer(currentCCOperation, ccOperationFinalizerGroup)
@@ -190,9 +264,58 @@ func (r *CruiseControlOperationReconciler) Reconcile(ctx context.Context, reques
 	// Sorting operations into categories which are sorted by priority
 	ccOperationQueueMap := sortOperations(ccOperationsKafkaClusterFiltered)
 
+	// Interleaving the operations of the scheduling groups
+	lastServed := schedulingGroupsLastServed(ccOperationListClusterWide.Items, kafkaClusterRef)
+	ccOperationQueueMap[ccOperationFirstExecution] = interleaveSchedulingGroups(ccOperationQueueMap[ccOperationFirstExecution], lastServed)
+	ccOperationQueueMap[ccOperationRetryExecution] = interleaveSchedulingGroups(ccOperationQueueMap[ccOperationRetryExecution], lastServed)
+
+	// Holding back operations which exceed the quotas of the tenants
+	var heldBackByQuota bool
+	ccOperationQueueMap[ccOperationFirstExecution], heldBackByQuota, err = r.applyQuotas(ctx, kafkaCluster, ccOperationQueueMap[ccOperationFirstExecution], ccOperationListClusterWide.Items)
+	if err != nil {
+		log.Error(err, "requeue event as applying CruiseControlOperation quotas failed")
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+
+	// New tasks are not started while the Kafka cluster is being deleted, only the running ones can be stopped
+	if !kafkaCluster.GetDeletionTimestamp().IsZero() {
+		ccOperationQueueMap[ccOperationFirstExecution] = nil
+		ccOperationQueueMap[ccOperationRetryExecution] = nil
+		heldBackByQuota = false
+	}
+
+	// Holding back operations which need proposals while the model of Cruise Control is not ready
+	var heldBackForModel bool
+	for _, queue := range []string{ccOperationFirstExecution, ccOperationRetryExecution} {
+		var heldBack bool
+		ccOperationQueueMap[queue], heldBack, err = r.holdBackForCCModel(ctx, ccOperationQueueMap[queue], status)
+		if err != nil {
+			log.Error(err, "requeue event as holding back CruiseControlOperations for the Cruise Control model failed")
+			return requeueAfter(defaultRequeueIntervalInSeconds)
+		}
+		heldBackForModel = heldBackForModel || heldBack
+	}
+
+	// Holding back the broker removals which would push partitions below their min.insync.replicas
+	var heldBackForMinISR bool
+	for _, queue := range []string{ccOperationFirstExecution, ccOperationRetryExecution} {
+		var heldBack bool
+		ccOperationQueueMap[queue], heldBack, err = r.holdBackForMinInSyncReplicas(ctx, ccOperationQueueMap[queue], kafkaCluster)
+		if err != nil {
+			log.Error(err, "requeue event as checking the min.insync.replicas of the partitions failed")
+			return requeueAfter(defaultRequeueIntervalInSeconds)
+		}
+		heldBackForMinISR = heldBackForMinISR || heldBack
+	}
+
 	// When there is no more job present in the cluster we reconciled.
 	if len(ccOperationQueueMap[ccOperationForStopExecution]) == 0 && len(ccOperationQueueMap[ccOperationFirstExecution]) == 0 &&
 		len(ccOperationQueueMap[ccOperationRetryExecution]) == 0 && len(ccOperationQueueMap[ccOperationInProgress]) == 0 {
+		// Operations held back by quota, for the model or for min.insync.replicas need to be checked again when
+		// their execution is allowed
+		if heldBackByQuota || heldBackForModel || heldBackForMinISR {
+			return requeueAfter(defaultRequeueIntervalInSeconds)
+		}
 		log.Info("there is no more operation for execution")
 		return reconciled()
 	}
@@ -203,28 +326,89 @@ func (r *CruiseControlOperationReconciler) Reconcile(ctx context.Context, reques
 		return requeueAfter(defaultRequeueIntervalInSeconds)
 	}
 
+	// The operations of the Kafka clusters sharing the same Cruise Control are not executed at the same time
+	if ccOperationExecution.CurrentTaskOperation() != banzaiv1alpha1.OperationStopExecution {
+		sharedClusters, err := r.sharedCruiseControlClusters(ctx, kafkaCluster)
+		if err != nil {
+			log.Error(err, "could not look up the Kafka clusters sharing Cruise Control")
+			return requeueAfter(defaultRequeueIntervalInSeconds)
+		}
+		if other := sharedCruiseControlInProgress(ccOperationListClusterWide.Items, sharedClusters, kafkaClusterRef); other != nil {
+			log.Info("requeue event as the shared Cruise Control is executing the operation of another Kafka cluster",
+				"name", other.GetName(), "namespace", other.GetNamespace(), "kafkaCluster", other.GetClusterRef())
+			return requeueAfter(defaultRequeueIntervalInSeconds)
+		}
+	}
+
 	// Check if CruiseControl is ready as we cannot perform any operation until it is in ready state unless it is a stop execution operation
 	if (status.InExecution() || len(ccOperationQueueMap[ccOperationInProgress]) > 0) && ccOperationExecution.CurrentTaskOperation() != banzaiv1alpha1.OperationStopExecution {
 		// Requeue because we can't do more
 		return requeueAfter(defaultRequeueIntervalInSeconds)
 	}
 
-	log.Info("executing Cruise Control task", "operation", ccOperationExecution.CurrentTaskOperation(), "parameters", ccOperationExecution.CurrentTaskParameters())
-	// Executing operation
-	cruseControlTaskResult, err := r.executeOperation(ctx, ccOperationExecution)
+	// The acknowledgement of the failed task is used up by re-executing it
+	if ccOperationExecution.IsWaitingForRetryExecution() {
+		if err := r.removeFailureAcknowledgement(ctx, ccOperationExecution); err != nil {
+			return requeueWithError(log, "failed to remove the failure acknowledgement from CruiseControlOperation", err)
+		}
+	}
+
+	// The task could have been submitted by a previous instance of the operator which stopped before saving its result
+	cruseControlTaskResult, err := r.findSubmittedTask(ctx, ccOperationExecution)
+	if err != nil {
+		log.Error(err, "could not look up the Cruise Control user task submitted for the CruiseControlOperation")
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+
+	if cruseControlTaskResult != nil {
+		log.Info("re-adopting Cruise Control task submitted earlier", "operation", ccOperationExecution.CurrentTaskOperation(), "taskID", cruseControlTaskResult.TaskID)
+	} else {
+		if err := r.recordExecutionIntent(ctx, ccOperationExecution); err != nil {
+			log.Info("requeue event as the execution intent of the Cruise Control task could not be recorded", "reason", err.Error())
+			return requeueAfter(defaultRequeueIntervalInSeconds)
+		}
+		log.Info("executing Cruise Control task", "operation", ccOperationExecution.CurrentTaskOperation(), "parameters", scale.RedactParameters(ccOperationExecution.CurrentTaskParameters()))
+		// Executing operation
+		cruseControlTaskResult, err = r.executeOperation(ctx, ccOperationExecution)
+	}
 
 	if err != nil {
-		log.Error(err, "Cruise Control task execution got an error", "name", ccOperationExecution.GetName(), "namespace", ccOperationExecution.GetNamespace(), "operation", ccOperationExecution.CurrentTaskOperation(), "parameters", ccOperationExecution.CurrentTaskParameters())
+		log.Error(err, "Cruise Control task execution got an error", "name", ccOperationExecution.GetName(), "namespace", ccOperationExecution.GetNamespace(), "operation", ccOperationExecution.CurrentTaskOperation(), "parameters", scale.RedactParameters(ccOperationExecution.CurrentTaskParameters()))
+		// The operation cannot be performed with its parameters or by the running Cruise Control version, retrying it
+		// would not help so the task is completed with error to make it visible in the CruiseControlOperation status
+		if cruseControlTaskResult == nil && scale.ClassifyError(err, 0).Terminal {
+			cruseControlTaskResult = &scale.Result{
+				StartedAt: time.Now().UTC().Format(time.RFC1123),
+				State:     banzaiv1beta1.CruiseControlTaskCompletedWithError,
+				Err:       err,
+			}
+		}
 		// This can happen when the CruiseControlOperation parameter is wrong
 		if cruseControlTaskResult == nil {
 			return requeueWithError(log, "CruiseControlOperation custom resource is invalid", err)
 		}
 	}
 
+	statusLimits := kafkaCluster.Spec.CruiseControlConfig.CruiseControlOperationSpec.GetStatusLimits()
+	var resultConfigMap string
+	if statusLimits != nil && statusLimits.SpillResultToConfigMap && cruseControlTaskResult.Result != nil && cruseControlTaskResult.TaskID != "" {
+		// Failing to store the full result must not prevent saving the state of the task
+		if resultConfigMap, err = r.spillResult(ctx, ccOperationExecution, cruseControlTaskResult.TaskID, cruseControlTaskResult.Result); err != nil {
+			log.Error(err, "could not store the Cruise Control user task result into ConfigMap", "name", ccOperationExecution.GetName(), "namespace", ccOperationExecution.GetNamespace())
+		}
+	}
+
 	conflictRetryFunction := func() error {
 		if err = updateResult(log, cruseControlTaskResult, ccOperationExecution, true); err != nil {
 			return err
 		}
+		ccOperationExecution.CurrentTask().ResultConfigMap = resultConfigMap
+		// The result of the submission is saved, the intent is fulfilled
+		ccOperationExecution.Status.ExecutionIntent = nil
+		maxLength, maxAge := failedTasksHistoryLimits(ccOperationExecution.Spec.FailedTasksHistory,
+			statusLimits.GetFailedTasksHistory(), &r.FailedTasksHistory)
+		pruneFailedTasks(ccOperationExecution, maxLength, maxAge, time.Now())
+		boundStatus(ccOperationExecution, statusLimits)
 		err = r.Status().Update(ctx, ccOperationExecution)
 		if apiErrors.IsConflict(err) {
 			err = r.Get(ctx, client.ObjectKey{Name: ccOperationExecution.GetName(), Namespace: ccOperationExecution.GetNamespace()}, ccOperationExecution)
@@ -236,12 +420,16 @@ func (r *CruiseControlOperationReconciler) Reconcile(ctx context.Context, reques
 		return requeueWithError(log, "could not update the result of the Cruise Control user task execution to the CruiseControlOperation status", err)
 	}
 
+	if ccOperationExecution.CurrentTaskState() == banzaiv1beta1.CruiseControlTaskCompletedWithError {
+		r.reportIgnoredFailure(ccOperationExecution)
+	}
+
 	return reconciled()
 }
 
 func (r *CruiseControlOperationReconciler) addFinalizer(ctx context.Context, currentCCOperation *banzaiv1alpha1.CruiseControlOperation) error {
 	// examine DeletionTimestamp to determine if object is under deletion
-	if currentCCOperation.ObjectMeta.DeletionTimestamp.IsZero() {
+	if currentCCOperation.ObjectMeta.DeletionTimestamp.IsZero() && !r.FinalizerPolicy.skipFinalizer(currentCCOperation) {
 		// The object is not being deleted, so if it does not have our finalizer,
 		// then lets add the finalizer and update the object. This is equivalent
 		// registering our finalizer.
@@ -255,24 +443,144 @@ func (r *CruiseControlOperationReconciler) addFinalizer(ctx context.Context, cur
 	return nil
 }
 
+// preferredLeaderElectionParams returns the rebalance parameters which make Cruise Control move only the partition
+// leaderships to the preferred replicas. The executor of Cruise Control runs the preferred leader election through
+// the Kafka Admin API, partition replicas are not moved.
+func preferredLeaderElectionParams(params map[string]string) map[string]string {
+	ret := make(map[string]string, len(params)+2)
+	for key, value := range params {
+		ret[key] = value
+	}
+	ret[banzaiv1alpha1.ParamGoals] = types.PreferredLeaderElectionGoal.String()
+	ret[banzaiv1alpha1.ParamSkipHardGoalCheck] = "true"
+	return ret
+}
+
 func (r *CruiseControlOperationReconciler) executeOperation(ctx context.Context, ccOperationExecution *banzaiv1alpha1.CruiseControlOperation) (*scale.Result, error) {
 	var cruseControlTaskResult *scale.Result
 	var err error
+	ctx = withCCRequestTag(ctx, ccOperationExecution, string(ccOperationExecution.CurrentTaskOperation()))
+	// The fingerprint in the reason of the request makes it possible to find the submitted task after an operator restart
+	params := withFingerprint(ccOperationExecution.CurrentTaskParameters(), ccOperationFingerprint(ccOperationExecution))
 	switch ccOperationExecution.CurrentTaskOperation() {
 	case banzaiv1alpha1.OperationAddBroker:
-		cruseControlTaskResult, err = r.scaler.AddBrokersWithParams(ctx, ccOperationExecution.CurrentTaskParameters())
+		cruseControlTaskResult, err = r.scaler.AddBrokersWithParams(ctx, params)
 	case banzaiv1alpha1.OperationRemoveBroker:
-		cruseControlTaskResult, err = r.scaler.RemoveBrokersWithParams(ctx, ccOperationExecution.CurrentTaskParameters())
+		cruseControlTaskResult, err = r.scaler.RemoveBrokersWithParams(ctx, params)
 	case banzaiv1alpha1.OperationRebalance:
-		cruseControlTaskResult, err = r.scaler.RebalanceWithParams(ctx, ccOperationExecution.CurrentTaskParameters())
+		cruseControlTaskResult, err = r.scaler.RebalanceWithParams(ctx, params)
 	case banzaiv1alpha1.OperationStopExecution:
 		cruseControlTaskResult, err = r.scaler.StopExecution(ctx)
+	case banzaiv1alpha1.OperationDemoteBroker:
+		cruseControlTaskResult, err = r.scaler.DemoteBrokers(ctx, strings.Split(params[banzaiv1alpha1.ParamBrokerID], ",")...)
+	case banzaiv1alpha1.OperationPreferredLeaderElection:
+		cruseControlTaskResult, err = r.scaler.RebalanceWithParams(ctx, preferredLeaderElectionParams(params))
+	case banzaiv1alpha1.OperationFixOfflineReplicas:
+		cruseControlTaskResult, err = r.scaler.FixOfflineReplicasWithParams(ctx, params)
 	default:
-		err = errors.NewWithDetails("Cruise Control operation not supported", "name", ccOperationExecution.GetName(), "namespace", ccOperationExecution.GetNamespace(), "operation", ccOperationExecution.CurrentTaskOperation(), "parameters", ccOperationExecution.CurrentTaskParameters())
+		err = errors.NewWithDetails("Cruise Control operation not supported", "name", ccOperationExecution.GetName(), "namespace", ccOperationExecution.GetNamespace(), "operation", ccOperationExecution.CurrentTaskOperation(), "parameters", scale.RedactParameters(ccOperationExecution.CurrentTaskParameters()))
 	}
 	return cruseControlTaskResult, err
 }
 
+// adjustConcurrency applies the off-peak or the peak executor concurrency of the ConcurrencyBoost to Cruise Control
+// for the rebalance and remove_broker operations of the Kafka cluster. The peak concurrency is restored when the boosted
+// operation is not in progress anymore.
+func (r *CruiseControlOperationReconciler) adjustConcurrency(ctx context.Context, ccOperations []banzaiv1alpha1.CruiseControlOperation, kafkaClusterRef client.ObjectKey) error {
+	log := logr.FromContextOrDiscard(ctx)
+
+	for i := range ccOperations {
+		ccOperation := &ccOperations[i]
+		boost := ccOperation.Spec.ConcurrencyBoost
+		if boost == nil || (ccOperation.CurrentTaskOperation() != banzaiv1alpha1.OperationRebalance &&
+			ccOperation.CurrentTaskOperation() != banzaiv1alpha1.OperationRemoveBroker) {
+			continue
+		}
+		if ref, err := kafkaClusterReference(ccOperation); err != nil || ref != kafkaClusterRef {
+			continue
+		}
+
+		var boosted bool
+		switch {
+		case ccOperation.IsInProgress():
+			offPeak, err := boost.IsOffPeak(time.Now())
+			if err != nil {
+				return errors.WrapIfWithDetails(err, "could not check off-peak windows of CruiseControlOperation",
+					"name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace())
+			}
+			boosted = offPeak
+		case ccOperation.Status.ConcurrencyBoosted == nil:
+			// The concurrency has not been changed for the operation yet
+			continue
+		}
+
+		if ccOperation.Status.ConcurrencyBoosted != nil && *ccOperation.Status.ConcurrencyBoosted == boosted {
+			continue
+		}
+
+		concurrency := boost.Peak
+		if boosted {
+			concurrency = boost.OffPeak
+		}
+		log.Info("changing Cruise Control executor concurrency", "name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace(),
+			"offPeak", boosted, "concurrency", concurrency)
+		if err := r.scaler.Admin(withCCRequestTag(ctx, ccOperation, "concurrency-boost"), executorConcurrencyAdminConfig(&concurrency)); err != nil {
+			return errors.WrapIfWithDetails(err, "could not change Cruise Control executor concurrency",
+				"name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace())
+		}
+
+		ccOperation.Status.ConcurrencyBoosted = &boosted
+		if err := r.Status().Update(ctx, ccOperation); err != nil {
+			return errors.WrapIfWithDetails(err, "could not update CruiseControlOperation status",
+				"name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace())
+		}
+	}
+	return nil
+}
+
+// applyQuotas returns the operations waiting for their first execution which can be executed according to the quotas
+// of the Kafka cluster and whether any of the operations has been held back. Operations with operation type not allowed
+// by their quota are completed with error.
+func (r *CruiseControlOperationReconciler) applyQuotas(ctx context.Context, kafkaCluster *banzaiv1beta1.KafkaCluster,
+	ccOperations []*banzaiv1alpha1.CruiseControlOperation, allOperations []banzaiv1alpha1.CruiseControlOperation) ([]*banzaiv1alpha1.CruiseControlOperation, bool, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	quotas := kafkaCluster.Spec.CruiseControlConfig.CruiseControlOperationSpec.GetQuotas()
+	if len(quotas) == 0 {
+		return ccOperations, false, nil
+	}
+
+	var admitted []*banzaiv1alpha1.CruiseControlOperation
+	var heldBack bool
+	for _, ccOperation := range ccOperations {
+		if err := k8sutil.IsCCOperationTypeAllowed(quotas, ccOperation); err != nil {
+			log.Info("CruiseControlOperation is rejected by quota", "name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace(), "reason", err.Error())
+			task := ccOperation.CurrentTask()
+			task.Started = &v1.Time{Time: time.Now()}
+			task.Finished = &v1.Time{Time: time.Now()}
+			task.State = banzaiv1beta1.CruiseControlTaskCompletedWithError
+			task.ErrorMessage = err.Error()
+			if err := r.Status().Update(ctx, ccOperation); err != nil {
+				return nil, false, errors.WrapIfWithDetails(err, "could not update CruiseControlOperation status",
+					"name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace())
+			}
+			continue
+		}
+
+		reason, err := k8sutil.CCOperationHeldBackByQuota(quotas, ccOperation, allOperations, time.Now())
+		if err != nil {
+			return nil, false, err
+		}
+		if reason != "" {
+			log.Info("CruiseControlOperation is held back by quota", "name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace(), "reason", reason)
+			heldBack = true
+			continue
+		}
+		admitted = append(admitted, ccOperation)
+	}
+	return admitted, heldBack, nil
+}
+
 func sortOperations(ccOperations []*banzaiv1alpha1.CruiseControlOperation) map[string][]*banzaiv1alpha1.CruiseControlOperation {
 	ccOperationQueueMap := make(map[string][]*banzaiv1alpha1.CruiseControlOperation)
 	for _, ccOperation := range ccOperations {
@@ -300,6 +608,65 @@ func sortOperations(ccOperations []*banzaiv1alpha1.CruiseControlOperation) map[s
 	return ccOperationQueueMap
 }
 
+// schedulingGroupsLastServed returns the time when an operation of the scheduling groups has been executed last
+func schedulingGroupsLastServed(ccOperations []banzaiv1alpha1.CruiseControlOperation, kafkaClusterRef client.ObjectKey) map[string]time.Time {
+	lastServed := make(map[string]time.Time)
+	for i := range ccOperations {
+		ccOperation := &ccOperations[i]
+		if ref, err := kafkaClusterReference(ccOperation); err != nil || ref != kafkaClusterRef {
+			continue
+		}
+		started := ccOperation.LastStarted()
+		if started == nil {
+			continue
+		}
+		group := ccOperation.Spec.SchedulingGroup
+		if started.Time.After(lastServed[group]) {
+			lastServed[group] = started.Time
+		}
+	}
+	return lastServed
+}
+
+// interleaveSchedulingGroups reorders the operations having the same execution priority so the scheduling groups
+// follow each other in a round-robin fashion starting with the group which has been served the longest time ago.
+// The order of the operations within a scheduling group is kept.
+func interleaveSchedulingGroups(ccOperationQueue []*banzaiv1alpha1.CruiseControlOperation, lastServed map[string]time.Time) []*banzaiv1alpha1.CruiseControlOperation {
+	ret := make([]*banzaiv1alpha1.CruiseControlOperation, 0, len(ccOperationQueue))
+	for start := 0; start < len(ccOperationQueue); {
+		// Operations are sorted by priority so the ones with the same priority are next to each other
+		priority := executionPriorityMap[ccOperationQueue[start].CurrentTaskOperation()]
+		end := start
+		var groupOrder []string
+		groups := make(map[string][]*banzaiv1alpha1.CruiseControlOperation)
+		for ; end < len(ccOperationQueue) && executionPriorityMap[ccOperationQueue[end].CurrentTaskOperation()] == priority; end++ {
+			group := ccOperationQueue[end].Spec.SchedulingGroup
+			if _, ok := groups[group]; !ok {
+				groupOrder = append(groupOrder, group)
+			}
+			groups[group] = append(groups[group], ccOperationQueue[end])
+		}
+
+		// Groups served the longest time ago come first, the groups having the same last served time keep the order
+		// of their oldest operation
+		sort.SliceStable(groupOrder, func(i, j int) bool {
+			return lastServed[groupOrder[i]].Before(lastServed[groupOrder[j]])
+		})
+		for len(groupOrder) > 0 {
+			var remaining []string
+			for _, group := range groupOrder {
+				ret = append(ret, groups[group][0])
+				if groups[group] = groups[group][1:]; len(groups[group]) > 0 {
+					remaining = append(remaining, group)
+				}
+			}
+			groupOrder = remaining
+		}
+		start = end
+	}
+	return ret
+}
+
 func selectOperationForExecution(ccOperationQueueMap map[string][]*banzaiv1alpha1.CruiseControlOperation) *banzaiv1alpha1.CruiseControlOperation {
 	// SELECTING OPERATION FOR EXECUTION
 	var ccOperationExecution *banzaiv1alpha1.CruiseControlOperation
@@ -313,7 +680,7 @@ func selectOperationForExecution(ccOperationQueueMap map[string][]*banzaiv1alpha
 		ccOperationExecution = ccOperationQueueMap[ccOperationFirstExecution][0]
 	// Third prio: execute failed task
 	case len(ccOperationQueueMap[ccOperationRetryExecution]) > 0:
-		// When the default backoff duration elapsed we retry
+		// When the backoff duration elapsed we retry
 		if ccOperationQueueMap[ccOperationRetryExecution][0].IsReadyForRetryExecution() {
 			ccOperationExecution = ccOperationQueueMap[ccOperationRetryExecution][0]
 		}
@@ -328,36 +695,44 @@ func selectOperationForExecution(ccOperationQueueMap map[string][]*banzaiv1alpha
 // SetupCruiseControlWithManager registers cruise control controller to the manager
 func SetupCruiseControlOperationWithManager(mgr ctrl.Manager) *ctrl.Builder {
 	builder := ctrl.NewControllerManagedBy(mgr).
-		For(&banzaiv1alpha1.CruiseControlOperation{}).
+		For(&banzaiv1alpha1.CruiseControlOperation{}, ctrlBuilder.WithPredicates(ccOperationPredicate())).
 		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
 		Named("CruiseControlOperation")
 
-	builder.WithEventFilter(
-		predicate.Funcs{
-			// We don't reconcile when there is no operation defined
-			CreateFunc: func(e event.CreateEvent) bool {
-				obj := e.Object.(*banzaiv1alpha1.CruiseControlOperation)
-				// Doesn't need to reconcile when the operation is done and finalizing is not needed
-				return !(obj.IsDone() && obj.GetDeletionTimestamp().IsZero()) && obj.CurrentTaskOperation() != ""
-			},
-			UpdateFunc: func(e event.UpdateEvent) bool {
-				oldObj := e.ObjectOld.(*banzaiv1alpha1.CruiseControlOperation)
-				newObj := e.ObjectNew.(*banzaiv1alpha1.CruiseControlOperation)
-				// Doesn't need to reconcile when the operation is done and finalizing is not needed
-				if newObj.IsDone() && newObj.GetDeletionTimestamp().IsZero() {
-					return false
-				}
-				if !reflect.DeepEqual(oldObj.CurrentTask(), newObj.CurrentTask()) ||
-					oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
-					oldObj.IsPaused() != newObj.IsPaused() ||
-					oldObj.GetGeneration() != newObj.GetGeneration() {
-					return true
-				}
+	return ccOperationWakeUpWatches(builder, mgr.GetClient(), mgr.GetLogger())
+}
+
+func ccOperationPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		// We don't reconcile when there is no operation defined
+		CreateFunc: func(e event.CreateEvent) bool {
+			obj := e.Object.(*banzaiv1alpha1.CruiseControlOperation)
+			// Doesn't need to reconcile when the operation is done and finalizing is not needed
+			return !(obj.IsDone() && obj.GetDeletionTimestamp().IsZero()) && obj.CurrentTaskOperation() != ""
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldObj := e.ObjectOld.(*banzaiv1alpha1.CruiseControlOperation)
+			newObj := e.ObjectNew.(*banzaiv1alpha1.CruiseControlOperation)
+			// Doesn't need to reconcile when the operation is done and finalizing is not needed
+			if newObj.IsDone() && newObj.GetDeletionTimestamp().IsZero() {
 				return false
-			},
-		})
+			}
+			if !reflect.DeepEqual(oldObj.CurrentTask(), newObj.CurrentTask()) ||
+				oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
+				oldObj.IsPaused() != newObj.IsPaused() ||
+				isFailureAcknowledgementChanged(oldObj, newObj) ||
+				oldObj.GetAnnotations()[banzaiv1beta1.ForceCleanupAnnotationKey] != newObj.GetAnnotations()[banzaiv1beta1.ForceCleanupAnnotationKey] ||
+				oldObj.GetGeneration() != newObj.GetGeneration() {
+				return true
+			}
+			return false
+		},
+	}
+}
 
-	return builder
+func isFailureAcknowledgementChanged(oldObj, newObj *banzaiv1alpha1.CruiseControlOperation) bool {
+	return oldObj.GetAnnotations()[banzaiv1alpha1.AcknowledgeFailureAnnotation] != newObj.GetAnnotations()[banzaiv1alpha1.AcknowledgeFailureAnnotation]
 }
 
 func isFinalizerNeeded(operation *banzaiv1alpha1.CruiseControlOperation) bool {
@@ -386,6 +761,7 @@ func updateResult(log logr.Logger, res *scale.Result, operation *banzaiv1alpha1.
 	}
 
 	operation.Status.ErrorPolicy = operation.Spec.ErrorPolicy
+	operation.Status.ObservedGeneration = operation.GetGeneration()
 	task := operation.CurrentTask()
 
 	if (res.State == banzaiv1beta1.CruiseControlTaskCompleted || res.State == banzaiv1beta1.CruiseControlTaskCompletedWithError) && task.Finished == nil {
@@ -394,9 +770,6 @@ func updateResult(log logr.Logger, res *scale.Result, operation *banzaiv1alpha1.
 
 	// Add the failed task into the status.failedTasks slice only when the update is happened after executing the task
 	if isAfterExecution && task.Finished != nil && task.State == banzaiv1beta1.CruiseControlTaskCompletedWithError {
-		if len(operation.Status.FailedTasks) >= defaultFailedTasksHistoryMaxLength {
-			operation.Status.FailedTasks = operation.Status.FailedTasks[1:]
-		}
 		operation.Status.FailedTasks = append(operation.Status.FailedTasks, *task)
 
 		operation.Status.RetryCount += 1
@@ -413,21 +786,42 @@ func updateResult(log logr.Logger, res *scale.Result, operation *banzaiv1alpha1.
 		}
 		task.ID = res.TaskID
 		task.Summary = formatSummary(res.Result)
+		if isScaleDownDryRun(operation) {
+			task.Simulation = scaleDownSimulation(res.Result, operationBrokerIDs(operation))
+		}
 		if res.Err != nil {
-			task.ErrorMessage = res.Err.Error()
+			task.ErrorMessage = scale.Redact(res.Err.Error())
+			classified := scale.ClassifyError(res.Err, res.ResponseStatusCode)
+			task.ErrorReason = string(classified.Reason)
+			task.TerminalError = classified.Terminal
 		}
-		task.HTTPRequest = res.RequestURL
+		task.HTTPRequest = scale.Redact(res.RequestURL)
 		task.HTTPResponseCode = &res.ResponseStatusCode
 	}
 
 	task.State = res.State
+	updateRetryBackoffStatus(operation)
 
 	return nil
 }
 
+// updateRetryBackoffStatus mirrors the schedule of the next retry into the status of the CruiseControlOperation
+// so users can see when the failed task is re-executed.
+func updateRetryBackoffStatus(operation *banzaiv1alpha1.CruiseControlOperation) {
+	next := operation.NextRetryTime()
+	if next == nil {
+		operation.Status.NextRetryTime = nil
+		operation.Status.RetryBackoffAttempt = 0
+		return
+	}
+	operation.Status.NextRetryTime = &v1.Time{Time: *next}
+	operation.Status.RetryBackoffAttempt = operation.RetryBackoffAttempt()
+}
+
 // updateCurrentTasks the state of the CruiseControlOperation from the CruiseControlTasksAndStates instance by getting their
-// status from Cruise Control.
-func (r *CruiseControlOperationReconciler) updateCurrentTasks(ctx context.Context, ccOperations []*banzaiv1alpha1.CruiseControlOperation) error {
+// status from Cruise Control. The partition movements of the task in execution are taken from the given status.
+func (r *CruiseControlOperationReconciler) updateCurrentTasks(ctx context.Context, ccOperations []*banzaiv1alpha1.CruiseControlOperation,
+	status scale.CruiseControlStatus) error {
 	log := logr.FromContextOrDiscard(ctx)
 
 	userTaskIDs := make([]string, 0, len(ccOperations))
@@ -456,6 +850,7 @@ func (r *CruiseControlOperationReconciler) updateCurrentTasks(ctx context.Contex
 				return errors.WrapWithDetails(err, "could not set Cruise Control user task result to CruiseControlOperation CurrentTask", "name", ccOperations[i].GetName(), "namespace", ccOperations[i].GetNamespace())
 			}
 		}
+		updatePartitionMovements(ccOperation, status)
 	}
 
 	for i := range ccOperations {
@@ -463,11 +858,128 @@ func (r *CruiseControlOperationReconciler) updateCurrentTasks(ctx context.Contex
 			if err := r.Status().Update(ctx, ccOperations[i]); err != nil {
 				return errors.WrapIfWithDetails(err, "could not update CruiseControlOperation status", "name", ccOperations[i].GetName(), "namespace", ccOperations[i].GetNamespace())
 			}
+			if ccOperations[i].CurrentTaskState() == banzaiv1beta1.CruiseControlTaskCompletedWithError &&
+				ccOperationsCopy[i].CurrentTaskState() != banzaiv1beta1.CruiseControlTaskCompletedWithError {
+				r.reportIgnoredFailure(ccOperations[i])
+			}
 		}
 	}
 	return nil
 }
 
+// reportIgnoredFailure emits a warning event and counts the failed task of the CruiseControlOperation
+// when it is handled as completed due to the "ignoreWithEvent" error policy
+func (r *CruiseControlOperationReconciler) reportIgnoredFailure(operation *banzaiv1alpha1.CruiseControlOperation) {
+	if operation.Spec.ErrorPolicy != banzaiv1alpha1.ErrorPolicyIgnoreWithEvent {
+		return
+	}
+	ccOperationIgnoredFailuresCounter.WithLabelValues(operation.GetNamespace(), operation.GetClusterRef()).Inc()
+	if r.Recorder != nil {
+		r.Recorder.Eventf(operation, corev1.EventTypeWarning, "FailureIgnored",
+			"Cruise Control task %s of operation %s completed with error: %s", operation.CurrentTaskID(),
+			operation.CurrentTaskOperation(), operation.CurrentTask().ErrorMessage)
+	}
+}
+
+// removeFailureAcknowledgement removes the acknowledgement of the failed task from the CruiseControlOperation
+// with the "manualAck" error policy so the next failure needs to be acknowledged again
+func (r *CruiseControlOperationReconciler) removeFailureAcknowledgement(ctx context.Context, operation *banzaiv1alpha1.CruiseControlOperation) error {
+	if _, ok := operation.GetAnnotations()[banzaiv1alpha1.AcknowledgeFailureAnnotation]; !ok {
+		return nil
+	}
+	annotations := operation.GetAnnotations()
+	delete(annotations, banzaiv1alpha1.AcknowledgeFailureAnnotation)
+	operation.SetAnnotations(annotations)
+	return r.Update(ctx, operation)
+}
+
+// coalesceDuplicateOperations filters out the CruiseControlOperations waiting for their first execution which have
+// the same operation type and parameters as an earlier created operation of the same Kafka cluster. When the earlier
+// operation is still pending or in progress the duplicate is held back. When the earlier operation has completed after
+// the duplicate was created the duplicate is marked as completed without sending it to Cruise Control.
+func (r *CruiseControlOperationReconciler) coalesceDuplicateOperations(ctx context.Context, allOperations []banzaiv1alpha1.CruiseControlOperation,
+	ccOperations []*banzaiv1alpha1.CruiseControlOperation) ([]*banzaiv1alpha1.CruiseControlOperation, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	filtered := make([]*banzaiv1alpha1.CruiseControlOperation, 0, len(ccOperations))
+	for _, ccOperation := range ccOperations {
+		original, originalCompleted := findOriginalOperation(ccOperation, allOperations)
+		switch {
+		case original == nil:
+			filtered = append(filtered, ccOperation)
+		case originalCompleted:
+			log.Info("CruiseControlOperation is a duplicate of an already completed operation, marking it as completed",
+				"name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace(), "duplicate of", original.GetName())
+			now := v1.Now()
+			task := ccOperation.CurrentTask()
+			task.Started = &now
+			task.Finished = &now
+			task.State = banzaiv1beta1.CruiseControlTaskCompleted
+			task.Summary = original.CurrentTask().Summary
+			task.Simulation = original.CurrentTask().Simulation
+			ccOperation.Status.ErrorPolicy = ccOperation.Spec.ErrorPolicy
+			ccOperation.Status.ObservedGeneration = ccOperation.GetGeneration()
+			ccOperation.Status.DuplicateOf = original.GetName()
+			if err := r.Status().Update(ctx, ccOperation); err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not update status of duplicated CruiseControlOperation", "name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace())
+			}
+		default:
+			log.V(1).Info("CruiseControlOperation is held back as it is a duplicate of a pending or running operation",
+				"name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace(), "duplicate of", original.GetName())
+		}
+	}
+	return filtered, nil
+}
+
+// findOriginalOperation returns the earliest created operation which makes the provided operation redundant
+// and whether that operation has already been completed.
+func findOriginalOperation(ccOperation *banzaiv1alpha1.CruiseControlOperation, allOperations []banzaiv1alpha1.CruiseControlOperation) (*banzaiv1alpha1.CruiseControlOperation, bool) {
+	// Only the operations which have not been sent to Cruise Control yet can be coalesced
+	if !ccOperation.IsWaitingForFirstExecution() || !ccOperation.GetDeletionTimestamp().IsZero() {
+		return nil, false
+	}
+	if _, ok := executionPriorityMap[ccOperation.CurrentTaskOperation()]; !ok {
+		return nil, false
+	}
+
+	var original *banzaiv1alpha1.CruiseControlOperation
+	var originalCompleted bool
+	for i := range allOperations {
+		other := &allOperations[i]
+		if !ccOperation.IsDuplicateOf(other) || !other.GetDeletionTimestamp().IsZero() || !isCreatedBefore(other, ccOperation) {
+			continue
+		}
+
+		var completed bool
+		switch {
+		case other.CurrentTaskState() == banzaiv1beta1.CruiseControlTaskCompleted:
+			// A completed operation makes the duplicate redundant only when it has been finished after the duplicate was created
+			if other.CurrentTaskFinished() == nil || other.CurrentTaskFinished().Before(&ccOperation.CreationTimestamp) {
+				continue
+			}
+			completed = true
+		case other.IsWaitingForFirstExecution(), other.IsInProgress(), other.IsWaitingForRetryExecution():
+		default:
+			continue
+		}
+
+		if original == nil || isCreatedBefore(other, original) {
+			original = other
+			originalCompleted = completed
+		}
+	}
+	return original, originalCompleted
+}
+
+// isCreatedBefore returns true when the first operation has been created earlier than the second one.
+// Operations with the same creation timestamp are ordered by their name.
+func isCreatedBefore(first, second *banzaiv1alpha1.CruiseControlOperation) bool {
+	if first.CreationTimestamp.Equal(&second.CreationTimestamp) {
+		return first.GetName() < second.GetName()
+	}
+	return first.CreationTimestamp.Before(&second.CreationTimestamp)
+}
+
 func isWaitingForFinalization(ccOperation *banzaiv1alpha1.CruiseControlOperation) bool {
 	return ccOperation.IsCurrentTaskRunning() && !ccOperation.ObjectMeta.DeletionTimestamp.IsZero() && controllerutil.ContainsFinalizer(ccOperation, ccOperationFinalizerGroup)
 }
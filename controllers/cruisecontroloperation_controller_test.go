@@ -18,11 +18,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
 )
 
 func createCCRetryExecutionOperation(createTime time.Time, id string, operation v1alpha1.CruiseControlTaskOperation) *v1alpha1.CruiseControlOperation {
@@ -91,3 +95,221 @@ func TestSortOperations(t *testing.T) {
 		assert.Equal(t, sortedRetryOutput, testCase.expectedOutput, "test", testCase.testName)
 	}
 }
+
+func createCCFirstExecutionOperation(createTime time.Time, name string, operation v1alpha1.CruiseControlTaskOperation, params map[string]string) *v1alpha1.CruiseControlOperation {
+	return &v1alpha1.CruiseControlOperation{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: "kafka",
+			Labels: map[string]string{
+				v1beta1.KafkaCRLabelKey: "kafka",
+			},
+			CreationTimestamp: v1.Time{
+				Time: createTime,
+			},
+		},
+		Spec: v1alpha1.CruiseControlOperationSpec{
+			ErrorPolicy: v1alpha1.ErrorPolicyRetry,
+		},
+		Status: v1alpha1.CruiseControlOperationStatus{
+			CurrentTask: &v1alpha1.CruiseControlTask{
+				Operation:  operation,
+				Parameters: params,
+			},
+		},
+	}
+}
+
+func TestFindOriginalOperation(t *testing.T) {
+	timeNow := time.Now()
+	rebalanceParams := map[string]string{"destination_broker_ids": "1,2"}
+
+	completedBefore := createCCFirstExecutionOperation(timeNow.Add(-2*time.Minute), "completed-before", v1alpha1.OperationRebalance, rebalanceParams)
+	completedBefore.Status.CurrentTask.ID = "completed-before"
+	completedBefore.Status.CurrentTask.State = v1beta1.CruiseControlTaskCompleted
+	completedBefore.Status.CurrentTask.Finished = &v1.Time{Time: timeNow.Add(-time.Minute)}
+
+	completedAfter := completedBefore.DeepCopy()
+	completedAfter.Name = "completed-after"
+	completedAfter.Status.CurrentTask.Finished = &v1.Time{Time: timeNow.Add(time.Minute)}
+
+	inProgress := createCCFirstExecutionOperation(timeNow.Add(-time.Minute), "in-progress", v1alpha1.OperationRebalance, rebalanceParams)
+	inProgress.Status.CurrentTask.ID = "in-progress"
+	inProgress.Status.CurrentTask.State = v1beta1.CruiseControlTaskInExecution
+
+	testCases := []struct {
+		testName          string
+		ccOperation       *v1alpha1.CruiseControlOperation
+		allOperations     []*v1alpha1.CruiseControlOperation
+		expectedOriginal  string
+		expectedCompleted bool
+	}{
+		{
+			testName:    "no duplicate with different parameters",
+			ccOperation: createCCFirstExecutionOperation(timeNow, "new", v1alpha1.OperationRebalance, rebalanceParams),
+			allOperations: []*v1alpha1.CruiseControlOperation{
+				createCCFirstExecutionOperation(timeNow.Add(-time.Second), "other", v1alpha1.OperationRebalance, map[string]string{"destination_broker_ids": "3"}),
+			},
+		},
+		{
+			testName:    "no duplicate with different operation",
+			ccOperation: createCCFirstExecutionOperation(timeNow, "new", v1alpha1.OperationRebalance, rebalanceParams),
+			allOperations: []*v1alpha1.CruiseControlOperation{
+				createCCFirstExecutionOperation(timeNow.Add(-time.Second), "other", v1alpha1.OperationAddBroker, rebalanceParams),
+			},
+		},
+		{
+			testName:    "later created operation is not the original",
+			ccOperation: createCCFirstExecutionOperation(timeNow, "new", v1alpha1.OperationRebalance, rebalanceParams),
+			allOperations: []*v1alpha1.CruiseControlOperation{
+				createCCFirstExecutionOperation(timeNow.Add(time.Second), "later", v1alpha1.OperationRebalance, rebalanceParams),
+			},
+		},
+		{
+			testName:    "duplicate of pending operation",
+			ccOperation: createCCFirstExecutionOperation(timeNow, "new", v1alpha1.OperationRebalance, rebalanceParams),
+			allOperations: []*v1alpha1.CruiseControlOperation{
+				createCCFirstExecutionOperation(timeNow.Add(-time.Second), "pending", v1alpha1.OperationRebalance, rebalanceParams),
+			},
+			expectedOriginal: "pending",
+		},
+		{
+			testName:         "duplicate of in progress operation",
+			ccOperation:      createCCFirstExecutionOperation(timeNow, "new", v1alpha1.OperationRebalance, rebalanceParams),
+			allOperations:    []*v1alpha1.CruiseControlOperation{inProgress},
+			expectedOriginal: "in-progress",
+		},
+		{
+			testName:      "operation completed before creation is not the original",
+			ccOperation:   createCCFirstExecutionOperation(timeNow, "new", v1alpha1.OperationRebalance, rebalanceParams),
+			allOperations: []*v1alpha1.CruiseControlOperation{completedBefore},
+		},
+		{
+			testName:          "duplicate of operation completed after creation",
+			ccOperation:       createCCFirstExecutionOperation(timeNow, "new", v1alpha1.OperationRebalance, rebalanceParams),
+			allOperations:     []*v1alpha1.CruiseControlOperation{completedAfter},
+			expectedOriginal:  "completed-after",
+			expectedCompleted: true,
+		},
+		{
+			testName:    "stop execution is not coalesced",
+			ccOperation: createCCFirstExecutionOperation(timeNow, "new", v1alpha1.OperationStopExecution, nil),
+			allOperations: []*v1alpha1.CruiseControlOperation{
+				createCCFirstExecutionOperation(timeNow.Add(-time.Second), "pending", v1alpha1.OperationStopExecution, nil),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		allOperations := []v1alpha1.CruiseControlOperation{*testCase.ccOperation}
+		for _, operation := range testCase.allOperations {
+			allOperations = append(allOperations, *operation)
+		}
+		original, completed := findOriginalOperation(testCase.ccOperation, allOperations)
+		originalName := ""
+		if original != nil {
+			originalName = original.GetName()
+		}
+		assert.Equal(t, testCase.expectedOriginal, originalName, "test", testCase.testName)
+		assert.Equal(t, testCase.expectedCompleted, completed, "test", testCase.testName)
+	}
+}
+
+func TestInterleaveSchedulingGroups(t *testing.T) {
+	timeNow := time.Now()
+	newOperation := func(createTime time.Time, name, group string, operation v1alpha1.CruiseControlTaskOperation) *v1alpha1.CruiseControlOperation {
+		op := createCCFirstExecutionOperation(createTime, name, operation, nil)
+		op.Spec.SchedulingGroup = group
+		return op
+	}
+
+	addBroker := newOperation(timeNow.Add(3*time.Second), "add", "b", v1alpha1.OperationAddBroker)
+	a1 := newOperation(timeNow, "a1", "a", v1alpha1.OperationRebalance)
+	a2 := newOperation(timeNow.Add(time.Second), "a2", "a", v1alpha1.OperationRebalance)
+	a3 := newOperation(timeNow.Add(2*time.Second), "a3", "a", v1alpha1.OperationRebalance)
+	b1 := newOperation(timeNow.Add(3*time.Second), "b1", "b", v1alpha1.OperationRebalance)
+	c1 := newOperation(timeNow.Add(4*time.Second), "c1", "", v1alpha1.OperationRebalance)
+
+	testCases := []struct {
+		testName       string
+		queue          []*v1alpha1.CruiseControlOperation
+		lastServed     map[string]time.Time
+		expectedOutput []*v1alpha1.CruiseControlOperation
+	}{
+		{
+			testName:       "groups follow each other",
+			queue:          []*v1alpha1.CruiseControlOperation{addBroker, a1, a2, a3, b1, c1},
+			expectedOutput: []*v1alpha1.CruiseControlOperation{addBroker, a1, b1, c1, a2, a3},
+		},
+		{
+			testName: "group served the longest time ago comes first",
+			queue:    []*v1alpha1.CruiseControlOperation{a1, a2, a3, b1, c1},
+			lastServed: map[string]time.Time{
+				"a": timeNow.Add(-time.Minute),
+				"b": timeNow.Add(-2 * time.Minute),
+			},
+			expectedOutput: []*v1alpha1.CruiseControlOperation{c1, b1, a1, a2, a3},
+		},
+	}
+
+	for _, testCase := range testCases {
+		output := interleaveSchedulingGroups(testCase.queue, testCase.lastServed)
+		assert.Equal(t, testCase.expectedOutput, output, "test", testCase.testName)
+	}
+}
+
+func TestReportIgnoredFailure(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := CruiseControlOperationReconciler{Recorder: recorder}
+	defer ccOperationIgnoredFailuresCounter.DeleteLabelValues("kafka", "kafka")
+
+	operation := createCCRetryExecutionOperation(time.Now(), "task", v1alpha1.OperationRebalance)
+	operation.Namespace = "kafka"
+	operation.Labels = map[string]string{v1beta1.KafkaCRLabelKey: "kafka"}
+	operation.CurrentTask().ErrorMessage = "not enough brokers"
+
+	// Only the failures of the operations with ignoreWithEvent error policy are reported
+	r.reportIgnoredFailure(operation)
+	assert.Len(t, recorder.Events, 0)
+
+	operation.Spec.ErrorPolicy = v1alpha1.ErrorPolicyIgnoreWithEvent
+	r.reportIgnoredFailure(operation)
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "not enough brokers")
+	assert.Equal(t, 1.0, testutil.ToFloat64(ccOperationIgnoredFailuresCounter.WithLabelValues("kafka", "kafka")))
+}
+
+func TestPreferredLeaderElectionParams(t *testing.T) {
+	params := map[string]string{
+		v1alpha1.ParamGoals:  "RackAwareGoal",
+		v1alpha1.ParamReason: "koperator fingerprint",
+	}
+	assert.Equal(t, map[string]string{
+		v1alpha1.ParamGoals:             "PreferredLeaderElectionGoal",
+		v1alpha1.ParamSkipHardGoalCheck: "true",
+		v1alpha1.ParamReason:            "koperator fingerprint",
+	}, preferredLeaderElectionParams(params))
+	// The parameters of the operation are not modified
+	assert.Equal(t, "RackAwareGoal", params[v1alpha1.ParamGoals])
+}
+
+func TestUpdateRetryBackoffStatus(t *testing.T) {
+	initialDelay := int32(10)
+	operation := createCCRetryExecutionOperation(time.Now(), "task", v1alpha1.OperationRebalance)
+	operation.Spec.RetryBackoff = &v1alpha1.RetryBackoff{InitialDelaySeconds: &initialDelay}
+	operation.Status.RetryCount = 1
+
+	// The failed task is waiting for its second retry
+	err := updateResult(logr.Discard(), &scale.Result{TaskID: "task", State: v1beta1.CruiseControlTaskCompletedWithError}, operation, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, operation.Status.RetryBackoffAttempt)
+	if assert.NotNil(t, operation.Status.NextRetryTime) {
+		assert.Equal(t, 20*time.Second, operation.Status.NextRetryTime.Sub(operation.CurrentTaskFinished().Time))
+	}
+
+	// The schedule is cleared when the task is no longer waiting for retry
+	operation.Status.CurrentTask.State = v1beta1.CruiseControlTaskActive
+	err = updateResult(logr.Discard(), &scale.Result{TaskID: "task", State: v1beta1.CruiseControlTaskCompleted}, operation, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, operation.Status.RetryBackoffAttempt)
+	assert.Nil(t, operation.Status.NextRetryTime)
+}
@@ -0,0 +1,133 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaiv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	kafkautil "github.com/banzaicloud/koperator/pkg/util/kafka"
+)
+
+// isMinInSyncReplicasCheckRequired returns true when removing the brokers of the CruiseControlOperation needs to be
+// checked against the min.insync.replicas of the topics. The check can be skipped in emergencies by annotating the
+// CruiseControlOperation or the Kafka cluster.
+func isMinInSyncReplicasCheckRequired(operation *banzaiv1alpha1.CruiseControlOperation, kafkaCluster *banzaiv1beta1.KafkaCluster) bool {
+	if operation.CurrentTaskOperation() != banzaiv1alpha1.OperationRemoveBroker ||
+		operation.CurrentTaskParameters()[banzaiv1alpha1.ParamDryRun] == "true" {
+		return false
+	}
+	return operation.GetAnnotations()[banzaiv1beta1.SkipMinInSyncReplicasCheckAnnotationKey] != "true" &&
+		kafkaCluster.GetAnnotations()[banzaiv1beta1.SkipMinInSyncReplicasCheckAnnotationKey] != "true"
+}
+
+// holdBackForMinInSyncReplicas returns the operations waiting for execution which can be submitted without pushing
+// partitions below their min.insync.replicas and whether any of them has been held back. The remove_broker operations
+// whose brokers host in-sync replicas needed to satisfy min.insync.replicas are put into the
+// WaitingForMinInSyncReplicas state, the state is cleared when the partitions have enough in-sync replicas again.
+func (r *CruiseControlOperationReconciler) holdBackForMinInSyncReplicas(ctx context.Context, ccOperations []*banzaiv1alpha1.CruiseControlOperation,
+	kafkaCluster *banzaiv1beta1.KafkaCluster) ([]*banzaiv1alpha1.CruiseControlOperation, bool, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	var admitted []*banzaiv1alpha1.CruiseControlOperation
+	var heldBack bool
+	var partitions []kafkaclient.PartitionReplicas
+	for _, ccOperation := range ccOperations {
+		var reason string
+		if r.KafkaClientProvider != nil && isMinInSyncReplicasCheckRequired(ccOperation, kafkaCluster) {
+			if partitions == nil {
+				var err error
+				if partitions, err = r.partitionReplicas(kafkaCluster); err != nil {
+					return nil, false, err
+				}
+			}
+			reason = minInSyncReplicasWaitReason(ccOperation, partitions, kafkautil.MinInSyncReplicas(kafkaCluster))
+		}
+
+		if reason == "" {
+			if ccOperation.Status.WaitState == banzaiv1alpha1.WaitStateWaitingForMinInSyncReplicas {
+				if err := r.setWaitState(ctx, ccOperation, "", ""); err != nil {
+					return nil, false, err
+				}
+			}
+			admitted = append(admitted, ccOperation)
+			continue
+		}
+
+		if err := r.setWaitState(ctx, ccOperation, banzaiv1alpha1.WaitStateWaitingForMinInSyncReplicas, reason); err != nil {
+			return nil, false, err
+		}
+		log.Info("CruiseControlOperation is held back as it would push partitions below min.insync.replicas",
+			"name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace(), "reason", reason)
+		heldBack = true
+	}
+	return admitted, heldBack, nil
+}
+
+func (r *CruiseControlOperationReconciler) partitionReplicas(kafkaCluster *banzaiv1beta1.KafkaCluster) ([]kafkaclient.PartitionReplicas, error) {
+	kClient, close, err := r.KafkaClientProvider.NewFromCluster(r.Client, kafkaCluster)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	defer close()
+	partitions, err := kClient.PartitionReplicas()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not get the in-sync replicas of the partitions")
+	}
+	// The partitions are fetched only once per reconciliation even when the cluster has no topics
+	if partitions == nil {
+		partitions = []kafkaclient.PartitionReplicas{}
+	}
+	return partitions, nil
+}
+
+// minInSyncReplicasWaitReason returns why removing the brokers of the CruiseControlOperation would push partitions
+// below their min.insync.replicas, or an empty string when it would not
+func minInSyncReplicasWaitReason(operation *banzaiv1alpha1.CruiseControlOperation, partitions []kafkaclient.PartitionReplicas,
+	defaultMinInSyncReplicas int32) string {
+	brokerIDs := operationBrokerIDs(operation)
+	violations := kafkaclient.MinInSyncReplicasViolations(partitions, brokerIDs, defaultMinInSyncReplicas)
+	if len(violations) == 0 {
+		return ""
+	}
+	first := violations[0]
+	return fmt.Sprintf("removing brokers %v would push %d partitions below their min.insync.replicas, e.g. %s-%d would "+
+		"have %d in-sync replicas instead of %d; set the %s annotation to \"true\" to remove them anyway",
+		brokerIDs, len(violations), first.Topic, first.Partition, first.InSyncReplicas, first.MinInSyncReplicas,
+		banzaiv1beta1.SkipMinInSyncReplicasCheckAnnotationKey)
+}
+
+// operationBrokerIDs returns the added, removed or demoted brokers of the CruiseControlOperation, the invalid broker
+// IDs are left out as they are rejected by Cruise Control anyway
+func operationBrokerIDs(operation *banzaiv1alpha1.CruiseControlOperation) []int32 {
+	var brokerIDs []int32
+	for _, value := range strings.Split(operation.CurrentTaskParameters()[banzaiv1alpha1.ParamBrokerID], ",") {
+		brokerID, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil {
+			continue
+		}
+		brokerIDs = append(brokerIDs, int32(brokerID))
+	}
+	return brokerIDs
+}
@@ -0,0 +1,79 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+func TestHoldBackForMinInSyncReplicas(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	newOperation := func(name string, operation v1alpha1.CruiseControlTaskOperation, brokerIDs string) *v1alpha1.CruiseControlOperation {
+		return &v1alpha1.CruiseControlOperation{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kafka", Name: name},
+			Status: v1alpha1.CruiseControlOperationStatus{
+				CurrentTask: &v1alpha1.CruiseControlTask{Operation: operation, Parameters: map[string]string{v1alpha1.ParamBrokerID: brokerIDs}},
+			},
+		}
+	}
+	removeOne := newOperation("remove-one", v1alpha1.OperationRemoveBroker, "2")
+	removeTwo := newOperation("remove-two", v1alpha1.OperationRemoveBroker, "1,2")
+	demote := newOperation("demote", v1alpha1.OperationDemoteBroker, "1,2")
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(removeOne, removeTwo, demote).Build()
+	recorder := record.NewFakeRecorder(10)
+	kafkaCluster := &v1beta1.KafkaCluster{Spec: v1beta1.KafkaClusterSpec{ClusterWideConfig: "min.insync.replicas=2"}}
+	kafkaClient := &rfRepairTestKafkaClient{partitions: []kafkaclient.PartitionReplicas{
+		{Topic: "orders", Partition: 0, Replicas: []int32{0, 1, 2}, Isr: []int32{0, 1, 2}},
+	}}
+	r := &CruiseControlOperationReconciler{Client: c, Recorder: recorder,
+		KafkaClientProvider: &rfRepairTestProvider{kafkaClient: kafkaClient}}
+
+	admitted, heldBack, err := r.holdBackForMinInSyncReplicas(ctx, []*v1alpha1.CruiseControlOperation{removeOne, removeTwo, demote}, kafkaCluster)
+	require.NoError(t, err)
+	assert.True(t, heldBack)
+	assert.Equal(t, []*v1alpha1.CruiseControlOperation{removeOne, demote}, admitted)
+
+	stored := &v1alpha1.CruiseControlOperation{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "remove-two"}, stored))
+	assert.Equal(t, v1alpha1.WaitStateWaitingForMinInSyncReplicas, stored.Status.WaitState)
+	assert.Contains(t, stored.Status.WaitReason, "orders-0 would have 1 in-sync replicas instead of 2")
+	assert.Len(t, recorder.Events, 1)
+
+	// the check is skipped by the annotation of the operation
+	removeTwo.SetAnnotations(map[string]string{v1beta1.SkipMinInSyncReplicasCheckAnnotationKey: "true"})
+	admitted, heldBack, err = r.holdBackForMinInSyncReplicas(ctx, []*v1alpha1.CruiseControlOperation{removeTwo}, kafkaCluster)
+	require.NoError(t, err)
+	assert.False(t, heldBack)
+	assert.Len(t, admitted, 1)
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "remove-two"}, stored))
+	assert.Empty(t, stored.Status.WaitState)
+	assert.Empty(t, stored.Status.WaitReason)
+}
@@ -0,0 +1,89 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+// requiresCCModel returns true when Cruise Control needs to generate proposals from its cluster model
+// to execute the task of the CruiseControlOperation
+func requiresCCModel(operation *banzaiv1alpha1.CruiseControlOperation) bool {
+	switch operation.CurrentTaskOperation() {
+	case banzaiv1alpha1.OperationAddBroker, banzaiv1alpha1.OperationRemoveBroker, banzaiv1alpha1.OperationRebalance,
+		banzaiv1alpha1.OperationFixOfflineReplicas:
+		return true
+	}
+	return false
+}
+
+// holdBackForCCModel returns the operations waiting for execution which can be submitted according to the state of
+// the Cruise Control model and whether any of them has been held back. The held back operations are put into the
+// WaitingForCCModel state with the reason published, the state is cleared when the model becomes ready. The wait
+// states set by the other gates are left intact.
+func (r *CruiseControlOperationReconciler) holdBackForCCModel(ctx context.Context, ccOperations []*banzaiv1alpha1.CruiseControlOperation,
+	status scale.CruiseControlStatus) ([]*banzaiv1alpha1.CruiseControlOperation, bool, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	var admitted []*banzaiv1alpha1.CruiseControlOperation
+	var heldBack bool
+	reason := status.ModelNotReadyReason()
+	for _, ccOperation := range ccOperations {
+		if reason == "" || !requiresCCModel(ccOperation) {
+			if ccOperation.Status.WaitState == banzaiv1alpha1.WaitStateWaitingForCCModel {
+				if err := r.setWaitState(ctx, ccOperation, "", ""); err != nil {
+					return nil, false, err
+				}
+			}
+			admitted = append(admitted, ccOperation)
+			continue
+		}
+
+		if err := r.setWaitState(ctx, ccOperation, banzaiv1alpha1.WaitStateWaitingForCCModel, reason); err != nil {
+			return nil, false, err
+		}
+		log.Info("CruiseControlOperation is held back until the Cruise Control model is ready", "name", ccOperation.GetName(),
+			"namespace", ccOperation.GetNamespace(), "reason", reason)
+		heldBack = true
+	}
+	return admitted, heldBack, nil
+}
+
+// setWaitState updates the wait state and the reason in the status of the CruiseControlOperation when they change,
+// an event is emitted when the operation enters a wait state
+func (r *CruiseControlOperationReconciler) setWaitState(ctx context.Context, ccOperation *banzaiv1alpha1.CruiseControlOperation,
+	waitState banzaiv1alpha1.CruiseControlOperationWaitState, waitReason string) error {
+	if ccOperation.Status.WaitState == waitState && ccOperation.Status.WaitReason == waitReason {
+		return nil
+	}
+	entered := waitState != "" && ccOperation.Status.WaitState != waitState
+	ccOperation.Status.WaitState = waitState
+	ccOperation.Status.WaitReason = waitReason
+	if err := r.Status().Update(ctx, ccOperation); err != nil {
+		return errors.WrapIfWithDetails(err, "could not update CruiseControlOperation status",
+			"name", ccOperation.GetName(), "namespace", ccOperation.GetNamespace())
+	}
+	if entered && r.Recorder != nil {
+		r.Recorder.Event(ccOperation, corev1.EventTypeNormal, string(waitState), waitReason)
+	}
+	return nil
+}
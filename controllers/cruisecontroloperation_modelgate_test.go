@@ -0,0 +1,77 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+func TestHoldBackForCCModel(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	newOperation := func(name string, operation v1alpha1.CruiseControlTaskOperation) *v1alpha1.CruiseControlOperation {
+		return &v1alpha1.CruiseControlOperation{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kafka", Name: name},
+			Status: v1alpha1.CruiseControlOperationStatus{
+				CurrentTask: &v1alpha1.CruiseControlTask{Operation: operation},
+			},
+		}
+	}
+	rebalance := newOperation("rebalance", v1alpha1.OperationRebalance)
+	demote := newOperation("demote", v1alpha1.OperationDemoteBroker)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rebalance, demote).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &CruiseControlOperationReconciler{Client: c, Recorder: recorder}
+
+	notReady := scale.CruiseControlStatus{MonitorReady: true, MonitoredWindows: 1, ValidPartitionsPercentage: 42}
+	admitted, heldBack, err := r.holdBackForCCModel(ctx, []*v1alpha1.CruiseControlOperation{rebalance, demote}, notReady)
+	require.NoError(t, err)
+	assert.True(t, heldBack)
+	assert.Equal(t, []*v1alpha1.CruiseControlOperation{demote}, admitted)
+
+	stored := &v1alpha1.CruiseControlOperation{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "rebalance"}, stored))
+	assert.Equal(t, v1alpha1.WaitStateWaitingForCCModel, stored.Status.WaitState)
+	assert.Equal(t, "Cruise Control has not collected enough metrics to generate proposals (monitored windows: 1, valid partitions: 42.0%)", stored.Status.WaitReason)
+	assert.Len(t, recorder.Events, 1)
+
+	// the event is emitted only when the operation enters the state
+	_, _, err = r.holdBackForCCModel(ctx, []*v1alpha1.CruiseControlOperation{rebalance}, notReady)
+	require.NoError(t, err)
+	assert.Len(t, recorder.Events, 1)
+
+	ready := scale.CruiseControlStatus{MonitorReady: true, AnalyzerReady: true, ProposalReady: true, GoalsReady: true}
+	admitted, heldBack, err = r.holdBackForCCModel(ctx, []*v1alpha1.CruiseControlOperation{rebalance, demote}, ready)
+	require.NoError(t, err)
+	assert.False(t, heldBack)
+	assert.Len(t, admitted, 2)
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "rebalance"}, stored))
+	assert.Empty(t, stored.Status.WaitState)
+	assert.Empty(t, stored.Status.WaitReason)
+}
@@ -0,0 +1,74 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+const (
+	// maxReportedPartitionMovements limits the number of the partition movements in progress listed in the status
+	// of the CruiseControlOperation to keep its size bounded
+	maxReportedPartitionMovements = 50
+)
+
+// updatePartitionMovements mirrors the partition movements of the execution of Cruise Control into the status of the
+// CruiseControlOperation when its task is the one being executed. The movements in progress are dropped from the
+// status of the other operations as they are not moving partitions anymore.
+func updatePartitionMovements(operation *banzaiv1alpha1.CruiseControlOperation, status scale.CruiseControlStatus) {
+	task := operation.CurrentTask()
+	if task == nil {
+		return
+	}
+	if task.ID == "" || task.ID != status.ExecutingUserTaskID || !status.InExecution() {
+		if task.PartitionMovements != nil {
+			task.PartitionMovements.InProgress = nil
+			task.PartitionMovements.InProgressTopics = nil
+		}
+		return
+	}
+
+	movements := &banzaiv1alpha1.CruiseControlTaskPartitionMovements{
+		Total:            status.PartitionMovements.Total,
+		Pending:          status.PartitionMovements.Pending,
+		Finished:         status.PartitionMovements.Finished,
+		InProgressTopics: inProgressTopics(status.PartitionMovements.InProgress),
+	}
+	for i, movement := range status.PartitionMovements.InProgress {
+		if i == maxReportedPartitionMovements {
+			break
+		}
+		movements.InProgress = append(movements.InProgress, banzaiv1alpha1.PartitionMovement{
+			Topic:       movement.Topic,
+			Partition:   movement.Partition,
+			OldReplicas: movement.OldReplicas,
+			NewReplicas: movement.NewReplicas,
+		})
+	}
+	task.PartitionMovements = movements
+}
+
+// inProgressTopics returns the number of the partitions being moved keyed by the name of their topic
+func inProgressTopics(movements []scale.PartitionMovement) map[string]int32 {
+	if len(movements) == 0 {
+		return nil
+	}
+	topics := make(map[string]int32)
+	for _, movement := range movements {
+		topics[movement.Topic]++
+	}
+	return topics
+}
@@ -0,0 +1,82 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+func TestUpdatePartitionMovements(t *testing.T) {
+	operation := &v1alpha1.CruiseControlOperation{
+		Status: v1alpha1.CruiseControlOperationStatus{
+			CurrentTask: &v1alpha1.CruiseControlTask{
+				ID:        "task-id",
+				Operation: v1alpha1.OperationRemoveBroker,
+				State:     v1beta1.CruiseControlTaskInExecution,
+			},
+		},
+	}
+	status := scale.CruiseControlStatus{
+		ExecutorReady:       false,
+		ExecutingUserTaskID: "task-id",
+		PartitionMovements: scale.PartitionMovements{
+			Total:    10,
+			Pending:  6,
+			Finished: 1,
+			InProgress: []scale.PartitionMovement{
+				{Topic: "orders", Partition: 0, OldReplicas: []int32{0, 1}, NewReplicas: []int32{1, 2}},
+				{Topic: "orders", Partition: 3, OldReplicas: []int32{0, 2}, NewReplicas: []int32{2, 1}},
+				{Topic: "payments", Partition: 1, OldReplicas: []int32{0}, NewReplicas: []int32{2}},
+			},
+		},
+	}
+
+	updatePartitionMovements(operation, status)
+	assert.Equal(t, &v1alpha1.CruiseControlTaskPartitionMovements{
+		Total:            10,
+		Pending:          6,
+		Finished:         1,
+		InProgressTopics: map[string]int32{"orders": 2, "payments": 1},
+		InProgress: []v1alpha1.PartitionMovement{
+			{Topic: "orders", Partition: 0, OldReplicas: []int32{0, 1}, NewReplicas: []int32{1, 2}},
+			{Topic: "orders", Partition: 3, OldReplicas: []int32{0, 2}, NewReplicas: []int32{2, 1}},
+			{Topic: "payments", Partition: 1, OldReplicas: []int32{0}, NewReplicas: []int32{2}},
+		},
+	}, operation.Status.CurrentTask.PartitionMovements)
+
+	// the list of the movements in progress is truncated
+	status.PartitionMovements.InProgress = nil
+	for i := 0; i < maxReportedPartitionMovements+5; i++ {
+		status.PartitionMovements.InProgress = append(status.PartitionMovements.InProgress, scale.PartitionMovement{Topic: fmt.Sprintf("topic-%d", i%2)})
+	}
+	updatePartitionMovements(operation, status)
+	assert.Len(t, operation.Status.CurrentTask.PartitionMovements.InProgress, maxReportedPartitionMovements)
+	assert.Equal(t, map[string]int32{"topic-0": 28, "topic-1": 27}, operation.Status.CurrentTask.PartitionMovements.InProgressTopics)
+
+	// the movements of the task in progress are dropped when another task is being executed
+	status.ExecutingUserTaskID = "other-task-id"
+	updatePartitionMovements(operation, status)
+	movements := operation.Status.CurrentTask.PartitionMovements
+	assert.Nil(t, movements.InProgress)
+	assert.Nil(t, movements.InProgressTopics)
+	assert.Equal(t, int32(10), movements.Total)
+}
@@ -0,0 +1,78 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"emperror.dev/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaiv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+// cruiseControlServerKey identifies the Cruise Control instance serving the Kafka cluster. Several KafkaClusters share
+// a Cruise Control when their Cruise Control endpoints point to the same server, e.g. to the service of the Cruise
+// Control deployed for one of them.
+func cruiseControlServerKey(cluster *banzaiv1beta1.KafkaCluster) string {
+	u, err := url.Parse(scale.CruiseControlURLFromKafkaCluster(cluster))
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	// The in-cluster service of Cruise Control can be addressed with or without the cluster domain
+	host = strings.TrimSuffix(host, "."+strings.ToLower(cluster.Spec.GetKubernetesClusterDomain()))
+	host = strings.TrimSuffix(host, ".svc")
+	return host + ":" + u.Port()
+}
+
+// sharedCruiseControlClusters returns the references of the Kafka clusters served by the same Cruise Control as the
+// Kafka cluster, the cluster itself included
+func (r *CruiseControlOperationReconciler) sharedCruiseControlClusters(ctx context.Context, kafkaCluster *banzaiv1beta1.KafkaCluster) (map[client.ObjectKey]bool, error) {
+	shared := map[client.ObjectKey]bool{client.ObjectKeyFromObject(kafkaCluster): true}
+	kafkaClusters := &banzaiv1beta1.KafkaClusterList{}
+	if err := r.List(ctx, kafkaClusters); err != nil {
+		return nil, errors.WrapIf(err, "could not list KafkaClusters")
+	}
+	key := cruiseControlServerKey(kafkaCluster)
+	for i := range kafkaClusters.Items {
+		if cruiseControlServerKey(&kafkaClusters.Items[i]) == key {
+			shared[client.ObjectKeyFromObject(&kafkaClusters.Items[i])] = true
+		}
+	}
+	return shared, nil
+}
+
+// sharedCruiseControlInProgress returns the CruiseControlOperation of another Kafka cluster being executed by the
+// shared Cruise Control. Cruise Control executes a single task at a time so the operations of the Kafka clusters
+// sharing it are executed one after the other.
+func sharedCruiseControlInProgress(ccOperations []banzaiv1alpha1.CruiseControlOperation, sharedClusters map[client.ObjectKey]bool,
+	kafkaClusterRef client.ObjectKey) *banzaiv1alpha1.CruiseControlOperation {
+	for i := range ccOperations {
+		ccOperation := &ccOperations[i]
+		ref, err := kafkaClusterReference(ccOperation)
+		if err != nil || ref == kafkaClusterRef || !sharedClusters[ref] {
+			continue
+		}
+		if ccOperation.IsCurrentTaskOperationValid() && !ccOperation.IsDone() && ccOperation.IsInProgress() {
+			return ccOperation
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestCruiseControlServerKey(t *testing.T) {
+	owner := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	viaFQDN := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "tenant-a"}}
+	viaFQDN.Spec.CruiseControlConfig.CruiseControlEndpoint = "kafka-cruisecontrol-svc.kafka.svc.cluster.local:8090"
+	viaShortName := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "tenant-b", Namespace: "tenant-b"}}
+	viaShortName.Spec.CruiseControlConfig.CruiseControlEndpoint = "kafka-cruisecontrol-svc.kafka:8090"
+	other := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "kafka"}}
+
+	assert.Equal(t, "kafka-cruisecontrol-svc.kafka:8090", cruiseControlServerKey(owner))
+	assert.Equal(t, cruiseControlServerKey(owner), cruiseControlServerKey(viaFQDN))
+	assert.Equal(t, cruiseControlServerKey(owner), cruiseControlServerKey(viaShortName))
+	assert.NotEqual(t, cruiseControlServerKey(owner), cruiseControlServerKey(other))
+}
+
+func TestSharedCruiseControlInProgress(t *testing.T) {
+	newOperation := func(name, clusterRef, taskID string, state v1beta1.CruiseControlUserTaskState) v1alpha1.CruiseControlOperation {
+		return v1alpha1.CruiseControlOperation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "kafka",
+				Labels:    map[string]string{v1beta1.KafkaCRLabelKey: clusterRef},
+			},
+			Status: v1alpha1.CruiseControlOperationStatus{
+				CurrentTask: &v1alpha1.CruiseControlTask{Operation: v1alpha1.OperationRebalance, ID: taskID, State: state},
+			},
+		}
+	}
+	kafkaClusterRef := client.ObjectKey{Name: "kafka", Namespace: "kafka"}
+	sharedClusters := map[client.ObjectKey]bool{
+		kafkaClusterRef:                      true,
+		{Name: "tenant", Namespace: "kafka"}: true,
+	}
+
+	operations := []v1alpha1.CruiseControlOperation{
+		newOperation("own", "kafka", "1", v1beta1.CruiseControlTaskInExecution),
+		newOperation("unrelated", "other", "2", v1beta1.CruiseControlTaskInExecution),
+		newOperation("tenant-done", "tenant", "3", v1beta1.CruiseControlTaskCompleted),
+	}
+	assert.Nil(t, sharedCruiseControlInProgress(operations, sharedClusters, kafkaClusterRef))
+
+	operations = append(operations, newOperation("tenant-active", "tenant", "4", v1beta1.CruiseControlTaskActive))
+	inProgress := sharedCruiseControlInProgress(operations, sharedClusters, kafkaClusterRef)
+	if assert.NotNil(t, inProgress) {
+		assert.Equal(t, "tenant-active", inProgress.GetName())
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"math"
+	"sort"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+)
+
+// maxSimulationTopics is the maximum number of topics listed in the scale-down simulation, the topics with the most
+// replica movements are kept
+const maxSimulationTopics = 100
+
+// isScaleDownDryRun returns true when the CruiseControlOperation only simulates the removal of brokers
+func isScaleDownDryRun(operation *banzaiv1alpha1.CruiseControlOperation) bool {
+	return operation.CurrentTaskOperation() == banzaiv1alpha1.OperationRemoveBroker &&
+		operation.CurrentTaskParameters()[banzaiv1alpha1.ParamDryRun] == "true"
+}
+
+// scaleDownSimulation returns the replica movements per topic and the projected load of the remaining brokers
+// computed from the proposals of the dry run of a remove_broker task
+func scaleDownSimulation(res *types.OptimizationResult, removedBrokerIDs []int32) *banzaiv1alpha1.ScaleDownSimulation {
+	if res == nil {
+		return nil
+	}
+	simulation := &banzaiv1alpha1.ScaleDownSimulation{DataToMoveMB: res.Summary.DataToMoveMB}
+
+	movements := make(map[string]*banzaiv1alpha1.TopicReplicaMovements)
+	targets := make(map[string]map[int32]struct{})
+	for _, proposal := range res.Proposals {
+		topic := proposal.TopicPartition.Topic
+		for _, brokerID := range proposal.NewReplicas {
+			if containsBrokerID(proposal.OldReplicas, brokerID) {
+				continue
+			}
+			movement, ok := movements[topic]
+			if !ok {
+				movement = &banzaiv1alpha1.TopicReplicaMovements{Topic: topic}
+				movements[topic] = movement
+				targets[topic] = make(map[int32]struct{})
+			}
+			movement.ReplicaMovements++
+			targets[topic][brokerID] = struct{}{}
+			simulation.ReplicaMovements++
+		}
+	}
+	for topic, movement := range movements {
+		movement.TargetBrokers = sortedBrokerIDs(targets[topic])
+		simulation.Topics = append(simulation.Topics, *movement)
+	}
+	if len(simulation.Topics) > maxSimulationTopics {
+		sort.Slice(simulation.Topics, func(i, j int) bool {
+			return simulation.Topics[i].ReplicaMovements > simulation.Topics[j].ReplicaMovements
+		})
+		simulation.OmittedTopics = int32(len(simulation.Topics) - maxSimulationTopics)
+		simulation.Topics = simulation.Topics[:maxSimulationTopics]
+	}
+	sort.Slice(simulation.Topics, func(i, j int) bool { return simulation.Topics[i].Topic < simulation.Topics[j].Topic })
+
+	for _, broker := range res.LoadAfterOptimization.Brokers {
+		if containsBrokerID(removedBrokerIDs, broker.Broker) {
+			continue
+		}
+		simulation.Brokers = append(simulation.Brokers, banzaiv1alpha1.BrokerProjectedLoad{
+			BrokerID:         broker.Broker,
+			Replicas:         broker.Replicas,
+			Leaders:          broker.Leaders,
+			DiskMB:           int64(math.Round(broker.DiskMB)),
+			DiskCapacityMB:   int64(math.Round(broker.DiskCapacityMB)),
+			DiskUsagePercent: int32(math.Ceil(broker.DiskPct)),
+		})
+	}
+	sort.Slice(simulation.Brokers, func(i, j int) bool { return simulation.Brokers[i].BrokerID < simulation.Brokers[j].BrokerID })
+	return simulation
+}
+
+func containsBrokerID(brokerIDs []int32, brokerID int32) bool {
+	for _, id := range brokerIDs {
+		if id == brokerID {
+			return true
+		}
+	}
+	return false
+}
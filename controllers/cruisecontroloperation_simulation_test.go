@@ -0,0 +1,90 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+)
+
+func TestScaleDownSimulation(t *testing.T) {
+	res := &types.OptimizationResult{
+		Summary: types.OptimizerResult{NumReplicaMovements: 3, DataToMoveMB: 1200},
+		Proposals: []types.ExecutionProposal{
+			{TopicPartition: types.TopicPartition{Topic: "orders", Partition: 0}, OldReplicas: []int32{2, 0}, NewReplicas: []int32{1, 0}},
+			{TopicPartition: types.TopicPartition{Topic: "orders", Partition: 1}, OldReplicas: []int32{0, 2}, NewReplicas: []int32{0, 3}},
+			// only the leadership is moved
+			{TopicPartition: types.TopicPartition{Topic: "payments", Partition: 0}, OldReplicas: []int32{2, 1}, NewReplicas: []int32{1, 2}},
+			{TopicPartition: types.TopicPartition{Topic: "audit", Partition: 0}, OldReplicas: []int32{2}, NewReplicas: []int32{3}},
+		},
+		LoadAfterOptimization: types.BrokerStats{Brokers: []types.BrokerLoadStats{
+			{Broker: 3, Replicas: 20, Leaders: 10, DiskMB: 800.4, DiskCapacityMB: 1000, DiskPct: 80.04},
+			{Broker: 2, Replicas: 0, Leaders: 0},
+			{Broker: 1, Replicas: 18, Leaders: 9, DiskMB: 700, DiskCapacityMB: 1000, DiskPct: 70},
+		}},
+	}
+
+	simulation := scaleDownSimulation(res, []int32{2})
+	assert.Equal(t, &v1alpha1.ScaleDownSimulation{
+		ReplicaMovements: 3,
+		DataToMoveMB:     1200,
+		Topics: []v1alpha1.TopicReplicaMovements{
+			{Topic: "audit", ReplicaMovements: 1, TargetBrokers: []int32{3}},
+			{Topic: "orders", ReplicaMovements: 2, TargetBrokers: []int32{1, 3}},
+		},
+		Brokers: []v1alpha1.BrokerProjectedLoad{
+			{BrokerID: 1, Replicas: 18, Leaders: 9, DiskMB: 700, DiskCapacityMB: 1000, DiskUsagePercent: 70},
+			{BrokerID: 3, Replicas: 20, Leaders: 10, DiskMB: 800, DiskCapacityMB: 1000, DiskUsagePercent: 81},
+		},
+	}, simulation)
+
+	assert.Nil(t, scaleDownSimulation(nil, []int32{2}))
+}
+
+func TestScaleDownSimulationOmitsTopics(t *testing.T) {
+	res := &types.OptimizationResult{}
+	for i := 0; i <= maxSimulationTopics; i++ {
+		res.Proposals = append(res.Proposals, types.ExecutionProposal{
+			TopicPartition: types.TopicPartition{Topic: fmt.Sprintf("topic-%03d", i)}, OldReplicas: []int32{2}, NewReplicas: []int32{1},
+		})
+	}
+	// the topic with the most movements is kept
+	res.Proposals = append(res.Proposals, types.ExecutionProposal{
+		TopicPartition: types.TopicPartition{Topic: "topic-000", Partition: 1}, OldReplicas: []int32{2}, NewReplicas: []int32{1},
+	})
+
+	simulation := scaleDownSimulation(res, []int32{2})
+	assert.Len(t, simulation.Topics, maxSimulationTopics)
+	assert.Equal(t, int32(1), simulation.OmittedTopics)
+	assert.Equal(t, v1alpha1.TopicReplicaMovements{Topic: "topic-000", ReplicaMovements: 2, TargetBrokers: []int32{1}}, simulation.Topics[0])
+}
+
+func TestIsScaleDownDryRun(t *testing.T) {
+	operation := &v1alpha1.CruiseControlOperation{Status: v1alpha1.CruiseControlOperationStatus{
+		CurrentTask: &v1alpha1.CruiseControlTask{Operation: v1alpha1.OperationRemoveBroker,
+			Parameters: map[string]string{v1alpha1.ParamBrokerID: "2", v1alpha1.ParamDryRun: "true"}},
+	}}
+	assert.True(t, isScaleDownDryRun(operation))
+	assert.Equal(t, []int32{2}, operationBrokerIDs(operation))
+
+	operation.Status.CurrentTask.Parameters[v1alpha1.ParamDryRun] = "false"
+	assert.False(t, isScaleDownDryRun(operation))
+}
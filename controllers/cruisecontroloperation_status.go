@@ -0,0 +1,163 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaiv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+const (
+	ccOperationResultConfigMapKey   = "result.json.gz"
+	ccOperationResultConfigMapLabel = "cruisecontroloperation"
+	truncatedSuffix                 = "... (truncated)"
+)
+
+// boundStatus truncates the fields of the CruiseControlOperation tasks which can grow without limits and
+// compacts the failed tasks history when it is enabled.
+func boundStatus(operation *banzaiv1alpha1.CruiseControlOperation, limits *banzaiv1beta1.CruiseControlOperationStatusLimits) {
+	if task := operation.CurrentTask(); task != nil {
+		boundTask(task, limits)
+	}
+	for i := range operation.Status.FailedTasks {
+		task := &operation.Status.FailedTasks[i]
+		boundTask(task, limits)
+		if limits != nil && limits.CompactFailedTasks {
+			task.Summary = nil
+			task.Simulation = nil
+			task.HTTPRequest = ""
+		}
+	}
+}
+
+// failedTasksHistoryLimits returns the maximum length and age of the failedTasks history. The limits are taken from
+// the first level setting them, the levels are given in the order of precedence.
+func failedTasksHistoryLimits(levels ...*banzaiv1beta1.FailedTasksHistoryLimits) (int, time.Duration) {
+	maxLength, maxAge := defaultFailedTasksHistoryMaxLength, time.Duration(0)
+	lengthSet, ageSet := false, false
+	for _, level := range levels {
+		if level == nil {
+			continue
+		}
+		if !lengthSet && level.MaxLength != nil {
+			maxLength, lengthSet = int(*level.MaxLength), true
+		}
+		if !ageSet && level.MaxAgeSeconds != nil {
+			maxAge, ageSet = time.Duration(*level.MaxAgeSeconds)*time.Second, true
+		}
+	}
+	return maxLength, maxAge
+}
+
+// pruneFailedTasks drops the failed tasks which finished longer ago than maxAge and then the oldest ones exceeding
+// maxLength from the failedTasks history. Zero limits are not applied.
+func pruneFailedTasks(operation *banzaiv1alpha1.CruiseControlOperation, maxLength int, maxAge time.Duration, now time.Time) {
+	failedTasks := operation.Status.FailedTasks
+	if maxAge > 0 {
+		kept := failedTasks[:0]
+		for _, task := range failedTasks {
+			if task.Finished == nil || now.Sub(task.Finished.Time) <= maxAge {
+				kept = append(kept, task)
+			}
+		}
+		failedTasks = kept
+	}
+	if maxLength > 0 && len(failedTasks) > maxLength {
+		failedTasks = failedTasks[len(failedTasks)-maxLength:]
+	}
+	if len(failedTasks) == 0 {
+		failedTasks = nil
+	}
+	operation.Status.FailedTasks = failedTasks
+}
+
+func boundTask(task *banzaiv1alpha1.CruiseControlTask, limits *banzaiv1beta1.CruiseControlOperationStatusLimits) {
+	task.ErrorMessage = truncate(task.ErrorMessage, limits.GetMaxErrorMessageLength())
+	for key, value := range task.Summary {
+		task.Summary[key] = truncate(value, limits.GetMaxSummaryValueLength())
+	}
+}
+
+// truncate shortens the value to be at most maxLength bytes long without splitting multi-byte characters
+func truncate(value string, maxLength int) string {
+	if len(value) <= maxLength {
+		return value
+	}
+	suffix := truncatedSuffix
+	if maxLength <= len(suffix) {
+		suffix = ""
+	}
+	cut := maxLength - len(suffix)
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut] + suffix
+}
+
+// spillResult stores the gzip compressed optimization result of the Cruise Control user task in a ConfigMap which
+// is owned by the CruiseControlOperation and returns the name of the ConfigMap.
+func (r *CruiseControlOperationReconciler) spillResult(ctx context.Context, operation *banzaiv1alpha1.CruiseControlOperation,
+	taskID string, result *types.OptimizationResult) (string, error) {
+	rawResult, err := json.Marshal(result)
+	if err != nil {
+		return "", errors.WrapIf(err, "could not marshal Cruise Control optimization result")
+	}
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	if _, err = gzipWriter.Write(rawResult); err != nil {
+		return "", errors.WrapIf(err, "could not compress Cruise Control optimization result")
+	}
+	if err = gzipWriter.Close(); err != nil {
+		return "", errors.WrapIf(err, "could not compress Cruise Control optimization result")
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", operation.GetName(), taskID),
+			Namespace: operation.GetNamespace(),
+			Labels: map[string]string{
+				banzaiv1beta1.KafkaCRLabelKey:   operation.GetClusterRef(),
+				ccOperationResultConfigMapLabel: operation.GetName(),
+			},
+		},
+		BinaryData: map[string][]byte{
+			ccOperationResultConfigMapKey: compressed.Bytes(),
+		},
+	}
+	if err = controllerutil.SetControllerReference(operation, configMap, r.Scheme); err != nil {
+		return "", errors.WrapIf(err, "could not set owner reference of the ConfigMap")
+	}
+	if err = r.Create(ctx, configMap); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", errors.WrapIfWithDetails(err, "could not create ConfigMap for Cruise Control optimization result",
+			"configmap", configMap.GetName())
+	}
+	return configMap.GetName(), nil
+}
@@ -0,0 +1,127 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestBoundStatus(t *testing.T) {
+	longMessage := strings.Repeat("x", 5000)
+	newOperation := func() *v1alpha1.CruiseControlOperation {
+		return &v1alpha1.CruiseControlOperation{
+			Status: v1alpha1.CruiseControlOperationStatus{
+				CurrentTask: &v1alpha1.CruiseControlTask{
+					ErrorMessage: longMessage,
+					Summary:      map[string]string{"Provision recommendation": longMessage},
+				},
+				FailedTasks: []v1alpha1.CruiseControlTask{
+					{
+						ErrorMessage: "failed",
+						HTTPRequest:  "http://cruisecontrol/rebalance",
+						Summary:      map[string]string{"Data to move": "10"},
+					},
+				},
+			},
+		}
+	}
+
+	operation := newOperation()
+	boundStatus(operation, nil)
+	assert.Len(t, operation.CurrentTask().ErrorMessage, v1beta1.DefaultCruiseControlOperationMaxErrorMessageLength)
+	assert.True(t, strings.HasSuffix(operation.CurrentTask().ErrorMessage, truncatedSuffix))
+	assert.Len(t, operation.CurrentTask().Summary["Provision recommendation"], v1beta1.DefaultCruiseControlOperationMaxSummaryValueLength)
+	assert.Equal(t, "10", operation.Status.FailedTasks[0].Summary["Data to move"])
+
+	operation = newOperation()
+	boundStatus(operation, &v1beta1.CruiseControlOperationStatusLimits{
+		MaxErrorMessageLength: util.Int32Pointer(100),
+		CompactFailedTasks:    true,
+	})
+	assert.Len(t, operation.CurrentTask().ErrorMessage, 100)
+	assert.Equal(t, "failed", operation.Status.FailedTasks[0].ErrorMessage)
+	assert.Nil(t, operation.Status.FailedTasks[0].Summary)
+	assert.Empty(t, operation.Status.FailedTasks[0].HTTPRequest)
+
+	assert.Equal(t, "ab", truncate("abc", 2))
+	assert.Equal(t, "a", truncate("aé", 2))
+}
+
+func TestFailedTasksHistoryLimits(t *testing.T) {
+	maxLength, maxAge := failedTasksHistoryLimits(nil, nil, &v1beta1.FailedTasksHistoryLimits{})
+	assert.Equal(t, defaultFailedTasksHistoryMaxLength, maxLength)
+	assert.Zero(t, maxAge)
+
+	maxLength, maxAge = failedTasksHistoryLimits(
+		&v1beta1.FailedTasksHistoryLimits{MaxLength: util.Int32Pointer(0)},
+		&v1beta1.FailedTasksHistoryLimits{MaxLength: util.Int32Pointer(10), MaxAgeSeconds: util.Int64Pointer(3600)},
+		&v1beta1.FailedTasksHistoryLimits{MaxLength: util.Int32Pointer(50), MaxAgeSeconds: util.Int64Pointer(60)},
+	)
+	assert.Equal(t, 0, maxLength)
+	assert.Equal(t, time.Hour, maxAge)
+}
+
+func TestPruneFailedTasks(t *testing.T) {
+	now := time.Now()
+	finishedAgo := func(d time.Duration) v1alpha1.CruiseControlTask {
+		return v1alpha1.CruiseControlTask{ID: d.String(), Finished: &metav1.Time{Time: now.Add(-d)}}
+	}
+	newOperation := func() *v1alpha1.CruiseControlOperation {
+		return &v1alpha1.CruiseControlOperation{
+			Status: v1alpha1.CruiseControlOperationStatus{
+				FailedTasks: []v1alpha1.CruiseControlTask{
+					finishedAgo(3 * time.Hour), finishedAgo(2 * time.Hour), finishedAgo(time.Hour), finishedAgo(time.Minute),
+				},
+			},
+		}
+	}
+
+	operation := newOperation()
+	pruneFailedTasks(operation, 0, 0, now)
+	assert.Len(t, operation.Status.FailedTasks, 4)
+
+	operation = newOperation()
+	pruneFailedTasks(operation, 2, 0, now)
+	assert.Equal(t, []string{"1h0m0s", "1m0s"}, failedTaskIDs(operation))
+
+	operation = newOperation()
+	pruneFailedTasks(operation, 0, 90*time.Minute, now)
+	assert.Equal(t, []string{"1h0m0s", "1m0s"}, failedTaskIDs(operation))
+
+	operation = newOperation()
+	pruneFailedTasks(operation, 1, 150*time.Minute, now)
+	assert.Equal(t, []string{"1m0s"}, failedTaskIDs(operation))
+
+	operation = newOperation()
+	pruneFailedTasks(operation, 10, time.Second, now)
+	assert.Nil(t, operation.Status.FailedTasks)
+}
+
+func failedTaskIDs(operation *v1alpha1.CruiseControlOperation) []string {
+	var ids []string
+	for _, task := range operation.Status.FailedTasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
@@ -0,0 +1,93 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaiv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// ccOperationSummary returns the aggregated view of the CruiseControlOperations of the Kafka cluster.
+// The completed CruiseControlOperations may already have been removed thus the last completed rebalance
+// of the previous summary is kept when no later one can be found.
+func ccOperationSummary(operations []banzaiv1alpha1.CruiseControlOperation, cluster types.NamespacedName,
+	previous *banzaiv1beta1.CruiseControlOperationSummary) *banzaiv1beta1.CruiseControlOperationSummary {
+	summary := &banzaiv1beta1.CruiseControlOperationSummary{}
+	if previous != nil && previous.LastCompletedRebalance != nil {
+		summary.LastCompletedRebalance = previous.LastCompletedRebalance.DeepCopy()
+	}
+
+	for i := range operations {
+		operation := &operations[i]
+		if operation.GetNamespace() != cluster.Namespace || operation.GetClusterRef() != cluster.Name ||
+			!operation.IsCurrentTaskOperationValid() {
+			continue
+		}
+
+		if operation.CurrentTaskOperation() == banzaiv1alpha1.OperationRebalance &&
+			operation.CurrentTaskState() == banzaiv1beta1.CruiseControlTaskCompleted {
+			if finished := operation.CurrentTaskFinished(); finished != nil &&
+				(summary.LastCompletedRebalance == nil || summary.LastCompletedRebalance.Before(finished)) {
+				summary.LastCompletedRebalance = finished.DeepCopy()
+			}
+		}
+
+		switch {
+		case operation.IsFinished():
+		case operation.CurrentTaskState() == banzaiv1beta1.CruiseControlTaskCompletedWithError:
+			summary.Failed++
+		case operation.IsDone():
+		case operation.IsCurrentTaskRunning():
+			summary.InProgress++
+			if summary.Executing == nil || operation.GetName() < summary.Executing.Name {
+				summary.Executing = &banzaiv1beta1.ExecutingCruiseControlOperation{
+					Name:      operation.GetName(),
+					Operation: string(operation.CurrentTaskOperation()),
+				}
+			}
+		default:
+			summary.Pending++
+		}
+	}
+	return summary
+}
+
+// updateCCOperationSummary stores the summary of the CruiseControlOperations in the status of the Kafka cluster
+// when it has been changed
+func (r *CruiseControlOperationReconciler) updateCCOperationSummary(ctx context.Context, kafkaCluster *banzaiv1beta1.KafkaCluster,
+	operations []banzaiv1alpha1.CruiseControlOperation) error {
+	summary := ccOperationSummary(operations, client.ObjectKeyFromObject(kafkaCluster), kafkaCluster.Status.CruiseControlOperations)
+	if reflect.DeepEqual(summary, kafkaCluster.Status.CruiseControlOperations) {
+		return nil
+	}
+
+	err := k8sutil.PatchKafkaClusterStatus(ctx, r.Client, kafkaCluster,
+		func(status *banzaiv1beta1.KafkaClusterStatus) {
+			status.CruiseControlOperations = summary
+		})
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not update CruiseControlOperation summary of the Kafka cluster",
+			"name", kafkaCluster.GetName(), "namespace", kafkaCluster.GetNamespace())
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func newSummaryTestOperation(name, cluster string, operationType v1alpha1.CruiseControlTaskOperation,
+	state v1beta1.CruiseControlUserTaskState, finished *metav1.Time) v1alpha1.CruiseControlOperation {
+	return v1alpha1.CruiseControlOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "kafka",
+			Labels:    map[string]string{v1beta1.KafkaCRLabelKey: cluster},
+		},
+		Spec: v1alpha1.CruiseControlOperationSpec{ErrorPolicy: v1alpha1.ErrorPolicyRetry},
+		Status: v1alpha1.CruiseControlOperationStatus{
+			CurrentTask: &v1alpha1.CruiseControlTask{
+				ID:        name,
+				Operation: operationType,
+				State:     state,
+				Finished:  finished,
+			},
+		},
+	}
+}
+
+func TestCCOperationSummary(t *testing.T) {
+	cluster := types.NamespacedName{Namespace: "kafka", Name: "kafka"}
+	earlier := metav1.NewTime(time.Unix(1680000000, 0))
+	later := metav1.NewTime(earlier.Add(time.Hour))
+
+	paused := newSummaryTestOperation("paused", "kafka", v1alpha1.OperationRemoveBroker, v1beta1.CruiseControlTaskCompletedWithError, &earlier)
+	paused.Labels["pause"] = "true"
+	operations := []v1alpha1.CruiseControlOperation{
+		newSummaryTestOperation("pending", "kafka", v1alpha1.OperationAddBroker, "", nil),
+		newSummaryTestOperation("rebalance-b", "kafka", v1alpha1.OperationRebalance, v1beta1.CruiseControlTaskInExecution, nil),
+		newSummaryTestOperation("rebalance-a", "kafka", v1alpha1.OperationRebalance, v1beta1.CruiseControlTaskActive, nil),
+		newSummaryTestOperation("retry", "kafka", v1alpha1.OperationAddBroker, v1beta1.CruiseControlTaskCompletedWithError, &earlier),
+		paused,
+		newSummaryTestOperation("completed", "kafka", v1alpha1.OperationRebalance, v1beta1.CruiseControlTaskCompleted, &earlier),
+		newSummaryTestOperation("other", "other", v1alpha1.OperationRebalance, v1beta1.CruiseControlTaskCompleted, &later),
+	}
+
+	summary := ccOperationSummary(operations, cluster, nil)
+	assert.Equal(t, &v1beta1.CruiseControlOperationSummary{
+		Pending:    1,
+		InProgress: 2,
+		Failed:     2,
+		Executing: &v1beta1.ExecutingCruiseControlOperation{
+			Name:      "rebalance-a",
+			Operation: string(v1alpha1.OperationRebalance),
+		},
+		LastCompletedRebalance: &earlier,
+	}, summary)
+
+	// The last completed rebalance is kept when its CruiseControlOperation has already been removed
+	summary = ccOperationSummary(nil, cluster, &v1beta1.CruiseControlOperationSummary{Pending: 3, LastCompletedRebalance: &later})
+	assert.Equal(t, &v1beta1.CruiseControlOperationSummary{LastCompletedRebalance: &later}, summary)
+}
+
+func TestUpdateCCOperationSummary(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	kafkaCluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kafkaCluster.DeepCopy()).Build()
+	r := CruiseControlOperationReconciler{Client: c}
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(kafkaCluster), kafkaCluster))
+
+	operations := []v1alpha1.CruiseControlOperation{
+		newSummaryTestOperation("pending", "kafka", v1alpha1.OperationAddBroker, "", nil),
+	}
+	assert.NoError(t, r.updateCCOperationSummary(context.Background(), kafkaCluster, operations))
+
+	stored := &v1beta1.KafkaCluster{}
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(kafkaCluster), stored))
+	assert.Equal(t, &v1beta1.CruiseControlOperationSummary{Pending: 1}, stored.Status.CruiseControlOperations)
+}
@@ -97,6 +97,7 @@ func SetupCruiseControlOperationTTLWithManager(mgr ctrl.Manager) *ctrl.Builder {
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&banzaiv1alpha1.CruiseControlOperation{}).
 		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
 		WithEventFilter(cruiseControlOperationTTLPredicate).
 		Named("CruiseControlOperationTTL")
 
@@ -0,0 +1,165 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlBuilder "sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaiv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+const cruiseControlAppLabelValue = "cruisecontrol"
+
+// ccOperationWakeUpWatches adds the watches enqueueing the pending CruiseControlOperations of a Kafka cluster as soon as
+// its Cruise Control becomes ready or the state of the Kafka cluster changes, instead of waiting for the next requeue.
+func ccOperationWakeUpWatches(builder *ctrl.Builder, c client.Reader, log logr.Logger) *ctrl.Builder {
+	mapper := ccOperationWakeUpMapper{
+		client: c,
+		log:    log,
+	}
+	return builder.
+		Watches(
+			&source.Kind{Type: &appsv1.Deployment{}},
+			handler.EnqueueRequestsFromMapFunc(mapper.mapCruiseControlDeployment),
+			ctrlBuilder.WithPredicates(cruiseControlDeploymentReadyFilter())).
+		Watches(
+			&source.Kind{Type: &banzaiv1beta1.KafkaCluster{}},
+			handler.EnqueueRequestsFromMapFunc(mapper.mapKafkaCluster),
+			ctrlBuilder.WithPredicates(kafkaClusterStatusChangedFilter()))
+}
+
+// cruiseControlDeploymentReadyFilter lets through only the updates of Cruise Control deployments which became ready
+func cruiseControlDeploymentReadyFilter() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectNew.GetLabels()[banzaiv1beta1.AppLabelKey] != cruiseControlAppLabelValue {
+				return false
+			}
+			oldDeployment, ok := e.ObjectOld.(*appsv1.Deployment)
+			if !ok {
+				return false
+			}
+			newDeployment, ok := e.ObjectNew.(*appsv1.Deployment)
+			if !ok {
+				return false
+			}
+			return !isDeploymentReady(oldDeployment) && isDeploymentReady(newDeployment)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// kafkaClusterStatusChangedFilter lets through only the updates of KafkaClusters which changed the cluster state or
+// the state of the Cruise Control topic
+func kafkaClusterStatusChangedFilter() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCluster, ok := e.ObjectOld.(*banzaiv1beta1.KafkaCluster)
+			if !ok {
+				return false
+			}
+			newCluster, ok := e.ObjectNew.(*banzaiv1beta1.KafkaCluster)
+			if !ok {
+				return false
+			}
+			return oldCluster.Status.State != newCluster.Status.State ||
+				oldCluster.Status.CruiseControlTopicStatus != newCluster.Status.CruiseControlTopicStatus
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+func isDeploymentReady(deployment *appsv1.Deployment) bool {
+	return deployment.Status.ObservedGeneration >= deployment.GetGeneration() &&
+		deployment.Status.ReadyReplicas > 0 &&
+		deployment.Status.UnavailableReplicas == 0
+}
+
+type ccOperationWakeUpMapper struct {
+	client client.Reader
+	log    logr.Logger
+}
+
+// mapCruiseControlDeployment maps Cruise Control deployment events to the pending CruiseControlOperations of its Kafka cluster
+func (m *ccOperationWakeUpMapper) mapCruiseControlDeployment(obj client.Object) []ctrl.Request {
+	clusterName, ok := obj.GetLabels()[banzaiv1beta1.KafkaCRLabelKey]
+	if !ok || clusterName == "" {
+		return []ctrl.Request{}
+	}
+	return m.pendingCCOperations(types.NamespacedName{Namespace: obj.GetNamespace(), Name: clusterName})
+}
+
+// mapKafkaCluster maps KafkaCluster events to its pending CruiseControlOperations
+func (m *ccOperationWakeUpMapper) mapKafkaCluster(obj client.Object) []ctrl.Request {
+	return m.pendingCCOperations(types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()})
+}
+
+func (m *ccOperationWakeUpMapper) pendingCCOperations(cluster types.NamespacedName) []ctrl.Request {
+	ccOperationList := &banzaiv1alpha1.CruiseControlOperationList{}
+	err := m.client.List(context.Background(), ccOperationList,
+		client.InNamespace(cluster.Namespace), client.MatchingLabels{banzaiv1beta1.KafkaCRLabelKey: cluster.Name})
+	if err != nil {
+		m.log.Error(err, "couldn't list CruiseControlOperations", "namespace", cluster.Namespace, "kafkaCluster", cluster.Name)
+		return []ctrl.Request{}
+	}
+
+	requests := make([]ctrl.Request, 0, len(ccOperationList.Items))
+	for i := range ccOperationList.Items {
+		operation := &ccOperationList.Items[i]
+		if operation.IsDone() || operation.IsPaused() || operation.CurrentTaskOperation() == "" {
+			continue
+		}
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: operation.GetNamespace(),
+				Name:      operation.GetName(),
+			},
+		})
+	}
+
+	if len(requests) > 0 {
+		// The woken up reconciles must not wait for the backoff of the previous failed health checks
+		ccHealth.wake(cluster)
+	}
+	return requests
+}
@@ -0,0 +1,69 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestPendingCCOperations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	newOperation := func(name, cluster string, state v1beta1.CruiseControlUserTaskState) *v1alpha1.CruiseControlOperation {
+		return &v1alpha1.CruiseControlOperation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "kafka",
+				Labels:    map[string]string{v1beta1.KafkaCRLabelKey: cluster},
+			},
+			Status: v1alpha1.CruiseControlOperationStatus{
+				CurrentTask: &v1alpha1.CruiseControlTask{
+					Operation: v1alpha1.OperationRebalance,
+					State:     state,
+				},
+			},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newOperation("pending", "kafka", ""),
+		newOperation("completed", "kafka", v1beta1.CruiseControlTaskCompleted),
+		newOperation("other-cluster", "other", ""),
+	).Build()
+
+	cluster := types.NamespacedName{Namespace: "kafka", Name: "kafka"}
+	ccHealth.failed(cluster, metav1.Now().Time)
+	defer ccHealth.succeeded(cluster)
+
+	mapper := ccOperationWakeUpMapper{client: c, log: logr.Discard()}
+	requests := mapper.mapKafkaCluster(&v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}})
+	assert.Equal(t, []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: "kafka", Name: "pending"}}}, requests)
+
+	// Waking up the pending operations lifts the backoff of the health check
+	probe, _ := ccHealth.acquire(cluster, metav1.Now().Time)
+	assert.True(t, probe)
+}
@@ -19,29 +19,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	apiutil "github.com/banzaicloud/koperator/api/util"
 	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
 	banzaiv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/ccoperation"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
 	koperatorccconf "github.com/banzaicloud/koperator/pkg/resources/cruisecontrol"
 	"github.com/banzaicloud/koperator/pkg/scale"
-	"github.com/banzaicloud/koperator/pkg/util"
 )
 
 const (
@@ -116,6 +116,7 @@ func (r *CruiseControlTaskReconciler) Reconcile(ctx context.Context, request ctr
 	if err != nil {
 		return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
 	}
+	ctx = withCCRequestTag(ctx, instance, "cruisecontroltask-reconcile")
 
 	operationTTLSecondsAfterFinished := instance.Spec.CruiseControlConfig.CruiseControlOperationSpec.GetTTLSecondsAfterFinished()
 
@@ -300,46 +301,27 @@ func (r *CruiseControlTaskReconciler) createCCOperation(
 	bokerIDs []string,
 	isJBOD bool,
 ) (corev1.LocalObjectReference, error) {
-	operation := &banzaiv1alpha1.CruiseControlOperation{
-		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: fmt.Sprintf("%s-%s-", kafkaCluster.Name, strings.ReplaceAll(string(operationType), "_", "")),
-			Namespace:    kafkaCluster.Namespace,
-			Labels:       apiutil.LabelsForKafka(kafkaCluster.Name),
-		},
-		Spec: banzaiv1alpha1.CruiseControlOperationSpec{
-			ErrorPolicy: errorPolicy,
-		},
-	}
-
-	if ttlSecondsAfterFinished != nil {
-		operation.Spec.TTLSecondsAfterFinished = ttlSecondsAfterFinished
-	}
-
-	if err := controllerutil.SetControllerReference(kafkaCluster, operation, r.Scheme); err != nil {
-		return corev1.LocalObjectReference{}, err
-	}
-	if err := r.Client.Create(ctx, operation); err != nil {
+	brokerIDs, err := brokerIDsToInt32Slice(bokerIDs)
+	if err != nil {
 		return corev1.LocalObjectReference{}, err
 	}
 
-	operation.Status.CurrentTask = &banzaiv1alpha1.CruiseControlTask{
-		Operation: operationType,
-		Parameters: map[string]string{
-			"exclude_recently_demoted_brokers": "true",
-			"exclude_recently_removed_brokers": "true",
-		},
-	}
+	ccOperationBuilder := ccoperation.New(operationType).
+		ForCluster(kafkaCluster).
+		ForBrokers(brokerIDs...).
+		WithErrorPolicy(errorPolicy).
+		ExcludeRecentlyChangedBrokers().
+		OwnedBy(kafkaCluster, r.Scheme)
 
-	if operationType == banzaiv1alpha1.OperationRebalance {
-		operation.Status.CurrentTask.Parameters["destination_broker_ids"] = strings.Join(bokerIDs, ",")
-		if isJBOD {
-			operation.Status.CurrentTask.Parameters["rebalance_disk"] = "true"
-		}
-	} else {
-		operation.Status.CurrentTask.Parameters["brokerid"] = strings.Join(bokerIDs, ",")
+	if ttlSecondsAfterFinished != nil {
+		ccOperationBuilder.WithTTLSecondsAfterFinished(*ttlSecondsAfterFinished)
+	}
+	if operationType == banzaiv1alpha1.OperationRebalance && isJBOD {
+		ccOperationBuilder.WithRebalanceDisk()
 	}
 
-	if err := r.Status().Update(ctx, operation); err != nil {
+	operation, err := ccOperationBuilder.Create(ctx, r.Client)
+	if err != nil {
 		return corev1.LocalObjectReference{}, err
 	}
 	return corev1.LocalObjectReference{
@@ -347,6 +329,19 @@ func (r *CruiseControlTaskReconciler) createCCOperation(
 	}, nil
 }
 
+// brokerIDsToInt32Slice converts the broker IDs from string to int32
+func brokerIDsToInt32Slice(brokerIDs []string) ([]int32, error) {
+	ids := make([]int32, 0, len(brokerIDs))
+	for _, brokerID := range brokerIDs {
+		id, err := strconv.ParseInt(brokerID, 10, 32)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not parse broker ID", "brokerID", brokerID)
+		}
+		ids = append(ids, int32(id))
+	}
+	return ids, nil
+}
+
 // brokersJBODSelector filters out the JBOD and not JBOD brokers from a broker list based on the capacityConfig
 func brokersJBODSelector(brokerIDs []string, capacityConfigJSON string) (brokersJBOD []string, brokersNotJBOD []string, err error) {
 	// JBOD is generated by default
@@ -417,30 +412,18 @@ func (r *CruiseControlTaskReconciler) UpdateStatus(ctx context.Context, instance
 	taskAndStates *CruiseControlTasksAndStates) error {
 	log := logr.FromContextOrDiscard(ctx)
 
-	currentStatus := instance.Status.DeepCopy()
-	taskAndStates.SyncState(instance)
-	if reflect.DeepEqual(*currentStatus, instance.Status) {
+	desired := instance.DeepCopy()
+	taskAndStates.SyncState(desired)
+	if reflect.DeepEqual(instance.Status, desired.Status) {
 		log.Info("there are no updates to apply to Kafka Cluster Status")
 		return nil
 	}
 
-	conflictRetryFunction := func() error {
-		log.Info("Updating status....")
-		err := r.Status().Update(ctx, instance)
-		if apiErrors.IsConflict(err) {
-			err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, instance)
-			if err != nil {
-				return errors.WithMessage(err, "failed to get updated Kafka Cluster CR before updating its status")
-			}
+	log.Info("Updating status....")
+	return k8sutil.PatchKafkaClusterStatus(ctx, r.Client, instance,
+		func(status *banzaiv1beta1.KafkaClusterStatus) {
 			taskAndStates.SyncState(instance)
-		}
-		return err
-	}
-	if err := util.RetryOnConflict(util.DefaultBackOffForConflict, conflictRetryFunction); err != nil {
-		return err
-	}
-
-	return nil
+		})
 }
 
 // SetupCruiseControlWithManager registers cruise control controller to the manager
@@ -456,6 +439,7 @@ func SetupCruiseControlWithManager(mgr ctrl.Manager) *ctrl.Builder {
 			newObj := e.ObjectNew.(*banzaiv1alpha1.CruiseControlOperation)
 			if !reflect.DeepEqual(oldObj.CurrentTask(), newObj.CurrentTask()) ||
 				oldObj.IsPaused() != newObj.IsPaused() ||
+				isFailureAcknowledgementChanged(oldObj, newObj) ||
 				oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
 				oldObj.GetGeneration() != newObj.GetGeneration() {
 				return true
@@ -483,6 +467,7 @@ func SetupCruiseControlWithManager(mgr ctrl.Manager) *ctrl.Builder {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&banzaiv1beta1.KafkaCluster{}).
 		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
 		WithEventFilter(kafkaClusterPredicate).
 		Owns(&banzaiv1alpha1.CruiseControlOperation{}, builder.WithPredicates(cruiseControlOperationPredicate)).
 		Named("CruiseControlTask")
@@ -501,6 +486,7 @@ func getActiveTasksFromCluster(instance *banzaiv1beta1.KafkaCluster) *CruiseCont
 				t := &CruiseControlTask{
 					BrokerID:                        brokerId,
 					BrokerState:                     state.CruiseControlState,
+					BrokerVolumes:                   brokerVolumes(instance, brokerId),
 					Operation:                       banzaiv1alpha1.OperationAddBroker,
 					CruiseControlOperationReference: brokerStatus.GracefulActionState.CruiseControlOperationReference,
 				}
@@ -509,6 +495,7 @@ func getActiveTasksFromCluster(instance *banzaiv1beta1.KafkaCluster) *CruiseCont
 				t := &CruiseControlTask{
 					BrokerID:                        brokerId,
 					BrokerState:                     state.CruiseControlState,
+					BrokerVolumes:                   brokerVolumes(instance, brokerId),
 					Operation:                       banzaiv1alpha1.OperationRemoveBroker,
 					CruiseControlOperationReference: brokerStatus.GracefulActionState.CruiseControlOperationReference,
 				}
@@ -532,6 +519,30 @@ func getActiveTasksFromCluster(instance *banzaiv1beta1.KafkaCluster) *CruiseCont
 	return tasksAndStates
 }
 
+// brokerVolumes returns the mount paths of the log dirs of the broker. The volumes of the brokers which have already
+// been removed from the spec are taken from their status.
+func brokerVolumes(instance *banzaiv1beta1.KafkaCluster, brokerId string) []string {
+	var mountPaths []string
+	for _, broker := range instance.Spec.Brokers {
+		if strconv.Itoa(int(broker.Id)) != brokerId {
+			continue
+		}
+		brokerConfig, err := broker.GetBrokerConfig(instance.Spec)
+		if err != nil {
+			break
+		}
+		for _, storageConfig := range brokerConfig.StorageConfigs {
+			mountPaths = append(mountPaths, storageConfig.MountPath)
+		}
+		return mountPaths
+	}
+	for mountPath := range instance.Status.BrokersState[brokerId].GracefulActionState.VolumeStates {
+		mountPaths = append(mountPaths, mountPath)
+	}
+	sort.Strings(mountPaths)
+	return mountPaths
+}
+
 // updateActiveTasks updates the state of the tasks from the CruiseControlTasksAndStates instance by getting their
 // status from CruiseControlOperation
 func updateActiveTasks(tasksAndStates *CruiseControlTasksAndStates, ccOperations []*banzaiv1alpha1.CruiseControlOperation) {
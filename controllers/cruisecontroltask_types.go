@@ -16,6 +16,7 @@ package controllers
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	koperatorv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
 	koperatorv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
@@ -23,8 +24,10 @@ import (
 
 // CruiseControlTask defines a task to be performed via Cruise Control.
 type CruiseControlTask struct {
-	BrokerID                        string
-	BrokerState                     koperatorv1beta1.CruiseControlState
+	BrokerID    string
+	BrokerState koperatorv1beta1.CruiseControlState
+	// BrokerVolumes are the mount paths of the log dirs of the broker which are moved by the upscale or downscale
+	BrokerVolumes                   []string
 	Volume                          string
 	VolumeState                     koperatorv1beta1.CruiseControlVolumeState
 	Operation                       koperatorv1alpha1.CruiseControlTaskOperation
@@ -53,17 +56,35 @@ func (t *CruiseControlTask) Apply(instance *koperatorv1beta1.KafkaCluster) {
 		return
 	}
 
+	now := metav1.Now()
 	// nolint:exhaustive // Note: Not all CC operations require updates to the KafkaCluster status.
 	switch t.Operation {
 	case koperatorv1alpha1.OperationAddBroker, koperatorv1alpha1.OperationRemoveBroker:
 		if state, ok := instance.Status.BrokersState[t.BrokerID]; ok {
 			state.GracefulActionState.CruiseControlState = t.BrokerState
 			state.GracefulActionState.CruiseControlOperationReference = t.CruiseControlOperationReference
+			// The volumes of the broker are moved together with the broker, recording the state for each of them
+			// makes the progress of multi-disk brokers observable in the status. The volumes which are not known
+			// yet get their state recorded, while the disk rebalance state of the others is kept.
+			if len(t.BrokerVolumes) > 0 && state.GracefulActionState.VolumeStates == nil {
+				state.GracefulActionState.VolumeStates = make(map[string]koperatorv1beta1.VolumeState, len(t.BrokerVolumes))
+			}
+			for _, mountPath := range t.BrokerVolumes {
+				volState, ok := state.GracefulActionState.VolumeStates[mountPath]
+				if !ok || volState.CruiseControlState != t.BrokerState {
+					volState.CruiseControlState = t.BrokerState
+					volState.LastTransitionTime = &now
+					state.GracefulActionState.VolumeStates[mountPath] = volState
+				}
+			}
 			instance.Status.BrokersState[t.BrokerID] = state
 		}
 	case koperatorv1alpha1.OperationRebalance:
 		if state, ok := instance.Status.BrokersState[t.BrokerID]; ok {
 			if volState, ok := state.GracefulActionState.VolumeStates[t.Volume]; ok {
+				if volState.CruiseControlVolumeState != t.VolumeState {
+					volState.LastTransitionTime = &now
+				}
 				volState.CruiseControlVolumeState = t.VolumeState
 				volState.CruiseControlOperationReference = t.CruiseControlOperationReference
 				instance.Status.BrokersState[t.BrokerID].GracefulActionState.VolumeStates[t.Volume] = volState
@@ -106,7 +127,7 @@ func (t *CruiseControlTask) FromResult(operation *koperatorv1alpha1.CruiseContro
 			t.BrokerState = koperatorv1beta1.GracefulUpscaleSucceeded
 		case operation.IsErrorPolicyIgnore() && operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskCompletedWithError:
 			t.BrokerState = koperatorv1beta1.GracefulUpscaleSucceeded
-		case operation.IsPaused() && operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskCompletedWithError:
+		case (operation.IsPaused() || operation.IsWaitingForAcknowledgement()) && operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskCompletedWithError:
 			t.BrokerState = koperatorv1beta1.GracefulUpscalePaused
 		case operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskActive, operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskInExecution:
 			t.BrokerState = koperatorv1beta1.GracefulUpscaleRunning
@@ -123,7 +144,7 @@ func (t *CruiseControlTask) FromResult(operation *koperatorv1alpha1.CruiseContro
 			t.BrokerState = koperatorv1beta1.GracefulDownscaleSucceeded
 		case operation.IsErrorPolicyIgnore() && operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskCompletedWithError:
 			t.BrokerState = koperatorv1beta1.GracefulDownscaleSucceeded
-		case operation.IsPaused() && operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskCompletedWithError:
+		case (operation.IsPaused() || operation.IsWaitingForAcknowledgement()) && operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskCompletedWithError:
 			t.BrokerState = koperatorv1beta1.GracefulDownscalePaused
 		case operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskActive, operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskInExecution:
 			t.BrokerState = koperatorv1beta1.GracefulDownscaleRunning
@@ -141,7 +162,7 @@ func (t *CruiseControlTask) FromResult(operation *koperatorv1alpha1.CruiseContro
 			t.VolumeState = koperatorv1beta1.GracefulDiskRebalanceSucceeded
 		case operation.IsErrorPolicyIgnore() && operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskCompletedWithError:
 			t.VolumeState = koperatorv1beta1.GracefulDiskRebalanceSucceeded
-		case operation.IsPaused() && operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskCompletedWithError:
+		case (operation.IsPaused() || operation.IsWaitingForAcknowledgement()) && operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskCompletedWithError:
 			t.VolumeState = koperatorv1beta1.GracefulDiskRebalancePaused
 		case operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskActive, operation.CurrentTaskState() == koperatorv1beta1.CruiseControlTaskInExecution:
 			t.VolumeState = koperatorv1beta1.GracefulDiskRebalanceRunning
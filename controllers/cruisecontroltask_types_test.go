@@ -0,0 +1,119 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	koperatorv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	koperatorv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestCruiseControlTaskApplyVolumeStates(t *testing.T) {
+	instance := &koperatorv1beta1.KafkaCluster{
+		Status: koperatorv1beta1.KafkaClusterStatus{
+			BrokersState: map[string]koperatorv1beta1.BrokerState{
+				"0": {
+					GracefulActionState: koperatorv1beta1.GracefulActionState{
+						CruiseControlState: koperatorv1beta1.GracefulUpscaleRequired,
+						VolumeStates: map[string]koperatorv1beta1.VolumeState{
+							"/kafka-logs-1": {CruiseControlVolumeState: koperatorv1beta1.GracefulDiskRebalanceRequired},
+							"/kafka-logs-2": {CruiseControlVolumeState: koperatorv1beta1.GracefulDiskRebalanceSucceeded},
+						},
+					},
+				},
+			},
+		},
+	}
+	ref := &corev1.LocalObjectReference{Name: "kafka-addbroker-abcde"}
+
+	upscale := &CruiseControlTask{
+		BrokerID:                        "0",
+		BrokerState:                     koperatorv1beta1.GracefulUpscaleRunning,
+		BrokerVolumes:                   []string{"/kafka-logs-1", "/kafka-logs-2", "/kafka-logs-3"},
+		Operation:                       koperatorv1alpha1.OperationAddBroker,
+		CruiseControlOperationReference: ref,
+	}
+	upscale.Apply(instance)
+
+	state := instance.Status.BrokersState["0"].GracefulActionState
+	assert.Equal(t, koperatorv1beta1.GracefulUpscaleRunning, state.CruiseControlState)
+	assert.Equal(t, ref, state.CruiseControlOperationReference)
+	for mountPath, volState := range state.VolumeStates {
+		assert.Equal(t, koperatorv1beta1.GracefulUpscaleRunning, volState.CruiseControlState, mountPath)
+		assert.NotNil(t, volState.LastTransitionTime, mountPath)
+		assert.Nil(t, volState.CruiseControlOperationReference, mountPath)
+	}
+	assert.Len(t, state.VolumeStates, 3)
+	assert.Equal(t, koperatorv1beta1.GracefulDiskRebalanceRequired, state.VolumeStates["/kafka-logs-1"].CruiseControlVolumeState)
+	assert.Empty(t, state.VolumeStates["/kafka-logs-3"].CruiseControlVolumeState)
+
+	transitionTime := state.VolumeStates["/kafka-logs-1"].LastTransitionTime
+	// Applying the same state again must not change the transition time
+	upscale.Apply(instance)
+	assert.Equal(t, transitionTime, instance.Status.BrokersState["0"].GracefulActionState.VolumeStates["/kafka-logs-1"].LastTransitionTime)
+
+	rebalanceRef := &corev1.LocalObjectReference{Name: "kafka-rebalance-abcde"}
+	rebalance := &CruiseControlTask{
+		BrokerID:                        "0",
+		Volume:                          "/kafka-logs-1",
+		VolumeState:                     koperatorv1beta1.GracefulDiskRebalanceScheduled,
+		Operation:                       koperatorv1alpha1.OperationRebalance,
+		CruiseControlOperationReference: rebalanceRef,
+	}
+	rebalance.Apply(instance)
+
+	volStates := instance.Status.BrokersState["0"].GracefulActionState.VolumeStates
+	assert.Equal(t, koperatorv1beta1.GracefulDiskRebalanceScheduled, volStates["/kafka-logs-1"].CruiseControlVolumeState)
+	assert.Equal(t, rebalanceRef, volStates["/kafka-logs-1"].CruiseControlOperationReference)
+	assert.Equal(t, koperatorv1beta1.GracefulUpscaleRunning, volStates["/kafka-logs-1"].CruiseControlState)
+	assert.Equal(t, koperatorv1beta1.GracefulDiskRebalanceSucceeded, volStates["/kafka-logs-2"].CruiseControlVolumeState)
+	assert.Nil(t, volStates["/kafka-logs-2"].CruiseControlOperationReference)
+}
+
+func TestCruiseControlTaskApplyWithoutVolumeStates(t *testing.T) {
+	instance := &koperatorv1beta1.KafkaCluster{
+		Spec: koperatorv1beta1.KafkaClusterSpec{
+			Brokers: []koperatorv1beta1.Broker{{
+				Id: 1,
+				BrokerConfig: &koperatorv1beta1.BrokerConfig{
+					StorageConfigs: []koperatorv1beta1.StorageConfig{{MountPath: "/kafka-logs-1"}, {MountPath: "/kafka-logs-2"}},
+				},
+			}},
+		},
+		Status: koperatorv1beta1.KafkaClusterStatus{
+			BrokersState: map[string]koperatorv1beta1.BrokerState{
+				"1": {GracefulActionState: koperatorv1beta1.GracefulActionState{CruiseControlState: koperatorv1beta1.GracefulDownscaleRequired}},
+			},
+		},
+	}
+
+	tasks := getActiveTasksFromCluster(instance).GetActiveTasksByOp(koperatorv1alpha1.OperationRemoveBroker)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, []string{"/kafka-logs-1", "/kafka-logs-2"}, tasks[0].BrokerVolumes)
+		tasks[0].BrokerState = koperatorv1beta1.GracefulDownscaleRunning
+		tasks[0].Apply(instance)
+	}
+
+	volStates := instance.Status.BrokersState["1"].GracefulActionState.VolumeStates
+	assert.Len(t, volStates, 2)
+	for mountPath, volState := range volStates {
+		assert.Equal(t, koperatorv1beta1.GracefulDownscaleRunning, volState.CruiseControlState, mountPath)
+		assert.NotNil(t, volState.LastTransitionTime, mountPath)
+	}
+}
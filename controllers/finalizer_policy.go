@@ -0,0 +1,50 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// FinalizerPolicy defines how the finalizers of the KafkaTopics, KafkaUsers and CruiseControlOperations are handled
+type FinalizerPolicy struct {
+	// SkipFinalizers prevents adding finalizers to the resources, the topics, ACLs and Cruise Control tasks
+	// belonging to them are not cleaned up when they are deleted
+	SkipFinalizers bool
+	// ReleaseTimeout is the duration after which the finalizer of a resource under deletion is removed even when its
+	// cleanup has not succeeded, e.g. because Kafka or Cruise Control is permanently gone. Zero disables the release.
+	ReleaseTimeout time.Duration
+}
+
+// skipFinalizer returns true when the finalizer must not be added to the resource
+func (p FinalizerPolicy) skipFinalizer(object metav1.Object) bool {
+	return p.SkipFinalizers || object.GetAnnotations()[v1beta1.SkipFinalizersAnnotationKey] == "true"
+}
+
+// forceRelease returns true when the finalizer of the resource under deletion has to be removed without cleanup
+func (p FinalizerPolicy) forceRelease(object metav1.Object, now time.Time) bool {
+	deletionTimestamp := object.GetDeletionTimestamp()
+	if deletionTimestamp.IsZero() {
+		return false
+	}
+	if object.GetAnnotations()[v1beta1.ForceCleanupAnnotationKey] == "true" {
+		return true
+	}
+	return p.ReleaseTimeout > 0 && now.Sub(deletionTimestamp.Time) >= p.ReleaseTimeout
+}
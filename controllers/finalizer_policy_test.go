@@ -0,0 +1,47 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestFinalizerPolicy(t *testing.T) {
+	now := time.Now()
+	deletedAt := metav1.NewTime(now.Add(-10 * time.Minute))
+
+	user := &v1alpha1.KafkaUser{}
+	assert.False(t, FinalizerPolicy{}.skipFinalizer(user))
+	assert.True(t, FinalizerPolicy{SkipFinalizers: true}.skipFinalizer(user))
+	user.SetAnnotations(map[string]string{v1beta1.SkipFinalizersAnnotationKey: "true"})
+	assert.True(t, FinalizerPolicy{}.skipFinalizer(user))
+
+	topic := &v1alpha1.KafkaTopic{}
+	// resources which are not being deleted are never released
+	assert.False(t, FinalizerPolicy{ReleaseTimeout: time.Minute}.forceRelease(topic, now))
+	topic.SetDeletionTimestamp(&deletedAt)
+	assert.False(t, FinalizerPolicy{}.forceRelease(topic, now))
+	assert.False(t, FinalizerPolicy{ReleaseTimeout: time.Hour}.forceRelease(topic, now))
+	assert.True(t, FinalizerPolicy{ReleaseTimeout: 5 * time.Minute}.forceRelease(topic, now))
+	topic.SetAnnotations(map[string]string{v1beta1.ForceCleanupAnnotationKey: "true"})
+	assert.True(t, FinalizerPolicy{}.forceRelease(topic, now))
+}
@@ -0,0 +1,340 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaiv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/ccoperation"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// KafkaBrokerDecommissionReconciler reconciles KafkaBrokerDecommission custom resources
+type KafkaBrokerDecommissionReconciler struct {
+	client.Client
+	Scheme              *runtime.Scheme
+	KafkaClientProvider kafkaclient.Provider
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkabrokerdecommissions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkabrokerdecommissions/status,verbs=get;update;patch
+
+// Reconcile drives the decommission of the broker through its phases. Every phase is left only when its outcome
+// has been observed, so the decommission can be resumed after an operator restart.
+func (r *KafkaBrokerDecommissionReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	decommission := &banzaiv1alpha1.KafkaBrokerDecommission{}
+	if err := r.Get(ctx, request.NamespacedName, decommission); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if !decommission.GetDeletionTimestamp().IsZero() || decommission.IsDone() {
+		return reconciled()
+	}
+
+	kafkaCluster, err := k8sutil.LookupKafkaCluster(ctx, r.Client, decommission.Spec.ClusterRef.Name,
+		getClusterRefNamespace(decommission.GetNamespace(), decommission.Spec.ClusterRef))
+	if err != nil {
+		return requeueWithError(log, "failed to lookup referenced kafka cluster", err)
+	}
+	if err = k8sutil.CheckCrossNamespaceReference(ctx, r.Client, kafkaCluster, decommission.GetNamespace()); err != nil {
+		return requeueWithError(log, "kafkabrokerdecommission is not allowed to reference the cluster", err)
+	}
+	log = log.WithValues(banzaiv1beta1.BrokerIdLabelKey, decommission.Spec.BrokerID, "phase", decommission.Status.Phase)
+
+	switch decommission.Status.Phase {
+	case banzaiv1alpha1.DecommissionPhaseDemoting:
+		return r.demote(ctx, log, decommission, kafkaCluster)
+	case banzaiv1alpha1.DecommissionPhaseRemoving:
+		return r.remove(ctx, log, decommission, kafkaCluster)
+	case banzaiv1alpha1.DecommissionPhaseVerifying:
+		return r.verify(ctx, log, decommission, kafkaCluster)
+	case banzaiv1alpha1.DecommissionPhaseCleaningUp:
+		return r.cleanUp(ctx, log, decommission, kafkaCluster)
+	default:
+		return r.start(ctx, log, decommission, kafkaCluster)
+	}
+}
+
+func (r *KafkaBrokerDecommissionReconciler) start(ctx context.Context, log logr.Logger,
+	decommission *banzaiv1alpha1.KafkaBrokerDecommission, kafkaCluster *banzaiv1beta1.KafkaCluster) (ctrl.Result, error) {
+	brokerID := strconv.Itoa(int(decommission.Spec.BrokerID))
+	_, hasState := kafkaCluster.Status.BrokersState[brokerID]
+	if !isBrokerInSpec(kafkaCluster, decommission.Spec.BrokerID) && !hasState {
+		return r.fail(ctx, log, decommission, fmt.Sprintf("broker %s is not part of the kafka cluster", brokerID))
+	}
+
+	if decommission.Spec.SkipDemotion {
+		setDecommissionCondition(decommission, banzaiv1alpha1.DecommissionConditionDemoted, metav1.ConditionFalse, "Skipped",
+			"demotion of the broker is skipped")
+		decommission.Status.Phase = banzaiv1alpha1.DecommissionPhaseRemoving
+	} else {
+		decommission.Status.Phase = banzaiv1alpha1.DecommissionPhaseDemoting
+	}
+	log.Info("decommission of broker started")
+	return r.updateStatus(ctx, log, decommission, reconcile.Result{Requeue: true})
+}
+
+// demote moves the partition leaderships off from the broker so that clients are not impacted when its replicas
+// are moved later on
+func (r *KafkaBrokerDecommissionReconciler) demote(ctx context.Context, log logr.Logger,
+	decommission *banzaiv1alpha1.KafkaBrokerDecommission, kafkaCluster *banzaiv1beta1.KafkaCluster) (ctrl.Result, error) {
+	if decommission.Status.DemoteOperation == "" {
+		operation, err := ccoperation.NewDemoteBroker(decommission.Spec.BrokerID).
+			ForCluster(kafkaCluster).
+			OwnedBy(kafkaCluster, r.Scheme).
+			Create(ctx, r.Client)
+		if err != nil {
+			return requeueWithError(log, "failed to create the CruiseControlOperation which demotes the broker", err)
+		}
+		decommission.Status.DemoteOperation = operation.GetName()
+		setDecommissionCondition(decommission, banzaiv1alpha1.DecommissionConditionDemoted, metav1.ConditionFalse, "InProgress",
+			fmt.Sprintf("CruiseControlOperation %s is demoting the broker", operation.GetName()))
+		return r.updateStatus(ctx, log, decommission, ctrl.Result{RequeueAfter: time.Duration(defaultRequeueIntervalInSeconds) * time.Second})
+	}
+
+	operation, err := getCCOperation(ctx, r.Client, kafkaCluster.GetNamespace(), decommission.Status.DemoteOperation)
+	if err != nil {
+		return requeueWithError(log, "failed to get the CruiseControlOperation which demotes the broker", err)
+	}
+	if operation == nil {
+		log.Info("CruiseControlOperation of the demotion is not found, demoting again", "operation", decommission.Status.DemoteOperation)
+		decommission.Status.DemoteOperation = ""
+		return r.updateStatus(ctx, log, decommission, reconcile.Result{Requeue: true})
+	}
+
+	switch {
+	case operation.IsFinished():
+		setDecommissionCondition(decommission, banzaiv1alpha1.DecommissionConditionDemoted, metav1.ConditionTrue, "Completed",
+			"partition leaderships have been moved off from the broker")
+		decommission.Status.Phase = banzaiv1alpha1.DecommissionPhaseRemoving
+		log.Info("broker demoted")
+		return r.updateStatus(ctx, log, decommission, reconcile.Result{Requeue: true})
+	case operation.IsDone():
+		setDecommissionCondition(decommission, banzaiv1alpha1.DecommissionConditionDemoted, metav1.ConditionFalse, "Failed",
+			fmt.Sprintf("CruiseControlOperation %s failed", operation.GetName()))
+		return r.fail(ctx, log, decommission, fmt.Sprintf("demotion of the broker failed in CruiseControlOperation %s",
+			operation.GetName()))
+	default:
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+}
+
+// remove drops the broker from the KafkaCluster which makes the operator move its partition replicas off by
+// Cruise Control before its pod gets deleted
+func (r *KafkaBrokerDecommissionReconciler) remove(ctx context.Context, log logr.Logger,
+	decommission *banzaiv1alpha1.KafkaBrokerDecommission, kafkaCluster *banzaiv1beta1.KafkaCluster) (ctrl.Result, error) {
+	if isBrokerInSpec(kafkaCluster, decommission.Spec.BrokerID) {
+		brokers := make([]banzaiv1beta1.Broker, 0, len(kafkaCluster.Spec.Brokers))
+		for _, broker := range kafkaCluster.Spec.Brokers {
+			if broker.Id != decommission.Spec.BrokerID {
+				brokers = append(brokers, broker)
+			}
+		}
+		kafkaCluster.Spec.Brokers = brokers
+		if err := r.Update(ctx, kafkaCluster); err != nil {
+			return requeueWithError(log, "failed to remove the broker from the kafka cluster", err)
+		}
+		log.Info("broker removed from the kafka cluster")
+		setDecommissionCondition(decommission, banzaiv1alpha1.DecommissionConditionRemoved, metav1.ConditionFalse, "InProgress",
+			"broker has been removed from the kafka cluster, waiting for Cruise Control to move its partition replicas")
+		return r.updateStatus(ctx, log, decommission, ctrl.Result{RequeueAfter: time.Duration(defaultRequeueIntervalInSeconds) * time.Second})
+	}
+
+	brokerState, ok := kafkaCluster.Status.BrokersState[strconv.Itoa(int(decommission.Spec.BrokerID))]
+	if ok && brokerState.GracefulActionState.CruiseControlState != banzaiv1beta1.GracefulDownscaleSucceeded {
+		log.V(1).Info("waiting for the graceful downscale of the broker", "state", brokerState.GracefulActionState.CruiseControlState)
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+
+	setDecommissionCondition(decommission, banzaiv1alpha1.DecommissionConditionRemoved, metav1.ConditionTrue, "Completed",
+		"partition replicas have been moved off from the broker")
+	decommission.Status.Phase = banzaiv1alpha1.DecommissionPhaseVerifying
+	return r.updateStatus(ctx, log, decommission, reconcile.Result{Requeue: true})
+}
+
+// verify checks the partition metadata of the Kafka cluster for replicas still assigned to the broker
+func (r *KafkaBrokerDecommissionReconciler) verify(ctx context.Context, log logr.Logger,
+	decommission *banzaiv1alpha1.KafkaBrokerDecommission, kafkaCluster *banzaiv1beta1.KafkaCluster) (ctrl.Result, error) {
+	kClient, closeClient, err := r.KafkaClientProvider.NewFromCluster(r.Client, kafkaCluster)
+	if err != nil {
+		log.Error(err, "could not connect to kafka brokers")
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+	defer closeClient()
+
+	topics, err := kClient.ListTopics()
+	if err != nil {
+		log.Error(err, "could not list topics")
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+
+	if replicas := brokerReplicaCount(topics, decommission.Spec.BrokerID); replicas > 0 {
+		setDecommissionCondition(decommission, banzaiv1alpha1.DecommissionConditionVerified, metav1.ConditionFalse, "ReplicasLeft",
+			fmt.Sprintf("%d partition replicas are still assigned to the broker", replicas))
+		return r.updateStatus(ctx, log, decommission, ctrl.Result{RequeueAfter: time.Duration(defaultRequeueIntervalInSeconds) * time.Second})
+	}
+
+	setDecommissionCondition(decommission, banzaiv1alpha1.DecommissionConditionVerified, metav1.ConditionTrue, "NoReplicas",
+		"no partition replica is assigned to the broker")
+	decommission.Status.Phase = banzaiv1alpha1.DecommissionPhaseCleaningUp
+	return r.updateStatus(ctx, log, decommission, reconcile.Result{Requeue: true})
+}
+
+// cleanUp waits for the pod of the broker to be deleted, removes the PersistentVolumeClaims and the status of the
+// broker which are left behind, then retires the ID of the broker
+func (r *KafkaBrokerDecommissionReconciler) cleanUp(ctx context.Context, log logr.Logger,
+	decommission *banzaiv1alpha1.KafkaBrokerDecommission, kafkaCluster *banzaiv1beta1.KafkaCluster) (ctrl.Result, error) {
+	brokerID := strconv.Itoa(int(decommission.Spec.BrokerID))
+	matchingLabels := client.MatchingLabels(apiutil.MergeLabels(
+		apiutil.LabelsForKafka(kafkaCluster.GetName()),
+		map[string]string{banzaiv1beta1.BrokerIdLabelKey: brokerID},
+	))
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(kafkaCluster.GetNamespace()), matchingLabels); err != nil {
+		return requeueWithError(log, "failed to list the pods of the broker", err)
+	}
+	if len(podList.Items) > 0 {
+		setDecommissionCondition(decommission, banzaiv1alpha1.DecommissionConditionCleanedUp, metav1.ConditionFalse, "PodExists",
+			"waiting for the pod of the broker to be deleted")
+		return r.updateStatus(ctx, log, decommission, ctrl.Result{RequeueAfter: time.Duration(defaultRequeueIntervalInSeconds) * time.Second})
+	}
+
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, pvcList, client.InNamespace(kafkaCluster.GetNamespace()), matchingLabels); err != nil {
+		return requeueWithError(log, "failed to list the persistent volume claims of the broker", err)
+	}
+	for i := range pvcList.Items {
+		if err := r.Delete(ctx, &pvcList.Items[i]); client.IgnoreNotFound(err) != nil {
+			return requeueWithError(log, "failed to delete persistent volume claim of the broker", err)
+		}
+		log.Info("persistent volume claim of the broker deleted", "pvc", pvcList.Items[i].GetName())
+	}
+
+	if _, ok := kafkaCluster.Status.BrokersState[brokerID]; ok {
+		if err := k8sutil.DeleteStatus(r.Client, brokerID, kafkaCluster, log); err != nil {
+			return requeueWithError(log, "failed to delete the status of the broker", err)
+		}
+	}
+	setDecommissionCondition(decommission, banzaiv1alpha1.DecommissionConditionCleanedUp, metav1.ConditionTrue, "Completed",
+		"resources of the broker have been removed")
+
+	if !kafkaCluster.IsBrokerIDRetired(decommission.Spec.BrokerID) {
+		err := k8sutil.PatchKafkaClusterStatus(ctx, r.Client, kafkaCluster,
+			func(status *banzaiv1beta1.KafkaClusterStatus) {
+				if !kafkaCluster.IsBrokerIDRetired(decommission.Spec.BrokerID) {
+					status.RetiredBrokerIDs = append(status.RetiredBrokerIDs, decommission.Spec.BrokerID)
+				}
+			})
+		if err != nil {
+			return requeueWithError(log, "failed to retire the broker ID", err)
+		}
+	}
+	setDecommissionCondition(decommission, banzaiv1alpha1.DecommissionConditionRetired, metav1.ConditionTrue, "Completed",
+		"broker ID has been retired")
+
+	decommission.Status.Phase = banzaiv1alpha1.DecommissionPhaseCompleted
+	decommission.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	log.Info("decommission of broker completed")
+	return r.updateStatus(ctx, log, decommission, reconcile.Result{})
+}
+
+func (r *KafkaBrokerDecommissionReconciler) fail(ctx context.Context, log logr.Logger,
+	decommission *banzaiv1alpha1.KafkaBrokerDecommission, message string) (ctrl.Result, error) {
+	log.Info("decommission of broker failed", "reason", message)
+	decommission.Status.Phase = banzaiv1alpha1.DecommissionPhaseFailed
+	decommission.Status.ErrorMessage = message
+	return r.updateStatus(ctx, log, decommission, reconcile.Result{})
+}
+
+func (r *KafkaBrokerDecommissionReconciler) updateStatus(ctx context.Context, log logr.Logger,
+	decommission *banzaiv1alpha1.KafkaBrokerDecommission, result ctrl.Result) (ctrl.Result, error) {
+	if err := r.Status().Update(ctx, decommission); err != nil {
+		return requeueWithError(log, "could not update KafkaBrokerDecommission status", err)
+	}
+	return result, nil
+}
+
+func setDecommissionCondition(decommission *banzaiv1alpha1.KafkaBrokerDecommission, conditionType string,
+	status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&decommission.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: decommission.GetGeneration(),
+	})
+}
+
+func isBrokerInSpec(kafkaCluster *banzaiv1beta1.KafkaCluster, brokerID int32) bool {
+	for _, broker := range kafkaCluster.Spec.Brokers {
+		if broker.Id == brokerID {
+			return true
+		}
+	}
+	return false
+}
+
+// brokerReplicaCount returns the number of partition replicas assigned to the broker
+func brokerReplicaCount(topics map[string]sarama.TopicDetail, brokerID int32) int {
+	var count int
+	for _, topic := range topics {
+		for _, replicas := range topic.ReplicaAssignment {
+			for _, replica := range replicas {
+				if replica == brokerID {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+// SetupKafkaBrokerDecommissionWithManager registers KafkaBrokerDecommission controller to the manager
+func SetupKafkaBrokerDecommissionWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&banzaiv1alpha1.KafkaBrokerDecommission{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
+		Named("KafkaBrokerDecommission")
+}
+
+// blank assignment to verify that KafkaBrokerDecommissionReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &KafkaBrokerDecommissionReconciler{}
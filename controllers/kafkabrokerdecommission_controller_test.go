@@ -0,0 +1,219 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+type decommissionTestKafkaClient struct {
+	kafkaclient.KafkaClient
+	topics map[string]sarama.TopicDetail
+}
+
+func (c *decommissionTestKafkaClient) ListTopics() (map[string]sarama.TopicDetail, error) {
+	return c.topics, nil
+}
+
+type decommissionTestProvider struct {
+	kafkaClient *decommissionTestKafkaClient
+}
+
+func (p *decommissionTestProvider) NewFromCluster(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+	return p.kafkaClient, func() {}, nil
+}
+
+func newDecommissionTestReconciler(t *testing.T, objects ...client.Object) (*KafkaBrokerDecommissionReconciler, *decommissionTestKafkaClient) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	kafkaClient := &decommissionTestKafkaClient{}
+	return &KafkaBrokerDecommissionReconciler{
+		Client:              fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Scheme:              scheme,
+		KafkaClientProvider: &decommissionTestProvider{kafkaClient: kafkaClient},
+	}, kafkaClient
+}
+
+func newDecommissionTestObjects(brokerID int32) (*v1beta1.KafkaCluster, *v1alpha1.KafkaBrokerDecommission) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"0": {}, "1": {}, "2": {},
+			},
+		},
+	}
+	decommission := &v1alpha1.KafkaBrokerDecommission{
+		ObjectMeta: metav1.ObjectMeta{Name: "remove-broker", Namespace: "kafka", Generation: 1},
+		Spec: v1alpha1.KafkaBrokerDecommissionSpec{
+			ClusterRef: v1alpha1.ClusterReference{Name: "kafka"},
+			BrokerID:   brokerID,
+		},
+	}
+	return cluster, decommission
+}
+
+func TestKafkaBrokerDecommissionReconcile(t *testing.T) {
+	cluster, decommission := newDecommissionTestObjects(2)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kafka-2-storage",
+			Namespace: "kafka",
+			Labels:    apiutil.MergeLabels(apiutil.LabelsForKafka("kafka"), map[string]string{v1beta1.BrokerIdLabelKey: "2"}),
+		},
+	}
+	r, kafkaClient := newDecommissionTestReconciler(t, cluster, decommission, pvc)
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "remove-broker", Namespace: "kafka"}}
+
+	reconcileAndGet := func() *v1alpha1.KafkaBrokerDecommission {
+		_, err := r.Reconcile(ctx, request)
+		assert.NoError(t, err)
+		current := &v1alpha1.KafkaBrokerDecommission{}
+		assert.NoError(t, r.Get(ctx, request.NamespacedName, current))
+		return current
+	}
+	getCluster := func() *v1beta1.KafkaCluster {
+		current := &v1beta1.KafkaCluster{}
+		assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: "kafka", Namespace: "kafka"}, current))
+		return current
+	}
+
+	current := reconcileAndGet()
+	assert.Equal(t, v1alpha1.DecommissionPhaseDemoting, current.Status.Phase)
+
+	// The broker is demoted through a CruiseControlOperation
+	current = reconcileAndGet()
+	assert.NotEmpty(t, current.Status.DemoteOperation)
+	assert.True(t, meta.IsStatusConditionFalse(current.Status.Conditions, v1alpha1.DecommissionConditionDemoted))
+	operation := &v1alpha1.CruiseControlOperation{}
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: current.Status.DemoteOperation, Namespace: "kafka"}, operation))
+	assert.Equal(t, v1alpha1.OperationDemoteBroker, operation.CurrentTaskOperation())
+	assert.Equal(t, "2", operation.CurrentTaskParameters()[v1alpha1.ParamBrokerID])
+
+	// The broker stays in the cluster until the demotion has been completed
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.DecommissionPhaseDemoting, current.Status.Phase)
+	operation.Status.CurrentTask.State = v1beta1.CruiseControlTaskCompleted
+	assert.NoError(t, r.Status().Update(ctx, operation))
+
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.DecommissionPhaseRemoving, current.Status.Phase)
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, v1alpha1.DecommissionConditionDemoted))
+
+	reconcileAndGet()
+	assert.Equal(t, []v1beta1.Broker{{Id: 0}, {Id: 1}}, getCluster().Spec.Brokers)
+
+	// Waiting for the graceful downscale of the broker
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.DecommissionPhaseRemoving, current.Status.Phase)
+
+	kafkaCluster := getCluster()
+	kafkaCluster.Status.BrokersState["2"] = v1beta1.BrokerState{
+		GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulDownscaleSucceeded},
+	}
+	assert.NoError(t, r.Status().Update(ctx, kafkaCluster))
+
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.DecommissionPhaseVerifying, current.Status.Phase)
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, v1alpha1.DecommissionConditionRemoved))
+
+	kafkaClient.topics = map[string]sarama.TopicDetail{
+		"topic": {ReplicaAssignment: map[int32][]int32{0: {0, 2}, 1: {1, 0}}},
+	}
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.DecommissionPhaseVerifying, current.Status.Phase)
+	assert.True(t, meta.IsStatusConditionFalse(current.Status.Conditions, v1alpha1.DecommissionConditionVerified))
+
+	kafkaClient.topics = map[string]sarama.TopicDetail{
+		"topic": {ReplicaAssignment: map[int32][]int32{0: {0, 1}, 1: {1, 0}}},
+	}
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.DecommissionPhaseCleaningUp, current.Status.Phase)
+
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.DecommissionPhaseCompleted, current.Status.Phase)
+	assert.NotNil(t, current.Status.CompletionTime)
+	for _, conditionType := range []string{
+		v1alpha1.DecommissionConditionDemoted, v1alpha1.DecommissionConditionRemoved, v1alpha1.DecommissionConditionVerified,
+		v1alpha1.DecommissionConditionCleanedUp, v1alpha1.DecommissionConditionRetired,
+	} {
+		assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, conditionType), conditionType)
+	}
+
+	kafkaCluster = getCluster()
+	assert.Equal(t, []int32{2}, kafkaCluster.Status.RetiredBrokerIDs)
+	assert.NotContains(t, kafkaCluster.Status.BrokersState, "2")
+
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	assert.NoError(t, r.List(ctx, pvcList))
+	assert.Empty(t, pvcList.Items)
+}
+
+func TestKafkaBrokerDecommissionCrossNamespaceReference(t *testing.T) {
+	cluster, decommission := newDecommissionTestObjects(2)
+	cluster.Spec.CrossNamespaceReferences = &v1beta1.CrossNamespaceReferences{}
+	decommission.Namespace = "other"
+	decommission.Spec.ClusterRef.Namespace = "kafka"
+	r, _ := newDecommissionTestReconciler(t, cluster, decommission)
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "remove-broker", Namespace: "other"}}
+
+	_, err := r.Reconcile(ctx, request)
+	assert.Error(t, err)
+
+	current := &v1alpha1.KafkaBrokerDecommission{}
+	assert.NoError(t, r.Get(ctx, request.NamespacedName, current))
+	assert.Empty(t, current.Status.Phase)
+}
+
+func TestKafkaBrokerDecommissionUnknownBroker(t *testing.T) {
+	cluster, decommission := newDecommissionTestObjects(5)
+	r, _ := newDecommissionTestReconciler(t, cluster, decommission)
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "remove-broker", Namespace: "kafka"}}
+
+	result, err := r.Reconcile(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	current := &v1alpha1.KafkaBrokerDecommission{}
+	assert.NoError(t, r.Get(ctx, request.NamespacedName, current))
+	assert.Equal(t, v1alpha1.DecommissionPhaseFailed, current.Status.Phase)
+	assert.NotEmpty(t, current.Status.ErrorMessage)
+}
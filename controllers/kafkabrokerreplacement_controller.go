@@ -0,0 +1,411 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	banzaiv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaiv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/ccoperation"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+// KafkaBrokerReplacementReconciler reconciles KafkaBrokerReplacement custom resources
+type KafkaBrokerReplacementReconciler struct {
+	client.Client
+	Scheme              *runtime.Scheme
+	ScaleFactory        func(ctx context.Context, kafkaCluster *banzaiv1beta1.KafkaCluster) (scale.CruiseControlScaler, error)
+	KafkaClientProvider kafkaclient.Provider
+	PodEvictor          k8sutil.PodEvictor
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkabrokerreplacements,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkabrokerreplacements/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+
+// Reconcile drives the replacement of the broker through its phases. Every phase is left only when its outcome
+// has been observed, so the replacement can be resumed after an operator restart.
+func (r *KafkaBrokerReplacementReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	replacement := &banzaiv1alpha1.KafkaBrokerReplacement{}
+	if err := r.Get(ctx, request.NamespacedName, replacement); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	if !replacement.GetDeletionTimestamp().IsZero() || replacement.IsDone() {
+		return reconciled()
+	}
+
+	kafkaCluster, err := k8sutil.LookupKafkaCluster(ctx, r.Client, replacement.Spec.ClusterRef.Name,
+		getClusterRefNamespace(replacement.GetNamespace(), replacement.Spec.ClusterRef))
+	if err != nil {
+		return requeueWithError(log, "failed to lookup referenced kafka cluster", err)
+	}
+	if err = k8sutil.CheckCrossNamespaceReference(ctx, r.Client, kafkaCluster, replacement.GetNamespace()); err != nil {
+		return requeueWithError(log, "kafkabrokerreplacement is not allowed to reference the cluster", err)
+	}
+	log = log.WithValues(banzaiv1beta1.BrokerIdLabelKey, replacement.Spec.BrokerID, "phase", replacement.Status.Phase)
+
+	if replacement.Status.Phase != "" && replacement.Status.Phase != banzaiv1alpha1.ReplacementPhasePending &&
+		!isBrokerInSpec(kafkaCluster, replacement.Spec.BrokerID) {
+		return r.fail(ctx, log, replacement, "broker has been removed from the kafka cluster during the replacement")
+	}
+
+	switch replacement.Status.Phase {
+	case banzaiv1alpha1.ReplacementPhaseDemoting:
+		return r.demote(ctx, log, replacement, kafkaCluster)
+	case banzaiv1alpha1.ReplacementPhaseEvicting:
+		return r.evict(ctx, log, replacement, kafkaCluster)
+	case banzaiv1alpha1.ReplacementPhaseRescheduling:
+		return r.reschedule(ctx, log, replacement, kafkaCluster)
+	case banzaiv1alpha1.ReplacementPhaseCatchingUp:
+		return r.catchUp(ctx, log, replacement, kafkaCluster)
+	default:
+		return r.start(ctx, log, replacement, kafkaCluster)
+	}
+}
+
+func (r *KafkaBrokerReplacementReconciler) start(ctx context.Context, log logr.Logger,
+	replacement *banzaiv1alpha1.KafkaBrokerReplacement, kafkaCluster *banzaiv1beta1.KafkaCluster) (ctrl.Result, error) {
+	if !isBrokerInSpec(kafkaCluster, replacement.Spec.BrokerID) {
+		return r.fail(ctx, log, replacement, fmt.Sprintf("broker %d is not part of the kafka cluster", replacement.Spec.BrokerID))
+	}
+
+	if replacement.Spec.SkipDemotion {
+		setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionDemoted, metav1.ConditionFalse, "Skipped",
+			"demotion of the broker is skipped")
+		replacement.Status.Phase = banzaiv1alpha1.ReplacementPhaseEvicting
+	} else {
+		replacement.Status.Phase = banzaiv1alpha1.ReplacementPhaseDemoting
+	}
+	log.Info("replacement of broker started")
+	return r.updateStatus(ctx, log, replacement, reconcile.Result{Requeue: true})
+}
+
+// demote moves the partition leaderships off from the broker so that clients are not impacted when its pod is evicted
+func (r *KafkaBrokerReplacementReconciler) demote(ctx context.Context, log logr.Logger,
+	replacement *banzaiv1alpha1.KafkaBrokerReplacement, kafkaCluster *banzaiv1beta1.KafkaCluster) (ctrl.Result, error) {
+	if replacement.Status.DemoteOperation == "" {
+		operation, err := ccoperation.NewDemoteBroker(replacement.Spec.BrokerID).
+			ForCluster(kafkaCluster).
+			OwnedBy(kafkaCluster, r.Scheme).
+			Create(ctx, r.Client)
+		if err != nil {
+			return requeueWithError(log, "failed to create the CruiseControlOperation which demotes the broker", err)
+		}
+		replacement.Status.DemoteOperation = operation.GetName()
+		setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionDemoted, metav1.ConditionFalse, "InProgress",
+			fmt.Sprintf("CruiseControlOperation %s is demoting the broker", operation.GetName()))
+		return r.updateStatus(ctx, log, replacement, ctrl.Result{RequeueAfter: time.Duration(defaultRequeueIntervalInSeconds) * time.Second})
+	}
+
+	operation, err := getCCOperation(ctx, r.Client, kafkaCluster.GetNamespace(), replacement.Status.DemoteOperation)
+	if err != nil {
+		return requeueWithError(log, "failed to get the CruiseControlOperation which demotes the broker", err)
+	}
+	if operation == nil {
+		log.Info("CruiseControlOperation of the demotion is not found, demoting again", "operation", replacement.Status.DemoteOperation)
+		replacement.Status.DemoteOperation = ""
+		return r.updateStatus(ctx, log, replacement, reconcile.Result{Requeue: true})
+	}
+
+	switch {
+	case operation.IsFinished():
+		setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionDemoted, metav1.ConditionTrue, "Completed",
+			"partition leaderships have been moved off from the broker")
+		replacement.Status.Phase = banzaiv1alpha1.ReplacementPhaseEvicting
+		log.Info("broker demoted")
+		return r.updateStatus(ctx, log, replacement, reconcile.Result{Requeue: true})
+	case operation.IsDone():
+		setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionDemoted, metav1.ConditionFalse, "Failed",
+			fmt.Sprintf("CruiseControlOperation %s failed", operation.GetName()))
+		return r.fail(ctx, log, replacement, fmt.Sprintf("demotion of the broker failed in CruiseControlOperation %s",
+			operation.GetName()))
+	default:
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+}
+
+// evict records the pod of the broker, sets the node selector of the replacement on the broker, then evicts the
+// recorded pod unless it has already been replaced by the KafkaCluster reconciler due to the changed pod spec.
+// The eviction is retried while it is refused due to a PodDisruptionBudget.
+func (r *KafkaBrokerReplacementReconciler) evict(ctx context.Context, log logr.Logger,
+	replacement *banzaiv1alpha1.KafkaBrokerReplacement, kafkaCluster *banzaiv1beta1.KafkaCluster) (ctrl.Result, error) {
+	pods, err := r.brokerPods(ctx, kafkaCluster, replacement.Spec.BrokerID)
+	if err != nil {
+		return requeueWithError(log, "failed to list the pods of the broker", err)
+	}
+	// The pod has to be recorded before the spec is changed, otherwise the pod recreated with the new node selector
+	// would be recorded and evicted again
+	if replacement.Status.EvictedPodUID == "" {
+		for i := range pods {
+			if pods[i].GetDeletionTimestamp().IsZero() {
+				replacement.Status.EvictedPodUID = pods[i].GetUID()
+				return r.updateStatus(ctx, log, replacement, reconcile.Result{Requeue: true})
+			}
+		}
+	}
+
+	if brokers, changed := brokersWithNodeSelector(kafkaCluster, replacement.Spec.BrokerID, replacement.Spec.NodeSelector); changed {
+		kafkaCluster.Spec.Brokers = brokers
+		if err := r.Update(ctx, kafkaCluster); err != nil {
+			return requeueWithError(log, "failed to set the node selector of the broker", err)
+		}
+		log.Info("node selector of the broker updated", "nodeSelector", replacement.Spec.NodeSelector)
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		if pod.GetUID() != replacement.Status.EvictedPodUID {
+			continue
+		}
+		if pod.GetDeletionTimestamp().IsZero() {
+			if err := r.PodEvictor.Evict(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+				if !apierrors.IsTooManyRequests(err) {
+					return requeueWithError(log, "failed to evict the pod of the broker", err)
+				}
+				setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionEvicted, metav1.ConditionFalse, "DisruptionBudget",
+					fmt.Sprintf("eviction of pod %s is refused: %s", pod.GetName(), err.Error()))
+				return r.updateStatus(ctx, log, replacement, ctrl.Result{RequeueAfter: time.Duration(defaultRequeueIntervalInSeconds) * time.Second})
+			}
+			log.Info("pod of the broker evicted", "pod", pod.GetName())
+		}
+		setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionEvicted, metav1.ConditionFalse, "InProgress",
+			fmt.Sprintf("waiting for pod %s to be terminated", pod.GetName()))
+		return r.updateStatus(ctx, log, replacement, ctrl.Result{RequeueAfter: time.Duration(defaultRequeueIntervalInSeconds) * time.Second})
+	}
+
+	setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionEvicted, metav1.ConditionTrue, "Completed",
+		"pod of the broker has been evicted")
+	replacement.Status.Phase = banzaiv1alpha1.ReplacementPhaseRescheduling
+	return r.updateStatus(ctx, log, replacement, reconcile.Result{Requeue: true})
+}
+
+// reschedule waits for the pod of the broker, which is recreated by the KafkaCluster reconciler, to become ready
+func (r *KafkaBrokerReplacementReconciler) reschedule(ctx context.Context, log logr.Logger,
+	replacement *banzaiv1alpha1.KafkaBrokerReplacement, kafkaCluster *banzaiv1beta1.KafkaCluster) (ctrl.Result, error) {
+	pods, err := r.brokerPods(ctx, kafkaCluster, replacement.Spec.BrokerID)
+	if err != nil {
+		return requeueWithError(log, "failed to list the pods of the broker", err)
+	}
+	var pod *corev1.Pod
+	for i := range pods {
+		if pods[i].GetUID() != replacement.Status.EvictedPodUID && pods[i].GetDeletionTimestamp().IsZero() {
+			pod = &pods[i]
+			break
+		}
+	}
+
+	switch {
+	case pod == nil:
+		setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionRescheduled, metav1.ConditionFalse, "WaitingForPod",
+			"waiting for the new pod of the broker to be created")
+	case !isPodReady(pod):
+		if message := unschedulableMessage(pod); message != "" {
+			setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionRescheduled, metav1.ConditionFalse, "Unschedulable",
+				fmt.Sprintf("pod %s cannot be scheduled: %s", pod.GetName(), message))
+		} else {
+			setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionRescheduled, metav1.ConditionFalse, "WaitingForPod",
+				fmt.Sprintf("waiting for pod %s to become ready", pod.GetName()))
+		}
+	default:
+		replacement.Status.NodeName = pod.Spec.NodeName
+		setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionRescheduled, metav1.ConditionTrue, "Ready",
+			fmt.Sprintf("pod %s is ready on node %s", pod.GetName(), pod.Spec.NodeName))
+		replacement.Status.Phase = banzaiv1alpha1.ReplacementPhaseCatchingUp
+		log.Info("broker rescheduled", "node", pod.Spec.NodeName)
+		return r.updateStatus(ctx, log, replacement, reconcile.Result{Requeue: true})
+	}
+	return r.updateStatus(ctx, log, replacement, ctrl.Result{RequeueAfter: time.Duration(defaultRequeueIntervalInSeconds) * time.Second})
+}
+
+// catchUp waits for every replica of the broker to rejoin the in-sync replicas, then lets the broker lead partitions
+// again by dropping it from the recently demoted brokers of Cruise Control
+func (r *KafkaBrokerReplacementReconciler) catchUp(ctx context.Context, log logr.Logger,
+	replacement *banzaiv1alpha1.KafkaBrokerReplacement, kafkaCluster *banzaiv1beta1.KafkaCluster) (ctrl.Result, error) {
+	kClient, closeClient, err := r.KafkaClientProvider.NewFromCluster(r.Client, kafkaCluster)
+	if err != nil {
+		log.Error(err, "could not connect to kafka brokers")
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+	defer closeClient()
+
+	partitions, err := kClient.PartitionReplicas()
+	if err != nil {
+		log.Error(err, "could not describe the partitions")
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+	if outOfSync := outOfSyncReplicaCount(partitions, replacement.Spec.BrokerID); outOfSync > 0 {
+		setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionCaughtUp, metav1.ConditionFalse, "OutOfSync",
+			fmt.Sprintf("%d partition replicas of the broker are not in sync", outOfSync))
+		return r.updateStatus(ctx, log, replacement, ctrl.Result{RequeueAfter: time.Duration(defaultRequeueIntervalInSeconds) * time.Second})
+	}
+
+	if !replacement.Spec.SkipDemotion {
+		scaler, err := r.ScaleFactory(ctx, kafkaCluster)
+		if err != nil {
+			return requeueWithError(log, "failed to create Cruise Control Scaler instance", err)
+		}
+		ctx = withCCRequestTag(ctx, replacement, "kafkabrokerreplacement-catchup")
+		if err := scaler.Admin(ctx, scale.AdminConfig{DropRecentlyDemotedBrokers: []int32{replacement.Spec.BrokerID}}); err != nil {
+			log.Error(err, "could not drop the broker from the recently demoted brokers of Cruise Control")
+			return requeueAfter(defaultRequeueIntervalInSeconds)
+		}
+	}
+
+	setReplacementCondition(replacement, banzaiv1alpha1.ReplacementConditionCaughtUp, metav1.ConditionTrue, "InSync",
+		"every partition replica of the broker is in sync")
+	replacement.Status.Phase = banzaiv1alpha1.ReplacementPhaseCompleted
+	replacement.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	log.Info("replacement of broker completed", "node", replacement.Status.NodeName)
+	return r.updateStatus(ctx, log, replacement, reconcile.Result{})
+}
+
+// getCCOperation returns the CruiseControlOperation with the given name, or nil when it does not exist
+func getCCOperation(ctx context.Context, c client.Reader, namespace, name string) (*banzaiv1alpha1.CruiseControlOperation, error) {
+	operation := &banzaiv1alpha1.CruiseControlOperation{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, operation); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return operation, nil
+}
+
+func (r *KafkaBrokerReplacementReconciler) brokerPods(ctx context.Context, kafkaCluster *banzaiv1beta1.KafkaCluster, brokerID int32) ([]corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	err := r.List(ctx, podList, client.InNamespace(kafkaCluster.GetNamespace()), client.MatchingLabels(apiutil.MergeLabels(
+		apiutil.LabelsForKafka(kafkaCluster.GetName()),
+		map[string]string{banzaiv1beta1.BrokerIdLabelKey: strconv.Itoa(int(brokerID))},
+	)))
+	return podList.Items, err
+}
+
+func (r *KafkaBrokerReplacementReconciler) fail(ctx context.Context, log logr.Logger,
+	replacement *banzaiv1alpha1.KafkaBrokerReplacement, message string) (ctrl.Result, error) {
+	log.Info("replacement of broker failed", "reason", message)
+	replacement.Status.Phase = banzaiv1alpha1.ReplacementPhaseFailed
+	replacement.Status.ErrorMessage = message
+	return r.updateStatus(ctx, log, replacement, reconcile.Result{})
+}
+
+func (r *KafkaBrokerReplacementReconciler) updateStatus(ctx context.Context, log logr.Logger,
+	replacement *banzaiv1alpha1.KafkaBrokerReplacement, result ctrl.Result) (ctrl.Result, error) {
+	if err := r.Status().Update(ctx, replacement); err != nil {
+		return requeueWithError(log, "could not update KafkaBrokerReplacement status", err)
+	}
+	return result, nil
+}
+
+func setReplacementCondition(replacement *banzaiv1alpha1.KafkaBrokerReplacement, conditionType string,
+	status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&replacement.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: replacement.GetGeneration(),
+	})
+}
+
+// brokersWithNodeSelector returns the brokers of the cluster where the labels of the node selector are set on the
+// given broker. The second return value is false when the broker already has the labels.
+func brokersWithNodeSelector(kafkaCluster *banzaiv1beta1.KafkaCluster, brokerID int32, nodeSelector map[string]string) ([]banzaiv1beta1.Broker, bool) {
+	if len(nodeSelector) == 0 {
+		return nil, false
+	}
+	brokers := make([]banzaiv1beta1.Broker, 0, len(kafkaCluster.Spec.Brokers))
+	changed := false
+	for _, broker := range kafkaCluster.Spec.Brokers {
+		if broker.Id == brokerID {
+			broker = *broker.DeepCopy()
+			if broker.BrokerConfig == nil {
+				broker.BrokerConfig = &banzaiv1beta1.BrokerConfig{}
+			}
+			for key, value := range nodeSelector {
+				if current, ok := broker.BrokerConfig.NodeSelector[key]; ok && current == value {
+					continue
+				}
+				if broker.BrokerConfig.NodeSelector == nil {
+					broker.BrokerConfig.NodeSelector = make(map[string]string, len(nodeSelector))
+				}
+				broker.BrokerConfig.NodeSelector[key] = value
+				changed = true
+			}
+		}
+		brokers = append(brokers, broker)
+	}
+	return brokers, changed
+}
+
+// unschedulableMessage returns the message of the scheduler when the pod cannot be scheduled, e.g. because the
+// zonal persistent volume of the broker is not reachable from the nodes selected by the node selector
+func unschedulableMessage(pod *corev1.Pod) string {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			return condition.Message
+		}
+	}
+	return ""
+}
+
+// outOfSyncReplicaCount returns the number of partition replicas hosted by the broker which are not in sync
+func outOfSyncReplicaCount(partitions []kafkaclient.PartitionReplicas, brokerID int32) int {
+	var count int
+	for _, partition := range partitions {
+		if containsBrokerID(partition.Replicas, brokerID) && !containsBrokerID(partition.Isr, brokerID) {
+			count++
+		}
+	}
+	return count
+}
+
+// SetupKafkaBrokerReplacementWithManager registers KafkaBrokerReplacement controller to the manager
+func SetupKafkaBrokerReplacementWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&banzaiv1alpha1.KafkaBrokerReplacement{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
+		Named("KafkaBrokerReplacement")
+}
+
+// blank assignment to verify that KafkaBrokerReplacementReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &KafkaBrokerReplacementReconciler{}
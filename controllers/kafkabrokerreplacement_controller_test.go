@@ -0,0 +1,276 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/scale"
+	koperatortesting "github.com/banzaicloud/koperator/pkg/testing"
+)
+
+type replacementTestKafkaClient struct {
+	kafkaclient.KafkaClient
+	partitions []kafkaclient.PartitionReplicas
+}
+
+func (c *replacementTestKafkaClient) PartitionReplicas() ([]kafkaclient.PartitionReplicas, error) {
+	return c.partitions, nil
+}
+
+type replacementTestProvider struct {
+	kafkaClient *replacementTestKafkaClient
+}
+
+func (p *replacementTestProvider) NewFromCluster(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+	return p.kafkaClient, func() {}, nil
+}
+
+// replacementTestEvictor deletes the evicted pods unless the eviction is refused
+type replacementTestEvictor struct {
+	client  client.Client
+	refused bool
+	evicted []string
+}
+
+func (e *replacementTestEvictor) Evict(ctx context.Context, pod *corev1.Pod) error {
+	if e.refused {
+		return apierrors.NewTooManyRequests("cannot evict pod as it would violate the pod's disruption budget", 0)
+	}
+	e.evicted = append(e.evicted, pod.GetName())
+	return e.client.Delete(ctx, pod)
+}
+
+func newReplacementTestBrokerPod(name, uid, nodeName string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "kafka",
+			UID:       types.UID(uid),
+			Labels:    apiutil.MergeLabels(apiutil.LabelsForKafka("kafka"), map[string]string{v1beta1.BrokerIdLabelKey: "1"}),
+		},
+		Spec:   corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	}
+}
+
+func TestKafkaBrokerReplacementReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}},
+		},
+	}
+	replacement := &v1alpha1.KafkaBrokerReplacement{
+		ObjectMeta: metav1.ObjectMeta{Name: "replace-broker", Namespace: "kafka", Generation: 1},
+		Spec: v1alpha1.KafkaBrokerReplacementSpec{
+			ClusterRef:   v1alpha1.ClusterReference{Name: "kafka"},
+			BrokerID:     1,
+			NodeSelector: map[string]string{"pool": "new"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cluster, replacement, newReplacementTestBrokerPod("kafka-1-old", "old", "old-node", true)).Build()
+	scaler := koperatortesting.NewFakeCruiseControlScaler("0", "1")
+	kafkaClient := &replacementTestKafkaClient{}
+	evictor := &replacementTestEvictor{client: c, refused: true}
+	r := &KafkaBrokerReplacementReconciler{
+		Client: c,
+		Scheme: scheme,
+		ScaleFactory: func(context.Context, *v1beta1.KafkaCluster) (scale.CruiseControlScaler, error) {
+			return scaler, nil
+		},
+		KafkaClientProvider: &replacementTestProvider{kafkaClient: kafkaClient},
+		PodEvictor:          evictor,
+	}
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "replace-broker", Namespace: "kafka"}}
+
+	reconcileAndGet := func() *v1alpha1.KafkaBrokerReplacement {
+		_, err := r.Reconcile(ctx, request)
+		assert.NoError(t, err)
+		current := &v1alpha1.KafkaBrokerReplacement{}
+		assert.NoError(t, r.Get(ctx, request.NamespacedName, current))
+		return current
+	}
+
+	current := reconcileAndGet()
+	assert.Equal(t, v1alpha1.ReplacementPhaseDemoting, current.Status.Phase)
+
+	// The broker is demoted through a CruiseControlOperation
+	current = reconcileAndGet()
+	assert.NotEmpty(t, current.Status.DemoteOperation)
+	operation := &v1alpha1.CruiseControlOperation{}
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: current.Status.DemoteOperation, Namespace: "kafka"}, operation))
+	assert.Equal(t, v1alpha1.OperationDemoteBroker, operation.CurrentTaskOperation())
+	assert.Equal(t, "1", operation.CurrentTaskParameters()[v1alpha1.ParamBrokerID])
+
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.ReplacementPhaseDemoting, current.Status.Phase)
+
+	operation.Status.CurrentTask.State = v1beta1.CruiseControlTaskCompleted
+	assert.NoError(t, c.Status().Update(ctx, operation))
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.ReplacementPhaseEvicting, current.Status.Phase)
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, v1alpha1.ReplacementConditionDemoted))
+
+	// The pod of the broker is recorded before the node selector is set on the broker
+	current = reconcileAndGet()
+	assert.Equal(t, types.UID("old"), current.Status.EvictedPodUID)
+	kafkaCluster := &v1beta1.KafkaCluster{}
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: "kafka", Namespace: "kafka"}, kafkaCluster))
+	assert.Nil(t, kafkaCluster.Spec.Brokers[1].BrokerConfig)
+
+	reconcileAndGet()
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: "kafka", Namespace: "kafka"}, kafkaCluster))
+	assert.Equal(t, map[string]string{"pool": "new"}, kafkaCluster.Spec.Brokers[1].BrokerConfig.NodeSelector)
+	assert.Nil(t, kafkaCluster.Spec.Brokers[0].BrokerConfig)
+
+	// The eviction is retried while it is refused by the disruption budget
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.ReplacementPhaseEvicting, current.Status.Phase)
+	assert.Equal(t, "DisruptionBudget", meta.FindStatusCondition(current.Status.Conditions, v1alpha1.ReplacementConditionEvicted).Reason)
+
+	evictor.refused = false
+	reconcileAndGet()
+	assert.Equal(t, []string{"kafka-1-old"}, evictor.evicted)
+
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.ReplacementPhaseRescheduling, current.Status.Phase)
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, v1alpha1.ReplacementConditionEvicted))
+
+	current = reconcileAndGet()
+	assert.Equal(t, "WaitingForPod", meta.FindStatusCondition(current.Status.Conditions, v1alpha1.ReplacementConditionRescheduled).Reason)
+
+	newPod := newReplacementTestBrokerPod("kafka-1-new", "new", "", false)
+	newPod.Status.Conditions = append(newPod.Status.Conditions, corev1.PodCondition{
+		Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+		Message: "volume node affinity conflict",
+	})
+	assert.NoError(t, c.Create(ctx, newPod))
+	current = reconcileAndGet()
+	condition := meta.FindStatusCondition(current.Status.Conditions, v1alpha1.ReplacementConditionRescheduled)
+	assert.Equal(t, "Unschedulable", condition.Reason)
+	assert.Contains(t, condition.Message, "volume node affinity conflict")
+
+	newPod.Spec.NodeName = "new-node"
+	newPod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	assert.NoError(t, c.Update(ctx, newPod))
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.ReplacementPhaseCatchingUp, current.Status.Phase)
+	assert.Equal(t, "new-node", current.Status.NodeName)
+
+	// The replacement is completed once the replicas of the broker are back in sync
+	kafkaClient.partitions = []kafkaclient.PartitionReplicas{
+		{Topic: "topic", Partition: 0, Replicas: []int32{0, 1}, Isr: []int32{0}},
+		{Topic: "topic", Partition: 1, Replicas: []int32{1, 0}, Isr: []int32{0, 1}},
+	}
+	current = reconcileAndGet()
+	condition = meta.FindStatusCondition(current.Status.Conditions, v1alpha1.ReplacementConditionCaughtUp)
+	assert.Equal(t, "OutOfSync", condition.Reason)
+	assert.Contains(t, condition.Message, "1 partition replicas")
+
+	kafkaClient.partitions[0].Isr = []int32{0, 1}
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.ReplacementPhaseCompleted, current.Status.Phase)
+	assert.NotNil(t, current.Status.CompletionTime)
+	assert.Equal(t, []scale.AdminConfig{{DropRecentlyDemotedBrokers: []int32{1}}}, scaler.AdminConfigs())
+}
+
+func TestKafkaBrokerReplacementCrossNamespaceReference(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers:                  []v1beta1.Broker{{Id: 0}},
+			CrossNamespaceReferences: &v1beta1.CrossNamespaceReferences{},
+		},
+	}
+	replacement := &v1alpha1.KafkaBrokerReplacement{
+		ObjectMeta: metav1.ObjectMeta{Name: "replace-broker", Namespace: "other"},
+		Spec: v1alpha1.KafkaBrokerReplacementSpec{
+			ClusterRef: v1alpha1.ClusterReference{Name: "kafka", Namespace: "kafka"},
+			BrokerID:   0,
+		},
+	}
+	r := &KafkaBrokerReplacementReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, replacement).Build()}
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "replace-broker", Namespace: "other"}}
+
+	_, err := r.Reconcile(ctx, request)
+	assert.Error(t, err)
+
+	current := &v1alpha1.KafkaBrokerReplacement{}
+	assert.NoError(t, r.Get(ctx, request.NamespacedName, current))
+	assert.Empty(t, current.Status.Phase)
+}
+
+func TestKafkaBrokerReplacementUnknownBroker(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec:       v1beta1.KafkaClusterSpec{Brokers: []v1beta1.Broker{{Id: 0}}},
+	}
+	replacement := &v1alpha1.KafkaBrokerReplacement{
+		ObjectMeta: metav1.ObjectMeta{Name: "replace-broker", Namespace: "kafka"},
+		Spec: v1alpha1.KafkaBrokerReplacementSpec{
+			ClusterRef: v1alpha1.ClusterReference{Name: "kafka"},
+			BrokerID:   5,
+		},
+	}
+	r := &KafkaBrokerReplacementReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, replacement).Build()}
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "replace-broker", Namespace: "kafka"}}
+
+	result, err := r.Reconcile(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	current := &v1alpha1.KafkaBrokerReplacement{}
+	assert.NoError(t, r.Get(ctx, request.NamespacedName, current))
+	assert.Equal(t, v1alpha1.ReplacementPhaseFailed, current.Status.Phase)
+	assert.NotEmpty(t, current.Status.ErrorMessage)
+}
@@ -0,0 +1,243 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// maxDownBrokersForReplacement is the number of brokers which can be down at the same time for the failed brokers to
+// be replaced. More brokers being down points to a cluster-wide outage which is not fixed by replacing the brokers.
+const maxDownBrokersForReplacement = 1
+
+// handleBrokerFailures tracks the brokers of the cluster which are down and takes the action of the broker failure
+// policy on the ones which have been down for longer than the grace period. The failed brokers are replaced one at a
+// time and only while no graceful up- or downscale is in progress, the same way as the capacity schedule changes
+// the brokers, and while no other broker of the cluster is down.
+func (r *KafkaClusterReconciler) handleBrokerFailures(ctx context.Context, cluster *v1beta1.KafkaCluster) error {
+	policy := cluster.Spec.BrokerFailurePolicy
+	if policy == nil {
+		if cluster.Status.FailedBrokers == nil {
+			return nil
+		}
+		return r.updateStatus(ctx, cluster, func(status *v1beta1.KafkaClusterStatus) {
+			status.FailedBrokers = nil
+		})
+	}
+	log := logr.FromContextOrDiscard(ctx)
+	now := time.Now()
+
+	downBrokers, err := r.downBrokerIDs(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	failedBrokers := trackFailedBrokers(cluster, downBrokers, now)
+
+	brokerIDs := make([]string, 0, len(failedBrokers))
+	for brokerID := range failedBrokers {
+		brokerIDs = append(brokerIDs, brokerID)
+	}
+	sort.Strings(brokerIDs)
+
+	replaced := false
+	for _, brokerID := range brokerIDs {
+		failedBroker := failedBrokers[brokerID]
+		if failedBroker.ReplacedBy != nil || now.Sub(failedBroker.DownSince.Time) < policy.GetGracePeriod() {
+			continue
+		}
+		if !failedBroker.GracePeriodExceeded {
+			failedBroker.GracePeriodExceeded = true
+			log.Info("broker has been down for longer than the grace period", "brokerId", brokerID, "downSince", failedBroker.DownSince)
+			r.recordEvent(cluster, corev1.EventTypeWarning, "BrokerFailureGracePeriodExceeded",
+				fmt.Sprintf("broker %s has been down since %s", brokerID, failedBroker.DownSince.UTC().Format(time.RFC3339)))
+		}
+		if policy.GetAction() != v1beta1.BrokerFailureActionReplace || replaced || isGracefulScalingInProgress(cluster) {
+			failedBrokers[brokerID] = failedBroker
+			continue
+		}
+		if len(downBrokers) > maxDownBrokersForReplacement {
+			log.Info("failed broker is not replaced while other brokers of the cluster are down", "brokerId", brokerID,
+				"downBrokers", len(downBrokers))
+			failedBrokers[brokerID] = failedBroker
+			continue
+		}
+		id, err := strconv.Atoi(brokerID)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "invalid broker ID in the failed brokers", "brokerId", brokerID)
+		}
+		retainedIDs, err := r.retainedDataBrokerIDs(ctx, cluster)
+		if err != nil {
+			return err
+		}
+		brokers, replacementID := replaceBroker(cluster, int32(id), retainedIDs)
+		cluster.Spec.Brokers = brokers
+		if _, err := r.updateAndFetchLatest(ctx, cluster); err != nil {
+			return errors.WrapIfWithDetails(err, "could not replace the failed broker", "brokerId", brokerID)
+		}
+		log.Info("failed broker replaced", "brokerId", brokerID, "replacementBrokerId", replacementID)
+		r.recordEvent(cluster, corev1.EventTypeNormal, "BrokerReplaced",
+			fmt.Sprintf("broker %s has been replaced by broker %d", brokerID, replacementID))
+		failedBroker.ReplacedBy = &replacementID
+		failedBrokers[brokerID] = failedBroker
+		replaced = true
+	}
+
+	if len(failedBrokers) == 0 {
+		failedBrokers = nil
+	}
+	if reflect.DeepEqual(failedBrokers, cluster.Status.FailedBrokers) {
+		return nil
+	}
+	if err := r.updateStatus(ctx, cluster, func(status *v1beta1.KafkaClusterStatus) {
+		status.FailedBrokers = failedBrokers
+	}); err != nil {
+		return errors.WrapIfWithDetails(err, "could not update the failed brokers of the cluster", "kafkaCluster", cluster.GetName())
+	}
+	return nil
+}
+
+// brokerFailureRequeueSeconds returns the seconds after which the failed brokers of the cluster have to be checked
+// again, which is when the first grace period passes or, when a failed broker is waiting to be replaced, the default
+// requeue interval. Zero is returned when no failed broker is waiting.
+func brokerFailureRequeueSeconds(cluster *v1beta1.KafkaCluster, now time.Time) int32 {
+	policy := cluster.Spec.BrokerFailurePolicy
+	if policy == nil {
+		return 0
+	}
+	var requeueSeconds int32
+	for _, failedBroker := range cluster.Status.FailedBrokers {
+		if failedBroker.ReplacedBy != nil {
+			continue
+		}
+		var seconds int32
+		switch remaining := failedBroker.DownSince.Add(policy.GetGracePeriod()).Sub(now); {
+		case remaining > 0:
+			seconds = int32(math.Ceil(remaining.Seconds()))
+		case policy.GetAction() == v1beta1.BrokerFailureActionReplace:
+			seconds = int32(defaultRequeueIntervalInSeconds)
+		default:
+			continue
+		}
+		if requeueSeconds == 0 || seconds < requeueSeconds {
+			requeueSeconds = seconds
+		}
+	}
+	return requeueSeconds
+}
+
+func (r *KafkaClusterReconciler) recordEvent(cluster *v1beta1.KafkaCluster, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(cluster, eventType, reason, message)
+	}
+}
+
+// downBrokerIDs returns the IDs of the brokers in the spec which have been added to the cluster by Cruise Control and
+// whose pod is missing or not ready
+func (r *KafkaClusterReconciler) downBrokerIDs(ctx context.Context, cluster *v1beta1.KafkaCluster) (map[int32]struct{}, error) {
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.InNamespace(cluster.GetNamespace()),
+		client.MatchingLabels(apiutil.LabelsForKafka(cluster.GetName()))); err != nil {
+		return nil, errors.WrapIf(err, "failed to list broker pods that belong to Kafka cluster")
+	}
+	ready := make(map[int32]struct{}, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !pod.GetDeletionTimestamp().IsZero() || !isPodReady(pod) {
+			continue
+		}
+		if id, err := strconv.ParseInt(pod.GetLabels()[v1beta1.BrokerIdLabelKey], 10, 32); err == nil {
+			ready[int32(id)] = struct{}{}
+		}
+	}
+
+	down := make(map[int32]struct{})
+	for _, broker := range cluster.Spec.Brokers {
+		brokerState, ok := cluster.Status.BrokersState[strconv.Itoa(int(broker.Id))]
+		if !ok || brokerState.GracefulActionState.CruiseControlState != v1beta1.GracefulUpscaleSucceeded {
+			continue
+		}
+		if _, ok := ready[broker.Id]; !ok {
+			down[broker.Id] = struct{}{}
+		}
+	}
+	return down, nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// trackFailedBrokers returns the failed brokers of the cluster. The brokers which are down keep the time they have
+// been found down first, the replaced brokers are kept until their removal has finished.
+func trackFailedBrokers(cluster *v1beta1.KafkaCluster, downBrokers map[int32]struct{}, now time.Time) map[string]v1beta1.FailedBrokerStatus {
+	failedBrokers := make(map[string]v1beta1.FailedBrokerStatus, len(downBrokers))
+	for brokerID := range downBrokers {
+		id := strconv.Itoa(int(brokerID))
+		if previous, ok := cluster.Status.FailedBrokers[id]; ok {
+			failedBrokers[id] = previous
+			continue
+		}
+		failedBrokers[id] = v1beta1.FailedBrokerStatus{DownSince: metav1.Time{Time: now}}
+	}
+	for id, previous := range cluster.Status.FailedBrokers {
+		if previous.ReplacedBy == nil {
+			continue
+		}
+		if _, ok := cluster.Status.BrokersState[id]; ok {
+			failedBrokers[id] = previous
+		}
+	}
+	return failedBrokers
+}
+
+// replaceBroker returns the brokers of the cluster where the given broker is replaced by a new broker with the same
+// configuration and the next ID allowed by the broker ID policy apart from the reserved IDs, and the ID of the new broker
+func replaceBroker(cluster *v1beta1.KafkaCluster, brokerID int32, reservedIDs []int32) ([]v1beta1.Broker, int32) {
+	replacementID := cluster.GetNextBrokerID(reservedIDs...)
+	brokers := make([]v1beta1.Broker, 0, len(cluster.Spec.Brokers))
+	var replacement *v1beta1.Broker
+	for _, broker := range cluster.Spec.Brokers {
+		if broker.Id == brokerID {
+			replacement = broker.DeepCopy()
+			replacement.Id = replacementID
+			continue
+		}
+		brokers = append(brokers, broker)
+	}
+	if replacement != nil {
+		brokers = append(brokers, *replacement)
+	}
+	return brokers, replacementID
+}
@@ -0,0 +1,194 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestReplaceBroker(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfigGroup: "default"},
+				{Id: 1, BrokerConfigGroup: "spot", BrokerConfig: &v1beta1.BrokerConfig{Image: "kafka:custom"}},
+				{Id: 2, BrokerConfigGroup: "default"},
+			},
+		},
+	}
+
+	brokers, replacementID := replaceBroker(cluster, 1, nil)
+	assert.Equal(t, int32(3), replacementID)
+	assert.Equal(t, []v1beta1.Broker{
+		{Id: 0, BrokerConfigGroup: "default"},
+		{Id: 2, BrokerConfigGroup: "default"},
+		{Id: 3, BrokerConfigGroup: "spot", BrokerConfig: &v1beta1.BrokerConfig{Image: "kafka:custom"}},
+	}, brokers)
+
+	// The data of a former broker is still kept for ID 3
+	_, replacementID = replaceBroker(cluster, 1, []int32{3})
+	assert.Equal(t, int32(4), replacementID)
+}
+
+func TestHandleBrokerFailures(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	upscaled := v1beta1.BrokerState{GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleSucceeded}}
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0, BrokerConfigGroup: "default"}, {Id: 1, BrokerConfigGroup: "default"}},
+			BrokerFailurePolicy: &v1beta1.BrokerFailurePolicy{
+				GracePeriodSeconds: util.Int32Pointer(600),
+				Action:             v1beta1.BrokerFailureActionAlert,
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{"0": upscaled, "1": upscaled},
+		},
+	}
+	labels := apiutil.LabelsForKafka("kafka")
+	labels[v1beta1.BrokerIdLabelKey] = "0"
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka-0", Namespace: "kafka", Labels: labels},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, pod).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := KafkaClusterReconciler{Client: c, Recorder: recorder}
+	ctx := context.Background()
+
+	// The broker without a pod is tracked as down
+	assert.NoError(t, r.handleBrokerFailures(ctx, cluster))
+	assert.Len(t, cluster.Status.FailedBrokers, 1)
+	assert.False(t, cluster.Status.FailedBrokers["1"].GracePeriodExceeded)
+	assert.Empty(t, recorder.Events)
+
+	// The broker is only reported once the grace period has been exceeded
+	downSince := metav1.Time{Time: time.Now().Add(-time.Hour)}
+	cluster.Status.FailedBrokers["1"] = v1beta1.FailedBrokerStatus{DownSince: downSince}
+	assert.NoError(t, c.Status().Update(ctx, cluster))
+	assert.NoError(t, r.handleBrokerFailures(ctx, cluster))
+	assert.True(t, cluster.Status.FailedBrokers["1"].GracePeriodExceeded)
+	assert.Nil(t, cluster.Status.FailedBrokers["1"].ReplacedBy)
+	assert.Contains(t, <-recorder.Events, "BrokerFailureGracePeriodExceeded")
+	assert.Len(t, cluster.Spec.Brokers, 2)
+
+	// The broker is replaced by the Replace action
+	cluster.Spec.BrokerFailurePolicy.Action = v1beta1.BrokerFailureActionReplace
+	assert.NoError(t, r.handleBrokerFailures(ctx, cluster))
+	assert.Equal(t, util.Int32Pointer(2), cluster.Status.FailedBrokers["1"].ReplacedBy)
+	assert.Contains(t, <-recorder.Events, "BrokerReplaced")
+
+	stored := &v1beta1.KafkaCluster{}
+	assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(cluster), stored))
+	assert.Equal(t, []v1beta1.Broker{{Id: 0, BrokerConfigGroup: "default"}, {Id: 2, BrokerConfigGroup: "default"}}, stored.Spec.Brokers)
+	assert.Equal(t, downSince.Unix(), stored.Status.FailedBrokers["1"].DownSince.Unix())
+
+	// The replaced broker is forgotten once its removal has finished
+	delete(cluster.Status.BrokersState, "1")
+	assert.NoError(t, r.handleBrokerFailures(ctx, cluster))
+	assert.Empty(t, cluster.Status.FailedBrokers)
+}
+
+func TestHandleBrokerFailuresOutage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	upscaled := v1beta1.BrokerState{GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleSucceeded}}
+	downSince := metav1.Time{Time: time.Now().Add(-time.Hour)}
+	brokers := []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}}
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: brokers,
+			BrokerFailurePolicy: &v1beta1.BrokerFailurePolicy{
+				GracePeriodSeconds: util.Int32Pointer(600),
+				Action:             v1beta1.BrokerFailureActionReplace,
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{"0": upscaled, "1": upscaled, "2": upscaled},
+			FailedBrokers: map[string]v1beta1.FailedBrokerStatus{
+				"1": {DownSince: downSince},
+				"2": {DownSince: downSince},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	r := KafkaClusterReconciler{Client: c, Recorder: record.NewFakeRecorder(10)}
+
+	// None of the brokers is replaced while more than one of them is down
+	assert.NoError(t, r.handleBrokerFailures(context.Background(), cluster))
+	assert.Equal(t, brokers, cluster.Spec.Brokers)
+	assert.Len(t, cluster.Status.FailedBrokers, 3)
+	for _, failedBroker := range cluster.Status.FailedBrokers {
+		assert.Nil(t, failedBroker.ReplacedBy)
+	}
+}
+
+func TestBrokerFailureRequeueSeconds(t *testing.T) {
+	now := time.Now()
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			BrokerFailurePolicy: &v1beta1.BrokerFailurePolicy{
+				GracePeriodSeconds: util.Int32Pointer(600),
+				Action:             v1beta1.BrokerFailureActionAlert,
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			FailedBrokers: map[string]v1beta1.FailedBrokerStatus{
+				"0": {DownSince: metav1.Time{Time: now.Add(-time.Hour)}, GracePeriodExceeded: true},
+				"1": {DownSince: metav1.Time{Time: now.Add(-500 * time.Second)}},
+				"2": {DownSince: metav1.Time{Time: now.Add(-time.Minute)}},
+				"3": {DownSince: metav1.Time{Time: now.Add(-590 * time.Second)}, ReplacedBy: util.Int32Pointer(4)},
+			},
+		},
+	}
+
+	// The check is due when the first grace period passes
+	assert.Equal(t, int32(100), brokerFailureRequeueSeconds(cluster, now))
+
+	// The broker waiting to be replaced is polled
+	cluster.Spec.BrokerFailurePolicy.Action = v1beta1.BrokerFailureActionReplace
+	assert.Equal(t, int32(defaultRequeueIntervalInSeconds), brokerFailureRequeueSeconds(cluster, now))
+
+	delete(cluster.Status.FailedBrokers, "1")
+	delete(cluster.Status.FailedBrokers, "2")
+	delete(cluster.Status.FailedBrokers, "0")
+	assert.Zero(t, brokerFailureRequeueSeconds(cluster, now))
+
+	cluster.Spec.BrokerFailurePolicy = nil
+	assert.Zero(t, brokerFailureRequeueSeconds(cluster, now))
+}
@@ -0,0 +1,38 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// updateHighestBrokerID records the highest broker ID the KafkaCluster has ever had in its status, so that the
+// AlwaysIncrement broker ID policy can be enforced after the brokers have been removed from the cluster
+func (r *KafkaClusterReconciler) updateHighestBrokerID(ctx context.Context, cluster *v1beta1.KafkaCluster) error {
+	highest, ok := cluster.GetHighestBrokerID()
+	if !ok || (cluster.Status.HighestBrokerID != nil && *cluster.Status.HighestBrokerID == highest) {
+		return nil
+	}
+	if err := r.updateStatus(ctx, cluster, func(status *v1beta1.KafkaClusterStatus) {
+		status.HighestBrokerID = &highest
+	}); err != nil {
+		return errors.WrapIfWithDetails(err, "could not update the highest broker ID", "kafkaCluster", cluster.GetName())
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// applyCapacitySchedule scales the broker config groups to the broker counts of the rule of the capacity schedule in
+// effect by adding brokers to or removing the brokers with the highest IDs from the spec. The brokers are changed only
+// inside the maintenance windows and while no graceful up- or downscale is in progress, so that Cruise Control moves
+// the partitions of one change at a time.
+func (r *KafkaClusterReconciler) applyCapacitySchedule(ctx context.Context, cluster *v1beta1.KafkaCluster) error {
+	schedule := cluster.Spec.CapacitySchedule
+	if schedule == nil {
+		if cluster.Status.CapacitySchedule == nil {
+			return nil
+		}
+		return r.updateStatus(ctx, cluster, func(status *v1beta1.KafkaClusterStatus) {
+			status.CapacitySchedule = nil
+		})
+	}
+	log := logr.FromContextOrDiscard(ctx)
+	now := time.Now()
+
+	status := &v1beta1.CapacityScheduleStatus{}
+	if previous := cluster.Status.CapacitySchedule; previous != nil {
+		status.LastScaleTime = previous.LastScaleTime
+	}
+	rule, err := schedule.ActiveRule(now)
+	if err != nil {
+		return errors.WrapIf(err, "could not evaluate the capacity schedule")
+	}
+	if rule != nil {
+		status.ActiveRule = rule.Name
+		status.BrokerCounts = rule.BrokerCounts
+		retainedIDs, err := r.retainedDataBrokerIDs(ctx, cluster)
+		if err != nil {
+			return err
+		}
+		if brokers, changed := scheduledBrokers(cluster, rule.BrokerCounts, retainedIDs); changed {
+			inWindow, err := schedule.InMaintenanceWindow(now)
+			if err != nil {
+				return errors.WrapIf(err, "could not evaluate the maintenance windows of the capacity schedule")
+			}
+			switch {
+			case !inWindow:
+				status.Message = "waiting for a maintenance window"
+			case isGracefulScalingInProgress(cluster):
+				status.Message = "waiting for the graceful up- or downscale of the brokers to finish"
+			default:
+				cluster.Spec.Brokers = brokers
+				if _, err := r.updateAndFetchLatest(ctx, cluster); err != nil {
+					return errors.WrapIfWithDetails(err, "could not scale the brokers of the cluster", "rule", rule.Name)
+				}
+				log.Info("brokers of the cluster scaled by the capacity schedule", "rule", rule.Name, "brokerCounts", rule.BrokerCounts)
+				status.LastScaleTime = &metav1.Time{Time: now}
+			}
+		}
+	}
+
+	if reflect.DeepEqual(status, cluster.Status.CapacitySchedule) {
+		return nil
+	}
+	if err := r.updateStatus(ctx, cluster, func(clusterStatus *v1beta1.KafkaClusterStatus) {
+		clusterStatus.CapacitySchedule = status
+	}); err != nil {
+		return errors.WrapIfWithDetails(err, "could not update the capacity schedule status of the cluster", "kafkaCluster", cluster.GetName())
+	}
+	return nil
+}
+
+// scheduledBrokers returns the brokers of the cluster with the given number of brokers in the broker config groups.
+// The added brokers get the next IDs allowed by the broker ID policy apart from the reserved IDs, the brokers with the
+// highest IDs are removed first. The brokers of the parked groups are left unchanged. The second return value is
+// false when the brokers already match the counts.
+func scheduledBrokers(cluster *v1beta1.KafkaCluster, brokerCounts map[string]int32, reservedIDs []int32) ([]v1beta1.Broker, bool) {
+	groups := make([]string, 0, len(brokerCounts))
+	for group := range brokerCounts {
+		if !cluster.Spec.IsBrokerConfigGroupParked(group) {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+
+	removed := make(map[int32]struct{})
+	var added []v1beta1.Broker
+	scaled := cluster.DeepCopy()
+	for _, group := range groups {
+		brokerIDs := brokerIDsOfGroup(cluster, group)
+		count := int(brokerCounts[group])
+		for i := len(brokerIDs) - 1; i >= count; i-- {
+			removed[brokerIDs[i]] = struct{}{}
+		}
+		for i := len(brokerIDs); i < count; i++ {
+			broker := v1beta1.Broker{Id: scaled.GetNextBrokerID(reservedIDs...), BrokerConfigGroup: group}
+			scaled.Spec.Brokers = append(scaled.Spec.Brokers, broker)
+			added = append(added, broker)
+		}
+	}
+	if len(removed) == 0 && len(added) == 0 {
+		return nil, false
+	}
+
+	brokers := make([]v1beta1.Broker, 0, len(cluster.Spec.Brokers)+len(added))
+	for _, broker := range cluster.Spec.Brokers {
+		if _, ok := removed[broker.Id]; !ok {
+			brokers = append(brokers, broker)
+		}
+	}
+	return append(brokers, added...), true
+}
+
+// isGracefulScalingInProgress returns true when a graceful up- or downscale of a broker is required or running
+func isGracefulScalingInProgress(cluster *v1beta1.KafkaCluster) bool {
+	for brokerID, brokerState := range cluster.Status.BrokersState {
+		if brokerState.GracefulActionState.CruiseControlState.IsActive() {
+			return true
+		}
+		// The removed brokers are kept in the status until their pods have been deleted
+		if id, err := strconv.Atoi(brokerID); err == nil && !isBrokerInSpec(cluster, int32(id)) &&
+			brokerState.GracefulActionState.CruiseControlState != "" {
+			return true
+		}
+	}
+	return false
+}
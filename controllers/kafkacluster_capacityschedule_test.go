@@ -0,0 +1,115 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestScheduledBrokers(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfigGroup: "default"},
+				{Id: 1, BrokerConfigGroup: "default"},
+				{Id: 2, BrokerConfigGroup: "spot"},
+				{Id: 3, BrokerConfigGroup: "spot"},
+				{Id: 4, BrokerConfigGroup: "spot"},
+			},
+			ParkedBrokerConfigGroups: []string{"parked"},
+		},
+	}
+
+	_, changed := scheduledBrokers(cluster, map[string]int32{"default": 2, "spot": 3, "parked": 5}, nil)
+	assert.False(t, changed)
+
+	brokers, changed := scheduledBrokers(cluster, map[string]int32{"default": 3, "spot": 1}, nil)
+	assert.True(t, changed)
+	assert.Equal(t, []v1beta1.Broker{
+		{Id: 0, BrokerConfigGroup: "default"},
+		{Id: 1, BrokerConfigGroup: "default"},
+		{Id: 2, BrokerConfigGroup: "spot"},
+		{Id: 5, BrokerConfigGroup: "default"},
+	}, brokers)
+
+	// The IDs of the former brokers whose data is still kept are skipped
+	brokers, _ = scheduledBrokers(cluster, map[string]int32{"default": 3, "spot": 3}, []int32{5, 6})
+	assert.Equal(t, v1beta1.Broker{Id: 7, BrokerConfigGroup: "default"}, brokers[len(brokers)-1])
+}
+
+func TestApplyCapacitySchedule(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0, BrokerConfigGroup: "default"}},
+			CapacitySchedule: &v1beta1.CapacitySchedule{
+				Rules: []v1beta1.CapacityScheduleRule{{Name: "always", BrokerCounts: map[string]int32{"default": 2}}},
+				// The window is empty so the brokers cannot be changed
+				MaintenanceWindows: []v1beta1.WeeklyTimeWindow{{Start: "00:00", End: "00:00"}},
+			},
+		},
+	}
+	// The data of the former broker 1 is still kept
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Name:      "kafka-1-storage",
+		Namespace: "kafka",
+		Labels:    apiutil.MergeLabels(apiutil.LabelsForKafka("kafka"), map[string]string{v1beta1.BrokerIdLabelKey: "1"}),
+	}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, pvc).Build()
+	r := KafkaClusterReconciler{Client: c}
+	ctx := context.Background()
+
+	assert.NoError(t, r.applyCapacitySchedule(ctx, cluster))
+	assert.Equal(t, "always", cluster.Status.CapacitySchedule.ActiveRule)
+	assert.Equal(t, "waiting for a maintenance window", cluster.Status.CapacitySchedule.Message)
+	assert.Len(t, cluster.Spec.Brokers, 1)
+
+	// The brokers are not changed while a broker is being added
+	cluster.Spec.CapacitySchedule.MaintenanceWindows = nil
+	cluster.Status.BrokersState = map[string]v1beta1.BrokerState{
+		"0": {GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleRunning}},
+	}
+	assert.NoError(t, c.Update(ctx, cluster))
+	assert.NoError(t, r.applyCapacitySchedule(ctx, cluster))
+	assert.Equal(t, "waiting for the graceful up- or downscale of the brokers to finish", cluster.Status.CapacitySchedule.Message)
+
+	cluster.Status.BrokersState["0"] = v1beta1.BrokerState{
+		GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleSucceeded},
+	}
+	assert.NoError(t, c.Update(ctx, cluster))
+	assert.NoError(t, r.applyCapacitySchedule(ctx, cluster))
+	assert.Empty(t, cluster.Status.CapacitySchedule.Message)
+	assert.NotNil(t, cluster.Status.CapacitySchedule.LastScaleTime)
+
+	stored := &v1beta1.KafkaCluster{}
+	assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(cluster), stored))
+	assert.Equal(t, []v1beta1.Broker{{Id: 0, BrokerConfigGroup: "default"}, {Id: 2, BrokerConfigGroup: "default"}}, stored.Spec.Brokers)
+	assert.Equal(t, "always", stored.Status.CapacitySchedule.ActiveRule)
+}
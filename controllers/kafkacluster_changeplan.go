@@ -0,0 +1,104 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources/kafka"
+)
+
+// planChanges computes the plan of the pending changes of the KafkaCluster into its status instead of applying them.
+// The plan is published in an event as well when it differs from the previous one.
+func (r *KafkaClusterReconciler) planChanges(ctx context.Context, instance *v1beta1.KafkaCluster) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	plan, err := kafka.New(r.Client, r.DirectClient, instance, r.KafkaClientProvider, r.Recorder).Plan(ctx, log)
+	if err != nil {
+		log.Error(err, "could not compute the plan of the changes")
+		plan.Error = err.Error()
+	}
+
+	if !isSameChangePlan(instance.Status.ChangePlan, plan) {
+		if err := r.updateStatus(ctx, instance, func(status *v1beta1.KafkaClusterStatus) {
+			status.ChangePlan = plan
+		}); err != nil {
+			return requeueWithError(log, "could not update the change plan of the KafkaCluster", err)
+		}
+		if r.Recorder != nil {
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "ChangePlanned", changePlanSummary(plan))
+		}
+	}
+
+	if plan.Error != "" {
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+	return reconciled()
+}
+
+// clearChangePlan removes the plan computed during a previous dry-run from the status
+func (r *KafkaClusterReconciler) clearChangePlan(ctx context.Context, instance *v1beta1.KafkaCluster) error {
+	if instance.Status.ChangePlan == nil {
+		return nil
+	}
+	return r.updateStatus(ctx, instance, func(status *v1beta1.KafkaClusterStatus) {
+		status.ChangePlan = nil
+	})
+}
+
+// isSameChangePlan returns true when the plans only differ in the time they have been computed
+func isSameChangePlan(current, plan *v1beta1.KafkaClusterChangePlan) bool {
+	if current == nil || plan == nil {
+		return current == plan
+	}
+	withoutTime := current.DeepCopy()
+	withoutTime.ComputedAt = plan.ComputedAt
+	return equality.Semantic.DeepEqual(withoutTime, plan)
+}
+
+func changePlanSummary(plan *v1beta1.KafkaClusterChangePlan) string {
+	var parts []string
+	if len(plan.BrokersToRestart) > 0 {
+		parts = append(parts, fmt.Sprintf("restart brokers %v", plan.BrokersToRestart))
+	}
+	if len(plan.BrokersToAdd) > 0 {
+		parts = append(parts, fmt.Sprintf("add brokers %v", plan.BrokersToAdd))
+	}
+	if len(plan.BrokersToRemove) > 0 {
+		parts = append(parts, fmt.Sprintf("remove brokers %v", plan.BrokersToRemove))
+	}
+	for _, operation := range plan.CruiseControlOperations {
+		parts = append(parts, fmt.Sprintf("create %s CruiseControlOperation for brokers %v", operation.Operation, operation.BrokerIDs))
+	}
+	if len(plan.Resources) > 0 {
+		parts = append(parts, fmt.Sprintf("create or update %d resources", len(plan.Resources)))
+	}
+	summary := "no changes"
+	if len(parts) > 0 {
+		summary = strings.Join(parts, ", ")
+	}
+	if plan.Error != "" {
+		summary += fmt.Sprintf(" (incomplete: %s)", plan.Error)
+	}
+	return fmt.Sprintf("dry-run of generation %d: %s", plan.ObservedGeneration, summary)
+}
@@ -24,9 +24,11 @@ import (
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -36,6 +38,7 @@ import (
 
 	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/ccoperation"
 	"github.com/banzaicloud/koperator/pkg/errorfactory"
 	"github.com/banzaicloud/koperator/pkg/k8sutil"
 	"github.com/banzaicloud/koperator/pkg/kafkaclient"
@@ -46,7 +49,9 @@ import (
 	"github.com/banzaicloud/koperator/pkg/resources/envoy"
 	"github.com/banzaicloud/koperator/pkg/resources/istioingress"
 	"github.com/banzaicloud/koperator/pkg/resources/kafka"
+	"github.com/banzaicloud/koperator/pkg/resources/kafkaexporter"
 	"github.com/banzaicloud/koperator/pkg/resources/kafkamonitoring"
+	"github.com/banzaicloud/koperator/pkg/resources/networkpolicy"
 	"github.com/banzaicloud/koperator/pkg/resources/nodeportexternalaccess"
 	"github.com/banzaicloud/koperator/pkg/util"
 )
@@ -55,12 +60,17 @@ var clusterFinalizer = "finalizer.kafkaclusters.kafka.banzaicloud.io"
 var clusterTopicsFinalizer = "topics.kafkaclusters.kafka.banzaicloud.io"
 var clusterUsersFinalizer = "users.kafkaclusters.kafka.banzaicloud.io"
 
+// clusterDeletionCCOperationTimeout bounds the time the deletion of a KafkaCluster waits for its running
+// CruiseControlOperations to be stopped, so an unreachable Cruise Control cannot block the deletion forever
+var clusterDeletionCCOperationTimeout = 10 * time.Minute
+
 // KafkaClusterReconciler reconciles a KafkaCluster object
 type KafkaClusterReconciler struct {
 	client.Client
 	DirectClient        client.Reader
 	Namespaces          []string
 	KafkaClientProvider kafkaclient.Provider
+	Recorder            record.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a KafkaCluster object and makes changes based on the state read
@@ -69,13 +79,17 @@ type KafkaClusterReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete;patch
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="policy",resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaclusters/status,verbs=get;update;patch
@@ -94,6 +108,7 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, request ctrl.Req
 		if apiErrors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			clusterBacklogs.forget(request.NamespacedName)
 			return reconciled()
 		}
 		// Error reading the object - requeue the request.
@@ -105,20 +120,54 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, request ctrl.Req
 		return r.checkFinalizers(ctx, instance)
 	}
 
+	// Every return before the last steps of the reconcile leaves changes of the KafkaCluster to be applied later
+	reconcileStart := time.Now()
+	reconcileCompleted := false
+	defer func() {
+		clusterBacklogs.setReconcileResult(request.NamespacedName, reconcileStart, reconcileCompleted)
+	}()
+
+	// The pending changes are not applied while the dry-run annotation is set, only their plan is published
+	if instance.IsDryRun() {
+		return r.planChanges(ctx, instance)
+	}
+	if err := r.clearChangePlan(ctx, instance); err != nil {
+		return requeueWithError(log, "could not clear the change plan of the KafkaCluster", err)
+	}
+
 	if instance.Status.State != v1beta1.KafkaClusterRollingUpgrading {
 		if err := k8sutil.UpdateCRStatus(r.Client, instance, v1beta1.KafkaClusterReconciling, log); err != nil {
 			return requeueWithError(log, err.Error(), err)
 		}
 	}
 
+	if err := r.updateHighestBrokerID(ctx, instance); err != nil {
+		return requeueWithError(log, "failed to update the highest broker ID", err)
+	}
+
+	parkingInProgress, err := r.reconcileBrokerGroupParking(ctx, instance)
+	if err != nil {
+		return requeueWithError(log, "failed to reconcile the parking of the broker config groups", err)
+	}
+
+	if err := r.applyCapacitySchedule(ctx, instance); err != nil {
+		return requeueWithError(log, "failed to apply the capacity schedule", err)
+	}
+
+	if err := r.handleBrokerFailures(ctx, instance); err != nil {
+		return requeueWithError(log, "failed to handle the failed brokers", err)
+	}
+
 	reconcilers := []resources.ComponentReconciler{
 		envoy.New(r.Client, instance),
 		istioingress.New(r.Client, instance),
 		nodeportexternalaccess.New(r.Client, instance),
 		kafkamonitoring.New(r.Client, instance),
 		cruisecontrolmonitoring.New(r.Client, instance),
-		kafka.New(r.Client, r.DirectClient, instance, r.KafkaClientProvider),
-		cruisecontrol.New(r.Client, instance),
+		kafka.New(r.Client, r.DirectClient, instance, r.KafkaClientProvider, r.Recorder),
+		cruisecontrol.New(r.Client, instance, r.KafkaClientProvider, r.Recorder),
+		kafkaexporter.New(r.Client, instance),
+		networkpolicy.New(r.Client, instance),
 	}
 
 	for _, rec := range reconcilers {
@@ -182,15 +231,79 @@ func (r *KafkaClusterReconciler) Reconcile(ctx context.Context, request ctrl.Req
 
 	// Update rolling upgrade last successful state
 	if instance.Status.State == v1beta1.KafkaClusterRollingUpgrading {
+		completed, err := kafka.RunUpgradeHook(ctx, r.Client, log, instance,
+			instance.Spec.RollingUpgradeConfig.PostUpgradeHook, kafka.PostUpgradeHookPhase)
+		if err != nil {
+			return requeueWithError(log, "post-upgrade hook failed", err)
+		}
+		if !completed {
+			log.Info("waiting for the post-upgrade hook job to complete")
+			return requeueAfter(defaultRequeueIntervalInSeconds)
+		}
 		if err := k8sutil.UpdateRollingUpgradeState(r.Client, instance, time.Now(), log); err != nil {
 			return requeueWithError(log, err.Error(), err)
 		}
 	}
 
+	if instance.Spec.RollingUpgradeConfig.PreUpgradeHook != nil || instance.Spec.RollingUpgradeConfig.PostUpgradeHook != nil {
+		if err := kafka.DeleteUpgradeHookJobs(ctx, r.Client, log, instance); err != nil {
+			return requeueWithError(log, "failed to delete the upgrade hook jobs", err)
+		}
+	}
+
 	if err := k8sutil.UpdateCRStatus(r.Client, instance, v1beta1.KafkaClusterRunning, log); err != nil {
 		return requeueWithError(log, err.Error(), err)
 	}
 
+	if err := r.requestPreferredLeaderElection(ctx, instance); err != nil {
+		return requeueWithError(log, "failed to request preferred leader election", err)
+	}
+
+	if err := r.checkZoneBalance(ctx, instance); err != nil {
+		return requeueWithError(log, "failed to check the zone balance of the cluster", err)
+	}
+
+	if err := r.checkReplicationFactor(ctx, instance); err != nil {
+		return requeueWithError(log, "failed to check the replication factor of the partitions", err)
+	}
+
+	if err := r.cleanUpOrphanedResources(ctx, instance); err != nil {
+		return requeueWithError(log, "failed to clean up the orphaned resources of the removed brokers", err)
+	}
+	reconcileCompleted = true
+
+	var requeueSeconds int32
+	// The brokers need to be asked periodically to reload the keystores of the SPIFFE listeners to pick up the rotated SVIDs
+	if len(instance.Spec.GetSPIFFEListeners()) > 0 {
+		requeueSeconds = instance.Spec.SPIFFEConfig.GetKeystoreReloadIntervalSeconds()
+	}
+	if zoneBalancing := instance.Spec.ZoneBalancing; zoneBalancing != nil &&
+		(requeueSeconds == 0 || zoneBalancing.GetCheckIntervalSeconds() < requeueSeconds) {
+		requeueSeconds = zoneBalancing.GetCheckIntervalSeconds()
+	}
+	// The capacity schedule is evaluated periodically to follow its calendar
+	if instance.Spec.CapacitySchedule != nil &&
+		(requeueSeconds == 0 || v1beta1.DefaultCapacityScheduleCheckIntervalSeconds < requeueSeconds) {
+		requeueSeconds = v1beta1.DefaultCapacityScheduleCheckIntervalSeconds
+	}
+	// The demotion of the parked brokers and the rejoining of the unparked ones are polled
+	if parkingInProgress && (requeueSeconds == 0 || int32(defaultRequeueIntervalInSeconds) < requeueSeconds) {
+		requeueSeconds = int32(defaultRequeueIntervalInSeconds)
+	}
+	// The orphaned resources are deleted once their retention period has passed
+	if len(instance.Status.OrphanedResources) > 0 &&
+		(requeueSeconds == 0 || v1beta1.DefaultOrphanedResourceCheckIntervalSeconds < requeueSeconds) {
+		requeueSeconds = v1beta1.DefaultOrphanedResourceCheckIntervalSeconds
+	}
+	// The failed brokers are acted on once their grace period has passed
+	if seconds := brokerFailureRequeueSeconds(instance, time.Now()); seconds > 0 &&
+		(requeueSeconds == 0 || seconds < requeueSeconds) {
+		requeueSeconds = seconds
+	}
+	if requeueSeconds > 0 {
+		return requeueAfter(int(requeueSeconds))
+	}
+
 	return reconciled()
 }
 
@@ -203,7 +316,30 @@ func (r *KafkaClusterReconciler) checkFinalizers(ctx context.Context, cluster *v
 		return reconciled()
 	}
 
-	var err error
+	// Running Cruise Control tasks are stopped before the cluster is torn down, otherwise they would be left orphaned in Cruise Control
+	stopped, err := r.stopRunningCCOperations(ctx, cluster)
+	if err != nil {
+		return requeueWithError(log, "failed to stop running CruiseControlOperations", err)
+	}
+	if !stopped {
+		log.Info("Still waiting for the running CruiseControlOperations to be stopped")
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+
+	completed, err := r.runFinalSnapshotHook(ctx, log, cluster)
+	if err != nil {
+		return requeueWithError(log, "final snapshot hook of the kafkacluster did not succeed", err)
+	}
+	if !completed {
+		log.Info("Still waiting for the final snapshot hook to complete")
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+
+	if cluster.Spec.RetainsData() {
+		if err := r.retainBrokerPVCs(ctx, log, cluster); err != nil {
+			return requeueWithError(log, "failed to retain the persistent volume claims of the brokers", err)
+		}
+	}
 
 	var namespaces []string
 	if r.Namespaces == nil {
@@ -317,6 +453,52 @@ func (r *KafkaClusterReconciler) checkFinalizers(ctx context.Context, cluster *v
 	return ctrl.Result{}, nil
 }
 
+// stopRunningCCOperations requests Cruise Control to stop the execution of the running CruiseControlOperations of the
+// KafkaCluster being deleted. It returns true when there is no running operation or the deletion has waited long enough.
+func (r *KafkaClusterReconciler) stopRunningCCOperations(ctx context.Context, cluster *v1beta1.KafkaCluster) (bool, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	var ccOperations v1alpha1.CruiseControlOperationList
+	if err := r.Client.List(ctx, &ccOperations,
+		client.InNamespace(cluster.GetNamespace()), client.MatchingLabels{v1beta1.KafkaCRLabelKey: cluster.GetName()}); err != nil {
+		return false, errors.WrapIfWithDetails(err, "failed to list CruiseControlOperations", "kafkaCluster", cluster.GetName())
+	}
+
+	var running []string
+	stopRequested := false
+	for i := range ccOperations.Items {
+		operation := &ccOperations.Items[i]
+		// The stop request is kept until the cluster is deleted, it is only repeated when it failed
+		if operation.CurrentTaskOperation() == v1alpha1.OperationStopExecution {
+			stopRequested = stopRequested || !operation.IsFailed()
+			continue
+		}
+		if operation.IsCurrentTaskRunning() {
+			running = append(running, operation.GetName())
+		}
+	}
+	if len(running) == 0 {
+		return true, nil
+	}
+
+	if deletedAt := cluster.GetDeletionTimestamp(); deletedAt != nil && time.Since(deletedAt.Time) > clusterDeletionCCOperationTimeout {
+		log.Info("giving up waiting for the running CruiseControlOperations to be stopped", "operations", running)
+		return true, nil
+	}
+
+	if !stopRequested {
+		log.Info("stopping the running CruiseControlOperations", "operations", running)
+		_, err := ccoperation.NewStopExecution().
+			ForCluster(cluster).
+			OwnedBy(cluster, r.Client.Scheme()).
+			Create(ctx, r.Client)
+		if err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
 func topicListToStrSlice(list v1alpha1.KafkaTopicList) []string {
 	names := make([]string, 0)
 	for _, topic := range list.Items {
@@ -351,17 +533,26 @@ func (r *KafkaClusterReconciler) updateAndFetchLatest(ctx context.Context, clust
 	return cluster, nil
 }
 
+// updateStatus patches the status of the KafkaCluster with the changes made by mutate and keeps its TypeMeta, which is
+// needed to set the owner references of the resources reconciled after the update
+func (r *KafkaClusterReconciler) updateStatus(ctx context.Context, cluster *v1beta1.KafkaCluster, mutate func(status *v1beta1.KafkaClusterStatus)) error {
+	return k8sutil.PatchKafkaClusterStatus(ctx, r.Client, cluster, mutate)
+}
+
 // SetupKafkaClusterWithManager registers kafka cluster controller to the manager
 func SetupKafkaClusterWithManager(mgr ctrl.Manager) *ctrl.Builder {
 	log := mgr.GetLogger()
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.KafkaCluster{}).
 		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
 		Named("KafkaCluster")
 
 	kafkaWatches(builder)
 	envoyWatches(builder)
 	cruiseControlWatches(builder)
+	log4jConfigMapWatches(builder, mgr.GetClient(), log)
+	externalListenerServiceWatches(builder, mgr.GetClient(), log)
 
 	builder.WithEventFilter(
 		predicate.Funcs{
@@ -385,6 +576,9 @@ func SetupKafkaClusterWithManager(mgr ctrl.Manager) *ctrl.Builder {
 					if !reflect.DeepEqual(oldObj.Spec, newObj.Spec) ||
 						oldObj.GetDeletionTimestamp() != newObj.GetDeletionTimestamp() ||
 						oldObj.GetGeneration() != newObj.GetGeneration() ||
+						oldObj.GetAnnotations()[v1beta1.RestartAtAnnotationKey] != newObj.GetAnnotations()[v1beta1.RestartAtAnnotationKey] ||
+						oldObj.GetAnnotations()[v1beta1.PreferredLeaderElectionAnnotationKey] != newObj.GetAnnotations()[v1beta1.PreferredLeaderElectionAnnotationKey] ||
+						oldObj.GetAnnotations()[v1beta1.DryRunAnnotationKey] != newObj.GetAnnotations()[v1beta1.DryRunAnnotationKey] ||
 						!reflect.DeepEqual(oldObj.Status.BrokersState, newObj.Status.BrokersState) {
 						return true
 					}
@@ -403,7 +597,8 @@ func kafkaWatches(builder *ctrl.Builder) *ctrl.Builder {
 		Owns(&corev1.ConfigMap{}).
 		Owns(&policyv1.PodDisruptionBudget{}).
 		Owns(&corev1.PersistentVolumeClaim{}).
-		Owns(&corev1.Pod{})
+		Owns(&corev1.Pod{}).
+		Owns(&networkingv1.NetworkPolicy{})
 }
 
 func envoyWatches(builder *ctrl.Builder) *ctrl.Builder {
@@ -0,0 +1,121 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestStopRunningCCOperations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	newOperation := func(name string, operationType v1alpha1.CruiseControlTaskOperation, state v1beta1.CruiseControlUserTaskState) *v1alpha1.CruiseControlOperation {
+		return &v1alpha1.CruiseControlOperation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "kafka",
+				Labels:    map[string]string{v1beta1.KafkaCRLabelKey: "kafka"},
+			},
+			Status: v1alpha1.CruiseControlOperationStatus{
+				CurrentTask: &v1alpha1.CruiseControlTask{
+					Operation: operationType,
+					State:     state,
+				},
+			},
+		}
+	}
+	newCluster := func(deletedAgo time.Duration) *v1beta1.KafkaCluster {
+		return &v1beta1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "kafka",
+				Namespace:         "kafka",
+				UID:               "uid",
+				DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-deletedAgo)},
+			},
+		}
+	}
+
+	listStopOperations := func(c client.Client) int {
+		var operations v1alpha1.CruiseControlOperationList
+		assert.NoError(t, c.List(context.Background(), &operations))
+		var count int
+		for i := range operations.Items {
+			if operations.Items[i].CurrentTaskOperation() == v1alpha1.OperationStopExecution {
+				count++
+			}
+		}
+		return count
+	}
+
+	t.Run("no running operation", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newOperation("pending", v1alpha1.OperationRebalance, ""),
+			newOperation("completed", v1alpha1.OperationRebalance, v1beta1.CruiseControlTaskCompleted),
+		).Build()
+		r := KafkaClusterReconciler{Client: c}
+		stopped, err := r.stopRunningCCOperations(context.Background(), newCluster(0))
+		assert.NoError(t, err)
+		assert.True(t, stopped)
+		assert.Equal(t, 0, listStopOperations(c))
+	})
+
+	t.Run("running operation is stopped once", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newOperation("running", v1alpha1.OperationRemoveBroker, v1beta1.CruiseControlTaskActive),
+		).Build()
+		r := KafkaClusterReconciler{Client: c}
+		for i := 0; i < 2; i++ {
+			stopped, err := r.stopRunningCCOperations(context.Background(), newCluster(0))
+			assert.NoError(t, err)
+			assert.False(t, stopped)
+		}
+		assert.Equal(t, 1, listStopOperations(c))
+	})
+
+	t.Run("finished stop request is not repeated", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newOperation("running", v1alpha1.OperationRemoveBroker, v1beta1.CruiseControlTaskInExecution),
+			newOperation("stop", v1alpha1.OperationStopExecution, v1beta1.CruiseControlTaskCompleted),
+		).Build()
+		r := KafkaClusterReconciler{Client: c}
+		stopped, err := r.stopRunningCCOperations(context.Background(), newCluster(0))
+		assert.NoError(t, err)
+		assert.False(t, stopped)
+		assert.Equal(t, 1, listStopOperations(c))
+	})
+
+	t.Run("waiting is bounded", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newOperation("running", v1alpha1.OperationRemoveBroker, v1beta1.CruiseControlTaskInExecution),
+		).Build()
+		r := KafkaClusterReconciler{Client: c}
+		stopped, err := r.stopRunningCCOperations(context.Background(), newCluster(2*clusterDeletionCCOperationTimeout))
+		assert.NoError(t, err)
+		assert.True(t, stopped)
+	})
+}
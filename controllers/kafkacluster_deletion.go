@@ -0,0 +1,68 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources/kafka"
+)
+
+const finalSnapshotHookJobTemplate = "%s-final-snapshot"
+
+// runFinalSnapshotHook creates the Job of the final snapshot hook of the KafkaCluster being deleted and returns true
+// once it has completed successfully. A failed Job blocks the deletion, it can be skipped by removing the hook from
+// the KafkaCluster.
+func (r *KafkaClusterReconciler) runFinalSnapshotHook(ctx context.Context, log logr.Logger, cluster *v1beta1.KafkaCluster) (bool, error) {
+	return kafka.RunHookJob(ctx, r.Client, log, cluster, cluster.Spec.FinalSnapshotHook,
+		fmt.Sprintf(finalSnapshotHookJobTemplate, cluster.GetName()))
+}
+
+// retainBrokerPVCs removes the owner reference of the KafkaCluster from the PersistentVolumeClaims of the brokers
+// so that they are not garbage collected together with the KafkaCluster
+func (r *KafkaClusterReconciler) retainBrokerPVCs(ctx context.Context, log logr.Logger, cluster *v1beta1.KafkaCluster) error {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.Client.List(ctx, pvcList, client.InNamespace(cluster.GetNamespace()),
+		client.MatchingLabels(apiutil.LabelsForKafka(cluster.GetName()))); err != nil {
+		return errors.WrapIf(err, "could not list the persistent volume claims of the brokers")
+	}
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		ownerReferences := make([]metav1.OwnerReference, 0, len(pvc.GetOwnerReferences()))
+		for _, ownerReference := range pvc.GetOwnerReferences() {
+			if ownerReference.UID != cluster.GetUID() {
+				ownerReferences = append(ownerReferences, ownerReference)
+			}
+		}
+		if len(ownerReferences) == len(pvc.GetOwnerReferences()) {
+			continue
+		}
+		pvc.SetOwnerReferences(ownerReferences)
+		if err := r.Client.Update(ctx, pvc); err != nil {
+			return errors.WrapIfWithDetails(err, "could not retain persistent volume claim", "pvc", pvc.GetName())
+		}
+		log.Info("persistent volume claim is retained", "pvc", pvc.GetName())
+	}
+	return nil
+}
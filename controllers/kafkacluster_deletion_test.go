@@ -0,0 +1,127 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func newDeletionTestScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, batchv1.AddToScheme(scheme))
+	return scheme
+}
+
+func newDeletionTestCluster() *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", UID: "cluster-uid"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", ContainerPort: 29092},
+						UsedForInnerBrokerCommunication: true,
+					},
+				},
+			},
+			FinalSnapshotHook: &v1beta1.HookJob{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "snapshot", Image: "snapshot:latest"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRunFinalSnapshotHook(t *testing.T) {
+	cluster := newDeletionTestCluster()
+	c := fake.NewClientBuilder().WithScheme(newDeletionTestScheme(t)).Build()
+	r := KafkaClusterReconciler{Client: c}
+	ctx := context.Background()
+
+	completed, err := r.runFinalSnapshotHook(ctx, logr.Discard(), cluster)
+	assert.NoError(t, err)
+	assert.False(t, completed)
+
+	job := &batchv1.Job{}
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "kafka-final-snapshot"}, job))
+	assert.Equal(t, int32(3), *job.Spec.BackoffLimit)
+	assert.Equal(t, corev1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+	assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env,
+		corev1.EnvVar{Name: "KAFKA_BOOTSTRAP_SERVERS", Value: "kafka-all-broker.kafka.svc.cluster.local:29092"})
+	assert.True(t, metav1.IsControlledBy(job, cluster))
+
+	// The deletion is blocked while the job is failed
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"}}
+	assert.NoError(t, c.Status().Update(ctx, job))
+	completed, err = r.runFinalSnapshotHook(ctx, logr.Discard(), cluster)
+	assert.Error(t, err)
+	assert.False(t, completed)
+
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	assert.NoError(t, c.Status().Update(ctx, job))
+	completed, err = r.runFinalSnapshotHook(ctx, logr.Discard(), cluster)
+	assert.NoError(t, err)
+	assert.True(t, completed)
+
+	// Without the hook the deletion is not blocked
+	cluster.Spec.FinalSnapshotHook = nil
+	completed, err = r.runFinalSnapshotHook(ctx, logr.Discard(), cluster)
+	assert.NoError(t, err)
+	assert.True(t, completed)
+}
+
+func TestRetainBrokerPVCs(t *testing.T) {
+	cluster := newDeletionTestCluster()
+	controller := true
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kafka-0-storage-0",
+			Namespace: "kafka",
+			Labels:    apiutil.LabelsForKafka("kafka"),
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "KafkaCluster", Name: "kafka", UID: cluster.GetUID(), Controller: &controller},
+				{Kind: "ConfigMap", Name: "other", UID: "other-uid"},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(newDeletionTestScheme(t)).WithObjects(pvc).Build()
+	r := KafkaClusterReconciler{Client: c}
+	ctx := context.Background()
+
+	assert.NoError(t, r.retainBrokerPVCs(ctx, logr.Discard(), cluster))
+
+	stored := &corev1.PersistentVolumeClaim{}
+	assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pvc), stored))
+	assert.Equal(t, []metav1.OwnerReference{{Kind: "ConfigMap", Name: "other", UID: "other-uid"}}, stored.GetOwnerReferences())
+}
@@ -0,0 +1,52 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/ccoperation"
+)
+
+// requestPreferredLeaderElection creates a preferred_leader_election CruiseControlOperation when the value of the
+// preferred-leader-election annotation of the KafkaCluster differs from the one processed last. It is called once
+// the cluster is running so that the leaderships are restored after the maintenance of the brokers has finished.
+func (r *KafkaClusterReconciler) requestPreferredLeaderElection(ctx context.Context, cluster *v1beta1.KafkaCluster) error {
+	requested, ok := cluster.GetAnnotations()[v1beta1.PreferredLeaderElectionAnnotationKey]
+	if !ok || requested == cluster.Status.PreferredLeaderElection {
+		return nil
+	}
+
+	operation, err := ccoperation.NewPreferredLeaderElection().
+		ForCluster(cluster).
+		OwnedBy(cluster, r.Client.Scheme()).
+		Create(ctx, r.Client)
+	if err != nil {
+		return err
+	}
+	logr.FromContextOrDiscard(ctx).Info("preferred leader election requested", "operation", operation.GetName(), "request", requested)
+
+	if err := r.updateStatus(ctx, cluster, func(status *v1beta1.KafkaClusterStatus) {
+		status.PreferredLeaderElection = requested
+	}); err != nil {
+		return errors.WrapIfWithDetails(err, "could not update the processed preferred leader election request",
+			"kafkaCluster", cluster.GetName())
+	}
+	return nil
+}
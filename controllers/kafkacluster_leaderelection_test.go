@@ -0,0 +1,59 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestRequestPreferredLeaderElection(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "kafka",
+			Namespace:   "kafka",
+			Annotations: map[string]string{v1beta1.PreferredLeaderElectionAnnotationKey: "2023-05-01T10:00:00Z"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	r := KafkaClusterReconciler{Client: c}
+	ctx := context.Background()
+
+	assert.NoError(t, r.requestPreferredLeaderElection(ctx, cluster))
+	assert.Equal(t, "2023-05-01T10:00:00Z", cluster.Status.PreferredLeaderElection)
+
+	var operations v1alpha1.CruiseControlOperationList
+	assert.NoError(t, c.List(ctx, &operations))
+	assert.Len(t, operations.Items, 1)
+	assert.Equal(t, v1alpha1.OperationPreferredLeaderElection, operations.Items[0].CurrentTaskOperation())
+	assert.Equal(t, "kafka", operations.Items[0].GetLabels()[v1beta1.KafkaCRLabelKey])
+
+	// The processed request is not repeated
+	assert.NoError(t, r.requestPreferredLeaderElection(ctx, cluster))
+	assert.NoError(t, c.List(ctx, &operations))
+	assert.Len(t, operations.Items, 1)
+}
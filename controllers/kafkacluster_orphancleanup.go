@@ -0,0 +1,172 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+const (
+	orphanedResourceKindPVC     = "PersistentVolumeClaim"
+	orphanedResourceKindService = "Service"
+)
+
+// cleanUpOrphanedResources deletes the PersistentVolumeClaims and Services created by the operator for the brokers
+// which have been removed from the cluster, once they have been orphaned for longer than the retention period.
+// The removal of a broker is considered to be completed when the broker is neither in the spec nor in the status of
+// the cluster and it has no pod, so the resources of the brokers being downscaled gracefully or of the parked
+// brokers are never touched.
+func (r *KafkaClusterReconciler) cleanUpOrphanedResources(ctx context.Context, cluster *v1beta1.KafkaCluster) error {
+	config := cluster.Spec.OrphanedResourceCleanup
+	if config == nil {
+		if cluster.Status.OrphanedResources == nil {
+			return nil
+		}
+		return r.updateStatus(ctx, cluster, func(status *v1beta1.KafkaClusterStatus) {
+			status.OrphanedResources = nil
+		})
+	}
+	log := logr.FromContextOrDiscard(ctx)
+	now := time.Now()
+
+	candidates, err := r.orphanedResourceCandidates(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	orphanedSince := make(map[string]metav1.Time, len(cluster.Status.OrphanedResources))
+	for _, resource := range cluster.Status.OrphanedResources {
+		orphanedSince[resource.Kind+"/"+resource.Name] = resource.OrphanedSince
+	}
+
+	var orphanedResources []v1beta1.OrphanedResource
+	for _, candidate := range candidates {
+		resource := candidate.status
+		if since, ok := orphanedSince[resource.Kind+"/"+resource.Name]; ok {
+			resource.OrphanedSince = since
+		} else {
+			resource.OrphanedSince = metav1.NewTime(now)
+			log.Info("orphaned resource of a removed broker found", "kind", resource.Kind, "name", resource.Name, "brokerId", resource.BrokerID)
+		}
+		if now.Sub(resource.OrphanedSince.Time) < config.GetRetention() {
+			orphanedResources = append(orphanedResources, resource)
+			continue
+		}
+		if err := r.Delete(ctx, candidate.object); client.IgnoreNotFound(err) != nil {
+			return errors.WrapIfWithDetails(err, "could not delete orphaned resource", "kind", resource.Kind, "name", resource.Name, "brokerId", resource.BrokerID)
+		}
+		log.Info("orphaned resource deleted", "kind", resource.Kind, "name", resource.Name, "brokerId", resource.BrokerID)
+		r.recordEvent(cluster, corev1.EventTypeNormal, "OrphanedResourceDeleted",
+			fmt.Sprintf("%s %s of the removed broker %s has been deleted", resource.Kind, resource.Name, resource.BrokerID))
+	}
+
+	sort.Slice(orphanedResources, func(i, j int) bool {
+		if orphanedResources[i].Kind != orphanedResources[j].Kind {
+			return orphanedResources[i].Kind < orphanedResources[j].Kind
+		}
+		return orphanedResources[i].Name < orphanedResources[j].Name
+	})
+	if reflect.DeepEqual(orphanedResources, cluster.Status.OrphanedResources) {
+		return nil
+	}
+	if err := r.updateStatus(ctx, cluster, func(status *v1beta1.KafkaClusterStatus) {
+		status.OrphanedResources = orphanedResources
+	}); err != nil {
+		return errors.WrapIfWithDetails(err, "could not update the orphaned resources of the cluster", "kafkaCluster", cluster.GetName())
+	}
+	return nil
+}
+
+type orphanedResourceCandidate struct {
+	object client.Object
+	status v1beta1.OrphanedResource
+}
+
+// orphanedResourceCandidates returns the PersistentVolumeClaims and Services controlled by the cluster which belong to
+// a broker whose removal has been completed
+func (r *KafkaClusterReconciler) orphanedResourceCandidates(ctx context.Context, cluster *v1beta1.KafkaCluster) ([]orphanedResourceCandidate, error) {
+	listOpts := []client.ListOption{
+		client.InNamespace(cluster.GetNamespace()),
+		client.MatchingLabels(apiutil.LabelsForKafka(cluster.GetName())),
+	}
+
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, listOpts...); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not list the pods of the cluster", "kafkaCluster", cluster.GetName())
+	}
+	inUse := make(map[string]struct{}, len(cluster.Spec.Brokers)+len(podList.Items))
+	for _, broker := range cluster.Spec.Brokers {
+		inUse[strconv.Itoa(int(broker.Id))] = struct{}{}
+	}
+	for brokerID := range cluster.Status.BrokersState {
+		inUse[brokerID] = struct{}{}
+	}
+	for _, pod := range podList.Items {
+		if brokerID, ok := pod.GetLabels()[v1beta1.BrokerIdLabelKey]; ok {
+			inUse[brokerID] = struct{}{}
+		}
+	}
+
+	var pvcList corev1.PersistentVolumeClaimList
+	if err := r.List(ctx, &pvcList, listOpts...); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not list the persistent volume claims of the cluster", "kafkaCluster", cluster.GetName())
+	}
+	var serviceList corev1.ServiceList
+	if err := r.List(ctx, &serviceList, listOpts...); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not list the services of the cluster", "kafkaCluster", cluster.GetName())
+	}
+
+	objects := make([]client.Object, 0, len(pvcList.Items)+len(serviceList.Items))
+	for i := range pvcList.Items {
+		objects = append(objects, &pvcList.Items[i])
+	}
+	for i := range serviceList.Items {
+		objects = append(objects, &serviceList.Items[i])
+	}
+
+	var candidates []orphanedResourceCandidate
+	for _, object := range objects {
+		brokerID, ok := object.GetLabels()[v1beta1.BrokerIdLabelKey]
+		if !ok || !metav1.IsControlledBy(object, cluster) || !object.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if _, ok := inUse[brokerID]; ok {
+			continue
+		}
+		kind := orphanedResourceKindService
+		if _, ok := object.(*corev1.PersistentVolumeClaim); ok {
+			kind = orphanedResourceKindPVC
+		}
+		candidates = append(candidates, orphanedResourceCandidate{
+			object: object,
+			status: v1beta1.OrphanedResource{Kind: kind, Name: object.GetName(), BrokerID: brokerID},
+		})
+	}
+	return candidates, nil
+}
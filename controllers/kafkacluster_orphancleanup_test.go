@@ -0,0 +1,109 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestCleanUpOrphanedResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", UID: "cluster-uid"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0, BrokerConfigGroup: "default"}},
+			OrphanedResourceCleanup: &v1beta1.OrphanedResourceCleanupConfig{
+				RetentionSeconds: util.Int32Pointer(3600),
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{"0": {}, "1": {}},
+		},
+	}
+	ownerRefs := []metav1.OwnerReference{{
+		APIVersion: v1beta1.GroupVersion.String(), Kind: "KafkaCluster", Name: "kafka", UID: "cluster-uid", Controller: util.BoolPointer(true),
+	}}
+	objectMeta := func(name, brokerID string, ownerRefs []metav1.OwnerReference) metav1.ObjectMeta {
+		labels := apiutil.LabelsForKafka("kafka")
+		labels[v1beta1.BrokerIdLabelKey] = brokerID
+		return metav1.ObjectMeta{Name: name, Namespace: "kafka", Labels: labels, OwnerReferences: ownerRefs}
+	}
+	objects := []client.Object{
+		cluster,
+		&corev1.PersistentVolumeClaim{ObjectMeta: objectMeta("kafka-0-storage", "0", ownerRefs)},
+		// Broker 1 is being downscaled, broker 2 has been removed
+		&corev1.PersistentVolumeClaim{ObjectMeta: objectMeta("kafka-1-storage", "1", ownerRefs)},
+		&corev1.PersistentVolumeClaim{ObjectMeta: objectMeta("kafka-2-storage", "2", ownerRefs)},
+		&corev1.Service{ObjectMeta: objectMeta("kafka-2", "2", ownerRefs)},
+		// Broker 3 has been removed but still has a pod, broker 4 has a resource not controlled by the cluster
+		&corev1.Pod{ObjectMeta: objectMeta("kafka-3-pod", "3", ownerRefs)},
+		&corev1.PersistentVolumeClaim{ObjectMeta: objectMeta("kafka-3-storage", "3", ownerRefs)},
+		&corev1.PersistentVolumeClaim{ObjectMeta: objectMeta("kafka-4-storage", "4", nil)},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := KafkaClusterReconciler{Client: c, Recorder: recorder}
+	ctx := context.Background()
+
+	// The resources of the removed broker are tracked until the retention period passes
+	assert.NoError(t, r.cleanUpOrphanedResources(ctx, cluster))
+	assert.Len(t, cluster.Status.OrphanedResources, 2)
+	assert.Equal(t, "PersistentVolumeClaim", cluster.Status.OrphanedResources[0].Kind)
+	assert.Equal(t, "kafka-2-storage", cluster.Status.OrphanedResources[0].Name)
+	assert.Equal(t, "Service", cluster.Status.OrphanedResources[1].Kind)
+	assert.Equal(t, "2", cluster.Status.OrphanedResources[1].BrokerID)
+	assert.Empty(t, recorder.Events)
+
+	orphanedSince := cluster.Status.OrphanedResources[0].OrphanedSince
+	assert.NoError(t, r.cleanUpOrphanedResources(ctx, cluster))
+	assert.Equal(t, orphanedSince.Unix(), cluster.Status.OrphanedResources[0].OrphanedSince.Unix())
+
+	// The resources are deleted once the retention period has passed
+	cluster.Status.OrphanedResources[0].OrphanedSince = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	assert.NoError(t, r.cleanUpOrphanedResources(ctx, cluster))
+	assert.Len(t, cluster.Status.OrphanedResources, 1)
+	assert.Equal(t, "kafka-2", cluster.Status.OrphanedResources[0].Name)
+	assert.Contains(t, <-recorder.Events, "OrphanedResourceDeleted")
+
+	err := c.Get(ctx, client.ObjectKey{Namespace: "kafka", Name: "kafka-2-storage"}, &corev1.PersistentVolumeClaim{})
+	assert.True(t, apierrors.IsNotFound(err))
+	for _, name := range []string{"kafka-0-storage", "kafka-1-storage", "kafka-3-storage", "kafka-4-storage"} {
+		assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "kafka", Name: name}, &corev1.PersistentVolumeClaim{}))
+	}
+
+	// The tracking is dropped when the cleanup is disabled
+	cluster.Spec.OrphanedResourceCleanup = nil
+	assert.NoError(t, r.cleanUpOrphanedResources(ctx, cluster))
+	assert.Nil(t, cluster.Status.OrphanedResources)
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "kafka", Name: "kafka-2"}, &corev1.Service{}))
+}
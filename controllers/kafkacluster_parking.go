@@ -0,0 +1,201 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/ccoperation"
+)
+
+// reconcileBrokerGroupParking drives the parking of the broker config groups listed in the spec and the unparking of
+// the groups removed from the list. The partition leaderships of the brokers of a parked group are demoted by a
+// CruiseControlOperation first, then the group is marked as draining which makes the brokers removed by Cruise Control
+// the same way as the brokers deleted from the spec. The unparked brokers are added back as new brokers.
+// It returns true while the parking or the unparking of a group is in progress.
+func (r *KafkaClusterReconciler) reconcileBrokerGroupParking(ctx context.Context, cluster *v1beta1.KafkaCluster) (bool, error) {
+	if len(cluster.Spec.ParkedBrokerConfigGroups) == 0 && len(cluster.Status.BrokerGroupParking) == 0 {
+		return false, nil
+	}
+	log := logr.FromContextOrDiscard(ctx)
+
+	runningBrokers, err := r.runningBrokerIDs(ctx, cluster)
+	if err != nil {
+		return false, err
+	}
+
+	parking := make(map[string]v1beta1.BrokerGroupParkingStatus, len(cluster.Status.BrokerGroupParking))
+	for group, status := range cluster.Status.BrokerGroupParking {
+		parking[group] = status
+	}
+
+	for _, group := range cluster.Spec.ParkedBrokerConfigGroups {
+		status := parking[group]
+		switch status.State {
+		case "", v1beta1.BrokerGroupUnparking:
+			status = v1beta1.BrokerGroupParkingStatus{State: v1beta1.BrokerGroupDraining, BrokerIDs: brokerIDsOfGroup(cluster, group)}
+			var running []int32
+			for _, brokerID := range status.BrokerIDs {
+				if _, ok := runningBrokers[brokerID]; ok {
+					running = append(running, brokerID)
+				}
+			}
+			// Demoting the brokers is only an optimization, the replicas are moved off by Cruise Control anyway
+			if len(running) > 0 && cluster.Status.CruiseControlTopicStatus == v1beta1.CruiseControlTopicReady {
+				operation, err := ccoperation.NewDemoteBroker(running...).
+					ForCluster(cluster).
+					OwnedBy(cluster, r.Client.Scheme()).
+					Create(ctx, r.Client)
+				if err != nil {
+					return false, err
+				}
+				status.State = v1beta1.BrokerGroupDemoting
+				status.DemoteOperation = operation.GetName()
+			}
+			log.Info("parking of broker config group started", "group", group, "state", status.State)
+		case v1beta1.BrokerGroupDemoting:
+			pending, err := r.isCCOperationPending(ctx, cluster.GetNamespace(), status.DemoteOperation)
+			if err != nil {
+				return false, err
+			}
+			if !pending {
+				status.State = v1beta1.BrokerGroupDraining
+				log.Info("brokers of broker config group demoted, draining them", "group", group)
+			}
+		case v1beta1.BrokerGroupDraining:
+			if !anyBrokerRunning(runningBrokers, status.BrokerIDs) {
+				status.State = v1beta1.BrokerGroupParked
+				log.Info("broker config group parked", "group", group)
+			}
+		}
+		parking[group] = status
+	}
+
+	for group, status := range parking {
+		if cluster.Spec.IsBrokerConfigGroupParked(group) {
+			continue
+		}
+		if status.State != v1beta1.BrokerGroupUnparking {
+			parking[group] = v1beta1.BrokerGroupParkingStatus{State: v1beta1.BrokerGroupUnparking, BrokerIDs: status.BrokerIDs}
+			log.Info("unparking of broker config group started", "group", group)
+			continue
+		}
+		if brokersRejoined(cluster, status.BrokerIDs) {
+			delete(parking, group)
+			log.Info("broker config group unparked", "group", group)
+		}
+	}
+
+	inProgress := false
+	for _, status := range parking {
+		if status.State != v1beta1.BrokerGroupParked {
+			inProgress = true
+		}
+	}
+
+	if len(parking) == 0 {
+		parking = nil
+	}
+	if reflect.DeepEqual(parking, cluster.Status.BrokerGroupParking) {
+		return inProgress, nil
+	}
+	if err := r.updateStatus(ctx, cluster, func(status *v1beta1.KafkaClusterStatus) {
+		status.BrokerGroupParking = parking
+	}); err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not update the parking state of the broker config groups", "kafkaCluster", cluster.GetName())
+	}
+	return inProgress, nil
+}
+
+// runningBrokerIDs returns the IDs of the brokers which have a pod
+func (r *KafkaClusterReconciler) runningBrokerIDs(ctx context.Context, cluster *v1beta1.KafkaCluster) (map[int32]struct{}, error) {
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.InNamespace(cluster.GetNamespace()),
+		client.MatchingLabels(apiutil.LabelsForKafka(cluster.GetName()))); err != nil {
+		return nil, errors.WrapIf(err, "failed to list broker pods that belong to Kafka cluster")
+	}
+	running := make(map[int32]struct{}, len(podList.Items))
+	for _, pod := range podList.Items {
+		if id, err := strconv.ParseInt(pod.GetLabels()[v1beta1.BrokerIdLabelKey], 10, 32); err == nil {
+			running[int32(id)] = struct{}{}
+		}
+	}
+	return running, nil
+}
+
+// retainedDataBrokerIDs returns the IDs of the brokers whose data is kept on a PersistentVolumeClaim, a new broker
+// must not get the ID of a former broker as it would start with the stale log segments of the former one
+func (r *KafkaClusterReconciler) retainedDataBrokerIDs(ctx context.Context, cluster *v1beta1.KafkaCluster) ([]int32, error) {
+	var pvcList corev1.PersistentVolumeClaimList
+	if err := r.List(ctx, &pvcList, client.InNamespace(cluster.GetNamespace()),
+		client.MatchingLabels(apiutil.LabelsForKafka(cluster.GetName()))); err != nil {
+		return nil, errors.WrapIf(err, "failed to list the persistent volume claims that belong to Kafka cluster")
+	}
+	var brokerIDs []int32
+	for _, pvc := range pvcList.Items {
+		if !pvc.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if id, err := strconv.ParseInt(pvc.GetLabels()[v1beta1.BrokerIdLabelKey], 10, 32); err == nil {
+			brokerIDs = append(brokerIDs, int32(id))
+		}
+	}
+	return brokerIDs, nil
+}
+
+func brokerIDsOfGroup(cluster *v1beta1.KafkaCluster, group string) []int32 {
+	var brokerIDs []int32
+	for _, broker := range cluster.Spec.Brokers {
+		if broker.BrokerConfigGroup == group {
+			brokerIDs = append(brokerIDs, broker.Id)
+		}
+	}
+	sort.Slice(brokerIDs, func(i, j int) bool { return brokerIDs[i] < brokerIDs[j] })
+	return brokerIDs
+}
+
+func anyBrokerRunning(runningBrokers map[int32]struct{}, brokerIDs []int32) bool {
+	for _, brokerID := range brokerIDs {
+		if _, ok := runningBrokers[brokerID]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// brokersRejoined returns true when the unparked brokers, which are still in the spec, have been added back to the
+// cluster by Cruise Control
+func brokersRejoined(cluster *v1beta1.KafkaCluster, brokerIDs []int32) bool {
+	for _, brokerID := range brokerIDs {
+		if !isBrokerInSpec(cluster, brokerID) {
+			continue
+		}
+		brokerState, ok := cluster.Status.BrokersState[strconv.Itoa(int(brokerID))]
+		if !ok || brokerState.GracefulActionState.CruiseControlState != v1beta1.GracefulUpscaleSucceeded {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestReconcileBrokerGroupParking(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{
+				{Id: 0, BrokerConfigGroup: "default"},
+				{Id: 1, BrokerConfigGroup: "spot"},
+				{Id: 2, BrokerConfigGroup: "spot"},
+			},
+			ParkedBrokerConfigGroups: []string{"spot"},
+		},
+		Status: v1beta1.KafkaClusterStatus{CruiseControlTopicStatus: v1beta1.CruiseControlTopicReady},
+	}
+	objects := []client.Object{cluster}
+	for _, broker := range cluster.Spec.Brokers {
+		objects = append(objects, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      "kafka-" + strconv.Itoa(int(broker.Id)),
+			Namespace: "kafka",
+			Labels:    apiutil.MergeLabels(apiutil.LabelsForKafka("kafka"), map[string]string{v1beta1.BrokerIdLabelKey: strconv.Itoa(int(broker.Id))}),
+		}})
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	r := KafkaClusterReconciler{Client: c}
+	ctx := context.Background()
+
+	// the brokers of the parked group are demoted first
+	inProgress, err := r.reconcileBrokerGroupParking(ctx, cluster)
+	assert.NoError(t, err)
+	assert.True(t, inProgress)
+	parking := cluster.Status.BrokerGroupParking["spot"]
+	assert.Equal(t, v1beta1.BrokerGroupDemoting, parking.State)
+	assert.Equal(t, []int32{1, 2}, parking.BrokerIDs)
+	assert.False(t, cluster.IsBrokerParked(cluster.Spec.Brokers[1]))
+
+	var operations v1alpha1.CruiseControlOperationList
+	assert.NoError(t, c.List(ctx, &operations))
+	assert.Len(t, operations.Items, 1)
+	assert.Equal(t, parking.DemoteOperation, operations.Items[0].GetName())
+	assert.Equal(t, v1alpha1.OperationDemoteBroker, operations.Items[0].CurrentTaskOperation())
+	assert.Equal(t, "1,2", operations.Items[0].CurrentTaskParameters()[v1alpha1.ParamBrokerID])
+
+	// the brokers are drained once the demotion is over
+	assert.NoError(t, c.Delete(ctx, &operations.Items[0]))
+	_, err = r.reconcileBrokerGroupParking(ctx, cluster)
+	assert.NoError(t, err)
+	assert.Equal(t, v1beta1.BrokerGroupDraining, cluster.Status.BrokerGroupParking["spot"].State)
+	assert.True(t, cluster.IsBrokerParked(cluster.Spec.Brokers[1]))
+	assert.False(t, cluster.IsBrokerParked(cluster.Spec.Brokers[0]))
+
+	// the group is parked when the pods of its brokers are gone
+	for _, name := range []string{"kafka-1", "kafka-2"} {
+		assert.NoError(t, c.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kafka"}}))
+	}
+	inProgress, err = r.reconcileBrokerGroupParking(ctx, cluster)
+	assert.NoError(t, err)
+	assert.False(t, inProgress)
+	assert.Equal(t, v1beta1.BrokerGroupParked, cluster.Status.BrokerGroupParking["spot"].State)
+
+	// unparking lasts until the brokers have been added back
+	cluster.Spec.ParkedBrokerConfigGroups = nil
+	assert.NoError(t, c.Update(ctx, cluster))
+	inProgress, err = r.reconcileBrokerGroupParking(ctx, cluster)
+	assert.NoError(t, err)
+	assert.True(t, inProgress)
+	assert.Equal(t, v1beta1.BrokerGroupUnparking, cluster.Status.BrokerGroupParking["spot"].State)
+	assert.False(t, cluster.IsBrokerParked(cluster.Spec.Brokers[1]))
+
+	cluster.Status.BrokersState = map[string]v1beta1.BrokerState{
+		"1": {GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleSucceeded}},
+		"2": {GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleSucceeded}},
+	}
+	assert.NoError(t, c.Update(ctx, cluster))
+	inProgress, err = r.reconcileBrokerGroupParking(ctx, cluster)
+	assert.NoError(t, err)
+	assert.False(t, inProgress)
+	assert.Nil(t, cluster.Status.BrokerGroupParking)
+}
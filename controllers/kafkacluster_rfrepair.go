@@ -0,0 +1,177 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/ccoperation"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// checkReplicationFactor publishes the partitions which lost replicas with the brokers removed from the cluster into
+// the status of the KafkaCluster once per check interval. When there are such partitions a fix_offline_replicas
+// CruiseControlOperation is created to restore their replication factor, unless the one created last is still
+// pending. The repair is held back while brokers of the spec are offline as Cruise Control would move their
+// replicas as well.
+func (r *KafkaClusterReconciler) checkReplicationFactor(ctx context.Context, cluster *v1beta1.KafkaCluster) error {
+	config := cluster.Spec.ReplicationFactorRepair
+	if config == nil {
+		return nil
+	}
+	interval := time.Duration(config.GetCheckIntervalSeconds()) * time.Second
+	if previous := cluster.Status.ReplicationFactorRepair; previous != nil && time.Since(previous.CheckedAt.Time) < interval {
+		return nil
+	}
+	log := logr.FromContextOrDiscard(ctx)
+
+	intendedReplicationFactors, err := r.intendedReplicationFactors(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	kClient, close, err := r.KafkaClientProvider.NewFromCluster(r.Client, cluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	defer close()
+	partitions, err := kClient.PartitionReplicas()
+	if err != nil {
+		return errors.WrapIf(err, "could not get the replicas of the partitions")
+	}
+
+	status, offlineClusterBrokers := computeReplicationFactorRepair(cluster, partitions, intendedReplicationFactors)
+	status.CheckedAt = metav1.Now()
+	if previous := cluster.Status.ReplicationFactorRepair; previous != nil {
+		status.RepairOperation = previous.RepairOperation
+	}
+
+	if status.UnderReplicatedPartitions > 0 && !config.ReportOnly &&
+		cluster.Status.CruiseControlTopicStatus == v1beta1.CruiseControlTopicReady {
+		pending, err := r.isCCOperationPending(ctx, cluster.GetNamespace(), status.RepairOperation)
+		if err != nil {
+			return err
+		}
+		switch {
+		case pending:
+		case len(offlineClusterBrokers) > 0:
+			log.Info("replication factor repair is held back while brokers of the cluster are offline",
+				"offlineBrokers", offlineClusterBrokers)
+		default:
+			operation, err := ccoperation.NewFixOfflineReplicas().
+				ForCluster(cluster).
+				OwnedBy(cluster, r.Client.Scheme()).
+				Create(ctx, r.Client)
+			if err != nil {
+				return err
+			}
+			log.Info("partitions lost replicas with the removed brokers, replication factor repair requested",
+				"operation", operation.GetName(), "lostBrokers", status.LostBrokers,
+				"underReplicatedPartitions", status.UnderReplicatedPartitions)
+			status.RepairOperation = operation.GetName()
+		}
+	}
+
+	if err := r.updateStatus(ctx, cluster, func(clusterStatus *v1beta1.KafkaClusterStatus) {
+		clusterStatus.ReplicationFactorRepair = status
+	}); err != nil {
+		return errors.WrapIfWithDetails(err, "could not update the replication factor repair state of the cluster", "kafkaCluster", cluster.GetName())
+	}
+	return nil
+}
+
+// intendedReplicationFactors returns the replication factors of the topics of the cluster which are managed by
+// KafkaTopic custom resources keyed by the name of the topic
+func (r *KafkaClusterReconciler) intendedReplicationFactors(ctx context.Context, cluster *v1beta1.KafkaCluster) (map[string]int32, error) {
+	var topics v1alpha1.KafkaTopicList
+	if err := r.Client.List(ctx, &topics,
+		client.InNamespace(metav1.NamespaceAll),
+		client.MatchingLabels{clusterRefLabel: clusterLabelString(cluster)},
+	); err != nil {
+		return nil, errors.WrapIf(err, "failed to list kafkatopics")
+	}
+	replicationFactors := make(map[string]int32, len(topics.Items))
+	for _, topic := range topics.Items {
+		// The default replication factor of the brokers is not known, the current one is used instead
+		if topic.Spec.ReplicationFactor > 0 {
+			replicationFactors[topic.Spec.Name] = topic.Spec.ReplicationFactor
+		}
+	}
+	return replicationFactors, nil
+}
+
+// computeReplicationFactorRepair returns the partitions whose alive replicas are fewer than their intended
+// replication factor and which have replicas on lost brokers, i.e. on offline brokers which are not in the spec of
+// the cluster. The intended replication factor is the one of the KafkaTopic or the number of the assigned replicas.
+// The offline brokers which are still in the spec of the cluster are returned as well.
+func computeReplicationFactorRepair(cluster *v1beta1.KafkaCluster, partitions []kafkaclient.PartitionReplicas,
+	intendedReplicationFactors map[string]int32) (*v1beta1.ReplicationFactorRepairStatus, []int32) {
+	clusterBrokers := make(map[int32]struct{}, len(cluster.Spec.Brokers))
+	for _, broker := range cluster.Spec.Brokers {
+		clusterBrokers[broker.Id] = struct{}{}
+	}
+
+	status := &v1beta1.ReplicationFactorRepairStatus{}
+	lostBrokers := make(map[int32]struct{})
+	offlineClusterBrokers := make(map[int32]struct{})
+	topics := make(map[string]struct{})
+	for _, partition := range partitions {
+		lost := false
+		for _, brokerID := range partition.OfflineReplicas {
+			if _, ok := clusterBrokers[brokerID]; ok {
+				offlineClusterBrokers[brokerID] = struct{}{}
+				continue
+			}
+			lostBrokers[brokerID] = struct{}{}
+			lost = true
+		}
+		intended, ok := intendedReplicationFactors[partition.Topic]
+		if !ok {
+			intended = int32(len(partition.Replicas))
+		}
+		if lost && int32(len(partition.Replicas)-len(partition.OfflineReplicas)) < intended {
+			status.UnderReplicatedPartitions++
+			topics[partition.Topic] = struct{}{}
+		}
+	}
+
+	status.LostBrokers = sortedBrokerIDs(lostBrokers)
+	for topic := range topics {
+		status.Topics = append(status.Topics, topic)
+	}
+	sort.Strings(status.Topics)
+	return status, sortedBrokerIDs(offlineClusterBrokers)
+}
+
+func sortedBrokerIDs(brokerIDs map[int32]struct{}) []int32 {
+	if len(brokerIDs) == 0 {
+		return nil
+	}
+	ret := make([]int32, 0, len(brokerIDs))
+	for brokerID := range brokerIDs {
+		ret = append(ret, brokerID)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret
+}
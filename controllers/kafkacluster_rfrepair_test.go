@@ -0,0 +1,128 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+type rfRepairTestKafkaClient struct {
+	kafkaclient.KafkaClient
+	partitions []kafkaclient.PartitionReplicas
+}
+
+func (c *rfRepairTestKafkaClient) PartitionReplicas() ([]kafkaclient.PartitionReplicas, error) {
+	return c.partitions, nil
+}
+
+type rfRepairTestProvider struct {
+	kafkaClient *rfRepairTestKafkaClient
+}
+
+func (p *rfRepairTestProvider) NewFromCluster(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+	return p.kafkaClient, func() {}, nil
+}
+
+func TestComputeReplicationFactorRepair(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}},
+		},
+	}
+	partitions := []kafkaclient.PartitionReplicas{
+		{Topic: "healthy", Partition: 0, Replicas: []int32{0, 1, 2}},
+		{Topic: "lost", Partition: 0, Replicas: []int32{0, 1, 3}, OfflineReplicas: []int32{3}},
+		{Topic: "lost", Partition: 1, Replicas: []int32{1, 2, 4}, OfflineReplicas: []int32{4}},
+		// the KafkaTopic intends a lower replication factor than the current one
+		{Topic: "shrunk", Partition: 0, Replicas: []int32{0, 1, 3}, OfflineReplicas: []int32{3}},
+		// the replica of a broker of the cluster is offline temporarily
+		{Topic: "restarting", Partition: 0, Replicas: []int32{0, 1, 2}, OfflineReplicas: []int32{2}},
+	}
+
+	status, offlineClusterBrokers := computeReplicationFactorRepair(cluster, partitions, map[string]int32{"shrunk": 2})
+
+	assert.Equal(t, []int32{3, 4}, status.LostBrokers)
+	assert.Equal(t, int32(2), status.UnderReplicatedPartitions)
+	assert.Equal(t, []string{"lost"}, status.Topics)
+	assert.Equal(t, []int32{2}, offlineClusterBrokers)
+}
+
+func TestCheckReplicationFactor(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers:                 []v1beta1.Broker{{Id: 0}, {Id: 1}},
+			ReplicationFactorRepair: &v1beta1.ReplicationFactorRepairConfig{},
+		},
+		Status: v1beta1.KafkaClusterStatus{CruiseControlTopicStatus: v1beta1.CruiseControlTopicReady},
+	}
+	topic := &v1alpha1.KafkaTopic{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "apps", Labels: map[string]string{clusterRefLabel: clusterLabelString(cluster)}},
+		Spec:       v1alpha1.KafkaTopicSpec{Name: "orders", Partitions: 1, ReplicationFactor: 3},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, topic).Build()
+	kafkaClient := &rfRepairTestKafkaClient{partitions: []kafkaclient.PartitionReplicas{
+		{Topic: "orders", Partition: 0, Replicas: []int32{0, 1}, OfflineReplicas: []int32{1}},
+	}}
+	r := KafkaClusterReconciler{Client: c, KafkaClientProvider: &rfRepairTestProvider{kafkaClient: kafkaClient}}
+	ctx := context.Background()
+
+	// the offline broker is part of the cluster
+	assert.NoError(t, r.checkReplicationFactor(ctx, cluster))
+	assert.Equal(t, int32(0), cluster.Status.ReplicationFactorRepair.UnderReplicatedPartitions)
+	assert.Empty(t, cluster.Status.ReplicationFactorRepair.RepairOperation)
+
+	// the broker has been removed from the cluster without moving its replicas
+	kafkaClient.partitions = []kafkaclient.PartitionReplicas{
+		{Topic: "orders", Partition: 0, Replicas: []int32{0, 2}, OfflineReplicas: []int32{2}},
+	}
+	cluster.Status.ReplicationFactorRepair.CheckedAt = metav1.NewTime(cluster.Status.ReplicationFactorRepair.CheckedAt.Add(-5 * time.Minute))
+	assert.NoError(t, r.checkReplicationFactor(ctx, cluster))
+	assert.Equal(t, []int32{2}, cluster.Status.ReplicationFactorRepair.LostBrokers)
+	assert.Equal(t, []string{"orders"}, cluster.Status.ReplicationFactorRepair.Topics)
+	assert.NotEmpty(t, cluster.Status.ReplicationFactorRepair.RepairOperation)
+
+	var operations v1alpha1.CruiseControlOperationList
+	assert.NoError(t, c.List(ctx, &operations))
+	assert.Len(t, operations.Items, 1)
+	assert.Equal(t, v1alpha1.OperationFixOfflineReplicas, operations.Items[0].CurrentTaskOperation())
+
+	// no new operation is created while the previous one is pending
+	cluster.Status.ReplicationFactorRepair.CheckedAt = metav1.NewTime(cluster.Status.ReplicationFactorRepair.CheckedAt.Add(-5 * time.Minute))
+	assert.NoError(t, r.checkReplicationFactor(ctx, cluster))
+	assert.NoError(t, c.List(ctx, &operations))
+	assert.Len(t, operations.Items, 1)
+
+	// the partitions are not checked again within the check interval
+	kafkaClient.partitions = nil
+	assert.NoError(t, r.checkReplicationFactor(ctx, cluster))
+	assert.Equal(t, int32(1), cluster.Status.ReplicationFactorRepair.UnderReplicatedPartitions)
+}
@@ -0,0 +1,159 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+// log4jConfigMapWatches adds the watch enqueueing the KafkaClusters whose logging configuration is selected from the
+// changed ConfigMap, so that the new logger levels are applied without waiting for the next reconcile
+func log4jConfigMapWatches(builder *ctrl.Builder, c client.Reader, log logr.Logger) *ctrl.Builder {
+	mapper := log4jConfigMapMapper{
+		client: c,
+		log:    log,
+	}
+	return builder.
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(mapper.mapConfigMap))
+}
+
+type log4jConfigMapMapper struct {
+	client client.Reader
+	log    logr.Logger
+}
+
+// mapConfigMap maps ConfigMap events to the KafkaClusters of the namespace referencing the ConfigMap as logging configuration
+func (m *log4jConfigMapMapper) mapConfigMap(obj client.Object) []ctrl.Request {
+	clusterList := &v1beta1.KafkaClusterList{}
+	if err := m.client.List(context.Background(), clusterList, client.InNamespace(obj.GetNamespace())); err != nil {
+		m.log.Error(err, "couldn't list KafkaClusters", "namespace", obj.GetNamespace())
+		return []ctrl.Request{}
+	}
+
+	requests := make([]ctrl.Request, 0)
+	for i := range clusterList.Items {
+		cluster := &clusterList.Items[i]
+		if !util.StringSliceContains(cluster.Spec.GetLog4jConfigMapNames(), obj.GetName()) {
+			continue
+		}
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: cluster.GetNamespace(),
+				Name:      cluster.GetName(),
+			},
+		})
+	}
+	return requests
+}
+
+// externalListenerServiceWatches adds the watch enqueueing the KafkaClusters whose external listener is exposed through
+// the LoadBalancer service with changed ingress points, so that the new addresses are advertised by the brokers even
+// when the service is not owned by the KafkaCluster (e.g. it is managed by the Istio operator)
+func externalListenerServiceWatches(b *ctrl.Builder, c client.Reader, log logr.Logger) *ctrl.Builder {
+	mapper := externalListenerServiceMapper{
+		client: c,
+		log:    log,
+	}
+	return b.
+		Watches(
+			&source.Kind{Type: &corev1.Service{}},
+			handler.EnqueueRequestsFromMapFunc(mapper.mapService),
+			builder.WithPredicates(loadBalancerStatusChangedPredicate()))
+}
+
+// loadBalancerStatusChangedPredicate filters the update events of the LoadBalancer services whose ingress points changed
+func loadBalancerStatusChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldService, ok := e.ObjectOld.(*corev1.Service)
+			if !ok {
+				return false
+			}
+			newService, ok := e.ObjectNew.(*corev1.Service)
+			if !ok || newService.Spec.Type != corev1.ServiceTypeLoadBalancer {
+				return false
+			}
+			return !reflect.DeepEqual(oldService.Status.LoadBalancer, newService.Status.LoadBalancer)
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+type externalListenerServiceMapper struct {
+	client client.Reader
+	log    logr.Logger
+}
+
+// mapService maps Service events to the KafkaClusters of the namespace exposing an external listener through the Service
+func (m *externalListenerServiceMapper) mapService(obj client.Object) []ctrl.Request {
+	clusterList := &v1beta1.KafkaClusterList{}
+	if err := m.client.List(context.Background(), clusterList, client.InNamespace(obj.GetNamespace())); err != nil {
+		m.log.Error(err, "couldn't list KafkaClusters", "namespace", obj.GetNamespace())
+		return []ctrl.Request{}
+	}
+
+	requests := make([]ctrl.Request, 0)
+	for i := range clusterList.Items {
+		cluster := &clusterList.Items[i]
+		if !isExternalListenerService(cluster, obj.GetName(), m.log) {
+			continue
+		}
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: cluster.GetNamespace(),
+				Name:      cluster.GetName(),
+			},
+		})
+	}
+	return requests
+}
+
+func isExternalListenerService(cluster *v1beta1.KafkaCluster, serviceName string, log logr.Logger) bool {
+	for _, eListener := range cluster.Spec.ListenersConfig.ExternalListeners {
+		if eListener.GetAccessMethod() != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+		ingressConfigs, _, err := util.GetIngressConfigs(cluster.Spec, eListener)
+		if err != nil {
+			log.Error(err, "couldn't get ingress configs of external listener", "cluster", cluster.GetName(), "externalListenerName", eListener.Name)
+			continue
+		}
+		for ingressConfigName := range ingressConfigs {
+			if util.GetIngressControllerServiceName(cluster, eListener.Name, ingressConfigName) == serviceName {
+				return true
+			}
+		}
+	}
+	return false
+}
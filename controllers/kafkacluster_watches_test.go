@@ -0,0 +1,142 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestMapLog4jConfigMap(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	selector := func(name string) *corev1.ConfigMapKeySelector {
+		return &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: "log4j.properties"}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1beta1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "brokers", Namespace: "kafka"},
+			Spec: v1beta1.KafkaClusterSpec{
+				BrokerConfigGroups: map[string]v1beta1.BrokerConfig{"default": {Log4jConfigMap: selector("logging")}},
+			},
+		},
+		&v1beta1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cruise-control", Namespace: "kafka"},
+			Spec: v1beta1.KafkaClusterSpec{
+				CruiseControlConfig: v1beta1.CruiseControlConfig{Log4jConfigMap: selector("logging")},
+			},
+		},
+		&v1beta1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "kafka"},
+			Spec: v1beta1.KafkaClusterSpec{
+				Brokers: []v1beta1.Broker{{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{Log4jConfigMap: selector("other-logging")}}},
+			},
+		},
+		&v1beta1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "default"},
+			Spec: v1beta1.KafkaClusterSpec{
+				BrokerConfigGroups: map[string]v1beta1.BrokerConfig{"default": {Log4jConfigMap: selector("logging")}},
+			},
+		},
+	).Build()
+
+	mapper := log4jConfigMapMapper{client: c, log: logr.Discard()}
+	requests := mapper.mapConfigMap(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "logging", Namespace: "kafka"}})
+	assert.ElementsMatch(t, []ctrl.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "kafka", Name: "brokers"}},
+		{NamespacedName: types.NamespacedName{Namespace: "kafka", Name: "cruise-control"}},
+	}, requests)
+
+	requests = mapper.mapConfigMap(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "kafka"}})
+	assert.Empty(t, requests)
+}
+
+func TestMapExternalListenerService(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	externalListener := func(name string, accessMethod corev1.ServiceType) v1beta1.ExternalListenerConfig {
+		return v1beta1.ExternalListenerConfig{
+			CommonListenerSpec: v1beta1.CommonListenerSpec{Name: name},
+			AccessMethod:       accessMethod,
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1beta1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+			Spec: v1beta1.KafkaClusterSpec{
+				ListenersConfig: v1beta1.ListenersConfig{
+					ExternalListeners: []v1beta1.ExternalListenerConfig{externalListener("external", corev1.ServiceTypeLoadBalancer)},
+				},
+			},
+		},
+		&v1beta1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio", Namespace: "kafka"},
+			Spec: v1beta1.KafkaClusterSpec{
+				IngressController: "istioingress",
+				ListenersConfig: v1beta1.ListenersConfig{
+					ExternalListeners: []v1beta1.ExternalListenerConfig{externalListener("external", corev1.ServiceTypeLoadBalancer)},
+				},
+			},
+		},
+		&v1beta1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "nodeport", Namespace: "kafka"},
+			Spec: v1beta1.KafkaClusterSpec{
+				ListenersConfig: v1beta1.ListenersConfig{
+					ExternalListeners: []v1beta1.ExternalListenerConfig{externalListener("external", corev1.ServiceTypeNodePort)},
+				},
+			},
+		},
+	).Build()
+
+	mapper := externalListenerServiceMapper{client: c, log: logr.Discard()}
+	requests := mapper.mapService(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "envoy-loadbalancer-external-kafka", Namespace: "kafka"}})
+	assert.Equal(t, []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: "kafka", Name: "kafka"}}}, requests)
+
+	requests = mapper.mapService(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "meshgateway-external-istio", Namespace: "kafka"}})
+	assert.Equal(t, []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: "kafka", Name: "istio"}}}, requests)
+
+	requests = mapper.mapService(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "envoy-loadbalancer-external-nodeport", Namespace: "kafka"}})
+	assert.Empty(t, requests)
+}
+
+func TestLoadBalancerStatusChangedPredicate(t *testing.T) {
+	loadBalancer := func(ip string) *corev1.Service {
+		service := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer}}
+		if ip != "" {
+			service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: ip}}
+		}
+		return service
+	}
+	p := loadBalancerStatusChangedPredicate()
+
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: loadBalancer(""), ObjectNew: loadBalancer("10.0.0.1")}))
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: loadBalancer("10.0.0.1"), ObjectNew: loadBalancer("10.0.0.2")}))
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: loadBalancer("10.0.0.1"), ObjectNew: loadBalancer("10.0.0.1")}))
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: &corev1.Service{}, ObjectNew: &corev1.Service{}}))
+	assert.False(t, p.Create(event.CreateEvent{Object: loadBalancer("10.0.0.1")}))
+}
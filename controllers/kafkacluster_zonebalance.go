@@ -0,0 +1,158 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/ccoperation"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	kafkautil "github.com/banzaicloud/koperator/pkg/util/kafka"
+)
+
+// checkZoneBalance publishes the distribution of the partition replicas and leaders across the zones of the brokers
+// into the status of the KafkaCluster once per check interval. When the skew of the zones exceeds the threshold a
+// rebalance CruiseControlOperation with the rack-aware goals is created, unless the one created last is still pending.
+func (r *KafkaClusterReconciler) checkZoneBalance(ctx context.Context, cluster *v1beta1.KafkaCluster) error {
+	config := cluster.Spec.ZoneBalancing
+	if config == nil {
+		return nil
+	}
+	interval := time.Duration(config.GetCheckIntervalSeconds()) * time.Second
+	if previous := cluster.Status.ZoneBalance; previous != nil && time.Since(previous.ComputedAt.Time) < interval {
+		return nil
+	}
+	log := logr.FromContextOrDiscard(ctx)
+
+	kClient, close, err := r.KafkaClientProvider.NewFromCluster(r.Client, cluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	defer close()
+	distribution, err := kClient.PartitionDistribution()
+	if err != nil {
+		return errors.WrapIf(err, "could not get the distribution of the partitions")
+	}
+
+	status := computeZoneBalance(cluster, distribution)
+	status.ComputedAt = metav1.Now()
+	if previous := cluster.Status.ZoneBalance; previous != nil {
+		status.RebalanceOperation = previous.RebalanceOperation
+	}
+
+	threshold := config.RebalanceSkewThresholdPercent
+	if threshold != nil && len(status.Zones) > 1 && (status.ReplicaSkewPercent > *threshold || status.LeaderSkewPercent > *threshold) &&
+		cluster.Status.CruiseControlTopicStatus == v1beta1.CruiseControlTopicReady {
+		pending, err := r.isCCOperationPending(ctx, cluster.GetNamespace(), status.RebalanceOperation)
+		if err != nil {
+			return err
+		}
+		if !pending {
+			operation, err := ccoperation.NewRebalance().
+				ForCluster(cluster).
+				WithGoals(config.GetGoals()...).
+				OwnedBy(cluster, r.Client.Scheme()).
+				Create(ctx, r.Client)
+			if err != nil {
+				return err
+			}
+			log.Info("zones of the cluster are skewed, rebalance requested", "operation", operation.GetName(),
+				"replicaSkewPercent", status.ReplicaSkewPercent, "leaderSkewPercent", status.LeaderSkewPercent)
+			status.RebalanceOperation = operation.GetName()
+		}
+	}
+
+	if err := r.updateStatus(ctx, cluster, func(clusterStatus *v1beta1.KafkaClusterStatus) {
+		clusterStatus.ZoneBalance = status
+	}); err != nil {
+		return errors.WrapIfWithDetails(err, "could not update the zone balance of the cluster", "kafkaCluster", cluster.GetName())
+	}
+	return nil
+}
+
+// isCCOperationPending returns true when the CruiseControlOperation with the given name exists and has not finished yet
+func (r *KafkaClusterReconciler) isCCOperationPending(ctx context.Context, namespace, name string) (bool, error) {
+	if name == "" {
+		return false, nil
+	}
+	operation := &v1alpha1.CruiseControlOperation{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, operation); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.WrapIfWithDetails(err, "could not get the CruiseControlOperation", "name", name)
+	}
+	return !operation.IsDone(), nil
+}
+
+// computeZoneBalance returns the load and the skew of the zones of the unparked brokers in the spec of the cluster
+func computeZoneBalance(cluster *v1beta1.KafkaCluster, distribution map[int32]kafkaclient.BrokerPartitions) *v1beta1.ZoneBalanceStatus {
+	loads := make(map[string]*v1beta1.ZoneLoad)
+	for _, broker := range cluster.Spec.Brokers {
+		// The parked brokers do not host partitions, counting them would skew their zones
+		if cluster.IsBrokerParked(broker) {
+			continue
+		}
+		zone := kafkautil.BrokerRack(cluster, broker)
+		load, ok := loads[zone]
+		if !ok {
+			load = &v1beta1.ZoneLoad{Zone: zone}
+			loads[zone] = load
+		}
+		load.Brokers++
+		load.Replicas += int32(distribution[broker.Id].Replicas)
+		load.Leaders += int32(distribution[broker.Id].Leaders)
+	}
+
+	status := &v1beta1.ZoneBalanceStatus{Zones: make([]v1beta1.ZoneLoad, 0, len(loads))}
+	for _, load := range loads {
+		status.Zones = append(status.Zones, *load)
+	}
+	sort.Slice(status.Zones, func(i, j int) bool { return status.Zones[i].Zone < status.Zones[j].Zone })
+	status.ReplicaSkewPercent = zoneSkewPercent(status.Zones, func(load v1beta1.ZoneLoad) int32 { return load.Replicas })
+	status.LeaderSkewPercent = zoneSkewPercent(status.Zones, func(load v1beta1.ZoneLoad) int32 { return load.Leaders })
+	return status
+}
+
+// zoneSkewPercent returns the highest deviation of the zones from the share of the total proportional to the number
+// of their brokers
+func zoneSkewPercent(zones []v1beta1.ZoneLoad, count func(v1beta1.ZoneLoad) int32) int32 {
+	var brokers, total int32
+	for _, zone := range zones {
+		brokers += zone.Brokers
+		total += count(zone)
+	}
+	if brokers == 0 || total == 0 {
+		return 0
+	}
+	var skew float64
+	for _, zone := range zones {
+		expected := float64(total) * float64(zone.Brokers) / float64(brokers)
+		skew = math.Max(skew, math.Abs(float64(count(zone))-expected)/expected*100)
+	}
+	return int32(math.Round(skew))
+}
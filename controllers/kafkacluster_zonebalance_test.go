@@ -0,0 +1,128 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+type zoneBalanceTestKafkaClient struct {
+	kafkaclient.KafkaClient
+	distribution map[int32]kafkaclient.BrokerPartitions
+}
+
+func (c *zoneBalanceTestKafkaClient) PartitionDistribution() (map[int32]kafkaclient.BrokerPartitions, error) {
+	return c.distribution, nil
+}
+
+type zoneBalanceTestProvider struct {
+	kafkaClient *zoneBalanceTestKafkaClient
+}
+
+func (p *zoneBalanceTestProvider) NewFromCluster(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+	return p.kafkaClient, func() {}, nil
+}
+
+func TestComputeZoneBalance(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			ReadOnlyConfig: "broker.rack=zone-a",
+			Brokers: []v1beta1.Broker{
+				{Id: 0},
+				{Id: 1, ReadOnlyConfig: "broker.rack=zone-b\n"},
+				{Id: 2, ReadOnlyConfig: "auto.create.topics.enable=false\nbroker.rack=zone-b\n"},
+			},
+		},
+	}
+	status := computeZoneBalance(cluster, map[int32]kafkaclient.BrokerPartitions{
+		0: {Replicas: 30, Leaders: 10},
+		1: {Replicas: 15, Leaders: 5},
+		2: {Replicas: 15, Leaders: 15},
+	})
+
+	assert.Equal(t, []v1beta1.ZoneLoad{
+		{Zone: "zone-a", Brokers: 1, Replicas: 30, Leaders: 10},
+		{Zone: "zone-b", Brokers: 2, Replicas: 30, Leaders: 20},
+	}, status.Zones)
+	// zone-a is expected to host a third of the replicas and leaders
+	assert.Equal(t, int32(50), status.ReplicaSkewPercent)
+	assert.Equal(t, int32(0), status.LeaderSkewPercent)
+}
+
+func TestCheckZoneBalance(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{
+				{Id: 0, ReadOnlyConfig: "broker.rack=zone-a"},
+				{Id: 1, ReadOnlyConfig: "broker.rack=zone-b"},
+			},
+			ZoneBalancing: &v1beta1.ZoneBalancingConfig{RebalanceSkewThresholdPercent: util.Int32Pointer(20)},
+		},
+		Status: v1beta1.KafkaClusterStatus{CruiseControlTopicStatus: v1beta1.CruiseControlTopicReady},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	kafkaClient := &zoneBalanceTestKafkaClient{distribution: map[int32]kafkaclient.BrokerPartitions{
+		0: {Replicas: 12, Leaders: 6},
+		1: {Replicas: 8, Leaders: 4},
+	}}
+	r := KafkaClusterReconciler{Client: c, KafkaClientProvider: &zoneBalanceTestProvider{kafkaClient: kafkaClient}}
+	ctx := context.Background()
+
+	assert.NoError(t, r.checkZoneBalance(ctx, cluster))
+	assert.Equal(t, int32(20), cluster.Status.ZoneBalance.ReplicaSkewPercent)
+	assert.Empty(t, cluster.Status.ZoneBalance.RebalanceOperation)
+
+	// the skew exceeds the threshold
+	kafkaClient.distribution[0] = kafkaclient.BrokerPartitions{Replicas: 15, Leaders: 6}
+	cluster.Status.ZoneBalance.ComputedAt = metav1.NewTime(cluster.Status.ZoneBalance.ComputedAt.Add(-5 * time.Minute))
+	assert.NoError(t, r.checkZoneBalance(ctx, cluster))
+	assert.NotEmpty(t, cluster.Status.ZoneBalance.RebalanceOperation)
+
+	var operations v1alpha1.CruiseControlOperationList
+	assert.NoError(t, c.List(ctx, &operations))
+	assert.Len(t, operations.Items, 1)
+	assert.Equal(t, v1alpha1.OperationRebalance, operations.Items[0].CurrentTaskOperation())
+	assert.Equal(t, "RackAwareDistributionGoal,ReplicaDistributionGoal,LeaderReplicaDistributionGoal",
+		operations.Items[0].CurrentTaskParameters()["goals"])
+
+	// no new operation is created while the previous one is pending
+	cluster.Status.ZoneBalance.ComputedAt = metav1.NewTime(cluster.Status.ZoneBalance.ComputedAt.Add(-5 * time.Minute))
+	assert.NoError(t, r.checkZoneBalance(ctx, cluster))
+	assert.NoError(t, c.List(ctx, &operations))
+	assert.Len(t, operations.Items, 1)
+
+	// the distribution is not computed again within the check interval
+	kafkaClient.distribution = nil
+	assert.NoError(t, r.checkZoneBalance(ctx, cluster))
+	assert.Equal(t, int32(30), cluster.Status.ZoneBalance.ReplicaSkewPercent)
+}
@@ -0,0 +1,407 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/util"
+	kafkautil "github.com/banzaicloud/koperator/pkg/util/kafka"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	kafkaConnectResourceNameTemplate = "%s-connect"
+	kafkaConnectLabelKey             = "kafka_connect"
+	kafkaConnectConfigFileName       = "connect-distributed.properties"
+	kafkaConnectConfigMountPath      = "/opt/kafka-connect/config"
+	kafkaConnectCredentialsMountPath = "/var/run/secrets/kafka-connect/credentials"
+	kafkaConnectTokenMountPath       = "/var/run/secrets/kafka-connect/token"
+	// The credentials and the token are rotated by the KafkaUser controller, periodic resync makes sure that
+	// the workers are restarted with the new ones.
+	defaultKafkaConnectResyncIntervalInSeconds = 60
+
+	// The workers advertise their pod IP for the forwarding of the REST requests between them
+	kafkaConnectCommand = `cp ` + kafkaConnectConfigMountPath + `/` + kafkaConnectConfigFileName + ` /tmp/` + kafkaConnectConfigFileName + ` &&
+echo "rest.advertised.host.name=${POD_IP}" >> /tmp/` + kafkaConnectConfigFileName + ` &&
+exec /opt/kafka/bin/connect-distributed.sh /tmp/` + kafkaConnectConfigFileName
+)
+
+// kafkaConnectClientPrefixes are the prefixes of the configurations of the clients of the worker: the worker itself,
+// the producers of the source connectors, the consumers of the sink connectors and the admin clients
+var kafkaConnectClientPrefixes = []string{"", "producer.", "consumer.", "admin."}
+
+// KafkaConnectReconciler reconciles KafkaConnect custom resources
+type KafkaConnectReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaconnects,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaconnects/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+
+func (r *KafkaConnectReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	connect := &v1alpha1.KafkaConnect{}
+	if err := r.Get(ctx, request.NamespacedName, connect); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	// the owned resources are garbage collected
+	if !connect.GetDeletionTimestamp().IsZero() {
+		return reconciled()
+	}
+
+	cluster, err := k8sutil.LookupKafkaCluster(ctx, r.Client, connect.Spec.ClusterRef.Name,
+		getClusterRefNamespace(connect.GetNamespace(), connect.Spec.ClusterRef))
+	if err != nil {
+		return requeueWithError(log, "failed to lookup referenced kafka cluster", err)
+	}
+
+	listener, err := clientListener(cluster, connect.Spec.ListenerName)
+	if err != nil {
+		return r.updateFailedStatus(ctx, log, connect, err)
+	}
+
+	user := kafkaConnectUser(connect, listener.Type)
+	if user != nil {
+		if err = reconcileClientKafkaUser(ctx, r.Client, r.Scheme, connect, user); err != nil {
+			return requeueWithError(log, "failed to reconcile the KafkaUser of the Kafka Connect workers", err)
+		}
+		connect.Status.UserName = user.GetName()
+	}
+
+	secrets, ready, err := clientCredentials(ctx, r.Client, user)
+	if err != nil {
+		return requeueWithError(log, "failed to get the credentials of the Kafka Connect workers", err)
+	}
+	if !ready {
+		log.Info("credentials of the Kafka Connect workers are not ready yet")
+		return r.updateStatus(ctx, log, connect, cluster, v1alpha1.KafkaConnectStateCreating, 0, defaultRequeueIntervalInSeconds)
+	}
+
+	workerConfig, err := kafkaConnectWorkerConfig(connect, cluster, listener)
+	if err != nil {
+		return r.updateFailedStatus(ctx, log, connect, err)
+	}
+
+	if err = r.reconcileKafkaConnectConfigMap(ctx, connect, workerConfig); err != nil {
+		return requeueWithError(log, "failed to reconcile the configuration of the Kafka Connect workers", err)
+	}
+	if err = r.reconcileKafkaConnectService(ctx, connect); err != nil {
+		return requeueWithError(log, "failed to reconcile the service of the Kafka Connect workers", err)
+	}
+	deployment, err := r.reconcileKafkaConnectDeployment(ctx, connect, cluster, user, clientConfigHash(workerConfig, secrets))
+	if err != nil {
+		return requeueWithError(log, "failed to reconcile the deployment of the Kafka Connect workers", err)
+	}
+
+	state := v1alpha1.KafkaConnectStateCreating
+	if deployment.Status.ReadyReplicas == connect.Spec.GetReplicas() && deployment.Status.UpdatedReplicas == connect.Spec.GetReplicas() {
+		state = v1alpha1.KafkaConnectStateRunning
+	}
+	return r.updateStatus(ctx, log, connect, cluster, state, deployment.Status.ReadyReplicas, defaultKafkaConnectResyncIntervalInSeconds)
+}
+
+func (r *KafkaConnectReconciler) updateStatus(ctx context.Context, log logr.Logger, connect *v1alpha1.KafkaConnect,
+	cluster *v1beta1.KafkaCluster, state v1alpha1.KafkaConnectState, readyReplicas int32, requeueInterval int) (ctrl.Result, error) {
+	connect.Status.ObservedGeneration = connect.GetGeneration()
+	connect.Status.State = state
+	connect.Status.ReadyReplicas = readyReplicas
+	connect.Status.RESTEndpoint = connect.GetRESTEndpoint(cluster.Spec.GetKubernetesClusterDomain())
+	connect.Status.ErrorMessage = ""
+	if err := r.Status().Update(ctx, connect); err != nil {
+		return requeueWithError(log, "could not update KafkaConnect status", err)
+	}
+	return requeueAfter(requeueInterval)
+}
+
+func (r *KafkaConnectReconciler) updateFailedStatus(ctx context.Context, log logr.Logger, connect *v1alpha1.KafkaConnect, err error) (ctrl.Result, error) {
+	log.Error(err, "deploying Kafka Connect failed")
+	connect.Status.ObservedGeneration = connect.GetGeneration()
+	connect.Status.State = v1alpha1.KafkaConnectStateFailed
+	connect.Status.ErrorMessage = err.Error()
+	if err := r.Status().Update(ctx, connect); err != nil {
+		return requeueWithError(log, "could not update KafkaConnect status", err)
+	}
+	return requeueAfter(defaultRequeueIntervalInSeconds)
+}
+
+// kafkaConnectUser returns the KafkaUser holding the credentials of the workers, the workers are granted access
+// to their internal topics which are prefixed with the group of the workers
+func kafkaConnectUser(connect *v1alpha1.KafkaConnect, protocol v1beta1.SecurityProtocol) *v1alpha1.KafkaUser {
+	return clientKafkaUser(fmt.Sprintf(kafkaConnectResourceNameTemplate, connect.GetName()), connect.GetNamespace(),
+		connect.Spec.ClusterRef, kafkaConnectLabels(connect), protocol,
+		append([]v1alpha1.UserTopicGrant{
+			{TopicName: connect.GetGroupID() + "-", AccessType: v1alpha1.KafkaAccessTypeRead, PatternType: v1alpha1.KafkaPatternTypePrefixed},
+			{TopicName: connect.GetGroupID() + "-", AccessType: v1alpha1.KafkaAccessTypeWrite, PatternType: v1alpha1.KafkaPatternTypePrefixed},
+		}, connect.Spec.TopicGrants...))
+}
+
+// kafkaConnectWorkerConfig returns the configuration of the workers. The configuration given in the KafkaConnect
+// cannot override the connection, the group, the internal topics and the security settings.
+func kafkaConnectWorkerConfig(connect *v1alpha1.KafkaConnect, cluster *v1beta1.KafkaCluster,
+	listener v1beta1.InternalListenerConfig) (*properties.Properties, error) {
+	config, err := properties.NewFromString(connect.Spec.Config)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not parse the configuration of the Kafka Connect workers")
+	}
+	defaults := map[string]string{
+		"key.converter":   "org.apache.kafka.connect.json.JsonConverter",
+		"value.converter": "org.apache.kafka.connect.json.JsonConverter",
+	}
+	for key, value := range defaults {
+		if _, ok := config.Get(key); !ok {
+			if err = config.Set(key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	groupID := connect.GetGroupID()
+	managed := [][2]string{
+		{"bootstrap.servers", fmt.Sprintf("%s:%d", kafkautil.GetClusterServiceFqdn(cluster), listener.ContainerPort)},
+		{"group.id", groupID},
+		{"config.storage.topic", groupID + "-configs"},
+		{"offset.storage.topic", groupID + "-offsets"},
+		{"status.storage.topic", groupID + "-status"},
+		{"listeners", fmt.Sprintf("http://:%d", v1alpha1.KafkaConnectRESTPort)},
+		{"config.providers", "dir"},
+		{"config.providers.dir.class", "org.apache.kafka.common.config.provider.DirectoryConfigProvider"},
+	}
+	if pluginPath := kafkaConnectPluginPath(connect.Spec.VolumeMounts, connect.Spec.PluginVolumes); pluginPath != "" {
+		managed = append(managed, [2]string{"plugin.path", pluginPath})
+	}
+	for _, prefix := range kafkaConnectClientPrefixes {
+		managed = append(managed, kafkaConnectSecurityConfig(connect, prefix, listener.Type)...)
+	}
+	for _, entry := range managed {
+		if err = config.Set(entry[0], entry[1]); err != nil {
+			return nil, err
+		}
+	}
+	config.Sort()
+	return config, nil
+}
+
+// kafkaConnectSecurityConfig returns the security settings of the clients of the workers with the given prefix,
+// the secrets are read by the DirectoryConfigProvider from the mounted Secrets
+func kafkaConnectSecurityConfig(connect *v1alpha1.KafkaConnect, prefix string, protocol v1beta1.SecurityProtocol) [][2]string {
+	config := [][2]string{{prefix + "security.protocol", protocol.ToUpperString()}}
+	if protocol.IsSSL() {
+		password := fmt.Sprintf("${dir:%s:%s}", kafkaConnectCredentialsMountPath, v1alpha1.PasswordKey)
+		config = append(config,
+			[2]string{prefix + "ssl.truststore.location", kafkaConnectCredentialsMountPath + "/" + v1alpha1.TLSJKSTrustStore},
+			[2]string{prefix + "ssl.truststore.password", password},
+		)
+		if !protocol.IsSasl() {
+			config = append(config,
+				[2]string{prefix + "ssl.keystore.location", kafkaConnectCredentialsMountPath + "/" + v1alpha1.TLSJKSKeyStore},
+				[2]string{prefix + "ssl.keystore.password", password},
+				[2]string{prefix + "ssl.key.password", password},
+			)
+		}
+	}
+	if protocol.IsSasl() {
+		config = append(config,
+			[2]string{prefix + "sasl.mechanism", connect.Spec.GetSASLMechanism()},
+			[2]string{prefix + "sasl.jaas.config", fmt.Sprintf("${dir:%s:%s}", kafkaConnectTokenMountPath, v1alpha1.DelegationTokenJAASConfigKey)},
+		)
+	}
+	return config
+}
+
+// kafkaConnectPluginPath returns the plugin path of the workers made of the additional volume mounts holding
+// connector plugins
+func kafkaConnectPluginPath(volumeMounts []corev1.VolumeMount, pluginVolumes []string) string {
+	paths := make([]string, 0, len(pluginVolumes))
+	for _, volumeMount := range volumeMounts {
+		if util.StringSliceContains(pluginVolumes, volumeMount.Name) {
+			paths = append(paths, volumeMount.MountPath)
+		}
+	}
+	return strings.Join(paths, ",")
+}
+
+func kafkaConnectLabels(connect *v1alpha1.KafkaConnect) map[string]string {
+	return map[string]string{
+		v1beta1.AppLabelKey:     "kafka-connect",
+		kafkaConnectLabelKey:    connect.GetName(),
+		v1beta1.KafkaCRLabelKey: connect.Spec.ClusterRef.Name,
+	}
+}
+
+func (r *KafkaConnectReconciler) reconcileKafkaConnectConfigMap(ctx context.Context, connect *v1alpha1.KafkaConnect, config *properties.Properties) error {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      fmt.Sprintf(kafkaConnectResourceNameTemplate, connect.GetName()),
+		Namespace: connect.GetNamespace(),
+	}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Labels = kafkaConnectLabels(connect)
+		configMap.Data = map[string]string{kafkaConnectConfigFileName: config.String()}
+		return controllerutil.SetControllerReference(connect, configMap, r.Scheme)
+	})
+	return errors.WrapIfWithDetails(err, "could not create or update ConfigMap", "configMap", configMap.GetName())
+}
+
+func (r *KafkaConnectReconciler) reconcileKafkaConnectService(ctx context.Context, connect *v1alpha1.KafkaConnect) error {
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:      fmt.Sprintf(kafkaConnectResourceNameTemplate, connect.GetName()),
+		Namespace: connect.GetNamespace(),
+	}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
+		service.Labels = kafkaConnectLabels(connect)
+		service.Spec.Selector = kafkaConnectLabels(connect)
+		service.Spec.Ports = []corev1.ServicePort{{
+			Name:       "rest",
+			Protocol:   corev1.ProtocolTCP,
+			Port:       v1alpha1.KafkaConnectRESTPort,
+			TargetPort: intstr.FromInt(v1alpha1.KafkaConnectRESTPort),
+		}}
+		return controllerutil.SetControllerReference(connect, service, r.Scheme)
+	})
+	return errors.WrapIfWithDetails(err, "could not create or update Service", "service", service.GetName())
+}
+
+func (r *KafkaConnectReconciler) reconcileKafkaConnectDeployment(ctx context.Context, connect *v1alpha1.KafkaConnect,
+	cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser, configHash string) (*appsv1.Deployment, error) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:      fmt.Sprintf(kafkaConnectResourceNameTemplate, connect.GetName()),
+		Namespace: connect.GetNamespace(),
+	}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, deployment, func() error {
+		deployment.Labels = kafkaConnectLabels(connect)
+		deployment.Spec.Replicas = util.Int32Pointer(connect.Spec.GetReplicas())
+		deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: kafkaConnectLabels(connect)}
+		deployment.Spec.Template = kafkaConnectPodTemplate(connect, cluster, user, configHash)
+		return controllerutil.SetControllerReference(connect, deployment, r.Scheme)
+	})
+	return deployment, errors.WrapIfWithDetails(err, "could not create or update Deployment", "deployment", deployment.GetName())
+}
+
+func kafkaConnectPodTemplate(connect *v1alpha1.KafkaConnect, cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser,
+	configHash string) corev1.PodTemplateSpec {
+	image := connect.Spec.Image
+	if image == "" {
+		image = cluster.Spec.GetClusterImage()
+	}
+	// the image pull secrets of the Kafka cluster are only available in its namespace
+	imagePullSecrets := connect.Spec.ImagePullSecrets
+	if cluster.GetNamespace() == connect.GetNamespace() {
+		imagePullSecrets = cluster.Spec.GetImagePullSecrets(imagePullSecrets)
+	}
+
+	volumes := []corev1.Volume{{
+		Name: "connect-config",
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf(kafkaConnectResourceNameTemplate, connect.GetName())},
+			DefaultMode:          util.Int32Pointer(0644),
+		}},
+	}}
+	volumeMounts := []corev1.VolumeMount{{Name: "connect-config", MountPath: kafkaConnectConfigMountPath, ReadOnly: true}}
+	if user != nil && user.Spec.GetIfCertShouldBeCreated() {
+		volumes = append(volumes, corev1.Volume{
+			Name: "connect-credentials",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName:  user.Spec.SecretName,
+				DefaultMode: util.Int32Pointer(0400),
+			}},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "connect-credentials", MountPath: kafkaConnectCredentialsMountPath, ReadOnly: true})
+	}
+	if user != nil && user.Spec.DelegationToken != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: "connect-token",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName:  user.Spec.DelegationToken.SecretName,
+				DefaultMode: util.Int32Pointer(0400),
+			}},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "connect-token", MountPath: kafkaConnectTokenMountPath, ReadOnly: true})
+	}
+
+	container := corev1.Container{
+		Name:    "kafka-connect",
+		Image:   cluster.Spec.GetImage(image),
+		Command: []string{"bash", "-c", kafkaConnectCommand},
+		Env: append([]corev1.EnvVar{{
+			Name:      "POD_IP",
+			ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.podIP"}},
+		}}, connect.Spec.Envs...),
+		Ports: []corev1.ContainerPort{{Name: "rest", ContainerPort: v1alpha1.KafkaConnectRESTPort, Protocol: corev1.ProtocolTCP}},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+				Path: "/",
+				Port: intstr.FromInt(v1alpha1.KafkaConnectRESTPort),
+			}},
+			InitialDelaySeconds: 10,
+			PeriodSeconds:       10,
+		},
+		VolumeMounts: append(volumeMounts, connect.Spec.VolumeMounts...),
+	}
+	if connect.Spec.Resources != nil {
+		container.Resources = *connect.Spec.Resources
+	}
+
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      kafkaConnectLabels(connect),
+			Annotations: map[string]string{v1beta1.ConfigHashAnnotationKey: configHash},
+		},
+		Spec: corev1.PodSpec{
+			Containers:       []corev1.Container{container},
+			Volumes:          append(volumes, connect.Spec.Volumes...),
+			ImagePullSecrets: imagePullSecrets,
+		},
+	}
+}
+
+// SetupKafkaConnectWithManager registers KafkaConnect controller to the manager
+func SetupKafkaConnectWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.KafkaConnect{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&appsv1.Deployment{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
+		Named("KafkaConnect")
+}
+
+// blank assignment to verify that KafkaConnectReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &KafkaConnectReconciler{}
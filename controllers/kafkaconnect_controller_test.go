@@ -0,0 +1,278 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func newKafkaConnectTestReconciler(t *testing.T, objects ...client.Object) *KafkaConnectReconciler {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, appsv1.AddToScheme(scheme))
+
+	return &KafkaConnectReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Scheme: scheme,
+	}
+}
+
+func newKafkaConnectTestCluster(protocol v1beta1.SecurityProtocol) *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Type: protocol, Name: "internal", ContainerPort: 29092},
+						UsedForInnerBrokerCommunication: true,
+					},
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Type: protocol, Name: "controller", ContainerPort: 29093},
+						UsedForInnerBrokerCommunication: true,
+						UsedForControllerCommunication:  true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestKafkaConnectReconcile(t *testing.T) {
+	cluster := newKafkaConnectTestCluster(v1beta1.SecurityProtocolSaslSSL)
+	connect := &v1alpha1.KafkaConnect{
+		ObjectMeta: metav1.ObjectMeta{Name: "connect", Namespace: "kafka", Generation: 1},
+		Spec: v1alpha1.KafkaConnectSpec{
+			ClusterRef: v1alpha1.ClusterReference{Name: "kafka"},
+			Replicas:   util.Int32Pointer(2),
+			TopicGrants: []v1alpha1.UserTopicGrant{
+				{TopicName: "orders", AccessType: v1alpha1.KafkaAccessTypeRead},
+			},
+		},
+	}
+	r := newKafkaConnectTestReconciler(t, cluster, connect)
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "connect", Namespace: "kafka"}}
+
+	reconcileAndGet := func() *v1alpha1.KafkaConnect {
+		_, err := r.Reconcile(ctx, request)
+		assert.NoError(t, err)
+		current := &v1alpha1.KafkaConnect{}
+		assert.NoError(t, r.Get(ctx, request.NamespacedName, current))
+		return current
+	}
+
+	// The workers are not deployed until the credentials are provisioned
+	current := reconcileAndGet()
+	assert.Equal(t, v1alpha1.KafkaConnectStateCreating, current.Status.State)
+	assert.Equal(t, "connect-connect", current.Status.UserName)
+	assert.Equal(t, "http://connect-connect.kafka.svc.cluster.local:8083", current.Status.RESTEndpoint)
+
+	user := &v1alpha1.KafkaUser{}
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: "connect-connect", Namespace: "kafka"}, user))
+	assert.Equal(t, "connect-connect-credentials", user.Spec.SecretName)
+	assert.True(t, user.Spec.IncludeJKS)
+	assert.True(t, user.Spec.GetIfCertShouldBeCreated())
+	assert.Equal(t, "connect-connect-token", user.Spec.DelegationToken.SecretName)
+	assert.Equal(t, []v1alpha1.UserTopicGrant{
+		{TopicName: "connect-", AccessType: v1alpha1.KafkaAccessTypeRead, PatternType: v1alpha1.KafkaPatternTypePrefixed},
+		{TopicName: "connect-", AccessType: v1alpha1.KafkaAccessTypeWrite, PatternType: v1alpha1.KafkaPatternTypePrefixed},
+		{TopicName: "orders", AccessType: v1alpha1.KafkaAccessTypeRead},
+	}, user.Spec.TopicGrants)
+	assert.Equal(t, "connect", user.GetOwnerReferences()[0].Name)
+
+	deployment := &appsv1.Deployment{}
+	assert.Error(t, r.Get(ctx, types.NamespacedName{Name: "connect-connect", Namespace: "kafka"}, deployment))
+
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "connect-connect-credentials", Namespace: "kafka"},
+		Data:       map[string][]byte{v1alpha1.PasswordKey: []byte("changeit")},
+	}
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "connect-connect-token", Namespace: "kafka"},
+		Data:       map[string][]byte{v1alpha1.DelegationTokenJAASConfigKey: []byte("token")},
+	}
+	assert.NoError(t, r.Create(ctx, credentials))
+	assert.NoError(t, r.Create(ctx, token))
+
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.KafkaConnectStateCreating, current.Status.State)
+
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: "connect-connect", Namespace: "kafka"}, deployment))
+	assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+	assert.Equal(t, "ghcr.io/banzaicloud/kafka:2.13-3.1.0", deployment.Spec.Template.Spec.Containers[0].Image)
+	assert.Len(t, deployment.Spec.Template.Spec.Volumes, 3)
+	configHash := deployment.Spec.Template.GetAnnotations()[v1beta1.ConfigHashAnnotationKey]
+	assert.NotEmpty(t, configHash)
+
+	configMap := &corev1.ConfigMap{}
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: "connect-connect", Namespace: "kafka"}, configMap))
+	assert.Contains(t, configMap.Data[kafkaConnectConfigFileName], "bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:29092\n")
+	service := &corev1.Service{}
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: "connect-connect", Namespace: "kafka"}, service))
+
+	// Rotating the delegation token restarts the workers
+	token.Data[v1alpha1.DelegationTokenJAASConfigKey] = []byte("renewed-token")
+	assert.NoError(t, r.Update(ctx, token))
+	reconcileAndGet()
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: "connect-connect", Namespace: "kafka"}, deployment))
+	assert.NotEqual(t, configHash, deployment.Spec.Template.GetAnnotations()[v1beta1.ConfigHashAnnotationKey])
+
+	deployment.Status.ReadyReplicas = 2
+	deployment.Status.UpdatedReplicas = 2
+	assert.NoError(t, r.Status().Update(ctx, deployment))
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.KafkaConnectStateRunning, current.Status.State)
+	assert.Equal(t, int32(2), current.Status.ReadyReplicas)
+}
+
+func TestKafkaConnectReconcilePlaintext(t *testing.T) {
+	cluster := newKafkaConnectTestCluster(v1beta1.SecurityProtocolPlaintext)
+	connect := &v1alpha1.KafkaConnect{
+		ObjectMeta: metav1.ObjectMeta{Name: "connect", Namespace: "kafka"},
+		Spec:       v1alpha1.KafkaConnectSpec{ClusterRef: v1alpha1.ClusterReference{Name: "kafka"}},
+	}
+	r := newKafkaConnectTestReconciler(t, cluster, connect)
+	ctx := context.Background()
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "connect", Namespace: "kafka"}})
+	assert.NoError(t, err)
+
+	users := &v1alpha1.KafkaUserList{}
+	assert.NoError(t, r.List(ctx, users))
+	assert.Empty(t, users.Items)
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: "connect-connect", Namespace: "kafka"}, deployment))
+	assert.Equal(t, int32(1), *deployment.Spec.Replicas)
+	assert.Len(t, deployment.Spec.Template.Spec.Volumes, 1)
+}
+
+func TestClientListener(t *testing.T) {
+	cluster := newKafkaConnectTestCluster(v1beta1.SecurityProtocolSSL)
+
+	listener, err := clientListener(cluster, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "internal", listener.Name)
+
+	_, err = clientListener(cluster, "controller")
+	assert.Error(t, err)
+
+	_, err = clientListener(cluster, "external")
+	assert.Error(t, err)
+}
+
+func TestKafkaConnectWorkerConfig(t *testing.T) {
+	testCases := []struct {
+		testName string
+		protocol v1beta1.SecurityProtocol
+		expected map[string]string
+		absent   []string
+	}{
+		{
+			testName: "ssl listener",
+			protocol: v1beta1.SecurityProtocolSSL,
+			expected: map[string]string{
+				"security.protocol":                "SSL",
+				"ssl.keystore.location":            "/var/run/secrets/kafka-connect/credentials/keystore.jks",
+				"producer.ssl.keystore.password":   "${dir:/var/run/secrets/kafka-connect/credentials:password}",
+				"consumer.ssl.truststore.location": "/var/run/secrets/kafka-connect/credentials/truststore.jks",
+			},
+			absent: []string{"sasl.jaas.config"},
+		},
+		{
+			testName: "sasl_plaintext listener",
+			protocol: v1beta1.SecurityProtocolSaslPlaintext,
+			expected: map[string]string{
+				"security.protocol":      "SASL_PLAINTEXT",
+				"sasl.mechanism":         "SCRAM-SHA-256",
+				"admin.sasl.jaas.config": "${dir:/var/run/secrets/kafka-connect/token:sasl.jaas.config}",
+			},
+			absent: []string{"ssl.truststore.location", "ssl.keystore.location"},
+		},
+		{
+			testName: "sasl_ssl listener",
+			protocol: v1beta1.SecurityProtocolSaslSSL,
+			expected: map[string]string{
+				"security.protocol":       "SASL_SSL",
+				"ssl.truststore.location": "/var/run/secrets/kafka-connect/credentials/truststore.jks",
+				"sasl.jaas.config":        "${dir:/var/run/secrets/kafka-connect/token:sasl.jaas.config}",
+			},
+			absent: []string{"ssl.keystore.location"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			cluster := newKafkaConnectTestCluster(test.protocol)
+			connect := &v1alpha1.KafkaConnect{
+				ObjectMeta: metav1.ObjectMeta{Name: "connect", Namespace: "kafka"},
+				Spec: v1alpha1.KafkaConnectSpec{
+					GroupID:       "workers",
+					SASLMechanism: "SCRAM-SHA-256",
+					// the security settings and the internal topics cannot be overridden
+					Config: "security.protocol=PLAINTEXT\nconfig.storage.topic=configs\nvalue.converter=org.apache.kafka.connect.converters.ByteArrayConverter\n",
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "plugins", MountPath: "/opt/plugins"},
+						{Name: "connector-files", MountPath: "/opt/connector-files"},
+					},
+					PluginVolumes: []string{"plugins"},
+				},
+			}
+			config, err := kafkaConnectWorkerConfig(connect, cluster, cluster.Spec.ListenersConfig.InternalListeners[0])
+			assert.NoError(t, err)
+
+			expected := map[string]string{
+				"bootstrap.servers":    "kafka-all-broker.kafka.svc.cluster.local:29092",
+				"group.id":             "workers",
+				"config.storage.topic": "workers-configs",
+				"offset.storage.topic": "workers-offsets",
+				"status.storage.topic": "workers-status",
+				"plugin.path":          "/opt/plugins",
+				"key.converter":        "org.apache.kafka.connect.json.JsonConverter",
+				"value.converter":      "org.apache.kafka.connect.converters.ByteArrayConverter",
+			}
+			for key, value := range test.expected {
+				expected[key] = value
+			}
+			for key, value := range expected {
+				property, found := config.Get(key)
+				assert.True(t, found, key)
+				assert.Equal(t, value, property.Value(), key)
+			}
+			for _, key := range test.absent {
+				_, found := config.Get(key)
+				assert.False(t, found, key)
+			}
+		})
+	}
+}
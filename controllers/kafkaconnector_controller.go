@@ -0,0 +1,205 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/kafkaconnect"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+var connectorFinalizer = "finalizer.kafkaconnectors.kafka.banzaicloud.io"
+
+const (
+	// The state of the connector is mirrored periodically into the status of the KafkaConnector
+	defaultKafkaConnectorResyncIntervalInSeconds = 30
+)
+
+// KafkaConnectorReconciler reconciles KafkaConnector custom resources
+type KafkaConnectorReconciler struct {
+	client.Client
+	Scheme               *runtime.Scheme
+	ConnectClientFactory func(endpoint string) kafkaconnect.Client
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaconnectors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaconnectors/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkaconnectors/finalizers,verbs=update
+
+//nolint:gocyclo
+func (r *KafkaConnectorReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	connector := &v1alpha1.KafkaConnector{}
+	if err := r.Get(ctx, request.NamespacedName, connector); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	connect := &v1alpha1.KafkaConnect{}
+	err := r.Get(ctx, types.NamespacedName{Name: connector.Spec.ConnectRef, Namespace: connector.GetNamespace()}, connect)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return requeueWithError(log, "failed to get referenced KafkaConnect", err)
+	}
+	connectFound := err == nil
+
+	if !connector.GetDeletionTimestamp().IsZero() {
+		// the connector is gone together with the Kafka Connect cluster, the REST API of a Kafka Connect cluster
+		// which is not running is not called so that the deletion of the KafkaConnector is not blocked
+		if connectFound && connect.GetDeletionTimestamp().IsZero() && connect.Status.State == v1alpha1.KafkaConnectStateRunning {
+			connectClient := r.ConnectClientFactory(connect.Status.RESTEndpoint)
+			if err = connectClient.DeleteConnector(ctx, connector.GetName()); err != nil && !errors.Is(err, kafkaconnect.ErrConnectorNotFound) {
+				return requeueWithError(log, "failed to delete connector", err)
+			}
+		}
+		if util.StringSliceContains(connector.GetFinalizers(), connectorFinalizer) {
+			controllerutil.RemoveFinalizer(connector, connectorFinalizer)
+			if err = r.Update(ctx, connector); err != nil {
+				return requeueWithError(log, "failed to remove finalizer from KafkaConnector", err)
+			}
+		}
+		return reconciled()
+	}
+
+	if !connectFound {
+		return r.updateFailedStatus(ctx, log, connector, errors.NewWithDetails("KafkaConnect not found", "kafkaConnect", connector.Spec.ConnectRef))
+	}
+	if connect.Status.State != v1alpha1.KafkaConnectStateRunning {
+		log.Info("Kafka Connect cluster is not running yet", "kafkaConnect", connect.GetName())
+		return requeueAfter(defaultRequeueIntervalInSeconds)
+	}
+
+	if !util.StringSliceContains(connector.GetFinalizers(), connectorFinalizer) {
+		controllerutil.AddFinalizer(connector, connectorFinalizer)
+		if err = r.Update(ctx, connector); err != nil {
+			return requeueWithError(log, "failed to add finalizer to KafkaConnector", err)
+		}
+	}
+
+	connectClient := r.ConnectClientFactory(connect.Status.RESTEndpoint)
+
+	status, err := connectClient.GetConnectorStatus(ctx, connector.GetName())
+	notFound := errors.Is(err, kafkaconnect.ErrConnectorNotFound)
+	if err != nil && !notFound {
+		return requeueWithError(log, "failed to get connector status", err)
+	}
+
+	// Updating the configuration restarts the connector so it is only applied when the KafkaConnector has been
+	// changed, the previous attempt failed or the connector has been removed from the Kafka Connect cluster
+	if notFound || connector.Status.ObservedGeneration != connector.GetGeneration() || connector.Status.ErrorMessage != "" {
+		log.Info("applying connector configuration", "connector", connector.GetName())
+		if err = connectClient.PutConnectorConfig(ctx, connector.GetName(), connectorConfig(connector)); err != nil {
+			return r.updateFailedStatus(ctx, log, connector, errors.WrapIf(err, "could not apply connector configuration"))
+		}
+		if status, err = connectClient.GetConnectorStatus(ctx, connector.GetName()); err != nil {
+			// the connector may not be started yet
+			log.Info("could not get connector status", "error", err.Error())
+			status = kafkaconnect.ConnectorStatus{}
+		}
+	}
+
+	if paused := status.Connector.State == kafkaconnect.ConnectorStatePaused; connector.Spec.Paused != paused {
+		if connector.Spec.Paused {
+			log.Info("pausing connector", "connector", connector.GetName())
+			err = connectClient.PauseConnector(ctx, connector.GetName())
+		} else {
+			log.Info("resuming connector", "connector", connector.GetName())
+			err = connectClient.ResumeConnector(ctx, connector.GetName())
+		}
+		if err != nil {
+			return requeueWithError(log, "failed to change the state of the connector", err)
+		}
+		if refreshed, err := connectClient.GetConnectorStatus(ctx, connector.GetName()); err == nil {
+			status = refreshed
+		}
+	}
+
+	connector.Status.ObservedGeneration = connector.GetGeneration()
+	connector.Status.ErrorMessage = ""
+	connector.Status.State = status.Connector.State
+	connector.Status.WorkerID = status.Connector.WorkerID
+	connector.Status.Tasks = connectorTaskStatuses(status.Tasks)
+	if err = r.Status().Update(ctx, connector); err != nil {
+		return requeueWithError(log, "could not update KafkaConnector status", err)
+	}
+
+	return requeueAfter(defaultKafkaConnectorResyncIntervalInSeconds)
+}
+
+func (r *KafkaConnectorReconciler) updateFailedStatus(ctx context.Context, log logr.Logger, connector *v1alpha1.KafkaConnector, err error) (ctrl.Result, error) {
+	log.Error(err, "configuring connector failed")
+	connector.Status.ObservedGeneration = connector.GetGeneration()
+	connector.Status.ErrorMessage = err.Error()
+	if err := r.Status().Update(ctx, connector); err != nil {
+		return requeueWithError(log, "could not update KafkaConnector status", err)
+	}
+	return requeueAfter(defaultRequeueIntervalInSeconds)
+}
+
+// connectorConfig returns the configuration of the connector, the name, the class and the maximum number of tasks
+// are taken from the dedicated fields of the KafkaConnector
+func connectorConfig(connector *v1alpha1.KafkaConnector) map[string]string {
+	config := make(map[string]string, len(connector.Spec.Config)+3)
+	for key, value := range connector.Spec.Config {
+		config[key] = value
+	}
+	config["name"] = connector.GetName()
+	config["connector.class"] = connector.Spec.Class
+	config["tasks.max"] = strconv.Itoa(int(connector.Spec.GetTasksMax()))
+	return config
+}
+
+func connectorTaskStatuses(tasks []kafkaconnect.TaskStatus) []v1alpha1.KafkaConnectorTaskStatus {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ret := make([]v1alpha1.KafkaConnectorTaskStatus, 0, len(tasks))
+	for _, task := range tasks {
+		ret = append(ret, v1alpha1.KafkaConnectorTaskStatus{
+			ID:       task.ID,
+			State:    task.State,
+			WorkerID: task.WorkerID,
+			Trace:    task.Trace,
+		})
+	}
+	return ret
+}
+
+// SetupKafkaConnectorWithManager registers KafkaConnector controller to the manager
+func SetupKafkaConnectorWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.KafkaConnector{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
+		Named("KafkaConnector")
+}
+
+// blank assignment to verify that KafkaConnectorReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &KafkaConnectorReconciler{}
@@ -0,0 +1,188 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/kafkaconnect"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+type fakeConnectClient struct {
+	connectors map[string]map[string]string
+	states     map[string]string
+	puts       int
+}
+
+func (c *fakeConnectClient) PutConnectorConfig(_ context.Context, name string, config map[string]string) error {
+	c.connectors[name] = config
+	if _, ok := c.states[name]; !ok {
+		c.states[name] = kafkaconnect.ConnectorStateRunning
+	}
+	c.puts++
+	return nil
+}
+
+func (c *fakeConnectClient) GetConnectorStatus(_ context.Context, name string) (kafkaconnect.ConnectorStatus, error) {
+	if _, ok := c.connectors[name]; !ok {
+		return kafkaconnect.ConnectorStatus{}, kafkaconnect.ErrConnectorNotFound
+	}
+	return kafkaconnect.ConnectorStatus{
+		Name:      name,
+		Connector: kafkaconnect.TaskState{State: c.states[name], WorkerID: "10.0.0.1:8083"},
+		Tasks: []kafkaconnect.TaskStatus{
+			{ID: 0, TaskState: kafkaconnect.TaskState{State: c.states[name], WorkerID: "10.0.0.2:8083"}},
+		},
+	}, nil
+}
+
+func (c *fakeConnectClient) PauseConnector(_ context.Context, name string) error {
+	c.states[name] = kafkaconnect.ConnectorStatePaused
+	return nil
+}
+
+func (c *fakeConnectClient) ResumeConnector(_ context.Context, name string) error {
+	c.states[name] = kafkaconnect.ConnectorStateRunning
+	return nil
+}
+
+func (c *fakeConnectClient) DeleteConnector(_ context.Context, name string) error {
+	if _, ok := c.connectors[name]; !ok {
+		return kafkaconnect.ErrConnectorNotFound
+	}
+	delete(c.connectors, name)
+	delete(c.states, name)
+	return nil
+}
+
+func newKafkaConnectorTestReconciler(t *testing.T, objects ...client.Object) (*KafkaConnectorReconciler, *fakeConnectClient) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	connectClient := &fakeConnectClient{connectors: make(map[string]map[string]string), states: make(map[string]string)}
+	return &KafkaConnectorReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Scheme: scheme,
+		ConnectClientFactory: func(endpoint string) kafkaconnect.Client {
+			assert.Equal(t, "http://connect-connect.kafka.svc.cluster.local:8083", endpoint)
+			return connectClient
+		},
+	}, connectClient
+}
+
+func TestKafkaConnectorReconcile(t *testing.T) {
+	connect := &v1alpha1.KafkaConnect{
+		ObjectMeta: metav1.ObjectMeta{Name: "connect", Namespace: "kafka"},
+		Status: v1alpha1.KafkaConnectStatus{
+			State:        v1alpha1.KafkaConnectStateRunning,
+			RESTEndpoint: "http://connect-connect.kafka.svc.cluster.local:8083",
+		},
+	}
+	connector := &v1alpha1.KafkaConnector{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders-sink", Namespace: "kafka", Generation: 1},
+		Spec: v1alpha1.KafkaConnectorSpec{
+			ConnectRef: "connect",
+			Class:      "org.example.OrdersSink",
+			TasksMax:   util.Int32Pointer(2),
+			Config:     map[string]string{"topics": "orders", "tasks.max": "5"},
+		},
+	}
+	r, connectClient := newKafkaConnectorTestReconciler(t, connect, connector)
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "orders-sink", Namespace: "kafka"}}
+
+	reconcileAndGet := func() *v1alpha1.KafkaConnector {
+		_, err := r.Reconcile(ctx, request)
+		assert.NoError(t, err)
+		current := &v1alpha1.KafkaConnector{}
+		assert.NoError(t, r.Get(ctx, request.NamespacedName, current))
+		return current
+	}
+
+	current := reconcileAndGet()
+	assert.Equal(t, map[string]string{
+		"name":            "orders-sink",
+		"connector.class": "org.example.OrdersSink",
+		"tasks.max":       "2",
+		"topics":          "orders",
+	}, connectClient.connectors["orders-sink"])
+	assert.Equal(t, []string{connectorFinalizer}, current.GetFinalizers())
+	assert.Equal(t, kafkaconnect.ConnectorStateRunning, current.Status.State)
+	assert.Equal(t, "10.0.0.1:8083", current.Status.WorkerID)
+	assert.Equal(t, []v1alpha1.KafkaConnectorTaskStatus{
+		{ID: 0, State: kafkaconnect.ConnectorStateRunning, WorkerID: "10.0.0.2:8083"},
+	}, current.Status.Tasks)
+	assert.Equal(t, int64(1), current.Status.ObservedGeneration)
+
+	// The configuration is not applied again while the KafkaConnector is unchanged
+	current = reconcileAndGet()
+	assert.Equal(t, 1, connectClient.puts)
+
+	current.Spec.Paused = true
+	current.Generation = 2
+	assert.NoError(t, r.Update(ctx, current))
+	current = reconcileAndGet()
+	assert.Equal(t, 2, connectClient.puts)
+	assert.Equal(t, kafkaconnect.ConnectorStatePaused, current.Status.State)
+
+	current.Spec.Paused = false
+	current.Generation = 3
+	assert.NoError(t, r.Update(ctx, current))
+	current = reconcileAndGet()
+	assert.Equal(t, kafkaconnect.ConnectorStateRunning, current.Status.State)
+
+	// The connector is removed from the Kafka Connect cluster when the KafkaConnector is deleted
+	assert.NoError(t, r.Delete(ctx, current))
+	_, err := r.Reconcile(ctx, request)
+	assert.NoError(t, err)
+	assert.Empty(t, connectClient.connectors)
+	assert.Error(t, r.Get(ctx, request.NamespacedName, &v1alpha1.KafkaConnector{}))
+}
+
+func TestKafkaConnectorReconcileWithoutConnect(t *testing.T) {
+	connector := &v1alpha1.KafkaConnector{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "orders-sink",
+			Namespace:         "kafka",
+			Finalizers:        []string{connectorFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Spec: v1alpha1.KafkaConnectorSpec{ConnectRef: "connect", Class: "org.example.OrdersSink"},
+	}
+	r, _ := newKafkaConnectorTestReconciler(t, connector)
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "orders-sink", Namespace: "kafka"}}
+
+	// The deletion is not blocked by a missing Kafka Connect cluster
+	_, err := r.Reconcile(ctx, request)
+	assert.NoError(t, err)
+	current := &v1alpha1.KafkaConnector{}
+	err = r.Get(ctx, request.NamespacedName, current)
+	assert.True(t, err != nil || len(current.GetFinalizers()) == 0)
+}
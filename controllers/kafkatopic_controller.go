@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -52,6 +53,7 @@ func SetupKafkaTopicWithManager(mgr ctrl.Manager, maxConcurrentReconciles int) *
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.KafkaTopic{}).
 		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
 		Named("KafkaTopic")
 	builder.WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles})
 
@@ -67,6 +69,8 @@ type KafkaTopicReconciler struct {
 	// that reads objects from the cache and writes to the apiserver
 	Client client.Client
 	Scheme *runtime.Scheme
+	// FinalizerPolicy defines whether the finalizer is added to the KafkaTopics and when it is released without cleanup
+	FinalizerPolicy FinalizerPolicy
 }
 
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkatopics,verbs=get;list;watch;create;update;patch;delete;deletecollection
@@ -89,6 +93,14 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 		return requeueWithError(reqLogger, err.Error(), err)
 	}
 
+	if r.FinalizerPolicy.forceRelease(instance, time.Now()) && util.StringSliceContains(instance.GetFinalizers(), topicFinalizer) {
+		reqLogger.Info("Releasing the finalizer of the KafkaTopic without deleting the topic from Kafka")
+		if err = r.removeFinalizer(ctx, instance); err != nil {
+			return requeueWithError(reqLogger, "failed to remove finalizer from kafkatopic", err)
+		}
+		return reconciled()
+	}
+
 	// Get the referenced kafkacluster
 	clusterNamespace := getClusterRefNamespace(instance.Namespace, instance.Spec.ClusterRef)
 	var cluster *v1beta1.KafkaCluster
@@ -105,6 +117,12 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 		// the cluster does not exist - should have been caught pre-flight
 		return requeueWithError(reqLogger, "failed to lookup referenced cluster", err)
 	}
+	// the topic is deleted on deletion even when the namespace is no longer allowed to reference the cluster
+	if !k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		if err = k8sutil.CheckCrossNamespaceReference(ctx, r.Client, cluster, instance.GetNamespace()); err != nil {
+			return requeueWithError(reqLogger, "kafkatopic is not allowed to reference the cluster", err)
+		}
+	}
 
 	// Set managed status based on KafkaTopic managedBy annotation
 	managedByStatus := webhooks.TopicManagedByKoperatorAnnotationValue
@@ -128,12 +146,18 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 
 	// Check if marked for deletion and if so run finalizers
 	if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
-		return r.checkFinalizers(ctx, broker, instance)
+		return r.checkFinalizers(ctx, cluster, broker, instance)
 	}
 
 	// No need to do anything when the kafka topic is not managed by Koperator
 	if !isTopicManagedByKoperator(instance) {
 		reqLogger.Info(fmt.Sprintf("topic '%s' is not managed by %s it is managed by '%s' ==> nothing to reconcile here", instance.Spec.Name, webhooks.TopicManagedByKoperatorAnnotationValue, managedByStatus))
+		if instance.Status.ObservedGeneration != instance.GetGeneration() {
+			instance.Status.ObservedGeneration = instance.GetGeneration()
+			if err := r.Client.Status().Update(ctx, instance); err != nil {
+				return requeueWithError(reqLogger, "failed to update kafkatopic status", err)
+			}
+		}
 		return reconciled()
 	}
 
@@ -179,7 +203,7 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 	}
 
 	// ensure a finalizer for cleanup on deletion
-	if !util.StringSliceContains(instance.GetFinalizers(), topicFinalizer) {
+	if !r.FinalizerPolicy.skipFinalizer(instance) && !util.StringSliceContains(instance.GetFinalizers(), topicFinalizer) {
 		reqLogger.Info("Adding Finalizer for the KafkaTopic")
 		instance.SetFinalizers(append(instance.GetFinalizers(), topicFinalizer))
 		if instance, err = r.updateAndFetchLatest(ctx, instance); err != nil {
@@ -188,8 +212,9 @@ func (r *KafkaTopicReconciler) Reconcile(ctx context.Context, request reconcile.
 	}
 
 	// set topic status as created
-	if instance.Status.State != v1alpha1.TopicStateCreated {
+	if instance.Status.State != v1alpha1.TopicStateCreated || instance.Status.ObservedGeneration != instance.GetGeneration() {
 		instance.Status.State = v1alpha1.TopicStateCreated
+		instance.Status.ObservedGeneration = instance.GetGeneration()
 		if err := r.Client.Status().Update(ctx, instance); err != nil {
 			return requeueWithError(reqLogger, "failed to update kafkatopic status", err)
 		}
@@ -219,13 +244,17 @@ func (r *KafkaTopicReconciler) updateAndFetchLatest(ctx context.Context, topic *
 	return topic, nil
 }
 
-func (r *KafkaTopicReconciler) checkFinalizers(ctx context.Context, broker kafkaclient.KafkaClient, topic *v1alpha1.KafkaTopic) (reconcile.Result, error) {
+func (r *KafkaTopicReconciler) checkFinalizers(ctx context.Context, cluster *v1beta1.KafkaCluster, broker kafkaclient.KafkaClient, topic *v1alpha1.KafkaTopic) (reconcile.Result, error) {
 	reqLogger := logr.FromContextOrDiscard(ctx)
 	reqLogger.Info("Kafka topic is marked for deletion")
 	var err error
 	if util.StringSliceContains(topic.GetFinalizers(), topicFinalizer) {
-		// Remove topic from Kafka cluster when it is managed by Koperator
-		if isTopicManagedByKoperator(topic) {
+		// Remove topic from Kafka cluster when it is managed by Koperator, unless the cluster is being deleted
+		// and its data is retained
+		switch {
+		case k8sutil.IsMarkedForDeletion(cluster.ObjectMeta) && cluster.Spec.RetainsData():
+			reqLogger.Info("Cluster is being deleted with its data retained, skipping topic deletion")
+		case isTopicManagedByKoperator(topic):
 			if err = r.finalizeKafkaTopic(reqLogger, broker, topic); err != nil {
 				return requeueWithError(reqLogger, "failed to finalize kafkatopic", err)
 			}
@@ -0,0 +1,151 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// KafkaTopicPartitionScalingReconciler recommends partition counts for the KafkaTopics with a partition scaling
+// policy based on the lag of their consumer groups
+type KafkaTopicPartitionScalingReconciler struct {
+	client.Client
+	Scheme              *runtime.Scheme
+	KafkaClientProvider kafkaclient.Provider
+}
+
+// Reconcile evaluates the consumer lag of the KafkaTopic and records the recommended partition count in its status
+func (r *KafkaTopicPartitionScalingReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	topic := &v1alpha1.KafkaTopic{}
+	if err := r.Get(ctx, request.NamespacedName, topic); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	policy := topic.Spec.PartitionScaling
+	if policy == nil || k8sutil.IsMarkedForDeletion(topic.ObjectMeta) || !isTopicManagedByKoperator(topic) {
+		return reconciled()
+	}
+	interval := int(policy.GetEvaluationIntervalSeconds())
+	// The partitions of the topic can only be increased once it has been created by the KafkaTopic controller
+	if topic.Status.State != v1alpha1.TopicStateCreated {
+		return requeueAfter(interval)
+	}
+
+	cluster, err := k8sutil.LookupKafkaCluster(ctx, r.Client, topic.Spec.ClusterRef.Name,
+		getClusterRefNamespace(topic.GetNamespace(), topic.Spec.ClusterRef))
+	if err != nil {
+		return requeueWithError(log, "failed to lookup referenced kafka cluster", err)
+	}
+	if err = k8sutil.CheckCrossNamespaceReference(ctx, r.Client, cluster, topic.GetNamespace()); err != nil {
+		return requeueWithError(log, "kafkatopic is not allowed to reference the cluster", err)
+	}
+
+	kClient, close, err := r.KafkaClientProvider.NewFromCluster(r.Client, cluster)
+	if err != nil {
+		return checkBrokerConnectionError(log, err)
+	}
+	defer close()
+
+	existing, err := kClient.GetTopic(topic.Spec.Name)
+	if err != nil {
+		return requeueWithError(log, "failure checking for existing topic", err)
+	}
+	if existing == nil {
+		return requeueAfter(interval)
+	}
+	lags, err := kClient.ConsumerGroupLags(topic.Spec.Name)
+	if err != nil {
+		return requeueWithError(log, "could not get the lag of the consumer groups", err)
+	}
+
+	recommendation := recommendPartitions(policy, existing.NumPartitions, lags, time.Now())
+	if policy.AutoApply && recommendation.RecommendedPartitions > topic.Spec.Partitions {
+		log.Info("increasing the partitions of the topic according to the consumer lag",
+			"topic", topic.Spec.Name, "from", topic.Spec.Partitions, "to", recommendation.RecommendedPartitions,
+			"consumerGroup", recommendation.ConsumerGroup, "consumerLag", recommendation.ConsumerLag)
+		topic.Spec.Partitions = recommendation.RecommendedPartitions
+		if err := r.Update(ctx, topic); err != nil {
+			return requeueWithError(log, "could not increase the partitions of the KafkaTopic", err)
+		}
+		recommendation.Applied = true
+	}
+
+	topic.Status.PartitionRecommendation = &recommendation
+	if err := r.Status().Update(ctx, topic); err != nil {
+		return requeueWithError(log, "could not update KafkaTopic status", err)
+	}
+	return requeueAfter(interval)
+}
+
+// recommendPartitions returns the partition count which keeps the lag per partition of the consumer group with the
+// highest lag within the policy. Partitions are never decreased and the recommendation is capped by the policy.
+func recommendPartitions(policy *v1alpha1.PartitionScalingPolicy, currentPartitions int32, lags map[string]int64,
+	now time.Time) v1alpha1.PartitionRecommendation {
+	recommendation := v1alpha1.PartitionRecommendation{
+		CurrentPartitions:     currentPartitions,
+		RecommendedPartitions: currentPartitions,
+		LastEvaluatedTime:     metav1.NewTime(now),
+	}
+	for group, lag := range lags {
+		if lag > recommendation.ConsumerLag || (lag == recommendation.ConsumerLag && group < recommendation.ConsumerGroup) {
+			recommendation.ConsumerGroup = group
+			recommendation.ConsumerLag = lag
+		}
+	}
+
+	if policy.MaxLagPerPartition <= 0 {
+		return recommendation
+	}
+	needed := (recommendation.ConsumerLag + policy.MaxLagPerPartition - 1) / policy.MaxLagPerPartition
+	if needed > int64(policy.MaxPartitions) {
+		needed = int64(policy.MaxPartitions)
+	}
+	if needed > int64(currentPartitions) {
+		recommendation.RecommendedPartitions = int32(needed)
+	}
+	return recommendation
+}
+
+// SetupKafkaTopicPartitionScalingWithManager registers the KafkaTopic partition scaling controller to the manager
+func SetupKafkaTopicPartitionScalingWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.KafkaTopic{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
+		Named("KafkaTopicPartitionScaling")
+}
+
+// blank assignment to verify that KafkaTopicPartitionScalingReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &KafkaTopicPartitionScalingReconciler{}
@@ -0,0 +1,158 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+type partitionScalingTestKafkaClient struct {
+	kafkaclient.KafkaClient
+	partitions int32
+	lags       map[string]int64
+}
+
+func (c *partitionScalingTestKafkaClient) GetTopic(string) (*sarama.TopicDetail, error) {
+	return &sarama.TopicDetail{NumPartitions: c.partitions}, nil
+}
+
+func (c *partitionScalingTestKafkaClient) ConsumerGroupLags(string) (map[string]int64, error) {
+	return c.lags, nil
+}
+
+type partitionScalingTestProvider struct {
+	kafkaClient *partitionScalingTestKafkaClient
+}
+
+func (p *partitionScalingTestProvider) NewFromCluster(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+	return p.kafkaClient, func() {}, nil
+}
+
+func TestRecommendPartitions(t *testing.T) {
+	now := time.Now()
+	policy := &v1alpha1.PartitionScalingPolicy{MaxLagPerPartition: 100, MaxPartitions: 12}
+	testCases := []struct {
+		testName            string
+		lags                map[string]int64
+		expectedGroup       string
+		expectedPartitions  int32
+		expectedConsumerLag int64
+	}{
+		{
+			testName:           "no consumer groups",
+			expectedPartitions: 3,
+		},
+		{
+			testName:            "lag within the policy",
+			lags:                map[string]int64{"group-a": 250},
+			expectedGroup:       "group-a",
+			expectedPartitions:  3,
+			expectedConsumerLag: 250,
+		},
+		{
+			testName:            "group with the highest lag is used",
+			lags:                map[string]int64{"group-a": 250, "group-b": 701},
+			expectedGroup:       "group-b",
+			expectedPartitions:  8,
+			expectedConsumerLag: 701,
+		},
+		{
+			testName:            "recommendation is capped",
+			lags:                map[string]int64{"group-a": 5000},
+			expectedGroup:       "group-a",
+			expectedPartitions:  12,
+			expectedConsumerLag: 5000,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			recommendation := recommendPartitions(policy, 3, testCase.lags, now)
+			assert.Equal(t, v1alpha1.PartitionRecommendation{
+				ConsumerGroup:         testCase.expectedGroup,
+				ConsumerLag:           testCase.expectedConsumerLag,
+				CurrentPartitions:     3,
+				RecommendedPartitions: testCase.expectedPartitions,
+				LastEvaluatedTime:     metav1.NewTime(now),
+			}, recommendation)
+		})
+	}
+}
+
+func TestKafkaTopicPartitionScalingReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	topic := &v1alpha1.KafkaTopic{
+		ObjectMeta: metav1.ObjectMeta{Name: "hot-topic", Namespace: "kafka"},
+		Spec: v1alpha1.KafkaTopicSpec{
+			Name:       "hot-topic",
+			Partitions: 3,
+			ClusterRef: v1alpha1.ClusterReference{Name: "kafka"},
+			PartitionScaling: &v1alpha1.PartitionScalingPolicy{
+				MaxLagPerPartition: 100,
+				MaxPartitions:      6,
+			},
+		},
+		Status: v1alpha1.KafkaTopicStatus{State: v1alpha1.TopicStateCreated},
+	}
+	kafkaClient := &partitionScalingTestKafkaClient{partitions: 3, lags: map[string]int64{"consumer": 450}}
+	r := &KafkaTopicPartitionScalingReconciler{
+		Client:              fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, topic).Build(),
+		Scheme:              scheme,
+		KafkaClientProvider: &partitionScalingTestProvider{kafkaClient: kafkaClient},
+	}
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "hot-topic", Namespace: "kafka"}}
+
+	result, err := r.Reconcile(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(v1alpha1.DefaultPartitionScalingEvaluationIntervalSeconds)*time.Second, result.RequeueAfter)
+
+	current := &v1alpha1.KafkaTopic{}
+	require.NoError(t, r.Get(ctx, request.NamespacedName, current))
+	require.NotNil(t, current.Status.PartitionRecommendation)
+	assert.Equal(t, int32(5), current.Status.PartitionRecommendation.RecommendedPartitions)
+	assert.False(t, current.Status.PartitionRecommendation.Applied)
+	// The recommendation is only reported without auto-apply
+	assert.Equal(t, int32(3), current.Spec.Partitions)
+
+	current.Spec.PartitionScaling.AutoApply = true
+	require.NoError(t, r.Update(ctx, current))
+	_, err = r.Reconcile(ctx, request)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Get(ctx, request.NamespacedName, current))
+	assert.Equal(t, int32(5), current.Spec.Partitions)
+	assert.True(t, current.Status.PartitionRecommendation.Applied)
+}
@@ -0,0 +1,260 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+	kafkautil "github.com/banzaicloud/koperator/pkg/util/kafka"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	connectionProfileDefaultKey           = "client.properties"
+	connectionProfileListenerKeyTemplate  = "%s.properties"
+	connectionProfileKeyStoreKey          = "keystore.pem"
+	connectionProfileTrustStoreKey        = "truststore.pem"
+	connectionProfileDefaultSASLMechanism = "SCRAM-SHA-512"
+	pemStoreType                          = "PEM"
+)
+
+// reconcileConnectionProfile writes the client connection profile of the KafkaUser into the Secret given in its spec.
+// The key material is included only when the certificate of the user has been issued by the operator.
+func (r *KafkaUserReconciler) reconcileConnectionProfile(ctx context.Context, cluster *v1beta1.KafkaCluster,
+	user *v1alpha1.KafkaUser, userCertificate *pkicommon.UserCertificate) error {
+	profile := user.Spec.ConnectionProfile
+	if profile.SecretName == user.Spec.SecretName {
+		return errors.NewWithDetails("the connection profile cannot be stored in the secret of the user", "secret", profile.SecretName)
+	}
+
+	var stores map[string][]byte
+	if userCertificate != nil {
+		keyStore, err := pemKeyStore(userCertificate)
+		if err != nil {
+			return err
+		}
+		trustStore, err := r.userTrustBundle(ctx, cluster, userCertificate)
+		if err != nil {
+			return err
+		}
+		stores = map[string][]byte{
+			connectionProfileKeyStoreKey:   keyStore,
+			connectionProfileTrustStoreKey: trustStore,
+		}
+	}
+
+	var jaasConfig string
+	if user.Spec.DelegationToken != nil {
+		tokenSecret := &corev1.Secret{}
+		err := r.Client.Get(ctx, types.NamespacedName{Namespace: user.GetNamespace(), Name: user.Spec.DelegationToken.SecretName}, tokenSecret)
+		if client.IgnoreNotFound(err) != nil {
+			return errors.WrapIfWithDetails(err, "could not get delegation token secret", "secret", user.Spec.DelegationToken.SecretName)
+		}
+		jaasConfig = string(tokenSecret.Data[v1alpha1.DelegationTokenJAASConfigKey])
+	}
+
+	data, err := connectionProfileData(cluster, profile, stores != nil, jaasConfig)
+	if err != nil {
+		return err
+	}
+	for key, value := range stores {
+		data[key] = value
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: profile.SecretName, Namespace: user.GetNamespace()},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Data = data
+		return controllerutil.SetControllerReference(user, secret, r.Scheme)
+	})
+	return errors.WrapIfWithDetails(err, "could not store connection profile", "secret", secret.GetName())
+}
+
+type connectionProfileClusterMapper struct {
+	client client.Reader
+	log    logr.Logger
+}
+
+// mapToKafkaUsers maps KafkaCluster events to the KafkaUsers of the cluster which have a connection profile, so that
+// the profiles follow the changes of the listener addresses
+func (m *connectionProfileClusterMapper) mapToKafkaUsers(obj client.Object) []ctrl.Request {
+	cluster, ok := obj.(*v1beta1.KafkaCluster)
+	if !ok {
+		return nil
+	}
+	var users v1alpha1.KafkaUserList
+	if err := m.client.List(context.Background(), &users, client.MatchingLabels{clusterRefLabel: clusterLabelString(cluster)}); err != nil {
+		m.log.Error(err, "couldn't list KafkaUsers", "cluster", cluster.GetName())
+		return nil
+	}
+	var requests []ctrl.Request
+	for i := range users.Items {
+		user := &users.Items[i]
+		if user.Spec.ConnectionProfile == nil || util.ObjectManagedByClusterRegistry(user) {
+			continue
+		}
+		requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: user.GetNamespace(), Name: user.GetName()}})
+	}
+	return requests
+}
+
+// listenerStatusesChangedPredicate passes the updates of the KafkaClusters whose listener addresses have changed
+func listenerStatusesChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCluster, ok := e.ObjectOld.(*v1beta1.KafkaCluster)
+			if !ok {
+				return false
+			}
+			newCluster, ok := e.ObjectNew.(*v1beta1.KafkaCluster)
+			if !ok {
+				return false
+			}
+			return !reflect.DeepEqual(oldCluster.Status.ListenerStatuses, newCluster.Status.ListenerStatuses)
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// connectionProfileData returns the client properties of the listeners of the cluster which have an address together
+// with the service binding keys of the default listener
+func connectionProfileData(cluster *v1beta1.KafkaCluster, profile *v1alpha1.UserConnectionProfile, withStores bool,
+	jaasConfig string) (map[string][]byte, error) {
+	defaultListener := profile.Listener
+	var binding map[string][]byte
+	data := make(map[string][]byte)
+	addListener := func(listener v1beta1.CommonListenerSpec, statuses v1beta1.ListenerStatusList) {
+		bootstrapServers := kafkautil.ListenerBootstrapServers(statuses)
+		if bootstrapServers == "" {
+			return
+		}
+		data[fmt.Sprintf(connectionProfileListenerKeyTemplate, listener.Name)] =
+			listenerClientProperties(cluster, profile, listener, bootstrapServers, withStores, jaasConfig)
+		if listener.Name == defaultListener {
+			var saslMechanism string
+			if jaasConfig != "" && (listener.Type == v1beta1.SecurityProtocolSaslSSL || listener.Type == v1beta1.SecurityProtocolSaslPlaintext) {
+				saslMechanism = connectionProfileSASLMechanism(cluster)
+			}
+			binding = kafkautil.ServiceBindingData(bootstrapServers, listener.Type, saslMechanism)
+		}
+	}
+	for _, listener := range cluster.Spec.ListenersConfig.InternalListeners {
+		if listener.UsedForInnerBrokerCommunication && defaultListener == "" {
+			defaultListener = listener.Name
+		}
+		// the controller listener is not used by the clients
+		if listener.UsedForControllerCommunication {
+			continue
+		}
+		addListener(listener.CommonListenerSpec, cluster.Status.ListenerStatuses.InternalListeners[listener.Name])
+	}
+	for _, listener := range cluster.Spec.ListenersConfig.ExternalListeners {
+		addListener(listener.CommonListenerSpec, cluster.Status.ListenerStatuses.ExternalListeners[listener.Name])
+	}
+
+	defaultProperties, ok := data[fmt.Sprintf(connectionProfileListenerKeyTemplate, defaultListener)]
+	if !ok {
+		return nil, errors.NewWithDetails("the listener of the connection profile has no address", "listener", defaultListener)
+	}
+	data[connectionProfileDefaultKey] = defaultProperties
+	for key, value := range binding {
+		data[key] = value
+	}
+	return data, nil
+}
+
+func listenerClientProperties(cluster *v1beta1.KafkaCluster, profile *v1alpha1.UserConnectionProfile,
+	listener v1beta1.CommonListenerSpec, bootstrapServers string, withStores bool, jaasConfig string) []byte {
+	config := properties.NewProperties()
+	_ = config.Set(kafkautil.KafkaConfigBoostrapServers, bootstrapServers)
+	_ = config.Set(kafkautil.KafkaConfigSecurityProtocol, listener.Type.ToUpperString())
+
+	if withStores && (listener.Type == v1beta1.SecurityProtocolSSL || listener.Type == v1beta1.SecurityProtocolSaslSSL) {
+		_ = config.Set(kafkautil.KafkaConfigSSLTrustStoreType, pemStoreType)
+		_ = config.Set(kafkautil.KafkaConfigSSLTrustStoreLocation, profile.GetMountPath()+"/"+connectionProfileTrustStoreKey)
+		// the brokers authenticate the clients of SASL listeners with SASL
+		if listener.Type == v1beta1.SecurityProtocolSSL {
+			_ = config.Set(kafkautil.KafkaConfigSSLKeystoreType, pemStoreType)
+			_ = config.Set(kafkautil.KafkaConfigSSLKeyStoreLocation, profile.GetMountPath()+"/"+connectionProfileKeyStoreKey)
+		}
+	}
+	if jaasConfig != "" && (listener.Type == v1beta1.SecurityProtocolSaslSSL || listener.Type == v1beta1.SecurityProtocolSaslPlaintext) {
+		_ = config.Set(kafkautil.KafkaConfigSASLMechanism, connectionProfileSASLMechanism(cluster))
+		_ = config.Set(kafkautil.KafkaConfigSASLJAASConfig, jaasConfig)
+	}
+	return []byte(config.String())
+}
+
+// connectionProfileSASLMechanism returns the SCRAM mechanism enabled on the brokers which the delegation tokens
+// are used with
+func connectionProfileSASLMechanism(cluster *v1beta1.KafkaCluster) string {
+	config, err := properties.NewFromString(cluster.Spec.ReadOnlyConfig)
+	if err != nil {
+		return connectionProfileDefaultSASLMechanism
+	}
+	if mechanisms, ok := config.Get(kafkautil.KafkaConfigSASLEnabledMechanisms); ok {
+		for _, mechanism := range strings.Split(mechanisms.Value(), ",") {
+			if mechanism = strings.TrimSpace(mechanism); strings.HasPrefix(mechanism, "SCRAM-") {
+				return mechanism
+			}
+		}
+	}
+	return connectionProfileDefaultSASLMechanism
+}
+
+// pemKeyStore returns the private key of the user in PKCS#8 format followed by its certificate chain, the format the
+// Kafka clients accept as PEM keystore
+func pemKeyStore(userCertificate *pkicommon.UserCertificate) ([]byte, error) {
+	key, err := certutil.DecodePrivateKeyBytes(userCertificate.Key)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not decode private key of user")
+	}
+	pkcs8Key, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not encode private key of user")
+	}
+	keyStore := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Key})
+	return append(keyStore, userCertificate.Certificate...), nil
+}
@@ -0,0 +1,148 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+)
+
+func TestConnectionProfileData(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			ReadOnlyConfig: "sasl.enabled.mechanisms=PLAIN,SCRAM-SHA-256",
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL},
+						UsedForInnerBrokerCommunication: true,
+					},
+					{
+						CommonListenerSpec:             v1beta1.CommonListenerSpec{Name: "controller", Type: v1beta1.SecurityProtocolSSL},
+						UsedForControllerCommunication: true,
+					},
+				},
+				ExternalListeners: []v1beta1.ExternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "external", Type: v1beta1.SecurityProtocolSaslSSL}},
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "pending", Type: v1beta1.SecurityProtocolPlaintext}},
+				},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			ListenerStatuses: v1beta1.ListenerStatuses{
+				InternalListeners: map[string]v1beta1.ListenerStatusList{
+					"internal": {
+						{Name: "any-broker", Address: "kafka-all-broker.kafka.svc.cluster.local:29092"},
+						{Name: "broker-0", Address: "kafka-0.kafka.svc.cluster.local:29092"},
+					},
+					"controller": {{Name: "any-broker", Address: "kafka-all-broker.kafka.svc.cluster.local:29093"}},
+				},
+				ExternalListeners: map[string]v1beta1.ListenerStatusList{
+					"external": {
+						{Name: "broker-0", Address: "kafka.example.com:19090"},
+						{Name: "broker-1", Address: "kafka.example.com:19091"},
+					},
+				},
+			},
+		},
+	}
+	profile := &v1alpha1.UserConnectionProfile{SecretName: "app-profile"}
+
+	data, err := connectionProfileData(cluster, profile, true, `ScramLoginModule required username="id" password="hmac" tokenauth="true";`)
+	require.NoError(t, err)
+	assert.Len(t, data, 7)
+	internal := "bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:29092\n" +
+		"security.protocol=SSL\n" +
+		"ssl.truststore.type=PEM\n" +
+		"ssl.truststore.location=/etc/kafka/client/truststore.pem\n" +
+		"ssl.keystore.type=PEM\n" +
+		"ssl.keystore.location=/etc/kafka/client/keystore.pem\n"
+	assert.Equal(t, internal, string(data["internal.properties"]))
+	assert.Equal(t, internal, string(data["client.properties"]))
+	assert.Equal(t, "bootstrap.servers=kafka.example.com:19090,kafka.example.com:19091\n"+
+		"security.protocol=SASL_SSL\n"+
+		"ssl.truststore.type=PEM\n"+
+		"ssl.truststore.location=/etc/kafka/client/truststore.pem\n"+
+		"sasl.mechanism=SCRAM-SHA-256\n"+
+		`sasl.jaas.config=ScramLoginModule required username="id" password="hmac" tokenauth="true";`+"\n",
+		string(data["external.properties"]))
+	assert.Equal(t, "kafka", string(data["type"]))
+	assert.Equal(t, "koperator", string(data["provider"]))
+	assert.Equal(t, "kafka-all-broker.kafka.svc.cluster.local:29092", string(data["bootstrap-servers"]))
+	assert.Equal(t, "SSL", string(data["securityProtocol"]))
+
+	// the service binding keys follow the default listener
+	profile.Listener = "external"
+	data, err = connectionProfileData(cluster, profile, true, `ScramLoginModule required username="id" password="hmac" tokenauth="true";`)
+	require.NoError(t, err)
+	assert.Equal(t, data["external.properties"], data["client.properties"])
+	assert.Equal(t, "kafka.example.com:19090,kafka.example.com:19091", string(data["bootstrap-servers"]))
+	assert.Equal(t, "SASL_SSL", string(data["securityProtocol"]))
+	assert.Equal(t, "SCRAM-SHA-256", string(data["saslMechanism"]))
+
+	profile.Listener = "pending"
+	_, err = connectionProfileData(cluster, profile, false, "")
+	assert.Error(t, err)
+}
+
+func TestPEMKeyStore(t *testing.T) {
+	cert, key, _, err := certutil.GenerateTestCert()
+	require.NoError(t, err)
+
+	keyStore, err := pemKeyStore(&pkicommon.UserCertificate{Certificate: cert, Key: key})
+	require.NoError(t, err)
+	block, rest := pem.Decode(keyStore)
+	require.NotNil(t, block)
+	assert.Equal(t, "PRIVATE KEY", block.Type)
+	assert.Equal(t, cert, rest)
+}
+
+func TestConnectionProfileClusterMapper(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	labels := map[string]string{clusterRefLabel: clusterLabelString(cluster)}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1alpha1.KafkaUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "apps", Labels: labels},
+			Spec:       v1alpha1.KafkaUserSpec{ConnectionProfile: &v1alpha1.UserConnectionProfile{SecretName: "app-profile"}},
+		},
+		&v1alpha1.KafkaUser{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "apps", Labels: labels}},
+	).Build()
+
+	mapper := connectionProfileClusterMapper{client: c}
+	assert.Equal(t, []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: "apps", Name: "app"}}}, mapper.mapToKafkaUsers(cluster))
+
+	updated := cluster.DeepCopy()
+	assert.False(t, listenerStatusesChangedPredicate().Update(event.UpdateEvent{ObjectOld: cluster, ObjectNew: updated}))
+	updated.Status.ListenerStatuses.ExternalListeners = map[string]v1beta1.ListenerStatusList{
+		"external": {{Name: "any-broker", Address: "kafka.example.com:9094"}},
+	}
+	assert.True(t, listenerStatusesChangedPredicate().Update(event.UpdateEvent{ObjectOld: cluster, ObjectNew: updated}))
+}
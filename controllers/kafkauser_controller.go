@@ -15,6 +15,7 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"reflect"
@@ -28,6 +29,7 @@ import (
 	certv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/go-logr/logr"
 	certsigningreqv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -46,6 +48,7 @@ import (
 	"github.com/banzaicloud/koperator/pkg/k8sutil"
 	"github.com/banzaicloud/koperator/pkg/pki"
 	"github.com/banzaicloud/koperator/pkg/util"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
 	kafkautil "github.com/banzaicloud/koperator/pkg/util/kafka"
 	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
 
@@ -60,6 +63,7 @@ func SetupKafkaUserWithManager(mgr ctrl.Manager, certSigningEnabled bool, certMa
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.KafkaUser{}).
 		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
 		Named("KafkaUser")
 	if certSigningEnabled {
 		csrMapper := csrMapper{
@@ -74,6 +78,21 @@ func SetupKafkaUserWithManager(mgr ctrl.Manager, certSigningEnabled bool, certMa
 	if certManagerEnabled {
 		builder.Owns(&certv1.Certificate{})
 	}
+	secretMapper := externalCertSecretMapper{
+		client: mgr.GetClient(),
+		log:    log,
+	}
+	builder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(secretMapper.mapToKafkaUsers))
+	profileMapper := connectionProfileClusterMapper{
+		client: mgr.GetClient(),
+		log:    log,
+	}
+	builder.Watches(
+		&source.Kind{Type: &v1beta1.KafkaCluster{}},
+		handler.EnqueueRequestsFromMapFunc(profileMapper.mapToKafkaUsers),
+		ctrlBuilder.WithPredicates(listenerStatusesChangedPredicate()))
 	return builder
 }
 
@@ -150,6 +169,8 @@ type KafkaUserReconciler struct {
 	// that reads objects from the cache and writes to the apiserver
 	Client client.Client
 	Scheme *runtime.Scheme
+	// FinalizerPolicy defines whether the finalizer is added to the KafkaUsers and when it is released without cleanup
+	FinalizerPolicy FinalizerPolicy
 }
 
 // +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=kafkausers,verbs=get;list;watch;create;update;patch;delete;deletecollection
@@ -158,6 +179,9 @@ type KafkaUserReconciler struct {
 // +kubebuilder:rbac:groups=cert-manager.io,resources=issuers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cert-manager.io,resources=clusterissuers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=certificatesigningrequests,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;delete
 
 // Reconcile reads that state of the cluster for a KafkaUser object and makes changes based on the state read
 // and what is in the KafkaUser.Spec
@@ -177,6 +201,14 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 		return requeueWithError(reqLogger, err.Error(), err)
 	}
 
+	if r.FinalizerPolicy.forceRelease(instance, time.Now()) && util.StringSliceContains(instance.GetFinalizers(), userFinalizer) {
+		reqLogger.Info("Releasing the finalizer of the KafkaUser without deleting its ACLs and certificates")
+		if err = r.removeFinalizer(ctx, instance); err != nil {
+			return requeueWithError(reqLogger, "failed to remove finalizer from kafkauser", err)
+		}
+		return reconciled()
+	}
+
 	// Get the referenced kafkacluster
 	clusterNamespace := getClusterRefNamespace(instance.Namespace, instance.Spec.ClusterRef)
 	var cluster *v1beta1.KafkaCluster
@@ -191,10 +223,39 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 		}
 		return requeueWithError(reqLogger, "failed to lookup referenced cluster", err)
 	}
+	// the ACLs are removed on deletion even when the namespace is no longer allowed to reference the cluster
+	if !k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
+		if err = k8sutil.CheckCrossNamespaceReference(ctx, r.Client, cluster, instance.GetNamespace()); err != nil {
+			return requeueWithError(reqLogger, "kafkauser is not allowed to reference the cluster", err)
+		}
+	}
 
 	var kafkaUser string
+	var userCertificate *pkicommon.UserCertificate
 
-	if instance.Spec.GetIfCertShouldBeCreated() {
+	if instance.Spec.ExternalCertificate != nil {
+		if kafkaUser, err = r.externalCertificatePrincipal(ctx, instance); err != nil {
+			switch {
+			case k8sutil.IsMarkedForDeletion(instance.ObjectMeta) && instance.Status.Principal != "":
+				// the ACLs can be removed even when the secret has been deleted before the KafkaUser
+				kafkaUser = instance.Status.Principal
+			case k8sutil.IsMarkedForDeletion(instance.ObjectMeta):
+				reqLogger.Info("Kafka user marked for deletion before creating ACLs")
+				if err = r.removeFinalizer(ctx, instance); err != nil {
+					return requeueWithError(reqLogger, "failed to remove finalizer from kafkauser", err)
+				}
+				return reconciled()
+			case apierrors.IsNotFound(errors.Cause(err)):
+				reqLogger.Info("secret of the externally managed certificate not found, may not be created yet")
+				return ctrl.Result{
+					Requeue:      true,
+					RequeueAfter: time.Duration(5) * time.Second,
+				}, nil
+			default:
+				return requeueWithError(reqLogger, "failed to get the principal of the externally managed certificate", err)
+			}
+		}
+	} else if instance.Spec.GetIfCertShouldBeCreated() {
 		// Avoid panic if the user wants to create a kafka user but the cluster is in plaintext mode
 		// TODO: refactor this and use webhook to validate if the cluster is eligible to create a kafka user
 		if cluster.Spec.ListenersConfig.SSLSecrets == nil && instance.Spec.PKIBackendSpec == nil {
@@ -245,6 +306,10 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 				Requeue: false,
 			}, err
 		}
+		userCertificate = user
+		if err := r.ensureUserTrustBundle(ctx, cluster, instance, user); err != nil {
+			return requeueWithError(reqLogger, "failed to add trust bundle to user secret", err)
+		}
 		// check if marked for deletion and remove created certs
 		if k8sutil.IsMarkedForDeletion(instance.ObjectMeta) {
 			reqLogger.Info("Kafka user is marked for deletion, revoking certificates")
@@ -266,6 +331,15 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 		return requeueWithError(reqLogger, "failed to ensure kafkacluster label on user", err)
 	}
 
+	// the ACLs of the former principal are removed when the certificate of the user has been replaced by one with
+	// a different Distinguished Name
+	if previous := instance.Status.Principal; previous != "" && previous != kafkaUser {
+		reqLogger.Info("Principal of the user changed, removing the ACLs of the former principal", "principal", previous)
+		if err = r.finalizeKafkaUserACLs(reqLogger, cluster, previous); err != nil {
+			return requeueWithError(reqLogger, "failed to remove the ACLs of the former principal of kafkauser", err)
+		}
+	}
+
 	// If topic grants supplied, grab a broker connection and set ACLs
 	if len(instance.Spec.TopicGrants) > 0 {
 		broker, close, err := newKafkaFromCluster(r.Client, cluster)
@@ -284,8 +358,35 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 		}
 	}
 
+	var tokenCheckInterval time.Duration
+	if instance.Spec.DelegationToken != nil {
+		if tokenCheckInterval, err = r.reconcileDelegationToken(ctx, reqLogger, cluster, instance, "User:"+kafkaUser); err != nil {
+			return requeueWithError(reqLogger, "failed to reconcile delegation token of kafkauser", err)
+		}
+	} else if instance.Status.DelegationToken != nil {
+		expired, err := r.expireDelegationToken(ctx, reqLogger, cluster, instance)
+		if err != nil {
+			return requeueWithError(reqLogger, "failed to expire delegation token of kafkauser", err)
+		}
+		if expired {
+			instance.Status.DelegationToken = nil
+		} else {
+			tokenCheckInterval = delegationTokenRetryInSeconds * time.Second
+		}
+	}
+	tokenStatus := instance.Status.DelegationToken
+
+	// the connection profile is the binding Secret of the user
+	var binding *corev1.LocalObjectReference
+	if instance.Spec.ConnectionProfile != nil {
+		if err = r.reconcileConnectionProfile(ctx, cluster, instance, userCertificate); err != nil {
+			return requeueWithError(reqLogger, "failed to reconcile connection profile of kafkauser", err)
+		}
+		binding = &corev1.LocalObjectReference{Name: instance.Spec.ConnectionProfile.SecretName}
+	}
+
 	// ensure a finalizer for cleanup on deletion
-	if !util.StringSliceContains(instance.GetFinalizers(), userFinalizer) {
+	if !r.FinalizerPolicy.skipFinalizer(instance) && !util.StringSliceContains(instance.GetFinalizers(), userFinalizer) {
 		r.addFinalizer(reqLogger, instance)
 		if instance, err = r.updateAndFetchLatest(ctx, instance); err != nil {
 			return requeueWithError(reqLogger, "failed to update kafkauser with finalizer", err)
@@ -294,7 +395,11 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 
 	// set user status
 	instance.Status = v1alpha1.KafkaUserStatus{
-		State: v1alpha1.UserStateCreated,
+		State:              v1alpha1.UserStateCreated,
+		DelegationToken:    tokenStatus,
+		ObservedGeneration: instance.GetGeneration(),
+		Principal:          kafkaUser,
+		Binding:            binding,
 	}
 	if len(instance.Spec.TopicGrants) > 0 {
 		instance.Status.ACLs = kafkautil.GrantsToACLStrings(kafkaUser, instance.Spec.TopicGrants)
@@ -303,9 +408,54 @@ func (r *KafkaUserReconciler) Reconcile(ctx context.Context, request reconcile.R
 		return requeueWithError(reqLogger, "failed to update kafkauser status", err)
 	}
 
+	if tokenCheckInterval > 0 {
+		return ctrl.Result{RequeueAfter: tokenCheckInterval}, nil
+	}
 	return reconciled()
 }
 
+// ensureUserTrustBundle stores the CA certificate of the user together with the additional trusted CA certificates
+// of the cluster in the user secret
+func (r *KafkaUserReconciler) ensureUserTrustBundle(ctx context.Context, cluster *v1beta1.KafkaCluster, instance *v1alpha1.KafkaUser, user *pkicommon.UserCertificate) error {
+	if len(cluster.Spec.ListenersConfig.AdditionalTrustedCAs) == 0 {
+		return nil
+	}
+	bundle, err := r.userTrustBundle(ctx, cluster, user)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Spec.SecretName, Namespace: instance.Namespace}, secret); err != nil {
+		return errors.WrapIfWithDetails(err, "could not get user secret", "secret", instance.Spec.SecretName)
+	}
+	if bytes.Equal(secret.Data[v1alpha1.TrustBundleKey], bundle) {
+		return nil
+	}
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[v1alpha1.TrustBundleKey] = bundle
+	return r.Client.Update(ctx, secret)
+}
+
+// userTrustBundle returns the CA certificate of the user together with the additional trusted CA certificates
+// of the cluster
+func (r *KafkaUserReconciler) userTrustBundle(ctx context.Context, cluster *v1beta1.KafkaCluster, user *pkicommon.UserCertificate) ([]byte, error) {
+	if len(cluster.Spec.ListenersConfig.AdditionalTrustedCAs) == 0 {
+		return user.CA, nil
+	}
+	additionalCAs, err := pkicommon.GetAdditionalTrustedCAs(ctx, r.Client, cluster)
+	if err != nil {
+		return nil, err
+	}
+	caCerts, err := certutil.ParseCertificates(user.CA)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not parse CA certificate of user")
+	}
+	return pkicommon.EncodeCertificates(pkicommon.AppendCertificates(certutil.GetCertBundle(caCerts), additionalCAs...)), nil
+}
+
 func (r *KafkaUserReconciler) ensureClusterLabel(ctx context.Context, cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser) (*v1alpha1.KafkaUser, error) {
 	labels := applyClusterRefLabel(cluster, user.GetLabels())
 	if !reflect.DeepEqual(labels, user.GetLabels()) {
@@ -335,6 +485,13 @@ func (r *KafkaUserReconciler) checkFinalizers(ctx context.Context, cluster *v1be
 				return requeueWithError(reqLogger, "failed to finalize kafkauser", err)
 			}
 		}
+		expired, err := r.expireDelegationToken(ctx, reqLogger, cluster, instance)
+		if err != nil {
+			return requeueWithError(reqLogger, "failed to expire delegation token of kafkauser", err)
+		}
+		if !expired {
+			return requeueAfter(delegationTokenRetryInSeconds)
+		}
 		// remove finalizer
 		if err = r.removeFinalizer(ctx, instance); err != nil {
 			return requeueWithError(reqLogger, "failed to remove finalizer from kafkauser", err)
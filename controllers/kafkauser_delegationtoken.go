@@ -0,0 +1,500 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	clientutil "github.com/banzaicloud/koperator/pkg/util/client"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+)
+
+const (
+	delegationTokenJobTemplate         = "%s-%s-delegation-token"
+	delegationTokenOperationAnnotation = "kafka.banzaicloud.io/delegation-token-operation"
+	delegationTokenClientKeystore      = "/var/run/secrets/java.io/keystores/client"
+	delegationTokenServiceAccountPath  = "/var/run/secrets/kubernetes.io/serviceaccount"
+	delegationTokenRetryInSeconds      = 5
+	delegationTokenJAASConfigFormat    = `org.apache.kafka.common.security.scram.ScramLoginModule required username="%s" password="%s" tokenauth="true";`
+
+	delegationTokenOperationCreate = "create"
+	delegationTokenOperationRenew  = "renew"
+	delegationTokenOperationExpire = "expire"
+
+	// A new token is written by the Job into the Secret of the Job through the Kubernetes API, the service account of
+	// the Job may patch only that Secret. The HMAC of the token to renew or expire is passed in the same Secret.
+	delegationTokenScript = `set -eo pipefail
+cat > /tmp/client.properties <<EOF
+security.protocol=SSL
+ssl.keystore.location=` + delegationTokenClientKeystore + `/` + v1alpha1.TLSJKSKeyStore + `
+ssl.keystore.password=${CLIENT_PASSWORD}
+ssl.truststore.location=` + delegationTokenClientKeystore + `/` + v1alpha1.TLSJKSTrustStore + `
+ssl.truststore.password=${CLIENT_PASSWORD}
+EOF
+tokens() {
+  /opt/kafka/bin/kafka-delegation-tokens.sh --bootstrap-server "$KAFKA_BOOTSTRAP_SERVERS" --command-config /tmp/client.properties "$@"
+}
+case "$OPERATION" in
+` + delegationTokenOperationCreate + `)
+  TOKEN=$(tokens --create --max-life-time-period "$MAX_LIFETIME_MS" --owner-principal "$OWNER_PRINCIPAL" | tail -n 1)
+  PATCH=$(echo "$TOKEN" | awk '{printf "{\"stringData\":{\"` + v1alpha1.DelegationTokenIDKey + `\":\"%s\",\"` + v1alpha1.DelegationTokenHMACKey + `\":\"%s\"}}", $1, $2}')
+  curl -sSf -o /dev/null --cacert ` + delegationTokenServiceAccountPath + `/ca.crt \
+    -H "Authorization: Bearer $(cat ` + delegationTokenServiceAccountPath + `/token)" \
+    -H "Content-Type: application/merge-patch+json" -X PATCH -d "$PATCH" \
+    "https://kubernetes.default.svc/api/v1/namespaces/${POD_NAMESPACE}/secrets/${TOKEN_SECRET}"
+  ;;
+` + delegationTokenOperationRenew + `)
+  tokens --renew --hmac "$HMAC" --renew-time-period "$EXPIRY_TIME_MS"
+  ;;
+` + delegationTokenOperationExpire + `)
+  tokens --expire --hmac "$HMAC" --expiry-time-period -1
+  ;;
+esac
+`
+)
+
+// reconcileDelegationToken issues a delegation token for the KafkaUser when it has none yet, renews it before it
+// expires and replaces it with a new one once it cannot be renewed any longer, the replaced token is expired. It
+// returns when the token needs to be checked again.
+func (r *KafkaUserReconciler) reconcileDelegationToken(ctx context.Context, log logr.Logger, cluster *v1beta1.KafkaCluster,
+	user *v1alpha1.KafkaUser, principal string) (time.Duration, error) {
+	if cluster.Spec.DelegationTokenConfig == nil {
+		return 0, errors.NewWithDetails("delegation tokens are not enabled for the cluster", "cluster", cluster.GetName())
+	}
+	if !isClientSSLUsedForInnerBrokerCommunication(cluster) {
+		return 0, errors.New("delegation tokens can only be issued when the brokers communicate over SSL with client authentication")
+	}
+	retry := time.Duration(delegationTokenRetryInSeconds) * time.Second
+
+	now := time.Now()
+	operation, finished, err := r.finishDelegationTokenJob(ctx, log, cluster, user)
+	if err != nil || !finished {
+		return retry, err
+	}
+	switch operation {
+	case delegationTokenOperationCreate:
+		return r.storeIssuedDelegationToken(ctx, log, cluster, user, now)
+	case delegationTokenOperationRenew:
+		if status := user.Status.DelegationToken; status != nil {
+			status.ExpiryTime = metav1.NewTime(delegationTokenExpiryTime(cluster, user, now))
+			log.Info("delegation token renewed", "tokenId", status.TokenID, "expiryTime", status.ExpiryTime)
+		}
+	}
+
+	hmac, err := r.delegationTokenHMAC(ctx, user)
+	if err != nil {
+		return 0, err
+	}
+	renewAt, ok := delegationTokenRenewalTime(user)
+	switch {
+	case !ok || hmac == "" || !now.Before(user.Status.DelegationToken.ExpiryTime.Time):
+		return retry, r.startDelegationTokenJob(ctx, log, cluster, user, principal, delegationTokenOperationCreate, "")
+	case now.Before(renewAt):
+		return renewAt.Sub(now), nil
+	case user.Status.DelegationToken.ExpiryTime.Time.Before(delegationTokenMaxLifetimeEnd(cluster, user)):
+		return retry, r.startDelegationTokenJob(ctx, log, cluster, user, principal, delegationTokenOperationRenew, hmac)
+	default:
+		// the token has reached its maximal lifetime, it is replaced
+		return retry, r.startDelegationTokenJob(ctx, log, cluster, user, principal, delegationTokenOperationCreate, "")
+	}
+}
+
+// expireDelegationToken expires the delegation token of the KafkaUser which is not needed any longer, it returns
+// true once the token is gone and the status of the token can be removed
+func (r *KafkaUserReconciler) expireDelegationToken(ctx context.Context, log logr.Logger, cluster *v1beta1.KafkaCluster,
+	user *v1alpha1.KafkaUser) (bool, error) {
+	if user.Status.DelegationToken == nil || cluster.Spec.DelegationTokenConfig == nil {
+		return true, nil
+	}
+	hmac, err := r.delegationTokenHMAC(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	// the Job may expire the token replaced by the current one
+	jobSecret := &corev1.Secret{}
+	name := delegationTokenJobName(user)
+	err = r.Client.Get(ctx, types.NamespacedName{Namespace: cluster.GetNamespace(), Name: name}, jobSecret)
+	if client.IgnoreNotFound(err) != nil {
+		return false, errors.WrapIfWithDetails(err, "could not get the secret of the delegation token job", "secret", name)
+	}
+	operation, finished, err := r.finishDelegationTokenJob(ctx, log, cluster, user)
+	if err != nil || !finished {
+		return false, err
+	}
+	if operation == delegationTokenOperationExpire && string(jobSecret.Data[v1alpha1.DelegationTokenHMACKey]) == hmac {
+		return true, nil
+	}
+	// a token issued meanwhile is not stored, it expires on its own
+	if err := r.deleteDelegationTokenJob(ctx, cluster, user); err != nil {
+		return false, err
+	}
+	if hmac == "" {
+		return true, nil
+	}
+	return false, r.startDelegationTokenJob(ctx, log, cluster, user, "", delegationTokenOperationExpire, hmac)
+}
+
+// storeIssuedDelegationToken writes the token issued by the Job into the Secret given in the KafkaUser and expires the
+// token it replaces
+func (r *KafkaUserReconciler) storeIssuedDelegationToken(ctx context.Context, log logr.Logger, cluster *v1beta1.KafkaCluster,
+	user *v1alpha1.KafkaUser, now time.Time) (time.Duration, error) {
+	name := delegationTokenJobName(user)
+	jobSecret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: cluster.GetNamespace(), Name: name}, jobSecret); err != nil {
+		return 0, errors.WrapIfWithDetails(err, "could not get the secret of the delegation token job", "secret", name)
+	}
+	tokenID, hmac := string(jobSecret.Data[v1alpha1.DelegationTokenIDKey]), string(jobSecret.Data[v1alpha1.DelegationTokenHMACKey])
+	if tokenID == "" || hmac == "" {
+		if err := r.deleteDelegationTokenJob(ctx, cluster, user); err != nil {
+			return 0, err
+		}
+		return time.Duration(delegationTokenRetryInSeconds) * time.Second,
+			errors.NewWithDetails("delegation token job completed without storing the token, it is retried", "job", name)
+	}
+
+	previousHMAC, err := r.delegationTokenHMAC(ctx, user)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.storeDelegationToken(ctx, user, tokenID, hmac); err != nil {
+		return 0, err
+	}
+	log.Info("delegation token issued", "tokenId", tokenID)
+	if err := r.deleteDelegationTokenJob(ctx, cluster, user); err != nil {
+		return 0, err
+	}
+
+	user.Status.DelegationToken = &v1alpha1.UserDelegationTokenStatus{
+		TokenID:    tokenID,
+		SecretName: user.Spec.DelegationToken.SecretName,
+		IssueTime:  metav1.NewTime(now),
+	}
+	user.Status.DelegationToken.ExpiryTime = metav1.NewTime(delegationTokenExpiryTime(cluster, user, now))
+
+	if previousHMAC != "" && previousHMAC != hmac {
+		return time.Duration(delegationTokenRetryInSeconds) * time.Second,
+			r.startDelegationTokenJob(ctx, log, cluster, user, "", delegationTokenOperationExpire, previousHMAC)
+	}
+	renewAt, _ := delegationTokenRenewalTime(user)
+	return renewAt.Sub(now), nil
+}
+
+// delegationTokenRenewalTime returns when the delegation token of the KafkaUser has to be renewed
+func delegationTokenRenewalTime(user *v1alpha1.KafkaUser) (time.Time, bool) {
+	status := user.Status.DelegationToken
+	if status == nil {
+		return time.Time{}, false
+	}
+	return status.ExpiryTime.Add(-user.Spec.DelegationToken.GetRenewBefore()), true
+}
+
+// delegationTokenExpiryTime returns when the delegation token of the KafkaUser expires when it is issued or renewed at
+// the given time
+func delegationTokenExpiryTime(cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser, now time.Time) time.Time {
+	expiryTime := now.Add(time.Duration(cluster.Spec.DelegationTokenConfig.GetExpiryTimeSeconds()) * time.Second)
+	if maxLifetimeEnd := delegationTokenMaxLifetimeEnd(cluster, user); maxLifetimeEnd.Before(expiryTime) {
+		return maxLifetimeEnd
+	}
+	return expiryTime
+}
+
+// delegationTokenMaxLifetimeEnd returns the time the delegation token of the KafkaUser cannot be renewed beyond
+func delegationTokenMaxLifetimeEnd(cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser) time.Time {
+	maxLifetime := time.Duration(delegationTokenMaxLifetimeSeconds(cluster, user)) * time.Second
+	return user.Status.DelegationToken.IssueTime.Add(maxLifetime)
+}
+
+func delegationTokenMaxLifetimeSeconds(cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser) int64 {
+	maxLifetime := cluster.Spec.DelegationTokenConfig.GetMaxLifetimeSeconds()
+	if user.Spec.DelegationToken == nil {
+		return maxLifetime
+	}
+	if userMaxLifetime := user.Spec.DelegationToken.MaxLifetimeSeconds; userMaxLifetime != nil && *userMaxLifetime < maxLifetime {
+		maxLifetime = *userMaxLifetime
+	}
+	return maxLifetime
+}
+
+func delegationTokenJobName(user *v1alpha1.KafkaUser) string {
+	return fmt.Sprintf(delegationTokenJobTemplate, user.GetNamespace(), user.GetName())
+}
+
+// isClientSSLUsedForInnerBrokerCommunication returns true when the operator authenticates to the brokers with its
+// client certificate, the delegation tokens are requested with that identity
+func isClientSSLUsedForInnerBrokerCommunication(cluster *v1beta1.KafkaCluster) bool {
+	if !cluster.Spec.IsClientSSLSecretPresent() {
+		return false
+	}
+	for _, listener := range cluster.Spec.ListenersConfig.InternalListeners {
+		if listener.UsedForInnerBrokerCommunication {
+			return listener.Type == v1beta1.SecurityProtocolSSL
+		}
+	}
+	return false
+}
+
+// delegationTokenHMAC returns the HMAC of the delegation token issued last for the KafkaUser, it is empty when there
+// is none
+func (r *KafkaUserReconciler) delegationTokenHMAC(ctx context.Context, user *v1alpha1.KafkaUser) (string, error) {
+	status := user.Status.DelegationToken
+	if status == nil {
+		return "", nil
+	}
+	secretName := status.SecretName
+	if secretName == "" && user.Spec.DelegationToken != nil {
+		secretName = user.Spec.DelegationToken.SecretName
+	}
+	if secretName == "" {
+		return "", nil
+	}
+	secret := &corev1.Secret{}
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: user.GetNamespace(), Name: secretName}, secret)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.WrapIfWithDetails(err, "could not get delegation token secret", "secret", secretName)
+	}
+	return string(secret.Data[v1alpha1.DelegationTokenHMACKey]), nil
+}
+
+// finishDelegationTokenJob checks the Job of the KafkaUser and returns its operation and whether it has finished. The
+// Job is removed once it has finished except for a succeeded create operation, the issued token is read from the
+// Secret of the Job first. A failed Job is removed as well so that it is retried, its error is returned unless it has
+// tried to expire a token which expires on its own eventually.
+func (r *KafkaUserReconciler) finishDelegationTokenJob(ctx context.Context, log logr.Logger, cluster *v1beta1.KafkaCluster,
+	user *v1alpha1.KafkaUser) (string, bool, error) {
+	job := &batchv1.Job{}
+	name := delegationTokenJobName(user)
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: cluster.GetNamespace(), Name: name}, job)
+	if apierrors.IsNotFound(err) {
+		return "", true, nil
+	}
+	if err != nil {
+		return "", false, errors.WrapIfWithDetails(err, "could not get delegation token job", "job", name)
+	}
+
+	operation := job.GetAnnotations()[delegationTokenOperationAnnotation]
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			if operation == delegationTokenOperationCreate {
+				return operation, true, nil
+			}
+			return operation, true, r.deleteDelegationTokenJob(ctx, cluster, user)
+		case batchv1.JobFailed:
+			if err := r.deleteDelegationTokenJob(ctx, cluster, user); err != nil {
+				return operation, false, err
+			}
+			if operation == delegationTokenOperationExpire {
+				log.Info("could not expire the delegation token, it expires at its expiry time",
+					"job", name, "reason", condition.Reason, "message", condition.Message)
+				return operation, true, nil
+			}
+			return operation, false, errors.NewWithDetails("delegation token job failed, it is retried",
+				"job", name, "operation", operation, "reason", condition.Reason, "message", condition.Message)
+		}
+	}
+	return operation, false, nil
+}
+
+// startDelegationTokenJob creates the Job running the operation on the delegation token of the KafkaUser along with
+// the Secret and the service account of the Job
+func (r *KafkaUserReconciler) startDelegationTokenJob(ctx context.Context, log logr.Logger, cluster *v1beta1.KafkaCluster,
+	user *v1alpha1.KafkaUser, principal, operation, hmac string) error {
+	name := delegationTokenJobName(user)
+	jobSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.GetNamespace(), Name: name}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, jobSecret, func() error {
+		jobSecret.Labels = map[string]string{v1beta1.KafkaCRLabelKey: cluster.GetName()}
+		jobSecret.Data = map[string][]byte{}
+		if hmac != "" {
+			jobSecret.Data[v1alpha1.DelegationTokenHMACKey] = []byte(hmac)
+		}
+		return controllerutil.SetControllerReference(cluster, jobSecret, r.Scheme)
+	})
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not create the secret of the delegation token job", "secret", name)
+	}
+
+	for _, obj := range delegationTokenJobServiceAccount(cluster, user) {
+		if err := r.Client.Create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+			return errors.WrapIfWithDetails(err, "could not create the service account of the delegation token job",
+				"kind", fmt.Sprintf("%T", obj), "name", name)
+		}
+	}
+
+	log.Info("creating delegation token job", "job", name, "operation", operation)
+	if err := r.Client.Create(ctx, delegationTokenJob(cluster, user, principal, operation)); err != nil {
+		return errors.WrapIfWithDetails(err, "could not create delegation token job", "job", name)
+	}
+	return nil
+}
+
+// deleteDelegationTokenJob removes the Job of the KafkaUser with its Secret and service account
+func (r *KafkaUserReconciler) deleteDelegationTokenJob(ctx context.Context, cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser) error {
+	name := delegationTokenJobName(user)
+	objects := append([]client.Object{
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.GetNamespace(), Name: name}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.GetNamespace(), Name: name}},
+	}, delegationTokenJobServiceAccount(cluster, user)...)
+	for _, obj := range objects {
+		if err := r.Client.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return errors.WrapIfWithDetails(err, "could not delete delegation token job",
+				"kind", fmt.Sprintf("%T", obj), "name", name)
+		}
+	}
+	return nil
+}
+
+// storeDelegationToken writes the delegation token into the Secret given in the KafkaUser
+func (r *KafkaUserReconciler) storeDelegationToken(ctx context.Context, user *v1alpha1.KafkaUser, tokenID, hmac string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: user.Spec.DelegationToken.SecretName, Namespace: user.GetNamespace()},
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Data = map[string][]byte{
+			v1alpha1.DelegationTokenIDKey:         []byte(tokenID),
+			v1alpha1.DelegationTokenHMACKey:       []byte(hmac),
+			v1alpha1.DelegationTokenJAASConfigKey: []byte(fmt.Sprintf(delegationTokenJAASConfigFormat, tokenID, hmac)),
+		}
+		return controllerutil.SetControllerReference(user, secret, r.Scheme)
+	})
+	return errors.WrapIfWithDetails(err, "could not store delegation token", "secret", secret.GetName())
+}
+
+// delegationTokenJobServiceAccount returns the service account of the Job of the KafkaUser and its permission to
+// patch the Secret of the Job
+func delegationTokenJobServiceAccount(cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser) []client.Object {
+	name := delegationTokenJobName(user)
+	labels := map[string]string{v1beta1.KafkaCRLabelKey: cluster.GetName()}
+	return []client.Object{
+		&corev1.ServiceAccount{ObjectMeta: templates.ObjectMeta(name, labels, cluster)},
+		&rbacv1.Role{
+			ObjectMeta: templates.ObjectMeta(name, labels, cluster),
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups:     []string{""},
+					Resources:     []string{"secrets"},
+					ResourceNames: []string{name},
+					Verbs:         []string{"patch"},
+				},
+			},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: templates.ObjectMeta(name, labels, cluster),
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: cluster.GetNamespace()}},
+		},
+	}
+}
+
+func delegationTokenJob(cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser, principal, operation string) *batchv1.Job {
+	clientSecretName := cluster.Spec.GetClientSSLCertSecretName()
+	if clientSecretName == "" {
+		clientSecretName = fmt.Sprintf(pkicommon.BrokerControllerTemplate, cluster.GetName())
+	}
+	name := delegationTokenJobName(user)
+	env := []corev1.EnvVar{
+		{Name: "OPERATION", Value: operation},
+		{Name: "KAFKA_BOOTSTRAP_SERVERS", Value: clientutil.GenerateKafkaAddress(cluster)},
+		{
+			Name: "CLIENT_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: clientSecretName},
+					Key:                  v1alpha1.PasswordKey,
+				},
+			},
+		},
+	}
+	if operation == delegationTokenOperationCreate {
+		env = append(env,
+			corev1.EnvVar{Name: "OWNER_PRINCIPAL", Value: principal},
+			corev1.EnvVar{Name: "MAX_LIFETIME_MS", Value: strconv.FormatInt(delegationTokenMaxLifetimeSeconds(cluster, user)*1000, 10)},
+			corev1.EnvVar{Name: "TOKEN_SECRET", Value: name},
+			corev1.EnvVar{
+				Name:      "POD_NAMESPACE",
+				ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"}},
+			})
+	} else {
+		env = append(env,
+			corev1.EnvVar{Name: "EXPIRY_TIME_MS", Value: strconv.FormatInt(cluster.Spec.DelegationTokenConfig.GetExpiryTimeSeconds()*1000, 10)},
+			corev1.EnvVar{
+				Name: "HMAC",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: name},
+						Key:                  v1alpha1.DelegationTokenHMACKey,
+					},
+				},
+			})
+	}
+
+	objectMeta := templates.ObjectMeta(name, map[string]string{v1beta1.KafkaCRLabelKey: cluster.GetName()}, cluster)
+	objectMeta.Annotations = map[string]string{delegationTokenOperationAnnotation: operation}
+	backoffLimit := int32(3)
+	return &batchv1.Job{
+		ObjectMeta: objectMeta,
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: name,
+					ImagePullSecrets:   cluster.Spec.GetImagePullSecrets(nil),
+					Containers: []corev1.Container{
+						{
+							Name:    "delegation-token",
+							Image:   cluster.Spec.GetImage(cluster.Spec.GetClusterImage()),
+							Command: []string{"bash", "-c", delegationTokenScript},
+							Env:     env,
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "client-keystore", MountPath: delegationTokenClientKeystore, ReadOnly: true},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "client-keystore",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: clientSecretName},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestReconcileDelegationToken(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, batchv1.AddToScheme(scheme))
+	assert.NoError(t, rbacv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := KafkaUserReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL, ContainerPort: 29092},
+						UsedForInnerBrokerCommunication: true,
+					},
+				},
+				SSLSecrets: &v1beta1.SSLSecrets{TLSSecretName: "kafka-tls"},
+			},
+		},
+	}
+	user := &v1alpha1.KafkaUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "apps", UID: "user-uid"},
+		Spec: v1alpha1.KafkaUserSpec{
+			DelegationToken: &v1alpha1.UserDelegationToken{SecretName: "app-token"},
+		},
+	}
+	jobName := types.NamespacedName{Namespace: "kafka", Name: "apps-app-delegation-token"}
+	completeJob := func(conditionType batchv1.JobConditionType) {
+		job := &batchv1.Job{}
+		assert.NoError(t, c.Get(ctx, jobName, job))
+		job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}}
+		assert.NoError(t, c.Status().Update(ctx, job))
+	}
+	// the Job writes the issued token into its own Secret
+	issueToken := func(tokenID, hmac string) {
+		jobSecret := &corev1.Secret{}
+		assert.NoError(t, c.Get(ctx, jobName, jobSecret))
+		jobSecret.Data = map[string][]byte{v1alpha1.DelegationTokenIDKey: []byte(tokenID), v1alpha1.DelegationTokenHMACKey: []byte(hmac)}
+		assert.NoError(t, c.Update(ctx, jobSecret))
+		completeJob(batchv1.JobComplete)
+	}
+	jobOperation := func() string {
+		job := &batchv1.Job{}
+		if err := c.Get(ctx, jobName, job); err != nil {
+			return ""
+		}
+		return job.GetAnnotations()[delegationTokenOperationAnnotation]
+	}
+
+	_, err := r.reconcileDelegationToken(ctx, logr.Discard(), cluster, user, "User:CN=app")
+	assert.Error(t, err, "delegation tokens are not enabled for the cluster")
+
+	cluster.Spec.DelegationTokenConfig = &v1beta1.DelegationTokenConfig{
+		SecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "token-key"}, Key: "key"},
+	}
+	interval, err := r.reconcileDelegationToken(ctx, logr.Discard(), cluster, user, "User:CN=app")
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, interval)
+
+	job := &batchv1.Job{}
+	assert.NoError(t, c.Get(ctx, jobName, job))
+	assert.Equal(t, delegationTokenOperationCreate, jobOperation())
+	assert.Equal(t, jobName.Name, job.Spec.Template.Spec.ServiceAccountName)
+	assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "OWNER_PRINCIPAL", Value: "User:CN=app"})
+	assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "MAX_LIFETIME_MS", Value: "604800000"})
+	role := &rbacv1.Role{}
+	assert.NoError(t, c.Get(ctx, jobName, role))
+	assert.Equal(t, []string{jobName.Name}, role.Rules[0].ResourceNames)
+
+	// The Job is waited for until it finishes
+	interval, err = r.reconcileDelegationToken(ctx, logr.Discard(), cluster, user, "User:CN=app")
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, interval)
+
+	issueToken("token-id", "token-hmac")
+	interval, err = r.reconcileDelegationToken(ctx, logr.Discard(), cluster, user, "User:CN=app")
+	assert.NoError(t, err)
+	assert.InDelta(t, (23 * time.Hour).Seconds(), interval.Seconds(), 5)
+	assert.Equal(t, "token-id", user.Status.DelegationToken.TokenID)
+	assert.Equal(t, "app-token", user.Status.DelegationToken.SecretName)
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "apps", Name: "app-token"}, secret))
+	assert.Equal(t, "token-hmac", string(secret.Data[v1alpha1.DelegationTokenHMACKey]))
+	assert.Equal(t, `org.apache.kafka.common.security.scram.ScramLoginModule required username="token-id" password="token-hmac" tokenauth="true";`,
+		string(secret.Data[v1alpha1.DelegationTokenJAASConfigKey]))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, jobName, &batchv1.Job{})))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, jobName, &corev1.Secret{})))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, jobName, &corev1.ServiceAccount{})))
+
+	// Nothing is done until the token is about to expire
+	interval, err = r.reconcileDelegationToken(ctx, logr.Discard(), cluster, user, "User:CN=app")
+	assert.NoError(t, err)
+	assert.InDelta(t, (23 * time.Hour).Seconds(), interval.Seconds(), 5)
+	assert.Equal(t, "", jobOperation())
+
+	// The token is renewed with its HMAC passed in the Secret of the Job
+	user.Status.DelegationToken.ExpiryTime = metav1.NewTime(time.Now().Add(30 * time.Minute))
+	_, err = r.reconcileDelegationToken(ctx, logr.Discard(), cluster, user, "User:CN=app")
+	assert.NoError(t, err)
+	assert.Equal(t, delegationTokenOperationRenew, jobOperation())
+	jobSecret := &corev1.Secret{}
+	assert.NoError(t, c.Get(ctx, jobName, jobSecret))
+	assert.Equal(t, "token-hmac", string(jobSecret.Data[v1alpha1.DelegationTokenHMACKey]))
+
+	// A failed Job is removed and retried
+	completeJob(batchv1.JobFailed)
+	_, err = r.reconcileDelegationToken(ctx, logr.Discard(), cluster, user, "User:CN=app")
+	assert.Error(t, err)
+	assert.Equal(t, "", jobOperation())
+	_, err = r.reconcileDelegationToken(ctx, logr.Discard(), cluster, user, "User:CN=app")
+	assert.NoError(t, err)
+	assert.Equal(t, delegationTokenOperationRenew, jobOperation())
+
+	completeJob(batchv1.JobComplete)
+	interval, err = r.reconcileDelegationToken(ctx, logr.Discard(), cluster, user, "User:CN=app")
+	assert.NoError(t, err)
+	assert.InDelta(t, (23 * time.Hour).Seconds(), interval.Seconds(), 5)
+	assert.Equal(t, "token-id", user.Status.DelegationToken.TokenID)
+	assert.Equal(t, "", jobOperation())
+
+	// A token which cannot be renewed any longer is replaced and the replaced one is expired
+	user.Status.DelegationToken.IssueTime = metav1.NewTime(time.Now().Add(-7*24*time.Hour + 30*time.Minute))
+	user.Status.DelegationToken.ExpiryTime = metav1.NewTime(time.Now().Add(30 * time.Minute))
+	_, err = r.reconcileDelegationToken(ctx, logr.Discard(), cluster, user, "User:CN=app")
+	assert.NoError(t, err)
+	assert.Equal(t, delegationTokenOperationCreate, jobOperation())
+
+	issueToken("new-token-id", "new-token-hmac")
+	_, err = r.reconcileDelegationToken(ctx, logr.Discard(), cluster, user, "User:CN=app")
+	assert.NoError(t, err)
+	assert.Equal(t, "new-token-id", user.Status.DelegationToken.TokenID)
+	assert.Equal(t, delegationTokenOperationExpire, jobOperation())
+	assert.NoError(t, c.Get(ctx, jobName, jobSecret))
+	assert.Equal(t, "token-hmac", string(jobSecret.Data[v1alpha1.DelegationTokenHMACKey]))
+
+	// The token is expired once it is not needed any longer
+	expired, err := r.expireDelegationToken(ctx, logr.Discard(), cluster, user)
+	assert.NoError(t, err)
+	assert.False(t, expired)
+	completeJob(batchv1.JobComplete)
+	expired, err = r.expireDelegationToken(ctx, logr.Discard(), cluster, user)
+	assert.NoError(t, err)
+	assert.False(t, expired)
+	assert.Equal(t, delegationTokenOperationExpire, jobOperation())
+	assert.NoError(t, c.Get(ctx, jobName, jobSecret))
+	assert.Equal(t, "new-token-hmac", string(jobSecret.Data[v1alpha1.DelegationTokenHMACKey]))
+	completeJob(batchv1.JobComplete)
+	expired, err = r.expireDelegationToken(ctx, logr.Discard(), cluster, user)
+	assert.NoError(t, err)
+	assert.True(t, expired)
+	assert.Equal(t, "", jobOperation())
+}
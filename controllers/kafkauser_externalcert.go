@@ -0,0 +1,74 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/pkg/util"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+)
+
+// externalCertificatePrincipal returns the Distinguished Name of the externally managed client certificate
+// of the KafkaUser
+func (r *KafkaUserReconciler) externalCertificatePrincipal(ctx context.Context, user *v1alpha1.KafkaUser) (string, error) {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: user.GetNamespace(), Name: user.Spec.SecretName}, secret); err != nil {
+		return "", errors.WrapIfWithDetails(err, "could not get the secret of the externally managed certificate", "secret", user.Spec.SecretName)
+	}
+	certificateKey := user.Spec.ExternalCertificate.GetCertificateKey()
+	certificate, ok := secret.Data[certificateKey]
+	if !ok {
+		return "", errors.NewWithDetails("the secret of the externally managed certificate has no certificate",
+			"secret", user.Spec.SecretName, "key", certificateKey)
+	}
+	cert, err := certutil.DecodeCertificate(certificate)
+	if err != nil {
+		return "", errors.WrapIfWithDetails(err, "could not decode the externally managed certificate", "secret", user.Spec.SecretName)
+	}
+	return cert.Subject.String(), nil
+}
+
+type externalCertSecretMapper struct {
+	client client.Reader
+	log    logr.Logger
+}
+
+// mapToKafkaUsers maps Secret events to the KafkaUsers using the Secret as externally managed certificate,
+// so that the ACLs follow the Distinguished Name of the certificate when it is replaced
+func (m *externalCertSecretMapper) mapToKafkaUsers(obj client.Object) []ctrl.Request {
+	var users v1alpha1.KafkaUserList
+	if err := m.client.List(context.Background(), &users, client.InNamespace(obj.GetNamespace())); err != nil {
+		m.log.Error(err, "couldn't list KafkaUsers", "namespace", obj.GetNamespace())
+		return nil
+	}
+	var requests []ctrl.Request
+	for i := range users.Items {
+		user := &users.Items[i]
+		if user.Spec.ExternalCertificate == nil || user.Spec.SecretName != obj.GetName() || util.ObjectManagedByClusterRegistry(user) {
+			continue
+		}
+		requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: user.GetNamespace(), Name: user.GetName()}})
+	}
+	return requests
+}
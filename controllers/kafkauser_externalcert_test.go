@@ -0,0 +1,68 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+)
+
+func TestExternalCertificatePrincipal(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	cert, _, expectedDN, err := certutil.GenerateTestCert()
+	assert.NoError(t, err)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-cert", Namespace: "apps"},
+		Data:       map[string][]byte{"client.pem": cert},
+	}
+	user := &v1alpha1.KafkaUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "apps"},
+		Spec: v1alpha1.KafkaUserSpec{
+			SecretName:          "app-cert",
+			ExternalCertificate: &v1alpha1.ExternalUserCertificate{CertificateKey: "client.pem"},
+		},
+	}
+	issuedUser := &v1alpha1.KafkaUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "issued", Namespace: "apps"},
+		Spec:       v1alpha1.KafkaUserSpec{SecretName: "app-cert"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, user, issuedUser).Build()
+	r := KafkaUserReconciler{Client: c, Scheme: scheme}
+
+	principal, err := r.externalCertificatePrincipal(context.Background(), user)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedDN, principal)
+
+	user.Spec.ExternalCertificate.CertificateKey = ""
+	_, err = r.externalCertificatePrincipal(context.Background(), user)
+	assert.Error(t, err)
+
+	mapper := externalCertSecretMapper{client: c}
+	assert.Equal(t, []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: "apps", Name: "app"}}}, mapper.mapToKafkaUsers(secret))
+}
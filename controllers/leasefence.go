@@ -0,0 +1,101 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// leaseFenceSafetyMargin is the time the lease has to remain valid for after the check, so that the action
+	// guarded by the fence finishes before another operator replica could acquire the lease
+	leaseFenceSafetyMargin = 5 * time.Second
+
+	inClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// LeaseFence verifies that the operator replica still holds the leader election lease right before the actions
+// which must not be performed by two replicas at the same time, like submitting Cruise Control tasks. A replica
+// which has lost the lease keeps reconciling until it notices the loss, the fence closes this window.
+type LeaseFence struct {
+	// Reader reads the lease directly from the API server
+	Reader client.Reader
+	// Lease is the name of the lease used for the leader election
+	Lease types.NamespacedName
+	// Identity is the host name of the operator replica, the holder identity of the lease held by the replica
+	// starts with it
+	Identity string
+}
+
+// NewLeaseFence returns the LeaseFence of the leader election lease with the given name. The namespace of the
+// operator is used when the namespace is empty, like the leader election does.
+func NewLeaseFence(reader client.Reader, namespace, name string) (*LeaseFence, error) {
+	if namespace == "" {
+		ns, err := os.ReadFile(inClusterNamespacePath)
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not determine the namespace of the leader election lease")
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+	identity, err := os.Hostname()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not determine the leader election identity")
+	}
+	return &LeaseFence{
+		Reader:   reader,
+		Lease:    types.NamespacedName{Namespace: namespace, Name: name},
+		Identity: identity,
+	}, nil
+}
+
+// Check returns an error unless the lease is held by the operator replica and it remains valid for the safety margin.
+// A nil LeaseFence, i.e. when leader election is disabled, always passes the check.
+func (f *LeaseFence) Check(ctx context.Context, now time.Time) error {
+	if f == nil {
+		return nil
+	}
+	lease := &coordinationv1.Lease{}
+	if err := f.Reader.Get(ctx, f.Lease, lease); err != nil {
+		return errors.WrapIfWithDetails(err, "could not get the leader election lease", "lease", f.Lease)
+	}
+	spec := lease.Spec
+	if spec.HolderIdentity == nil || !strings.HasPrefix(*spec.HolderIdentity, f.Identity+"_") {
+		return errors.NewWithDetails("the leader election lease is not held by the operator", "lease", f.Lease, "identity", f.Identity)
+	}
+	if spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+		return errors.NewWithDetails("the leader election lease has not been renewed", "lease", f.Lease)
+	}
+	expiry := spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second)
+	if !now.Add(leaseFenceSafetyMargin).Before(expiry) {
+		return errors.NewWithDetails("the leader election lease is about to expire", "lease", f.Lease, "expiry", expiry)
+	}
+	return nil
+}
+
+// identity returns the identity of the operator replica recorded on the actions guarded by the fence
+func (f *LeaseFence) identity() string {
+	if f == nil {
+		return ""
+	}
+	return f.Identity
+}
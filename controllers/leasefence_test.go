@@ -0,0 +1,83 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestLeaseFenceCheck(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, coordinationv1.AddToScheme(scheme))
+	now := time.Now()
+
+	newLease := func(holder string, renewed time.Time) *coordinationv1.Lease {
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kafka", Name: "koperator"},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       util.StringPointer(holder),
+				LeaseDurationSeconds: util.Int32Pointer(15),
+				RenewTime:            &metav1.MicroTime{Time: renewed},
+			},
+		}
+	}
+	tests := []struct {
+		name    string
+		lease   *coordinationv1.Lease
+		wantErr bool
+	}{
+		{name: "held and renewed", lease: newLease("operator-0_6f1c", now.Add(-2*time.Second))},
+		{name: "held by another replica", lease: newLease("operator-1_98ab", now.Add(-2*time.Second)), wantErr: true},
+		{name: "held by a replica with a longer host name", lease: newLease("operator-0-1_98ab", now), wantErr: true},
+		{name: "about to expire", lease: newLease("operator-0_6f1c", now.Add(-12*time.Second)), wantErr: true},
+		{name: "missing", wantErr: true},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if test.lease != nil {
+				builder = builder.WithObjects(test.lease)
+			}
+			fence := &LeaseFence{
+				Reader:   builder.Build(),
+				Lease:    types.NamespacedName{Namespace: "kafka", Name: "koperator"},
+				Identity: "operator-0",
+			}
+			err := fence.Check(ctx, now)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	var fence *LeaseFence
+	assert.NoError(t, fence.Check(ctx, now))
+}
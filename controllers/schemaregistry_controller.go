@@ -0,0 +1,397 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/util"
+	kafkautil "github.com/banzaicloud/koperator/pkg/util/kafka"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	schemaRegistryResourceNameTemplate = "%s-schema-registry"
+	schemaRegistryTopicNameTemplate    = "%s-schemas"
+	schemaRegistryLabelKey             = "schema_registry"
+	schemaRegistryEnvPrefix            = "SCHEMA_REGISTRY_"
+	schemaRegistryCredentialsMountPath = "/var/run/secrets/schema-registry/credentials"
+	// The credentials and the token are rotated by the KafkaUser controller, periodic resync makes sure that
+	// the instances are restarted with the new ones.
+	defaultSchemaRegistryResyncIntervalInSeconds = 60
+	defaultSchemaRegistryTopicReplicationFactor  = 3
+)
+
+// SchemaRegistryReconciler reconciles SchemaRegistry custom resources
+type SchemaRegistryReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=schemaregistries,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kafka.banzaicloud.io,resources=schemaregistries/status,verbs=get;update;patch
+
+func (r *SchemaRegistryReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	registry := &v1alpha1.SchemaRegistry{}
+	if err := r.Get(ctx, request.NamespacedName, registry); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconciled()
+		}
+		return requeueWithError(log, err.Error(), err)
+	}
+
+	// the owned resources are garbage collected, the topic of the schemas is kept
+	if !registry.GetDeletionTimestamp().IsZero() {
+		return reconciled()
+	}
+
+	cluster, err := k8sutil.LookupKafkaCluster(ctx, r.Client, registry.Spec.ClusterRef.Name,
+		getClusterRefNamespace(registry.GetNamespace(), registry.Spec.ClusterRef))
+	if err != nil {
+		return requeueWithError(log, "failed to lookup referenced kafka cluster", err)
+	}
+
+	listener, err := clientListener(cluster, registry.Spec.ListenerName)
+	if err != nil {
+		return r.updateFailedStatus(ctx, log, registry, err)
+	}
+
+	topic, err := r.reconcileSchemaRegistryTopic(ctx, registry, cluster)
+	if err != nil {
+		return requeueWithError(log, "failed to reconcile the topic of the Schema Registry", err)
+	}
+	registry.Status.TopicName = topic.Spec.Name
+
+	user := schemaRegistryUser(registry, listener.Type)
+	if user != nil {
+		if err = reconcileClientKafkaUser(ctx, r.Client, r.Scheme, registry, user); err != nil {
+			return requeueWithError(log, "failed to reconcile the KafkaUser of the Schema Registry", err)
+		}
+		registry.Status.UserName = user.GetName()
+	}
+
+	secrets, ready, err := clientCredentials(ctx, r.Client, user)
+	if err != nil {
+		return requeueWithError(log, "failed to get the credentials of the Schema Registry", err)
+	}
+	if !ready || topic.Status.State != v1alpha1.TopicStateCreated {
+		log.Info("credentials or the topic of the Schema Registry are not ready yet")
+		return r.updateStatus(ctx, log, registry, cluster, v1alpha1.SchemaRegistryStateCreating, 0, defaultRequeueIntervalInSeconds)
+	}
+
+	config, err := schemaRegistryConfig(registry, cluster, listener, topic.Spec.Name)
+	if err != nil {
+		return r.updateFailedStatus(ctx, log, registry, err)
+	}
+
+	if err = r.reconcileSchemaRegistryService(ctx, registry); err != nil {
+		return requeueWithError(log, "failed to reconcile the service of the Schema Registry", err)
+	}
+	deployment, err := r.reconcileSchemaRegistryDeployment(ctx, registry, cluster, user, config, clientConfigHash(config, secrets))
+	if err != nil {
+		return requeueWithError(log, "failed to reconcile the deployment of the Schema Registry", err)
+	}
+
+	state := v1alpha1.SchemaRegistryStateCreating
+	if deployment.Status.ReadyReplicas == registry.Spec.GetReplicas() && deployment.Status.UpdatedReplicas == registry.Spec.GetReplicas() {
+		state = v1alpha1.SchemaRegistryStateRunning
+	}
+	return r.updateStatus(ctx, log, registry, cluster, state, deployment.Status.ReadyReplicas, defaultSchemaRegistryResyncIntervalInSeconds)
+}
+
+func (r *SchemaRegistryReconciler) updateStatus(ctx context.Context, log logr.Logger, registry *v1alpha1.SchemaRegistry,
+	cluster *v1beta1.KafkaCluster, state v1alpha1.SchemaRegistryState, readyReplicas int32, requeueInterval int) (ctrl.Result, error) {
+	registry.Status.ObservedGeneration = registry.GetGeneration()
+	registry.Status.State = state
+	registry.Status.ReadyReplicas = readyReplicas
+	registry.Status.URL = registry.GetURL(cluster.Spec.GetKubernetesClusterDomain())
+	registry.Status.ErrorMessage = ""
+	if err := r.Status().Update(ctx, registry); err != nil {
+		return requeueWithError(log, "could not update SchemaRegistry status", err)
+	}
+	return requeueAfter(requeueInterval)
+}
+
+func (r *SchemaRegistryReconciler) updateFailedStatus(ctx context.Context, log logr.Logger, registry *v1alpha1.SchemaRegistry, err error) (ctrl.Result, error) {
+	log.Error(err, "deploying Schema Registry failed")
+	registry.Status.ObservedGeneration = registry.GetGeneration()
+	registry.Status.State = v1alpha1.SchemaRegistryStateFailed
+	registry.Status.ErrorMessage = err.Error()
+	if err := r.Status().Update(ctx, registry); err != nil {
+		return requeueWithError(log, "could not update SchemaRegistry status", err)
+	}
+	return requeueAfter(defaultRequeueIntervalInSeconds)
+}
+
+// reconcileSchemaRegistryTopic creates the KafkaTopic of the schemas. The Schema Registry requires a single compacted
+// partition to keep the order of the schemas. The KafkaTopic is not owned by the SchemaRegistry so that the schemas
+// are not deleted together with it.
+func (r *SchemaRegistryReconciler) reconcileSchemaRegistryTopic(ctx context.Context, registry *v1alpha1.SchemaRegistry,
+	cluster *v1beta1.KafkaCluster) (*v1alpha1.KafkaTopic, error) {
+	replicationFactor := int32(defaultSchemaRegistryTopicReplicationFactor)
+	if brokers := int32(len(cluster.Spec.Brokers)); brokers < replicationFactor {
+		replicationFactor = brokers
+	}
+	if registry.Spec.TopicReplicationFactor != nil {
+		replicationFactor = *registry.Spec.TopicReplicationFactor
+	}
+
+	topic := &v1alpha1.KafkaTopic{ObjectMeta: metav1.ObjectMeta{
+		Name:      fmt.Sprintf(schemaRegistryTopicNameTemplate, registry.GetName()),
+		Namespace: registry.GetNamespace(),
+	}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, topic, func() error {
+		if topic.Labels == nil {
+			topic.Labels = make(map[string]string)
+		}
+		topic.Labels[schemaRegistryLabelKey] = registry.GetName()
+		topic.Spec.Name = registry.Spec.GetTopic()
+		topic.Spec.ClusterRef = registry.Spec.ClusterRef
+		topic.Spec.Partitions = 1
+		// the partitions and the replicas of an existing topic cannot be decreased
+		if topic.Spec.ReplicationFactor < replicationFactor {
+			topic.Spec.ReplicationFactor = replicationFactor
+		}
+		if topic.Spec.Config == nil {
+			topic.Spec.Config = make(map[string]string)
+		}
+		topic.Spec.Config["cleanup.policy"] = "compact"
+		return nil
+	})
+	return topic, errors.WrapIfWithDetails(err, "could not create or update KafkaTopic", "kafkaTopic", topic.GetName())
+}
+
+// schemaRegistryUser returns the KafkaUser holding the credentials of the Schema Registry. It is granted access to
+// the topic of the schemas and to the group used for the leader election.
+func schemaRegistryUser(registry *v1alpha1.SchemaRegistry, protocol v1beta1.SecurityProtocol) *v1alpha1.KafkaUser {
+	return clientKafkaUser(fmt.Sprintf(schemaRegistryResourceNameTemplate, registry.GetName()), registry.GetNamespace(),
+		registry.Spec.ClusterRef, schemaRegistryLabels(registry), protocol,
+		[]v1alpha1.UserTopicGrant{
+			{TopicName: registry.Spec.GetTopic(), AccessType: v1alpha1.KafkaAccessTypeRead, PatternType: v1alpha1.KafkaPatternTypeLiteral},
+			{TopicName: registry.Spec.GetTopic(), AccessType: v1alpha1.KafkaAccessTypeWrite, PatternType: v1alpha1.KafkaPatternTypeLiteral},
+		})
+}
+
+// schemaRegistryConfig returns the configuration of the Schema Registry apart from the passwords and the SASL JAAS
+// configuration which are read from the Secrets of the KafkaUser. The configuration given in the SchemaRegistry
+// cannot override the connection, the topic, the group and the security settings.
+func schemaRegistryConfig(registry *v1alpha1.SchemaRegistry, cluster *v1beta1.KafkaCluster,
+	listener v1beta1.InternalListenerConfig, topicName string) (*properties.Properties, error) {
+	config, err := properties.NewFromString(registry.Spec.Config)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not parse the configuration of the Schema Registry")
+	}
+	managed := [][2]string{
+		{"listeners", fmt.Sprintf("http://0.0.0.0:%d", v1alpha1.SchemaRegistryPort)},
+		{"kafkastore.bootstrap.servers", fmt.Sprintf("%s://%s:%d", listener.Type.ToUpperString(), kafkautil.GetClusterServiceFqdn(cluster), listener.ContainerPort)},
+		{"kafkastore.topic", topicName},
+		{"schema.registry.group.id", registry.GetGroupID()},
+		{"kafkastore.security.protocol", listener.Type.ToUpperString()},
+	}
+	if listener.Type.IsSSL() {
+		managed = append(managed, [2]string{"kafkastore.ssl.truststore.location", schemaRegistryCredentialsMountPath + "/" + v1alpha1.TLSJKSTrustStore})
+		if !listener.Type.IsSasl() {
+			managed = append(managed, [2]string{"kafkastore.ssl.keystore.location", schemaRegistryCredentialsMountPath + "/" + v1alpha1.TLSJKSKeyStore})
+		}
+	}
+	if listener.Type.IsSasl() {
+		managed = append(managed, [2]string{"kafkastore.sasl.mechanism", registry.Spec.GetSASLMechanism()})
+	}
+	for _, entry := range managed {
+		if err = config.Set(entry[0], entry[1]); err != nil {
+			return nil, err
+		}
+	}
+	config.Sort()
+	return config, nil
+}
+
+// schemaRegistryEnvName returns the environment variable of the given configuration of the Schema Registry,
+// the Confluent images map the underscores to dots, the double underscores to underscores and the triple
+// underscores to dashes in the environment variables
+func schemaRegistryEnvName(key string) string {
+	replacer := strings.NewReplacer("-", "___", "_", "__", ".", "_")
+	return schemaRegistryEnvPrefix + strings.ToUpper(replacer.Replace(key))
+}
+
+// schemaRegistryEnvs returns the environment variables holding the configuration of the Schema Registry
+func schemaRegistryEnvs(registry *v1alpha1.SchemaRegistry, user *v1alpha1.KafkaUser, config *properties.Properties) []corev1.EnvVar {
+	envs := []corev1.EnvVar{{
+		// the instances advertise their pod IP to the leader
+		Name:      schemaRegistryEnvName("host.name"),
+		ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.podIP"}},
+	}}
+	keys := config.Keys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		property, _ := config.Get(key)
+		envs = append(envs, corev1.EnvVar{Name: schemaRegistryEnvName(key), Value: property.Value()})
+	}
+
+	secretKeyRef := func(name, key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Key:                  key,
+		}}
+	}
+	if user != nil && user.Spec.GetIfCertShouldBeCreated() {
+		passwordKeys := []string{"kafkastore.ssl.truststore.password"}
+		if user.Spec.DelegationToken == nil {
+			passwordKeys = append(passwordKeys, "kafkastore.ssl.keystore.password", "kafkastore.ssl.key.password")
+		}
+		for _, key := range passwordKeys {
+			envs = append(envs, corev1.EnvVar{Name: schemaRegistryEnvName(key), ValueFrom: secretKeyRef(user.Spec.SecretName, v1alpha1.PasswordKey)})
+		}
+	}
+	if user != nil && user.Spec.DelegationToken != nil {
+		envs = append(envs, corev1.EnvVar{
+			Name:      schemaRegistryEnvName("kafkastore.sasl.jaas.config"),
+			ValueFrom: secretKeyRef(user.Spec.DelegationToken.SecretName, v1alpha1.DelegationTokenJAASConfigKey),
+		})
+	}
+	return append(envs, registry.Spec.Envs...)
+}
+
+func schemaRegistryLabels(registry *v1alpha1.SchemaRegistry) map[string]string {
+	return map[string]string{
+		v1beta1.AppLabelKey:     "schema-registry",
+		schemaRegistryLabelKey:  registry.GetName(),
+		v1beta1.KafkaCRLabelKey: registry.Spec.ClusterRef.Name,
+	}
+}
+
+func (r *SchemaRegistryReconciler) reconcileSchemaRegistryService(ctx context.Context, registry *v1alpha1.SchemaRegistry) error {
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:      fmt.Sprintf(schemaRegistryResourceNameTemplate, registry.GetName()),
+		Namespace: registry.GetNamespace(),
+	}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
+		service.Labels = schemaRegistryLabels(registry)
+		service.Spec.Selector = schemaRegistryLabels(registry)
+		service.Spec.Ports = []corev1.ServicePort{{
+			Name:       "http",
+			Protocol:   corev1.ProtocolTCP,
+			Port:       v1alpha1.SchemaRegistryPort,
+			TargetPort: intstr.FromInt(v1alpha1.SchemaRegistryPort),
+		}}
+		return controllerutil.SetControllerReference(registry, service, r.Scheme)
+	})
+	return errors.WrapIfWithDetails(err, "could not create or update Service", "service", service.GetName())
+}
+
+func (r *SchemaRegistryReconciler) reconcileSchemaRegistryDeployment(ctx context.Context, registry *v1alpha1.SchemaRegistry,
+	cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser, config *properties.Properties, configHash string) (*appsv1.Deployment, error) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:      fmt.Sprintf(schemaRegistryResourceNameTemplate, registry.GetName()),
+		Namespace: registry.GetNamespace(),
+	}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, deployment, func() error {
+		deployment.Labels = schemaRegistryLabels(registry)
+		deployment.Spec.Replicas = util.Int32Pointer(registry.Spec.GetReplicas())
+		deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: schemaRegistryLabels(registry)}
+		deployment.Spec.Template = schemaRegistryPodTemplate(registry, cluster, user, config, configHash)
+		return controllerutil.SetControllerReference(registry, deployment, r.Scheme)
+	})
+	return deployment, errors.WrapIfWithDetails(err, "could not create or update Deployment", "deployment", deployment.GetName())
+}
+
+func schemaRegistryPodTemplate(registry *v1alpha1.SchemaRegistry, cluster *v1beta1.KafkaCluster, user *v1alpha1.KafkaUser,
+	config *properties.Properties, configHash string) corev1.PodTemplateSpec {
+	// the image pull secrets of the Kafka cluster are only available in its namespace
+	imagePullSecrets := registry.Spec.ImagePullSecrets
+	if cluster.GetNamespace() == registry.GetNamespace() {
+		imagePullSecrets = cluster.Spec.GetImagePullSecrets(imagePullSecrets)
+	}
+
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	if user != nil && user.Spec.GetIfCertShouldBeCreated() {
+		volumes = append(volumes, corev1.Volume{
+			Name: "schema-registry-credentials",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName:  user.Spec.SecretName,
+				DefaultMode: util.Int32Pointer(0400),
+			}},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "schema-registry-credentials", MountPath: schemaRegistryCredentialsMountPath, ReadOnly: true})
+	}
+
+	container := corev1.Container{
+		Name:  "schema-registry",
+		Image: cluster.Spec.GetImage(registry.Spec.GetImage()),
+		Env:   schemaRegistryEnvs(registry, user, config),
+		Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: v1alpha1.SchemaRegistryPort, Protocol: corev1.ProtocolTCP}},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+				Path: "/subjects",
+				Port: intstr.FromInt(v1alpha1.SchemaRegistryPort),
+			}},
+			InitialDelaySeconds: 10,
+			PeriodSeconds:       10,
+		},
+		VolumeMounts: volumeMounts,
+	}
+	if registry.Spec.Resources != nil {
+		container.Resources = *registry.Spec.Resources
+	}
+
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      schemaRegistryLabels(registry),
+			Annotations: map[string]string{v1beta1.ConfigHashAnnotationKey: configHash},
+		},
+		Spec: corev1.PodSpec{
+			Containers:       []corev1.Container{container},
+			Volumes:          volumes,
+			ImagePullSecrets: imagePullSecrets,
+		},
+	}
+}
+
+// SetupSchemaRegistryWithManager registers SchemaRegistry controller to the manager
+func SetupSchemaRegistryWithManager(mgr ctrl.Manager) *ctrl.Builder {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.SchemaRegistry{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&appsv1.Deployment{}).
+		WithEventFilter(SkipClusterRegistryOwnedResourcePredicate{}).
+		WithEventFilter(WatchSelectorPredicate{Client: mgr.GetClient()}).
+		Named("SchemaRegistry")
+}
+
+// blank assignment to verify that SchemaRegistryReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &SchemaRegistryReconciler{}
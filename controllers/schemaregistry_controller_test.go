@@ -0,0 +1,145 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func newSchemaRegistryTestReconciler(t *testing.T, objects ...client.Object) *SchemaRegistryReconciler {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, appsv1.AddToScheme(scheme))
+
+	return &SchemaRegistryReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Scheme: scheme,
+	}
+}
+
+func envValues(envs []corev1.EnvVar) map[string]corev1.EnvVar {
+	ret := make(map[string]corev1.EnvVar, len(envs))
+	for _, env := range envs {
+		ret[env.Name] = env
+	}
+	return ret
+}
+
+func TestSchemaRegistryReconcile(t *testing.T) {
+	cluster := newKafkaConnectTestCluster(v1beta1.SecurityProtocolSSL)
+	cluster.Spec.Brokers = []v1beta1.Broker{{Id: 0}, {Id: 1}}
+	registry := &v1alpha1.SchemaRegistry{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "kafka", Generation: 1},
+		Spec: v1alpha1.SchemaRegistrySpec{
+			ClusterRef: v1alpha1.ClusterReference{Name: "kafka"},
+			Config:     "schema.compatibility.level=backward\nkafkastore.topic=other\n",
+		},
+	}
+	r := newSchemaRegistryTestReconciler(t, cluster, registry)
+	ctx := context.Background()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "registry", Namespace: "kafka"}}
+	name := types.NamespacedName{Name: "registry-schema-registry", Namespace: "kafka"}
+
+	reconcileAndGet := func() *v1alpha1.SchemaRegistry {
+		_, err := r.Reconcile(ctx, request)
+		assert.NoError(t, err)
+		current := &v1alpha1.SchemaRegistry{}
+		assert.NoError(t, r.Get(ctx, request.NamespacedName, current))
+		return current
+	}
+
+	// The instances are not deployed until the topic and the credentials are ready
+	current := reconcileAndGet()
+	assert.Equal(t, v1alpha1.SchemaRegistryStateCreating, current.Status.State)
+	assert.Equal(t, "_schemas", current.Status.TopicName)
+	assert.Equal(t, "http://registry-schema-registry.kafka.svc.cluster.local:8081", current.Status.URL)
+
+	topic := &v1alpha1.KafkaTopic{}
+	assert.NoError(t, r.Get(ctx, types.NamespacedName{Name: "registry-schemas", Namespace: "kafka"}, topic))
+	assert.Equal(t, "_schemas", topic.Spec.Name)
+	assert.Equal(t, int32(1), topic.Spec.Partitions)
+	assert.Equal(t, int32(2), topic.Spec.ReplicationFactor)
+	assert.Equal(t, map[string]string{"cleanup.policy": "compact"}, topic.Spec.Config)
+	assert.Empty(t, topic.GetOwnerReferences())
+
+	user := &v1alpha1.KafkaUser{}
+	assert.NoError(t, r.Get(ctx, name, user))
+	assert.Equal(t, "registry-schema-registry-credentials", user.Spec.SecretName)
+	assert.True(t, user.Spec.IncludeJKS)
+	assert.Nil(t, user.Spec.DelegationToken)
+	assert.Equal(t, []v1alpha1.UserTopicGrant{
+		{TopicName: "_schemas", AccessType: v1alpha1.KafkaAccessTypeRead, PatternType: v1alpha1.KafkaPatternTypeLiteral},
+		{TopicName: "_schemas", AccessType: v1alpha1.KafkaAccessTypeWrite, PatternType: v1alpha1.KafkaPatternTypeLiteral},
+	}, user.Spec.TopicGrants)
+
+	topic.Status.State = v1alpha1.TopicStateCreated
+	assert.NoError(t, r.Status().Update(ctx, topic))
+	assert.NoError(t, r.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry-schema-registry-credentials", Namespace: "kafka"},
+		Data:       map[string][]byte{v1alpha1.PasswordKey: []byte("changeit")},
+	}))
+
+	reconcileAndGet()
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, r.Get(ctx, name, deployment))
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, v1alpha1.DefaultSchemaRegistryImage, container.Image)
+	envs := envValues(container.Env)
+	assert.Equal(t, "SSL://kafka-all-broker.kafka.svc.cluster.local:29092", envs["SCHEMA_REGISTRY_KAFKASTORE_BOOTSTRAP_SERVERS"].Value)
+	assert.Equal(t, "_schemas", envs["SCHEMA_REGISTRY_KAFKASTORE_TOPIC"].Value)
+	assert.Equal(t, "registry", envs["SCHEMA_REGISTRY_SCHEMA_REGISTRY_GROUP_ID"].Value)
+	assert.Equal(t, "backward", envs["SCHEMA_REGISTRY_SCHEMA_COMPATIBILITY_LEVEL"].Value)
+	assert.Equal(t, "/var/run/secrets/schema-registry/credentials/keystore.jks", envs["SCHEMA_REGISTRY_KAFKASTORE_SSL_KEYSTORE_LOCATION"].Value)
+	assert.Equal(t, "registry-schema-registry-credentials", envs["SCHEMA_REGISTRY_KAFKASTORE_SSL_KEYSTORE_PASSWORD"].ValueFrom.SecretKeyRef.Name)
+	assert.Equal(t, "status.podIP", envs["SCHEMA_REGISTRY_HOST_NAME"].ValueFrom.FieldRef.FieldPath)
+	assert.NotEmpty(t, deployment.Spec.Template.GetAnnotations()[v1beta1.ConfigHashAnnotationKey])
+
+	deployment.Status.ReadyReplicas = 1
+	deployment.Status.UpdatedReplicas = 1
+	assert.NoError(t, r.Status().Update(ctx, deployment))
+	current = reconcileAndGet()
+	assert.Equal(t, v1alpha1.SchemaRegistryStateRunning, current.Status.State)
+}
+
+func TestSchemaRegistryEnvName(t *testing.T) {
+	testCases := []struct {
+		key      string
+		expected string
+	}{
+		{key: "kafkastore.topic", expected: "SCHEMA_REGISTRY_KAFKASTORE_TOPIC"},
+		{key: "access.control.allow-methods", expected: "SCHEMA_REGISTRY_ACCESS_CONTROL_ALLOW___METHODS"},
+		{key: "kafkastore.init_timeout_ms", expected: "SCHEMA_REGISTRY_KAFKASTORE_INIT__TIMEOUT__MS"},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, schemaRegistryEnvName(test.key))
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// ShardCountEnvVar is the environment variable holding the number of the operator instances sharing the KafkaClusters
+	ShardCountEnvVar = "KOPERATOR_SHARD_COUNT"
+	// ShardIndexEnvVar is the environment variable holding the index of the shard of the operator instance. When it is
+	// not set the operator has to run as a StatefulSet, the ordinal of the StatefulSet pod is taken from the hostname.
+	ShardIndexEnvVar = "KOPERATOR_SHARD_INDEX"
+	hostnameEnvVar   = "HOSTNAME"
+)
+
+// reconcileShard is the shard of the KafkaClusters reconciled by the operator instance, every KafkaCluster is
+// reconciled when it is nil
+var reconcileShard *clusterShard
+
+// clusterShard assigns the KafkaClusters to one of the operator instances by the hash of their namespace and name
+type clusterShard struct {
+	index uint32
+	count uint32
+}
+
+// SetShard makes the operator instance reconcile only the KafkaClusters, and the resources belonging to them,
+// which are assigned to the shard with the given index so that multiple instances can work in parallel
+func SetShard(index, count int) error {
+	if count <= 1 {
+		reconcileShard = nil
+		return nil
+	}
+	if index < 0 || index >= count {
+		return errors.NewWithDetails("shard index must be between zero and the shard count", "index", index, "count", count)
+	}
+	reconcileShard = &clusterShard{index: uint32(index), count: uint32(count)}
+	return nil
+}
+
+// owns returns true when the KafkaCluster is assigned to the shard
+func (s *clusterShard) owns(cluster types.NamespacedName) bool {
+	if s == nil {
+		return true
+	}
+	return shardOf(cluster, s.count) == s.index
+}
+
+// shardOf returns the index of the shard which the KafkaCluster is assigned to
+func shardOf(cluster types.NamespacedName, count uint32) uint32 {
+	hash := fnv.New32a()
+	// Writing to the hash never fails
+	_, _ = hash.Write([]byte(cluster.String()))
+	return hash.Sum32() % count
+}
+
+// ShardFromEnv returns the shard index and count of the operator instance configured by the environment variables.
+// A count of zero means that sharding is disabled.
+func ShardFromEnv(getenv func(string) string) (index, count int, err error) {
+	countValue := getenv(ShardCountEnvVar)
+	if countValue == "" {
+		return 0, 0, nil
+	}
+	if count, err = strconv.Atoi(countValue); err != nil {
+		return 0, 0, errors.WrapIfWithDetails(err, "invalid shard count", "value", countValue)
+	}
+	if count <= 1 {
+		return 0, 0, nil
+	}
+
+	if indexValue := getenv(ShardIndexEnvVar); indexValue != "" {
+		if index, err = strconv.Atoi(indexValue); err != nil {
+			return 0, 0, errors.WrapIfWithDetails(err, "invalid shard index", "value", indexValue)
+		}
+		return index, count, nil
+	}
+
+	hostname := getenv(hostnameEnvVar)
+	if index, err = statefulSetOrdinal(hostname); err != nil {
+		return 0, 0, errors.WrapIfWithDetails(err, "the shard index is not set by "+ShardIndexEnvVar+
+			" and cannot be taken from the hostname, the operator has to run as a StatefulSet when sharding is enabled",
+			"hostname", hostname)
+	}
+	return index, count, nil
+}
+
+// statefulSetOrdinal returns the ordinal of the StatefulSet pod with the given hostname, which is the name of the
+// StatefulSet followed by a dash and the ordinal
+func statefulSetOrdinal(hostname string) (int, error) {
+	separator := strings.LastIndex(hostname, "-")
+	if separator <= 0 {
+		return 0, errors.New("hostname has no ordinal suffix")
+	}
+	ordinal := hostname[separator+1:]
+	// The ordinal of a StatefulSet pod has no leading zeros, unlike the random suffix of a Deployment pod may have
+	if ordinal == "" || (len(ordinal) > 1 && ordinal[0] == '0') || strings.Trim(ordinal, "0123456789") != "" {
+		return 0, errors.New("hostname has no ordinal suffix")
+	}
+	return strconv.Atoi(ordinal)
+}
@@ -0,0 +1,106 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestClusterShard(t *testing.T) {
+	const count = 3
+	owners := make(map[int]int)
+	for i := 0; i < 30; i++ {
+		cluster := types.NamespacedName{Namespace: "kafka", Name: fmt.Sprintf("kafka-%d", i)}
+		owned := 0
+		for index := 0; index < count; index++ {
+			if (&clusterShard{index: uint32(index), count: count}).owns(cluster) {
+				owned++
+				owners[index]++
+			}
+		}
+		assert.Equal(t, 1, owned, "every KafkaCluster must be owned by exactly one shard")
+	}
+	assert.Len(t, owners, count, "every shard should own some of the KafkaClusters")
+
+	var noShard *clusterShard
+	assert.True(t, noShard.owns(types.NamespacedName{Namespace: "kafka", Name: "kafka"}))
+
+	assert.Error(t, SetShard(3, 3))
+	assert.NoError(t, SetShard(0, 1))
+	assert.Nil(t, reconcileShard)
+}
+
+func TestShardFromEnv(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		env           map[string]string
+		expectedIndex int
+		expectedCount int
+		expectedErr   bool
+	}{
+		{
+			testName: "sharding disabled",
+		},
+		{
+			testName:      "explicit index",
+			env:           map[string]string{ShardCountEnvVar: "4", ShardIndexEnvVar: "2", hostnameEnvVar: "koperator-1"},
+			expectedIndex: 2,
+			expectedCount: 4,
+		},
+		{
+			testName:      "index from StatefulSet ordinal",
+			env:           map[string]string{ShardCountEnvVar: "4", hostnameEnvVar: "kafka-operator-3"},
+			expectedIndex: 3,
+			expectedCount: 4,
+		},
+		{
+			testName:    "hostname without ordinal",
+			env:         map[string]string{ShardCountEnvVar: "4", hostnameEnvVar: "kafka-operator-7d9f8b6c5-x2x4z"},
+			expectedErr: true,
+		},
+		{
+			testName:    "hostname without dash",
+			env:         map[string]string{ShardCountEnvVar: "4", hostnameEnvVar: "koperator"},
+			expectedErr: true,
+		},
+		{
+			testName:    "missing hostname",
+			env:         map[string]string{ShardCountEnvVar: "4"},
+			expectedErr: true,
+		},
+		{
+			testName:    "invalid count",
+			env:         map[string]string{ShardCountEnvVar: "many"},
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			index, count, err := ShardFromEnv(func(key string) string { return testCase.env[key] })
+			if testCase.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedIndex, index)
+			assert.Equal(t, testCase.expectedCount, count)
+		})
+	}
+}
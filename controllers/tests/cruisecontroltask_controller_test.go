@@ -25,7 +25,7 @@ import (
 	. "github.com/onsi/gomega"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"github.com/banzaicloud/koperator/controllers/tests/mocks"
+	"github.com/banzaicloud/koperator/pkg/testing/mocks"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -140,7 +140,7 @@ var _ = Describe("CruiseControlTaskReconciler", func() {
 				return volumeState.CruiseControlOperationReference.Name == operation.Name &&
 					operation.CurrentTaskOperation() == v1alpha1.OperationRebalance &&
 					volumeState.CruiseControlVolumeState == v1beta1.GracefulDiskRebalanceScheduled &&
-					operation.CurrentTask() != nil && operation.CurrentTask().Parameters["rebalance_disk"] == trueStr
+					operation.CurrentTask() != nil && operation.CurrentTaskParameters()["rebalance_disk"] == trueStr
 
 			}, 15*time.Second, 500*time.Millisecond).Should(BeTrue())
 		})
@@ -220,7 +220,7 @@ var _ = Describe("CruiseControlTaskReconciler", func() {
 				return volumeState.CruiseControlOperationReference.Name == operation.Name &&
 					operation.CurrentTaskOperation() == v1alpha1.OperationRebalance &&
 					volumeState.CruiseControlVolumeState == v1beta1.GracefulDiskRebalanceScheduled &&
-					operation.CurrentTask() != nil && operation.CurrentTask().Parameters["rebalance_disk"] != trueStr
+					operation.CurrentTask() != nil && operation.CurrentTaskParameters()["rebalance_disk"] != trueStr
 
 			}, 15*time.Second, 500*time.Millisecond).Should(BeTrue())
 		})
@@ -306,7 +306,7 @@ var _ = Describe("CruiseControlTaskReconciler", func() {
 				return volumeState.CruiseControlOperationReference.Name == operation.Name &&
 					operation.CurrentTaskOperation() == v1alpha1.OperationRebalance &&
 					volumeState.CruiseControlVolumeState == v1beta1.GracefulDiskRebalanceScheduled &&
-					operation.CurrentTask() != nil && operation.CurrentTask().Parameters["rebalance_disk"] != trueStr
+					operation.CurrentTask() != nil && operation.CurrentTaskParameters()["rebalance_disk"] != trueStr
 			}, 15*time.Second, 500*time.Millisecond).Should(BeTrue())
 		})
 	})
@@ -0,0 +1,151 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+const (
+	kafkaClusterKind   = "KafkaCluster"
+	kafkaConnectKind   = "KafkaConnect"
+	schemaRegistryKind = "SchemaRegistry"
+)
+
+// watchLabelSelector selects the KafkaClusters which are reconciled by the operator, every KafkaCluster is reconciled
+// when it is nil
+var watchLabelSelector labels.Selector
+
+// SetWatchLabelSelector restricts the operator to the KafkaClusters matching the label selector and to the resources
+// belonging to them, so that multiple operator instances can manage disjoint sets of Kafka clusters
+func SetWatchLabelSelector(selector labels.Selector) {
+	if selector != nil && selector.Empty() {
+		selector = nil
+	}
+	watchLabelSelector = selector
+}
+
+// kafkaClusterKeyOf returns the key of the KafkaCluster which the object belongs to. The KafkaCluster of the
+// KafkaConnectors and of the resources of the KafkaConnects and SchemaRegistries is the one referenced by their owner.
+func kafkaClusterKeyOf(ctx context.Context, reader client.Reader, obj client.Object) (types.NamespacedName, bool) {
+	switch o := obj.(type) {
+	case *v1beta1.KafkaCluster:
+		return types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}, true
+	case *v1alpha1.KafkaTopic:
+		return types.NamespacedName{Namespace: getClusterRefNamespace(o.GetNamespace(), o.Spec.ClusterRef), Name: o.Spec.ClusterRef.Name}, true
+	case *v1alpha1.KafkaUser:
+		return types.NamespacedName{Namespace: getClusterRefNamespace(o.GetNamespace(), o.Spec.ClusterRef), Name: o.Spec.ClusterRef.Name}, true
+	case *v1alpha1.CruiseControlAdmin:
+		return types.NamespacedName{Namespace: getClusterRefNamespace(o.GetNamespace(), o.Spec.ClusterRef), Name: o.Spec.ClusterRef.Name}, true
+	case *v1alpha1.KafkaBrokerDecommission:
+		return types.NamespacedName{Namespace: getClusterRefNamespace(o.GetNamespace(), o.Spec.ClusterRef), Name: o.Spec.ClusterRef.Name}, true
+	case *v1alpha1.KafkaBrokerReplacement:
+		return types.NamespacedName{Namespace: getClusterRefNamespace(o.GetNamespace(), o.Spec.ClusterRef), Name: o.Spec.ClusterRef.Name}, true
+	case *v1alpha1.KafkaConnect:
+		return types.NamespacedName{Namespace: getClusterRefNamespace(o.GetNamespace(), o.Spec.ClusterRef), Name: o.Spec.ClusterRef.Name}, true
+	case *v1alpha1.KafkaConnector:
+		return referencedClusterKey(ctx, reader, &v1alpha1.KafkaConnect{}, types.NamespacedName{Namespace: o.GetNamespace(), Name: o.Spec.ConnectRef})
+	case *v1alpha1.SchemaRegistry:
+		return types.NamespacedName{Namespace: getClusterRefNamespace(o.GetNamespace(), o.Spec.ClusterRef), Name: o.Spec.ClusterRef.Name}, true
+	}
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.Controller == nil || !*ownerRef.Controller {
+			continue
+		}
+		switch ownerRef.Kind {
+		case kafkaClusterKind:
+			return types.NamespacedName{Namespace: obj.GetNamespace(), Name: ownerRef.Name}, true
+		case kafkaConnectKind:
+			return referencedClusterKey(ctx, reader, &v1alpha1.KafkaConnect{}, types.NamespacedName{Namespace: obj.GetNamespace(), Name: ownerRef.Name})
+		case schemaRegistryKind:
+			return referencedClusterKey(ctx, reader, &v1alpha1.SchemaRegistry{}, types.NamespacedName{Namespace: obj.GetNamespace(), Name: ownerRef.Name})
+		}
+	}
+	if name, ok := obj.GetLabels()[v1beta1.KafkaCRLabelKey]; ok && name != "" {
+		return types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}, true
+	}
+	return types.NamespacedName{}, false
+}
+
+// referencedClusterKey reads the object with the given key and returns the key of the KafkaCluster it belongs to, false
+// is returned when the object cannot be read
+func referencedClusterKey(ctx context.Context, reader client.Reader, obj client.Object, key types.NamespacedName) (types.NamespacedName, bool) {
+	if reader == nil || reader.Get(ctx, key, obj) != nil {
+		return types.NamespacedName{}, false
+	}
+	return kafkaClusterKeyOf(ctx, reader, obj)
+}
+
+// WatchSelectorPredicate returns a controller event filter that filters out the events of the resources which do not
+// belong to the KafkaClusters selected by the watch label selector or owned by the shard of the operator instance.
+// Resources whose KafkaCluster does not exist are selected by their own labels.
+type WatchSelectorPredicate struct {
+	Client client.Reader
+}
+
+func (p WatchSelectorPredicate) isSelected(obj client.Object) bool {
+	if obj == nil {
+		return true
+	}
+	if key, ok := kafkaClusterKeyOf(context.Background(), p.Client, obj); ok && key.Name != "" && !reconcileShard.owns(key) {
+		return false
+	}
+	return p.matchesLabelSelector(obj)
+}
+
+func (p WatchSelectorPredicate) matchesLabelSelector(obj client.Object) bool {
+	if watchLabelSelector == nil {
+		return true
+	}
+	if cluster, ok := obj.(*v1beta1.KafkaCluster); ok {
+		return watchLabelSelector.Matches(labels.Set(cluster.GetLabels()))
+	}
+	if key, ok := kafkaClusterKeyOf(context.Background(), p.Client, obj); ok && key.Name != "" {
+		cluster := &v1beta1.KafkaCluster{}
+		if err := p.Client.Get(context.Background(), key, cluster); err == nil {
+			return watchLabelSelector.Matches(labels.Set(cluster.GetLabels()))
+		}
+	}
+	return watchLabelSelector.Matches(labels.Set(obj.GetLabels()))
+}
+
+func (p WatchSelectorPredicate) Create(e event.CreateEvent) bool {
+	return p.isSelected(e.Object)
+}
+
+func (p WatchSelectorPredicate) Delete(e event.DeleteEvent) bool {
+	return p.isSelected(e.Object)
+}
+
+func (p WatchSelectorPredicate) Update(e event.UpdateEvent) bool {
+	// The KafkaCluster which is moved out of the selection is left to the operator instance selecting it
+	return p.isSelected(e.ObjectNew)
+}
+
+func (p WatchSelectorPredicate) Generic(e event.GenericEvent) bool {
+	return p.isSelected(e.Object)
+}
+
+// blank assignment to verify that WatchSelectorPredicate implements predicate.Predicate
+var _ predicate.Predicate = WatchSelectorPredicate{}
@@ -0,0 +1,163 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestWatchSelectorPredicate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	selected := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "selected", Namespace: "kafka", Labels: map[string]string{"tenant": "a"}}}
+	other := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "kafka", Labels: map[string]string{"tenant": "b"}}}
+	connectOf := func(cluster string) *v1alpha1.KafkaConnect {
+		return &v1alpha1.KafkaConnect{
+			ObjectMeta: metav1.ObjectMeta{Name: cluster + "-connect", Namespace: "kafka", Labels: map[string]string{"tenant": "a"}},
+			Spec:       v1alpha1.KafkaConnectSpec{ClusterRef: v1alpha1.ClusterReference{Name: cluster}},
+		}
+	}
+	registryOf := func(cluster string) *v1alpha1.SchemaRegistry {
+		return &v1alpha1.SchemaRegistry{
+			ObjectMeta: metav1.ObjectMeta{Name: cluster + "-registry", Namespace: "kafka", Labels: map[string]string{"tenant": "a"}},
+			Spec:       v1alpha1.SchemaRegistrySpec{ClusterRef: v1alpha1.ClusterReference{Name: cluster}},
+		}
+	}
+	p := WatchSelectorPredicate{Client: fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(selected, other, connectOf("selected"), connectOf("other"), registryOf("selected"), registryOf("other")).Build()}
+
+	topicOf := func(cluster string) *v1alpha1.KafkaTopic {
+		return &v1alpha1.KafkaTopic{
+			ObjectMeta: metav1.ObjectMeta{Name: "topic", Namespace: "kafka"},
+			Spec:       v1alpha1.KafkaTopicSpec{ClusterRef: v1alpha1.ClusterReference{Name: cluster}},
+		}
+	}
+	podOf := func(cluster string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "kafka",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: kafkaClusterKind, Name: cluster, Controller: util.BoolPointer(true)},
+			},
+		}}
+	}
+	ccOperationOf := func(cluster string) *v1alpha1.CruiseControlOperation {
+		return &v1alpha1.CruiseControlOperation{ObjectMeta: metav1.ObjectMeta{
+			Name:      "operation",
+			Namespace: "kafka",
+			Labels:    map[string]string{v1beta1.KafkaCRLabelKey: cluster},
+		}}
+	}
+
+	decommissionOf := func(cluster string) *v1alpha1.KafkaBrokerDecommission {
+		return &v1alpha1.KafkaBrokerDecommission{
+			ObjectMeta: metav1.ObjectMeta{Name: "decommission", Namespace: "kafka", Labels: map[string]string{"tenant": "a"}},
+			Spec:       v1alpha1.KafkaBrokerDecommissionSpec{ClusterRef: v1alpha1.ClusterReference{Name: cluster}},
+		}
+	}
+	replacementOf := func(cluster string) *v1alpha1.KafkaBrokerReplacement {
+		return &v1alpha1.KafkaBrokerReplacement{
+			ObjectMeta: metav1.ObjectMeta{Name: "replacement", Namespace: "kafka", Labels: map[string]string{"tenant": "a"}},
+			Spec:       v1alpha1.KafkaBrokerReplacementSpec{ClusterRef: v1alpha1.ClusterReference{Name: cluster}},
+		}
+	}
+	connectorOf := func(cluster string) *v1alpha1.KafkaConnector {
+		return &v1alpha1.KafkaConnector{
+			ObjectMeta: metav1.ObjectMeta{Name: "connector", Namespace: "kafka", Labels: map[string]string{"tenant": "a"}},
+			Spec:       v1alpha1.KafkaConnectorSpec{ConnectRef: cluster + "-connect"},
+		}
+	}
+	connectPodOf := func(cluster string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      "connect-pod",
+			Namespace: "kafka",
+			Labels:    map[string]string{"tenant": "a"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: kafkaConnectKind, Name: cluster + "-connect", Controller: util.BoolPointer(true)},
+			},
+		}}
+	}
+	registryDeploymentOf := func(cluster string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:      "registry-deployment",
+			Namespace: "kafka",
+			Labels:    map[string]string{"tenant": "a"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: schemaRegistryKind, Name: cluster + "-registry", Controller: util.BoolPointer(true)},
+			},
+		}}
+	}
+
+	testCases := []struct {
+		testName string
+		object   client.Object
+		expected bool
+	}{
+		{testName: "selected cluster", object: selected, expected: true},
+		{testName: "other cluster", object: other},
+		{testName: "topic of selected cluster", object: topicOf("selected"), expected: true},
+		{testName: "topic of other cluster", object: topicOf("other")},
+		{testName: "pod of selected cluster", object: podOf("selected"), expected: true},
+		{testName: "pod of other cluster", object: podOf("other")},
+		{testName: "operation of selected cluster", object: ccOperationOf("selected"), expected: true},
+		{testName: "operation of other cluster", object: ccOperationOf("other")},
+		{testName: "topic of missing cluster", object: topicOf("missing")},
+		{testName: "decommission of selected cluster", object: decommissionOf("selected"), expected: true},
+		{testName: "decommission of other cluster", object: decommissionOf("other")},
+		{testName: "replacement of selected cluster", object: replacementOf("selected"), expected: true},
+		{testName: "replacement of other cluster", object: replacementOf("other")},
+		{testName: "connect of selected cluster", object: connectOf("selected"), expected: true},
+		{testName: "connect of other cluster", object: connectOf("other")},
+		{testName: "connector of selected cluster", object: connectorOf("selected"), expected: true},
+		{testName: "connector of other cluster", object: connectorOf("other")},
+		{testName: "connect pod of selected cluster", object: connectPodOf("selected"), expected: true},
+		{testName: "connect pod of other cluster", object: connectPodOf("other")},
+		{testName: "schema registry of selected cluster", object: registryOf("selected"), expected: true},
+		{testName: "schema registry of other cluster", object: registryOf("other")},
+		{testName: "schema registry deployment of selected cluster", object: registryDeploymentOf("selected"), expected: true},
+		{testName: "schema registry deployment of other cluster", object: registryDeploymentOf("other")},
+	}
+
+	SetWatchLabelSelector(labels.SelectorFromSet(labels.Set{"tenant": "a"}))
+	defer SetWatchLabelSelector(nil)
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, p.Create(event.CreateEvent{Object: testCase.object}))
+		})
+	}
+
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: other, ObjectNew: selected}))
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: selected, ObjectNew: other}))
+
+	SetWatchLabelSelector(nil)
+	assert.True(t, p.Create(event.CreateEvent{Object: other}))
+}
@@ -18,14 +18,17 @@ import (
 	"net/http"
 
 	"github.com/go-logr/logr"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/banzaicloud/koperator/internal/alertmanager/ccanomaly"
 	"github.com/banzaicloud/koperator/internal/alertmanager/receiver"
 )
 
 // NewApp returns HTTPHandler
-func NewApp(log logr.Logger, client client.Client) http.Handler {
+func NewApp(log logr.Logger, client client.Client, recorder record.EventRecorder) http.Handler {
 	mux := http.NewServeMux()
 	mux.Handle(receiver.APIEndPoint, receiver.NewHTTPHandler(log, client))
+	mux.Handle(ccanomaly.APIEndPoint, ccanomaly.NewHTTPHandler(log.WithName("cruisecontrol-anomaly"), client, recorder))
 	return mux
 }
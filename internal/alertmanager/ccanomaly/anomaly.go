@@ -0,0 +1,161 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ccanomaly handles the anomalies reported by the Slack notifier of Cruise Control. The anomalies are recorded
+// as Kubernetes events and metrics, and remediated with CruiseControlOperations according to the anomaly notifier
+// configuration of the Kafka cluster.
+package ccanomaly
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/ccoperation"
+)
+
+const (
+	// AnomalyDetectedReason is the reason of the events recorded for the anomalies detected by Cruise Control
+	AnomalyDetectedReason = "CruiseControlAnomalyDetected"
+	// AnomalyRemediationCreatedReason is the reason of the events recorded for the created remediations
+	AnomalyRemediationCreatedReason = "CruiseControlAnomalyRemediationCreated"
+
+	// maxEventDescriptionLength keeps the event messages below the size recommended by Kubernetes
+	maxEventDescriptionLength = 768
+)
+
+// anomalyTypes are the anomaly types reported by Cruise Control
+var anomalyTypes = map[string]struct{}{
+	"BROKER_FAILURE":    {},
+	"DISK_FAILURE":      {},
+	"GOAL_VIOLATION":    {},
+	"METRIC_ANOMALY":    {},
+	"TOPIC_ANOMALY":     {},
+	"MAINTENANCE_EVENT": {},
+}
+
+var anomaliesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "koperator_cruisecontrol_anomalies_total",
+	Help: "Number of anomalies of the given type reported by Cruise Control of the Kafka cluster",
+}, []string{"namespace", "kafka_cluster", "anomaly_type"})
+
+func init() {
+	metrics.Registry.MustRegister(anomaliesCounter)
+}
+
+// Anomaly is an anomaly reported by Cruise Control
+type Anomaly struct {
+	// Type of the anomaly, e.g. BROKER_FAILURE
+	Type string
+	// Description of the anomaly by Cruise Control
+	Description string
+	// SelfHealingTriggered is true when Cruise Control started fixing the anomaly itself
+	SelfHealingTriggered bool
+}
+
+// parseAnomaly parses the text of the message sent by the Slack notifier of Cruise Control, which has the
+// "<type> detected <anomaly>. Self healing <state>." format optionally followed by a line reporting the self-healing.
+func parseAnomaly(text string) (Anomaly, error) {
+	typeEnd := strings.Index(text, " detected ")
+	if typeEnd < 0 {
+		return Anomaly{}, errors.NewWithDetails("unexpected anomaly notification format", "text", text)
+	}
+	anomaly := Anomaly{
+		Type:                 text[:typeEnd],
+		SelfHealingTriggered: strings.Contains(text, "Self-healing has been triggered."),
+	}
+	if _, ok := anomalyTypes[anomaly.Type]; !ok {
+		return Anomaly{}, errors.NewWithDetails("unknown anomaly type", "anomalyType", anomaly.Type)
+	}
+	description := text[typeEnd+len(" detected "):]
+	if i := strings.LastIndex(description, ". Self healing "); i >= 0 {
+		description = description[:i]
+	}
+	anomaly.Description = strings.TrimSpace(description)
+	return anomaly, nil
+}
+
+// processAnomaly records the anomaly of the Kafka cluster and creates the remediation of its type unless one is
+// pending or has been created within its minimum interval
+func processAnomaly(ctx context.Context, log logr.Logger, c client.Client, recorder record.EventRecorder,
+	cluster *v1beta1.KafkaCluster, anomaly Anomaly, now time.Time) error {
+	anomaliesCounter.WithLabelValues(cluster.GetNamespace(), cluster.GetName(), anomaly.Type).Inc()
+
+	description := anomaly.Description
+	if len(description) > maxEventDescriptionLength {
+		description = description[:maxEventDescriptionLength] + "..."
+	}
+	message := fmt.Sprintf("%s detected by Cruise Control: %s", anomaly.Type, description)
+	if anomaly.SelfHealingTriggered {
+		message += ", self-healing has been triggered"
+	}
+	recorder.Event(cluster, corev1.EventTypeWarning, AnomalyDetectedReason, message)
+	log.Info("anomaly detected by Cruise Control", "anomalyType", anomaly.Type, "selfHealingTriggered", anomaly.SelfHealingTriggered)
+
+	remediation, ok := cluster.Spec.CruiseControlConfig.AnomalyNotifier.GetRemediation(anomaly.Type)
+	if !ok {
+		return nil
+	}
+	due, err := isRemediationDue(ctx, c, cluster, remediation, now)
+	if err != nil || !due {
+		return err
+	}
+
+	operation, err := ccoperation.New(v1alpha1.CruiseControlTaskOperation(remediation.Operation)).
+		ForCluster(cluster).
+		WithLabels(map[string]string{v1alpha1.AnomalyTypeLabel: strings.ToLower(anomaly.Type)}).
+		OwnedBy(cluster, c.Scheme()).
+		Create(ctx, c)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not create the remediation of the anomaly", "anomalyType", anomaly.Type)
+	}
+	recorder.Eventf(cluster, corev1.EventTypeNormal, AnomalyRemediationCreatedReason,
+		"%s operation %s created to remediate %s", remediation.Operation, operation.GetName(), anomaly.Type)
+	log.Info("remediation of the anomaly created", "anomalyType", anomaly.Type, "operation", operation.GetName())
+	return nil
+}
+
+// isRemediationDue returns false when a remediation of the anomaly type is pending or has been created within the
+// minimum interval of the remediations
+func isRemediationDue(ctx context.Context, c client.Client, cluster *v1beta1.KafkaCluster,
+	remediation v1beta1.CruiseControlAnomalyRemediation, now time.Time) (bool, error) {
+	var operations v1alpha1.CruiseControlOperationList
+	if err := c.List(ctx, &operations,
+		client.InNamespace(cluster.GetNamespace()),
+		client.MatchingLabels(apiutil.MergeLabels(apiutil.LabelsForKafka(cluster.GetName()),
+			map[string]string{v1alpha1.AnomalyTypeLabel: strings.ToLower(remediation.AnomalyType)})),
+	); err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not list the remediations of the anomaly", "anomalyType", remediation.AnomalyType)
+	}
+	minInterval := time.Duration(remediation.GetMinIntervalSeconds()) * time.Second
+	for i := range operations.Items {
+		operation := &operations.Items[i]
+		if !operation.IsDone() || now.Sub(operation.GetCreationTimestamp().Time) < minInterval {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,165 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ccanomaly
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestParseAnomaly(t *testing.T) {
+	anomaly, err := parseAnomaly("BROKER_FAILURE detected {Broker 2 failed at 2023-05-01T10:00:00Z}. " +
+		"Self healing start time 2023-05-01T10:30:00Z.\nSelf-healing has been triggered.")
+	require.NoError(t, err)
+	assert.Equal(t, Anomaly{
+		Type:                 "BROKER_FAILURE",
+		Description:          "{Broker 2 failed at 2023-05-01T10:00:00Z}",
+		SelfHealingTriggered: true,
+	}, anomaly)
+
+	anomaly, err = parseAnomaly("GOAL_VIOLATION detected {Unfixable goal violations: {RackAwareGoal}}. Self healing is disabled.")
+	require.NoError(t, err)
+	assert.Equal(t, Anomaly{Type: "GOAL_VIOLATION", Description: "{Unfixable goal violations: {RackAwareGoal}}"}, anomaly)
+
+	_, err = parseAnomaly("UNKNOWN detected something. Self healing is disabled.")
+	assert.Error(t, err)
+	_, err = parseAnomaly("hello")
+	assert.Error(t, err)
+}
+
+func newTestCluster(remediations ...v1beta1.CruiseControlAnomalyRemediation) *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", UID: "uid"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				AnomalyNotifier: &v1beta1.CruiseControlAnomalyNotifier{
+					ReceiverURL:  "http://kafka-operator-alertmanager.kafka.svc:9001",
+					Remediations: remediations,
+				},
+			},
+		},
+	}
+}
+
+func newTestClient(t *testing.T, objects ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+func TestProcessAnomaly(t *testing.T) {
+	cluster := newTestCluster(v1beta1.CruiseControlAnomalyRemediation{AnomalyType: "GOAL_VIOLATION", Operation: "rebalance"})
+	c := newTestClient(t, cluster)
+	recorder := record.NewFakeRecorder(10)
+	ctx := context.Background()
+	now := time.Now()
+
+	// anomalies without remediation are only recorded
+	err := processAnomaly(ctx, logr.Discard(), c, recorder, cluster, Anomaly{Type: "METRIC_ANOMALY", Description: "slow broker"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "Warning CruiseControlAnomalyDetected METRIC_ANOMALY detected by Cruise Control: slow broker", <-recorder.Events)
+
+	anomaly := Anomaly{Type: "GOAL_VIOLATION", Description: "{RackAwareGoal}"}
+	require.NoError(t, processAnomaly(ctx, logr.Discard(), c, recorder, cluster, anomaly, now))
+	<-recorder.Events
+	assert.True(t, strings.HasPrefix(<-recorder.Events, "Normal CruiseControlAnomalyRemediationCreated rebalance operation"))
+
+	var operations v1alpha1.CruiseControlOperationList
+	require.NoError(t, c.List(ctx, &operations))
+	require.Len(t, operations.Items, 1)
+	assert.Equal(t, "goal_violation", operations.Items[0].GetLabels()[v1alpha1.AnomalyTypeLabel])
+	assert.Equal(t, v1alpha1.OperationRebalance, operations.Items[0].CurrentTaskOperation())
+
+	// the pending remediation is not duplicated
+	require.NoError(t, processAnomaly(ctx, logr.Discard(), c, recorder, cluster, anomaly, now))
+	<-recorder.Events
+	require.NoError(t, c.List(ctx, &operations))
+	assert.Len(t, operations.Items, 1)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestIsRemediationDue(t *testing.T) {
+	cluster := newTestCluster()
+	now := time.Now()
+	remediation := v1beta1.CruiseControlAnomalyRemediation{AnomalyType: "DISK_FAILURE", Operation: "fix_offline_replicas"}
+	finished := &v1alpha1.CruiseControlOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "kafka-fixofflinereplicas-abcde",
+			Namespace:         "kafka",
+			CreationTimestamp: metav1.NewTime(now.Add(-30 * time.Minute)),
+			Labels:            map[string]string{"app": "kafka", "kafka_cr": "kafka", v1alpha1.AnomalyTypeLabel: "disk_failure"},
+		},
+		Status: v1alpha1.CruiseControlOperationStatus{
+			CurrentTask: &v1alpha1.CruiseControlTask{Operation: v1alpha1.OperationFixOfflineReplicas, State: v1beta1.CruiseControlTaskCompleted},
+		},
+	}
+	c := newTestClient(t, finished)
+
+	due, err := isRemediationDue(context.Background(), c, cluster, remediation, now)
+	require.NoError(t, err)
+	assert.False(t, due)
+
+	due, err = isRemediationDue(context.Background(), c, cluster, remediation, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, due)
+}
+
+func TestReceiveAnomaly(t *testing.T) {
+	cluster := newTestCluster()
+	cluster.SetName("notifying")
+	silent := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "silent", Namespace: "kafka"}}
+	recorder := record.NewFakeRecorder(10)
+	handler := NewHTTPHandler(logr.Discard(), newTestClient(t, cluster, silent), recorder)
+
+	testCases := []struct {
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{method: http.MethodPost, path: "/cruisecontrol/anomalies/kafka/notifying",
+			body: `{"text":"DISK_FAILURE detected {Disk /kafka-logs failed}. Self healing is disabled."}`, expected: http.StatusOK},
+		{method: http.MethodGet, path: "/cruisecontrol/anomalies/kafka/notifying", expected: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, path: "/cruisecontrol/anomalies/kafka", body: `{}`, expected: http.StatusNotFound},
+		{method: http.MethodPost, path: "/cruisecontrol/anomalies/kafka/notifying", body: `{"text":"hello"}`, expected: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/cruisecontrol/anomalies/kafka/silent",
+			body: `{"text":"DISK_FAILURE detected {Disk /kafka-logs failed}. Self healing is disabled."}`, expected: http.StatusNotFound},
+		{method: http.MethodPost, path: "/cruisecontrol/anomalies/kafka/missing",
+			body: `{"text":"DISK_FAILURE detected {Disk /kafka-logs failed}. Self healing is disabled."}`, expected: http.StatusNotFound},
+	}
+	for _, testCase := range testCases {
+		recorded := httptest.NewRecorder()
+		handler.ServeHTTP(recorded, httptest.NewRequest(testCase.method, testCase.path, strings.NewReader(testCase.body)))
+		assert.Equal(t, testCase.expected, recorded.Code, testCase.path)
+	}
+	assert.Len(t, recorder.Events, 1)
+}
@@ -0,0 +1,118 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ccanomaly
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// APIEndPoint is the path the anomalies of the Kafka clusters are posted to
+const APIEndPoint = v1beta1.CruiseControlAnomalyReceiverPath
+
+// slackMessage is the message sent by the Slack notifier of Cruise Control
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// HTTPController receives the anomalies of the Kafka clusters reported by Cruise Control
+type HTTPController struct {
+	Logger   logr.Logger
+	Client   client.Client
+	Recorder record.EventRecorder
+}
+
+// NewHTTPHandler returns a new HTTP handler for the anomalies of the Kafka clusters
+func NewHTTPHandler(log logr.Logger, client client.Client, recorder record.EventRecorder) http.Handler {
+	controller := &HTTPController{
+		Logger:   log,
+		Client:   client,
+		Recorder: recorder,
+	}
+	return http.HandlerFunc(controller.receiveAnomaly)
+}
+
+func (a *HTTPController) receiveAnomaly(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+	clusterName, ok := clusterFromPath(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	log := a.Logger.WithValues("namespace", clusterName.Namespace, "kafkaCluster", clusterName.Name)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "reading request body failed", http.StatusInternalServerError)
+		return
+	}
+	var message slackMessage
+	if err = json.Unmarshal(body, &message); err != nil {
+		http.Error(w, "invalid anomaly notification", http.StatusBadRequest)
+		return
+	}
+	anomaly, err := parseAnomaly(message.Text)
+	if err != nil {
+		log.Error(err, "could not parse the anomaly notification of Cruise Control")
+		http.Error(w, "invalid anomaly notification", http.StatusBadRequest)
+		return
+	}
+
+	cluster := &v1beta1.KafkaCluster{}
+	if err = a.Client.Get(r.Context(), clusterName, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			http.NotFound(w, r)
+			return
+		}
+		log.Error(err, "could not get the Kafka cluster of the anomaly")
+		http.Error(w, "could not get the Kafka cluster", http.StatusInternalServerError)
+		return
+	}
+	// Only the Kafka clusters which are configured to report their anomalies are accepted
+	if cluster.Spec.CruiseControlConfig.AnomalyNotifier == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err = processAnomaly(r.Context(), log, a.Client, a.Recorder, cluster, anomaly, time.Now()); err != nil {
+		log.Error(err, "could not process the anomaly detected by Cruise Control")
+		http.Error(w, "could not process the anomaly", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// clusterFromPath returns the namespace and the name of the Kafka cluster from the path of the request
+func clusterFromPath(path string) (types.NamespacedName, bool) {
+	parts := strings.Split(strings.TrimPrefix(path, APIEndPoint), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, true
+}
@@ -316,10 +316,10 @@ func downScale(ctx context.Context, log logr.Logger, labels model.LabelSet, clie
 	} else {
 		cruiseControlURL := scale.CruiseControlURLFromKafkaCluster(cr)
 		// FIXME: we should reuse the context of passed to AController.Start() here
-		cc, err := scale.NewCruiseControlScaler(context.TODO(), cruiseControlURL)
+		cc, err := scale.NewCruiseControlScalerForCluster(context.TODO(), client, cr)
 		if err != nil {
 			return errors.WrapIfWithDetails(err, "failed to initialize Cruise Control Scaler",
-				"cruise control url", cruiseControlURL)
+				"cruise control url", scale.Redact(cruiseControlURL))
 		}
 		brokerID, err = cc.BrokerWithLeastPartitionReplicas(ctx)
 		if err != nil {
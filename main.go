@@ -32,16 +32,20 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	istioclientv1beta1 "github.com/banzaicloud/istio-client-go/pkg/networking/v1beta1"
 
 	banzaiistiov1alpha1 "github.com/banzaicloud/istio-operator/api/v2/v1alpha1"
 
 	certv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -52,6 +56,7 @@ import (
 	"github.com/banzaicloud/koperator/controllers"
 	"github.com/banzaicloud/koperator/pkg/k8sutil"
 	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/kafkaconnect"
 	"github.com/banzaicloud/koperator/pkg/scale"
 	"github.com/banzaicloud/koperator/pkg/util"
 	"github.com/banzaicloud/koperator/pkg/webhooks"
@@ -79,7 +84,11 @@ func init() {
 func main() {
 	var (
 		namespaces                        string
+		watchLabelSelector                string
+		leaderElectionID                  string
+		leaderElectionNamespace           string
 		metricsAddr                       string
+		healthProbeAddr                   string
 		enableLeaderElection              bool
 		webhookCertDir                    string
 		webhookDisabled                   bool
@@ -89,12 +98,22 @@ func main() {
 		certSigningDisabled               bool
 		certManagerEnabled                bool
 		maxKafkaTopicConcurrentReconciles int
+		partitionScalingEnabled           bool
+		finalizersDisabled                bool
+		finalizerReleaseTimeout           time.Duration
+		failedTasksHistoryMaxLength       int
+		failedTasksHistoryMaxAge          time.Duration
 	)
 
 	flag.StringVar(&namespaces, "namespaces", "", "Comma separated list of namespaces where operator listens for resources")
+	flag.StringVar(&watchLabelSelector, "watch-label-selector", "", "Label selector of the KafkaClusters which are reconciled by the operator together with the resources belonging to them")
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&healthProbeAddr, "health-probe-addr", ":8081", "The address the health probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", "controller-leader-election-helper",
+		"Name of the resource used for leader election. Operator instances watching disjoint sets of resources need to use different names.")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "", "Namespace of the resource used for leader election, it defaults to the namespace of the operator")
 	flag.BoolVar(&webhookDisabled, "disable-webhooks", false, "Disable webhooks used to validate custom resources")
 	flag.StringVar(&webhookCertDir, "tls-cert-dir", "/etc/webhook/certs", "The directory with a tls.key and tls.crt for serving HTTPS requests")
 	flag.IntVar(&webhookServerPort, "webhook-server-port", 443, "The port that the webhook server serves at")
@@ -103,6 +122,11 @@ func main() {
 	flag.BoolVar(&certManagerEnabled, "cert-manager-enabled", false, "Enable cert-manager integration")
 	flag.BoolVar(&certSigningDisabled, "disable-cert-signing-support", false, "Disable native certificate signing integration")
 	flag.IntVar(&maxKafkaTopicConcurrentReconciles, "max-kafka-topic-concurrent-reconciles", 10, "Define max amount of concurrent KafkaTopic reconciles")
+	flag.BoolVar(&partitionScalingEnabled, "enable-partition-scaling", false, "Enable consumer lag based partition count recommendations for the KafkaTopics with a partition scaling policy")
+	flag.BoolVar(&finalizersDisabled, "disable-finalizers", false, "Disable adding finalizers to KafkaTopics, KafkaUsers and CruiseControlOperations, their cleanup is skipped on deletion")
+	flag.DurationVar(&finalizerReleaseTimeout, "finalizer-release-timeout", 0, "Remove the finalizers of KafkaTopics, KafkaUsers and CruiseControlOperations which are terminating for longer than this duration without cleanup, 0 disables the forced release")
+	flag.IntVar(&failedTasksHistoryMaxLength, "failed-tasks-history-max-length", 50, "Maximum number of failed tasks kept in the status of the CruiseControlOperations, 0 keeps the full history")
+	flag.DurationVar(&failedTasksHistoryMaxAge, "failed-tasks-history-max-age", 0, "Drop the failed tasks from the status of the CruiseControlOperations which finished longer ago than this duration, 0 disables the pruning by age")
 	flag.Parse()
 	ctrl.SetLogger(util.CreateLogger(verboseLogging, developmentLogging))
 
@@ -124,14 +148,39 @@ func main() {
 		managerWatchCacheBuilder = cache.MultiNamespacedCacheBuilder(namespaceList)
 	}
 
+	if watchLabelSelector != "" {
+		selector, err := labels.Parse(watchLabelSelector)
+		if err != nil {
+			setupLog.Error(err, "invalid watch label selector", "selector", watchLabelSelector)
+			os.Exit(1)
+		}
+		controllers.SetWatchLabelSelector(selector)
+	}
+
+	shardIndex, shardCount, err := controllers.ShardFromEnv(os.Getenv)
+	if err == nil {
+		err = controllers.SetShard(shardIndex, shardCount)
+	}
+	if err != nil {
+		setupLog.Error(err, "invalid sharding configuration")
+		os.Exit(1)
+	}
+	if shardCount > 1 {
+		// Every shard elects its own leader so that the shards are reconciled in parallel
+		leaderElectionID = fmt.Sprintf("%s-shard-%d", leaderElectionID, shardIndex)
+		setupLog.Info("reconciling a shard of the KafkaClusters", "shardIndex", shardIndex, "shardCount", shardCount)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:             scheme,
-		MetricsBindAddress: metricsAddr,
-		LeaderElection:     enableLeaderElection,
-		LeaderElectionID:   "controller-leader-election-helper",
-		NewCache:           managerWatchCacheBuilder,
-		Port:               webhookServerPort,
-		CertDir:            webhookCertDir,
+		Scheme:                  scheme,
+		MetricsBindAddress:      metricsAddr,
+		HealthProbeBindAddress:  healthProbeAddr,
+		LeaderElection:          enableLeaderElection,
+		LeaderElectionID:        leaderElectionID,
+		LeaderElectionNamespace: leaderElectionNamespace,
+		NewCache:                managerWatchCacheBuilder,
+		Port:                    webhookServerPort,
+		CertDir:                 webhookCertDir,
 	})
 
 	if err != nil {
@@ -154,6 +203,7 @@ func main() {
 		DirectClient:        mgr.GetAPIReader(),
 		Namespaces:          namespaceList,
 		KafkaClientProvider: kafkaclient.NewDefaultProvider(),
+		Recorder:            mgr.GetEventRecorderFor("kafkacluster-controller"),
 	}
 
 	if err = controllers.SetupKafkaClusterWithManager(mgr).Complete(kafkaClusterReconciler); err != nil {
@@ -161,9 +211,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	finalizerPolicy := controllers.FinalizerPolicy{
+		SkipFinalizers: finalizersDisabled,
+		ReleaseTimeout: finalizerReleaseTimeout,
+	}
+
 	kafkaTopicReconciler := &controllers.KafkaTopicReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		FinalizerPolicy: finalizerPolicy,
 	}
 
 	if err = controllers.SetupKafkaTopicWithManager(mgr, maxKafkaTopicConcurrentReconciles).Complete(kafkaTopicReconciler); err != nil {
@@ -171,10 +227,24 @@ func main() {
 		os.Exit(1)
 	}
 
+	if partitionScalingEnabled {
+		kafkaTopicPartitionScalingReconciler := &controllers.KafkaTopicPartitionScalingReconciler{
+			Client:              mgr.GetClient(),
+			Scheme:              mgr.GetScheme(),
+			KafkaClientProvider: kafkaclient.NewDefaultProvider(),
+		}
+
+		if err = controllers.SetupKafkaTopicPartitionScalingWithManager(mgr).Complete(kafkaTopicPartitionScalingReconciler); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "KafkaTopicPartitionScaling")
+			os.Exit(1)
+		}
+	}
+
 	// Create a new  kafka user reconciler
 	kafkaUserReconciler := &controllers.KafkaUserReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		FinalizerPolicy: finalizerPolicy,
 	}
 
 	if err = controllers.SetupKafkaUserWithManager(mgr, !certSigningDisabled, certManagerEnabled).Complete(kafkaUserReconciler); err != nil {
@@ -186,7 +256,7 @@ func main() {
 		Client:       mgr.GetClient(),
 		DirectClient: mgr.GetAPIReader(),
 		Scheme:       mgr.GetScheme(),
-		ScaleFactory: scale.ScaleFactoryFn(),
+		ScaleFactory: scale.ScaleFactoryFn(mgr.GetClient()),
 	}
 
 	if err = controllers.SetupCruiseControlWithManager(mgr).Complete(kafkaClusterCCReconciler); err != nil {
@@ -194,11 +264,27 @@ func main() {
 		os.Exit(1)
 	}
 
+	var leaseFence *controllers.LeaseFence
+	if enableLeaderElection {
+		if leaseFence, err = controllers.NewLeaseFence(mgr.GetAPIReader(), leaderElectionNamespace, leaderElectionID); err != nil {
+			setupLog.Error(err, "unable to set up the fencing of the Cruise Control task submissions")
+			os.Exit(1)
+		}
+	}
+
 	cruiseControlOperationReconciler := controllers.CruiseControlOperationReconciler{
-		Client:       mgr.GetClient(),
-		DirectClient: mgr.GetAPIReader(),
-		Scheme:       mgr.GetScheme(),
-		ScaleFactory: scale.ScaleFactoryFn(),
+		Client:          mgr.GetClient(),
+		DirectClient:    mgr.GetAPIReader(),
+		Scheme:          mgr.GetScheme(),
+		ScaleFactory:    scale.ScaleFactoryFn(mgr.GetClient()),
+		Recorder:        mgr.GetEventRecorderFor("cruisecontroloperation-controller"),
+		FinalizerPolicy: finalizerPolicy,
+		LeaseFence:      leaseFence,
+		FailedTasksHistory: banzaicloudv1beta1.FailedTasksHistoryLimits{
+			MaxLength:     util.Int32Pointer(int32(failedTasksHistoryMaxLength)),
+			MaxAgeSeconds: util.Int64Pointer(int64(failedTasksHistoryMaxAge.Seconds())),
+		},
+		KafkaClientProvider: kafkaclient.NewDefaultProvider(),
 	}
 
 	if err = controllers.SetupCruiseControlOperationWithManager(mgr).Complete(&cruiseControlOperationReconciler); err != nil {
@@ -216,10 +302,82 @@ func main() {
 		os.Exit(1)
 	}
 
+	cruiseControlAdminReconciler := controllers.CruiseControlAdminReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		ScaleFactory: scale.ScaleFactoryFn(mgr.GetClient()),
+	}
+
+	if err = controllers.SetupCruiseControlAdminWithManager(mgr).Complete(&cruiseControlAdminReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CruiseControlAdmin")
+		os.Exit(1)
+	}
+
+	kafkaBrokerDecommissionReconciler := controllers.KafkaBrokerDecommissionReconciler{
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		KafkaClientProvider: kafkaclient.NewDefaultProvider(),
+	}
+
+	if err = controllers.SetupKafkaBrokerDecommissionWithManager(mgr).Complete(&kafkaBrokerDecommissionReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaBrokerDecommission")
+		os.Exit(1)
+	}
+
+	podEvictor, err := k8sutil.NewPodEvictor(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create pod evictor")
+		os.Exit(1)
+	}
+	kafkaBrokerReplacementReconciler := controllers.KafkaBrokerReplacementReconciler{
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		ScaleFactory:        scale.ScaleFactoryFn(mgr.GetClient()),
+		KafkaClientProvider: kafkaclient.NewDefaultProvider(),
+		PodEvictor:          podEvictor,
+	}
+
+	if err = controllers.SetupKafkaBrokerReplacementWithManager(mgr).Complete(&kafkaBrokerReplacementReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaBrokerReplacement")
+		os.Exit(1)
+	}
+
+	kafkaConnectReconciler := controllers.KafkaConnectReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupKafkaConnectWithManager(mgr).Complete(&kafkaConnectReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaConnect")
+		os.Exit(1)
+	}
+
+	kafkaConnectorReconciler := controllers.KafkaConnectorReconciler{
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		ConnectClientFactory: kafkaconnect.NewClient,
+	}
+
+	if err = controllers.SetupKafkaConnectorWithManager(mgr).Complete(&kafkaConnectorReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KafkaConnector")
+		os.Exit(1)
+	}
+
+	schemaRegistryReconciler := controllers.SchemaRegistryReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err = controllers.SetupSchemaRegistryWithManager(mgr).Complete(&schemaRegistryReconciler); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SchemaRegistry")
+		os.Exit(1)
+	}
+
 	if !webhookDisabled {
 		err = ctrl.NewWebhookManagedBy(mgr).For(&banzaicloudv1beta1.KafkaCluster{}).
 			WithValidator(webhooks.KafkaClusterValidator{
-				Log: mgr.GetLogger().WithName("webhooks").WithName("KafkaCluster"),
+				Client: mgr.GetClient(),
+				Log:    mgr.GetLogger().WithName("webhooks").WithName("KafkaCluster"),
 			}).
 			Complete()
 		if err != nil {
@@ -237,10 +395,40 @@ func main() {
 			setupLog.Error(err, "unable to create validating webhook", "Kind", "KafkaTopic")
 			os.Exit(1)
 		}
+		operatorUsername := webhooks.OperatorUsernameFromEnv(os.Getenv)
+		if operatorUsername == "" {
+			setupLog.Info("the service account of the operator is not set by the " + webhooks.PodNamespaceEnvVar + " and " +
+				webhooks.PodServiceAccountEnvVar + " environment variables, the CruiseControlOperations of the operator are subject to the quotas")
+		}
+		err = ctrl.NewWebhookManagedBy(mgr).For(&banzaicloudv1alpha1.CruiseControlOperation{}).
+			WithValidator(webhooks.CruiseControlOperationValidator{
+				Client:           mgr.GetClient(),
+				Log:              mgr.GetLogger().WithName("webhooks").WithName("CruiseControlOperation"),
+				OperatorUsername: operatorUsername,
+			}).
+			Complete()
+		if err != nil {
+			setupLog.Error(err, "unable to create validating webhook", "Kind", "CruiseControlOperation")
+			os.Exit(1)
+		}
 	}
 
 	// +kubebuilder:scaffold:builder
 
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+	// Per Kafka cluster backlog for monitoring many clusters, the same is exported as metrics as well
+	if err := mgr.AddMetricsExtraHandler("/clusters", controllers.ClusterBacklogHandler()); err != nil {
+		setupLog.Error(err, "unable to set up cluster backlog endpoint")
+		os.Exit(1)
+	}
+
 	if err := k8sutil.AddKafkaTopicIndexers(ctx, mgr.GetCache()); err != nil {
 		setupLog.Error(err, "unable to add indexers to manager's cache")
 		os.Exit(1)
@@ -0,0 +1,402 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ccoperation provides typed builders for creating well-formed CruiseControlOperations.
+//
+//	operation, err := ccoperation.NewRebalance().
+//		ForCluster(kafkaCluster).
+//		ForBrokers(1, 2).
+//		WithErrorPolicy(v1alpha1.ErrorPolicyIgnore).
+//		Create(ctx, client)
+package ccoperation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+// Builder builds a CruiseControlOperation. The methods can be chained, errors are reported by Build and Create.
+type Builder struct {
+	operationType    v1alpha1.CruiseControlTaskOperation
+	clusterName      string
+	clusterNamespace string
+	name             string
+	labels           map[string]string
+	annotations      map[string]string
+	spec             v1alpha1.CruiseControlOperationSpec
+	parameters       map[string]string
+	typedParameters  v1alpha1.CruiseControlTaskParameters
+	owner            metav1.Object
+	scheme           *runtime.Scheme
+	// exclusions and brokers of the Kafka cluster set by ForCluster
+	movementExclusions []v1beta1.CruiseControlMovementExclusion
+	clusterBrokerIDs   []int32
+	// removal destination policy of the Kafka cluster and the state of its brokers set by ForCluster
+	removalDestinations *v1beta1.BrokerRemovalDestinationPolicy
+	brokerZones         map[int32]string
+	brokerDiskUsage     map[int32]float64
+}
+
+// New returns a Builder for the given Cruise Control operation type
+func New(operationType v1alpha1.CruiseControlTaskOperation) *Builder {
+	return &Builder{
+		operationType: operationType,
+	}
+}
+
+// NewRebalance returns a Builder for a rebalance operation
+func NewRebalance() *Builder {
+	return New(v1alpha1.OperationRebalance)
+}
+
+// NewAddBroker returns a Builder for an add_broker operation of the given brokers
+func NewAddBroker(brokerIDs ...int32) *Builder {
+	return New(v1alpha1.OperationAddBroker).ForBrokers(brokerIDs...)
+}
+
+// NewRemoveBroker returns a Builder for a remove_broker operation of the given brokers
+func NewRemoveBroker(brokerIDs ...int32) *Builder {
+	return New(v1alpha1.OperationRemoveBroker).ForBrokers(brokerIDs...)
+}
+
+// NewDemoteBroker returns a Builder for a demote_broker operation of the given brokers
+func NewDemoteBroker(brokerIDs ...int32) *Builder {
+	return New(v1alpha1.OperationDemoteBroker).ForBrokers(brokerIDs...)
+}
+
+// NewPreferredLeaderElection returns a Builder for a preferred_leader_election operation
+func NewPreferredLeaderElection() *Builder {
+	return New(v1alpha1.OperationPreferredLeaderElection)
+}
+
+// NewFixOfflineReplicas returns a Builder for a fix_offline_replicas operation
+func NewFixOfflineReplicas() *Builder {
+	return New(v1alpha1.OperationFixOfflineReplicas)
+}
+
+// NewStopExecution returns a Builder for a stop_proposal_execution operation
+func NewStopExecution() *Builder {
+	return New(v1alpha1.OperationStopExecution)
+}
+
+// ForCluster sets the Kafka cluster the operation is executed on. The movement exclusions and the broker removal
+// destination policy of the Kafka cluster are applied to the parameters of the operation.
+func (b *Builder) ForCluster(kafkaCluster *v1beta1.KafkaCluster) *Builder {
+	b.movementExclusions = kafkaCluster.Spec.CruiseControlConfig.CruiseControlOperationSpec.GetMovementExclusions()
+	b.clusterBrokerIDs = make([]int32, 0, len(kafkaCluster.Spec.Brokers))
+	for _, broker := range kafkaCluster.Spec.Brokers {
+		b.clusterBrokerIDs = append(b.clusterBrokerIDs, broker.Id)
+	}
+	b.removalDestinations = kafkaCluster.Spec.CruiseControlConfig.CruiseControlOperationSpec.GetBrokerRemovalDestinations()
+	if b.removalDestinations != nil {
+		b.brokerZones = brokerZones(kafkaCluster)
+		b.brokerDiskUsage = brokerDiskUsage(kafkaCluster)
+	}
+	return b.ForClusterRef(kafkaCluster.GetNamespace(), kafkaCluster.GetName())
+}
+
+// ForClusterRef sets the namespace and the name of the Kafka cluster the operation is executed on.
+// The operation is created in the namespace of the Kafka cluster.
+func (b *Builder) ForClusterRef(namespace, name string) *Builder {
+	b.clusterNamespace = namespace
+	b.clusterName = name
+	return b
+}
+
+// ForBrokers sets the brokers of the operation. These are the added, removed or demoted brokers of the add_broker,
+// remove_broker and demote_broker operations, and the destination brokers of the rebalance operation.
+func (b *Builder) ForBrokers(brokerIDs ...int32) *Builder {
+	if b.operationType == v1alpha1.OperationRebalance {
+		b.typedParameters.DestinationBrokerIDs = brokerIDs
+	} else {
+		b.typedParameters.BrokerIDs = brokerIDs
+	}
+	return b
+}
+
+// WithDestinationBrokers sets the brokers which the partition replicas can be moved to
+func (b *Builder) WithDestinationBrokers(brokerIDs ...int32) *Builder {
+	b.typedParameters.DestinationBrokerIDs = brokerIDs
+	return b
+}
+
+// WithName sets the name of the operation, by default the name is generated from the cluster name and the operation type
+func (b *Builder) WithName(name string) *Builder {
+	b.name = name
+	return b
+}
+
+// WithLabels adds labels to the operation
+func (b *Builder) WithLabels(labels map[string]string) *Builder {
+	b.labels = apiutil.MergeLabels(b.labels, labels)
+	return b
+}
+
+// WithAnnotations adds annotations to the operation
+func (b *Builder) WithAnnotations(annotations map[string]string) *Builder {
+	b.annotations = util.MergeAnnotations(b.annotations, annotations)
+	return b
+}
+
+// WithErrorPolicy sets how the failed Cruise Control task is handled
+func (b *Builder) WithErrorPolicy(errorPolicy v1alpha1.ErrorPolicyType) *Builder {
+	b.spec.ErrorPolicy = errorPolicy
+	return b
+}
+
+// WithMaxRetries sets how many times the failed Cruise Control task is re-executed with the retryN error policy
+func (b *Builder) WithMaxRetries(maxRetries int) *Builder {
+	b.spec.MaxRetries = &maxRetries
+	return b
+}
+
+// WithTTLSecondsAfterFinished sets the time after the finished operation is deleted
+func (b *Builder) WithTTLSecondsAfterFinished(ttlSecondsAfterFinished int) *Builder {
+	b.spec.TTLSecondsAfterFinished = &ttlSecondsAfterFinished
+	return b
+}
+
+// WithSchedulingGroup sets the scheduling group of the operation
+func (b *Builder) WithSchedulingGroup(group string) *Builder {
+	b.spec.SchedulingGroup = group
+	return b
+}
+
+// WithConcurrencyBoost sets the concurrency boost of the operation
+func (b *Builder) WithConcurrencyBoost(boost *v1alpha1.ConcurrencyBoost) *Builder {
+	b.spec.ConcurrencyBoost = boost
+	return b
+}
+
+// WithGoals sets the Cruise Control goals used for the optimization
+func (b *Builder) WithGoals(goals ...string) *Builder {
+	b.typedParameters.Goals = goals
+	return b
+}
+
+// WithExcludedTopics sets the regular expression matching the topics whose partition replicas are not moved
+func (b *Builder) WithExcludedTopics(excludedTopics string) *Builder {
+	b.typedParameters.ExcludedTopics = excludedTopics
+	return b
+}
+
+// WithConcurrency sets the concurrent partition movements per broker and the concurrent leader movements.
+// Zero values are left unset.
+func (b *Builder) WithConcurrency(partitionMovementsPerBroker, leaderMovements int32) *Builder {
+	if partitionMovementsPerBroker > 0 {
+		b.typedParameters.ConcurrentPartitionMovementsPerBroker = &partitionMovementsPerBroker
+	}
+	if leaderMovements > 0 {
+		b.typedParameters.ConcurrentLeaderMovements = &leaderMovements
+	}
+	return b
+}
+
+// DryRun makes Cruise Control only calculate the proposal of the operation
+func (b *Builder) DryRun() *Builder {
+	b.typedParameters.DryRun = util.BoolPointer(true)
+	return b
+}
+
+// WithRebalanceDisk makes the rebalance operation balance the load between the disks of the brokers
+func (b *Builder) WithRebalanceDisk() *Builder {
+	b.typedParameters.RebalanceDisk = util.BoolPointer(true)
+	return b
+}
+
+// ExcludeRecentlyChangedBrokers excludes the recently demoted and removed brokers from receiving leadership and replicas
+func (b *Builder) ExcludeRecentlyChangedBrokers() *Builder {
+	b.typedParameters.ExcludeRecentlyDemotedBrokers = util.BoolPointer(true)
+	b.typedParameters.ExcludeRecentlyRemovedBrokers = util.BoolPointer(true)
+	return b
+}
+
+// SkipHardGoalCheck allows Cruise Control to use goals which do not include all the configured hard goals
+func (b *Builder) SkipHardGoalCheck() *Builder {
+	b.typedParameters.SkipHardGoalCheck = util.BoolPointer(true)
+	return b
+}
+
+// WithParameter sets a raw Cruise Control REST API parameter which is not covered by the typed parameters
+func (b *Builder) WithParameter(name, value string) *Builder {
+	if b.parameters == nil {
+		b.parameters = make(map[string]string)
+	}
+	b.parameters[name] = value
+	return b
+}
+
+// OwnedBy sets the owner as the controller of the operation so that it is garbage collected with the owner
+func (b *Builder) OwnedBy(owner metav1.Object, scheme *runtime.Scheme) *Builder {
+	b.owner = owner
+	b.scheme = scheme
+	return b
+}
+
+// Build validates and returns the CruiseControlOperation. The current task is part of the status of the returned
+// operation which needs to be updated separately after the operation has been created, see Create.
+func (b *Builder) Build() (*v1alpha1.CruiseControlOperation, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	typedParameters := b.typedParameters.DeepCopy()
+	if err := b.applyRemovalDestinations(typedParameters); err != nil {
+		return nil, err
+	}
+	if err := b.applyMovementExclusions(typedParameters); err != nil {
+		return nil, err
+	}
+
+	operation := &v1alpha1.CruiseControlOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.name,
+			Namespace:   b.clusterNamespace,
+			Labels:      apiutil.MergeLabels(b.labels, apiutil.LabelsForKafka(b.clusterName)),
+			Annotations: b.annotations,
+		},
+		Spec: *b.spec.DeepCopy(),
+	}
+	if b.name == "" {
+		operation.SetGenerateName(fmt.Sprintf("%s-%s-", b.clusterName, strings.ReplaceAll(string(b.operationType), "_", "")))
+	}
+
+	if b.owner != nil {
+		if err := controllerutil.SetControllerReference(b.owner, operation, b.scheme); err != nil {
+			return nil, errors.WrapIf(err, "could not set the owner of the CruiseControlOperation")
+		}
+	}
+
+	task := &v1alpha1.CruiseControlTask{
+		Operation: b.operationType,
+	}
+	if len(b.parameters) > 0 {
+		task.Parameters = make(map[string]string, len(b.parameters))
+		for name, value := range b.parameters {
+			task.Parameters[name] = value
+		}
+	}
+	if len(typedParameters.ToParameters()) > 0 {
+		task.TypedParameters = typedParameters
+	}
+	operation.Status.CurrentTask = task
+
+	return operation, nil
+}
+
+// Create creates the CruiseControlOperation and then sets its current task through the status subresource.
+func (b *Builder) Create(ctx context.Context, c client.Client) (*v1alpha1.CruiseControlOperation, error) {
+	operation, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	status := operation.Status.DeepCopy()
+	if err := c.Create(ctx, operation); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not create CruiseControlOperation", "kafkaCluster", b.clusterName)
+	}
+	operation.Status = *status
+	if err := c.Status().Update(ctx, operation); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not set the current task of CruiseControlOperation", "name", operation.GetName())
+	}
+	return operation, nil
+}
+
+// applyMovementExclusions adds the topics excluded by the Kafka cluster to the excluded topics of the operation
+// and removes the excluded brokers from the destination brokers of the rebalance and remove_broker operations
+func (b *Builder) applyMovementExclusions(params *v1alpha1.CruiseControlTaskParameters) error {
+	var excludedTopics []string
+	excludedBrokers := make(map[int32]struct{})
+	for _, exclusion := range b.movementExclusions {
+		if !exclusion.AppliesTo(string(b.operationType)) {
+			continue
+		}
+		excludedTopics = append(excludedTopics, exclusion.Topics...)
+		for _, brokerID := range exclusion.BrokerIDs {
+			excludedBrokers[brokerID] = struct{}{}
+		}
+	}
+
+	if len(excludedTopics) > 0 {
+		if params.ExcludedTopics == "" {
+			params.ExcludedTopics = b.parameters[v1alpha1.ParamExcludedTopics]
+		}
+		if params.ExcludedTopics != "" {
+			excludedTopics = append([]string{params.ExcludedTopics}, excludedTopics...)
+		}
+		params.ExcludedTopics = strings.Join(excludedTopics, "|")
+	}
+
+	if len(excludedBrokers) == 0 ||
+		(b.operationType != v1alpha1.OperationRebalance && b.operationType != v1alpha1.OperationRemoveBroker) {
+		return nil
+	}
+	destinations := params.DestinationBrokerIDs
+	if len(destinations) == 0 {
+		destinations = b.clusterBrokerIDs
+	}
+	if b.operationType == v1alpha1.OperationRemoveBroker {
+		for _, brokerID := range params.BrokerIDs {
+			excludedBrokers[brokerID] = struct{}{}
+		}
+	}
+	allowed := make([]int32, 0, len(destinations))
+	for _, brokerID := range destinations {
+		if _, ok := excludedBrokers[brokerID]; !ok {
+			allowed = append(allowed, brokerID)
+		}
+	}
+	if len(allowed) == 0 {
+		return errors.NewWithDetails("every destination broker of the operation is excluded from partition movements",
+			"operation", b.operationType)
+	}
+	params.DestinationBrokerIDs = allowed
+	return nil
+}
+
+func (b *Builder) validate() error {
+	if b.clusterName == "" || b.clusterNamespace == "" {
+		return errors.New("the Kafka cluster of the CruiseControlOperation must be set")
+	}
+	o := v1alpha1.CruiseControlOperation{Status: v1alpha1.CruiseControlOperationStatus{
+		CurrentTask: &v1alpha1.CruiseControlTask{Operation: b.operationType},
+	}}
+	if !o.IsCurrentTaskOperationValid() {
+		return errors.NewWithDetails("unsupported Cruise Control operation", "operation", b.operationType)
+	}
+	switch b.operationType {
+	case v1alpha1.OperationAddBroker, v1alpha1.OperationRemoveBroker, v1alpha1.OperationDemoteBroker:
+		if len(b.typedParameters.BrokerIDs) == 0 && b.parameters[v1alpha1.ParamBrokerID] == "" {
+			return errors.NewWithDetails("broker IDs must be set for the operation", "operation", b.operationType)
+		}
+	}
+	switch b.spec.ErrorPolicy {
+	case "", v1alpha1.ErrorPolicyIgnore, v1alpha1.ErrorPolicyRetry, v1alpha1.ErrorPolicyRetryN,
+		v1alpha1.ErrorPolicyIgnoreWithEvent, v1alpha1.ErrorPolicyManualAck:
+	default:
+		return errors.NewWithDetails("unsupported error policy", "errorPolicy", b.spec.ErrorPolicy)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ccoperation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestBuild(t *testing.T) {
+	kafkaCluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+	excludingCluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}, {Id: 3}},
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				CruiseControlOperationSpec: &v1beta1.CruiseControlOperationSpec{
+					MovementExclusions: []v1beta1.CruiseControlMovementExclusion{
+						{Topics: []string{".*-changelog"}, BrokerIDs: []int32{0}},
+						{Operations: []v1beta1.CruiseControlMovementOperation{v1beta1.MovementOperationRebalance}, Topics: []string{"audit"}},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		testName        string
+		builder         *Builder
+		expectedError   bool
+		expectedName    string
+		expectedGenName string
+		expectedParams  map[string]string
+	}{
+		{
+			testName:        "rebalance for destination brokers",
+			builder:         NewRebalance().ForCluster(kafkaCluster).ForBrokers(1, 2).WithRebalanceDisk(),
+			expectedGenName: "kafka-rebalance-",
+			expectedParams: map[string]string{
+				v1alpha1.ParamDestbrokerIDs: "1,2",
+				v1alpha1.ParamRebalanceDisk: "true",
+			},
+		},
+		{
+			testName:     "remove broker with name",
+			builder:      NewRemoveBroker(3).ForClusterRef("kafka", "kafka").WithName("remove-3").ExcludeRecentlyChangedBrokers(),
+			expectedName: "remove-3",
+			expectedParams: map[string]string{
+				v1alpha1.ParamBrokerID:       "3",
+				v1alpha1.ParamExcludeDemoted: "true",
+				v1alpha1.ParamExcludeRemoved: "true",
+			},
+		},
+		{
+			testName:        "demote brokers",
+			builder:         NewDemoteBroker(1, 2).ForCluster(kafkaCluster),
+			expectedGenName: "kafka-demotebroker-",
+			expectedParams: map[string]string{
+				v1alpha1.ParamBrokerID: "1,2",
+			},
+		},
+		{
+			testName:        "stop execution without parameters",
+			builder:         NewStopExecution().ForCluster(kafkaCluster),
+			expectedGenName: "kafka-stopproposalexecution-",
+		},
+		{
+			testName:        "rebalance with movement exclusions",
+			builder:         NewRebalance().ForCluster(excludingCluster).WithExcludedTopics("__.*"),
+			expectedGenName: "kafka-rebalance-",
+			expectedParams: map[string]string{
+				v1alpha1.ParamDestbrokerIDs:  "1,2,3",
+				v1alpha1.ParamExcludedTopics: "__.*|.*-changelog|audit",
+			},
+		},
+		{
+			testName:        "remove broker with movement exclusions",
+			builder:         NewRemoveBroker(3).ForCluster(excludingCluster),
+			expectedGenName: "kafka-removebroker-",
+			expectedParams: map[string]string{
+				v1alpha1.ParamBrokerID:       "3",
+				v1alpha1.ParamDestbrokerIDs:  "1,2",
+				v1alpha1.ParamExcludedTopics: ".*-changelog",
+			},
+		},
+		{
+			testName:        "demote broker is not affected by movement exclusions",
+			builder:         NewDemoteBroker(1).ForCluster(excludingCluster),
+			expectedGenName: "kafka-demotebroker-",
+			expectedParams: map[string]string{
+				v1alpha1.ParamBrokerID: "1",
+			},
+		},
+		{
+			testName:      "every destination broker excluded",
+			builder:       NewRebalance().ForCluster(excludingCluster).ForBrokers(0),
+			expectedError: true,
+		},
+		{
+			testName:      "missing cluster",
+			builder:       NewRebalance(),
+			expectedError: true,
+		},
+		{
+			testName:      "add broker without brokers",
+			builder:       NewAddBroker().ForCluster(kafkaCluster),
+			expectedError: true,
+		},
+		{
+			testName:      "unsupported operation",
+			builder:       New("rightsize").ForCluster(kafkaCluster),
+			expectedError: true,
+		},
+		{
+			testName:      "unsupported error policy",
+			builder:       NewRebalance().ForCluster(kafkaCluster).WithErrorPolicy("panic"),
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			operation, err := testCase.builder.Build()
+			if testCase.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedName, operation.GetName())
+			assert.Equal(t, testCase.expectedGenName, operation.GetGenerateName())
+			assert.Equal(t, "kafka", operation.GetNamespace())
+			assert.Equal(t, "kafka", operation.GetClusterRef())
+			assert.Equal(t, testCase.expectedParams, operation.CurrentTask().TypedParameters.ToParameters())
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	kafkaCluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", UID: "uid"}}
+	created, err := NewAddBroker(4, 5).
+		ForCluster(kafkaCluster).
+		WithName("add-brokers").
+		WithErrorPolicy(v1alpha1.ErrorPolicyIgnore).
+		WithTTLSecondsAfterFinished(60).
+		WithSchedulingGroup("scale-out").
+		WithLabels(map[string]string{"team": "platform"}).
+		OwnedBy(kafkaCluster, scheme).
+		Create(context.Background(), c)
+	assert.NoError(t, err)
+
+	operation := &v1alpha1.CruiseControlOperation{}
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(created), operation))
+	assert.Equal(t, v1alpha1.ErrorPolicyIgnore, operation.Spec.ErrorPolicy)
+	assert.Equal(t, 60, *operation.Spec.TTLSecondsAfterFinished)
+	assert.Equal(t, "scale-out", operation.Spec.SchedulingGroup)
+	assert.Equal(t, "platform", operation.GetLabels()["team"])
+	assert.Equal(t, "kafka", operation.GetClusterRef())
+	assert.Len(t, operation.GetOwnerReferences(), 1)
+	assert.Equal(t, v1alpha1.OperationAddBroker, operation.CurrentTaskOperation())
+	assert.Equal(t, []int32{4, 5}, operation.CurrentTask().TypedParameters.BrokerIDs)
+}
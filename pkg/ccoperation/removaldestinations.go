@@ -0,0 +1,119 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ccoperation
+
+import (
+	"strconv"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+	kafkautil "github.com/banzaicloud/koperator/pkg/util/kafka"
+)
+
+// applyRemovalDestinations restricts the destination brokers of the remove_broker operation to the brokers
+// satisfying the removal destination policy of the Kafka cluster
+func (b *Builder) applyRemovalDestinations(params *v1alpha1.CruiseControlTaskParameters) error {
+	policy := b.removalDestinations
+	if policy == nil || b.operationType != v1alpha1.OperationRemoveBroker {
+		return nil
+	}
+
+	excluded := make(map[int32]struct{}, len(params.BrokerIDs)+len(policy.ExcludedBrokerIDs))
+	zones := make(map[string]struct{})
+	for _, brokerID := range params.BrokerIDs {
+		excluded[brokerID] = struct{}{}
+		if zone, ok := b.brokerZones[brokerID]; ok {
+			zones[zone] = struct{}{}
+		}
+	}
+	for _, brokerID := range policy.ExcludedBrokerIDs {
+		excluded[brokerID] = struct{}{}
+	}
+
+	destinations := params.DestinationBrokerIDs
+	if len(destinations) == 0 {
+		destinations = b.clusterBrokerIDs
+	}
+	allowed := make([]int32, 0, len(destinations))
+	for _, brokerID := range destinations {
+		if _, ok := excluded[brokerID]; ok {
+			continue
+		}
+		if policy.SameZone && len(zones) > 0 {
+			if _, ok := zones[b.brokerZones[brokerID]]; !ok {
+				continue
+			}
+		}
+		if policy.MaxDiskUsagePercent != nil && b.brokerDiskUsage[brokerID] > float64(*policy.MaxDiskUsagePercent) {
+			continue
+		}
+		allowed = append(allowed, brokerID)
+	}
+	if len(allowed) == 0 {
+		return errors.NewWithDetails("none of the brokers satisfies the broker removal destination policy",
+			"removedBrokers", params.BrokerIDs)
+	}
+	params.DestinationBrokerIDs = allowed
+	return nil
+}
+
+// brokerZones returns the zone (broker.rack) of the brokers of the Kafka cluster. The brokers which have already been
+// removed from the spec are looked up from their configuration backup in the status.
+func brokerZones(kafkaCluster *v1beta1.KafkaCluster) map[int32]string {
+	zones := make(map[int32]string, len(kafkaCluster.Spec.Brokers))
+	for _, broker := range kafkaCluster.Spec.Brokers {
+		zones[broker.Id] = kafkautil.BrokerRack(kafkaCluster, broker)
+	}
+	for id, brokerState := range kafkaCluster.Status.BrokersState {
+		brokerID, err := strconv.ParseInt(id, 10, 32)
+		if err != nil {
+			continue
+		}
+		if _, ok := zones[int32(brokerID)]; ok {
+			continue
+		}
+		broker, err := util.GetBrokerFromBrokerConfigurationBackup(brokerState.ConfigurationBackup)
+		if err != nil {
+			continue
+		}
+		zones[int32(brokerID)] = kafkautil.BrokerRack(kafkaCluster, broker)
+	}
+	return zones
+}
+
+// brokerDiskUsage returns the usage in percent of the fullest log directory of the brokers according to the disk
+// usage in the status of the Kafka cluster. The log directories without known capacity are ignored.
+func brokerDiskUsage(kafkaCluster *v1beta1.KafkaCluster) map[int32]float64 {
+	usage := make(map[int32]float64, len(kafkaCluster.Status.DiskUsage))
+	for id, logDirs := range kafkaCluster.Status.DiskUsage {
+		brokerID, err := strconv.ParseInt(id, 10, 32)
+		if err != nil {
+			continue
+		}
+		for _, logDir := range logDirs {
+			if logDir.CapacityBytes == nil || *logDir.CapacityBytes <= 0 {
+				continue
+			}
+			percent := float64(logDir.UsedBytes) / float64(*logDir.CapacityBytes) * 100
+			if percent > usage[int32(brokerID)] {
+				usage[int32(brokerID)] = percent
+			}
+		}
+	}
+	return usage
+}
@@ -0,0 +1,105 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ccoperation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestRemovalDestinations(t *testing.T) {
+	// broker 3 has already been removed from the spec, its zone is known from the configuration backup
+	removedBroker := &v1beta1.Broker{Id: 3, ReadOnlyConfig: "broker.rack=zone-a"}
+	backup, err := util.GzipAndBase64BrokerConfiguration(removedBroker)
+	require.NoError(t, err)
+	newCluster := func(policy *v1beta1.BrokerRemovalDestinationPolicy) *v1beta1.KafkaCluster {
+		return &v1beta1.KafkaCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+			Spec: v1beta1.KafkaClusterSpec{
+				Brokers: []v1beta1.Broker{
+					{Id: 0, ReadOnlyConfig: "broker.rack=zone-a"},
+					{Id: 1, ReadOnlyConfig: "broker.rack=zone-a"},
+					{Id: 2, ReadOnlyConfig: "broker.rack=zone-b"},
+					{Id: 4, ReadOnlyConfig: "broker.rack=zone-a"},
+				},
+				CruiseControlConfig: v1beta1.CruiseControlConfig{
+					CruiseControlOperationSpec: &v1beta1.CruiseControlOperationSpec{BrokerRemovalDestinations: policy},
+				},
+			},
+			Status: v1beta1.KafkaClusterStatus{
+				BrokersState: map[string]v1beta1.BrokerState{"3": {ConfigurationBackup: backup}},
+				DiskUsage: map[string][]v1beta1.LogDirUsage{
+					"1": {{Path: "/kafka-logs", UsedBytes: 90, CapacityBytes: util.Int64Pointer(100)}},
+					"4": {{Path: "/kafka-logs", UsedBytes: 40, CapacityBytes: util.Int64Pointer(100)}},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		testName             string
+		policy               *v1beta1.BrokerRemovalDestinationPolicy
+		expectedError        bool
+		expectedDestinations string
+	}{
+		{
+			testName: "no policy",
+		},
+		{
+			testName:             "same zone",
+			policy:               &v1beta1.BrokerRemovalDestinationPolicy{SameZone: true},
+			expectedDestinations: "0,1,4",
+		},
+		{
+			testName:             "same zone without hot and excluded brokers",
+			policy:               &v1beta1.BrokerRemovalDestinationPolicy{SameZone: true, ExcludedBrokerIDs: []int32{0}, MaxDiskUsagePercent: util.Int32Pointer(80)},
+			expectedDestinations: "4",
+		},
+		{
+			testName:             "excluded brokers in any zone",
+			policy:               &v1beta1.BrokerRemovalDestinationPolicy{ExcludedBrokerIDs: []int32{0, 1}},
+			expectedDestinations: "2,4",
+		},
+		{
+			testName:      "no broker satisfies the policy",
+			policy:        &v1beta1.BrokerRemovalDestinationPolicy{SameZone: true, ExcludedBrokerIDs: []int32{0, 4}, MaxDiskUsagePercent: util.Int32Pointer(50)},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			operation, err := NewRemoveBroker(3).ForCluster(newCluster(testCase.policy)).Build()
+			if testCase.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedDestinations, operation.CurrentTask().TypedParameters.ToParameters()[v1alpha1.ParamDestbrokerIDs])
+		})
+	}
+
+	// the policy does not apply to the other operations
+	operation, err := NewRebalance().ForCluster(newCluster(&v1beta1.BrokerRemovalDestinationPolicy{ExcludedBrokerIDs: []int32{0}})).Build()
+	require.NoError(t, err)
+	assert.Empty(t, operation.CurrentTask().TypedParameters.ToParameters()[v1alpha1.ParamDestbrokerIDs])
+}
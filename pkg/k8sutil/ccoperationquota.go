@@ -0,0 +1,210 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// matchingCCOperationQuotas returns the quotas which select the CruiseControlOperation
+func matchingCCOperationQuotas(quotas []v1beta1.CruiseControlOperationQuota, operation *v1alpha1.CruiseControlOperation) ([]v1beta1.CruiseControlOperationQuota, error) {
+	var matching []v1beta1.CruiseControlOperationQuota
+	for _, quota := range quotas {
+		matches, err := quotaSelects(quota, operation)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			matching = append(matching, quota)
+		}
+	}
+	return matching, nil
+}
+
+func quotaSelects(quota v1beta1.CruiseControlOperationQuota, operation *v1alpha1.CruiseControlOperation) (bool, error) {
+	if quota.Selector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(quota.Selector)
+	if err != nil {
+		return false, errors.WrapIfWithDetails(err, "invalid CruiseControlOperation quota selector", "quota", quota.Name)
+	}
+	return selector.Matches(labels.Set(operation.GetLabels())), nil
+}
+
+// sameClusterOperations returns the CruiseControlOperations which belong to the same Kafka cluster as the operation
+// and are selected by the quota, excluding the operation itself.
+func sameClusterOperations(quota v1beta1.CruiseControlOperationQuota, operation *v1alpha1.CruiseControlOperation,
+	operations []v1alpha1.CruiseControlOperation) ([]*v1alpha1.CruiseControlOperation, error) {
+	var ret []*v1alpha1.CruiseControlOperation
+	for i := range operations {
+		other := &operations[i]
+		if other.GetNamespace() != operation.GetNamespace() || other.GetName() == operation.GetName() ||
+			other.GetClusterRef() != operation.GetClusterRef() {
+			continue
+		}
+		matches, err := quotaSelects(quota, other)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			ret = append(ret, other)
+		}
+	}
+	return ret, nil
+}
+
+// IsCCOperationControlledByKoperator returns true when the CruiseControlOperation is controlled by one of the resources
+// of the Koperator, e.g. the KafkaCluster the operation has been created for. The CruiseControlOperation webhook allows
+// only the Koperator itself to set such a controller, so these are the operations created by the Koperator.
+func IsCCOperationControlledByKoperator(operation *v1alpha1.CruiseControlOperation) bool {
+	owner := metav1.GetControllerOf(operation)
+	if owner == nil {
+		return false
+	}
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	return err == nil && gv.Group == v1beta1.GroupVersion.Group
+}
+
+// IsCCOperationTypeAllowed returns an error when the operation type of the CruiseControlOperation
+// is not allowed by one of the quotas selecting it. The operations created by the Koperator are never rejected.
+func IsCCOperationTypeAllowed(quotas []v1beta1.CruiseControlOperationQuota, operation *v1alpha1.CruiseControlOperation) error {
+	operationType := operation.CurrentTaskOperation()
+	if operationType == "" || IsCCOperationControlledByKoperator(operation) {
+		return nil
+	}
+	matching, err := matchingCCOperationQuotas(quotas, operation)
+	if err != nil {
+		return err
+	}
+	for _, quota := range matching {
+		if len(quota.AllowedOperations) == 0 {
+			continue
+		}
+		allowed := false
+		for _, allowedOperation := range quota.AllowedOperations {
+			if allowedOperation == string(operationType) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return errors.Errorf("operation %s is not allowed by quota %s, allowed operations: %v", operationType, quota.Name, quota.AllowedOperations)
+		}
+	}
+	return nil
+}
+
+// CheckCCOperationAdmission returns an error when creating the CruiseControlOperation would exceed one of the quotas
+// selecting it. The operations argument contains the existing CruiseControlOperations of the namespace.
+// The operations created by the Koperator are never rejected, they are only held back from execution by the quotas.
+func CheckCCOperationAdmission(quotas []v1beta1.CruiseControlOperationQuota, operation *v1alpha1.CruiseControlOperation,
+	operations []v1alpha1.CruiseControlOperation, now time.Time) error {
+	if IsCCOperationControlledByKoperator(operation) {
+		return nil
+	}
+	if err := IsCCOperationTypeAllowed(quotas, operation); err != nil {
+		return err
+	}
+	matching, err := matchingCCOperationQuotas(quotas, operation)
+	if err != nil {
+		return err
+	}
+	for _, quota := range matching {
+		others, err := sameClusterOperations(quota, operation, operations)
+		if err != nil {
+			return err
+		}
+		var pending, createdWithinHour int32
+		for _, other := range others {
+			if !other.IsDone() {
+				pending++
+			}
+			if now.Sub(other.GetCreationTimestamp().Time) < time.Hour {
+				createdWithinHour++
+			}
+		}
+		if quota.MaxPending != nil && pending >= *quota.MaxPending {
+			return errors.Errorf("quota %s allows at most %d pending operations", quota.Name, *quota.MaxPending)
+		}
+		if quota.MaxPerHour != nil && createdWithinHour >= *quota.MaxPerHour {
+			return errors.Errorf("quota %s allows at most %d operations per hour", quota.Name, *quota.MaxPerHour)
+		}
+	}
+	return nil
+}
+
+// CCOperationHeldBackByQuota returns the reason why the CruiseControlOperation waiting for its first execution cannot
+// be executed yet due to the quotas selecting it, or an empty string when it can be executed. Operations are admitted
+// in the order of their creation so an operation is held back while older ones exhaust the pending limit.
+func CCOperationHeldBackByQuota(quotas []v1beta1.CruiseControlOperationQuota, operation *v1alpha1.CruiseControlOperation,
+	operations []v1alpha1.CruiseControlOperation, now time.Time) (string, error) {
+	matching, err := matchingCCOperationQuotas(quotas, operation)
+	if err != nil {
+		return "", err
+	}
+	for _, quota := range matching {
+		others, err := sameClusterOperations(quota, operation, operations)
+		if err != nil {
+			return "", err
+		}
+		var olderPending, executedWithinHour int32
+		for _, other := range others {
+			if !other.IsDone() && isCreatedEarlier(other, operation) {
+				olderPending++
+			}
+			if isExecutedSince(other, now.Add(-time.Hour)) {
+				executedWithinHour++
+			}
+		}
+		if quota.MaxPending != nil && olderPending >= *quota.MaxPending {
+			return fmt.Sprintf("quota %s allows at most %d pending operations", quota.Name, *quota.MaxPending), nil
+		}
+		if quota.MaxPerHour != nil && executedWithinHour >= *quota.MaxPerHour {
+			return fmt.Sprintf("quota %s allows at most %d operations per hour", quota.Name, *quota.MaxPerHour), nil
+		}
+	}
+	return "", nil
+}
+
+func isCreatedEarlier(operation, other *v1alpha1.CruiseControlOperation) bool {
+	created, otherCreated := operation.GetCreationTimestamp(), other.GetCreationTimestamp()
+	if created.Equal(&otherCreated) {
+		return operation.GetName() < other.GetName()
+	}
+	return created.Before(&otherCreated)
+}
+
+// isExecutedSince returns true when any task of the CruiseControlOperation has been started after the given time
+func isExecutedSince(operation *v1alpha1.CruiseControlOperation, since time.Time) bool {
+	if task := operation.CurrentTask(); task != nil && task.Started != nil && task.Started.After(since) {
+		return true
+	}
+	for _, task := range operation.Status.FailedTasks {
+		if task.Started != nil && task.Started.After(since) {
+			return true
+		}
+	}
+	return false
+}
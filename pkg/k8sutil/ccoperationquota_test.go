@@ -0,0 +1,120 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func newQuotaTestOperation(name, tenant string, created time.Time, operation v1alpha1.CruiseControlTaskOperation,
+	state v1beta1.CruiseControlUserTaskState, started *time.Time) v1alpha1.CruiseControlOperation {
+	op := v1alpha1.CruiseControlOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "kafka",
+			CreationTimestamp: metav1.Time{Time: created},
+			Labels: map[string]string{
+				v1beta1.KafkaCRLabelKey: "kafka",
+				"tenant":                tenant,
+			},
+		},
+		Status: v1alpha1.CruiseControlOperationStatus{
+			CurrentTask: &v1alpha1.CruiseControlTask{
+				Operation: operation,
+				State:     state,
+			},
+		},
+	}
+	if started != nil {
+		op.Status.CurrentTask.Started = &metav1.Time{Time: *started}
+	}
+	return op
+}
+
+func TestCCOperationQuotas(t *testing.T) {
+	now := time.Now()
+	startedRecently := now.Add(-10 * time.Minute)
+	quotas := []v1beta1.CruiseControlOperationQuota{
+		{
+			Name:              "team-a",
+			Selector:          &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			MaxPending:        util.Int32Pointer(1),
+			MaxPerHour:        util.Int32Pointer(2),
+			AllowedOperations: []string{string(v1alpha1.OperationRebalance)},
+		},
+	}
+
+	t.Run("operation type not allowed", func(t *testing.T) {
+		op := newQuotaTestOperation("op", "a", now, v1alpha1.OperationRemoveBroker, "", nil)
+		assert.Error(t, IsCCOperationTypeAllowed(quotas, &op))
+		other := newQuotaTestOperation("op", "b", now, v1alpha1.OperationRemoveBroker, "", nil)
+		assert.NoError(t, IsCCOperationTypeAllowed(quotas, &other))
+	})
+
+	t.Run("admission over max pending", func(t *testing.T) {
+		existing := []v1alpha1.CruiseControlOperation{
+			newQuotaTestOperation("existing", "a", now.Add(-2*time.Hour), v1alpha1.OperationRebalance, v1beta1.CruiseControlTaskActive, &startedRecently),
+		}
+		op := newQuotaTestOperation("op", "a", now, "", "", nil)
+		assert.Error(t, CheckCCOperationAdmission(quotas, &op, existing, now))
+
+		// the operations of the Koperator are held back instead of being rejected
+		owned := newQuotaTestOperation("owned", "a", now, v1alpha1.OperationRemoveBroker, "", nil)
+		owned.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: v1beta1.GroupVersion.String(),
+			Kind:       "KafkaCluster",
+			Name:       "kafka",
+			Controller: util.BoolPointer(true),
+		}})
+		assert.NoError(t, CheckCCOperationAdmission(quotas, &owned, existing, now))
+		assert.NoError(t, IsCCOperationTypeAllowed(quotas, &owned))
+
+		existing[0].Status.CurrentTask.State = v1beta1.CruiseControlTaskCompleted
+		assert.NoError(t, CheckCCOperationAdmission(quotas, &op, existing, now))
+	})
+
+	t.Run("held back by max pending and max per hour", func(t *testing.T) {
+		op := newQuotaTestOperation("op", "a", now, v1alpha1.OperationRebalance, "", nil)
+		operations := []v1alpha1.CruiseControlOperation{
+			newQuotaTestOperation("older", "a", now.Add(-time.Minute), v1alpha1.OperationRebalance, v1beta1.CruiseControlTaskActive, &startedRecently),
+			op,
+		}
+		reason, err := CCOperationHeldBackByQuota(quotas, &op, operations, now)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, reason)
+
+		operations = []v1alpha1.CruiseControlOperation{
+			newQuotaTestOperation("done-1", "a", now.Add(-time.Minute), v1alpha1.OperationRebalance, v1beta1.CruiseControlTaskCompleted, &startedRecently),
+			newQuotaTestOperation("done-2", "a", now.Add(-time.Minute), v1alpha1.OperationRebalance, v1beta1.CruiseControlTaskCompleted, &startedRecently),
+			newQuotaTestOperation("other-tenant", "b", now.Add(-time.Minute), v1alpha1.OperationRebalance, v1beta1.CruiseControlTaskActive, &startedRecently),
+			op,
+		}
+		reason, err = CCOperationHeldBackByQuota(quotas, &op, operations, now)
+		assert.NoError(t, err)
+		assert.Contains(t, reason, "per hour")
+
+		reason, err = CCOperationHeldBackByQuota(quotas, &op, operations[2:], now)
+		assert.NoError(t, err)
+		assert.Empty(t, reason)
+	})
+}
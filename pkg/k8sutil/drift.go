@@ -0,0 +1,143 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+)
+
+// DriftDetectedEventReason is the reason of the Event emitted when a managed resource has been changed out-of-band
+const DriftDetectedEventReason = "DriftDetected"
+
+// lastAppliedObject returns the state of the object which has been applied last by the operator, or nil when the
+// object does not carry the last applied annotation
+func lastAppliedObject(current runtime.Object) (runtime.Object, error) {
+	original, err := patch.DefaultAnnotator.GetOriginalConfiguration(current)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not get the last applied state of the resource")
+	}
+	if len(original) == 0 {
+		return nil, nil
+	}
+	lastApplied := reflect.New(reflect.TypeOf(current).Elem()).Interface().(runtime.Object)
+	if err := json.Unmarshal(original, lastApplied); err != nil {
+		return nil, errors.WrapIf(err, "could not unmarshal the last applied state of the resource")
+	}
+	return lastApplied, nil
+}
+
+// DetectDrift returns the patch which reverts the changes of the object made since the operator has applied it
+// last, or nil when the object has not been changed out-of-band
+func DetectDrift(current runtime.Object) ([]byte, error) {
+	lastApplied, err := lastAppliedObject(current)
+	if err != nil || lastApplied == nil {
+		return nil, err
+	}
+	patchResult, err := patch.DefaultPatchMaker.Calculate(current, lastApplied)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not match objects")
+	}
+	if patchResult.IsEmpty() {
+		return nil, nil
+	}
+	return patchResult.Patch, nil
+}
+
+// IsDesiredStateChanged returns true when the desired state of the object differs from the one applied last by
+// the operator
+func IsDesiredStateChanged(current, desired runtime.Object) (bool, error) {
+	lastApplied, err := lastAppliedObject(current)
+	if err != nil {
+		return false, err
+	}
+	if lastApplied == nil {
+		return true, nil
+	}
+	if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(lastApplied); err != nil {
+		return false, errors.WrapIf(err, "could not apply last state to annotation")
+	}
+	patchResult, err := patch.DefaultPatchMaker.Calculate(lastApplied, desired)
+	if err != nil {
+		return false, errors.WrapIf(err, "could not match objects")
+	}
+	return !patchResult.IsEmpty(), nil
+}
+
+// HandleDrift reports the out-of-band changes of the resource managed for the KafkaCluster as an Event on the
+// KafkaCluster, no Event is emitted when the recorder is nil. It returns false when the drift policy of the
+// KafkaCluster does not allow to revert the changes, i.e. the resource must be left as it is.
+func HandleDrift(log logr.Logger, recorder record.EventRecorder, current, desired runtime.Object, cr *v1beta1.KafkaCluster) (bool, error) {
+	drift, err := DetectDrift(current)
+	if err != nil {
+		return false, err
+	}
+	if len(drift) == 0 {
+		return true, nil
+	}
+
+	kind := reflect.TypeOf(current).Elem().Name()
+	name := current.(runtimeClient.Object).GetName()
+	log.Info("resource has been changed out-of-band", "kind", kind, "name", name, "drift", string(drift))
+	if recorder != nil {
+		recorder.Eventf(cr, corev1.EventTypeWarning, DriftDetectedEventReason,
+			"%s %s has been changed out-of-band, drift policy: %s", kind, name, cr.Spec.GetDriftPolicy())
+	}
+
+	if cr.Spec.GetDriftPolicy() == v1beta1.DriftPolicyEnforce {
+		return true, nil
+	}
+	return IsDesiredStateChanged(current, desired)
+}
+
+// ReconcileWithDriftDetection reconciles the K8S resource like Reconcile does, but the out-of-band changes of the
+// existing resource are reported and only reverted when the drift policy of the KafkaCluster allows it
+func ReconcileWithDriftDetection(log logr.Logger, client runtimeClient.Client, recorder record.EventRecorder, desired runtime.Object, cr *v1beta1.KafkaCluster) error {
+	current := desired.DeepCopyObject().(runtimeClient.Object)
+	key := runtimeClient.ObjectKeyFromObject(current)
+	err := client.Get(context.TODO(), key, current)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errorfactory.New(
+			errorfactory.APIFailure{},
+			err,
+			"getting resource failed",
+			"kind", reflect.TypeOf(desired), "name", key.Name,
+		)
+	}
+	if err == nil {
+		converge, err := HandleDrift(log, recorder, current, desired, cr)
+		if err != nil {
+			return err
+		}
+		if !converge {
+			log.V(1).Info("resource is left as it is according to the drift policy", "kind", reflect.TypeOf(desired), "name", key.Name)
+			return nil
+		}
+	}
+	return Reconcile(log, client, desired, cr)
+}
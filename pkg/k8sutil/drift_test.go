@@ -0,0 +1,125 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func newDriftTestConfigMap(data string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kafka-config",
+			Namespace: "kafka",
+		},
+		Data: map[string]string{"config": data},
+	}
+}
+
+func TestReconcileWithDriftDetection(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       v1beta1.DriftPolicy
+		desired      string
+		expectedData string
+	}{
+		{
+			name:         "drift is reverted with Enforce policy",
+			policy:       v1beta1.DriftPolicyEnforce,
+			desired:      "a",
+			expectedData: "a",
+		},
+		{
+			name:         "drift is kept with Warn policy",
+			policy:       v1beta1.DriftPolicyWarn,
+			desired:      "a",
+			expectedData: "edited",
+		},
+		{
+			name:         "desired change is applied with Warn policy",
+			policy:       v1beta1.DriftPolicyWarn,
+			desired:      "b",
+			expectedData: "b",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+			assert.NoError(t, v1beta1.AddToScheme(scheme))
+			client := fake.NewClientBuilder().WithScheme(scheme).Build()
+			recorder := record.NewFakeRecorder(10)
+			cluster := &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+				Spec:       v1beta1.KafkaClusterSpec{DriftPolicy: test.policy},
+			}
+
+			assert.NoError(t, ReconcileWithDriftDetection(logr.Discard(), client, recorder, newDriftTestConfigMap("a"), cluster))
+			assert.Empty(t, recorder.Events)
+
+			current := &corev1.ConfigMap{}
+			assert.NoError(t, client.Get(context.Background(), runtimeClient.ObjectKeyFromObject(newDriftTestConfigMap("")), current))
+			current.Data["config"] = "edited"
+			assert.NoError(t, client.Update(context.Background(), current))
+
+			assert.NoError(t, ReconcileWithDriftDetection(logr.Discard(), client, recorder, newDriftTestConfigMap(test.desired), cluster))
+			assert.Len(t, recorder.Events, 1)
+			assert.Contains(t, <-recorder.Events, DriftDetectedEventReason)
+
+			assert.NoError(t, client.Get(context.Background(), runtimeClient.ObjectKeyFromObject(current), current))
+			assert.Equal(t, test.expectedData, current.Data["config"])
+		})
+	}
+}
+
+func TestDetectDrift(t *testing.T) {
+	configMap := newDriftTestConfigMap("a")
+	drift, err := DetectDrift(configMap)
+	assert.NoError(t, err)
+	assert.Nil(t, drift, "objects without the last applied annotation cannot drift")
+
+	assert.NoError(t, patch.DefaultAnnotator.SetLastAppliedAnnotation(configMap))
+	drift, err = DetectDrift(configMap)
+	assert.NoError(t, err)
+	assert.Nil(t, drift)
+
+	configMap.Data["config"] = "edited"
+	drift, err = DetectDrift(configMap)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, drift)
+
+	changed, err := IsDesiredStateChanged(configMap, newDriftTestConfigMap("a"))
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	changed, err = IsDesiredStateChanged(configMap, newDriftTestConfigMap("b"))
+	assert.NoError(t, err)
+	assert.True(t, changed)
+}
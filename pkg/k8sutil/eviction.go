@@ -0,0 +1,56 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// PodEvictor evicts pods through the Eviction API, which refuses the eviction with a TooManyRequests error while it
+// would violate a PodDisruptionBudget
+type PodEvictor interface {
+	Evict(ctx context.Context, pod *corev1.Pod) error
+}
+
+type podEvictor struct {
+	clientset kubernetes.Interface
+}
+
+// NewPodEvictor returns a PodEvictor which uses the given REST config to reach the API server
+func NewPodEvictor(config *rest.Config) (PodEvictor, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &podEvictor{clientset: clientset}, nil
+}
+
+// Evict requests the eviction of the pod, the UID precondition makes sure that a recreated pod with the same name
+// is not evicted
+func (e *podEvictor) Evict(ctx context.Context, pod *corev1.Pod) error {
+	uid := pod.GetUID()
+	return e.clientset.CoreV1().Pods(pod.GetNamespace()).EvictV1(ctx, &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.GetName(), Namespace: pod.GetNamespace()},
+		DeleteOptions: &metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &uid},
+		},
+	})
+}
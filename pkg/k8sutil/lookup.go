@@ -17,6 +17,11 @@ package k8sutil
 import (
 	"context"
 
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -30,6 +35,68 @@ func LookupKafkaCluster(ctx context.Context, client runtimeClient.Reader, cluste
 	return
 }
 
+// IsCrossNamespaceReferenceAllowed returns true when the resources in the given namespace are allowed to reference
+// the cluster by its cross-namespace references allow-list
+func IsCrossNamespaceReferenceAllowed(ctx context.Context, client runtimeClient.Reader, cluster *v1beta1.KafkaCluster, namespace string) (bool, error) {
+	allowList := cluster.Spec.CrossNamespaceReferences
+	if allowList == nil || namespace == cluster.GetNamespace() {
+		return true, nil
+	}
+	for _, allowed := range allowList.Namespaces {
+		if allowed == namespace {
+			return true, nil
+		}
+	}
+	if allowList.NamespaceSelector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(allowList.NamespaceSelector)
+	if err != nil {
+		return false, errors.WrapIfWithDetails(err, "invalid namespace selector of the cross-namespace references", "cluster", cluster.GetName())
+	}
+	ns := &corev1.Namespace{}
+	if err := client.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not get namespace", "namespace", namespace)
+	}
+	return selector.Matches(labels.Set(ns.GetLabels())), nil
+}
+
+// CheckCrossNamespaceReference returns an error when the resources in the given namespace are not allowed to reference
+// the cluster
+func CheckCrossNamespaceReference(ctx context.Context, client runtimeClient.Reader, cluster *v1beta1.KafkaCluster, namespace string) error {
+	allowed, err := IsCrossNamespaceReferenceAllowed(ctx, client, cluster, namespace)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.NewWithDetails("the namespace is not allowed to reference the kafka cluster",
+			"namespace", namespace, "cluster", cluster.GetName(), "clusterNamespace", cluster.GetNamespace())
+	}
+	return nil
+}
+
+// LookupConfigMapKey returns the value of the selected ConfigMap key in the given namespace. The second return value
+// is false when the selector is optional and the ConfigMap or the key does not exist.
+func LookupConfigMapKey(ctx context.Context, client runtimeClient.Reader, namespace string, selector *corev1.ConfigMapKeySelector) (string, bool, error) {
+	optional := selector.Optional != nil && *selector.Optional
+	configMap := &corev1.ConfigMap{}
+	err := client.Get(ctx, types.NamespacedName{Name: selector.Name, Namespace: namespace}, configMap)
+	switch {
+	case apierrors.IsNotFound(err) && optional:
+		return "", false, nil
+	case err != nil:
+		return "", false, errors.WrapIfWithDetails(err, "could not get ConfigMap", "configMap", selector.Name)
+	}
+	value, ok := configMap.Data[selector.Key]
+	if !ok {
+		if optional {
+			return "", false, nil
+		}
+		return "", false, errors.NewWithDetails("key is missing from ConfigMap", "configMap", selector.Name, "key", selector.Key)
+	}
+	return value, true, nil
+}
+
 // This could be used if we get rid of the "intermediate" certificate we create for now during cluster creation
 // func LookupControllerSecret(client runtimeClient.Client, clusterName, clusterNamespace, controllerTempl string) (secret *corev1.Secret, err error) {
 // 	secret = &corev1.Secret{}
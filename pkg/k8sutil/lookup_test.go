@@ -0,0 +1,97 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestIsCrossNamespaceReferenceAllowed(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"kafka-access": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-b"}},
+	).Build()
+	cluster := &v1beta1.KafkaCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"}}
+
+	testCases := []struct {
+		testName  string
+		allowList *v1beta1.CrossNamespaceReferences
+		namespace string
+		expected  bool
+	}{
+		{
+			testName:  "no allow-list",
+			namespace: "tenant-b",
+			expected:  true,
+		},
+		{
+			testName:  "namespace of the cluster",
+			allowList: &v1beta1.CrossNamespaceReferences{},
+			namespace: "kafka",
+			expected:  true,
+		},
+		{
+			testName:  "empty allow-list",
+			allowList: &v1beta1.CrossNamespaceReferences{},
+			namespace: "tenant-a",
+			expected:  false,
+		},
+		{
+			testName:  "namespace listed",
+			allowList: &v1beta1.CrossNamespaceReferences{Namespaces: []string{"tenant-b"}},
+			namespace: "tenant-b",
+			expected:  true,
+		},
+		{
+			testName: "namespace selected",
+			allowList: &v1beta1.CrossNamespaceReferences{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kafka-access": "true"}},
+			},
+			namespace: "tenant-a",
+			expected:  true,
+		},
+		{
+			testName: "namespace not selected",
+			allowList: &v1beta1.CrossNamespaceReferences{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kafka-access": "true"}},
+			},
+			namespace: "tenant-b",
+			expected:  false,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			cluster.Spec.CrossNamespaceReferences = testCase.allowList
+			allowed, err := IsCrossNamespaceReferenceAllowed(context.Background(), client, cluster, testCase.namespace)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, allowed)
+		})
+	}
+
+	cluster.Spec.CrossNamespaceReferences = &v1beta1.CrossNamespaceReferences{}
+	assert.Error(t, CheckCrossNamespaceReference(context.Background(), client, cluster, "tenant-a"))
+}
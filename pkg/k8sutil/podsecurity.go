@@ -0,0 +1,77 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+const capabilityAll corev1.Capability = "ALL"
+
+// RestrictedPodSecurityContext returns a copy of the pod security context where the fields which are not set
+// are defaulted to pass the restricted Pod Security Standard
+func RestrictedPodSecurityContext(podSecurityContext *corev1.PodSecurityContext) *corev1.PodSecurityContext {
+	ret := &corev1.PodSecurityContext{}
+	if podSecurityContext != nil {
+		ret = podSecurityContext.DeepCopy()
+	}
+	if ret.RunAsNonRoot == nil {
+		ret.RunAsNonRoot = util.BoolPointer(true)
+	}
+	if ret.SeccompProfile == nil {
+		ret.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+	return ret
+}
+
+// RestrictedSecurityContext returns a copy of the container security context where the fields which are not set
+// are defaulted to pass the restricted Pod Security Standard. All capabilities are dropped unless the drop list is
+// given explicitly.
+func RestrictedSecurityContext(securityContext *corev1.SecurityContext) *corev1.SecurityContext {
+	ret := &corev1.SecurityContext{}
+	if securityContext != nil {
+		ret = securityContext.DeepCopy()
+	}
+	if ret.AllowPrivilegeEscalation == nil {
+		ret.AllowPrivilegeEscalation = util.BoolPointer(false)
+	}
+	if ret.RunAsNonRoot == nil {
+		ret.RunAsNonRoot = util.BoolPointer(true)
+	}
+	if ret.SeccompProfile == nil {
+		ret.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+	if ret.Capabilities == nil {
+		ret.Capabilities = &corev1.Capabilities{}
+	}
+	if len(ret.Capabilities.Drop) == 0 {
+		ret.Capabilities.Drop = []corev1.Capability{capabilityAll}
+	}
+	return ret
+}
+
+// ApplyRestrictedPodSecurityStandard defaults the security context of the pod and all of its containers to pass
+// the restricted Pod Security Standard
+func ApplyRestrictedPodSecurityStandard(podSpec *corev1.PodSpec) {
+	podSpec.SecurityContext = RestrictedPodSecurityContext(podSpec.SecurityContext)
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].SecurityContext = RestrictedSecurityContext(podSpec.InitContainers[i].SecurityContext)
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].SecurityContext = RestrictedSecurityContext(podSpec.Containers[i].SecurityContext)
+	}
+}
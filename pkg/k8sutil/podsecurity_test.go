@@ -0,0 +1,72 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestApplyRestrictedPodSecurityStandard(t *testing.T) {
+	podSpec := &corev1.PodSpec{
+		SecurityContext: &corev1.PodSecurityContext{RunAsUser: util.Int64Pointer(1000)},
+		InitContainers:  []corev1.Container{{Name: "init"}},
+		Containers: []corev1.Container{
+			{Name: "kafka"},
+			{
+				Name: "sidecar",
+				SecurityContext: &corev1.SecurityContext{
+					ReadOnlyRootFilesystem: util.BoolPointer(true),
+					RunAsNonRoot:           util.BoolPointer(false),
+					Capabilities:           &corev1.Capabilities{Add: []corev1.Capability{"NET_BIND_SERVICE"}},
+				},
+			},
+		},
+	}
+
+	ApplyRestrictedPodSecurityStandard(podSpec)
+
+	runtimeDefault := &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	assert.Equal(t, &corev1.PodSecurityContext{
+		RunAsUser:      util.Int64Pointer(1000),
+		RunAsNonRoot:   util.BoolPointer(true),
+		SeccompProfile: runtimeDefault,
+	}, podSpec.SecurityContext)
+
+	defaults := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: util.BoolPointer(false),
+		RunAsNonRoot:             util.BoolPointer(true),
+		SeccompProfile:           runtimeDefault,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+	assert.Equal(t, defaults, podSpec.InitContainers[0].SecurityContext)
+	assert.Equal(t, defaults, podSpec.Containers[0].SecurityContext)
+
+	// Explicitly set fields are kept
+	assert.Equal(t, &corev1.SecurityContext{
+		ReadOnlyRootFilesystem:   util.BoolPointer(true),
+		AllowPrivilegeEscalation: util.BoolPointer(false),
+		RunAsNonRoot:             util.BoolPointer(false),
+		SeccompProfile:           runtimeDefault,
+		Capabilities: &corev1.Capabilities{
+			Add:  []corev1.Capability{"NET_BIND_SERVICE"},
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}, podSpec.Containers[1].SecurityContext)
+}
@@ -24,8 +24,8 @@ import (
 	"github.com/go-logr/logr"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -44,30 +44,50 @@ func IsMarkedForDeletion(m metav1.ObjectMeta) bool {
 	return m.GetDeletionTimestamp() != nil
 }
 
-// UpdateBrokerConfigurationBackup updates the broker status with a backup from kafka broker configurations
-func UpdateBrokerConfigurationBackup(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster) error {
-	ctx := context.Background()
-	updateFn := func() error {
-		needsUpdate, err := generateBrokerConfigurationBackups(cluster)
-		if err != nil {
-			return err
-		}
-		if !needsUpdate {
-			return nil
-		}
-		err = c.Status().Update(ctx, cluster)
-		if err == nil {
-			return nil
+// PatchKafkaClusterStatus applies the changes made by mutate to the status of the KafkaCluster with a merge patch.
+// The patch carries the resource version of the cluster, so it is rejected when the cluster has been changed since it
+// has been read, otherwise the list fields replaced by the patch would overwrite the concurrent changes. On a conflict
+// the latest version of the cluster is read and mutate is applied to it again, so mutate has to derive its changes
+// from the status it is given.
+func PatchKafkaClusterStatus(ctx context.Context, c client.Client, cluster *banzaicloudv1beta1.KafkaCluster,
+	mutate func(status *banzaicloudv1beta1.KafkaClusterStatus)) error {
+	typeMeta := cluster.TypeMeta
+	patchFn := func() error {
+		patch := client.MergeFromWithOptions(cluster.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		mutate(&cluster.Status)
+
+		err := c.Status().Patch(ctx, cluster, patch)
+		if apierrors.IsNotFound(err) {
+			err = c.Patch(ctx, cluster, patch)
 		}
 		if apierrors.IsConflict(err) {
-			if errGet := c.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}, cluster); err != nil {
+			if errGet := c.Get(ctx, client.ObjectKeyFromObject(cluster), cluster); errGet != nil {
 				return errors.WrapIf(errGet, "could not get config for updating status")
 			}
 		}
 		return err
 	}
+	err := util.RetryOnConflict(util.DefaultBackOffForConflict, patchFn)
+	// patch loses the typeMeta of the config that's used later when setting ownerrefs
+	cluster.TypeMeta = typeMeta
+	return err
+}
 
-	return util.RetryOnConflict(util.DefaultBackOffForConflict, updateFn)
+// UpdateBrokerConfigurationBackup updates the broker status with a backup from kafka broker configurations
+func UpdateBrokerConfigurationBackup(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster) error {
+	needsUpdate, err := generateBrokerConfigurationBackups(cluster.DeepCopy())
+	if err != nil || !needsUpdate {
+		return err
+	}
+	var generateErr error
+	err = PatchKafkaClusterStatus(context.Background(), c, cluster,
+		func(status *banzaicloudv1beta1.KafkaClusterStatus) {
+			_, generateErr = generateBrokerConfigurationBackups(cluster)
+		})
+	if generateErr != nil {
+		return generateErr
+	}
+	return err
 }
 
 func generateBrokerConfigurationBackups(cluster *banzaicloudv1beta1.KafkaCluster) (bool, error) {
@@ -93,44 +113,19 @@ func generateBrokerConfigurationBackups(cluster *banzaicloudv1beta1.KafkaCluster
 
 // UpdateBrokerStatus updates the broker status with rack and configuration infos
 func UpdateBrokerStatus(c client.Client, brokerIDs []string, cluster *banzaicloudv1beta1.KafkaCluster, state interface{}, logger logr.Logger) error {
-	typeMeta := cluster.TypeMeta
-
-	generateBrokerState(brokerIDs, cluster, state)
-
-	err := c.Status().Update(context.Background(), cluster)
-	if apierrors.IsNotFound(err) {
-		err = c.Update(context.Background(), cluster)
-	}
+	err := PatchKafkaClusterStatus(context.Background(), c, cluster,
+		func(status *banzaicloudv1beta1.KafkaClusterStatus) {
+			generateBrokerState(brokerIDs, status, state)
+		})
 	if err != nil {
-		if !apierrors.IsConflict(err) {
-			return errors.WrapIff(err, "could not update Kafka broker(s) %s state", strings.Join(brokerIDs, ","))
-		}
-		err := c.Get(context.TODO(), types.NamespacedName{
-			Namespace: cluster.Namespace,
-			Name:      cluster.Name,
-		}, cluster)
-		if err != nil {
-			return errors.WrapIf(err, "could not get config for updating status")
-		}
-
-		generateBrokerState(brokerIDs, cluster, state)
-
-		err = c.Status().Update(context.Background(), cluster)
-		if apierrors.IsNotFound(err) {
-			err = c.Update(context.Background(), cluster)
-		}
-		if err != nil {
-			return errors.WrapIff(err, "could not update Kafka clusters broker(s) %s state", strings.Join(brokerIDs, ","))
-		}
+		return errors.WrapIff(err, "could not update Kafka broker(s) %s state", strings.Join(brokerIDs, ","))
 	}
-	// update loses the typeMeta of the config that's used later when setting ownerrefs
-	cluster.TypeMeta = typeMeta
 	logger.Info("Kafka cluster state updated")
 	return nil
 }
 
-func generateBrokerState(brokerIDs []string, cluster *banzaicloudv1beta1.KafkaCluster, state interface{}) {
-	brokersState := cluster.Status.BrokersState
+func generateBrokerState(brokerIDs []string, status *banzaicloudv1beta1.KafkaClusterStatus, state interface{}) {
+	brokersState := status.BrokersState
 	if brokersState == nil {
 		brokersState = make(map[string]banzaicloudv1beta1.BrokerState, len(brokerIDs))
 	}
@@ -154,6 +149,8 @@ func generateBrokerState(brokerIDs []string, cluster *banzaicloudv1beta1.KafkaCl
 			brokerState.ConfigurationState = s
 		case banzaicloudv1beta1.PerBrokerConfigurationState:
 			brokerState.PerBrokerConfigurationState = s
+		case banzaicloudv1beta1.Log4jConfigHash:
+			brokerState.Log4jConfigHash = s
 		case map[string]banzaicloudv1beta1.VolumeState:
 			if brokerState.GracefulActionState.VolumeStates == nil {
 				brokerState.GracefulActionState.VolumeStates = make(map[string]banzaicloudv1beta1.VolumeState)
@@ -175,136 +172,132 @@ func generateBrokerState(brokerIDs []string, cluster *banzaicloudv1beta1.KafkaCl
 		}
 		brokersState[brokerID] = brokerState
 	}
-	cluster.Status.BrokersState = brokersState
+	status.BrokersState = brokersState
 }
 
 // DeleteStatus deletes the given broker state from the CR
 func DeleteStatus(c client.Client, brokerID string, cluster *banzaicloudv1beta1.KafkaCluster, logger logr.Logger) error {
-	typeMeta := cluster.TypeMeta
-
-	brokerStatus := cluster.Status.BrokersState
-
-	delete(brokerStatus, brokerID)
-
-	cluster.Status.BrokersState = brokerStatus
-
-	err := c.Status().Update(context.Background(), cluster)
-	if apierrors.IsNotFound(err) {
-		err = c.Update(context.Background(), cluster)
-	}
+	err := PatchKafkaClusterStatus(context.Background(), c, cluster,
+		func(status *banzaicloudv1beta1.KafkaClusterStatus) {
+			delete(status.BrokersState, brokerID)
+		})
 	if err != nil {
-		if !apierrors.IsConflict(err) {
-			return errors.WrapIff(err, "could not delete Kafka cluster broker %s state ", brokerID)
-		}
-		err := c.Get(context.TODO(), types.NamespacedName{
-			Namespace: cluster.Namespace,
-			Name:      cluster.Name,
-		}, cluster)
-		if err != nil {
-			return errors.WrapIf(err, "could not get config for updating status")
-		}
-		brokerStatus = cluster.Status.BrokersState
-
-		delete(brokerStatus, brokerID)
-
-		cluster.Status.BrokersState = brokerStatus
-		err = c.Status().Update(context.Background(), cluster)
-		if apierrors.IsNotFound(err) {
-			err = c.Update(context.Background(), cluster)
-		}
-		if err != nil {
-			return errors.WrapIff(err, "could not delete Kafka clusters broker %s state ", brokerID)
-		}
+		return errors.WrapIff(err, "could not delete Kafka cluster broker %s state ", brokerID)
 	}
-
-	// update loses the typeMeta of the config that's used later when setting ownerrefs
-	cluster.TypeMeta = typeMeta
 	logger.Info(fmt.Sprintf("Kafka broker %s state deleted", brokerID))
 	return nil
 }
 
-// UpdateCRStatus updates the cluster state
-func UpdateCRStatus(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, state interface{}, logger logr.Logger) error {
-	typeMeta := cluster.TypeMeta
-
-	switch s := state.(type) {
-	case banzaicloudv1beta1.ClusterState:
-		cluster.Status.State = s
-	case banzaicloudv1beta1.CruiseControlTopicStatus:
-		cluster.Status.CruiseControlTopicStatus = s
+// UpdateVolumeSnapshotStatus records the references of the VolumeSnapshots in the status of the cluster, the existing
+// references of the same snapshots are replaced
+func UpdateVolumeSnapshotStatus(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, snapshots []banzaicloudv1beta1.VolumeSnapshotReference, logger logr.Logger) error {
+	err := PatchKafkaClusterStatus(context.Background(), c, cluster,
+		func(status *banzaicloudv1beta1.KafkaClusterStatus) {
+			status.VolumeSnapshots = mergeVolumeSnapshotReferences(status.VolumeSnapshots, snapshots)
+		})
+	if err != nil {
+		return errors.WrapIf(err, "could not update volume snapshot status")
 	}
+	logger.V(1).Info("volume snapshot status updated", "snapshots", len(snapshots))
+	return nil
+}
 
-	err := c.Status().Update(context.Background(), cluster)
-	if apierrors.IsNotFound(err) {
-		err = c.Update(context.Background(), cluster)
+// RemoveVolumeSnapshotStatus drops the references of the deleted VolumeSnapshots from the status of the cluster
+func RemoveVolumeSnapshotStatus(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, names []string, logger logr.Logger) error {
+	removed := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		removed[name] = struct{}{}
 	}
+	err := PatchKafkaClusterStatus(context.Background(), c, cluster,
+		func(status *banzaicloudv1beta1.KafkaClusterStatus) {
+			var kept []banzaicloudv1beta1.VolumeSnapshotReference
+			for _, snapshot := range status.VolumeSnapshots {
+				if _, ok := removed[snapshot.Name]; !ok {
+					kept = append(kept, snapshot)
+				}
+			}
+			status.VolumeSnapshots = kept
+		})
 	if err != nil {
-		if !apierrors.IsConflict(err) {
-			return errors.WrapIf(err, "could not update CR state")
-		}
-		err := c.Get(context.TODO(), types.NamespacedName{
-			Namespace: cluster.Namespace,
-			Name:      cluster.Name,
-		}, cluster)
-		if err != nil {
-			return errors.WrapIf(err, "could not get config for updating status")
-		}
-		switch s := state.(type) {
-		case banzaicloudv1beta1.ClusterState:
-			cluster.Status.State = s
-		case banzaicloudv1beta1.CruiseControlTopicStatus:
-			cluster.Status.CruiseControlTopicStatus = s
-		}
+		return errors.WrapIf(err, "could not remove volume snapshots from the status")
+	}
+	logger.V(1).Info("volume snapshots removed from the status", "snapshots", len(names))
+	return nil
+}
 
-		err = c.Status().Update(context.Background(), cluster)
-		if apierrors.IsNotFound(err) {
-			err = c.Update(context.Background(), cluster)
-		}
-		if err != nil {
-			return errors.WrapIf(err, "could not update CR state")
+func mergeVolumeSnapshotReferences(current, snapshots []banzaicloudv1beta1.VolumeSnapshotReference) []banzaicloudv1beta1.VolumeSnapshotReference {
+	merged := make([]banzaicloudv1beta1.VolumeSnapshotReference, 0, len(current)+len(snapshots))
+	updated := make(map[string]banzaicloudv1beta1.VolumeSnapshotReference, len(snapshots))
+	for _, snapshot := range snapshots {
+		updated[snapshot.Name] = snapshot
+	}
+	for _, snapshot := range current {
+		if _, ok := updated[snapshot.Name]; !ok {
+			merged = append(merged, snapshot)
 		}
 	}
-	// update loses the typeMeta of the config that's used later when setting ownerrefs
-	cluster.TypeMeta = typeMeta
+	return append(merged, snapshots...)
+}
+
+// UpdateCRStatus updates the cluster state. The generation of the cluster is recorded as observed when the cluster
+// becomes running.
+func UpdateCRStatus(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, state interface{}, logger logr.Logger) error {
+	generation := cluster.GetGeneration()
+
+	err := PatchKafkaClusterStatus(context.Background(), c, cluster,
+		func(status *banzaicloudv1beta1.KafkaClusterStatus) {
+			switch s := state.(type) {
+			case banzaicloudv1beta1.ClusterState:
+				setClusterState(status, s, generation)
+			case banzaicloudv1beta1.CruiseControlTopicStatus:
+				status.CruiseControlTopicStatus = s
+			}
+		})
+	if err != nil {
+		return errors.WrapIf(err, "could not update CR state")
+	}
 	logger.Info("CR status updated", "status", state)
 	return nil
 }
 
+func setClusterState(status *banzaicloudv1beta1.KafkaClusterStatus, state banzaicloudv1beta1.ClusterState, generation int64) {
+	// A cluster without any state or broker state is being created, the clusters which existed before the
+	// Bootstrapping condition was introduced never get it
+	if status.State == "" && len(status.BrokersState) == 0 && state != banzaicloudv1beta1.KafkaClusterRunning {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               banzaicloudv1beta1.BootstrappingCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             banzaicloudv1beta1.ClusterCreatedReason,
+			Message:            "the brokers of the cluster are being created",
+			ObservedGeneration: generation,
+		})
+	}
+	status.State = state
+	if state == banzaicloudv1beta1.KafkaClusterRunning {
+		status.ObservedGeneration = generation
+		if meta.IsStatusConditionTrue(status.Conditions, banzaicloudv1beta1.BootstrappingCondition) {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               banzaicloudv1beta1.BootstrappingCondition,
+				Status:             metav1.ConditionFalse,
+				Reason:             banzaicloudv1beta1.ClusterRunningReason,
+				Message:            "the cluster has reached the running state",
+				ObservedGeneration: generation,
+			})
+		}
+	}
+}
+
 // UpdateRollingUpgradeState updates the state of the cluster with rolling upgrade info
 func UpdateRollingUpgradeState(c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, time time.Time, logger logr.Logger) error {
-	typeMeta := cluster.TypeMeta
-
 	timeStamp := time.Format("2006-01-02 15:04:05")
-	cluster.Status.RollingUpgrade.LastSuccess = timeStamp
 
-	err := c.Status().Update(context.Background(), cluster)
-	if apierrors.IsNotFound(err) {
-		err = c.Update(context.Background(), cluster)
-	}
+	err := PatchKafkaClusterStatus(context.Background(), c, cluster,
+		func(status *banzaicloudv1beta1.KafkaClusterStatus) {
+			status.RollingUpgrade.LastSuccess = timeStamp
+		})
 	if err != nil {
-		if !apierrors.IsConflict(err) {
-			return errors.WrapIf(err, "could not update rolling upgrade state")
-		}
-		err := c.Get(context.TODO(), types.NamespacedName{
-			Namespace: cluster.Namespace,
-			Name:      cluster.Name,
-		}, cluster)
-		if err != nil {
-			return errors.WrapIf(err, "could not get config for updating status")
-		}
-
-		cluster.Status.RollingUpgrade.LastSuccess = timeStamp
-
-		err = c.Status().Update(context.Background(), cluster)
-		if apierrors.IsNotFound(err) {
-			err = c.Update(context.Background(), cluster)
-		}
-		if err != nil {
-			return errors.WrapIf(err, "could not update rolling upgrade state")
-		}
+		return errors.WrapIf(err, "could not update rolling upgrade state")
 	}
-	// update loses the typeMeta of the config that's used later when setting ownerrefs
-	cluster.TypeMeta = typeMeta
 	logger.Info("Rolling upgrade status updated", "status", timeStamp)
 	return nil
 }
@@ -312,44 +305,16 @@ func UpdateRollingUpgradeState(c client.Client, cluster *banzaicloudv1beta1.Kafk
 func UpdateListenerStatuses(ctx context.Context, c client.Client, cluster *banzaicloudv1beta1.KafkaCluster, intListenerStatuses, extListenerStatuses map[string]banzaicloudv1beta1.ListenerStatusList) error {
 	logger := logr.FromContextOrDiscard(ctx)
 
-	typeMeta := cluster.TypeMeta
-
-	cluster.Status.ListenerStatuses = banzaicloudv1beta1.ListenerStatuses{
-		InternalListeners: intListenerStatuses,
-		ExternalListeners: extListenerStatuses,
-	}
-
-	err := c.Status().Update(ctx, cluster)
-	if apierrors.IsNotFound(err) {
-		err = c.Update(ctx, cluster)
-	}
+	err := PatchKafkaClusterStatus(ctx, c, cluster,
+		func(status *banzaicloudv1beta1.KafkaClusterStatus) {
+			status.ListenerStatuses = banzaicloudv1beta1.ListenerStatuses{
+				InternalListeners: intListenerStatuses,
+				ExternalListeners: extListenerStatuses,
+			}
+		})
 	if err != nil {
-		if !apierrors.IsConflict(err) {
-			return errors.WrapIf(err, "could not update listener status")
-		}
-		err := c.Get(ctx, types.NamespacedName{
-			Namespace: cluster.Namespace,
-			Name:      cluster.Name,
-		}, cluster)
-		if err != nil {
-			return errors.WrapIf(err, "could not get config for updating listener status")
-		}
-
-		cluster.Status.ListenerStatuses = banzaicloudv1beta1.ListenerStatuses{
-			InternalListeners: intListenerStatuses,
-			ExternalListeners: extListenerStatuses,
-		}
-
-		err = c.Status().Update(ctx, cluster)
-		if apierrors.IsNotFound(err) {
-			err = c.Update(ctx, cluster)
-		}
-		if err != nil {
-			return errors.WrapIf(err, "could not update listener statuses")
-		}
+		return errors.WrapIf(err, "could not update listener statuses")
 	}
-	// update loses the typeMeta of the config that's used later when setting ownerrefs
-	cluster.TypeMeta = typeMeta
 	logger.Info("updated listener statuses")
 	return nil
 }
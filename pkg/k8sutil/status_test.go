@@ -0,0 +1,118 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestUpdateCRStatusBootstrappingOfExistingCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	// A cluster created before the Bootstrapping condition was introduced has no observed generation either
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", Generation: 2},
+		Status: v1beta1.KafkaClusterStatus{
+			State:        v1beta1.KafkaClusterRunning,
+			BrokersState: map[string]v1beta1.BrokerState{"0": {}},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+	require.NoError(t, UpdateCRStatus(client, cluster, v1beta1.KafkaClusterReconciling, logr.Discard()))
+	assert.False(t, cluster.IsBootstrapping())
+}
+
+func TestUpdateCRStatusObservedGeneration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", Generation: 2},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+	require.NoError(t, UpdateCRStatus(client, cluster, v1beta1.KafkaClusterReconciling, logr.Discard()))
+	assert.Equal(t, int64(0), cluster.Status.ObservedGeneration)
+	assert.True(t, cluster.IsBootstrapping())
+
+	require.NoError(t, UpdateCRStatus(client, cluster, v1beta1.KafkaClusterRunning, logr.Discard()))
+	assert.Equal(t, int64(2), cluster.Status.ObservedGeneration)
+	assert.False(t, cluster.IsBootstrapping())
+
+	// The cluster is not bootstrapped again once it has been running
+	require.NoError(t, UpdateCRStatus(client, cluster, v1beta1.KafkaClusterReconciling, logr.Discard()))
+	assert.False(t, cluster.IsBootstrapping())
+
+	stored := &v1beta1.KafkaCluster{}
+	require.NoError(t, client.Get(context.Background(), types.NamespacedName{Name: "kafka", Namespace: "kafka"}, stored))
+	assert.Equal(t, int64(2), stored.Status.ObservedGeneration)
+}
+
+func TestPatchKafkaClusterStatusKeepsConcurrentChanges(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	cluster := &v1beta1.KafkaCluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "KafkaCluster", APIVersion: v1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{"0": {}, "1": {}},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	ctx := context.Background()
+
+	// Both writers work on the same, soon outdated, version of the cluster
+	first, second := cluster.DeepCopy(), cluster.DeepCopy()
+	require.NoError(t, UpdateBrokerStatus(client, []string{"0"}, first, v1beta1.ConfigInSync, logr.Discard()))
+	require.NoError(t, PatchKafkaClusterStatus(ctx, client, second,
+		func(status *v1beta1.KafkaClusterStatus) {
+			state := status.BrokersState["1"]
+			state.GracefulActionState.CruiseControlState = v1beta1.GracefulUpscaleSucceeded
+			status.BrokersState["1"] = state
+		}))
+	assert.Equal(t, "KafkaCluster", second.Kind)
+
+	require.NoError(t, DeleteStatus(client, "1", cluster, logr.Discard()))
+
+	stored := &v1beta1.KafkaCluster{}
+	require.NoError(t, client.Get(ctx, types.NamespacedName{Name: "kafka", Namespace: "kafka"}, stored))
+	assert.Equal(t, v1beta1.ConfigInSync, stored.Status.BrokersState["0"].ConfigurationState)
+	assert.NotContains(t, stored.Status.BrokersState, "1")
+
+	// The list fields are replaced by the patch, the one based on the outdated version is applied to the latest one
+	first, second = stored.DeepCopy(), stored.DeepCopy()
+	for _, writer := range []struct {
+		cluster *v1beta1.KafkaCluster
+		id      int32
+	}{{first, 3}, {second, 4}} {
+		id := writer.id
+		require.NoError(t, PatchKafkaClusterStatus(ctx, client, writer.cluster, func(status *v1beta1.KafkaClusterStatus) {
+			status.RetiredBrokerIDs = append(status.RetiredBrokerIDs, id)
+		}))
+	}
+	require.NoError(t, client.Get(ctx, types.NamespacedName{Name: "kafka", Namespace: "kafka"}, stored))
+	assert.Equal(t, []int32{3, 4}, stored.Status.RetiredBrokerIDs)
+}
@@ -53,12 +53,33 @@ type KafkaClient interface {
 	// OutOfSyncReplicas returns the list of unique out of sync replica (broker) ids
 	OutOfSyncReplicas() ([]int32, error)
 
+	// ProbeBrokerHealth returns the health of the given brokers as reported by the Admin API
+	ProbeBrokerHealth([]int32) (map[int32]BrokerHealth, error)
+
+	// DescribeLogDirUsage returns the disk usage of the log directories of the given brokers
+	DescribeLogDirUsage([]int32) (map[int32][]LogDirUsage, error)
+
+	// PartitionDistribution returns the number of partition replicas and leaders hosted by the brokers
+	PartitionDistribution() (map[int32]BrokerPartitions, error)
+
+	// PartitionReplicas returns the replicas and the offline replicas of the partitions
+	PartitionReplicas() ([]PartitionReplicas, error)
+
+	// ConsumerGroupLags returns the lag of the consumer groups on the given topic
+	ConsumerGroupLags(string) (map[string]int64, error)
+
 	AlterPerBrokerConfig(int32, map[string]*string, bool) error
 	DescribePerBrokerConfig(int32, []string) ([]*sarama.ConfigEntry, error)
 
 	AlterClusterWideConfig(map[string]*string, bool) error
 	DescribeClusterWideConfig() ([]sarama.ConfigEntry, error)
 
+	DescribeDefaultClientQuotas(sarama.QuotaEntityType) (map[string]float64, error)
+	AlterDefaultClientQuotas(sarama.QuotaEntityType, map[string]*float64, bool) error
+
+	DescribeBrokerLoggers(int32) (map[string]string, error)
+	AlterBrokerLoggers(int32, map[string]string) error
+
 	TopicMetaToStatus(meta *sarama.TopicMetadata) *v1alpha1.KafkaTopicStatus
 
 	Open() error
@@ -15,7 +15,9 @@
 package kafkaclient
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 
 	"emperror.dev/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -25,6 +27,7 @@ import (
 	"github.com/banzaicloud/koperator/pkg/pki"
 	"github.com/banzaicloud/koperator/pkg/util"
 	clientutil "github.com/banzaicloud/koperator/pkg/util/client"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
 )
 
 const kafkaDefaultTimeout = int64(5)
@@ -56,6 +59,20 @@ func ClusterConfig(client client.Client, cluster *v1beta1.KafkaCluster) (*KafkaC
 		if err != nil {
 			return conf, err
 		}
+		// the additional trusted CAs are needed to accept brokers presenting certificates which are not issued
+		// by the CA of the cluster, e.g. X.509 SVIDs on SPIFFE listeners
+		if len(cluster.Spec.ListenersConfig.AdditionalTrustedCAs) > 0 {
+			additionalCAs, err := pkicommon.GetAdditionalTrustedCAs(context.TODO(), client, cluster)
+			if err != nil {
+				return conf, err
+			}
+			if tlsConfig.RootCAs == nil {
+				tlsConfig.RootCAs = x509.NewCertPool()
+			}
+			for _, caCert := range additionalCAs {
+				tlsConfig.RootCAs.AddCert(caCert)
+			}
+		}
 		conf.UseSSL = true
 		conf.TLSConfig = tlsConfig
 	}
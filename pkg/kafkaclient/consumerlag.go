@@ -0,0 +1,66 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+)
+
+// ConsumerGroupLags returns the lag of the consumer groups which have committed offsets on the topic. The lag of a
+// group is the sum of the differences between the high watermarks and the committed offsets of the partitions.
+func (k *kafkaClient) ConsumerGroupLags(topic string) (map[string]int64, error) {
+	partitions, err := k.client.Partitions(topic)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not fetch partitions", "topic", topic)
+	}
+	highWatermarks := make(map[int32]int64, len(partitions))
+	for _, partition := range partitions {
+		offset, err := k.client.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not fetch high watermark", "topic", topic, "partition", partition)
+		}
+		highWatermarks[partition] = offset
+	}
+
+	groups, err := k.admin.ListConsumerGroups()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list consumer groups")
+	}
+	lags := make(map[string]int64)
+	for group := range groups {
+		offsets, err := k.admin.ListConsumerGroupOffsets(group, map[string][]int32{topic: partitions})
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not fetch consumer group offsets", "group", group)
+		}
+		var lag int64
+		committed := false
+		for _, partition := range partitions {
+			block := offsets.GetBlock(topic, partition)
+			// Offset -1 means that the group has not committed an offset for the partition
+			if block == nil || block.Err != sarama.ErrNoError || block.Offset < 0 {
+				continue
+			}
+			committed = true
+			if partitionLag := highWatermarks[partition] - block.Offset; partitionLag > 0 {
+				lag += partitionLag
+			}
+		}
+		if committed {
+			lags[group] = lag
+		}
+	}
+	return lags, nil
+}
@@ -0,0 +1,51 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+func TestConsumerGroupLags(t *testing.T) {
+	client := newOpenedMockClient()
+	metadata := client.client.(*mockClusterAdmin)
+	metadata.mockHighWatermarks = map[string]map[int32]int64{
+		"test-topic": {0: 100, 1: 50},
+	}
+	admin := client.admin.(*mockClusterAdmin)
+	admin.mockGroupOffsets = map[string]map[string]map[int32]int64{
+		"lagging":   {"test-topic": {0: 40, 1: 50}},
+		"partial":   {"test-topic": {1: 20}},
+		"unrelated": {"other-topic": {0: 10}},
+	}
+
+	lags, err := client.ConsumerGroupLags("test-topic")
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	expected := map[string]int64{"lagging": 60, "partial": 30}
+	if !reflect.DeepEqual(lags, expected) {
+		t.Errorf("Expected %v, got: %v", expected, lags)
+	}
+
+	failing, _ := newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	client.client = failing.(sarama.Client)
+	if _, err := client.ConsumerGroupLags("test-topic"); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+
+	kafkautil "github.com/banzaicloud/koperator/pkg/util/kafka"
+)
+
+// BrokerPartitions is the number of partition replicas and leaders hosted by a broker
+type BrokerPartitions struct {
+	Replicas int
+	Leaders  int
+}
+
+// PartitionReplicas are the replicas of a partition, the ones which are hosted by brokers that are not alive and the
+// in-sync ones. MinInSyncReplicas is the min.insync.replicas of the topic, 0 when the topic uses the broker default.
+type PartitionReplicas struct {
+	Topic             string
+	Partition         int32
+	Replicas          []int32
+	OfflineReplicas   []int32
+	Isr               []int32
+	MinInSyncReplicas int32
+}
+
+// PartitionDistribution returns the number of partition replicas and leaders hosted by the brokers of the cluster
+func (k *kafkaClient) PartitionDistribution() (map[int32]BrokerPartitions, error) {
+	_, topicsMeta, err := k.describeAllTopics()
+	if err != nil {
+		return nil, err
+	}
+	distribution := make(map[int32]BrokerPartitions)
+	for _, topicMeta := range topicsMeta {
+		for _, partition := range topicMeta.Partitions {
+			for _, replica := range partition.Replicas {
+				brokerPartitions := distribution[replica]
+				brokerPartitions.Replicas++
+				distribution[replica] = brokerPartitions
+			}
+			if partition.Leader >= 0 {
+				brokerPartitions := distribution[partition.Leader]
+				brokerPartitions.Leaders++
+				distribution[partition.Leader] = brokerPartitions
+			}
+		}
+	}
+	return distribution, nil
+}
+
+// PartitionReplicas returns the replicas, the offline and the in-sync replicas of the partitions of the cluster
+func (k *kafkaClient) PartitionReplicas() ([]PartitionReplicas, error) {
+	topics, topicsMeta, err := k.describeAllTopics()
+	if err != nil {
+		return nil, err
+	}
+	var partitions []PartitionReplicas
+	for _, topicMeta := range topicsMeta {
+		minInSyncReplicas := topicMinInSyncReplicas(topics[topicMeta.Name])
+		for _, partition := range topicMeta.Partitions {
+			partitions = append(partitions, PartitionReplicas{
+				Topic:             topicMeta.Name,
+				Partition:         partition.ID,
+				Replicas:          partition.Replicas,
+				OfflineReplicas:   partition.OfflineReplicas,
+				Isr:               partition.Isr,
+				MinInSyncReplicas: minInSyncReplicas,
+			})
+		}
+	}
+	return partitions, nil
+}
+
+// topicMinInSyncReplicas returns the min.insync.replicas set for the topic, 0 when it is not set
+func topicMinInSyncReplicas(topic sarama.TopicDetail) int32 {
+	value := topic.ConfigEntries[kafkautil.KafkaConfigMinInSyncReplicas]
+	if value == nil {
+		return 0
+	}
+	minInSyncReplicas, err := strconv.ParseInt(*value, 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(minInSyncReplicas)
+}
+
+// describeAllTopics returns the details and the metadata of every topic of the cluster
+func (k *kafkaClient) describeAllTopics() (map[string]sarama.TopicDetail, []*sarama.TopicMetadata, error) {
+	topics, err := k.admin.ListTopics()
+	if err != nil {
+		return nil, nil, errors.WrapIf(err, "could not list topics")
+	}
+	if len(topics) == 0 {
+		return topics, nil, nil
+	}
+	topicNames := make([]string, 0, len(topics))
+	for name := range topics {
+		topicNames = append(topicNames, name)
+	}
+	topicsMeta, err := k.admin.DescribeTopics(topicNames)
+	if err != nil {
+		return nil, nil, errors.WrapIf(err, "could not describe topics")
+	}
+	return topics, topicsMeta, nil
+}
@@ -0,0 +1,73 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+func TestPartitionDistribution(t *testing.T) {
+	client := newOpenedMockClient()
+	admin := client.admin.(*mockClusterAdmin)
+	admin.mockTopics["test-topic"] = sarama.TopicDetail{NumPartitions: 2}
+	admin.mockPartitions = map[string][]*sarama.PartitionMetadata{
+		"test-topic": {
+			{ID: 0, Leader: 0, Replicas: []int32{0, 1}, Isr: []int32{0, 1}},
+			{ID: 1, Leader: -1, Replicas: []int32{1, 2}, Isr: []int32{}},
+		},
+	}
+
+	distribution, err := client.PartitionDistribution()
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	expected := map[int32]BrokerPartitions{
+		0: {Replicas: 1, Leaders: 1},
+		1: {Replicas: 2},
+		2: {Replicas: 1},
+	}
+	if !reflect.DeepEqual(distribution, expected) {
+		t.Errorf("Expected %v, got: %v", expected, distribution)
+	}
+}
+
+func TestPartitionReplicas(t *testing.T) {
+	client := newOpenedMockClient()
+	admin := client.admin.(*mockClusterAdmin)
+	minInSyncReplicas := "2"
+	admin.mockTopics["test-topic"] = sarama.TopicDetail{NumPartitions: 2,
+		ConfigEntries: map[string]*string{"min.insync.replicas": &minInSyncReplicas}}
+	admin.mockPartitions = map[string][]*sarama.PartitionMetadata{
+		"test-topic": {
+			{ID: 0, Leader: 0, Replicas: []int32{0, 1}, Isr: []int32{0, 1}},
+			{ID: 1, Leader: 1, Replicas: []int32{1, 2}, Isr: []int32{1}, OfflineReplicas: []int32{2}},
+		},
+	}
+
+	partitions, err := client.PartitionReplicas()
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	expected := []PartitionReplicas{
+		{Topic: "test-topic", Partition: 0, Replicas: []int32{0, 1}, Isr: []int32{0, 1}, MinInSyncReplicas: 2},
+		{Topic: "test-topic", Partition: 1, Replicas: []int32{1, 2}, OfflineReplicas: []int32{2}, Isr: []int32{1}, MinInSyncReplicas: 2},
+	}
+	if !reflect.DeepEqual(partitions, expected) {
+		t.Errorf("Expected %v, got: %v", expected, partitions)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"sort"
+
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+)
+
+// healthProbeMaxTopics is the maximum number of topics described by a health probe, so that probing the brokers of a
+// cluster with many topics does not send a metadata request of every partition on each reconcile
+const healthProbeMaxTopics = 200
+
+// BrokerHealth is the health of a broker as reported by the Kafka Admin API
+type BrokerHealth struct {
+	// Registered is true when the broker is part of the cluster metadata
+	Registered bool
+	// OfflineLogDirs are the log directories of the broker which cannot be used due to storage errors
+	OfflineLogDirs []string
+	// UnderReplicatedPartitions is the number of partitions led by the broker which have replicas out of the ISR,
+	// the same value as the UnderReplicatedPartitions metric of the broker when the cluster has no more topics than
+	// the probe describes
+	UnderReplicatedPartitions int
+}
+
+// IsHealthy returns true when the broker is registered, all of its log directories are online and all of the
+// partitions it leads are fully replicated
+func (h BrokerHealth) IsHealthy() bool {
+	return h.Registered && len(h.OfflineLogDirs) == 0 && h.UnderReplicatedPartitions == 0
+}
+
+// ProbeBrokerHealth returns the health of the given brokers by describing the cluster, the log directories of the
+// brokers and the partitions of the topics through the Admin API, so it works without scraping the metrics of the
+// brokers. Only the first healthProbeMaxTopics topics, in alphabetical order, are described.
+func (k *kafkaClient) ProbeBrokerHealth(brokerIDs []int32) (map[int32]BrokerHealth, error) {
+	brokers, _, err := k.admin.DescribeCluster()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not describe kafka cluster")
+	}
+	registered := make(map[int32]struct{}, len(brokers))
+	for _, broker := range brokers {
+		registered[broker.ID()] = struct{}{}
+	}
+
+	health := make(map[int32]BrokerHealth, len(brokerIDs))
+	registeredIDs := make([]int32, 0, len(brokerIDs))
+	for _, brokerID := range brokerIDs {
+		_, ok := registered[brokerID]
+		health[brokerID] = BrokerHealth{Registered: ok}
+		if ok {
+			registeredIDs = append(registeredIDs, brokerID)
+		}
+	}
+	if len(registeredIDs) == 0 {
+		return health, nil
+	}
+
+	logDirs, err := k.admin.DescribeLogDirs(registeredIDs)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not describe log dirs")
+	}
+	for brokerID, dirs := range logDirs {
+		brokerHealth, ok := health[brokerID]
+		if !ok {
+			continue
+		}
+		for _, dir := range dirs {
+			if dir.ErrorCode != sarama.ErrNoError {
+				brokerHealth.OfflineLogDirs = append(brokerHealth.OfflineLogDirs, dir.Path)
+			}
+		}
+		health[brokerID] = brokerHealth
+	}
+
+	topics, err := k.admin.ListTopics()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list topics")
+	}
+	if len(topics) == 0 {
+		return health, nil
+	}
+	topicNames := make([]string, 0, len(topics))
+	for name := range topics {
+		topicNames = append(topicNames, name)
+	}
+	sort.Strings(topicNames)
+	if len(topicNames) > healthProbeMaxTopics {
+		topicNames = topicNames[:healthProbeMaxTopics]
+	}
+	topicsMeta, err := k.admin.DescribeTopics(topicNames)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not describe topics")
+	}
+	for _, topicMeta := range topicsMeta {
+		for _, partition := range topicMeta.Partitions {
+			if len(partition.Isr) >= len(partition.Replicas) {
+				continue
+			}
+			if brokerHealth, ok := health[partition.Leader]; ok {
+				brokerHealth.UnderReplicatedPartitions++
+				health[partition.Leader] = brokerHealth
+			}
+		}
+	}
+	return health, nil
+}
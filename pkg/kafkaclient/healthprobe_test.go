@@ -0,0 +1,87 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+func TestProbeBrokerHealth(t *testing.T) {
+	client := newOpenedMockClient()
+	admin := client.admin.(*mockClusterAdmin)
+	admin.mockTopics["test-topic"] = sarama.TopicDetail{NumPartitions: 2}
+	admin.mockTopics["other-topic"] = sarama.TopicDetail{NumPartitions: 1}
+	admin.mockPartitions = map[string][]*sarama.PartitionMetadata{
+		"test-topic": {
+			{ID: 0, Leader: 0, Replicas: []int32{0, 1}, Isr: []int32{0}},
+			{ID: 1, Leader: 0, Replicas: []int32{0, 1}, Isr: []int32{0, 1}},
+		},
+		"other-topic": {
+			{ID: 0, Leader: 0, Replicas: []int32{0, 1}, Isr: []int32{0}},
+		},
+	}
+	admin.mockLogDirs = map[int32][]sarama.DescribeLogDirsResponseDirMetadata{
+		0: {
+			{ErrorCode: sarama.ErrNoError, Path: "/kafka-logs/kafka"},
+			{ErrorCode: sarama.ErrKafkaStorageError, Path: "/kafka-logs2/kafka"},
+		},
+	}
+
+	health, err := client.ProbeBrokerHealth([]int32{0, 1})
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	expected := map[int32]BrokerHealth{
+		0: {Registered: true, OfflineLogDirs: []string{"/kafka-logs2/kafka"}, UnderReplicatedPartitions: 2},
+		1: {Registered: false},
+	}
+	if !reflect.DeepEqual(health, expected) {
+		t.Errorf("Expected %v, got: %v", expected, health)
+	}
+	if health[0].IsHealthy() || health[1].IsHealthy() {
+		t.Error("Expected unhealthy brokers, got:", health)
+	}
+
+	admin.mockLogDirs = nil
+	for i := 0; i < healthProbeMaxTopics; i++ {
+		admin.mockTopics[fmt.Sprintf("a-topic-%03d", i)] = sarama.TopicDetail{NumPartitions: 1}
+	}
+	health, err = client.ProbeBrokerHealth([]int32{0})
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if health[0].UnderReplicatedPartitions != 0 {
+		t.Error("Expected only the first topics to be described, got:", health[0])
+	}
+
+	admin.mockPartitions = nil
+	admin.mockTopics = make(map[string]sarama.TopicDetail)
+	health, err = client.ProbeBrokerHealth([]int32{0})
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if !health[0].IsHealthy() {
+		t.Error("Expected healthy broker, got:", health[0])
+	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, err := client.ProbeBrokerHealth([]int32{0}); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
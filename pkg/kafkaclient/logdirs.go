@@ -0,0 +1,54 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+)
+
+// LogDirUsage is the disk usage of a log directory of a broker as reported by the Kafka Admin API
+type LogDirUsage struct {
+	// Path is the absolute path of the log directory
+	Path string
+	// UsedBytes is the total size of the partition replicas stored in the log directory, including the future
+	// replicas being moved into it
+	UsedBytes int64
+	// Offline is true when the log directory cannot be used due to storage errors
+	Offline bool
+}
+
+// DescribeLogDirUsage returns the disk usage of the log directories of the given brokers
+func (k *kafkaClient) DescribeLogDirUsage(brokerIDs []int32) (map[int32][]LogDirUsage, error) {
+	logDirs, err := k.admin.DescribeLogDirs(brokerIDs)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not describe log dirs")
+	}
+	usage := make(map[int32][]LogDirUsage, len(logDirs))
+	for brokerID, dirs := range logDirs {
+		brokerUsage := make([]LogDirUsage, 0, len(dirs))
+		for _, dir := range dirs {
+			dirUsage := LogDirUsage{Path: dir.Path, Offline: dir.ErrorCode != sarama.ErrNoError}
+			for _, topic := range dir.Topics {
+				for _, partition := range topic.Partitions {
+					dirUsage.UsedBytes += partition.Size
+				}
+			}
+			brokerUsage = append(brokerUsage, dirUsage)
+		}
+		usage[brokerID] = brokerUsage
+	}
+	return usage, nil
+}
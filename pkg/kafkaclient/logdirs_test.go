@@ -0,0 +1,56 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+func TestDescribeLogDirUsage(t *testing.T) {
+	client := newOpenedMockClient()
+	admin := client.admin.(*mockClusterAdmin)
+	admin.mockLogDirs = map[int32][]sarama.DescribeLogDirsResponseDirMetadata{
+		0: {
+			{ErrorCode: sarama.ErrNoError, Path: "/kafka-logs/kafka", Topics: []sarama.DescribeLogDirsResponseTopic{
+				{Topic: "test-topic", Partitions: []sarama.DescribeLogDirsResponsePartition{{PartitionID: 0, Size: 100}, {PartitionID: 1, Size: 50}}},
+				{Topic: "other-topic", Partitions: []sarama.DescribeLogDirsResponsePartition{{PartitionID: 0, Size: 10}}},
+			}},
+			{ErrorCode: sarama.ErrKafkaStorageError, Path: "/kafka-logs2/kafka"},
+		},
+	}
+
+	usage, err := client.DescribeLogDirUsage([]int32{0, 1})
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	expected := map[int32][]LogDirUsage{
+		0: {
+			{Path: "/kafka-logs/kafka", UsedBytes: 160},
+			{Path: "/kafka-logs2/kafka", Offline: true},
+		},
+		1: {},
+	}
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("Expected %v, got: %v", expected, usage)
+	}
+
+	admin.failOps = true
+	if _, err = client.DescribeLogDirUsage([]int32{0}); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+)
+
+// DescribeBrokerLoggers returns the levels of the loggers of the broker by the name of the loggers
+func (k *kafkaClient) DescribeBrokerLoggers(brokerID int32) (map[string]string, error) {
+	entries, err := k.admin.DescribeConfig(sarama.ConfigResource{Type: sarama.BrokerLoggerResource, Name: strconv.Itoa(int(brokerID))})
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not describe broker loggers", "brokerId", brokerID)
+	}
+	levels := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		levels[entry.Name] = entry.Value
+	}
+	return levels, nil
+}
+
+// AlterBrokerLoggers sets the levels of the given loggers of the running broker
+func (k *kafkaClient) AlterBrokerLoggers(brokerID int32, levels map[string]string) error {
+	entries := make(map[string]sarama.IncrementalAlterConfigsEntry, len(levels))
+	for logger, level := range levels {
+		level := level
+		entries[logger] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationSet, Value: &level}
+	}
+	err := k.admin.IncrementalAlterConfig(sarama.BrokerLoggerResource, strconv.Itoa(int(brokerID)), entries, false)
+	return errors.WrapIfWithDetails(err, "could not alter broker loggers", "brokerId", brokerID)
+}
@@ -0,0 +1,55 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+func TestBrokerLoggers(t *testing.T) {
+	client := newOpenedMockClient()
+
+	if err := client.AlterBrokerLoggers(0, map[string]string{"root": "INFO", "kafka.controller": "TRACE"}); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	levels, err := client.DescribeBrokerLoggers(0)
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	expected := map[string]string{"root": "INFO", "kafka.controller": "TRACE"}
+	if !reflect.DeepEqual(levels, expected) {
+		t.Errorf("Expected %v, got: %v", expected, levels)
+	}
+
+	levels, err = client.DescribeBrokerLoggers(1)
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if len(levels) != 0 {
+		t.Error("Expected no loggers for broker 1, got:", levels)
+	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, err := client.DescribeBrokerLoggers(0); err == nil {
+		t.Error("Expected error, got nil")
+	}
+	if err := client.AlterBrokerLoggers(0, map[string]string{"root": "DEBUG"}); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+// MinInSyncReplicasViolation is a partition whose in-sync replicas would drop below its min.insync.replicas
+type MinInSyncReplicasViolation struct {
+	Topic             string
+	Partition         int32
+	InSyncReplicas    int32
+	MinInSyncReplicas int32
+}
+
+// MinInSyncReplicasViolations returns the partitions whose in-sync replicas would drop below their
+// min.insync.replicas if the given brokers went offline. Only the partitions having in-sync replicas on the given
+// brokers are affected, the default min.insync.replicas is used for the topics which do not set it.
+func MinInSyncReplicasViolations(partitions []PartitionReplicas, brokerIDs []int32, defaultMinInSyncReplicas int32) []MinInSyncReplicasViolation {
+	offline := make(map[int32]struct{}, len(brokerIDs))
+	for _, brokerID := range brokerIDs {
+		offline[brokerID] = struct{}{}
+	}
+	var violations []MinInSyncReplicasViolation
+	for _, partition := range partitions {
+		var remaining int32
+		affected := false
+		for _, brokerID := range partition.Isr {
+			if _, ok := offline[brokerID]; ok {
+				affected = true
+				continue
+			}
+			remaining++
+		}
+		minInSyncReplicas := partition.MinInSyncReplicas
+		if minInSyncReplicas <= 0 {
+			minInSyncReplicas = defaultMinInSyncReplicas
+		}
+		if affected && remaining < minInSyncReplicas {
+			violations = append(violations, MinInSyncReplicasViolation{
+				Topic:             partition.Topic,
+				Partition:         partition.Partition,
+				InSyncReplicas:    remaining,
+				MinInSyncReplicas: minInSyncReplicas,
+			})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,43 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMinInSyncReplicasViolations(t *testing.T) {
+	partitions := []PartitionReplicas{
+		{Topic: "orders", Partition: 0, Replicas: []int32{0, 1, 2}, Isr: []int32{0, 1, 2}, MinInSyncReplicas: 2},
+		{Topic: "orders", Partition: 1, Replicas: []int32{0, 1, 2}, Isr: []int32{1, 2}, MinInSyncReplicas: 2},
+		{Topic: "payments", Partition: 0, Replicas: []int32{1, 2}, Isr: []int32{1, 2}},
+		// the partition is not hosted by the broker
+		{Topic: "payments", Partition: 1, Replicas: []int32{0, 2}, Isr: []int32{0}},
+	}
+
+	violations := MinInSyncReplicasViolations(partitions, []int32{1}, 2)
+	expected := []MinInSyncReplicasViolation{
+		{Topic: "orders", Partition: 1, InSyncReplicas: 1, MinInSyncReplicas: 2},
+		{Topic: "payments", Partition: 0, InSyncReplicas: 1, MinInSyncReplicas: 2},
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Errorf("Expected %v, got: %v", expected, violations)
+	}
+
+	if violations := MinInSyncReplicasViolations(partitions, []int32{1}, 1); len(violations) != 1 {
+		t.Errorf("Expected a single violation, got: %v", violations)
+	}
+}
@@ -16,6 +16,7 @@ package kafkaclient
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -28,9 +29,18 @@ type mockClusterAdmin struct {
 	sarama.ClusterAdmin
 	sarama.Client
 	sync.Mutex
-	failOps    bool
-	mockTopics map[string]sarama.TopicDetail
-	mockACLs   map[sarama.Resource]*sarama.ResourceAcls
+	failOps     bool
+	mockTopics  map[string]sarama.TopicDetail
+	mockACLs    map[sarama.Resource]*sarama.ResourceAcls
+	mockQuotas  map[sarama.QuotaEntityType]map[string]float64
+	mockLoggers map[string]map[string]string
+	// mockPartitions are the partitions of the topics returned by DescribeTopics
+	mockPartitions map[string][]*sarama.PartitionMetadata
+	mockLogDirs    map[int32][]sarama.DescribeLogDirsResponseDirMetadata
+	// mockHighWatermarks are the high watermarks of the partitions of the topics
+	mockHighWatermarks map[string]map[int32]int64
+	// mockGroupOffsets are the committed offsets of the consumer groups on the partitions of the topics
+	mockGroupOffsets map[string]map[string]map[int32]int64
 }
 
 func NewMockFromCluster(client client.Client, cluster *v1beta1.KafkaCluster) (KafkaClient, func(), error) {
@@ -40,9 +50,11 @@ func NewMockFromCluster(client client.Client, cluster *v1beta1.KafkaCluster) (Ka
 
 func newEmptyMockClusterAdmin(failOps bool) *mockClusterAdmin {
 	return &mockClusterAdmin{
-		mockTopics: make(map[string]sarama.TopicDetail, 0),
-		mockACLs:   make(map[sarama.Resource]*sarama.ResourceAcls, 0),
-		failOps:    failOps,
+		mockTopics:  make(map[string]sarama.TopicDetail, 0),
+		mockACLs:    make(map[sarama.Resource]*sarama.ResourceAcls, 0),
+		mockQuotas:  make(map[sarama.QuotaEntityType]map[string]float64, 0),
+		mockLoggers: make(map[string]map[string]string, 0),
+		failOps:     failOps,
 	}
 }
 
@@ -92,6 +104,64 @@ func (m *mockClusterAdmin) DescribeCluster() ([]*sarama.Broker, int32, error) {
 	return []*sarama.Broker{{}}, 0, nil
 }
 
+func (m *mockClusterAdmin) Partitions(topic string) ([]int32, error) {
+	if m.failOps {
+		return nil, errors.New("bad partitions")
+	}
+	partitions := make([]int32, 0, len(m.mockHighWatermarks[topic]))
+	for partition := range m.mockHighWatermarks[topic] {
+		partitions = append(partitions, partition)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+	return partitions, nil
+}
+
+func (m *mockClusterAdmin) GetOffset(topic string, partition int32, offsetTime int64) (int64, error) {
+	if m.failOps {
+		return 0, errors.New("bad get offset")
+	}
+	return m.mockHighWatermarks[topic][partition], nil
+}
+
+func (m *mockClusterAdmin) ListConsumerGroups() (map[string]string, error) {
+	if m.failOps {
+		return nil, errors.New("bad list consumer groups")
+	}
+	groups := make(map[string]string, len(m.mockGroupOffsets))
+	for group := range m.mockGroupOffsets {
+		groups[group] = "consumer"
+	}
+	return groups, nil
+}
+
+func (m *mockClusterAdmin) ListConsumerGroupOffsets(group string, topicPartitions map[string][]int32) (*sarama.OffsetFetchResponse, error) {
+	if m.failOps {
+		return nil, errors.New("bad list consumer group offsets")
+	}
+	response := &sarama.OffsetFetchResponse{}
+	for topic, partitions := range topicPartitions {
+		for _, partition := range partitions {
+			offset, ok := m.mockGroupOffsets[group][topic][partition]
+			if !ok {
+				offset = -1
+			}
+			response.AddBlock(topic, partition, &sarama.OffsetFetchResponseBlock{Offset: offset, Err: sarama.ErrNoError})
+		}
+	}
+	return response, nil
+}
+
+func (m *mockClusterAdmin) DescribeLogDirs(brokerIDs []int32) (map[int32][]sarama.DescribeLogDirsResponseDirMetadata, error) {
+	if m.failOps {
+		return nil, errors.New("bad describe log dirs")
+	}
+	logDirs := make(map[int32][]sarama.DescribeLogDirsResponseDirMetadata, len(brokerIDs))
+	for _, brokerID := range brokerIDs {
+		logDirs[brokerID] = m.mockLogDirs[brokerID]
+	}
+	return logDirs, nil
+}
+
 func (m *mockClusterAdmin) ListTopics() (map[string]sarama.TopicDetail, error) {
 	m.Lock()
 	defer m.Unlock()
@@ -120,28 +190,28 @@ func (m *mockClusterAdmin) DescribeTopics(topics []string) ([]*sarama.TopicMetad
 	if m.failOps {
 		return []*sarama.TopicMetadata{}, errors.New("bad describe topics")
 	}
-	switch topics[0] {
-	case "test-topic", "already-created-topic":
-		return []*sarama.TopicMetadata{
-			{
-				Name:       topics[0],
+	metadata := make([]*sarama.TopicMetadata, 0, len(topics))
+	for _, topic := range topics {
+		if partitions, ok := m.mockPartitions[topic]; ok {
+			metadata = append(metadata, &sarama.TopicMetadata{Name: topic, Partitions: partitions, Err: sarama.ErrNoError})
+			continue
+		}
+		switch topic {
+		case "test-topic", "already-created-topic":
+			metadata = append(metadata, &sarama.TopicMetadata{
+				Name:       topic,
 				Partitions: []*sarama.PartitionMetadata{{}},
 				Err:        sarama.ErrNoError,
-			},
-		}, nil
-	case "not-exists":
-		return []*sarama.TopicMetadata{}, nil
-	case "with-error":
-		return []*sarama.TopicMetadata{
-			{
+			})
+		case "with-error":
+			metadata = append(metadata, &sarama.TopicMetadata{
 				Name:       "with-error",
 				Partitions: []*sarama.PartitionMetadata{{}},
 				Err:        sarama.ErrUnknown,
-			},
-		}, nil
-	default:
-		return []*sarama.TopicMetadata{}, nil
+			})
+		}
 	}
+	return metadata, nil
 }
 
 func (m *mockClusterAdmin) CreateTopic(name string, detail *sarama.TopicDetail, validateOnly bool) error {
@@ -240,7 +310,86 @@ func (m *mockClusterAdmin) DeleteACL(filter sarama.AclFilter, validateOnly bool)
 }
 
 func (m *mockClusterAdmin) DescribeConfig(resource sarama.ConfigResource) ([]sarama.ConfigEntry, error) {
-	return []sarama.ConfigEntry{}, nil
+	m.Lock()
+	defer m.Unlock()
+
+	if resource.Type != sarama.BrokerLoggerResource {
+		return []sarama.ConfigEntry{}, nil
+	}
+	if m.failOps {
+		return nil, errors.New("bad describe config")
+	}
+	entries := []sarama.ConfigEntry{}
+	for logger, level := range m.mockLoggers[resource.Name] {
+		entries = append(entries, sarama.ConfigEntry{Name: logger, Value: level})
+	}
+	return entries, nil
+}
+
+func (m *mockClusterAdmin) IncrementalAlterConfig(resourceType sarama.ConfigResourceType, name string,
+	entries map[string]sarama.IncrementalAlterConfigsEntry, validateOnly bool) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return errors.New("bad incremental alter config")
+	}
+	if validateOnly || resourceType != sarama.BrokerLoggerResource {
+		return nil
+	}
+	levels, ok := m.mockLoggers[name]
+	if !ok {
+		levels = make(map[string]string)
+		m.mockLoggers[name] = levels
+	}
+	for key, entry := range entries {
+		levels[key] = *entry.Value
+	}
+	return nil
+}
+
+func (m *mockClusterAdmin) DescribeClientQuotas(components []sarama.QuotaFilterComponent, strict bool) ([]sarama.DescribeClientQuotasEntry, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return nil, errors.New("bad describe client quotas")
+	}
+	var entries []sarama.DescribeClientQuotasEntry
+	for _, component := range components {
+		if values, ok := m.mockQuotas[component.EntityType]; ok && len(values) > 0 {
+			entries = append(entries, sarama.DescribeClientQuotasEntry{
+				Entity: []sarama.QuotaEntityComponent{{EntityType: component.EntityType, MatchType: component.MatchType}},
+				Values: values,
+			})
+		}
+	}
+	return entries, nil
+}
+
+func (m *mockClusterAdmin) AlterClientQuotas(entity []sarama.QuotaEntityComponent, op sarama.ClientQuotasOp, validateOnly bool) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failOps {
+		return errors.New("bad alter client quotas")
+	}
+	if validateOnly {
+		return nil
+	}
+	for _, component := range entity {
+		values, ok := m.mockQuotas[component.EntityType]
+		if !ok {
+			values = make(map[string]float64)
+			m.mockQuotas[component.EntityType] = values
+		}
+		if op.Remove {
+			delete(values, op.Key)
+		} else {
+			values[op.Key] = op.Value
+		}
+	}
+	return nil
 }
 
 func (m *mockClusterAdmin) Controller() (*sarama.Broker, error) {
@@ -0,0 +1,53 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"github.com/Shopify/sarama"
+)
+
+// DescribeDefaultClientQuotas returns the quotas of the default entity of the given entity type, e.g. the quotas
+// of the users which have no quota of their own
+func (k *kafkaClient) DescribeDefaultClientQuotas(entityType sarama.QuotaEntityType) (map[string]float64, error) {
+	entries, err := k.admin.DescribeClientQuotas([]sarama.QuotaFilterComponent{
+		{EntityType: entityType, MatchType: sarama.QuotaMatchDefault},
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	quotas := make(map[string]float64)
+	for _, entry := range entries {
+		for key, value := range entry.Values {
+			quotas[key] = value
+		}
+	}
+	return quotas, nil
+}
+
+// AlterDefaultClientQuotas sets the quotas of the default entity of the given entity type, the quotas
+// with nil value are removed
+func (k *kafkaClient) AlterDefaultClientQuotas(entityType sarama.QuotaEntityType, quotas map[string]*float64, validateOnly bool) error {
+	entity := []sarama.QuotaEntityComponent{{EntityType: entityType, MatchType: sarama.QuotaMatchDefault}}
+	for key, value := range quotas {
+		op := sarama.ClientQuotasOp{Key: key, Remove: value == nil}
+		if value != nil {
+			op.Value = *value
+		}
+		if err := k.admin.AlterClientQuotas(entity, op, validateOnly); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaclient
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+func TestDefaultClientQuotas(t *testing.T) {
+	client := newOpenedMockClient()
+
+	producerByteRate := float64(1048576)
+	requestPercentage := float64(50)
+	if err := client.AlterDefaultClientQuotas(sarama.QuotaEntityUser, map[string]*float64{
+		"producer_byte_rate": &producerByteRate,
+		"request_percentage": &requestPercentage,
+	}, false); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+
+	quotas, err := client.DescribeDefaultClientQuotas(sarama.QuotaEntityUser)
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	expected := map[string]float64{"producer_byte_rate": producerByteRate, "request_percentage": requestPercentage}
+	if !reflect.DeepEqual(quotas, expected) {
+		t.Errorf("Expected %v, got: %v", expected, quotas)
+	}
+
+	if err := client.AlterDefaultClientQuotas(sarama.QuotaEntityUser, map[string]*float64{"request_percentage": nil}, false); err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	quotas, err = client.DescribeDefaultClientQuotas(sarama.QuotaEntityUser)
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	expected = map[string]float64{"producer_byte_rate": producerByteRate}
+	if !reflect.DeepEqual(quotas, expected) {
+		t.Errorf("Expected %v, got: %v", expected, quotas)
+	}
+
+	quotas, err = client.DescribeDefaultClientQuotas(sarama.QuotaEntityClientID)
+	if err != nil {
+		t.Error("Expected no error, got:", err)
+	}
+	if len(quotas) != 0 {
+		t.Error("Expected no client ID quotas, got:", quotas)
+	}
+
+	client.admin, _ = newMockClusterAdminFailOps([]string{}, sarama.NewConfig())
+	if _, err := client.DescribeDefaultClientQuotas(sarama.QuotaEntityUser); err == nil {
+		t.Error("Expected error, got nil")
+	}
+	if err := client.AlterDefaultClientQuotas(sarama.QuotaEntityUser, map[string]*float64{"producer_byte_rate": &producerByteRate}, false); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaconnect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"emperror.dev/errors"
+)
+
+const (
+	// ConnectorStateRunning is the state of the running connectors and tasks
+	ConnectorStateRunning = "RUNNING"
+	// ConnectorStatePaused is the state of the paused connectors and tasks
+	ConnectorStatePaused = "PAUSED"
+	// ConnectorStateFailed is the state of the failed connectors and tasks
+	ConnectorStateFailed = "FAILED"
+
+	defaultRequestTimeout = 30 * time.Second
+)
+
+// ErrConnectorNotFound is returned when the connector does not exist in the Kafka Connect cluster
+var ErrConnectorNotFound = errors.Sentinel("connector not found")
+
+// Client manages the connectors of a Kafka Connect cluster through the REST API of the workers
+type Client interface {
+	PutConnectorConfig(ctx context.Context, name string, config map[string]string) error
+	GetConnectorStatus(ctx context.Context, name string) (ConnectorStatus, error)
+	PauseConnector(ctx context.Context, name string) error
+	ResumeConnector(ctx context.Context, name string) error
+	DeleteConnector(ctx context.Context, name string) error
+}
+
+// ConnectorStatus is the state of a connector and its tasks reported by the Kafka Connect cluster
+type ConnectorStatus struct {
+	Name      string       `json:"name"`
+	Connector TaskState    `json:"connector"`
+	Tasks     []TaskStatus `json:"tasks"`
+}
+
+// TaskState is the state of a connector or a task
+type TaskState struct {
+	State    string `json:"state"`
+	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace,omitempty"`
+}
+
+// TaskStatus is the state of a task of a connector
+type TaskStatus struct {
+	TaskState
+	ID int32 `json:"id"`
+}
+
+type restClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewClient returns a client for the REST API of the Kafka Connect cluster available at the given endpoint
+func NewClient(endpoint string) Client {
+	return &restClient{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: defaultRequestTimeout},
+	}
+}
+
+// PutConnectorConfig creates the connector or updates its configuration
+func (c *restClient) PutConnectorConfig(ctx context.Context, name string, config map[string]string) error {
+	body, err := json.Marshal(config)
+	if err != nil {
+		return errors.WrapIf(err, "could not marshal connector configuration")
+	}
+	_, err = c.do(ctx, http.MethodPut, "/connectors/"+url.PathEscape(name)+"/config", body)
+	return err
+}
+
+// GetConnectorStatus returns the state of the connector and its tasks
+func (c *restClient) GetConnectorStatus(ctx context.Context, name string) (ConnectorStatus, error) {
+	status := ConnectorStatus{}
+	body, err := c.do(ctx, http.MethodGet, "/connectors/"+url.PathEscape(name)+"/status", nil)
+	if err != nil {
+		return status, err
+	}
+	if err = json.Unmarshal(body, &status); err != nil {
+		return status, errors.WrapIfWithDetails(err, "could not parse connector status", "connector", name)
+	}
+	return status, nil
+}
+
+// PauseConnector pauses the connector and its tasks
+func (c *restClient) PauseConnector(ctx context.Context, name string) error {
+	_, err := c.do(ctx, http.MethodPut, "/connectors/"+url.PathEscape(name)+"/pause", nil)
+	return err
+}
+
+// ResumeConnector resumes the paused connector and its tasks
+func (c *restClient) ResumeConnector(ctx context.Context, name string) error {
+	_, err := c.do(ctx, http.MethodPut, "/connectors/"+url.PathEscape(name)+"/resume", nil)
+	return err
+}
+
+// DeleteConnector deletes the connector, it returns ErrConnectorNotFound when the connector does not exist
+func (c *restClient) DeleteConnector(ctx context.Context, name string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/connectors/"+url.PathEscape(name), nil)
+	return err
+}
+
+func (c *restClient) do(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not create Kafka Connect request")
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "Kafka Connect request failed", "method", method, "path", path)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not read Kafka Connect response", "method", method, "path", path)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrConnectorNotFound
+	case resp.StatusCode >= http.StatusBadRequest:
+		return nil, errors.NewWithDetails(fmt.Sprintf("Kafka Connect request failed: %s", errorMessage(respBody)),
+			"method", method, "path", path, "status", resp.StatusCode)
+	}
+	return respBody, nil
+}
+
+// errorMessage returns the message of the error response of the REST API
+func errorMessage(body []byte) string {
+	resp := struct {
+		Message string `json:"message"`
+	}{}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Message == "" {
+		return string(body)
+	}
+	return resp.Message
+}
@@ -0,0 +1,90 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaconnect
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+	var requests []string
+	var putConfig map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		switch {
+		case req.Method == http.MethodPut && req.URL.Path == "/connectors/orders-sink/config":
+			body, _ := io.ReadAll(req.Body)
+			_ = json.Unmarshal(body, &putConfig)
+			w.WriteHeader(http.StatusCreated)
+		case req.Method == http.MethodGet && req.URL.Path == "/connectors/orders-sink/status":
+			_, _ = w.Write([]byte(`{"name":"orders-sink","connector":{"state":"RUNNING","worker_id":"10.0.0.1:8083"},` +
+				`"tasks":[{"id":0,"state":"FAILED","worker_id":"10.0.0.2:8083","trace":"org.apache.kafka.connect.errors.ConnectException"}],"type":"sink"}`))
+		case req.Method == http.MethodPut && req.URL.Path == "/connectors/orders-sink/pause":
+			w.WriteHeader(http.StatusAccepted)
+		case req.Method == http.MethodPut && req.URL.Path == "/connectors/invalid/config":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error_code":400,"message":"Connector configuration is invalid"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":404,"message":"Connector not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(server.URL)
+
+	assert.NoError(t, client.PutConnectorConfig(ctx, "orders-sink", map[string]string{"connector.class": "org.example.OrdersSink"}))
+	assert.Equal(t, map[string]string{"connector.class": "org.example.OrdersSink"}, putConfig)
+
+	status, err := client.GetConnectorStatus(ctx, "orders-sink")
+	assert.NoError(t, err)
+	assert.Equal(t, ConnectorStatus{
+		Name:      "orders-sink",
+		Connector: TaskState{State: ConnectorStateRunning, WorkerID: "10.0.0.1:8083"},
+		Tasks: []TaskStatus{{
+			ID:        0,
+			TaskState: TaskState{State: ConnectorStateFailed, WorkerID: "10.0.0.2:8083", Trace: "org.apache.kafka.connect.errors.ConnectException"},
+		}},
+	}, status)
+
+	assert.NoError(t, client.PauseConnector(ctx, "orders-sink"))
+
+	err = client.PutConnectorConfig(ctx, "invalid", map[string]string{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Connector configuration is invalid")
+	assert.False(t, errors.Is(err, ErrConnectorNotFound))
+
+	assert.True(t, errors.Is(client.DeleteConnector(ctx, "missing"), ErrConnectorNotFound))
+	_, err = client.GetConnectorStatus(ctx, "missing")
+	assert.True(t, errors.Is(err, ErrConnectorNotFound))
+
+	assert.Equal(t, []string{
+		"PUT /connectors/orders-sink/config",
+		"GET /connectors/orders-sink/status",
+		"PUT /connectors/orders-sink/pause",
+		"PUT /connectors/invalid/config",
+		"DELETE /connectors/missing",
+		"GET /connectors/missing/status",
+	}, requests)
+}
@@ -0,0 +1,163 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certmanagerpki
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	certv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	certmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+)
+
+const (
+	brokerIdPlaceholder    = "{brokerId}"
+	clusterNamePlaceholder = "{clusterName}"
+	namespacePlaceholder   = "{namespace}"
+)
+
+// listenerCertificatesForCluster returns the JKS password secrets and the cert-manager Certificates of the listeners
+// which request a dedicated server certificate
+func listenerCertificatesForCluster(cluster *v1beta1.KafkaCluster, extListenerStatuses map[string]v1beta1.ListenerStatusList) ([]runtime.Object, error) {
+	var listeners []v1beta1.CommonListenerSpec
+	for _, iListener := range cluster.Spec.ListenersConfig.InternalListeners {
+		listeners = append(listeners, iListener.CommonListenerSpec)
+	}
+	for _, eListener := range cluster.Spec.ListenersConfig.ExternalListeners {
+		listeners = append(listeners, eListener.CommonListenerSpec)
+	}
+
+	var objects []runtime.Object
+	for _, listener := range listeners {
+		if !listener.HasDedicatedCertManagerCertificate() {
+			continue
+		}
+		passwordSecret, err := listenerJKSPasswordSecret(cluster, listener)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, passwordSecret, listenerCertificate(cluster, listener, extListenerStatuses[listener.Name]))
+	}
+	return objects, nil
+}
+
+// listenerJKSPasswordSecret returns the secret of the listener certificate populated with the password of the JKS
+// keystore so that cert-manager can create the keystore into it
+func listenerJKSPasswordSecret(cluster *v1beta1.KafkaCluster, listener v1beta1.CommonListenerSpec) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: templates.ObjectMeta(pkicommon.ListenerServerCertSecretName(cluster.Name, listener),
+			pkicommon.LabelsForKafkaPKI(cluster.Name, cluster.Namespace), cluster),
+		Data: map[string][]byte{},
+	}
+	secret, err := certutil.EnsureSecretPassJKS(secret)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.InternalError{}, err, "could not inject listener secret with jks password")
+	}
+	return secret, nil
+}
+
+// listenerCertificate returns the cert-manager Certificate holding the server certificate of the listener
+func listenerCertificate(cluster *v1beta1.KafkaCluster, listener v1beta1.CommonListenerSpec, listenerStatuses v1beta1.ListenerStatusList) *certv1.Certificate {
+	config := listener.CertManager
+	secretName := pkicommon.ListenerServerCertSecretName(cluster.Name, listener)
+
+	issuerRef := cluster.Spec.ListenersConfig.SSLSecrets.IssuerRef
+	if config.IssuerRef != nil {
+		issuerRef = config.IssuerRef
+	}
+	if issuerRef == nil {
+		issuerRef = &certmeta.ObjectReference{
+			Name: fmt.Sprintf(pkicommon.BrokerClusterIssuerTemplate, cluster.Namespace, cluster.Name),
+			Kind: certv1.ClusterIssuerKind,
+		}
+	}
+
+	dnsNames := pkicommon.GetInternalDNSNames(cluster)
+	for _, status := range listenerStatuses {
+		dnsNames = append(dnsNames, strings.Split(status.Address, ":")[0])
+	}
+	dnsNames = append(dnsNames, expandDNSNameTemplates(cluster, config.DNSNames)...)
+
+	return &certv1.Certificate{
+		ObjectMeta: templates.ObjectMeta(secretName, pkicommon.LabelsForKafkaPKI(cluster.Name, cluster.Namespace), cluster),
+		Spec: certv1.CertificateSpec{
+			SecretName: secretName,
+			PrivateKey: &certv1.CertificatePrivateKey{
+				Encoding: certv1.PKCS8,
+			},
+			CommonName:  pkicommon.EnsureValidCommonNameLen(pkicommon.GetCommonName(cluster)),
+			DNSNames:    sortAndDedupe(dnsNames),
+			Duration:    config.Duration,
+			RenewBefore: config.RenewBefore,
+			Usages:      []certv1.KeyUsage{certv1.UsageClientAuth, certv1.UsageServerAuth},
+			IssuerRef:   *issuerRef.DeepCopy(),
+			Keystores: &certv1.CertificateKeystores{
+				JKS: &certv1.JKSKeystore{
+					Create: true,
+					PasswordSecretRef: certmeta.SecretKeySelector{
+						LocalObjectReference: certmeta.LocalObjectReference{
+							Name: secretName,
+						},
+						Key: v1alpha1.PasswordKey,
+					},
+				},
+			},
+		},
+	}
+}
+
+// expandDNSNameTemplates replaces the placeholders of the DNS name templates, the ones containing the broker ID
+// placeholder are expanded for every broker of the cluster
+func expandDNSNameTemplates(cluster *v1beta1.KafkaCluster, dnsNameTemplates []string) []string {
+	replacer := strings.NewReplacer(clusterNamePlaceholder, cluster.Name, namespacePlaceholder, cluster.Namespace)
+
+	var dnsNames []string
+	for _, template := range dnsNameTemplates {
+		template = replacer.Replace(template)
+		if !strings.Contains(template, brokerIdPlaceholder) {
+			dnsNames = append(dnsNames, template)
+			continue
+		}
+		for _, broker := range cluster.Spec.Brokers {
+			dnsNames = append(dnsNames, strings.ReplaceAll(template, brokerIdPlaceholder, strconv.Itoa(int(broker.Id))))
+		}
+	}
+	return dnsNames
+}
+
+func sortAndDedupe(names []string) []string {
+	ret := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
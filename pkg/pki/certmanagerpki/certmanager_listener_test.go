@@ -0,0 +1,152 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certmanagerpki
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	certv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	certmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func newListenerCertificateCluster() *v1beta1.KafkaCluster {
+	cluster := newMockCluster()
+	cluster.Spec.Brokers = []v1beta1.Broker{{Id: 0}, {Id: 1}}
+	cluster.Spec.ListenersConfig.ExternalListeners = []v1beta1.ExternalListenerConfig{
+		{CommonListenerSpec: v1beta1.CommonListenerSpec{
+			Name: "public",
+			Type: v1beta1.SecurityProtocolSSL,
+			CertManager: &v1beta1.ListenerCertManagerConfig{
+				IssuerRef:   &certmeta.ObjectReference{Name: "letsencrypt", Kind: certv1.ClusterIssuerKind},
+				DNSNames:    []string{"broker-{brokerId}.{clusterName}.example.com", "{namespace}.example.com"},
+				Duration:    &metav1.Duration{Duration: 720 * time.Hour},
+				RenewBefore: &metav1.Duration{Duration: 240 * time.Hour},
+			},
+		}},
+		{CommonListenerSpec: v1beta1.CommonListenerSpec{
+			Name:        "custom",
+			Type:        v1beta1.SecurityProtocolSSL,
+			CertManager: &v1beta1.ListenerCertManagerConfig{},
+			ServerSSLCertSecret: &corev1.LocalObjectReference{
+				Name: "custom-secret",
+			},
+		}},
+	}
+	cluster.Spec.ListenersConfig.InternalListeners = append(cluster.Spec.ListenersConfig.InternalListeners,
+		v1beta1.InternalListenerConfig{CommonListenerSpec: v1beta1.CommonListenerSpec{
+			Name:        "private",
+			Type:        v1beta1.SecurityProtocolSSL,
+			CertManager: &v1beta1.ListenerCertManagerConfig{},
+		}})
+	return cluster
+}
+
+func TestListenerCertificatesForCluster(t *testing.T) {
+	cluster := newListenerCertificateCluster()
+	extListenerStatuses := map[string]v1beta1.ListenerStatusList{
+		"public": {{Name: "broker-0", Address: "kafka.example.com:29092"}},
+	}
+
+	objects, err := listenerCertificatesForCluster(cluster, extListenerStatuses)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if len(objects) != 4 {
+		t.Fatal("Expected a secret and a certificate for both listeners, got:", len(objects))
+	}
+
+	privateSecret, ok := objects[0].(*corev1.Secret)
+	if !ok || privateSecret.Name != "test-private-server-certificate" || len(privateSecret.Data[v1alpha1.PasswordKey]) == 0 {
+		t.Error("Expected secret with JKS password for the private listener, got:", objects[0])
+	}
+
+	privateCert := objects[1].(*certv1.Certificate)
+	expectedIssuer := certmeta.ObjectReference{Name: "test-namespace-test-issuer", Kind: certv1.ClusterIssuerKind}
+	if !reflect.DeepEqual(privateCert.Spec.IssuerRef, expectedIssuer) {
+		t.Error("Expected the cluster issuer for the private listener, got:", privateCert.Spec.IssuerRef)
+	}
+	if privateCert.Spec.Duration != nil || privateCert.Spec.RenewBefore != nil {
+		t.Error("Expected the default duration for the private listener, got:", privateCert.Spec.Duration, privateCert.Spec.RenewBefore)
+	}
+
+	publicCert := objects[3].(*certv1.Certificate)
+	if publicCert.Name != "test-public-server-certificate" || publicCert.Spec.SecretName != "test-public-server-certificate" {
+		t.Error("Expected certificate for the public listener, got:", publicCert.Name)
+	}
+	if publicCert.Spec.IssuerRef.Name != "letsencrypt" {
+		t.Error("Expected the issuer of the listener, got:", publicCert.Spec.IssuerRef)
+	}
+	if publicCert.Spec.Duration.Duration != 720*time.Hour || publicCert.Spec.RenewBefore.Duration != 240*time.Hour {
+		t.Error("Expected the duration of the listener, got:", publicCert.Spec.Duration, publicCert.Spec.RenewBefore)
+	}
+	for _, dnsName := range []string{"kafka.example.com", "broker-0.test.example.com", "broker-1.test.example.com", "test-namespace.example.com"} {
+		found := false
+		for _, name := range publicCert.Spec.DNSNames {
+			if name == dnsName {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected DNS name %s in %v", dnsName, publicCert.Spec.DNSNames)
+		}
+	}
+}
+
+func TestReconcilePKIListenerCertificates(t *testing.T) {
+	ctx := context.Background()
+	manager, err := newMock(newListenerCertificateCluster())
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if err := manager.ReconcilePKI(ctx, map[string]v1beta1.ListenerStatusList{}); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	cert := &certv1.Certificate{}
+	if err := manager.client.Get(ctx, client.ObjectKey{Name: "test-public-server-certificate", Namespace: testNamespace}, cert); err != nil {
+		t.Error("Expected listener certificate to be created, got:", err)
+	}
+
+	// The existing certificate is updated when the listener addresses and its lifetime change
+	manager.cluster.Spec.ListenersConfig.ExternalListeners[0].CertManager.RenewBefore = &metav1.Duration{Duration: 120 * time.Hour}
+	if err := manager.ReconcilePKI(ctx, map[string]v1beta1.ListenerStatusList{
+		"public": {{Name: "broker-0", Address: "kafka.example.com:29092"}},
+	}); err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if err := manager.client.Get(ctx, client.ObjectKey{Name: "test-public-server-certificate", Namespace: testNamespace}, cert); err != nil {
+		t.Fatal("Expected listener certificate to exist, got:", err)
+	}
+	if cert.Spec.RenewBefore == nil || cert.Spec.RenewBefore.Duration != 120*time.Hour {
+		t.Error("Expected the renewal time of the certificate to be updated, got:", cert.Spec.RenewBefore)
+	}
+	found := false
+	for _, name := range cert.Spec.DNSNames {
+		if name == "kafka.example.com" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the address of the listener in the updated DNS names, got:", cert.Spec.DNSNames)
+	}
+}
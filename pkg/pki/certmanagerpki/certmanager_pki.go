@@ -96,6 +96,11 @@ func (c *certManager) ReconcilePKI(ctx context.Context, extListenerStatuses map[
 	if err != nil {
 		return err
 	}
+	listenerCertificates, err := listenerCertificatesForCluster(c.cluster, extListenerStatuses)
+	if err != nil {
+		return err
+	}
+	resources = append(resources, listenerCertificates...)
 
 	for _, o := range resources {
 		if err := reconcile(ctx, c.client, o); err != nil {
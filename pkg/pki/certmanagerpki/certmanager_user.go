@@ -123,11 +123,11 @@ func (c *certManager) getUserSecret(ctx context.Context, user *v1alpha1.KafkaUse
 		return secret, errorfactory.New(errorfactory.APIFailure{}, err, "failed to get user secret")
 	}
 	if user.Spec.IncludeJKS {
-		if len(secret.Data) != 6 {
+		if len(secret.Data) < 6 {
 			return secret, errorfactory.New(errorfactory.ResourceNotReady{}, err, "user secret not populated yet")
 		}
 	} else {
-		if len(secret.Data) != 3 {
+		if len(secret.Data) < 3 {
 			return secret, errorfactory.New(errorfactory.ResourceNotReady{}, err, "user secret not populated yet")
 		}
 	}
@@ -68,7 +68,13 @@ func reconcileCertificate(ctx context.Context, client client.Client, cert *certv
 		}
 		return client.Create(ctx, cert)
 	}
-	return nil
+	// The DNS names, the issuer and the lifetime of the certificate follow the changes of the cluster and its
+	// listeners, cert-manager reissues the certificate when its spec is updated
+	if reflect.DeepEqual(obj.Spec, cert.Spec) {
+		return nil
+	}
+	obj.Spec = cert.Spec
+	return client.Update(ctx, obj)
 }
 
 // reconcileSecret ensures a Kubernetes secret
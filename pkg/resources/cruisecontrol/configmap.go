@@ -42,7 +42,19 @@ import (
 
 const MinLogDirSizeInMB = int64(1)
 
-func (r *Reconciler) configMap(clientPass string, capacityConfig string, log logr.Logger) runtime.Object {
+// topicsExcludedFromPartitionMovementConfig is the Cruise Control configuration of the regular expression matching
+// the topics excluded from partition movements when a request does not specify the excluded topics
+const topicsExcludedFromPartitionMovementConfig = "topics.excluded.from.partition.movement"
+
+const (
+	anomalyNotifierClassKey   = "anomaly.notifier.class"
+	slackAnomalyNotifierClass = "com.linkedin.kafka.cruisecontrol.detector.notifier.SlackSelfHealingNotifier"
+	slackNotifierWebhookKey   = "slack.self.healing.notifier.webhook"
+	slackNotifierChannelKey   = "slack.self.healing.notifier.channel"
+	slackNotifierUserKey      = "slack.self.healing.notifier.user"
+)
+
+func (r *Reconciler) configMap(clientPass, capacityConfig, log4jConfig string, log logr.Logger) runtime.Object {
 	ccConfig := properties.NewProperties()
 
 	// Add base Cruise Control configuration
@@ -52,6 +64,38 @@ func (r *Reconciler) configMap(clientPass string, capacityConfig string, log log
 	}
 	ccConfig.Merge(conf)
 
+	// Add the typed settings unless they are overridden in the base configuration
+	settingsConf, err := settingsConfig(r.KafkaCluster.Spec.CruiseControlConfig.Settings)
+	if err != nil {
+		log.Error(err, "generating Cruise Control configuration from the settings failed")
+	} else {
+		ccConfig.MergeDefaults(settingsConf)
+	}
+
+	// Make Cruise Control post the detected anomalies to the operator
+	notifierConf, err := anomalyNotifierConfig(r.KafkaCluster)
+	if err != nil {
+		log.Error(err, "generating anomaly notifier configuration for Cruise Control failed")
+	} else {
+		ccConfig.Merge(notifierConf)
+	}
+
+	// Exclude the topics from the partition movements of self-healing unless it is configured explicitly
+	excludedTopics := r.KafkaCluster.Spec.CruiseControlConfig.CruiseControlOperationSpec.GetSelfHealingExcludedTopics()
+	if _, found := ccConfig.Get(topicsExcludedFromPartitionMovementConfig); !found && excludedTopics != "" {
+		if err = ccConfig.Set(topicsExcludedFromPartitionMovementConfig, excludedTopics); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' in Cruise Control configuration failed", topicsExcludedFromPartitionMovementConfig), "config", excludedTopics)
+		}
+	}
+
+	// Add sample store configuration
+	sampleStoreConf, err := sampleStoreConfig(r.KafkaCluster, ccConfig)
+	if err != nil {
+		log.Error(err, "generating sample store configuration for Cruise Control failed")
+	} else {
+		ccConfig.Merge(sampleStoreConf)
+	}
+
 	bootstrapServers, err := kafkautils.GetBootstrapServersService(r.KafkaCluster)
 	if err != nil {
 		log.Error(err, "getting Kafka bootstrap servers for Cruise Control failed")
@@ -84,12 +128,46 @@ func (r *Reconciler) configMap(clientPass string, capacityConfig string, log log
 			"cruisecontrol.properties": ccConfig.String(),
 			"capacity.json":            capacityConfig,
 			"clusterConfigs.json":      r.KafkaCluster.Spec.CruiseControlConfig.ClusterConfig,
-			"log4j.properties":         r.KafkaCluster.Spec.CruiseControlConfig.GetCCLog4jConfig(),
+			log4jConfigKey:             log4jConfig,
 		},
 	}
 	return configMap
 }
 
+// settingsConfig returns the Cruise Control configuration of the typed settings
+func settingsConfig(settings *v1beta1.CruiseControlSettings) (*properties.Properties, error) {
+	config := properties.NewProperties()
+	for key, value := range settings.Properties() {
+		if err := config.Set(key, value); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not set Cruise Control property", "key", key)
+		}
+	}
+	return config, nil
+}
+
+// anomalyNotifierConfig returns the Cruise Control configuration which makes the Slack notifier of Cruise Control post
+// the anomalies to the alert receiver of the operator. The channel is required by the notifier, it is set to the name
+// of the Kafka cluster.
+func anomalyNotifierConfig(cluster *v1beta1.KafkaCluster) (*properties.Properties, error) {
+	config := properties.NewProperties()
+	notifier := cluster.Spec.CruiseControlConfig.AnomalyNotifier
+	if notifier == nil {
+		return config, nil
+	}
+	notifierConfig := map[string]string{
+		anomalyNotifierClassKey: slackAnomalyNotifierClass,
+		slackNotifierWebhookKey: notifier.GetWebhookURL(cluster.GetNamespace(), cluster.GetName()),
+		slackNotifierChannelKey: cluster.GetName(),
+		slackNotifierUserKey:    "cruise-control",
+	}
+	for key, value := range notifierConfig {
+		if err := config.Set(key, value); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
 func generateSSLConfig(kafkaCluster v1beta1.KafkaClusterSpec, clientPass string, log logr.Logger) *properties.Properties {
 	config := properties.NewProperties()
 	if kafkaCluster.IsClientSSLSecretPresent() && util.IsSSLEnabledForInternalCommunication(kafkaCluster.ListenersConfig.InternalListeners) {
@@ -22,8 +22,12 @@ import (
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/util"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
 )
 
 //nolint:funlen
@@ -1017,3 +1021,73 @@ func TestGenerateCapacityConfigWithUserProvidedInput(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigMapSettings(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				Config: "num.concurrent.leader.movements=500\n",
+				Settings: &v1beta1.CruiseControlSettings{
+					NumConcurrentPartitionMovementsPerBroker: util.Int32Pointer(5),
+					NumConcurrentLeaderMovements:             util.Int32Pointer(1000),
+					PartitionMetricsWindowMs:                 util.Int64Pointer(300000),
+					AnomalyNotifierClass:                     "com.example.Notifier",
+				},
+			},
+		},
+	}
+	r := &Reconciler{Reconciler: resources.Reconciler{KafkaCluster: cluster}}
+
+	configMap := r.configMap("", "", "", logr.Discard()).(*v1.ConfigMap)
+	ccConfig, err := properties.NewFromString(configMap.Data["cruisecontrol.properties"])
+	if err != nil {
+		t.Fatal("could not parse the Cruise Control configuration", err)
+	}
+
+	expected := map[string]string{
+		"num.concurrent.partition.movements.per.broker": "5",
+		// the base configuration takes precedence
+		"num.concurrent.leader.movements": "500",
+		"partition.metrics.window.ms":     "300000",
+		"anomaly.notifier.class":          "com.example.Notifier",
+	}
+	for key, value := range expected {
+		if property, found := ccConfig.Get(key); !found || property.Value() != value {
+			t.Errorf("Expected %s=%s, got: %v", key, value, property)
+		}
+	}
+}
+
+func TestAnomalyNotifierConfig(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				Config: "anomaly.notifier.class=com.linkedin.kafka.cruisecontrol.detector.notifier.SelfHealingNotifier\n",
+				AnomalyNotifier: &v1beta1.CruiseControlAnomalyNotifier{
+					ReceiverURL: "http://kafka-operator-alertmanager.kafka.svc:9001/",
+				},
+			},
+		},
+	}
+	r := &Reconciler{Reconciler: resources.Reconciler{KafkaCluster: cluster}}
+
+	configMap := r.configMap("", "", "", logr.Discard()).(*v1.ConfigMap)
+	ccConfig, err := properties.NewFromString(configMap.Data["cruisecontrol.properties"])
+	if err != nil {
+		t.Fatal("could not parse the Cruise Control configuration", err)
+	}
+
+	expected := map[string]string{
+		// the notifier overrides the base configuration
+		"anomaly.notifier.class":              "com.linkedin.kafka.cruisecontrol.detector.notifier.SlackSelfHealingNotifier",
+		"slack.self.healing.notifier.webhook": "http://kafka-operator-alertmanager.kafka.svc:9001/cruisecontrol/anomalies/kafka/kafka",
+		"slack.self.healing.notifier.channel": "kafka",
+	}
+	for key, value := range expected {
+		if property, found := ccConfig.Get(key); !found || property.Value() != value {
+			t.Errorf("Expected %s=%s, got: %v", key, value, property)
+		}
+	}
+}
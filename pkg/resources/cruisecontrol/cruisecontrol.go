@@ -23,12 +23,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/errorfactory"
 	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
 	"github.com/banzaicloud/koperator/pkg/resources"
 	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
 	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
@@ -54,6 +56,7 @@ type CapacityConfigAnnotation string
 // Reconciler implements the Component Reconciler
 type Reconciler struct {
 	resources.Reconciler
+	kafkaClientProvider kafkaclient.Provider
 }
 
 func ccLabelSelector(kafkaCluster string) map[string]string {
@@ -64,12 +67,14 @@ func ccLabelSelector(kafkaCluster string) map[string]string {
 }
 
 // New creates a new reconciler for CC
-func New(client client.Client, cluster *v1beta1.KafkaCluster) *Reconciler {
+func New(client client.Client, cluster *v1beta1.KafkaCluster, kafkaClientProvider kafkaclient.Provider, recorder record.EventRecorder) *Reconciler {
 	return &Reconciler{
 		Reconciler: resources.Reconciler{
 			Client:       client,
 			KafkaCluster: cluster,
+			Recorder:     recorder,
 		},
+		kafkaClientProvider: kafkaClientProvider,
 	}
 }
 
@@ -89,8 +94,20 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		}
 	}
 
+	// The client certificate of the operator is issued through a KafkaUser so that it is renewed before it expires
+	if r.KafkaCluster.Spec.CruiseControlConfig.ClientTLS != nil {
+		o := pkicommon.CruiseControlClientUserForCluster(r.KafkaCluster)
+		if err = k8sutil.Reconcile(log, r.Client, o, r.KafkaCluster); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", o.GetObjectKind().GroupVersionKind())
+		}
+	}
+
 	if r.KafkaCluster.Spec.CruiseControlConfig.CruiseControlEndpoint == "" {
-		genErr := generateCCTopic(r.KafkaCluster, r.Client, log.WithName("generateCCTopic"))
+		genErr := r.generateCCTopics(context.Background(), log.WithName("generateCCTopic"))
+		if genErr == nil && r.KafkaCluster.Spec.CruiseControlConfig.IsSampleStoreEnabled() &&
+			r.KafkaCluster.Spec.CruiseControlConfig.SampleStore.VerifyTopics {
+			genErr = r.verifySampleStoreTopics()
+		}
 		if genErr != nil {
 			updateErr := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, v1beta1.CruiseControlTopicNotReady, log)
 			return errors.Combine(genErr, updateErr)
@@ -129,19 +146,24 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 				return errors.WrapIf(err, "failed to generate capacity config")
 			}
 
-			o = r.configMap(clientPass, capacityConfig, log)
-			err = k8sutil.Reconcile(log, r.Client, o, r.KafkaCluster)
+			log4jConfig, err := r.log4jConfig(context.Background())
+			if err != nil {
+				return err
+			}
+
+			o = r.configMap(clientPass, capacityConfig, log4jConfig, log)
+			err = k8sutil.ReconcileWithDriftDetection(log, r.Client, r.Recorder, o, r.KafkaCluster)
 			if err != nil {
 				return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", o.GetObjectKind().GroupVersionKind())
 			}
 
 			podAnnotations := GeneratePodAnnotations(
 				r.KafkaCluster.Spec.CruiseControlConfig.GetCruiseControlAnnotations(),
-				o.(*corev1.ConfigMap).Data,
+				r.hashedConfigData(o.(*corev1.ConfigMap).Data),
 			)
 
 			o = r.deployment(podAnnotations)
-			err = k8sutil.Reconcile(log, r.Client, o, r.KafkaCluster)
+			err = k8sutil.ReconcileWithDriftDetection(log, r.Client, r.Recorder, o, r.KafkaCluster)
 			if err != nil {
 				return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", o.GetObjectKind().GroupVersionKind())
 			}
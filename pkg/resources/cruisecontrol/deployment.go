@@ -52,7 +52,7 @@ func (r *Reconciler) deployment(podAnnotations map[string]string) runtime.Object
 		},
 	}...)
 
-	return &appsv1.Deployment{
+	deployment := &appsv1.Deployment{
 		ObjectMeta: templates.ObjectMeta(
 			fmt.Sprintf(deploymentNameTemplate, r.KafkaCluster.Name),
 			templates.ObjectMetaLabels(r.KafkaCluster, ccLabelSelector(r.KafkaCluster.Name)),
@@ -71,14 +71,16 @@ func (r *Reconciler) deployment(podAnnotations map[string]string) runtime.Object
 				Spec: corev1.PodSpec{
 					SecurityContext:               r.KafkaCluster.Spec.CruiseControlConfig.PodSecurityContext,
 					ServiceAccountName:            r.KafkaCluster.Spec.CruiseControlConfig.GetServiceAccount(),
-					ImagePullSecrets:              r.KafkaCluster.Spec.CruiseControlConfig.GetImagePullSecrets(),
+					ImagePullSecrets:              r.KafkaCluster.Spec.GetImagePullSecrets(r.KafkaCluster.Spec.CruiseControlConfig.GetImagePullSecrets()),
 					Tolerations:                   r.KafkaCluster.Spec.CruiseControlConfig.GetTolerations(),
 					NodeSelector:                  r.KafkaCluster.Spec.CruiseControlConfig.GetNodeSelector(),
+					Affinity:                      r.KafkaCluster.Spec.CruiseControlConfig.GetAffinity(),
+					TopologySpreadConstraints:     r.KafkaCluster.Spec.CruiseControlConfig.GetTopologySpreadConstraints(),
 					TerminationGracePeriodSeconds: util.Int64Pointer(30),
 					InitContainers: append(initContainers, []corev1.Container{
 						{
 							Name:    "jmx-exporter",
-							Image:   r.KafkaCluster.Spec.MonitoringConfig.GetImage(),
+							Image:   r.KafkaCluster.Spec.GetImage(r.KafkaCluster.Spec.MonitoringConfig.GetImage()),
 							Command: []string{"cp", r.KafkaCluster.Spec.MonitoringConfig.GetPathToJar(), "/opt/jmx-exporter/jmx_prometheus.jar"},
 							VolumeMounts: []corev1.VolumeMount{
 								{
@@ -93,12 +95,7 @@ func (r *Reconciler) deployment(podAnnotations map[string]string) runtime.Object
 						{
 							Name:            fmt.Sprintf(deploymentNameTemplate, r.KafkaCluster.Name),
 							SecurityContext: r.KafkaCluster.Spec.CruiseControlConfig.SecurityContext,
-							Env: []corev1.EnvVar{
-								{
-									Name:  "KAFKA_OPTS",
-									Value: "-javaagent:/opt/jmx-exporter/jmx_prometheus.jar=9020:/etc/jmx-exporter/config.yaml",
-								},
-							},
+							Env:             generateEnvVars(r.KafkaCluster.Spec.CruiseControlConfig),
 							Lifecycle: &corev1.Lifecycle{
 								PreStop: &corev1.LifecycleHandler{
 									Exec: &corev1.ExecAction{
@@ -114,7 +111,7 @@ fi`},
 									},
 								},
 							},
-							Image: r.KafkaCluster.Spec.CruiseControlConfig.GetCCImage(),
+							Image: r.KafkaCluster.Spec.GetImage(r.KafkaCluster.Spec.CruiseControlConfig.GetCCImage()),
 							Ports: []corev1.ContainerPort{
 								{
 									ContainerPort: 8090,
@@ -125,19 +122,10 @@ fi`},
 									Protocol:      corev1.ProtocolTCP,
 								},
 							},
-							Resources: *r.KafkaCluster.Spec.CruiseControlConfig.GetResources(),
-							ReadinessProbe: &corev1.Probe{
-								ProbeHandler: corev1.ProbeHandler{
-									TCPSocket: &corev1.TCPSocketAction{
-										Port: *util.IntstrPointer(8090),
-									},
-								},
-								TimeoutSeconds:      int32(1),
-								InitialDelaySeconds: 5,
-								PeriodSeconds:       10,
-								FailureThreshold:    3,
-								SuccessThreshold:    1,
-							},
+							Resources:      *r.KafkaCluster.Spec.CruiseControlConfig.GetResources(),
+							StartupProbe:   r.KafkaCluster.Spec.CruiseControlConfig.StartupProbe,
+							LivenessProbe:  r.KafkaCluster.Spec.CruiseControlConfig.LivenessProbe,
+							ReadinessProbe: r.KafkaCluster.Spec.CruiseControlConfig.GetReadinessProbe(),
 							VolumeMounts: append(volumeMount, []corev1.VolumeMount{
 								{
 									Name:      jmxVolumeName,
@@ -181,6 +169,32 @@ fi`},
 			},
 		},
 	}
+	if r.KafkaCluster.Spec.IsRestrictedPodSecurityStandard() {
+		k8sutil.ApplyRestrictedPodSecurityStandard(&deployment.Spec.Template.Spec)
+	}
+	return deployment
+}
+
+// generateEnvVars returns the environment variables of the Cruise Control container, the JVM options are appended to
+// the options of the JMX exporter agent
+func generateEnvVars(ccConfig v1beta1.CruiseControlConfig) []corev1.EnvVar {
+	kafkaOpts := "-javaagent:/opt/jmx-exporter/jmx_prometheus.jar=9020:/etc/jmx-exporter/config.yaml"
+	if jvmOptions := ccConfig.JVMOptions.String(); jvmOptions != "" {
+		kafkaOpts += " " + jvmOptions
+	}
+	envs := []corev1.EnvVar{
+		{
+			Name:  "KAFKA_OPTS",
+			Value: kafkaOpts,
+		},
+	}
+	if ccConfig.HeapOpts != "" {
+		envs = append(envs, corev1.EnvVar{
+			Name:  "KAFKA_HEAP_OPTS",
+			Value: ccConfig.HeapOpts,
+		})
+	}
+	return envs
 }
 
 func GeneratePodAnnotations(cruiseControlAnnotations, cruiseControlConfig map[string]string) map[string]string {
@@ -207,20 +221,10 @@ func GeneratePodAnnotations(cruiseControlAnnotations, cruiseControlConfig map[st
 }
 
 func generateVolumesForSSL(cluster *v1beta1.KafkaCluster) []corev1.Volume {
-	// Use that secret as default which has autogenerated for clients by us
-	secretName := fmt.Sprintf(pkicommon.BrokerControllerTemplate, cluster.Name)
-	if cluster.Spec.GetClientSSLCertSecretName() != "" {
-		secretName = cluster.Spec.GetClientSSLCertSecretName()
-	}
 	return []corev1.Volume{
 		{
-			Name: keystoreVolume,
-			VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName:  secretName,
-					DefaultMode: util.Int32Pointer(0644),
-				},
-			},
+			Name:         keystoreVolume,
+			VolumeSource: pkicommon.ClientSSLCertVolumeSource(cluster),
 		},
 	}
 }
@@ -0,0 +1,69 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestDeploymentSchedulingAndJVM(t *testing.T) {
+	affinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"cruise-control"}},
+					},
+				}},
+			},
+		},
+	}
+	constraints := []corev1.TopologySpreadConstraint{
+		{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.ScheduleAnyway},
+	}
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				Affinity:                  affinity,
+				TopologySpreadConstraints: constraints,
+				HeapOpts:                  "-Xms2G -Xmx2G",
+				JVMOptions:                &v1beta1.JVMOptions{Flags: []string{"-XX:+UseG1GC"}},
+			},
+		},
+	}
+	r := &Reconciler{Reconciler: resources.Reconciler{KafkaCluster: cluster}}
+
+	podSpec := r.deployment(nil).(*appsv1.Deployment).Spec.Template.Spec
+	assert.Equal(t, affinity, podSpec.Affinity)
+	assert.Equal(t, constraints, podSpec.TopologySpreadConstraints)
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "KAFKA_OPTS", Value: "-javaagent:/opt/jmx-exporter/jmx_prometheus.jar=9020:/etc/jmx-exporter/config.yaml -XX:+UseG1GC"},
+		{Name: "KAFKA_HEAP_OPTS", Value: "-Xms2G -Xmx2G"},
+	}, podSpec.Containers[0].Env)
+
+	// the defaults of the start script are used
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "KAFKA_OPTS", Value: "-javaagent:/opt/jmx-exporter/jmx_prometheus.jar=9020:/etc/jmx-exporter/config.yaml"},
+	}, generateEnvVars(v1beta1.CruiseControlConfig{}))
+}
@@ -0,0 +1,74 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	log4jConfigKey = "log4j.properties"
+	// log4j2 checks the configuration file for changes with the given interval
+	log4jMonitorIntervalKey            = "monitorInterval"
+	defaultLog4jMonitorIntervalSeconds = "30"
+)
+
+// log4jConfig returns the log4j2 configuration of Cruise Control. The configuration selected from a ConfigMap is
+// reloaded by Cruise Control on change, so its monitorInterval is set unless it is set explicitly.
+func (r *Reconciler) log4jConfig(ctx context.Context) (string, error) {
+	ccConfig := r.KafkaCluster.Spec.CruiseControlConfig
+	if ccConfig.Log4jConfigMap == nil {
+		return ccConfig.GetCCLog4jConfig(), nil
+	}
+	value, found, err := k8sutil.LookupConfigMapKey(ctx, r.Client, r.KafkaCluster.GetNamespace(), ccConfig.Log4jConfigMap)
+	if err != nil {
+		return "", errorfactory.New(errorfactory.ResourceNotReady{}, err, "could not get the log4j configuration of Cruise Control")
+	}
+	if !found {
+		value = ccConfig.GetCCLog4jConfig()
+	}
+	return withLog4jMonitorInterval(value)
+}
+
+func withLog4jMonitorInterval(log4jConfig string) (string, error) {
+	config, err := properties.NewFromString(log4jConfig)
+	if err != nil {
+		return "", errors.WrapIf(err, "could not parse the log4j configuration of Cruise Control")
+	}
+	if _, ok := config.Get(log4jMonitorIntervalKey); ok {
+		return log4jConfig, nil
+	}
+	return log4jMonitorIntervalKey + "=" + defaultLog4jMonitorIntervalSeconds + "\n" + log4jConfig, nil
+}
+
+// hashedConfigData returns the data of the Cruise Control ConfigMap whose changes need a restart of Cruise Control
+func (r *Reconciler) hashedConfigData(data map[string]string) map[string]string {
+	if r.KafkaCluster.Spec.CruiseControlConfig.Log4jConfigMap == nil {
+		return data
+	}
+	ret := make(map[string]string, len(data))
+	for key, value := range data {
+		if key != log4jConfigKey {
+			ret[key] = value
+		}
+	}
+	return ret
+}
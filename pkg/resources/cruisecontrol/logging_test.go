@@ -0,0 +1,73 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestLog4jConfig(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cc-logging", Namespace: "kafka"},
+		Data: map[string]string{
+			"log4j2.properties":    "rootLogger.level=DEBUG\n",
+			"monitored.properties": "monitorInterval=5\nrootLogger.level=WARN\n",
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(configMap).Build()
+	newReconciler := func(selector *corev1.ConfigMapKeySelector) *Reconciler {
+		return &Reconciler{Reconciler: resources.Reconciler{
+			Client: c,
+			KafkaCluster: &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+				Spec:       v1beta1.KafkaClusterSpec{CruiseControlConfig: v1beta1.CruiseControlConfig{Log4jConfigMap: selector}},
+			},
+		}}
+	}
+	selector := func(name, key string, optional bool) *corev1.ConfigMapKeySelector {
+		return &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key, Optional: &optional}
+	}
+
+	log4jConfig, err := newReconciler(nil).log4jConfig(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, (&v1beta1.CruiseControlConfig{}).GetCCLog4jConfig(), log4jConfig)
+
+	log4jConfig, err = newReconciler(selector("cc-logging", "log4j2.properties", false)).log4jConfig(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "monitorInterval=30\nrootLogger.level=DEBUG\n", log4jConfig)
+
+	// The monitor interval set in the configuration is kept
+	log4jConfig, err = newReconciler(selector("cc-logging", "monitored.properties", false)).log4jConfig(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "monitorInterval=5\nrootLogger.level=WARN\n", log4jConfig)
+
+	// A missing optional ConfigMap falls back to the default configuration
+	log4jConfig, err = newReconciler(selector("missing", "log4j2.properties", true)).log4jConfig(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, log4jConfig, "monitorInterval=30\n")
+
+	_, err = newReconciler(selector("missing", "log4j2.properties", false)).log4jConfig(context.Background())
+	assert.Error(t, err)
+}
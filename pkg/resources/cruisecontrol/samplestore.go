@@ -0,0 +1,100 @@
+// Copyright © 2019 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"fmt"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const sampleLoadingThreadsKey = "num.sample.loading.threads"
+
+// sampleStoreConfig returns the Cruise Control configuration which makes Cruise Control persist its metric samples
+// in the sample store topics and load them back on startup. The topic names configured explicitly are kept.
+func sampleStoreConfig(cluster *v1beta1.KafkaCluster, ccConfig *properties.Properties) (*properties.Properties, error) {
+	config := properties.NewProperties()
+	sampleStore := cluster.Spec.CruiseControlConfig.SampleStore
+	if sampleStore == nil || !sampleStore.Enabled {
+		return config, nil
+	}
+	if err := config.Set(sampleStoreClassKey, kafkaSampleStoreClass); err != nil {
+		return nil, err
+	}
+	for _, sampleStoreTopic := range sampleStoreTopics {
+		if property, found := ccConfig.Get(sampleStoreTopic.topicNameKey); found && property.Value() != "" {
+			continue
+		}
+		if err := config.Set(sampleStoreTopic.topicNameKey, sampleStoreTopic.defaultTopicName); err != nil {
+			return nil, err
+		}
+	}
+	if sampleStore.LoadingThreads != nil {
+		if err := config.Set(sampleLoadingThreadsKey, strconv.Itoa(int(*sampleStore.LoadingThreads))); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// verifySampleStoreTopics returns ResourceNotReady error when any of the sample store topics does not exist or has a
+// partition without leader, as Cruise Control could not load the samples from them.
+func (r *Reconciler) verifySampleStoreTopics() error {
+	topics, err := newSampleStoreTopics(r.KafkaCluster)
+	if err != nil {
+		return err
+	}
+	if len(topics) == 0 {
+		return nil
+	}
+
+	kClient, closeClient, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.ResourceNotReady{}, err, "could not connect to kafka cluster to verify sample store topics")
+	}
+	defer closeClient()
+
+	for _, topic := range topics {
+		meta, err := kClient.DescribeTopic(topic.Spec.Name)
+		if err != nil {
+			return errorfactory.New(errorfactory.ResourceNotReady{}, err, "could not describe sample store topic", "topic", topic.Spec.Name)
+		}
+		if err := checkSampleStorePartitions(meta); err != nil {
+			return errorfactory.New(errorfactory.ResourceNotReady{}, err, "sample store topic is not healthy", "topic", topic.Spec.Name)
+		}
+	}
+	return nil
+}
+
+func checkSampleStorePartitions(meta *sarama.TopicMetadata) error {
+	if len(meta.Partitions) == 0 {
+		return errors.New("topic has no partitions")
+	}
+	for _, partition := range meta.Partitions {
+		if partition.Err != sarama.ErrNoError && partition.Err != sarama.ErrReplicaNotAvailable {
+			return errors.WrapIf(partition.Err, fmt.Sprintf("partition %d is not available", partition.ID))
+		}
+		if partition.Leader < 0 {
+			return errors.Errorf("partition %d has no leader", partition.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright © 2020 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/util"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+type sampleStoreStubKafkaClient struct {
+	topicStubKafkaClient
+	metadata map[string]*sarama.TopicMetadata
+}
+
+func (c *sampleStoreStubKafkaClient) DescribeTopic(name string) (*sarama.TopicMetadata, error) {
+	if meta, ok := c.metadata[name]; ok {
+		return meta, nil
+	}
+	return nil, sarama.ErrUnknownTopicOrPartition
+}
+
+type sampleStoreStubProvider struct {
+	kafkaClient *sampleStoreStubKafkaClient
+}
+
+func (p *sampleStoreStubProvider) NewFromCluster(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+	return p.kafkaClient, func() {}, nil
+}
+
+func TestSampleStoreConfig(t *testing.T) {
+	cluster := newTopicManagerCluster()
+	ccConfig, err := properties.NewFromString("partition.metric.sample.store.topic=partition-samples")
+	require.NoError(t, err)
+
+	config, err := sampleStoreConfig(cluster, ccConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 0, config.Len())
+
+	cluster.Spec.CruiseControlConfig.SampleStore = &v1beta1.CruiseControlSampleStore{
+		Enabled:        true,
+		LoadingThreads: util.Int32Pointer(4),
+	}
+	config, err = sampleStoreConfig(cluster, ccConfig)
+	require.NoError(t, err)
+	config.Sort()
+	assert.Equal(t, "broker.metric.sample.store.topic=__KafkaCruiseControlModelTrainingSamples\n"+
+		"num.sample.loading.threads=4\n"+
+		"sample.store.class=com.linkedin.kafka.cruisecontrol.monitor.sampling.KafkaSampleStore\n", config.String())
+}
+
+func TestVerifySampleStoreTopics(t *testing.T) {
+	cluster := newTopicManagerCluster()
+	cluster.Spec.CruiseControlConfig.SampleStore = &v1beta1.CruiseControlSampleStore{Enabled: true, VerifyTopics: true}
+	kafkaClient := &sampleStoreStubKafkaClient{metadata: map[string]*sarama.TopicMetadata{
+		"__KafkaCruiseControlPartitionMetricSamples": {
+			Partitions: []*sarama.PartitionMetadata{{ID: 0, Leader: 0}, {ID: 1, Leader: 1}},
+		},
+	}}
+	r := &Reconciler{
+		Reconciler:          resources.Reconciler{KafkaCluster: cluster},
+		kafkaClientProvider: &sampleStoreStubProvider{kafkaClient: kafkaClient},
+	}
+
+	// the broker sample store topic does not exist
+	err := r.verifySampleStoreTopics()
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &errorfactory.ResourceNotReady{})
+
+	kafkaClient.metadata["__KafkaCruiseControlModelTrainingSamples"] = &sarama.TopicMetadata{
+		Partitions: []*sarama.PartitionMetadata{{ID: 0, Leader: -1, Err: sarama.ErrLeaderNotAvailable}},
+	}
+	err = r.verifySampleStoreTopics()
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &errorfactory.ResourceNotReady{})
+
+	kafkaClient.metadata["__KafkaCruiseControlModelTrainingSamples"].Partitions[0] = &sarama.PartitionMetadata{
+		ID: 0, Leader: 1, Err: sarama.ErrReplicaNotAvailable,
+	}
+	assert.NoError(t, r.verifySampleStoreTopics())
+}
@@ -17,41 +17,81 @@ package cruisecontrol
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/errorfactory"
 	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	"github.com/banzaicloud/koperator/pkg/util"
 	"github.com/banzaicloud/koperator/pkg/webhooks"
 	properties "github.com/banzaicloud/koperator/properties/pkg"
 )
 
 const (
 	ccMetricTopicAutoCreate             = "cruise.control.metrics.topic.auto.create"
+	ccMetricTopicRetention              = "cruise.control.metrics.topic.retention.ms"
 	cruiseControlTopicFormat            = "%s-cruise-control-topic"
 	cruiseControlTopicName              = "__CruiseControlMetrics"
 	cruiseControlTopicPartitions        = 12
 	cruiseControlTopicReplicationFactor = 3
+	// the metrics reporter keeps the metrics for 5 hours by default
+	cruiseControlTopicRetentionMs = 5 * 60 * 60 * 1000
+
+	partitionSampleStoreTopicFormat = "%s-cruise-control-partition-samples-topic"
+	brokerSampleStoreTopicFormat    = "%s-cruise-control-broker-samples-topic"
+	sampleStoreClassKey             = "sample.store.class"
+	kafkaSampleStoreClass           = "com.linkedin.kafka.cruisecontrol.monitor.sampling.KafkaSampleStore"
 )
 
-func newCruiseControlTopic(cluster *v1beta1.KafkaCluster) *v1alpha1.KafkaTopic {
-	var topicPartitions, topicReplicationFactor int32
-	if cluster.Spec.CruiseControlConfig.TopicConfig != nil {
-		topicPartitions = cluster.Spec.CruiseControlConfig.TopicConfig.Partitions
-		topicReplicationFactor = cluster.Spec.CruiseControlConfig.TopicConfig.ReplicationFactor
-	} else {
-		topicPartitions = cruiseControlTopicPartitions
-		topicReplicationFactor = cruiseControlTopicReplicationFactor
-	}
+// sampleStoreTopic describes the Cruise Control configuration of a sample store topic and its defaults
+type sampleStoreTopic struct {
+	nameFormat            string
+	topicNameKey          string
+	defaultTopicName      string
+	partitionCountKey     string
+	windowMsKey           string
+	defaultWindowMs       int64
+	numWindowsKey         string
+	defaultNumWindows     int64
+	defaultPartitionCount int32
+}
+
+var sampleStoreTopics = []sampleStoreTopic{
+	{
+		nameFormat:            partitionSampleStoreTopicFormat,
+		topicNameKey:          "partition.metric.sample.store.topic",
+		defaultTopicName:      "__KafkaCruiseControlPartitionMetricSamples",
+		partitionCountKey:     "partition.sample.store.topic.partition.count",
+		windowMsKey:           "partition.metrics.window.ms",
+		defaultWindowMs:       60 * 60 * 1000,
+		numWindowsKey:         "num.partition.metrics.windows",
+		defaultNumWindows:     5,
+		defaultPartitionCount: 32,
+	},
+	{
+		nameFormat:            brokerSampleStoreTopicFormat,
+		topicNameKey:          "broker.metric.sample.store.topic",
+		defaultTopicName:      "__KafkaCruiseControlModelTrainingSamples",
+		partitionCountKey:     "broker.sample.store.topic.partition.count",
+		windowMsKey:           "broker.metrics.window.ms",
+		defaultWindowMs:       60 * 60 * 1000,
+		numWindowsKey:         "num.broker.metrics.windows",
+		defaultNumWindows:     20,
+		defaultPartitionCount: 32,
+	},
+}
+
+func newCruiseControlKafkaTopic(cluster *v1beta1.KafkaCluster, name, topicName string, partitions, replicationFactor int32,
+	config map[string]string) *v1alpha1.KafkaTopic {
 	return &v1alpha1.KafkaTopic{
 		ObjectMeta: templates.ObjectMeta(
-			fmt.Sprintf(cruiseControlTopicFormat, cluster.Name),
+			name,
 			map[string]string{
 				v1beta1.AppLabelKey: "kafka",
 				"clusterName":       cluster.Name,
@@ -60,9 +100,10 @@ func newCruiseControlTopic(cluster *v1beta1.KafkaCluster) *v1alpha1.KafkaTopic {
 			cluster,
 		),
 		Spec: v1alpha1.KafkaTopicSpec{
-			Name:              cruiseControlTopicName,
-			Partitions:        topicPartitions,
-			ReplicationFactor: topicReplicationFactor,
+			Name:              topicName,
+			Partitions:        partitions,
+			ReplicationFactor: replicationFactor,
+			Config:            config,
 			ClusterRef: v1alpha1.ClusterReference{
 				Name:      cluster.Name,
 				Namespace: cluster.Namespace,
@@ -71,7 +112,100 @@ func newCruiseControlTopic(cluster *v1beta1.KafkaCluster) *v1alpha1.KafkaTopic {
 	}
 }
 
-func generateCCTopic(cluster *v1beta1.KafkaCluster, client client.Client, log logr.Logger) error {
+func newCruiseControlTopic(cluster *v1beta1.KafkaCluster) (*v1alpha1.KafkaTopic, error) {
+	var topicPartitions, topicReplicationFactor int32
+	if cluster.Spec.CruiseControlConfig.TopicConfig != nil {
+		topicPartitions = cluster.Spec.CruiseControlConfig.TopicConfig.Partitions
+		topicReplicationFactor = cluster.Spec.CruiseControlConfig.TopicConfig.ReplicationFactor
+	} else {
+		topicPartitions = cruiseControlTopicPartitions
+		topicReplicationFactor = cruiseControlTopicReplicationFactor
+	}
+	var config map[string]string
+	if cluster.Spec.CruiseControlConfig.ManageInternalTopics {
+		readOnlyConfigProperties, err := properties.NewFromString(cluster.Spec.ReadOnlyConfig)
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not parse broker config")
+		}
+		retentionMs, err := int64Property(readOnlyConfigProperties, ccMetricTopicRetention, cruiseControlTopicRetentionMs)
+		if err != nil {
+			return nil, err
+		}
+		config = map[string]string{
+			"cleanup.policy": "delete",
+			"retention.ms":   strconv.FormatInt(retentionMs, 10),
+		}
+	}
+	return newCruiseControlKafkaTopic(cluster, fmt.Sprintf(cruiseControlTopicFormat, cluster.Name), cruiseControlTopicName,
+		topicPartitions, topicReplicationFactor, config), nil
+}
+
+// newSampleStoreTopics returns the sample store topics of Cruise Control, the samples are kept for the time covered
+// by the metric windows of Cruise Control. There are no sample store topics when Cruise Control uses a different
+// sample store and the persistence of the samples is not enabled.
+func newSampleStoreTopics(cluster *v1beta1.KafkaCluster) ([]*v1alpha1.KafkaTopic, error) {
+	ccConfig, err := properties.NewFromString(cluster.Spec.CruiseControlConfig.Config)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not parse Cruise Control config")
+	}
+	if sampleStoreClass, found := ccConfig.Get(sampleStoreClassKey); found && sampleStoreClass.Value() != kafkaSampleStoreClass &&
+		!cluster.Spec.CruiseControlConfig.IsSampleStoreEnabled() {
+		return nil, nil
+	}
+
+	replicationFactor := int64(cruiseControlTopicReplicationFactor)
+	if brokers := int64(len(cluster.Spec.Brokers)); brokers < replicationFactor {
+		replicationFactor = brokers
+	}
+	if replicationFactor, err = int64Property(ccConfig, "sample.store.topic.replication.factor", replicationFactor); err != nil {
+		return nil, err
+	}
+
+	topics := make([]*v1alpha1.KafkaTopic, 0, len(sampleStoreTopics))
+	for _, sampleStore := range sampleStoreTopics {
+		topicName := sampleStore.defaultTopicName
+		if property, found := ccConfig.Get(sampleStore.topicNameKey); found && property.Value() != "" {
+			topicName = property.Value()
+		}
+		partitions, err := int64Property(ccConfig, sampleStore.partitionCountKey, int64(sampleStore.defaultPartitionCount))
+		if err != nil {
+			return nil, err
+		}
+		windowMs, err := int64Property(ccConfig, sampleStore.windowMsKey, sampleStore.defaultWindowMs)
+		if err != nil {
+			return nil, err
+		}
+		numWindows, err := int64Property(ccConfig, sampleStore.numWindowsKey, sampleStore.defaultNumWindows)
+		if err != nil {
+			return nil, err
+		}
+		topicPartitions, topicReplicationFactor := int32(partitions), int32(replicationFactor)
+		if topicConfig := cluster.Spec.CruiseControlConfig.SampleStoreTopicConfig; topicConfig != nil {
+			topicPartitions, topicReplicationFactor = topicConfig.Partitions, topicConfig.ReplicationFactor
+		}
+		topics = append(topics, newCruiseControlKafkaTopic(cluster, fmt.Sprintf(sampleStore.nameFormat, cluster.Name), topicName,
+			topicPartitions, topicReplicationFactor, map[string]string{
+				"cleanup.policy": "delete",
+				"retention.ms":   strconv.FormatInt(windowMs*numWindows, 10),
+			}))
+	}
+	return topics, nil
+}
+
+func int64Property(config *properties.Properties, key string, defaultValue int64) (int64, error) {
+	property, found := config.Get(key)
+	if !found {
+		return defaultValue, nil
+	}
+	value, err := property.Int()
+	if err != nil {
+		return 0, errors.WrapIfWithDetails(err, "could not parse config", "key", key)
+	}
+	return value, nil
+}
+
+func (r *Reconciler) generateCCTopics(ctx context.Context, log logr.Logger) error {
+	cluster := r.KafkaCluster
 	readOnlyConfigProperties, err := properties.NewFromString(cluster.Spec.ReadOnlyConfig)
 	if err != nil {
 		return errors.WrapIf(err, "could not parse broker config")
@@ -79,37 +213,129 @@ func generateCCTopic(cluster *v1beta1.KafkaCluster, client client.Client, log lo
 
 	// for compatibility reasons the only case when we let CC to create its own kafka topics is
 	// when we enable the creation explicitly
+	autoCreate := false
 	if autoCreateProperty, present := readOnlyConfigProperties.Get(ccMetricTopicAutoCreate); present {
-		if autoCreate, err := autoCreateProperty.Bool(); err != nil {
+		if autoCreate, err = autoCreateProperty.Bool(); err != nil {
+			return err
+		}
+	}
+	if autoCreate {
+		log.Info("CruiseControl topic has been created by CruiseControl")
+	} else {
+		metricsTopic, err := newCruiseControlTopic(cluster)
+		if err != nil {
+			return err
+		}
+		if err := r.reconcileCCTopic(ctx, log, metricsTopic, cluster.Spec.CruiseControlConfig.ManageInternalTopics); err != nil {
 			return err
-		} else if autoCreate {
-			log.Info("CruiseControl topic has been created by CruiseControl")
-			return nil
 		}
 	}
 
+	// the sample store topics are always managed as they are created only when it is requested
+	if cluster.Spec.CruiseControlConfig.ManageInternalTopics || cluster.Spec.CruiseControlConfig.IsSampleStoreEnabled() {
+		sampleStoreTopics, err := newSampleStoreTopics(cluster)
+		if err != nil {
+			return err
+		}
+		for _, topic := range sampleStoreTopics {
+			if err := r.reconcileCCTopic(ctx, log, topic, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reconcileCCTopic creates the KafkaTopic of an internal topic of Cruise Control. When the topic is managed by the
+// operator the topic created outside of the operator is adopted, and the partitions and the configuration of the
+// KafkaTopic are enforced.
+func (r *Reconciler) reconcileCCTopic(ctx context.Context, log logr.Logger, topic *v1alpha1.KafkaTopic, manage bool) error {
 	existing := &v1alpha1.KafkaTopic{}
-	topic := newCruiseControlTopic(cluster)
-	if err := client.Get(context.TODO(), types.NamespacedName{Name: topic.Name, Namespace: topic.Namespace}, existing); err != nil {
-		if apierrors.IsNotFound(err) {
-			// Attempt to create the topic
-			if err := client.Create(context.TODO(), topic); err != nil {
-				// If webhook was unable to connect to kafka - return not ready
-				if webhooks.IsAdmissionCantConnect(err) {
-					return errorfactory.New(errorfactory.ResourceNotReady{}, err, "topic admission failed to connect to kafka cluster")
-				}
-				// If less than the required brokers are available - return not ready
-				if webhooks.IsAdmissionInvalidReplicationFactor(err) {
-					return errorfactory.New(errorfactory.ResourceNotReady{}, err, fmt.Sprintf("not enough brokers available (at least %d needed) for CC topic", topic.Spec.ReplicationFactor))
-				}
-				return errorfactory.New(errorfactory.APIFailure{}, err, "could not create cruise control topic")
+	err := r.Client.Get(ctx, types.NamespacedName{Name: topic.Name, Namespace: topic.Namespace}, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		existing = topic.DeepCopy()
+		if manage {
+			if err := r.adoptCCTopic(existing); err != nil {
+				return err
+			}
+		}
+		// Attempt to create the topic
+		if err := r.Client.Create(ctx, existing); err != nil {
+			// If webhook was unable to connect to kafka - return not ready
+			if webhooks.IsAdmissionCantConnect(err) {
+				return errorfactory.New(errorfactory.ResourceNotReady{}, err, "topic admission failed to connect to kafka cluster")
 			}
-		} else {
-			// pass though any other api failure
-			return errorfactory.New(errorfactory.APIFailure{}, err, "failed to lookup cruise control topic")
+			// If less than the required brokers are available - return not ready
+			if webhooks.IsAdmissionInvalidReplicationFactor(err) {
+				return errorfactory.New(errorfactory.ResourceNotReady{}, err, fmt.Sprintf("not enough brokers available (at least %d needed) for CC topic", topic.Spec.ReplicationFactor))
+			}
+			return errorfactory.New(errorfactory.APIFailure{}, err, "could not create cruise control topic", "topic", topic.Spec.Name)
 		}
+		log.Info("CruiseControl topic has been created by Operator", "topic", topic.Spec.Name)
+	case err != nil:
+		// pass though any other api failure
+		return errorfactory.New(errorfactory.APIFailure{}, err, "failed to lookup cruise control topic", "topic", topic.Spec.Name)
+	}
+
+	if !manage {
+		return nil
 	}
 
-	log.Info("CruiseControl topic has been created by Operator")
+	if existing.Spec.ReplicationFactor != topic.Spec.ReplicationFactor {
+		log.Info("the replication factor of the CruiseControl topic differs from the desired one, it is not changed by the operator",
+			"topic", topic.Spec.Name, "replicationFactor", existing.Spec.ReplicationFactor, "desiredReplicationFactor", topic.Spec.ReplicationFactor)
+	}
+	changed := false
+	// the partitions of an existing topic cannot be decreased
+	if existing.Spec.Partitions < topic.Spec.Partitions {
+		existing.Spec.Partitions = topic.Spec.Partitions
+		changed = true
+	}
+	for key, value := range topic.Spec.Config {
+		if existing.Spec.Config[key] != value {
+			if existing.Spec.Config == nil {
+				existing.Spec.Config = make(map[string]string, len(topic.Spec.Config))
+			}
+			existing.Spec.Config[key] = value
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	log.Info("repairing CruiseControl topic", "topic", topic.Spec.Name)
+	if err := r.Client.Update(ctx, existing); err != nil {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "could not update cruise control topic", "topic", topic.Spec.Name)
+	}
+	return nil
+}
+
+// adoptCCTopic sets the partitions, the replication factor and the configuration of the topic to the ones of the
+// existing Kafka topic, as the KafkaTopic of an existing topic has to match it when it is created
+func (r *Reconciler) adoptCCTopic(topic *v1alpha1.KafkaTopic) error {
+	kClient, closeClient, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.ResourceNotReady{}, err, "could not connect to kafka cluster to check cruise control topic")
+	}
+	defer closeClient()
+
+	existing, err := kClient.GetTopic(topic.Spec.Name)
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersRequestError{}, err, "could not get cruise control topic", "topic", topic.Spec.Name)
+	}
+	if existing == nil {
+		return nil
+	}
+	topic.Annotations = util.MergeAnnotations(topic.Annotations,
+		map[string]string{webhooks.TopicManagedByAnnotationKey: webhooks.TopicManagedByKoperatorAnnotationValue})
+	topic.Spec.Partitions = existing.NumPartitions
+	topic.Spec.ReplicationFactor = int32(existing.ReplicationFactor)
+	topic.Spec.Config = make(map[string]string, len(existing.ConfigEntries))
+	for key, value := range existing.ConfigEntries {
+		if value != nil {
+			topic.Spec.Config[key] = *value
+		}
+	}
 	return nil
 }
@@ -0,0 +1,154 @@
+// Copyright © 2020 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cruisecontrol
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/webhooks"
+)
+
+type topicStubKafkaClient struct {
+	kafkaclient.KafkaClient
+	topics map[string]*sarama.TopicDetail
+}
+
+func (c *topicStubKafkaClient) GetTopic(name string) (*sarama.TopicDetail, error) {
+	return c.topics[name], nil
+}
+
+type topicStubProvider struct {
+	kafkaClient *topicStubKafkaClient
+}
+
+func (p *topicStubProvider) NewFromCluster(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+	return p.kafkaClient, func() {}, nil
+}
+
+func newTopicManagerCluster() *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}},
+			CruiseControlConfig: v1beta1.CruiseControlConfig{
+				ManageInternalTopics: true,
+			},
+		},
+	}
+}
+
+func TestNewSampleStoreTopics(t *testing.T) {
+	cluster := newTopicManagerCluster()
+
+	topics, err := newSampleStoreTopics(cluster)
+	require.NoError(t, err)
+	require.Len(t, topics, 2)
+	assert.Equal(t, "kafka-cruise-control-partition-samples-topic", topics[0].Name)
+	assert.Equal(t, "__KafkaCruiseControlPartitionMetricSamples", topics[0].Spec.Name)
+	assert.Equal(t, int32(32), topics[0].Spec.Partitions)
+	// the replication factor is limited by the number of brokers
+	assert.Equal(t, int32(2), topics[0].Spec.ReplicationFactor)
+	assert.Equal(t, map[string]string{"cleanup.policy": "delete", "retention.ms": "18000000"}, topics[0].Spec.Config)
+	assert.Equal(t, "__KafkaCruiseControlModelTrainingSamples", topics[1].Spec.Name)
+	assert.Equal(t, "72000000", topics[1].Spec.Config["retention.ms"])
+
+	cluster.Spec.CruiseControlConfig.Config = "partition.metric.sample.store.topic=partition-samples\n" +
+		"sample.store.topic.replication.factor=1\n" +
+		"partition.metrics.window.ms=60000\n" +
+		"num.partition.metrics.windows=10\n"
+	topics, err = newSampleStoreTopics(cluster)
+	require.NoError(t, err)
+	assert.Equal(t, "partition-samples", topics[0].Spec.Name)
+	assert.Equal(t, int32(1), topics[0].Spec.ReplicationFactor)
+	assert.Equal(t, "600000", topics[0].Spec.Config["retention.ms"])
+
+	cluster.Spec.CruiseControlConfig.SampleStoreTopicConfig = &v1beta1.TopicConfig{Partitions: 8, ReplicationFactor: 2}
+	topics, err = newSampleStoreTopics(cluster)
+	require.NoError(t, err)
+	assert.Equal(t, int32(8), topics[1].Spec.Partitions)
+	assert.Equal(t, int32(2), topics[1].Spec.ReplicationFactor)
+
+	// no sample store topics are needed when Cruise Control uses a different sample store
+	cluster.Spec.CruiseControlConfig.Config = "sample.store.class=com.example.SampleStore"
+	topics, err = newSampleStoreTopics(cluster)
+	require.NoError(t, err)
+	assert.Empty(t, topics)
+}
+
+func TestGenerateCCTopics(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := newTopicManagerCluster()
+	drifted, err := newCruiseControlTopic(cluster)
+	require.NoError(t, err)
+	drifted.Spec.Partitions = 6
+	drifted.Spec.Config = map[string]string{"cleanup.policy": "compact", "segment.ms": "600000"}
+
+	retention := "3600000"
+	kafkaClient := &topicStubKafkaClient{topics: map[string]*sarama.TopicDetail{
+		"__KafkaCruiseControlModelTrainingSamples": {
+			NumPartitions:     4,
+			ReplicationFactor: 1,
+			ConfigEntries:     map[string]*string{"retention.ms": &retention},
+		},
+	}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, drifted).Build()
+	r := &Reconciler{
+		Reconciler:          resources.Reconciler{Client: fakeClient, KafkaCluster: cluster},
+		kafkaClientProvider: &topicStubProvider{kafkaClient: kafkaClient},
+	}
+
+	require.NoError(t, r.generateCCTopics(context.Background(), logr.Discard()))
+
+	getTopic := func(name string) *v1alpha1.KafkaTopic {
+		topic := &v1alpha1.KafkaTopic{}
+		require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "kafka"}, topic))
+		return topic
+	}
+
+	// the drifted metrics topic is repaired
+	metrics := getTopic("kafka-cruise-control-topic")
+	assert.Equal(t, int32(12), metrics.Spec.Partitions)
+	assert.Equal(t, map[string]string{"cleanup.policy": "delete", "retention.ms": "18000000", "segment.ms": "600000"}, metrics.Spec.Config)
+
+	// the missing sample store topic is created
+	partitionSamples := getTopic("kafka-cruise-control-partition-samples-topic")
+	assert.Equal(t, int32(32), partitionSamples.Spec.Partitions)
+	assert.Empty(t, partitionSamples.Annotations[webhooks.TopicManagedByAnnotationKey])
+
+	// the existing sample store topic is adopted and its configuration is fixed, the replication factor is kept
+	brokerSamples := getTopic("kafka-cruise-control-broker-samples-topic")
+	assert.Equal(t, webhooks.TopicManagedByKoperatorAnnotationValue, brokerSamples.Annotations[webhooks.TopicManagedByAnnotationKey])
+	assert.Equal(t, int32(32), brokerSamples.Spec.Partitions)
+	assert.Equal(t, int32(1), brokerSamples.Spec.ReplicationFactor)
+	assert.Equal(t, map[string]string{"cleanup.policy": "delete", "retention.ms": "72000000"}, brokerSamples.Spec.Config)
+}
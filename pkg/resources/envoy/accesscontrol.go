@@ -0,0 +1,121 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"fmt"
+	"net"
+
+	"emperror.dev/errors"
+	envoycore "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoylistener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoyrbac "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	envoyrbacfilter "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/rbac/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+const networkRBACFilterName = "envoy.filters.network.rbac"
+
+// generateAccessControlFilters returns the RBAC network filters enforcing the access control of the external listener.
+// The filter of the denied ranges comes first so that they take precedence over the allowed ones.
+func generateAccessControlFilters(elistener v1beta1.ExternalListenerConfig) ([]*envoylistener.Filter, error) {
+	accessControl := elistener.AccessControl
+	if accessControl == nil {
+		return nil, nil
+	}
+
+	rules := []struct {
+		name   string
+		action envoyrbac.RBAC_Action
+		ranges []string
+	}{
+		{name: "denied", action: envoyrbac.RBAC_DENY, ranges: accessControl.DeniedSourceRanges},
+		{name: "allowed", action: envoyrbac.RBAC_ALLOW, ranges: accessControl.AllowedSourceRanges},
+	}
+
+	var filters []*envoylistener.Filter
+	for _, rule := range rules {
+		if len(rule.ranges) == 0 {
+			continue
+		}
+		principals, err := sourceRangePrincipals(rule.ranges)
+		if err != nil {
+			return nil, err
+		}
+		rbacRules := &envoyrbac.RBAC{
+			Action: rule.action,
+			Policies: map[string]*envoyrbac.Policy{
+				fmt.Sprintf("%s-source-ranges", rule.name): {
+					Permissions: []*envoyrbac.Permission{{Rule: &envoyrbac.Permission_Any{Any: true}}},
+					Principals:  principals,
+				},
+			},
+		}
+		rbacFilter := &envoyrbacfilter.RBAC{
+			StatPrefix: fmt.Sprintf("%s_%s_source_ranges.", elistener.Name, rule.name),
+		}
+		if accessControl.ShadowMode {
+			rbacFilter.ShadowRules = rbacRules
+		} else {
+			rbacFilter.Rules = rbacRules
+		}
+		pbstRBACFilter, err := anypb.New(rbacFilter)
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not marshall envoy rbac config")
+		}
+		filters = append(filters, &envoylistener.Filter{
+			Name: networkRBACFilterName,
+			ConfigType: &envoylistener.Filter_TypedConfig{
+				TypedConfig: pbstRBACFilter,
+			},
+		})
+	}
+	return filters, nil
+}
+
+// withAccessControlFilters returns the filters of a listener where the access control filters precede the given filter
+func withAccessControlFilters(accessControlFilters []*envoylistener.Filter, filter *envoylistener.Filter) []*envoylistener.Filter {
+	filters := make([]*envoylistener.Filter, 0, len(accessControlFilters)+1)
+	return append(append(filters, accessControlFilters...), filter)
+}
+
+func sourceRangePrincipals(sourceRanges []string) ([]*envoyrbac.Principal, error) {
+	principals := make([]*envoyrbac.Principal, 0, len(sourceRanges))
+	for _, sourceRange := range sourceRanges {
+		cidrRange, err := parseCidrRange(sourceRange)
+		if err != nil {
+			return nil, err
+		}
+		principals = append(principals, &envoyrbac.Principal{
+			Identifier: &envoyrbac.Principal_RemoteIp{RemoteIp: cidrRange},
+		})
+	}
+	return principals, nil
+}
+
+func parseCidrRange(sourceRange string) (*envoycore.CidrRange, error) {
+	_, ipNet, err := net.ParseCIDR(sourceRange)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "invalid source range", "sourceRange", sourceRange)
+	}
+	prefixLen, _ := ipNet.Mask.Size()
+	return &envoycore.CidrRange{
+		AddressPrefix: ipNet.IP.String(),
+		PrefixLen:     wrapperspb.UInt32(uint32(prefixLen)),
+	}, nil
+}
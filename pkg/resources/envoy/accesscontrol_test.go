@@ -0,0 +1,71 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"testing"
+
+	envoyrbac "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	envoyrbacfilter "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/rbac/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestGenerateAccessControlFilters(t *testing.T) {
+	elistener := v1beta1.ExternalListenerConfig{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "external"}}
+	filters, err := generateAccessControlFilters(elistener)
+	require.NoError(t, err)
+	assert.Empty(t, filters)
+
+	elistener.AccessControl = &v1beta1.EnvoyAccessControl{
+		AllowedSourceRanges: []string{"10.0.0.0/8", "2001:db8::1/32"},
+		DeniedSourceRanges:  []string{"10.1.2.3/16"},
+	}
+	filters, err = generateAccessControlFilters(elistener)
+	require.NoError(t, err)
+	require.Len(t, filters, 2)
+
+	denied := &envoyrbacfilter.RBAC{}
+	require.NoError(t, filters[0].GetTypedConfig().UnmarshalTo(denied))
+	assert.Equal(t, networkRBACFilterName, filters[0].GetName())
+	assert.Equal(t, "external_denied_source_ranges.", denied.GetStatPrefix())
+	assert.Equal(t, envoyrbac.RBAC_DENY, denied.GetRules().GetAction())
+	principals := denied.GetRules().GetPolicies()["denied-source-ranges"].GetPrincipals()
+	require.Len(t, principals, 1)
+	assert.Equal(t, "10.1.0.0", principals[0].GetRemoteIp().GetAddressPrefix())
+	assert.Equal(t, uint32(16), principals[0].GetRemoteIp().GetPrefixLen().GetValue())
+
+	allowed := &envoyrbacfilter.RBAC{}
+	require.NoError(t, filters[1].GetTypedConfig().UnmarshalTo(allowed))
+	assert.Equal(t, envoyrbac.RBAC_ALLOW, allowed.GetRules().GetAction())
+	principals = allowed.GetRules().GetPolicies()["allowed-source-ranges"].GetPrincipals()
+	require.Len(t, principals, 2)
+	assert.Equal(t, "2001:db8::", principals[1].GetRemoteIp().GetAddressPrefix())
+	assert.Equal(t, uint32(32), principals[1].GetRemoteIp().GetPrefixLen().GetValue())
+
+	// In shadow mode connections are not rejected
+	elistener.AccessControl.ShadowMode = true
+	filters, err = generateAccessControlFilters(elistener)
+	require.NoError(t, err)
+	require.NoError(t, filters[1].GetTypedConfig().UnmarshalTo(allowed))
+	assert.Nil(t, allowed.GetRules())
+	assert.Equal(t, envoyrbac.RBAC_ALLOW, allowed.GetShadowRules().GetAction())
+
+	elistener.AccessControl.AllowedSourceRanges = []string{"not-a-cidr"}
+	_, err = generateAccessControlFilters(elistener)
+	assert.Error(t, err)
+}
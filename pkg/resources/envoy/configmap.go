@@ -222,6 +222,12 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 		},
 	}
 
+	accessControlFilters, err := generateAccessControlFilters(elistener)
+	if err != nil {
+		log.Error(err, "could not generate envoy access control filters")
+		return ""
+	}
+
 	var listeners []*envoylistener.Listener
 	var clusters []*envoycluster.Cluster
 
@@ -258,14 +264,14 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 				},
 				FilterChains: []*envoylistener.FilterChain{
 					{
-						Filters: []*envoylistener.Filter{
-							{
+						Filters: withAccessControlFilters(accessControlFilters,
+							&envoylistener.Filter{
 								Name: wellknown.TCPProxy,
 								ConfigType: &envoylistener.Filter_TypedConfig{
 									TypedConfig: pbstTcpProxy,
 								},
 							},
-						},
+						),
 					},
 				},
 			})
@@ -348,14 +354,14 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 		},
 		FilterChains: []*envoylistener.FilterChain{
 			{
-				Filters: []*envoylistener.Filter{
-					{
+				Filters: withAccessControlFilters(accessControlFilters,
+					&envoylistener.Filter{
 						Name: wellknown.TCPProxy,
 						ConfigType: &envoylistener.Filter_TypedConfig{
 							TypedConfig: pbstTcpProxy,
 						},
 					},
-				},
+				),
 			},
 		},
 	})
@@ -441,6 +447,10 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 		Listeners: listeners,
 		Clusters:  clusters,
 	}
+	if err := applyConfigOverrides(&config, ingressConfig.EnvoyConfig.ConfigOverrides); err != nil {
+		log.Error(err, "could not apply envoy config overrides")
+		return ""
+	}
 	generatedConfig := envoybootstrap.Bootstrap{
 		Admin:           &adminConfig,
 		StaticResources: &config,
@@ -452,6 +462,12 @@ func GenerateEnvoyConfig(kc *v1beta1.KafkaCluster, elistener v1beta1.ExternalLis
 		return ""
 	}
 
+	if patchedConfig, err := applyConfigPatches(marshalledProtobufConfig, ingressConfig.EnvoyConfig.ConfigPatches); err != nil {
+		log.Error(err, "skipping envoy config patches")
+	} else {
+		marshalledProtobufConfig = patchedConfig
+	}
+
 	marshalledConfig, err := yaml.JSONToYAML(marshalledProtobufConfig)
 	if err != nil {
 		log.Error(err, "could not convert config from Json to Yaml")
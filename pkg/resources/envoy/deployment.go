@@ -28,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
 	"github.com/banzaicloud/koperator/pkg/resources/templates"
 	"github.com/banzaicloud/koperator/pkg/util"
 	envoyutils "github.com/banzaicloud/koperator/pkg/util/envoy"
@@ -71,7 +72,7 @@ func (r *Reconciler) deployment(log logr.Logger, extListener v1beta1.ExternalLis
 		arguments = append(arguments, "--concurrency", strconv.Itoa(int(ingressConfig.EnvoyConfig.GetConcurrency())))
 	}
 
-	return &appsv1.Deployment{
+	deployment := &appsv1.Deployment{
 		ObjectMeta: templates.ObjectMetaWithAnnotations(
 			deploymentName,
 			labelsForEnvoyIngress(r.KafkaCluster.GetName(), eListenerLabelName),
@@ -89,17 +90,19 @@ func (r *Reconciler) deployment(log logr.Logger, extListener v1beta1.ExternalLis
 						defaultIngressConfigName, log),
 				},
 				Spec: corev1.PodSpec{
+					SecurityContext:           ingressConfig.EnvoyConfig.PodSecurityContext,
 					ServiceAccountName:        ingressConfig.EnvoyConfig.GetServiceAccount(),
-					ImagePullSecrets:          ingressConfig.EnvoyConfig.GetImagePullSecrets(),
+					ImagePullSecrets:          r.KafkaCluster.Spec.GetImagePullSecrets(ingressConfig.EnvoyConfig.GetImagePullSecrets()),
 					Tolerations:               ingressConfig.EnvoyConfig.GetTolerations(),
 					NodeSelector:              ingressConfig.EnvoyConfig.GetNodeSelector(),
 					Affinity:                  ingressConfig.EnvoyConfig.GetAffinity(),
 					TopologySpreadConstraints: ingressConfig.EnvoyConfig.GetTopologySpreadConstaints(),
 					Containers: []corev1.Container{
 						{
-							Name:  "envoy",
-							Image: ingressConfig.EnvoyConfig.GetEnvoyImage(),
-							Args:  arguments,
+							Name:            "envoy",
+							Image:           r.KafkaCluster.Spec.GetImage(ingressConfig.EnvoyConfig.GetEnvoyImage()),
+							Args:            arguments,
+							SecurityContext: ingressConfig.EnvoyConfig.SecurityContext,
 							Ports: append(exposedPorts,
 								[]corev1.ContainerPort{
 									{
@@ -123,6 +126,10 @@ func (r *Reconciler) deployment(log logr.Logger, extListener v1beta1.ExternalLis
 			},
 		},
 	}
+	if r.KafkaCluster.Spec.IsRestrictedPodSecurityStandard() {
+		k8sutil.ApplyRestrictedPodSecurityStandard(&deployment.Spec.Template.Spec)
+	}
+	return deployment
 }
 
 func getExposedContainerPorts(extListener v1beta1.ExternalListenerConfig, brokerIds []int,
@@ -0,0 +1,120 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"encoding/json"
+
+	"emperror.dev/errors"
+	envoyaccesslog "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	envoybootstrap "github.com/envoyproxy/go-control-plane/envoy/config/bootstrap/v3"
+	envoylistener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoystdoutaccesslog "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/stream/v3"
+	envoytcpproxy "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	jsonpatch "github.com/evanphx/json-patch"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// applyConfigOverrides applies the typed overrides to the listeners and the clusters of the generated Envoy configuration
+func applyConfigOverrides(resources *envoybootstrap.Bootstrap_StaticResources, overrides *v1beta1.EnvoyConfigOverrides) error {
+	if overrides == nil {
+		return nil
+	}
+	for _, cluster := range resources.GetClusters() {
+		if overrides.ConnectTimeout != nil {
+			cluster.ConnectTimeout = durationpb.New(overrides.ConnectTimeout.Duration)
+		}
+		if overrides.PerConnectionBufferLimitBytes != nil {
+			cluster.PerConnectionBufferLimitBytes = wrapperspb.UInt32(*overrides.PerConnectionBufferLimitBytes)
+		}
+	}
+	for _, listener := range resources.GetListeners() {
+		if overrides.PerConnectionBufferLimitBytes != nil {
+			listener.PerConnectionBufferLimitBytes = wrapperspb.UInt32(*overrides.PerConnectionBufferLimitBytes)
+		}
+		for _, filterChain := range listener.GetFilterChains() {
+			for _, filter := range filterChain.GetFilters() {
+				if filter.GetName() != wellknown.TCPProxy {
+					continue
+				}
+				if err := overrideTcpProxy(filter, overrides); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func overrideTcpProxy(filter *envoylistener.Filter, overrides *v1beta1.EnvoyConfigOverrides) error {
+	if overrides.IdleTimeout == nil && !overrides.AccessLog {
+		return nil
+	}
+	tcpProxy := &envoytcpproxy.TcpProxy{}
+	if err := filter.GetTypedConfig().UnmarshalTo(tcpProxy); err != nil {
+		return errors.WrapIf(err, "could not unmarshall envoy tcp_proxy config")
+	}
+	if overrides.IdleTimeout != nil {
+		tcpProxy.IdleTimeout = durationpb.New(overrides.IdleTimeout.Duration)
+	}
+	if overrides.AccessLog {
+		pbstStdoutAccessLog, err := anypb.New(&envoystdoutaccesslog.StdoutAccessLog{})
+		if err != nil {
+			return errors.WrapIf(err, "could not marshall envoy tcp_proxy stdoutAccessLog config")
+		}
+		tcpProxy.AccessLog = append(tcpProxy.AccessLog, &envoyaccesslog.AccessLog{
+			Name: "envoy.access_loggers.stdout",
+			ConfigType: &envoyaccesslog.AccessLog_TypedConfig{
+				TypedConfig: pbstStdoutAccessLog,
+			},
+		})
+	}
+	pbstTcpProxy, err := anypb.New(tcpProxy)
+	if err != nil {
+		return errors.WrapIf(err, "could not marshall envoy tcp_proxy config")
+	}
+	filter.ConfigType = &envoylistener.Filter_TypedConfig{TypedConfig: pbstTcpProxy}
+	return nil
+}
+
+// applyConfigPatches applies the JSON patches to the JSON representation of the Envoy bootstrap configuration
+// and verifies that the result is still a valid bootstrap configuration
+func applyConfigPatches(config []byte, patches []v1beta1.EnvoyConfigPatch) ([]byte, error) {
+	if len(patches) == 0 {
+		return config, nil
+	}
+	marshalledPatches, err := json.Marshal(patches)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not marshall envoy config patches")
+	}
+	patch, err := jsonpatch.DecodePatch(marshalledPatches)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not decode envoy config patches")
+	}
+	patchedConfig, err := patch.Apply(config)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not apply envoy config patches")
+	}
+	if err := protojson.Unmarshal(patchedConfig, &envoybootstrap.Bootstrap{}); err != nil {
+		return nil, errors.WrapIf(err, "patched envoy config is not a valid bootstrap configuration")
+	}
+	return patchedConfig, nil
+}
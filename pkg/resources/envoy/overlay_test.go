@@ -0,0 +1,110 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"testing"
+	"time"
+
+	envoybootstrap "github.com/envoyproxy/go-control-plane/envoy/config/bootstrap/v3"
+	envoycluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoylistener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoytcpproxy "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func testStaticResources(t *testing.T) *envoybootstrap.Bootstrap_StaticResources {
+	pbstTcpProxy, err := anypb.New(&envoytcpproxy.TcpProxy{
+		StatPrefix:       "broker_tcp-0",
+		ClusterSpecifier: &envoytcpproxy.TcpProxy_Cluster{Cluster: "broker-0"},
+	})
+	require.NoError(t, err)
+	return &envoybootstrap.Bootstrap_StaticResources{
+		Listeners: []*envoylistener.Listener{{
+			FilterChains: []*envoylistener.FilterChain{{
+				Filters: []*envoylistener.Filter{{
+					Name:       wellknown.TCPProxy,
+					ConfigType: &envoylistener.Filter_TypedConfig{TypedConfig: pbstTcpProxy},
+				}},
+			}},
+		}},
+		Clusters: []*envoycluster.Cluster{{Name: "broker-0", ConnectTimeout: &durationpb.Duration{Seconds: 1}}},
+	}
+}
+
+func TestApplyConfigOverrides(t *testing.T) {
+	resources := testStaticResources(t)
+	require.NoError(t, applyConfigOverrides(resources, nil))
+	assert.Equal(t, int64(1), resources.GetClusters()[0].GetConnectTimeout().GetSeconds())
+
+	bufferLimit := uint32(32768)
+	overrides := &v1beta1.EnvoyConfigOverrides{
+		ConnectTimeout:                &metav1.Duration{Duration: 5 * time.Second},
+		IdleTimeout:                   &metav1.Duration{Duration: 10 * time.Minute},
+		PerConnectionBufferLimitBytes: &bufferLimit,
+		AccessLog:                     true,
+	}
+	require.NoError(t, applyConfigOverrides(resources, overrides))
+
+	cluster := resources.GetClusters()[0]
+	assert.Equal(t, 5*time.Second, cluster.GetConnectTimeout().AsDuration())
+	assert.Equal(t, uint32(32768), cluster.GetPerConnectionBufferLimitBytes().GetValue())
+
+	listener := resources.GetListeners()[0]
+	assert.Equal(t, uint32(32768), listener.GetPerConnectionBufferLimitBytes().GetValue())
+	tcpProxy := &envoytcpproxy.TcpProxy{}
+	require.NoError(t, listener.GetFilterChains()[0].GetFilters()[0].GetTypedConfig().UnmarshalTo(tcpProxy))
+	assert.Equal(t, "broker-0", tcpProxy.GetCluster())
+	assert.Equal(t, 10*time.Minute, tcpProxy.GetIdleTimeout().AsDuration())
+	require.Len(t, tcpProxy.GetAccessLog(), 1)
+	assert.Equal(t, "envoy.access_loggers.stdout", tcpProxy.GetAccessLog()[0].GetName())
+}
+
+func TestApplyConfigPatches(t *testing.T) {
+	config, err := protojson.Marshal(&envoybootstrap.Bootstrap{StaticResources: testStaticResources(t)})
+	require.NoError(t, err)
+
+	patched, err := applyConfigPatches(config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, config, patched)
+
+	patched, err = applyConfigPatches(config, []v1beta1.EnvoyConfigPatch{
+		{Op: "add", Path: "/staticResources/listeners/0/perConnectionBufferLimitBytes", Value: &runtime.RawExtension{Raw: []byte("65536")}},
+		{Op: "replace", Path: "/staticResources/clusters/0/connectTimeout", Value: &runtime.RawExtension{Raw: []byte(`"3s"`)}},
+	})
+	require.NoError(t, err)
+	bootstrap := &envoybootstrap.Bootstrap{}
+	require.NoError(t, protojson.Unmarshal(patched, bootstrap))
+	assert.Equal(t, uint32(65536), bootstrap.GetStaticResources().GetListeners()[0].GetPerConnectionBufferLimitBytes().GetValue())
+	assert.Equal(t, 3*time.Second, bootstrap.GetStaticResources().GetClusters()[0].GetConnectTimeout().AsDuration())
+
+	// Patches resulting in invalid Envoy configuration are rejected
+	_, err = applyConfigPatches(config, []v1beta1.EnvoyConfigPatch{
+		{Op: "add", Path: "/staticResources/unknownField", Value: &runtime.RawExtension{Raw: []byte("true")}},
+	})
+	assert.Error(t, err)
+
+	_, err = applyConfigPatches(config, []v1beta1.EnvoyConfigPatch{{Op: "remove", Path: "/staticResources/missing"}})
+	assert.Error(t, err)
+}
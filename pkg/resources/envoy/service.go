@@ -52,8 +52,9 @@ func (r *Reconciler) service(log logr.Logger, extListener v1beta1.ExternalListen
 			Selector:                 labelsForEnvoyIngress(r.KafkaCluster.GetName(), eListenerLabelName),
 			Type:                     ingressConfig.GetServiceType(),
 			Ports:                    exposedPorts,
-			LoadBalancerSourceRanges: ingressConfig.EnvoyConfig.GetLoadBalancerSourceRanges(),
+			LoadBalancerSourceRanges: ingressConfig.IngressServiceSettings.GetLoadBalancerSourceRanges(ingressConfig.EnvoyConfig.GetLoadBalancerSourceRanges()),
 			LoadBalancerIP:           ingressConfig.EnvoyConfig.LoadBalancerIP,
+			LoadBalancerClass:        ingressConfig.LoadBalancerClass,
 			ExternalTrafficPolicy:    ingressConfig.ExternalTrafficPolicy,
 		},
 	}
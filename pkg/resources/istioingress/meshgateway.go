@@ -76,7 +76,7 @@ func (r *Reconciler) meshgateway(log logr.Logger, externalListenerConfig v1beta1
 					util.GetBrokerIdsFromStatusAndSpec(r.KafkaCluster.Status.BrokersState, r.KafkaCluster.Spec.Brokers, log),
 					externalListenerConfig, log, ingressConfigName, defaultIngressConfigName),
 				Type:                     string(ingressConfig.GetServiceType()),
-				LoadBalancerSourceRanges: ingressConfig.IstioIngressConfig.GetLoadBalancerSourceRanges(),
+				LoadBalancerSourceRanges: ingressConfig.IngressServiceSettings.GetLoadBalancerSourceRanges(ingressConfig.IstioIngressConfig.GetLoadBalancerSourceRanges()),
 			},
 			RunAsRoot: wrapperspb.Bool(true),
 			Type:      istioOperatorApi.GatewayType_ingress,
@@ -0,0 +1,37 @@
+#
+# Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+SVID_DIR=/var/run/secrets/spiffe.io
+CLUSTER_CA=/var/run/secrets/java.io/keystores/client/ca.crt
+until [[ -s "$SVID_DIR/svid.pem" && -s "$SVID_DIR/svid_key.pem" && -s "$SVID_DIR/bundle.pem" ]]; do
+  echo "waiting for the X.509 SVID of the broker";
+  sleep 1;
+done
+assemble_spiffe_stores() {
+  cat "$SVID_DIR/svid_key.pem" "$SVID_DIR/svid.pem" > "$SVID_DIR/keystore.pem.tmp" && mv -f "$SVID_DIR/keystore.pem.tmp" "$SVID_DIR/keystore.pem"
+  cat "$SVID_DIR/bundle.pem" > "$SVID_DIR/truststore.pem.tmp"
+  if [[ -s "$CLUSTER_CA" ]]; then
+    cat "$CLUSTER_CA" >> "$SVID_DIR/truststore.pem.tmp"
+  fi
+  mv -f "$SVID_DIR/truststore.pem.tmp" "$SVID_DIR/truststore.pem"
+}
+assemble_spiffe_stores
+while sleep 10; do
+  if [[ "$SVID_DIR/svid.pem" -nt "$SVID_DIR/keystore.pem" || "$SVID_DIR/svid_key.pem" -nt "$SVID_DIR/keystore.pem" || "$SVID_DIR/bundle.pem" -nt "$SVID_DIR/truststore.pem" ]]; then
+    # give spiffe-helper the time to finish writing the key and the certificate of the rotated SVID
+    sleep 2;
+    assemble_spiffe_stores
+  fi
+done &
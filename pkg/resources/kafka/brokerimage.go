@@ -0,0 +1,62 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// BrokerImageChangedEventReason is the reason of the Event emitted when a broker is restarted with a different image
+const BrokerImageChangedEventReason = "BrokerImageChanged"
+
+// kafkaContainerImage returns the image of the kafka container of the broker pod
+func kafkaContainerImage(pod *corev1.Pod) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == "kafka" {
+			return container.Image
+		}
+	}
+	return ""
+}
+
+// brokersWithUnconfirmedImage returns the IDs of the brokers, apart from the one of the given pod, whose pod runs a
+// different image than the one recorded in the status of the broker. Broker groups may run different images during a
+// staged migration, so instead of comparing the images of the brokers with each other the next broker is held back
+// until every restarted broker reports the image it runs. Changing only the registry of the images does not need
+// a confirmation.
+func brokersWithUnconfirmedImage(cluster *v1beta1.KafkaCluster, pods []corev1.Pod, currentPod *corev1.Pod) []string {
+	var brokerIDs []string
+	for i := range pods {
+		pod := &pods[i]
+		brokerID := pod.GetLabels()[v1beta1.BrokerIdLabelKey]
+		if pod.GetName() == currentPod.GetName() || brokerID == "" {
+			continue
+		}
+		state, ok := cluster.Status.BrokersState[brokerID]
+		// Brokers which have never reported their image are new ones, they do not take part in the rolling upgrade
+		if !ok || state.Image == "" {
+			continue
+		}
+		if image := kafkaContainerImage(pod); image != "" && image != state.Image && image != cluster.Spec.GetImage(state.Image) {
+			brokerIDs = append(brokerIDs, brokerID)
+		}
+	}
+	sort.Strings(brokerIDs)
+	return brokerIDs
+}
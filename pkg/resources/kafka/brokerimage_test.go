@@ -0,0 +1,72 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestBrokersWithUnconfirmedImage(t *testing.T) {
+	brokerPod := func(brokerID, image string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "kafka-" + brokerID,
+				Labels: map[string]string{v1beta1.BrokerIdLabelKey: brokerID},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "envoy", Image: "envoy:v1"},
+					{Name: "kafka", Image: image},
+				},
+			},
+		}
+	}
+	cluster := &v1beta1.KafkaCluster{
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState: map[string]v1beta1.BrokerState{
+				"0": {Image: "kafka:amd64"},
+				"1": {Image: "kafka:arm64"},
+				"2": {Image: "kafka:amd64"},
+				"3": {},
+			},
+		},
+	}
+
+	// Broker groups running different images are not considered to be in the middle of a restart
+	pods := []corev1.Pod{
+		brokerPod("0", "kafka:amd64"),
+		brokerPod("1", "kafka:arm64"),
+		brokerPod("2", "kafka:amd64"),
+		brokerPod("3", "kafka:arm64"),
+	}
+	assert.Empty(t, brokersWithUnconfirmedImage(cluster, pods, &pods[0]))
+
+	// Broker 2 has been moved to the new image but has not reported it yet
+	pods[2] = brokerPod("2", "kafka:arm64")
+	assert.Equal(t, []string{"2"}, brokersWithUnconfirmedImage(cluster, pods, &pods[0]))
+	// The broker which is about to be restarted is not taken into account
+	assert.Empty(t, brokersWithUnconfirmedImage(cluster, pods, &pods[2]))
+
+	// Pulling the images from a mirror is not an image change
+	cluster.Spec.ImageRegistry = "mirror.internal"
+	pods[2] = brokerPod("2", "mirror.internal/kafka:amd64")
+	assert.Empty(t, brokersWithUnconfirmedImage(cluster, pods, &pods[0]))
+}
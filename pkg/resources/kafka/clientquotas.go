@@ -0,0 +1,127 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/Shopify/sarama"
+	"github.com/go-logr/logr"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
+
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+// reconcileDefaultClientQuotas applies the cluster default quotas of the users and client IDs as dynamic configs
+func (r *Reconciler) reconcileDefaultClientQuotas(log logr.Logger) error {
+	quotaConfig := r.KafkaCluster.Spec.ClientQuotaConfig
+	if quotaConfig == nil || (quotaConfig.UserDefaults == nil && quotaConfig.ClientIDDefaults == nil) {
+		return nil
+	}
+
+	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	defer close()
+
+	for _, defaults := range []struct {
+		entityType sarama.QuotaEntityType
+		quotas     *v1beta1.ClientQuotas
+	}{
+		{entityType: sarama.QuotaEntityUser, quotas: quotaConfig.UserDefaults},
+		{entityType: sarama.QuotaEntityClientID, quotas: quotaConfig.ClientIDDefaults},
+	} {
+		if defaults.quotas == nil {
+			continue
+		}
+
+		currentQuotas, err := kClient.DescribeDefaultClientQuotas(defaults.entityType)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "could not describe default client quotas", "entityType", defaults.entityType)
+		}
+
+		changes := clientQuotaChanges(currentQuotas, desiredClientQuotas(defaults.quotas))
+		if len(changes) == 0 {
+			continue
+		}
+
+		if err = kClient.AlterDefaultClientQuotas(defaults.entityType, changes, true); err != nil {
+			return errors.WrapIfWithDetails(err, "validation of default client quotas update failed", "entityType", defaults.entityType)
+		}
+		if err = kClient.AlterDefaultClientQuotas(defaults.entityType, changes, false); err != nil {
+			return errors.WrapIfWithDetails(err, "could not alter default client quotas", "entityType", defaults.entityType)
+		}
+		log.Info("default client quotas updated", "entityType", defaults.entityType)
+	}
+
+	return nil
+}
+
+// desiredClientQuotas returns the quota keys and values of the given client quotas
+func desiredClientQuotas(quotas *v1beta1.ClientQuotas) map[string]float64 {
+	desired := make(map[string]float64)
+	if quotas.ProducerByteRate != nil {
+		desired[kafkautils.KafkaQuotaProducerByteRate] = float64(*quotas.ProducerByteRate)
+	}
+	if quotas.ConsumerByteRate != nil {
+		desired[kafkautils.KafkaQuotaConsumerByteRate] = float64(*quotas.ConsumerByteRate)
+	}
+	if quotas.RequestPercentage != nil {
+		desired[kafkautils.KafkaQuotaRequestPercentage] = float64(*quotas.RequestPercentage)
+	}
+	return desired
+}
+
+// clientQuotaChanges returns the quotas which have to be set to reach the desired state, the managed quotas which
+// are not desired are returned with nil value to be removed
+func clientQuotaChanges(current, desired map[string]float64) map[string]*float64 {
+	changes := make(map[string]*float64)
+	for key, value := range desired {
+		if currentValue, ok := current[key]; !ok || currentValue != value {
+			value := value
+			changes[key] = &value
+		}
+	}
+	for _, key := range []string{kafkautils.KafkaQuotaProducerByteRate, kafkautils.KafkaQuotaConsumerByteRate, kafkautils.KafkaQuotaRequestPercentage} {
+		_, isCurrent := current[key]
+		_, isDesired := desired[key]
+		if isCurrent && !isDesired {
+			changes[key] = nil
+		}
+	}
+	return changes
+}
+
+// generateClientQuotaCallbackConfig returns the configuration properties of the client quota callback plugin of the brokers
+func generateClientQuotaCallbackConfig(quotaConfig *v1beta1.ClientQuotaConfig, log logr.Logger) *properties.Properties {
+	config := properties.NewProperties()
+	if quotaConfig == nil || quotaConfig.CallbackClassName == "" {
+		return config
+	}
+	for key, value := range quotaConfig.PluginConfig {
+		if err := config.Set(key, value); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", key))
+		}
+	}
+	if err := config.Set(kafkautils.KafkaConfigClientQuotaCallbackClass, quotaConfig.CallbackClassName); err != nil {
+		log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigClientQuotaCallbackClass))
+	}
+	return config
+}
@@ -0,0 +1,63 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestClientQuotaChanges(t *testing.T) {
+	tests := []struct {
+		testName string
+		current  map[string]float64
+		quotas   *v1beta1.ClientQuotas
+		expected map[string]*float64
+	}{
+		{
+			testName: "new quotas are set",
+			current:  map[string]float64{},
+			quotas:   &v1beta1.ClientQuotas{ProducerByteRate: util.Int64Pointer(1024), RequestPercentage: util.Int32Pointer(50)},
+			expected: map[string]*float64{"producer_byte_rate": floatPointer(1024), "request_percentage": floatPointer(50)},
+		},
+		{
+			testName: "unchanged quotas are skipped",
+			current:  map[string]float64{"producer_byte_rate": 1024, "consumer_byte_rate": 2048},
+			quotas:   &v1beta1.ClientQuotas{ProducerByteRate: util.Int64Pointer(1024), ConsumerByteRate: util.Int64Pointer(4096)},
+			expected: map[string]*float64{"consumer_byte_rate": floatPointer(4096)},
+		},
+		{
+			testName: "quotas which are not set are removed",
+			current:  map[string]float64{"producer_byte_rate": 1024, "request_percentage": 50},
+			quotas:   &v1beta1.ClientQuotas{ProducerByteRate: util.Int64Pointer(1024)},
+			expected: map[string]*float64{"request_percentage": nil},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.testName, func(t *testing.T) {
+			assert.Equal(t, test.expected, clientQuotaChanges(test.current, desiredClientQuotas(test.quotas)))
+		})
+	}
+}
+
+func floatPointer(value float64) *float64 {
+	return &value
+}
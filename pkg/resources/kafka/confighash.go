@@ -0,0 +1,139 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util/kafka"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+// brokerConfigHash returns the hash of the effective configuration of the broker: the broker configuration apart
+// from the configs which are updated without restart, the images of the containers and the contents of the Secrets
+// mounted into the pod. The certificates of the brokers are left out, since their renewal is picked up by reloading
+// the listener stores. The hash is set as an annotation of the broker pod, so that only the brokers whose hash
+// changed are restarted.
+func (r *Reconciler) brokerConfigHash(ctx context.Context, configMap *corev1.ConfigMap, pod *corev1.Pod) (string, error) {
+	h := sha256.New()
+
+	config, err := properties.NewFromString(configMap.Data[kafka.ConfigPropertyName])
+	if err != nil {
+		return "", errors.WrapIf(err, "could not parse the broker configuration")
+	}
+	for _, perBrokerConfig := range kafka.PerBrokerConfigKeys(config) {
+		config.Delete(perBrokerConfig)
+	}
+	config.Sort()
+	for _, key := range config.Keys() {
+		property, _ := config.Get(key)
+		writeHashEntry(h, "config", key, property.Value())
+	}
+
+	for _, container := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		writeHashEntry(h, "image", container.Name, container.Image)
+	}
+
+	renewedSecrets := r.brokerCertificateSecretNames()
+	for _, secretName := range mountedSecretNames(pod) {
+		if _, ok := renewedSecrets[secretName]; ok {
+			continue
+		}
+		secret := &corev1.Secret{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: pod.GetNamespace()}, secret)
+		if apierrors.IsNotFound(err) {
+			// Optional secrets may not exist, their creation changes the hash
+			continue
+		}
+		if err != nil {
+			return "", errors.WrapIfWithDetails(err, "could not get secret mounted into the broker pod", "secret", secretName)
+		}
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			writeHashEntry(h, "secret", secretName+"/"+key, string(secret.Data[key]))
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// brokerCertificateSecretNames returns the names of the Secrets holding the server certificates of the SSL listeners
+// and the client certificate of the brokers, which are renewed while the brokers are running
+func (r *Reconciler) brokerCertificateSecretNames() map[string]struct{} {
+	secretNames := make(map[string]struct{})
+	if r.KafkaCluster == nil {
+		return secretNames
+	}
+	for _, listener := range sslListeners(r.KafkaCluster.Spec.ListenersConfig) {
+		secretNames[pkicommon.ListenerServerCertSecretName(r.KafkaCluster.Name, listener)] = struct{}{}
+	}
+	secretNames[pkicommon.ClientSSLCertSecretName(r.KafkaCluster)] = struct{}{}
+	return secretNames
+}
+
+// configHashUnchanged returns true when both pods have the configuration hash annotation and it is the same, so
+// the changes of the broker configuration do not require the broker to be restarted
+func configHashUnchanged(desiredPod, currentPod *corev1.Pod) bool {
+	desiredHash, ok := desiredPod.GetAnnotations()[v1beta1.ConfigHashAnnotationKey]
+	if !ok {
+		return false
+	}
+	currentHash, ok := currentPod.GetAnnotations()[v1beta1.ConfigHashAnnotationKey]
+	return ok && currentHash == desiredHash
+}
+
+// writeHashEntry writes the length prefixed fields of an entry so that different entries cannot have the same encoding
+func writeHashEntry(h hash.Hash, kind, key, value string) {
+	fmt.Fprintf(h, "%s:%d:%s:%d:%s\n", kind, len(key), key, len(value), value)
+}
+
+// mountedSecretNames returns the sorted names of the Secrets referenced by the volumes of the pod
+func mountedSecretNames(pod *corev1.Pod) []string {
+	secretNames := make(map[string]struct{})
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Secret != nil {
+			secretNames[volume.Secret.SecretName] = struct{}{}
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					secretNames[source.Secret.Name] = struct{}{}
+				}
+			}
+		}
+	}
+	ret := make([]string, 0, len(secretNames))
+	for secretName := range secretNames {
+		ret = append(ret, secretName)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,129 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/util/kafka"
+)
+
+func TestBrokerConfigHash(t *testing.T) {
+	ctx := context.Background()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "server-cert", Namespace: "kafka"},
+		Data:       map[string][]byte{"keystore.jks": []byte("keystore")},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+	r := Reconciler{Reconciler: resources.Reconciler{Client: c}}
+
+	newConfigMap := func(config string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{Data: map[string]string{kafka.ConfigPropertyName: config}}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kafka"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "kafka", Image: "kafka:3.4.0"}},
+			Volumes: []corev1.Volume{
+				{Name: "cert", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "server-cert"}}},
+				{Name: "optional", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "missing"}}},
+			},
+		},
+	}
+
+	hash, err := r.brokerConfigHash(ctx, newConfigMap("broker.id=0\nlog.retention.hours=24\nlisteners=INTERNAL://:29092"), pod)
+	require.NoError(t, err)
+	assert.NotEmpty(t, hash)
+
+	// Changing the per broker configs does not change the hash
+	sameHash, err := r.brokerConfigHash(ctx, newConfigMap("log.retention.hours=24\nbroker.id=0\nlisteners=INTERNAL://:29093"), pod)
+	require.NoError(t, err)
+	assert.Equal(t, hash, sameHash)
+
+	configChanged, err := r.brokerConfigHash(ctx, newConfigMap("broker.id=0\nlog.retention.hours=48\nlisteners=INTERNAL://:29092"), pod)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, configChanged)
+
+	imageChanged := pod.DeepCopy()
+	imageChanged.Spec.Containers[0].Image = "kafka:3.4.1"
+	imageChangedHash, err := r.brokerConfigHash(ctx, newConfigMap("broker.id=0\nlog.retention.hours=24\nlisteners=INTERNAL://:29092"), imageChanged)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, imageChangedHash)
+
+	secret.Data["keystore.jks"] = []byte("rotated")
+	require.NoError(t, c.Update(ctx, secret))
+	secretChanged, err := r.brokerConfigHash(ctx, newConfigMap("broker.id=0\nlog.retention.hours=24\nlisteners=INTERNAL://:29092"), pod)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, secretChanged)
+
+	// The renewal of the server certificate of the listeners does not change the hash
+	r.KafkaCluster = &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{{CommonListenerSpec: v1beta1.CommonListenerSpec{
+					Name: "internal", Type: v1beta1.SecurityProtocolSSL, ContainerPort: 29092,
+					ServerSSLCertSecret: &corev1.LocalObjectReference{Name: "server-cert"},
+				}}},
+			},
+		},
+	}
+	certHash, err := r.brokerConfigHash(ctx, newConfigMap("broker.id=0\nlog.retention.hours=24\nlisteners=INTERNAL://:29092"), pod)
+	require.NoError(t, err)
+	secret.Data["keystore.jks"] = []byte("renewed")
+	require.NoError(t, c.Update(ctx, secret))
+	certRenewedHash, err := r.brokerConfigHash(ctx, newConfigMap("broker.id=0\nlog.retention.hours=24\nlisteners=INTERNAL://:29092"), pod)
+	require.NoError(t, err)
+	assert.Equal(t, certHash, certRenewedHash)
+}
+
+func TestConfigHashUnchanged(t *testing.T) {
+	newPod := func(annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+	hashed := map[string]string{v1beta1.ConfigHashAnnotationKey: "hash"}
+
+	assert.True(t, configHashUnchanged(newPod(hashed), newPod(hashed)))
+	assert.False(t, configHashUnchanged(newPod(hashed), newPod(map[string]string{v1beta1.ConfigHashAnnotationKey: "other"})))
+	assert.False(t, configHashUnchanged(newPod(hashed), newPod(nil)))
+	assert.False(t, configHashUnchanged(newPod(nil), newPod(nil)))
+}
+
+func TestOnlyPodMetadataChanged(t *testing.T) {
+	currentPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka-0", Labels: map[string]string{"app": "kafka"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "kafka", Image: "kafka:3.4.0"}}},
+	}
+	require.NoError(t, patch.DefaultAnnotator.SetLastAppliedAnnotation(currentPod))
+
+	labelChanged := currentPod.DeepCopy()
+	labelChanged.Labels["team"] = "streaming"
+	assert.True(t, onlyPodMetadataChanged(logr.Discard(), labelChanged, currentPod))
+
+	imageChanged := labelChanged.DeepCopy()
+	imageChanged.Spec.Containers[0].Image = "kafka:3.4.1"
+	assert.False(t, onlyPodMetadataChanged(logr.Discard(), imageChanged, currentPod))
+}
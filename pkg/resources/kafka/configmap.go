@@ -33,6 +33,7 @@ import (
 	"github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/resources/templates"
 	"github.com/banzaicloud/koperator/pkg/util"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
 	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
 	zookeeperutils "github.com/banzaicloud/koperator/pkg/util/zookeeper"
 	properties "github.com/banzaicloud/koperator/properties/pkg"
@@ -136,8 +137,21 @@ func (r *Reconciler) getConfigProperties(bConfig *v1beta1.BrokerConfig, id int32
 		}
 	}
 
+	// Add delegation token configuration
+	config.Merge(r.generateDelegationTokenConfig(log))
+
+	// Add client quota callback configuration
+	config.Merge(generateClientQuotaCallbackConfig(r.KafkaCluster.Spec.ClientQuotaConfig, log))
+
+	// Add authorizer configuration
+	suPrincipals := generateSuperUsers(superUsers)
+	if authConfig := r.KafkaCluster.Spec.AuthorizationConfig; authConfig != nil {
+		config.Merge(generateAuthorizerConfig(authConfig, log))
+		suPrincipals = appendMissingPrincipals(suPrincipals, authConfig.SuperUsers...)
+	}
+
 	// Add superuser configuration
-	su := strings.Join(generateSuperUsers(superUsers), ";")
+	su := strings.Join(suPrincipals, ";")
 	if su != "" {
 		if err := config.Set(kafkautils.KafkaConfigSuperUsers, su); err != nil {
 			log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigSuperUsers))
@@ -171,6 +185,46 @@ func mergeMountPaths(mountPathsOld, mountPathsNew []string) ([]string, bool) {
 	return mountPathsMerged, isMountPathRemoved
 }
 
+// generateAuthorizerConfig returns the configuration properties of the authorizer plugin of the brokers
+func generateAuthorizerConfig(authConfig *v1beta1.AuthorizationConfig, log logr.Logger) *properties.Properties {
+	config := properties.NewProperties()
+	for key, value := range authConfig.PluginConfig {
+		if err := config.Set(key, value); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", key))
+		}
+	}
+	if err := config.Set(kafkautils.KafkaConfigAuthorizerClassName, authConfig.GetAuthorizerClassName()); err != nil {
+		log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", kafkautils.KafkaConfigAuthorizerClassName))
+	}
+	if opa := authConfig.OPA; opa != nil && authConfig.GetAuthorizer() == v1beta1.AuthorizerTypeOPA {
+		opaConfig := map[string]interface{}{
+			kafkautils.KafkaConfigOPAAuthorizerURL: opa.URL,
+		}
+		if opa.AllowOnError != nil {
+			opaConfig[kafkautils.KafkaConfigOPAAuthorizerAllowOnError] = *opa.AllowOnError
+		}
+		if opa.CacheExpireAfterSeconds != nil {
+			opaConfig[kafkautils.KafkaConfigOPAAuthorizerCacheExpireAfterSeconds] = *opa.CacheExpireAfterSeconds
+		}
+		for key, value := range opaConfig {
+			if err := config.Set(key, value); err != nil {
+				log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", key))
+			}
+		}
+	}
+	return config
+}
+
+// appendMissingPrincipals appends the principals which are not present yet
+func appendMissingPrincipals(principals []string, additional ...string) []string {
+	for _, principal := range additional {
+		if !util.StringSliceContains(principals, principal) {
+			principals = append(principals, principal)
+		}
+	}
+	return principals
+}
+
 func generateSuperUsers(users []string) (suStrings []string) {
 	suStrings = make([]string, 0)
 	for _, x := range users {
@@ -194,9 +248,12 @@ func (r *Reconciler) configMap(id int32, brokerConfig *v1beta1.BrokerConfig, ext
 		Data: map[string]string{kafkautils.ConfigPropertyName: r.generateBrokerConfig(id, brokerConfig, extListenerStatuses,
 			intListenerStatuses, controllerIntListenerStatuses, serverPasses, clientPass, superUsers, log)},
 	}
-	if brokerConfig.Log4jConfig != "" {
+	if brokerConfig.Log4jConfig != "" && brokerConfig.Log4jConfigMap == nil {
 		brokerConf.Data["log4j.properties"] = brokerConfig.Log4jConfig
 	}
+	if len(r.KafkaCluster.Spec.GetSPIFFEListeners()) > 0 {
+		brokerConf.Data[spiffeHelperConfigKey] = generateSPIFFEHelperConfig(r.KafkaCluster.Spec.SPIFFEConfig)
+	}
 	return brokerConf
 }
 
@@ -208,6 +265,15 @@ func generateAdvertisedListenerConfig(id int32, l v1beta1.ListenersConfig,
 	advertisedListenerConfig = appendListenerConfigs(advertisedListenerConfig, id, intListenerStatuses)
 	advertisedListenerConfig = appendListenerConfigs(advertisedListenerConfig, id, controllerIntListenerStatuses)
 
+	// the brokers advertise their address on the secondary network of the listeners bound to one
+	networkAttachmentAddresses := networkAttachmentListenerAddresses(l)
+	for i, listenerConfig := range advertisedListenerConfig {
+		listenerName := strings.SplitN(listenerConfig, "://", 2)[0]
+		if address, ok := networkAttachmentAddresses[listenerName]; ok {
+			advertisedListenerConfig[i] = fmt.Sprintf("%s://%s", listenerName, address)
+		}
+	}
+
 	return advertisedListenerConfig
 }
 
@@ -267,12 +333,14 @@ func generateControlPlaneListener(iListeners []v1beta1.InternalListenerConfig) s
 func generateListenerSpecificConfig(l *v1beta1.ListenersConfig, serverPasses map[string]string, log logr.Logger) *properties.Properties {
 	var (
 		interBrokerListenerName   string
+		replicationListenerName   string
 		securityProtocolMapConfig []string
 		listenerConfig            []string
 	)
 
 	config := properties.NewProperties()
 
+	networkAttachmentAddresses := networkAttachmentListenerAddresses(*l)
 	for _, iListener := range l.InternalListeners {
 		if iListener.UsedForInnerBrokerCommunication {
 			if interBrokerListenerName == "" {
@@ -281,14 +349,27 @@ func generateListenerSpecificConfig(l *v1beta1.ListenersConfig, serverPasses map
 				log.Error(errors.New("inter broker listener name already set"), "config error")
 			}
 		}
+		if iListener.UsedForReplication {
+			if replicationListenerName == "" {
+				replicationListenerName = strings.ToUpper(iListener.Name)
+			} else {
+				log.Error(errors.New("replication listener name already set"), "config error")
+			}
+		}
 		upperedListenerType := iListener.Type.ToUpperString()
 		upperedListenerName := strings.ToUpper(iListener.Name)
 		securityProtocolMapConfig = append(securityProtocolMapConfig, fmt.Sprintf("%s:%s", upperedListenerName, upperedListenerType))
-		listenerConfig = append(listenerConfig, fmt.Sprintf("%s://:%d", upperedListenerName, iListener.ContainerPort))
+		// the listeners bound to a secondary network listen only on the address of the broker pod on that network
+		if address, ok := networkAttachmentAddresses[upperedListenerName]; ok {
+			listenerConfig = append(listenerConfig, fmt.Sprintf("%s://%s", upperedListenerName, address))
+		} else {
+			listenerConfig = append(listenerConfig, fmt.Sprintf("%s://:%d", upperedListenerName, iListener.ContainerPort))
+		}
 		// Add internal listeners SSL configuration
 		if iListener.Type == v1beta1.SecurityProtocolSSL {
-			generateListenerSSLConfig(config, iListener.Name, iListener.SSLClientAuth, serverPasses[iListener.Name], log)
+			generateListenerSSLConfig(config, iListener.CommonListenerSpec, serverPasses[iListener.Name], log)
 		}
+		generateListenerConnectionLimitsConfig(config, iListener.CommonListenerSpec, log)
 	}
 
 	for _, eListener := range l.ExternalListeners {
@@ -298,12 +379,18 @@ func generateListenerSpecificConfig(l *v1beta1.ListenersConfig, serverPasses map
 		listenerConfig = append(listenerConfig, fmt.Sprintf("%s://:%d", upperedListenerName, eListener.ContainerPort))
 		// Add external listeners SSL configuration
 		if eListener.Type == v1beta1.SecurityProtocolSSL {
-			generateListenerSSLConfig(config, eListener.Name, eListener.SSLClientAuth, serverPasses[eListener.Name], log)
+			generateListenerSSLConfig(config, eListener.CommonListenerSpec, serverPasses[eListener.Name], log)
 		}
+		generateListenerConnectionLimitsConfig(config, eListener.CommonListenerSpec, log)
 	}
+	generateBrokerConnectionLimitsConfig(config, l.ConnectionLimits, log)
 	if err := config.Set(kafkautils.KafkaConfigListenerSecurityProtocolMap, securityProtocolMapConfig); err != nil {
 		log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", kafkautils.KafkaConfigListenerSecurityProtocolMap))
 	}
+	// the brokers replicate over the dedicated replication listener when there is one
+	if replicationListenerName != "" {
+		interBrokerListenerName = replicationListenerName
+	}
 	if err := config.Set(kafkautils.KafkaConfigInterBrokerListenerName, interBrokerListenerName); err != nil {
 		log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", kafkautils.KafkaConfigInterBrokerListenerName))
 	}
@@ -313,21 +400,30 @@ func generateListenerSpecificConfig(l *v1beta1.ListenersConfig, serverPasses map
 	return config
 }
 
-func generateListenerSSLConfig(config *properties.Properties, name string, sslClientAuth v1beta1.SSLClientAuthentication, password string, log logr.Logger) {
+func generateListenerSSLConfig(config *properties.Properties, commonSpec v1beta1.CommonListenerSpec, password string, log logr.Logger) {
 	var listenerSSLConfig map[string]string
+	name, sslClientAuth := commonSpec.Name, commonSpec.SSLClientAuth
 	namedKeystorePath := fmt.Sprintf(listenerServerKeyStorePathTemplate, serverKeystorePath, name)
-	keyStoreType := "JKS"
-	keyStoreLoc := namedKeystorePath + "/" + v1alpha1.TLSJKSKeyStore
-	trustStoreType := "JKS"
-	trustStoreLoc := namedKeystorePath + "/" + v1alpha1.TLSJKSTrustStore
+	keyStoreType := string(commonSpec.TLSPolicy.GetKeyStoreType())
+	keyStoreLoc := namedKeystorePath + "/" + certutil.KeyStoreFileName(commonSpec.TLSPolicy.GetKeyStoreType())
+	trustStoreType := string(commonSpec.TLSPolicy.GetTrustStoreType())
+	trustStoreLoc := namedKeystorePath + "/" + certutil.TrustStoreFileName(commonSpec.TLSPolicy.GetTrustStoreType())
+	// the PEM stores assembled from the X.509 SVID of the broker hold an unencrypted private key
+	if commonSpec.UsesSPIFFE() {
+		keyStoreType, trustStoreType = pemStoreType, pemStoreType
+		keyStoreLoc = spiffeSVIDPath + "/" + spiffeKeyStoreFile
+		trustStoreLoc = spiffeSVIDPath + "/" + spiffeTrustStoreFile
+	}
 
 	listenerSSLConfig = map[string]string{
 		fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLKeyStoreLocation):   keyStoreLoc,
 		fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLTrustStoreLocation): trustStoreLoc,
 		fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLKeystoreType):       keyStoreType,
 		fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLTrustStoreType):     trustStoreType,
-		fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLTrustStorePassword): password,
-		fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLKeyStorePassword):   password,
+	}
+	if !commonSpec.UsesSPIFFE() {
+		listenerSSLConfig[fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLTrustStorePassword)] = password
+		listenerSSLConfig[fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLKeyStorePassword)] = password
 	}
 
 	// enable 2-way SSL authentication if SSL is enabled but this field is not provided in the listener config
@@ -337,6 +433,21 @@ func generateListenerSSLConfig(config *properties.Properties, name string, sslCl
 		listenerSSLConfig[fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLClientAuth)] = string(sslClientAuth)
 	}
 
+	if policy := commonSpec.TLSPolicy; policy != nil {
+		if len(policy.EnabledProtocols) > 0 {
+			protocols := make([]string, 0, len(policy.EnabledProtocols))
+			for _, protocol := range policy.EnabledProtocols {
+				protocols = append(protocols, string(protocol))
+			}
+			sort.Strings(protocols)
+			listenerSSLConfig[fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLEnabledProtocols)] = strings.Join(protocols, ",")
+			listenerSSLConfig[fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLProtocol)] = protocols[len(protocols)-1]
+		}
+		if len(policy.CipherSuites) > 0 {
+			listenerSSLConfig[fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, name, kafkautils.KafkaConfigSSLCipherSuites)] = strings.Join(policy.CipherSuites, ",")
+		}
+	}
+
 	for k, v := range listenerSSLConfig {
 		if err := config.Set(k, v); err != nil {
 			log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", k))
@@ -344,6 +455,57 @@ func generateListenerSSLConfig(config *properties.Properties, name string, sslCl
 	}
 }
 
+// generateBrokerConnectionLimitsConfig sets the connection limits which apply to all the listeners of the broker
+func generateBrokerConnectionLimitsConfig(config *properties.Properties, limits *v1beta1.BrokerConnectionLimits, log logr.Logger) {
+	if limits == nil {
+		return
+	}
+	limitsConfig := map[string]*int32{
+		kafkautils.KafkaConfigMaxConnections:            limits.MaxConnections,
+		kafkautils.KafkaConfigMaxConnectionsPerIP:       limits.MaxConnectionsPerIP,
+		kafkautils.KafkaConfigMaxConnectionCreationRate: limits.MaxConnectionCreationRate,
+	}
+	for k, v := range limitsConfig {
+		if v == nil {
+			continue
+		}
+		if err := config.Set(k, *v); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", k))
+		}
+	}
+
+	if len(limits.MaxConnectionsPerIPOverrides) > 0 {
+		overrides := make([]string, 0, len(limits.MaxConnectionsPerIPOverrides))
+		for host, maxConnections := range limits.MaxConnectionsPerIPOverrides {
+			overrides = append(overrides, fmt.Sprintf("%s:%d", host, maxConnections))
+		}
+		sort.Strings(overrides)
+		if err := config.Set(kafkautils.KafkaConfigMaxConnectionsPerIPOverrides, strings.Join(overrides, ",")); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", kafkautils.KafkaConfigMaxConnectionsPerIPOverrides))
+		}
+	}
+}
+
+// generateListenerConnectionLimitsConfig sets the listener specific connection limits
+func generateListenerConnectionLimitsConfig(config *properties.Properties, commonSpec v1beta1.CommonListenerSpec, log logr.Logger) {
+	limits := commonSpec.ConnectionLimits
+	if limits == nil {
+		return
+	}
+	limitsConfig := map[string]*int32{
+		fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, commonSpec.Name, kafkautils.KafkaConfigMaxConnections):            limits.MaxConnections,
+		fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, commonSpec.Name, kafkautils.KafkaConfigMaxConnectionCreationRate): limits.MaxConnectionCreationRate,
+	}
+	for k, v := range limitsConfig {
+		if v == nil {
+			continue
+		}
+		if err := config.Set(k, *v); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' parameter in broker configuration resulted an error", k))
+		}
+	}
+}
+
 // mergeSuperUsersPropertyValue merges the target and source super.users property value, and returns it as string.
 // It returns empty string when there were no updates or any of the super.users property value was empty.
 func mergeSuperUsersPropertyValue(source *properties.Properties, target *properties.Properties) string {
@@ -127,6 +127,52 @@ func TestMergeMountPaths(t *testing.T) {
 	}
 }
 
+func TestConnectionLimitsConfig(t *testing.T) {
+	maxConnections, perIP, creationRate := int32(1000), int32(50), int32(20)
+	listeners := &v1beta1.ListenersConfig{
+		InternalListeners: []v1beta1.InternalListenerConfig{
+			{
+				CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolPlaintext, ContainerPort: 29092},
+			},
+		},
+		ExternalListeners: []v1beta1.ExternalListenerConfig{
+			{
+				CommonListenerSpec: v1beta1.CommonListenerSpec{
+					Name:             "external",
+					Type:             v1beta1.SecurityProtocolPlaintext,
+					ContainerPort:    9094,
+					ConnectionLimits: &v1beta1.ListenerConnectionLimits{MaxConnections: &perIP, MaxConnectionCreationRate: &creationRate},
+				},
+			},
+		},
+		ConnectionLimits: &v1beta1.BrokerConnectionLimits{
+			MaxConnections:               &maxConnections,
+			MaxConnectionsPerIP:          &perIP,
+			MaxConnectionsPerIPOverrides: map[string]int32{"10.0.0.2": 200, "10.0.0.1": 100},
+		},
+	}
+
+	config := generateListenerSpecificConfig(listeners, nil, logr.Discard())
+	expected := map[string]string{
+		"max.connections":                                     "1000",
+		"max.connections.per.ip":                              "50",
+		"max.connections.per.ip.overrides":                    "10.0.0.1:100,10.0.0.2:200",
+		"listener.name.external.max.connections":              "50",
+		"listener.name.external.max.connection.creation.rate": "20",
+	}
+	for key, value := range expected {
+		property, found := config.Get(key)
+		if !found || property.Value() != value {
+			t.Errorf("expected %s=%s, got: %s", key, value, property.Value())
+		}
+	}
+	for _, key := range []string{"max.connection.creation.rate", "listener.name.internal.max.connections"} {
+		if _, found := config.Get(key); found {
+			t.Errorf("%s should not be set", key)
+		}
+	}
+}
+
 func TestGenerateBrokerConfig(t *testing.T) { //nolint funlen
 	tests := []struct {
 		testName                  string
@@ -140,6 +186,10 @@ func TestGenerateBrokerConfig(t *testing.T) { //nolint funlen
 		advertisedListenerAddress string
 		listenerType              string
 		sslClientAuth             v1beta1.SSLClientAuthentication
+		tlsPolicy                 *v1beta1.ListenerTLSPolicy
+		spiffe                    bool
+		authorizationConfig       *v1beta1.AuthorizationConfig
+		clientQuotaConfig         *v1beta1.ClientQuotaConfig
 		expectedConfig            string
 		perBrokerStorageConfig    []v1beta1.StorageConfig
 	}{
@@ -604,6 +654,147 @@ listener.security.protocol.map=INTERNAL:SSL
 listeners=INTERNAL://:9092
 metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
 super.users=User:CN=kafka-headless.kafka.svc.cluster.local
+zookeeper.connect=example.zk:2181/`,
+		},
+		{
+			testName:                  "configWithSSL_TLSPolicy",
+			zkAddresses:               []string{"example.zk:2181"},
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "ssl",
+			tlsPolicy: &v1beta1.ListenerTLSPolicy{
+				EnabledProtocols: []v1beta1.TLSProtocol{v1beta1.TLSProtocolV13, v1beta1.TLSProtocolV12},
+				CipherSuites:     []string{"TLS_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+				KeyStoreType:     v1beta1.KeyStoreTypePKCS12,
+			},
+			expectedConfig: `advertised.listeners=INTERNAL://kafka-0.kafka.svc.cluster.local:9092
+broker.id=0
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+cruise.control.metrics.reporter.security.protocol=SSL
+cruise.control.metrics.reporter.ssl.keystore.location=/var/run/secrets/java.io/keystores/client/keystore.jks
+cruise.control.metrics.reporter.ssl.keystore.password=keystore_clientpassword123
+cruise.control.metrics.reporter.ssl.truststore.location=/var/run/secrets/java.io/keystores/client/truststore.jks
+cruise.control.metrics.reporter.ssl.truststore.password=keystore_clientpassword123
+inter.broker.listener.name=INTERNAL
+listener.name.internal.ssl.cipher.suites=TLS_AES_256_GCM_SHA384,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384
+listener.name.internal.ssl.client.auth=required
+listener.name.internal.ssl.enabled.protocols=TLSv1.2,TLSv1.3
+listener.name.internal.ssl.keystore.location=/var/run/secrets/java.io/keystores/server/internal/keystore.p12
+listener.name.internal.ssl.keystore.password=keystore_serverpassword123
+listener.name.internal.ssl.keystore.type=PKCS12
+listener.name.internal.ssl.protocol=TLSv1.3
+listener.name.internal.ssl.truststore.location=/var/run/secrets/java.io/keystores/server/internal/truststore.jks
+listener.name.internal.ssl.truststore.password=keystore_serverpassword123
+listener.name.internal.ssl.truststore.type=JKS
+listener.security.protocol.map=INTERNAL:SSL
+listeners=INTERNAL://:9092
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+super.users=User:CN=kafka-headless.kafka.svc.cluster.local
+zookeeper.connect=example.zk:2181/`,
+		},
+		{
+			testName:                  "configWithSSL_SPIFFE",
+			zkAddresses:               []string{"example.zk:2181"},
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "ssl",
+			spiffe:                    true,
+			expectedConfig: `advertised.listeners=INTERNAL://kafka-0.kafka.svc.cluster.local:9092
+broker.id=0
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+cruise.control.metrics.reporter.security.protocol=SSL
+cruise.control.metrics.reporter.ssl.keystore.location=/var/run/secrets/java.io/keystores/client/keystore.jks
+cruise.control.metrics.reporter.ssl.keystore.password=keystore_clientpassword123
+cruise.control.metrics.reporter.ssl.truststore.location=/var/run/secrets/java.io/keystores/client/truststore.jks
+cruise.control.metrics.reporter.ssl.truststore.password=keystore_clientpassword123
+inter.broker.listener.name=INTERNAL
+listener.name.internal.ssl.client.auth=required
+listener.name.internal.ssl.keystore.location=/var/run/secrets/spiffe.io/keystore.pem
+listener.name.internal.ssl.keystore.type=PEM
+listener.name.internal.ssl.truststore.location=/var/run/secrets/spiffe.io/truststore.pem
+listener.name.internal.ssl.truststore.type=PEM
+listener.security.protocol.map=INTERNAL:SSL
+listeners=INTERNAL://:9092
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+super.users=User:CN=kafka-headless.kafka.svc.cluster.local
+zookeeper.connect=example.zk:2181/`,
+		},
+		{
+			testName:                  "basicConfigWithACLAuthorizer",
+			zkAddresses:               []string{"example.zk:2181"},
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			authorizationConfig: &v1beta1.AuthorizationConfig{
+				SuperUsers:   []string{"User:admin"},
+				PluginConfig: map[string]string{"allow.everyone.if.no.acl.found": "false"},
+			},
+			expectedConfig: `advertised.listeners=INTERNAL://kafka-0.kafka.svc.cluster.local:9092
+allow.everyone.if.no.acl.found=false
+authorizer.class.name=kafka.security.authorizer.AclAuthorizer
+broker.id=0
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT
+listeners=INTERNAL://:9092
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+super.users=User:admin
+zookeeper.connect=example.zk:2181/`,
+		},
+		{
+			testName:                  "configWithSSL_OPAAuthorizer",
+			zkAddresses:               []string{"example.zk:2181"},
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "ssl",
+			authorizationConfig: &v1beta1.AuthorizationConfig{
+				Authorizer: v1beta1.AuthorizerTypeOPA,
+				SuperUsers: []string{"User:CN=kafka-headless.kafka.svc.cluster.local", "User:admin"},
+				OPA:        &v1beta1.OPAAuthorizerConfig{URL: "http://opa:8181/v1/data/kafka/authz/allow"},
+			},
+			expectedConfig: `advertised.listeners=INTERNAL://kafka-0.kafka.svc.cluster.local:9092
+authorizer.class.name=org.openpolicyagent.kafka.OpaAuthorizer
+broker.id=0
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+cruise.control.metrics.reporter.security.protocol=SSL
+cruise.control.metrics.reporter.ssl.keystore.location=/var/run/secrets/java.io/keystores/client/keystore.jks
+cruise.control.metrics.reporter.ssl.keystore.password=keystore_clientpassword123
+cruise.control.metrics.reporter.ssl.truststore.location=/var/run/secrets/java.io/keystores/client/truststore.jks
+cruise.control.metrics.reporter.ssl.truststore.password=keystore_clientpassword123
+inter.broker.listener.name=INTERNAL
+listener.name.internal.ssl.client.auth=required
+listener.name.internal.ssl.keystore.location=/var/run/secrets/java.io/keystores/server/internal/keystore.jks
+listener.name.internal.ssl.keystore.password=keystore_serverpassword123
+listener.name.internal.ssl.keystore.type=JKS
+listener.name.internal.ssl.truststore.location=/var/run/secrets/java.io/keystores/server/internal/truststore.jks
+listener.name.internal.ssl.truststore.password=keystore_serverpassword123
+listener.name.internal.ssl.truststore.type=JKS
+listener.security.protocol.map=INTERNAL:SSL
+listeners=INTERNAL://:9092
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
+opa.authorizer.url=http://opa:8181/v1/data/kafka/authz/allow
+super.users=User:CN=kafka-headless.kafka.svc.cluster.local;User:admin
+zookeeper.connect=example.zk:2181/`,
+		},
+		{
+			testName:                  "configWithClientQuotaCallback",
+			zkAddresses:               []string{"example.zk:2181"},
+			advertisedListenerAddress: `kafka-0.kafka.svc.cluster.local:9092`,
+			listenerType:              "plaintext",
+			clientQuotaConfig: &v1beta1.ClientQuotaConfig{
+				CallbackClassName: "io.strimzi.kafka.quotas.StaticQuotaCallback",
+				PluginConfig:      map[string]string{"client.quota.callback.static.produce": "1000000"},
+			},
+			expectedConfig: `advertised.listeners=INTERNAL://kafka-0.kafka.svc.cluster.local:9092
+broker.id=0
+client.quota.callback.class=io.strimzi.kafka.quotas.StaticQuotaCallback
+client.quota.callback.static.produce=1000000
+cruise.control.metrics.reporter.bootstrap.servers=kafka-all-broker.kafka.svc.cluster.local:9092
+cruise.control.metrics.reporter.kubernetes.mode=true
+inter.broker.listener.name=INTERNAL
+listener.security.protocol.map=INTERNAL:PLAINTEXT
+listeners=INTERNAL://:9092
+metric.reporters=com.linkedin.kafka.cruisecontrol.metricsreporter.CruiseControlMetricsReporter
 zookeeper.connect=example.zk:2181/`,
 		},
 	}
@@ -641,12 +832,16 @@ zookeeper.connect=example.zk:2181/`,
 												Name: "server-secret",
 											},
 											SSLClientAuth: test.sslClientAuth,
+											TLSPolicy:     test.tlsPolicy,
+											SPIFFE:        test.spiffe,
 										},
 										UsedForInnerBrokerCommunication: true,
 									},
 								},
 							},
 							ReadOnlyConfig:          test.readOnlyConfig,
+							AuthorizationConfig:     test.authorizationConfig,
+							ClientQuotaConfig:       test.clientQuotaConfig,
 							KubernetesClusterDomain: test.kubernetesClusterDomain,
 							ClusterWideConfig:       test.clusterWideConfig,
 							Brokers: []v1beta1.Broker{{
@@ -44,8 +44,14 @@ func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfi
 		return errors.WrapIf(err, "could not parse broker configuration")
 	}
 
+	// the brokers reload the stores of the listeners when their location is set again and the files have been
+	// modified since they were loaded last, which happens when the X.509 SVID is rotated or the certificate is renewed
+	reloadStoreConfig := r.renewedListenerStoreConfig(brokerId, log)
+	for key, value := range r.rotatedSPIFFEStoreConfig(brokerId, log) {
+		reloadStoreConfig[key] = value
+	}
 	currentPerBrokerConfigState := r.KafkaCluster.Status.BrokersState[strconv.Itoa(int(brokerId))].PerBrokerConfigurationState
-	if fullPerBrokerConfig.Len() == 0 && currentPerBrokerConfigState != v1beta1.PerBrokerConfigOutOfSync {
+	if fullPerBrokerConfig.Len() == 0 && len(reloadStoreConfig) == 0 && currentPerBrokerConfigState != v1beta1.PerBrokerConfigOutOfSync {
 		return nil
 	}
 
@@ -54,11 +60,18 @@ func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfi
 	if err != nil {
 		return errors.WrapIf(err, "could not parse broker configuration from configmap")
 	}
-	for _, perBrokerConfig := range kafka.PerBrokerConfigs {
-		if configProperty, ok := configsFromConfigMap.Get(perBrokerConfig); ok {
+	for _, perBrokerConfig := range kafka.PerBrokerConfigKeys(configsFromConfigMap) {
+		// the addresses of the secondary networks are only resolved when the broker is started, the listeners on
+		// them are changed by restarting the broker with the new pod spec anyway
+		if configProperty, ok := configsFromConfigMap.Get(perBrokerConfig); ok && !hasNetworkAttachmentAddress(configProperty.Value()) {
 			fullPerBrokerConfig.Put(configProperty)
 		}
 	}
+	for key, value := range reloadStoreConfig {
+		if err := fullPerBrokerConfig.Set(key, value); err != nil {
+			return errors.WrapIfWithDetails(err, "could not set listener store configuration", "key", key)
+		}
+	}
 
 	// query the current config
 	brokerConfigKeys := fullPerBrokerConfig.Keys()
@@ -107,11 +120,19 @@ func (r *Reconciler) reconcilePerBrokerDynamicConfig(brokerId int32, brokerConfi
 		if statusErr != nil {
 			return errors.WrapIfWithDetails(err, "updating status for per-broker configuration status failed", v1beta1.BrokerIdLabelKey, brokerId)
 		}
-	} else if currentPerBrokerConfigState != v1beta1.PerBrokerConfigInSync {
-		log.V(1).Info("setting per broker config status to in sync")
-		statusErr := k8sutil.UpdateBrokerStatus(r.Client, []string{strconv.Itoa(int(brokerId))}, r.KafkaCluster, v1beta1.PerBrokerConfigInSync, log)
-		if statusErr != nil {
-			return errors.WrapIfWithDetails(err, "updating status for per-broker configuration status failed", v1beta1.BrokerIdLabelKey, brokerId)
+	} else {
+		if len(reloadStoreConfig) > 0 {
+			log.Info("reloading keystores of the listeners with rotated certificate")
+			if err := kClient.AlterPerBrokerConfig(brokerId, util.ConvertPropertiesToMapStringPointer(fullPerBrokerConfig), false); err != nil {
+				return errors.WrapIfWithDetails(err, "could not reload keystores of the listeners", v1beta1.BrokerIdLabelKey, brokerId)
+			}
+		}
+		if currentPerBrokerConfigState != v1beta1.PerBrokerConfigInSync {
+			log.V(1).Info("setting per broker config status to in sync")
+			statusErr := k8sutil.UpdateBrokerStatus(r.Client, []string{strconv.Itoa(int(brokerId))}, r.KafkaCluster, v1beta1.PerBrokerConfigInSync, log)
+			if statusErr != nil {
+				return errors.WrapIfWithDetails(err, "updating status for per-broker configuration status failed", v1beta1.BrokerIdLabelKey, brokerId)
+			}
 		}
 	}
 
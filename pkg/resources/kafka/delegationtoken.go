@@ -0,0 +1,110 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/util"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	delegationTokenSecretVolume = "delegation-token-secret"
+	delegationTokenSecretPath   = "/var/run/secrets/kafka/delegation-token"
+
+	// The secret key is read by the brokers from the mounted Secret through a config provider so that it is not
+	// written into the ConfigMap of the brokers
+	delegationTokenConfigProvider      = "delegationtoken"
+	delegationTokenConfigProviderClass = "org.apache.kafka.common.config.provider.DirectoryConfigProvider"
+)
+
+// getDelegationTokenSecretKey returns the secret the brokers use to generate and verify the delegation tokens
+func (r *Reconciler) getDelegationTokenSecretKey(ctx context.Context) (string, error) {
+	secretKeyRef := r.KafkaCluster.Spec.DelegationTokenConfig.SecretKeyRef
+	secret := &corev1.Secret{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: secretKeyRef.Name, Namespace: r.KafkaCluster.Namespace}, secret)
+	if apierrors.IsNotFound(err) {
+		return "", errorfactory.New(errorfactory.ResourceNotReady{}, err, "delegation token secret not found", "secret", secretKeyRef.Name)
+	}
+	if err != nil {
+		return "", errors.WrapIfWithDetails(err, "failed to get delegation token secret", "secret", secretKeyRef.Name)
+	}
+	secretKey, ok := secret.Data[secretKeyRef.Key]
+	if !ok || len(secretKey) == 0 {
+		return "", errorfactory.New(errorfactory.ResourceNotReady{}, errors.New("key not found in secret"),
+			"delegation token secret is not ready", "secret", secretKeyRef.Name, "key", secretKeyRef.Key)
+	}
+	return string(secretKey), nil
+}
+
+// generateDelegationTokenConfig returns the delegation token configuration of the brokers
+func (r *Reconciler) generateDelegationTokenConfig(log logr.Logger) *properties.Properties {
+	config := properties.NewProperties()
+	tokenConfig := r.KafkaCluster.Spec.DelegationTokenConfig
+	if tokenConfig == nil {
+		return config
+	}
+
+	if _, err := r.getDelegationTokenSecretKey(context.Background()); err != nil {
+		log.Error(err, "getting the delegation token secret resulted an error")
+		return config
+	}
+	tokenProperties := map[string]interface{}{
+		kafkautils.KafkaConfigConfigProviders: delegationTokenConfigProvider,
+		fmt.Sprintf(kafkautils.KafkaConfigConfigProviderClassTemplate, delegationTokenConfigProvider): delegationTokenConfigProviderClass,
+		kafkautils.KafkaConfigDelegationTokenSecretKey: fmt.Sprintf("${%s:%s:%s}",
+			delegationTokenConfigProvider, delegationTokenSecretPath, tokenConfig.SecretKeyRef.Key),
+		kafkautils.KafkaConfigDelegationTokenMaxLifetime: tokenConfig.GetMaxLifetimeSeconds() * 1000,
+		kafkautils.KafkaConfigDelegationTokenExpiryTime:  tokenConfig.GetExpiryTimeSeconds() * 1000,
+	}
+	for key, value := range tokenProperties {
+		if err := config.Set(key, value); err != nil {
+			log.Error(err, fmt.Sprintf("setting '%s' in broker configuration resulted an error", key))
+		}
+	}
+	return config
+}
+
+func generateDelegationTokenSecretVolume(tokenConfig *v1beta1.DelegationTokenConfig) corev1.Volume {
+	return corev1.Volume{
+		Name: delegationTokenSecretVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  tokenConfig.SecretKeyRef.Name,
+				Items:       []corev1.KeyToPath{{Key: tokenConfig.SecretKeyRef.Key, Path: tokenConfig.SecretKeyRef.Key}},
+				DefaultMode: util.Int32Pointer(0400),
+			},
+		},
+	}
+}
+
+func generateDelegationTokenSecretVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      delegationTokenSecretVolume,
+		MountPath: delegationTokenSecretPath,
+		ReadOnly:  true,
+	}
+}
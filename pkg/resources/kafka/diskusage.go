@@ -0,0 +1,159 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+var (
+	logDirUsedBytesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_broker_log_dir_used_bytes",
+		Help: "Total size of the partition replicas stored in the log directory of the broker",
+	}, []string{"namespace", "kafka_cluster", "broker_id", "log_dir"})
+	logDirCapacityBytesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_broker_log_dir_capacity_bytes",
+		Help: "Requested storage size of the persistent volume claim of the log directory of the broker",
+	}, []string{"namespace", "kafka_cluster", "broker_id", "log_dir"})
+	logDirOfflineGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "koperator_broker_log_dir_offline",
+		Help: "Whether the log directory of the broker cannot be used due to storage errors",
+	}, []string{"namespace", "kafka_cluster", "broker_id", "log_dir"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(logDirUsedBytesGauge, logDirCapacityBytesGauge, logDirOfflineGauge)
+}
+
+// updateDiskUsage publishes the disk usage of the log directories of the brokers registered in the cluster into the
+// status of the KafkaCluster and the metrics of the operator
+func (r *Reconciler) updateDiskUsage(ctx context.Context, log logr.Logger) error {
+	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	defer close()
+
+	// The log directories can only be described for the brokers which are registered in the cluster
+	registered := kClient.Brokers()
+	capacities := make(map[int32]map[string]int64, len(r.KafkaCluster.Spec.Brokers))
+	brokerIDs := make([]int32, 0, len(r.KafkaCluster.Spec.Brokers))
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		if _, ok := registered[broker.Id]; !ok {
+			continue
+		}
+		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
+		if err != nil {
+			return errors.WrapIf(err, "failed to compute broker configuration")
+		}
+		brokerIDs = append(brokerIDs, broker.Id)
+		capacities[broker.Id] = logDirCapacities(brokerConfig.StorageConfigs)
+	}
+
+	var diskUsage map[string][]v1beta1.LogDirUsage
+	if len(brokerIDs) > 0 {
+		usage, err := kClient.DescribeLogDirUsage(brokerIDs)
+		if err != nil {
+			return errors.WrapIf(err, "could not describe the disk usage of the brokers")
+		}
+		diskUsage = toLogDirUsageStatus(usage, capacities)
+	}
+
+	r.exportDiskUsageMetrics(diskUsage)
+	if equality.Semantic.DeepEqual(r.KafkaCluster.Status.DiskUsage, diskUsage) {
+		return nil
+	}
+
+	err = k8sutil.PatchKafkaClusterStatus(ctx, r.Client, r.KafkaCluster, func(status *v1beta1.KafkaClusterStatus) {
+		status.DiskUsage = diskUsage
+	})
+	if err != nil {
+		return errors.WrapIf(err, "could not update the disk usage in the KafkaCluster status")
+	}
+	log.V(1).Info("disk usage of the brokers updated")
+	return nil
+}
+
+// logDirCapacities returns the requested storage size of the log directories backed by persistent volume claims
+func logDirCapacities(storageConfigs []v1beta1.StorageConfig) map[string]int64 {
+	capacities := make(map[string]int64, len(storageConfigs))
+	for _, storageConfig := range storageConfigs {
+		if storageConfig.PvcSpec == nil {
+			continue
+		}
+		if size, ok := storageConfig.PvcSpec.Resources.Requests[corev1.ResourceStorage]; ok && !size.IsZero() {
+			capacities[util.StorageConfigKafkaMountPath(storageConfig.MountPath)] = size.Value()
+		}
+	}
+	return capacities
+}
+
+func toLogDirUsageStatus(usage map[int32][]kafkaclient.LogDirUsage, capacities map[int32]map[string]int64) map[string][]v1beta1.LogDirUsage {
+	diskUsage := make(map[string][]v1beta1.LogDirUsage, len(usage))
+	for brokerID, dirs := range usage {
+		brokerUsage := make([]v1beta1.LogDirUsage, 0, len(dirs))
+		for _, dir := range dirs {
+			dirUsage := v1beta1.LogDirUsage{Path: dir.Path, UsedBytes: dir.UsedBytes, Offline: dir.Offline}
+			if capacity, ok := capacities[brokerID][dir.Path]; ok {
+				dirUsage.CapacityBytes = &capacity
+			}
+			brokerUsage = append(brokerUsage, dirUsage)
+		}
+		sort.Slice(brokerUsage, func(i, j int) bool { return brokerUsage[i].Path < brokerUsage[j].Path })
+		diskUsage[strconv.Itoa(int(brokerID))] = brokerUsage
+	}
+	return diskUsage
+}
+
+// exportDiskUsageMetrics replaces the disk usage metrics of the cluster, the series of the log directories which are
+// not reported anymore are removed
+func (r *Reconciler) exportDiskUsageMetrics(diskUsage map[string][]v1beta1.LogDirUsage) {
+	namespace, name := r.KafkaCluster.GetNamespace(), r.KafkaCluster.GetName()
+	for brokerID, dirs := range r.KafkaCluster.Status.DiskUsage {
+		for _, dir := range dirs {
+			for _, gauge := range []*prometheus.GaugeVec{logDirUsedBytesGauge, logDirCapacityBytesGauge, logDirOfflineGauge} {
+				gauge.DeleteLabelValues(namespace, name, brokerID, dir.Path)
+			}
+		}
+	}
+	for brokerID, dirs := range diskUsage {
+		for _, dir := range dirs {
+			logDirUsedBytesGauge.WithLabelValues(namespace, name, brokerID, dir.Path).Set(float64(dir.UsedBytes))
+			if dir.CapacityBytes != nil {
+				logDirCapacityBytesGauge.WithLabelValues(namespace, name, brokerID, dir.Path).Set(float64(*dir.CapacityBytes))
+			}
+			offline := 0.0
+			if dir.Offline {
+				offline = 1
+			}
+			logDirOfflineGauge.WithLabelValues(namespace, name, brokerID, dir.Path).Set(offline)
+		}
+	}
+}
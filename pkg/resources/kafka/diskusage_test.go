@@ -0,0 +1,54 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestToLogDirUsageStatus(t *testing.T) {
+	capacities := map[int32]map[string]int64{
+		0: logDirCapacities([]v1beta1.StorageConfig{
+			{
+				MountPath: "/kafka-logs",
+				PvcSpec: &corev1.PersistentVolumeClaimSpec{Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				}},
+			},
+			{MountPath: "/kafka-logs2", EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		}),
+	}
+	usage := map[int32][]kafkaclient.LogDirUsage{
+		0: {
+			{Path: "/kafka-logs2/kafka", UsedBytes: 20},
+			{Path: "/kafka-logs/kafka", UsedBytes: 1024, Offline: true},
+		},
+	}
+
+	assert.Equal(t, map[string][]v1beta1.LogDirUsage{
+		"0": {
+			{Path: "/kafka-logs/kafka", UsedBytes: 1024, CapacityBytes: util.Int64Pointer(10 * 1024 * 1024 * 1024), Offline: true},
+			{Path: "/kafka-logs2/kafka", UsedBytes: 20},
+		},
+	}, toLogDirUsageStatus(usage, capacities))
+}
@@ -0,0 +1,72 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// ExternalAddressChangedEventReason is the reason of the Event emitted when the address advertised for an external
+// listener changes, e.g. when the LoadBalancer has been re-provisioned. The brokers pick up the new address through
+// the dynamic update of advertised.listeners without restart.
+const ExternalAddressChangedEventReason = "ExternalAddressChanged"
+
+// reportExternalAddressChanges logs and emits an Event for every external listener whose addresses differ from the
+// ones recorded in the status of the KafkaCluster
+func (r *Reconciler) reportExternalAddressChanges(log logr.Logger, extListenerStatuses map[string]v1beta1.ListenerStatusList) {
+	changes := changedExternalAddresses(r.KafkaCluster.Status.ListenerStatuses.ExternalListeners, extListenerStatuses)
+	listenerNames := make([]string, 0, len(changes))
+	for listenerName := range changes {
+		listenerNames = append(listenerNames, listenerName)
+	}
+	sort.Strings(listenerNames)
+
+	for _, listenerName := range listenerNames {
+		log.Info("external listener address changed, advertising the new address", "externalListenerName", listenerName, "changes", changes[listenerName])
+		if r.Recorder != nil {
+			r.Recorder.Eventf(r.KafkaCluster, corev1.EventTypeNormal, ExternalAddressChangedEventReason,
+				"address of external listener %s changed: %s", listenerName, strings.Join(changes[listenerName], ", "))
+		}
+	}
+}
+
+// changedExternalAddresses returns the changed addresses per external listener in "<name>: <old> -> <new>" form.
+// Listeners without recorded addresses are not reported as their addresses are being set for the first time.
+func changedExternalAddresses(current, desired map[string]v1beta1.ListenerStatusList) map[string][]string {
+	changes := make(map[string][]string)
+	for listenerName, desiredStatuses := range desired {
+		currentStatuses, ok := current[listenerName]
+		if !ok || len(currentStatuses) == 0 {
+			continue
+		}
+		currentAddresses := make(map[string]string, len(currentStatuses))
+		for _, status := range currentStatuses {
+			currentAddresses[status.Name] = status.Address
+		}
+		for _, status := range desiredStatuses {
+			if address, ok := currentAddresses[status.Name]; ok && address != status.Address {
+				changes[listenerName] = append(changes[listenerName], fmt.Sprintf("%s: %s -> %s", status.Name, address, status.Address))
+			}
+		}
+	}
+	return changes
+}
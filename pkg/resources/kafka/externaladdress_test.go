@@ -0,0 +1,59 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestChangedExternalAddresses(t *testing.T) {
+	current := map[string]v1beta1.ListenerStatusList{
+		"external": {
+			{Name: "any-broker", Address: "10.0.0.1:29092"},
+			{Name: "broker-0", Address: "10.0.0.1:19090"},
+			{Name: "broker-1", Address: "10.0.0.1:19091"},
+		},
+		"unchanged": {
+			{Name: "broker-0", Address: "kafka.example.com:19090"},
+		},
+	}
+	desired := map[string]v1beta1.ListenerStatusList{
+		"external": {
+			{Name: "any-broker", Address: "lb.example.com:29092"},
+			{Name: "broker-0", Address: "lb.example.com:19090"},
+			{Name: "broker-1", Address: "lb.example.com:19091"},
+			{Name: "broker-2", Address: "lb.example.com:19092"},
+		},
+		"unchanged": {
+			{Name: "broker-0", Address: "kafka.example.com:19090"},
+		},
+		"new": {
+			{Name: "broker-0", Address: "10.0.0.2:19090"},
+		},
+	}
+
+	assert.Equal(t, map[string][]string{
+		"external": {
+			"any-broker: 10.0.0.1:29092 -> lb.example.com:29092",
+			"broker-0: 10.0.0.1:19090 -> lb.example.com:19090",
+			"broker-1: 10.0.0.1:19091 -> lb.example.com:19091",
+		},
+	}, changedExternalAddresses(current, desired))
+	assert.Empty(t, changedExternalAddresses(nil, desired))
+}
@@ -0,0 +1,93 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	clientutil "github.com/banzaicloud/koperator/pkg/util/client"
+)
+
+// RunHookJob creates the Job of the hook with the given name and returns true once it has completed successfully, or
+// when no hook is defined. A failed Job is reported as an error, it is retried when the Job is deleted. The containers
+// of the Job get the given environment variables on top of the coordinates of the Kafka cluster.
+func RunHookJob(ctx context.Context, c client.Client, log logr.Logger, cluster *v1beta1.KafkaCluster, hook *v1beta1.HookJob, name string, env ...corev1.EnvVar) (bool, error) {
+	if hook == nil {
+		return true, nil
+	}
+
+	job := &batchv1.Job{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: cluster.GetNamespace(), Name: name}, job)
+	if apierrors.IsNotFound(err) {
+		log.Info("creating hook job", "job", name)
+		if err = c.Create(ctx, hookJob(cluster, hook, name, env)); err != nil {
+			return false, errors.WrapIfWithDetails(err, "could not create hook job", "job", name)
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not get hook job", "job", name)
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, nil
+		case batchv1.JobFailed:
+			return false, errors.NewWithDetails("hook job failed", "job", name, "reason", condition.Reason,
+				"message", condition.Message)
+		}
+	}
+	return false, nil
+}
+
+func hookJob(cluster *v1beta1.KafkaCluster, hook *v1beta1.HookJob, name string, hookEnv []corev1.EnvVar) *batchv1.Job {
+	podTemplate := hook.Template.DeepCopy()
+	if podTemplate.Spec.RestartPolicy == "" {
+		podTemplate.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	// The hook gets the coordinates of the Kafka cluster to be able to reach the brokers
+	env := append([]corev1.EnvVar{
+		{Name: "KAFKA_CLUSTER_NAME", Value: cluster.GetName()},
+		{Name: "KAFKA_CLUSTER_NAMESPACE", Value: cluster.GetNamespace()},
+		{Name: "KAFKA_BOOTSTRAP_SERVERS", Value: clientutil.GenerateKafkaAddress(cluster)},
+	}, hookEnv...)
+	for i := range podTemplate.Spec.Containers {
+		podTemplate.Spec.Containers[i].Env = append(append([]corev1.EnvVar{}, env...), podTemplate.Spec.Containers[i].Env...)
+	}
+
+	backoffLimit := hook.GetBackoffLimit()
+	return &batchv1.Job{
+		ObjectMeta: templates.ObjectMeta(name, map[string]string{v1beta1.KafkaCRLabelKey: cluster.GetName()}, cluster),
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: hook.ActiveDeadlineSeconds,
+			Template:              *podTemplate,
+		},
+	}
+}
@@ -29,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiutil "github.com/banzaicloud/koperator/api/util"
@@ -47,7 +48,6 @@ import (
 	"github.com/banzaicloud/koperator/pkg/scale"
 	"github.com/banzaicloud/koperator/pkg/util"
 	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
-	envoyutils "github.com/banzaicloud/koperator/pkg/util/envoy"
 	istioingressutils "github.com/banzaicloud/koperator/pkg/util/istioingress"
 	"github.com/banzaicloud/koperator/pkg/util/kafka"
 	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
@@ -95,12 +95,13 @@ type Reconciler struct {
 }
 
 // New creates a new reconciler for Kafka
-func New(client client.Client, directClient client.Reader, cluster *v1beta1.KafkaCluster, kafkaClientProvider kafkaclient.Provider) *Reconciler {
+func New(client client.Client, directClient client.Reader, cluster *v1beta1.KafkaCluster, kafkaClientProvider kafkaclient.Provider, recorder record.EventRecorder) *Reconciler {
 	return &Reconciler{
 		Reconciler: resources.Reconciler{
 			Client:       client,
 			DirectClient: directClient,
 			KafkaCluster: cluster,
+			Recorder:     recorder,
 		},
 		kafkaClientProvider: kafkaClientProvider,
 	}
@@ -209,6 +210,11 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		}
 	}
 
+	// The outcome of the shutdown of the brokers is only verified on a best-effort basis
+	if err := r.verifyControlledShutdowns(ctx, log); err != nil {
+		log.V(1).Info("could not verify the controlled shutdown of the brokers", "error", err.Error())
+	}
+
 	// Handle Pod delete
 	err := r.reconcileKafkaPodDelete(ctx, log)
 	if err != nil {
@@ -219,12 +225,17 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 	if err != nil {
 		return errors.WrapIf(err, "could not update status for external listeners")
 	}
+	r.reportExternalAddressChanges(log, extListenerStatuses)
 	intListenerStatuses, controllerIntListenerStatuses := k8sutil.CreateInternalListenerStatuses(r.KafkaCluster)
 	err = k8sutil.UpdateListenerStatuses(ctx, r.Client, r.KafkaCluster, intListenerStatuses, extListenerStatuses)
 	if err != nil {
 		return errors.WrapIf(err, "failed to update listener statuses")
 	}
 
+	if err := r.reconcileServiceBinding(ctx, log); err != nil {
+		return err
+	}
+
 	// Setup the PKI if using SSL
 	if r.KafkaCluster.Spec.ListenersConfig.SSLSecrets != nil {
 		// reconcile the PKI
@@ -240,8 +251,21 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		return err
 	}
 
-	brokersVolumes := make(map[string][]*corev1.PersistentVolumeClaim, len(r.KafkaCluster.Spec.Brokers))
-	for _, broker := range r.KafkaCluster.Spec.Brokers {
+	// The brokers are not configured until the secret of the delegation tokens is available, otherwise they would
+	// start without delegation token support
+	if r.KafkaCluster.Spec.DelegationTokenConfig != nil {
+		if _, err := r.getDelegationTokenSecretKey(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := r.reconcileTrustBundle(ctx, log); err != nil {
+		return err
+	}
+
+	brokers := r.unparkedBrokers()
+	brokersVolumes := make(map[string][]*corev1.PersistentVolumeClaim, len(brokers))
+	for _, broker := range brokers {
 		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
 		if err != nil {
 			return errors.WrapIf(err, "failed to reconcile resource")
@@ -306,8 +330,14 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		}
 	}
 
-	reorderedBrokers := reorderBrokers(runningBrokers, boundPersistentVolumeClaims, r.KafkaCluster.Spec.Brokers, r.KafkaCluster.Status.BrokersState, controllerID, log)
+	reorderedBrokers := reorderBrokers(runningBrokers, boundPersistentVolumeClaims, brokers, r.KafkaCluster.Status.BrokersState, controllerID, log)
+	// The brokers of a cluster being bootstrapped are created all at once instead of waiting for each broker to come up
+	// before the next one is created
+	bootstrapping := r.KafkaCluster.IsBootstrapping()
+	var startingBrokers []int32
 	allBrokerDynamicConfigSucceeded := true
+	loggerClient := &brokerLoggerClient{reconciler: r}
+	defer loggerClient.Close()
 	for _, broker := range reorderedBrokers {
 		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
 		if err != nil {
@@ -317,14 +347,14 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		var configMap *corev1.ConfigMap
 		if r.KafkaCluster.Spec.RackAwareness == nil {
 			configMap = r.configMap(broker.Id, brokerConfig, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses, serverPasses, clientPass, superUsers, log)
-			err := k8sutil.Reconcile(log, r.Client, configMap, r.KafkaCluster)
+			err := k8sutil.ReconcileWithDriftDetection(log, r.Client, r.Recorder, configMap, r.KafkaCluster)
 			if err != nil {
 				return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", configMap.GetObjectKind().GroupVersionKind())
 			}
 		} else if brokerState, ok := r.KafkaCluster.Status.BrokersState[strconv.Itoa(int(broker.Id))]; ok {
 			if brokerState.RackAwarenessState != "" {
 				configMap = r.configMap(broker.Id, brokerConfig, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses, serverPasses, clientPass, superUsers, log)
-				err := k8sutil.Reconcile(log, r.Client, configMap, r.KafkaCluster)
+				err := k8sutil.ReconcileWithDriftDetection(log, r.Client, r.Recorder, configMap, r.KafkaCluster)
 				if err != nil {
 					return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", configMap.GetObjectKind().GroupVersionKind())
 				}
@@ -343,13 +373,31 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 				return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", o.GetObjectKind().GroupVersionKind())
 			}
 		}
+		if r.KafkaCluster.Spec.IsRemoteJMXEnabled() {
+			if err = r.reconcileRemoteJMXCredentials(ctx, broker.Id, log); err != nil {
+				return err
+			}
+		}
 		o := r.pod(broker.Id, brokerConfig, pvcs, log)
+		// The configuration of the broker is not rendered yet while its rack awareness state is unknown
+		if configMap != nil {
+			configHash, err := r.brokerConfigHash(ctx, configMap, o.(*corev1.Pod))
+			if err != nil {
+				return errors.WrapIfWithDetails(err, "failed to compute the configuration hash of the broker", v1beta1.BrokerIdLabelKey, broker.Id)
+			}
+			o.(*corev1.Pod).Annotations[v1beta1.ConfigHashAnnotationKey] = configHash
+		}
 		err = r.reconcileKafkaPod(log, o.(*corev1.Pod), brokerConfig)
 		if err != nil {
 			return err
 		}
 		if err = r.updateStatusWithDockerImageAndVersion(broker.Id, brokerConfig, log); err != nil {
-			return err
+			if !bootstrapping {
+				return err
+			}
+			log.V(1).Info("broker of the bootstrapping cluster is not up yet", v1beta1.BrokerIdLabelKey, broker.Id, "error", err.Error())
+			startingBrokers = append(startingBrokers, broker.Id)
+			continue
 		}
 		// If dynamic configs can not be set then let the loop continue to the next broker,
 		// after the loop we return error. This solves that case when other brokers could get healthy,
@@ -359,6 +407,16 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 			log.Error(err, "setting dynamic configs has failed", v1beta1.BrokerIdLabelKey, broker.Id)
 			allBrokerDynamicConfigSucceeded = false
 		}
+		// The logging configuration is only applied on a best-effort basis, the brokers keep working with their
+		// current logger levels
+		if err = r.reconcileBrokerLoggers(ctx, loggerClient, broker.Id, brokerConfig, log); err != nil {
+			log.Error(err, "setting logger levels has failed", v1beta1.BrokerIdLabelKey, broker.Id)
+		}
+	}
+
+	if len(startingBrokers) > 0 {
+		return errorfactory.New(errorfactory.ResourceNotReady{}, errors.New("brokers are not up yet"),
+			"waiting for the brokers of the bootstrapping cluster", "brokerIds", startingBrokers)
 	}
 
 	if !allBrokerDynamicConfigSucceeded {
@@ -373,6 +431,15 @@ func (r *Reconciler) Reconcile(log logr.Logger) error {
 		return err
 	}
 
+	if err = r.reconcileDefaultClientQuotas(log); err != nil {
+		return err
+	}
+
+	// The disk usage of the brokers is only reported on a best-effort basis
+	if err = r.updateDiskUsage(ctx, log); err != nil {
+		log.V(1).Info("could not update the disk usage of the brokers", "error", err.Error())
+	}
+
 	// in case HeadlessServiceEnabled is changed, delete the service that was created by the previous
 	// reconcile flow. The services must be deleted at the end of the reconcile flow after the new services
 	// were created and broker configurations reflecting the new services otherwise the Kafka brokers
@@ -412,8 +479,10 @@ func (r *Reconciler) reconcileKafkaPodDelete(ctx context.Context, log logr.Logge
 		return errors.WrapIf(err, "failed to reconcile resource")
 	}
 
-	brokerIDsFromSpec := make(map[string]bool, len(r.KafkaCluster.Spec.Brokers))
-	for _, broker := range r.KafkaCluster.Spec.Brokers {
+	// The brokers of the parked broker config groups are removed the same way as the brokers deleted from the spec
+	brokers := r.unparkedBrokers()
+	brokerIDsFromSpec := make(map[string]bool, len(brokers))
+	for _, broker := range brokers {
 		brokerIDsFromSpec[strconv.Itoa(int(broker.Id))] = true
 	}
 
@@ -434,10 +503,10 @@ func (r *Reconciler) reconcileKafkaPodDelete(ctx context.Context, log logr.Logge
 		if !arePodsAlreadyDeleted(podsDeletedFromSpec, log) {
 			cruiseControlURL := scale.CruiseControlURLFromKafkaCluster(r.KafkaCluster)
 			// FIXME: we should reuse the context of the Kafka Controller
-			cc, err := scale.NewCruiseControlScaler(context.TODO(), scale.CruiseControlURLFromKafkaCluster(r.KafkaCluster))
+			cc, err := scale.NewCruiseControlScalerForCluster(context.TODO(), r.Client, r.KafkaCluster)
 			if err != nil {
 				return errorfactory.New(errorfactory.CruiseControlNotReady{}, err,
-					"failed to initialize Cruise Control Scaler", "cruise control url", cruiseControlURL)
+					"failed to initialize Cruise Control Scaler", "cruise control url", scale.Redact(cruiseControlURL))
 			}
 
 			brokerStates := []scale.KafkaBrokerState{
@@ -446,7 +515,8 @@ func (r *Reconciler) reconcileKafkaPodDelete(ctx context.Context, log logr.Logge
 				scale.KafkaBrokerDemoted,
 				scale.KafkaBrokerBadDisks,
 			}
-			availableBrokers, err := cc.BrokersWithState(ctx, brokerStates...)
+			ccCtx := scale.WithRequestTag(ctx, scale.RequestTag{CorrelationID: string(r.KafkaCluster.GetUID()), Reason: "broker-removal"})
+			availableBrokers, err := cc.BrokersWithState(ccCtx, brokerStates...)
 			if err != nil {
 				log.Error(err, "failed to get the list of available brokers from Cruise Control")
 				return errorfactory.New(errorfactory.CruiseControlNotReady{}, err,
@@ -503,6 +573,15 @@ func (r *Reconciler) reconcileKafkaPodDelete(ctx context.Context, log logr.Logge
 				continue
 			}
 
+			ready, err := r.snapshotVolumesBeforeScaleDown(ctx, log, &broker)
+			if err != nil {
+				return errors.WrapIfWithDetails(err, "could not snapshot the volumes of the broker", "id", broker.Labels[v1beta1.BrokerIdLabelKey])
+			}
+			if !ready {
+				return errorfactory.New(errorfactory.ResourceNotReady{}, errors.New("volume snapshots are not ready to use yet"),
+					"waiting for the volume snapshots of the removed broker", "id", broker.Labels[v1beta1.BrokerIdLabelKey])
+			}
+
 			err = r.Client.Delete(context.TODO(), &broker)
 			if err != nil {
 				return errors.WrapIfWithDetails(err, "could not delete broker", "id", broker.Labels[v1beta1.BrokerIdLabelKey])
@@ -520,6 +599,11 @@ func (r *Reconciler) reconcileKafkaPodDelete(ctx context.Context, log logr.Logge
 			} else {
 				log.V(1).Info("configMap for broker deleted", "configMap name", configMapName, v1beta1.BrokerIdLabelKey, broker.Labels[v1beta1.BrokerIdLabelKey])
 			}
+			jmxSecretName := remoteJMXCredentialsSecretName(r.KafkaCluster.Name, broker.Labels[v1beta1.BrokerIdLabelKey])
+			err = r.Client.Delete(context.TODO(), &corev1.Secret{ObjectMeta: templates.ObjectMeta(jmxSecretName, apiutil.LabelsForKafka(r.KafkaCluster.Name), r.KafkaCluster)})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return errors.WrapIfWithDetails(err, "could not delete remote JMX credentials for broker", "id", broker.Labels[v1beta1.BrokerIdLabelKey])
+			}
 			if !r.KafkaCluster.Spec.HeadlessServiceEnabled {
 				serviceName := fmt.Sprintf("%s-%s", r.KafkaCluster.Name, broker.Labels[v1beta1.BrokerIdLabelKey])
 				err = r.Client.Delete(context.TODO(), &corev1.Service{ObjectMeta: templates.ObjectMeta(serviceName, apiutil.LabelsForKafka(r.KafkaCluster.Name), r.KafkaCluster)})
@@ -557,6 +641,17 @@ func (r *Reconciler) reconcileKafkaPodDelete(ctx context.Context, log logr.Logge
 	return nil
 }
 
+// unparkedBrokers returns the brokers of the spec which do not belong to a parked broker config group
+func (r *Reconciler) unparkedBrokers() []v1beta1.Broker {
+	brokers := make([]v1beta1.Broker, 0, len(r.KafkaCluster.Spec.Brokers))
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		if !r.KafkaCluster.IsBrokerParked(broker) {
+			brokers = append(brokers, broker)
+		}
+	}
+	return brokers
+}
+
 func arePodsAlreadyDeleted(pods []corev1.Pod, log logr.Logger) bool {
 	for _, broker := range pods {
 		if broker.ObjectMeta.DeletionTimestamp == nil {
@@ -599,11 +694,7 @@ func (r *Reconciler) getClientPasswordKeyAndUser() (string, string, error) {
 }
 
 func getListenerSSLCertSecret(client client.Reader, commonSpec v1beta1.CommonListenerSpec, clusterName string, clusterNamespace string) (*corev1.Secret, error) {
-	// Use default SSL cert secret
-	secretNamespacedName := types.NamespacedName{Name: fmt.Sprintf(pkicommon.BrokerServerCertTemplate, clusterName), Namespace: clusterNamespace}
-	if commonSpec.GetServerSSLCertSecretName() != "" {
-		secretNamespacedName = types.NamespacedName{Name: commonSpec.GetServerSSLCertSecretName(), Namespace: clusterNamespace}
-	}
+	secretNamespacedName := types.NamespacedName{Name: pkicommon.ListenerServerCertSecretName(clusterName, commonSpec), Namespace: clusterNamespace}
 	serverSecret := &corev1.Secret{}
 	if err := client.Get(context.TODO(), secretNamespacedName, serverSecret); err != nil {
 		if apierrors.IsNotFound(err) && commonSpec.GetServerSSLCertSecretName() == "" {
@@ -612,7 +703,7 @@ func getListenerSSLCertSecret(client client.Reader, commonSpec v1beta1.CommonLis
 		return nil, errors.WrapIfWithDetails(err, "failed to get server secret")
 	}
 	// Check secret data fields
-	if err := certutil.CheckSSLCertSecret(serverSecret); err != nil {
+	if err := certutil.CheckSSLCertSecretWithStoreTypes(serverSecret, commonSpec.TLSPolicy.GetKeyStoreType(), commonSpec.TLSPolicy.GetTrustStoreType()); err != nil {
 		if commonSpec.GetServerSSLCertSecretName() != "" {
 			return nil, err
 		}
@@ -632,10 +723,11 @@ func (r *Reconciler) getServerPasswordKeysAndUsers() (map[string]string, []strin
 	var err error
 	serverSecret := &corev1.Secret{}
 	for _, iListener := range r.KafkaCluster.Spec.ListenersConfig.InternalListeners {
-		if iListener.Type == v1beta1.SecurityProtocolSSL {
+		if iListener.Type == v1beta1.SecurityProtocolSSL && !iListener.UsesSPIFFE() {
 			// This implementation logic gets the generated ssl secret only once even
 			// if multiple listener use the generated one, because they share the same.
-			if globKeyPass == "" || iListener.GetServerSSLCertSecretName() != "" {
+			sharedCert := iListener.GetServerSSLCertSecretName() == "" && !iListener.HasDedicatedCertManagerCertificate()
+			if globKeyPass == "" || !sharedCert {
 				// get the appropriate secret: the generated as default or the custom if its specified
 				serverSecret, err = getListenerSSLCertSecret(r.Client, iListener.CommonListenerSpec, r.KafkaCluster.Name, r.KafkaCluster.Namespace)
 				if err != nil {
@@ -646,7 +738,7 @@ func (r *Reconciler) getServerPasswordKeysAndUsers() (map[string]string, []strin
 			}
 
 			// Set the globKeyPass if there is no custom server cert present
-			if r.KafkaCluster.Spec.ListenersConfig.SSLSecrets != nil && iListener.GetServerSSLCertSecretName() == "" {
+			if r.KafkaCluster.Spec.ListenersConfig.SSLSecrets != nil && sharedCert {
 				if globKeyPass == "" {
 					globKeyPass = string(serverSecret.Data[v1alpha1.PasswordKey])
 				}
@@ -656,7 +748,8 @@ func (r *Reconciler) getServerPasswordKeysAndUsers() (map[string]string, []strin
 			// That way we can continue to manage topics and users
 			// We put these Common Names from certificates into the superusers kafka broker config
 			if iListener.UsedForControllerCommunication || iListener.UsedForInnerBrokerCommunication {
-				tlsCert, err := certutil.ParseKeyStoreToTLSCertificate(serverSecret.Data[v1alpha1.TLSJKSKeyStore], serverSecret.Data[v1alpha1.PasswordKey])
+				keyStoreType := iListener.TLSPolicy.GetKeyStoreType()
+				tlsCert, err := certutil.ParseKeyStoreOfTypeToTLSCertificate(keyStoreType, serverSecret.Data[certutil.KeyStoreFileName(keyStoreType)], serverSecret.Data[v1alpha1.PasswordKey])
 				if err != nil {
 					return nil, nil, errors.WrapIfWithDetails(err, fmt.Sprintf("failed to decode certificate, secretName: %s", serverSecret.Name))
 				}
@@ -666,8 +759,9 @@ func (r *Reconciler) getServerPasswordKeysAndUsers() (map[string]string, []strin
 	}
 	// Same as at the internalListeners except we dont need to collect Common Names from certificates.
 	for _, eListener := range r.KafkaCluster.Spec.ListenersConfig.ExternalListeners {
-		if eListener.Type == v1beta1.SecurityProtocolSSL {
-			if globKeyPass == "" || eListener.GetServerSSLCertSecretName() != "" {
+		if eListener.Type == v1beta1.SecurityProtocolSSL && !eListener.UsesSPIFFE() {
+			sharedCert := eListener.GetServerSSLCertSecretName() == "" && !eListener.HasDedicatedCertManagerCertificate()
+			if globKeyPass == "" || !sharedCert {
 				serverSecret, err = getListenerSSLCertSecret(r.Client, eListener.CommonListenerSpec, r.KafkaCluster.Name, r.KafkaCluster.Namespace)
 				if err != nil {
 					return nil, nil, err
@@ -675,7 +769,7 @@ func (r *Reconciler) getServerPasswordKeysAndUsers() (map[string]string, []strin
 				pair[eListener.Name] = string(serverSecret.Data[v1alpha1.PasswordKey])
 			}
 
-			if r.KafkaCluster.Spec.ListenersConfig.SSLSecrets != nil && eListener.GetServerSSLCertSecretName() == "" {
+			if r.KafkaCluster.Spec.ListenersConfig.SSLSecrets != nil && sharedCert {
 				if globKeyPass == "" {
 					globKeyPass = string(serverSecret.Data[v1alpha1.PasswordKey])
 				}
@@ -809,7 +903,12 @@ func (r *Reconciler) updateStatusWithDockerImageAndVersion(brokerId int32, broke
 	kafkaVersion, err := jmxExp.ExtractDockerImageAndVersion(brokerId, brokerConfig,
 		r.KafkaCluster.Spec.GetClusterImage(), r.KafkaCluster.Spec.HeadlessServiceEnabled)
 	if err != nil {
-		return err
+		// The metrics of the broker may not be reachable by the operator, e.g. due to network policies, the broker
+		// is looked up through the Admin API in that case
+		var registered bool
+		if kafkaVersion, registered = r.brokerVersionFromAdminAPI(brokerId, brokerConfig, log); !registered {
+			return err
+		}
 	}
 	err = k8sutil.UpdateBrokerStatus(r.Client, []string{strconv.Itoa(int(brokerId))}, r.KafkaCluster,
 		*kafkaVersion, log)
@@ -819,7 +918,37 @@ func (r *Reconciler) updateStatusWithDockerImageAndVersion(brokerId int32, broke
 	return nil
 }
 
-func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPod *corev1.Pod, desiredType reflect.Type) error {
+// brokerVersionFromAdminAPI returns the image of the broker when it is registered in the cluster according to the
+// Admin API. The Kafka version is only known by the JMX exporter, the last reported one is kept unless the image changed.
+func (r *Reconciler) brokerVersionFromAdminAPI(brokerId int32, brokerConfig *v1beta1.BrokerConfig, log logr.Logger) (*v1beta1.KafkaVersion, bool) {
+	kClient, close, err := r.kafkaClientProvider.NewFromCluster(r.Client, r.KafkaCluster)
+	if err != nil {
+		return nil, false
+	}
+	defer close()
+	health, err := kClient.ProbeBrokerHealth([]int32{brokerId})
+	if err != nil {
+		log.V(1).Info("could not probe the health of the broker", v1beta1.BrokerIdLabelKey, brokerId, "error", err.Error())
+		return nil, false
+	}
+	if !health[brokerId].Registered {
+		return nil, false
+	}
+	kafkaVersion := &v1beta1.KafkaVersion{Image: util.GetBrokerImage(brokerConfig, r.KafkaCluster.Spec.GetClusterImage())}
+	if brokerState, ok := r.KafkaCluster.Status.BrokersState[strconv.Itoa(int(brokerId))]; ok && brokerState.Image == kafkaVersion.Image {
+		kafkaVersion.Version = brokerState.Version
+	}
+	return kafkaVersion, true
+}
+
+// prepareDesiredPod aligns the desired pod with the current one so that the differences which do not require
+// the recreation of the pod are not detected
+func prepareDesiredPod(desiredPod, currentPod *corev1.Pod) {
+	// Pods created before the configuration hash was introduced are not restarted just to get the hash, they get it
+	// when they are restarted for another reason
+	if _, ok := currentPod.GetAnnotations()[v1beta1.ConfigHashAnnotationKey]; !ok {
+		delete(desiredPod.Annotations, v1beta1.ConfigHashAnnotationKey)
+	}
 	// Since toleration does not support patchStrategy:"merge,retainKeys",
 	// we need to add all toleration from the current pod if the toleration is set in the CR
 	if len(desiredPod.Spec.Tolerations) > 0 {
@@ -834,16 +963,83 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 		}
 		desiredPod.Spec.Tolerations = uniqueTolerations
 	}
+}
+
+// onlyPodMetadataChanged returns true when the desired pod differs from the current one only in its labels and
+// annotations. A changed restart-at annotation asks for restarting the broker, so it is not a metadata only change.
+func onlyPodMetadataChanged(log logr.Logger, desiredPod, currentPod *corev1.Pod) bool {
+	if desiredPod.GetAnnotations()[v1beta1.RestartAtAnnotationKey] != currentPod.GetAnnotations()[v1beta1.RestartAtAnnotationKey] {
+		return false
+	}
+	desiredSpec := desiredPod.DeepCopy()
+	desiredSpec.Labels = currentPod.Labels
+	desiredSpec.Annotations = currentPod.Annotations
+	patchResult, err := patch.DefaultPatchMaker.Calculate(currentPod, desiredSpec)
+	if err != nil {
+		log.Error(err, "could not match objects", "kind", reflect.TypeOf(desiredPod))
+		return false
+	}
+	return patchResult.IsEmpty()
+}
+
+// updatePodMetadata updates the labels and annotations of the broker pod in place, without restarting the broker
+func (r *Reconciler) updatePodMetadata(log logr.Logger, desiredPod, currentPod *corev1.Pod) error {
+	if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(desiredPod); err != nil {
+		return errors.WrapIf(err, "could not apply last state to annotation")
+	}
+	pod := currentPod.DeepCopy()
+	if pod.Labels == nil {
+		pod.Labels = make(map[string]string, len(desiredPod.Labels))
+	}
+	for key, value := range desiredPod.Labels {
+		pod.Labels[key] = value
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string, len(desiredPod.Annotations))
+	}
+	for key, value := range desiredPod.Annotations {
+		pod.Annotations[key] = value
+	}
+	if err := r.Client.Update(context.TODO(), pod); err != nil {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "updating the metadata of the broker pod failed")
+	}
+	log.Info("metadata of the broker pod updated without restart")
+	return nil
+}
+
+func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPod *corev1.Pod, desiredType reflect.Type) error {
+	prepareDesiredPod(desiredPod, currentPod)
+	brokerId := currentPod.Labels[v1beta1.BrokerIdLabelKey]
+	configHashUnchanged := configHashUnchanged(desiredPod, currentPod)
+	configurationState := r.KafkaCluster.Status.BrokersState[brokerId].ConfigurationState
+	// The configuration hash covers every change of the configuration which requires the broker to be restarted
+	if configurationState == v1beta1.ConfigOutOfSync && configHashUnchanged {
+		log.Info("configuration changes of the broker do not require restart")
+		if err := k8sutil.UpdateBrokerStatus(r.Client, []string{brokerId}, r.KafkaCluster, v1beta1.ConfigInSync, log); err != nil {
+			return errors.WrapIf(err, "could not update broker status")
+		}
+		configurationState = v1beta1.ConfigInSync
+	}
+	podHealthyAndInSync := !k8sutil.IsPodContainsTerminatedContainer(currentPod) &&
+		configurationState == v1beta1.ConfigInSync &&
+		!k8sutil.IsPodContainsEvictedContainer(currentPod) &&
+		!k8sutil.IsPodContainsShutdownContainer(currentPod)
+	// Out-of-band changes of the pod are only reverted by restarting the broker when the drift policy enforces it
+	converge, err := k8sutil.HandleDrift(log, r.Recorder, currentPod, desiredPod, r.KafkaCluster)
+	if err != nil {
+		return err
+	}
+	if !converge && podHealthyAndInSync {
+		log.V(1).Info("out-of-band changes of the pod are kept according to the drift policy")
+		return nil
+	}
 	// Check if the resource actually updated
 	patchResult, err := patch.DefaultPatchMaker.Calculate(currentPod, desiredPod)
 	switch {
 	case err != nil:
 		log.Error(err, "could not match objects", "kind", desiredType)
 	case patchResult.IsEmpty():
-		if !k8sutil.IsPodContainsTerminatedContainer(currentPod) &&
-			r.KafkaCluster.Status.BrokersState[currentPod.Labels[v1beta1.BrokerIdLabelKey]].ConfigurationState == v1beta1.ConfigInSync &&
-			!k8sutil.IsPodContainsEvictedContainer(currentPod) &&
-			!k8sutil.IsPodContainsShutdownContainer(currentPod) {
+		if podHealthyAndInSync {
 			log.V(1).Info("resource is in sync")
 			return nil
 		}
@@ -853,6 +1049,9 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 			"current", string(patchResult.Current),
 			"modified", string(patchResult.Modified),
 			"original", string(patchResult.Original))
+		if podHealthyAndInSync && configHashUnchanged && onlyPodMetadataChanged(log, desiredPod, currentPod) {
+			return r.updatePodMetadata(log, desiredPod, currentPod)
+		}
 	}
 
 	if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(desiredPod); err != nil {
@@ -861,6 +1060,21 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 
 	if !k8sutil.IsPodContainsTerminatedContainer(currentPod) {
 		if r.KafkaCluster.Status.State != v1beta1.KafkaClusterRollingUpgrading {
+			completed, err := RunUpgradeHook(context.TODO(), r.Client, log, r.KafkaCluster,
+				r.KafkaCluster.Spec.RollingUpgradeConfig.PreUpgradeHook, PreUpgradeHookPhase)
+			if err != nil {
+				return errors.WrapIf(err, "pre-upgrade hook failed, rolling upgrade is held back")
+			}
+			if !completed {
+				return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("pre-upgrade hook job is running"), "rolling upgrade in progress")
+			}
+			ready, err := r.snapshotVolumesBeforeRollingUpgrade(context.TODO(), log)
+			if err != nil {
+				return errors.WrapIf(err, "could not snapshot the volumes of the brokers, rolling upgrade is held back")
+			}
+			if !ready {
+				return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("volume snapshots are not ready to use yet"), "rolling upgrade in progress")
+			}
 			if err := k8sutil.UpdateCRStatus(r.Client, r.KafkaCluster, v1beta1.KafkaClusterRollingUpgrading, log); err != nil {
 				return errorfactory.New(errorfactory.StatusUpdateError{}, err, "setting state to rolling upgrade failed")
 			}
@@ -883,6 +1097,10 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 					return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("pod is still creating"), "rolling upgrade in progress")
 				}
 			}
+			if brokerIDs := brokersWithUnconfirmedImage(r.KafkaCluster, podList.Items, currentPod); len(brokerIDs) > 0 {
+				log.Info("brokers have not reported their new image yet", "brokerIds", brokerIDs)
+				return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("image change of brokers is not confirmed yet"), "rolling upgrade in progress")
+			}
 
 			errorCount := r.KafkaCluster.Status.RollingUpgrade.ErrorCount
 
@@ -914,11 +1132,19 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 				impactedReplicas[brokerID] = struct{}{}
 			}
 
+			if err := r.checkBrokerReadinessGate(log, kClient, impactedReplicas, podList.Items, currentPod); err != nil {
+				return err
+			}
+
 			errorCount += len(impactedReplicas)
 
 			if errorCount >= r.KafkaCluster.Spec.RollingUpgradeConfig.FailureThreshold {
 				return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("cluster is not healthy"), "rolling upgrade in progress")
 			}
+
+			if err := r.checkMinInSyncReplicas(context.TODO(), log, kClient, currentPod); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -936,6 +1162,14 @@ func (r *Reconciler) handleRollingUpgrade(log logr.Logger, desiredPod, currentPo
 			}
 		}
 	}
+	if fromImage, toImage := kafkaContainerImage(currentPod), kafkaContainerImage(desiredPod); fromImage != toImage {
+		log.Info("broker is restarted with a different image", v1beta1.BrokerIdLabelKey, currentPod.Labels[v1beta1.BrokerIdLabelKey],
+			"fromImage", fromImage, "toImage", toImage)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(r.KafkaCluster, corev1.EventTypeNormal, BrokerImageChangedEventReason,
+				"broker %s is restarted with image %s instead of %s", currentPod.Labels[v1beta1.BrokerIdLabelKey], toImage, fromImage)
+		}
+	}
 	log.Info("broker pod deleted", "pod", currentPod.GetName(), v1beta1.BrokerIdLabelKey, currentPod.Labels[v1beta1.BrokerIdLabelKey])
 	return nil
 }
@@ -989,9 +1223,12 @@ func (r *Reconciler) reconcileKafkaPvc(ctx context.Context, log logr.Logger, bro
 					alreadyCreated = true
 					// Checking pvc state, if bounded, so the broker has already restarted and the CC GracefulDiskRebalance has not happened yet,
 					// then we make it happening with status update.
-					if _, ok := r.KafkaCluster.Status.BrokersState[brokerId].GracefulActionState.VolumeStates[mountPath]; !ok &&
+					// The volumes only having the state of the CC upscale or downscale of the broker recorded
+					// have not been rebalanced yet either
+					if volumeState := r.KafkaCluster.Status.BrokersState[brokerId].GracefulActionState.VolumeStates[mountPath]; volumeState.CruiseControlVolumeState == "" &&
 						currentPvc.Status.Phase == corev1.ClaimBound {
-						brokerVolumesState[mountPath] = v1beta1.VolumeState{CruiseControlVolumeState: v1beta1.GracefulDiskRebalanceRequired}
+						volumeState.CruiseControlVolumeState = v1beta1.GracefulDiskRebalanceRequired
+						brokerVolumesState[mountPath] = volumeState
 					}
 					break
 				}
@@ -1007,6 +1244,14 @@ func (r *Reconciler) reconcileKafkaPvc(ctx context.Context, log logr.Logger, bro
 				}
 				continue
 			}
+			// PersistentVolumeClaims retained from a deleted KafkaCluster with the same name are adopted
+			if !metav1.IsControlledBy(currentPvc, r.KafkaCluster) {
+				currentPvc.SetOwnerReferences(desiredPvc.GetOwnerReferences())
+				if err := r.Client.Update(ctx, currentPvc); err != nil {
+					return errorfactory.New(errorfactory.APIFailure{}, err, "adopting resource failed", "kind", desiredType)
+				}
+				log.Info("retained resource adopted", "name", currentPvc.GetName())
+			}
 			if err == nil {
 				if k8sutil.CheckIfObjectUpdated(log, desiredType, currentPvc, desiredPvc) {
 					if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(desiredPvc); err != nil {
@@ -1084,6 +1329,19 @@ func (r *Reconciler) getBrokerHost(log logr.Logger, defaultHost string, broker v
 		if err != nil {
 			return "", err
 		}
+		// brokers using the host network are reached directly on the address of their node
+		if bConfig.HostNetwork && brokerHost == "" && bConfig.NodePortExternalIP[eListener.Name] == "" {
+			nodeAddressType := bConfig.NodePortNodeAddressType
+			if nodeAddressType == "" {
+				nodeAddressType = corev1.NodeInternalIP
+			}
+			brokerHost, err = r.getK8sAssignedNodeAddress(broker.Id, string(nodeAddressType))
+			if err != nil {
+				log.Error(err, fmt.Sprintf("could not get the (%s) address of the broker's (ID: %d) node for external listener (%s) configuration",
+					nodeAddressType, broker.Id, eListener.Name))
+			}
+			return fmt.Sprintf("%s:%d", brokerHost, eListener.ContainerPort), nil
+		}
 		if eListener.ExternalStartingPort == 0 {
 			portNumber, err = r.getK8sAssignedNodeport(log, eListener.Name, broker.Id)
 			if err != nil {
@@ -1292,21 +1550,7 @@ func (r *Reconciler) determineControllerId() (int32, error) {
 func getServiceFromExternalListener(client client.Client, cluster *v1beta1.KafkaCluster,
 	eListenerName string, ingressConfigName string) (*corev1.Service, error) {
 	foundLBService := &corev1.Service{}
-	var iControllerServiceName string
-	switch cluster.Spec.GetIngressController() {
-	case istioingressutils.IngressControllerName:
-		if ingressConfigName == util.IngressConfigGlobalName {
-			iControllerServiceName = fmt.Sprintf(istioingressutils.MeshGatewayNameTemplate, eListenerName, cluster.GetName())
-		} else {
-			iControllerServiceName = fmt.Sprintf(istioingressutils.MeshGatewayNameTemplateWithScope, eListenerName, ingressConfigName, cluster.GetName())
-		}
-	case envoyutils.IngressControllerName:
-		if ingressConfigName == util.IngressConfigGlobalName {
-			iControllerServiceName = fmt.Sprintf(envoyutils.EnvoyServiceName, eListenerName, cluster.GetName())
-		} else {
-			iControllerServiceName = fmt.Sprintf(envoyutils.EnvoyServiceNameWithScope, eListenerName, ingressConfigName, cluster.GetName())
-		}
-	}
+	iControllerServiceName := util.GetIngressControllerServiceName(cluster, eListenerName, ingressConfigName)
 
 	err := client.Get(context.TODO(), types.NamespacedName{Name: iControllerServiceName, Namespace: cluster.GetNamespace()}, foundLBService)
 	if err != nil {
@@ -25,13 +25,19 @@ import (
 	"github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
 	"github.com/banzaicloud/koperator/pkg/resources"
 	mocks "github.com/banzaicloud/koperator/pkg/resources/kafka/mocks"
 )
@@ -951,3 +957,77 @@ func TestGetServerPasswordKeysAndUsers(t *testing.T) { //nolint funlen
 		})
 	}
 }
+
+func TestHandleRollingUpgradePodMetadata(t *testing.T) {
+	newPod := func(annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "kafka-0-abcde",
+				Namespace:   "kafka",
+				Labels:      map[string]string{v1beta1.AppLabelKey: "kafka", v1beta1.KafkaCRLabelKey: "kafka", v1beta1.BrokerIdLabelKey: "0"},
+				Annotations: annotations,
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "kafka", Image: "ghcr.io/banzaicloud/kafka:2.13-3.4.1"}}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "kafka",
+				Ready: true,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}}},
+		}
+	}
+	testCases := []struct {
+		testName           string
+		desiredAnnotations map[string]string
+		expectedDeleted    bool
+	}{
+		{
+			testName:           "only a metadata annotation changed",
+			desiredAnnotations: map[string]string{v1beta1.ConfigHashAnnotationKey: "hash", v1beta1.RestartAtAnnotationKey: "1", "team": "a"},
+			expectedDeleted:    false,
+		},
+		{
+			testName:           "only the restart-at annotation changed",
+			desiredAnnotations: map[string]string{v1beta1.ConfigHashAnnotationKey: "hash", v1beta1.RestartAtAnnotationKey: "2"},
+			expectedDeleted:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.testName, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, v1beta1.AddToScheme(scheme))
+			require.NoError(t, corev1.AddToScheme(scheme))
+			cluster := &v1beta1.KafkaCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+				Spec:       v1beta1.KafkaClusterSpec{RollingUpgradeConfig: v1beta1.RollingUpgradeConfig{FailureThreshold: 1}},
+				Status: v1beta1.KafkaClusterStatus{
+					State:        v1beta1.KafkaClusterRunning,
+					BrokersState: map[string]v1beta1.BrokerState{"0": {ConfigurationState: v1beta1.ConfigInSync}},
+				},
+			}
+			currentPod := newPod(map[string]string{v1beta1.ConfigHashAnnotationKey: "hash", v1beta1.RestartAtAnnotationKey: "1"})
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, currentPod).Build()
+			require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(currentPod), currentPod))
+			r := Reconciler{
+				Reconciler: resources.Reconciler{
+					Client:       fakeClient,
+					KafkaCluster: cluster,
+				},
+				kafkaClientProvider: kafkaclient.NewMockProvider(),
+			}
+
+			desiredPod := newPod(testCase.desiredAnnotations)
+			require.NoError(t, r.handleRollingUpgrade(logr.Discard(), desiredPod, currentPod, reflect.TypeOf(desiredPod)))
+
+			pod := &corev1.Pod{}
+			err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(currentPod), pod)
+			if testCase.expectedDeleted {
+				assert.True(t, apierrors.IsNotFound(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "a", pod.GetAnnotations()["team"])
+		})
+	}
+}
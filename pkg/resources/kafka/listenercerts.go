@@ -0,0 +1,130 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+)
+
+const listenerCertificateTimeout = 5 * time.Second
+
+// servedListenerCertificate returns the certificate presented by the listener of the broker at the given address. The
+// certificate is only inspected, so it is not verified.
+var servedListenerCertificate = func(address string) (*x509.Certificate, error) {
+	var served *x509.Certificate
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: listenerCertificateTimeout},
+		Config: &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec
+			// the certificate of the broker is received before the client certificate is requested, so it is known
+			// even if the handshake fails on listeners requiring client authentication
+			VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				if len(rawCerts) == 0 {
+					return errors.New("no certificate is presented")
+				}
+				cert, err := x509.ParseCertificate(rawCerts[0])
+				if err != nil {
+					return err
+				}
+				served = cert
+				return nil
+			},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), listenerCertificateTimeout)
+	defer cancel()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if conn != nil {
+		conn.Close()
+	}
+	if served != nil {
+		return served, nil
+	}
+	if err == nil {
+		err = errors.New("no certificate is presented")
+	}
+	return nil, errors.WrapIfWithDetails(err, "could not get the certificate of the listener", "address", address)
+}
+
+// brokerListenerAddress returns the address of the listener of the broker in the Kubernetes cluster
+func brokerListenerAddress(cluster *v1beta1.KafkaCluster, brokerId int32, port int32) string {
+	host := kafkautils.GetBrokerServiceFqdn(cluster, &v1beta1.Broker{Id: brokerId})
+	if cluster.Spec.HeadlessServiceEnabled {
+		host = fmt.Sprintf("%s.%s.%s", fmt.Sprintf(kafkautils.BrokerHostnameTemplate, cluster.Name, brokerId),
+			fmt.Sprintf(kafkautils.HeadlessServiceTemplate, cluster.Name), kafkautils.GetClusterServiceDomainName(cluster))
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
+
+// renewedListenerStoreConfig returns the keystore and truststore locations of the SSL listeners of the broker which
+// present another certificate than the one in their server certificate Secret. Setting them again through the admin
+// API makes the broker reload the stores holding the renewed certificate.
+func (r *Reconciler) renewedListenerStoreConfig(brokerId int32, log logr.Logger) map[string]string {
+	config := make(map[string]string)
+	for _, listener := range sslListeners(r.KafkaCluster.Spec.ListenersConfig) {
+		renewed, err := r.listenerCertificateRenewed(brokerId, listener)
+		if err != nil {
+			log.V(1).Info("could not check the certificate of the listener", "listener", listener.Name, "error", err.Error())
+			continue
+		}
+		if !renewed {
+			continue
+		}
+		namedKeystorePath := fmt.Sprintf(listenerServerKeyStorePathTemplate, serverKeystorePath, listener.Name)
+		config[fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, listener.Name, kafkautils.KafkaConfigSSLKeyStoreLocation)] =
+			namedKeystorePath + "/" + certutil.KeyStoreFileName(listener.TLSPolicy.GetKeyStoreType())
+		config[fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, listener.Name, kafkautils.KafkaConfigSSLTrustStoreLocation)] =
+			namedKeystorePath + "/" + certutil.TrustStoreFileName(listener.TLSPolicy.GetTrustStoreType())
+	}
+	return config
+}
+
+// listenerCertificateRenewed returns true when the listener of the broker presents another certificate than the one
+// in the keystore of its server certificate Secret
+func (r *Reconciler) listenerCertificateRenewed(brokerId int32, listener v1beta1.CommonListenerSpec) (bool, error) {
+	secretName := pkicommon.ListenerServerCertSecretName(r.KafkaCluster.Name, listener)
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: r.KafkaCluster.Namespace}, secret); err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not get the server certificate of the listener", "secret", secretName)
+	}
+	keyStoreType := listener.TLSPolicy.GetKeyStoreType()
+	keyStore, err := certutil.ParseKeyStoreOfTypeToTLSCertificate(keyStoreType, secret.Data[certutil.KeyStoreFileName(keyStoreType)], secret.Data[v1alpha1.PasswordKey])
+	if err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not parse the keystore of the listener", "secret", secretName)
+	}
+	served, err := servedListenerCertificate(brokerListenerAddress(r.KafkaCluster, brokerId, listener.ContainerPort))
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(keyStore.Leaf.Raw, served.Raw), nil
+}
@@ -0,0 +1,89 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+)
+
+func TestRenewedListenerStoreConfig(t *testing.T) {
+	certPEM, keyPEM, _, err := certutil.GenerateTestCert()
+	require.NoError(t, err)
+	cert, err := certutil.DecodeCertificate(certPEM)
+	require.NoError(t, err)
+	keyStore, password, err := certutil.GenerateJKS([]*x509.Certificate{cert}, keyPEM)
+	require.NoError(t, err)
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			HeadlessServiceEnabled: true,
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{
+						Name: "internal", Type: v1beta1.SecurityProtocolSSL, ContainerPort: 29092,
+						ServerSSLCertSecret: &corev1.LocalObjectReference{Name: "server-cert"},
+					}},
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{
+						Name: "plaintext", Type: v1beta1.SecurityProtocolPlaintext, ContainerPort: 29093,
+					}},
+				},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "server-cert", Namespace: "kafka"},
+		Data:       map[string][]byte{v1alpha1.TLSJKSKeyStore: keyStore, v1alpha1.PasswordKey: password},
+	}
+	r := Reconciler{Reconciler: resources.Reconciler{
+		Client:       fake.NewClientBuilder().WithObjects(secret).Build(),
+		KafkaCluster: cluster,
+	}}
+
+	served := cert
+	var addresses []string
+	defer func(orig func(string) (*x509.Certificate, error)) { servedListenerCertificate = orig }(servedListenerCertificate)
+	servedListenerCertificate = func(address string) (*x509.Certificate, error) {
+		addresses = append(addresses, address)
+		return served, nil
+	}
+
+	// The broker presents the certificate of the keystore
+	assert.Empty(t, r.renewedListenerStoreConfig(0, logr.Discard()))
+	assert.Equal(t, []string{"kafka-0.kafka-headless.kafka.svc.cluster.local:29092"}, addresses)
+
+	// The broker presents another certificate than the renewed one in the keystore
+	renewedPEM, _, _, err := certutil.GenerateTestCert()
+	require.NoError(t, err)
+	served, err = certutil.DecodeCertificate(renewedPEM)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"listener.name.internal.ssl.keystore.location":   "/var/run/secrets/java.io/keystores/server/internal/keystore.jks",
+		"listener.name.internal.ssl.truststore.location": "/var/run/secrets/java.io/keystores/server/internal/truststore.jks",
+	}, r.renewedListenerStoreConfig(0, logr.Discard()))
+}
@@ -0,0 +1,186 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/util"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
+)
+
+const (
+	log4jConfigVolume   = "log4j-config"
+	log4jConfigPath     = "/logging-config"
+	log4jConfigFileName = "log4j.properties"
+
+	log4jRootLoggerKey   = "log4j.rootLogger"
+	log4jLoggerKeyPrefix = "log4j.logger."
+	// rootLoggerName is the name of the root logger in the broker logger configs of Kafka
+	rootLoggerName = "root"
+)
+
+func generateLog4jConfigVolume(selector *corev1.ConfigMapKeySelector) corev1.Volume {
+	return corev1.Volume{
+		Name: log4jConfigVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: selector.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: selector.Key, Path: log4jConfigFileName}},
+				DefaultMode:          util.Int32Pointer(0644),
+				Optional:             selector.Optional,
+			},
+		},
+	}
+}
+
+func generateLog4jConfigVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      log4jConfigVolume,
+		MountPath: log4jConfigPath,
+	}
+}
+
+// log4jLoggerLevels returns the levels of the root logger and the named loggers set in the log4j configuration.
+// Loggers without a level inherit the level of their parent, they are left out.
+func log4jLoggerLevels(log4jConfig string) (map[string]string, error) {
+	config, err := properties.NewFromString(log4jConfig)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not parse the log4j configuration")
+	}
+	levels := make(map[string]string)
+	for _, key := range config.Keys() {
+		var logger string
+		switch {
+		case key == log4jRootLoggerKey:
+			logger = rootLoggerName
+		case strings.HasPrefix(key, log4jLoggerKeyPrefix):
+			logger = strings.TrimPrefix(key, log4jLoggerKeyPrefix)
+		default:
+			continue
+		}
+		property, _ := config.Get(key)
+		// The level is followed by the names of the appenders
+		level := strings.TrimSpace(strings.SplitN(property.Value(), ",", 2)[0])
+		if level != "" {
+			levels[logger] = strings.ToUpper(level)
+		}
+	}
+	return levels, nil
+}
+
+// loggerLevelChanges returns the levels which differ from the current levels of the broker. Loggers which do not
+// exist in the broker are not created, Kafka refuses to set the level of an unknown logger.
+func loggerLevelChanges(current, desired map[string]string) map[string]string {
+	changes := make(map[string]string)
+	for logger, level := range desired {
+		if currentLevel, ok := current[logger]; ok && currentLevel != level {
+			changes[logger] = level
+		}
+	}
+	return changes
+}
+
+// brokerLoggerClient connects to the Kafka cluster at most once per reconcile for setting the logger levels of
+// all the brokers
+type brokerLoggerClient struct {
+	reconciler *Reconciler
+	client     kafkaclient.KafkaClient
+	close      func()
+}
+
+func (c *brokerLoggerClient) get() (kafkaclient.KafkaClient, error) {
+	if c.client != nil {
+		return c.client, nil
+	}
+	kClient, close, err := c.reconciler.kafkaClientProvider.NewFromCluster(c.reconciler.Client, c.reconciler.KafkaCluster)
+	if err != nil {
+		return nil, errorfactory.New(errorfactory.BrokersUnreachable{}, err, "could not connect to kafka brokers")
+	}
+	c.client, c.close = kClient, close
+	return c.client, nil
+}
+
+// Close closes the connection to the Kafka cluster if it has been opened
+func (c *brokerLoggerClient) Close() {
+	if c.close != nil {
+		c.close()
+	}
+	c.client, c.close = nil, nil
+}
+
+func log4jConfigHash(log4jConfig string) v1beta1.Log4jConfigHash {
+	sum := sha256.Sum256([]byte(log4jConfig))
+	return v1beta1.Log4jConfigHash(hex.EncodeToString(sum[:]))
+}
+
+// reconcileBrokerLoggers applies the logger levels of the log4j configuration of the broker to the running broker,
+// so that changing the logging configuration does not need a rolling restart. The levels are only set when the
+// configuration differs from the one recorded in the status of the broker.
+func (r *Reconciler) reconcileBrokerLoggers(ctx context.Context, loggerClient *brokerLoggerClient, brokerID int32, brokerConfig *v1beta1.BrokerConfig, log logr.Logger) error {
+	log4jConfig := brokerConfig.Log4jConfig
+	if brokerConfig.Log4jConfigMap != nil {
+		value, found, err := k8sutil.LookupConfigMapKey(ctx, r.Client, r.KafkaCluster.GetNamespace(), brokerConfig.Log4jConfigMap)
+		if err != nil {
+			return errors.WrapIf(err, "could not get the log4j configuration of the broker")
+		}
+		if !found {
+			return nil
+		}
+		log4jConfig = value
+	}
+	if log4jConfig == "" {
+		return nil
+	}
+
+	configHash := log4jConfigHash(log4jConfig)
+	brokerIDStr := strconv.Itoa(int(brokerID))
+	if r.KafkaCluster.Status.BrokersState[brokerIDStr].Log4jConfigHash == configHash {
+		return nil
+	}
+
+	desired, err := log4jLoggerLevels(log4jConfig)
+	if err != nil {
+		return err
+	}
+
+	kClient, err := loggerClient.get()
+	if err != nil {
+		return err
+	}
+	current, err := kClient.DescribeBrokerLoggers(brokerID)
+	if err != nil {
+		return err
+	}
+	if changes := loggerLevelChanges(current, desired); len(changes) > 0 {
+		log.Info("setting logger levels of the broker", v1beta1.BrokerIdLabelKey, brokerID, "levels", changes)
+		if err = kClient.AlterBrokerLoggers(brokerID, changes); err != nil {
+			return err
+		}
+	}
+	return k8sutil.UpdateBrokerStatus(r.Client, []string{brokerIDStr}, r.KafkaCluster, configHash, log)
+}
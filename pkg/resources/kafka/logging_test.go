@@ -0,0 +1,163 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+type loggersTestKafkaClient struct {
+	kafkaclient.KafkaClient
+	levels    map[int32]map[string]string
+	describes int
+}
+
+func (c *loggersTestKafkaClient) DescribeBrokerLoggers(brokerID int32) (map[string]string, error) {
+	c.describes++
+	return c.levels[brokerID], nil
+}
+
+func (c *loggersTestKafkaClient) AlterBrokerLoggers(brokerID int32, levels map[string]string) error {
+	for logger, level := range levels {
+		c.levels[brokerID][logger] = level
+	}
+	return nil
+}
+
+type loggersTestProvider struct {
+	kClient     *loggersTestKafkaClient
+	connections int
+}
+
+func (p *loggersTestProvider) NewFromCluster(client.Client, *v1beta1.KafkaCluster) (kafkaclient.KafkaClient, func(), error) {
+	p.connections++
+	return p.kClient, func() {}, nil
+}
+
+func TestLog4jLoggerLevels(t *testing.T) {
+	levels, err := log4jLoggerLevels(`log4j.rootLogger=info, stdout, kafkaAppender
+log4j.appender.stdout=org.apache.log4j.ConsoleAppender
+log4j.logger.kafka.controller=TRACE, controllerAppender
+log4j.logger.kafka.request.logger=WARN, requestAppender
+log4j.logger.kafka.network.Processor=, requestAppender
+`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"root":                 "INFO",
+		"kafka.controller":     "TRACE",
+		"kafka.request.logger": "WARN",
+	}, levels)
+}
+
+func TestLoggerLevelChanges(t *testing.T) {
+	current := map[string]string{"root": "INFO", "kafka.controller": "INFO", "kafka.request.logger": "WARN"}
+	desired := map[string]string{"root": "INFO", "kafka.controller": "TRACE", "kafka.request.logger": "WARN", "unknown": "DEBUG"}
+	assert.Equal(t, map[string]string{"kafka.controller": "TRACE"}, loggerLevelChanges(current, desired))
+}
+
+func TestReconcileBrokerLoggers(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+	}
+	provider := &loggersTestProvider{kClient: &loggersTestKafkaClient{levels: map[int32]map[string]string{
+		0: {"root": "INFO", "kafka.controller": "INFO"},
+		1: {"root": "INFO", "kafka.controller": "INFO"},
+	}}}
+	r := Reconciler{
+		Reconciler: resources.Reconciler{
+			Client:       fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build(),
+			KafkaCluster: cluster,
+		},
+		kafkaClientProvider: provider,
+	}
+	brokerConfig := &v1beta1.BrokerConfig{Log4jConfig: "log4j.logger.kafka.controller=TRACE, controllerAppender"}
+
+	loggerClient := &brokerLoggerClient{reconciler: &r}
+	for _, brokerID := range []int32{0, 1} {
+		require.NoError(t, r.reconcileBrokerLoggers(ctx, loggerClient, brokerID, brokerConfig, logr.Discard()))
+	}
+	loggerClient.Close()
+	// the brokers share a single connection
+	assert.Equal(t, 1, provider.connections)
+	assert.Equal(t, 2, provider.kClient.describes)
+	assert.Equal(t, "TRACE", provider.kClient.levels[0]["kafka.controller"])
+	assert.Equal(t, "TRACE", provider.kClient.levels[1]["kafka.controller"])
+	assert.Equal(t, log4jConfigHash(brokerConfig.Log4jConfig), cluster.Status.BrokersState["0"].Log4jConfigHash)
+
+	// the unchanged configuration is not applied again
+	loggerClient = &brokerLoggerClient{reconciler: &r}
+	require.NoError(t, r.reconcileBrokerLoggers(ctx, loggerClient, 0, brokerConfig, logr.Discard()))
+	assert.Equal(t, 1, provider.connections)
+	assert.Equal(t, 2, provider.kClient.describes)
+
+	// the changed configuration is applied
+	brokerConfig.Log4jConfig = "log4j.logger.kafka.controller=DEBUG, controllerAppender"
+	require.NoError(t, r.reconcileBrokerLoggers(ctx, loggerClient, 0, brokerConfig, logr.Discard()))
+	assert.Equal(t, 2, provider.connections)
+	assert.Equal(t, "DEBUG", provider.kClient.levels[0]["kafka.controller"])
+}
+
+func TestPodWithLog4jConfigMap(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+	}
+	r := Reconciler{Reconciler: resources.Reconciler{KafkaCluster: cluster}}
+	brokerConfig := &v1beta1.BrokerConfig{
+		Log4jConfig: "log4j.rootLogger=INFO, stdout",
+		Log4jConfigMap: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "broker-logging"},
+			Key:                  "broker-log4j.properties",
+		},
+	}
+
+	pod := r.pod(0, brokerConfig, nil, logr.Discard()).(*corev1.Pod)
+
+	var volume *corev1.Volume
+	for i := range pod.Spec.Volumes {
+		if pod.Spec.Volumes[i].Name == log4jConfigVolume {
+			volume = &pod.Spec.Volumes[i]
+		}
+	}
+	require.NotNil(t, volume)
+	assert.Equal(t, "broker-logging", volume.ConfigMap.Name)
+	assert.Equal(t, []corev1.KeyToPath{{Key: "broker-log4j.properties", Path: log4jConfigFileName}}, volume.ConfigMap.Items)
+
+	kafkaContainer := pod.Spec.Containers[0]
+	assert.Contains(t, kafkaContainer.VolumeMounts, corev1.VolumeMount{Name: log4jConfigVolume, MountPath: log4jConfigPath})
+	assert.Contains(t, kafkaContainer.Env, corev1.EnvVar{
+		Name:  "KAFKA_LOG4J_OPTS",
+		Value: "-Dlog4j.configuration=file:/logging-config/log4j.properties",
+	})
+	// The volumes of the broker config are not modified
+	assert.Empty(t, brokerConfig.Volumes)
+}
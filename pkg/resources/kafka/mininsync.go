@@ -0,0 +1,95 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	kafkautil "github.com/banzaicloud/koperator/pkg/util/kafka"
+)
+
+// checkMinInSyncReplicas returns an error when restarting the broker of the pod would push partitions below their
+// min.insync.replicas, unless the check is skipped by the annotation of the KafkaCluster. The outcome is published
+// in the MinInSyncReplicasPreserved condition of the KafkaCluster.
+func (r *Reconciler) checkMinInSyncReplicas(ctx context.Context, log logr.Logger, kClient kafkaclient.KafkaClient, currentPod *corev1.Pod) error {
+	if r.KafkaCluster.GetAnnotations()[v1beta1.SkipMinInSyncReplicasCheckAnnotationKey] == "true" {
+		log.Info("min.insync.replicas check of the broker restart is skipped by annotation",
+			v1beta1.BrokerIdLabelKey, currentPod.Labels[v1beta1.BrokerIdLabelKey])
+		return nil
+	}
+	brokerID, err := strconv.ParseInt(currentPod.Labels[v1beta1.BrokerIdLabelKey], 10, 32)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not parse the broker ID of the pod", "pod", currentPod.GetName())
+	}
+	partitions, err := kClient.PartitionReplicas()
+	if err != nil {
+		return errors.WrapIf(err, "could not get the in-sync replicas of the partitions")
+	}
+	violations := kafkaclient.MinInSyncReplicasViolations(partitions, []int32{int32(brokerID)}, kafkautil.MinInSyncReplicas(r.KafkaCluster))
+
+	condition := minInSyncReplicasCondition(r.KafkaCluster, int32(brokerID), violations)
+	if current := meta.FindStatusCondition(r.KafkaCluster.Status.Conditions, condition.Type); current == nil ||
+		current.Status != condition.Status || current.Reason != condition.Reason || current.Message != condition.Message {
+		err := k8sutil.PatchKafkaClusterStatus(ctx, r.Client, r.KafkaCluster, func(status *v1beta1.KafkaClusterStatus) {
+			meta.SetStatusCondition(&status.Conditions, condition)
+		})
+		if err != nil {
+			return errors.WrapIf(err, "could not update the MinInSyncReplicasPreserved condition of the KafkaCluster")
+		}
+	}
+
+	if len(violations) > 0 {
+		log.Info("broker restart is blocked by min.insync.replicas", v1beta1.BrokerIdLabelKey, brokerID,
+			"partitions", len(violations))
+		return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New(condition.Message), "rolling upgrade in progress")
+	}
+	return nil
+}
+
+// minInSyncReplicasCondition returns the MinInSyncReplicasPreserved condition computed from the partitions which
+// would drop below their min.insync.replicas when the broker is restarted
+func minInSyncReplicasCondition(cluster *v1beta1.KafkaCluster, brokerID int32, violations []kafkaclient.MinInSyncReplicasViolation) metav1.Condition {
+	if len(violations) == 0 {
+		return metav1.Condition{
+			Type:    v1beta1.MinInSyncReplicasPreservedCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  v1beta1.BrokerRestartAllowedReason,
+			Message: "restarting the brokers keeps the partitions at or above their min.insync.replicas",
+		}
+	}
+	first := violations[0]
+	return metav1.Condition{
+		Type:   v1beta1.MinInSyncReplicasPreservedCondition,
+		Status: metav1.ConditionFalse,
+		Reason: v1beta1.BrokerRestartBlockedReason,
+		Message: fmt.Sprintf("restarting broker %d would push %d partitions below their min.insync.replicas, e.g. %s-%d "+
+			"would have %d in-sync replicas instead of %d; set the %s annotation to \"true\" to restart it anyway",
+			brokerID, len(violations), first.Topic, first.Partition, first.InSyncReplicas, first.MinInSyncReplicas,
+			v1beta1.SkipMinInSyncReplicasCheckAnnotationKey),
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+type minInSyncReplicasTestKafkaClient struct {
+	kafkaclient.KafkaClient
+	partitions []kafkaclient.PartitionReplicas
+}
+
+func (c *minInSyncReplicasTestKafkaClient) PartitionReplicas() ([]kafkaclient.PartitionReplicas, error) {
+	return c.partitions, nil
+}
+
+func TestCheckMinInSyncReplicas(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec:       v1beta1.KafkaClusterSpec{ReadOnlyConfig: "min.insync.replicas=2"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	r := Reconciler{Reconciler: resources.Reconciler{Client: c, KafkaCluster: cluster}}
+	kClient := &minInSyncReplicasTestKafkaClient{partitions: []kafkaclient.PartitionReplicas{
+		{Topic: "orders", Partition: 0, Replicas: []int32{0, 1, 2}, Isr: []int32{0, 1, 2}},
+		{Topic: "payments", Partition: 0, Replicas: []int32{1, 2}, Isr: []int32{1, 2}},
+	}}
+	pod := func(brokerID string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "kafka-" + brokerID, Labels: map[string]string{v1beta1.BrokerIdLabelKey: brokerID}}}
+	}
+
+	require.NoError(t, r.checkMinInSyncReplicas(ctx, logr.Discard(), kClient, pod("0")))
+	condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MinInSyncReplicasPreservedCondition)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+
+	// the replicas of payments-0 are all needed to satisfy min.insync.replicas
+	err := r.checkMinInSyncReplicas(ctx, logr.Discard(), kClient, pod("1"))
+	require.Error(t, err)
+	assert.True(t, errors.As(err, &errorfactory.ReconcileRollingUpgrade{}))
+	condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MinInSyncReplicasPreservedCondition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, v1beta1.BrokerRestartBlockedReason, condition.Reason)
+	assert.Contains(t, condition.Message, "payments-0")
+
+	// the check can be skipped in emergencies
+	cluster.SetAnnotations(map[string]string{v1beta1.SkipMinInSyncReplicasCheckAnnotationKey: "true"})
+	require.NoError(t, r.checkMinInSyncReplicas(ctx, logr.Discard(), kClient, pod("1")))
+}
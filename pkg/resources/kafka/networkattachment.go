@@ -0,0 +1,111 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+const (
+	// multusNetworksAnnotation is the annotation of the pods requesting Multus to attach them to secondary networks
+	multusNetworksAnnotation = "k8s.v1.cni.cncf.io/networks"
+	networkAttachmentVolume  = "network-attachments"
+	// networkAttachmentPath is where the render-network-attachment-config.sh script writes the broker config holding
+	// the addresses of the broker pod on the secondary networks
+	networkAttachmentPath = "/var/run/network-attachments"
+	// networkAttachmentAddressPlaceholder is replaced by the address of the broker pod on the secondary network of
+	// the given interface when the broker is started
+	networkAttachmentAddressPlaceholder = "{{network-attachment-address:%s}}"
+	networkAttachmentInterfacesEnvVar   = "KAFKA_NETWORK_ATTACHMENT_INTERFACES"
+)
+
+var (
+	//go:embed render-network-attachment-config.sh
+	networkAttachmentConfigScript string
+)
+
+// networkAttachmentAddress returns the placeholder of the address of the broker pod on the secondary network of the listener
+func networkAttachmentAddress(attachment *v1beta1.ListenerNetworkAttachment) string {
+	return fmt.Sprintf(networkAttachmentAddressPlaceholder, attachment.Interface)
+}
+
+// hasNetworkAttachmentAddress returns true when the config value holds the placeholder of an address on a secondary
+// network which is only resolved when the broker is started
+func hasNetworkAttachmentAddress(value string) bool {
+	placeholderPrefix, _, _ := strings.Cut(networkAttachmentAddressPlaceholder, "%s")
+	return strings.Contains(value, placeholderPrefix)
+}
+
+// networkAttachmentListenerAddresses returns the "host:port" addresses of the listeners bound to a secondary network
+// keyed by the upper case name of the listeners
+func networkAttachmentListenerAddresses(l v1beta1.ListenersConfig) map[string]string {
+	addresses := make(map[string]string)
+	for _, iListener := range l.InternalListeners {
+		if iListener.NetworkAttachment != nil {
+			addresses[strings.ToUpper(iListener.Name)] = fmt.Sprintf("%s:%d", networkAttachmentAddress(iListener.NetworkAttachment), iListener.ContainerPort)
+		}
+	}
+	return addresses
+}
+
+// addNetworkAttachmentAnnotation requests Multus to attach the broker pod to the secondary networks of the listeners,
+// the networks already requested in the broker annotations are kept
+func addNetworkAttachmentAnnotation(annotations map[string]string, listeners []v1beta1.InternalListenerConfig) map[string]string {
+	if len(listeners) == 0 {
+		return annotations
+	}
+	networks := make([]string, 0, len(listeners)+1)
+	if current := strings.TrimSpace(annotations[multusNetworksAnnotation]); current != "" {
+		networks = append(networks, current)
+	}
+	for _, listener := range listeners {
+		networks = append(networks, fmt.Sprintf("%s@%s", listener.NetworkAttachment.Name, listener.NetworkAttachment.Interface))
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[multusNetworksAnnotation] = strings.Join(networks, ",")
+	return annotations
+}
+
+func networkAttachmentInterfacesEnv(listeners []v1beta1.InternalListenerConfig) corev1.EnvVar {
+	interfaces := make([]string, 0, len(listeners))
+	for _, listener := range listeners {
+		interfaces = append(interfaces, listener.NetworkAttachment.Interface)
+	}
+	return corev1.EnvVar{Name: networkAttachmentInterfacesEnvVar, Value: strings.Join(interfaces, " ")}
+}
+
+func generateNetworkAttachmentVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: networkAttachmentVolume,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+}
+
+func generateNetworkAttachmentVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      networkAttachmentVolume,
+		MountPath: networkAttachmentPath,
+	}
+}
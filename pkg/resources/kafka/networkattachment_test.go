@@ -0,0 +1,83 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func networkAttachedCluster() *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolPlaintext, ContainerPort: 29092},
+						UsedForInnerBrokerCommunication: true,
+					},
+					{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "replication", Type: v1beta1.SecurityProtocolPlaintext, ContainerPort: 29093},
+						UsedForReplication: true,
+						NetworkAttachment:  &v1beta1.ListenerNetworkAttachment{Name: "kafka/replication-net", Interface: "net1"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPodWithNetworkAttachedListener(t *testing.T) {
+	r := Reconciler{Reconciler: resources.Reconciler{KafkaCluster: networkAttachedCluster()}}
+
+	pod := r.pod(0, &v1beta1.BrokerConfig{BrokerAnnotations: map[string]string{multusNetworksAnnotation: "kafka/monitoring-net@net0"}},
+		nil, logr.Discard()).(*corev1.Pod)
+
+	assert.Equal(t, "kafka/monitoring-net@net0,kafka/replication-net@net1", pod.GetAnnotations()[multusNetworksAnnotation])
+	assert.Equal(t, []string{"bash", "-c", networkAttachmentConfigScript + envoySidecarScript}, pod.Spec.Containers[0].Command)
+	assert.Contains(t, pod.Spec.Containers[0].Env, corev1.EnvVar{Name: networkAttachmentInterfacesEnvVar, Value: "net1"})
+	assert.Contains(t, pod.Spec.Containers[0].VolumeMounts, generateNetworkAttachmentVolumeMount())
+	assert.Contains(t, pod.Spec.Volumes, generateNetworkAttachmentVolume())
+}
+
+func TestNetworkAttachedListenerConfig(t *testing.T) {
+	cluster := networkAttachedCluster()
+	statuses := map[string]v1beta1.ListenerStatusList{
+		"internal":    {{Name: "broker-0", Address: "kafka-0.kafka.svc.cluster.local:29092"}},
+		"replication": {{Name: "broker-0", Address: "kafka-0.kafka.svc.cluster.local:29093"}},
+	}
+
+	config := generateListenerSpecificConfig(&cluster.Spec.ListenersConfig, nil, logr.Discard())
+	listeners, _ := config.Get("listeners")
+	assert.Equal(t, "INTERNAL://:29092,REPLICATION://{{network-attachment-address:net1}}:29093", listeners.Value())
+	interBrokerListenerName, _ := config.Get("inter.broker.listener.name")
+	assert.Equal(t, "REPLICATION", interBrokerListenerName.Value())
+
+	assert.Equal(t, []string{"INTERNAL://kafka-0.kafka.svc.cluster.local:29092", "REPLICATION://{{network-attachment-address:net1}}:29093"},
+		generateAdvertisedListenerConfig(0, cluster.Spec.ListenersConfig, nil, statuses, nil))
+}
+
+func TestHasNetworkAttachmentAddress(t *testing.T) {
+	assert.True(t, hasNetworkAttachmentAddress("INTERNAL://:29092,REPLICATION://{{network-attachment-address:net1}}:29093"))
+	assert.False(t, hasNetworkAttachmentAddress("INTERNAL://:29092,REPLICATION://:29093"))
+}
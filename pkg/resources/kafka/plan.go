@@ -0,0 +1,274 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+const (
+	plannedActionCreate = "create"
+	plannedActionUpdate = "update"
+)
+
+// Plan computes the changes the reconciler would apply to the brokers of the Kafka cluster without applying them.
+// The resources are rendered the same way as during the reconciliation and compared with the existing ones.
+func (r *Reconciler) Plan(ctx context.Context, log logr.Logger) (*v1beta1.KafkaClusterChangePlan, error) {
+	log = log.WithValues("component", componentName, "clusterName", r.KafkaCluster.Name, "clusterNamespace", r.KafkaCluster.Namespace)
+
+	plan := &v1beta1.KafkaClusterChangePlan{
+		ObservedGeneration: r.KafkaCluster.GetGeneration(),
+		ComputedAt:         metav1.NewTime(time.Now()),
+	}
+
+	var services []runtime.Object
+	if r.KafkaCluster.Spec.HeadlessServiceEnabled {
+		services = append(services, r.headlessService())
+	} else {
+		services = append(services, r.allBrokerService())
+	}
+	if r.KafkaCluster.Spec.DisruptionBudget.Create {
+		o, err := r.podDisruptionBudget(log)
+		if err != nil {
+			return plan, errors.WrapIf(err, "failed to compute podDisruptionBudget")
+		}
+		services = append(services, o)
+	}
+	for _, o := range services {
+		if err := r.planResource(ctx, log, plan, o.(client.Object)); err != nil {
+			return plan, err
+		}
+	}
+
+	extListenerStatuses, err := r.createExternalListenerStatuses(log)
+	if err != nil {
+		return plan, errors.WrapIf(err, "could not compute the statuses of the external listeners")
+	}
+	intListenerStatuses, controllerIntListenerStatuses := k8sutil.CreateInternalListenerStatuses(r.KafkaCluster)
+	clientPass, serverPasses, superUsers, err := r.getPasswordKeysAndSuperUsers()
+	if err != nil {
+		return plan, err
+	}
+
+	var brokerPods corev1.PodList
+	err = r.Client.List(ctx, &brokerPods, client.InNamespace(r.KafkaCluster.Namespace),
+		client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.Name)))
+	if err != nil {
+		return plan, errors.WrapIf(err, "failed to list broker pods that belong to Kafka cluster")
+	}
+	currentPods := make(map[string]*corev1.Pod, len(brokerPods.Items))
+	for i := range brokerPods.Items {
+		currentPods[brokerPods.Items[i].Labels[v1beta1.BrokerIdLabelKey]] = &brokerPods.Items[i]
+	}
+
+	var brokersWithNewVolumes []int32
+	brokers := r.unparkedBrokers()
+	specBrokerIDs := make(map[string]struct{}, len(brokers))
+	for _, broker := range brokers {
+		brokerID := strconv.Itoa(int(broker.Id))
+		specBrokerIDs[brokerID] = struct{}{}
+		brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec)
+		if err != nil {
+			return plan, errors.WrapIf(err, "failed to compute broker configuration")
+		}
+
+		newVolumes, err := r.planBrokerVolumes(ctx, plan, broker.Id, brokerConfig)
+		if err != nil {
+			return plan, err
+		}
+
+		var configMap *corev1.ConfigMap
+		// The configuration of the broker is not rendered while its rack awareness state is unknown
+		if brokerState, ok := r.KafkaCluster.Status.BrokersState[brokerID]; r.KafkaCluster.Spec.RackAwareness == nil || ok && brokerState.RackAwarenessState != "" {
+			configMap = r.configMap(broker.Id, brokerConfig, extListenerStatuses, intListenerStatuses, controllerIntListenerStatuses, serverPasses, clientPass, superUsers, log)
+			if err := r.planResource(ctx, log, plan, configMap); err != nil {
+				return plan, err
+			}
+		}
+		if !r.KafkaCluster.Spec.HeadlessServiceEnabled {
+			if err := r.planResource(ctx, log, plan, r.service(broker.Id, brokerConfig).(client.Object)); err != nil {
+				return plan, err
+			}
+		}
+
+		pvcs, err := getCreatedPvcForBroker(ctx, r.Client, broker.Id, brokerConfig.StorageConfigs, r.KafkaCluster.Namespace, r.KafkaCluster.Name)
+		if err != nil {
+			return plan, errors.WrapIfWithDetails(err, "failed to list PVC's")
+		}
+		desiredPod := r.pod(broker.Id, brokerConfig, pvcs, log).(*corev1.Pod)
+		currentPod, ok := currentPods[brokerID]
+		if !ok {
+			plan.BrokersToAdd = append(plan.BrokersToAdd, broker.Id)
+			plan.Resources = append(plan.Resources, v1beta1.PlannedResourceChange{
+				Kind: "Pod", Name: desiredPod.GetGenerateName(), Action: plannedActionCreate,
+			})
+			// Only the brokers which have never been part of the cluster are added by Cruise Control
+			if _, known := r.KafkaCluster.Status.BrokersState[brokerID]; !known &&
+				r.KafkaCluster.Status.CruiseControlTopicStatus == v1beta1.CruiseControlTopicReady {
+				addPlannedCCOperation(plan, v1alpha1.OperationAddBroker, broker.Id)
+			}
+			continue
+		}
+
+		if newVolumes {
+			brokersWithNewVolumes = append(brokersWithNewVolumes, broker.Id)
+		}
+		if configMap != nil {
+			configHash, err := r.brokerConfigHash(ctx, configMap, desiredPod)
+			if err != nil {
+				return plan, errors.WrapIfWithDetails(err, "failed to compute the configuration hash of the broker", v1beta1.BrokerIdLabelKey, broker.Id)
+			}
+			desiredPod.Annotations[v1beta1.ConfigHashAnnotationKey] = configHash
+		}
+		prepareDesiredPod(desiredPod, currentPod)
+		// The new volumes can only be mounted by recreating the pod
+		podUpdated := k8sutil.CheckIfObjectUpdated(log, reflect.TypeOf(desiredPod), currentPod, desiredPod)
+		switch {
+		case newVolumes:
+			plan.BrokersToRestart = append(plan.BrokersToRestart, broker.Id)
+		case podUpdated && configHashUnchanged(desiredPod, currentPod) && onlyPodMetadataChanged(log, desiredPod, currentPod):
+			// the labels and annotations of the pod are updated without restarting the broker
+			plan.Resources = append(plan.Resources, v1beta1.PlannedResourceChange{
+				Kind: "Pod", Name: currentPod.GetName(), Action: plannedActionUpdate,
+			})
+		case podUpdated:
+			plan.BrokersToRestart = append(plan.BrokersToRestart, broker.Id)
+		}
+	}
+
+	for brokerID := range currentPods {
+		if _, ok := specBrokerIDs[brokerID]; ok {
+			continue
+		}
+		id, err := strconv.ParseInt(brokerID, 10, 32)
+		if err != nil {
+			continue
+		}
+		plan.BrokersToRemove = append(plan.BrokersToRemove, int32(id))
+		ccState := r.KafkaCluster.Status.BrokersState[brokerID].GracefulActionState.CruiseControlState
+		if ccState == v1beta1.GracefulUpscaleSucceeded || ccState == v1beta1.GracefulUpscaleRequired {
+			addPlannedCCOperation(plan, v1alpha1.OperationRemoveBroker, int32(id))
+		}
+	}
+	sort.Slice(plan.BrokersToRemove, func(i, j int) bool { return plan.BrokersToRemove[i] < plan.BrokersToRemove[j] })
+
+	// The new volumes of the brokers are filled by an intra-broker disk rebalance
+	if len(brokersWithNewVolumes) > 0 && r.KafkaCluster.Status.CruiseControlTopicStatus == v1beta1.CruiseControlTopicReady {
+		addPlannedCCOperation(plan, v1alpha1.OperationRebalance, brokersWithNewVolumes...)
+	}
+	for i := range plan.CruiseControlOperations {
+		brokerIDs := plan.CruiseControlOperations[i].BrokerIDs
+		sort.Slice(brokerIDs, func(i, j int) bool { return brokerIDs[i] < brokerIDs[j] })
+	}
+	return plan, nil
+}
+
+// planResource adds the resource to the plan when it does not exist or differs from the desired state
+func (r *Reconciler) planResource(ctx context.Context, log logr.Logger, plan *v1beta1.KafkaClusterChangePlan, desired client.Object) error {
+	current := desired.DeepCopyObject().(client.Object)
+	desiredType := reflect.TypeOf(desired)
+	err := r.Client.Get(ctx, client.ObjectKeyFromObject(desired), current)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.WrapIfWithDetails(err, "getting resource failed", "kind", desiredType, "name", desired.GetName())
+	}
+	action := ""
+	switch {
+	case apierrors.IsNotFound(err):
+		action = plannedActionCreate
+	case k8sutil.CheckIfObjectUpdated(log, desiredType, current, desired):
+		action = plannedActionUpdate
+	default:
+		return nil
+	}
+	plan.Resources = append(plan.Resources, v1beta1.PlannedResourceChange{
+		Kind:   desiredType.Elem().Name(),
+		Name:   desired.GetName(),
+		Action: action,
+	})
+	return nil
+}
+
+// planBrokerVolumes adds the persistent volume claims of the broker to the plan which do not exist or would be
+// resized, it returns true when the broker gets new volumes
+func (r *Reconciler) planBrokerVolumes(ctx context.Context, plan *v1beta1.KafkaClusterChangePlan, brokerID int32, brokerConfig *v1beta1.BrokerConfig) (bool, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	err := r.Client.List(ctx, pvcList, client.InNamespace(r.KafkaCluster.GetNamespace()),
+		client.MatchingLabels(apiutil.MergeLabels(
+			apiutil.LabelsForKafka(r.KafkaCluster.Name),
+			map[string]string{v1beta1.BrokerIdLabelKey: strconv.Itoa(int(brokerID))},
+		)))
+	if err != nil {
+		return false, errors.WrapIf(err, "failed to list broker pvcs that belong to Kafka cluster")
+	}
+	currentPvcs := make(map[string]*corev1.PersistentVolumeClaim, len(pvcList.Items))
+	for i := range pvcList.Items {
+		currentPvcs[pvcList.Items[i].Annotations["mountPath"]] = &pvcList.Items[i]
+	}
+
+	newVolumes := false
+	for index, storage := range brokerConfig.StorageConfigs {
+		if storage.PvcSpec == nil {
+			continue
+		}
+		desiredPvc, err := r.pvc(brokerID, index, storage)
+		if err != nil {
+			return false, errors.WrapIfWithDetails(err, "failed to generate resource", "resources", "PersistentVolumeClaim")
+		}
+		currentPvc, ok := currentPvcs[desiredPvc.Annotations["mountPath"]]
+		if !ok {
+			newVolumes = len(currentPvcs) > 0
+			plan.Resources = append(plan.Resources, v1beta1.PlannedResourceChange{
+				Kind: "PersistentVolumeClaim", Name: desiredPvc.GetGenerateName(), Action: plannedActionCreate,
+			})
+			continue
+		}
+		if !currentPvc.Spec.Resources.Requests.Storage().Equal(*desiredPvc.Spec.Resources.Requests.Storage()) {
+			plan.Resources = append(plan.Resources, v1beta1.PlannedResourceChange{
+				Kind: "PersistentVolumeClaim", Name: currentPvc.GetName(), Action: plannedActionUpdate,
+			})
+		}
+	}
+	return newVolumes, nil
+}
+
+func addPlannedCCOperation(plan *v1beta1.KafkaClusterChangePlan, operation v1alpha1.CruiseControlTaskOperation, brokerIDs ...int32) {
+	for i := range plan.CruiseControlOperations {
+		if plan.CruiseControlOperations[i].Operation == string(operation) {
+			plan.CruiseControlOperations[i].BrokerIDs = append(plan.CruiseControlOperations[i].BrokerIDs, brokerIDs...)
+			return
+		}
+	}
+	plan.CruiseControlOperations = append(plan.CruiseControlOperations, v1beta1.PlannedCruiseControlOperation{
+		Operation: string(operation),
+		BrokerIDs: brokerIDs,
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestPlan(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", UID: "kafka-uid", Generation: 3},
+		Spec: v1beta1.KafkaClusterSpec{
+			HeadlessServiceEnabled: true,
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{{
+					CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolPlaintext, ContainerPort: 29092},
+					UsedForInnerBrokerCommunication: true,
+				}},
+			},
+			Brokers: []v1beta1.Broker{{Id: 0, BrokerConfig: &v1beta1.BrokerConfig{}}, {Id: 1, BrokerConfig: &v1beta1.BrokerConfig{}}},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			CruiseControlTopicStatus: v1beta1.CruiseControlTopicReady,
+			BrokersState: map[string]v1beta1.BrokerState{
+				"0": {GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleSucceeded}},
+				"2": {GracefulActionState: v1beta1.GracefulActionState{CruiseControlState: v1beta1.GracefulUpscaleSucceeded}},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := Reconciler{Reconciler: resources.Reconciler{Client: c, KafkaCluster: cluster}}
+
+	// the resources of broker 0 are in sync with the spec
+	intListenerStatuses, controllerIntListenerStatuses := k8sutil.CreateInternalListenerStatuses(cluster)
+	brokerConfig, err := cluster.Spec.Brokers[0].GetBrokerConfig(cluster.Spec)
+	require.NoError(t, err)
+	configMap := r.configMap(0, brokerConfig, nil, intListenerStatuses, controllerIntListenerStatuses, nil, "", nil, logr.Discard())
+	pod := r.pod(0, brokerConfig, nil, logr.Discard()).(*corev1.Pod)
+	pod.Annotations[v1beta1.ConfigHashAnnotationKey], err = r.brokerConfigHash(ctx, configMap, pod)
+	require.NoError(t, err)
+	objects := []client.Object{configMap, pod, r.headlessService().(client.Object)}
+	for _, o := range objects {
+		require.NoError(t, patch.DefaultAnnotator.SetLastAppliedAnnotation(o))
+	}
+	// the name of the pod is generated by the API server after the last applied annotation has been set
+	pod.Name = "kafka-0-abcde"
+	for _, o := range objects {
+		require.NoError(t, c.Create(ctx, o))
+	}
+	// broker 2 has been removed from the spec
+	removedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "kafka-2-fghij",
+		Namespace: "kafka",
+		Labels:    apiutil.MergeLabels(apiutil.LabelsForKafka("kafka"), map[string]string{v1beta1.BrokerIdLabelKey: "2"}),
+	}}
+	require.NoError(t, c.Create(ctx, removedPod))
+
+	plan, err := r.Plan(ctx, logr.Discard())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), plan.ObservedGeneration)
+	assert.Empty(t, plan.BrokersToRestart)
+	assert.Equal(t, []int32{1}, plan.BrokersToAdd)
+	assert.Equal(t, []int32{2}, plan.BrokersToRemove)
+	assert.Equal(t, []v1beta1.PlannedCruiseControlOperation{
+		{Operation: "add_broker", BrokerIDs: []int32{1}},
+		{Operation: "remove_broker", BrokerIDs: []int32{2}},
+	}, plan.CruiseControlOperations)
+	assert.Equal(t, []v1beta1.PlannedResourceChange{
+		{Kind: "ConfigMap", Name: "kafka-config-1", Action: "create"},
+		{Kind: "Pod", Name: "kafka-1-", Action: "create"},
+	}, plan.Resources)
+
+	// changing the configuration of the brokers restarts broker 0
+	cluster.Spec.ReadOnlyConfig = "auto.create.topics.enable=false"
+	plan, err = r.Plan(ctx, logr.Discard())
+	require.NoError(t, err)
+	assert.Equal(t, []int32{0}, plan.BrokersToRestart)
+	assert.Contains(t, plan.Resources, v1beta1.PlannedResourceChange{Kind: "ConfigMap", Name: "kafka-config-0", Action: "update"})
+}
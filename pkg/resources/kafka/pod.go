@@ -65,8 +65,16 @@ func (r *Reconciler) pod(id int32, brokerConfig *v1beta1.BrokerConfig, pvcs []co
 
 	kafkaBrokerContainerPorts = append(kafkaBrokerContainerPorts, r.KafkaCluster.Spec.AdditionalPorts...)
 
+	if r.KafkaCluster.Spec.IsRemoteJMXEnabled() {
+		kafkaBrokerContainerPorts = append(kafkaBrokerContainerPorts, corev1.ContainerPort{
+			Name:          "jmx",
+			ContainerPort: r.KafkaCluster.Spec.RemoteJMXConfig.GetPort(),
+			Protocol:      corev1.ProtocolTCP,
+		})
+	}
+
 	for _, envVar := range r.KafkaCluster.Spec.Envs {
-		if envVar.Name == "JMX_PORT" {
+		if envVar.Name == "JMX_PORT" && !r.KafkaCluster.Spec.IsRemoteJMXEnabled() {
 			port, err := strconv.ParseInt(envVar.Value, 10, 32)
 			if err != nil {
 				log.Error(err, "can't parse JMX_PORT environment variable")
@@ -85,13 +93,58 @@ func (r *Reconciler) pod(id int32, brokerConfig *v1beta1.BrokerConfig, pvcs []co
 	dataVolume, dataVolumeMount := generateDataVolumeAndVolumeMount(pvcs, brokerConfig.StorageConfigs)
 
 	// TODO remove this bash envoy sidecar checker script once sidecar precedence becomes available to Kubernetes(baluchicken)
-	command := []string{"bash", "-c", envoySidecarScript}
+	script := envoySidecarScript
+
+	defaultEnvVars := []corev1.EnvVar{
+		{
+			Name:  "CLASSPATH",
+			Value: "/opt/kafka/libs/extensions/*",
+		},
+		{
+			Name:  "KAFKA_OPTS",
+			Value: "-javaagent:/opt/jmx-exporter/jmx_prometheus.jar=9020:/etc/jmx-exporter/config.yaml",
+		},
+		{
+			Name: "ENVOY_SIDECAR_STATUS",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: `metadata.annotations['sidecar.istio.io/status']`,
+				},
+			},
+		},
+	}
+
+	networkAttachedListeners := r.KafkaCluster.Spec.GetNetworkAttachedListeners()
+	if len(networkAttachedListeners) > 0 {
+		script = networkAttachmentConfigScript + script
+		defaultEnvVars = append(defaultEnvVars, networkAttachmentInterfacesEnv(networkAttachedListeners))
+	}
+
+	volumes, volumeMounts := brokerConfig.Volumes, brokerConfig.VolumeMounts
+	if brokerConfig.Log4jConfigMap != nil {
+		volumes = append(append([]corev1.Volume{}, volumes...), generateLog4jConfigVolume(brokerConfig.Log4jConfigMap))
+		volumeMounts = append(append([]corev1.VolumeMount{}, volumeMounts...), generateLog4jConfigVolumeMount())
+	}
+
+	if r.KafkaCluster.Spec.IsRemoteJMXEnabled() {
+		script = remoteJMXScript + script
+		defaultEnvVars = append(defaultEnvVars, remoteJMXEnvVars(r.KafkaCluster.Spec)...)
+		volumes = append(append([]corev1.Volume{}, volumes...), generateRemoteJMXVolumes(r.KafkaCluster.Name, id)...)
+		volumeMounts = append(append([]corev1.VolumeMount{}, volumeMounts...), generateRemoteJMXVolumeMounts()...)
+	}
+
+	containers := brokerConfig.Containers
+	if len(r.KafkaCluster.Spec.GetSPIFFEListeners()) > 0 {
+		script = spiffeStoresScript + script
+		containers = append([]corev1.Container{generateSPIFFEHelperContainer(r.KafkaCluster.Spec)}, containers...)
+	}
+	command := []string{"bash", "-c", script}
 
 	pod := &corev1.Pod{
 		ObjectMeta: templates.ObjectMetaWithGeneratedNameAndAnnotations(
 			fmt.Sprintf("%s-%d-", r.KafkaCluster.Name, id),
 			brokerConfig.GetBrokerLabels(r.KafkaCluster.Name, id),
-			brokerConfig.GetBrokerAnnotations(),
+			addNetworkAttachmentAnnotation(getBrokerAnnotations(brokerConfig, r.KafkaCluster), networkAttachedListeners),
 			r.KafkaCluster,
 		),
 		Spec: corev1.PodSpec{
@@ -101,7 +154,7 @@ func (r *Reconciler) pod(id int32, brokerConfig *v1beta1.BrokerConfig, pvcs []co
 			Containers: append([]corev1.Container{
 				{
 					Name:  "kafka",
-					Image: util.GetBrokerImage(brokerConfig, r.KafkaCluster.Spec.GetClusterImage()),
+					Image: r.KafkaCluster.Spec.GetImage(util.GetBrokerImage(brokerConfig, r.KafkaCluster.Spec.GetClusterImage())),
 					Lifecycle: &corev1.Lifecycle{
 						PreStop: &corev1.LifecycleHandler{
 							Exec: &corev1.ExecAction{
@@ -121,24 +174,8 @@ fi`},
 						},
 					},
 					SecurityContext: brokerConfig.SecurityContext,
-					Env: generateEnvConfig(brokerConfig, []corev1.EnvVar{
-						{
-							Name:  "CLASSPATH",
-							Value: "/opt/kafka/libs/extensions/*",
-						},
-						{
-							Name:  "KAFKA_OPTS",
-							Value: "-javaagent:/opt/jmx-exporter/jmx_prometheus.jar=9020:/etc/jmx-exporter/config.yaml",
-						},
-						{
-							Name: "ENVOY_SIDECAR_STATUS",
-							ValueFrom: &corev1.EnvVarSource{
-								FieldRef: &corev1.ObjectFieldSelector{
-									FieldPath: `metadata.annotations['sidecar.istio.io/status']`,
-								},
-							},
-						},
-					}),
+					Env:             generateEnvConfig(brokerConfig, defaultEnvVars),
+					EnvFrom:         brokerConfig.EnvFrom,
 
 					Command: command,
 					Ports: append(kafkaBrokerContainerPorts, []corev1.ContainerPort{
@@ -148,14 +185,17 @@ fi`},
 							Name:          "metrics",
 						},
 					}...),
-					VolumeMounts: getVolumeMounts(brokerConfig.VolumeMounts, dataVolumeMount, r.KafkaCluster.Spec, r.KafkaCluster.Name),
-					Resources:    *brokerConfig.GetResources(),
+					VolumeMounts:   getVolumeMounts(volumeMounts, dataVolumeMount, r.KafkaCluster.Spec, r.KafkaCluster.Name),
+					Resources:      *brokerConfig.GetResources(),
+					StartupProbe:   brokerConfig.StartupProbe,
+					LivenessProbe:  brokerConfig.LivenessProbe,
+					ReadinessProbe: brokerConfig.ReadinessProbe,
 				},
-			}, brokerConfig.Containers...),
-			Volumes:                       getVolumes(brokerConfig.Volumes, dataVolume, r.KafkaCluster.Spec, r.KafkaCluster.Name, id),
+			}, containers...),
+			Volumes:                       getVolumes(volumes, dataVolume, r.KafkaCluster, id),
 			RestartPolicy:                 corev1.RestartPolicyNever,
 			TerminationGracePeriodSeconds: util.Int64Pointer(brokerConfig.GetTerminationGracePeriod()),
-			ImagePullSecrets:              brokerConfig.GetImagePullSecrets(),
+			ImagePullSecrets:              r.KafkaCluster.Spec.GetImagePullSecrets(brokerConfig.GetImagePullSecrets()),
 			ServiceAccountName:            brokerConfig.GetServiceAccount(),
 			Tolerations:                   brokerConfig.GetTolerations(),
 			NodeSelector:                  brokerConfig.GetNodeSelector(),
@@ -166,6 +206,12 @@ fi`},
 		pod.Spec.Hostname = fmt.Sprintf("%s-%d", r.KafkaCluster.Name, id)
 		pod.Spec.Subdomain = fmt.Sprintf(kafkautils.HeadlessServiceTemplate, r.KafkaCluster.Name)
 	}
+	if brokerConfig.HostNetwork {
+		applyHostNetwork(&pod.Spec)
+	}
+	if r.KafkaCluster.Spec.IsRestrictedPodSecurityStandard() {
+		k8sutil.ApplyRestrictedPodSecurityStandard(&pod.Spec)
+	}
 
 	return pod
 }
@@ -177,7 +223,7 @@ func getInitContainers(brokerConfig *v1beta1.BrokerConfig, kafkaClusterSpec v1be
 	initContainers = append(initContainers, []corev1.Container{
 		{
 			Name:    "cruise-control-reporter",
-			Image:   util.GetBrokerMetricsReporterImage(brokerConfig, kafkaClusterSpec),
+			Image:   kafkaClusterSpec.GetImage(util.GetBrokerMetricsReporterImage(brokerConfig, kafkaClusterSpec)),
 			Command: []string{"/bin/sh", "-cex", "cp -v /opt/cruise-control/cruise-control/build/dependant-libs/cruise-control-metrics-reporter.jar /opt/kafka/libs/extensions/cruise-control-metrics-reporter.jar"},
 			VolumeMounts: []corev1.VolumeMount{{
 				Name:      "extensions",
@@ -187,7 +233,7 @@ func getInitContainers(brokerConfig *v1beta1.BrokerConfig, kafkaClusterSpec v1be
 		},
 		{
 			Name:    "jmx-exporter",
-			Image:   kafkaClusterSpec.MonitoringConfig.GetImage(),
+			Image:   kafkaClusterSpec.GetImage(kafkaClusterSpec.MonitoringConfig.GetImage()),
 			Command: []string{"cp", kafkaClusterSpec.MonitoringConfig.GetPathToJar(), "/opt/jmx-exporter/jmx_prometheus.jar"},
 			VolumeMounts: []corev1.VolumeMount{
 				{
@@ -218,6 +264,15 @@ func getVolumeMounts(brokerConfigVolumeMounts, dataVolumeMount []corev1.VolumeMo
 	}
 
 	volumeMounts = append(volumeMounts, generateVolumeMountForListenerCerts(kafkaClusterSpec.ListenersConfig)...)
+	if len(kafkaClusterSpec.GetSPIFFEListeners()) > 0 {
+		volumeMounts = append(volumeMounts, generateSPIFFEVolumeMount())
+	}
+	if len(kafkaClusterSpec.GetNetworkAttachedListeners()) > 0 {
+		volumeMounts = append(volumeMounts, generateNetworkAttachmentVolumeMount())
+	}
+	if kafkaClusterSpec.DelegationTokenConfig != nil {
+		volumeMounts = append(volumeMounts, generateDelegationTokenSecretVolumeMount())
+	}
 	volumeMounts = append(volumeMounts, []corev1.VolumeMount{
 		{
 			Name:      brokerConfigMapVolumeMount,
@@ -248,17 +303,27 @@ func getVolumeMounts(brokerConfigVolumeMounts, dataVolumeMount []corev1.VolumeMo
 	return volumeMounts
 }
 
-func getVolumes(brokerConfigVolumes, dataVolume []corev1.Volume, kafkaClusterSpec v1beta1.KafkaClusterSpec, kafkaClusterName string, id int32) []corev1.Volume {
+func getVolumes(brokerConfigVolumes, dataVolume []corev1.Volume, kafkaCluster *v1beta1.KafkaCluster, id int32) []corev1.Volume {
+	kafkaClusterSpec, kafkaClusterName := kafkaCluster.Spec, kafkaCluster.Name
 	volumes := make([]corev1.Volume, 0, len(brokerConfigVolumes))
 	// clone the brokerConfig volumes
 	volumes = append(volumes, brokerConfigVolumes...)
 	volumes = append(volumes, dataVolume...)
 
 	if kafkaClusterSpec.IsClientSSLSecretPresent() {
-		volumes = append(volumes, generateVolumeForClientSSLCert(kafkaClusterSpec, kafkaClusterName))
+		volumes = append(volumes, generateVolumeForClientSSLCert(kafkaCluster))
 	}
 
 	volumes = append(volumes, generateVolumesForListenerCerts(kafkaClusterSpec.ListenersConfig, kafkaClusterName)...)
+	if len(kafkaClusterSpec.GetSPIFFEListeners()) > 0 {
+		volumes = append(volumes, generateSPIFFEVolumes(kafkaClusterSpec.SPIFFEConfig)...)
+	}
+	if len(kafkaClusterSpec.GetNetworkAttachedListeners()) > 0 {
+		volumes = append(volumes, generateNetworkAttachmentVolume())
+	}
+	if kafkaClusterSpec.DelegationTokenConfig != nil {
+		volumes = append(volumes, generateDelegationTokenSecretVolume(kafkaClusterSpec.DelegationTokenConfig))
+	}
 	volumes = append(volumes, []corev1.Volume{
 		{
 			Name: "exitfile",
@@ -305,15 +370,47 @@ func getVolumes(brokerConfigVolumes, dataVolume []corev1.Volume, kafkaClusterSpe
 	return volumes
 }
 
+// getBrokerAnnotations returns the annotations of the broker pod. The restart-at annotation of the KafkaCluster is
+// propagated to the pods so that changing its value rolls the brokers one by one, the restart-at annotation set in
+// the brokerAnnotations takes precedence to restart only the affected brokers.
+func getBrokerAnnotations(bc *v1beta1.BrokerConfig, cluster *v1beta1.KafkaCluster) map[string]string {
+	annotations := bc.GetBrokerAnnotations()
+	restartAt, ok := cluster.GetAnnotations()[v1beta1.RestartAtAnnotationKey]
+	if !ok {
+		return annotations
+	}
+	if _, ok := annotations[v1beta1.RestartAtAnnotationKey]; !ok {
+		annotations[v1beta1.RestartAtAnnotationKey] = restartAt
+	}
+	return annotations
+}
+
 // getAffinity returns a default `v1.Affinity` which is generated regarding the `OneBrokerPerNode` value
 // or if there is any user Affinity definition provided by the user the latter will be used ignoring the value of `OneBrokerPerNode`
 func getAffinity(bc *v1beta1.BrokerConfig, cluster *v1beta1.KafkaCluster) *corev1.Affinity {
 	if bc.Affinity == nil {
-		return &corev1.Affinity{PodAntiAffinity: generatePodAntiAffinity(cluster.Name, cluster.Spec.OneBrokerPerNode)}
+		// brokers using the host network cannot share a node as they would allocate the same host ports
+		return &corev1.Affinity{PodAntiAffinity: generatePodAntiAffinity(cluster.Name, cluster.Spec.OneBrokerPerNode || bc.HostNetwork)}
 	}
 	return bc.Affinity
 }
 
+// applyHostNetwork makes the broker pod use the network namespace of its node, the ports of the kafka container
+// are allocated on the node with the same port numbers
+func applyHostNetwork(podSpec *corev1.PodSpec) {
+	podSpec.HostNetwork = true
+	// the brokers still need to resolve the cluster local service names, e.g. of ZooKeeper
+	podSpec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != "kafka" {
+			continue
+		}
+		for j := range podSpec.Containers[i].Ports {
+			podSpec.Containers[i].Ports[j].HostPort = podSpec.Containers[i].Ports[j].ContainerPort
+		}
+	}
+}
+
 func generatePodAntiAffinity(clusterName string, hardRuleEnabled bool) *corev1.PodAntiAffinity {
 	podAntiAffinity := corev1.PodAntiAffinity{}
 	if hardRuleEnabled {
@@ -396,11 +493,7 @@ func generateDataVolumeAndVolumeMount(pvcs []corev1.PersistentVolumeClaim, stora
 }
 
 func generateVolumeForListenersCertsFromCommonSpec(commonSpec v1beta1.CommonListenerSpec, clusterName string) corev1.Volume {
-	// Use default one if custom has not specified
-	secretName := fmt.Sprintf(pkicommon.BrokerServerCertTemplate, clusterName)
-	if commonSpec.GetServerSSLCertSecretName() != "" {
-		secretName = commonSpec.GetServerSSLCertSecretName()
-	}
+	secretName := pkicommon.ListenerServerCertSecretName(clusterName, commonSpec)
 	return corev1.Volume{
 		Name: fmt.Sprintf(listenerSSLCertVolumeNameTemplate, commonSpec.Name),
 		VolumeSource: corev1.VolumeSource{
@@ -414,40 +507,37 @@ func generateVolumeForListenersCertsFromCommonSpec(commonSpec v1beta1.CommonList
 
 func generateVolumesForListenerCerts(listenerConfig v1beta1.ListenersConfig, clusterName string) (ret []corev1.Volume) {
 	for _, iListener := range listenerConfig.InternalListeners {
-		if iListener.CommonListenerSpec.Type != v1beta1.SecurityProtocolSSL {
+		if iListener.CommonListenerSpec.Type != v1beta1.SecurityProtocolSSL || iListener.UsesSPIFFE() {
 			continue
 		}
-		ret = append(ret, generateVolumeForListenersCertsFromCommonSpec(iListener.CommonListenerSpec, clusterName))
+		ret = append(ret, generateVolumeForListenerCerts(listenerConfig, iListener.CommonListenerSpec, clusterName))
 	}
 	for _, eListener := range listenerConfig.ExternalListeners {
-		if eListener.CommonListenerSpec.Type != v1beta1.SecurityProtocolSSL {
+		if eListener.CommonListenerSpec.Type != v1beta1.SecurityProtocolSSL || eListener.UsesSPIFFE() {
 			continue
 		}
-		ret = append(ret, generateVolumeForListenersCertsFromCommonSpec(eListener.CommonListenerSpec, clusterName))
+		ret = append(ret, generateVolumeForListenerCerts(listenerConfig, eListener.CommonListenerSpec, clusterName))
 	}
 	return ret
 }
 
-func generateVolumeForClientSSLCert(kafkaClusterSpec v1beta1.KafkaClusterSpec, clusterName string) (ret corev1.Volume) {
-	// Use default one if custom has not specified
-	clientSecretName := fmt.Sprintf(pkicommon.BrokerControllerTemplate, clusterName)
-	if kafkaClusterSpec.GetClientSSLCertSecretName() != "" {
-		clientSecretName = kafkaClusterSpec.GetClientSSLCertSecretName()
+func generateVolumeForListenerCerts(listenerConfig v1beta1.ListenersConfig, commonSpec v1beta1.CommonListenerSpec, clusterName string) corev1.Volume {
+	if len(listenerConfig.AdditionalTrustedCAs) > 0 {
+		return generateVolumeForListenerTrustBundle(commonSpec, clusterName)
 	}
+	return generateVolumeForListenersCertsFromCommonSpec(commonSpec, clusterName)
+}
+
+func generateVolumeForClientSSLCert(kafkaCluster *v1beta1.KafkaCluster) corev1.Volume {
 	return corev1.Volume{
-		Name: clientKeystoreVolume,
-		VolumeSource: corev1.VolumeSource{
-			Secret: &corev1.SecretVolumeSource{
-				SecretName:  clientSecretName,
-				DefaultMode: util.Int32Pointer(0644),
-			},
-		},
+		Name:         clientKeystoreVolume,
+		VolumeSource: pkicommon.ClientSSLCertVolumeSource(kafkaCluster),
 	}
 }
 
 func generateVolumeMountForListenerCerts(listenerConfig v1beta1.ListenersConfig) (ret []corev1.VolumeMount) {
 	for _, iListener := range listenerConfig.InternalListeners {
-		if iListener.CommonListenerSpec.Type != v1beta1.SecurityProtocolSSL {
+		if iListener.CommonListenerSpec.Type != v1beta1.SecurityProtocolSSL || iListener.UsesSPIFFE() {
 			continue
 		}
 		vm := corev1.VolumeMount{
@@ -457,7 +547,7 @@ func generateVolumeMountForListenerCerts(listenerConfig v1beta1.ListenersConfig)
 		ret = append(ret, vm)
 	}
 	for _, eListener := range listenerConfig.ExternalListeners {
-		if eListener.CommonListenerSpec.Type != v1beta1.SecurityProtocolSSL {
+		if eListener.CommonListenerSpec.Type != v1beta1.SecurityProtocolSSL || eListener.UsesSPIFFE() {
 			continue
 		}
 		vm := corev1.VolumeMount{
@@ -516,7 +606,13 @@ func generateEnvConfig(brokerConfig *v1beta1.BrokerConfig, defaultEnvVars []core
 		}
 	}
 
-	if brokerConfig.Log4jConfig != "" {
+	switch {
+	case brokerConfig.Log4jConfigMap != nil:
+		envs["KAFKA_LOG4J_OPTS"] = corev1.EnvVar{
+			Name:  "KAFKA_LOG4J_OPTS",
+			Value: "-Dlog4j.configuration=file:" + log4jConfigPath + "/" + log4jConfigFileName,
+		}
+	case brokerConfig.Log4jConfig != "":
 		envs["KAFKA_LOG4J_OPTS"] = corev1.EnvVar{
 			Name:  "KAFKA_LOG4J_OPTS",
 			Value: "-Dlog4j.configuration=file:/config/log4j.properties",
@@ -536,6 +632,17 @@ func generateEnvConfig(brokerConfig *v1beta1.BrokerConfig, defaultEnvVars []core
 			Value: brokerConfig.GetKafkaPerfJmvOpts(),
 		}
 	}
+
+	// JVM options cannot be appended to a value populated from a source
+	if jvmOptions := brokerConfig.JVMOptions.String(); jvmOptions != "" {
+		if kafkaOpts, ok := envs["KAFKA_OPTS"]; !ok {
+			envs["KAFKA_OPTS"] = corev1.EnvVar{Name: "KAFKA_OPTS", Value: jvmOptions}
+		} else if kafkaOpts.ValueFrom == nil {
+			kafkaOpts.Value = strings.TrimSpace(kafkaOpts.Value + " " + jvmOptions)
+			envs["KAFKA_OPTS"] = kafkaOpts
+		}
+	}
+
 	// Sort map values by key to avoid diff in sequence
 	keys := make([]string, 0, len(envs))
 
@@ -549,5 +656,67 @@ func generateEnvConfig(brokerConfig *v1beta1.BrokerConfig, defaultEnvVars []core
 		mergedEnv = append(mergedEnv, envs[k])
 	}
 
-	return mergedEnv
+	return orderEnvsByReferences(mergedEnv)
+}
+
+// orderEnvsByReferences moves the environment variables after the ones they reference in the $(VAR_NAME) format as
+// Kubernetes expands only the references to the variables defined earlier in the list. The relative order of the
+// variables is kept otherwise, variables with cyclic references are left at the end of the list.
+func orderEnvsByReferences(envs []corev1.EnvVar) []corev1.EnvVar {
+	defined := make(map[string]struct{}, len(envs))
+	for _, envVar := range envs {
+		defined[envVar.Name] = struct{}{}
+	}
+
+	ordered := make([]corev1.EnvVar, 0, len(envs))
+	emitted := make(map[string]struct{}, len(envs))
+	pending := envs
+	for len(pending) > 0 {
+		var deferred []corev1.EnvVar
+		for _, envVar := range pending {
+			ready := true
+			for _, ref := range envVarReferences(envVar.Value) {
+				_, isDefined := defined[ref]
+				_, isEmitted := emitted[ref]
+				if isDefined && !isEmitted && ref != envVar.Name {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				deferred = append(deferred, envVar)
+				continue
+			}
+			ordered = append(ordered, envVar)
+			emitted[envVar.Name] = struct{}{}
+		}
+		if len(deferred) == len(pending) {
+			return append(ordered, deferred...)
+		}
+		pending = deferred
+	}
+	return ordered
+}
+
+// envVarReferences returns the names of the variables referenced in the $(VAR_NAME) format, the escaped $$(VAR_NAME)
+// references are skipped
+func envVarReferences(value string) []string {
+	var refs []string
+	for i := 0; i < len(value)-1; i++ {
+		if value[i] != '$' {
+			continue
+		}
+		switch value[i+1] {
+		case '$':
+			i++
+		case '(':
+			end := strings.IndexByte(value[i+2:], ')')
+			if end < 0 {
+				return refs
+			}
+			refs = append(refs, value[i+2:i+2+end])
+			i += end + 2
+		}
+	}
+	return refs
 }
@@ -18,11 +18,13 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/go-logr/logr"
 	"gotest.tools/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
 )
 
 func TestGetAffinity(t *testing.T) {
@@ -100,6 +102,30 @@ func TestGetAffinity(t *testing.T) {
 	assert.DeepEqual(t, affinity, nonNilAffinityBrokerConfig.Affinity.DeepCopy())
 }
 
+func TestPodWithHostNetwork(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{{
+					CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolPlaintext, ContainerPort: 29092},
+					UsedForInnerBrokerCommunication: true,
+				}},
+			},
+		},
+	}
+	r := Reconciler{Reconciler: resources.Reconciler{KafkaCluster: cluster}}
+
+	pod := r.pod(0, &v1beta1.BrokerConfig{HostNetwork: true}, nil, logr.Discard()).(*corev1.Pod)
+
+	assert.Equal(t, pod.Spec.HostNetwork, true)
+	assert.Equal(t, pod.Spec.DNSPolicy, corev1.DNSClusterFirstWithHostNet)
+	assert.Equal(t, len(pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution), 1)
+	for _, port := range pod.Spec.Containers[0].Ports {
+		assert.Equal(t, port.HostPort, port.ContainerPort)
+	}
+}
+
 func Test_generateEnvConfig(t *testing.T) {
 	expected := []corev1.EnvVar{
 		{Name: "KAFKA_HEAP_OPTS", Value: "-Xmx2G -Xms2G"},
@@ -185,3 +211,70 @@ func Test_generateEnvConfig(t *testing.T) {
 		t.Error("Expected:", expected, "Got:", result)
 	}
 }
+
+func TestGenerateEnvConfigWithJVMOptions(t *testing.T) {
+	brokerConfig := &v1beta1.BrokerConfig{
+		KafkaHeapOpts: "-Xmx1G",
+		Envs: []corev1.EnvVar{
+			{Name: "RACK", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.labels['rack']"}}},
+		},
+		JVMOptions: &v1beta1.JVMOptions{
+			Flags:            []string{"-XX:+ExitOnOutOfMemoryError"},
+			SystemProperties: map[string]string{"broker.rack": "$(RACK)", "escaped": "$$(RACK)"},
+		},
+	}
+
+	result := generateEnvConfig(brokerConfig, []corev1.EnvVar{{Name: "KAFKA_OPTS", Value: "-javaagent:agent.jar"}})
+	expected := []corev1.EnvVar{
+		{Name: "KAFKA_HEAP_OPTS", Value: "-Xmx1G"},
+		{Name: "KAFKA_JVM_PERFORMANCE_OPTS", Value: brokerConfig.GetKafkaPerfJmvOpts()},
+		brokerConfig.Envs[0],
+		// moved after the referenced variable
+		{Name: "KAFKA_OPTS", Value: "-javaagent:agent.jar -XX:+ExitOnOutOfMemoryError -Dbroker.rack=$(RACK) -Descaped=$$(RACK)"},
+	}
+	assert.DeepEqual(t, expected, result)
+
+	// JVM options cannot be appended to a variable populated from a source
+	kafkaOpts := corev1.EnvVar{Name: "KAFKA_OPTS", ValueFrom: &corev1.EnvVarSource{
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "jvm"}, Key: "opts"}}}
+	brokerConfig.Envs = []corev1.EnvVar{kafkaOpts}
+	result = generateEnvConfig(brokerConfig, nil)
+	assert.DeepEqual(t, kafkaOpts, result[2])
+}
+
+func TestOrderEnvsByReferences(t *testing.T) {
+	envs := []corev1.EnvVar{
+		{Name: "A", Value: "$(C)-$(UNDEFINED)"},
+		{Name: "B", Value: "b"},
+		{Name: "C", Value: "$(B)"},
+		{Name: "D", Value: "$$(A)"},
+		{Name: "E", Value: "$(F)"},
+		{Name: "F", Value: "$(E)"},
+		{Name: "G", Value: "$(G)"},
+	}
+	expected := []corev1.EnvVar{envs[1], envs[2], envs[3], envs[6], envs[0], envs[4], envs[5]}
+	assert.DeepEqual(t, expected, orderEnvsByReferences(envs))
+}
+
+func TestGetBrokerAnnotations(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "kafka",
+			Annotations: map[string]string{v1beta1.RestartAtAnnotationKey: "2023-05-01T10:00:00Z"},
+		},
+	}
+
+	annotations := getBrokerAnnotations(&v1beta1.BrokerConfig{BrokerAnnotations: map[string]string{"key": "value"}}, cluster)
+	assert.DeepEqual(t, map[string]string{"key": "value", v1beta1.RestartAtAnnotationKey: "2023-05-01T10:00:00Z"}, annotations)
+
+	// The restart-at annotation of the broker takes precedence
+	brokerConfig := &v1beta1.BrokerConfig{
+		BrokerAnnotations: map[string]string{v1beta1.RestartAtAnnotationKey: "2023-05-02T10:00:00Z"},
+	}
+	annotations = getBrokerAnnotations(brokerConfig, cluster)
+	assert.DeepEqual(t, map[string]string{v1beta1.RestartAtAnnotationKey: "2023-05-02T10:00:00Z"}, annotations)
+	assert.Equal(t, "2023-05-02T10:00:00Z", brokerConfig.BrokerAnnotations[v1beta1.RestartAtAnnotationKey])
+
+	annotations = getBrokerAnnotations(&v1beta1.BrokerConfig{}, &v1beta1.KafkaCluster{})
+	assert.DeepEqual(t, map[string]string{}, annotations)
+}
@@ -0,0 +1,33 @@
+#
+# Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+JMX_CONFIG_DIR=/var/run/jmx
+# the JVM refuses to use password and access files which can be read by others
+cp /var/run/secrets/jmx/jmxremote.password /var/run/secrets/jmx/jmxremote.access "$JMX_CONFIG_DIR/"
+chmod 0600 "$JMX_CONFIG_DIR/jmxremote.password" "$JMX_CONFIG_DIR/jmxremote.access"
+KAFKA_JMX_OPTS="-Dcom.sun.management.jmxremote -Dcom.sun.management.jmxremote.authenticate=true -Dcom.sun.management.jmxremote.password.file=$JMX_CONFIG_DIR/jmxremote.password -Dcom.sun.management.jmxremote.access.file=$JMX_CONFIG_DIR/jmxremote.access -Djava.rmi.server.hostname=$KAFKA_JMX_HOSTNAME"
+if [[ -n "$KAFKA_JMX_SSL_KEYSTORE_DIR" ]]; then
+  # the keystore password is kept out of the command line of the broker
+  (
+    umask 0077
+    echo "javax.net.ssl.keyStore=$KAFKA_JMX_SSL_KEYSTORE_DIR/$KAFKA_JMX_SSL_KEYSTORE_FILE"
+    echo "javax.net.ssl.keyStoreType=$KAFKA_JMX_SSL_KEYSTORE_TYPE"
+    echo "javax.net.ssl.keyStorePassword=$(cat "$KAFKA_JMX_SSL_KEYSTORE_DIR/password")"
+  ) > "$JMX_CONFIG_DIR/jmxremote.ssl"
+  KAFKA_JMX_OPTS="$KAFKA_JMX_OPTS -Dcom.sun.management.jmxremote.ssl=true -Dcom.sun.management.jmxremote.registry.ssl=true -Dcom.sun.management.jmxremote.ssl.config.file=$JMX_CONFIG_DIR/jmxremote.ssl"
+else
+  KAFKA_JMX_OPTS="$KAFKA_JMX_OPTS -Dcom.sun.management.jmxremote.ssl=false"
+fi
+export KAFKA_JMX_OPTS
@@ -0,0 +1,166 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/common/expfmt"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+)
+
+// underReplicatedPartitionsMetric is the name of the UnderReplicatedPartitions gauge of the ReplicaManager as
+// exported by the upstream Kafka rules of the Prometheus JMX exporter
+const underReplicatedPartitionsMetric = "kafka_server_replicamanager_underreplicatedpartitions"
+
+var metricsHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// checkBrokerReadinessGate returns an error when the broker readiness gate is enabled and there are brokers which
+// have not rejoined the ISR of their partitions yet. The impactedReplicas argument holds the brokers with offline or
+// out of sync replicas as reported by the Admin API. When the Admin API is used the brokers of the pods, except the one
+// about to be restarted, also have to be registered in the cluster with all of their log directories online.
+func (r *Reconciler) checkBrokerReadinessGate(log logr.Logger, kClient kafkaclient.KafkaClient, impactedReplicas map[int32]struct{},
+	pods []corev1.Pod, currentPod *corev1.Pod) error {
+	gate := r.KafkaCluster.Spec.RollingUpgradeConfig.BrokerReadinessGate
+	if gate == nil {
+		return nil
+	}
+
+	switch gate.GetMethod() {
+	case v1beta1.BrokerReadinessGateJMX:
+		var underReplicated float64
+		for _, pod := range pods {
+			value, err := scrapeUnderReplicatedPartitions(pod.Status.PodIP)
+			if err != nil {
+				return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, err, "could not query under-replicated partitions",
+					"pod", pod.GetName())
+			}
+			underReplicated += value
+		}
+		if underReplicated > 0 {
+			log.Info("brokers have under-replicated partitions", "count", underReplicated)
+			return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("brokers have under-replicated partitions"),
+				"rolling upgrade in progress")
+		}
+	default:
+		if len(impactedReplicas) > 0 {
+			brokerIDs := make([]int32, 0, len(impactedReplicas))
+			for brokerID := range impactedReplicas {
+				brokerIDs = append(brokerIDs, brokerID)
+			}
+			sort.Slice(brokerIDs, func(i, j int) bool { return brokerIDs[i] < brokerIDs[j] })
+			return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("brokers have not rejoined ISR yet"),
+				"rolling upgrade in progress", "brokerIDs", brokerIDs)
+		}
+		if err := checkBrokerHealth(log, kClient, pods, currentPod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBrokerHealth returns an error when a broker of the given pods, other than the current pod, is not registered in
+// the cluster or has offline log directories according to the Admin API
+func checkBrokerHealth(log logr.Logger, kClient kafkaclient.KafkaClient, pods []corev1.Pod, currentPod *corev1.Pod) error {
+	if kClient == nil {
+		return nil
+	}
+	brokerIDs := make([]int32, 0, len(pods))
+	for _, pod := range pods {
+		if currentPod != nil && pod.GetName() == currentPod.GetName() {
+			continue
+		}
+		brokerID, err := strconv.ParseInt(pod.Labels[v1beta1.BrokerIdLabelKey], 10, 32)
+		if err != nil {
+			continue
+		}
+		brokerIDs = append(brokerIDs, int32(brokerID))
+	}
+	if len(brokerIDs) == 0 {
+		return nil
+	}
+	health, err := kClient.ProbeBrokerHealth(brokerIDs)
+	if err != nil {
+		return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, err, "could not probe the health of the brokers")
+	}
+	var unhealthy []int32
+	for _, brokerID := range brokerIDs {
+		brokerHealth := health[brokerID]
+		if !brokerHealth.Registered || len(brokerHealth.OfflineLogDirs) > 0 {
+			log.Info("broker is not healthy", v1beta1.BrokerIdLabelKey, brokerID,
+				"registered", brokerHealth.Registered, "offlineLogDirs", brokerHealth.OfflineLogDirs)
+			unhealthy = append(unhealthy, brokerID)
+		}
+	}
+	if len(unhealthy) > 0 {
+		sort.Slice(unhealthy, func(i, j int) bool { return unhealthy[i] < unhealthy[j] })
+		return errorfactory.New(errorfactory.ReconcileRollingUpgrade{}, errors.New("brokers are not healthy"),
+			"rolling upgrade in progress", "brokerIDs", unhealthy)
+	}
+	return nil
+}
+
+// scrapeUnderReplicatedPartitions returns the number of under-replicated partitions reported by the JMX exporter
+// of the broker running in the pod with the given IP
+func scrapeUnderReplicatedPartitions(podIP string) (float64, error) {
+	if podIP == "" {
+		return 0, errors.New("pod has no IP address assigned yet")
+	}
+	url := "http://" + net.JoinHostPort(podIP, strconv.Itoa(MetricsPort)) + "/metrics"
+	resp, err := metricsHTTPClient.Get(url)
+	if err != nil {
+		return 0, errors.WrapIfWithDetails(err, "could not scrape broker metrics", "url", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, errors.NewWithDetails("unexpected status code when scraping broker metrics", "url", url, "statusCode", resp.StatusCode)
+	}
+	return parseUnderReplicatedPartitions(resp.Body)
+}
+
+// parseUnderReplicatedPartitions returns the number of under-replicated partitions from metrics in the Prometheus
+// text exposition format
+func parseUnderReplicatedPartitions(metrics io.Reader) (float64, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(metrics)
+	if err != nil {
+		return 0, errors.WrapIf(err, "could not parse broker metrics")
+	}
+	family, ok := families[underReplicatedPartitionsMetric]
+	if !ok {
+		return 0, errors.NewWithDetails("broker metric is missing", "metric", underReplicatedPartitionsMetric)
+	}
+	var value float64
+	for _, metric := range family.GetMetric() {
+		switch {
+		case metric.GetGauge() != nil:
+			value += metric.GetGauge().GetValue()
+		case metric.GetUntyped() != nil:
+			value += metric.GetUntyped().GetValue()
+		}
+	}
+	return value, nil
+}
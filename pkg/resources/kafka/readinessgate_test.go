@@ -0,0 +1,143 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"strings"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/kafkaclient"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestParseUnderReplicatedPartitions(t *testing.T) {
+	testCases := []struct {
+		testName string
+		metrics  string
+		expected float64
+		errorExp bool
+	}{
+		{
+			testName: "no under-replicated partitions",
+			metrics: `# HELP kafka_server_replicamanager_underreplicatedpartitions Attribute exposed for management
+# TYPE kafka_server_replicamanager_underreplicatedpartitions untyped
+kafka_server_replicamanager_underreplicatedpartitions 0.0
+`,
+			expected: 0,
+		},
+		{
+			testName: "gauge with under-replicated partitions",
+			metrics: `# TYPE kafka_server_replicamanager_underreplicatedpartitions gauge
+kafka_server_replicamanager_underreplicatedpartitions 3.0
+`,
+			expected: 3,
+		},
+		{
+			testName: "missing metric",
+			metrics: `# TYPE kafka_server_replicamanager_leadercount gauge
+kafka_server_replicamanager_leadercount 10.0
+`,
+			errorExp: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			value, err := parseUnderReplicatedPartitions(strings.NewReader(test.metrics))
+			if test.errorExp {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestCheckBrokerReadinessGate(t *testing.T) {
+	testCases := []struct {
+		testName         string
+		gate             *v1beta1.BrokerReadinessGate
+		impactedReplicas map[int32]struct{}
+		errorExp         bool
+	}{
+		{
+			testName:         "gate disabled",
+			impactedReplicas: map[int32]struct{}{1: {}},
+		},
+		{
+			testName: "all brokers in sync",
+			gate:     &v1beta1.BrokerReadinessGate{},
+		},
+		{
+			testName:         "broker has not rejoined ISR",
+			gate:             &v1beta1.BrokerReadinessGate{Method: v1beta1.BrokerReadinessGateAdminAPI},
+			impactedReplicas: map[int32]struct{}{1: {}},
+			errorExp:         true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			r := Reconciler{
+				Reconciler: resources.Reconciler{
+					KafkaCluster: &v1beta1.KafkaCluster{
+						Spec: v1beta1.KafkaClusterSpec{
+							RollingUpgradeConfig: v1beta1.RollingUpgradeConfig{
+								FailureThreshold:    2,
+								BrokerReadinessGate: test.gate,
+							},
+						},
+					},
+				},
+			}
+			err := r.checkBrokerReadinessGate(logr.Discard(), nil, test.impactedReplicas, nil, nil)
+			if test.errorExp {
+				require.Error(t, err)
+				require.True(t, errors.As(err, &errorfactory.ReconcileRollingUpgrade{}))
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckBrokerHealth(t *testing.T) {
+	brokerPod := func(name, brokerID string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1beta1.BrokerIdLabelKey: brokerID}}}
+	}
+	// The mock cluster has a single registered broker with ID 0
+	kClient, close, err := kafkaclient.NewMockFromCluster(nil, nil)
+	require.NoError(t, err)
+	defer close()
+
+	require.NoError(t, checkBrokerHealth(logr.Discard(), kClient, []corev1.Pod{brokerPod("kafka-0", "0")}, nil))
+
+	err = checkBrokerHealth(logr.Discard(), kClient, []corev1.Pod{brokerPod("kafka-0", "0"), brokerPod("kafka-1", "1")}, nil)
+	require.Error(t, err)
+	require.True(t, errors.As(err, &errorfactory.ReconcileRollingUpgrade{}))
+
+	// The broker about to be restarted is not checked
+	currentPod := brokerPod("kafka-1", "1")
+	require.NoError(t, checkBrokerHealth(logr.Discard(), kClient, []corev1.Pod{brokerPod("kafka-0", "0"), currentPod}, &currentPod))
+}
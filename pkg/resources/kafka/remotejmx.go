@@ -0,0 +1,175 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	"github.com/banzaicloud/koperator/pkg/util"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+)
+
+const (
+	// remoteJMXCredentialsSecretTemplate is the name of the Secret holding the remote JMX credentials of a broker
+	remoteJMXCredentialsSecretTemplate = "%s-%s-jmx"
+	remoteJMXCredentialsVolume         = "jmx-credentials"
+	remoteJMXCredentialsPath           = "/var/run/secrets/jmx"
+	// remoteJMXConfigVolume holds the copies of the password and access files with the permissions required by the JVM
+	remoteJMXConfigVolume = "jmx-remote-config"
+	remoteJMXConfigPath   = "/var/run/jmx"
+
+	remoteJMXUsername       = "kafka-jmx"
+	remoteJMXUsernameKey    = "username"
+	remoteJMXPasswordFile   = "jmxremote.password"
+	remoteJMXAccessFile     = "jmxremote.access"
+	remoteJMXPasswordLength = 24
+)
+
+var (
+	//go:embed prepare-remote-jmx.sh
+	remoteJMXScript string
+)
+
+func remoteJMXCredentialsSecretName(clusterName string, brokerID string) string {
+	return fmt.Sprintf(remoteJMXCredentialsSecretTemplate, clusterName, brokerID)
+}
+
+// reconcileRemoteJMXCredentials creates the Secret holding the remote JMX credentials of the broker. The password is
+// generated once, it is kept as long as the Secret exists.
+func (r *Reconciler) reconcileRemoteJMXCredentials(ctx context.Context, brokerID int32, log logr.Logger) error {
+	name := remoteJMXCredentialsSecretName(r.KafkaCluster.GetName(), strconv.Itoa(int(brokerID)))
+	err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.KafkaCluster.GetNamespace()}, &corev1.Secret{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.WrapIfWithDetails(err, "could not get remote JMX credentials", "secret", name)
+	}
+
+	password, err := util.GetRandomString(remoteJMXPasswordLength)
+	if err != nil {
+		return errors.WrapIf(err, "could not generate remote JMX password")
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: templates.ObjectMeta(name,
+			apiutil.MergeLabels(
+				apiutil.LabelsForKafka(r.KafkaCluster.GetName()),
+				map[string]string{v1beta1.BrokerIdLabelKey: fmt.Sprintf("%d", brokerID)},
+			),
+			r.KafkaCluster,
+		),
+		Data: map[string][]byte{
+			remoteJMXUsernameKey:  []byte(remoteJMXUsername),
+			v1alpha1.PasswordKey:  []byte(password),
+			remoteJMXPasswordFile: []byte(fmt.Sprintf("%s %s\n", remoteJMXUsername, password)),
+			remoteJMXAccessFile:   []byte(fmt.Sprintf("%s readonly\n", remoteJMXUsername)),
+		},
+	}
+	if err := r.Client.Create(ctx, secret); err != nil {
+		return errors.WrapIfWithDetails(err, "could not create remote JMX credentials", "secret", name)
+	}
+	log.Info("remote JMX credentials created", v1beta1.BrokerIdLabelKey, brokerID, "secret", name)
+	return nil
+}
+
+// remoteJMXEnvVars returns the environment variables the prepare-remote-jmx.sh script configures the remote JMX
+// endpoint of the broker from
+func remoteJMXEnvVars(kafkaClusterSpec v1beta1.KafkaClusterSpec) []corev1.EnvVar {
+	envVars := []corev1.EnvVar{
+		{
+			Name:  "JMX_PORT",
+			Value: fmt.Sprintf("%d", kafkaClusterSpec.RemoteJMXConfig.GetPort()),
+		},
+		{
+			Name: "KAFKA_JMX_HOSTNAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.podIP"},
+			},
+		},
+	}
+	if !kafkaClusterSpec.RemoteJMXConfig.SSL {
+		return envVars
+	}
+	for _, iListener := range kafkaClusterSpec.ListenersConfig.InternalListeners {
+		if iListener.UsedForInnerBrokerCommunication && iListener.Type == v1beta1.SecurityProtocolSSL && !iListener.UsesSPIFFE() {
+			envVars = append(envVars,
+				corev1.EnvVar{
+					Name:  "KAFKA_JMX_SSL_KEYSTORE_DIR",
+					Value: fmt.Sprintf(listenerServerKeyStorePathTemplate, serverKeystorePath, iListener.Name),
+				},
+				corev1.EnvVar{
+					Name:  "KAFKA_JMX_SSL_KEYSTORE_FILE",
+					Value: certutil.KeyStoreFileName(iListener.TLSPolicy.GetKeyStoreType()),
+				},
+				corev1.EnvVar{
+					Name:  "KAFKA_JMX_SSL_KEYSTORE_TYPE",
+					Value: string(iListener.TLSPolicy.GetKeyStoreType()),
+				})
+			break
+		}
+	}
+	return envVars
+}
+
+func generateRemoteJMXVolumes(clusterName string, brokerID int32) []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: remoteJMXCredentialsVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: remoteJMXCredentialsSecretName(clusterName, strconv.Itoa(int(brokerID))),
+					Items: []corev1.KeyToPath{
+						{Key: remoteJMXPasswordFile, Path: remoteJMXPasswordFile},
+						{Key: remoteJMXAccessFile, Path: remoteJMXAccessFile},
+					},
+					DefaultMode: util.Int32Pointer(0400),
+				},
+			},
+		},
+		{
+			Name: remoteJMXConfigVolume,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+}
+
+func generateRemoteJMXVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      remoteJMXCredentialsVolume,
+			MountPath: remoteJMXCredentialsPath,
+			ReadOnly:  true,
+		},
+		{
+			Name:      remoteJMXConfigVolume,
+			MountPath: remoteJMXConfigPath,
+		},
+	}
+}
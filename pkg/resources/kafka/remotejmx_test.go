@@ -0,0 +1,98 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestReconcileRemoteJMXCredentials(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+	}
+	c := fake.NewClientBuilder().Build()
+	r := Reconciler{Reconciler: resources.Reconciler{KafkaCluster: cluster, Client: c}}
+
+	require.NoError(t, r.reconcileRemoteJMXCredentials(context.Background(), 1, logr.Discard()))
+
+	secret := &corev1.Secret{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "kafka-1-jmx", Namespace: "kafka"}, secret))
+	password := string(secret.Data[v1alpha1.PasswordKey])
+	assert.Len(t, password, remoteJMXPasswordLength)
+	assert.Equal(t, remoteJMXUsername, string(secret.Data[remoteJMXUsernameKey]))
+	assert.Equal(t, remoteJMXUsername+" "+password+"\n", string(secret.Data[remoteJMXPasswordFile]))
+	assert.Equal(t, remoteJMXUsername+" readonly\n", string(secret.Data[remoteJMXAccessFile]))
+	assert.Equal(t, "1", secret.Labels[v1beta1.BrokerIdLabelKey])
+
+	// The password is not regenerated by subsequent reconciles
+	require.NoError(t, r.reconcileRemoteJMXCredentials(context.Background(), 1, logr.Discard()))
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "kafka-1-jmx", Namespace: "kafka"}, secret))
+	assert.Equal(t, password, string(secret.Data[v1alpha1.PasswordKey]))
+}
+
+func TestPodWithRemoteJMX(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			RemoteJMXConfig: &v1beta1.RemoteJMXConfig{Enabled: true, SSL: true},
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL, ContainerPort: 29092},
+						UsedForInnerBrokerCommunication: true,
+					},
+				},
+			},
+			Envs: []corev1.EnvVar{{Name: "JMX_PORT", Value: "5555"}},
+		},
+	}
+	r := Reconciler{Reconciler: resources.Reconciler{KafkaCluster: cluster}}
+
+	pod := r.pod(2, &v1beta1.BrokerConfig{}, nil, logr.Discard()).(*corev1.Pod)
+	kafkaContainer := pod.Spec.Containers[0]
+
+	assert.True(t, strings.HasPrefix(kafkaContainer.Command[2], remoteJMXScript))
+	assert.Contains(t, kafkaContainer.Ports, corev1.ContainerPort{Name: "jmx", ContainerPort: v1beta1.DefaultRemoteJMXPort, Protocol: corev1.ProtocolTCP})
+	assert.NotContains(t, kafkaContainer.Ports, corev1.ContainerPort{Name: "jmx", ContainerPort: 5555, Protocol: corev1.ProtocolTCP})
+	assert.Contains(t, kafkaContainer.Env, corev1.EnvVar{Name: "JMX_PORT", Value: "9999"})
+	assert.Contains(t, kafkaContainer.Env, corev1.EnvVar{Name: "KAFKA_JMX_SSL_KEYSTORE_DIR", Value: serverKeystorePath + "/internal"})
+	assert.Contains(t, kafkaContainer.Env, corev1.EnvVar{Name: "KAFKA_JMX_SSL_KEYSTORE_FILE", Value: v1alpha1.TLSJKSKeyStore})
+	assert.Contains(t, kafkaContainer.Env, corev1.EnvVar{Name: "KAFKA_JMX_SSL_KEYSTORE_TYPE", Value: string(v1beta1.KeyStoreTypeJKS)})
+	assert.Contains(t, kafkaContainer.VolumeMounts, corev1.VolumeMount{Name: remoteJMXCredentialsVolume, MountPath: remoteJMXCredentialsPath, ReadOnly: true})
+	assert.Contains(t, kafkaContainer.VolumeMounts, corev1.VolumeMount{Name: remoteJMXConfigVolume, MountPath: remoteJMXConfigPath})
+
+	var volume *corev1.Volume
+	for i := range pod.Spec.Volumes {
+		if pod.Spec.Volumes[i].Name == remoteJMXCredentialsVolume {
+			volume = &pod.Spec.Volumes[i]
+		}
+	}
+	require.NotNil(t, volume)
+	assert.Equal(t, "kafka-2-jmx", volume.Secret.SecretName)
+}
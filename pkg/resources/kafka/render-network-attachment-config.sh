@@ -0,0 +1,57 @@
+#
+# Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+hex_le_to_int() {
+  local h=$1
+  echo $(( 0x${h:6:2} << 24 | 0x${h:4:2} << 16 | 0x${h:2:2} << 8 | 0x${h:0:2} ))
+}
+ipv4_to_int() {
+  local IFS=.
+  local -a p=($1)
+  echo $(( p[0] << 24 | p[1] << 16 | p[2] << 8 | p[3] ))
+}
+# interface_address prints the IPv4 address of the network interface. Without the ip command the local addresses
+# are matched against the networks routed through the interface.
+interface_address() {
+  if command -v ip >/dev/null 2>&1; then
+    ip -4 -o addr show dev "$1" 2>/dev/null | awk '{split($4, a, "/"); print a[1]; exit}'
+    return
+  fi
+  local iface destination mask address
+  while read -r iface destination _ _ _ _ _ mask _; do
+    [[ "$iface" == "$1" && "$mask" != "00000000" ]] || continue
+    for address in $(awk '/32 host LOCAL/ {print previous} {previous = $2}' /proc/net/fib_trie 2>/dev/null | sort -u); do
+      if (( ($(ipv4_to_int "$address") & $(hex_le_to_int "$mask")) == $(hex_le_to_int "$destination") )); then
+        echo "$address"
+        return
+      fi
+    done
+  done < /proc/net/route
+}
+RENDERED_CONFIG=/var/run/network-attachments/broker-config
+cp /config/broker-config "$RENDERED_CONFIG.tmp"
+for IFACE in $KAFKA_NETWORK_ATTACHMENT_INTERFACES; do
+  ADDRESS=""
+  until [[ -n "$ADDRESS" ]]; do
+    ADDRESS=$(interface_address "$IFACE")
+    if [[ -z "$ADDRESS" ]]; then
+      echo "waiting for the address of the $IFACE network interface";
+      sleep 1;
+    fi
+  done
+  sed -i "s/{{network-attachment-address:$IFACE}}/$ADDRESS/g" "$RENDERED_CONFIG.tmp"
+done
+mv -f "$RENDERED_CONFIG.tmp" "$RENDERED_CONFIG"
+KAFKA_BROKER_CONFIG="$RENDERED_CONFIG"
@@ -0,0 +1,111 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	"github.com/banzaicloud/koperator/pkg/util/kafka"
+)
+
+// serviceBindingSecretTemplate is the name of the binding Secret of the cluster
+const serviceBindingSecretTemplate = "%s-binding"
+
+func serviceBindingSecretName(clusterName string) string {
+	return fmt.Sprintf(serviceBindingSecretTemplate, clusterName)
+}
+
+// reconcileServiceBinding maintains the binding Secret of the cluster defined by the Service Binding specification
+// and publishes its reference in the status. The Secret is created only when the listener has an address.
+func (r *Reconciler) reconcileServiceBinding(ctx context.Context, log logr.Logger) error {
+	var binding *corev1.LocalObjectReference
+	secretName := serviceBindingSecretName(r.KafkaCluster.GetName())
+
+	if r.KafkaCluster.Spec.ServiceBinding == nil {
+		if r.KafkaCluster.Status.Binding != nil {
+			secret := &corev1.Secret{}
+			secret.SetName(secretName)
+			secret.SetNamespace(r.KafkaCluster.GetNamespace())
+			if err := r.Client.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+				return errors.WrapIfWithDetails(err, "could not delete service binding secret", "secret", secretName)
+			}
+		}
+	} else {
+		data, err := serviceBindingData(r.KafkaCluster)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			log.Info("the listener of the service binding has no address yet", "listener", r.KafkaCluster.Spec.ServiceBinding.Listener)
+			return nil
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: templates.ObjectMeta(secretName, apiutil.LabelsForKafka(r.KafkaCluster.GetName()), r.KafkaCluster),
+			Data:       data,
+		}
+		if err := k8sutil.Reconcile(log, r.Client, secret, r.KafkaCluster); err != nil {
+			return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", secret.GetObjectKind().GroupVersionKind())
+		}
+		binding = &corev1.LocalObjectReference{Name: secretName}
+	}
+
+	if equality.Semantic.DeepEqual(r.KafkaCluster.Status.Binding, binding) {
+		return nil
+	}
+	err := k8sutil.PatchKafkaClusterStatus(ctx, r.Client, r.KafkaCluster, func(status *v1beta1.KafkaClusterStatus) {
+		status.Binding = binding
+	})
+	if err != nil {
+		return errors.WrapIf(err, "could not update the service binding in the KafkaCluster status")
+	}
+	log.Info("service binding of the cluster updated", "binding", binding)
+	return nil
+}
+
+// serviceBindingData returns the content of the binding Secret of the cluster, or nil when the listener of the
+// binding has no address
+func serviceBindingData(cluster *v1beta1.KafkaCluster) (map[string][]byte, error) {
+	listenerName := cluster.Spec.ServiceBinding.Listener
+	for _, listener := range cluster.Spec.ListenersConfig.InternalListeners {
+		if listener.Name == listenerName || (listenerName == "" && listener.UsedForInnerBrokerCommunication) {
+			return listenerServiceBindingData(listener.Type, cluster.Status.ListenerStatuses.InternalListeners[listener.Name]), nil
+		}
+	}
+	for _, listener := range cluster.Spec.ListenersConfig.ExternalListeners {
+		if listener.Name == listenerName {
+			return listenerServiceBindingData(listener.Type, cluster.Status.ListenerStatuses.ExternalListeners[listener.Name]), nil
+		}
+	}
+	return nil, errors.NewWithDetails("the listener of the service binding does not exist", "listener", listenerName)
+}
+
+func listenerServiceBindingData(securityProtocol v1beta1.SecurityProtocol, statuses v1beta1.ListenerStatusList) map[string][]byte {
+	bootstrapServers := kafka.ListenerBootstrapServers(statuses)
+	if bootstrapServers == "" {
+		return nil
+	}
+	return kafka.ServiceBindingData(bootstrapServers, securityProtocol, "")
+}
@@ -0,0 +1,103 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestReconcileServiceBinding(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", UID: "kafka-uid"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ServiceBinding: &v1beta1.ClusterServiceBinding{},
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{{
+					CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolPlaintext},
+					UsedForInnerBrokerCommunication: true,
+				}},
+				ExternalListeners: []v1beta1.ExternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "external", Type: v1beta1.SecurityProtocolSSL}},
+				},
+			},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			ListenerStatuses: v1beta1.ListenerStatuses{
+				InternalListeners: map[string]v1beta1.ListenerStatusList{
+					"internal": {
+						{Name: "any-broker", Address: "kafka-all-broker.kafka.svc.cluster.local:29092"},
+						{Name: "broker-0", Address: "kafka-0.kafka.svc.cluster.local:29092"},
+					},
+				},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	r := Reconciler{Reconciler: resources.Reconciler{Client: c, KafkaCluster: cluster}}
+
+	require.NoError(t, r.reconcileServiceBinding(ctx, logr.Discard()))
+	assert.Equal(t, &corev1.LocalObjectReference{Name: "kafka-binding"}, cluster.Status.Binding)
+	secret := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "kafka-binding"}, secret))
+	assert.Equal(t, map[string][]byte{
+		"type":              []byte("kafka"),
+		"provider":          []byte("koperator"),
+		"bootstrap-servers": []byte("kafka-all-broker.kafka.svc.cluster.local:29092"),
+		"securityProtocol":  []byte("PLAINTEXT"),
+	}, secret.Data)
+
+	// the binding is not published until the listener has an address
+	cluster.Spec.ServiceBinding.Listener = "external"
+	require.NoError(t, r.reconcileServiceBinding(ctx, logr.Discard()))
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "kafka-binding"}, secret))
+	assert.Equal(t, "PLAINTEXT", string(secret.Data["securityProtocol"]))
+
+	cluster.Status.ListenerStatuses.ExternalListeners = map[string]v1beta1.ListenerStatusList{
+		"external": {{Name: "broker-0", Address: "kafka.example.com:19090"}, {Name: "broker-1", Address: "kafka.example.com:19091"}},
+	}
+	require.NoError(t, r.reconcileServiceBinding(ctx, logr.Discard()))
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "kafka-binding"}, secret))
+	assert.Equal(t, "kafka.example.com:19090,kafka.example.com:19091", string(secret.Data["bootstrap-servers"]))
+	assert.Equal(t, "SSL", string(secret.Data["securityProtocol"]))
+
+	cluster.Spec.ServiceBinding.Listener = "missing"
+	assert.Error(t, r.reconcileServiceBinding(ctx, logr.Discard()))
+
+	// the Secret is removed when the binding is disabled
+	cluster.Spec.ServiceBinding = nil
+	require.NoError(t, r.reconcileServiceBinding(ctx, logr.Discard()))
+	assert.Nil(t, cluster.Status.Binding)
+	err := c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "kafka-binding"}, secret)
+	assert.True(t, apierrors.IsNotFound(err))
+}
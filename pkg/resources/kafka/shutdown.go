@@ -0,0 +1,188 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// UncleanBrokerShutdownEventReason is the reason of the Event emitted when a broker container has been terminated
+// without completing the controlled shutdown
+const UncleanBrokerShutdownEventReason = "UncleanBrokerShutdown"
+
+// cleanShutdownTerminationMessage is the part of the termination message written by the broker container when every
+// log directory has been closed by a completed controlled shutdown
+const cleanShutdownTerminationMessage = "cleanShutdown=true"
+
+// verifyControlledShutdowns records the outcome of the last termination of the broker containers in the status of the
+// KafkaCluster and emits an Event for every broker which has been killed before completing the controlled shutdown
+func (r *Reconciler) verifyControlledShutdowns(ctx context.Context, log logr.Logger) error {
+	podList := &corev1.PodList{}
+	err := r.Client.List(ctx, podList,
+		client.InNamespace(r.KafkaCluster.Namespace),
+		client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.Name)),
+	)
+	if err != nil {
+		return errors.WrapIf(err, "failed to list the broker pods")
+	}
+
+	shutdowns := make(map[string]*v1beta1.BrokerShutdownStatus)
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		brokerID, ok := pod.Labels[v1beta1.BrokerIdLabelKey]
+		if !ok {
+			continue
+		}
+		brokerState, ok := r.KafkaCluster.Status.BrokersState[brokerID]
+		if !ok {
+			continue
+		}
+		shutdown := brokerShutdownStatus(pod, r.terminationGracePeriod(brokerID))
+		if shutdown == nil || isShutdownRecorded(brokerState.LastShutdown, shutdown) {
+			continue
+		}
+
+		if shutdown.Clean {
+			log.Info("broker completed the controlled shutdown", v1beta1.BrokerIdLabelKey, brokerID, "pod", pod.GetName())
+		} else {
+			log.Info("broker has been terminated without completing the controlled shutdown", v1beta1.BrokerIdLabelKey, brokerID,
+				"pod", pod.GetName(), "exitCode", shutdown.ExitCode, "reason", shutdown.Reason)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(r.KafkaCluster, corev1.EventTypeWarning, UncleanBrokerShutdownEventReason,
+					"broker %s in pod %s: %s", brokerID, pod.GetName(), shutdown.Message)
+			}
+		}
+		shutdowns[brokerID] = shutdown
+	}
+
+	recordShutdowns := func(cluster *v1beta1.KafkaCluster) {
+		for brokerID, shutdown := range shutdowns {
+			if brokerState, ok := cluster.Status.BrokersState[brokerID]; ok {
+				brokerState.LastShutdown = shutdown
+				cluster.Status.BrokersState[brokerID] = brokerState
+			}
+		}
+		meta.SetStatusCondition(&cluster.Status.Conditions, shutdownCondition(cluster))
+	}
+	desired := r.KafkaCluster.DeepCopy()
+	recordShutdowns(desired)
+	if equality.Semantic.DeepEqual(r.KafkaCluster.Status, desired.Status) {
+		return nil
+	}
+
+	err = k8sutil.PatchKafkaClusterStatus(ctx, r.Client, r.KafkaCluster, func(*v1beta1.KafkaClusterStatus) {
+		recordShutdowns(r.KafkaCluster)
+	})
+	if err != nil {
+		return errors.WrapIf(err, "could not update the shutdown state of the brokers in the KafkaCluster status")
+	}
+	return nil
+}
+
+// terminationGracePeriod returns the termination grace period of the pod of the broker in seconds
+func (r *Reconciler) terminationGracePeriod(brokerID string) int64 {
+	for _, broker := range r.KafkaCluster.Spec.Brokers {
+		if strconv.Itoa(int(broker.Id)) != brokerID {
+			continue
+		}
+		if brokerConfig, err := broker.GetBrokerConfig(r.KafkaCluster.Spec); err == nil {
+			return brokerConfig.GetTerminationGracePeriod()
+		}
+	}
+	return v1beta1.DefaultBrokerTerminationGracePeriod
+}
+
+// brokerShutdownStatus returns the outcome of the last termination of the kafka container of the pod, or nil when
+// the container has not been terminated yet
+func brokerShutdownStatus(pod *corev1.Pod, terminationGracePeriod int64) *v1beta1.BrokerShutdownStatus {
+	var terminated *corev1.ContainerStateTerminated
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name != "kafka" {
+			continue
+		}
+		terminated = containerStatus.State.Terminated
+		if terminated == nil {
+			terminated = containerStatus.LastTerminationState.Terminated
+		}
+	}
+	if terminated == nil {
+		return nil
+	}
+
+	shutdown := &v1beta1.BrokerShutdownStatus{
+		Pod:        pod.GetName(),
+		ExitCode:   terminated.ExitCode,
+		Reason:     terminated.Reason,
+		FinishedAt: terminated.FinishedAt,
+	}
+	switch {
+	case strings.Contains(terminated.Message, cleanShutdownTerminationMessage):
+		shutdown.Clean = true
+		shutdown.Message = "the broker completed the controlled shutdown"
+	case terminated.Message == "":
+		// The termination message is only written after the broker process exited
+		shutdown.Message = fmt.Sprintf("the broker has been killed (exit code %d, reason %s) before completing the "+
+			"controlled shutdown within the termination grace period of %d seconds", terminated.ExitCode, terminated.Reason, terminationGracePeriod)
+	default:
+		shutdown.Message = fmt.Sprintf("the broker exited without completing the controlled shutdown: %s", strings.TrimSpace(terminated.Message))
+	}
+	return shutdown
+}
+
+func isShutdownRecorded(recorded, shutdown *v1beta1.BrokerShutdownStatus) bool {
+	return recorded != nil && recorded.Pod == shutdown.Pod && recorded.FinishedAt.Equal(&shutdown.FinishedAt)
+}
+
+// shutdownCondition returns the BrokersShutDownCleanly condition computed from the last recorded shutdown of the brokers
+func shutdownCondition(cluster *v1beta1.KafkaCluster) metav1.Condition {
+	var unclean []string
+	for brokerID, brokerState := range cluster.Status.BrokersState {
+		if brokerState.LastShutdown != nil && !brokerState.LastShutdown.Clean {
+			unclean = append(unclean, brokerID)
+		}
+	}
+	if len(unclean) == 0 {
+		return metav1.Condition{
+			Type:    v1beta1.BrokersShutDownCleanlyCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  v1beta1.ControlledShutdownReason,
+			Message: "the brokers completed the controlled shutdown when they have been terminated last",
+		}
+	}
+	sort.Strings(unclean)
+	return metav1.Condition{
+		Type:               v1beta1.BrokersShutDownCleanlyCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             v1beta1.UncleanShutdownReason,
+		Message:            fmt.Sprintf("brokers %s have been terminated without completing the controlled shutdown", strings.Join(unclean, ", ")),
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestBrokerShutdownStatus(t *testing.T) {
+	finishedAt := metav1.NewTime(time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC))
+	testCases := []struct {
+		testName       string
+		containerState corev1.ContainerStatus
+		expectedClean  bool
+		expectedNil    bool
+		expectedInMsg  string
+	}{
+		{
+			testName:       "running container",
+			containerState: corev1.ContainerStatus{Name: "kafka", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			expectedNil:    true,
+		},
+		{
+			testName: "clean shutdown before the restart of the container",
+			containerState: corev1.ContainerStatus{Name: "kafka", LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 0, Reason: "Completed", Message: "exitCode=143 cleanShutdown=true", FinishedAt: finishedAt,
+			}}},
+			expectedClean: true,
+			expectedInMsg: "completed the controlled shutdown",
+		},
+		{
+			testName: "broker exited without closing the log directories",
+			containerState: corev1.ContainerStatus{Name: "kafka", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 0, Reason: "Completed", Message: "exitCode=1 cleanShutdown=false\n", FinishedAt: finishedAt,
+			}}},
+			expectedInMsg: "exitCode=1 cleanShutdown=false",
+		},
+		{
+			testName: "broker killed at the end of the termination grace period",
+			containerState: corev1.ContainerStatus{Name: "kafka", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 137, Reason: "Error", FinishedAt: finishedAt,
+			}}},
+			expectedInMsg: "termination grace period of 120 seconds",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.testName, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka-0-abcde"},
+				Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "envoy", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+					test.containerState,
+				}},
+			}
+			shutdown := brokerShutdownStatus(pod, v1beta1.DefaultBrokerTerminationGracePeriod)
+			if test.expectedNil {
+				assert.Nil(t, shutdown)
+				return
+			}
+			assert.NotNil(t, shutdown)
+			assert.Equal(t, "kafka-0-abcde", shutdown.Pod)
+			assert.Equal(t, test.expectedClean, shutdown.Clean)
+			assert.Equal(t, finishedAt, shutdown.FinishedAt)
+			assert.Contains(t, shutdown.Message, test.expectedInMsg)
+			assert.True(t, isShutdownRecorded(shutdown.DeepCopy(), shutdown))
+		})
+	}
+}
+
+func TestShutdownCondition(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{Status: v1beta1.KafkaClusterStatus{BrokersState: map[string]v1beta1.BrokerState{
+		"0": {LastShutdown: &v1beta1.BrokerShutdownStatus{Clean: true}},
+		"1": {},
+	}}}
+	condition := shutdownCondition(cluster)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, v1beta1.ControlledShutdownReason, condition.Reason)
+
+	cluster.Status.BrokersState["2"] = v1beta1.BrokerState{LastShutdown: &v1beta1.BrokerShutdownStatus{Clean: false}}
+	cluster.Status.BrokersState["1"] = v1beta1.BrokerState{LastShutdown: &v1beta1.BrokerShutdownStatus{Clean: false}}
+	condition = shutdownCondition(cluster)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, v1beta1.UncleanShutdownReason, condition.Reason)
+	assert.Equal(t, "brokers 1, 2 have been terminated without completing the controlled shutdown", condition.Message)
+}
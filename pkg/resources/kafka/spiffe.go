@@ -0,0 +1,139 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
+)
+
+const (
+	spiffeWorkloadAPIVolume = "spiffe-workload-api"
+	spiffeWorkloadAPIPath   = "/spiffe-workload-api"
+	spiffeSVIDVolume        = "spiffe-svid"
+	// spiffeSVIDPath is where spiffe-helper writes the X.509 SVID of the broker and the assemble-spiffe-stores.sh
+	// script creates the PEM keystore and truststore of the SPIFFE listeners
+	spiffeSVIDPath        = "/var/run/secrets/spiffe.io"
+	spiffeHelperConfigKey = "spiffe-helper.conf"
+	spiffeKeyStoreFile    = "keystore.pem"
+	spiffeTrustStoreFile  = "truststore.pem"
+	pemStoreType          = "PEM"
+)
+
+var (
+	//go:embed assemble-spiffe-stores.sh
+	spiffeStoresScript string
+)
+
+// generateSPIFFEHelperConfig returns the configuration of the spiffe-helper sidecar which keeps the X.509 SVID
+// of the broker and the SPIFFE trust bundle up to date in the SVID volume
+func generateSPIFFEHelperConfig(spiffeConfig *v1beta1.SPIFFEConfig) string {
+	return fmt.Sprintf(`agent_address = "%s/%s"
+cmd = ""
+cmd_args = ""
+cert_dir = "%s"
+daemon_mode = true
+svid_file_name = "svid.pem"
+svid_key_file_name = "svid_key.pem"
+svid_bundle_file_name = "bundle.pem"
+`, spiffeWorkloadAPIPath, spiffeConfig.GetAgentSocketName(), spiffeSVIDPath)
+}
+
+func generateSPIFFEHelperContainer(kafkaClusterSpec v1beta1.KafkaClusterSpec) corev1.Container {
+	spiffeConfig := kafkaClusterSpec.SPIFFEConfig
+	return corev1.Container{
+		Name:  "spiffe-helper",
+		Image: kafkaClusterSpec.GetImage(spiffeConfig.GetHelperImage()),
+		Args:  []string{"-config", "/config/" + spiffeHelperConfigKey},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      brokerConfigMapVolumeMount,
+				MountPath: "/config",
+			},
+			{
+				Name:      spiffeSVIDVolume,
+				MountPath: spiffeSVIDPath,
+			},
+			{
+				Name:      spiffeWorkloadAPIVolume,
+				MountPath: spiffeWorkloadAPIPath,
+				ReadOnly:  true,
+			},
+		},
+		Resources: spiffeConfig.GetHelperResources(),
+	}
+}
+
+func generateSPIFFEVolumes(spiffeConfig *v1beta1.SPIFFEConfig) []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: spiffeWorkloadAPIVolume,
+			VolumeSource: corev1.VolumeSource{
+				CSI: &corev1.CSIVolumeSource{
+					Driver:   spiffeConfig.GetCSIDriver(),
+					ReadOnly: util.BoolPointer(true),
+				},
+			},
+		},
+		{
+			Name: spiffeSVIDVolume,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: corev1.StorageMediumMemory,
+				},
+			},
+		},
+	}
+}
+
+func generateSPIFFEVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      spiffeSVIDVolume,
+		MountPath: spiffeSVIDPath,
+	}
+}
+
+// setSPIFFEListenerStoreConfig sets the keystore and truststore locations of the SPIFFE listener. Setting them again
+// through the admin API makes the brokers reload the stores when the SVID has been rotated.
+func setSPIFFEListenerStoreConfig(config map[string]string, listenerName string) {
+	config[fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, listenerName, kafkautils.KafkaConfigSSLKeyStoreLocation)] = spiffeSVIDPath + "/" + spiffeKeyStoreFile
+	config[fmt.Sprintf("%s.%s.%s", kafkautils.KafkaConfigListenerName, listenerName, kafkautils.KafkaConfigSSLTrustStoreLocation)] = spiffeSVIDPath + "/" + spiffeTrustStoreFile
+}
+
+// rotatedSPIFFEStoreConfig returns the store locations of the SPIFFE listeners of the broker which still present an
+// X.509 SVID past the half of its lifetime, by when the SPIRE agent has rotated it
+func (r *Reconciler) rotatedSPIFFEStoreConfig(brokerId int32, log logr.Logger) map[string]string {
+	config := make(map[string]string)
+	for _, listener := range r.KafkaCluster.Spec.GetSPIFFEListeners() {
+		svid, err := servedListenerCertificate(brokerListenerAddress(r.KafkaCluster, brokerId, listener.ContainerPort))
+		if err != nil {
+			log.V(1).Info("could not check the X.509 SVID of the listener", "listener", listener.Name, "error", err.Error())
+			continue
+		}
+		if time.Now().Before(svid.NotBefore.Add(svid.NotAfter.Sub(svid.NotBefore) / 2)) {
+			continue
+		}
+		setSPIFFEListenerStoreConfig(config, listener.Name)
+	}
+	return config
+}
@@ -0,0 +1,114 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+)
+
+func TestPodWithSPIFFEListener(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			SPIFFEConfig: &v1beta1.SPIFFEConfig{CSIDriver: "csi.example.org"},
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL, SPIFFE: true},
+						UsedForInnerBrokerCommunication: true,
+					},
+					{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "clients", Type: v1beta1.SecurityProtocolSSL},
+					},
+				},
+				SSLSecrets: &v1beta1.SSLSecrets{},
+			},
+		},
+	}
+	r := Reconciler{Reconciler: resources.Reconciler{KafkaCluster: cluster}}
+
+	pod := r.pod(0, &v1beta1.BrokerConfig{}, nil, logr.Discard()).(*corev1.Pod)
+
+	require.Len(t, pod.Spec.Containers, 2)
+	assert.Equal(t, "kafka", pod.Spec.Containers[0].Name)
+	assert.Equal(t, []string{"bash", "-c", spiffeStoresScript + envoySidecarScript}, pod.Spec.Containers[0].Command)
+	assert.Equal(t, "spiffe-helper", pod.Spec.Containers[1].Name)
+	assert.Equal(t, v1beta1.DefaultSPIFFEHelperImage, pod.Spec.Containers[1].Image)
+
+	volumes := make(map[string]corev1.Volume)
+	for _, volume := range pod.Spec.Volumes {
+		volumes[volume.Name] = volume
+	}
+	require.Contains(t, volumes, spiffeWorkloadAPIVolume)
+	assert.Equal(t, "csi.example.org", volumes[spiffeWorkloadAPIVolume].CSI.Driver)
+	assert.Contains(t, volumes, spiffeSVIDVolume)
+	assert.Contains(t, volumes, "listener-clients-certs")
+	assert.NotContains(t, volumes, "listener-internal-certs")
+
+	mountPaths := make(map[string]string)
+	for _, volumeMount := range pod.Spec.Containers[0].VolumeMounts {
+		mountPaths[volumeMount.Name] = volumeMount.MountPath
+	}
+	assert.Equal(t, spiffeSVIDPath, mountPaths[spiffeSVIDVolume])
+	assert.NotContains(t, mountPaths, "listener-internal-certs")
+
+}
+
+func TestRotatedSPIFFEStoreConfig(t *testing.T) {
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			SPIFFEConfig: &v1beta1.SPIFFEConfig{},
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL, SPIFFE: true, ContainerPort: 29092}},
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "clients", Type: v1beta1.SecurityProtocolSSL, ContainerPort: 29093}},
+				},
+			},
+		},
+	}
+	r := Reconciler{Reconciler: resources.Reconciler{KafkaCluster: cluster}}
+
+	var issued time.Time
+	var addresses []string
+	defer func(orig func(string) (*x509.Certificate, error)) { servedListenerCertificate = orig }(servedListenerCertificate)
+	servedListenerCertificate = func(address string) (*x509.Certificate, error) {
+		addresses = append(addresses, address)
+		return &x509.Certificate{NotBefore: issued, NotAfter: issued.Add(time.Hour)}, nil
+	}
+
+	// The SVID presented by the broker is not due for rotation yet
+	issued = time.Now().Add(-10 * time.Minute)
+	assert.Empty(t, r.rotatedSPIFFEStoreConfig(0, logr.Discard()))
+	assert.Equal(t, []string{"kafka-0.kafka.svc.cluster.local:29092"}, addresses)
+
+	// The SVID presented by the broker has been rotated since
+	issued = time.Now().Add(-40 * time.Minute)
+	assert.Equal(t, map[string]string{
+		"listener.name.internal.ssl.keystore.location":   "/var/run/secrets/spiffe.io/keystore.pem",
+		"listener.name.internal.ssl.truststore.location": "/var/run/secrets/spiffe.io/truststore.pem",
+	}, r.rotatedSPIFFEStoreConfig(0, logr.Discard()))
+}
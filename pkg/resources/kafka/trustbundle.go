@@ -0,0 +1,166 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	"github.com/banzaicloud/koperator/pkg/util"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+)
+
+// sslListeners returns the common spec of the listeners using SSL with keystores stored in secrets
+func sslListeners(listenersConfig v1beta1.ListenersConfig) []v1beta1.CommonListenerSpec {
+	var listeners []v1beta1.CommonListenerSpec
+	for _, iListener := range listenersConfig.InternalListeners {
+		if iListener.Type == v1beta1.SecurityProtocolSSL && !iListener.UsesSPIFFE() {
+			listeners = append(listeners, iListener.CommonListenerSpec)
+		}
+	}
+	for _, eListener := range listenersConfig.ExternalListeners {
+		if eListener.Type == v1beta1.SecurityProtocolSSL && !eListener.UsesSPIFFE() {
+			listeners = append(listeners, eListener.CommonListenerSpec)
+		}
+	}
+	return listeners
+}
+
+// reconcileTrustBundle ensures the secret holding the truststores of the SSL listeners and the client certificate
+// extended with the additional trusted CA certificates. The brokers are marked for a rolling restart when the
+// truststores change as they are only loaded at startup.
+func (r *Reconciler) reconcileTrustBundle(ctx context.Context, log logr.Logger) error {
+	if len(r.KafkaCluster.Spec.ListenersConfig.AdditionalTrustedCAs) == 0 {
+		return nil
+	}
+
+	additionalCAs, err := pkicommon.GetAdditionalTrustedCAs(ctx, r.Client, r.KafkaCluster)
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string][]byte)
+	for _, listener := range sslListeners(r.KafkaCluster.Spec.ListenersConfig) {
+		serverSecret, err := getListenerSSLCertSecret(r.Client, listener, r.KafkaCluster.Name, r.KafkaCluster.Namespace)
+		if err != nil {
+			return err
+		}
+		password := serverSecret.Data[v1alpha1.PasswordKey]
+		trustStoreType := listener.TLSPolicy.GetTrustStoreType()
+		caCerts, err := certutil.ParseTrustStoreOfTypeToCaChain(trustStoreType, serverSecret.Data[certutil.TrustStoreFileName(trustStoreType)], password)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "could not parse truststore of listener", "listener", listener.Name)
+		}
+		trustStore, err := certutil.GenerateTrustStoreOfType(trustStoreType, pkicommon.AppendCertificates(caCerts, additionalCAs...), password)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "could not generate truststore of listener", "listener", listener.Name)
+		}
+		data[pkicommon.ListenerTrustStoreKey(listener.Name, trustStoreType)] = trustStore
+	}
+
+	if r.KafkaCluster.Spec.IsClientSSLSecretPresent() {
+		clientSecret := &corev1.Secret{}
+		clientSecretName := pkicommon.ClientSSLCertSecretName(r.KafkaCluster)
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: clientSecretName, Namespace: r.KafkaCluster.Namespace}, clientSecret); err != nil {
+			return errors.WrapIfWithDetails(err, "could not get client secret", "secret", clientSecretName)
+		}
+		password := clientSecret.Data[v1alpha1.PasswordKey]
+		caCerts, err := certutil.ParseTrustStoreToCaChain(clientSecret.Data[v1alpha1.TLSJKSTrustStore], password)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "could not parse truststore of client certificate", "secret", clientSecretName)
+		}
+		trustStore, err := certutil.GenerateTrustStoreJKS(pkicommon.AppendCertificates(caCerts, additionalCAs...), password)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "could not generate truststore of client certificate", "secret", clientSecretName)
+		}
+		data[pkicommon.ClientTrustStoreKey] = trustStore
+	}
+
+	secretName := fmt.Sprintf(pkicommon.TrustBundleSecretTemplate, r.KafkaCluster.Name)
+	current := &corev1.Secret{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: r.KafkaCluster.Namespace}, current)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.WrapIfWithDetails(err, "could not get trust bundle secret", "secret", secretName)
+	}
+	changed := err == nil && !reflect.DeepEqual(current.Data, data)
+
+	desired := &corev1.Secret{
+		ObjectMeta: templates.ObjectMeta(secretName, apiutil.LabelsForKafka(r.KafkaCluster.Name), r.KafkaCluster),
+		Data:       data,
+	}
+	if err := k8sutil.Reconcile(log, r.Client, desired, r.KafkaCluster); err != nil {
+		return errors.WrapIfWithDetails(err, "failed to reconcile resource", "resource", desired.GetObjectKind().GroupVersionKind())
+	}
+
+	if changed {
+		brokerIDs := make([]string, 0, len(r.KafkaCluster.Spec.Brokers))
+		for _, broker := range r.KafkaCluster.Spec.Brokers {
+			brokerIDs = append(brokerIDs, strconv.Itoa(int(broker.Id)))
+		}
+		log.Info("truststores of the listeners changed, brokers need to be restarted")
+		if err := k8sutil.UpdateBrokerStatus(r.Client, brokerIDs, r.KafkaCluster, v1beta1.ConfigOutOfSync, log); err != nil {
+			return errors.WrapIf(err, "could not update broker status")
+		}
+	}
+	return nil
+}
+
+// generateVolumeForListenerTrustBundle returns the volume holding the keystore of the listener and its truststore
+// extended with the additional trusted CA certificates
+func generateVolumeForListenerTrustBundle(commonSpec v1beta1.CommonListenerSpec, clusterName string) corev1.Volume {
+	keyStoreFile := certutil.KeyStoreFileName(commonSpec.TLSPolicy.GetKeyStoreType())
+	trustStoreType := commonSpec.TLSPolicy.GetTrustStoreType()
+	return corev1.Volume{
+		Name: fmt.Sprintf(listenerSSLCertVolumeNameTemplate, commonSpec.Name),
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{
+						Secret: &corev1.SecretProjection{
+							LocalObjectReference: corev1.LocalObjectReference{Name: pkicommon.ListenerServerCertSecretName(clusterName, commonSpec)},
+							Items: []corev1.KeyToPath{
+								{Key: keyStoreFile, Path: keyStoreFile},
+								{Key: v1alpha1.PasswordKey, Path: v1alpha1.PasswordKey},
+							},
+						},
+					},
+					{
+						Secret: &corev1.SecretProjection{
+							LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf(pkicommon.TrustBundleSecretTemplate, clusterName)},
+							Items: []corev1.KeyToPath{
+								{Key: pkicommon.ListenerTrustStoreKey(commonSpec.Name, trustStoreType), Path: certutil.TrustStoreFileName(trustStoreType)},
+							},
+						},
+					},
+				},
+				DefaultMode: util.Int32Pointer(0644),
+			},
+		},
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+)
+
+func TestReconcileTrustBundle(t *testing.T) {
+	clusterCAPEM, _, _, err := certutil.GenerateTestCert()
+	require.NoError(t, err)
+	clusterCA, err := certutil.DecodeCertificate(clusterCAPEM)
+	require.NoError(t, err)
+	corporateCAPEM, _, _, err := certutil.GenerateTestCert()
+	require.NoError(t, err)
+
+	password := []byte("serverpass")
+	trustStore, err := certutil.GenerateTrustStoreJKS([]*x509.Certificate{clusterCA}, password)
+	require.NoError(t, err)
+	pkcs12TrustStore, err := certutil.GenerateTrustStorePKCS12([]*x509.Certificate{clusterCA}, password)
+	require.NoError(t, err)
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL}},
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "plain", Type: v1beta1.SecurityProtocolPlaintext}},
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{
+						Name:                "secure",
+						Type:                v1beta1.SecurityProtocolSSL,
+						ServerSSLCertSecret: &corev1.LocalObjectReference{Name: "secure-server-certificate"},
+						TLSPolicy: &v1beta1.ListenerTLSPolicy{
+							KeyStoreType:   v1beta1.KeyStoreTypePKCS12,
+							TrustStoreType: v1beta1.KeyStoreTypePKCS12,
+						},
+					}},
+				},
+				SSLSecrets: &v1beta1.SSLSecrets{},
+				AdditionalTrustedCAs: []v1beta1.TrustedCASource{{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "corporate-ca"}, Key: "ca.crt"},
+				}},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "kafka-server-certificate", Namespace: "kafka"},
+			Data: map[string][]byte{
+				v1alpha1.TLSJKSKeyStore:   []byte("keystore"),
+				v1alpha1.TLSJKSTrustStore: trustStore,
+				v1alpha1.PasswordKey:      password,
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "secure-server-certificate", Namespace: "kafka"},
+			Data: map[string][]byte{
+				v1alpha1.TLSPKCS12KeyStore:   []byte("keystore"),
+				v1alpha1.TLSPKCS12TrustStore: pkcs12TrustStore,
+				v1alpha1.PasswordKey:         password,
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "kafka-controller", Namespace: "kafka"},
+			Data: map[string][]byte{
+				v1alpha1.TLSJKSKeyStore:   []byte("keystore"),
+				v1alpha1.TLSJKSTrustStore: trustStore,
+				v1alpha1.PasswordKey:      password,
+			},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "corporate-ca", Namespace: "kafka"},
+			Data:       map[string]string{"ca.crt": string(corporateCAPEM)},
+		},
+	).Build()
+
+	r := Reconciler{Reconciler: resources.Reconciler{Client: c, KafkaCluster: cluster}}
+	require.NoError(t, r.reconcileTrustBundle(context.Background(), logr.Discard()))
+
+	bundle := &corev1.Secret{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "kafka-trust-bundle", Namespace: "kafka"}, bundle))
+	assert.Len(t, bundle.Data, 3)
+	for key, storeType := range map[string]v1beta1.KeyStoreType{
+		"internal-truststore.jks": v1beta1.KeyStoreTypeJKS,
+		"client-truststore.jks":   v1beta1.KeyStoreTypeJKS,
+		"secure-truststore.p12":   v1beta1.KeyStoreTypePKCS12,
+	} {
+		caCerts, err := certutil.ParseTrustStoreOfTypeToCaChain(storeType, bundle.Data[key], password)
+		require.NoError(t, err)
+		assert.Len(t, caCerts, 2)
+		assert.True(t, caCerts[0].Equal(clusterCA) || caCerts[1].Equal(clusterCA))
+	}
+
+	volumes := generateVolumesForListenerCerts(cluster.Spec.ListenersConfig, cluster.Name)
+	require.Len(t, volumes, 2)
+	// the stores of the PKCS12 listener keep their format
+	require.NotNil(t, volumes[1].Projected)
+	assert.Equal(t, []corev1.KeyToPath{
+		{Key: v1alpha1.TLSPKCS12KeyStore, Path: v1alpha1.TLSPKCS12KeyStore},
+		{Key: v1alpha1.PasswordKey, Path: v1alpha1.PasswordKey},
+	}, volumes[1].Projected.Sources[0].Secret.Items)
+	assert.Equal(t, []corev1.KeyToPath{{Key: "secure-truststore.p12", Path: v1alpha1.TLSPKCS12TrustStore}}, volumes[1].Projected.Sources[1].Secret.Items)
+
+	require.NotNil(t, volumes[0].Projected)
+	sources := volumes[0].Projected.Sources
+	require.Len(t, sources, 2)
+	assert.Equal(t, "kafka-server-certificate", sources[0].Secret.Name)
+	assert.Equal(t, "kafka-trust-bundle", sources[1].Secret.Name)
+	assert.Equal(t, []corev1.KeyToPath{{Key: "internal-truststore.jks", Path: v1alpha1.TLSJKSTrustStore}}, sources[1].Secret.Items)
+
+	clientVolume := generateVolumeForClientSSLCert(cluster)
+	require.NotNil(t, clientVolume.Projected)
+	sources = clientVolume.Projected.Sources
+	require.Len(t, sources, 3)
+	assert.Equal(t, "kafka-controller", sources[0].Secret.Name)
+	assert.Equal(t, []corev1.KeyToPath{{Key: "client-truststore.jks", Path: v1alpha1.TLSJKSTrustStore}}, sources[2].Secret.Items)
+}
@@ -0,0 +1,73 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// UpgradeHookPhase is the phase of the rolling upgrade when an upgrade hook is run
+type UpgradeHookPhase string
+
+const (
+	// PreUpgradeHookPhase is the phase before the first broker of the rolling upgrade is restarted
+	PreUpgradeHookPhase UpgradeHookPhase = "pre-upgrade"
+	// PostUpgradeHookPhase is the phase after every broker of the rolling upgrade has been restarted
+	PostUpgradeHookPhase UpgradeHookPhase = "post-upgrade"
+
+	upgradeHookJobTemplate = "%s-%s-hook"
+)
+
+func upgradeHookJobName(cluster *v1beta1.KafkaCluster, phase UpgradeHookPhase) string {
+	return fmt.Sprintf(upgradeHookJobTemplate, cluster.GetName(), phase)
+}
+
+// RunUpgradeHook creates the Job of the upgrade hook of the given phase and returns true once it has completed
+// successfully, or when no hook is defined. A failed Job is reported as an error, it is retried when the Job is
+// deleted.
+func RunUpgradeHook(ctx context.Context, c client.Client, log logr.Logger, cluster *v1beta1.KafkaCluster, hook *v1beta1.HookJob, phase UpgradeHookPhase) (bool, error) {
+	return RunHookJob(ctx, c, log, cluster, hook, upgradeHookJobName(cluster, phase),
+		corev1.EnvVar{Name: "KAFKA_UPGRADE_HOOK_PHASE", Value: string(phase)})
+}
+
+// DeleteUpgradeHookJobs removes the Jobs of the upgrade hooks of a finished rolling upgrade, so that the hooks are run
+// again by the next rolling upgrade
+func DeleteUpgradeHookJobs(ctx context.Context, c client.Client, log logr.Logger, cluster *v1beta1.KafkaCluster) error {
+	for _, phase := range []UpgradeHookPhase{PreUpgradeHookPhase, PostUpgradeHookPhase} {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: upgradeHookJobName(cluster, phase), Namespace: cluster.GetNamespace()},
+		}
+		err := c.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "could not delete upgrade hook job", "job", job.GetName())
+		}
+		log.Info("upgrade hook job deleted", "job", job.GetName())
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func TestRunUpgradeHook(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	assert.NoError(t, batchv1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	ctx := context.Background()
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", ContainerPort: 29092},
+						UsedForInnerBrokerCommunication: true,
+					},
+				},
+			},
+		},
+	}
+	hook := &v1beta1.HookJob{
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "check", Image: "check:latest"}},
+			},
+		},
+	}
+
+	completed, err := RunUpgradeHook(ctx, c, logr.Discard(), cluster, nil, PreUpgradeHookPhase)
+	assert.NoError(t, err)
+	assert.True(t, completed, "missing hook must not block the rolling upgrade")
+
+	completed, err = RunUpgradeHook(ctx, c, logr.Discard(), cluster, hook, PreUpgradeHookPhase)
+	assert.NoError(t, err)
+	assert.False(t, completed)
+
+	job := &batchv1.Job{}
+	key := types.NamespacedName{Namespace: "kafka", Name: "kafka-pre-upgrade-hook"}
+	assert.NoError(t, c.Get(ctx, key, job))
+	assert.Equal(t, corev1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+	assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env,
+		corev1.EnvVar{Name: "KAFKA_UPGRADE_HOOK_PHASE", Value: "pre-upgrade"})
+
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"}}
+	assert.NoError(t, c.Status().Update(ctx, job))
+	completed, err = RunUpgradeHook(ctx, c, logr.Discard(), cluster, hook, PreUpgradeHookPhase)
+	assert.Error(t, err)
+	assert.False(t, completed)
+
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	assert.NoError(t, c.Status().Update(ctx, job))
+	completed, err = RunUpgradeHook(ctx, c, logr.Discard(), cluster, hook, PreUpgradeHookPhase)
+	assert.NoError(t, err)
+	assert.True(t, completed)
+
+	assert.NoError(t, DeleteUpgradeHookJobs(ctx, c, logr.Discard(), cluster))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, key, job)))
+}
@@ -0,0 +1,249 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+// VolumeSnapshotGVK is the kind of the CSI volume snapshots, the objects are handled as unstructured so that the
+// operator does not depend on the snapshot client library
+var VolumeSnapshotGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+// VolumeSnapshotListGVK is the kind of the lists of the CSI volume snapshots
+var VolumeSnapshotListGVK = VolumeSnapshotGVK.GroupVersion().WithKind("VolumeSnapshotList")
+
+// volumeSnapshotToCreate is a PersistentVolumeClaim of a broker to be snapshotted
+type volumeSnapshotToCreate struct {
+	brokerID string
+	pvcName  string
+}
+
+// snapshotVolumesBeforeRollingUpgrade snapshots the volumes of every broker before the rolling upgrade is started.
+// The snapshots are named after the generation of the cluster, so that they are created once for the change which
+// has triggered the rolling upgrade. It returns false until the snapshots are ready to use.
+func (r *Reconciler) snapshotVolumesBeforeRollingUpgrade(ctx context.Context, log logr.Logger) (bool, error) {
+	if !r.KafkaCluster.Spec.VolumeSnapshots.IsEnabledBefore(v1beta1.VolumeSnapshotBeforeRollingUpgrade) {
+		return true, nil
+	}
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := r.Client.List(ctx, pvcList, client.InNamespace(r.KafkaCluster.GetNamespace()),
+		client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.GetName()))); err != nil {
+		return false, errors.WrapIf(err, "could not list the persistent volume claims of the brokers")
+	}
+	volumes := make([]volumeSnapshotToCreate, 0, len(pvcList.Items))
+	for _, pvc := range pvcList.Items {
+		if brokerID, ok := pvc.GetLabels()[v1beta1.BrokerIdLabelKey]; ok && pvc.GetDeletionTimestamp().IsZero() {
+			volumes = append(volumes, volumeSnapshotToCreate{brokerID: brokerID, pvcName: pvc.GetName()})
+		}
+	}
+	suffix := fmt.Sprintf("upgrade-%d", r.KafkaCluster.GetGeneration())
+	return r.ensureVolumeSnapshots(ctx, log, v1beta1.VolumeSnapshotBeforeRollingUpgrade, suffix, volumes)
+}
+
+// snapshotVolumesBeforeScaleDown snapshots the data volumes of the pod of the removed broker before the pod and
+// its PersistentVolumeClaims are deleted. It returns false until the snapshots are ready to use.
+func (r *Reconciler) snapshotVolumesBeforeScaleDown(ctx context.Context, log logr.Logger, pod *corev1.Pod) (bool, error) {
+	if !r.KafkaCluster.Spec.VolumeSnapshots.IsEnabledBefore(v1beta1.VolumeSnapshotBeforeScaleDown) {
+		return true, nil
+	}
+	var volumes []volumeSnapshotToCreate
+	for _, volume := range pod.Spec.Volumes {
+		if strings.HasPrefix(volume.Name, kafkaDataVolumeMount) && volume.PersistentVolumeClaim != nil {
+			volumes = append(volumes, volumeSnapshotToCreate{
+				brokerID: pod.Labels[v1beta1.BrokerIdLabelKey],
+				pvcName:  volume.PersistentVolumeClaim.ClaimName,
+			})
+		}
+	}
+	return r.ensureVolumeSnapshots(ctx, log, v1beta1.VolumeSnapshotBeforeScaleDown, "scaledown", volumes)
+}
+
+// ensureVolumeSnapshots creates the missing VolumeSnapshots of the volumes and records them in the status of the
+// cluster. It returns false until every snapshot is ready to use, and an error when a snapshot has failed.
+func (r *Reconciler) ensureVolumeSnapshots(ctx context.Context, log logr.Logger, operation v1beta1.VolumeSnapshotOperation,
+	suffix string, volumes []volumeSnapshotToCreate) (bool, error) {
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].pvcName < volumes[j].pvcName })
+
+	allReady := true
+	references := make([]v1beta1.VolumeSnapshotReference, 0, len(volumes))
+	for _, volume := range volumes {
+		name := fmt.Sprintf("%s-%s", volume.pvcName, suffix)
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(VolumeSnapshotGVK)
+		err := r.Client.Get(ctx, types.NamespacedName{Namespace: r.KafkaCluster.GetNamespace(), Name: name}, snapshot)
+		switch {
+		case apierrors.IsNotFound(err):
+			snapshot = r.volumeSnapshot(name, volume)
+			if err := r.Client.Create(ctx, snapshot); err != nil {
+				return false, errors.WrapIfWithDetails(err, "could not create volume snapshot", "volumeSnapshot", name)
+			}
+			log.Info("volume snapshot created", "volumeSnapshot", name, "pvc", volume.pvcName, "operation", operation)
+			if creationTimestamp := snapshot.GetCreationTimestamp(); creationTimestamp.IsZero() {
+				snapshot.SetCreationTimestamp(metav1.Time{Time: time.Now()})
+			}
+		case err != nil:
+			return false, errors.WrapIfWithDetails(err, "could not get volume snapshot", "volumeSnapshot", name)
+		}
+
+		if message, failed, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); failed && message != "" {
+			return false, errors.NewWithDetails("volume snapshot failed", "volumeSnapshot", name, "error", message)
+		}
+		readyToUse, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+		allReady = allReady && readyToUse
+		references = append(references, v1beta1.VolumeSnapshotReference{
+			Name:                      name,
+			BrokerID:                  volume.brokerID,
+			PersistentVolumeClaimName: volume.pvcName,
+			Operation:                 operation,
+			CreationTime:              snapshot.GetCreationTimestamp(),
+			ReadyToUse:                readyToUse,
+		})
+	}
+
+	if volumeSnapshotsChanged(r.KafkaCluster.Status.VolumeSnapshots, references) {
+		if err := k8sutil.UpdateVolumeSnapshotStatus(r.Client, r.KafkaCluster, references, log); err != nil {
+			return false, errorfactory.New(errorfactory.StatusUpdateError{}, err, "could not update the volume snapshots in the status")
+		}
+	}
+	// The older snapshots are only pruned once the new ones can be restored from
+	if allReady && len(references) > 0 {
+		if err := r.pruneVolumeSnapshots(ctx, log, references); err != nil {
+			return false, err
+		}
+	}
+	return allReady, nil
+}
+
+// pruneVolumeSnapshots deletes the snapshots of each PersistentVolumeClaim apart from the most recent ones, and drops
+// the references of the snapshots which no longer exist from the status of the cluster. The snapshots just created
+// are always kept.
+func (r *Reconciler) pruneVolumeSnapshots(ctx context.Context, log logr.Logger, current []v1beta1.VolumeSnapshotReference) error {
+	snapshotList := &unstructured.UnstructuredList{}
+	snapshotList.SetGroupVersionKind(VolumeSnapshotListGVK)
+	if err := r.Client.List(ctx, snapshotList, client.InNamespace(r.KafkaCluster.GetNamespace()),
+		client.MatchingLabels(apiutil.LabelsForKafka(r.KafkaCluster.GetName()))); err != nil {
+		return errors.WrapIf(err, "could not list the volume snapshots of the brokers")
+	}
+
+	kept := make(map[string]struct{}, len(current))
+	for _, reference := range current {
+		kept[reference.Name] = struct{}{}
+	}
+	snapshotsOfVolumes := make(map[string][]unstructured.Unstructured)
+	for _, snapshot := range snapshotList.Items {
+		pvcName, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		snapshotsOfVolumes[pvcName] = append(snapshotsOfVolumes[pvcName], snapshot)
+	}
+
+	existing := make(map[string]struct{}, len(snapshotList.Items))
+	retained := r.KafkaCluster.Spec.VolumeSnapshots.GetRetained()
+	for _, snapshots := range snapshotsOfVolumes {
+		sort.SliceStable(snapshots, func(i, j int) bool {
+			_, iKept := kept[snapshots[i].GetName()]
+			_, jKept := kept[snapshots[j].GetName()]
+			if iKept != jKept {
+				return iKept
+			}
+			iCreated, jCreated := snapshots[i].GetCreationTimestamp(), snapshots[j].GetCreationTimestamp()
+			if !iCreated.Equal(&jCreated) {
+				return jCreated.Before(&iCreated)
+			}
+			return snapshots[i].GetName() > snapshots[j].GetName()
+		})
+		for i := range snapshots {
+			if _, ok := kept[snapshots[i].GetName()]; ok || i < retained {
+				existing[snapshots[i].GetName()] = struct{}{}
+				continue
+			}
+			if err := r.Client.Delete(ctx, &snapshots[i]); client.IgnoreNotFound(err) != nil {
+				return errors.WrapIfWithDetails(err, "could not delete volume snapshot", "volumeSnapshot", snapshots[i].GetName())
+			}
+			log.Info("volume snapshot pruned", "volumeSnapshot", snapshots[i].GetName())
+		}
+	}
+
+	var removed []string
+	for _, reference := range r.KafkaCluster.Status.VolumeSnapshots {
+		if _, ok := existing[reference.Name]; !ok {
+			removed = append(removed, reference.Name)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	if err := k8sutil.RemoveVolumeSnapshotStatus(r.Client, r.KafkaCluster, removed, log); err != nil {
+		return errorfactory.New(errorfactory.StatusUpdateError{}, err, "could not remove the pruned volume snapshots from the status")
+	}
+	return nil
+}
+
+// volumeSnapshot returns the VolumeSnapshot of the PersistentVolumeClaim. It is not owned by the KafkaCluster, so
+// that it outlives the cluster and the volume.
+func (r *Reconciler) volumeSnapshot(name string, volume volumeSnapshotToCreate) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"source": map[string]interface{}{
+				"persistentVolumeClaimName": volume.pvcName,
+			},
+		},
+	}}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGVK)
+	snapshot.SetName(name)
+	snapshot.SetNamespace(r.KafkaCluster.GetNamespace())
+	snapshot.SetLabels(apiutil.MergeLabels(
+		apiutil.LabelsForKafka(r.KafkaCluster.GetName()),
+		map[string]string{v1beta1.BrokerIdLabelKey: volume.brokerID},
+	))
+	if className := r.KafkaCluster.Spec.VolumeSnapshots.VolumeSnapshotClassName; className != "" {
+		_ = unstructured.SetNestedField(snapshot.Object, className, "spec", "volumeSnapshotClassName")
+	}
+	return snapshot
+}
+
+// volumeSnapshotsChanged returns true when any of the references is missing from or differs in the status
+func volumeSnapshotsChanged(current, references []v1beta1.VolumeSnapshotReference) bool {
+	recorded := make(map[string]v1beta1.VolumeSnapshotReference, len(current))
+	for _, reference := range current {
+		recorded[reference.Name] = reference
+	}
+	for _, reference := range references {
+		previous, ok := recorded[reference.Name]
+		if !ok || previous.ReadyToUse != reference.ReadyToUse || previous.BrokerID != reference.BrokerID ||
+			previous.PersistentVolumeClaimName != reference.PersistentVolumeClaimName || previous.Operation != reference.Operation {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func TestSnapshotVolumesBeforeScaleDown(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(VolumeSnapshotGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(VolumeSnapshotListGVK, &unstructured.UnstructuredList{})
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", Generation: 3},
+		Spec: v1beta1.KafkaClusterSpec{
+			VolumeSnapshots: &v1beta1.VolumeSnapshotConfig{
+				VolumeSnapshotClassName: "csi-snapshots",
+				Before:                  []v1beta1.VolumeSnapshotOperation{v1beta1.VolumeSnapshotBeforeScaleDown},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kafka-2-abcde",
+			Namespace: "kafka",
+			Labels:    apiutil.MergeLabels(apiutil.LabelsForKafka("kafka"), map[string]string{v1beta1.BrokerIdLabelKey: "2"}),
+		},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: kafkaDataVolumeMount + "-0", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "kafka-2-storage-0-xyz"},
+			}},
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+		}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	r := Reconciler{Reconciler: resources.Reconciler{Client: c, KafkaCluster: cluster}}
+
+	// The rolling upgrade is not preceded by snapshots
+	ready, err := r.snapshotVolumesBeforeRollingUpgrade(ctx, logr.Discard())
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	ready, err = r.snapshotVolumesBeforeScaleDown(ctx, logr.Discard(), pod)
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGVK)
+	key := types.NamespacedName{Namespace: "kafka", Name: "kafka-2-storage-0-xyz-scaledown"}
+	require.NoError(t, c.Get(ctx, key, snapshot))
+	className, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	assert.Equal(t, "csi-snapshots", className)
+	source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	assert.Equal(t, "kafka-2-storage-0-xyz", source)
+	assert.Empty(t, snapshot.GetOwnerReferences())
+
+	require.Len(t, cluster.Status.VolumeSnapshots, 1)
+	assert.Equal(t, "kafka-2-storage-0-xyz-scaledown", cluster.Status.VolumeSnapshots[0].Name)
+	assert.Equal(t, "2", cluster.Status.VolumeSnapshots[0].BrokerID)
+	assert.Equal(t, v1beta1.VolumeSnapshotBeforeScaleDown, cluster.Status.VolumeSnapshots[0].Operation)
+	assert.False(t, cluster.Status.VolumeSnapshots[0].ReadyToUse)
+
+	require.NoError(t, unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse"))
+	require.NoError(t, c.Update(ctx, snapshot))
+	ready, err = r.snapshotVolumesBeforeScaleDown(ctx, logr.Discard(), pod)
+	require.NoError(t, err)
+	assert.True(t, ready)
+	require.Len(t, cluster.Status.VolumeSnapshots, 1)
+	assert.True(t, cluster.Status.VolumeSnapshots[0].ReadyToUse)
+
+	// A failed snapshot holds the scale-down back
+	require.NoError(t, unstructured.SetNestedField(snapshot.Object, "volume is busy", "status", "error", "message"))
+	require.NoError(t, c.Update(ctx, snapshot))
+	_, err = r.snapshotVolumesBeforeScaleDown(ctx, logr.Discard(), pod)
+	assert.Error(t, err)
+}
+
+func TestSnapshotVolumesBeforeRollingUpgrade(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(VolumeSnapshotGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(VolumeSnapshotListGVK, &unstructured.UnstructuredList{})
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", Generation: 3},
+		Spec: v1beta1.KafkaClusterSpec{
+			VolumeSnapshots: &v1beta1.VolumeSnapshotConfig{
+				Before: []v1beta1.VolumeSnapshotOperation{v1beta1.VolumeSnapshotBeforeRollingUpgrade},
+			},
+		},
+	}
+	var objects []runtime.Object
+	for _, brokerID := range []string{"0", "1"} {
+		objects = append(objects, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name:      "kafka-" + brokerID + "-storage-0",
+			Namespace: "kafka",
+			Labels:    apiutil.MergeLabels(apiutil.LabelsForKafka("kafka"), map[string]string{v1beta1.BrokerIdLabelKey: brokerID}),
+		}})
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).WithRuntimeObjects(objects...).Build()
+	r := Reconciler{Reconciler: resources.Reconciler{Client: c, KafkaCluster: cluster}}
+
+	ready, err := r.snapshotVolumesBeforeRollingUpgrade(ctx, logr.Discard())
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	// The snapshots are named after the generation which has triggered the rolling upgrade
+	for _, name := range []string{"kafka-0-storage-0-upgrade-3", "kafka-1-storage-0-upgrade-3"} {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(VolumeSnapshotGVK)
+		require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: name}, snapshot))
+		_, found, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+		assert.False(t, found)
+	}
+	require.Len(t, cluster.Status.VolumeSnapshots, 2)
+	assert.Equal(t, v1beta1.VolumeSnapshotBeforeRollingUpgrade, cluster.Status.VolumeSnapshots[1].Operation)
+}
+
+func TestPruneVolumeSnapshots(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(VolumeSnapshotGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(VolumeSnapshotListGVK, &unstructured.UnstructuredList{})
+
+	cluster := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", Generation: 4},
+		Spec: v1beta1.KafkaClusterSpec{
+			VolumeSnapshots: &v1beta1.VolumeSnapshotConfig{
+				Before:   []v1beta1.VolumeSnapshotOperation{v1beta1.VolumeSnapshotBeforeRollingUpgrade},
+				Retained: util.Int32Pointer(2),
+			},
+		},
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Name:      "kafka-0-storage-0",
+		Namespace: "kafka",
+		Labels:    apiutil.MergeLabels(apiutil.LabelsForKafka("kafka"), map[string]string{v1beta1.BrokerIdLabelKey: "0"}),
+	}}
+	objects := []runtime.Object{pvc}
+	// The snapshots of the earlier rolling upgrades, the one of the second generation has been deleted already
+	now := time.Now()
+	for _, generation := range []int{1, 3} {
+		r := Reconciler{Reconciler: resources.Reconciler{KafkaCluster: cluster}}
+		snapshot := r.volumeSnapshot(fmt.Sprintf("kafka-0-storage-0-upgrade-%d", generation),
+			volumeSnapshotToCreate{brokerID: "0", pvcName: "kafka-0-storage-0"})
+		snapshot.SetCreationTimestamp(metav1.Time{Time: now.Add(time.Duration(generation-4) * time.Hour)})
+		objects = append(objects, snapshot)
+	}
+	for _, generation := range []int{1, 2, 3} {
+		cluster.Status.VolumeSnapshots = append(cluster.Status.VolumeSnapshots, v1beta1.VolumeSnapshotReference{
+			Name:                      fmt.Sprintf("kafka-0-storage-0-upgrade-%d", generation),
+			BrokerID:                  "0",
+			PersistentVolumeClaimName: "kafka-0-storage-0",
+			Operation:                 v1beta1.VolumeSnapshotBeforeRollingUpgrade,
+			ReadyToUse:                true,
+		})
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).WithRuntimeObjects(objects...).Build()
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "kafka"}, cluster))
+	r := Reconciler{Reconciler: resources.Reconciler{Client: c, KafkaCluster: cluster}}
+
+	// Nothing is pruned until the new snapshot is ready to use
+	ready, err := r.snapshotVolumesBeforeRollingUpgrade(ctx, logr.Discard())
+	require.NoError(t, err)
+	assert.False(t, ready)
+	assert.Len(t, cluster.Status.VolumeSnapshots, 4)
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGVK)
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: "kafka-0-storage-0-upgrade-4"}, snapshot))
+	require.NoError(t, unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse"))
+	require.NoError(t, c.Update(ctx, snapshot))
+
+	ready, err = r.snapshotVolumesBeforeRollingUpgrade(ctx, logr.Discard())
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	// The new snapshot and the most recent earlier one are retained
+	for name, exists := range map[string]bool{
+		"kafka-0-storage-0-upgrade-1": false,
+		"kafka-0-storage-0-upgrade-3": true,
+		"kafka-0-storage-0-upgrade-4": true,
+	} {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(VolumeSnapshotGVK)
+		err := c.Get(ctx, types.NamespacedName{Namespace: "kafka", Name: name}, snapshot)
+		assert.Equal(t, exists, err == nil, name)
+	}
+	var names []string
+	for _, reference := range cluster.Status.VolumeSnapshots {
+		names = append(names, reference.Name)
+	}
+	assert.Equal(t, []string{"kafka-0-storage-0-upgrade-3", "kafka-0-storage-0-upgrade-4"}, names)
+}
@@ -28,5 +28,20 @@ if [[ -n "$ENVOY_SIDECAR_STATUS" ]]; then
   done
 fi
 touch /var/run/wait/do-not-exit-yet
-/opt/kafka/bin/kafka-server-start.sh /config/broker-config
+BROKER_CONFIG="${KAFKA_BROKER_CONFIG:-/config/broker-config}"
+/opt/kafka/bin/kafka-server-start.sh "$BROKER_CONFIG"
+EXIT_CODE=$?
+# Kafka leaves a marker file in every log directory once the controlled shutdown has been completed, the outcome
+# is reported in the termination message of the container so that the operator can verify the shutdown
+LOG_DIRS=$(sed -n 's/^log\.dirs=//p' "$BROKER_CONFIG" | tr ',' ' ')
+CLEAN_SHUTDOWN=false
+if [[ -n "$LOG_DIRS" ]]; then
+  CLEAN_SHUTDOWN=true
+  for LOG_DIR in $LOG_DIRS; do
+    if [[ ! -f "$LOG_DIR/.kafka_cleanshutdown" ]]; then
+      CLEAN_SHUTDOWN=false
+    fi
+  done
+fi
+echo "exitCode=$EXIT_CODE cleanShutdown=$CLEAN_SHUTDOWN" > /dev/termination-log
 rm /var/run/wait/do-not-exit-yet
@@ -0,0 +1,274 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaexporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+	"github.com/banzaicloud/koperator/pkg/util"
+	clientutil "github.com/banzaicloud/koperator/pkg/util/client"
+	pkicommon "github.com/banzaicloud/koperator/pkg/util/pki"
+)
+
+const (
+	componentName = "kafka_exporter"
+
+	// AppLabel is the value of the app label of the kafka_exporter pods
+	AppLabel = "kafka-exporter"
+	// MetricsPort is the port where kafka_exporter exposes the metrics
+	MetricsPort = 9308
+
+	deploymentNameTemplate = "%s-kafka-exporter"
+	tlsVolumeName          = "client-certs"
+	tlsVolumePath          = "/var/run/secrets/kafka-exporter"
+	saslUsernameEnvName    = "SASL_USERNAME"
+	saslPasswordEnvName    = "SASL_PASSWORD"
+)
+
+// Reconciler implements the Component Reconciler
+type Reconciler struct {
+	resources.Reconciler
+}
+
+// New creates a new reconciler for the kafka_exporter of the Kafka cluster
+func New(client client.Client, cluster *v1beta1.KafkaCluster) *Reconciler {
+	return &Reconciler{
+		Reconciler: resources.Reconciler{
+			Client:       client,
+			KafkaCluster: cluster,
+		},
+	}
+}
+
+// LabelSelector returns the labels of the kafka_exporter pods of the Kafka cluster
+func LabelSelector(kafkaCluster string) map[string]string {
+	return map[string]string{
+		v1beta1.AppLabelKey:     AppLabel,
+		v1beta1.KafkaCRLabelKey: kafkaCluster,
+	}
+}
+
+// Reconcile implements the reconcile logic for the kafka_exporter of the Kafka cluster
+func (r *Reconciler) Reconcile(log logr.Logger) error {
+	log = log.WithValues("component", componentName)
+
+	log.V(1).Info("Reconciling")
+
+	if !r.KafkaCluster.Spec.KafkaExporterConfig.IsEnabled() {
+		for _, o := range []client.Object{
+			&appsv1.Deployment{ObjectMeta: templates.ObjectMeta(r.name(), nil, r.KafkaCluster)},
+			&corev1.Service{ObjectMeta: templates.ObjectMeta(r.name(), nil, r.KafkaCluster)},
+		} {
+			err := r.Client.Delete(context.TODO(), o)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return errorfactory.New(errorfactory.APIFailure{}, err, "deleting resource failed", "name", o.GetName())
+			}
+		}
+		log.V(1).Info("Reconciled")
+		return nil
+	}
+
+	for _, o := range []runtime.Object{r.service(), r.deployment()} {
+		if err := k8sutil.Reconcile(log, r.Client, o, r.KafkaCluster); err != nil {
+			return err
+		}
+	}
+
+	log.V(1).Info("Reconciled")
+
+	return nil
+}
+
+func (r *Reconciler) name() string {
+	return fmt.Sprintf(deploymentNameTemplate, r.KafkaCluster.Name)
+}
+
+func (r *Reconciler) service() runtime.Object {
+	return &corev1.Service{
+		ObjectMeta: templates.ObjectMeta(r.name(), LabelSelector(r.KafkaCluster.Name), r.KafkaCluster),
+		Spec: corev1.ServiceSpec{
+			Selector: LabelSelector(r.KafkaCluster.Name),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "metrics",
+					Port:       MetricsPort,
+					TargetPort: intstr.FromInt(MetricsPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}
+
+// innerListener returns the internal listener used for inner broker communication which kafka_exporter connects to
+func (r *Reconciler) innerListener() v1beta1.InternalListenerConfig {
+	for _, iListener := range r.KafkaCluster.Spec.ListenersConfig.InternalListeners {
+		if iListener.UsedForInnerBrokerCommunication {
+			return iListener
+		}
+	}
+	return r.KafkaCluster.Spec.ListenersConfig.InternalListeners[0]
+}
+
+// args returns the arguments of kafka_exporter with the TLS and SASL settings matching the inner broker listener
+func (r *Reconciler) args() []string {
+	config := r.KafkaCluster.Spec.KafkaExporterConfig
+	args := []string{
+		"--kafka.server=" + clientutil.GenerateKafkaAddress(r.KafkaCluster),
+		fmt.Sprintf("--web.listen-address=:%d", MetricsPort),
+	}
+	if config.TopicFilter != "" {
+		args = append(args, "--topic.filter="+config.TopicFilter)
+	}
+	if config.GroupFilter != "" {
+		args = append(args, "--group.filter="+config.GroupFilter)
+	}
+
+	listenerType := r.innerListener().Type
+	if listenerType.IsSSL() {
+		args = append(args,
+			"--tls.enabled",
+			fmt.Sprintf("--tls.ca-file=%s/%s", tlsVolumePath, v1alpha1.CoreCACertKey),
+			fmt.Sprintf("--tls.cert-file=%s/%s", tlsVolumePath, corev1.TLSCertKey),
+			fmt.Sprintf("--tls.key-file=%s/%s", tlsVolumePath, corev1.TLSPrivateKeyKey),
+		)
+	}
+	if listenerType.IsSasl() && config.SASL != nil {
+		args = append(args,
+			"--sasl.enabled",
+			"--sasl.mechanism="+config.SASL.Mechanism,
+			// the credentials are expanded by Kubernetes from the environment variables of the container
+			fmt.Sprintf("--sasl.username=$(%s)", saslUsernameEnvName),
+			fmt.Sprintf("--sasl.password=$(%s)", saslPasswordEnvName),
+		)
+	}
+	return append(args, config.ExtraArgs...)
+}
+
+func (r *Reconciler) deployment() runtime.Object {
+	config := r.KafkaCluster.Spec.KafkaExporterConfig
+	container := corev1.Container{
+		Name:  "kafka-exporter",
+		Image: r.KafkaCluster.Spec.GetImage(config.GetImage()),
+		Args:  r.args(),
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "metrics",
+				ContainerPort: MetricsPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: "/healthz",
+					Port: intstr.FromInt(MetricsPort),
+				},
+			},
+			InitialDelaySeconds: 5,
+			PeriodSeconds:       10,
+		},
+		Resources: config.GetResources(),
+	}
+
+	var volumes []corev1.Volume
+	listenerType := r.innerListener().Type
+	if listenerType.IsSSL() {
+		volumes = append(volumes, corev1.Volume{
+			Name: tlsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: pkicommon.ClientSSLCertSecretName(r.KafkaCluster),
+					Items: []corev1.KeyToPath{
+						{Key: v1alpha1.CoreCACertKey, Path: v1alpha1.CoreCACertKey},
+						{Key: corev1.TLSCertKey, Path: corev1.TLSCertKey},
+						{Key: corev1.TLSPrivateKeyKey, Path: corev1.TLSPrivateKeyKey},
+					},
+					DefaultMode: util.Int32Pointer(0644),
+				},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      tlsVolumeName,
+			MountPath: tlsVolumePath,
+			ReadOnly:  true,
+		})
+	}
+	if listenerType.IsSasl() && config.SASL != nil {
+		container.Env = []corev1.EnvVar{
+			{
+				Name: saslUsernameEnvName,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: config.SASL.SecretRef, Key: "username"},
+				},
+			},
+			{
+				Name: saslPasswordEnvName,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: config.SASL.SecretRef, Key: "password"},
+				},
+			},
+		}
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: templates.ObjectMeta(
+			r.name(),
+			templates.ObjectMetaLabels(r.KafkaCluster, LabelSelector(r.KafkaCluster.Name)),
+			r.KafkaCluster,
+		),
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: LabelSelector(r.KafkaCluster.Name),
+			},
+			Replicas: util.Int32Pointer(1),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      templates.ObjectMetaLabels(r.KafkaCluster, LabelSelector(r.KafkaCluster.Name)),
+					Annotations: config.Annotations,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: config.GetServiceAccount(),
+					ImagePullSecrets:   r.KafkaCluster.Spec.GetImagePullSecrets(config.ImagePullSecrets),
+					NodeSelector:       config.NodeSelector,
+					Tolerations:        config.Tolerations,
+					Affinity:           config.Affinity,
+					Containers:         []corev1.Container{container},
+					Volumes:            volumes,
+				},
+			},
+		},
+	}
+	if r.KafkaCluster.Spec.IsRestrictedPodSecurityStandard() {
+		k8sutil.ApplyRestrictedPodSecurityStandard(&deployment.Spec.Template.Spec)
+	}
+	return deployment
+}
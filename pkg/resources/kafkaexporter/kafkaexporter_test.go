@@ -0,0 +1,131 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafkaexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func newTestKafkaCluster(listenerType v1beta1.SecurityProtocol) *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", UID: "kafka-uid"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "controller", Type: v1beta1.SecurityProtocolPlaintext, ContainerPort: 29093},
+					},
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: listenerType, ContainerPort: 29092},
+						UsedForInnerBrokerCommunication: true,
+					},
+				},
+			},
+			KafkaExporterConfig: &v1beta1.KafkaExporterConfig{
+				Enabled:     true,
+				GroupFilter: "^app-.*",
+				SASL: &v1beta1.KafkaExporterSASLConfig{
+					Mechanism: "scram-sha512",
+					SecretRef: corev1.LocalObjectReference{Name: "exporter-credentials"},
+				},
+			},
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	testCases := []struct {
+		testName        string
+		listenerType    v1beta1.SecurityProtocol
+		expectedArgs    []string
+		expectedVolumes int
+		expectedEnvs    int
+	}{
+		{
+			testName:     "ssl listener",
+			listenerType: v1beta1.SecurityProtocolSSL,
+			expectedArgs: []string{
+				"--kafka.server=kafka-all-broker.kafka.svc.cluster.local:29092",
+				"--web.listen-address=:9308",
+				"--group.filter=^app-.*",
+				"--tls.enabled",
+				"--tls.ca-file=/var/run/secrets/kafka-exporter/ca.crt",
+				"--tls.cert-file=/var/run/secrets/kafka-exporter/tls.crt",
+				"--tls.key-file=/var/run/secrets/kafka-exporter/tls.key",
+			},
+			expectedVolumes: 1,
+		},
+		{
+			testName:     "sasl_plaintext listener",
+			listenerType: v1beta1.SecurityProtocolSaslPlaintext,
+			expectedArgs: []string{
+				"--kafka.server=kafka-all-broker.kafka.svc.cluster.local:29092",
+				"--web.listen-address=:9308",
+				"--group.filter=^app-.*",
+				"--sasl.enabled",
+				"--sasl.mechanism=scram-sha512",
+				"--sasl.username=$(SASL_USERNAME)",
+				"--sasl.password=$(SASL_PASSWORD)",
+			},
+			expectedEnvs: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			cluster := newTestKafkaCluster(testCase.listenerType)
+			fakeClient := fake.NewClientBuilder().Build()
+
+			require.NoError(t, New(fakeClient, cluster).Reconcile(logr.Discard()))
+
+			deployment := &appsv1.Deployment{}
+			require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "kafka", Name: "kafka-kafka-exporter"}, deployment))
+			podSpec := deployment.Spec.Template.Spec
+			require.Len(t, podSpec.Containers, 1)
+			assert.Equal(t, v1beta1.DefaultKafkaExporterImage, podSpec.Containers[0].Image)
+			assert.Equal(t, testCase.expectedArgs, podSpec.Containers[0].Args)
+			assert.Len(t, podSpec.Volumes, testCase.expectedVolumes)
+			assert.Len(t, podSpec.Containers[0].Env, testCase.expectedEnvs)
+			if testCase.expectedVolumes > 0 {
+				assert.Equal(t, "kafka-controller", podSpec.Volumes[0].Secret.SecretName)
+			}
+
+			service := &corev1.Service{}
+			require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "kafka", Name: "kafka-kafka-exporter"}, service))
+			assert.Equal(t, LabelSelector("kafka"), service.Spec.Selector)
+
+			// the resources are removed when kafka_exporter gets disabled
+			cluster.Spec.KafkaExporterConfig.Enabled = false
+			require.NoError(t, New(fakeClient, cluster).Reconcile(logr.Discard()))
+			err := fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "kafka", Name: "kafka-kafka-exporter"}, &appsv1.Deployment{})
+			assert.True(t, apierrors.IsNotFound(err))
+			err = fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "kafka", Name: "kafka-kafka-exporter"}, &corev1.Service{})
+			assert.True(t, apierrors.IsNotFound(err))
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/errorfactory"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+	"github.com/banzaicloud/koperator/pkg/resources"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+)
+
+const (
+	componentName = "network_policy"
+
+	kafkaNetworkPolicyTemplate         = "%s-kafka"
+	cruiseControlNetworkPolicyTemplate = "%s-cruisecontrol"
+	zooKeeperNetworkPolicyTemplate     = "%s-zookeeper"
+)
+
+// Reconciler implements the Component Reconciler
+type Reconciler struct {
+	resources.Reconciler
+}
+
+// New creates a new reconciler for the NetworkPolicies of the Kafka cluster
+func New(client client.Client, cluster *v1beta1.KafkaCluster) *Reconciler {
+	return &Reconciler{
+		Reconciler: resources.Reconciler{
+			Client:       client,
+			KafkaCluster: cluster,
+		},
+	}
+}
+
+// Reconcile implements the reconcile logic for the NetworkPolicies of the Kafka cluster
+func (r *Reconciler) Reconcile(log logr.Logger) error {
+	log = log.WithValues("component", componentName)
+
+	log.V(1).Info("Reconciling")
+
+	config := r.KafkaCluster.Spec.NetworkPolicyConfig
+	desired := make(map[string]*networkingv1.NetworkPolicy)
+	if config.IsEnabled() {
+		desired[r.kafkaNetworkPolicyName()] = r.kafkaNetworkPolicy()
+		// Cruise Control is deployed by the operator only when no external endpoint is given
+		if r.KafkaCluster.Spec.CruiseControlConfig.CruiseControlEndpoint == "" {
+			desired[r.cruiseControlNetworkPolicyName()] = r.cruiseControlNetworkPolicy()
+		}
+		if config.ZooKeeperPodSelector != nil {
+			desired[r.zooKeeperNetworkPolicyName()] = r.zooKeeperNetworkPolicy()
+		}
+	}
+
+	for _, name := range []string{r.kafkaNetworkPolicyName(), r.cruiseControlNetworkPolicyName(), r.zooKeeperNetworkPolicyName()} {
+		if policy, ok := desired[name]; ok {
+			if err := k8sutil.Reconcile(log, r.Client, policy, r.KafkaCluster); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.deleteNetworkPolicy(name); err != nil {
+			return err
+		}
+	}
+
+	log.V(1).Info("Reconciled")
+
+	return nil
+}
+
+// deleteNetworkPolicy removes the NetworkPolicy which is not needed anymore
+func (r *Reconciler) deleteNetworkPolicy(name string) error {
+	policy := &networkingv1.NetworkPolicy{ObjectMeta: templates.ObjectMeta(name, nil, r.KafkaCluster)}
+	err := r.Client.Delete(context.TODO(), policy)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errorfactory.New(errorfactory.APIFailure{}, err, "deleting resource failed", "kind", "NetworkPolicy", "name", name)
+	}
+	return nil
+}
+
+func (r *Reconciler) kafkaNetworkPolicyName() string {
+	return fmt.Sprintf(kafkaNetworkPolicyTemplate, r.KafkaCluster.GetName())
+}
+
+func (r *Reconciler) cruiseControlNetworkPolicyName() string {
+	return fmt.Sprintf(cruiseControlNetworkPolicyTemplate, r.KafkaCluster.GetName())
+}
+
+func (r *Reconciler) zooKeeperNetworkPolicyName() string {
+	return fmt.Sprintf(zooKeeperNetworkPolicyTemplate, r.KafkaCluster.GetName())
+}
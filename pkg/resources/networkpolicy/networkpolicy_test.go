@@ -0,0 +1,98 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+func newTestKafkaCluster() *v1beta1.KafkaCluster {
+	return &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka", UID: "kafka-uid"},
+		Spec: v1beta1.KafkaClusterSpec{
+			ZKAddresses: []string{"zookeeper-0.zookeeper:2181", "zookeeper-1.zookeeper:2182"},
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", ContainerPort: 29092},
+						UsedForInnerBrokerCommunication: true,
+					},
+					{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "clients", ContainerPort: 29093},
+					},
+				},
+			},
+			NetworkPolicyConfig: &v1beta1.NetworkPolicyConfig{
+				Enabled: true,
+				ListenerPeers: map[string][]networkingv1.NetworkPolicyPeer{
+					"clients": {{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "producer"}}}},
+				},
+				ZooKeeperPodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "zookeeper"}},
+			},
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	cluster := newTestKafkaCluster()
+	fakeClient := fake.NewClientBuilder().Build()
+	r := New(fakeClient, cluster)
+
+	require.NoError(t, r.Reconcile(logr.Discard()))
+
+	kafkaPolicy := &networkingv1.NetworkPolicy{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "kafka", Name: "kafka-kafka"}, kafkaPolicy))
+	assert.Equal(t, map[string]string{"app": "kafka", "kafka_cr": "kafka"}, kafkaPolicy.Spec.PodSelector.MatchLabels)
+	require.Len(t, kafkaPolicy.Spec.Ingress, 3)
+	// Listeners without peers are not restricted
+	assert.Equal(t, 29092, kafkaPolicy.Spec.Ingress[0].Ports[0].Port.IntValue())
+	assert.Empty(t, kafkaPolicy.Spec.Ingress[0].From)
+	// Brokers, Cruise Control, the operator and the listener peers can reach the restricted listener
+	assert.Equal(t, 29093, kafkaPolicy.Spec.Ingress[1].Ports[0].Port.IntValue())
+	assert.Len(t, kafkaPolicy.Spec.Ingress[1].From, 4)
+	assert.Equal(t, map[string]string{"app": "producer"}, kafkaPolicy.Spec.Ingress[1].From[3].PodSelector.MatchLabels)
+	assert.Equal(t, metricsPort, kafkaPolicy.Spec.Ingress[2].Ports[0].Port.IntValue())
+
+	ccPolicy := &networkingv1.NetworkPolicy{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "kafka", Name: "kafka-cruisecontrol"}, ccPolicy))
+	assert.Equal(t, cruiseControlPort, ccPolicy.Spec.Ingress[0].Ports[0].Port.IntValue())
+	assert.Equal(t, cluster.Spec.NetworkPolicyConfig.GetOperatorPeers(), ccPolicy.Spec.Ingress[0].From)
+
+	zkPolicy := &networkingv1.NetworkPolicy{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "kafka", Name: "kafka-zookeeper"}, zkPolicy))
+	assert.Equal(t, map[string]string{"app": "zookeeper"}, zkPolicy.Spec.PodSelector.MatchLabels)
+	require.Len(t, zkPolicy.Spec.Ingress[0].Ports, 2)
+	assert.Equal(t, 2181, zkPolicy.Spec.Ingress[0].Ports[0].Port.IntValue())
+	assert.Equal(t, 2182, zkPolicy.Spec.Ingress[0].Ports[1].Port.IntValue())
+
+	// Disabling the generation removes the NetworkPolicies
+	cluster.Spec.NetworkPolicyConfig.Enabled = false
+	require.NoError(t, r.Reconcile(logr.Discard()))
+
+	policies := &networkingv1.NetworkPolicyList{}
+	require.NoError(t, fakeClient.List(context.Background(), policies))
+	assert.Empty(t, policies.Items)
+}
@@ -0,0 +1,203 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	apiutil "github.com/banzaicloud/koperator/api/util"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/resources/kafkaexporter"
+	"github.com/banzaicloud/koperator/pkg/resources/templates"
+)
+
+const (
+	metricsPort            = 9020
+	cruiseControlPort      = 8090
+	defaultZooKeeperPort   = 2181
+	envoyIngressAppLabel   = "envoyingress"
+	cruiseControlAppLabel  = "cruisecontrol"
+	networkPolicyAppLabel  = "kafka-networkpolicy"
+	jmxPortEnvironmentName = "JMX_PORT"
+)
+
+func labelsForNetworkPolicy(crName string) map[string]string {
+	return map[string]string{v1beta1.AppLabelKey: networkPolicyAppLabel, v1beta1.KafkaCRLabelKey: crName}
+}
+
+func podSelectorPeer(labels map[string]string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: labels}}
+}
+
+func (r *Reconciler) brokerLabels() map[string]string {
+	return apiutil.LabelsForKafka(r.KafkaCluster.GetName())
+}
+
+func (r *Reconciler) cruiseControlLabels() map[string]string {
+	return map[string]string{v1beta1.AppLabelKey: cruiseControlAppLabel, v1beta1.KafkaCRLabelKey: r.KafkaCluster.GetName()}
+}
+
+// clusterPeers returns the peers which need to reach the brokers regardless of the restrictions of the listeners
+func (r *Reconciler) clusterPeers() []networkingv1.NetworkPolicyPeer {
+	peers := []networkingv1.NetworkPolicyPeer{
+		podSelectorPeer(r.brokerLabels()),
+		podSelectorPeer(r.cruiseControlLabels()),
+	}
+	if r.KafkaCluster.Spec.KafkaExporterConfig.IsEnabled() {
+		peers = append(peers, podSelectorPeer(kafkaexporter.LabelSelector(r.KafkaCluster.GetName())))
+	}
+	return append(peers, r.KafkaCluster.Spec.NetworkPolicyConfig.GetOperatorPeers()...)
+}
+
+func tcpPorts(ports ...int32) []networkingv1.NetworkPolicyPort {
+	ret := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for _, port := range ports {
+		protocol := corev1.ProtocolTCP
+		p := intstr.FromInt(int(port))
+		ret = append(ret, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &p})
+	}
+	return ret
+}
+
+// listenerRule returns the ingress rule of the listener. Clients are not restricted unless peers are given for the listener.
+func (r *Reconciler) listenerRule(name string, port int32, extraPeers ...networkingv1.NetworkPolicyPeer) networkingv1.NetworkPolicyIngressRule {
+	rule := networkingv1.NetworkPolicyIngressRule{Ports: tcpPorts(port)}
+	if peers, ok := r.KafkaCluster.Spec.NetworkPolicyConfig.ListenerPeers[name]; ok {
+		rule.From = append(r.clusterPeers(), extraPeers...)
+		rule.From = append(rule.From, peers...)
+	}
+	return rule
+}
+
+// metricsRule returns the ingress rule of the metrics endpoints which are not restricted unless metrics peers are given
+func (r *Reconciler) metricsRule(ports ...int32) networkingv1.NetworkPolicyIngressRule {
+	rule := networkingv1.NetworkPolicyIngressRule{Ports: tcpPorts(ports...)}
+	if peers := r.KafkaCluster.Spec.NetworkPolicyConfig.MetricsPeers; len(peers) > 0 {
+		rule.From = append(rule.From, peers...)
+	}
+	return rule
+}
+
+func (r *Reconciler) jmxPort() (int32, bool) {
+	for _, envVar := range r.KafkaCluster.Spec.Envs {
+		if envVar.Name == jmxPortEnvironmentName {
+			port, err := strconv.ParseInt(envVar.Value, 10, 32)
+			if err != nil {
+				return 0, false
+			}
+			return int32(port), true
+		}
+	}
+	return 0, false
+}
+
+func (r *Reconciler) kafkaNetworkPolicy() *networkingv1.NetworkPolicy {
+	var rules []networkingv1.NetworkPolicyIngressRule
+
+	for _, iListener := range r.KafkaCluster.Spec.ListenersConfig.InternalListeners {
+		rules = append(rules, r.listenerRule(iListener.Name, iListener.ContainerPort))
+	}
+	// External listeners are reached through the ingress controller
+	envoy := podSelectorPeer(map[string]string{v1beta1.AppLabelKey: envoyIngressAppLabel, v1beta1.KafkaCRLabelKey: r.KafkaCluster.GetName()})
+	for _, eListener := range r.KafkaCluster.Spec.ListenersConfig.ExternalListeners {
+		rules = append(rules, r.listenerRule(eListener.Name, eListener.ContainerPort, envoy))
+	}
+
+	metricsPorts := []int32{metricsPort}
+	if port, ok := r.jmxPort(); ok {
+		metricsPorts = append(metricsPorts, port)
+	}
+	rules = append(rules, r.metricsRule(metricsPorts...))
+
+	if len(r.KafkaCluster.Spec.AdditionalPorts) > 0 {
+		additionalPorts := make([]int32, 0, len(r.KafkaCluster.Spec.AdditionalPorts))
+		for _, port := range r.KafkaCluster.Spec.AdditionalPorts {
+			additionalPorts = append(additionalPorts, port.ContainerPort)
+		}
+		rules = append(rules, networkingv1.NetworkPolicyIngressRule{Ports: tcpPorts(additionalPorts...)})
+	}
+
+	return r.networkPolicy(r.kafkaNetworkPolicyName(), r.brokerLabels(), rules)
+}
+
+func (r *Reconciler) cruiseControlNetworkPolicy() *networkingv1.NetworkPolicy {
+	rules := []networkingv1.NetworkPolicyIngressRule{
+		{
+			Ports: tcpPorts(cruiseControlPort),
+			From:  r.KafkaCluster.Spec.NetworkPolicyConfig.GetOperatorPeers(),
+		},
+		r.metricsRule(metricsPort),
+	}
+	return r.networkPolicy(r.cruiseControlNetworkPolicyName(), r.cruiseControlLabels(), rules)
+}
+
+// zooKeeperPorts returns the client ports of ZooKeeper used in the connection string of the Kafka cluster
+func (r *Reconciler) zooKeeperPorts() []int32 {
+	ports := make(map[int32]struct{})
+	for _, address := range r.KafkaCluster.Spec.ZKAddresses {
+		port := int32(defaultZooKeeperPort)
+		if idx := strings.LastIndex(address, ":"); idx >= 0 {
+			if p, err := strconv.ParseInt(address[idx+1:], 10, 32); err == nil {
+				port = int32(p)
+			}
+		}
+		ports[port] = struct{}{}
+	}
+	if len(ports) == 0 {
+		ports[defaultZooKeeperPort] = struct{}{}
+	}
+	ret := make([]int32, 0, len(ports))
+	for port := range ports {
+		ret = append(ret, port)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret
+}
+
+func (r *Reconciler) zooKeeperNetworkPolicy() *networkingv1.NetworkPolicy {
+	zooKeeperPodSelector := r.KafkaCluster.Spec.NetworkPolicyConfig.ZooKeeperPodSelector
+	rules := []networkingv1.NetworkPolicyIngressRule{
+		{
+			Ports: tcpPorts(r.zooKeeperPorts()...),
+			From:  r.clusterPeers(),
+		},
+		{
+			// The members of the ZooKeeper ensemble need to reach each other on the quorum and leader election ports
+			From: []networkingv1.NetworkPolicyPeer{{PodSelector: zooKeeperPodSelector.DeepCopy()}},
+		},
+	}
+
+	policy := r.networkPolicy(r.zooKeeperNetworkPolicyName(), nil, rules)
+	policy.Spec.PodSelector = *zooKeeperPodSelector.DeepCopy()
+	return policy
+}
+
+func (r *Reconciler) networkPolicy(name string, podLabels map[string]string, rules []networkingv1.NetworkPolicyIngressRule) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: templates.ObjectMeta(name, labelsForNetworkPolicy(r.KafkaCluster.GetName()), r.KafkaCluster),
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: podLabels},
+			Ingress:     rules,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
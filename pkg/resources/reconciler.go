@@ -17,6 +17,7 @@ package resources
 import (
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/banzaicloud/koperator/api/v1beta1"
@@ -28,10 +29,12 @@ import (
 // - cached client : split client reading cached/watched resources from informers and writing to api-server
 // - direct client : to read non-watched resources
 // - KafkaCluster CR
+// - event recorder : to report the events of the KafkaCluster, no events are emitted when it is nil
 type Reconciler struct {
 	client.Client
 	DirectClient client.Reader
 	KafkaCluster *v1beta1.KafkaCluster
+	Recorder     record.EventRecorder
 }
 
 // ComponentReconciler describes the Reconcile method
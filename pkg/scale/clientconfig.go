@@ -0,0 +1,110 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+// clientTransportConfig returns the configuration of the requests sent to the Cruise Control of the KafkaCluster,
+// or nil when the requests are sent through the default transport
+func clientTransportConfig(ctx context.Context, reader runtimeClient.Reader, kafkaCluster *v1beta1.KafkaCluster) (*transportConfig, error) {
+	ccConfig := kafkaCluster.Spec.CruiseControlConfig
+	if ccConfig.ClientTLS == nil && ccConfig.HTTPClient == nil {
+		return nil, nil
+	}
+	config := &transportConfig{}
+
+	if ccConfig.ClientTLS != nil {
+		tlsConfig, err := util.GetClientTLSConfig(reader, k8stypes.NamespacedName{
+			Namespace: kafkaCluster.GetNamespace(),
+			Name:      ccConfig.GetClientTLSSecretName(kafkaCluster.GetName()),
+		})
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not get the client certificate of the operator for Cruise Control")
+		}
+		config.TLSConfig = tlsConfig
+	}
+
+	httpClient := ccConfig.HTTPClient
+	if httpClient == nil {
+		return config, nil
+	}
+
+	proxyURL := httpClient.ProxyURL
+	if httpClient.ProxyURLSecretRef != nil {
+		value, err := secretKeyValue(ctx, reader, kafkaCluster.GetNamespace(), httpClient.ProxyURLSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		proxyURL = value
+	}
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			// The URL is not part of the error as it may contain credentials
+			return nil, errors.New("could not parse the proxy URL of Cruise Control")
+		}
+		config.ProxyURL = u
+	}
+
+	if len(httpClient.Headers) > 0 || httpClient.BearerTokenSecretRef != nil {
+		config.Headers = make(http.Header, len(httpClient.Headers)+1)
+	}
+	for _, header := range httpClient.Headers {
+		value := header.Value
+		if header.ValueFrom != nil {
+			v, err := secretKeyValue(ctx, reader, kafkaCluster.GetNamespace(), header.ValueFrom)
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		}
+		config.Headers.Set(header.Name, value)
+	}
+	if httpClient.BearerTokenSecretRef != nil {
+		token, err := secretKeyValue(ctx, reader, kafkaCluster.GetNamespace(), httpClient.BearerTokenSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		config.Headers.Set("Authorization", "Bearer "+token)
+	}
+	return config, nil
+}
+
+func secretKeyValue(ctx context.Context, reader runtimeClient.Reader, namespace string, selector *corev1.SecretKeySelector) (string, error) {
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, k8stypes.NamespacedName{Namespace: namespace, Name: selector.Name}, secret); err != nil {
+		return "", errors.WrapIfWithDetails(err, "could not get secret referenced by the HTTP client configuration of Cruise Control",
+			"secret", selector.Name)
+	}
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		return "", errors.NewWithDetails("key not found in secret referenced by the HTTP client configuration of Cruise Control",
+			"secret", selector.Name, "key", selector.Key)
+	}
+	return strings.TrimSpace(string(value)), nil
+}
@@ -0,0 +1,100 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+)
+
+const (
+	correlationIDKey = "correlation-id"
+	requestReasonKey = "reason"
+)
+
+var correlationIDPattern = regexp.MustCompile(correlationIDKey + `=(\S+)`)
+
+type requestTagContextKey struct{}
+
+// RequestTag identifies the object on behalf of which the requests are sent to Cruise Control. It is added to the reason
+// of every request so that the Cruise Control logs and user tasks can be correlated with the custom resources.
+type RequestTag struct {
+	// CorrelationID is the UID of the custom resource which initiated the request
+	CorrelationID string
+	// Reason is a short description of why the request is sent
+	Reason string
+}
+
+// String returns the representation of the RequestTag used in the reason of the Cruise Control requests
+func (t RequestTag) String() string {
+	var parts []string
+	if t.CorrelationID != "" {
+		parts = append(parts, fmt.Sprintf("%s=%s", correlationIDKey, t.CorrelationID))
+	}
+	if t.Reason != "" {
+		parts = append(parts, fmt.Sprintf("%s=%s", requestReasonKey, t.Reason))
+	}
+	return strings.Join(parts, " ")
+}
+
+// WithRequestTag returns a copy of the context which makes the Cruise Control requests sent with it tagged by the RequestTag
+func WithRequestTag(ctx context.Context, tag RequestTag) context.Context {
+	return context.WithValue(ctx, requestTagContextKey{}, tag)
+}
+
+// RequestTagFromContext returns the RequestTag of the context
+func RequestTagFromContext(ctx context.Context) (RequestTag, bool) {
+	tag, ok := ctx.Value(requestTagContextKey{}).(RequestTag)
+	return tag, ok
+}
+
+// requestReason returns the reason of the Cruise Control request extended with the RequestTag of the context
+func requestReason(ctx context.Context, reason string) string {
+	tag, ok := RequestTagFromContext(ctx)
+	if !ok || tag.String() == "" {
+		return reason
+	}
+	if reason == "" {
+		return tag.String()
+	}
+	return fmt.Sprintf("%s %s", reason, tag)
+}
+
+// CorrelationIDFromRequestURL returns the correlation ID which the Cruise Control request has been tagged with.
+// The request URL of the user tasks returned by Cruise Control may be prefixed with the HTTP method.
+func CorrelationIDFromRequestURL(requestURL string) string {
+	idx := strings.Index(requestURL, "?")
+	if idx < 0 {
+		return ""
+	}
+	query, err := url.ParseQuery(requestURL[idx+1:])
+	if err != nil {
+		return ""
+	}
+	if match := correlationIDPattern.FindStringSubmatch(query.Get(paramReason)); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// tagRequest adds the RequestTag of the context to the reason of the Cruise Control request
+func tagRequest(ctx context.Context, req *types.GenericRequestWithReason) {
+	req.Reason = requestReason(ctx, req.Reason)
+}
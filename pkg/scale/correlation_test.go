@@ -0,0 +1,74 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestReason(t *testing.T) {
+	testCases := []struct {
+		testName string
+		tag      *RequestTag
+		reason   string
+		expected string
+	}{
+		{
+			testName: "untagged request",
+			reason:   "scheduled rebalance",
+			expected: "scheduled rebalance",
+		},
+		{
+			testName: "tagged request",
+			tag:      &RequestTag{CorrelationID: "4f0b7c3e", Reason: "rebalance"},
+			expected: "correlation-id=4f0b7c3e reason=rebalance",
+		},
+		{
+			testName: "tagged request with reason",
+			tag:      &RequestTag{CorrelationID: "4f0b7c3e", Reason: "rebalance"},
+			reason:   "scheduled rebalance",
+			expected: "scheduled rebalance correlation-id=4f0b7c3e reason=rebalance",
+		},
+		{
+			testName: "empty tag",
+			tag:      &RequestTag{},
+			reason:   "scheduled rebalance",
+			expected: "scheduled rebalance",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			ctx := context.Background()
+			if testCase.tag != nil {
+				ctx = WithRequestTag(ctx, *testCase.tag)
+			}
+			assert.Equal(t, testCase.expected, requestReason(ctx, testCase.reason))
+		})
+	}
+}
+
+func TestCorrelationIDFromRequestURL(t *testing.T) {
+	query := url.Values{paramReason: []string{"scheduled rebalance correlation-id=4f0b7c3e reason=rebalance"}}.Encode()
+	assert.Equal(t, "4f0b7c3e", CorrelationIDFromRequestURL("POST /kafkacruisecontrol/rebalance?"+query))
+
+	query = url.Values{paramReason: []string{"scheduled rebalance"}}.Encode()
+	assert.Equal(t, "", CorrelationIDFromRequestURL("POST /kafkacruisecontrol/rebalance?"+query))
+	assert.Equal(t, "", CorrelationIDFromRequestURL("GET /kafkacruisecontrol/state"))
+}
@@ -0,0 +1,106 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"net/http"
+	"strings"
+
+	"emperror.dev/errors"
+)
+
+// ErrorReason describes the cause of a failed Cruise Control request.
+type ErrorReason string
+
+const (
+	// ErrorReasonInvalidParameter means that the request could not be created or it has been rejected by Cruise Control
+	// due to its parameters
+	ErrorReasonInvalidParameter ErrorReason = "InvalidParameter"
+	// ErrorReasonUnsupportedFeature means that the request needs a newer Cruise Control version
+	ErrorReasonUnsupportedFeature ErrorReason = "UnsupportedFeature"
+	// ErrorReasonOngoingExecution means that Cruise Control rejected the request as it is executing another task
+	ErrorReasonOngoingExecution ErrorReason = "OngoingExecution"
+	// ErrorReasonServerError means that Cruise Control failed to serve the request
+	ErrorReasonServerError ErrorReason = "ServerError"
+	// ErrorReasonUnavailable means that Cruise Control could not be reached
+	ErrorReasonUnavailable ErrorReason = "Unavailable"
+	// ErrorReasonUnauthorized means that Cruise Control rejected the credentials of the request, which may succeed
+	// once the credentials or the access rules are fixed
+	ErrorReasonUnauthorized ErrorReason = "Unauthorized"
+)
+
+// ongoingExecutionMessages are the parts of the Cruise Control error messages returned when a new task cannot be
+// started while another one is being executed
+var ongoingExecutionMessages = []string{
+	"ongoing execution",
+	"ongoing proposal execution",
+	"already in progress",
+}
+
+// InvalidParameterError is returned when the parameters of the operation cannot be turned into a Cruise Control request.
+type InvalidParameterError struct {
+	Err error
+}
+
+func (e InvalidParameterError) Error() string {
+	return "invalid Cruise Control request parameter: " + e.Err.Error()
+}
+
+func (e InvalidParameterError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifiedError is the classification of the error of a failed Cruise Control request.
+type ClassifiedError struct {
+	Reason ErrorReason
+	// Terminal is true when sending the same request again would fail the same way
+	Terminal bool
+}
+
+// ClassifyError returns whether the failed Cruise Control request can be retried based on the error and the HTTP status
+// code of the response. Errors which cannot be recognized are handled as transient ones.
+func ClassifyError(err error, statusCode int) ClassifiedError {
+	switch {
+	case err == nil:
+		return ClassifiedError{}
+	case IsUnsupportedFeatureError(err):
+		return ClassifiedError{Reason: ErrorReasonUnsupportedFeature, Terminal: true}
+	case errors.As(err, &InvalidParameterError{}):
+		return ClassifiedError{Reason: ErrorReasonInvalidParameter, Terminal: true}
+	case isOngoingExecutionError(err):
+		return ClassifiedError{Reason: ErrorReasonOngoingExecution}
+	case statusCode == 0:
+		return ClassifiedError{Reason: ErrorReasonUnavailable}
+	case statusCode == http.StatusTooManyRequests || statusCode == http.StatusConflict ||
+		statusCode == http.StatusRequestTimeout:
+		return ClassifiedError{Reason: ErrorReasonServerError}
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ClassifiedError{Reason: ErrorReasonUnauthorized}
+	case statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError:
+		return ClassifiedError{Reason: ErrorReasonInvalidParameter, Terminal: true}
+	default:
+		return ClassifiedError{Reason: ErrorReasonServerError}
+	}
+}
+
+func isOngoingExecutionError(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, ongoing := range ongoingExecutionMessages {
+		if strings.Contains(message, ongoing) {
+			return true
+		}
+	}
+	return false
+}
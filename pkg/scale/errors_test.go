@@ -0,0 +1,95 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	testCases := []struct {
+		testName   string
+		err        error
+		statusCode int
+		expected   ClassifiedError
+	}{
+		{
+			testName: "no error",
+		},
+		{
+			testName: "invalid parameter",
+			err:      InvalidParameterError{Err: errors.New("could not parse goals")},
+			expected: ClassifiedError{Reason: ErrorReasonInvalidParameter, Terminal: true},
+		},
+		{
+			testName: "wrapped unsupported feature",
+			err:      errors.WrapIf(UnsupportedFeatureError{Feature: FeatureRebalanceDisk, Version: "2.4.0", RequiredVersion: "2.5.0"}, "request failed"),
+			expected: ClassifiedError{Reason: ErrorReasonUnsupportedFeature, Terminal: true},
+		},
+		{
+			testName:   "ongoing execution",
+			err:        fmt.Errorf("HTTP request failed: %w", types.APIError{ErrorMessage: "Cannot start a new execution while there is an ongoing execution."}),
+			statusCode: http.StatusInternalServerError,
+			expected:   ClassifiedError{Reason: ErrorReasonOngoingExecution},
+		},
+		{
+			testName:   "rejected by Cruise Control",
+			err:        fmt.Errorf("HTTP request failed: %w", types.APIError{ErrorMessage: "Unrecognized parameter: foo"}),
+			statusCode: http.StatusBadRequest,
+			expected:   ClassifiedError{Reason: ErrorReasonInvalidParameter, Terminal: true},
+		},
+		{
+			testName:   "unauthorized",
+			err:        errors.New("HTTP request failed"),
+			statusCode: http.StatusUnauthorized,
+			expected:   ClassifiedError{Reason: ErrorReasonUnauthorized},
+		},
+		{
+			testName:   "forbidden",
+			err:        errors.New("HTTP request failed"),
+			statusCode: http.StatusForbidden,
+			expected:   ClassifiedError{Reason: ErrorReasonUnauthorized},
+		},
+		{
+			testName:   "too many requests",
+			err:        errors.New("HTTP request failed"),
+			statusCode: http.StatusTooManyRequests,
+			expected:   ClassifiedError{Reason: ErrorReasonServerError},
+		},
+		{
+			testName:   "server error",
+			err:        errors.New("HTTP request failed"),
+			statusCode: http.StatusServiceUnavailable,
+			expected:   ClassifiedError{Reason: ErrorReasonServerError},
+		},
+		{
+			testName: "unreachable",
+			err:      errors.New("sending HTTP request failed: connection refused"),
+			expected: ClassifiedError{Reason: ErrorReasonUnavailable},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, ClassifyError(testCase.err, testCase.statusCode))
+		})
+	}
+}
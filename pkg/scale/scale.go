@@ -18,11 +18,16 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"emperror.dev/errors"
+	"github.com/Masterminds/semver/v3"
 	"github.com/go-logr/logr"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/banzaicloud/go-cruise-control/pkg/api"
 	"github.com/banzaicloud/go-cruise-control/pkg/client"
@@ -35,11 +40,18 @@ import (
 const (
 	// Constants for the Cruise Control operations parameters
 	// Check for more details: https://github.com/linkedin/cruise-control/wiki/REST-APIs
-	paramBrokerID       = "brokerid"
-	paramExcludeDemoted = "exclude_recently_demoted_brokers"
-	paramExcludeRemoved = "exclude_recently_removed_brokers"
-	paramDestbrokerIDs  = "destination_broker_ids"
-	paramRebalanceDisk  = "rebalance_disk"
+	paramBrokerID                     = v1alpha1.ParamBrokerID
+	paramExcludeDemoted               = v1alpha1.ParamExcludeDemoted
+	paramExcludeRemoved               = v1alpha1.ParamExcludeRemoved
+	paramDestbrokerIDs                = v1alpha1.ParamDestbrokerIDs
+	paramRebalanceDisk                = v1alpha1.ParamRebalanceDisk
+	paramExcludedTopics               = v1alpha1.ParamExcludedTopics
+	paramGoals                        = v1alpha1.ParamGoals
+	paramConcurrentPartitionMovements = v1alpha1.ParamConcurrentPartitionMovements
+	paramConcurrentLeaderMovements    = v1alpha1.ParamConcurrentLeaderMovements
+	paramDryRun                       = v1alpha1.ParamDryRun
+	paramSkipHardGoalCheck            = v1alpha1.ParamSkipHardGoalCheck
+	paramReason                       = v1alpha1.ParamReason
 	// Cruise Control API returns NullPointerException when a broker storage capacity calculations are missing
 	// from the Cruise Control configurations
 	nullPointerExceptionErrString = "NullPointerException"
@@ -50,34 +62,91 @@ const (
 var (
 	newCruiseControlScaler   = createNewDefaultCruiseControlScaler
 	addBrokerSupportedParams = map[string]struct{}{
-		paramBrokerID:       {},
-		paramExcludeDemoted: {},
-		paramExcludeRemoved: {},
+		paramBrokerID:                     {},
+		paramExcludeDemoted:               {},
+		paramExcludeRemoved:               {},
+		paramExcludedTopics:               {},
+		paramGoals:                        {},
+		paramConcurrentPartitionMovements: {},
+		paramConcurrentLeaderMovements:    {},
+		paramDryRun:                       {},
+		paramSkipHardGoalCheck:            {},
+		paramReason:                       {},
 	}
 	removeBrokerSupportedParams = map[string]struct{}{
-		paramBrokerID:       {},
-		paramExcludeDemoted: {},
-		paramExcludeRemoved: {},
+		paramBrokerID:                     {},
+		paramDestbrokerIDs:                {},
+		paramExcludeDemoted:               {},
+		paramExcludeRemoved:               {},
+		paramExcludedTopics:               {},
+		paramGoals:                        {},
+		paramConcurrentPartitionMovements: {},
+		paramConcurrentLeaderMovements:    {},
+		paramDryRun:                       {},
+		paramSkipHardGoalCheck:            {},
+		paramReason:                       {},
 	}
 	rebalanceSupportedParams = map[string]struct{}{
-		paramDestbrokerIDs:  {},
-		paramRebalanceDisk:  {},
-		paramExcludeDemoted: {},
-		paramExcludeRemoved: {},
+		paramDestbrokerIDs:                {},
+		paramRebalanceDisk:                {},
+		paramExcludeDemoted:               {},
+		paramExcludeRemoved:               {},
+		paramExcludedTopics:               {},
+		paramGoals:                        {},
+		paramConcurrentPartitionMovements: {},
+		paramConcurrentLeaderMovements:    {},
+		paramDryRun:                       {},
+		paramSkipHardGoalCheck:            {},
+		paramReason:                       {},
+	}
+	fixOfflineReplicasSupportedParams = map[string]struct{}{
+		paramExcludeDemoted:               {},
+		paramExcludeRemoved:               {},
+		paramExcludedTopics:               {},
+		paramGoals:                        {},
+		paramConcurrentPartitionMovements: {},
+		paramConcurrentLeaderMovements:    {},
+		paramDryRun:                       {},
+		paramSkipHardGoalCheck:            {},
+		paramReason:                       {},
 	}
 )
 
-func ScaleFactoryFn() func(ctx context.Context, kafkaCluster *v1beta1.KafkaCluster) (CruiseControlScaler, error) {
+// ScaleFactoryFn returns the factory of the Cruise Control scalers, the reader is used to read the client certificate
+// of the operator and the Secrets referenced by the HTTP client configuration of Cruise Control
+func ScaleFactoryFn(reader runtimeClient.Reader) func(ctx context.Context, kafkaCluster *v1beta1.KafkaCluster) (CruiseControlScaler, error) {
 	return func(ctx context.Context, kafkaCluster *v1beta1.KafkaCluster) (CruiseControlScaler, error) {
-		return NewCruiseControlScaler(ctx, CruiseControlURLFromKafkaCluster(kafkaCluster))
+		return NewCruiseControlScalerForCluster(ctx, reader, kafkaCluster)
+	}
+}
+
+// NewCruiseControlScalerForCluster returns a scaler for the Cruise Control of the KafkaCluster. The client certificate
+// and the Secrets of the HTTP client configuration are read on every call so that renewed certificates and rotated
+// credentials are picked up.
+func NewCruiseControlScalerForCluster(ctx context.Context, reader runtimeClient.Reader, kafkaCluster *v1beta1.KafkaCluster) (CruiseControlScaler, error) {
+	serverURL := CruiseControlURLFromKafkaCluster(kafkaCluster)
+	if kafkaCluster == nil {
+		return NewCruiseControlScaler(ctx, serverURL)
+	}
+	config, err := clientTransportConfig(ctx, reader, kafkaCluster)
+	if err != nil {
+		return nil, err
+	}
+	cluster := k8stypes.NamespacedName{Namespace: kafkaCluster.GetNamespace(), Name: kafkaCluster.GetName()}
+	if config == nil {
+		defaultClusterTransports.remove(cluster)
+		return newCruiseControlScaler(ctx, serverURL, nil)
 	}
+	return newCruiseControlScaler(ctx, serverURL, defaultClusterTransports.transport(cluster, *config))
 }
 
 func NewCruiseControlScaler(ctx context.Context, serverURL string) (CruiseControlScaler, error) {
-	return newCruiseControlScaler(ctx, serverURL)
+	return newCruiseControlScaler(ctx, serverURL, nil)
 }
 
-func createNewDefaultCruiseControlScaler(ctx context.Context, serverURL string) (CruiseControlScaler, error) {
+// createNewDefaultCruiseControlScaler returns a scaler sending its requests through the given transport, or through
+// http.DefaultTransport when it is nil
+func createNewDefaultCruiseControlScaler(ctx context.Context, serverURL string, transport http.RoundTripper) (CruiseControlScaler, error) {
 	log := logr.FromContextOrDiscard(ctx).WithName("Scaler")
 
 	cfg := &client.Config{
@@ -86,31 +155,85 @@ func createNewDefaultCruiseControlScaler(ctx context.Context, serverURL string)
 	}
 
 	cruisecontrol, err := client.NewClient(cfg)
+	if err == nil && transport != nil {
+		err = setClientTransport(cruisecontrol, transport)
+	}
 	if err != nil {
 		log.Error(err, "creating Cruise Control client failed")
 		return nil, err
 	}
-	return &cruiseControlScaler{
-		log:    log,
-		client: cruisecontrol,
-	}, nil
+	scaler := &cruiseControlScaler{
+		log:       log,
+		client:    cruisecontrol,
+		serverURL: serverURL,
+	}
+	scaler.detectVersion(ctx)
+	return scaler, nil
 }
 
 type cruiseControlScaler struct {
 	CruiseControlScaler
 
-	log    logr.Logger
-	client *client.Client
+	log       logr.Logger
+	client    *client.Client
+	serverURL string
+	version   *semver.Version
+}
+
+// detectVersion gets the version of Cruise Control from the response of the state endpoint unless it has already been
+// detected for the same server, the cached version is refreshed by every Status call. Failing to detect the version is
+// not an error, in that case every feature is considered to be supported.
+func (cc *cruiseControlScaler) detectVersion(ctx context.Context) {
+	if v := detectedVersions.get(cc.serverURL); v != nil {
+		cc.version = v
+		return
+	}
+	req := api.StateRequestWithDefaults()
+	tagRequest(ctx, &req.GenericRequestWithReason)
+	resp, err := cc.client.State(ctx, req)
+	if err != nil {
+		cc.log.V(1).Info("could not detect Cruise Control version", "error", Redact(err.Error()))
+		return
+	}
+	cc.setVersion(resp.CruiseControlVersion)
+}
+
+func (cc *cruiseControlScaler) setVersion(version string) {
+	if version == "" {
+		return
+	}
+	v, err := parseCruiseControlVersion(version)
+	if err != nil {
+		cc.log.V(1).Info("could not detect Cruise Control version", "error", Redact(err.Error()))
+		return
+	}
+	cc.version = v
+	detectedVersions.set(cc.serverURL, v)
+}
+
+// Version returns the version of Cruise Control or an empty string if it could not be detected.
+func (cc *cruiseControlScaler) Version() string {
+	if cc.version == nil {
+		return ""
+	}
+	return cc.version.String()
+}
+
+// SupportsFeature returns true if the feature is available in the detected version of Cruise Control.
+func (cc *cruiseControlScaler) SupportsFeature(feature Feature) bool {
+	return supportsFeature(cc.version, feature)
 }
 
 // Status returns a CruiseControlStatus describing the internal state of Cruise Control.
 func (cc *cruiseControlScaler) Status(ctx context.Context) (CruiseControlStatus, error) {
 	req := api.StateRequestWithDefaults()
 	req.Verbose = true
+	tagRequest(ctx, &req.GenericRequestWithReason)
 	resp, err := cc.client.State(ctx, req)
 	if err != nil {
 		return CruiseControlStatus{}, err
 	}
+	cc.setVersion(resp.CruiseControlVersion)
 
 	goalsReady := true
 	if len(resp.Result.AnalyzerState.GoalReadiness) > 0 {
@@ -130,9 +253,71 @@ func (cc *cruiseControlScaler) Status(ctx context.Context) (CruiseControlStatus,
 		GoalsReady:         goalsReady,
 		MonitoredWindows:   resp.Result.MonitorState.NumMonitoredWindows,
 		MonitoringCoverage: resp.Result.MonitorState.MonitoringCoveragePercentage,
+
+		ValidPartitionsPercentage: validPartitionsPercentage(resp.Result.MonitorState),
+		ExecutorState:             resp.Result.ExecutorState.State.String(),
+
+		SelfHealingEnabled:     anomalyTypesToStrings(resp.Result.AnomalyDetectorState.SelfHealingEnabled),
+		SelfHealingDisabled:    anomalyTypesToStrings(resp.Result.AnomalyDetectorState.SelfHealingDisabled),
+		RecentlyRemovedBrokers: resp.Result.ExecutorState.RecentlyRemovedBrokers,
+		RecentlyDemotedBrokers: resp.Result.ExecutorState.RecentlyDemotedBrokers,
+
+		ExecutingUserTaskID: resp.Result.ExecutorState.TriggeredUserTaskID,
+		PartitionMovements:  toPartitionMovements(resp.Result.ExecutorState),
 	}, nil
 }
 
+func validPartitionsPercentage(state types.LoadMonitorState) float64 {
+	if state.NumTotalPartitions == 0 {
+		return 0
+	}
+	return float64(state.NumValidPartitions) / float64(state.NumTotalPartitions) * 100
+}
+
+func toPartitionMovements(state types.ExecutorState) PartitionMovements {
+	movements := PartitionMovements{
+		Total:    state.NumTotalPartitionMovements,
+		Pending:  state.NumPendingPartitionMovements,
+		Finished: state.NumFinishedPartitionMovements,
+	}
+	for _, task := range state.InProgressPartitionMovement {
+		movements.InProgress = append(movements.InProgress, PartitionMovement{
+			Topic:       task.Proposal.TopicPartition.Topic,
+			Partition:   task.Proposal.TopicPartition.Partition,
+			OldReplicas: task.Proposal.OldReplicas,
+			NewReplicas: task.Proposal.NewReplicas,
+		})
+	}
+	return movements
+}
+
+// Admin requests Cruise Control to change its runtime configuration, like the concurrency of the executor or
+// the anomaly types which are self-healed.
+func (cc *cruiseControlScaler) Admin(ctx context.Context, config AdminConfig) error {
+	enableSelfHealingFor, err := parseAnomalyTypes(config.EnableSelfHealingFor)
+	if err != nil {
+		return err
+	}
+	disableSelfHealingFor, err := parseAnomalyTypes(config.DisableSelfHealingFor)
+	if err != nil {
+		return err
+	}
+
+	adminReq := &api.AdminRequest{
+		ConcurrentPartitionMovementsPerBroker:   config.ConcurrentPartitionMovementsPerBroker,
+		ConcurrentIntraBrokerPartitionMovements: config.ConcurrentIntraBrokerPartitionMovements,
+		ConcurrentLeaderMovements:               config.ConcurrentLeaderMovements,
+		EnableSelfHealingFor:                    enableSelfHealingFor,
+		DisableSelfHealingFor:                   disableSelfHealingFor,
+		DropRecentlyRemovedBrokers:              config.DropRecentlyRemovedBrokers,
+		DropRecentlyDemotedBrokers:              config.DropRecentlyDemotedBrokers,
+	}
+
+	tagRequest(ctx, &adminReq.GenericRequestWithReason)
+	_, err = cc.client.Admin(ctx, adminReq)
+	return err
+}
+
 // IsReady returns true if the Analyzer and Monitor components of Cruise Control are in ready state.
 func (cc *cruiseControlScaler) IsReady(ctx context.Context) bool {
 	status, err := cc.Status(ctx)
@@ -152,7 +337,9 @@ func (cc *cruiseControlScaler) IsReady(ctx context.Context) bool {
 
 // IsUp returns true if Cruise Control is online.
 func (cc *cruiseControlScaler) IsUp(ctx context.Context) bool {
-	_, err := cc.client.State(ctx, api.StateRequestWithDefaults())
+	req := api.StateRequestWithDefaults()
+	tagRequest(ctx, &req.GenericRequestWithReason)
+	_, err := cc.client.State(ctx, req)
 	return err == nil
 }
 
@@ -162,6 +349,7 @@ func (cc *cruiseControlScaler) UserTasks(ctx context.Context, taskIDs ...string)
 		UserTaskIDs: taskIDs,
 	}
 
+	tagRequest(ctx, &req.GenericRequestWithReason)
 	resp, err := cc.client.UserTasks(ctx, req)
 	if err != nil {
 		return nil, err
@@ -170,9 +358,11 @@ func (cc *cruiseControlScaler) UserTasks(ctx context.Context, taskIDs ...string)
 	results := make([]*Result, len(resp.Result.UserTasks))
 	for idx, taskInfo := range resp.Result.UserTasks {
 		results[idx] = &Result{
-			TaskID:    taskInfo.UserTaskID,
-			StartedAt: taskInfo.StartMs.UTC().String(),
-			State:     v1beta1.CruiseControlUserTaskState(taskInfo.Status.String()),
+			TaskID:        taskInfo.UserTaskID,
+			StartedAt:     taskInfo.StartMs.UTC().Format(time.RFC1123),
+			RequestURL:    taskInfo.RequestURL,
+			CorrelationID: CorrelationIDFromRequestURL(taskInfo.RequestURL),
+			State:         v1beta1.CruiseControlUserTaskState(taskInfo.Status.String()),
 		}
 	}
 
@@ -193,10 +383,59 @@ func parseBrokerIDtoSlice(brokerid string) ([]int32, error) {
 	return brokerIDIntSlice, nil
 }
 
+// parseGoals parses the comma separated list of Cruise Control goal names
+func parseGoals(goals string) ([]types.Goal, error) {
+	var parsedGoals []types.Goal
+	for _, goalName := range strings.Split(goals, ",") {
+		var goal types.Goal
+		if err := goal.UnmarshalText([]byte(strings.TrimSpace(goalName))); err != nil || goal == types.UndefinedGoal {
+			return nil, errors.NewWithDetails("unsupported Cruise Control goal", "goal", goalName)
+		}
+		parsedGoals = append(parsedGoals, goal)
+	}
+	return parsedGoals, nil
+}
+
+// parseAnomalyTypes parses the list of Cruise Control anomaly type names
+func parseAnomalyTypes(anomalyTypes []string) ([]types.AnomalyType, error) {
+	parsedAnomalyTypes := make([]types.AnomalyType, 0, len(anomalyTypes))
+	for _, anomalyTypeName := range anomalyTypes {
+		var anomalyType types.AnomalyType
+		if err := anomalyType.UnmarshalText([]byte(anomalyTypeName)); err != nil || anomalyType == types.AnomalyTypeUndefined {
+			return nil, errors.NewWithDetails("unsupported Cruise Control anomaly type", "anomalyType", anomalyTypeName)
+		}
+		parsedAnomalyTypes = append(parsedAnomalyTypes, anomalyType)
+	}
+	return parsedAnomalyTypes, nil
+}
+
+func anomalyTypesToStrings(anomalyTypes []types.AnomalyType) []string {
+	ret := make([]string, 0, len(anomalyTypes))
+	for _, anomalyType := range anomalyTypes {
+		ret = append(ret, anomalyType.String())
+	}
+	return ret
+}
+
+// parsePositiveInt32 parses the value to an int32 which must be larger than 0
+func parsePositiveInt32(value string) (int32, error) {
+	ret, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if ret <= 0 {
+		return 0, errors.NewWithDetails("value must be larger than 0", "value", value)
+	}
+	return int32(ret), nil
+}
+
 // AddBrokersWithParams requests Cruise Control to add the list of provided brokers to the Kafka cluster
 // by reassigning partition replicas to them. The broker list and operation properties can be added
 // with the use of the params argument.
 func (cc *cruiseControlScaler) AddBrokersWithParams(ctx context.Context, params map[string]string) (*Result, error) {
+	if err := checkParamFeatures(cc.version, params); err != nil {
+		return nil, err
+	}
 	addBrokerReq := &api.AddBrokerRequest{
 		AllowCapacityEstimation: true,
 		DataFrom:                types.ProposalDataSourceValidWindows,
@@ -208,27 +447,62 @@ func (cc *cruiseControlScaler) AddBrokersWithParams(ctx context.Context, params
 			case paramBrokerID:
 				ret, err := parseBrokerIDtoSlice(pvalue)
 				if err != nil {
-					return nil, err
+					return nil, InvalidParameterError{Err: err}
 				}
 				addBrokerReq.BrokerIDs = ret
 			case paramExcludeDemoted:
 				ret, err := strconv.ParseBool(pvalue)
 				if err != nil {
-					return nil, err
+					return nil, InvalidParameterError{Err: err}
 				}
 				addBrokerReq.ExcludeRecentlyDemotedBrokers = ret
 			case paramExcludeRemoved:
 				ret, err := strconv.ParseBool(pvalue)
 				if err != nil {
-					return nil, err
+					return nil, InvalidParameterError{Err: err}
 				}
 				addBrokerReq.ExcludeRecentlyRemovedBrokers = ret
+			case paramExcludedTopics:
+				addBrokerReq.ExcludedTopics = pvalue
+			case paramGoals:
+				ret, err := parseGoals(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				addBrokerReq.Goals = ret
+			case paramConcurrentPartitionMovements:
+				ret, err := parsePositiveInt32(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				addBrokerReq.ConcurrentPartitionMovementsPerBroker = ret
+			case paramConcurrentLeaderMovements:
+				ret, err := parsePositiveInt32(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				addBrokerReq.ConcurrentLeaderMovements = ret
+			case paramDryRun:
+				ret, err := strconv.ParseBool(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				addBrokerReq.DryRun = ret
+			case paramSkipHardGoalCheck:
+				ret, err := strconv.ParseBool(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				addBrokerReq.SkipHardGoalCheck = ret
+			case paramReason:
+				addBrokerReq.Reason = pvalue
 			default:
-				return nil, fmt.Errorf("unsupported %s parameter: %s, supported parameters: %s", v1alpha1.OperationAddBroker, param, addBrokerSupportedParams)
+				return nil, InvalidParameterError{Err: fmt.Errorf("unsupported %s parameter: %s, supported parameters: %s", v1alpha1.OperationAddBroker, param, addBrokerSupportedParams)}
 			}
 		}
 	}
 
+	tagRequest(ctx, &addBrokerReq.GenericRequestWithReason)
 	addBrokerResp, err := cc.client.AddBroker(ctx, addBrokerReq)
 	if err != nil {
 		return &Result{
@@ -254,6 +528,7 @@ func (cc *cruiseControlScaler) AddBrokersWithParams(ctx context.Context, params
 // StopExecution requests Cruise Control to stop running operation gracefully
 func (cc *cruiseControlScaler) StopExecution(ctx context.Context) (*Result, error) {
 	stopReq := &api.StopProposalExecutionRequest{}
+	tagRequest(ctx, &stopReq.GenericRequestWithReason)
 	stopResp, err := cc.client.StopProposalExecution(ctx, stopReq)
 	if err != nil {
 		return &Result{
@@ -274,6 +549,9 @@ func (cc *cruiseControlScaler) StopExecution(ctx context.Context) (*Result, erro
 }
 
 func (cc *cruiseControlScaler) RemoveBrokersWithParams(ctx context.Context, params map[string]string) (*Result, error) {
+	if err := checkParamFeatures(cc.version, params); err != nil {
+		return nil, err
+	}
 	rmBrokerReq := &api.RemoveBrokerRequest{
 		AllowCapacityEstimation: true,
 		DataFrom:                types.ProposalDataSourceValidWindows,
@@ -285,27 +563,68 @@ func (cc *cruiseControlScaler) RemoveBrokersWithParams(ctx context.Context, para
 			case paramBrokerID:
 				ret, err := parseBrokerIDtoSlice(pvalue)
 				if err != nil {
-					return nil, err
+					return nil, InvalidParameterError{Err: err}
 				}
 				rmBrokerReq.BrokerIDs = ret
 			case paramExcludeDemoted:
 				ret, err := strconv.ParseBool(pvalue)
 				if err != nil {
-					return nil, err
+					return nil, InvalidParameterError{Err: err}
 				}
 				rmBrokerReq.ExcludeRecentlyDemotedBrokers = ret
 			case paramExcludeRemoved:
 				ret, err := strconv.ParseBool(pvalue)
 				if err != nil {
-					return nil, err
+					return nil, InvalidParameterError{Err: err}
 				}
 				rmBrokerReq.ExcludeRecentlyRemovedBrokers = ret
+			case paramDestbrokerIDs:
+				ret, err := parseBrokerIDtoSlice(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				rmBrokerReq.DestinationBrokerIDs = ret
+			case paramExcludedTopics:
+				rmBrokerReq.ExcludedTopics = pvalue
+			case paramGoals:
+				ret, err := parseGoals(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				rmBrokerReq.Goals = ret
+			case paramConcurrentPartitionMovements:
+				ret, err := parsePositiveInt32(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				rmBrokerReq.ConcurrentPartitionMovementsPerBroker = ret
+			case paramConcurrentLeaderMovements:
+				ret, err := parsePositiveInt32(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				rmBrokerReq.ConcurrentLeaderMovements = ret
+			case paramDryRun:
+				ret, err := strconv.ParseBool(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				rmBrokerReq.DryRun = ret
+			case paramSkipHardGoalCheck:
+				ret, err := strconv.ParseBool(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				rmBrokerReq.SkipHardGoalCheck = ret
+			case paramReason:
+				rmBrokerReq.Reason = pvalue
 			default:
-				return nil, fmt.Errorf("unsupported %s parameter: %s, supported parameters: %s", v1alpha1.OperationRemoveBroker, param, removeBrokerSupportedParams)
+				return nil, InvalidParameterError{Err: fmt.Errorf("unsupported %s parameter: %s, supported parameters: %s", v1alpha1.OperationRemoveBroker, param, removeBrokerSupportedParams)}
 			}
 		}
 	}
 
+	tagRequest(ctx, &rmBrokerReq.GenericRequestWithReason)
 	rmBrokerResp, err := cc.client.RemoveBroker(ctx, rmBrokerReq)
 	if err != nil {
 		return &Result{
@@ -368,6 +687,7 @@ func (cc *cruiseControlScaler) AddBrokers(ctx context.Context, brokerIDs ...stri
 		DataFrom:                types.ProposalDataSourceValidWindows,
 		UseReadyDefaultGoals:    true,
 	}
+	tagRequest(ctx, &addBrokerReq.GenericRequestWithReason)
 	addBrokerResp, err := cc.client.AddBroker(ctx, addBrokerReq)
 	if err != nil {
 		return &Result{
@@ -397,6 +717,7 @@ func (cc *cruiseControlScaler) RemoveBrokers(ctx context.Context, brokerIDs ...s
 	}
 
 	clusterStateReq := api.KafkaClusterStateRequestWithDefaults()
+	tagRequest(ctx, &clusterStateReq.GenericRequestWithReason)
 	clusterStateResp, err := cc.client.KafkaClusterState(ctx, clusterStateReq)
 	if err != nil {
 		return nil, err
@@ -432,6 +753,7 @@ func (cc *cruiseControlScaler) RemoveBrokers(ctx context.Context, brokerIDs ...s
 		DataFrom:                types.ProposalDataSourceValidWindows,
 		UseReadyDefaultGoals:    true,
 	}
+	tagRequest(ctx, &rmBrokerReq.GenericRequestWithReason)
 	rmBrokerResp, err := cc.client.RemoveBroker(ctx, rmBrokerReq)
 
 	if err != nil {
@@ -454,7 +776,51 @@ func (cc *cruiseControlScaler) RemoveBrokers(ctx context.Context, brokerIDs ...s
 	}, nil
 }
 
+// DemoteBrokers requests Cruise Control to move the partition leaderships off from the list of provided brokers.
+func (cc *cruiseControlScaler) DemoteBrokers(ctx context.Context, brokerIDs ...string) (*Result, error) {
+	if len(brokerIDs) == 0 {
+		return nil, errors.New("no broker id(s) provided for demote brokers request")
+	}
+
+	brokersToDemote := make([]int32, 0, len(brokerIDs))
+	for _, brokerID := range brokerIDs {
+		bID, err := strconv.Atoi(brokerID)
+		if err != nil {
+			cc.log.Error(err, "failed to cast broker ID from string to integer", "broker_id", brokerID)
+			return nil, err
+		}
+		brokersToDemote = append(brokersToDemote, int32(bID))
+	}
+
+	demoteBrokerReq := api.DemoteBrokerRequestWithDefaults()
+	demoteBrokerReq.BrokerIDs = brokersToDemote
+	tagRequest(ctx, &demoteBrokerReq.GenericRequestWithReason)
+	demoteBrokerResp, err := cc.client.DemoteBroker(ctx, demoteBrokerReq)
+	if err != nil {
+		return &Result{
+			TaskID:             demoteBrokerResp.TaskID,
+			StartedAt:          demoteBrokerResp.Date,
+			ResponseStatusCode: demoteBrokerResp.StatusCode,
+			RequestURL:         demoteBrokerResp.RequestURL,
+			State:              v1beta1.CruiseControlTaskCompletedWithError,
+			Err:                err,
+		}, err
+	}
+
+	return &Result{
+		TaskID:             demoteBrokerResp.TaskID,
+		StartedAt:          demoteBrokerResp.Date,
+		ResponseStatusCode: demoteBrokerResp.StatusCode,
+		RequestURL:         demoteBrokerResp.RequestURL,
+		Result:             demoteBrokerResp.Result,
+		State:              v1beta1.CruiseControlTaskActive,
+	}, nil
+}
+
 func (cc *cruiseControlScaler) RebalanceWithParams(ctx context.Context, params map[string]string) (*Result, error) {
+	if err := checkParamFeatures(cc.version, params); err != nil {
+		return nil, err
+	}
 	rebalanceReq := &api.RebalanceRequest{
 		AllowCapacityEstimation: true,
 		DataFrom:                types.ProposalDataSourceValidWindows,
@@ -467,33 +833,68 @@ func (cc *cruiseControlScaler) RebalanceWithParams(ctx context.Context, params m
 			case paramDestbrokerIDs:
 				ret, err := parseBrokerIDtoSlice(pvalue)
 				if err != nil {
-					return nil, err
+					return nil, InvalidParameterError{Err: err}
 				}
 				rebalanceReq.DestinationBrokerIDs = ret
 			case paramRebalanceDisk:
 				ret, err := strconv.ParseBool(pvalue)
 				if err != nil {
-					return nil, err
+					return nil, InvalidParameterError{Err: err}
 				}
 				rebalanceReq.RebalanceDisk = ret
 			case paramExcludeDemoted:
 				ret, err := strconv.ParseBool(pvalue)
 				if err != nil {
-					return nil, err
+					return nil, InvalidParameterError{Err: err}
 				}
 				rebalanceReq.ExcludeRecentlyDemotedBrokers = ret
 			case paramExcludeRemoved:
 				ret, err := strconv.ParseBool(pvalue)
 				if err != nil {
-					return nil, err
+					return nil, InvalidParameterError{Err: err}
 				}
 				rebalanceReq.ExcludeRecentlyRemovedBrokers = ret
+			case paramExcludedTopics:
+				rebalanceReq.ExcludedTopics = pvalue
+			case paramGoals:
+				ret, err := parseGoals(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				rebalanceReq.Goals = ret
+			case paramConcurrentPartitionMovements:
+				ret, err := parsePositiveInt32(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				rebalanceReq.ConcurrentPartitionMovementsPerBroker = ret
+			case paramConcurrentLeaderMovements:
+				ret, err := parsePositiveInt32(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				rebalanceReq.ConcurrentLeaderMovements = ret
+			case paramDryRun:
+				ret, err := strconv.ParseBool(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				rebalanceReq.DryRun = ret
+			case paramSkipHardGoalCheck:
+				ret, err := strconv.ParseBool(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				rebalanceReq.SkipHardGoalCheck = ret
+			case paramReason:
+				rebalanceReq.Reason = pvalue
 			default:
-				return nil, fmt.Errorf("unsupported %s parameter: %s, supported parameters: %s", v1alpha1.OperationRebalance, param, rebalanceSupportedParams)
+				return nil, InvalidParameterError{Err: fmt.Errorf("unsupported %s parameter: %s, supported parameters: %s", v1alpha1.OperationRebalance, param, rebalanceSupportedParams)}
 			}
 		}
 	}
 
+	tagRequest(ctx, &rebalanceReq.GenericRequestWithReason)
 	rebalanceResp, err := cc.client.Rebalance(ctx, rebalanceReq)
 	if err != nil {
 		return &Result{
@@ -516,8 +917,99 @@ func (cc *cruiseControlScaler) RebalanceWithParams(ctx context.Context, params m
 	}, nil
 }
 
+// FixOfflineReplicasWithParams moves the offline replicas of the partitions to the alive brokers of the cluster
+func (cc *cruiseControlScaler) FixOfflineReplicasWithParams(ctx context.Context, params map[string]string) (*Result, error) {
+	if err := checkParamFeatures(cc.version, params); err != nil {
+		return nil, err
+	}
+	fixReq := &api.FixOfflineReplicasRequest{
+		AllowCapacityEstimation: true,
+		DataFrom:                types.ProposalDataSourceValidWindows,
+		UseReadyDefaultGoals:    true,
+	}
+
+	for param, pvalue := range params {
+		if _, ok := fixOfflineReplicasSupportedParams[param]; ok {
+			switch param {
+			case paramExcludeDemoted:
+				ret, err := strconv.ParseBool(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				fixReq.ExcludeRecentlyDemotedBrokers = ret
+			case paramExcludeRemoved:
+				ret, err := strconv.ParseBool(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				fixReq.ExcludeRecentlyRemovedBrokers = ret
+			case paramExcludedTopics:
+				fixReq.ExcludedTopics = pvalue
+			case paramGoals:
+				ret, err := parseGoals(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				fixReq.Goals = ret
+			case paramConcurrentPartitionMovements:
+				ret, err := parsePositiveInt32(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				fixReq.ConcurrentPartitionMovementsPerBroker = ret
+			case paramConcurrentLeaderMovements:
+				ret, err := parsePositiveInt32(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				fixReq.ConcurrentLeaderMovements = ret
+			case paramDryRun:
+				ret, err := strconv.ParseBool(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				fixReq.DryRun = ret
+			case paramSkipHardGoalCheck:
+				ret, err := strconv.ParseBool(pvalue)
+				if err != nil {
+					return nil, InvalidParameterError{Err: err}
+				}
+				fixReq.SkipHardGoalCheck = ret
+			case paramReason:
+				fixReq.Reason = pvalue
+			default:
+				return nil, InvalidParameterError{Err: fmt.Errorf("unsupported %s parameter: %s, supported parameters: %s", v1alpha1.OperationFixOfflineReplicas, param, fixOfflineReplicasSupportedParams)}
+			}
+		}
+	}
+
+	tagRequest(ctx, &fixReq.GenericRequestWithReason)
+	fixResp, err := cc.client.FixOfflineReplicas(ctx, fixReq)
+	if err != nil {
+		return &Result{
+			TaskID:             fixResp.TaskID,
+			StartedAt:          fixResp.Date,
+			ResponseStatusCode: fixResp.StatusCode,
+			RequestURL:         fixResp.RequestURL,
+			State:              v1beta1.CruiseControlTaskCompletedWithError,
+			Err:                err,
+		}, err
+	}
+
+	return &Result{
+		TaskID:             fixResp.TaskID,
+		StartedAt:          fixResp.Date,
+		ResponseStatusCode: fixResp.StatusCode,
+		RequestURL:         fixResp.RequestURL,
+		Result:             fixResp.Result,
+		State:              v1beta1.CruiseControlTaskActive,
+	}, nil
+}
+
 func (cc *cruiseControlScaler) KafkaClusterLoad(ctx context.Context) (*api.KafkaClusterLoadResponse, error) {
-	clusterLoadResp, err := cc.client.KafkaClusterLoad(ctx, api.KafkaClusterLoadRequestWithDefaults())
+	req := api.KafkaClusterLoadRequestWithDefaults()
+	tagRequest(ctx, &req.GenericRequestWithReason)
+	clusterLoadResp, err := cc.client.KafkaClusterLoad(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -526,7 +1018,9 @@ func (cc *cruiseControlScaler) KafkaClusterLoad(ctx context.Context) (*api.Kafka
 
 // RebalanceDisks performs a disk rebalance via Cruise Control for the provided list of brokers.
 func (cc *cruiseControlScaler) RebalanceDisks(ctx context.Context, brokerIDs ...string) (*Result, error) {
-	clusterLoadResp, err := cc.client.KafkaClusterLoad(ctx, api.KafkaClusterLoadRequestWithDefaults())
+	req := api.KafkaClusterLoadRequestWithDefaults()
+	tagRequest(ctx, &req.GenericRequestWithReason)
+	clusterLoadResp, err := cc.client.KafkaClusterLoad(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -558,6 +1052,7 @@ func (cc *cruiseControlScaler) RebalanceDisks(ctx context.Context, brokerIDs ...
 		UseReadyDefaultGoals:          true,
 		ExcludeRecentlyRemovedBrokers: true,
 	}
+	tagRequest(ctx, &rebalanceReq.GenericRequestWithReason)
 	rebalanceResp, err := cc.client.Rebalance(ctx, rebalanceReq)
 	if err != nil {
 		return &Result{
@@ -582,7 +1077,9 @@ func (cc *cruiseControlScaler) RebalanceDisks(ctx context.Context, brokerIDs ...
 // BrokersWithState returns a list of IDs for Kafka brokers which are available in Cruise Control
 // and have one of the expected states.
 func (cc *cruiseControlScaler) BrokersWithState(ctx context.Context, states ...KafkaBrokerState) ([]string, error) {
-	resp, err := cc.client.KafkaClusterLoad(ctx, api.KafkaClusterLoadRequestWithDefaults())
+	req := api.KafkaClusterLoadRequestWithDefaults()
+	tagRequest(ctx, &req.GenericRequestWithReason)
+	resp, err := cc.client.KafkaClusterLoad(ctx, req)
 	if err != nil {
 		switch {
 		case strings.Contains(err.Error(), nullPointerExceptionErrString):
@@ -611,6 +1108,7 @@ func (cc *cruiseControlScaler) BrokersWithState(ctx context.Context, states ...K
 // KafkaClusterState returns the state of the Kafka cluster
 func (cc *cruiseControlScaler) KafkaClusterState(ctx context.Context) (*types.KafkaClusterState, error) {
 	clusterStateReq := api.KafkaClusterStateRequestWithDefaults()
+	tagRequest(ctx, &clusterStateReq.GenericRequestWithReason)
 	clusterStateResp, err := cc.client.KafkaClusterState(ctx, clusterStateReq)
 	if err != nil {
 		return nil, err
@@ -621,6 +1119,7 @@ func (cc *cruiseControlScaler) KafkaClusterState(ctx context.Context) (*types.Ka
 // PartitionLeadersReplicasByBroker returns the number of partition replicas for every broker in the Kafka cluster.
 func (cc *cruiseControlScaler) PartitionLeadersReplicasByBroker(ctx context.Context) (brokerIDReplicaCounts map[string]int32, brokerIDLeaderCounts map[string]int32, err error) {
 	clusterStateReq := api.KafkaClusterStateRequestWithDefaults()
+	tagRequest(ctx, &clusterStateReq.GenericRequestWithReason)
 	clusterStateResp, err := cc.client.KafkaClusterState(ctx, clusterStateReq)
 	if err != nil {
 		return nil, nil, err
@@ -650,7 +1149,9 @@ func (cc *cruiseControlScaler) BrokerWithLeastPartitionReplicas(ctx context.Cont
 
 // LogDirsByBroker returns the ID of the broker which host the least partition replicas.
 func (cc *cruiseControlScaler) LogDirsByBroker(ctx context.Context) (map[string]map[LogDirState][]string, error) {
-	resp, err := cc.client.KafkaClusterState(ctx, api.KafkaClusterStateRequestWithDefaults())
+	req := api.KafkaClusterStateRequestWithDefaults()
+	tagRequest(ctx, &req.GenericRequestWithReason)
+	resp, err := cc.client.KafkaClusterState(ctx, req)
 	if err != nil {
 		cc.log.Error(err, "getting Kafka cluster state from Cruise Control returned an error")
 		return nil, err
@@ -0,0 +1,169 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"emperror.dev/errors"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/client"
+)
+
+// The Cruise Control client sends its requests through http.DefaultTransport which cannot be configured per client.
+// The scalers of the KafkaClusters configuring the requests get an HTTP client of their own instead, so neither the
+// other HTTP clients of the process nor the KafkaClusters sharing the same Cruise Control are affected by the client
+// certificate, the proxy and the headers of a KafkaCluster.
+var defaultClusterTransports = newClusterTransports(nil)
+
+// transportConfig describes the requests sent to a Cruise Control server
+type transportConfig struct {
+	// TLSConfig holds the client certificate presented to Cruise Control
+	TLSConfig *tls.Config
+	// ProxyURL is the URL of the proxy the requests are sent through
+	ProxyURL *url.URL
+	// Headers are set on every request
+	Headers http.Header
+}
+
+type clusterTransport struct {
+	config    transportConfig
+	transport *http.Transport
+}
+
+// clusterTransports holds the transports of the requests sent to Cruise Control by the KafkaClusters
+type clusterTransports struct {
+	// base is cloned for the transports of the KafkaClusters, http.DefaultTransport is used when it is not set
+	base *http.Transport
+
+	mu       sync.Mutex
+	clusters map[k8stypes.NamespacedName]*clusterTransport
+}
+
+func newClusterTransports(base *http.Transport) *clusterTransports {
+	return &clusterTransports{
+		base:     base,
+		clusters: make(map[k8stypes.NamespacedName]*clusterTransport),
+	}
+}
+
+// transport returns the transport of the requests sent to Cruise Control by the KafkaCluster. The transport is reused
+// while its configuration is unchanged, the connections opened with the previous configuration are closed when it has
+// been changed, e.g. the client certificate has been renewed.
+func (t *clusterTransports) transport(cluster k8stypes.NamespacedName, config transportConfig) http.RoundTripper {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	current, ok := t.clusters[cluster]
+	if !ok || !isSameTransportConfig(current.config, config) {
+		transport := t.newTransport()
+		transport.TLSClientConfig = config.TLSConfig
+		if config.ProxyURL != nil {
+			transport.Proxy = http.ProxyURL(config.ProxyURL)
+		}
+		if ok {
+			current.transport.CloseIdleConnections()
+		}
+		current = &clusterTransport{config: config, transport: transport}
+		t.clusters[cluster] = current
+	}
+	if len(current.config.Headers) == 0 {
+		return current.transport
+	}
+	return &headerTransport{headers: current.config.Headers, base: current.transport}
+}
+
+// remove drops the transport of the KafkaCluster which does not configure its requests anymore
+func (t *clusterTransports) remove(cluster k8stypes.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if current, ok := t.clusters[cluster]; ok {
+		delete(t.clusters, cluster)
+		current.transport.CloseIdleConnections()
+	}
+}
+
+func (t *clusterTransports) newTransport() *http.Transport {
+	if t.base != nil {
+		return t.base.Clone()
+	}
+	if base, ok := http.DefaultTransport.(*http.Transport); ok {
+		return base.Clone()
+	}
+	return &http.Transport{}
+}
+
+// headerTransport sets the headers on every request sent through the base transport
+type headerTransport struct {
+	headers http.Header
+	base    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		req.Header[name] = values
+	}
+	return t.base.RoundTrip(req)
+}
+
+// setClientTransport makes the Cruise Control client send its requests through the transport. The client does not
+// allow setting its HTTP client, so the unexported field holding it is replaced.
+func setClientTransport(cc *client.Client, transport http.RoundTripper) error {
+	field := reflect.ValueOf(cc).Elem().FieldByName("httpClient")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(&http.Client{}) {
+		return errors.New("the HTTP client of the Cruise Control client cannot be replaced")
+	}
+	httpClient := reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem() //nolint:gosec
+	httpClient.Set(reflect.ValueOf(&http.Client{Transport: transport}))
+	return nil
+}
+func isSameTransportConfig(current, config transportConfig) bool {
+	if (current.ProxyURL == nil) != (config.ProxyURL == nil) ||
+		(current.ProxyURL != nil && current.ProxyURL.String() != config.ProxyURL.String()) {
+		return false
+	}
+	if !reflect.DeepEqual(current.Headers, config.Headers) {
+		return false
+	}
+	if current.TLSConfig == nil || config.TLSConfig == nil {
+		return current.TLSConfig == config.TLSConfig
+	}
+	return isSameClientCertificate(current.TLSConfig, config.TLSConfig)
+}
+
+func isSameClientCertificate(current, config *tls.Config) bool {
+	if len(current.Certificates) != len(config.Certificates) {
+		return false
+	}
+	for i := range current.Certificates {
+		if len(current.Certificates[i].Certificate) != len(config.Certificates[i].Certificate) {
+			return false
+		}
+		for j := range current.Certificates[i].Certificate {
+			if !bytes.Equal(current.Certificates[i].Certificate[j], config.Certificates[i].Certificate[j]) {
+				return false
+			}
+		}
+	}
+	return current.RootCAs.Equal(config.RootCAs)
+}
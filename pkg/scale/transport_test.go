@@ -0,0 +1,138 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+	"github.com/banzaicloud/go-cruise-control/pkg/client"
+)
+
+func TestClusterTransports(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Echo", r.Header.Get("X-Custom"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+	clientCert := server.TLS.Certificates[0]
+	cluster := k8stypes.NamespacedName{Namespace: "kafka", Name: "kafka"}
+	otherCluster := k8stypes.NamespacedName{Namespace: "other", Name: "kafka"}
+
+	transports := newClusterTransports(&http.Transport{})
+	transport := transports.transport(cluster, transportConfig{
+		TLSConfig: &tls.Config{RootCAs: rootCAs, Certificates: []tls.Certificate{clientCert}},
+		Headers:   http.Header{"X-Custom": []string{"value"}},
+	})
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "value", resp.Header.Get("X-Echo"))
+	assert.Empty(t, req.Header.Get("X-Custom"))
+
+	// The transport is only replaced when the configuration has been changed
+	current := transports.clusters[cluster]
+	transports.transport(cluster, transportConfig{
+		TLSConfig: &tls.Config{RootCAs: rootCAs, Certificates: []tls.Certificate{clientCert}},
+		Headers:   http.Header{"X-Custom": []string{"value"}},
+	})
+	assert.Same(t, current, transports.clusters[cluster])
+
+	// The KafkaClusters sharing the same Cruise Control do not overwrite the configuration of each other
+	otherTransport := transports.transport(otherCluster, transportConfig{TLSConfig: &tls.Config{RootCAs: rootCAs}})
+	assert.Same(t, current, transports.clusters[cluster])
+	resp, err = (&http.Client{Transport: otherTransport}).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	transports.remove(cluster)
+	assert.NotContains(t, transports.clusters, cluster)
+	assert.Contains(t, transports.clusters, otherCluster)
+
+	// The transport of the process is left unchanged
+	_, err = http.Get(server.URL)
+	assert.Error(t, err)
+}
+
+func TestClusterTransportsProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	transports := newClusterTransports(&http.Transport{})
+	transport := transports.transport(k8stypes.NamespacedName{Namespace: "kafka", Name: "kafka"}, transportConfig{ProxyURL: proxyURL})
+	resp, err := (&http.Client{Transport: transport}).Get("http://cruisecontrol.example:8090/kafkacruisecontrol/state")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "http://cruisecontrol.example:8090/kafkacruisecontrol/state", proxied)
+}
+
+func TestSetClientTransport(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	var sent int
+	cc, err := client.NewClient(&client.Config{ServerURL: "http://cruisecontrol.example:8090/kafkacruisecontrol", UserAgent: "koperator"})
+	require.NoError(t, err)
+	require.NoError(t, setClientTransport(cc, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		sent++
+		req.URL.Scheme, req.URL.Host = serverURL.Scheme, serverURL.Host
+		return http.DefaultTransport.RoundTrip(req)
+	})))
+
+	_, _ = cc.State(context.Background(), api.StateRequestWithDefaults())
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, "koperator", userAgent)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
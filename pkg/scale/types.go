@@ -16,6 +16,7 @@ package scale
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/banzaicloud/go-cruise-control/pkg/api"
 	"github.com/banzaicloud/go-cruise-control/pkg/types"
@@ -32,8 +33,10 @@ type CruiseControlScaler interface {
 	AddBrokersWithParams(ctx context.Context, params map[string]string) (*Result, error)
 	RemoveBrokersWithParams(ctx context.Context, params map[string]string) (*Result, error)
 	RebalanceWithParams(ctx context.Context, params map[string]string) (*Result, error)
+	FixOfflineReplicasWithParams(ctx context.Context, params map[string]string) (*Result, error)
 	StopExecution(ctx context.Context) (*Result, error)
 	RemoveBrokers(ctx context.Context, brokerIDs ...string) (*Result, error)
+	DemoteBrokers(ctx context.Context, brokerIDs ...string) (*Result, error)
 	RebalanceDisks(ctx context.Context, brokerIDs ...string) (*Result, error)
 	BrokersWithState(ctx context.Context, states ...KafkaBrokerState) ([]string, error)
 	KafkaClusterState(ctx context.Context) (*types.KafkaClusterState, error)
@@ -41,6 +44,9 @@ type CruiseControlScaler interface {
 	BrokerWithLeastPartitionReplicas(ctx context.Context) (string, error)
 	LogDirsByBroker(ctx context.Context) (map[string]map[LogDirState][]string, error)
 	KafkaClusterLoad(ctx context.Context) (*api.KafkaClusterLoadResponse, error)
+	Version() string
+	SupportsFeature(feature Feature) bool
+	Admin(ctx context.Context, config AdminConfig) error
 }
 
 type Result struct {
@@ -51,6 +57,8 @@ type Result struct {
 	Result             *types.OptimizationResult
 	State              v1beta1.CruiseControlUserTaskState
 	Err                error
+	// CorrelationID is the UID of the custom resource which initiated the Cruise Control request
+	CorrelationID string
 }
 
 type LogDirState int8
@@ -70,6 +78,58 @@ type CruiseControlStatus struct {
 
 	MonitoredWindows   float32
 	MonitoringCoverage float64
+	// ValidPartitionsPercentage is the percentage of the partitions having enough valid metric samples
+	ValidPartitionsPercentage float64
+	// ExecutorState is the state of the Executor component, e.g. NO_TASK_IN_PROGRESS
+	ExecutorState string
+
+	SelfHealingEnabled     []string
+	SelfHealingDisabled    []string
+	RecentlyRemovedBrokers []int32
+	RecentlyDemotedBrokers []int32
+
+	// ExecutingUserTaskID is the ID of the user task whose proposal is being executed by the Executor component
+	ExecutingUserTaskID string
+	// PartitionMovements describes the inter-broker partition movements of the ongoing execution
+	PartitionMovements PartitionMovements
+}
+
+// PartitionMovements describes the progress of the inter-broker partition movements of the execution.
+type PartitionMovements struct {
+	Total    int32
+	Pending  int32
+	Finished int32
+
+	InProgress []PartitionMovement
+}
+
+// PartitionMovement describes the reassignment of a partition between brokers.
+type PartitionMovement struct {
+	Topic       string
+	Partition   int32
+	OldReplicas []int32
+	NewReplicas []int32
+}
+
+// AdminConfig describes the runtime configuration changes which are applied using the admin endpoint of Cruise Control.
+// Zero values are left unchanged in Cruise Control.
+type AdminConfig struct {
+	ConcurrentPartitionMovementsPerBroker   int32
+	ConcurrentIntraBrokerPartitionMovements int32
+	ConcurrentLeaderMovements               int32
+
+	EnableSelfHealingFor  []string
+	DisableSelfHealingFor []string
+
+	DropRecentlyRemovedBrokers []int32
+	DropRecentlyDemotedBrokers []int32
+}
+
+// IsEmpty returns true if the AdminConfig does not change anything in Cruise Control.
+func (c AdminConfig) IsEmpty() bool {
+	return c.ConcurrentPartitionMovementsPerBroker == 0 && c.ConcurrentIntraBrokerPartitionMovements == 0 &&
+		c.ConcurrentLeaderMovements == 0 && len(c.EnableSelfHealingFor) == 0 && len(c.DisableSelfHealingFor) == 0 &&
+		len(c.DropRecentlyRemovedBrokers) == 0 && len(c.DropRecentlyDemotedBrokers) == 0
 }
 
 // IsReady returns true if the Analyzer and Monitor components of Cruise Control are in ready state.
@@ -77,6 +137,27 @@ func (s CruiseControlStatus) IsReady() bool {
 	return s.AnalyzerReady && s.MonitorReady
 }
 
+// IsModelReady returns true if the Analyzer component of Cruise Control has enough metrics and ready goals
+// to generate proposals for the operations moving partition replicas.
+func (s CruiseControlStatus) IsModelReady() bool {
+	return s.AnalyzerReady
+}
+
+// ModelNotReadyReason returns why the Analyzer component of Cruise Control cannot generate proposals
+// or an empty string when it is ready.
+func (s CruiseControlStatus) ModelNotReadyReason() string {
+	switch {
+	case s.IsModelReady():
+		return ""
+	case !s.ProposalReady:
+		return fmt.Sprintf("Cruise Control has not collected enough metrics to generate proposals (monitored windows: %v, valid partitions: %.1f%%)",
+			s.MonitoredWindows, s.ValidPartitionsPercentage)
+	case !s.GoalsReady:
+		return "the goals of Cruise Control are not ready to generate proposals"
+	}
+	return "the analyzer of Cruise Control is not ready"
+}
+
 // InExecution returns true if the Executor component of Cruise Control is performing an operation which means that new
 // operations cannot be started until the current has finished or the forced to be terminated.
 func (s CruiseControlStatus) InExecution() bool {
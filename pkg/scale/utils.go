@@ -37,22 +37,27 @@ func CruiseControlURLFromKafkaCluster(instance *v1beta1.KafkaCluster) string {
 	if instance == nil {
 		return ""
 	}
-	return CruiseControlURL(
-		instance.Namespace,
-		instance.Spec.GetKubernetesClusterDomain(),
-		instance.Spec.CruiseControlConfig.CruiseControlEndpoint,
-		instance.Name,
+	// Cruise Control is reached over HTTPS when it authenticates the operator with a client certificate
+	return cruiseControlURL(
+		cruiseControlEndpoint(
+			instance.Namespace,
+			instance.Spec.GetKubernetesClusterDomain(),
+			instance.Spec.CruiseControlConfig.CruiseControlEndpoint,
+			instance.Name,
+		),
+		instance.Spec.CruiseControlConfig.ClientTLS != nil,
 	)
 }
 
 func CruiseControlURL(namespace, domain, endpoint, name string) string {
-	var url string
+	return cruiseControlURL(cruiseControlEndpoint(namespace, domain, endpoint, name), false)
+}
+
+func cruiseControlEndpoint(namespace, domain, endpoint, name string) string {
 	if endpoint != "" {
-		url = endpoint
-	} else {
-		url = fmt.Sprintf("%s-cruisecontrol-svc.%s.svc.%s:8090", name, namespace, domain)
+		return endpoint
 	}
-	return cruiseControlURL(url, false)
+	return fmt.Sprintf("%s-cruisecontrol-svc.%s.svc.%s:8090", name, namespace, domain)
 }
 
 func cruiseControlURL(endpoint string, secure bool) string {
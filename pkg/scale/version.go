@@ -0,0 +1,183 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"emperror.dev/errors"
+	"github.com/Masterminds/semver/v3"
+)
+
+// Feature is a Cruise Control REST API endpoint or parameter which is only available from a specific Cruise Control version.
+type Feature string
+
+const (
+	// FeatureRebalanceDisk is the rebalance_disk parameter of the rebalance endpoint used for intra-broker rebalance
+	FeatureRebalanceDisk Feature = paramRebalanceDisk
+	// FeatureIntraBrokerPartitionMovements is the configuration of the concurrency of the intra-broker partition movements
+	FeatureIntraBrokerPartitionMovements Feature = "num.concurrent.intra.broker.partition.movements"
+)
+
+var (
+	// featureMinimumVersions holds the first Cruise Control version which supports the given feature
+	featureMinimumVersions = map[Feature]*semver.Version{
+		FeatureRebalanceDisk:                 semver.MustParse("2.5.0"),
+		FeatureIntraBrokerPartitionMovements: semver.MustParse("2.5.0"),
+	}
+	// paramFeatures maps the operation parameters to the feature which is needed to use them
+	paramFeatures = map[string]Feature{
+		paramRebalanceDisk: FeatureRebalanceDisk,
+	}
+	// propertyFeatures maps the Cruise Control configuration properties to the feature which is needed to set them
+	propertyFeatures = map[string]Feature{
+		string(FeatureIntraBrokerPartitionMovements): FeatureIntraBrokerPartitionMovements,
+	}
+	// detectedVersions holds the last version reported by the Cruise Control servers so that a new scaler does not have
+	// to query the state of Cruise Control before it can check the features of its requests
+	detectedVersions = newVersionCache()
+)
+
+// versionCache holds the detected Cruise Control versions by the URL of the Cruise Control server
+type versionCache struct {
+	mu       sync.RWMutex
+	versions map[string]*semver.Version
+}
+
+func newVersionCache() *versionCache {
+	return &versionCache{
+		versions: make(map[string]*semver.Version),
+	}
+}
+
+func (c *versionCache) get(serverURL string) *semver.Version {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.versions[serverURL]
+}
+
+func (c *versionCache) set(serverURL string, version *semver.Version) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versions[serverURL] = version
+}
+
+// UnsupportedFeatureError is returned when the requested operation cannot be performed by the running Cruise Control
+// as it is older than the version which introduced the feature.
+type UnsupportedFeatureError struct {
+	Feature         Feature
+	Version         string
+	RequiredVersion string
+}
+
+func (e UnsupportedFeatureError) Error() string {
+	return fmt.Sprintf("%s is not supported by Cruise Control version %s, version %s or newer is required",
+		e.Feature, e.Version, e.RequiredVersion)
+}
+
+// IsUnsupportedFeatureError returns true if the error (or any error in its chain) is an UnsupportedFeatureError.
+func IsUnsupportedFeatureError(err error) bool {
+	var e UnsupportedFeatureError
+	return errors.As(err, &e)
+}
+
+// parseCruiseControlVersion parses the version reported by Cruise Control. Pre-release and build metadata, like -SNAPSHOT,
+// are dropped as they would make the version lower than the release it is built from.
+func parseCruiseControlVersion(version string) (*semver.Version, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not parse Cruise Control version", "version", version)
+	}
+	release, err := semver.NewVersion(fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch()))
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not parse Cruise Control version", "version", version)
+	}
+	return release, nil
+}
+
+// supportsFeature returns true if the given Cruise Control version supports the feature. When the version is not known
+// every feature is considered to be supported and it is left to Cruise Control to reject the request.
+func supportsFeature(version *semver.Version, feature Feature) bool {
+	if version == nil {
+		return true
+	}
+	minVersion, ok := featureMinimumVersions[feature]
+	if !ok {
+		return true
+	}
+	return !version.LessThan(minVersion)
+}
+
+// checkFeature returns an UnsupportedFeatureError if the feature is not available in the given Cruise Control version.
+func checkFeature(version *semver.Version, feature Feature) error {
+	if supportsFeature(version, feature) {
+		return nil
+	}
+	return UnsupportedFeatureError{
+		Feature:         feature,
+		Version:         version.String(),
+		RequiredVersion: featureMinimumVersions[feature].String(),
+	}
+}
+
+// checkParamFeatures returns an UnsupportedFeatureError for the first parameter, in alphabetical order, which is not
+// supported by the given Cruise Control version.
+func checkParamFeatures(version *semver.Version, params map[string]string) error {
+	paramNames := make([]string, 0, len(params))
+	for param := range params {
+		paramNames = append(paramNames, param)
+	}
+	sort.Strings(paramNames)
+
+	for _, param := range paramNames {
+		feature, ok := paramFeatures[param]
+		// Disabled boolean parameters are equal to omitting them from the request
+		if !ok || params[param] == "false" {
+			continue
+		}
+		if err := checkFeature(version, feature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckPropertiesSupported returns an UnsupportedFeatureError for the first Cruise Control configuration property, in
+// alphabetical order, which is not supported by the given Cruise Control version. The properties are not checked when
+// the version cannot be parsed, e.g. when the image is tagged with a name.
+func CheckPropertiesSupported(version string, properties map[string]string) error {
+	v, err := parseCruiseControlVersion(version)
+	if err != nil {
+		return nil
+	}
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		feature, ok := propertyFeatures[key]
+		if !ok {
+			continue
+		}
+		if err := checkFeature(v, feature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCruiseControlVersion(t *testing.T) {
+	testCases := []struct {
+		version  string
+		expected string
+		wantErr  bool
+	}{
+		{version: "2.5.101", expected: "2.5.101"},
+		{version: "2.5.114-SNAPSHOT", expected: "2.5.114"},
+		{version: "not-a-version", wantErr: true},
+	}
+
+	for _, testCase := range testCases {
+		v, err := parseCruiseControlVersion(testCase.version)
+		if testCase.wantErr {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.expected, v.String())
+	}
+}
+
+func TestCheckParamFeatures(t *testing.T) {
+	testCases := []struct {
+		testName string
+		version  *semver.Version
+		params   map[string]string
+		wantErr  bool
+	}{
+		{
+			testName: "unknown version allows every feature",
+			params:   map[string]string{paramRebalanceDisk: "true"},
+		},
+		{
+			testName: "supported feature",
+			version:  semver.MustParse("2.5.101"),
+			params:   map[string]string{paramRebalanceDisk: "true"},
+		},
+		{
+			testName: "unsupported feature",
+			version:  semver.MustParse("2.0.100"),
+			params:   map[string]string{paramRebalanceDisk: "true"},
+			wantErr:  true,
+		},
+		{
+			testName: "disabled unsupported feature",
+			version:  semver.MustParse("2.0.100"),
+			params:   map[string]string{paramRebalanceDisk: "false"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.testName, func(t *testing.T) {
+			err := checkParamFeatures(testCase.version, testCase.params)
+			if testCase.wantErr {
+				assert.True(t, IsUnsupportedFeatureError(err))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	assert.False(t, supportsFeature(semver.MustParse("2.4.9"), FeatureRebalanceDisk))
+	assert.True(t, supportsFeature(semver.MustParse("2.5.0"), FeatureRebalanceDisk))
+}
+
+func TestCheckPropertiesSupported(t *testing.T) {
+	props := map[string]string{
+		"num.concurrent.leader.movements":                 "1000",
+		"num.concurrent.intra.broker.partition.movements": "2",
+	}
+	assert.NoError(t, CheckPropertiesSupported("2.5.101", props))
+	assert.NoError(t, CheckPropertiesSupported("latest", props))
+	assert.NoError(t, CheckPropertiesSupported("2.0.100", map[string]string{"num.concurrent.leader.movements": "1000"}))
+
+	err := CheckPropertiesSupported("2.0.100", props)
+	assert.True(t, IsUnsupportedFeatureError(err))
+	assert.EqualError(t, err, "num.concurrent.intra.broker.partition.movements is not supported by Cruise Control version 2.0.100, version 2.5.0 or newer is required")
+}
+
+func TestVersionCache(t *testing.T) {
+	cache := newVersionCache()
+	assert.Nil(t, cache.get("http://kafka-cruisecontrol-svc.kafka.svc:8090"))
+
+	cache.set("http://kafka-cruisecontrol-svc.kafka.svc:8090", semver.MustParse("2.5.101"))
+	assert.Equal(t, "2.5.101", cache.get("http://kafka-cruisecontrol-svc.kafka.svc:8090").String())
+	assert.Nil(t, cache.get("http://other-cruisecontrol-svc.kafka.svc:8090"))
+
+	cache.set("http://kafka-cruisecontrol-svc.kafka.svc:8090", semver.MustParse("2.5.113"))
+	assert.Equal(t, "2.5.113", cache.get("http://kafka-cruisecontrol-svc.kafka.svc:8090").String())
+}
@@ -0,0 +1,90 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"path/filepath"
+	"runtime"
+
+	"emperror.dev/errors"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// CRDDirectory returns the directory holding the Koperator CRDs. The path is resolved relative to this source file so
+// it works both from the repository and from the module cache.
+func CRDDirectory() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "config", "base", "crds")
+}
+
+// NewScheme returns a scheme with the built-in Kubernetes types and the Koperator types registered
+func NewScheme() (*k8sruntime.Scheme, error) {
+	scheme := k8sruntime.NewScheme()
+	for _, addToScheme := range []func(*k8sruntime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		v1alpha1.AddToScheme,
+		v1beta1.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			return nil, errors.WrapIf(err, "could not build scheme")
+		}
+	}
+	return scheme, nil
+}
+
+// Environment is an envtest environment with the Koperator CRDs installed
+type Environment struct {
+	envtest.Environment
+
+	// Scheme is the scheme used by Client, additional types can be registered to it before Start is called.
+	Scheme *k8sruntime.Scheme
+	// Client is the client of the test API server, it is set by Start.
+	Client client.Client
+}
+
+// NewEnvironment returns an Environment installing the Koperator CRDs and the CRDs of the additional directories
+func NewEnvironment(additionalCRDDirectories ...string) (*Environment, error) {
+	scheme, err := NewScheme()
+	if err != nil {
+		return nil, err
+	}
+	return &Environment{
+		Environment: envtest.Environment{
+			ErrorIfCRDPathMissing: true,
+			CRDDirectoryPaths:     append([]string{CRDDirectory()}, additionalCRDDirectories...),
+		},
+		Scheme: scheme,
+	}, nil
+}
+
+// Start starts the test control plane and creates the client for it
+func (e *Environment) Start() (*rest.Config, error) {
+	cfg, err := e.Environment.Start()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not start test environment")
+	}
+	e.Client, err = client.New(cfg, client.Options{Scheme: e.Scheme})
+	if err != nil {
+		return nil, errors.Combine(errors.WrapIf(err, "could not create client for test environment"), e.Environment.Stop())
+	}
+	return cfg, nil
+}
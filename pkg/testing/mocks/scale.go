@@ -52,6 +52,20 @@ func (m *MockCruiseControlScaler) EXPECT() *MockCruiseControlScalerMockRecorder
 	return m.recorder
 }
 
+// Admin mocks base method.
+func (m *MockCruiseControlScaler) Admin(ctx context.Context, config scale.AdminConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Admin", ctx, config)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Admin indicates an expected call of Admin.
+func (mr *MockCruiseControlScalerMockRecorder) Admin(ctx, config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Admin", reflect.TypeOf((*MockCruiseControlScaler)(nil).Admin), ctx, config)
+}
+
 // AddBrokers mocks base method.
 func (m *MockCruiseControlScaler) AddBrokers(ctx context.Context, brokerIDs ...string) (*scale.Result, error) {
 	m.ctrl.T.Helper()
@@ -122,6 +136,41 @@ func (mr *MockCruiseControlScalerMockRecorder) BrokersWithState(ctx interface{},
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BrokersWithState", reflect.TypeOf((*MockCruiseControlScaler)(nil).BrokersWithState), varargs...)
 }
 
+// DemoteBrokers mocks base method.
+func (m *MockCruiseControlScaler) DemoteBrokers(ctx context.Context, brokerIDs ...string) (*scale.Result, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range brokerIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DemoteBrokers", varargs...)
+	ret0, _ := ret[0].(*scale.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DemoteBrokers indicates an expected call of DemoteBrokers.
+func (mr *MockCruiseControlScalerMockRecorder) DemoteBrokers(ctx interface{}, brokerIDs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, brokerIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DemoteBrokers", reflect.TypeOf((*MockCruiseControlScaler)(nil).DemoteBrokers), varargs...)
+}
+
+// FixOfflineReplicasWithParams mocks base method.
+func (m *MockCruiseControlScaler) FixOfflineReplicasWithParams(ctx context.Context, params map[string]string) (*scale.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FixOfflineReplicasWithParams", ctx, params)
+	ret0, _ := ret[0].(*scale.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FixOfflineReplicasWithParams indicates an expected call of FixOfflineReplicasWithParams.
+func (mr *MockCruiseControlScalerMockRecorder) FixOfflineReplicasWithParams(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FixOfflineReplicasWithParams", reflect.TypeOf((*MockCruiseControlScaler)(nil).FixOfflineReplicasWithParams), ctx, params)
+}
+
 // IsReady mocks base method.
 func (m *MockCruiseControlScaler) IsReady(ctx context.Context) bool {
 	m.ctrl.T.Helper()
@@ -310,6 +359,20 @@ func (mr *MockCruiseControlScalerMockRecorder) StopExecution(ctx interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopExecution", reflect.TypeOf((*MockCruiseControlScaler)(nil).StopExecution), ctx)
 }
 
+// SupportsFeature mocks base method.
+func (m *MockCruiseControlScaler) SupportsFeature(feature scale.Feature) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SupportsFeature", feature)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// SupportsFeature indicates an expected call of SupportsFeature.
+func (mr *MockCruiseControlScalerMockRecorder) SupportsFeature(feature interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SupportsFeature", reflect.TypeOf((*MockCruiseControlScaler)(nil).SupportsFeature), feature)
+}
+
 // UserTasks mocks base method.
 func (m *MockCruiseControlScaler) UserTasks(ctx context.Context, taskIDs ...string) ([]*scale.Result, error) {
 	m.ctrl.T.Helper()
@@ -329,3 +392,17 @@ func (mr *MockCruiseControlScalerMockRecorder) UserTasks(ctx interface{}, taskID
 	varargs := append([]interface{}{ctx}, taskIDs...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserTasks", reflect.TypeOf((*MockCruiseControlScaler)(nil).UserTasks), varargs...)
 }
+
+// Version mocks base method.
+func (m *MockCruiseControlScaler) Version() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockCruiseControlScalerMockRecorder) Version() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockCruiseControlScaler)(nil).Version))
+}
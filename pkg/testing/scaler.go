@@ -0,0 +1,518 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testing provides helpers for testing extensions and pipelines built around the custom resources of Koperator
+// without a real Cruise Control: a stateful fake CruiseControlScaler, a gomock based mock of the same interface in the
+// mocks subpackage, and an envtest environment with the Koperator CRDs installed.
+package testing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/banzaicloud/go-cruise-control/pkg/api"
+	"github.com/banzaicloud/go-cruise-control/pkg/types"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+)
+
+// FakeCruiseControlScaler is an in-memory implementation of scale.CruiseControlScaler. The operations create user
+// tasks which stay active until they are finished by CompleteTask or FailTask, while a task is active the executor of
+// Cruise Control is reported to be busy. It is safe for concurrent use.
+type FakeCruiseControlScaler struct {
+	mu sync.Mutex
+
+	status            scale.CruiseControlStatus
+	statusErr         error
+	operationErr      error
+	version           string
+	unsupported       map[scale.Feature]bool
+	brokers           map[string]scale.KafkaBrokerState
+	partitionReplicas map[string]int32
+	logDirs           map[string]map[scale.LogDirState][]string
+	tasks             map[string]*scale.Result
+	taskOrder         []string
+	adminConfigs      []scale.AdminConfig
+	nextTaskID        int
+}
+
+// NewFakeCruiseControlScaler returns a ready FakeCruiseControlScaler with the given alive brokers
+func NewFakeCruiseControlScaler(brokerIDs ...string) *FakeCruiseControlScaler {
+	f := &FakeCruiseControlScaler{
+		status: scale.CruiseControlStatus{
+			MonitorReady:       true,
+			ExecutorReady:      true,
+			AnalyzerReady:      true,
+			ProposalReady:      true,
+			GoalsReady:         true,
+			MonitoredWindows:   1,
+			MonitoringCoverage: 1,
+
+			ValidPartitionsPercentage: 100,
+			ExecutorState:             "NO_TASK_IN_PROGRESS",
+		},
+		unsupported:       make(map[scale.Feature]bool),
+		brokers:           make(map[string]scale.KafkaBrokerState),
+		partitionReplicas: make(map[string]int32),
+		logDirs:           make(map[string]map[scale.LogDirState][]string),
+		tasks:             make(map[string]*scale.Result),
+	}
+	for _, brokerID := range brokerIDs {
+		f.brokers[brokerID] = scale.KafkaBrokerAlive
+	}
+	return f
+}
+
+// ScaleFactory returns a scale factory, as used by the reconcilers of Koperator, which always returns the given scaler
+func ScaleFactory(scaler scale.CruiseControlScaler) func(ctx context.Context, kafkaCluster *v1beta1.KafkaCluster) (scale.CruiseControlScaler, error) {
+	return func(ctx context.Context, kafkaCluster *v1beta1.KafkaCluster) (scale.CruiseControlScaler, error) {
+		return scaler, nil
+	}
+}
+
+// SetStatus sets the status reported by Cruise Control. The executor is reported to be busy regardless of the
+// ExecutorReady field while a user task is active.
+func (f *FakeCruiseControlScaler) SetStatus(status scale.CruiseControlStatus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status = status
+}
+
+// SetStatusError makes Cruise Control unreachable when err is not nil
+func (f *FakeCruiseControlScaler) SetStatusError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statusErr = err
+}
+
+// SetOperationError makes the operations fail with the given error when err is not nil
+func (f *FakeCruiseControlScaler) SetOperationError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.operationErr = err
+}
+
+// SetVersion sets the version reported by Cruise Control
+func (f *FakeCruiseControlScaler) SetVersion(version string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.version = version
+}
+
+// SetFeatureSupported sets whether the feature is supported, every feature is supported by default
+func (f *FakeCruiseControlScaler) SetFeatureSupported(feature scale.Feature, supported bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unsupported[feature] = !supported
+}
+
+// SetBrokerState sets the state of the broker
+func (f *FakeCruiseControlScaler) SetBrokerState(brokerID string, state scale.KafkaBrokerState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.brokers[brokerID] = state
+}
+
+// SetPartitionReplicas sets the number of partition replicas hosted by the broker
+func (f *FakeCruiseControlScaler) SetPartitionReplicas(brokerID string, replicas int32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.partitionReplicas[brokerID] = replicas
+}
+
+// SetLogDirs sets the online and offline log directories of the broker
+func (f *FakeCruiseControlScaler) SetLogDirs(brokerID string, online, offline []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.logDirs[brokerID] = map[scale.LogDirState][]string{
+		scale.LogDirStateOnline:  online,
+		scale.LogDirStateOffline: offline,
+	}
+}
+
+// CompleteTask finishes the user task successfully
+func (f *FakeCruiseControlScaler) CompleteTask(taskID string) error {
+	return f.finishTask(taskID, v1beta1.CruiseControlTaskCompleted, nil)
+}
+
+// FailTask finishes the user task with the given error
+func (f *FakeCruiseControlScaler) FailTask(taskID string, err error) error {
+	return f.finishTask(taskID, v1beta1.CruiseControlTaskCompletedWithError, err)
+}
+
+func (f *FakeCruiseControlScaler) finishTask(taskID string, state v1beta1.CruiseControlUserTaskState, err error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	task, ok := f.tasks[taskID]
+	if !ok {
+		return fmt.Errorf("user task %s not found", taskID)
+	}
+	task.State = state
+	task.Err = err
+	return nil
+}
+
+// Tasks returns the user tasks in the order of their creation
+func (f *FakeCruiseControlScaler) Tasks() []scale.Result {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tasks := make([]scale.Result, 0, len(f.taskOrder))
+	for _, taskID := range f.taskOrder {
+		tasks = append(tasks, *f.tasks[taskID])
+	}
+	return tasks
+}
+
+// AdminConfigs returns the runtime configurations applied through Admin in the order they were applied
+func (f *FakeCruiseControlScaler) AdminConfigs() []scale.AdminConfig {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]scale.AdminConfig(nil), f.adminConfigs...)
+}
+
+func (f *FakeCruiseControlScaler) hasActiveTask() bool {
+	for _, task := range f.tasks {
+		if task.State == v1beta1.CruiseControlTaskActive || task.State == v1beta1.CruiseControlTaskInExecution {
+			return true
+		}
+	}
+	return false
+}
+
+// newTask creates an active user task for the operation
+func (f *FakeCruiseControlScaler) newTask(operation v1alpha1.CruiseControlTaskOperation, params map[string]string) (*scale.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.statusErr != nil {
+		return nil, f.statusErr
+	}
+	for param, value := range params {
+		if f.unsupported[scale.Feature(param)] && value != "false" {
+			return nil, scale.UnsupportedFeatureError{Feature: scale.Feature(param), Version: f.version}
+		}
+	}
+
+	f.nextTaskID++
+	query := url.Values{}
+	for param, value := range params {
+		query.Set(param, value)
+	}
+	task := &scale.Result{
+		TaskID:             fmt.Sprintf("fake-task-%d", f.nextTaskID),
+		StartedAt:          time.Now().UTC().Format(time.RFC1123),
+		ResponseStatusCode: 200,
+		RequestURL:         fmt.Sprintf("http://cruisecontrol/kafkacruisecontrol/%s?%s", operation, query.Encode()),
+		Result:             &types.OptimizationResult{},
+		State:              v1beta1.CruiseControlTaskActive,
+	}
+	if f.operationErr != nil {
+		task.ResponseStatusCode = 500
+		task.State = v1beta1.CruiseControlTaskCompletedWithError
+		task.Err = f.operationErr
+	}
+	f.tasks[task.TaskID] = task
+	f.taskOrder = append(f.taskOrder, task.TaskID)
+
+	result := *task
+	return &result, task.Err
+}
+
+// IsReady returns true when Cruise Control is reachable and ready
+func (f *FakeCruiseControlScaler) IsReady(ctx context.Context) bool {
+	status, err := f.Status(ctx)
+	return err == nil && status.IsReady()
+}
+
+// Status returns the status of Cruise Control
+func (f *FakeCruiseControlScaler) Status(ctx context.Context) (scale.CruiseControlStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.statusErr != nil {
+		return scale.CruiseControlStatus{}, f.statusErr
+	}
+	status := f.status
+	if f.hasActiveTask() {
+		status.ExecutorReady = false
+	}
+	return status, nil
+}
+
+// UserTasks returns the user tasks with the given IDs, or every user task when no ID is given
+func (f *FakeCruiseControlScaler) UserTasks(ctx context.Context, taskIDs ...string) ([]*scale.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.statusErr != nil {
+		return nil, f.statusErr
+	}
+	if len(taskIDs) == 0 {
+		taskIDs = f.taskOrder
+	}
+	results := make([]*scale.Result, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		if task, ok := f.tasks[taskID]; ok {
+			result := *task
+			results = append(results, &result)
+		}
+	}
+	return results, nil
+}
+
+// IsUp returns true when Cruise Control is reachable
+func (f *FakeCruiseControlScaler) IsUp(ctx context.Context) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.statusErr == nil
+}
+
+// AddBrokers creates an add_broker user task for the given brokers
+func (f *FakeCruiseControlScaler) AddBrokers(ctx context.Context, brokerIDs ...string) (*scale.Result, error) {
+	return f.AddBrokersWithParams(ctx, map[string]string{v1alpha1.ParamBrokerID: strings.Join(brokerIDs, ",")})
+}
+
+// AddBrokersWithParams creates an add_broker user task
+func (f *FakeCruiseControlScaler) AddBrokersWithParams(ctx context.Context, params map[string]string) (*scale.Result, error) {
+	return f.newTask(v1alpha1.OperationAddBroker, params)
+}
+
+// RemoveBrokersWithParams creates a remove_broker user task
+func (f *FakeCruiseControlScaler) RemoveBrokersWithParams(ctx context.Context, params map[string]string) (*scale.Result, error) {
+	return f.newTask(v1alpha1.OperationRemoveBroker, params)
+}
+
+// RebalanceWithParams creates a rebalance user task
+func (f *FakeCruiseControlScaler) RebalanceWithParams(ctx context.Context, params map[string]string) (*scale.Result, error) {
+	return f.newTask(v1alpha1.OperationRebalance, params)
+}
+
+// StopExecution creates a stop_proposal_execution user task and fails the active user tasks
+func (f *FakeCruiseControlScaler) StopExecution(ctx context.Context) (*scale.Result, error) {
+	result, err := f.newTask(v1alpha1.OperationStopExecution, nil)
+	if err != nil {
+		return result, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, task := range f.tasks {
+		if task.TaskID != result.TaskID && (task.State == v1beta1.CruiseControlTaskActive || task.State == v1beta1.CruiseControlTaskInExecution) {
+			task.State = v1beta1.CruiseControlTaskCompletedWithError
+			task.Err = fmt.Errorf("execution of user task %s has been stopped", task.TaskID)
+		}
+	}
+	f.tasks[result.TaskID].State = v1beta1.CruiseControlTaskCompleted
+	return result, nil
+}
+
+// RemoveBrokers creates a remove_broker user task for the given brokers
+func (f *FakeCruiseControlScaler) RemoveBrokers(ctx context.Context, brokerIDs ...string) (*scale.Result, error) {
+	return f.RemoveBrokersWithParams(ctx, map[string]string{v1alpha1.ParamBrokerID: strings.Join(brokerIDs, ",")})
+}
+
+// DemoteBrokers creates a demote_broker user task for the given brokers
+func (f *FakeCruiseControlScaler) DemoteBrokers(ctx context.Context, brokerIDs ...string) (*scale.Result, error) {
+	return f.newTask(v1alpha1.OperationDemoteBroker, map[string]string{v1alpha1.ParamBrokerID: strings.Join(brokerIDs, ",")})
+}
+
+// FixOfflineReplicasWithParams creates a fix_offline_replicas user task
+func (f *FakeCruiseControlScaler) FixOfflineReplicasWithParams(ctx context.Context, params map[string]string) (*scale.Result, error) {
+	return f.newTask(v1alpha1.OperationFixOfflineReplicas, params)
+}
+
+// RebalanceDisks creates an intra-broker rebalance user task for the given brokers
+func (f *FakeCruiseControlScaler) RebalanceDisks(ctx context.Context, brokerIDs ...string) (*scale.Result, error) {
+	return f.RebalanceWithParams(ctx, map[string]string{
+		v1alpha1.ParamDestbrokerIDs: strings.Join(brokerIDs, ","),
+		v1alpha1.ParamRebalanceDisk: "true",
+	})
+}
+
+// BrokersWithState returns the brokers in any of the given states ordered by their ID
+func (f *FakeCruiseControlScaler) BrokersWithState(ctx context.Context, states ...scale.KafkaBrokerState) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.statusErr != nil {
+		return nil, f.statusErr
+	}
+	brokerIDs := make([]string, 0, len(f.brokers))
+	for brokerID, brokerState := range f.brokers {
+		for _, state := range states {
+			if brokerState == state {
+				brokerIDs = append(brokerIDs, brokerID)
+				break
+			}
+		}
+	}
+	sort.Strings(brokerIDs)
+	return brokerIDs, nil
+}
+
+// KafkaClusterState returns an empty Kafka cluster state
+func (f *FakeCruiseControlScaler) KafkaClusterState(ctx context.Context) (*types.KafkaClusterState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.statusErr != nil {
+		return nil, f.statusErr
+	}
+	return &types.KafkaClusterState{}, nil
+}
+
+// PartitionReplicasByBroker returns the number of partition replicas hosted by the brokers
+func (f *FakeCruiseControlScaler) PartitionReplicasByBroker(ctx context.Context) (map[string]int32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.statusErr != nil {
+		return nil, f.statusErr
+	}
+	replicas := make(map[string]int32, len(f.brokers))
+	for brokerID := range f.brokers {
+		replicas[brokerID] = f.partitionReplicas[brokerID]
+	}
+	return replicas, nil
+}
+
+// BrokerWithLeastPartitionReplicas returns the alive broker hosting the least partition replicas
+func (f *FakeCruiseControlScaler) BrokerWithLeastPartitionReplicas(ctx context.Context) (string, error) {
+	replicas, err := f.PartitionReplicasByBroker(ctx)
+	if err != nil {
+		return "", err
+	}
+	aliveBrokers, err := f.BrokersWithState(ctx, scale.KafkaBrokerAlive)
+	if err != nil {
+		return "", err
+	}
+	var brokerWithLeastReplicas string
+	for _, brokerID := range aliveBrokers {
+		if brokerWithLeastReplicas == "" || replicas[brokerID] < replicas[brokerWithLeastReplicas] {
+			brokerWithLeastReplicas = brokerID
+		}
+	}
+	return brokerWithLeastReplicas, nil
+}
+
+// LogDirsByBroker returns the log directories of the brokers
+func (f *FakeCruiseControlScaler) LogDirsByBroker(ctx context.Context) (map[string]map[scale.LogDirState][]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.statusErr != nil {
+		return nil, f.statusErr
+	}
+	logDirs := make(map[string]map[scale.LogDirState][]string, len(f.logDirs))
+	for brokerID, dirs := range f.logDirs {
+		logDirs[brokerID] = map[scale.LogDirState][]string{
+			scale.LogDirStateOnline:  append([]string(nil), dirs[scale.LogDirStateOnline]...),
+			scale.LogDirStateOffline: append([]string(nil), dirs[scale.LogDirStateOffline]...),
+		}
+	}
+	return logDirs, nil
+}
+
+// KafkaClusterLoad returns an empty Kafka cluster load
+func (f *FakeCruiseControlScaler) KafkaClusterLoad(ctx context.Context) (*api.KafkaClusterLoadResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.statusErr != nil {
+		return nil, f.statusErr
+	}
+	return &api.KafkaClusterLoadResponse{}, nil
+}
+
+// Version returns the version set by SetVersion
+func (f *FakeCruiseControlScaler) Version() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.version
+}
+
+// SupportsFeature returns false for the features disabled by SetFeatureSupported
+func (f *FakeCruiseControlScaler) SupportsFeature(feature scale.Feature) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.unsupported[feature]
+}
+
+// Admin records the runtime configuration and mirrors the self-healing and recently removed or demoted broker
+// changes into the status
+func (f *FakeCruiseControlScaler) Admin(ctx context.Context, config scale.AdminConfig) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.statusErr != nil {
+		return f.statusErr
+	}
+	if f.operationErr != nil {
+		return f.operationErr
+	}
+	f.adminConfigs = append(f.adminConfigs, config)
+
+	f.status.SelfHealingEnabled = addValues(removeValues(f.status.SelfHealingEnabled, config.DisableSelfHealingFor), config.EnableSelfHealingFor)
+	f.status.SelfHealingDisabled = addValues(removeValues(f.status.SelfHealingDisabled, config.EnableSelfHealingFor), config.DisableSelfHealingFor)
+	f.status.RecentlyRemovedBrokers = removeBrokerIDs(f.status.RecentlyRemovedBrokers, config.DropRecentlyRemovedBrokers)
+	f.status.RecentlyDemotedBrokers = removeBrokerIDs(f.status.RecentlyDemotedBrokers, config.DropRecentlyDemotedBrokers)
+	return nil
+}
+
+func addValues(values, add []string) []string {
+	for _, value := range add {
+		if !containsString(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func removeValues(values, remove []string) []string {
+	var ret []string
+	for _, value := range values {
+		if !containsString(remove, value) {
+			ret = append(ret, value)
+		}
+	}
+	return ret
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func removeBrokerIDs(brokerIDs, remove []int32) []int32 {
+	var ret []int32
+	for _, brokerID := range brokerIDs {
+		drop := false
+		for _, r := range remove {
+			if r == brokerID {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			ret = append(ret, brokerID)
+		}
+	}
+	return ret
+}
+
+// blank assignment to verify that FakeCruiseControlScaler implements scale.CruiseControlScaler
+var _ scale.CruiseControlScaler = &FakeCruiseControlScaler{}
@@ -0,0 +1,137 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
+	koperatortesting "github.com/banzaicloud/koperator/pkg/testing"
+)
+
+func TestFakeCruiseControlScalerTasks(t *testing.T) {
+	ctx := context.Background()
+	scaler := koperatortesting.NewFakeCruiseControlScaler("0", "1", "2")
+
+	status, err := scaler.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, status.IsReady())
+	assert.False(t, status.InExecution())
+
+	result, err := scaler.RemoveBrokersWithParams(ctx, map[string]string{v1alpha1.ParamBrokerID: "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, v1beta1.CruiseControlTaskActive, result.State)
+
+	// The executor is busy while the task is active
+	status, err = scaler.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, status.InExecution())
+
+	assert.NoError(t, scaler.CompleteTask(result.TaskID))
+	tasks, err := scaler.UserTasks(ctx, result.TaskID)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, v1beta1.CruiseControlTaskCompleted, tasks[0].State)
+	assert.Error(t, scaler.CompleteTask("missing"))
+
+	status, err = scaler.Status(ctx)
+	assert.NoError(t, err)
+	assert.False(t, status.InExecution())
+
+	// Stopping the execution fails the active tasks
+	result, err = scaler.RebalanceWithParams(ctx, nil)
+	assert.NoError(t, err)
+	_, err = scaler.StopExecution(ctx)
+	assert.NoError(t, err)
+	tasks, err = scaler.UserTasks(ctx, result.TaskID)
+	assert.NoError(t, err)
+	assert.Equal(t, v1beta1.CruiseControlTaskCompletedWithError, tasks[0].State)
+	assert.Len(t, scaler.Tasks(), 3)
+}
+
+func TestFakeCruiseControlScalerFailures(t *testing.T) {
+	ctx := context.Background()
+	scaler := koperatortesting.NewFakeCruiseControlScaler("0")
+
+	scaler.SetOperationError(errors.New("boom"))
+	result, err := scaler.AddBrokers(ctx, "1")
+	assert.Error(t, err)
+	assert.Equal(t, v1beta1.CruiseControlTaskCompletedWithError, result.State)
+	scaler.SetOperationError(nil)
+
+	scaler.SetFeatureSupported(scale.FeatureRebalanceDisk, false)
+	_, err = scaler.RebalanceDisks(ctx, "0")
+	assert.True(t, scale.IsUnsupportedFeatureError(err))
+	assert.False(t, scaler.SupportsFeature(scale.FeatureRebalanceDisk))
+
+	scaler.SetStatusError(errors.New("connection refused"))
+	assert.False(t, scaler.IsUp(ctx))
+	assert.False(t, scaler.IsReady(ctx))
+	_, err = scaler.Status(ctx)
+	assert.Error(t, err)
+}
+
+func TestFakeCruiseControlScalerBrokers(t *testing.T) {
+	ctx := context.Background()
+	scaler := koperatortesting.NewFakeCruiseControlScaler("0", "1", "2")
+	scaler.SetBrokerState("2", scale.KafkaBrokerDead)
+	scaler.SetPartitionReplicas("0", 10)
+	scaler.SetPartitionReplicas("1", 5)
+
+	alive, err := scaler.BrokersWithState(ctx, scale.KafkaBrokerAlive)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0", "1"}, alive)
+
+	broker, err := scaler.BrokerWithLeastPartitionReplicas(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", broker)
+}
+
+func TestFakeCruiseControlScalerAdmin(t *testing.T) {
+	ctx := context.Background()
+	scaler := koperatortesting.NewFakeCruiseControlScaler()
+	scaler.SetStatus(scale.CruiseControlStatus{
+		MonitorReady:           true,
+		AnalyzerReady:          true,
+		ExecutorReady:          true,
+		SelfHealingDisabled:    []string{"BROKER_FAILURE"},
+		RecentlyRemovedBrokers: []int32{1, 2},
+	})
+
+	assert.NoError(t, scaler.Admin(ctx, scale.AdminConfig{
+		EnableSelfHealingFor:       []string{"BROKER_FAILURE"},
+		DropRecentlyRemovedBrokers: []int32{1},
+	}))
+
+	status, err := scaler.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BROKER_FAILURE"}, status.SelfHealingEnabled)
+	assert.Empty(t, status.SelfHealingDisabled)
+	assert.Equal(t, []int32{2}, status.RecentlyRemovedBrokers)
+	assert.Len(t, scaler.AdminConfigs(), 1)
+}
+
+func TestCRDDirectory(t *testing.T) {
+	info, err := os.Stat(koperatortesting.CRDDirectory())
+	assert.NoError(t, err)
+	assert.True(t, info.IsDir())
+}
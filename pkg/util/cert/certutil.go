@@ -34,6 +34,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
 )
 
 const (
@@ -231,6 +232,30 @@ func GenerateJKS(certs []*x509.Certificate, privateKey []byte) (out, passw []byt
 	return outBuf.Bytes(), password, err
 }
 
+// GenerateTrustStoreJKS creates a JKS truststore protected with the given password from the CA certificates.
+// The output only depends on the certificates so that it does not change between reconciliations.
+func GenerateTrustStoreJKS(caCerts []*x509.Certificate, password []byte) ([]byte, error) {
+	jksTrustStore := jks.New(jks.WithOrderedAliases())
+	for i, caCert := range caCerts {
+		caIn := jks.TrustedCertificateEntry{
+			CreationTime: caCert.NotBefore,
+			Certificate: jks.Certificate{
+				Type:    "X.509",
+				Content: caCert.Raw,
+			},
+		}
+		if err := jksTrustStore.SetTrustedCertificateEntry(fmt.Sprintf("trusted_ca_%03d", i), caIn); err != nil {
+			return nil, err
+		}
+	}
+
+	var outBuf bytes.Buffer
+	if err := jksTrustStore.Store(&outBuf, password); err != nil {
+		return nil, err
+	}
+	return outBuf.Bytes(), nil
+}
+
 // GenerateTestCert is used from unit tests for generating certificates
 func GenerateTestCert() (cert, key []byte, expectedDn string, err error) {
 	priv, serialNumber, err := generatePrivateKey()
@@ -317,32 +342,77 @@ func GenerateSigningRequestInPemFormat(priv *rsa.PrivateKey, commonName string,
 	return signingReq, err
 }
 
-func checkSSLCertInJKS(data map[string][]byte) error {
+func checkSSLCertInStores(data map[string][]byte, keyStoreType, trustStoreType v1beta1.KeyStoreType) error {
 	var err error
-	if len(data[v1alpha1.TLSJKSTrustStore]) == 0 {
-		err = errors.Combine(err, fmt.Errorf("%s entry is missing", v1alpha1.TLSJKSTrustStore))
+	if trustStoreKey := TrustStoreFileName(trustStoreType); len(data[trustStoreKey]) == 0 {
+		err = errors.Combine(err, fmt.Errorf("%s entry is missing", trustStoreKey))
 	}
-	if len(data[v1alpha1.TLSJKSKeyStore]) == 0 {
-		err = errors.Combine(err, fmt.Errorf("%s entry is missing", v1alpha1.TLSJKSKeyStore))
+	if keyStoreKey := KeyStoreFileName(keyStoreType); len(data[keyStoreKey]) == 0 {
+		err = errors.Combine(err, fmt.Errorf("%s entry is missing", keyStoreKey))
 	}
 	if len(data[v1alpha1.PasswordKey]) == 0 {
 		err = errors.Combine(err, fmt.Errorf("%s entry is missing", v1alpha1.PasswordKey))
 	}
 
 	if err != nil {
-		err = errors.WrapIff(err, "there is missing data entry for JKS format based certificates")
+		err = errors.WrapIff(err, "there is missing data entry for %s format based certificates", keyStoreType)
 	}
 
 	return err
 }
 
 func CheckSSLCertSecret(secret *corev1.Secret) error {
-	if err := checkSSLCertInJKS(secret.Data); err != nil {
+	return CheckSSLCertSecretWithStoreTypes(secret, v1beta1.KeyStoreTypeJKS, v1beta1.KeyStoreTypeJKS)
+}
+
+// CheckSSLCertSecretWithStoreTypes checks that the secret holds the keystore and the truststore of the given formats
+func CheckSSLCertSecretWithStoreTypes(secret *corev1.Secret, keyStoreType, trustStoreType v1beta1.KeyStoreType) error {
+	if err := checkSSLCertInStores(secret.Data, keyStoreType, trustStoreType); err != nil {
 		return errors.WrapIfWithDetails(err, "couldn't get certificates from secret", "name", secret.GetName(), "namespace", secret.GetNamespace())
 	}
 	return nil
 }
 
+// KeyStoreFileName returns the key of the keystore of the given format in the certificate secrets
+func KeyStoreFileName(storeType v1beta1.KeyStoreType) string {
+	if storeType == v1beta1.KeyStoreTypePKCS12 {
+		return v1alpha1.TLSPKCS12KeyStore
+	}
+	return v1alpha1.TLSJKSKeyStore
+}
+
+// TrustStoreFileName returns the key of the truststore of the given format in the certificate secrets
+func TrustStoreFileName(storeType v1beta1.KeyStoreType) string {
+	if storeType == v1beta1.KeyStoreTypePKCS12 {
+		return v1alpha1.TLSPKCS12TrustStore
+	}
+	return v1alpha1.TLSJKSTrustStore
+}
+
+// ParseKeyStoreOfTypeToTLSCertificate parses the keystore of the given format
+func ParseKeyStoreOfTypeToTLSCertificate(storeType v1beta1.KeyStoreType, keystore, password []byte) (tls.Certificate, error) {
+	if storeType == v1beta1.KeyStoreTypePKCS12 {
+		return ParsePKCS12KeyStoreToTLSCertificate(keystore, password)
+	}
+	return ParseKeyStoreToTLSCertificate(keystore, password)
+}
+
+// ParseTrustStoreOfTypeToCaChain parses the truststore of the given format
+func ParseTrustStoreOfTypeToCaChain(storeType v1beta1.KeyStoreType, truststore, password []byte) ([]*x509.Certificate, error) {
+	if storeType == v1beta1.KeyStoreTypePKCS12 {
+		return ParsePKCS12TrustStoreToCaChain(truststore, password)
+	}
+	return ParseTrustStoreToCaChain(truststore, password)
+}
+
+// GenerateTrustStoreOfType creates a truststore of the given format from the CA certificates
+func GenerateTrustStoreOfType(storeType v1beta1.KeyStoreType, caCerts []*x509.Certificate, password []byte) ([]byte, error) {
+	if storeType == v1beta1.KeyStoreTypePKCS12 {
+		return GenerateTrustStorePKCS12(caCerts, password)
+	}
+	return GenerateTrustStoreJKS(caCerts, password)
+}
+
 func ParseTrustStoreToCaChain(truststore, password []byte) ([]*x509.Certificate, error) {
 	jksTrustStore := jks.New()
 	err := jksTrustStore.Load(bytes.NewReader(truststore), password)
@@ -229,3 +229,44 @@ func TestParseKeyStoreToTLSCertificate(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateTrustStoreJKS(t *testing.T) {
+	var caCerts []*x509.Certificate
+	for i := 0; i < 2; i++ {
+		certPEM, _, _, err := GenerateTestCert()
+		if err != nil {
+			t.Fatal("Failed to generate test certificate", err)
+		}
+		caCert, err := DecodeCertificate(certPEM)
+		if err != nil {
+			t.Fatal("Failed to decode test certificate", err)
+		}
+		caCerts = append(caCerts, caCert)
+	}
+	password := []byte("test-password")
+
+	trustStore, err := GenerateTrustStoreJKS(caCerts, password)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	again, err := GenerateTrustStoreJKS(caCerts, password)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if !bytes.Equal(trustStore, again) {
+		t.Error("Expected the truststore to be the same for the same certificates")
+	}
+
+	parsed, err := ParseTrustStoreToCaChain(trustStore, password)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if len(parsed) != 2 {
+		t.Fatal("Expected two CA certificates in the truststore, got:", len(parsed))
+	}
+	for _, caCert := range caCerts {
+		if !caCert.Equal(parsed[0]) && !caCert.Equal(parsed[1]) {
+			t.Error("Expected the CA certificate in the truststore:", caCert.Subject)
+		}
+	}
+}
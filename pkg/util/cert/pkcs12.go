@@ -0,0 +1,536 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"hash"
+	"unicode/utf16"
+
+	"emperror.dev/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// pkcs12MacIterations is the iteration count of the integrity MAC of the generated PKCS#12 truststores
+const pkcs12MacIterations = 10000
+
+var (
+	oidDataContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedDataContentType = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+
+	oidKeyBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidPKCS8ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidX509Certificate     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+
+	oidFriendlyName = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	// oidJavaTrustedKeyUsage marks the certificate entries Java loads as trusted certificates from PKCS#12 stores
+	oidJavaTrustedKeyUsage = asn1.ObjectIdentifier{2, 16, 840, 1, 113894, 746875, 1, 1}
+	oidAnyExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37, 0}
+
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+
+	oidPBEWithSHAAnd3KeyTripleDESCBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidPBES2                         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2                        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1                  = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256                = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES128CBC                     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC                     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC                     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC                    = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+type pfxPdu struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           []byte `asn1:"tag:0,optional"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type pbeParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// ParsePKCS12TrustStoreToCaChain returns the certificates of the PKCS#12 truststore protected with the given password
+func ParsePKCS12TrustStoreToCaChain(truststore, password []byte) ([]*x509.Certificate, error) {
+	bags, err := pkcs12SafeBags(truststore, password)
+	if err != nil {
+		return nil, err
+	}
+	var caCerts []*x509.Certificate
+	for _, bag := range bags {
+		if !bag.ID.Equal(oidCertBag) {
+			continue
+		}
+		caCert, err := bag.certificate()
+		if err != nil {
+			return nil, err
+		}
+		caCerts = append(caCerts, caCert)
+	}
+	if len(caCerts) == 0 {
+		return nil, errors.New("couldn't find certificate in truststore")
+	}
+	return caCerts, nil
+}
+
+// ParsePKCS12KeyStoreToTLSCertificate returns the private key of the PKCS#12 keystore protected with the given
+// password together with its certificate chain
+func ParsePKCS12KeyStoreToTLSCertificate(keystore, password []byte) (tls.Certificate, error) {
+	bags, err := pkcs12SafeBags(keystore, password)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	var privKeys []crypto.Signer
+	var certs []*x509.Certificate
+	for _, bag := range bags {
+		switch {
+		case bag.ID.Equal(oidCertBag):
+			cert, err := bag.certificate()
+			if err != nil {
+				return tls.Certificate{}, err
+			}
+			certs = append(certs, cert)
+		case bag.ID.Equal(oidKeyBag), bag.ID.Equal(oidPKCS8ShroudedKeyBag):
+			privKey, err := bag.privateKey(password)
+			if err != nil {
+				return tls.Certificate{}, err
+			}
+			privKeys = append(privKeys, privKey)
+		}
+	}
+	// When there are more private keys then how can we know which one should be used
+	if len(privKeys) > 1 {
+		return tls.Certificate{}, fmt.Errorf("keystore should contains only one private key entry, but got: %d", len(privKeys))
+	} else if len(privKeys) == 0 {
+		return tls.Certificate{}, errors.New("couldn't find private key entry in keystore")
+	}
+
+	publicKey, ok := privKeys[0].Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok {
+		return tls.Certificate{}, errors.New("private key couldn't be recognized")
+	}
+	ret := tls.Certificate{PrivateKey: privKeys[0]}
+	for _, cert := range certs {
+		if ret.Leaf == nil && publicKey.Equal(cert.PublicKey) {
+			ret.Leaf = cert
+			ret.Certificate = append([][]byte{cert.Raw}, ret.Certificate...)
+			continue
+		}
+		ret.Certificate = append(ret.Certificate, cert.Raw)
+	}
+	if ret.Leaf == nil {
+		return tls.Certificate{}, errors.New("couldn't find the certificate of the private key in keystore")
+	}
+	return ret, nil
+}
+
+// GenerateTrustStorePKCS12 creates a PKCS#12 truststore protected with the given password from the CA certificates.
+// The certificates are stored unencrypted and marked as trusted for Java, the MAC salt is derived from the
+// certificates so that the output does not change between reconciliations.
+func GenerateTrustStorePKCS12(caCerts []*x509.Certificate, password []byte) ([]byte, error) {
+	trustedKeyUsage, err := asn1.Marshal(oidAnyExtendedKeyUsage)
+	if err != nil {
+		return nil, err
+	}
+	saltHash := sha256.New()
+	bags := make([]safeBag, 0, len(caCerts))
+	for i, caCert := range caCerts {
+		saltHash.Write(caCert.Raw)
+		value, err := asn1.Marshal(certBag{ID: oidX509Certificate, Data: caCert.Raw})
+		if err != nil {
+			return nil, err
+		}
+		friendlyName, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagBMPString, Bytes: bmpString(fmt.Sprintf("trusted_ca_%03d", i))})
+		if err != nil {
+			return nil, err
+		}
+		bags = append(bags, safeBag{
+			ID:    oidCertBag,
+			Value: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: value},
+			Attributes: []pkcs12Attribute{
+				{ID: oidFriendlyName, Value: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: friendlyName}},
+				{ID: oidJavaTrustedKeyUsage, Value: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: trustedKeyUsage}},
+			},
+		})
+	}
+
+	safeContents, err := asn1.Marshal(bags)
+	if err != nil {
+		return nil, err
+	}
+	safe, err := dataContentInfo(safeContents)
+	if err != nil {
+		return nil, err
+	}
+	authSafe, err := asn1.Marshal([]contentInfo{safe})
+	if err != nil {
+		return nil, err
+	}
+	pfx := pfxPdu{Version: 3}
+	if pfx.AuthSafe, err = dataContentInfo(authSafe); err != nil {
+		return nil, err
+	}
+	salt := saltHash.Sum(nil)[:20]
+	pfx.MacData = macData{
+		Mac: digestInfo{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA1, Parameters: asn1.NullRawValue},
+			Digest:    pkcs12MAC(sha1.New, authSafe, salt, password, pkcs12MacIterations),
+		},
+		MacSalt:    salt,
+		Iterations: pkcs12MacIterations,
+	}
+	return asn1.Marshal(pfx)
+}
+
+func dataContentInfo(data []byte) (contentInfo, error) {
+	content, err := asn1.Marshal(data)
+	if err != nil {
+		return contentInfo{}, err
+	}
+	return contentInfo{
+		ContentType: oidDataContentType,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+	}, nil
+}
+
+// pkcs12SafeBags returns the safe bags of the PKCS#12 store after checking its integrity with the password
+func pkcs12SafeBags(data, password []byte) ([]safeBag, error) {
+	pfx := pfxPdu{}
+	if err := unmarshalDER(data, &pfx); err != nil {
+		return nil, errors.WrapIf(err, "couldn't parse PKCS#12 store")
+	}
+	if pfx.Version != 3 {
+		return nil, errors.Errorf("unsupported PKCS#12 store version: %d", pfx.Version)
+	}
+	if !pfx.AuthSafe.ContentType.Equal(oidDataContentType) {
+		return nil, errors.New("only password protected PKCS#12 stores are supported")
+	}
+	var authSafe []byte
+	if err := unmarshalDER(pfx.AuthSafe.Content.Bytes, &authSafe); err != nil {
+		return nil, errors.WrapIf(err, "couldn't parse PKCS#12 store")
+	}
+
+	var macHash func() hash.Hash
+	switch macAlgorithm := pfx.MacData.Mac.Algorithm.Algorithm; {
+	case macAlgorithm.Equal(oidSHA1):
+		macHash = sha1.New
+	case macAlgorithm.Equal(oidSHA256):
+		macHash = sha256.New
+	default:
+		return nil, errors.Errorf("unsupported PKCS#12 MAC algorithm: %s", macAlgorithm)
+	}
+	expectedMAC := pkcs12MAC(macHash, authSafe, pfx.MacData.MacSalt, password, pfx.MacData.Iterations)
+	if !hmac.Equal(pfx.MacData.Mac.Digest, expectedMAC) {
+		return nil, errors.New("PKCS#12 store password is incorrect or the store is corrupted")
+	}
+
+	var contents []contentInfo
+	if err := unmarshalDER(authSafe, &contents); err != nil {
+		return nil, errors.WrapIf(err, "couldn't parse PKCS#12 store")
+	}
+	var bags []safeBag
+	for _, content := range contents {
+		var safeContents []byte
+		switch {
+		case content.ContentType.Equal(oidDataContentType):
+			if err := unmarshalDER(content.Content.Bytes, &safeContents); err != nil {
+				return nil, errors.WrapIf(err, "couldn't parse PKCS#12 store")
+			}
+		case content.ContentType.Equal(oidEncryptedDataContentType):
+			encrypted := encryptedData{}
+			if err := unmarshalDER(content.Content.Bytes, &encrypted); err != nil {
+				return nil, errors.WrapIf(err, "couldn't parse PKCS#12 store")
+			}
+			decrypted, err := pbeDecrypt(encrypted.EncryptedContentInfo.ContentEncryptionAlgorithm,
+				encrypted.EncryptedContentInfo.EncryptedContent, password)
+			if err != nil {
+				return nil, err
+			}
+			safeContents = decrypted
+		default:
+			return nil, errors.Errorf("unsupported PKCS#12 content type: %s", content.ContentType)
+		}
+		var contentBags []safeBag
+		if err := unmarshalDER(safeContents, &contentBags); err != nil {
+			return nil, errors.WrapIf(err, "couldn't parse PKCS#12 store")
+		}
+		bags = append(bags, contentBags...)
+	}
+	return bags, nil
+}
+
+func (b safeBag) certificate() (*x509.Certificate, error) {
+	bag := certBag{}
+	if err := unmarshalDER(b.Value.Bytes, &bag); err != nil {
+		return nil, errors.WrapIf(err, "couldn't parse certificate of PKCS#12 store")
+	}
+	if !bag.ID.Equal(oidX509Certificate) {
+		return nil, errors.Errorf("unsupported certificate type in PKCS#12 store: %s", bag.ID)
+	}
+	return x509.ParseCertificate(bag.Data)
+}
+
+func (b safeBag) privateKey(password []byte) (crypto.Signer, error) {
+	der := b.Value.Bytes
+	if b.ID.Equal(oidPKCS8ShroudedKeyBag) {
+		keyInfo := encryptedPrivateKeyInfo{}
+		if err := unmarshalDER(b.Value.Bytes, &keyInfo); err != nil {
+			return nil, errors.WrapIf(err, "couldn't parse private key of PKCS#12 store")
+		}
+		decrypted, err := pbeDecrypt(keyInfo.Algorithm, keyInfo.EncryptedData, password)
+		if err != nil {
+			return nil, err
+		}
+		der = decrypted
+	}
+	parsedKey, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	privKey, ok := parsedKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key couldn't be recognized")
+	}
+	return privKey, nil
+}
+
+// pbeDecrypt decrypts the content encrypted with the password based encryption schemes used by Java and OpenSSL
+func pbeDecrypt(algorithm pkix.AlgorithmIdentifier, encrypted, password []byte) ([]byte, error) {
+	var block cipher.Block
+	var iv []byte
+	switch {
+	case algorithm.Algorithm.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC):
+		params := pbeParams{}
+		if err := unmarshalDER(algorithm.Parameters.FullBytes, &params); err != nil {
+			return nil, errors.WrapIf(err, "couldn't parse PKCS#12 encryption parameters")
+		}
+		encodedPassword := bmpPassword(password)
+		key := pkcs12KDF(sha1.New, params.Salt, encodedPassword, params.Iterations, 1, 24)
+		iv = pkcs12KDF(sha1.New, params.Salt, encodedPassword, params.Iterations, 2, des.BlockSize)
+		var err error
+		if block, err = des.NewTripleDESCipher(key); err != nil {
+			return nil, err
+		}
+	case algorithm.Algorithm.Equal(oidPBES2):
+		params := pbes2Params{}
+		if err := unmarshalDER(algorithm.Parameters.FullBytes, &params); err != nil {
+			return nil, errors.WrapIf(err, "couldn't parse PKCS#12 encryption parameters")
+		}
+		if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+			return nil, errors.Errorf("unsupported PKCS#12 key derivation function: %s", params.KeyDerivationFunc.Algorithm)
+		}
+		kdfParams := pbkdf2Params{}
+		if err := unmarshalDER(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+			return nil, errors.WrapIf(err, "couldn't parse PKCS#12 key derivation parameters")
+		}
+		prf := sha1.New
+		switch {
+		case len(kdfParams.PRF.Algorithm) == 0, kdfParams.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		case kdfParams.PRF.Algorithm.Equal(oidHMACWithSHA256):
+			prf = sha256.New
+		default:
+			return nil, errors.Errorf("unsupported PKCS#12 key derivation PRF: %s", kdfParams.PRF.Algorithm)
+		}
+		newCipher, keyLength := aes.NewCipher, 0
+		switch scheme := params.EncryptionScheme.Algorithm; {
+		case scheme.Equal(oidAES128CBC):
+			keyLength = 16
+		case scheme.Equal(oidAES192CBC):
+			keyLength = 24
+		case scheme.Equal(oidAES256CBC):
+			keyLength = 32
+		case scheme.Equal(oidDESEDE3CBC):
+			newCipher, keyLength = des.NewTripleDESCipher, 24
+		default:
+			return nil, errors.Errorf("unsupported PKCS#12 encryption scheme: %s", scheme)
+		}
+		if err := unmarshalDER(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+			return nil, errors.WrapIf(err, "couldn't parse PKCS#12 encryption parameters")
+		}
+		var err error
+		if block, err = newCipher(pbkdf2.Key(password, kdfParams.Salt, kdfParams.Iterations, keyLength, prf)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unsupported PKCS#12 encryption algorithm: %s", algorithm.Algorithm)
+	}
+
+	if len(iv) != block.BlockSize() || len(encrypted) == 0 || len(encrypted)%block.BlockSize() != 0 {
+		return nil, errors.New("invalid PKCS#12 encrypted content")
+	}
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, encrypted)
+	padding := int(decrypted[len(decrypted)-1])
+	if padding == 0 || padding > block.BlockSize() ||
+		!bytes.Equal(decrypted[len(decrypted)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("PKCS#12 store password is incorrect or the store is corrupted")
+	}
+	return decrypted[:len(decrypted)-padding], nil
+}
+
+// pkcs12MAC returns the integrity MAC of the PKCS#12 store content
+func pkcs12MAC(h func() hash.Hash, message, salt, password []byte, iterations int) []byte {
+	key := pkcs12KDF(h, salt, bmpPassword(password), iterations, 3, h().Size())
+	mac := hmac.New(h, key)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// pkcs12KDF derives key material from the password as defined in RFC 7292 appendix B.2
+func pkcs12KDF(h func() hash.Hash, salt, password []byte, iterations int, id byte, size int) []byte {
+	digest := h()
+	u, v := digest.Size(), digest.BlockSize()
+	diversifier := bytes.Repeat([]byte{id}, v)
+	input := append(fillBlocks(salt, v, len(salt)), fillBlocks(password, v, len(password))...)
+
+	var ret []byte
+	for len(ret) < size {
+		digest.Reset()
+		digest.Write(diversifier)
+		digest.Write(input)
+		a := digest.Sum(nil)
+		for i := 1; i < iterations; i++ {
+			digest.Reset()
+			digest.Write(a)
+			a = digest.Sum(nil)
+		}
+		ret = append(ret, a[:u]...)
+
+		// every block of the input is incremented by a+1 modulo 2^(8v)
+		b := fillBlocks(a, v, v)
+		for j := 0; j < len(input); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(input[j+k]) + int(b[k]) + carry
+				input[j+k] = byte(sum)
+				carry = sum >> 8
+			}
+		}
+	}
+	return ret[:size]
+}
+
+// fillBlocks repeats the data to fill the blocks of size v needed for n bytes
+func fillBlocks(data []byte, v, n int) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	ret := make([]byte, v*((n+v-1)/v))
+	for i := range ret {
+		ret[i] = data[i%len(data)]
+	}
+	return ret
+}
+
+// bmpString returns the UTF-16 big endian encoding of the string
+func bmpString(s string) []byte {
+	encoded := utf16.Encode([]rune(s))
+	ret := make([]byte, 0, 2*len(encoded)+2)
+	for _, r := range encoded {
+		ret = append(ret, byte(r>>8), byte(r))
+	}
+	return ret
+}
+
+// bmpPassword returns the null terminated BMPString encoding of the password used by the PKCS#12 key derivation
+func bmpPassword(password []byte) []byte {
+	return append(bmpString(string(password)), 0, 0)
+}
+
+func unmarshalDER(data []byte, out interface{}) error {
+	rest, err := asn1.Unmarshal(data, out)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errors.New("trailing data found")
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+)
+
+func TestParsePKCS12KeyStoreToTLSCertificate(t *testing.T) {
+	testCases := []struct {
+		testName string
+		keyStore string
+		password string
+		succeed  bool
+	}{
+		{
+			testName: "aes",
+			//nolint:lll
+			keyStore: "MIIEDAIBAzCCA8IGCSqGSIb3DQEHAaCCA7MEggOvMIIDqzCCAmIGCSqGSIb3DQEHBqCCAlMwggJPAgEAMIICSAYJKoZIhvcNAQcBMFcGCSqGSIb3DQEFDTBKMCkGCSqGSIb3DQEFDDAcBAjKPAagTYEOvAICCAAwDAYIKoZIhvcNAgkFADAdBglghkgBZQMEASoEEPxMLJOK2ILJcWrcfDVo3T6AggHgwNqlrg5rB5vQI1gn2T5DdJdi+r4pFgMMP0Z0pG+5d3GvQirocu/0vgP1ZScHd9d66bvkSubwcB5LbkkLdo4Satfmr70uyRQmOZntTBpHX1r57D2Po/eWm2cwrNk8m0vH11K9YStR4+/TJatia1CcRR2lTzNbnXo13/EfNDk6DlvCc0lKMrdUN2MqxcGMfTcH0gALGAhwruhXlVRvi001kAPDjpbfbfwJEgIWBz496qv1ZbH5PBsRfiFfRY3uQZJMKmTTw15o2XvkP0kAYDZQ4ytriYfaUYU/GkMHoT4LrCEUyYeuS/G8nE9Yf2GhkYcPL/BlrG691Y9dqeWE/COQjzh9dO0az4OfGSYCPTkJsVyWiTfMyj5mgdwAF16Mqv6w1+RbOyl1xa2dKmr8bwt/6mWxO6C1yH+RNJOR2xfUcgNP6p3O/Eu21vQv5v9fHK1av6ebIBY3O7Io7GYT/CAMGvZzcoIwLMRkNTf9a4VqX4QW8sgLxsSKq529RhpTjG41MkUfOJz/gRXsOg9mvxoP+BPVyAXR4+25RqzVXgn1/G4Px7sop8LCx4u4SK9IenKJVb4w5xxkdiRuuKl58ugGE7qsqXzSsL4dHy72R8BsNsygbmrTwI+NcsOVu//jMt3DMIIBQQYJKoZIhvcNAQcBoIIBMgSCAS4wggEqMIIBJgYLKoZIhvcNAQwKAQKgge8wgewwVwYJKoZIhvcNAQUNMEowKQYJKoZIhvcNAQUMMBwECJmQYXaGNfdsAgIIADAMBggqhkiG9w0CCQUAMB0GCWCGSAFlAwQBKgQQbY3Qw7JiGeKG+BxkKs5C8gSBkLrZUNjxDfW3R/TYhwJgVz0kjvWmP+zMLHapRnCUdEvaF2J4/lBwGmxDzfEBMdlebHNg/IKX3gDJFcKt1v7iqUxS7veKnjJ49JmWVdccEUgqgXy3EQbRRhTjGkPqyyhY0I2UHRVOQuO3cDYrDncPMdNw+MKqXFUCDy4iujLjttMKrZg7BoOEIvzhkaeiJPgy1jElMCMGCSqGSIb3DQEJFTEWBBRZ9Ilfeg7hL5t+RpbOWPvhy+ROnzBBMDEwDQYJYIZIAWUDBAIBBQAEIEtI/iM3WixUhvjPJ3E1EC07aKbMjF3P2kyi2QAhRUg4BAjVy96XXoDaaAICCAA=",
+			password: "test-password",
+			succeed:  true,
+		},
+		{
+			testName: "triple des",
+			//nolint:lll
+			keyStore: "MIIDggIBAzCCA0gGCSqGSIb3DQEHAaCCAzkEggM1MIIDMTCCAicGCSqGSIb3DQEHBqCCAhgwggIUAgEAMIICDQYJKoZIhvcNAQcBMBwGCiqGSIb3DQEMAQMwDgQIvTGnNnlLQFUCAggAgIIB4LqQn0St/n7BbMRfCJQEpf8v8DNMrR2Lcsfhy+3UZxXIqz+CWA6IFWUEXoaTt2WGiLDUo7dotwok/lFJP1vVMwTQIBdXNk6EyTzgkjVwxEvdk7w2X+WlAxCYlG5wgpk566y9ACkoncs4+N2aujlWH/iIfVyhbNoC6rMYBKCTutBZhgUvYbjzDBykdSwiRGww+u+KR45y0Mhz/aN9JBq+NOdrLpZsJTdfTXAR0LO4CdXOgwuW2PGnHxQYhmFLta/y9bnr5c3cEoy5N6nCIBI3WaNHcQOsvPvjx5pQWOc8dZMX7ArrJSKdVSRD1bc/vemEz3Cqo+XsaHk824ctmUyeMjLDZl94W5Em8pRjNFDyScblCiFZgOnM/bABeqL7L4kR/seRRYj7A3aGsFP/abxbtEwhEAmFUFvkY0wGQ+rsZGgwm6Z23CvVyO92s8ZoSutOJcSOyb66DTEI1cz/Wpv1kVWN1622HU96cmwkUyRVgB9JLBPazcwVVRppJcIxlLibICpAJ7v6oBSfLi39f075LhKo2yT1YbmtD/EC1098A8enJTqIwxgn8hvOu/ZpzBDj0om7PuwLOoO1z9xY7OturNE4T2KbKdF1OxRiUbVmKb2KjBhOMCm6xA4imTMe4UdjgTCCAQIGCSqGSIb3DQEHAaCB9ASB8TCB7jCB6wYLKoZIhvcNAQwKAQKggbQwgbEwHAYKKoZIhvcNAQwBAzAOBAhtpeM7TrbjxQICCAAEgZDmeE5YhmRxKbb+FdwJ9PwBZ7x3DkIQRy6RLxnom5keUfrAGneYH7v9LAg0DNyahzIFwkb5oGYKk6Xyl3/V4U7oNAevlPWgFV5YaauMuJjKrDXs9EgvKZeX5yh50p8TLrXHSe9Gp8opxDgAv3lShAaK8I+tyDfOv7su1G0QaefcoUblFGWBzX0xDbRk4qJ9Oe4xJTAjBgkqhkiG9w0BCRUxFgQUWfSJX3oO4S+bfkaWzlj74cvkTp8wMTAhMAkGBSsOAwIaBQAEFAaG2A6Pk//C5d8ScHN2En8I9IBZBAiCSazktknyWwICCAA=",
+			password: "test-password",
+			succeed:  true,
+		},
+		{
+			testName: "bad pass",
+			//nolint:lll
+			keyStore: "MIIEDAIBAzCCA8IGCSqGSIb3DQEHAaCCA7MEggOvMIIDqzCCAmIGCSqGSIb3DQEHBqCCAlMwggJPAgEAMIICSAYJKoZIhvcNAQcBMFcGCSqGSIb3DQEFDTBKMCkGCSqGSIb3DQEFDDAcBAjKPAagTYEOvAICCAAwDAYIKoZIhvcNAgkFADAdBglghkgBZQMEASoEEPxMLJOK2ILJcWrcfDVo3T6AggHgwNqlrg5rB5vQI1gn2T5DdJdi+r4pFgMMP0Z0pG+5d3GvQirocu/0vgP1ZScHd9d66bvkSubwcB5LbkkLdo4Satfmr70uyRQmOZntTBpHX1r57D2Po/eWm2cwrNk8m0vH11K9YStR4+/TJatia1CcRR2lTzNbnXo13/EfNDk6DlvCc0lKMrdUN2MqxcGMfTcH0gALGAhwruhXlVRvi001kAPDjpbfbfwJEgIWBz496qv1ZbH5PBsRfiFfRY3uQZJMKmTTw15o2XvkP0kAYDZQ4ytriYfaUYU/GkMHoT4LrCEUyYeuS/G8nE9Yf2GhkYcPL/BlrG691Y9dqeWE/COQjzh9dO0az4OfGSYCPTkJsVyWiTfMyj5mgdwAF16Mqv6w1+RbOyl1xa2dKmr8bwt/6mWxO6C1yH+RNJOR2xfUcgNP6p3O/Eu21vQv5v9fHK1av6ebIBY3O7Io7GYT/CAMGvZzcoIwLMRkNTf9a4VqX4QW8sgLxsSKq529RhpTjG41MkUfOJz/gRXsOg9mvxoP+BPVyAXR4+25RqzVXgn1/G4Px7sop8LCx4u4SK9IenKJVb4w5xxkdiRuuKl58ugGE7qsqXzSsL4dHy72R8BsNsygbmrTwI+NcsOVu//jMt3DMIIBQQYJKoZIhvcNAQcBoIIBMgSCAS4wggEqMIIBJgYLKoZIhvcNAQwKAQKgge8wgewwVwYJKoZIhvcNAQUNMEowKQYJKoZIhvcNAQUMMBwECJmQYXaGNfdsAgIIADAMBggqhkiG9w0CCQUAMB0GCWCGSAFlAwQBKgQQbY3Qw7JiGeKG+BxkKs5C8gSBkLrZUNjxDfW3R/TYhwJgVz0kjvWmP+zMLHapRnCUdEvaF2J4/lBwGmxDzfEBMdlebHNg/IKX3gDJFcKt1v7iqUxS7veKnjJ49JmWVdccEUgqgXy3EQbRRhTjGkPqyyhY0I2UHRVOQuO3cDYrDncPMdNw+MKqXFUCDy4iujLjttMKrZg7BoOEIvzhkaeiJPgy1jElMCMGCSqGSIb3DQEJFTEWBBRZ9Ilfeg7hL5t+RpbOWPvhy+ROnzBBMDEwDQYJYIZIAWUDBAIBBQAEIEtI/iM3WixUhvjPJ3E1EC07aKbMjF3P2kyi2QAhRUg4BAjVy96XXoDaaAICCAA=",
+			password: "sdfsdf",
+			succeed:  false,
+		},
+		{
+			testName: "empty",
+			keyStore: "",
+			password: "test-password",
+			succeed:  false,
+		},
+	}
+
+	for _, test := range testCases {
+		keyStore, _ := base64.StdEncoding.DecodeString(test.keyStore)
+
+		tlsCert, err := ParsePKCS12KeyStoreToTLSCertificate(keyStore, []byte(test.password))
+		if test.succeed && err != nil {
+			t.Errorf("testname: %s, error should be nil, got: %s", test.testName, err)
+		} else if !test.succeed && err == nil {
+			t.Errorf("testname: %s, error shouldn't be nil", test.testName)
+		}
+		if test.succeed && err == nil && tlsCert.Leaf.Subject.CommonName != "kafka-test" {
+			t.Errorf("testname: %s, expected the certificate of the private key, got: %s", test.testName, tlsCert.Leaf.Subject)
+		}
+	}
+}
+
+func TestGenerateTrustStorePKCS12(t *testing.T) {
+	var caCerts []*x509.Certificate
+	for i := 0; i < 2; i++ {
+		certPEM, _, _, err := GenerateTestCert()
+		if err != nil {
+			t.Fatal("Failed to generate test certificate", err)
+		}
+		caCert, err := DecodeCertificate(certPEM)
+		if err != nil {
+			t.Fatal("Failed to decode test certificate", err)
+		}
+		caCerts = append(caCerts, caCert)
+	}
+	password := []byte("test-password")
+
+	trustStore, err := GenerateTrustStorePKCS12(caCerts, password)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	again, err := GenerateTrustStorePKCS12(caCerts, password)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if !bytes.Equal(trustStore, again) {
+		t.Error("Expected the truststore to be the same for the same certificates")
+	}
+
+	parsed, err := ParsePKCS12TrustStoreToCaChain(trustStore, password)
+	if err != nil {
+		t.Fatal("Expected no error, got:", err)
+	}
+	if len(parsed) != len(caCerts) {
+		t.Fatalf("Expected %d certificates, got: %d", len(caCerts), len(parsed))
+	}
+	for i := range caCerts {
+		if !parsed[i].Equal(caCerts[i]) {
+			t.Errorf("Expected certificate %d to be preserved", i)
+		}
+	}
+
+	if _, err := ParsePKCS12TrustStoreToCaChain(trustStore, []byte("wrong-password")); err == nil {
+		t.Error("Expected error for wrong password")
+	}
+}
@@ -37,6 +37,44 @@ var PerBrokerConfigs = []string{
 	KafkaConfigListeners,
 	KafkaConfigAdvertisedListeners,
 	KafkaConfigListenerSecurityProtocolMap,
+
+	// connection limits can be updated dynamically on each broker
+	KafkaConfigMaxConnections,
+	KafkaConfigMaxConnectionsPerIP,
+	KafkaConfigMaxConnectionsPerIPOverrides,
+	KafkaConfigMaxConnectionCreationRate,
+}
+
+// perBrokerListenerConfigs are the listener specific configurations, prefixed with listener.name.<listener>.,
+// which will not trigger rolling upgrade when updated
+var perBrokerListenerConfigs = []string{
+	KafkaConfigMaxConnections,
+	KafkaConfigMaxConnectionCreationRate,
+}
+
+// IsPerBrokerConfig returns true when the configuration is updated without triggering rolling upgrade
+func IsPerBrokerConfig(key string) bool {
+	if util.StringSliceContains(PerBrokerConfigs, key) {
+		return true
+	}
+	listenerConfig := strings.TrimPrefix(key, KafkaConfigListenerName+".")
+	if listenerConfig == key {
+		return false
+	}
+	// the listener name is followed by the name of the listener specific configuration
+	parts := strings.SplitN(listenerConfig, ".", 2)
+	return len(parts) == 2 && util.StringSliceContains(perBrokerListenerConfigs, parts[1])
+}
+
+// PerBrokerConfigKeys returns the keys of the configurations which are updated without triggering rolling upgrade
+func PerBrokerConfigKeys(config *properties.Properties) []string {
+	var keys []string
+	for _, key := range config.Keys() {
+		if IsPerBrokerConfig(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
 // commonACLString is the raw representation of an ACL allowing Describe on a Topic
@@ -106,8 +144,10 @@ func ShouldRefreshOnlyPerBrokerConfigs(currentConfigs, desiredConfigs *propertie
 		}
 	}
 
-	for _, perBrokerConfig := range PerBrokerConfigs {
-		delete(configDiff, perBrokerConfig)
+	for key := range configDiff {
+		if IsPerBrokerConfig(key) {
+			delete(configDiff, key)
+		}
 	}
 
 	return len(configDiff) == 0
@@ -218,6 +258,37 @@ func getBootstrapServers(cluster *v1beta1.KafkaCluster, useService bool) (string
 	return strings.Join(bootstrapServersList, ","), nil
 }
 
+// BrokerRack returns the broker.rack of the broker set in the read-only configuration of the broker or the cluster
+func BrokerRack(cluster *v1beta1.KafkaCluster, broker v1beta1.Broker) string {
+	for _, readOnlyConfig := range []string{broker.ReadOnlyConfig, cluster.Spec.ReadOnlyConfig} {
+		config, err := properties.NewFromString(readOnlyConfig)
+		if err != nil {
+			continue
+		}
+		if rack, ok := config.Get(KafkaConfigBrokerRack); ok {
+			return rack.Value()
+		}
+	}
+	return ""
+}
+
+// MinInSyncReplicas returns the default min.insync.replicas of the topics set in the cluster-wide or the read-only
+// configuration of the cluster, or the default of Kafka when it is not set
+func MinInSyncReplicas(cluster *v1beta1.KafkaCluster) int32 {
+	for _, clusterConfig := range []string{cluster.Spec.ClusterWideConfig, cluster.Spec.ReadOnlyConfig} {
+		config, err := properties.NewFromString(clusterConfig)
+		if err != nil {
+			continue
+		}
+		if value, ok := config.Get(KafkaConfigMinInSyncReplicas); ok {
+			if minInSyncReplicas, err := value.Int(); err == nil && minInSyncReplicas > 0 {
+				return int32(minInSyncReplicas)
+			}
+		}
+	}
+	return 1
+}
+
 // GatherBrokerConfigIfAvailable return the brokerConfig for a specific ID if available
 func GatherBrokerConfigIfAvailable(kafkaClusterSpec v1beta1.KafkaClusterSpec, brokerID int) (*v1beta1.BrokerConfig, error) {
 	// This check is used in case of broker delete. In case of broker delete there is some time when the CC removes the broker
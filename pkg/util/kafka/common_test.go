@@ -89,6 +89,23 @@ ssl.client.auth=modified_value_4
 			DesiredConfigs: "listener.security.protocol.map=listener1:protocol1,listener2:protocol3",
 			Result:         false,
 		},
+		{
+			Description: "connection limits changed",
+			CurrentConfigs: `max.connections=1000
+listener.name.external.max.connections=100
+`,
+			DesiredConfigs: `max.connections=2000
+listener.name.external.max.connections=200
+max.connections.per.ip.overrides=10.0.0.1:10
+`,
+			Result: true,
+		},
+		{
+			Description:    "listener specific config which is not a connection limit changed",
+			CurrentConfigs: "listener.name.external.ssl.truststore.type=JKS",
+			DesiredConfigs: "listener.name.external.ssl.truststore.type=PKCS12",
+			Result:         false,
+		},
 		{
 			Description:    "security protocol map added as config",
 			CurrentConfigs: "",
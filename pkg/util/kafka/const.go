@@ -21,11 +21,32 @@ const ConfigPropertyName = "broker-config"
 const (
 	KafkaConfigSuperUsers = "super.users"
 
+	KafkaConfigAuthorizerClassName                  = "authorizer.class.name"
+	KafkaConfigOPAAuthorizerURL                     = "opa.authorizer.url"
+	KafkaConfigOPAAuthorizerAllowOnError            = "opa.authorizer.allow.on.error"
+	KafkaConfigOPAAuthorizerCacheExpireAfterSeconds = "opa.authorizer.cache.expire.after.seconds"
+
+	KafkaConfigDelegationTokenSecretKey   = "delegation.token.secret.key"
+	KafkaConfigDelegationTokenMaxLifetime = "delegation.token.max.lifetime.ms"
+	KafkaConfigDelegationTokenExpiryTime  = "delegation.token.expiry.time.ms"
+
+	KafkaConfigConfigProviders             = "config.providers"
+	KafkaConfigConfigProviderClassTemplate = "config.providers.%s.class"
+
+	KafkaConfigClientQuotaCallbackClass = "client.quota.callback.class"
+
+	KafkaQuotaProducerByteRate  = "producer_byte_rate"
+	KafkaQuotaConsumerByteRate  = "consumer_byte_rate"
+	KafkaQuotaRequestPercentage = "request_percentage"
+
 	KafkaConfigBoostrapServers    = "bootstrap.servers"
 	KafkaConfigZooKeeperConnect   = "zookeeper.connect"
 	KafkaConfigBrokerId           = "broker.id"
+	KafkaConfigBrokerRack         = "broker.rack"
 	KafkaConfigBrokerLogDirectory = "log.dirs"
 
+	KafkaConfigMinInSyncReplicas = "min.insync.replicas"
+
 	KafkaConfigListeners                   = "listeners"
 	KafkaConfigListenerName                = "listener.name"
 	KafkaConfigListenerSecurityProtocolMap = "listener.security.protocol.map"
@@ -41,6 +62,18 @@ const (
 	KafkaConfigSSLKeystoreType       = "ssl.keystore.type"
 	KafkaConfigSSLKeyStoreLocation   = "ssl.keystore.location"
 	KafkaConfigSSLKeyStorePassword   = "ssl.keystore.password"
+	KafkaConfigSSLEnabledProtocols   = "ssl.enabled.protocols"
+	KafkaConfigSSLProtocol           = "ssl.protocol"
+	KafkaConfigSSLCipherSuites       = "ssl.cipher.suites"
+
+	KafkaConfigSASLEnabledMechanisms = "sasl.enabled.mechanisms"
+	KafkaConfigSASLMechanism         = "sasl.mechanism"
+	KafkaConfigSASLJAASConfig        = "sasl.jaas.config"
+
+	KafkaConfigMaxConnections               = "max.connections"
+	KafkaConfigMaxConnectionsPerIP          = "max.connections.per.ip"
+	KafkaConfigMaxConnectionsPerIPOverrides = "max.connections.per.ip.overrides"
+	KafkaConfigMaxConnectionCreationRate    = "max.connection.creation.rate"
 )
 
 // used for Cruise Control configurations
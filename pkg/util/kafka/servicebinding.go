@@ -0,0 +1,66 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"strings"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+)
+
+// Keys of the binding Secrets defined by the Service Binding specification (servicebinding.io) together with the
+// Kafka specific ones understood by the client libraries, e.g. Spring Cloud Bindings and Quarkus
+const (
+	ServiceBindingTypeKey             = "type"
+	ServiceBindingProviderKey         = "provider"
+	ServiceBindingBootstrapServersKey = "bootstrap-servers"
+	ServiceBindingSecurityProtocolKey = "securityProtocol"
+	ServiceBindingSASLMechanismKey    = "saslMechanism"
+
+	ServiceBindingType     = "kafka"
+	ServiceBindingProvider = "koperator"
+)
+
+// ServiceBindingData returns the content of the binding Secret pointing to a listener with the given bootstrap
+// servers and security protocol. The SASL mechanism is left out when it is empty.
+func ServiceBindingData(bootstrapServers string, securityProtocol v1beta1.SecurityProtocol, saslMechanism string) map[string][]byte {
+	data := map[string][]byte{
+		ServiceBindingTypeKey:             []byte(ServiceBindingType),
+		ServiceBindingProviderKey:         []byte(ServiceBindingProvider),
+		ServiceBindingBootstrapServersKey: []byte(bootstrapServers),
+		ServiceBindingSecurityProtocolKey: []byte(securityProtocol.ToUpperString()),
+	}
+	if saslMechanism != "" {
+		data[ServiceBindingSASLMechanismKey] = []byte(saslMechanism)
+	}
+	return data
+}
+
+// ListenerBootstrapServers returns the addresses reaching any broker through the listener, or the addresses of all
+// the brokers when there is no such address
+func ListenerBootstrapServers(statuses v1beta1.ListenerStatusList) string {
+	var anyBroker, brokers []string
+	for _, status := range statuses {
+		if strings.HasPrefix(status.Name, "any-broker") {
+			anyBroker = append(anyBroker, status.Address)
+		} else {
+			brokers = append(brokers, status.Address)
+		}
+	}
+	if len(anyBroker) > 0 {
+		return strings.Join(anyBroker, ",")
+	}
+	return strings.Join(brokers, ",")
+}
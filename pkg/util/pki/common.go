@@ -44,6 +44,8 @@ const (
 	BrokerCACertTemplate = "%s-ca-certificate"
 	// BrokerServerCertTemplate is the template used for broker certificate resources
 	BrokerServerCertTemplate = "%s-server-certificate"
+	// ListenerServerCertTemplate is the template used for the dedicated certificate resources of listeners
+	ListenerServerCertTemplate = "%s-%s-server-certificate"
 	// BrokerClusterIssuerTemplate is the template used for broker issuer resources
 	BrokerClusterIssuerTemplate = "%s-%s-issuer"
 	// LegacyBrokerClusterIssuerTemplate is the template used earlier for broker issuer resources
@@ -203,6 +205,18 @@ func BrokerUserForCluster(cluster *v1beta1.KafkaCluster, extListenerStatuses map
 	}
 }
 
+// ListenerServerCertSecretName returns the name of the secret holding the server certificate of the listener
+func ListenerServerCertSecretName(clusterName string, commonSpec v1beta1.CommonListenerSpec) string {
+	switch {
+	case commonSpec.GetServerSSLCertSecretName() != "":
+		return commonSpec.GetServerSSLCertSecretName()
+	case commonSpec.HasDedicatedCertManagerCertificate():
+		return fmt.Sprintf(ListenerServerCertTemplate, clusterName, commonSpec.Name)
+	default:
+		return fmt.Sprintf(BrokerServerCertTemplate, clusterName)
+	}
+}
+
 func sortAndDedupe(hosts []string) []string {
 	sort.Strings(hosts)
 
@@ -236,6 +250,36 @@ func ControllerUserForCluster(cluster *v1beta1.KafkaCluster) *v1alpha1.KafkaUser
 	}
 }
 
+// CruiseControlClientUserForCluster returns a KafkaUser CR for the dedicated client certificate the operator presents
+// to Cruise Control in a KafkaCluster
+func CruiseControlClientUserForCluster(cluster *v1beta1.KafkaCluster) *v1alpha1.KafkaUser {
+	ccConfig := cluster.Spec.CruiseControlConfig
+	secretName := ccConfig.GetClientTLSSecretName(cluster.Name)
+	user := &v1alpha1.KafkaUser{
+		ObjectMeta: templates.ObjectMeta(
+			EnsureValidCommonNameLen(fmt.Sprintf(BrokerControllerFQDNTemplate, secretName, cluster.Namespace, cluster.Spec.GetKubernetesClusterDomain())),
+			LabelsForKafkaPKI(cluster.Name, cluster.Namespace),
+			cluster,
+		),
+		Spec: v1alpha1.KafkaUserSpec{
+			SecretName: secretName,
+			IncludeJKS: true,
+			ClusterRef: v1alpha1.ClusterReference{
+				Name:      cluster.Name,
+				Namespace: cluster.Namespace,
+			},
+			PKIBackendSpec: &v1alpha1.PKIBackendSpec{
+				PKIBackend: string(ccConfig.GetClientTLSPKIBackend()),
+			},
+		},
+	}
+	if ccConfig.ClientTLS != nil {
+		user.Spec.PKIBackendSpec.IssuerRef = ccConfig.ClientTLS.IssuerRef
+		user.Spec.PKIBackendSpec.SignerName = ccConfig.ClientTLS.SignerName
+	}
+	return user
+}
+
 // EnsureControllerReference ensures that a KafkaUser owns a given Secret
 func EnsureControllerReference(ctx context.Context, user *v1alpha1.KafkaUser,
 	secret *corev1.Secret, scheme *runtime.Scheme, client client.Client) error {
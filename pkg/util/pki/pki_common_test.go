@@ -19,6 +19,8 @@ import (
 	"reflect"
 	"testing"
 
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+
 	"github.com/banzaicloud/koperator/api/v1alpha1"
 	"github.com/banzaicloud/koperator/api/v1beta1"
 	"github.com/banzaicloud/koperator/pkg/resources/templates"
@@ -179,6 +181,38 @@ func TestControllerUserForCluster(t *testing.T) {
 	}
 }
 
+func TestCruiseControlClientUserForCluster(t *testing.T) {
+	cluster := testCluster(t)
+	cluster.Spec.CruiseControlConfig.ClientTLS = &v1beta1.CruiseControlClientTLS{
+		IssuerRef: &cmmeta.ObjectReference{Name: "cc-issuer", Kind: "ClusterIssuer"},
+	}
+	user := CruiseControlClientUserForCluster(cluster)
+
+	expected := &v1alpha1.KafkaUser{
+		ObjectMeta: templates.ObjectMeta(
+			EnsureValidCommonNameLen(fmt.Sprintf(BrokerControllerFQDNTemplate, cluster.Name+"-cruisecontrol-client",
+				cluster.Namespace, cluster.Spec.GetKubernetesClusterDomain())),
+			LabelsForKafkaPKI(cluster.Name, cluster.Namespace), cluster,
+		),
+		Spec: v1alpha1.KafkaUserSpec{
+			SecretName: cluster.Name + "-cruisecontrol-client",
+			IncludeJKS: true,
+			ClusterRef: v1alpha1.ClusterReference{
+				Name:      cluster.Name,
+				Namespace: cluster.Namespace,
+			},
+			PKIBackendSpec: &v1alpha1.PKIBackendSpec{
+				PKIBackend: string(v1beta1.PKIBackendCertManager),
+				IssuerRef:  &cmmeta.ObjectReference{Name: "cc-issuer", Kind: "ClusterIssuer"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(user, expected) {
+		t.Errorf("Expected %+v\nGot %+v", expected, user)
+	}
+}
+
 func TestTruncatedCommonName(t *testing.T) {
 	testCases := []struct {
 		testName             string
@@ -0,0 +1,162 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+)
+
+const (
+	// TrustBundleSecretTemplate is the template used for the secret holding the truststores of the listeners
+	// extended with the additional trusted CA certificates
+	TrustBundleSecretTemplate = "%s-trust-bundle"
+	// listenerTrustStoreKeyTemplate is the template used for the keys of the listener truststores in the trust bundle secret
+	listenerTrustStoreKeyTemplate = "%s-%s"
+	// ClientTrustStoreKey is the key of the truststore of the client certificate used by the operator, Cruise Control
+	// and its metrics reporter in the trust bundle secret
+	ClientTrustStoreKey = "client-truststore.jks"
+)
+
+// ClientSSLCertSecretName returns the name of the secret holding the client certificate used by the operator,
+// Cruise Control and its metrics reporter
+func ClientSSLCertSecretName(cluster *v1beta1.KafkaCluster) string {
+	if cluster.Spec.GetClientSSLCertSecretName() != "" {
+		return cluster.Spec.GetClientSSLCertSecretName()
+	}
+	return fmt.Sprintf(BrokerControllerTemplate, cluster.Name)
+}
+
+// ClientSSLCertVolumeSource returns the volume source of the client certificate. The truststore is taken from the
+// trust bundle secret when additional trusted CA certificates are configured.
+func ClientSSLCertVolumeSource(cluster *v1beta1.KafkaCluster) corev1.VolumeSource {
+	secretName := ClientSSLCertSecretName(cluster)
+	if len(cluster.Spec.ListenersConfig.AdditionalTrustedCAs) == 0 {
+		return corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  secretName,
+				DefaultMode: util.Int32Pointer(0644),
+			},
+		}
+	}
+	return corev1.VolumeSource{
+		Projected: &corev1.ProjectedVolumeSource{
+			Sources: []corev1.VolumeProjection{
+				{
+					Secret: &corev1.SecretProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+						Items: []corev1.KeyToPath{
+							{Key: v1alpha1.TLSJKSKeyStore, Path: v1alpha1.TLSJKSKeyStore},
+							{Key: v1alpha1.PasswordKey, Path: v1alpha1.PasswordKey},
+						},
+					},
+				},
+				{
+					// custom client certificates are not required to contain the CA certificate
+					Secret: &corev1.SecretProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+						Items:                []corev1.KeyToPath{{Key: v1alpha1.CoreCACertKey, Path: v1alpha1.CoreCACertKey}},
+						Optional:             util.BoolPointer(true),
+					},
+				},
+				{
+					Secret: &corev1.SecretProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf(TrustBundleSecretTemplate, cluster.Name)},
+						Items:                []corev1.KeyToPath{{Key: ClientTrustStoreKey, Path: v1alpha1.TLSJKSTrustStore}},
+					},
+				},
+			},
+			DefaultMode: util.Int32Pointer(0644),
+		},
+	}
+}
+
+// ListenerTrustStoreKey returns the key of the truststore of the listener in the trust bundle secret
+func ListenerTrustStoreKey(listenerName string, storeType v1beta1.KeyStoreType) string {
+	return fmt.Sprintf(listenerTrustStoreKeyTemplate, listenerName, certutil.TrustStoreFileName(storeType))
+}
+
+// GetAdditionalTrustedCAs returns the additional trusted CA certificates referenced by the KafkaCluster
+func GetAdditionalTrustedCAs(ctx context.Context, c client.Reader, cluster *v1beta1.KafkaCluster) ([]*x509.Certificate, error) {
+	var caCerts []*x509.Certificate
+	for _, source := range cluster.Spec.ListenersConfig.AdditionalTrustedCAs {
+		var data []byte
+		switch {
+		case source.ConfigMapKeyRef != nil:
+			configMap := &corev1.ConfigMap{}
+			if err := c.Get(ctx, types.NamespacedName{Name: source.ConfigMapKeyRef.Name, Namespace: cluster.Namespace}, configMap); err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not get ConfigMap of additional trusted CA certificates",
+					"configMap", source.ConfigMapKeyRef.Name)
+			}
+			data = []byte(configMap.Data[source.ConfigMapKeyRef.Key])
+		case source.SecretKeyRef != nil:
+			secret := &corev1.Secret{}
+			if err := c.Get(ctx, types.NamespacedName{Name: source.SecretKeyRef.Name, Namespace: cluster.Namespace}, secret); err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not get Secret of additional trusted CA certificates",
+					"secret", source.SecretKeyRef.Name)
+			}
+			data = secret.Data[source.SecretKeyRef.Key]
+		default:
+			continue
+		}
+
+		certs, err := certutil.ParseCertificates(bytes.TrimSpace(data))
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not parse additional trusted CA certificates")
+		}
+		caCerts = append(caCerts, certutil.GetCertBundle(certs)...)
+	}
+	return caCerts, nil
+}
+
+// AppendCertificates returns the certificates which are not present in the given list appended to it
+func AppendCertificates(certs []*x509.Certificate, additional ...*x509.Certificate) []*x509.Certificate {
+	ret := append([]*x509.Certificate{}, certs...)
+	for _, cert := range additional {
+		duplicate := false
+		for _, existing := range ret {
+			if existing.Equal(cert) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			ret = append(ret, cert)
+		}
+	}
+	return ret
+}
+
+// EncodeCertificates returns the certificates in PEM format
+func EncodeCertificates(certs []*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		_ = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,77 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pki
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	certutil "github.com/banzaicloud/koperator/pkg/util/cert"
+)
+
+func TestGetAdditionalTrustedCAs(t *testing.T) {
+	configMapCA, _, _, err := certutil.GenerateTestCert()
+	if err != nil {
+		t.Fatal("failed to generate certificate for testing:", err)
+	}
+	secretCA, _, _, err := certutil.GenerateTestCert()
+	if err != nil {
+		t.Fatal("failed to generate certificate for testing:", err)
+	}
+
+	cluster := testCluster(t)
+	cluster.Spec.ListenersConfig.AdditionalTrustedCAs = []v1beta1.TrustedCASource{
+		{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "corporate-ca"}, Key: "ca.crt"}},
+		{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "remote-ca"}, Key: "ca.crt"}},
+	}
+	c := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "corporate-ca", Namespace: cluster.Namespace},
+			Data:       map[string]string{"ca.crt": string(configMapCA) + "\n"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "remote-ca", Namespace: cluster.Namespace},
+			Data:       map[string][]byte{"ca.crt": secretCA},
+		},
+	).Build()
+
+	caCerts, err := GetAdditionalTrustedCAs(context.Background(), c, cluster)
+	if err != nil {
+		t.Fatal("expected no error, got:", err)
+	}
+	if len(caCerts) != 2 {
+		t.Fatal("expected two CA certificates, got:", len(caCerts))
+	}
+
+	merged := AppendCertificates(caCerts[:1], caCerts...)
+	if len(merged) != 2 {
+		t.Error("expected duplicated certificates to be dropped, got:", len(merged))
+	}
+	parsed, err := certutil.ParseCertificates(EncodeCertificates(merged))
+	if err != nil || len(parsed) != 2 {
+		t.Error("expected the encoded certificates to be parsed back, got:", err)
+	}
+
+	cluster.Spec.ListenersConfig.AdditionalTrustedCAs = append(cluster.Spec.ListenersConfig.AdditionalTrustedCAs,
+		v1beta1.TrustedCASource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}, Key: "ca.crt"}})
+	if _, err := GetAdditionalTrustedCAs(context.Background(), c, cluster); err == nil {
+		t.Error("expected error for missing secret")
+	}
+}
@@ -324,6 +324,24 @@ func GetIngressConfigs(kafkaClusterSpec v1beta1.KafkaClusterSpec,
 	return ingressConfigs, defaultIngressConfigName, nil
 }
 
+// GetIngressControllerServiceName returns the name of the LoadBalancer service of the ingress controller
+// exposing the external listener through the given ingress config
+func GetIngressControllerServiceName(cluster *v1beta1.KafkaCluster, eListenerName, ingressConfigName string) string {
+	switch cluster.Spec.GetIngressController() {
+	case istioingress.IngressControllerName:
+		if ingressConfigName == IngressConfigGlobalName {
+			return fmt.Sprintf(istioingress.MeshGatewayNameTemplate, eListenerName, cluster.GetName())
+		}
+		return fmt.Sprintf(istioingress.MeshGatewayNameTemplateWithScope, eListenerName, ingressConfigName, cluster.GetName())
+	case envoyutils.IngressControllerName:
+		if ingressConfigName == IngressConfigGlobalName {
+			return fmt.Sprintf(envoyutils.EnvoyServiceName, eListenerName, cluster.GetName())
+		}
+		return fmt.Sprintf(envoyutils.EnvoyServiceNameWithScope, eListenerName, ingressConfigName, cluster.GetName())
+	}
+	return ""
+}
+
 // GetBrokerImage returns the used broker image
 func GetBrokerImage(brokerConfig *v1beta1.BrokerConfig, clusterImage string) string {
 	if brokerConfig.Image != "" {
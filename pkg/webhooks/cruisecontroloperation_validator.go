@@ -0,0 +1,262 @@
+// Copyright © 2023 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	banzaicloudv1alpha1 "github.com/banzaicloud/koperator/api/v1alpha1"
+	banzaicloudv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/k8sutil"
+)
+
+const (
+	// PodNamespaceEnvVar is the environment variable holding the namespace of the Koperator pod
+	PodNamespaceEnvVar = "POD_NAMESPACE"
+	// PodServiceAccountEnvVar is the environment variable holding the name of the service account of the Koperator pod
+	PodServiceAccountEnvVar = "POD_SERVICE_ACCOUNT"
+)
+
+// OperatorUsernameFromEnv returns the user name of the service account of the Koperator the API server authenticates
+// its requests with, or an empty string when the service account is not set by the environment variables
+func OperatorUsernameFromEnv(getenv func(string) string) string {
+	namespace, serviceAccount := getenv(PodNamespaceEnvVar), getenv(PodServiceAccountEnvVar)
+	if namespace == "" || serviceAccount == "" {
+		return ""
+	}
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccount)
+}
+
+// CruiseControlOperationValidator validates the parameters of the CruiseControlOperations and enforces
+// the CruiseControlOperation quotas of the referenced Kafka cluster
+type CruiseControlOperationValidator struct {
+	Client client.Client
+	Log    logr.Logger
+	// OperatorUsername is the user name of the Koperator in the admission requests. Only the Koperator may create
+	// CruiseControlOperations controlled by its resources as the quotas do not reject those operations.
+	OperatorUsername string
+}
+
+func (s CruiseControlOperationValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	operation := obj.(*banzaicloudv1alpha1.CruiseControlOperation)
+	log := s.Log.WithValues("name", operation.GetName(), "namespace", operation.GetNamespace())
+
+	if err := s.checkController(ctx, nil, operation); err != nil {
+		return s.forbidden(log, operation, forbiddenCCOperationControllerMsg, err)
+	}
+	cluster, err := s.kafkaCluster(ctx, operation)
+	if err != nil {
+		log.Error(err, errorDuringValidationMsg)
+		return apierrors.NewInternalError(errors.WithMessage(err, errorDuringValidationMsg))
+	}
+	if err := s.invalid(log, operation, validateCCOperationParameters(operation, cluster)); err != nil {
+		return err
+	}
+	quotas := ccOperationQuotas(cluster)
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	operations := &banzaicloudv1alpha1.CruiseControlOperationList{}
+	if err := s.Client.List(ctx, operations, client.InNamespace(operation.GetNamespace())); err != nil {
+		log.Error(err, errorDuringValidationMsg)
+		return apierrors.NewInternalError(errors.WithMessage(errors.Wrap(err, cantConnectAPIServerMsg), errorDuringValidationMsg))
+	}
+
+	if err := k8sutil.CheckCCOperationAdmission(quotas, operation, operations.Items, time.Now()); err != nil {
+		return s.forbidden(log, operation, exceededCCOperationQuotaMsg, err)
+	}
+	return nil
+}
+
+func (s CruiseControlOperationValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldOperation := oldObj.(*banzaicloudv1alpha1.CruiseControlOperation)
+	operation := newObj.(*banzaicloudv1alpha1.CruiseControlOperation)
+	if err := s.checkController(ctx, oldOperation, operation); err != nil {
+		return s.forbidden(s.Log.WithValues("name", operation.GetName(), "namespace", operation.GetNamespace()),
+			operation, forbiddenCCOperationControllerMsg, err)
+	}
+	// The operation type and the parameters are set in the status after the creation of the CruiseControlOperation
+	if oldOperation.CurrentTaskOperation() == operation.CurrentTaskOperation() &&
+		reflect.DeepEqual(oldOperation.CurrentTaskParameters(), operation.CurrentTaskParameters()) {
+		return nil
+	}
+	log := s.Log.WithValues("name", operation.GetName(), "namespace", operation.GetNamespace())
+
+	cluster, err := s.kafkaCluster(ctx, operation)
+	if err != nil {
+		log.Error(err, errorDuringValidationMsg)
+		return apierrors.NewInternalError(errors.WithMessage(err, errorDuringValidationMsg))
+	}
+	if err := s.invalid(log, operation, validateCCOperationParameters(operation, cluster)); err != nil {
+		return err
+	}
+	if oldOperation.CurrentTaskOperation() == operation.CurrentTaskOperation() {
+		return nil
+	}
+	if err := k8sutil.IsCCOperationTypeAllowed(ccOperationQuotas(cluster), operation); err != nil {
+		return s.forbidden(log, operation, exceededCCOperationQuotaMsg, err)
+	}
+	return nil
+}
+
+func (s CruiseControlOperationValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// checkController returns an error when a request which is not sent by the Koperator sets one of the resources of the
+// Koperator as the controller of the CruiseControlOperation, as that would exempt the operation from the quotas
+func (s CruiseControlOperationValidator) checkController(ctx context.Context, oldOperation, operation *banzaicloudv1alpha1.CruiseControlOperation) error {
+	if !k8sutil.IsCCOperationControlledByKoperator(operation) {
+		return nil
+	}
+	if oldOperation != nil && reflect.DeepEqual(metav1.GetControllerOf(oldOperation), metav1.GetControllerOf(operation)) {
+		return nil
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err == nil && s.OperatorUsername != "" && req.UserInfo.Username == s.OperatorUsername {
+		return nil
+	}
+	return errors.New("only the Koperator can create CruiseControlOperations controlled by its resources")
+}
+
+// kafkaCluster returns the referenced Kafka cluster. Missing Kafka cluster is not a validation error as the
+// CruiseControlOperation controller ignores those operations, nil is returned in that case.
+func (s CruiseControlOperationValidator) kafkaCluster(ctx context.Context, operation *banzaicloudv1alpha1.CruiseControlOperation) (*banzaicloudv1beta1.KafkaCluster, error) {
+	if operation.GetClusterRef() == "" {
+		return nil, nil
+	}
+	cluster, err := k8sutil.LookupKafkaCluster(ctx, s.Client, operation.GetClusterRef(), operation.GetNamespace())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, cantConnectAPIServerMsg)
+	}
+	return cluster, nil
+}
+
+func ccOperationQuotas(cluster *banzaicloudv1beta1.KafkaCluster) []banzaicloudv1beta1.CruiseControlOperationQuota {
+	if cluster == nil {
+		return nil
+	}
+	return cluster.Spec.CruiseControlConfig.CruiseControlOperationSpec.GetQuotas()
+}
+
+// validateCCOperationParameters validates the parameters of the current task of the CruiseControlOperation. The broker
+// IDs are checked against the Kafka cluster when it is known.
+func validateCCOperationParameters(operation *banzaicloudv1alpha1.CruiseControlOperation, cluster *banzaicloudv1beta1.KafkaCluster) field.ErrorList {
+	task := operation.CurrentTask()
+	if task == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	params := task.GetParameters()
+	paramPath := func(name, typedName string) *field.Path {
+		if _, ok := task.TypedParameters.ToParameters()[name]; ok {
+			return field.NewPath("status", "currentTask", "typedParameters", typedName)
+		}
+		return field.NewPath("status", "currentTask", "parameters").Key(name)
+	}
+
+	if value, ok := params[banzaicloudv1alpha1.ParamRebalanceDisk]; ok {
+		path := paramPath(banzaicloudv1alpha1.ParamRebalanceDisk, "rebalanceDisk")
+		if rebalanceDisk, err := strconv.ParseBool(value); err != nil {
+			allErrs = append(allErrs, field.Invalid(path, value, invalidCCOperationParameterErrMsg+": not a boolean"))
+		} else if rebalanceDisk && task.Operation != banzaicloudv1alpha1.OperationRebalance {
+			allErrs = append(allErrs, field.Invalid(path, value, invalidCCOperationParameterErrMsg+": supported only by the rebalance operation"))
+		}
+	}
+
+	if value, ok := params[banzaicloudv1alpha1.ParamDestbrokerIDs]; ok {
+		path := paramPath(banzaicloudv1alpha1.ParamDestbrokerIDs, "destinationBrokerIds")
+		if task.Operation != banzaicloudv1alpha1.OperationRebalance && task.Operation != banzaicloudv1alpha1.OperationRemoveBroker {
+			allErrs = append(allErrs, field.Invalid(path, value, invalidCCOperationParameterErrMsg+": supported only by the rebalance and remove_broker operations"))
+		} else {
+			allErrs = append(allErrs, validateDestinationBrokerIDs(path, value, params[banzaicloudv1alpha1.ParamBrokerID], cluster)...)
+		}
+	}
+
+	if value, ok := params[banzaicloudv1alpha1.ParamExcludedTopics]; ok {
+		if _, err := regexp.Compile(value); err != nil {
+			allErrs = append(allErrs, field.Invalid(paramPath(banzaicloudv1alpha1.ParamExcludedTopics, "excludedTopics"), value,
+				invalidCCOperationParameterErrMsg+": not a valid regular expression: "+err.Error()))
+		}
+	}
+	return allErrs
+}
+
+func validateDestinationBrokerIDs(path *field.Path, value, removedBrokerIDs string, cluster *banzaicloudv1beta1.KafkaCluster) field.ErrorList {
+	brokerIDs := make(map[string]struct{})
+	if cluster != nil {
+		for _, broker := range cluster.Spec.Brokers {
+			brokerIDs[strconv.Itoa(int(broker.Id))] = struct{}{}
+		}
+	}
+	removed := make(map[string]struct{})
+	for _, id := range strings.Split(removedBrokerIDs, ",") {
+		removed[strings.TrimSpace(id)] = struct{}{}
+	}
+
+	var allErrs field.ErrorList
+	seen := make(map[string]struct{})
+	for _, id := range strings.Split(value, ",") {
+		id = strings.TrimSpace(id)
+		if _, err := strconv.ParseInt(id, 10, 32); err != nil {
+			allErrs = append(allErrs, field.Invalid(path, value, fmt.Sprintf("%s: %q is not a broker ID", invalidCCOperationParameterErrMsg, id)))
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			allErrs = append(allErrs, field.Invalid(path, value, fmt.Sprintf("%s: broker %s is listed more than once", invalidCCOperationParameterErrMsg, id)))
+		}
+		seen[id] = struct{}{}
+		if _, ok := removed[id]; ok {
+			allErrs = append(allErrs, field.Invalid(path, value, fmt.Sprintf("%s: broker %s is removed by the operation", invalidCCOperationParameterErrMsg, id)))
+		}
+		if _, ok := brokerIDs[id]; cluster != nil && !ok {
+			allErrs = append(allErrs, field.Invalid(path, value, fmt.Sprintf("%s: broker %s is not part of the Kafka cluster", invalidCCOperationParameterErrMsg, id)))
+		}
+	}
+	return allErrs
+}
+
+func (s CruiseControlOperationValidator) invalid(log logr.Logger, operation *banzaicloudv1alpha1.CruiseControlOperation, fieldErrs field.ErrorList) error {
+	if len(fieldErrs) == 0 {
+		return nil
+	}
+	log.Info("rejected", "invalid field(s)", fieldErrs.ToAggregate().Error())
+	return apierrors.NewInvalid(banzaicloudv1alpha1.GroupVersion.WithKind("CruiseControlOperation").GroupKind(), operation.GetName(), fieldErrs)
+}
+
+func (s CruiseControlOperationValidator) forbidden(log logr.Logger, operation *banzaicloudv1alpha1.CruiseControlOperation, msg string, err error) error {
+	log.Info("rejected", "reason", err.Error())
+	return apierrors.NewForbidden(banzaicloudv1alpha1.GroupVersion.WithResource("cruisecontroloperations").GroupResource(),
+		operation.GetName(), errors.WithMessage(err, msg))
+}
@@ -0,0 +1,201 @@
+// Copyright © 2022 Cisco Systems, Inc. and/or its affiliates
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/banzaicloud/koperator/api/v1alpha1"
+	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
+)
+
+func newCCOperationWithTask(operation v1alpha1.CruiseControlTaskOperation, params map[string]string,
+	typedParams *v1alpha1.CruiseControlTaskParameters) *v1alpha1.CruiseControlOperation {
+	return &v1alpha1.CruiseControlOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-operation",
+			Namespace: "test-namespace",
+			Labels:    map[string]string{v1beta1.KafkaCRLabelKey: "test-cluster"},
+		},
+		Status: v1alpha1.CruiseControlOperationStatus{
+			CurrentTask: &v1alpha1.CruiseControlTask{
+				Operation:       operation,
+				Parameters:      params,
+				TypedParameters: typedParams,
+			},
+		},
+	}
+}
+
+func TestValidateCCOperationParameters(t *testing.T) {
+	cluster := newMockCluster()
+	cluster.Spec.Brokers = []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}}
+
+	testCases := []struct {
+		testName    string
+		operation   *v1alpha1.CruiseControlOperation
+		expectedErr string
+	}{
+		{
+			testName: "targeted rebalance",
+			operation: newCCOperationWithTask(v1alpha1.OperationRebalance, nil, &v1alpha1.CruiseControlTaskParameters{
+				DestinationBrokerIDs: []int32{1, 2},
+				ExcludedTopics:       "^__.*",
+			}),
+		},
+		{
+			testName: "intra-broker disk rebalance",
+			operation: newCCOperationWithTask(v1alpha1.OperationRebalance,
+				map[string]string{v1alpha1.ParamDestbrokerIDs: "0", v1alpha1.ParamRebalanceDisk: "true"}, nil),
+		},
+		{
+			testName: "unknown destination broker",
+			operation: newCCOperationWithTask(v1alpha1.OperationRebalance, nil, &v1alpha1.CruiseControlTaskParameters{
+				DestinationBrokerIDs: []int32{1, 5},
+			}),
+			expectedErr: "status.currentTask.typedParameters.destinationBrokerIds: Invalid value: \"1,5\": " +
+				"invalid Cruise Control operation parameter: broker 5 is not part of the Kafka cluster",
+		},
+		{
+			testName: "destination broker is removed",
+			operation: newCCOperationWithTask(v1alpha1.OperationRemoveBroker,
+				map[string]string{v1alpha1.ParamBrokerID: "2", v1alpha1.ParamDestbrokerIDs: "1,2"}, nil),
+			expectedErr: "status.currentTask.parameters[destination_broker_ids]: Invalid value: \"1,2\": " +
+				"invalid Cruise Control operation parameter: broker 2 is removed by the operation",
+		},
+		{
+			testName: "malformed destination brokers",
+			operation: newCCOperationWithTask(v1alpha1.OperationRebalance,
+				map[string]string{v1alpha1.ParamDestbrokerIDs: "1,one"}, nil),
+			expectedErr: "invalid Cruise Control operation parameter: \"one\" is not a broker ID",
+		},
+		{
+			testName: "destination brokers of add_broker",
+			operation: newCCOperationWithTask(v1alpha1.OperationAddBroker, nil, &v1alpha1.CruiseControlTaskParameters{
+				BrokerIDs:            []int32{2},
+				DestinationBrokerIDs: []int32{1},
+			}),
+			expectedErr: "supported only by the rebalance and remove_broker operations",
+		},
+		{
+			testName: "disk rebalance of remove_broker",
+			operation: newCCOperationWithTask(v1alpha1.OperationRemoveBroker, nil, &v1alpha1.CruiseControlTaskParameters{
+				BrokerIDs:     []int32{2},
+				RebalanceDisk: util.BoolPointer(true),
+			}),
+			expectedErr: "status.currentTask.typedParameters.rebalanceDisk: Invalid value: \"true\": " +
+				"invalid Cruise Control operation parameter: supported only by the rebalance operation",
+		},
+		{
+			testName: "invalid excluded topics",
+			operation: newCCOperationWithTask(v1alpha1.OperationRebalance, nil, &v1alpha1.CruiseControlTaskParameters{
+				ExcludedTopics: "topic-(",
+			}),
+			expectedErr: "not a valid regular expression",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			fieldErrs := validateCCOperationParameters(testCase.operation, cluster)
+			if testCase.expectedErr == "" {
+				assert.Empty(t, fieldErrs)
+				return
+			}
+			require.NotEmpty(t, fieldErrs)
+			assert.Contains(t, fieldErrs.ToAggregate().Error(), testCase.expectedErr)
+		})
+	}
+}
+
+func TestCruiseControlOperationValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cluster := newMockCluster()
+	cluster.Spec.Brokers = []v1beta1.Broker{{Id: 0}, {Id: 1}}
+	validator := CruiseControlOperationValidator{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build(),
+		Log:    logr.Discard(),
+	}
+
+	operation := newCCOperationWithTask(v1alpha1.OperationRebalance, nil, nil)
+	require.NoError(t, validator.ValidateCreate(context.Background(), operation))
+
+	updated := operation.DeepCopy()
+	updated.Status.CurrentTask.TypedParameters = &v1alpha1.CruiseControlTaskParameters{DestinationBrokerIDs: []int32{3}}
+	err := validator.ValidateUpdate(context.Background(), operation, updated)
+	require.Error(t, err)
+	assert.True(t, apierrors.IsInvalid(err))
+
+	// the parameters of operations referring to unknown Kafka clusters are validated without the broker IDs
+	updated.Labels[v1beta1.KafkaCRLabelKey] = "unknown-cluster"
+	assert.NoError(t, validator.ValidateUpdate(context.Background(), operation, updated))
+}
+
+func TestCruiseControlOperationValidatorController(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	validator := CruiseControlOperationValidator{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(newMockCluster()).Build(),
+		Log:    logr.Discard(),
+		OperatorUsername: OperatorUsernameFromEnv(func(name string) string {
+			return map[string]string{PodNamespaceEnvVar: "kafka", PodServiceAccountEnvVar: "kafka-operator"}[name]
+		}),
+	}
+	requestBy := func(username string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Username: username}},
+		})
+	}
+
+	operation := newCCOperationWithTask(v1alpha1.OperationRemoveBroker, nil, nil)
+	operation.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: v1beta1.GroupVersion.String(),
+		Kind:       "KafkaCluster",
+		Name:       "test-cluster",
+		Controller: util.BoolPointer(true),
+	}})
+	assert.NoError(t, validator.ValidateCreate(requestBy("system:serviceaccount:kafka:kafka-operator"), operation))
+
+	// other users cannot exempt their operations from the quotas by setting the Kafka cluster as their controller
+	err := validator.ValidateCreate(requestBy("system:serviceaccount:tenant:default"), operation)
+	require.Error(t, err)
+	assert.True(t, apierrors.IsForbidden(err))
+
+	notControlled := newCCOperationWithTask(v1alpha1.OperationRemoveBroker, nil, nil)
+	err = validator.ValidateUpdate(requestBy("tenant"), notControlled, operation)
+	require.Error(t, err)
+	assert.True(t, apierrors.IsForbidden(err))
+
+	// the unchanged controller of the operations of the Koperator does not prevent the updates of other users
+	updated := operation.DeepCopy()
+	updated.Labels["tenant"] = "a"
+	assert.NoError(t, validator.ValidateUpdate(requestBy("tenant"), operation, updated))
+}
@@ -28,6 +28,23 @@ const (
 	outOfRangePartitionsErrMsg                = "number of partitions must be larger than 0 (or set it to be -1 to use the broker's default)"
 	unsupportedRemovingStorageMsg             = "removing storage from a broker is not supported"
 	invalidExternalListenerStartingPortErrMsg = "invalid external listener starting port number"
+	exceededCCOperationQuotaMsg               = "CruiseControlOperation quota exceeded"
+	forbiddenCCOperationControllerMsg         = "CruiseControlOperation controller is not allowed"
+	invalidListenerCertManagerConfigErrMsg    = "listener certificates can be issued by cert-manager only when sslSecrets is set"
+	invalidListenerTLSPolicyErrMsg            = "invalid listener TLS policy"
+	invalidListenerSPIFFEConfigErrMsg         = "invalid listener SPIFFE configuration"
+	invalidBrokerIDErrMsg                     = "invalid broker ID"
+	invalidAuthorizationConfigErrMsg          = "invalid authorization configuration"
+	invalidListenerSecurityConfigErrMsg       = "invalid listener security configuration"
+	invalidReplicationListenerErrMsg          = "invalid replication listener configuration"
+	invalidRemoteJMXConfigErrMsg              = "invalid remote JMX configuration"
+	invalidCCOperationParameterErrMsg         = "invalid Cruise Control operation parameter"
+	invalidParkedBrokerConfigGroupsErrMsg     = "invalid parked broker config groups"
+	invalidCapacityScheduleErrMsg             = "invalid capacity schedule"
+	invalidListenerAccessControlErrMsg        = "invalid listener access control"
+	invalidListenerConnectionLimitsErrMsg     = "invalid listener connection limits"
+	crossNamespaceReferenceNotAllowedErrMsg   = "cross-namespace reference is not allowed"
+	invalidCruiseControlSettingsErrMsg        = "invalid Cruise Control settings"
 
 	// errorDuringValidationMsg is added to infrastructure errors (e.g. failed to connect), but not to field validation errors
 	errorDuringValidationMsg = "error during validation"
@@ -61,6 +78,10 @@ func IsAdmissionInvalidExternalListenerPort(err error) bool {
 	return apierrors.IsInvalid(err) && strings.Contains(err.Error(), invalidExternalListenerStartingPortErrMsg)
 }
 
+func IsAdmissionExceededCCOperationQuota(err error) bool {
+	return apierrors.IsForbidden(err) && strings.Contains(err.Error(), exceededCCOperationQuotaMsg)
+}
+
 func IsAdmissionErrorDuringValidation(err error) bool {
 	return apierrors.IsInternalError(err) && strings.Contains(err.Error(), errorDuringValidationMsg)
 }
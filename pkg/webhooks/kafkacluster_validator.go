@@ -17,21 +17,36 @@ package webhooks
 import (
 	"context"
 	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"emperror.dev/errors"
 	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/go-logr/logr"
 
+	apiutil "github.com/banzaicloud/koperator/api/util"
 	banzaicloudv1beta1 "github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/scale"
 	"github.com/banzaicloud/koperator/pkg/util"
+	envoyutils "github.com/banzaicloud/koperator/pkg/util/envoy"
+	kafkautils "github.com/banzaicloud/koperator/pkg/util/kafka"
+	properties "github.com/banzaicloud/koperator/properties/pkg"
 )
 
 type KafkaClusterValidator struct {
-	Log logr.Logger
+	// Client is used for looking up the data left behind by the former brokers, the check is skipped when it is nil
+	Client client.Client
+	Log    logr.Logger
 }
 
 func (s KafkaClusterValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
@@ -54,6 +69,21 @@ func (s KafkaClusterValidator) ValidateUpdate(ctx context.Context, oldObj, newOb
 		allErrs = append(allErrs, listenerErrs...)
 	}
 
+	allErrs = append(allErrs, checkBrokerIDPolicy(kafkaClusterOld, kafkaClusterNew)...)
+	allErrs = append(allErrs, checkRetiredBrokerIDs(kafkaClusterOld, kafkaClusterNew)...)
+	allErrs = append(allErrs, checkAuthorizationConfig(&kafkaClusterNew.Spec)...)
+	allErrs = append(allErrs, checkRemoteJMXConfig(&kafkaClusterNew.Spec)...)
+	allErrs = append(allErrs, checkParkedBrokerConfigGroups(&kafkaClusterNew.Spec)...)
+	allErrs = append(allErrs, checkCapacitySchedule(&kafkaClusterNew.Spec)...)
+	allErrs = append(allErrs, checkCruiseControlSettings(&kafkaClusterNew.Spec)...)
+
+	retainedDataErrs, err := s.checkRetainedBrokerData(ctx, kafkaClusterOld, kafkaClusterNew)
+	if err != nil {
+		log.Error(err, errorDuringValidationMsg)
+		return apierrors.NewInternalError(errors.WithMessage(err, errorDuringValidationMsg))
+	}
+	allErrs = append(allErrs, retainedDataErrs...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -74,6 +104,12 @@ func (s KafkaClusterValidator) ValidateCreate(ctx context.Context, obj runtime.O
 		allErrs = append(allErrs, listenerErrs...)
 	}
 
+	allErrs = append(allErrs, checkAuthorizationConfig(&kafkaCluster.Spec)...)
+	allErrs = append(allErrs, checkRemoteJMXConfig(&kafkaCluster.Spec)...)
+	allErrs = append(allErrs, checkParkedBrokerConfigGroups(&kafkaCluster.Spec)...)
+	allErrs = append(allErrs, checkCapacitySchedule(&kafkaCluster.Spec)...)
+	allErrs = append(allErrs, checkCruiseControlSettings(&kafkaCluster.Spec)...)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -88,6 +124,166 @@ func (s KafkaClusterValidator) ValidateDelete(ctx context.Context, obj runtime.O
 	return nil
 }
 
+// addedBrokerIndexes returns the indexes of the brokers of the new KafkaCluster which are neither part of the old
+// KafkaCluster nor being removed from it
+func addedBrokerIndexes(kafkaClusterOld, kafkaClusterNew *banzaicloudv1beta1.KafkaCluster) []int {
+	existing := make(map[int32]struct{}, len(kafkaClusterOld.Spec.Brokers))
+	for _, broker := range kafkaClusterOld.Spec.Brokers {
+		existing[broker.Id] = struct{}{}
+	}
+	var added []int
+	for i, broker := range kafkaClusterNew.Spec.Brokers {
+		if _, ok := existing[broker.Id]; ok {
+			continue
+		}
+		if _, ok := kafkaClusterOld.Status.BrokersState[strconv.Itoa(int(broker.Id))]; ok {
+			continue
+		}
+		added = append(added, i)
+	}
+	return added
+}
+
+// checkBrokerIDPolicy checks that the IDs of the added brokers are higher than the ID of any former broker when
+// the AlwaysIncrement broker ID policy is used
+func checkBrokerIDPolicy(kafkaClusterOld, kafkaClusterNew *banzaicloudv1beta1.KafkaCluster) field.ErrorList {
+	if kafkaClusterNew.Spec.GetBrokerIDPolicy() != banzaicloudv1beta1.BrokerIDPolicyAlwaysIncrement {
+		return nil
+	}
+	highest, ok := kafkaClusterOld.GetHighestBrokerID()
+	if !ok {
+		return nil
+	}
+	var allErrs field.ErrorList
+	for _, i := range addedBrokerIndexes(kafkaClusterOld, kafkaClusterNew) {
+		if id := kafkaClusterNew.Spec.Brokers[i].Id; id <= highest {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("brokers").Index(i).Child("id"), id,
+				fmt.Sprintf("%s, the ID of the added broker must be higher than %d", invalidBrokerIDErrMsg, highest)))
+		}
+	}
+	return allErrs
+}
+
+// checkRetiredBrokerIDs checks that the added brokers do not get an ID which has been retired by a
+// KafkaBrokerDecommission
+func checkRetiredBrokerIDs(kafkaClusterOld, kafkaClusterNew *banzaicloudv1beta1.KafkaCluster) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, i := range addedBrokerIndexes(kafkaClusterOld, kafkaClusterNew) {
+		if id := kafkaClusterNew.Spec.Brokers[i].Id; kafkaClusterOld.IsBrokerIDRetired(id) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("brokers").Index(i).Child("id"), id,
+				fmt.Sprintf("%s, the ID has been retired by a KafkaBrokerDecommission", invalidBrokerIDErrMsg)))
+		}
+	}
+	return allErrs
+}
+
+// checkRetainedBrokerData checks that the added brokers do not get the ID of a former broker whose data is still
+// kept on a PersistentVolumeClaim, since the new broker would start with the stale log segments of the former one
+func (s KafkaClusterValidator) checkRetainedBrokerData(ctx context.Context, kafkaClusterOld, kafkaClusterNew *banzaicloudv1beta1.KafkaCluster) (field.ErrorList, error) {
+	if s.Client == nil {
+		return nil, nil
+	}
+	var allErrs field.ErrorList
+	for _, i := range addedBrokerIndexes(kafkaClusterOld, kafkaClusterNew) {
+		id := kafkaClusterNew.Spec.Brokers[i].Id
+		pvcList := &corev1.PersistentVolumeClaimList{}
+		err := s.Client.List(ctx, pvcList, client.InNamespace(kafkaClusterNew.GetNamespace()),
+			client.MatchingLabels(apiutil.MergeLabels(
+				apiutil.LabelsForKafka(kafkaClusterNew.GetName()),
+				map[string]string{banzaicloudv1beta1.BrokerIdLabelKey: strconv.Itoa(int(id))},
+			)))
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not list the persistent volume claims of the broker", "brokerId", id)
+		}
+		for _, pvc := range pvcList.Items {
+			if pvc.GetDeletionTimestamp() != nil {
+				continue
+			}
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("brokers").Index(i).Child("id"), id,
+				fmt.Sprintf("%s, persistent volume claim %s still holds the data of a former broker with the same ID",
+					invalidBrokerIDErrMsg, pvc.GetName())))
+			break
+		}
+	}
+	return allErrs, nil
+}
+
+// checkAuthorizationConfig checks that the authorizer plugin is fully configured, the brokers communicate over an
+// SSL listener whose principals are made super users, the super users are valid principals and the authorizer is not
+// configured in the read-only configuration as well
+func checkAuthorizationConfig(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	authConfig := kafkaClusterSpec.AuthorizationConfig
+	if authConfig == nil {
+		return nil
+	}
+	path := field.NewPath("spec").Child("authorizationConfig")
+
+	var allErrs field.ErrorList
+	switch authConfig.GetAuthorizer() {
+	case banzaicloudv1beta1.AuthorizerTypeCustom:
+		if authConfig.CustomAuthorizerClassName == "" {
+			allErrs = append(allErrs, field.Required(path.Child("customAuthorizerClassName"),
+				invalidAuthorizationConfigErrMsg+": the class name must be set for custom authorizers"))
+		}
+	default:
+		if authConfig.CustomAuthorizerClassName != "" {
+			allErrs = append(allErrs, field.Forbidden(path.Child("customAuthorizerClassName"),
+				invalidAuthorizationConfigErrMsg+": the class name can only be set for custom authorizers"))
+		}
+	}
+	if authConfig.GetAuthorizer() == banzaicloudv1beta1.AuthorizerTypeOPA {
+		if authConfig.OPA == nil || authConfig.OPA.URL == "" {
+			allErrs = append(allErrs, field.Required(path.Child("opa").Child("url"),
+				invalidAuthorizationConfigErrMsg+": the URL of the OPA policy must be set"))
+		}
+	} else if authConfig.OPA != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("opa"),
+			invalidAuthorizationConfigErrMsg+": OPA can only be configured for the OPA authorizer"))
+	}
+
+	// The brokers, the operator and Cruise Control are only made super users by the principals of the certificates of
+	// the non-SPIFFE SSL listeners. Over any other listener they would lock themselves out of the cluster, e.g.
+	// unauthenticated clients are all the anonymous user which could only be granted access by granting it to everyone.
+	for i, listener := range kafkaClusterSpec.ListenersConfig.InternalListeners {
+		if !listener.UsedForInnerBrokerCommunication && !listener.UsedForControllerCommunication {
+			continue
+		}
+		listenerPath := field.NewPath("spec").Child("listenersConfig").Child("internalListeners").Index(i)
+		switch {
+		case listener.Type != banzaicloudv1beta1.SecurityProtocolSSL:
+			allErrs = append(allErrs, field.Invalid(listenerPath.Child("type"), listener.Type,
+				invalidAuthorizationConfigErrMsg+": the brokers must communicate over an ssl listener when authorization is enabled"))
+		case listener.UsesSPIFFE():
+			allErrs = append(allErrs, field.Invalid(listenerPath.Child("spiffe"), listener.SPIFFE,
+				invalidAuthorizationConfigErrMsg+": the brokers cannot communicate over a SPIFFE listener when authorization is enabled"))
+		}
+	}
+
+	for i, principal := range authConfig.SuperUsers {
+		principalType, name, found := strings.Cut(principal, ":")
+		if !found || principalType == "" || name == "" || strings.Contains(principal, ";") {
+			allErrs = append(allErrs, field.Invalid(path.Child("superUsers").Index(i), principal,
+				invalidAuthorizationConfigErrMsg+": super users must be principals in <type>:<name> format, e.g. User:admin"))
+		}
+	}
+
+	for key := range authConfig.PluginConfig {
+		if key == kafkautils.KafkaConfigAuthorizerClassName || key == kafkautils.KafkaConfigSuperUsers {
+			allErrs = append(allErrs, field.Forbidden(path.Child("pluginConfig").Key(key),
+				invalidAuthorizationConfigErrMsg+": the property is managed by the operator"))
+		}
+	}
+
+	readOnlyConfig, err := properties.NewFromString(kafkaClusterSpec.ReadOnlyConfig)
+	if err == nil {
+		if _, found := readOnlyConfig.Get(kafkautils.KafkaConfigAuthorizerClassName); found {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("readOnlyConfig"),
+				invalidAuthorizationConfigErrMsg+": "+kafkautils.KafkaConfigAuthorizerClassName+" cannot be set when authorizationConfig is used"))
+		}
+	}
+	return allErrs
+}
+
 // checkBrokerStorageRemoval checks if there is any broker storage which has been removed. If yes, admission will be rejected
 func checkBrokerStorageRemoval(kafkaClusterSpecOld, kafkaClusterSpecNew *banzaicloudv1beta1.KafkaClusterSpec) (*field.Error, error) {
 	for j := range kafkaClusterSpecOld.Brokers {
@@ -166,6 +362,471 @@ func checkInternalAndExternalListeners(kafkaClusterSpec *banzaicloudv1beta1.Kafk
 
 	allErrs = append(allErrs, checkExternalListenerStartingPort(kafkaClusterSpec)...)
 
+	allErrs = append(allErrs, checkListenerCertManagerConfig(kafkaClusterSpec)...)
+
+	allErrs = append(allErrs, checkListenerTLSPolicy(kafkaClusterSpec.ListenersConfig)...)
+
+	allErrs = append(allErrs, checkListenerSPIFFE(kafkaClusterSpec)...)
+
+	allErrs = append(allErrs, checkListenerSecurity(kafkaClusterSpec)...)
+
+	allErrs = append(allErrs, checkReplicationListener(kafkaClusterSpec.ListenersConfig)...)
+
+	allErrs = append(allErrs, checkListenerAccessControl(kafkaClusterSpec)...)
+
+	allErrs = append(allErrs, checkListenerConnectionLimits(kafkaClusterSpec)...)
+
+	return allErrs
+}
+
+// checkListenerAccessControl checks that the access control of the external listeners is enforced by Envoy
+// and that the source ranges are valid CIDRs
+func checkListenerAccessControl(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, extListener := range kafkaClusterSpec.ListenersConfig.ExternalListeners {
+		if extListener.AccessControl == nil {
+			continue
+		}
+		path := field.NewPath("spec").Child("listenersConfig").Child("externalListeners").Index(i).Child("accessControl")
+		if kafkaClusterSpec.GetIngressController() != envoyutils.IngressControllerName ||
+			extListener.GetAccessMethod() != corev1.ServiceTypeLoadBalancer {
+			allErrs = append(allErrs, field.Forbidden(path,
+				invalidListenerAccessControlErrMsg+": access control is supported only with Envoy ingress controller and LoadBalancer access method"))
+		}
+		for j, sourceRange := range extListener.AccessControl.AllowedSourceRanges {
+			if _, _, err := net.ParseCIDR(sourceRange); err != nil {
+				allErrs = append(allErrs, field.Invalid(path.Child("allowedSourceRanges").Index(j), sourceRange, invalidListenerAccessControlErrMsg+": "+err.Error()))
+			}
+		}
+		for j, sourceRange := range extListener.AccessControl.DeniedSourceRanges {
+			if _, _, err := net.ParseCIDR(sourceRange); err != nil {
+				allErrs = append(allErrs, field.Invalid(path.Child("deniedSourceRanges").Index(j), sourceRange, invalidListenerAccessControlErrMsg+": "+err.Error()))
+			}
+		}
+	}
+	return allErrs
+}
+
+// checkListenerConnectionLimits checks that the per IP overrides reference IP addresses or hostnames, that the
+// limits of the listeners do not exceed the limit of the broker and that the limits are not set in readOnlyConfig too
+func checkListenerConnectionLimits(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	limits := kafkaClusterSpec.ListenersConfig.ConnectionLimits
+	path := field.NewPath("spec").Child("listenersConfig")
+
+	checkListener := func(path *field.Path, listener banzaicloudv1beta1.CommonListenerSpec) {
+		if listener.ConnectionLimits == nil || listener.ConnectionLimits.MaxConnections == nil ||
+			limits == nil || limits.MaxConnections == nil {
+			return
+		}
+		if *listener.ConnectionLimits.MaxConnections > *limits.MaxConnections {
+			allErrs = append(allErrs, field.Invalid(path.Child("connectionLimits").Child("maxConnections"), *listener.ConnectionLimits.MaxConnections,
+				invalidListenerConnectionLimitsErrMsg+fmt.Sprintf(": the limit of the listener cannot be higher than the limit of the broker (%d)", *limits.MaxConnections)))
+		}
+	}
+	for i, iListener := range kafkaClusterSpec.ListenersConfig.InternalListeners {
+		checkListener(path.Child("internalListeners").Index(i), iListener.CommonListenerSpec)
+	}
+	for i, eListener := range kafkaClusterSpec.ListenersConfig.ExternalListeners {
+		checkListener(path.Child("externalListeners").Index(i), eListener.CommonListenerSpec)
+	}
+
+	if limits == nil {
+		return allErrs
+	}
+	overridesPath := path.Child("connectionLimits").Child("maxConnectionsPerIPOverrides")
+	hosts := make([]string, 0, len(limits.MaxConnectionsPerIPOverrides))
+	for host := range limits.MaxConnectionsPerIPOverrides {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		if net.ParseIP(host) == nil && len(validation.IsDNS1123Subdomain(host)) > 0 {
+			allErrs = append(allErrs, field.Invalid(overridesPath.Key(host), host,
+				invalidListenerConnectionLimitsErrMsg+": the key must be an IP address or a hostname"))
+		}
+		if limits.MaxConnectionsPerIPOverrides[host] < 0 {
+			allErrs = append(allErrs, field.Invalid(overridesPath.Key(host), limits.MaxConnectionsPerIPOverrides[host],
+				invalidListenerConnectionLimitsErrMsg+": the number of connections must not be negative"))
+		}
+	}
+
+	readOnlyConfig, err := properties.NewFromString(kafkaClusterSpec.ReadOnlyConfig)
+	if err == nil {
+		for _, key := range []string{kafkautils.KafkaConfigMaxConnections, kafkautils.KafkaConfigMaxConnectionsPerIP,
+			kafkautils.KafkaConfigMaxConnectionsPerIPOverrides, kafkautils.KafkaConfigMaxConnectionCreationRate} {
+			if _, found := readOnlyConfig.Get(key); found {
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("readOnlyConfig"),
+					invalidListenerConnectionLimitsErrMsg+": "+key+" cannot be set when connectionLimits is used"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// checkReplicationListener checks that at most one internal listener is used for replication and that the listeners
+// bound to secondary networks are neither used by the operator nor share the network interface of the broker pods
+// checkRemoteJMXConfig checks that the port of the remote JMX endpoint is not used by a listener or set by the JMX_PORT
+// environment variable and that the endpoint
+// is protected with SSL only when the listener used for inner broker communication has a server certificate mounted into
+// the brokers
+func checkRemoteJMXConfig(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	if !kafkaClusterSpec.IsRemoteJMXEnabled() {
+		return nil
+	}
+	path := field.NewPath("spec").Child("remoteJmxConfig")
+	jmxConfig := kafkaClusterSpec.RemoteJMXConfig
+
+	var allErrs field.ErrorList
+	port := jmxConfig.GetPort()
+	for _, iListener := range kafkaClusterSpec.ListenersConfig.InternalListeners {
+		if iListener.ContainerPort == port {
+			allErrs = append(allErrs, field.Invalid(path.Child("port"), port,
+				invalidRemoteJMXConfigErrMsg+": the port is used by the internal listener "+iListener.Name))
+		}
+	}
+	for _, eListener := range kafkaClusterSpec.ListenersConfig.ExternalListeners {
+		if eListener.ContainerPort == port {
+			allErrs = append(allErrs, field.Invalid(path.Child("port"), port,
+				invalidRemoteJMXConfigErrMsg+": the port is used by the external listener "+eListener.Name))
+		}
+	}
+
+	for i, envVar := range kafkaClusterSpec.Envs {
+		if envVar.Name == "JMX_PORT" {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("envs").Index(i),
+				invalidRemoteJMXConfigErrMsg+": the JMX port is set by remoteJmxConfig"))
+		}
+	}
+
+	if !jmxConfig.SSL {
+		return allErrs
+	}
+	for _, iListener := range kafkaClusterSpec.ListenersConfig.InternalListeners {
+		if !iListener.UsedForInnerBrokerCommunication {
+			continue
+		}
+		if iListener.Type != banzaicloudv1beta1.SecurityProtocolSSL || iListener.UsesSPIFFE() {
+			allErrs = append(allErrs, field.Forbidden(path.Child("ssl"),
+				invalidRemoteJMXConfigErrMsg+": SSL requires the listener used for inner broker communication to be an SSL listener which does not use SPIFFE"))
+		}
+		return allErrs
+	}
+	return append(allErrs, field.Forbidden(path.Child("ssl"),
+		invalidRemoteJMXConfigErrMsg+": SSL requires a listener used for inner broker communication"))
+}
+
+// checkParkedBrokerConfigGroups checks that the parked broker config groups exist and that at least one broker of the
+// cluster is left running to take over the partition replicas of the parked brokers
+func checkParkedBrokerConfigGroups(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	if len(kafkaClusterSpec.ParkedBrokerConfigGroups) == 0 {
+		return nil
+	}
+	path := field.NewPath("spec").Child("parkedBrokerConfigGroups")
+
+	var allErrs field.ErrorList
+	for i, group := range kafkaClusterSpec.ParkedBrokerConfigGroups {
+		if _, ok := kafkaClusterSpec.BrokerConfigGroups[group]; !ok {
+			allErrs = append(allErrs, field.NotFound(path.Index(i), group))
+		}
+	}
+
+	for _, broker := range kafkaClusterSpec.Brokers {
+		if !kafkaClusterSpec.IsBrokerConfigGroupParked(broker.BrokerConfigGroup) {
+			return allErrs
+		}
+	}
+	return append(allErrs, field.Forbidden(path, invalidParkedBrokerConfigGroupsErrMsg+": at least one broker must not be parked"))
+}
+
+// checkCapacitySchedule checks that the time zone of the capacity schedule is known and that its rules scale existing
+// broker config groups to a non-negative number of brokers
+func checkCapacitySchedule(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	schedule := kafkaClusterSpec.CapacitySchedule
+	if schedule == nil {
+		return nil
+	}
+	path := field.NewPath("spec").Child("capacitySchedule")
+
+	var allErrs field.ErrorList
+	if schedule.TimeZone != "" {
+		if _, err := time.LoadLocation(schedule.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("timeZone"), schedule.TimeZone,
+				invalidCapacityScheduleErrMsg+": unknown time zone"))
+		}
+	}
+	for i, rule := range schedule.Rules {
+		groups := make([]string, 0, len(rule.BrokerCounts))
+		for group := range rule.BrokerCounts {
+			groups = append(groups, group)
+		}
+		sort.Strings(groups)
+		for _, group := range groups {
+			countPath := path.Child("rules").Index(i).Child("brokerCounts").Key(group)
+			if _, ok := kafkaClusterSpec.BrokerConfigGroups[group]; !ok {
+				allErrs = append(allErrs, field.NotFound(countPath, group))
+			}
+			if count := rule.BrokerCounts[group]; count < 0 {
+				allErrs = append(allErrs, field.Invalid(countPath, count,
+					invalidCapacityScheduleErrMsg+": the number of brokers must not be negative"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// checkCruiseControlSettings checks that the typed settings of Cruise Control are supported by its version
+func checkCruiseControlSettings(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	ccConfig := kafkaClusterSpec.CruiseControlConfig
+	if ccConfig.Settings == nil {
+		return nil
+	}
+	if err := scale.CheckPropertiesSupported(ccConfig.GetVersion(), ccConfig.Settings.Properties()); err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("spec").Child("cruiseControlConfig").Child("settings"),
+			ccConfig.Settings, invalidCruiseControlSettingsErrMsg+": "+err.Error())}
+	}
+	return nil
+}
+
+func checkReplicationListener(listeners banzaicloudv1beta1.ListenersConfig) field.ErrorList {
+	var allErrs field.ErrorList
+	replicationListenerFound := false
+	interfaces := make(map[string]struct{})
+	for i, intListener := range listeners.InternalListeners {
+		path := field.NewPath("spec").Child("listenersConfig").Child("internalListeners").Index(i)
+		if intListener.UsedForReplication {
+			if replicationListenerFound {
+				allErrs = append(allErrs, field.Invalid(path.Child("usedForReplication"), true,
+					invalidReplicationListenerErrMsg+": only one internal listener can be used for replication"))
+			}
+			replicationListenerFound = true
+		}
+		attachment := intListener.NetworkAttachment
+		if attachment == nil {
+			continue
+		}
+		if intListener.UsedForInnerBrokerCommunication {
+			allErrs = append(allErrs, field.Forbidden(path.Child("networkAttachment"),
+				invalidReplicationListenerErrMsg+": the listener used for inner broker communication cannot be bound to a secondary network"))
+		}
+		if _, ok := interfaces[attachment.Interface]; ok {
+			allErrs = append(allErrs, field.Duplicate(path.Child("networkAttachment").Child("interface"), attachment.Interface))
+		}
+		interfaces[attachment.Interface] = struct{}{}
+	}
+	return allErrs
+}
+
+// checkListenerSecurity checks that the client authentication mode is set on SSL listeners only and that the listener
+// used for inner broker communication can be used by the brokers, the operator and Cruise Control. They connect to it
+// without SASL credentials and, when it uses SSL, authenticate with client certificates.
+func checkListenerSecurity(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	listenersPath := field.NewPath("spec").Child("listenersConfig")
+
+	var innerBrokerListeners, controllerListeners []int
+	for i, intListener := range kafkaClusterSpec.ListenersConfig.InternalListeners {
+		path := listenersPath.Child("internalListeners").Index(i)
+		allErrs = append(allErrs, checkSSLClientAuth(path, intListener.CommonListenerSpec)...)
+		if intListener.UsedForInnerBrokerCommunication {
+			innerBrokerListeners = append(innerBrokerListeners, i)
+		}
+		if intListener.UsedForControllerCommunication {
+			controllerListeners = append(controllerListeners, i)
+		}
+	}
+	for i, extListener := range kafkaClusterSpec.ListenersConfig.ExternalListeners {
+		allErrs = append(allErrs, checkSSLClientAuth(listenersPath.Child("externalListeners").Index(i), extListener.CommonListenerSpec)...)
+	}
+
+	switch {
+	case len(innerBrokerListeners) == 0:
+		allErrs = append(allErrs, field.Required(listenersPath.Child("internalListeners"),
+			invalidListenerSecurityConfigErrMsg+": one internal listener must be used for inner broker communication"))
+	case len(innerBrokerListeners) > 1:
+		for _, i := range innerBrokerListeners[1:] {
+			allErrs = append(allErrs, field.Invalid(listenersPath.Child("internalListeners").Index(i).Child("usedForInnerBrokerCommunication"), true,
+				invalidListenerSecurityConfigErrMsg+": only one internal listener can be used for inner broker communication"))
+		}
+	default:
+		i := innerBrokerListeners[0]
+		allErrs = append(allErrs, checkInnerBrokerListener(listenersPath.Child("internalListeners").Index(i), kafkaClusterSpec,
+			kafkaClusterSpec.ListenersConfig.InternalListeners[i].CommonListenerSpec)...)
+	}
+	if len(controllerListeners) > 1 {
+		for _, i := range controllerListeners[1:] {
+			allErrs = append(allErrs, field.Invalid(listenersPath.Child("internalListeners").Index(i).Child("usedForControllerCommunication"), true,
+				invalidListenerSecurityConfigErrMsg+": only one internal listener can be used for controller communication"))
+		}
+	}
+	return allErrs
+}
+
+func checkSSLClientAuth(path *field.Path, listener banzaicloudv1beta1.CommonListenerSpec) field.ErrorList {
+	if listener.SSLClientAuth == "" || listener.Type.IsSSL() {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(path.Child("sslClientAuth"), listener.SSLClientAuth,
+		invalidListenerSecurityConfigErrMsg+": client authentication mode can only be set on ssl and sasl_ssl listeners")}
+}
+
+func checkInnerBrokerListener(path *field.Path, kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec, listener banzaicloudv1beta1.CommonListenerSpec) field.ErrorList {
+	if listener.Type.IsSasl() {
+		return field.ErrorList{field.Invalid(path.Child("type"), listener.Type,
+			invalidListenerSecurityConfigErrMsg+": the operator and Cruise Control cannot connect to SASL listeners, the listener used for inner broker communication must be ssl or plaintext")}
+	}
+	if listener.Type != banzaicloudv1beta1.SecurityProtocolSSL {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if !kafkaClusterSpec.IsClientSSLSecretPresent() {
+		allErrs = append(allErrs, field.Invalid(path.Child("type"), listener.Type,
+			invalidListenerSecurityConfigErrMsg+": sslSecrets or clientSSLCertSecret must be set when the listener used for inner broker communication uses SSL"))
+	}
+	if kafkaClusterSpec.AuthorizationConfig != nil && listener.SSLClientAuth == banzaicloudv1beta1.SSLClientAuthNone {
+		allErrs = append(allErrs, field.Invalid(path.Child("sslClientAuth"), listener.SSLClientAuth,
+			invalidListenerSecurityConfigErrMsg+": client authentication is needed on the listener used for inner broker communication when an authorizer is configured"))
+	}
+	return allErrs
+}
+
+// tls13CipherSuites are the cipher suites which can be used with TLS 1.3
+var tls13CipherSuites = map[string]struct{}{
+	"TLS_AES_128_GCM_SHA256":       {},
+	"TLS_AES_256_GCM_SHA384":       {},
+	"TLS_CHACHA20_POLY1305_SHA256": {},
+	"TLS_AES_128_CCM_SHA256":       {},
+	"TLS_AES_128_CCM_8_SHA256":     {},
+}
+
+// checkListenerTLSPolicy checks that the TLS policies are set on SSL listeners only, the cipher suites can be used with
+// the enabled protocols and the PKCS12 keystores are provided by the user
+func checkListenerTLSPolicy(listeners banzaicloudv1beta1.ListenersConfig) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, intListener := range listeners.InternalListeners {
+		path := field.NewPath("spec").Child("listenersConfig").Child("internalListeners").Index(i).Child("tlsPolicy")
+		allErrs = append(allErrs, checkTLSPolicy(path, intListener.CommonListenerSpec)...)
+	}
+	for i, extListener := range listeners.ExternalListeners {
+		path := field.NewPath("spec").Child("listenersConfig").Child("externalListeners").Index(i).Child("tlsPolicy")
+		allErrs = append(allErrs, checkTLSPolicy(path, extListener.CommonListenerSpec)...)
+	}
+	return allErrs
+}
+
+func checkTLSPolicy(path *field.Path, listener banzaicloudv1beta1.CommonListenerSpec) field.ErrorList {
+	policy := listener.TLSPolicy
+	if policy == nil {
+		return nil
+	}
+	if listener.Type != banzaicloudv1beta1.SecurityProtocolSSL {
+		return field.ErrorList{field.Invalid(path, listener.Type, invalidListenerTLSPolicyErrMsg+": only ssl listeners can have TLS policy")}
+	}
+
+	var allErrs field.ErrorList
+	tls13Only := len(policy.EnabledProtocols) > 0
+	for _, protocol := range policy.EnabledProtocols {
+		if protocol != banzaicloudv1beta1.TLSProtocolV13 {
+			tls13Only = false
+		}
+	}
+	for j, cipherSuite := range policy.CipherSuites {
+		if cipherSuite == "" || strings.ContainsAny(cipherSuite, ", ") {
+			allErrs = append(allErrs, field.Invalid(path.Child("cipherSuites").Index(j), cipherSuite,
+				invalidListenerTLSPolicyErrMsg+": cipher suite must be a single JSSE cipher suite name"))
+			continue
+		}
+		if _, ok := tls13CipherSuites[cipherSuite]; tls13Only && !ok {
+			allErrs = append(allErrs, field.Invalid(path.Child("cipherSuites").Index(j), cipherSuite,
+				invalidListenerTLSPolicyErrMsg+": cipher suite cannot be used with TLSv1.3"))
+		}
+	}
+	if listener.GetServerSSLCertSecretName() == "" {
+		if policy.KeyStoreType == banzaicloudv1beta1.KeyStoreTypePKCS12 {
+			allErrs = append(allErrs, field.Invalid(path.Child("keyStoreType"), policy.KeyStoreType,
+				invalidListenerTLSPolicyErrMsg+": PKCS12 keystore requires serverSSLCertSecret"))
+		}
+		if policy.TrustStoreType == banzaicloudv1beta1.KeyStoreTypePKCS12 {
+			allErrs = append(allErrs, field.Invalid(path.Child("trustStoreType"), policy.TrustStoreType,
+				invalidListenerTLSPolicyErrMsg+": PKCS12 truststore requires serverSSLCertSecret"))
+		}
+	}
+	return allErrs
+}
+
+// checkListenerCertManagerConfig checks that the dedicated listener certificates can be issued by cert-manager which
+// is only set up when the 'sslSecrets' field is given
+func checkListenerCertManagerConfig(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	if kafkaClusterSpec.ListenersConfig.SSLSecrets != nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	for i, intListener := range kafkaClusterSpec.ListenersConfig.InternalListeners {
+		if intListener.HasDedicatedCertManagerCertificate() {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("listenersConfig").Child("internalListeners").Index(i).Child("certManager"),
+				intListener.Name, invalidListenerCertManagerConfigErrMsg))
+		}
+	}
+	for i, extListener := range kafkaClusterSpec.ListenersConfig.ExternalListeners {
+		if extListener.HasDedicatedCertManagerCertificate() {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("listenersConfig").Child("externalListeners").Index(i).Child("certManager"),
+				extListener.Name, invalidListenerCertManagerConfigErrMsg))
+		}
+	}
+	return allErrs
+}
+
+// checkListenerSPIFFE checks that the listeners using the X.509 SVIDs of the brokers are SSL listeners without other
+// sources of server certificates, and that the SVIDs of the brokers are trusted by the operator and Cruise Control when
+// the SPIFFE listener is used for inner broker communication
+func checkListenerSPIFFE(kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, intListener := range kafkaClusterSpec.ListenersConfig.InternalListeners {
+		path := field.NewPath("spec").Child("listenersConfig").Child("internalListeners").Index(i)
+		allErrs = append(allErrs, checkSPIFFE(path, kafkaClusterSpec, intListener.CommonListenerSpec, intListener.UsedForInnerBrokerCommunication)...)
+	}
+	for i, extListener := range kafkaClusterSpec.ListenersConfig.ExternalListeners {
+		path := field.NewPath("spec").Child("listenersConfig").Child("externalListeners").Index(i)
+		allErrs = append(allErrs, checkSPIFFE(path, kafkaClusterSpec, extListener.CommonListenerSpec, false)...)
+	}
+	return allErrs
+}
+
+func checkSPIFFE(path *field.Path, kafkaClusterSpec *banzaicloudv1beta1.KafkaClusterSpec, listener banzaicloudv1beta1.CommonListenerSpec,
+	usedForInnerBrokerCommunication bool) field.ErrorList {
+	if !listener.SPIFFE {
+		return nil
+	}
+	if listener.Type != banzaicloudv1beta1.SecurityProtocolSSL {
+		return field.ErrorList{field.Invalid(path.Child("spiffe"), listener.Type, invalidListenerSPIFFEConfigErrMsg+": only ssl listeners can use SPIFFE")}
+	}
+
+	var allErrs field.ErrorList
+	if kafkaClusterSpec.SPIFFEConfig == nil {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("spiffeConfig"), invalidListenerSPIFFEConfigErrMsg+": spiffeConfig must be set"))
+	}
+	if listener.ServerSSLCertSecret != nil {
+		allErrs = append(allErrs, field.Invalid(path.Child("serverSSLCertSecret"), listener.GetServerSSLCertSecretName(),
+			invalidListenerSPIFFEConfigErrMsg+": serverSSLCertSecret cannot be used together with SPIFFE"))
+	}
+	if listener.CertManager != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("certManager"), invalidListenerSPIFFEConfigErrMsg+": certManager cannot be used together with SPIFFE"))
+	}
+	if listener.TLSPolicy != nil && (listener.TLSPolicy.KeyStoreType != "" || listener.TLSPolicy.TrustStoreType != "") {
+		allErrs = append(allErrs, field.Forbidden(path.Child("tlsPolicy"), invalidListenerSPIFFEConfigErrMsg+": keystore types of SPIFFE listeners cannot be set"))
+	}
+	if usedForInnerBrokerCommunication {
+		if !kafkaClusterSpec.IsClientSSLSecretPresent() {
+			allErrs = append(allErrs, field.Invalid(path.Child("spiffe"), listener.SPIFFE,
+				invalidListenerSPIFFEConfigErrMsg+": sslSecrets or clientSSLCertSecret must be set when the listener is used for inner broker communication"))
+		}
+		if len(kafkaClusterSpec.ListenersConfig.AdditionalTrustedCAs) == 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("spiffe"), listener.SPIFFE,
+				invalidListenerSPIFFEConfigErrMsg+": the SPIFFE trust bundle must be added to additionalTrustedCAs when the listener is used for inner broker communication"))
+		}
+	}
 	return allErrs
 }
 
@@ -15,13 +15,19 @@
 package webhooks
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/banzaicloud/koperator/api/v1beta1"
+	"github.com/banzaicloud/koperator/pkg/util"
 
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 // nolint: funlen
@@ -690,3 +696,844 @@ func TestCheckExternalListenerStartingPort(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckListenerCertManagerConfig(t *testing.T) {
+	certManager := &v1beta1.ListenerCertManagerConfig{DNSNames: []string{"kafka.example.com"}}
+	listeners := v1beta1.ListenersConfig{
+		InternalListeners: []v1beta1.InternalListenerConfig{
+			{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL}},
+			{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal-plain", Type: v1beta1.SecurityProtocolPlaintext, CertManager: certManager}},
+		},
+		ExternalListeners: []v1beta1.ExternalListenerConfig{
+			{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "external", Type: v1beta1.SecurityProtocolSSL, CertManager: certManager}},
+		},
+	}
+
+	testCases := []struct {
+		testName        string
+		listenersConfig v1beta1.ListenersConfig
+		sslSecrets      *v1beta1.SSLSecrets
+		expected        field.ErrorList
+	}{
+		{
+			testName:        "valid config: sslSecrets is set",
+			listenersConfig: listeners,
+			sslSecrets:      &v1beta1.SSLSecrets{Create: true},
+			expected:        nil,
+		},
+		{
+			testName:        "invalid config: sslSecrets is missing",
+			listenersConfig: listeners,
+			expected: append(field.ErrorList{},
+				field.Invalid(field.NewPath("spec").Child("listenersConfig").Child("externalListeners").Index(0).Child("certManager"),
+					"external", invalidListenerCertManagerConfigErrMsg)),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			spec := v1beta1.KafkaClusterSpec{ListenersConfig: testCase.listenersConfig}
+			spec.ListenersConfig.SSLSecrets = testCase.sslSecrets
+			got := checkListenerCertManagerConfig(&spec)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckListenerTLSPolicy(t *testing.T) {
+	path := field.NewPath("spec").Child("listenersConfig").Child("internalListeners").Index(0).Child("tlsPolicy")
+	testCases := []struct {
+		testName string
+		listener v1beta1.CommonListenerSpec
+		expected field.ErrorList
+	}{
+		{
+			testName: "valid config: TLSv1.3 with TLSv1.3 cipher suites",
+			listener: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL, TLSPolicy: &v1beta1.ListenerTLSPolicy{
+				EnabledProtocols: []v1beta1.TLSProtocol{v1beta1.TLSProtocolV13},
+				CipherSuites:     []string{"TLS_AES_256_GCM_SHA384"},
+			}},
+			expected: nil,
+		},
+		{
+			testName: "invalid config: TLS policy on plaintext listener",
+			listener: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolPlaintext, TLSPolicy: &v1beta1.ListenerTLSPolicy{}},
+			expected: field.ErrorList{field.Invalid(path, v1beta1.SecurityProtocolPlaintext, invalidListenerTLSPolicyErrMsg+": only ssl listeners can have TLS policy")},
+		},
+		{
+			testName: "invalid config: TLSv1.2 cipher suite with TLSv1.3 only",
+			listener: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL, TLSPolicy: &v1beta1.ListenerTLSPolicy{
+				EnabledProtocols: []v1beta1.TLSProtocol{v1beta1.TLSProtocolV13},
+				CipherSuites:     []string{"TLS_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "A,B"},
+			}},
+			expected: field.ErrorList{
+				field.Invalid(path.Child("cipherSuites").Index(1), "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", invalidListenerTLSPolicyErrMsg+": cipher suite cannot be used with TLSv1.3"),
+				field.Invalid(path.Child("cipherSuites").Index(2), "A,B", invalidListenerTLSPolicyErrMsg+": cipher suite must be a single JSSE cipher suite name"),
+			},
+		},
+		{
+			testName: "invalid config: PKCS12 keystore with generated certificate",
+			listener: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL, TLSPolicy: &v1beta1.ListenerTLSPolicy{
+				KeyStoreType: v1beta1.KeyStoreTypePKCS12,
+			}},
+			expected: field.ErrorList{field.Invalid(path.Child("keyStoreType"), v1beta1.KeyStoreTypePKCS12, invalidListenerTLSPolicyErrMsg+": PKCS12 keystore requires serverSSLCertSecret")},
+		},
+		{
+			testName: "valid config: PKCS12 keystore with custom certificate",
+			listener: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL,
+				ServerSSLCertSecret: &corev1.LocalObjectReference{Name: "custom"},
+				TLSPolicy: &v1beta1.ListenerTLSPolicy{
+					KeyStoreType:   v1beta1.KeyStoreTypePKCS12,
+					TrustStoreType: v1beta1.KeyStoreTypePKCS12,
+				}},
+			expected: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			listeners := v1beta1.ListenersConfig{InternalListeners: []v1beta1.InternalListenerConfig{{CommonListenerSpec: testCase.listener}}}
+			got := checkListenerTLSPolicy(listeners)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckListenerSPIFFE(t *testing.T) {
+	path := field.NewPath("spec").Child("listenersConfig").Child("internalListeners").Index(0)
+	trustedCAs := []v1beta1.TrustedCASource{{
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "spire-bundle"}, Key: "bundle.crt"},
+	}}
+	testCases := []struct {
+		testName string
+		spec     v1beta1.KafkaClusterSpec
+		expected field.ErrorList
+	}{
+		{
+			testName: "valid config: SPIFFE inner broker listener",
+			spec: v1beta1.KafkaClusterSpec{
+				SPIFFEConfig: &v1beta1.SPIFFEConfig{},
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL, SPIFFE: true},
+						UsedForInnerBrokerCommunication: true,
+					}},
+					SSLSecrets:           &v1beta1.SSLSecrets{},
+					AdditionalTrustedCAs: trustedCAs,
+				},
+			},
+			expected: nil,
+		},
+		{
+			testName: "invalid config: SPIFFE on plaintext listener",
+			spec: v1beta1.KafkaClusterSpec{
+				SPIFFEConfig: &v1beta1.SPIFFEConfig{},
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolPlaintext, SPIFFE: true},
+					}},
+				},
+			},
+			expected: field.ErrorList{field.Invalid(path.Child("spiffe"), v1beta1.SecurityProtocolPlaintext, invalidListenerSPIFFEConfigErrMsg+": only ssl listeners can use SPIFFE")},
+		},
+		{
+			testName: "invalid config: SPIFFE inner broker listener without spiffeConfig and trusted SPIFFE bundle",
+			spec: v1beta1.KafkaClusterSpec{
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL, SPIFFE: true,
+							ServerSSLCertSecret: &corev1.LocalObjectReference{Name: "custom"}},
+						UsedForInnerBrokerCommunication: true,
+					}},
+					SSLSecrets: &v1beta1.SSLSecrets{},
+				},
+			},
+			expected: field.ErrorList{
+				field.Required(field.NewPath("spec").Child("spiffeConfig"), invalidListenerSPIFFEConfigErrMsg+": spiffeConfig must be set"),
+				field.Invalid(path.Child("serverSSLCertSecret"), "custom", invalidListenerSPIFFEConfigErrMsg+": serverSSLCertSecret cannot be used together with SPIFFE"),
+				field.Invalid(path.Child("spiffe"), true,
+					invalidListenerSPIFFEConfigErrMsg+": the SPIFFE trust bundle must be added to additionalTrustedCAs when the listener is used for inner broker communication"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			got := checkListenerSPIFFE(&testCase.spec)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckBrokerIDPolicy(t *testing.T) {
+	highest := int32(5)
+	kafkaClusterOld := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			BrokerIDPolicy: v1beta1.BrokerIDPolicyAlwaysIncrement,
+			Brokers:        []v1beta1.Broker{{Id: 0}, {Id: 1}},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState:    map[string]v1beta1.BrokerState{"0": {}, "1": {}, "2": {}},
+			HighestBrokerID: &highest,
+		},
+	}
+
+	testCases := []struct {
+		testName string
+		policy   v1beta1.BrokerIDPolicy
+		brokers  []v1beta1.Broker
+		expected field.ErrorList
+	}{
+		{
+			testName: "broker with higher ID is added",
+			policy:   v1beta1.BrokerIDPolicyAlwaysIncrement,
+			brokers:  []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 6}},
+		},
+		{
+			testName: "broker being removed is added back",
+			policy:   v1beta1.BrokerIDPolicyAlwaysIncrement,
+			brokers:  []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}},
+		},
+		{
+			testName: "ID of a former broker is reused",
+			policy:   v1beta1.BrokerIDPolicyAlwaysIncrement,
+			brokers:  []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 4}},
+			expected: field.ErrorList{field.Invalid(field.NewPath("spec").Child("brokers").Index(2).Child("id"), int32(4),
+				invalidBrokerIDErrMsg+", the ID of the added broker must be higher than 5")},
+		},
+		{
+			testName: "ID of a former broker is reused with the default policy",
+			brokers:  []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 4}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		kafkaClusterNew := kafkaClusterOld.DeepCopy()
+		kafkaClusterNew.Spec.BrokerIDPolicy = testCase.policy
+		kafkaClusterNew.Spec.Brokers = testCase.brokers
+		require.Equal(t, testCase.expected, checkBrokerIDPolicy(kafkaClusterOld, kafkaClusterNew), testCase.testName)
+	}
+}
+
+func TestCheckRetiredBrokerIDs(t *testing.T) {
+	kafkaClusterOld := &v1beta1.KafkaCluster{
+		Spec: v1beta1.KafkaClusterSpec{
+			Brokers: []v1beta1.Broker{{Id: 0}, {Id: 1}},
+		},
+		Status: v1beta1.KafkaClusterStatus{
+			BrokersState:     map[string]v1beta1.BrokerState{"0": {}, "1": {}},
+			RetiredBrokerIDs: []int32{2},
+		},
+	}
+
+	testCases := []struct {
+		testName string
+		brokers  []v1beta1.Broker
+		expected field.ErrorList
+	}{
+		{
+			testName: "broker with a new ID is added",
+			brokers:  []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 3}},
+		},
+		{
+			testName: "broker with a retired ID is added",
+			brokers:  []v1beta1.Broker{{Id: 0}, {Id: 1}, {Id: 2}},
+			expected: field.ErrorList{field.Invalid(field.NewPath("spec").Child("brokers").Index(2).Child("id"), int32(2),
+				invalidBrokerIDErrMsg+", the ID has been retired by a KafkaBrokerDecommission")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		kafkaClusterNew := kafkaClusterOld.DeepCopy()
+		kafkaClusterNew.Spec.Brokers = testCase.brokers
+		require.Equal(t, testCase.expected, checkRetiredBrokerIDs(kafkaClusterOld, kafkaClusterNew), testCase.testName)
+	}
+}
+
+func TestCheckRetainedBrokerData(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kafka-3-storage",
+			Namespace: "kafka",
+			Labels:    map[string]string{"app": "kafka", "kafka_cr": "kafka", v1beta1.BrokerIdLabelKey: "3"},
+		},
+	}
+	validator := KafkaClusterValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(pvc).Build()}
+
+	kafkaClusterOld := &v1beta1.KafkaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "kafka"},
+		Spec:       v1beta1.KafkaClusterSpec{Brokers: []v1beta1.Broker{{Id: 0}}},
+	}
+	kafkaClusterNew := kafkaClusterOld.DeepCopy()
+	kafkaClusterNew.Spec.Brokers = append(kafkaClusterNew.Spec.Brokers, v1beta1.Broker{Id: 3}, v1beta1.Broker{Id: 4})
+
+	allErrs, err := validator.checkRetainedBrokerData(context.Background(), kafkaClusterOld, kafkaClusterNew)
+	require.NoError(t, err)
+	require.Equal(t, field.ErrorList{field.Invalid(field.NewPath("spec").Child("brokers").Index(1).Child("id"), int32(3),
+		invalidBrokerIDErrMsg+", persistent volume claim kafka-3-storage still holds the data of a former broker with the same ID")}, allErrs)
+}
+
+func TestCheckAuthorizationConfig(t *testing.T) {
+	path := field.NewPath("spec").Child("authorizationConfig")
+	testCases := []struct {
+		testName       string
+		authConfig     *v1beta1.AuthorizationConfig
+		readOnlyConfig string
+		listenerType   v1beta1.SecurityProtocol
+		spiffe         bool
+		expected       field.ErrorList
+	}{
+		{
+			testName: "authorization is not configured",
+		},
+		{
+			testName:   "ACL authorizer with super users",
+			authConfig: &v1beta1.AuthorizationConfig{SuperUsers: []string{"User:admin", "User:CN=client"}},
+		},
+		{
+			testName:   "custom authorizer without class name",
+			authConfig: &v1beta1.AuthorizationConfig{Authorizer: v1beta1.AuthorizerTypeCustom},
+			expected: field.ErrorList{field.Required(path.Child("customAuthorizerClassName"),
+				invalidAuthorizationConfigErrMsg+": the class name must be set for custom authorizers")},
+		},
+		{
+			testName:   "OPA authorizer without URL",
+			authConfig: &v1beta1.AuthorizationConfig{Authorizer: v1beta1.AuthorizerTypeOPA},
+			expected: field.ErrorList{field.Required(path.Child("opa").Child("url"),
+				invalidAuthorizationConfigErrMsg+": the URL of the OPA policy must be set")},
+		},
+		{
+			testName:   "invalid super user",
+			authConfig: &v1beta1.AuthorizationConfig{SuperUsers: []string{"admin"}},
+			expected: field.ErrorList{field.Invalid(path.Child("superUsers").Index(0), "admin",
+				invalidAuthorizationConfigErrMsg+": super users must be principals in <type>:<name> format, e.g. User:admin")},
+		},
+		{
+			testName:   "authorizer class name is managed by the operator",
+			authConfig: &v1beta1.AuthorizationConfig{PluginConfig: map[string]string{"authorizer.class.name": "custom"}},
+			expected: field.ErrorList{field.Forbidden(path.Child("pluginConfig").Key("authorizer.class.name"),
+				invalidAuthorizationConfigErrMsg+": the property is managed by the operator")},
+		},
+		{
+			testName:       "authorizer is configured in the read-only config as well",
+			authConfig:     &v1beta1.AuthorizationConfig{},
+			readOnlyConfig: "authorizer.class.name=kafka.security.authorizer.AclAuthorizer",
+			expected: field.ErrorList{field.Forbidden(field.NewPath("spec").Child("readOnlyConfig"),
+				invalidAuthorizationConfigErrMsg+": authorizer.class.name cannot be set when authorizationConfig is used")},
+		},
+		{
+			testName:     "brokers communicate over a plaintext listener",
+			authConfig:   &v1beta1.AuthorizationConfig{},
+			listenerType: v1beta1.SecurityProtocolPlaintext,
+			expected: field.ErrorList{field.Invalid(field.NewPath("spec").Child("listenersConfig").Child("internalListeners").Index(0).Child("type"),
+				v1beta1.SecurityProtocolPlaintext, invalidAuthorizationConfigErrMsg+": the brokers must communicate over an ssl listener when authorization is enabled")},
+		},
+		{
+			testName:     "brokers communicate over a SASL listener",
+			authConfig:   &v1beta1.AuthorizationConfig{},
+			listenerType: v1beta1.SecurityProtocolSaslSSL,
+			expected: field.ErrorList{field.Invalid(field.NewPath("spec").Child("listenersConfig").Child("internalListeners").Index(0).Child("type"),
+				v1beta1.SecurityProtocolSaslSSL, invalidAuthorizationConfigErrMsg+": the brokers must communicate over an ssl listener when authorization is enabled")},
+		},
+		{
+			testName:   "brokers communicate over a SPIFFE listener",
+			authConfig: &v1beta1.AuthorizationConfig{},
+			spiffe:     true,
+			expected: field.ErrorList{field.Invalid(field.NewPath("spec").Child("listenersConfig").Child("internalListeners").Index(0).Child("spiffe"),
+				true, invalidAuthorizationConfigErrMsg+": the brokers cannot communicate over a SPIFFE listener when authorization is enabled")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		listenerType := testCase.listenerType
+		if listenerType == "" {
+			listenerType = v1beta1.SecurityProtocolSSL
+		}
+		spec := &v1beta1.KafkaClusterSpec{
+			AuthorizationConfig: testCase.authConfig,
+			ReadOnlyConfig:      testCase.readOnlyConfig,
+			ListenersConfig: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", Type: listenerType, SPIFFE: testCase.spiffe}, UsedForInnerBrokerCommunication: true},
+				},
+			},
+		}
+		require.Equal(t, testCase.expected, checkAuthorizationConfig(spec), testCase.testName)
+	}
+}
+
+func TestCheckListenerSecurity(t *testing.T) {
+	listenersPath := field.NewPath("spec").Child("listenersConfig")
+	testCases := []struct {
+		testName string
+		spec     v1beta1.KafkaClusterSpec
+		expected field.ErrorList
+	}{
+		{
+			testName: "valid config: plaintext external listener next to mTLS inner broker listener",
+			spec: v1beta1.KafkaClusterSpec{
+				AuthorizationConfig: &v1beta1.AuthorizationConfig{},
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL},
+						UsedForInnerBrokerCommunication: true,
+					}},
+					ExternalListeners: []v1beta1.ExternalListenerConfig{
+						{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "legacy", Type: v1beta1.SecurityProtocolPlaintext}},
+						{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "tls", Type: v1beta1.SecurityProtocolSSL, SSLClientAuth: v1beta1.SSLClientAuthRequested}},
+					},
+					SSLSecrets: &v1beta1.SSLSecrets{},
+				},
+			},
+			expected: nil,
+		},
+		{
+			testName: "invalid config: no inner broker listener and client auth on plaintext listener",
+			spec: v1beta1.KafkaClusterSpec{
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolPlaintext, SSLClientAuth: v1beta1.SSLClientAuthRequired},
+					}},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(listenersPath.Child("internalListeners").Index(0).Child("sslClientAuth"), v1beta1.SSLClientAuthRequired,
+					invalidListenerSecurityConfigErrMsg+": client authentication mode can only be set on ssl and sasl_ssl listeners"),
+				field.Required(listenersPath.Child("internalListeners"), invalidListenerSecurityConfigErrMsg+": one internal listener must be used for inner broker communication"),
+			},
+		},
+		{
+			testName: "invalid config: multiple inner broker and controller listeners",
+			spec: v1beta1.KafkaClusterSpec{
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{
+						{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolPlaintext}, UsedForInnerBrokerCommunication: true, UsedForControllerCommunication: true},
+						{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "other", Type: v1beta1.SecurityProtocolPlaintext}, UsedForInnerBrokerCommunication: true, UsedForControllerCommunication: true},
+					},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(listenersPath.Child("internalListeners").Index(1).Child("usedForInnerBrokerCommunication"), true,
+					invalidListenerSecurityConfigErrMsg+": only one internal listener can be used for inner broker communication"),
+				field.Invalid(listenersPath.Child("internalListeners").Index(1).Child("usedForControllerCommunication"), true,
+					invalidListenerSecurityConfigErrMsg+": only one internal listener can be used for controller communication"),
+			},
+		},
+		{
+			testName: "invalid config: SASL inner broker listener",
+			spec: v1beta1.KafkaClusterSpec{
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSaslSSL},
+						UsedForInnerBrokerCommunication: true,
+					}},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(listenersPath.Child("internalListeners").Index(0).Child("type"), v1beta1.SecurityProtocolSaslSSL,
+					invalidListenerSecurityConfigErrMsg+": the operator and Cruise Control cannot connect to SASL listeners, the listener used for inner broker communication must be ssl or plaintext"),
+			},
+		},
+		{
+			testName: "invalid config: SSL inner broker listener without client certificates and client authentication",
+			spec: v1beta1.KafkaClusterSpec{
+				AuthorizationConfig: &v1beta1.AuthorizationConfig{},
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL, SSLClientAuth: v1beta1.SSLClientAuthNone,
+							ServerSSLCertSecret: &corev1.LocalObjectReference{Name: "custom"}},
+						UsedForInnerBrokerCommunication: true,
+					}},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(listenersPath.Child("internalListeners").Index(0).Child("type"), v1beta1.SecurityProtocolSSL,
+					invalidListenerSecurityConfigErrMsg+": sslSecrets or clientSSLCertSecret must be set when the listener used for inner broker communication uses SSL"),
+				field.Invalid(listenersPath.Child("internalListeners").Index(0).Child("sslClientAuth"), v1beta1.SSLClientAuthNone,
+					invalidListenerSecurityConfigErrMsg+": client authentication is needed on the listener used for inner broker communication when an authorizer is configured"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			got := checkListenerSecurity(&testCase.spec)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckReplicationListener(t *testing.T) {
+	path := field.NewPath("spec").Child("listenersConfig").Child("internalListeners")
+	testCases := []struct {
+		testName  string
+		listeners v1beta1.ListenersConfig
+		expected  field.ErrorList
+	}{
+		{
+			testName: "valid config: replication listener on secondary network",
+			listeners: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL}, UsedForInnerBrokerCommunication: true},
+					{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "replication", Type: v1beta1.SecurityProtocolSSL},
+						UsedForReplication: true,
+						NetworkAttachment:  &v1beta1.ListenerNetworkAttachment{Name: "replication-net", Interface: "net1"},
+					},
+				},
+			},
+			expected: nil,
+		},
+		{
+			testName: "invalid config: multiple replication listeners sharing network interface and inner broker listener on secondary network",
+			listeners: v1beta1.ListenersConfig{
+				InternalListeners: []v1beta1.InternalListenerConfig{
+					{
+						CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL},
+						UsedForInnerBrokerCommunication: true,
+						NetworkAttachment:               &v1beta1.ListenerNetworkAttachment{Name: "replication-net", Interface: "net1"},
+					},
+					{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "replication", Type: v1beta1.SecurityProtocolSSL},
+						UsedForReplication: true,
+						NetworkAttachment:  &v1beta1.ListenerNetworkAttachment{Name: "replication-net", Interface: "net1"},
+					},
+					{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "other", Type: v1beta1.SecurityProtocolSSL},
+						UsedForReplication: true,
+					},
+				},
+			},
+			expected: field.ErrorList{
+				field.Forbidden(path.Index(0).Child("networkAttachment"),
+					invalidReplicationListenerErrMsg+": the listener used for inner broker communication cannot be bound to a secondary network"),
+				field.Duplicate(path.Index(1).Child("networkAttachment").Child("interface"), "net1"),
+				field.Invalid(path.Index(2).Child("usedForReplication"), true, invalidReplicationListenerErrMsg+": only one internal listener can be used for replication"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			got := checkReplicationListener(testCase.listeners)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckRemoteJMXConfig(t *testing.T) {
+	path := field.NewPath("spec").Child("remoteJmxConfig")
+	listenerPort := int32(29092)
+	sslListeners := v1beta1.ListenersConfig{
+		InternalListeners: []v1beta1.InternalListenerConfig{
+			{
+				CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolSSL, ContainerPort: 29092},
+				UsedForInnerBrokerCommunication: true,
+			},
+		},
+	}
+	plaintextListeners := v1beta1.ListenersConfig{
+		InternalListeners: []v1beta1.InternalListenerConfig{
+			{
+				CommonListenerSpec:              v1beta1.CommonListenerSpec{Name: "internal", Type: v1beta1.SecurityProtocolPlaintext, ContainerPort: 29092},
+				UsedForInnerBrokerCommunication: true,
+			},
+		},
+	}
+	testCases := []struct {
+		testName  string
+		jmxConfig *v1beta1.RemoteJMXConfig
+		listeners v1beta1.ListenersConfig
+		envs      []corev1.EnvVar
+		expected  field.ErrorList
+	}{
+		{
+			testName:  "remote JMX is not configured",
+			listeners: plaintextListeners,
+		},
+		{
+			testName:  "remote JMX is disabled",
+			jmxConfig: &v1beta1.RemoteJMXConfig{SSL: true},
+			listeners: plaintextListeners,
+		},
+		{
+			testName:  "valid config: SSL with SSL inner broker listener",
+			jmxConfig: &v1beta1.RemoteJMXConfig{Enabled: true, SSL: true},
+			listeners: sslListeners,
+		},
+		{
+			testName:  "invalid config: port used by a listener",
+			jmxConfig: &v1beta1.RemoteJMXConfig{Enabled: true, Port: &listenerPort},
+			listeners: plaintextListeners,
+			expected: field.ErrorList{field.Invalid(path.Child("port"), int32(29092),
+				invalidRemoteJMXConfigErrMsg+": the port is used by the internal listener internal")},
+		},
+		{
+			testName:  "invalid config: JMX port is set by environment variable",
+			jmxConfig: &v1beta1.RemoteJMXConfig{Enabled: true},
+			listeners: plaintextListeners,
+			envs:      []corev1.EnvVar{{Name: "JMX_PORT", Value: "5555"}},
+			expected: field.ErrorList{field.Forbidden(field.NewPath("spec").Child("envs").Index(0),
+				invalidRemoteJMXConfigErrMsg+": the JMX port is set by remoteJmxConfig")},
+		},
+		{
+			testName:  "invalid config: SSL with plaintext inner broker listener",
+			jmxConfig: &v1beta1.RemoteJMXConfig{Enabled: true, SSL: true},
+			listeners: plaintextListeners,
+			expected: field.ErrorList{field.Forbidden(path.Child("ssl"),
+				invalidRemoteJMXConfigErrMsg+": SSL requires the listener used for inner broker communication to be an SSL listener which does not use SPIFFE")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			spec := &v1beta1.KafkaClusterSpec{RemoteJMXConfig: testCase.jmxConfig, ListenersConfig: testCase.listeners, Envs: testCase.envs}
+			got := checkRemoteJMXConfig(spec)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckParkedBrokerConfigGroups(t *testing.T) {
+	path := field.NewPath("spec").Child("parkedBrokerConfigGroups")
+	brokerConfigGroups := map[string]v1beta1.BrokerConfig{"default": {}, "spot": {}}
+	brokers := []v1beta1.Broker{{Id: 0, BrokerConfigGroup: "default"}, {Id: 1, BrokerConfigGroup: "spot"}}
+	testCases := []struct {
+		testName     string
+		parkedGroups []string
+		expected     field.ErrorList
+	}{
+		{
+			testName: "no parked groups",
+		},
+		{
+			testName:     "valid config: some brokers are left running",
+			parkedGroups: []string{"spot"},
+		},
+		{
+			testName:     "invalid config: unknown group",
+			parkedGroups: []string{"spot", "missing"},
+			expected:     field.ErrorList{field.NotFound(path.Index(1), "missing")},
+		},
+		{
+			testName:     "invalid config: all brokers are parked",
+			parkedGroups: []string{"default", "spot"},
+			expected:     field.ErrorList{field.Forbidden(path, invalidParkedBrokerConfigGroupsErrMsg+": at least one broker must not be parked")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			spec := &v1beta1.KafkaClusterSpec{
+				Brokers:                  brokers,
+				BrokerConfigGroups:       brokerConfigGroups,
+				ParkedBrokerConfigGroups: testCase.parkedGroups,
+			}
+			got := checkParkedBrokerConfigGroups(spec)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckCapacitySchedule(t *testing.T) {
+	path := field.NewPath("spec").Child("capacitySchedule")
+	testCases := []struct {
+		testName string
+		schedule *v1beta1.CapacitySchedule
+		expected field.ErrorList
+	}{
+		{
+			testName: "no capacity schedule",
+		},
+		{
+			testName: "valid schedule",
+			schedule: &v1beta1.CapacitySchedule{
+				TimeZone: "Europe/Budapest",
+				Rules: []v1beta1.CapacityScheduleRule{
+					{Name: "weekend", Window: v1beta1.WeeklyTimeWindow{Days: []v1beta1.Weekday{"Saturday", "Sunday"}}, BrokerCounts: map[string]int32{"default": 6}},
+				},
+			},
+		},
+		{
+			testName: "invalid schedule",
+			schedule: &v1beta1.CapacitySchedule{
+				TimeZone: "Mars/Olympus_Mons",
+				Rules: []v1beta1.CapacityScheduleRule{
+					{Name: "weekend", BrokerCounts: map[string]int32{"default": -1, "missing": 3}},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(path.Child("timeZone"), "Mars/Olympus_Mons", invalidCapacityScheduleErrMsg+": unknown time zone"),
+				field.Invalid(path.Child("rules").Index(0).Child("brokerCounts").Key("default"), int32(-1),
+					invalidCapacityScheduleErrMsg+": the number of brokers must not be negative"),
+				field.NotFound(path.Child("rules").Index(0).Child("brokerCounts").Key("missing"), "missing"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			spec := &v1beta1.KafkaClusterSpec{
+				BrokerConfigGroups: map[string]v1beta1.BrokerConfig{"default": {}},
+				CapacitySchedule:   testCase.schedule,
+			}
+			got := checkCapacitySchedule(spec)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckCruiseControlSettings(t *testing.T) {
+	settings := &v1beta1.CruiseControlSettings{NumConcurrentIntraBrokerPartitionMovements: util.Int32Pointer(2)}
+	testCases := []struct {
+		testName string
+		ccConfig v1beta1.CruiseControlConfig
+		expected field.ErrorList
+	}{
+		{
+			testName: "no settings",
+		},
+		{
+			testName: "settings supported by the default image",
+			ccConfig: v1beta1.CruiseControlConfig{Settings: settings},
+		},
+		{
+			testName: "unknown version",
+			ccConfig: v1beta1.CruiseControlConfig{Settings: settings, Image: "registry.example.com/cruise-control:custom"},
+		},
+		{
+			testName: "settings not supported by the image",
+			ccConfig: v1beta1.CruiseControlConfig{Settings: settings, Image: "registry.example.com/cruise-control:2.0.100"},
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("spec").Child("cruiseControlConfig").Child("settings"), settings,
+					invalidCruiseControlSettingsErrMsg+": num.concurrent.intra.broker.partition.movements is not supported by "+
+						"Cruise Control version 2.0.100, version 2.5.0 or newer is required"),
+			},
+		},
+		{
+			testName: "version overrides the image tag",
+			ccConfig: v1beta1.CruiseControlConfig{Settings: settings, Image: "registry.example.com/cruise-control:2.0.100", Version: "2.5.101"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			got := checkCruiseControlSettings(&v1beta1.KafkaClusterSpec{CruiseControlConfig: testCase.ccConfig})
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckListenerAccessControl(t *testing.T) {
+	path := field.NewPath("spec").Child("listenersConfig").Child("externalListeners").Index(0).Child("accessControl")
+	testCases := []struct {
+		testName string
+		spec     v1beta1.KafkaClusterSpec
+		expected field.ErrorList
+	}{
+		{
+			testName: "valid access control",
+			spec: v1beta1.KafkaClusterSpec{
+				ListenersConfig: v1beta1.ListenersConfig{
+					ExternalListeners: []v1beta1.ExternalListenerConfig{{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "external"},
+						AccessControl: &v1beta1.EnvoyAccessControl{
+							AllowedSourceRanges: []string{"10.0.0.0/8", "2001:db8::/32"},
+							DeniedSourceRanges:  []string{"10.1.0.0/16"},
+						},
+					}},
+				},
+			},
+		},
+		{
+			testName: "invalid source ranges with istio ingress controller",
+			spec: v1beta1.KafkaClusterSpec{
+				IngressController: "istioingress",
+				ListenersConfig: v1beta1.ListenersConfig{
+					ExternalListeners: []v1beta1.ExternalListenerConfig{{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{Name: "external"},
+						AccessControl: &v1beta1.EnvoyAccessControl{
+							AllowedSourceRanges: []string{"10.0.0.1"},
+						},
+					}},
+				},
+			},
+			expected: field.ErrorList{
+				field.Forbidden(path, invalidListenerAccessControlErrMsg+": access control is supported only with Envoy ingress controller and LoadBalancer access method"),
+				field.Invalid(path.Child("allowedSourceRanges").Index(0), "10.0.0.1", invalidListenerAccessControlErrMsg+": invalid CIDR address: 10.0.0.1"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			got := checkListenerAccessControl(&testCase.spec)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
+
+func TestCheckListenerConnectionLimits(t *testing.T) {
+	maxConnections, listenerMaxConnections := int32(100), int32(200)
+	path := field.NewPath("spec").Child("listenersConfig")
+	testCases := []struct {
+		testName string
+		spec     v1beta1.KafkaClusterSpec
+		expected field.ErrorList
+	}{
+		{
+			testName: "valid connection limits",
+			spec: v1beta1.KafkaClusterSpec{
+				ListenersConfig: v1beta1.ListenersConfig{
+					InternalListeners: []v1beta1.InternalListenerConfig{{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{
+							Name:             "internal",
+							ConnectionLimits: &v1beta1.ListenerConnectionLimits{MaxConnections: &maxConnections},
+						},
+					}},
+					ConnectionLimits: &v1beta1.BrokerConnectionLimits{
+						MaxConnections:               &maxConnections,
+						MaxConnectionsPerIPOverrides: map[string]int32{"10.0.0.1": 10, "client.example.com": 20},
+					},
+				},
+			},
+		},
+		{
+			testName: "invalid connection limits",
+			spec: v1beta1.KafkaClusterSpec{
+				ReadOnlyConfig: "max.connections=1000",
+				ListenersConfig: v1beta1.ListenersConfig{
+					ExternalListeners: []v1beta1.ExternalListenerConfig{{
+						CommonListenerSpec: v1beta1.CommonListenerSpec{
+							Name:             "external",
+							ConnectionLimits: &v1beta1.ListenerConnectionLimits{MaxConnections: &listenerMaxConnections},
+						},
+					}},
+					ConnectionLimits: &v1beta1.BrokerConnectionLimits{
+						MaxConnections:               &maxConnections,
+						MaxConnectionsPerIPOverrides: map[string]int32{"10.0.0.1:9092": -1},
+					},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(path.Child("externalListeners").Index(0).Child("connectionLimits").Child("maxConnections"), int32(200),
+					invalidListenerConnectionLimitsErrMsg+": the limit of the listener cannot be higher than the limit of the broker (100)"),
+				field.Invalid(path.Child("connectionLimits").Child("maxConnectionsPerIPOverrides").Key("10.0.0.1:9092"), "10.0.0.1:9092",
+					invalidListenerConnectionLimitsErrMsg+": the key must be an IP address or a hostname"),
+				field.Invalid(path.Child("connectionLimits").Child("maxConnectionsPerIPOverrides").Key("10.0.0.1:9092"), int32(-1),
+					invalidListenerConnectionLimitsErrMsg+": the number of connections must not be negative"),
+				field.Forbidden(field.NewPath("spec").Child("readOnlyConfig"),
+					invalidListenerConnectionLimitsErrMsg+": max.connections cannot be set when connectionLimits is used"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			got := checkListenerConnectionLimits(&testCase.spec)
+			require.Equal(t, testCase.expected, got)
+		})
+	}
+}
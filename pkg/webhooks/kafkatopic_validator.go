@@ -127,6 +127,16 @@ func (s *KafkaTopicValidator) validateKafkaTopic(ctx context.Context, log logr.L
 		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("clusterRef").Child("name"), clusterName, logMsg))
 	}
 
+	if !k8sutil.IsMarkedForDeletion(topic.ObjectMeta) {
+		fieldErr, err := s.checkCrossNamespaceReference(ctx, topic, cluster)
+		if err != nil {
+			return nil, err
+		}
+		if fieldErr != nil {
+			allErrs = append(allErrs, fieldErr)
+		}
+	}
+
 	fieldErr, err := s.checkExistingKafkaTopicCRs(ctx, clusterNamespace, topic)
 	if err != nil {
 		return nil, err
@@ -145,6 +155,21 @@ func (s *KafkaTopicValidator) validateKafkaTopic(ctx context.Context, log logr.L
 	return allErrs, nil
 }
 
+// checkCrossNamespaceReference checks that the namespace of the KafkaTopic is allowed to reference the cluster
+func (s *KafkaTopicValidator) checkCrossNamespaceReference(ctx context.Context, topic *banzaicloudv1alpha1.KafkaTopic,
+	cluster *banzaicloudv1beta1.KafkaCluster) (*field.Error, error) {
+	allowed, err := k8sutil.IsCrossNamespaceReferenceAllowed(ctx, s.Client, cluster, topic.GetNamespace())
+	if err != nil {
+		return nil, errors.Wrap(err, cantConnectAPIServerMsg)
+	}
+	if allowed {
+		return nil, nil
+	}
+	return field.Forbidden(field.NewPath("spec").Child("clusterRef").Child("namespace"),
+		fmt.Sprintf("%s: kafkaCluster '%s' in the namespace '%s' does not allow references from the namespace '%s'",
+			crossNamespaceReferenceNotAllowedErrMsg, cluster.GetName(), cluster.GetNamespace(), topic.GetNamespace())), nil
+}
+
 // checkKafka creates a Kafka admin client and connects to the Kafka brokers to check
 // whether the referred topic exists, and what are its properties
 func (s *KafkaTopicValidator) checkKafka(ctx context.Context, topic *banzaicloudv1alpha1.KafkaTopic,
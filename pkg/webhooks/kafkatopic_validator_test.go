@@ -343,3 +343,26 @@ func TestValidateTopic(t *testing.T) {
 		t.Error("Expected not allowed for reason: kafka does not support changing the replication factor")
 	}
 }
+
+func TestCheckCrossNamespaceReference(t *testing.T) {
+	cluster := newMockCluster()
+	cluster.Spec.CrossNamespaceReferences = &v1beta1.CrossNamespaceReferences{Namespaces: []string{"apps"}}
+	client, _, _ := newMockClients(cluster)
+	kafkaTopicValidator := KafkaTopicValidator{Client: client}
+
+	topic := newMockTopic()
+	topic.Namespace = "apps"
+	fieldErr, err := kafkaTopicValidator.checkCrossNamespaceReference(context.Background(), topic, cluster)
+	if err != nil || fieldErr != nil {
+		t.Errorf("expected the reference to be allowed, got: %v, %v", fieldErr, err)
+	}
+
+	topic.Namespace = "other"
+	fieldErr, err = kafkaTopicValidator.checkCrossNamespaceReference(context.Background(), topic, cluster)
+	if err != nil {
+		t.Errorf("err should be nil, got: %s", err)
+	}
+	if fieldErr == nil || !strings.Contains(fieldErr.Error(), "does not allow references from the namespace 'other'") {
+		t.Errorf("expected the reference to be rejected, got: %v", fieldErr)
+	}
+}